@@ -75,9 +75,11 @@ func runInit() {
 		codegen.NewDomainGenerator(*outputDir),
 		codegen.NewApplicationGenerator(*outputDir),
 		codegen.NewInfrastructureGenerator(*outputDir),
+		codegen.NewReadModelGenerator(*outputDir),
 		codegen.NewPresentationGenerator(*outputDir),
 		codegen.NewMainGenerator(*outputDir),
 		codegen.NewSDKGenerator(*outputDir),
+		codegen.NewTopologyGenerator(*outputDir),
 	}
 
 		for _, gen := range generators {
@@ -123,6 +125,7 @@ func runGenerate() {
 	outputDir := fs.String("output", ".", "Output directory")
 	overwrite := fs.Bool("overwrite", false, "Overwrite existing files")
 	potterImportPath := fs.String("potter-import-path", defaultPotterImportPath, "Potter framework import path")
+	graphql := fs.Bool("graphql", false, "Generate gqlgen schema and resolvers wired to CommandBus/QueryBus")
 
 	fs.Parse(os.Args[2:])
 
@@ -176,9 +179,14 @@ func runGenerate() {
 		codegen.NewDomainGenerator(*outputDir),
 		codegen.NewApplicationGenerator(*outputDir),
 		codegen.NewInfrastructureGenerator(*outputDir),
+		codegen.NewReadModelGenerator(*outputDir),
 		codegen.NewPresentationGenerator(*outputDir),
 		codegen.NewMainGenerator(*outputDir),
 		codegen.NewSDKGenerator(*outputDir),
+		codegen.NewTopologyGenerator(*outputDir),
+	}
+	if *graphql {
+		generators = append(generators, codegen.NewGraphQLGenerator(*outputDir))
 	}
 
 		for _, gen := range generators {
@@ -212,6 +220,9 @@ func runGenerate() {
 		fmt.Println("Code generation completed (with warnings - see above)")
 	}
 	fmt.Printf("Generated files in: %s\n", *outputDir)
+	if *graphql {
+		fmt.Println("GraphQL: cd api/graphql && go run github.com/99designs/gqlgen generate")
+	}
 }
 
 func runUpdate() {
@@ -351,6 +362,7 @@ func runSDK() {
 	protoPath := fs.String("proto", "", "Path to proto file")
 	outputDir := fs.String("output", ".", "Output directory")
 	modulePath := fs.String("module", "", "Go module path")
+	lang := fs.String("lang", "go", "SDK language: go, ts (OpenAPI spec + TypeScript REST client) or all")
 
 	fs.Parse(os.Args[2:])
 
@@ -359,6 +371,13 @@ func runSDK() {
 		os.Exit(1)
 	}
 
+	generateGo := *lang == "go" || *lang == "all"
+	generateTS := *lang == "ts" || *lang == "all"
+	if !generateGo && !generateTS {
+		fmt.Fprintf(os.Stderr, "Error: unknown --lang %q (expected go, ts or all)\n", *lang)
+		os.Exit(1)
+	}
+
 	// Парсинг proto файла
 	spec, err := parseProtoFile(*protoPath)
 	if err != nil {
@@ -382,17 +401,34 @@ func runSDK() {
 		PotterImportPath: defaultPotterImportPath,
 	}
 
-	sdkGen := codegen.NewSDKGenerator(*outputDir)
-	if err := sdkGen.Generate(spec, config); err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating SDK: %v\n", err)
-		os.Exit(1)
+	var generators []codegen.Generator
+	if generateGo {
+		generators = append(generators, codegen.NewSDKGenerator(*outputDir))
+	}
+	if generateTS {
+		generators = append(generators,
+			codegen.NewOpenAPIGenerator(*outputDir),
+			codegen.NewTypeScriptSDKGenerator(*outputDir),
+		)
+	}
+	for _, gen := range generators {
+		if err := gen.Generate(spec, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating SDK (%s): %v\n", gen.Name(), err)
+			os.Exit(1)
+		}
 	}
 
 	fmt.Printf("SDK generated successfully in %s\n", *outputDir)
 	fmt.Println("Next steps:")
 	fmt.Println("  1. cd", *outputDir)
-	fmt.Println("  2. go mod tidy")
-	fmt.Println("  3. Use SDK in your application")
+	if generateGo {
+		fmt.Println("  2. go mod tidy")
+		fmt.Println("  3. Use SDK in your application")
+	}
+	if generateTS {
+		fmt.Println("  - OpenAPI spec: api/openapi/openapi.yaml")
+		fmt.Println("  - TypeScript client: cd pkg/sdk-ts && npm install && npm run build")
+	}
 }
 
 func runVersion() {
@@ -568,72 +604,7 @@ func parseProtoFile(protoPath string) (*codegen.ParsedSpec, error) {
 	}
 
 	// Находим путь к api/proto (где находятся Potter options)
-	// Поднимаемся вверх от protoDir, пока не найдем api/proto/potter/options.proto
-	potterOptionsPath := ""
-	currentDir := protoDir
-	
-	// Проверяем переменную окружения POTTER_PROTO_PATH
-	if envPath := os.Getenv("POTTER_PROTO_PATH"); envPath != "" {
-		testPath := filepath.Join(envPath, "potter", "options.proto")
-		if _, err := os.Stat(testPath); err == nil {
-			potterOptionsPath = envPath
-			if os.Getenv("POTTER_DEBUG") == "1" {
-				fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options via POTTER_PROTO_PATH: %s\n", potterOptionsPath)
-			}
-		}
-	}
-	
-	// Если не нашли через переменную окружения, ищем вверх по директориям
-	if potterOptionsPath == "" {
-		for {
-			testPath := filepath.Join(currentDir, "api", "proto", "potter", "options.proto")
-			if _, err := os.Stat(testPath); err == nil {
-				potterOptionsPath = filepath.Join(currentDir, "api", "proto")
-				if os.Getenv("POTTER_DEBUG") == "1" {
-					fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options by walking up directories: %s\n", potterOptionsPath)
-				}
-				break
-			}
-			parentDir := filepath.Dir(currentDir)
-			if parentDir == currentDir {
-				// Достигли корня файловой системы
-				break
-			}
-			currentDir = parentDir
-		}
-	}
-	
-	// Fallback: пытаемся найти через go list (если Potter установлен как зависимость)
-	if potterOptionsPath == "" {
-		cmd := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/akriventsev/potter")
-		output, err := cmd.Output()
-		if err == nil {
-			potterDir := strings.TrimSpace(string(output))
-			if potterDir != "" {
-				testPath := filepath.Join(potterDir, "api", "proto", "potter", "options.proto")
-				if _, err := os.Stat(testPath); err == nil {
-					potterOptionsPath = filepath.Join(potterDir, "api", "proto")
-					if os.Getenv("POTTER_DEBUG") == "1" {
-						fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options via go list: %s\n", potterOptionsPath)
-					}
-				}
-			}
-		}
-	}
-	
-	// Fallback: проверяем стандартные пути Go modules cache
-	if potterOptionsPath == "" {
-		if gopath := os.Getenv("GOPATH"); gopath != "" {
-			testPath := filepath.Join(gopath, "pkg", "mod", "github.com", "akriventsev", "potter@*", "api", "proto", "potter", "options.proto")
-			matches, _ := filepath.Glob(testPath)
-			if len(matches) > 0 {
-				potterOptionsPath = filepath.Dir(filepath.Dir(matches[0]))
-				if os.Getenv("POTTER_DEBUG") == "1" {
-					fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options in GOPATH: %s\n", potterOptionsPath)
-				}
-			}
-		}
-	}
+	potterOptionsPath := findPotterOptionsPath(protoDir)
 
 	// Создаем временный файл для descriptor set
 	tmpFile, err := os.CreateTemp("", "potter-desc-*.pb")
@@ -731,6 +702,80 @@ func parseProtoFile(protoPath string) (*codegen.ParsedSpec, error) {
 	return spec, nil
 }
 
+// findPotterOptionsPath находит путь к api/proto (где находятся Potter options):
+// POTTER_PROTO_PATH, директории выше protoDir, модуль Potter в go list или GOPATH.
+// Возвращает пустую строку, если options.proto не найден.
+func findPotterOptionsPath(protoDir string) string {
+	// Поднимаемся вверх от protoDir, пока не найдем api/proto/potter/options.proto
+	potterOptionsPath := ""
+	currentDir := protoDir
+	
+	// Проверяем переменную окружения POTTER_PROTO_PATH
+	if envPath := os.Getenv("POTTER_PROTO_PATH"); envPath != "" {
+		testPath := filepath.Join(envPath, "potter", "options.proto")
+		if _, err := os.Stat(testPath); err == nil {
+			potterOptionsPath = envPath
+			if os.Getenv("POTTER_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options via POTTER_PROTO_PATH: %s\n", potterOptionsPath)
+			}
+		}
+	}
+	
+	// Если не нашли через переменную окружения, ищем вверх по директориям
+	if potterOptionsPath == "" {
+		for {
+			testPath := filepath.Join(currentDir, "api", "proto", "potter", "options.proto")
+			if _, err := os.Stat(testPath); err == nil {
+				potterOptionsPath = filepath.Join(currentDir, "api", "proto")
+				if os.Getenv("POTTER_DEBUG") == "1" {
+					fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options by walking up directories: %s\n", potterOptionsPath)
+				}
+				break
+			}
+			parentDir := filepath.Dir(currentDir)
+			if parentDir == currentDir {
+				// Достигли корня файловой системы
+				break
+			}
+			currentDir = parentDir
+		}
+	}
+	
+	// Fallback: пытаемся найти через go list (если Potter установлен как зависимость)
+	if potterOptionsPath == "" {
+		cmd := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/akriventsev/potter")
+		output, err := cmd.Output()
+		if err == nil {
+			potterDir := strings.TrimSpace(string(output))
+			if potterDir != "" {
+				testPath := filepath.Join(potterDir, "api", "proto", "potter", "options.proto")
+				if _, err := os.Stat(testPath); err == nil {
+					potterOptionsPath = filepath.Join(potterDir, "api", "proto")
+					if os.Getenv("POTTER_DEBUG") == "1" {
+						fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options via go list: %s\n", potterOptionsPath)
+					}
+				}
+			}
+		}
+	}
+	
+	// Fallback: проверяем стандартные пути Go modules cache
+	if potterOptionsPath == "" {
+		if gopath := os.Getenv("GOPATH"); gopath != "" {
+			testPath := filepath.Join(gopath, "pkg", "mod", "github.com", "akriventsev", "potter@*", "api", "proto", "potter", "options.proto")
+			matches, _ := filepath.Glob(testPath)
+			if len(matches) > 0 {
+				potterOptionsPath = filepath.Dir(filepath.Dir(matches[0]))
+				if os.Getenv("POTTER_DEBUG") == "1" {
+					fmt.Fprintf(os.Stderr, "DEBUG: Found Potter options in GOPATH: %s\n", potterOptionsPath)
+				}
+			}
+		}
+	}
+
+	return potterOptionsPath
+}
+
 // validateProtoFile валидирует proto файл
 func validateProtoFile(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
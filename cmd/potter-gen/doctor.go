@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nats-io/nats.go"
+	"github.com/pressly/goose/v3"
+)
+
+// doctorTimeout таймаут сетевых проверок doctor
+const doctorTimeout = 10 * time.Second
+
+// doctorStatus результат проверки doctor
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "✓"
+	doctorWarn doctorStatus = "!"
+	doctorFail doctorStatus = "✗"
+	doctorSkip doctorStatus = "-"
+)
+
+// doctorResult результат одной проверки окружения
+type doctorResult struct {
+	name   string
+	status doctorStatus
+	detail string
+	fix    string
+}
+
+func runDoctor() {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	protoPath := fs.String("proto", "", "Path to proto file (used to locate Potter options)")
+	outputDir := fs.String("output", ".", "Project directory")
+	migrationsDir := fs.String("migrations", "", "Path to migrations directory (default: <output>/migrations)")
+	potterImportPath := fs.String("potter-import-path", defaultPotterImportPath, "Potter framework import path")
+
+	fs.Parse(os.Args[2:])
+
+	if *migrationsDir == "" {
+		*migrationsDir = filepath.Join(*outputDir, "migrations")
+	}
+
+	results := []doctorResult{
+		checkProtoc(),
+		checkPotterOptions(*protoPath, *outputDir),
+		checkGoModule(*outputDir, *potterImportPath),
+		checkMigrationsDir(*migrationsDir),
+		checkDatabase(),
+		checkNATS(),
+	}
+
+	failed := 0
+	for _, result := range results {
+		fmt.Printf("%s %s: %s\n", result.status, result.name, result.detail)
+		if result.fix != "" && (result.status == doctorFail || result.status == doctorWarn) {
+			for _, line := range strings.Split(result.fix, "\n") {
+				fmt.Printf("    fix: %s\n", line)
+			}
+		}
+		if result.status == doctorFail {
+			failed++
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "✗ %d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Environment is ready")
+}
+
+// checkProtoc проверяет наличие protoc в PATH
+func checkProtoc() doctorResult {
+	result := doctorResult{name: "protoc"}
+
+	path, err := exec.LookPath("protoc")
+	if err != nil {
+		result.status = doctorFail
+		result.detail = "not found in PATH"
+		result.fix = "install protoc: https://grpc.io/docs/protoc-installation/\n" +
+			"macOS: brew install protobuf; Debian/Ubuntu: apt install protobuf-compiler"
+		return result
+	}
+
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		result.status = doctorFail
+		result.detail = fmt.Sprintf("%s is not executable: %v", path, err)
+		result.fix = "reinstall protoc and make sure the binary matches your platform"
+		return result
+	}
+
+	result.status = doctorOK
+	result.detail = fmt.Sprintf("%s (%s)", strings.TrimSpace(string(output)), path)
+	return result
+}
+
+// checkPotterOptions проверяет, что potter/options.proto находится так же, как при генерации
+func checkPotterOptions(protoPath, outputDir string) doctorResult {
+	result := doctorResult{name: "potter options"}
+
+	startDir := outputDir
+	if protoPath != "" {
+		startDir = filepath.Dir(protoPath)
+		if err := validateProtoFile(protoPath); err != nil {
+			result.status = doctorFail
+			result.detail = err.Error()
+			result.fix = "pass the path to your service proto with --proto"
+			return result
+		}
+	}
+	absDir, err := filepath.Abs(startDir)
+	if err != nil {
+		result.status = doctorFail
+		result.detail = fmt.Sprintf("failed to get absolute path: %v", err)
+		return result
+	}
+
+	optionsPath := findPotterOptionsPath(absDir)
+	if optionsPath == "" {
+		result.status = doctorFail
+		result.detail = "potter/options.proto not found"
+		result.fix = "set POTTER_PROTO_PATH to the directory containing potter/options.proto (<potter>/api/proto)\n" +
+			"or add Potter as a dependency: go get " + defaultPotterImportPath + "@main"
+		return result
+	}
+
+	result.status = doctorOK
+	result.detail = filepath.Join(optionsPath, "potter", "options.proto")
+	return result
+}
+
+// checkGoModule проверяет наличие go и доступность модуля Potter
+func checkGoModule(outputDir, potterImportPath string) doctorResult {
+	result := doctorResult{name: "go module"}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		result.status = doctorFail
+		result.detail = "go not found in PATH"
+		result.fix = "install Go: https://go.dev/doc/install"
+		return result
+	}
+
+	modulePath := potterImportPath
+	if !strings.Contains(modulePath, "@") {
+		modulePath += "@main"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout*3)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-f", "{{.Version}}", modulePath)
+	if _, err := os.Stat(outputDir); err == nil {
+		cmd.Dir = outputDir
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		result.status = doctorFail
+		result.detail = fmt.Sprintf("%s is not reachable: %s", modulePath, strings.TrimSpace(string(output)))
+		result.fix = "check network access to the Go module proxy (go env GOPROXY)\n" +
+			"for private forks set GOPRIVATE and configure git credentials"
+		return result
+	}
+
+	result.status = doctorOK
+	result.detail = fmt.Sprintf("%s resolved to %s", strings.Split(modulePath, "@")[0], strings.TrimSpace(string(output)))
+	return result
+}
+
+// checkMigrationsDir проверяет директорию миграций goose
+func checkMigrationsDir(migrationsDir string) doctorResult {
+	result := doctorResult{name: "migrations"}
+
+	info, err := os.Stat(migrationsDir)
+	if err != nil || !info.IsDir() {
+		result.status = doctorWarn
+		result.detail = fmt.Sprintf("directory %s not found", migrationsDir)
+		result.fix = "pass --migrations or create the first migration: potter-migrate create --migrations-dir " + migrationsDir + " init"
+		return result
+	}
+
+	migrations, err := goose.CollectMigrations(migrationsDir, 0, math.MaxInt64)
+	if err != nil {
+		result.status = doctorFail
+		result.detail = err.Error()
+		result.fix = "migration files must be named <version>_<name>.sql with unique versions"
+		return result
+	}
+
+	// SQL миграции должны содержать аннотации goose
+	var invalid []string
+	for _, migration := range migrations {
+		if filepath.Ext(migration.Source) != ".sql" {
+			continue
+		}
+		content, err := os.ReadFile(migration.Source)
+		if err != nil || !strings.Contains(string(content), "+goose Up") {
+			invalid = append(invalid, filepath.Base(migration.Source))
+		}
+	}
+	if len(invalid) > 0 {
+		result.status = doctorFail
+		result.detail = fmt.Sprintf("missing '-- +goose Up' annotation in %s", strings.Join(invalid, ", "))
+		result.fix = "start each SQL migration with '-- +goose Up' (and '-- +goose Down' for rollback)"
+		return result
+	}
+
+	result.status = doctorOK
+	result.detail = fmt.Sprintf("%d migration(s) in %s", len(migrations), migrationsDir)
+	return result
+}
+
+// checkDatabase проверяет подключение к PostgreSQL по DATABASE_DSN (или DATABASE_URL)
+func checkDatabase() doctorResult {
+	result := doctorResult{name: "database"}
+
+	dsn := os.Getenv("DATABASE_DSN")
+	if dsn == "" {
+		dsn = os.Getenv("DATABASE_URL")
+	}
+	if dsn == "" {
+		result.status = doctorSkip
+		result.detail = "DATABASE_DSN is not set, skipped"
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err == nil {
+		err = conn.Ping(ctx)
+		_ = conn.Close(ctx)
+	}
+	if err != nil {
+		result.status = doctorFail
+		result.detail = fmt.Sprintf("cannot connect: %v", err)
+		result.fix = "start the database (make docker-up) and check host, port and credentials in DATABASE_DSN"
+		return result
+	}
+
+	result.status = doctorOK
+	result.detail = "connected"
+	return result
+}
+
+// checkNATS проверяет подключение к NATS по NATS_URL
+func checkNATS() doctorResult {
+	result := doctorResult{name: "nats"}
+
+	url := os.Getenv("NATS_URL")
+	if url == "" {
+		result.status = doctorSkip
+		result.detail = "NATS_URL is not set, skipped"
+		return result
+	}
+
+	conn, err := nats.Connect(url, nats.Timeout(doctorTimeout), nats.NoReconnect())
+	if err != nil {
+		result.status = doctorFail
+		result.detail = fmt.Sprintf("cannot connect: %v", err)
+		result.fix = "start NATS (make docker-up) and check NATS_URL (e.g. nats://localhost:4222)"
+		return result
+	}
+	connectedURL := conn.ConnectedUrlRedacted()
+	conn.Close()
+
+	result.status = doctorOK
+	result.detail = fmt.Sprintf("connected to %s", connectedURL)
+	return result
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/akriventsev/potter/framework/codegen"
+)
+
+func runLint() {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	protoPath := fs.String("proto", "", "Path to proto file")
+	strict := fs.Bool("strict", false, "Treat warnings as errors")
+
+	fs.Parse(os.Args[2:])
+
+	if *protoPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --proto is required\n")
+		os.Exit(1)
+	}
+
+	spec, err := parseProtoFile(*protoPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing proto file: %v\n", err)
+		os.Exit(1)
+	}
+
+	issues := codegen.NewLinter().Lint(spec)
+	if len(issues) == 0 {
+		fmt.Println("✓ No naming convention violations found.")
+		return
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		fmt.Println(issue.String())
+		if issue.Suggestion != "" {
+			fmt.Printf("    fix: rename to %s\n", issue.Suggestion)
+		}
+		if issue.Severity == codegen.LintError || *strict {
+			errorCount++
+		}
+	}
+
+	fmt.Println()
+	if errorCount > 0 {
+		fmt.Fprintf(os.Stderr, "✗ Found %d naming convention violation(s) in %s\n", errorCount, *protoPath)
+		os.Exit(1)
+	}
+	fmt.Printf("! Found %d warning(s) in %s\n", len(issues), *protoPath)
+}
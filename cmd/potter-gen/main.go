@@ -24,6 +24,12 @@ func main() {
 		runCheck()
 	case "sdk":
 		runSDK()
+	case "lint":
+		runLint()
+	case "doctor":
+		runDoctor()
+	case "saga-diagram":
+		runSagaDiagram()
 	case "version":
 		runVersion()
 	default:
@@ -43,7 +49,10 @@ func printUsage() {
 	fmt.Println("  generate   - Generate code from proto")
 	fmt.Println("  update     - Update existing code")
 	fmt.Println("  check      - Compare generated code against proto spec, exit with non-zero status on discrepancies (for CI)")
-	fmt.Println("  sdk        - Generate SDK")
+	fmt.Println("  sdk        - Generate SDK (--lang go|ts|all; ts emits OpenAPI spec and TypeScript REST client)")
+	fmt.Println("  lint       - Check naming conventions for events, commands, aggregates and subjects (--strict fails on warnings)")
+	fmt.Println("  doctor     - Verify toolchain and environment (protoc, Potter options, Go module, migrations, database, NATS)")
+	fmt.Println("  saga-diagram - Export saga step graph as Mermaid or Graphviz DOT (--pkg, --def, --format, --output)")
 	fmt.Println("  version    - Show version")
 	fmt.Println()
 	fmt.Println("Flags:")
@@ -51,6 +60,7 @@ func printUsage() {
 	fmt.Println("  --output   - Output directory (default: current dir)")
 	fmt.Println("  --module   - Go module path (required for init)")
 	fmt.Println("  --overwrite - Overwrite existing files")
+	fmt.Println("  --graphql  - Generate gqlgen schema and CQRS resolvers (generate)")
 	fmt.Println("  --interactive - Interactive mode for update")
 	fmt.Println("  --sdk-only - Generate only SDK")
 	fmt.Println("  --no-backup - Don't create backup on update")
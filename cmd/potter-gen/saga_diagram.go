@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+)
+
+// sagaDiagramProgram программа, экспортирующая диаграмму определения саги из пакета пользователя
+var sagaDiagramProgram = template.Must(template.New("saga-diagram").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/akriventsev/potter/framework/saga"
+
+	sagadef "{{.Package}}"
+)
+
+func main() {
+	if err := saga.WriteDiagram(os.Stdout, sagadef.{{.Definition}}(), saga.DiagramFormat("{{.Format}}")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+func runSagaDiagram() {
+	fs := flag.NewFlagSet("saga-diagram", flag.ExitOnError)
+	pkg := fs.String("pkg", "", "Import path of the package with the saga definition")
+	definition := fs.String("def", "", "Exported function of the package returning the saga definition")
+	format := fs.String("format", "mermaid", "Diagram format: mermaid or dot")
+	output := fs.String("output", "", "Output file (default: stdout)")
+
+	fs.Parse(os.Args[2:])
+
+	if *pkg == "" || *definition == "" {
+		fmt.Fprintf(os.Stderr, "Error: --pkg and --def are required\n")
+		os.Exit(1)
+	}
+	if *format != "mermaid" && *format != "dot" {
+		fmt.Fprintf(os.Stderr, "Error: unsupported format %s (expected mermaid or dot)\n", *format)
+		os.Exit(1)
+	}
+
+	diagram, err := exportSagaDiagram(*pkg, *definition, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting saga diagram: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		fmt.Print(string(diagram))
+		return
+	}
+	if err := os.WriteFile(*output, diagram, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Saga diagram written to %s\n", *output)
+}
+
+// exportSagaDiagram собирает и запускает временную программу внутри текущего Go модуля,
+// чтобы получить определение саги, описанное в коде пользователя
+func exportSagaDiagram(pkg, definition, format string) ([]byte, error) {
+	tmpDir, err := os.MkdirTemp(".", ".potter-saga-diagram-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var program bytes.Buffer
+	if err := sagaDiagramProgram.Execute(&program, map[string]string{
+		"Package":    pkg,
+		"Definition": definition,
+		"Format":     format,
+	}); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), program.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write program: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.Base(tmpDir))
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go run failed (run potter-gen inside the module that contains %s): %w", pkg, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/akriventsev/potter/framework/admin"
+	"github.com/akriventsev/potter/framework/eventsourcing"
+)
+
+func main() {
+	fs := flag.NewFlagSet("potter-replay", flag.ExitOnError)
+	fs.Usage = printUsage
+	databaseURL := fs.String("database-url", "", "PostgreSQL connection string for event and checkpoint stores")
+	mongoURI := fs.String("mongo-uri", "", "MongoDB URI for event and checkpoint stores")
+	mongoDatabase := fs.String("mongo-database", "potter", "MongoDB database")
+	fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	eventStore, checkpointStore, err := openStores(*databaseURL, *mongoURI, *mongoDatabase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	service := eventsourcing.NewReplayService(eventStore).WithCheckpointStore(checkpointStore)
+
+	// SIGINT/SIGTERM прерывают replay с сохранением checkpoint, SIGUSR1/SIGUSR2 - пауза и продолжение
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go handlePauseSignals(ctx, service)
+
+	replayAdmin := admin.NewReplayAdmin(service, os.Stdout).WithProgressOutput(os.Stderr)
+	if err := replayAdmin.Run(ctx, fs.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Potter Event Replay Tool")
+	fmt.Println()
+	fmt.Println("Usage: potter-replay [connection flags] <mode> [args] [flags]")
+	fmt.Println()
+	fmt.Println("Modes:")
+	fmt.Println("  all                         - Replay all events")
+	fmt.Println("  aggregate <id>              - Replay events of one aggregate")
+	fmt.Println("  type <event_type>...        - Replay events of the given types")
+	fmt.Println("  since <RFC3339>             - Replay events that occurred since the timestamp")
+	fmt.Println()
+	fmt.Println("Connection flags:")
+	fmt.Println("  --database-url    - PostgreSQL connection string")
+	fmt.Println("  --mongo-uri       - MongoDB URI (alternative to --database-url)")
+	fmt.Println("  --mongo-database  - MongoDB database (default: potter)")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --rate N              - Maximum events per second (default: unlimited)")
+	fmt.Println("  --progress N          - Report progress every N events (default: 1000)")
+	fmt.Println("  --checkpoint NAME     - Resume an interrupted replay from checkpoint NAME")
+	fmt.Println("  --continue-on-error   - Count failed events instead of stopping")
+	fmt.Println()
+	fmt.Println("Events are written to stdout as NDJSON, progress to stderr.")
+	fmt.Println("Send SIGUSR1 to pause and SIGUSR2 to resume; Ctrl+C stops and saves the checkpoint.")
+	fmt.Println()
+	fmt.Println("Note: projections are implemented in service code. Embed admin.ReplayAdmin into")
+	fmt.Println("the service binary to replay events into projections (--projection NAME).")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter-replay --database-url postgres://... aggregate order-42")
+	fmt.Println("  potter-replay --database-url postgres://... type order.created --rate 500 > orders.ndjson")
+	fmt.Println("  potter-replay --database-url postgres://... all --checkpoint export-2024 > events.ndjson")
+}
+
+func handlePauseSignals(ctx context.Context, service *eventsourcing.ReplayService) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			if sig == syscall.SIGUSR1 {
+				service.Pause()
+				fmt.Fprintln(os.Stderr, "Replay paused (send SIGUSR2 to resume)")
+			} else {
+				service.Resume()
+				fmt.Fprintln(os.Stderr, "Replay resumed")
+			}
+		}
+	}
+}
+
+func openStores(databaseURL, mongoURI, mongoDatabase string) (eventsourcing.EventStore, eventsourcing.CheckpointStore, error) {
+	if mongoURI != "" {
+		config := eventsourcing.DefaultMongoDBEventStoreConfig()
+		config.URI = mongoURI
+		config.Database = mongoDatabase
+		eventStore, err := eventsourcing.NewMongoDBEventStore(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create event store: %w", err)
+		}
+		checkpointStore, err := eventsourcing.NewMongoCheckpointStore(mongoURI, mongoDatabase)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create checkpoint store: %w", err)
+		}
+		return eventStore, checkpointStore, nil
+	}
+
+	if databaseURL != "" {
+		config := eventsourcing.DefaultPostgresEventStoreConfig()
+		config.DSN = databaseURL
+		eventStore, err := eventsourcing.NewPostgresEventStore(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create event store: %w", err)
+		}
+		checkpointStore, err := eventsourcing.NewPostgresCheckpointStore(databaseURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create checkpoint store: %w", err)
+		}
+		return eventStore, checkpointStore, nil
+	}
+
+	return nil, nil, fmt.Errorf("--database-url or --mongo-uri is required")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/akriventsev/potter/framework/admin"
+	"github.com/akriventsev/potter/framework/saga"
+)
+
+func main() {
+	fs := flag.NewFlagSet("potter-saga", flag.ExitOnError)
+	fs.Usage = printUsage
+	databaseURL := fs.String("database-url", "", "PostgreSQL connection string for saga persistence")
+	fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: --database-url is required")
+		os.Exit(1)
+	}
+
+	persistence, err := saga.NewPostgresPersistence(*databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := admin.NewSagaAdmin(persistence, os.Stdout).Run(ctx, fs.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Potter Saga Administration Tool")
+	fmt.Println()
+	fmt.Println("Usage: potter-saga --database-url DSN <command> [args] [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list [--status S[,S...]] [--definition D] [--limit N]  - List sagas")
+	fmt.Println("  history <id>                                           - Show saga step history")
+	fmt.Println("  force-fail <id> --reason R                             - Mark saga as failed without compensation")
+	fmt.Println("  retry-stuck --dry-run [--stuck-for 30m]                - List running/pending sagas not updated recently")
+	fmt.Println("  resume <id>                                            - Resume saga (embedded mode only)")
+	fmt.Println("  compensate <id>                                        - Compensate saga (embedded mode only)")
+	fmt.Println("  retry-stuck [--stuck-for 30m] [--definition D]         - Resume stuck sagas (embedded mode only)")
+	fmt.Println("  export [--id ID[,ID...]] [--status S] [--output FILE]  - Export sagas to a JSON bundle (secrets redacted)")
+	fmt.Println("  import <file> [--overwrite]                            - Import sagas from a JSON bundle")
+	fmt.Println()
+	fmt.Println("Flags:")
+	fmt.Println("  --database-url    - PostgreSQL connection string for saga persistence")
+	fmt.Println()
+	fmt.Println("Note: saga steps are implemented in service code, so potter-saga only inspects")
+	fmt.Println("and force-fails sagas. Embed admin.SagaAdmin with an orchestrator into the service")
+	fmt.Println("binary to resume, compensate and retry sagas.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  potter-saga --database-url postgres://... list --status running,compensation_stuck")
+	fmt.Println("  potter-saga --database-url postgres://... history order-saga-42")
+	fmt.Println("  potter-saga --database-url postgres://... force-fail order-saga-42 --reason \"payment provider removed\"")
+	fmt.Println("  potter-saga --database-url postgres://... retry-stuck --stuck-for 1h --dry-run")
+}
@@ -88,6 +88,7 @@ func generate(gen *protogen.Plugin, modulePath, outputDir string) error {
 			codegen.NewDomainGenerator(tempDir),
 			codegen.NewApplicationGenerator(tempDir),
 			codegen.NewInfrastructureGenerator(tempDir),
+			codegen.NewReadModelGenerator(tempDir),
 			codegen.NewPresentationGenerator(tempDir),
 			codegen.NewMainGenerator(tempDir),
 		}
@@ -20,6 +20,7 @@ func main() {
 		aggregateID = flag.String("aggregate-id", "", "Aggregate ID for replay-aggregate")
 		projection  = flag.String("projection", "", "Projection name for replay-projection")
 		fromTime    = flag.String("from-time", "", "Start time for replay-from (RFC3339 format)")
+		rate        = flag.Float64("rate", 0, "Maximum events per second (0 - unlimited)")
 	)
 	flag.Parse()
 
@@ -70,41 +71,46 @@ func main() {
 		log.Fatalf("Failed to register customer stats projection: %v", err)
 	}
 
+	// ReplayService воспроизводит события с ограничением скорости и отчетом о прогрессе
+	replayService := eventsourcing.NewReplayService(eventStore).WithCheckpointStore(checkpointStore)
+	replayOptions := eventsourcing.DefaultReplayServiceOptions()
+	replayOptions.EventsPerSecond = *rate
+	replayOptions.ProgressInterval = 100
+	replayOptions.OnProgress = func(progress eventsourcing.ReplayProgress) {
+		fmt.Printf("Progress: %d events | Position: %d | Elapsed: %s\n",
+			progress.ProcessedEvents, progress.CurrentPosition, progress.ElapsedTime.Round(time.Millisecond))
+	}
+
+	// Все проекции примера получают события одного replay
+	allProjections := eventsourcing.ReplayHandlerFunc(func(ctx context.Context, event eventsourcing.StoredEvent) error {
+		if err := orderSummaryProjection.HandleEvent(ctx, event); err != nil {
+			return fmt.Errorf("order summary: %w", err)
+		}
+		if err := customerStatsProjection.HandleEvent(ctx, event); err != nil {
+			return fmt.Errorf("customer stats: %w", err)
+		}
+		return nil
+	})
+
 	switch *command {
 	case "replay-all":
-		fmt.Println("Starting full replay of all events using ProjectionManager...")
-		// Запускаем ProjectionManager для обработки всех событий
-		if err := projectionManager.Start(ctx); err != nil {
-			log.Fatalf("Failed to start projection manager: %v", err)
-		}
-		// Ждем завершения обработки всех событий
-		// В реальном приложении это будет работать в фоне
-		fmt.Println("ProjectionManager started. Processing events...")
-		time.Sleep(5 * time.Second) // Даем время на обработку
-		if err := projectionManager.Stop(ctx); err != nil {
-			log.Printf("Warning: Failed to stop projection manager: %v", err)
+		fmt.Println("Starting full replay of all events...")
+		progress, err := replayService.ReplayAll(ctx, allProjections, replayOptions)
+		if err != nil {
+			log.Fatalf("Replay failed: %v", err)
 		}
-		fmt.Println("\n✓ Full replay completed successfully")
+		fmt.Printf("\n✓ Full replay completed successfully (processed %d events)\n", progress.ProcessedEvents)
 
 	case "replay-aggregate":
 		if *aggregateID == "" {
 			log.Fatal("aggregate-id is required for replay-aggregate")
 		}
 		fmt.Printf("Starting replay for aggregate: %s\n", *aggregateID)
-		// Получаем события агрегата и обрабатываем через проекции
-		events, err := eventStore.GetEvents(ctx, *aggregateID, 0)
+		progress, err := replayService.ReplayAggregate(ctx, *aggregateID, allProjections, replayOptions)
 		if err != nil {
-			log.Fatalf("Failed to get events: %v", err)
+			log.Fatalf("Replay failed: %v", err)
 		}
-		for _, event := range events {
-			if err := orderSummaryProjection.HandleEvent(ctx, event); err != nil {
-				log.Printf("Error processing event in order summary: %v", err)
-			}
-			if err := customerStatsProjection.HandleEvent(ctx, event); err != nil {
-				log.Printf("Error processing event in customer stats: %v", err)
-			}
-		}
-		fmt.Println("✓ Aggregate replay completed successfully")
+		fmt.Printf("✓ Aggregate replay completed successfully (processed %d events)\n", progress.ProcessedEvents)
 
 	case "replay-projection":
 		if *projection == "" {
@@ -135,24 +141,11 @@ func main() {
 			log.Fatalf("Invalid time format: %v (expected RFC3339)", err)
 		}
 		fmt.Printf("Starting replay from: %s\n", fromTimestamp.Format(time.RFC3339))
-		// Получаем все события и фильтруем по времени
-		eventChan, err := eventStore.GetAllEvents(ctx, 0)
+		progress, err := replayService.ReplaySince(ctx, fromTimestamp, allProjections, replayOptions)
 		if err != nil {
-			log.Fatalf("Failed to get events: %v", err)
-		}
-		count := 0
-		for event := range eventChan {
-			if event.OccurredAt.After(fromTimestamp) || event.OccurredAt.Equal(fromTimestamp) {
-				if err := orderSummaryProjection.HandleEvent(ctx, event); err != nil {
-					log.Printf("Error processing event in order summary: %v", err)
-				}
-				if err := customerStatsProjection.HandleEvent(ctx, event); err != nil {
-					log.Printf("Error processing event in customer stats: %v", err)
-				}
-				count++
-			}
+			log.Fatalf("Replay failed: %v", err)
 		}
-		fmt.Printf("✓ Replay from time completed successfully (processed %d events)\n", count)
+		fmt.Printf("✓ Replay from time completed successfully (processed %d events)\n", progress.ProcessedEvents)
 
 	default:
 		log.Fatalf("Unknown command: %s", *command)
@@ -49,8 +49,8 @@ func main() {
 	// TODO: Регистрация query handlers
 
 	// Создание базовой GraphQL схемы
-	// В реальной реализации схема должна быть сгенерирована из proto файлов
-	// с помощью potter-gen и gqlgen
+	// В реальной реализации схема генерируется из proto файлов (make generate:
+	// potter-gen generate --graphql и gqlgen) и создается через graphql.NewSchema(commandBus, queryBus)
 	baseSchema := createMinimalSchema()
 
 	// Создание GraphQL адаптера с интеграцией CQRS
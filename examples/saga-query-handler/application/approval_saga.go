@@ -0,0 +1,30 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/saga"
+)
+
+// ApprovalStepName имя шага ручного подтверждения в approval_saga
+const ApprovalStepName = "manager_approval"
+
+// NewApprovalSagaDefinition создает определение саги с шагом ручного подтверждения:
+// после step1 сага ожидает решения (POST /api/v1/sagas/:id/approvals/manager_approval)
+func NewApprovalSagaDefinition() saga.SagaDefinition {
+	builder := saga.NewSagaBuilder("approval_saga")
+
+	builder.AddStep(NewSimpleStep1())
+	builder.AddStep(saga.NewWaitForApprovalStep(ApprovalStepName))
+	builder.AddStep(NewSimpleStep3())
+
+	builder.WithTimeout(24 * time.Hour)
+
+	definition, err := builder.Build()
+	if err != nil {
+		panic(fmt.Sprintf("failed to build approval saga: %v", err))
+	}
+
+	return definition
+}
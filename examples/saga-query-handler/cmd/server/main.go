@@ -98,10 +98,14 @@ func main() {
 	}()
 
 	// Создаем SagaOrchestrator
-	sagaDefinition := application.NewSimpleSagaDefinition()
 	orchestrator := saga.NewDefaultOrchestrator(sagaPersistence, eventBus)
-	if err := orchestrator.RegisterSaga(sagaDefinition.Name(), sagaDefinition); err != nil {
-		log.Fatalf("Failed to register saga definition: %v", err)
+	for _, sagaDefinition := range []saga.SagaDefinition{
+		application.NewSimpleSagaDefinition(),
+		application.NewApprovalSagaDefinition(),
+	} {
+		if err := orchestrator.RegisterSaga(sagaDefinition.Name(), sagaDefinition); err != nil {
+			log.Fatalf("Failed to register saga definition: %v", err)
+		}
 	}
 
 	// Создаем QueryBus и QueryHandler
@@ -121,6 +125,9 @@ func main() {
 		// Создание саги
 		api.POST("/sagas", createSagaHandler(ctx, orchestrator))
 
+		// Решение по шагу ручного подтверждения
+		api.POST("/sagas/:id/approvals/:step", approveSagaStepHandler(ctx, orchestrator))
+
 		// Получение статуса саги
 		api.GET("/sagas/:id", getSagaStatusHandler(queryBus))
 
@@ -198,6 +205,46 @@ func createSagaHandler(appCtx context.Context, orchestrator *saga.DefaultOrchest
 	}
 }
 
+// approveSagaStepHandler принимает решение по шагу подтверждения и продолжает сагу
+func approveSagaStepHandler(appCtx context.Context, orchestrator *saga.DefaultOrchestrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Approved bool   `json:"approved"`
+			Approver string `json:"approver"`
+			Comment  string `json:"comment"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		sagaID := c.Param("id")
+		decision := saga.ApprovalDecision{
+			Approved: req.Approved,
+			Approver: req.Approver,
+			Comment:  req.Comment,
+		}
+
+		// Оставшиеся шаги выполняются в контексте приложения, а не запроса
+		if err := orchestrator.Approve(appCtx, sagaID, c.Param("step"), decision); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		status, err := orchestrator.GetStatus(c.Request.Context(), sagaID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"saga_id": sagaID,
+			"status":  status,
+		})
+	}
+}
+
 // getSagaStatusHandler получает статус саги
 func getSagaStatusHandler(queryBus transport.QueryBus) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -18,6 +18,7 @@ import (
 type KafkaEventConfig struct {
 	Brokers          []string
 	TopicPrefix      string
+	Naming           *events.SubjectNaming        // Стратегия именования topics; nil - {TopicPrefix}.{aggregate}.{event}
 	Partitioner      func(aggregateID string) int // Partitioning по aggregate ID
 	Compression      string                       // none, gzip, snappy, lz4, zstd
 	IdempotentWrites bool
@@ -25,6 +26,9 @@ type KafkaEventConfig struct {
 	EnableMetrics    bool
 	BatchSize        int
 	FlushInterval    time.Duration
+	// Codecs кодирует payload событий кодеком их типа (protobuf, Avro, MessagePack);
+	// content type передается в заголовке content_type. nil - JSON.
+	Codecs *events.SerializerRegistry
 }
 
 // DefaultKafkaEventConfig возвращает конфигурацию Kafka Event Publisher по умолчанию
@@ -70,6 +74,10 @@ func NewKafkaEventAdapter(config KafkaEventConfig) (*KafkaEventAdapter, error) {
 	// Transactional ID is set for future compatibility but not used in current implementation
 	_ = config.TransactionalID
 
+	if config.Naming == nil {
+		config.Naming = events.PrefixSubjectNaming(config.TopicPrefix)
+	}
+
 	adapter := &KafkaEventAdapter{
 		config:  config,
 		writer:  writer,
@@ -160,6 +168,13 @@ func (k *KafkaEventAdapter) Publish(ctx context.Context, event events.Event) err
 		},
 	}
 
+	if k.config.Codecs != nil {
+		msg.Headers = append(msg.Headers, kafka.Header{
+			Key:   events.ContentTypeMetadataKey,
+			Value: []byte(k.config.Codecs.ContentType(event.EventType())),
+		})
+	}
+
 	// Добавляем версию события в headers (для версионирования)
 	if metadata := event.Metadata(); metadata != nil {
 		if version, ok := metadata.Get("version"); ok {
@@ -208,24 +223,18 @@ func (k *KafkaEventAdapter) Publish(ctx context.Context, event events.Event) err
 	return nil
 }
 
-// getTopic формирует topic для события
+// getTopic формирует topic для события по стратегии именования
 func (k *KafkaEventAdapter) getTopic(event events.Event) string {
-	aggregateID := event.AggregateID()
-	aggregateType := "unknown"
-
-	if len(aggregateID) > 0 {
-		parts := splitAggregateID(aggregateID)
-		if len(parts) > 0 {
-			aggregateType = parts[0]
-		}
-	}
-
-	// Формат: events.{aggregate_type}.{event_type}
-	return fmt.Sprintf("%s.%s.%s", k.config.TopicPrefix, aggregateType, event.EventType())
+	return k.config.Naming.Subject(event)
 }
 
 // serializeEvent сериализует событие
 func (k *KafkaEventAdapter) serializeEvent(event events.Event) ([]byte, error) {
+	if k.config.Codecs != nil {
+		data, _, err := k.config.Codecs.Serialize(event)
+		return data, err
+	}
+
 	eventData := map[string]interface{}{
 		"event_id":     event.EventID(),
 		"event_type":   event.EventType(),
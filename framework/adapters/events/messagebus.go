@@ -4,6 +4,7 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -18,8 +19,12 @@ import (
 type MessageBusEventConfig struct {
 	Bus           transport.Publisher
 	SubjectPrefix string
-	HeaderMapping map[string]string // Маппинг полей события в headers
+	Naming        *events.SubjectNaming // Стратегия именования subjects; nil - {SubjectPrefix}.{aggregate}.{event}
+	HeaderMapping map[string]string     // Маппинг полей события в headers
 	Serializer    transport.MessageSerializer
+	// Codecs кодирует payload событий кодеком их типа (protobuf, Avro, MessagePack);
+	// content type передается в заголовке content_type. nil - Serializer или JSON.
+	Codecs        *events.SerializerRegistry
 	RetryPolicy   events.RetryConfig
 	EnableBatch   bool
 	BatchSize     int
@@ -50,9 +55,10 @@ type MessageBusEventAdapter struct {
 	config  MessageBusEventConfig
 	bus     transport.Publisher
 	metrics *metrics.Metrics
-	running bool
-	batch   []batchEvent
-	batchMu sync.Mutex
+	running  bool
+	batch    []batchEvent
+	batchMu  sync.Mutex
+	inflight core.InFlight
 }
 
 type batchEvent struct {
@@ -66,6 +72,10 @@ func NewMessageBusEventAdapter(config MessageBusEventConfig) (*MessageBusEventAd
 		return nil, fmt.Errorf("message bus is required")
 	}
 
+	if config.Naming == nil {
+		config.Naming = events.PrefixSubjectNaming(config.SubjectPrefix)
+	}
+
 	adapter := &MessageBusEventAdapter{
 		config: config,
 		bus:    config.Bus,
@@ -94,16 +104,36 @@ func (m *MessageBusEventAdapter) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop останавливает адаптер (реализация core.Lifecycle)
+// Stop останавливает адаптер (реализация core.Lifecycle).
+// Оставшиеся в batch события публикуются до возврата (см. Flush).
 func (m *MessageBusEventAdapter) Stop(ctx context.Context) error {
 	m.running = false
+	return m.Flush(ctx)
+}
 
-	// Flush оставшихся событий в batch
+// Flush дожидается выполняющихся Publish, публикует события из batch и дожидается
+// подтверждения шиной, если она буферизует сообщения (реализация core.Flushable).
+// Batch публикуется после ожидания, чтобы в него попали события Publish, выполнявшихся во время Flush.
+func (m *MessageBusEventAdapter) Flush(ctx context.Context) error {
+	if err := m.inflight.Wait(ctx); err != nil {
+		return fmt.Errorf("failed to wait for in-flight events: %w", err)
+	}
+	var batchErr error
 	if m.config.EnableBatch {
-		_ = m.flushBatch(ctx)
+		batchErr = m.flushBatch(ctx)
+	}
+	if flusher, ok := m.bus.(core.Flushable); ok {
+		if err := flusher.Flush(ctx); err != nil {
+			return errors.Join(batchErr, fmt.Errorf("failed to flush message bus: %w", err))
+		}
 	}
+	return batchErr
+}
 
-	return nil
+// Close прекращает прием событий и выполняет Stop
+func (m *MessageBusEventAdapter) Close(ctx context.Context) error {
+	m.inflight.Close()
+	return m.Stop(ctx)
 }
 
 // IsRunning проверяет, запущен ли адаптер (реализация core.Lifecycle)
@@ -121,8 +151,13 @@ func (m *MessageBusEventAdapter) Type() core.ComponentType {
 	return core.ComponentTypeAdapter
 }
 
-// Publish публикует событие
+// Publish публикует событие. После Close возвращает ошибку.
 func (m *MessageBusEventAdapter) Publish(ctx context.Context, event events.Event) error {
+	if !m.inflight.Begin() {
+		return fmt.Errorf("event adapter is closed")
+	}
+	defer m.inflight.End()
+
 	start := time.Now()
 
 	if m.config.EnableBatch {
@@ -165,22 +200,23 @@ func (m *MessageBusEventAdapter) publishSingle(ctx context.Context, event events
 	return nil
 }
 
-// publishBatch добавляет событие в batch
+// publishBatch добавляет событие в batch. Событие публикуется после возврата Publish,
+// поэтому отмена контекста запроса не отменяет его публикацию.
 func (m *MessageBusEventAdapter) publishBatch(ctx context.Context, event events.Event) error {
 	m.batchMu.Lock()
-	defer m.batchMu.Unlock()
-
-	m.batch = append(m.batch, batchEvent{ctx: ctx, event: event})
+	m.batch = append(m.batch, batchEvent{ctx: context.WithoutCancel(ctx), event: event})
+	full := len(m.batch) >= m.config.BatchSize
+	m.batchMu.Unlock()
 
 	// Если batch заполнен, публикуем
-	if len(m.batch) >= m.config.BatchSize {
+	if full {
 		return m.flushBatch(ctx)
 	}
 
 	return nil
 }
 
-// flushBatch публикует все события из batch
+// flushBatch публикует все события из batch и возвращает ошибки публикации
 func (m *MessageBusEventAdapter) flushBatch(ctx context.Context) error {
 	m.batchMu.Lock()
 	events := make([]batchEvent, len(m.batch))
@@ -188,11 +224,14 @@ func (m *MessageBusEventAdapter) flushBatch(ctx context.Context) error {
 	m.batch = m.batch[:0]
 	m.batchMu.Unlock()
 
+	var errs []error
 	for _, be := range events {
-		_ = m.publishSingle(be.ctx, be.event, time.Now())
+		if err := m.publishSingle(be.ctx, be.event, time.Now()); err != nil {
+			errs = append(errs, fmt.Errorf("event %s: %w", be.event.EventID(), err))
+		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // batchProcessor обрабатывает batch по таймауту
@@ -201,30 +240,26 @@ func (m *MessageBusEventAdapter) batchProcessor() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		if len(m.batch) > 0 {
+		m.batchMu.Lock()
+		pending := len(m.batch)
+		m.batchMu.Unlock()
+		if pending > 0 {
 			_ = m.flushBatch(context.Background())
 		}
 	}
 }
 
-// getSubject формирует subject для события
+// getSubject формирует subject для события по стратегии именования
 func (m *MessageBusEventAdapter) getSubject(event events.Event) string {
-	// Поддержка шаблонов: events.{aggregate}.{event_type}
-	aggregateID := event.AggregateID()
-	aggregateType := "unknown"
-
-	if len(aggregateID) > 0 {
-		parts := splitAggregateID(aggregateID)
-		if len(parts) > 0 {
-			aggregateType = parts[0]
-		}
-	}
-
-	return fmt.Sprintf("%s.%s.%s", m.config.SubjectPrefix, aggregateType, event.EventType())
+	return m.config.Naming.Subject(event)
 }
 
 // serializeEvent сериализует событие
 func (m *MessageBusEventAdapter) serializeEvent(event events.Event) ([]byte, error) {
+	if m.config.Codecs != nil {
+		data, _, err := m.config.Codecs.Serialize(event)
+		return data, err
+	}
 	if m.config.Serializer != nil {
 		return m.config.Serializer.Serialize(event)
 	}
@@ -277,6 +312,12 @@ func (m *MessageBusEventAdapter) buildHeaders(event events.Event) map[string]str
 		}
 	}
 
+	// Тип события и content type payload для выбора кодека подписчиком
+	if m.config.Codecs != nil {
+		headers["event_type"] = event.EventType()
+		headers[events.ContentTypeMetadataKey] = m.config.Codecs.ContentType(event.EventType())
+	}
+
 	// Добавляем метаданные события
 	if metadata := event.Metadata(); metadata != nil {
 		if correlationID := metadata.CorrelationID(); correlationID != "" {
@@ -293,6 +334,16 @@ func (m *MessageBusEventAdapter) buildHeaders(event events.Event) map[string]str
 	return headers
 }
 
+// DecodeEventMessage декодирует payload сообщения, опубликованного адаптером с Codecs,
+// по заголовкам event_type и content_type (без content_type - JSON)
+func DecodeEventMessage(codecs *events.SerializerRegistry, msg *transport.Message) (events.Event, error) {
+	eventType := msg.Headers["event_type"]
+	if eventType == "" {
+		return nil, fmt.Errorf("message %s has no event_type header", msg.Subject)
+	}
+	return codecs.Deserialize(eventType, msg.Headers[events.ContentTypeMetadataKey], msg.Data)
+}
+
 // publishWithRetry публикует событие с retry логикой
 func (m *MessageBusEventAdapter) publishWithRetry(ctx context.Context, subject string, data []byte, headers map[string]string) error {
 	retryConfig := m.config.RetryPolicy
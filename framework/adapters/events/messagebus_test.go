@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// countingBus шина, считающая опубликованные сообщения и запоминающая их число на момент Flush
+type countingBus struct {
+	mu        sync.Mutex
+	published int
+	flushedAt int
+}
+
+func (b *countingBus) Publish(ctx context.Context, subject string, data []byte, headers map[string]string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published++
+	return nil
+}
+
+func (b *countingBus) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushedAt = b.published
+	return nil
+}
+
+// publishUntilClosed публикует события из нескольких горутин, пока адаптер не закроется,
+// и возвращает функцию, дожидающуюся горутин и возвращающую число принятых событий
+func publishUntilClosed(t *testing.T, publisher events.EventPublisher) func() int64 {
+	t.Helper()
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := publisher.Publish(context.Background(), events.NewBaseEvent("OrderCreated", "order-1"))
+				if err != nil {
+					if err.Error() != "event adapter is closed" {
+						t.Errorf("Expected closed adapter error, got %v", err)
+					}
+					return
+				}
+				accepted.Add(1)
+			}
+		}()
+	}
+	return func() int64 {
+		wg.Wait()
+		return accepted.Load()
+	}
+}
+
+func TestMessageBusEventAdapter_PublishRacingClose(t *testing.T) {
+	bus := &countingBus{}
+	config := DefaultMessageBusEventConfig()
+	config.Bus = bus
+	config.EnableMetrics = false
+	config.EnableBatch = true
+	config.BatchSize = 1000
+	config.BatchTimeout = time.Hour
+	adapter, err := NewMessageBusEventAdapter(config)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+
+	wait := publishUntilClosed(t, adapter)
+	time.Sleep(10 * time.Millisecond)
+	if err := adapter.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	bus.mu.Lock()
+	published, flushedAt := bus.published, bus.flushedAt
+	bus.mu.Unlock()
+
+	// Принятые до Close события опубликованы и подтверждены шиной до его возврата
+	accepted := wait()
+	if accepted == 0 || int64(published) != accepted || int64(flushedAt) != accepted {
+		t.Errorf("Expected %d events published before bus flush, got %d published, %d flushed", accepted, published, flushedAt)
+	}
+}
+
+func TestMessageBusEventAdapter_CloseWaitsForInFlightPublishBeforeBatch(t *testing.T) {
+	bus := &countingBus{}
+	config := DefaultMessageBusEventConfig()
+	config.Bus = bus
+	config.EnableMetrics = false
+	config.EnableBatch = true
+	config.BatchTimeout = time.Hour
+	adapter, err := NewMessageBusEventAdapter(config)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	ctx := context.Background()
+	if err := adapter.Publish(ctx, events.NewBaseEvent("OrderCreated", "order-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Publish, начатый до Close, добавляет событие в batch уже во время Close
+	if !adapter.inflight.Begin() {
+		t.Fatal("Expected adapter to accept events")
+	}
+	closed := make(chan error, 1)
+	go func() {
+		closed <- adapter.Close(ctx)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if err := adapter.publishBatch(ctx, events.NewBaseEvent("OrderCreated", "order-2")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	adapter.inflight.End()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return")
+	}
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if bus.published != 2 || bus.flushedAt != 2 {
+		t.Errorf("Expected both events published before bus flush, got %d published, %d flushed", bus.published, bus.flushedAt)
+	}
+}
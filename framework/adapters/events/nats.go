@@ -19,9 +19,21 @@ import (
 type NATSEventConfig struct {
 	Conn          *nats.Conn
 	SubjectPrefix string
+	Naming        *events.SubjectNaming // Стратегия именования subjects; nil - {SubjectPrefix}.{aggregate}.{event}
 	Serializer    transport.MessageSerializer
+	// Codecs кодирует payload событий кодеком их типа (protobuf, Avro, MessagePack);
+	// content type передается в заголовке content_type. nil - Serializer или JSON.
+	Codecs        *events.SerializerRegistry
 	RetryPolicy   events.RetryConfig
 	EnableMetrics bool
+	// Metrics сборщик метрик адаптера (например, metrics.PrometheusExporter.Metrics());
+	// nil при EnableMetrics - OpenTelemetry
+	Metrics *metrics.Metrics
+	// BatchSize включает пакетную публикацию при значении больше 1: события накапливаются
+	// до BatchSize штук или FlushInterval и отправляются с одним Flush на пакет
+	BatchSize int
+	// FlushInterval окно накопления пакета (по умолчанию 5ms при включенной пакетной публикации)
+	FlushInterval time.Duration
 }
 
 // DefaultNATSEventConfig возвращает конфигурацию NATS Event Publisher по умолчанию
@@ -34,12 +46,16 @@ func DefaultNATSEventConfig() NATSEventConfig {
 	}
 }
 
-// NATSEventAdapter реализация Event Publisher через NATS
+// NATSEventAdapter реализация Event Publisher через NATS; подписка на события
+// в группах потребителей - SubscribeWithGroup
 type NATSEventAdapter struct {
-	config  NATSEventConfig
-	conn    *nats.Conn
-	metrics *metrics.Metrics
-	running bool
+	config   NATSEventConfig
+	conn     *nats.Conn
+	metrics  *metrics.Metrics
+	batcher  *natsPublishBatcher
+	groups   natsGroupSubscriptions
+	inflight core.InFlight
+	running  bool
 }
 
 // NewNATSEventAdapter создает новый NATS Event Publisher
@@ -48,13 +64,23 @@ func NewNATSEventAdapter(config NATSEventConfig) (*NATSEventAdapter, error) {
 		return nil, fmt.Errorf("NATS connection is required")
 	}
 
+	if config.Naming == nil {
+		config.Naming = events.PrefixSubjectNaming(config.SubjectPrefix)
+	}
+
 	adapter := &NATSEventAdapter{
 		config:  config,
 		conn:    config.Conn,
 		running: false,
 	}
 
-	if config.EnableMetrics {
+	if config.BatchSize > 1 {
+		adapter.batcher = newNATSPublishBatcher(adapter.publishBuffered, adapter.conn.Flush, config.BatchSize, config.FlushInterval)
+	}
+
+	if config.Metrics != nil {
+		adapter.metrics = config.Metrics
+	} else if config.EnableMetrics {
 		var err error
 		adapter.metrics, err = metrics.NewMetrics()
 		if err != nil {
@@ -71,12 +97,51 @@ func (n *NATSEventAdapter) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop останавливает адаптер (реализация core.Lifecycle)
+// Stop останавливает адаптер (реализация core.Lifecycle).
+// Накопленный пакет событий отправляется и подтверждается сервером до возврата
+// (см. Flush), подписки в группах завершаются.
 func (n *NATSEventAdapter) Stop(ctx context.Context) error {
 	n.running = false
+	err := n.Flush(ctx)
+	n.drainGroups()
+	return err
+}
+
+// Flush дожидается выполняющихся Publish, отправляет накопленный пакет и дожидается
+// подтверждения сервером всех опубликованных сообщений (реализация core.Flushable). Publish в NATS
+// буферизуется клиентом, поэтому без Flush последние события теряются при остановке.
+// Пакет отправляется после ожидания: иначе события Publish, выполнявшихся во время Flush,
+// остались бы в пакете без отправки.
+func (n *NATSEventAdapter) Flush(ctx context.Context) error {
+	if err := n.inflight.Wait(ctx); err != nil {
+		return fmt.Errorf("failed to wait for in-flight events: %w", err)
+	}
+	if n.batcher != nil {
+		n.batcher.flush()
+	}
+	if n.conn.IsClosed() {
+		return nil
+	}
+
+	// FlushWithContext требует дедлайн, без него используется таймаут клиента по умолчанию
+	var err error
+	if _, ok := ctx.Deadline(); ok {
+		err = n.conn.FlushWithContext(ctx)
+	} else {
+		err = n.conn.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to flush nats connection: %w", err)
+	}
 	return nil
 }
 
+// Close прекращает прием событий и выполняет Stop
+func (n *NATSEventAdapter) Close(ctx context.Context) error {
+	n.inflight.Close()
+	return n.Stop(ctx)
+}
+
 // IsRunning проверяет, запущен ли адаптер (реализация core.Lifecycle)
 func (n *NATSEventAdapter) IsRunning() bool {
 	return n.running
@@ -92,8 +157,12 @@ func (n *NATSEventAdapter) Type() core.ComponentType {
 	return core.ComponentTypeAdapter
 }
 
-// Publish публикует событие
+// Publish публикует событие. После Close возвращает ошибку.
 func (n *NATSEventAdapter) Publish(ctx context.Context, event events.Event) error {
+	if !n.inflight.Begin() {
+		return fmt.Errorf("event adapter is closed")
+	}
+	defer n.inflight.End()
 
 	// Формируем subject по шаблону: events.{aggregate}.{event_type}
 	subject := n.getSubject(event)
@@ -104,15 +173,21 @@ func (n *NATSEventAdapter) Publish(ctx context.Context, event events.Event) erro
 		if n.metrics != nil {
 			n.metrics.RecordEvent(ctx, event.EventType())
 		}
+		n.recordError(ctx, "serialize")
 		return fmt.Errorf("failed to serialize event: %w", err)
 	}
 
-	// Публикуем с retry
-	err = n.publishWithRetry(ctx, subject, data, event)
+	// Публикуем с retry (в составе пакета, если включена пакетная публикация)
+	if n.batcher != nil {
+		err = n.batcher.publish(ctx, subject, data, event)
+	} else {
+		err = n.publishWithRetry(ctx, subject, data, event)
+	}
 	if err != nil {
 		if n.metrics != nil {
 			n.metrics.RecordEvent(ctx, event.EventType())
 		}
+		n.recordError(ctx, "publish")
 		return err
 	}
 
@@ -123,26 +198,24 @@ func (n *NATSEventAdapter) Publish(ctx context.Context, event events.Event) erro
 	return nil
 }
 
-// getSubject формирует subject для события
-func (n *NATSEventAdapter) getSubject(event events.Event) string {
-	// Извлекаем aggregate type из aggregate ID (предполагаем формат: {aggregate_type}-{id})
-	aggregateID := event.AggregateID()
-	aggregateType := "unknown"
-
-	// Пытаемся извлечь тип агрегата
-	if len(aggregateID) > 0 {
-		parts := splitAggregateID(aggregateID)
-		if len(parts) > 0 {
-			aggregateType = parts[0]
-		}
+// recordError учитывает ошибку публикации в transport_errors_total{transport="nats"}
+func (n *NATSEventAdapter) recordError(ctx context.Context, operation string) {
+	if n.metrics != nil {
+		n.metrics.RecordTransportError(ctx, "nats", operation)
 	}
+}
 
-	// Формат: events.{aggregate_type}.{event_type}
-	return fmt.Sprintf("%s.%s.%s", n.config.SubjectPrefix, aggregateType, event.EventType())
+// getSubject формирует subject для события по стратегии именования
+func (n *NATSEventAdapter) getSubject(event events.Event) string {
+	return n.config.Naming.Subject(event)
 }
 
 // serializeEvent сериализует событие
 func (n *NATSEventAdapter) serializeEvent(event events.Event) ([]byte, error) {
+	if n.config.Codecs != nil {
+		data, _, err := n.config.Codecs.Serialize(event)
+		return data, err
+	}
 	if n.config.Serializer != nil {
 		return n.config.Serializer.Serialize(event)
 	}
@@ -181,6 +254,15 @@ func (n *NATSEventAdapter) serializeEvent(event events.Event) ([]byte, error) {
 	return json.Marshal(eventData)
 }
 
+// publishBuffered передает сообщение клиенту NATS без ожидания сервера (для пакетной публикации).
+// Сообщение, не принятое клиентом (переполнен буфер, переподключение), повторяется с backoff.
+func (n *NATSEventAdapter) publishBuffered(ctx context.Context, subject string, data []byte, event events.Event) error {
+	if err := n.publishMessage(subject, data, event); err != nil {
+		return n.publishWithRetry(ctx, subject, data, event)
+	}
+	return nil
+}
+
 // publishWithRetry публикует событие с retry логикой
 func (n *NATSEventAdapter) publishWithRetry(ctx context.Context, subject string, data []byte, event events.Event) error {
 	retryConfig := n.config.RetryPolicy
@@ -195,7 +277,7 @@ func (n *NATSEventAdapter) publishWithRetry(ctx context.Context, subject string,
 			}
 		}
 
-		err := n.conn.Publish(subject, data)
+		err := n.publishMessage(subject, data, event)
 		if err == nil {
 			return nil
 		}
@@ -210,6 +292,21 @@ func (n *NATSEventAdapter) publishWithRetry(ctx context.Context, subject string,
 	return fmt.Errorf("failed to publish event after %d attempts", retryConfig.MaxAttempts)
 }
 
+// publishMessage публикует сообщение; при заданных Codecs тип события и content type
+// передаются в заголовках, чтобы подписчики выбрали кодек
+func (n *NATSEventAdapter) publishMessage(subject string, data []byte, event events.Event) error {
+	if n.config.Codecs == nil {
+		return n.conn.Publish(subject, data)
+	}
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set("event_id", event.EventID())
+	msg.Header.Set("event_type", event.EventType())
+	msg.Header.Set("aggregate_id", event.AggregateID())
+	msg.Header.Set(events.ContentTypeMetadataKey, n.config.Codecs.ContentType(event.EventType()))
+	return n.conn.PublishMsg(msg)
+}
+
 // JSONSerializer реализация MessageSerializer для JSON
 type JSONSerializer struct{}
 
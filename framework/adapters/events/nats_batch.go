@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// defaultNATSFlushInterval окно накопления пакета, если задан только BatchSize
+const defaultNATSFlushInterval = 5 * time.Millisecond
+
+// natsPendingPublish событие, ожидающее отправки в составе пакета
+type natsPendingPublish struct {
+	ctx     context.Context
+	subject string
+	data    []byte
+	event   events.Event
+	done    chan error
+}
+
+// natsPublishBatcher накапливает публикации NATSEventAdapter и отправляет их пакетом:
+// асинхронные Publish и один Flush на пакет вместо round-trip на каждое событие.
+// Пакет отправляется при накоплении size событий или по истечении interval
+// с момента первого события пакета. Publish ожидает отправки своего пакета,
+// поэтому семантика ошибок для вызывающего кода не меняется.
+type natsPublishBatcher struct {
+	// publishMessage передает сообщение клиенту без ожидания подтверждения сервера
+	publishMessage func(ctx context.Context, subject string, data []byte, event events.Event) error
+	// flushConn дожидается подтверждения сервером всех переданных сообщений
+	flushConn func() error
+	size      int
+	interval  time.Duration
+
+	mu      sync.Mutex
+	pending []*natsPendingPublish
+	timer   *time.Timer
+}
+
+// newNATSPublishBatcher создает batcher, публикующий сообщения через publishMessage
+// и подтверждающий каждый пакет вызовом flushConn
+func newNATSPublishBatcher(
+	publishMessage func(ctx context.Context, subject string, data []byte, event events.Event) error,
+	flushConn func() error,
+	size int,
+	interval time.Duration,
+) *natsPublishBatcher {
+	if interval <= 0 {
+		interval = defaultNATSFlushInterval
+	}
+	return &natsPublishBatcher{
+		publishMessage: publishMessage,
+		flushConn:      flushConn,
+		size:           size,
+		interval:       interval,
+	}
+}
+
+// publish добавляет событие в текущий пакет и ожидает его отправки
+func (b *natsPublishBatcher) publish(ctx context.Context, subject string, data []byte, event events.Event) error {
+	pending := &natsPendingPublish{
+		ctx:     ctx,
+		subject: subject,
+		data:    data,
+		event:   event,
+		done:    make(chan error, 1),
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, pending)
+	var batch []*natsPendingPublish
+	if len(b.pending) >= b.size {
+		batch = b.takeLocked()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.interval, b.flush)
+	}
+	b.mu.Unlock()
+
+	// Заполненный пакет отправляет событие, которое его заполнило
+	if batch != nil {
+		b.send(batch)
+	}
+
+	select {
+	case err := <-pending.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush отправляет накопленный пакет (по таймеру и при остановке адаптера)
+func (b *natsPublishBatcher) flush() {
+	b.mu.Lock()
+	batch := b.takeLocked()
+	b.mu.Unlock()
+
+	b.send(batch)
+}
+
+// takeLocked забирает накопленный пакет. Вызывается под b.mu.
+func (b *natsPublishBatcher) takeLocked() []*natsPendingPublish {
+	batch := b.pending
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return batch
+}
+
+// send публикует пакет и сообщает результат каждому ожидающему Publish
+func (b *natsPublishBatcher) send(batch []*natsPendingPublish) {
+	if len(batch) == 0 {
+		return
+	}
+
+	errs := make([]error, len(batch))
+	for i, pending := range batch {
+		errs[i] = b.publishMessage(pending.ctx, pending.subject, pending.data, pending.event)
+	}
+
+	// Один Flush подтверждает, что сервер получил все сообщения пакета
+	if err := b.flushConn(); err != nil {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = fmt.Errorf("failed to flush event batch: %w", err)
+			}
+		}
+	}
+
+	for i, pending := range batch {
+		pending.done <- errs[i]
+	}
+}
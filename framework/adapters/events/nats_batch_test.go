@@ -0,0 +1,304 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+
+	"github.com/nats-io/nats.go"
+)
+
+// fakeNATSConn запоминает опубликованные subjects и число Flush
+type fakeNATSConn struct {
+	mu         sync.Mutex
+	published  []string
+	flushes    int
+	publishErr map[string]error
+	flushErr   error
+}
+
+func (c *fakeNATSConn) publish(ctx context.Context, subject string, data []byte, event events.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.publishErr[subject]; err != nil {
+		return err
+	}
+	c.published = append(c.published, subject)
+	return nil
+}
+
+func (c *fakeNATSConn) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushes++
+	return c.flushErr
+}
+
+func (c *fakeNATSConn) stats() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.published), c.flushes
+}
+
+func newTestBatcher(conn *fakeNATSConn, size int, interval time.Duration) *natsPublishBatcher {
+	return newNATSPublishBatcher(conn.publish, conn.flush, size, interval)
+}
+
+// publishAsync запускает publish и возвращает канал с его результатом
+func publishAsync(ctx context.Context, b *natsPublishBatcher, subject string) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- b.publish(ctx, subject, []byte(subject), events.NewBaseEvent("OrderCreated", subject))
+	}()
+	return result
+}
+
+// waitPending ожидает, пока в пакете накопится n событий
+func waitPending(t *testing.T, b *natsPublishBatcher, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		b.mu.Lock()
+		pending := len(b.pending)
+		b.mu.Unlock()
+		if pending == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d pending events", n)
+}
+
+func receiveResult(t *testing.T, result <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish did not return")
+		return nil
+	}
+}
+
+func TestNATSPublishBatcher_SizeTrigger(t *testing.T) {
+	conn := &fakeNATSConn{}
+	b := newTestBatcher(conn, 3, time.Hour)
+	ctx := context.Background()
+
+	first := publishAsync(ctx, b, "orders.1")
+	second := publishAsync(ctx, b, "orders.2")
+	waitPending(t, b, 2)
+	if published, flushes := conn.stats(); published != 0 || flushes != 0 {
+		t.Fatalf("Expected batch to be held until full, got %d published, %d flushes", published, flushes)
+	}
+
+	// Третье событие заполняет пакет и отправляет его
+	if err := b.publish(ctx, "orders.3", nil, events.NewBaseEvent("OrderCreated", "3")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, result := range []<-chan error{first, second} {
+		if err := receiveResult(t, result); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+	if published, flushes := conn.stats(); published != 3 || flushes != 1 {
+		t.Errorf("Expected 3 events in one flush, got %d published, %d flushes", published, flushes)
+	}
+}
+
+func TestNATSPublishBatcher_TimerFlush(t *testing.T) {
+	conn := &fakeNATSConn{}
+	b := newTestBatcher(conn, 100, 10*time.Millisecond)
+
+	start := time.Now()
+	if err := b.publish(context.Background(), "orders.1", nil, events.NewBaseEvent("OrderCreated", "1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("Expected publish to wait for flush interval, returned after %s", elapsed)
+	}
+	if published, flushes := conn.stats(); published != 1 || flushes != 1 {
+		t.Errorf("Expected timer flush, got %d published, %d flushes", published, flushes)
+	}
+
+	// После отправки таймер сбрасывается; следующий пакет запускает новый
+	if err := b.publish(context.Background(), "orders.2", nil, events.NewBaseEvent("OrderCreated", "2")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, flushes := conn.stats(); flushes != 2 {
+		t.Errorf("Expected second timer flush, got %d flushes", flushes)
+	}
+}
+
+func TestNATSPublishBatcher_ExplicitFlush(t *testing.T) {
+	conn := &fakeNATSConn{}
+	b := newTestBatcher(conn, 100, time.Hour)
+
+	// Пустой пакет не вызывает Flush соединения
+	b.flush()
+	if _, flushes := conn.stats(); flushes != 0 {
+		t.Fatalf("Expected no flush for empty batch, got %d", flushes)
+	}
+
+	first := publishAsync(context.Background(), b, "orders.1")
+	second := publishAsync(context.Background(), b, "orders.2")
+	waitPending(t, b, 2)
+
+	b.flush()
+	for _, result := range []<-chan error{first, second} {
+		if err := receiveResult(t, result); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+	if published, flushes := conn.stats(); published != 2 || flushes != 1 {
+		t.Errorf("Expected explicit flush of 2 events, got %d published, %d flushes", published, flushes)
+	}
+	b.mu.Lock()
+	timer := b.timer
+	b.mu.Unlock()
+	if timer != nil {
+		t.Error("Expected flush to stop the batch timer")
+	}
+}
+
+func TestNATSPublishBatcher_Errors(t *testing.T) {
+	publishErr := errors.New("nats: outbound buffer limit exceeded")
+	conn := &fakeNATSConn{
+		publishErr: map[string]error{"orders.bad": publishErr},
+		flushErr:   errors.New("nats: connection closed"),
+	}
+	b := newTestBatcher(conn, 2, time.Hour)
+	ctx := context.Background()
+
+	bad := publishAsync(ctx, b, "orders.bad")
+	waitPending(t, b, 1)
+	good := publishAsync(ctx, b, "orders.good")
+
+	// Ошибка публикации возвращается своему Publish, ошибка Flush - остальным событиям пакета
+	if err := receiveResult(t, bad); !errors.Is(err, publishErr) {
+		t.Errorf("Expected publish error, got %v", err)
+	}
+	if err := receiveResult(t, good); err == nil || !strings.Contains(err.Error(), "failed to flush event batch") {
+		t.Errorf("Expected flush error, got %v", err)
+	}
+}
+
+func TestNATSPublishBatcher_ContextCancel(t *testing.T) {
+	conn := &fakeNATSConn{}
+	b := newTestBatcher(conn, 100, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := publishAsync(ctx, b, "orders.1")
+	waitPending(t, b, 1)
+	cancel()
+
+	if err := receiveResult(t, result); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// Отмененное событие остается в пакете и отправляется при следующем flush
+	b.flush()
+	if published, flushes := conn.stats(); published != 1 || flushes != 1 {
+		t.Errorf("Expected pending event to be sent on flush, got %d published, %d flushes", published, flushes)
+	}
+}
+
+// fakeNATSServer минимальный сервер протокола NATS: отвечает на PING и считает PUB
+type fakeNATSServer struct {
+	listener  net.Listener
+	published atomic.Int64
+}
+
+func newFakeNATSServer(t *testing.T) *fakeNATSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &fakeNATSServer{listener: listener}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeNATSServer) serve(conn net.Conn) {
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576,\"proto\":1}\r\n"); err != nil {
+		return
+	}
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			// PONG отправляется после чтения предшествующих PUB - так подтверждает Flush клиента
+			if _, err := io.WriteString(conn, "PONG\r\n"); err != nil {
+				return
+			}
+		case "PUB":
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return
+			}
+			if _, err := io.CopyN(io.Discard, reader, int64(size)+2); err != nil {
+				return
+			}
+			s.published.Add(1)
+		}
+	}
+}
+
+func TestNATSEventAdapter_PublishRacingClose(t *testing.T) {
+	server := newFakeNATSServer(t)
+	conn, err := nats.Connect("nats://"+server.listener.Addr().String(), nats.NoReconnect())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	config := DefaultNATSEventConfig()
+	config.Conn = conn
+	config.EnableMetrics = false
+	config.BatchSize = 50
+	adapter, err := NewNATSEventAdapter(config)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+
+	wait := publishUntilClosed(t, adapter)
+	time.Sleep(10 * time.Millisecond)
+	if err := adapter.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	published := server.published.Load()
+
+	// Принятые до Close события отправлены и подтверждены сервером до его возврата
+	if accepted := wait(); accepted == 0 || published != accepted {
+		t.Errorf("Expected %d events confirmed by server, got %d", accepted, published)
+	}
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/akriventsev/potter/framework/events"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsGroupSubscriptions подписки адаптера в queue groups (тип события + группа -> подписка)
+type natsGroupSubscriptions struct {
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription
+}
+
+// SubscribeWithGroup подписывает обработчик на события типа eventType любых агрегатов
+// в NATS queue group: каждое событие получает один экземпляр сервиса группы
+// (реализация events.GroupSubscriber). Subject подписки - Naming.Pattern(eventType).
+// Для декодирования payload требуется Codecs с зарегистрированным типом события.
+func (n *NATSEventAdapter) SubscribeWithGroup(eventType string, handler events.EventHandler, group string) error {
+	if n.config.Codecs == nil {
+		return fmt.Errorf("codecs are required to subscribe to %s", eventType)
+	}
+	if group == "" {
+		return fmt.Errorf("consumer group is required")
+	}
+
+	ctx := context.Background()
+	subject := n.config.Naming.Pattern(eventType)
+	sub, err := n.conn.QueueSubscribe(subject, group, func(msg *nats.Msg) {
+		contentType := ""
+		if msg.Header != nil {
+			contentType = msg.Header.Get(events.ContentTypeMetadataKey)
+		}
+		event, err := n.config.Codecs.Deserialize(eventType, contentType, msg.Data)
+		if err != nil {
+			n.recordError(ctx, "decode")
+			return
+		}
+		if err := handler.Handle(ctx, event); err != nil {
+			// Учитываем ошибку, но не прерываем обработку других событий
+			n.recordError(ctx, "handle")
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s in group %s: %w", subject, group, err)
+	}
+
+	n.groups.mu.Lock()
+	defer n.groups.mu.Unlock()
+	if n.groups.subs == nil {
+		n.groups.subs = make(map[string]*nats.Subscription)
+	}
+	key := eventType + "/" + group
+	if previous, ok := n.groups.subs[key]; ok {
+		_ = previous.Unsubscribe()
+	}
+	n.groups.subs[key] = sub
+	return nil
+}
+
+// UnsubscribeGroup отменяет подписку на тип события в группе потребителей
+func (n *NATSEventAdapter) UnsubscribeGroup(eventType, group string) error {
+	n.groups.mu.Lock()
+	defer n.groups.mu.Unlock()
+
+	key := eventType + "/" + group
+	sub, ok := n.groups.subs[key]
+	if !ok {
+		return nil
+	}
+	delete(n.groups.subs, key)
+	if err := sub.Unsubscribe(); err != nil {
+		return fmt.Errorf("failed to unsubscribe from %s: %w", sub.Subject, err)
+	}
+	return nil
+}
+
+// drainGroups завершает подписки в группах: уже полученные события дообрабатываются
+func (n *NATSEventAdapter) drainGroups() {
+	n.groups.mu.Lock()
+	defer n.groups.mu.Unlock()
+
+	for key, sub := range n.groups.subs {
+		_ = sub.Drain()
+		delete(n.groups.subs, key)
+	}
+}
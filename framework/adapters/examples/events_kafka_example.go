@@ -13,9 +13,12 @@ import (
 // ExampleKafkaEventPublisher демонстрирует использование Kafka Event Publisher
 func ExampleKafkaEventPublisher() {
 	// Создание конфигурации
+	// Topic события user.created агрегата user-123: prod.sales.user.user.created.v1
 	config := eventsadapters.KafkaEventConfig{
-		Brokers:         []string{"localhost:9092"},
-		TopicPrefix:     "events",
+		Brokers: []string{"localhost:9092"},
+		Naming: frameworkevents.NewSubjectNaming("{env}.{context}.{aggregate}.{event}.v{version}").
+			WithEnv("prod").
+			WithContext("sales"),
 		Compression:     "snappy",
 		IdempotentWrites: true,
 		EnableMetrics:   true,
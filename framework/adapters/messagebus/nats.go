@@ -69,7 +69,8 @@ type NATSAdapter struct {
 
 // NATSAdapterBuilder построитель для NATS адаптера
 type NATSAdapterBuilder struct {
-	config NATSConfig
+	config  NATSConfig
+	metrics *metrics.Metrics
 }
 
 // NewNATSAdapterBuilder создает новый построитель NATS адаптера
@@ -134,6 +135,14 @@ func (b *NATSAdapterBuilder) WithMetrics(enable bool) *NATSAdapterBuilder {
 	return b
 }
 
+// WithMetricsBackend задает сборщик метрик адаптера (например, metrics.PrometheusExporter.Metrics())
+// и включает метрики; по умолчанию используется OpenTelemetry
+func (b *NATSAdapterBuilder) WithMetricsBackend(m *metrics.Metrics) *NATSAdapterBuilder {
+	b.metrics = m
+	b.config.EnableMetrics = m != nil
+	return b
+}
+
 // WithConnectionPool устанавливает размер connection pool
 func (b *NATSAdapterBuilder) WithConnectionPool(size int) *NATSAdapterBuilder {
 	b.config.ConnectionPoolSize = size
@@ -152,7 +161,9 @@ func (b *NATSAdapterBuilder) Build() (*NATSAdapter, error) {
 		running: false,
 	}
 
-	if b.config.EnableMetrics {
+	if b.metrics != nil {
+		adapter.metrics = b.metrics
+	} else if b.config.EnableMetrics {
 		var err error
 		adapter.metrics, err = metrics.NewMetrics()
 		if err != nil {
@@ -218,10 +229,13 @@ func (n *NATSAdapter) Start(ctx context.Context) error {
 		nats.Timeout(n.config.ConnectionTimeout),
 		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
 			if err != nil {
-				// Логируем ошибку отключения
-				_ = err
+				n.recordError(context.Background(), "disconnect")
 			}
 		}),
+		nats.ErrorHandler(func(nc *nats.Conn, sub *nats.Subscription, err error) {
+			// Асинхронные ошибки клиента (slow consumer, нарушение прав на subject)
+			n.recordError(context.Background(), "async")
+		}),
 		nats.ReconnectHandler(func(nc *nats.Conn) {
 			// Логируем переподключение
 		}),
@@ -336,6 +350,7 @@ func (n *NATSAdapter) Publish(ctx context.Context, subject string, data []byte,
 		if n.metrics != nil {
 			n.metrics.RecordTransport(ctx, "nats", time.Since(start), false)
 		}
+		n.recordError(ctx, "publish")
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
@@ -346,6 +361,13 @@ func (n *NATSAdapter) Publish(ctx context.Context, subject string, data []byte,
 	return nil
 }
 
+// recordError учитывает ошибку адаптера в transport_errors_total{transport="nats"}
+func (n *NATSAdapter) recordError(ctx context.Context, operation string) {
+	if n.metrics != nil {
+		n.metrics.RecordTransportError(ctx, "nats", operation)
+	}
+}
+
 // Subscribe подписывается на subject
 func (n *NATSAdapter) Subscribe(ctx context.Context, subject string, handler transport.MessageHandler) error {
 	conn := n.getConnection()
@@ -353,7 +375,42 @@ func (n *NATSAdapter) Subscribe(ctx context.Context, subject string, handler tra
 		return fmt.Errorf("nats adapter is not connected")
 	}
 
-	sub, err := conn.Subscribe(subject, func(msg *nats.Msg) {
+	sub, err := conn.Subscribe(subject, n.messageCallback(ctx, handler))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	n.mu.Lock()
+	n.subs[subject] = sub
+	n.mu.Unlock()
+
+	return nil
+}
+
+// QueueSubscribe подписывается на subject в queue group: каждое сообщение получает
+// один подписчик группы, поэтому экземпляры сервиса с одной группой не обрабатывают
+// сообщение повторно. Отписка - Unsubscribe(subject).
+func (n *NATSAdapter) QueueSubscribe(ctx context.Context, subject, queue string, handler transport.MessageHandler) error {
+	conn := n.getConnection()
+	if conn == nil {
+		return fmt.Errorf("nats adapter is not connected")
+	}
+
+	sub, err := conn.QueueSubscribe(subject, queue, n.messageCallback(ctx, handler))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to queue group %s: %w", queue, err)
+	}
+
+	n.mu.Lock()
+	n.subs[subject] = sub
+	n.mu.Unlock()
+
+	return nil
+}
+
+// messageCallback преобразует сообщения NATS в transport.Message и вызывает handler
+func (n *NATSAdapter) messageCallback(ctx context.Context, handler transport.MessageHandler) nats.MsgHandler {
+	return func(msg *nats.Msg) {
 		mbMsg := &transport.Message{
 			Subject: msg.Subject,
 			Data:    msg.Data,
@@ -370,20 +427,10 @@ func (n *NATSAdapter) Subscribe(ctx context.Context, subject string, handler tra
 		}
 
 		if err := handler(ctx, mbMsg); err != nil {
-			// Логируем ошибку, но не прерываем обработку других сообщений
-			_ = err
+			// Учитываем ошибку, но не прерываем обработку других сообщений
+			n.recordError(ctx, "handle")
 		}
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to subscribe: %w", err)
 	}
-
-	n.mu.Lock()
-	n.subs[subject] = sub
-	n.mu.Unlock()
-
-	return nil
 }
 
 // Unsubscribe отписывается от subject
@@ -420,6 +467,7 @@ func (n *NATSAdapter) Request(ctx context.Context, subject string, data []byte,
 
 	reply, err := conn.RequestMsgWithContext(reqCtx, msg)
 	if err != nil {
+		n.recordError(ctx, "request")
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
@@ -464,6 +512,7 @@ func (n *NATSAdapter) Respond(ctx context.Context, subject string, handler func(
 
 		mbReply, err := handler(ctx, mbRequest)
 		if err != nil {
+			n.recordError(ctx, "respond")
 			// Отправляем пустой ответ или ошибку
 			if msg.Reply != "" {
 				_ = msg.Respond(nil)
@@ -496,6 +545,35 @@ func (n *NATSAdapter) Close() error {
 	return n.Stop(context.Background())
 }
 
+// Flush дожидается подтверждения сервером всех опубликованных сообщений
+// (реализация core.Flushable). Publish в NATS буферизуется клиентом, поэтому
+// без Flush при остановке сервиса последние сообщения могут быть потеряны.
+func (n *NATSAdapter) Flush(ctx context.Context) error {
+	n.mu.RLock()
+	conns := n.conns
+	if len(conns) == 0 && n.conn != nil {
+		conns = []*nats.Conn{n.conn}
+	}
+	n.mu.RUnlock()
+
+	for _, conn := range conns {
+		if conn == nil || conn.IsClosed() {
+			continue
+		}
+		// FlushWithContext требует дедлайн, без него используется таймаут клиента по умолчанию
+		var err error
+		if _, ok := ctx.Deadline(); ok {
+			err = conn.FlushWithContext(ctx)
+		} else {
+			err = conn.Flush()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to flush nats connection: %w", err)
+		}
+	}
+	return nil
+}
+
 // Conn возвращает NATS соединение (для обратной совместимости)
 func (n *NATSAdapter) Conn() *nats.Conn {
 	return n.getConnection()
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// decimalValue десятичное значение с точным строковым представлением
+// (github.com/shopspring/decimal.Decimal и совместимые типы)
+type decimalValue interface {
+	String() string
+	Exponent() int32
+}
+
+// DecimalString возвращает точное строковое представление десятичного значения:
+// decimal.Decimal, *big.Rat, primitive.Decimal128, json.Number или строки.
+// float64 не поддерживается - значение уже потеряло точность.
+func DecimalString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case decimalValue:
+		return v.String(), true
+	case *big.Rat:
+		return v.FloatString(ratScale(v)), true
+	case primitive.Decimal128:
+		return v.String(), true
+	case json.Number:
+		return v.String(), true
+	case string:
+		if _, ok := new(big.Rat).SetString(v); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// ratScale возвращает число знаков после запятой, достаточное для точного представления
+// дроби; для периодических дробей - 38 знаков, как у NUMERIC(38) и Decimal128
+func ratScale(r *big.Rat) int {
+	denom := new(big.Int).Set(r.Denom())
+	remainder := new(big.Int)
+	factors := make(map[int64]int, 2)
+	for _, factor := range []int64{2, 5} {
+		divisor := big.NewInt(factor)
+		for {
+			quotient, rem := new(big.Int).QuoRem(denom, divisor, remainder)
+			if rem.Sign() != 0 {
+				break
+			}
+			denom = quotient
+			factors[factor]++
+		}
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return 38
+	}
+	return min(max(factors[2], factors[5]), 38)
+}
+
+// normalizeDecimalArg передает десятичные значения в PostgreSQL строкой,
+// чтобы NUMERIC колонки сравнивались без округления до float64
+func normalizeDecimalArg(value interface{}) interface{} {
+	switch value.(type) {
+	case decimalValue, *big.Rat:
+		if text, ok := DecimalString(value); ok {
+			return text
+		}
+	}
+	return value
+}
+
+// normalizeMongoDecimal конвертирует десятичные значения в primitive.Decimal128,
+// включая элементы срезов для $in/$nin
+func normalizeMongoDecimal(value interface{}) interface{} {
+	switch value.(type) {
+	case decimalValue, *big.Rat:
+		text, _ := DecimalString(value)
+		if parsed, err := primitive.ParseDecimal128(text); err == nil {
+			return parsed
+		}
+		return value
+	}
+	if value == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return value
+	}
+	result := make([]interface{}, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		result[i] = normalizeMongoDecimal(rv.Index(i).Interface())
+	}
+	return result
+}
+
+// RegisterDecimal128Codec регистрирует в BSON registry кодек, сохраняющий десятичный тип T
+// (например decimal.Decimal) как Decimal128 вместо структуры с неэкспортируемыми полями:
+//
+//	registry := bson.NewRegistry()
+//	repository.RegisterDecimal128Codec[decimal.Decimal](registry)
+//	client, _ := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetRegistry(registry))
+func RegisterDecimal128Codec[T fmt.Stringer, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](registry *bsoncodec.Registry) {
+	decimalType := reflect.TypeOf((*T)(nil)).Elem()
+	registry.RegisterTypeEncoder(decimalType, bsoncodec.ValueEncoderFunc(
+		func(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+			if !val.IsValid() || val.Type() != decimalType {
+				return bsoncodec.ValueEncoderError{Name: "Decimal128EncodeValue", Types: []reflect.Type{decimalType}, Received: val}
+			}
+			parsed, err := primitive.ParseDecimal128(val.Interface().(T).String())
+			if err != nil {
+				return err
+			}
+			return vw.WriteDecimal128(parsed)
+		}))
+	registry.RegisterTypeDecoder(decimalType, bsoncodec.ValueDecoderFunc(
+		func(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+			if !val.CanSet() || val.Type() != decimalType {
+				return bsoncodec.ValueDecoderError{Name: "Decimal128DecodeValue", Types: []reflect.Type{decimalType}, Received: val}
+			}
+			var text string
+			switch vr.Type() {
+			case bsontype.Decimal128:
+				d, err := vr.ReadDecimal128()
+				if err != nil {
+					return err
+				}
+				text = d.String()
+			case bsontype.String:
+				s, err := vr.ReadString()
+				if err != nil {
+					return err
+				}
+				text = s
+			case bsontype.Null:
+				if err := vr.ReadNull(); err != nil {
+					return err
+				}
+				val.Set(reflect.Zero(decimalType))
+				return nil
+			default:
+				return fmt.Errorf("cannot decode %v into %s", vr.Type(), decimalType)
+			}
+			var decoded T
+			if err := PT(&decoded).UnmarshalText([]byte(text)); err != nil {
+				return err
+			}
+			val.Set(reflect.ValueOf(decoded))
+			return nil
+		}))
+}
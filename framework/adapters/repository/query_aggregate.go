@@ -0,0 +1,479 @@
+// Package repository предоставляет агрегирующие запросы query builder'ов.
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AggregateFunc агрегатная функция
+type AggregateFunc string
+
+const (
+	AggCount AggregateFunc = "COUNT"
+	AggSum   AggregateFunc = "SUM"
+	AggAvg   AggregateFunc = "AVG"
+	AggMin   AggregateFunc = "MIN"
+	AggMax   AggregateFunc = "MAX"
+)
+
+// AggregateQuery агрегирующий запрос: значения агрегатных функций по группам.
+// Условия Where, joins и HAVING берутся из query builder'а, из которого создан запрос.
+// Результаты возвращаются строками AggregateRow с ключами - полями группировки
+// и именами агрегатов (count, sum_amount, avg_amount, ... или имя, заданное As).
+type AggregateQuery interface {
+	// Select добавляет поля группировки (аналог GroupBy)
+	Select(fields ...string) AggregateQuery
+	// GroupBy добавляет поля группировки
+	GroupBy(fields ...string) AggregateQuery
+	// Count добавляет количество записей группы (ключ count)
+	Count() AggregateQuery
+	// Sum добавляет сумму поля (ключ sum_<field>)
+	Sum(field string) AggregateQuery
+	// Avg добавляет среднее значение поля (ключ avg_<field>)
+	Avg(field string) AggregateQuery
+	// Min добавляет минимальное значение поля (ключ min_<field>)
+	Min(field string) AggregateQuery
+	// Max добавляет максимальное значение поля (ключ max_<field>)
+	Max(field string) AggregateQuery
+	// As задает ключ результата последнего добавленного агрегата
+	As(alias string) AggregateQuery
+	// OrderBy сортирует результаты по полю группировки или ключу агрегата
+	OrderBy(key string, order SortOrder) AggregateQuery
+	// Limit ограничивает количество групп
+	Limit(limit int) AggregateQuery
+	// Execute выполняет запрос
+	Execute(ctx context.Context) ([]AggregateRow, error)
+}
+
+// AggregateRow строка результата агрегирующего запроса.
+// Числа хранятся как json.Number без потери точности (суммы денежных полей);
+// для чтения используйте Int64, Float64 и String.
+type AggregateRow map[string]interface{}
+
+// String возвращает значение в виде строки ("" - значения нет)
+func (r AggregateRow) String(key string) string {
+	value, ok := r[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if s, ok := DecimalString(value); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// Int64 возвращает целое значение (0 - значения нет)
+func (r AggregateRow) Int64(key string) (int64, error) {
+	switch value := r[key].(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("aggregate value %s is not a number: %w", key, err)
+		}
+		return int64(f), nil
+	case int:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case float64:
+		return int64(value), nil
+	default:
+		return strconv.ParseInt(r.String(key), 10, 64)
+	}
+}
+
+// Float64 возвращает значение с плавающей точкой (0 - значения нет)
+func (r AggregateRow) Float64(key string) (float64, error) {
+	switch value := r[key].(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		return value.Float64()
+	case int:
+		return float64(value), nil
+	case int32:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	default:
+		return strconv.ParseFloat(r.String(key), 64)
+	}
+}
+
+// DecodeAggregateRows декодирует строки результата в структуры R по json тегам
+// (например, struct{ Status string `json:"status"`; Count int64 `json:"count"` })
+func DecodeAggregateRows[R any](rows []AggregateRow) ([]R, error) {
+	result := make([]R, 0, len(rows))
+	for _, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode aggregate row: %w", err)
+		}
+		var item R
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("failed to decode aggregate row: %w", err)
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// ExecuteAggregate выполняет агрегирующий запрос и декодирует результаты в R (см. DecodeAggregateRows)
+func ExecuteAggregate[R any](ctx context.Context, query AggregateQuery) ([]R, error) {
+	rows, err := query.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeAggregateRows[R](rows)
+}
+
+// aggregateItem агрегат запроса
+type aggregateItem struct {
+	fn    AggregateFunc
+	field string
+	alias string
+}
+
+// aggregateOrder сортировка результатов агрегирующего запроса
+type aggregateOrder struct {
+	key   string
+	order SortOrder
+}
+
+// aggregateSpec описание агрегирующего запроса, общее для backends
+type aggregateSpec struct {
+	groupBy []string
+	items   []aggregateItem
+	orderBy []aggregateOrder
+	limit   *int
+}
+
+// aggregateQuery реализация AggregateQuery: backend выполняет собранное описание запроса
+type aggregateQuery struct {
+	spec    aggregateSpec
+	execute func(ctx context.Context, spec aggregateSpec) ([]AggregateRow, error)
+}
+
+// newAggregateQuery создает агрегирующий запрос с выполнением через execute
+func newAggregateQuery(execute func(ctx context.Context, spec aggregateSpec) ([]AggregateRow, error)) *aggregateQuery {
+	return &aggregateQuery{execute: execute}
+}
+
+// Select добавляет поля группировки
+func (a *aggregateQuery) Select(fields ...string) AggregateQuery {
+	return a.GroupBy(fields...)
+}
+
+// GroupBy добавляет поля группировки
+func (a *aggregateQuery) GroupBy(fields ...string) AggregateQuery {
+	a.spec.groupBy = append(a.spec.groupBy, fields...)
+	return a
+}
+
+// Count добавляет количество записей группы
+func (a *aggregateQuery) Count() AggregateQuery {
+	return a.add(AggCount, "*", "count")
+}
+
+// Sum добавляет сумму поля
+func (a *aggregateQuery) Sum(field string) AggregateQuery {
+	return a.add(AggSum, field, "sum_"+field)
+}
+
+// Avg добавляет среднее значение поля
+func (a *aggregateQuery) Avg(field string) AggregateQuery {
+	return a.add(AggAvg, field, "avg_"+field)
+}
+
+// Min добавляет минимальное значение поля
+func (a *aggregateQuery) Min(field string) AggregateQuery {
+	return a.add(AggMin, field, "min_"+field)
+}
+
+// Max добавляет максимальное значение поля
+func (a *aggregateQuery) Max(field string) AggregateQuery {
+	return a.add(AggMax, field, "max_"+field)
+}
+
+// As задает ключ результата последнего добавленного агрегата
+func (a *aggregateQuery) As(alias string) AggregateQuery {
+	if len(a.spec.items) > 0 {
+		a.spec.items[len(a.spec.items)-1].alias = alias
+	}
+	return a
+}
+
+// OrderBy сортирует результаты
+func (a *aggregateQuery) OrderBy(key string, order SortOrder) AggregateQuery {
+	a.spec.orderBy = append(a.spec.orderBy, aggregateOrder{key: key, order: order})
+	return a
+}
+
+// Limit ограничивает количество групп
+func (a *aggregateQuery) Limit(limit int) AggregateQuery {
+	a.spec.limit = &limit
+	return a
+}
+
+// Execute выполняет запрос
+func (a *aggregateQuery) Execute(ctx context.Context) ([]AggregateRow, error) {
+	if len(a.spec.items) == 0 && len(a.spec.groupBy) == 0 {
+		return nil, fmt.Errorf("aggregate query requires at least one aggregate or group field")
+	}
+	return a.execute(ctx, a.spec)
+}
+
+// add добавляет агрегат
+func (a *aggregateQuery) add(fn AggregateFunc, field, alias string) AggregateQuery {
+	a.spec.items = append(a.spec.items, aggregateItem{fn: fn, field: field, alias: alias})
+	return a
+}
+
+// quoteIdentifier экранирует идентификатор PostgreSQL
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Select начинает агрегирующий запрос с полями группировки
+func (q *PostgresQueryBuilder[T]) Select(fields ...string) AggregateQuery {
+	return q.aggregate().Select(fields...)
+}
+
+// Sum начинает агрегирующий запрос с суммой поля
+func (q *PostgresQueryBuilder[T]) Sum(field string) AggregateQuery {
+	return q.aggregate().Sum(field)
+}
+
+// Avg начинает агрегирующий запрос со средним значением поля
+func (q *PostgresQueryBuilder[T]) Avg(field string) AggregateQuery {
+	return q.aggregate().Avg(field)
+}
+
+// Min начинает агрегирующий запрос с минимальным значением поля
+func (q *PostgresQueryBuilder[T]) Min(field string) AggregateQuery {
+	return q.aggregate().Min(field)
+}
+
+// Max начинает агрегирующий запрос с максимальным значением поля
+func (q *PostgresQueryBuilder[T]) Max(field string) AggregateQuery {
+	return q.aggregate().Max(field)
+}
+
+// aggregate создает агрегирующий запрос с условиями builder'а
+func (q *PostgresQueryBuilder[T]) aggregate() *aggregateQuery {
+	return newAggregateQuery(q.executeAggregate)
+}
+
+// buildAggregateQuery строит SQL агрегирующего запроса. Строки возвращаются через
+// row_to_json, поэтому numeric суммы читаются без потери точности.
+func (q *PostgresQueryBuilder[T]) buildAggregateQuery(spec aggregateSpec) (string, []interface{}, error) {
+	groupBy := append(append([]string(nil), q.groupBy...), spec.groupBy...)
+
+	columns := make([]string, 0, len(spec.groupBy)+len(spec.items))
+	for _, field := range spec.groupBy {
+		columns = append(columns, fmt.Sprintf("%s AS %s", field, quoteIdentifier(field)))
+	}
+	for _, item := range spec.items {
+		columns = append(columns, fmt.Sprintf("%s(%s) AS %s", item.fn, item.field, quoteIdentifier(item.alias)))
+	}
+
+	inner, args, err := q.buildSelect(strings.Join(columns, ", "), groupBy)
+	if err != nil {
+		return "", nil, err
+	}
+
+	parts := []string{"SELECT row_to_json(r) FROM (" + inner + ") r"}
+	if len(spec.orderBy) > 0 {
+		orderBy := make([]string, len(spec.orderBy))
+		for i, order := range spec.orderBy {
+			orderBy[i] = fmt.Sprintf("r.%s %s", quoteIdentifier(order.key), order.order)
+		}
+		parts = append(parts, "ORDER BY", strings.Join(orderBy, ", "))
+	}
+	if spec.limit != nil {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", *spec.limit))
+	}
+
+	return strings.Join(parts, " "), args, nil
+}
+
+// executeAggregate выполняет агрегирующий запрос
+func (q *PostgresQueryBuilder[T]) executeAggregate(ctx context.Context, spec aggregateSpec) ([]AggregateRow, error) {
+	query, args, err := q.buildAggregateQuery(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute aggregate query: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]AggregateRow, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate row: %w", err)
+		}
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.UseNumber()
+		row := AggregateRow{}
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode aggregate row: %w", err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// Select начинает агрегирующий запрос с полями группировки
+func (q *MongoQueryBuilder[T]) Select(fields ...string) AggregateQuery {
+	return q.aggregate().Select(fields...)
+}
+
+// Sum начинает агрегирующий запрос с суммой поля
+func (q *MongoQueryBuilder[T]) Sum(field string) AggregateQuery {
+	return q.aggregate().Sum(field)
+}
+
+// Avg начинает агрегирующий запрос со средним значением поля
+func (q *MongoQueryBuilder[T]) Avg(field string) AggregateQuery {
+	return q.aggregate().Avg(field)
+}
+
+// Min начинает агрегирующий запрос с минимальным значением поля
+func (q *MongoQueryBuilder[T]) Min(field string) AggregateQuery {
+	return q.aggregate().Min(field)
+}
+
+// Max начинает агрегирующий запрос с максимальным значением поля
+func (q *MongoQueryBuilder[T]) Max(field string) AggregateQuery {
+	return q.aggregate().Max(field)
+}
+
+// aggregate создает агрегирующий запрос с фильтром и pipeline builder'а
+func (q *MongoQueryBuilder[T]) aggregate() *aggregateQuery {
+	return newAggregateQuery(q.executeAggregate)
+}
+
+// mongoAccumulators операторы MongoDB для агрегатных функций
+var mongoAccumulators = map[AggregateFunc]string{
+	AggSum: "$sum",
+	AggAvg: "$avg",
+	AggMin: "$min",
+	AggMax: "$max",
+}
+
+// buildAggregatePipeline строит aggregation pipeline: $match по фильтру, stages builder'а,
+// $group по полям группировки и $project в плоские строки
+func (q *MongoQueryBuilder[T]) buildAggregatePipeline(spec aggregateSpec) []bson.D {
+	pipeline := make([]bson.D, 0, len(q.pipeline)+5)
+	if len(q.filter) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: q.filter}})
+	}
+	pipeline = append(pipeline, q.pipeline...)
+
+	// Ключи _id нумеруются: имена полей могут содержать точки вложенных документов
+	groupID := bson.D{}
+	project := bson.D{{Key: "_id", Value: 0}}
+	for i, field := range spec.groupBy {
+		key := fmt.Sprintf("g%d", i)
+		groupID = append(groupID, bson.E{Key: key, Value: "$" + field})
+		project = append(project, bson.E{Key: field, Value: "$_id." + key})
+	}
+
+	group := bson.D{{Key: "_id", Value: groupID}}
+	if len(spec.groupBy) == 0 {
+		group = bson.D{{Key: "_id", Value: nil}}
+	}
+	for _, item := range spec.items {
+		accumulator := bson.M{"$sum": 1}
+		if item.fn != AggCount {
+			accumulator = bson.M{mongoAccumulators[item.fn]: "$" + item.field}
+		}
+		group = append(group, bson.E{Key: item.alias, Value: accumulator})
+		project = append(project, bson.E{Key: item.alias, Value: 1})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: group}},
+		bson.D{{Key: "$project", Value: project}},
+	)
+
+	if len(spec.orderBy) > 0 {
+		sort := bson.D{}
+		for _, order := range spec.orderBy {
+			direction := 1
+			if order.order == Desc {
+				direction = -1
+			}
+			sort = append(sort, bson.E{Key: order.key, Value: direction})
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+	if spec.limit != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: int64(*spec.limit)}})
+	}
+	return pipeline
+}
+
+// executeAggregate выполняет агрегирующий запрос
+func (q *MongoQueryBuilder[T]) executeAggregate(ctx context.Context, spec aggregateSpec) ([]AggregateRow, error) {
+	cursor, err := q.collection.Aggregate(ctx, q.buildAggregatePipeline(spec), options.Aggregate())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute aggregate query: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var documents []bson.M
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("failed to decode aggregate results: %w", err)
+	}
+
+	result := make([]AggregateRow, 0, len(documents))
+	for _, document := range documents {
+		row := make(AggregateRow, len(document))
+		for key, value := range document {
+			row[key] = normalizeAggregateValue(value)
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// normalizeAggregateValue приводит числа MongoDB к json.Number: Decimal128 сумм
+// сохраняет точность и одинаково читается AggregateRow для всех backends
+func normalizeAggregateValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.Decimal128:
+		return json.Number(v.String())
+	case int32:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return value
+	}
+}
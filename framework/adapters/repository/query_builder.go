@@ -55,6 +55,12 @@ type QueryBuilder[T Entity] interface {
 	Count(ctx context.Context) (int64, error)
 	First(ctx context.Context) (T, error)
 	Exists(ctx context.Context) (bool, error)
+	// Select, Sum, Avg, Min и Max начинают агрегирующий запрос с условиями builder'а
+	Select(fields ...string) AggregateQuery
+	Sum(field string) AggregateQuery
+	Avg(field string) AggregateQuery
+	Min(field string) AggregateQuery
+	Max(field string) AggregateQuery
 }
 
 // QueryCondition условие запроса
@@ -240,7 +246,7 @@ func (q *PostgresQueryBuilder[T]) GroupBy(field string) *PostgresQueryBuilder[T]
 func (q *PostgresQueryBuilder[T]) Having(field string, op QueryOperator, value interface{}) *PostgresQueryBuilder[T] {
 	// Сохраняем условие без плейсхолдера, он будет добавлен позже
 	q.having = append(q.having, fmt.Sprintf("%s %s $PLACEHOLDER", field, op))
-	q.args = append(q.args, value)
+	q.args = append(q.args, normalizeDecimalArg(value))
 	return q
 }
 
@@ -274,7 +280,7 @@ func (q *PostgresQueryBuilder[T]) buildWhereClause() (string, []interface{}, err
 				return "", nil, fmt.Errorf("BETWEEN requires exactly 2 values, got %d", len(values))
 			}
 			conditionPart = fmt.Sprintf("%s BETWEEN $%d AND $%d", cond.Field, argIndex, argIndex+1)
-			args = append(args, values[0], values[1])
+			args = append(args, normalizeDecimalArg(values[0]), normalizeDecimalArg(values[1]))
 			argIndex += 2
 		case In, NotIn:
 			values, err := convertToInterfaceSlice(cond.Value)
@@ -287,7 +293,7 @@ func (q *PostgresQueryBuilder[T]) buildWhereClause() (string, []interface{}, err
 			placeholders := make([]string, len(values))
 			for j := range values {
 				placeholders[j] = fmt.Sprintf("$%d", argIndex)
-				args = append(args, values[j])
+				args = append(args, normalizeDecimalArg(values[j]))
 				argIndex++
 			}
 			conditionPart = fmt.Sprintf("%s %s (%s)", cond.Field, cond.Operator, strings.Join(placeholders, ", "))
@@ -297,7 +303,7 @@ func (q *PostgresQueryBuilder[T]) buildWhereClause() (string, []interface{}, err
 			argIndex++
 		default:
 			conditionPart = fmt.Sprintf("%s %s $%d", cond.Field, cond.Operator, argIndex)
-			args = append(args, cond.Value)
+			args = append(args, normalizeDecimalArg(cond.Value))
 			argIndex++
 		}
 
@@ -330,13 +336,39 @@ func (q *PostgresQueryBuilder[T]) BuildQuery() (string, []interface{}, error) {
 
 // buildQuery строит SQL запрос
 func (q *PostgresQueryBuilder[T]) buildQuery() (string, []interface{}, error) {
+	query, args, err := q.buildSelect("data", q.groupBy)
+	if err != nil {
+		return "", nil, err
+	}
+	parts := []string{query}
+
+	// ORDER BY
+	if len(q.orderBy) > 0 {
+		parts = append(parts, "ORDER BY", strings.Join(q.orderBy, ", "))
+	}
+
+	// LIMIT
+	if q.limitValue != nil {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", *q.limitValue))
+	}
+
+	// OFFSET
+	if q.offsetValue != nil {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", *q.offsetValue))
+	}
+
+	return strings.Join(parts, " "), args, nil
+}
+
+// buildSelect строит SELECT columns FROM ... WHERE ... GROUP BY ... HAVING ... без сортировки и лимитов
+func (q *PostgresQueryBuilder[T]) buildSelect(columns string, groupBy []string) (string, []interface{}, error) {
 	tableName := fmt.Sprintf("%s.%s", q.config.SchemaName, q.config.TableName)
-	
+
 	var parts []string
 	args := make([]interface{}, 0)
 
 	// SELECT
-	parts = append(parts, "SELECT data FROM", tableName)
+	parts = append(parts, "SELECT", columns, "FROM", tableName)
 
 	// JOINs
 	if len(q.joins) > 0 {
@@ -354,8 +386,8 @@ func (q *PostgresQueryBuilder[T]) buildQuery() (string, []interface{}, error) {
 	}
 
 	// GROUP BY
-	if len(q.groupBy) > 0 {
-		parts = append(parts, "GROUP BY", strings.Join(q.groupBy, ", "))
+	if len(groupBy) > 0 {
+		parts = append(parts, "GROUP BY", strings.Join(groupBy, ", "))
 	}
 
 	// HAVING
@@ -371,21 +403,6 @@ func (q *PostgresQueryBuilder[T]) buildQuery() (string, []interface{}, error) {
 		args = append(args, q.args...)
 	}
 
-	// ORDER BY
-	if len(q.orderBy) > 0 {
-		parts = append(parts, "ORDER BY", strings.Join(q.orderBy, ", "))
-	}
-
-	// LIMIT
-	if q.limitValue != nil {
-		parts = append(parts, fmt.Sprintf("LIMIT %d", *q.limitValue))
-	}
-
-	// OFFSET
-	if q.offsetValue != nil {
-		parts = append(parts, fmt.Sprintf("OFFSET %d", *q.offsetValue))
-	}
-
 	return strings.Join(parts, " "), args, nil
 }
 
@@ -507,6 +524,10 @@ func NewMongoQueryBuilder[T Entity](collection *mongo.Collection, config MongoCo
 
 // Where добавляет условие фильтрации
 func (q *MongoQueryBuilder[T]) Where(field string, op QueryOperator, value interface{}) QueryBuilder[T] {
+	// Десятичные значения сравниваются с Decimal128 полями без потери точности
+	if op != Like {
+		value = normalizeMongoDecimal(value)
+	}
 	switch op {
 	case Eq:
 		q.filter[field] = value
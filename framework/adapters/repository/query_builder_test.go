@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // QueryTestMapper тестовый mapper для QueryBuilder тестов
@@ -365,3 +369,129 @@ func TestPostgresQueryBuilder_RecordQueryPattern(t *testing.T) {
 		t.Errorf("Expected queryPattern for 'name' to be 1, got %d", autoIndexManager.queryPatterns["name"])
 	}
 }
+
+// testDecimal десятичный тип с методами github.com/shopspring/decimal.Decimal
+type testDecimal struct {
+	text string
+	exp  int32
+}
+
+func (d testDecimal) String() string  { return d.text }
+func (d testDecimal) Exponent() int32 { return d.exp }
+
+func TestPostgresQueryBuilder_DecimalArgs(t *testing.T) {
+	builder, err := createTestBuilder()
+	if err != nil {
+		t.Skipf("Skipping test - cannot create builder: %v", err)
+	}
+	builder.Where("price", Gte, testDecimal{text: "19.99", exp: -2})
+	builder.Where("price", Between, []interface{}{big.NewRat(1, 8), big.NewRat(1, 3)})
+
+	_, args, err := builder.BuildQuery()
+	if err != nil {
+		t.Fatalf("buildQuery failed: %v", err)
+	}
+	if len(args) != 3 {
+		t.Fatalf("Expected 3 args, got %d", len(args))
+	}
+	if args[0] != "19.99" || args[1] != "0.125" {
+		t.Errorf("Expected decimal args as exact strings, got %v, %v", args[0], args[1])
+	}
+	if text, ok := args[2].(string); !ok || !strings.HasPrefix(text, "0.3333") {
+		t.Errorf("Expected repeating fraction rounded to NUMERIC scale, got %v", args[2])
+	}
+}
+
+func TestNormalizeMongoDecimal(t *testing.T) {
+	value := normalizeMongoDecimal(testDecimal{text: "1234567890.123456789", exp: -9})
+	d, ok := value.(primitive.Decimal128)
+	if !ok {
+		t.Fatalf("Expected primitive.Decimal128, got %T", value)
+	}
+	if d.String() != "1234567890.123456789" {
+		t.Errorf("Expected exact decimal, got %s", d.String())
+	}
+
+	values, ok := normalizeMongoDecimal([]testDecimal{{text: "1.50", exp: -2}, {text: "2", exp: 0}}).([]interface{})
+	if !ok || len(values) != 2 {
+		t.Fatalf("Expected slice of 2 values, got %v", values)
+	}
+	if _, ok := values[0].(primitive.Decimal128); !ok {
+		t.Errorf("Expected slice elements converted to Decimal128, got %T", values[0])
+	}
+
+	if normalizeMongoDecimal("19.99") != "19.99" {
+		t.Error("Expected strings to stay unchanged")
+	}
+}
+
+func TestPostgresQueryBuilder_Aggregate(t *testing.T) {
+	config := DefaultPostgresConfig()
+	config.TableName = "orders"
+	// Соединение не требуется для генерации SQL
+	builder := NewPostgresQueryBuilder[TestEntity](nil, &QueryTestMapper{}, config)
+	builder.Where("status", Eq, "paid")
+
+	query := builder.Sum("amount").GroupBy("customer_id").Count().As("orders").OrderBy("sum_amount", Desc).Limit(10)
+	sql, args, err := builder.buildAggregateQuery(query.(*aggregateQuery).spec)
+	if err != nil {
+		t.Fatalf("buildAggregateQuery failed: %v", err)
+	}
+
+	expected := `SELECT row_to_json(r) FROM (SELECT customer_id AS "customer_id", SUM(amount) AS "sum_amount", COUNT(*) AS "orders" ` +
+		`FROM public.orders WHERE status = $1 GROUP BY customer_id) r ORDER BY r."sum_amount" DESC LIMIT 10`
+	if sql != expected {
+		t.Errorf("Unexpected aggregate query:\n%s\nexpected:\n%s", sql, expected)
+	}
+	if len(args) != 1 || args[0] != "paid" {
+		t.Errorf("Expected args [paid], got %v", args)
+	}
+
+	if _, err := NewPostgresQueryBuilder[TestEntity](nil, &QueryTestMapper{}, config).Select().Execute(context.Background()); err == nil {
+		t.Error("Expected error for aggregate query without aggregates")
+	}
+}
+
+func TestMongoQueryBuilder_AggregatePipeline(t *testing.T) {
+	builder := NewMongoQueryBuilder[TestEntity](nil, DefaultMongoConfig())
+	builder.Where("status", Eq, "paid")
+
+	query := builder.Select("customer.id").Avg("amount")
+	pipeline := builder.buildAggregatePipeline(query.(*aggregateQuery).spec)
+	if len(pipeline) != 3 {
+		t.Fatalf("Expected $match, $group and $project stages, got %v", pipeline)
+	}
+	group := pipeline[1][0].Value.(bson.D)
+	if group[0].Value.(bson.D)[0].Value != "$customer.id" {
+		t.Errorf("Expected group by $customer.id, got %v", group[0].Value)
+	}
+	if accumulator := group[1]; accumulator.Key != "avg_amount" || accumulator.Value.(bson.M)["$avg"] != "$amount" {
+		t.Errorf("Expected avg_amount accumulator, got %v", accumulator)
+	}
+}
+
+func TestDecodeAggregateRows(t *testing.T) {
+	total, err := primitive.ParseDecimal128("1234567890.123456789")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rows := []AggregateRow{
+		{"status": "paid", "count": normalizeAggregateValue(int32(3)), "sum_amount": normalizeAggregateValue(total)},
+	}
+	if count, err := rows[0].Int64("count"); err != nil || count != 3 {
+		t.Errorf("Expected count 3, got %d (%v)", count, err)
+	}
+
+	type statusTotals struct {
+		Status string      `json:"status"`
+		Count  int64       `json:"count"`
+		Total  json.Number `json:"sum_amount"`
+	}
+	decoded, err := DecodeAggregateRows[statusTotals](rows)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if decoded[0].Status != "paid" || decoded[0].Count != 3 || decoded[0].Total != "1234567890.123456789" {
+		t.Errorf("Unexpected decoded row: %+v", decoded[0])
+	}
+}
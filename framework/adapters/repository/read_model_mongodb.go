@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoReadModelDocument документ read model в MongoDB: JSON read model для чтения
+// и поля документа для фильтров и сортировки (по JSON именам полей)
+type mongoReadModelDocument struct {
+	ID     string                 `bson:"_id"`
+	Data   string                 `bson:"data"`
+	Fields map[string]interface{} `bson:"fields"`
+}
+
+// MongoReadModelStore хранилище read models в коллекции MongoDB
+type MongoReadModelStore[T any] struct {
+	collection *mongo.Collection
+}
+
+// NewMongoReadModelStore создает хранилище read models в коллекции collection
+func NewMongoReadModelStore[T any](db *mongo.Database, collection string) *MongoReadModelStore[T] {
+	return &MongoReadModelStore[T]{collection: db.Collection(collection)}
+}
+
+// Upsert создает или заменяет read model
+func (s *MongoReadModelStore[T]) Upsert(ctx context.Context, id string, model T) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal read model %s: %w", id, err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("read model %s must be a JSON object: %w", id, err)
+	}
+
+	document := mongoReadModelDocument{ID: id, Data: string(data), Fields: fields}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": id}, document, opts); err != nil {
+		return fmt.Errorf("failed to upsert read model %s: %w", id, err)
+	}
+	return nil
+}
+
+// Get возвращает read model по ID
+func (s *MongoReadModelStore[T]) Get(ctx context.Context, id string) (T, error) {
+	var model T
+	var document mongoReadModelDocument
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model, fmt.Errorf("%w: %s", ErrReadModelNotFound, id)
+	}
+	if err != nil {
+		return model, fmt.Errorf("failed to get read model %s: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(document.Data), &model); err != nil {
+		return model, fmt.Errorf("failed to unmarshal read model %s: %w", id, err)
+	}
+	return model, nil
+}
+
+// List возвращает read models, подходящие под фильтр
+func (s *MongoReadModelStore[T]) List(ctx context.Context, filter ReadModelFilter) ([]T, error) {
+	where, err := normalizeReadModelValues(filter.Where)
+	if err != nil {
+		return nil, err
+	}
+	query := bson.M{}
+	for field, value := range where {
+		query["fields."+field] = value
+	}
+
+	direction := 1
+	if filter.Desc {
+		direction = -1
+	}
+	sort := bson.D{}
+	if filter.OrderBy != "" {
+		sort = append(sort, bson.E{Key: "fields." + filter.OrderBy, Value: direction})
+	}
+	sort = append(sort, bson.E{Key: "_id", Value: direction})
+
+	opts := options.Find().SetSort(sort)
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		opts.SetSkip(int64(filter.Offset))
+	}
+
+	cursor, err := s.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list read models: %w", err)
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	result := make([]T, 0)
+	for cursor.Next(ctx) {
+		var document mongoReadModelDocument
+		if err := cursor.Decode(&document); err != nil {
+			return nil, fmt.Errorf("failed to decode read model: %w", err)
+		}
+		var model T
+		if err := json.Unmarshal([]byte(document.Data), &model); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal read model %s: %w", document.ID, err)
+		}
+		result = append(result, model)
+	}
+	return result, cursor.Err()
+}
+
+// Delete удаляет read model
+func (s *MongoReadModelStore[T]) Delete(ctx context.Context, id string) error {
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete read model %s: %w", id, err)
+	}
+	return nil
+}
+
+// Clear удаляет все read models
+func (s *MongoReadModelStore[T]) Clear(ctx context.Context) error {
+	if _, err := s.collection.DeleteMany(ctx, bson.M{}); err != nil {
+		return fmt.Errorf("failed to clear read models: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresReadModelStore хранилище read models в PostgreSQL: документ read model
+// хранится в колонке JSONB, фильтры выполняются по полям документа
+//
+//	CREATE TABLE {table} (id TEXT PRIMARY KEY, data JSONB NOT NULL, updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW())
+type PostgresReadModelStore[T any] struct {
+	db    *pgxpool.Pool
+	table string
+}
+
+// NewPostgresReadModelStore создает хранилище read models в таблице table
+func NewPostgresReadModelStore[T any](db *pgxpool.Pool, table string) *PostgresReadModelStore[T] {
+	return &PostgresReadModelStore[T]{db: db, table: table}
+}
+
+// EnsureTable создает таблицу хранилища, если ее нет
+func (s *PostgresReadModelStore[T]) EnsureTable(ctx context.Context) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		data JSONB NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`, pgx.Identifier{s.table}.Sanitize())
+	if _, err := s.db.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create read model table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Upsert создает или заменяет read model
+func (s *PostgresReadModelStore[T]) Upsert(ctx context.Context, id string, model T) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal read model %s: %w", id, err)
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (id, data, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, updated_at = EXCLUDED.updated_at`,
+		pgx.Identifier{s.table}.Sanitize())
+	if _, err := s.db.Exec(ctx, query, id, data); err != nil {
+		return fmt.Errorf("failed to upsert read model %s: %w", id, err)
+	}
+	return nil
+}
+
+// Get возвращает read model по ID
+func (s *PostgresReadModelStore[T]) Get(ctx context.Context, id string) (T, error) {
+	var model T
+	var data []byte
+	query := fmt.Sprintf("SELECT data FROM %s WHERE id = $1", pgx.Identifier{s.table}.Sanitize())
+	err := s.db.QueryRow(ctx, query, id).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model, fmt.Errorf("%w: %s", ErrReadModelNotFound, id)
+	}
+	if err != nil {
+		return model, fmt.Errorf("failed to get read model %s: %w", id, err)
+	}
+	if err := json.Unmarshal(data, &model); err != nil {
+		return model, fmt.Errorf("failed to unmarshal read model %s: %w", id, err)
+	}
+	return model, nil
+}
+
+// List возвращает read models, подходящие под фильтр. Условия равенства проверяются
+// вхождением JSONB (data @> filter), сортировка - по значению поля документа.
+func (s *PostgresReadModelStore[T]) List(ctx context.Context, filter ReadModelFilter) ([]T, error) {
+	var query strings.Builder
+	args := make([]interface{}, 0, 4)
+	query.WriteString(fmt.Sprintf("SELECT data FROM %s", pgx.Identifier{s.table}.Sanitize()))
+
+	if len(filter.Where) > 0 {
+		where, err := json.Marshal(filter.Where)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read model filter: %w", err)
+		}
+		args = append(args, where)
+		query.WriteString(fmt.Sprintf(" WHERE data @> $%d::jsonb", len(args)))
+	}
+
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+	if filter.OrderBy != "" {
+		args = append(args, filter.OrderBy)
+		query.WriteString(fmt.Sprintf(" ORDER BY data->$%d %s, id %s", len(args), direction, direction))
+	} else {
+		query.WriteString(" ORDER BY id " + direction)
+	}
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
+	}
+
+	rows, err := s.db.Query(ctx, query.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list read models %s: %w", s.table, err)
+	}
+	defer rows.Close()
+
+	result := make([]T, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan read model: %w", err)
+		}
+		var model T
+		if err := json.Unmarshal(data, &model); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal read model: %w", err)
+		}
+		result = append(result, model)
+	}
+	return result, rows.Err()
+}
+
+// Delete удаляет read model
+func (s *PostgresReadModelStore[T]) Delete(ctx context.Context, id string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", pgx.Identifier{s.table}.Sanitize())
+	if _, err := s.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete read model %s: %w", id, err)
+	}
+	return nil
+}
+
+// Clear удаляет все read models
+func (s *PostgresReadModelStore[T]) Clear(ctx context.Context) error {
+	if _, err := s.db.Exec(ctx, "TRUNCATE TABLE "+pgx.Identifier{s.table}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to truncate read models %s: %w", s.table, err)
+	}
+	return nil
+}
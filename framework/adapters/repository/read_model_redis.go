@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisReadModelStore хранилище read models в Redis: JSON read model хранится в ключе
+// {prefix}:{id}, ID - в множестве {prefix}:ids. List загружает все read models и
+// фильтрует их в памяти, поэтому подходит для небольших read models (справочники, кеши).
+type RedisReadModelStore[T any] struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisReadModelStore создает хранилище read models с префиксом ключей prefix
+func NewRedisReadModelStore[T any](client redis.UniversalClient, prefix string) *RedisReadModelStore[T] {
+	return &RedisReadModelStore[T]{client: client, prefix: prefix}
+}
+
+// key возвращает ключ read model
+func (s *RedisReadModelStore[T]) key(id string) string {
+	return s.prefix + ":" + id
+}
+
+// idsKey возвращает ключ множества ID read models
+func (s *RedisReadModelStore[T]) idsKey() string {
+	return s.prefix + ":ids"
+}
+
+// Upsert создает или заменяет read model
+func (s *RedisReadModelStore[T]) Upsert(ctx context.Context, id string, model T) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal read model %s: %w", id, err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.key(id), data, 0)
+		pipe.SAdd(ctx, s.idsKey(), id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert read model %s: %w", id, err)
+	}
+	return nil
+}
+
+// Get возвращает read model по ID
+func (s *RedisReadModelStore[T]) Get(ctx context.Context, id string) (T, error) {
+	var model T
+	data, err := s.client.Get(ctx, s.key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return model, fmt.Errorf("%w: %s", ErrReadModelNotFound, id)
+	}
+	if err != nil {
+		return model, fmt.Errorf("failed to get read model %s: %w", id, err)
+	}
+	if err := json.Unmarshal(data, &model); err != nil {
+		return model, fmt.Errorf("failed to unmarshal read model %s: %w", id, err)
+	}
+	return model, nil
+}
+
+// List возвращает read models, подходящие под фильтр
+func (s *RedisReadModelStore[T]) List(ctx context.Context, filter ReadModelFilter) ([]T, error) {
+	documents, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterReadModels[T](documents, filter)
+}
+
+// load загружает JSON всех read models
+func (s *RedisReadModelStore[T]) load(ctx context.Context) (map[string][]byte, error) {
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list read model ids: %w", err)
+	}
+	documents := make(map[string][]byte, len(ids))
+	if len(ids) == 0 {
+		return documents, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = s.key(id)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load read models: %w", err)
+	}
+	for i, value := range values {
+		if data, ok := value.(string); ok {
+			documents[ids[i]] = []byte(data)
+		}
+	}
+	return documents, nil
+}
+
+// Delete удаляет read model
+func (s *RedisReadModelStore[T]) Delete(ctx context.Context, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.key(id))
+		pipe.SRem(ctx, s.idsKey(), id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete read model %s: %w", id, err)
+	}
+	return nil
+}
+
+// Clear удаляет все read models
+func (s *RedisReadModelStore[T]) Clear(ctx context.Context) error {
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list read model ids: %w", err)
+	}
+	keys := make([]string, 0, len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, s.key(id))
+	}
+	keys = append(keys, s.idsKey())
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to clear read models: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrReadModelNotFound read model с указанным ID не найдена
+var ErrReadModelNotFound = errors.New("read model not found")
+
+// ReadModelFilter фильтр списка read models. Поля задаются по JSON именам read model,
+// значения сравниваются на равенство после JSON сериализации (одинаково во всех backends).
+type ReadModelFilter struct {
+	// Where условия равенства полей (все должны выполняться)
+	Where map[string]interface{}
+	// OrderBy поле сортировки (пусто - по ID)
+	OrderBy string
+	// Desc сортировка по убыванию
+	Desc bool
+	// Limit размер страницы (0 - без ограничения), Offset - смещение
+	Limit  int
+	Offset int
+}
+
+// ReadModelStore хранилище read models для query handlers. Позволяет переключать
+// backend (PostgreSQL, MongoDB, Redis, память) конфигурацией без изменения обработчиков.
+type ReadModelStore[T any] interface {
+	// Upsert создает или заменяет read model
+	Upsert(ctx context.Context, id string, model T) error
+	// Get возвращает read model по ID или ошибку ErrReadModelNotFound
+	Get(ctx context.Context, id string) (T, error)
+	// List возвращает read models, подходящие под фильтр
+	List(ctx context.Context, filter ReadModelFilter) ([]T, error)
+	// Delete удаляет read model (отсутствующая read model не является ошибкой)
+	Delete(ctx context.Context, id string) error
+}
+
+// ReadModelClearer реализуется хранилищами, поддерживающими очистку
+// (пересоздание проекции с начала потока событий)
+type ReadModelClearer interface {
+	Clear(ctx context.Context) error
+}
+
+// ReadModelBackend backend хранилища read models
+type ReadModelBackend string
+
+const (
+	ReadModelBackendPostgres ReadModelBackend = "postgres"
+	ReadModelBackendMongoDB  ReadModelBackend = "mongodb"
+	ReadModelBackendRedis    ReadModelBackend = "redis"
+	ReadModelBackendInMemory ReadModelBackend = "inmemory"
+)
+
+// ReadModelStoreConfig конфигурация хранилища read models: backend и клиент выбранного backend
+type ReadModelStoreConfig struct {
+	Backend  ReadModelBackend
+	Postgres *pgxpool.Pool
+	MongoDB  *mongo.Database
+	Redis    redis.UniversalClient
+}
+
+// NewReadModelStore создает хранилище read models name (таблица, коллекция или
+// префикс ключей) по конфигурации. Пустой backend - PostgreSQL.
+func NewReadModelStore[T any](config ReadModelStoreConfig, name string) (ReadModelStore[T], error) {
+	switch config.Backend {
+	case "", ReadModelBackendPostgres:
+		if config.Postgres == nil {
+			return nil, fmt.Errorf("postgres pool is required for read model %s", name)
+		}
+		return NewPostgresReadModelStore[T](config.Postgres, name), nil
+	case ReadModelBackendMongoDB:
+		if config.MongoDB == nil {
+			return nil, fmt.Errorf("mongodb database is required for read model %s", name)
+		}
+		return NewMongoReadModelStore[T](config.MongoDB, name), nil
+	case ReadModelBackendRedis:
+		if config.Redis == nil {
+			return nil, fmt.Errorf("redis client is required for read model %s", name)
+		}
+		return NewRedisReadModelStore[T](config.Redis, name), nil
+	case ReadModelBackendInMemory:
+		return NewInMemoryReadModelStore[T](), nil
+	default:
+		return nil, fmt.Errorf("unknown read model backend: %s", config.Backend)
+	}
+}
+
+// InMemoryReadModelStore хранилище read models в памяти (для тестов)
+type InMemoryReadModelStore[T any] struct {
+	mu     sync.RWMutex
+	models map[string][]byte
+}
+
+// NewInMemoryReadModelStore создает хранилище read models в памяти
+func NewInMemoryReadModelStore[T any]() *InMemoryReadModelStore[T] {
+	return &InMemoryReadModelStore[T]{models: make(map[string][]byte)}
+}
+
+// Upsert создает или заменяет read model. Хранится JSON копия, поэтому
+// изменения переданного значения после Upsert не влияют на хранилище.
+func (s *InMemoryReadModelStore[T]) Upsert(ctx context.Context, id string, model T) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal read model %s: %w", id, err)
+	}
+	s.mu.Lock()
+	s.models[id] = data
+	s.mu.Unlock()
+	return nil
+}
+
+// Get возвращает read model по ID
+func (s *InMemoryReadModelStore[T]) Get(ctx context.Context, id string) (T, error) {
+	s.mu.RLock()
+	data, ok := s.models[id]
+	s.mu.RUnlock()
+
+	var model T
+	if !ok {
+		return model, fmt.Errorf("%w: %s", ErrReadModelNotFound, id)
+	}
+	if err := json.Unmarshal(data, &model); err != nil {
+		return model, fmt.Errorf("failed to unmarshal read model %s: %w", id, err)
+	}
+	return model, nil
+}
+
+// List возвращает read models, подходящие под фильтр
+func (s *InMemoryReadModelStore[T]) List(ctx context.Context, filter ReadModelFilter) ([]T, error) {
+	s.mu.RLock()
+	documents := make(map[string][]byte, len(s.models))
+	for id, data := range s.models {
+		documents[id] = data
+	}
+	s.mu.RUnlock()
+
+	return filterReadModels[T](documents, filter)
+}
+
+// Delete удаляет read model
+func (s *InMemoryReadModelStore[T]) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.models, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// Clear удаляет все read models
+func (s *InMemoryReadModelStore[T]) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	s.models = make(map[string][]byte)
+	s.mu.Unlock()
+	return nil
+}
+
+// normalizeReadModelValues приводит значения фильтра к JSON представлению
+// (числа - float64, время - строка RFC 3339), в котором хранятся read models
+func normalizeReadModelValues(where map[string]interface{}) (map[string]interface{}, error) {
+	if len(where) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read model filter: %w", err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("invalid read model filter: %w", err)
+	}
+	return normalized, nil
+}
+
+// filterReadModels фильтрует, сортирует и разбивает на страницы JSON документы
+// read models (для backends без запросов по полям)
+func filterReadModels[T any](documents map[string][]byte, filter ReadModelFilter) ([]T, error) {
+	where, err := normalizeReadModelValues(filter.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	type document struct {
+		id     string
+		data   []byte
+		fields map[string]interface{}
+	}
+	matched := make([]document, 0, len(documents))
+	for id, data := range documents {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal read model %s: %w", id, err)
+		}
+		match := true
+		for field, value := range where {
+			if !reflect.DeepEqual(fields[field], value) {
+				match = false
+				break
+			}
+		}
+		if match {
+			matched = append(matched, document{id: id, data: data, fields: fields})
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		cmp := 0
+		if filter.OrderBy != "" {
+			cmp = compareReadModelValues(matched[i].fields[filter.OrderBy], matched[j].fields[filter.OrderBy])
+		}
+		if cmp == 0 {
+			cmp = compareReadModelValues(matched[i].id, matched[j].id)
+		}
+		if filter.Desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	if filter.Offset > 0 {
+		if filter.Offset >= len(matched) {
+			matched = matched[:0]
+		} else {
+			matched = matched[filter.Offset:]
+		}
+	}
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+
+	result := make([]T, 0, len(matched))
+	for _, doc := range matched {
+		var model T
+		if err := json.Unmarshal(doc.data, &model); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal read model %s: %w", doc.id, err)
+		}
+		result = append(result, model)
+	}
+	return result, nil
+}
+
+// compareReadModelValues сравнивает JSON значения: числа - численно, строки - лексикографически,
+// отсутствующие значения меньше любых
+func compareReadModelValues(a, b interface{}) int {
+	switch av := a.(type) {
+	case nil:
+		if b == nil {
+			return 0
+		}
+		return -1
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	}
+	if b == nil {
+		return 1
+	}
+	return compareReadModelValues(fmt.Sprint(a), fmt.Sprint(b))
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// orderView read model для тестирования
+type orderView struct {
+	ID       string  `json:"id"`
+	Customer string  `json:"customer"`
+	Status   string  `json:"status"`
+	Total    float64 `json:"total"`
+}
+
+func TestInMemoryReadModelStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewReadModelStore[*orderView](ReadModelStoreConfig{Backend: ReadModelBackendInMemory}, "orders")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	orders := []*orderView{
+		{ID: "o-1", Customer: "alice", Status: "paid", Total: 30},
+		{ID: "o-2", Customer: "bob", Status: "new", Total: 10},
+		{ID: "o-3", Customer: "alice", Status: "paid", Total: 20},
+		{ID: "o-4", Customer: "alice", Status: "new", Total: 40},
+	}
+	for _, order := range orders {
+		if err := store.Upsert(ctx, order.ID, order); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+	}
+
+	// Хранится копия: изменение после Upsert не влияет на read model
+	orders[0].Status = "cancelled"
+	got, err := store.Get(ctx, "o-1")
+	if err != nil || got.Status != "paid" {
+		t.Fatalf("Expected stored copy, got %+v, %v", got, err)
+	}
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrReadModelNotFound) {
+		t.Errorf("Expected ErrReadModelNotFound, got %v", err)
+	}
+
+	list, err := store.List(ctx, ReadModelFilter{
+		Where:   map[string]interface{}{"customer": "alice", "status": "paid"},
+		OrderBy: "total",
+	})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "o-3" || list[1].ID != "o-1" {
+		t.Errorf("Expected paid orders of alice sorted by total, got %+v", list)
+	}
+
+	// Числовые значения фильтра сравниваются независимо от Go типа
+	list, _ = store.List(ctx, ReadModelFilter{Where: map[string]interface{}{"total": 40}})
+	if len(list) != 1 || list[0].ID != "o-4" {
+		t.Errorf("Expected order with total 40, got %+v", list)
+	}
+
+	list, _ = store.List(ctx, ReadModelFilter{OrderBy: "total", Desc: true, Limit: 2, Offset: 1})
+	if len(list) != 2 || list[0].ID != "o-1" || list[1].ID != "o-3" {
+		t.Errorf("Expected second page by total desc, got %+v", list)
+	}
+
+	if err := store.Delete(ctx, "o-2"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	list, _ = store.List(ctx, ReadModelFilter{})
+	if len(list) != 3 || list[0].ID != "o-1" {
+		t.Errorf("Expected 3 orders sorted by ID, got %+v", list)
+	}
+
+	if _, err := NewReadModelStore[*orderView](ReadModelStoreConfig{Backend: ReadModelBackendRedis}, "orders"); err == nil {
+		t.Error("Expected error without redis client")
+	}
+}
@@ -167,8 +167,8 @@ func (s *potterExecutableSchema) createCommandDispatchResolver(fieldName string)
 // createSubscriptionDispatchResolver создает dispatch resolver для Subscription поля
 func (s *potterExecutableSchema) createSubscriptionDispatchResolver(fieldName string) ResolverFunc {
 	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		// Для subscriptions возвращаем канал событий
-		channel, err := s.subscriptionResolver.Subscribe(ctx, fieldName)
+		// Для subscriptions возвращаем канал событий, отфильтрованных по аргументам поля
+		channel, err := s.subscriptionResolver.SubscribeField(ctx, fieldName, args)
 		if err != nil {
 			return nil, err
 		}
@@ -440,6 +440,18 @@ func (g *GraphQLAdapter) RegisterResolver(typeName, fieldName string, resolver R
 	}
 }
 
+// WithSubscriptionEvents связывает поле Subscription с типами событий EventBus.
+// Аргументы поля aggregateId и correlationId фильтруют доставляемые события.
+// Действует только для адаптера, созданного через NewGraphQLAdapterWithCQRS.
+func (g *GraphQLAdapter) WithSubscriptionEvents(fieldName string, eventTypes ...string) *GraphQLAdapter {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if potterSchema, ok := g.schema.(*potterExecutableSchema); ok {
+		potterSchema.subscriptionResolver.MapField(fieldName, eventTypes...)
+	}
+	return g
+}
+
 // WithComplexityLimit устанавливает лимит сложности запросов
 func (g *GraphQLAdapter) WithComplexityLimit(limit int) *GraphQLAdapter {
 	g.mu.Lock()
@@ -147,16 +147,37 @@ func (q *BaseQuery) Args() map[string]interface{} {
 type SubscriptionResolver struct {
 	*BaseResolver
 	subscriptionManager *SubscriptionManager
+	fields              map[string][]string
+	mu                  sync.RWMutex
 }
 
 // NewSubscriptionResolver создает новый subscription resolver
 func NewSubscriptionResolver(base *BaseResolver, subscriptionManager *SubscriptionManager) *SubscriptionResolver {
 	return &SubscriptionResolver{
-		BaseResolver:       base,
+		BaseResolver:        base,
 		subscriptionManager: subscriptionManager,
+		fields:              make(map[string][]string),
 	}
 }
 
+// MapField связывает поле Subscription с типами событий EventBus.
+// Поле без маппинга подписывается на событие с именем поля.
+func (r *SubscriptionResolver) MapField(fieldName string, eventTypes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fields[fieldName] = eventTypes
+}
+
+// EventTypes возвращает типы событий поля Subscription
+func (r *SubscriptionResolver) EventTypes(fieldName string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if eventTypes, ok := r.fields[fieldName]; ok && len(eventTypes) > 0 {
+		return eventTypes
+	}
+	return []string{fieldName}
+}
+
 // Subscribe подписывается на события через EventBus
 func (r *SubscriptionResolver) Subscribe(ctx context.Context, eventType string) (<-chan events.Event, error) {
 	// Создаем подписку через SubscriptionManager
@@ -168,6 +189,55 @@ func (r *SubscriptionResolver) Subscribe(ctx context.Context, eventType string)
 	return channel, nil
 }
 
+// SubscribeField подписывается на события поля Subscription с фильтрацией по аргументам
+// aggregateId и correlationId (см. SubscriptionFilterFromArgs)
+func (r *SubscriptionResolver) SubscribeField(ctx context.Context, fieldName string, args map[string]interface{}) (<-chan events.Event, error) {
+	channel, err := r.subscriptionManager.SubscribeEvents(ctx, r.EventTypes(fieldName), SubscriptionFilterFromArgs(args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to field %s: %w", fieldName, err)
+	}
+
+	return channel, nil
+}
+
+// SubscriptionFilterFromArgs строит фильтр событий из аргументов поля Subscription:
+// aggregateId (aggregate_id) и correlationId (correlation_id). Возвращает nil без аргументов фильтрации.
+func SubscriptionFilterFromArgs(args map[string]interface{}) EventFilter {
+	var filters []EventFilter
+	if aggregateID := stringArg(args, "aggregateId", "aggregate_id"); aggregateID != "" {
+		filters = append(filters, &AggregateIDFilter{AggregateID: aggregateID})
+	}
+	if correlationID := stringArg(args, "correlationId", "correlation_id"); correlationID != "" {
+		filters = append(filters, &CorrelationIDFilter{CorrelationID: correlationID})
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	default:
+		return &CompositeFilter{Filters: filters, Op: "AND"}
+	}
+}
+
+// stringArg возвращает первый непустой строковый аргумент из names
+func stringArg(args map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		switch value := args[name].(type) {
+		case string:
+			if value != "" {
+				return value
+			}
+		case *string:
+			if value != nil && *value != "" {
+				return *value
+			}
+		}
+	}
+	return ""
+}
+
 // ResolverRegistry реестр resolvers
 type ResolverRegistry struct {
 	resolvers map[string]ResolverFunc
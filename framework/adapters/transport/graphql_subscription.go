@@ -22,12 +22,16 @@ type SubscriptionManager struct {
 type Subscription struct {
 	ID        string
 	EventType string
-	Channel   chan events.Event
-	Filter    EventFilter
-	Context   context.Context
-	Cancel    context.CancelFunc
-	CreatedAt time.Time
-	handler   events.EventHandler // Сохраняем handler для правильной отписки
+	// EventTypes все типы событий подписки (EventType - первый из них)
+	EventTypes []string
+	Channel    chan events.Event
+	Filter     EventFilter
+	Context    context.Context
+	Cancel     context.CancelFunc
+	CreatedAt  time.Time
+	handlers   []*subscriptionEventHandler // Сохраняем handlers для правильной отписки
+	mu         sync.RWMutex
+	closed     bool
 }
 
 // EventFilter интерфейс для фильтрации событий
@@ -46,6 +50,15 @@ func NewSubscriptionManager(eventBus events.EventBus) *SubscriptionManager {
 
 // Subscribe создает подписку на события
 func (sm *SubscriptionManager) Subscribe(ctx context.Context, eventType string, filter EventFilter) (<-chan events.Event, error) {
+	return sm.SubscribeEvents(ctx, []string{eventType}, filter)
+}
+
+// SubscribeEvents создает подписку на несколько типов событий с доставкой в один канал
+func (sm *SubscriptionManager) SubscribeEvents(ctx context.Context, eventTypes []string, filter EventFilter) (<-chan events.Event, error) {
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("at least one event type is required")
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	
@@ -60,32 +73,34 @@ func (sm *SubscriptionManager) Subscribe(ctx context.Context, eventType string,
 	
 	// Создаем подписку
 	subscription := &Subscription{
-		ID:        subscriptionID,
-		EventType: eventType,
-		Channel:   channel,
-		Filter:    filter,
-		Context:   subCtx,
-		Cancel:    cancel,
-		CreatedAt: time.Now(),
+		ID:         subscriptionID,
+		EventType:  eventTypes[0],
+		EventTypes: eventTypes,
+		Channel:    channel,
+		Filter:     filter,
+		Context:    subCtx,
+		Cancel:     cancel,
+		CreatedAt:  time.Now(),
 	}
 	
 	// Регистрируем подписку
 	sm.subscriptions[subscriptionID] = subscription
 	
-	// Подписываемся на EventBus
-	handler := &subscriptionEventHandler{
-		subscription: subscription,
-		manager:     sm,
-	}
-	
-	// Сохраняем handler в subscription для последующей отписки
-	subscription.handler = handler
-	
-	if err := sm.eventBus.Subscribe(eventType, handler); err != nil {
-		cancel()
-		close(channel)
-		delete(sm.subscriptions, subscriptionID)
-		return nil, fmt.Errorf("failed to subscribe to event bus: %w", err)
+	// Подписываемся на EventBus отдельным handler для каждого типа события
+	for _, eventType := range eventTypes {
+		handler := &subscriptionEventHandler{
+			subscription: subscription,
+			manager:      sm,
+			eventType:    eventType,
+		}
+		if err := sm.eventBus.Subscribe(eventType, handler); err != nil {
+			sm.unsubscribeHandlers(subscription)
+			subscription.close()
+			delete(sm.subscriptions, subscriptionID)
+			return nil, fmt.Errorf("failed to subscribe to event bus: %w", err)
+		}
+		// Сохраняем handler в subscription для последующей отписки
+		subscription.handlers = append(subscription.handlers, handler)
 	}
 	
 	// Запускаем goroutine для отслеживания отмены контекста
@@ -101,6 +116,7 @@ func (sm *SubscriptionManager) Subscribe(ctx context.Context, eventType string,
 type subscriptionEventHandler struct {
 	subscription *Subscription
 	manager      *SubscriptionManager
+	eventType    string
 }
 
 // Handle обрабатывает событие
@@ -109,6 +125,13 @@ func (h *subscriptionEventHandler) Handle(ctx context.Context, event events.Even
 	if h.subscription.Filter != nil && !h.subscription.Filter.Match(event) {
 		return nil
 	}
+
+	// Канал не должен закрыться во время отправки
+	h.subscription.mu.RLock()
+	defer h.subscription.mu.RUnlock()
+	if h.subscription.closed {
+		return nil
+	}
 	
 	// Отправляем событие в канал (неблокирующая отправка)
 	select {
@@ -126,7 +149,26 @@ func (h *subscriptionEventHandler) Handle(ctx context.Context, event events.Even
 
 // EventType возвращает тип события
 func (h *subscriptionEventHandler) EventType() string {
-	return h.subscription.EventType
+	return h.eventType
+}
+
+// close отменяет контекст подписки и закрывает канал (идемпотентно)
+func (s *Subscription) close() {
+	s.Cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.Channel)
+	}
+}
+
+// unsubscribeHandlers отписывает handlers подписки от EventBus
+func (sm *SubscriptionManager) unsubscribeHandlers(subscription *Subscription) {
+	for _, handler := range subscription.handlers {
+		_ = sm.eventBus.Unsubscribe(handler.eventType, handler)
+	}
 }
 
 // Unsubscribe отменяет подписку
@@ -139,16 +181,11 @@ func (sm *SubscriptionManager) Unsubscribe(subscriptionID string) error {
 		return fmt.Errorf("subscription %s not found", subscriptionID)
 	}
 	
-	// Отменяем контекст
-	subscription.Cancel()
+	// Отписываемся от EventBus используя сохраненные handlers
+	sm.unsubscribeHandlers(subscription)
 	
-	// Закрываем канал
-	close(subscription.Channel)
-	
-	// Отписываемся от EventBus используя сохраненный handler
-	if subscription.handler != nil {
-		_ = sm.eventBus.Unsubscribe(subscription.EventType, subscription.handler)
-	}
+	// Отменяем контекст и закрываем канал
+	subscription.close()
 	
 	// Удаляем из реестра
 	delete(sm.subscriptions, subscriptionID)
@@ -173,8 +210,8 @@ func (sm *SubscriptionManager) Close() error {
 	
 	for subscriptionID := range sm.subscriptions {
 		subscription := sm.subscriptions[subscriptionID]
-		subscription.Cancel()
-		close(subscription.Channel)
+		sm.unsubscribeHandlers(subscription)
+		subscription.close()
 		delete(sm.subscriptions, subscriptionID)
 	}
 	
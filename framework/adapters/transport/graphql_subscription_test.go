@@ -180,6 +180,82 @@ func TestSubscriptionManager_Close(t *testing.T) {
 	manager.mu.RUnlock()
 }
 
+func TestSubscriptionManager_SubscribeEvents(t *testing.T) {
+	eventBus := events.NewInMemoryEventBus()
+	manager := NewSubscriptionManager(eventBus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channel, err := manager.SubscribeEvents(ctx, []string{"saga.started", "saga.completed"}, &AggregateIDFilter{AggregateID: "saga-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, eventBus.Publish(ctx, events.NewBaseEvent("saga.started", "saga-1")))
+	require.NoError(t, eventBus.Publish(ctx, events.NewBaseEvent("saga.started", "saga-2")))
+	require.NoError(t, eventBus.Publish(ctx, events.NewBaseEvent("saga.completed", "saga-1")))
+
+	var received []string
+	for len(received) < 2 {
+		select {
+		case event := <-channel:
+			assert.Equal(t, "saga-1", event.AggregateID())
+			received = append(received, event.EventType())
+		case <-time.After(1 * time.Second):
+			t.Fatalf("expected 2 events, got %v", received)
+		}
+	}
+	assert.ElementsMatch(t, []string{"saga.started", "saga.completed"}, received)
+
+	// После отмены контекста handlers отписываются от всех типов событий
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+	manager.mu.RLock()
+	assert.Equal(t, 0, len(manager.subscriptions))
+	manager.mu.RUnlock()
+	require.NoError(t, eventBus.Publish(context.Background(), events.NewBaseEvent("saga.completed", "saga-1")))
+}
+
+func TestSubscriptionResolver_SubscribeField(t *testing.T) {
+	eventBus := events.NewInMemoryEventBus()
+	resolver := NewSubscriptionResolver(NewBaseResolver(nil, nil, eventBus), NewSubscriptionManager(eventBus))
+	resolver.MapField("orderUpdated", "order.created", "order.shipped")
+
+	assert.Equal(t, []string{"order.created", "order.shipped"}, resolver.EventTypes("orderUpdated"))
+	assert.Equal(t, []string{"productCreated"}, resolver.EventTypes("productCreated"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	correlationID := "corr-1"
+	channel, err := resolver.SubscribeField(ctx, "orderUpdated", map[string]interface{}{
+		"aggregateId":   "order-1",
+		"correlationId": &correlationID,
+	})
+	require.NoError(t, err)
+
+	other := events.NewBaseEvent("order.created", "order-1")
+	other.WithCorrelationID("corr-2")
+	require.NoError(t, eventBus.Publish(ctx, other))
+
+	expected := events.NewBaseEvent("order.shipped", "order-1")
+	expected.WithCorrelationID(correlationID)
+	require.NoError(t, eventBus.Publish(ctx, expected))
+
+	select {
+	case event := <-channel:
+		assert.Equal(t, "order.shipped", event.EventType())
+	case <-time.After(1 * time.Second):
+		t.Fatal("event not received")
+	}
+}
+
+func TestSubscriptionFilterFromArgs(t *testing.T) {
+	assert.Nil(t, SubscriptionFilterFromArgs(nil))
+	assert.Nil(t, SubscriptionFilterFromArgs(map[string]interface{}{"limit": 10}))
+
+	filter := SubscriptionFilterFromArgs(map[string]interface{}{"aggregate_id": "aggregate-1"})
+	assert.True(t, filter.Match(events.NewBaseEvent("test.event", "aggregate-1")))
+	assert.False(t, filter.Match(events.NewBaseEvent("test.event", "aggregate-2")))
+}
+
 func TestCorrelationIDFilter(t *testing.T) {
 	filter := &CorrelationIDFilter{CorrelationID: "corr-123"}
 
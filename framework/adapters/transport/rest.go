@@ -3,6 +3,7 @@ package transport
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -38,6 +39,19 @@ type RESTAdapter struct {
 	metrics    *metrics.Metrics
 	running    bool
 	server     *http.Server
+
+	consistencyWaiter  ConsistencyWaiter
+	consistencyTimeout time.Duration
+}
+
+// ConsistencyStaleHeader заголовок ответа на запрос, выполненный до того, как проекции
+// достигли токена согласованности (ожидание превысило таймаут)
+const ConsistencyStaleHeader = "X-Consistency-Stale"
+
+// ConsistencyWaiter ожидает, пока проекции достигнут токена согласованности из контекста
+// (реализуется eventsourcing.ProjectionManager)
+type ConsistencyWaiter interface {
+	WaitForContextToken(ctx context.Context, timeout time.Duration, projectionNames ...string) error
 }
 
 // NewRESTAdapter создает новый REST адаптер
@@ -61,6 +75,15 @@ func NewRESTAdapter(config RESTConfig, commandBus transport.CommandBus, queryBus
 	return adapter, nil
 }
 
+// WithConsistencyWaiter включает read-your-writes для запросов: запрос с заголовком
+// X-Consistency-Token ожидает проекции не дольше timeout, после чего выполняется
+// с заголовком ответа X-Consistency-Stale
+func (r *RESTAdapter) WithConsistencyWaiter(waiter ConsistencyWaiter, timeout time.Duration) *RESTAdapter {
+	r.consistencyWaiter = waiter
+	r.consistencyTimeout = timeout
+	return r
+}
+
 // Start запускает адаптер (реализация core.Lifecycle)
 func (r *RESTAdapter) Start(ctx context.Context) error {
 	r.running = true
@@ -116,7 +139,8 @@ func (r *RESTAdapter) Type() core.ComponentType {
 // эти функции должны быть реализованы на уровне приложения.
 func (r *RESTAdapter) RegisterCommand(method, path string, command transport.Command) {
 	r.router.Handle(method, path, func(c *gin.Context) {
-		ctx := c.Request.Context()
+		// Позиция сохраненных командой событий возвращается клиенту как токен согласованности
+		ctx, tracker := core.TrackConsistency(c.Request.Context())
 		start := time.Now()
 
 		if r.metrics != nil {
@@ -145,6 +169,11 @@ func (r *RESTAdapter) RegisterCommand(method, path string, command transport.Com
 		if r.metrics != nil {
 			r.metrics.RecordCommand(ctx, command.CommandName(), time.Since(start), true)
 		}
+		if token := tracker.Token(); token > 0 {
+			c.Header(core.ConsistencyTokenHeader, token.String())
+			c.JSON(http.StatusOK, gin.H{"message": "success", "consistency_token": token.String()})
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
 }
@@ -152,6 +181,7 @@ func (r *RESTAdapter) RegisterCommand(method, path string, command transport.Com
 // RegisterQuery регистрирует query handler
 // NOTE: Текущая реализация поддерживает только JSON body binding.
 // Query parameters и form data не поддерживаются.
+// При Accept: text/csv или application/x-ndjson результат выгружается потоково.
 func (r *RESTAdapter) RegisterQuery(method, path string, query transport.Query) {
 	r.router.Handle(method, path, func(c *gin.Context) {
 		ctx := c.Request.Context()
@@ -171,6 +201,40 @@ func (r *RESTAdapter) RegisterQuery(method, path string, query transport.Query)
 			return
 		}
 
+		// Read-your-writes: ожидание проекций до позиции токена согласованности
+		if header := c.GetHeader(core.ConsistencyTokenHeader); header != "" {
+			token, err := core.ParseConsistencyToken(header)
+			if err != nil {
+				if r.metrics != nil {
+					r.metrics.RecordQuery(ctx, query.QueryName(), time.Since(start), false)
+				}
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			ctx = core.WithConsistencyToken(ctx, token)
+			if r.consistencyWaiter != nil {
+				if err := r.consistencyWaiter.WaitForContextToken(ctx, r.consistencyTimeout); err != nil {
+					if !errors.Is(err, core.ErrConsistencyTimeout) {
+						if r.metrics != nil {
+							r.metrics.RecordQuery(ctx, query.QueryName(), time.Since(start), false)
+						}
+						c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+						return
+					}
+					c.Header(ConsistencyStaleHeader, "true")
+				}
+			}
+		}
+
+		// Выгрузка в CSV/NDJSON по заголовку Accept
+		if format := transport.NegotiateExportFormat(c.GetHeader("Accept")); format.IsExport() {
+			err := r.exportQuery(ctx, c, query, format)
+			if r.metrics != nil {
+				r.metrics.RecordQuery(ctx, query.QueryName(), time.Since(start), err == nil)
+			}
+			return
+		}
+
 		// Отправка запроса
 		result, err := r.queryBus.Ask(ctx, query)
 		if err != nil {
@@ -188,3 +252,26 @@ func (r *RESTAdapter) RegisterQuery(method, path string, query transport.Query)
 	})
 }
 
+// exportQuery потоково выгружает результат запроса (transport.ExportQuery): строки
+// отправляются клиенту по мере чтения. Ошибка до первой строки возвращается JSON
+// ответом, после начала выгрузки - только записывается в контекст gin.
+func (r *RESTAdapter) exportQuery(ctx context.Context, c *gin.Context, query transport.Query, format transport.ExportFormat) error {
+	writer, err := transport.NewExportWriter(c.Writer, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	if err := transport.ExportQuery(ctx, r.queryBus, query, writer); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return err
+		}
+		_ = c.Error(err)
+		return err
+	}
+	return nil
+}
+
@@ -0,0 +1,241 @@
+package admin
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akriventsev/potter/framework/eventsourcing"
+)
+
+// AggregateAdmin перечисление и верификация потоков агрегатов хранилища событий
+type AggregateAdmin struct {
+	store    eventsourcing.EventStore
+	verifier eventsourcing.StreamVerifier
+	out      io.Writer
+}
+
+// NewAggregateAdmin создает новый AggregateAdmin
+func NewAggregateAdmin(store eventsourcing.EventStore, out io.Writer) *AggregateAdmin {
+	return &AggregateAdmin{
+		store: store,
+		out:   out,
+	}
+}
+
+// WithVerifier устанавливает верификатор для команды aggregates verify.
+// Логика Apply реализована в коде сервиса, поэтому verify доступна только
+// при встраивании AggregateAdmin в сервис.
+func (a *AggregateAdmin) WithVerifier(verifier eventsourcing.StreamVerifier) *AggregateAdmin {
+	a.verifier = verifier
+	return a
+}
+
+// Run выполняет команду группы aggregates.
+// Поддерживаемые команды:
+//
+//	aggregates list [--type T] [--created-from RFC3339] [--created-to RFC3339] [--after ID] [--limit N] [--all]
+//	aggregates verify [<id>...] [--type T] [--created-from RFC3339] [--created-to RFC3339] [--after ID] [--verbose]
+func (a *AggregateAdmin) Run(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "aggregates" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("aggregates subcommand is required: list, verify")
+	}
+
+	switch args[0] {
+	case "list":
+		return a.runList(ctx, args[1:])
+	case "verify":
+		return a.runVerify(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown aggregates subcommand: %s", args[0])
+	}
+}
+
+// runList разбирает аргументы команды list
+func (a *AggregateAdmin) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("aggregates list", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	aggregateType := fs.String("type", "", "Aggregate type to list (default: all types)")
+	createdFrom := fs.String("created-from", "", "List aggregates created at or after the RFC3339 timestamp")
+	createdTo := fs.String("created-to", "", "List aggregates created before the RFC3339 timestamp")
+	after := fs.String("after", "", "Cursor: list aggregates with ID greater than this one")
+	limit := fs.Int("limit", eventsourcing.DefaultAggregatePageSize, "Page size")
+	all := fs.Bool("all", false, "Follow cursors and list all matching aggregates")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter := eventsourcing.AggregateFilter{
+		AggregateType: *aggregateType,
+		After:         *after,
+		Limit:         *limit,
+	}
+	var err error
+	if filter.CreatedFrom, err = parseOptionalTime(*createdFrom); err != nil {
+		return err
+	}
+	if filter.CreatedTo, err = parseOptionalTime(*createdTo); err != nil {
+		return err
+	}
+
+	return a.List(ctx, filter, *all)
+}
+
+// List выводит агрегаты, соответствующие фильтру. Если all == false, выводится одна страница
+// и курсор следующей; иначе страницы читаются до конца.
+func (a *AggregateAdmin) List(ctx context.Context, filter eventsourcing.AggregateFilter, all bool) error {
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGGREGATE ID\tTYPE\tVERSION\tEVENTS\tCREATED\tUPDATED")
+
+	total := 0
+	for {
+		page, err := eventsourcing.ListAggregates(ctx, a.store, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list aggregates: %w", err)
+		}
+
+		for _, aggregate := range page.Aggregates {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n",
+				aggregate.AggregateID,
+				aggregate.AggregateType,
+				aggregate.Version,
+				aggregate.EventCount,
+				aggregate.CreatedAt.Format("2006-01-02 15:04:05"),
+				aggregate.UpdatedAt.Format("2006-01-02 15:04:05"),
+			)
+		}
+		total += len(page.Aggregates)
+
+		if page.NextCursor == "" {
+			break
+		}
+		if !all {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.out, "More aggregates available: --after %s\n", page.NextCursor)
+			return nil
+		}
+		filter.After = page.NextCursor
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "%d aggregates\n", total)
+	return nil
+}
+
+// runVerify разбирает аргументы команды verify
+func (a *AggregateAdmin) runVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("aggregates verify", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	aggregateType := fs.String("type", "", "Aggregate type to verify (default: all types)")
+	createdFrom := fs.String("created-from", "", "Verify aggregates created at or after the RFC3339 timestamp")
+	createdTo := fs.String("created-to", "", "Verify aggregates created before the RFC3339 timestamp")
+	after := fs.String("after", "", "Cursor: verify aggregates with ID greater than this one")
+	verbose := fs.Bool("verbose", false, "Print verified aggregates too")
+
+	// ID агрегатов указываются перед флагами
+	var ids []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ids = append(ids, args[0])
+		args = args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ids = append(ids, fs.Args()...)
+
+	if len(ids) > 0 {
+		return a.VerifyIDs(ctx, ids, *verbose)
+	}
+
+	filter := eventsourcing.AggregateFilter{
+		AggregateType: *aggregateType,
+		After:         *after,
+	}
+	var err error
+	if filter.CreatedFrom, err = parseOptionalTime(*createdFrom); err != nil {
+		return err
+	}
+	if filter.CreatedTo, err = parseOptionalTime(*createdTo); err != nil {
+		return err
+	}
+	return a.Verify(ctx, filter, *verbose)
+}
+
+// Verify верифицирует агрегаты, соответствующие фильтру, и выводит расхождения.
+// Возвращает ошибку, если найдены расхождения или replay завершился ошибкой.
+func (a *AggregateAdmin) Verify(ctx context.Context, filter eventsourcing.AggregateFilter, verbose bool) error {
+	if a.verifier == nil {
+		return errVerifierRequired
+	}
+	report, err := eventsourcing.VerifyAggregates(ctx, a.store, a.verifier, filter)
+	if err != nil {
+		return fmt.Errorf("failed to verify aggregates: %w", err)
+	}
+	return a.printVerification(report, verbose)
+}
+
+// VerifyIDs верифицирует указанные агрегаты и выводит расхождения
+func (a *AggregateAdmin) VerifyIDs(ctx context.Context, ids []string, verbose bool) error {
+	if a.verifier == nil {
+		return errVerifierRequired
+	}
+	report := &eventsourcing.VerificationReport{}
+	for _, id := range ids {
+		verification, err := a.verifier.Verify(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to verify aggregate %s: %w", id, err)
+		}
+		report.Add(*verification)
+	}
+	return a.printVerification(report, verbose)
+}
+
+// errVerifierRequired команда verify вызвана без верификатора
+var errVerifierRequired = fmt.Errorf("aggregates verify replays aggregates through service Apply logic: " +
+	"embed framework/admin AggregateAdmin with a verifier into the service binary")
+
+// printVerification выводит отчет верификации
+func (a *AggregateAdmin) printVerification(report *eventsourcing.VerificationReport, verbose bool) error {
+	for _, verification := range report.Aggregates {
+		switch {
+		case verification.Error != nil:
+			fmt.Fprintf(a.out, "FAILED   %s: %v\n", verification.AggregateID, verification.Error)
+		case verification.Diverged():
+			fmt.Fprintf(a.out, "DIVERGED %s (version %d)\n", verification.AggregateID, verification.Version)
+			for _, divergence := range verification.Divergences {
+				fmt.Fprintf(a.out, "  %s\n", divergence)
+			}
+		case verbose:
+			fmt.Fprintf(a.out, "OK       %s (version %d)\n", verification.AggregateID, verification.Version)
+		}
+	}
+
+	fmt.Fprintf(a.out, "%d verified, %d diverged, %d failed\n", report.Verified, report.Diverged, report.Failed)
+	if report.Diverged > 0 || report.Failed > 0 {
+		return fmt.Errorf("verification found %d diverged and %d failed aggregates", report.Diverged, report.Failed)
+	}
+	return nil
+}
+
+// parseOptionalTime разбирает время в формате RFC3339 (пустая строка - zero time)
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %s (expected RFC3339): %w", value, err)
+	}
+	return parsed, nil
+}
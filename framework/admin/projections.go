@@ -0,0 +1,286 @@
+// Package admin предоставляет административные команды для эксплуатации сервисов Potter.
+//
+// Команды могут быть встроены в бинарник сервиса (где зарегистрированы проекции):
+//
+//	if len(os.Args) > 1 && os.Args[1] == "projection" {
+//		if err := admin.Run(ctx, projectionManager, os.Args[1:], os.Stdout); err != nil {
+//			log.Fatal(err)
+//		}
+//		return
+//	}
+//
+// или использованы через CLI potter-admin, который работает только с checkpoint store.
+//
+// Replay событий (ReplayAdmin) аналогично встраивается в сервис для replay в проекции
+// или используется через CLI potter-replay, который выводит события в формате NDJSON.
+//
+// Перечисление потоков агрегатов (AggregateAdmin, команда aggregates list) доступно
+// в CLI potter-admin и может быть встроено в сервис аналогично. Верификация агрегатов
+// (aggregates verify) выполняет replay через логику Apply сервиса и доступна только
+// при встраивании AggregateAdmin с верификатором.
+//
+// Саги (SagaAdmin) просматриваются и принудительно завершаются через CLI potter-saga;
+// resume, compensate и retry-stuck выполняют шаги и доступны только при встраивании
+// SagaAdmin с оркестратором в сервис.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/akriventsev/potter/framework/eventsourcing"
+)
+
+// ProjectionAdmin административные операции над проекциями через ProjectionManager
+type ProjectionAdmin struct {
+	manager *eventsourcing.ProjectionManager
+	out     io.Writer
+}
+
+// NewProjectionAdmin создает новый ProjectionAdmin
+func NewProjectionAdmin(manager *eventsourcing.ProjectionManager, out io.Writer) *ProjectionAdmin {
+	return &ProjectionAdmin{
+		manager: manager,
+		out:     out,
+	}
+}
+
+// Run выполняет команду группы projection.
+// Поддерживаемые команды:
+//
+//	projection rebuild <name> [--from-position N] [--shadow]
+//	projection backfill <name> --file records.ndjson [--source S] [--checkpoint N]
+//	projection status
+func Run(ctx context.Context, manager *eventsourcing.ProjectionManager, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "projection" {
+		return fmt.Errorf("unknown command group, expected: projection")
+	}
+	return NewProjectionAdmin(manager, out).Run(ctx, args[1:])
+}
+
+// Run выполняет подкоманду projection (rebuild, backfill, status)
+func (a *ProjectionAdmin) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("projection subcommand is required: rebuild, backfill, status")
+	}
+
+	switch args[0] {
+	case "rebuild":
+		return a.runRebuild(ctx, args[1:])
+	case "backfill":
+		return a.runBackfill(ctx, args[1:])
+	case "status":
+		return a.Status(ctx)
+	default:
+		return fmt.Errorf("unknown projection subcommand: %s", args[0])
+	}
+}
+
+// runRebuild разбирает аргументы команды rebuild
+func (a *ProjectionAdmin) runRebuild(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("projection rebuild", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	fromPosition := fs.Int64("from-position", 0, "Global position to start rebuild from (0 - full rebuild, requires registered projection)")
+	shadow := fs.Bool("shadow", false, "Rebuild into a shadow projection and promote it on completion")
+
+	// Имя проекции может быть указано до или после флагов
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+		args = args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		return fmt.Errorf("projection name is required")
+	}
+
+	return a.Rebuild(ctx, name, eventsourcing.RebuildOptions{
+		FromPosition: *fromPosition,
+		Shadow:       *shadow,
+	})
+}
+
+// Rebuild пересоздает проекцию. Если проекция не зарегистрирована в manager
+// (например, при запуске из potter-admin), выполняется только сброс checkpoint,
+// и сервис продолжит обработку с новой позиции после перезапуска. Read model при
+// этом не очищается, поэтому полный rebuild (с позиции 0) без зарегистрированной
+// проекции отклоняется: повторное применение всех событий поверх старых данных
+// испортило бы read model.
+func (a *ProjectionAdmin) Rebuild(ctx context.Context, name string, opts eventsourcing.RebuildOptions) error {
+	if !a.manager.IsRegistered(name) {
+		if opts.Shadow {
+			return fmt.Errorf("shadow rebuild requires projection %s to be registered in the service binary", name)
+		}
+		if opts.FromPosition <= 0 {
+			return fmt.Errorf("full rebuild requires projection %s to be registered in the service binary to reset its read model; use --from-position N to move only the checkpoint", name)
+		}
+		if err := a.manager.ResetCheckpoint(ctx, name, opts.FromPosition); err != nil {
+			return fmt.Errorf("failed to reset checkpoint: %w", err)
+		}
+		fmt.Fprintf(a.out, "Checkpoint of projection %s reset to position %d\n", name, opts.FromPosition)
+		fmt.Fprintln(a.out, "Projection is not registered here: read model is not reset, restart the service to replay events")
+		return nil
+	}
+
+	mode := "in-place"
+	if opts.Shadow {
+		mode = "shadow"
+	}
+	fmt.Fprintf(a.out, "Rebuilding projection %s from position %d (%s)...\n", name, opts.FromPosition, mode)
+
+	if err := a.manager.RebuildWithOptions(ctx, name, opts); err != nil {
+		return fmt.Errorf("rebuild failed: %w", err)
+	}
+
+	fmt.Fprintf(a.out, "Projection %s rebuilt successfully\n", name)
+	return nil
+}
+
+// runBackfill разбирает аргументы команды backfill
+func (a *ProjectionAdmin) runBackfill(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("projection backfill", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	file := fs.String("file", "", "NDJSON file with records: {\"key\": ..., \"source_id\": ..., \"data\": {...}}")
+	source := fs.String("source", "", "Name of the legacy system the records are imported from")
+	checkpoint := fs.Int64("checkpoint", 0, "Global position covered by the imported data (0 - keep checkpoint)")
+
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+		args = args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		return fmt.Errorf("projection name is required")
+	}
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *file, err)
+	}
+	defer f.Close()
+
+	return a.Backfill(ctx, name, f, eventsourcing.BackfillOptions{
+		Source:             *source,
+		CheckpointPosition: *checkpoint,
+	})
+}
+
+// backfillLine строка NDJSON файла backfill
+type backfillLine struct {
+	Key      string                 `json:"key"`
+	Source   string                 `json:"source"`
+	SourceID string                 `json:"source_id"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// Backfill загружает записи из NDJSON в read model проекции в обход потока событий.
+// Проекция должна быть зарегистрирована в manager и реализовывать eventsourcing.BackfillProjection.
+func (a *ProjectionAdmin) Backfill(ctx context.Context, name string, r io.Reader, opts eventsourcing.BackfillOptions) error {
+	if !a.manager.IsRegistered(name) {
+		return fmt.Errorf("backfill requires projection %s to be registered in the service binary", name)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := make(chan eventsourcing.BackfillRecord)
+	decodeErr := make(chan error, 1)
+	// Ошибка разбора отменяет backfill, чтобы checkpoint не был сдвинут по неполным данным
+	go func() {
+		decoder := json.NewDecoder(r)
+		for line := 1; ; line++ {
+			var item backfillLine
+			if err := decoder.Decode(&item); err != nil {
+				if errors.Is(err, io.EOF) {
+					close(records)
+					decodeErr <- nil
+				} else {
+					decodeErr <- fmt.Errorf("invalid record %d: %w", line, err)
+					cancel()
+				}
+				return
+			}
+			record := eventsourcing.BackfillRecord{
+				Key:  item.Key,
+				Data: item.Data,
+				Provenance: eventsourcing.Provenance{
+					Source:   item.Source,
+					SourceID: item.SourceID,
+				},
+			}
+			select {
+			case records <- record:
+			case <-ctx.Done():
+				decodeErr <- nil
+				return
+			}
+		}
+	}()
+
+	opts.OnProgress = func(progress eventsourcing.BackfillProgress) {
+		fmt.Fprintf(a.out, "Backfilled %d records into projection %s\n", progress.RecordsImported, name)
+	}
+	progress, err := a.manager.Backfill(ctx, name, records, opts)
+	cancel()
+	if decodeErr := <-decodeErr; decodeErr != nil {
+		return fmt.Errorf("backfill stopped after %d records: %w", progress.RecordsImported, decodeErr)
+	}
+	if err != nil {
+		return fmt.Errorf("backfill failed after %d records: %w", progress.RecordsImported, err)
+	}
+
+	fmt.Fprintf(a.out, "Projection %s backfilled successfully (backfill id %s)\n", name, progress.BackfillID)
+	return nil
+}
+
+// Status выводит статусы проекций
+func (a *ProjectionAdmin) Status(ctx context.Context) error {
+	statuses, err := a.manager.ListStatuses(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(statuses) == 0 {
+		fmt.Fprintln(a.out, "No projections found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATE\tPOSITION\tPROCESSED\tERRORS\tLAST PROCESSED")
+	for _, status := range statuses {
+		lastProcessed := "-"
+		if !status.LastProcessedAt.IsZero() {
+			lastProcessed = status.LastProcessedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n",
+			status.Name,
+			status.State,
+			status.LastProcessedPosition,
+			status.EventsProcessed,
+			status.ErrorCount,
+			lastProcessed,
+		)
+	}
+	return w.Flush()
+}
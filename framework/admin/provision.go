@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/akriventsev/potter/framework/topology"
+)
+
+// namedProvisioner провижинер брокера с именем для вывода
+type namedProvisioner struct {
+	broker      string
+	provisioner topology.Provisioner
+}
+
+// ProvisionAdmin провижининг топологии message bus (streams, consumers, topics) по спецификации
+type ProvisionAdmin struct {
+	provisioners []namedProvisioner
+	out          io.Writer
+}
+
+// NewProvisionAdmin создает новый ProvisionAdmin
+func NewProvisionAdmin(out io.Writer) *ProvisionAdmin {
+	return &ProvisionAdmin{out: out}
+}
+
+// WithProvisioner добавляет провижинер брокера (например nats или kafka)
+func (a *ProvisionAdmin) WithProvisioner(broker string, provisioner topology.Provisioner) *ProvisionAdmin {
+	a.provisioners = append(a.provisioners, namedProvisioner{broker: broker, provisioner: provisioner})
+	return a
+}
+
+// Run выполняет команду provision:
+//
+//	provision [--spec deploy/topology.yaml] [--dry-run]
+func (a *ProvisionAdmin) Run(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "provision" {
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("provision", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	specPath := fs.String("spec", "deploy/topology.yaml", "Topology spec generated by potter-gen")
+	dryRun := fs.Bool("dry-run", false, "Show planned changes without applying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	spec, err := topology.LoadSpec(*specPath)
+	if err != nil {
+		return err
+	}
+	return a.Provision(ctx, spec, topology.ProvisionOptions{DryRun: *dryRun})
+}
+
+// Provision применяет спецификацию всеми провижинерами и выводит изменения.
+// Возвращает ошибку, если есть конфликты, требующие ручного вмешательства.
+func (a *ProvisionAdmin) Provision(ctx context.Context, spec *topology.Spec, opts topology.ProvisionOptions) error {
+	if len(a.provisioners) == 0 {
+		return fmt.Errorf("no message bus configured: use --nats-url or --kafka-brokers")
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BROKER\tKIND\tNAME\tACTION\tDETAILS")
+
+	conflicts := 0
+	for _, named := range a.provisioners {
+		result, err := named.provisioner.Provision(ctx, spec, opts)
+		if result != nil {
+			for _, change := range result.Changes {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", named.broker, change.Kind, change.Name, change.Action, change.Details)
+				if change.Action == topology.ActionConflict {
+					conflicts++
+				}
+			}
+		}
+		if err != nil {
+			_ = w.Flush()
+			return fmt.Errorf("failed to provision %s: %w", named.broker, err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if opts.DryRun {
+		fmt.Fprintln(a.out, "Dry run: no changes applied")
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("%d conflicts require manual changes", conflicts)
+	}
+	return nil
+}
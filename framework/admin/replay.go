@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/akriventsev/potter/framework/eventsourcing"
+)
+
+// ReplayAdmin административный replay событий через ReplayService.
+// Без --projection события выводятся в out в формате NDJSON (например, для анализа
+// или загрузки во внешнюю систему); с --projection применяются к зарегистрированной проекции.
+type ReplayAdmin struct {
+	service     *eventsourcing.ReplayService
+	projections map[string]eventsourcing.Projection
+	out         io.Writer
+	progressOut io.Writer
+}
+
+// NewReplayAdmin создает новый ReplayAdmin
+func NewReplayAdmin(service *eventsourcing.ReplayService, out io.Writer) *ReplayAdmin {
+	return &ReplayAdmin{
+		service:     service,
+		projections: make(map[string]eventsourcing.Projection),
+		out:         out,
+		progressOut: out,
+	}
+}
+
+// WithProjection регистрирует проекцию, доступную для replay через --projection
+func (a *ReplayAdmin) WithProjection(projection eventsourcing.Projection) *ReplayAdmin {
+	a.projections[projection.Name()] = projection
+	return a
+}
+
+// WithProgressOutput устанавливает вывод сообщений о прогрессе (по умолчанию out).
+// Полезно, когда out используется для NDJSON событий.
+func (a *ReplayAdmin) WithProgressOutput(w io.Writer) *ReplayAdmin {
+	a.progressOut = w
+	return a
+}
+
+// Run выполняет команду replay.
+// Поддерживаемые команды:
+//
+//	replay all [flags]
+//	replay aggregate <id> [flags]
+//	replay type <event_type> [<event_type>...] [flags]
+//	replay since <RFC3339> [flags]
+//
+// Флаги: --projection, --rate, --progress, --checkpoint, --continue-on-error
+func (a *ReplayAdmin) Run(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "replay" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("replay mode is required: all, aggregate, type, since")
+	}
+
+	fs := flag.NewFlagSet("replay "+args[0], flag.ContinueOnError)
+	fs.SetOutput(a.progressOut)
+	projectionName := fs.String("projection", "", "Registered projection to replay events into (default: print events as NDJSON)")
+	rate := fs.Float64("rate", 0, "Maximum events per second (0 - unlimited)")
+	progressInterval := fs.Int("progress", 1000, "Report progress every N events")
+	checkpoint := fs.String("checkpoint", "", "Checkpoint name to resume an interrupted replay from")
+	continueOnError := fs.Bool("continue-on-error", false, "Count failed events instead of stopping the replay")
+
+	// Позиционные аргументы режима могут быть указаны до или после флагов
+	mode := args[0]
+	var positional []string
+	rest := args[1:]
+	for len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		positional = append(positional, rest[0])
+		rest = rest[1:]
+	}
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	positional = append(positional, fs.Args()...)
+
+	var filter eventsourcing.ReplayFilter
+	switch mode {
+	case "all":
+	case "aggregate":
+		if len(positional) != 1 {
+			return fmt.Errorf("replay aggregate requires aggregate id")
+		}
+		filter.AggregateID = positional[0]
+	case "type":
+		if len(positional) == 0 {
+			return fmt.Errorf("replay type requires at least one event type")
+		}
+		filter.EventTypes = positional
+	case "since":
+		if len(positional) != 1 {
+			return fmt.Errorf("replay since requires timestamp")
+		}
+		since, err := time.Parse(time.RFC3339, positional[0])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %s (expected RFC3339): %w", positional[0], err)
+		}
+		filter.Since = since
+	default:
+		return fmt.Errorf("unknown replay mode: %s", mode)
+	}
+
+	opts := eventsourcing.ReplayServiceOptions{
+		EventsPerSecond:  *rate,
+		ProgressInterval: *progressInterval,
+		StopOnError:      !*continueOnError,
+		CheckpointName:   *checkpoint,
+	}
+
+	if *projectionName == "" {
+		return a.Dump(ctx, filter, opts)
+	}
+	return a.ReplayProjection(ctx, *projectionName, filter, opts)
+}
+
+// ReplayProjection идемпотентно воспроизводит события в зарегистрированную проекцию
+func (a *ReplayAdmin) ReplayProjection(ctx context.Context, name string, filter eventsourcing.ReplayFilter, opts eventsourcing.ReplayServiceOptions) error {
+	projection, ok := a.projections[name]
+	if !ok {
+		return fmt.Errorf("replay requires projection %s to be registered in the service binary", name)
+	}
+
+	opts.OnProgress = a.reportProgress
+	fmt.Fprintf(a.progressOut, "Replaying events into projection %s...\n", name)
+	progress, err := a.service.ReplayProjection(ctx, projection, filter, opts)
+	if err != nil {
+		return fmt.Errorf("replay stopped at position %d: %w", progress.CurrentPosition, err)
+	}
+	fmt.Fprintf(a.progressOut, "Projection %s replayed successfully\n", name)
+	return nil
+}
+
+// replayedEvent строка NDJSON вывода replay
+type replayedEvent struct {
+	Position      int64                  `json:"position"`
+	ID            string                 `json:"id"`
+	AggregateID   string                 `json:"aggregate_id"`
+	AggregateType string                 `json:"aggregate_type,omitempty"`
+	EventType     string                 `json:"event_type"`
+	Version       int64                  `json:"version"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Data          interface{}            `json:"data"`
+}
+
+// Dump выводит события, соответствующие фильтру, в out в формате NDJSON
+func (a *ReplayAdmin) Dump(ctx context.Context, filter eventsourcing.ReplayFilter, opts eventsourcing.ReplayServiceOptions) error {
+	encoder := json.NewEncoder(a.out)
+	handler := eventsourcing.ReplayHandlerFunc(func(ctx context.Context, event eventsourcing.StoredEvent) error {
+		return encoder.Encode(replayedEvent{
+			Position:      event.Position,
+			ID:            event.ID,
+			AggregateID:   event.AggregateID,
+			AggregateType: event.AggregateType,
+			EventType:     event.EventType,
+			Version:       event.Version,
+			OccurredAt:    event.OccurredAt,
+			Metadata:      event.Metadata,
+			Data:          event.EventData,
+		})
+	})
+
+	opts.OnProgress = a.reportProgress
+	progress, err := a.service.Replay(ctx, filter, handler, opts)
+	if err != nil {
+		return fmt.Errorf("replay stopped at position %d: %w", progress.CurrentPosition, err)
+	}
+	return nil
+}
+
+func (a *ReplayAdmin) reportProgress(progress eventsourcing.ReplayProgress) {
+	fmt.Fprintf(a.progressOut, "Replayed %d events (skipped %d, failed %d), position %d, elapsed %s\n",
+		progress.ProcessedEvents, progress.SkippedEvents, progress.FailedEvents,
+		progress.CurrentPosition, progress.ElapsedTime.Round(time.Millisecond))
+}
@@ -0,0 +1,454 @@
+package admin
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akriventsev/potter/framework/saga"
+)
+
+// compensationResumer реализуется оркестраторами, умеющими продолжать зависшую компенсацию
+// (saga.DefaultOrchestrator.ResumeCompensation)
+type compensationResumer interface {
+	ResumeCompensation(ctx context.Context, sagaID string) error
+}
+
+// SagaAdmin административные операции над сохраненными сагами.
+// Просмотр и принудительное завершение работают напрямую с SagaPersistence;
+// resume, compensate и retry-stuck выполняют шаги и требуют оркестратора
+// с зарегистрированными определениями саг (см. WithOrchestrator).
+type SagaAdmin struct {
+	persistence  saga.SagaPersistence
+	orchestrator saga.SagaOrchestrator
+	out          io.Writer
+}
+
+// NewSagaAdmin создает новый SagaAdmin
+func NewSagaAdmin(persistence saga.SagaPersistence, out io.Writer) *SagaAdmin {
+	return &SagaAdmin{
+		persistence: persistence,
+		out:         out,
+	}
+}
+
+// WithOrchestrator устанавливает оркестратор для команд, выполняющих шаги саг
+func (a *SagaAdmin) WithOrchestrator(orchestrator saga.SagaOrchestrator) *SagaAdmin {
+	a.orchestrator = orchestrator
+	return a
+}
+
+// Run выполняет команду группы saga.
+// Поддерживаемые команды:
+//
+//	saga list [--status S[,S...]] [--definition D] [--limit N]
+//	saga history <id>
+//	saga resume <id>
+//	saga compensate <id>
+//	saga force-fail <id> --reason R
+//	saga retry-stuck [--stuck-for 30m] [--definition D] [--limit N] [--dry-run]
+//	saga export [--id ID[,ID...]] [--status S[,S...]] [--definition D] [--stuck-for 30m] [--limit N] [--output FILE] [--redact K[,K...]] [--no-redact]
+//	saga import <file> [--overwrite]
+func (a *SagaAdmin) Run(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "saga" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("saga subcommand is required: list, history, resume, compensate, force-fail, retry-stuck, export, import")
+	}
+
+	switch args[0] {
+	case "list":
+		return a.runList(ctx, args[1:])
+	case "history":
+		if len(args) < 2 {
+			return fmt.Errorf("saga ID is required")
+		}
+		return a.History(ctx, args[1])
+	case "resume":
+		if len(args) < 2 {
+			return fmt.Errorf("saga ID is required")
+		}
+		return a.Resume(ctx, args[1])
+	case "compensate":
+		if len(args) < 2 {
+			return fmt.Errorf("saga ID is required")
+		}
+		return a.Compensate(ctx, args[1])
+	case "force-fail":
+		return a.runForceFail(ctx, args[1:])
+	case "retry-stuck":
+		return a.runRetryStuck(ctx, args[1:])
+	case "export":
+		return a.runExport(ctx, args[1:])
+	case "import":
+		return a.runImport(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown saga subcommand: %s", args[0])
+	}
+}
+
+// runList разбирает аргументы команды list
+func (a *SagaAdmin) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("saga list", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	statuses := fs.String("status", "", "Comma-separated saga statuses (default: all statuses)")
+	definition := fs.String("definition", "", "Saga definition name (default: all definitions)")
+	limit := fs.Int("limit", 100, "Maximum number of sagas (0 - no limit)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return a.List(ctx, saga.SagaRecordFilter{
+		Statuses:       parseSagaStatuses(*statuses),
+		DefinitionName: *definition,
+		Limit:          *limit,
+	})
+}
+
+// List выводит саги, соответствующие фильтру
+func (a *SagaAdmin) List(ctx context.Context, filter saga.SagaRecordFilter) error {
+	records, err := a.listRecords(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SAGA ID\tDEFINITION\tVERSION\tSTATUS\tCURRENT STEP\tUPDATED")
+	for _, record := range records {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			record.SagaID,
+			record.DefinitionName,
+			record.DefinitionVersion,
+			record.Status,
+			record.CurrentStep,
+			record.UpdatedAt.Format("2006-01-02 15:04:05"),
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "%d sagas\n", len(records))
+	return nil
+}
+
+// History выводит историю выполнения шагов саги
+func (a *SagaAdmin) History(ctx context.Context, sagaID string) error {
+	history, err := a.persistence.GetHistory(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get history of saga %s: %w", sagaID, err)
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSTATUS\tATTEMPT\tSTARTED\tDURATION\tERROR")
+	for _, entry := range history {
+		duration := "-"
+		if entry.CompletedAt != nil {
+			duration = entry.CompletedAt.Sub(entry.StartedAt).String()
+		}
+		errText := ""
+		if entry.Error != nil {
+			errText = entry.Error.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			entry.StepName,
+			entry.Status,
+			entry.RetryAttempt,
+			entry.StartedAt.Format("2006-01-02 15:04:05"),
+			duration,
+			errText,
+		)
+	}
+	return w.Flush()
+}
+
+// Resume возобновляет выполнение саги через оркестратор
+func (a *SagaAdmin) Resume(ctx context.Context, sagaID string) error {
+	if err := a.requireOrchestrator("resume"); err != nil {
+		return err
+	}
+	if err := a.orchestrator.Resume(ctx, sagaID); err != nil {
+		return fmt.Errorf("failed to resume saga %s: %w", sagaID, err)
+	}
+	fmt.Fprintf(a.out, "Saga %s resumed\n", sagaID)
+	return nil
+}
+
+// Compensate запускает компенсацию саги. Для саги в статусе compensation_stuck
+// компенсация продолжается с незавершенных шагов.
+func (a *SagaAdmin) Compensate(ctx context.Context, sagaID string) error {
+	if err := a.requireOrchestrator("compensate"); err != nil {
+		return err
+	}
+
+	instance, err := a.persistence.Load(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to load saga %s: %w", sagaID, err)
+	}
+
+	if instance.Status() == saga.SagaStatusCompensationStuck {
+		resumer, ok := a.orchestrator.(compensationResumer)
+		if !ok {
+			return fmt.Errorf("orchestrator does not support resuming compensation of saga %s", sagaID)
+		}
+		err = resumer.ResumeCompensation(ctx, sagaID)
+	} else {
+		err = a.orchestrator.Compensate(ctx, instance)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compensate saga %s: %w", sagaID, err)
+	}
+	fmt.Fprintf(a.out, "Saga %s compensated\n", sagaID)
+	return nil
+}
+
+// runForceFail разбирает аргументы команды force-fail
+func (a *SagaAdmin) runForceFail(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("saga ID is required")
+	}
+	sagaID := args[0]
+
+	fs := flag.NewFlagSet("saga force-fail", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	reason := fs.String("reason", "", "Reason recorded in saga history")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *reason == "" {
+		return fmt.Errorf("--reason is required")
+	}
+
+	return a.ForceFail(ctx, sagaID, *reason)
+}
+
+// ForceFail переводит сагу в статус failed без компенсации
+func (a *SagaAdmin) ForceFail(ctx context.Context, sagaID, reason string) error {
+	store, ok := a.persistence.(saga.SagaAdminStore)
+	if !ok {
+		return fmt.Errorf("saga persistence does not support force fail")
+	}
+	if err := store.ForceFail(ctx, sagaID, reason); err != nil {
+		return fmt.Errorf("failed to force-fail saga %s: %w", sagaID, err)
+	}
+	fmt.Fprintf(a.out, "Saga %s marked as failed\n", sagaID)
+	return nil
+}
+
+// runRetryStuck разбирает аргументы команды retry-stuck
+func (a *SagaAdmin) runRetryStuck(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("saga retry-stuck", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	stuckFor := fs.Duration("stuck-for", 30*time.Minute, "Retry running and pending sagas not updated for this duration")
+	definition := fs.String("definition", "", "Saga definition name (default: all definitions)")
+	limit := fs.Int("limit", 100, "Maximum number of sagas to retry (0 - no limit)")
+	dryRun := fs.Bool("dry-run", false, "Only list stuck sagas")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return a.RetryStuck(ctx, saga.SagaRecordFilter{
+		Statuses:       []saga.SagaStatus{saga.SagaStatusRunning, saga.SagaStatusPending},
+		DefinitionName: *definition,
+		UpdatedBefore:  time.Now().Add(-*stuckFor),
+		Limit:          *limit,
+	}, *dryRun)
+}
+
+// RetryStuck возобновляет саги, соответствующие фильтру. Ошибка возобновления одной саги
+// не прерывает обработку остальных; возвращается ошибка с количеством неудачных попыток.
+func (a *SagaAdmin) RetryStuck(ctx context.Context, filter saga.SagaRecordFilter, dryRun bool) error {
+	if !dryRun {
+		if err := a.requireOrchestrator("retry-stuck"); err != nil {
+			return err
+		}
+	}
+
+	records, err := a.listRecords(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, record := range records {
+		if dryRun {
+			fmt.Fprintf(a.out, "%s\t%s\t%s\tstuck since %s\n",
+				record.SagaID, record.DefinitionName, record.Status, record.UpdatedAt.Format("2006-01-02 15:04:05"))
+			continue
+		}
+		if err := a.orchestrator.Resume(ctx, record.SagaID); err != nil {
+			failed++
+			fmt.Fprintf(a.out, "Saga %s: resume failed: %v\n", record.SagaID, err)
+			continue
+		}
+		fmt.Fprintf(a.out, "Saga %s resumed\n", record.SagaID)
+	}
+
+	if dryRun {
+		fmt.Fprintf(a.out, "%d stuck sagas\n", len(records))
+		return nil
+	}
+	fmt.Fprintf(a.out, "%d stuck sagas retried, %d failed\n", len(records)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to resume %d of %d stuck sagas", failed, len(records))
+	}
+	return nil
+}
+
+// runExport разбирает аргументы команды export
+func (a *SagaAdmin) runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("saga export", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	ids := fs.String("id", "", "Comma-separated saga IDs (overrides filter flags)")
+	statuses := fs.String("status", "", "Comma-separated saga statuses (default: all statuses)")
+	definition := fs.String("definition", "", "Saga definition name (default: all definitions)")
+	stuckFor := fs.Duration("stuck-for", 0, "Only sagas not updated for this duration (default: any)")
+	limit := fs.Int("limit", 100, "Maximum number of sagas (0 - no limit)")
+	output := fs.String("output", "-", "Bundle file (- for stdout)")
+	redact := fs.String("redact", "", "Additional comma-separated context keys to redact")
+	noRedact := fs.Bool("no-redact", false, "Export context without redacting secrets")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	options := saga.SagaExportOptions{
+		SagaIDs: splitList(*ids),
+		Filter: saga.SagaRecordFilter{
+			Statuses:       parseSagaStatuses(*statuses),
+			DefinitionName: *definition,
+			Limit:          *limit,
+		},
+	}
+	if *stuckFor > 0 {
+		options.Filter.UpdatedBefore = time.Now().Add(-*stuckFor)
+	}
+	if !*noRedact {
+		options.RedactKeys = append(append([]string(nil), saga.DefaultRedactedKeys...), splitList(*redact)...)
+	}
+
+	if *output == "-" {
+		return a.Export(ctx, options, a.out)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle file: %w", err)
+	}
+	if err := a.Export(ctx, options, file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle file: %w", err)
+	}
+	return nil
+}
+
+// Export выгружает саги в переносимый JSON bundle (состояние, контекст, история шагов).
+// Секреты маскируются по options.RedactKeys.
+func (a *SagaAdmin) Export(ctx context.Context, options saga.SagaExportOptions, out io.Writer) error {
+	bundle, err := saga.ExportSagaBundle(ctx, a.persistence, options)
+	if err != nil {
+		return err
+	}
+	if err := saga.WriteSagaBundle(out, bundle); err != nil {
+		return err
+	}
+	// Сводка выводится, только если выгрузка записана не в вывод команды
+	if out != a.out {
+		fmt.Fprintf(a.out, "%d sagas exported\n", len(bundle.Sagas))
+	}
+	return nil
+}
+
+// runImport разбирает аргументы команды import
+func (a *SagaAdmin) runImport(ctx context.Context, args []string) error {
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-") {
+		return fmt.Errorf("bundle file is required (- for stdin)")
+	}
+	path := args[0]
+
+	fs := flag.NewFlagSet("saga import", flag.ContinueOnError)
+	fs.SetOutput(a.out)
+	overwrite := fs.Bool("overwrite", false, "Replace sagas that already exist")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open bundle file: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+	return a.Import(ctx, in, *overwrite)
+}
+
+// Import загружает саги из JSON bundle. Замаскированные при выгрузке значения
+// импортируются как есть ("[REDACTED]") - восстановите их до возобновления саг.
+func (a *SagaAdmin) Import(ctx context.Context, in io.Reader, overwrite bool) error {
+	bundle, err := saga.ReadSagaBundle(in)
+	if err != nil {
+		return err
+	}
+
+	imported, err := saga.ImportSagaBundle(ctx, a.persistence, bundle, overwrite)
+	fmt.Fprintf(a.out, "%d of %d sagas imported\n", imported, len(bundle.Sagas))
+	if len(bundle.RedactedKeys) > 0 {
+		fmt.Fprintf(a.out, "Warning: bundle context is redacted (%s), restore secrets before resuming sagas\n",
+			strings.Join(bundle.RedactedKeys, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import sagas: %w", err)
+	}
+	return nil
+}
+
+// listRecords перечисляет саги через SagaAdminStore
+func (a *SagaAdmin) listRecords(ctx context.Context, filter saga.SagaRecordFilter) ([]saga.SagaRecord, error) {
+	store, ok := a.persistence.(saga.SagaAdminStore)
+	if !ok {
+		return nil, fmt.Errorf("saga persistence does not support listing sagas")
+	}
+	records, err := store.ListSagaRecords(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sagas: %w", err)
+	}
+	return records, nil
+}
+
+// requireOrchestrator проверяет, что для команды, выполняющей шаги, задан оркестратор
+func (a *SagaAdmin) requireOrchestrator(command string) error {
+	if a.orchestrator == nil {
+		return fmt.Errorf("saga %s executes saga steps and requires registered saga definitions: "+
+			"embed framework/admin SagaAdmin with an orchestrator into the service binary", command)
+	}
+	return nil
+}
+
+// parseSagaStatuses разбирает список статусов через запятую
+func parseSagaStatuses(value string) []saga.SagaStatus {
+	var statuses []saga.SagaStatus
+	for _, status := range splitList(value) {
+		statuses = append(statuses, saga.SagaStatus(status))
+	}
+	return statuses
+}
+
+// splitList разбирает список значений через запятую
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
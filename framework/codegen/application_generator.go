@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -27,6 +28,10 @@ func (g *ApplicationGenerator) Generate(spec *ParsedSpec, config *GeneratorConfi
 		return fmt.Errorf("failed to generate queries: %w", err)
 	}
 
+	if err := g.generateSagaSteps(spec, config); err != nil {
+		return fmt.Errorf("failed to generate saga steps: %w", err)
+	}
+
 	return nil
 }
 
@@ -49,7 +54,13 @@ func (g *ApplicationGenerator) generateCommand(cmd CommandSpec, _ *ParsedSpec, c
 	content.WriteString("import (\n")
 	content.WriteString("\t\"context\"\n")
 	content.WriteString("\t\"fmt\"\n")
+	if commandHasRegexRules(cmd) {
+		content.WriteString("\t\"regexp\"\n")
+	}
 	content.WriteString("\n")
+	if fieldsUseDecimal(cmd.RequestFields) {
+		content.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
 	if config != nil && config.ModulePath != "" {
 		content.WriteString(fmt.Sprintf("\t\"%s/domain\"\n", config.ModulePath))
 	} else {
@@ -67,6 +78,9 @@ func (g *ApplicationGenerator) generateCommand(cmd CommandSpec, _ *ParsedSpec, c
 	content.WriteString(fmt.Sprintf("\t\"%s/framework/events\"\n", baseImportPath))
 	content.WriteString(fmt.Sprintf("\t\"%s/framework/invoke\"\n", baseImportPath))
 	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", baseImportPath))
+	if commandHasRules(cmd) {
+		content.WriteString(fmt.Sprintf("\t\"%s/framework/validation\"\n", baseImportPath))
+	}
 	content.WriteString(")\n\n")
 
 	// Генерация команды
@@ -90,6 +104,11 @@ func (g *ApplicationGenerator) generateCommand(cmd CommandSpec, _ *ParsedSpec, c
 	content.WriteString(fmt.Sprintf("\treturn %q\n", g.converter.ToSnakeCase(cmd.Name)))
 	content.WriteString("}\n\n")
 
+	// Генерация Validate по правилам полей (potter.field)
+	if commandHasRules(cmd) {
+		g.writeCommandValidate(&content, cmd)
+	}
+
 	// Генерация handler
 	handlerName := fmt.Sprintf("%sHandler", cmd.Name)
 	content.WriteString(fmt.Sprintf("// %s обработчик команды\n", handlerName))
@@ -117,6 +136,14 @@ func (g *ApplicationGenerator) generateCommand(cmd CommandSpec, _ *ParsedSpec, c
 	content.WriteString("\t\treturn fmt.Errorf(\"invalid command type: %T\", cmd)\n")
 	content.WriteString("\t}\n\n")
 
+	// Проверка правил полей из proto (если команда не прошла через validation middleware)
+	if commandHasRules(cmd) {
+		content.WriteString("\t// Валидация правил полей\n")
+		content.WriteString(fmt.Sprintf("\tif err := %s.Validate(); err != nil {\n", strings.ToLower(cmd.Name)))
+		content.WriteString("\t\treturn fmt.Errorf(\"validation failed: %w\", err)\n")
+		content.WriteString("\t}\n\n")
+	}
+
 	// Вызов пользовательской функции валидации
 	validationFuncName := fmt.Sprintf("validate%s", cmd.Name)
 	content.WriteString(fmt.Sprintf("\t// Валидация команды\n"))
@@ -174,6 +201,107 @@ func (g *ApplicationGenerator) generateCommand(cmd CommandSpec, _ *ParsedSpec, c
 	return g.generateCommandUserCode(cmd, config)
 }
 
+// writeCommandValidate генерирует метод Validate команды по правилам полей и
+// скомпилированные регулярные выражения для правил regex
+func (g *ApplicationGenerator) writeCommandValidate(content *strings.Builder, cmd CommandSpec) {
+	cmdName := fmt.Sprintf("%sCommand", cmd.Name)
+	patternPrefix := strings.ToLower(cmd.Name[:1]) + cmd.Name[1:]
+
+	for _, field := range cmd.RequestFields {
+		if hasRegexRule(field) {
+			content.WriteString(fmt.Sprintf("var %s%sPattern = regexp.MustCompile(%q)\n",
+				patternPrefix, g.toPublicField(field.Name), field.Rules.Regex))
+		}
+	}
+	if commandHasRegexRules(cmd) {
+		content.WriteString("\n")
+	}
+
+	content.WriteString("// Validate проверяет правила полей команды, объявленные в proto (potter.field)\n")
+	content.WriteString(fmt.Sprintf("func (c %s) Validate() error {\n", cmdName))
+	content.WriteString("\tv := validation.New()\n")
+	for _, field := range cmd.RequestFields {
+		rules := field.Rules
+		if rules == nil {
+			continue
+		}
+		name := g.converter.ToSnakeCase(field.Name)
+		value := "c." + g.toPublicField(field.Name)
+
+		if rules.Required {
+			content.WriteString(fmt.Sprintf("\tv.Required(%q, %s)\n", name, value))
+		}
+		// Для строк, bytes и коллекций min/max ограничивают длину
+		byLength := field.Repeated || field.Type == "string" || field.Type == "[]byte"
+		// decimal.Decimal сравнивается через приближенное float64 значение
+		if field.Type == decimalType && !field.Repeated {
+			value += ".InexactFloat64()"
+		}
+		if rules.Min != nil {
+			if byLength {
+				content.WriteString(fmt.Sprintf("\tv.MinLength(%q, len(%s), %d)\n", name, value, int(*rules.Min)))
+			} else if field.Type == decimalType {
+				content.WriteString(fmt.Sprintf("\tv.Min(%q, %s, %s)\n", name, value, formatFloatLiteral(*rules.Min)))
+			} else if isNumericProtoType(field.Type) {
+				content.WriteString(fmt.Sprintf("\tv.Min(%q, float64(%s), %s)\n", name, value, formatFloatLiteral(*rules.Min)))
+			}
+		}
+		if rules.Max != nil {
+			if byLength {
+				content.WriteString(fmt.Sprintf("\tv.MaxLength(%q, len(%s), %d)\n", name, value, int(*rules.Max)))
+			} else if field.Type == decimalType {
+				content.WriteString(fmt.Sprintf("\tv.Max(%q, %s, %s)\n", name, value, formatFloatLiteral(*rules.Max)))
+			} else if isNumericProtoType(field.Type) {
+				content.WriteString(fmt.Sprintf("\tv.Max(%q, float64(%s), %s)\n", name, value, formatFloatLiteral(*rules.Max)))
+			}
+		}
+		if hasRegexRule(field) {
+			content.WriteString(fmt.Sprintf("\tv.Match(%q, %s, %s%sPattern)\n", name, value, patternPrefix, g.toPublicField(field.Name)))
+		}
+	}
+	content.WriteString(fmt.Sprintf("\treturn v.Err(%q)\n", g.converter.ToSnakeCase(cmd.Name)))
+	content.WriteString("}\n\n")
+}
+
+// commandHasRules проверяет, заданы ли правила валидации для полей команды
+func commandHasRules(cmd CommandSpec) bool {
+	for _, field := range cmd.RequestFields {
+		if rules := field.Rules; rules != nil && (rules.Required || rules.Min != nil || rules.Max != nil || rules.Regex != "") {
+			return true
+		}
+	}
+	return false
+}
+
+// commandHasRegexRules проверяет, есть ли у команды правила regex
+func commandHasRegexRules(cmd CommandSpec) bool {
+	for _, field := range cmd.RequestFields {
+		if hasRegexRule(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRegexRule проверяет, задано ли правило regex для строкового поля
+func hasRegexRule(field FieldSpec) bool {
+	return field.Rules != nil && field.Rules.Regex != "" && field.Type == "string" && !field.Repeated
+}
+
+// isNumericProtoType проверяет, является ли тип поля числовым
+func isNumericProtoType(protoType string) bool {
+	switch protoType {
+	case "int32", "int64", "float32", "float64":
+		return true
+	}
+	return false
+}
+
+// formatFloatLiteral форматирует число как Go литерал
+func formatFloatLiteral(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
 // generateCommandUserCode генерирует отдельный файл для пользовательского кода команды
 func (g *ApplicationGenerator) generateCommandUserCode(cmd CommandSpec, config *GeneratorConfig) error {
 	var userContent strings.Builder
@@ -230,6 +358,89 @@ func (g *ApplicationGenerator) generateCommandUserCode(cmd CommandSpec, config *
 	return g.writer.WriteFile(userPath, userContent.String())
 }
 
+// generateSagaSteps генерирует шаги саг для команд с компенсирующей командой (potter.command compensated_by):
+// New<Command>Step создает saga.CommandStep, компенсация которого строится из полей исходной команды
+func (g *ApplicationGenerator) generateSagaSteps(spec *ParsedSpec, config *GeneratorConfig) error {
+	commands := make(map[string]CommandSpec, len(spec.Commands))
+	for _, cmd := range spec.Commands {
+		commands[cmd.Name] = cmd
+	}
+
+	var compensable []CommandSpec
+	for _, cmd := range spec.Commands {
+		if cmd.CompensatedBy == "" {
+			continue
+		}
+		if _, ok := commands[cmd.CompensatedBy]; !ok {
+			return fmt.Errorf("command %s: compensating command %s not found", cmd.Name, cmd.CompensatedBy)
+		}
+		compensable = append(compensable, cmd)
+	}
+	if len(compensable) == 0 {
+		return nil
+	}
+
+	commandImportPath := "application/command"
+	if config != nil && config.ModulePath != "" {
+		commandImportPath = config.ModulePath + "/application/command"
+	}
+	baseImportPath := potterBaseImportPath(config)
+
+	var content strings.Builder
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+	content.WriteString("// Package sagas содержит шаги саг для команд с компенсацией (potter.command compensated_by).\n")
+	content.WriteString("package sagas\n\n")
+	content.WriteString("import (\n")
+	content.WriteString(fmt.Sprintf("\t\"%s\"\n", commandImportPath))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/saga\"\n", baseImportPath))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", baseImportPath))
+	content.WriteString(")\n\n")
+
+	for _, cmd := range compensable {
+		compensation := commands[cmd.CompensatedBy]
+		cmdName := cmd.Name + "Command"
+		compensationName := compensation.Name + "Command"
+		compensationFunc := fmt.Sprintf("%sCompensation", compensation.Name)
+
+		content.WriteString(fmt.Sprintf("// New%sStep создает шаг саги, выполняющий %s, с компенсацией %s\n", cmd.Name, cmd.Name, compensation.Name))
+		content.WriteString(fmt.Sprintf("func New%sStep(commandBus transport.CommandBus, cmd command.%s) *saga.CommandStep {\n", cmd.Name, cmdName))
+		content.WriteString(fmt.Sprintf("\treturn saga.NewCommandStep(%q, commandBus, cmd, %s(cmd))\n", g.converter.ToSnakeCase(cmd.Name), compensationFunc))
+		content.WriteString("}\n\n")
+
+		sourceFields := make(map[string]FieldSpec, len(cmd.RequestFields))
+		for _, field := range cmd.RequestFields {
+			sourceFields[field.Name] = field
+		}
+		var copied, missing []string
+		for _, field := range compensation.RequestFields {
+			source, ok := sourceFields[field.Name]
+			if ok && g.protoToGoType(source.Type, source.Repeated) == g.protoToGoType(field.Type, field.Repeated) {
+				copied = append(copied, g.toPublicField(field.Name))
+			} else {
+				missing = append(missing, g.toPublicField(field.Name))
+			}
+		}
+
+		content.WriteString(fmt.Sprintf("// %s строит компенсирующую команду %s из одноименных полей %s\n", compensationFunc, compensation.Name, cmd.Name))
+		if len(missing) > 0 {
+			content.WriteString(fmt.Sprintf("// Поля без соответствия в %s остаются нулевыми: %s\n", cmdName, strings.Join(missing, ", ")))
+		}
+		content.WriteString(fmt.Sprintf("func %s(cmd command.%s) command.%s {\n", compensationFunc, cmdName, compensationName))
+		if len(copied) == 0 {
+			content.WriteString(fmt.Sprintf("\treturn command.%s{}\n", compensationName))
+		} else {
+			content.WriteString(fmt.Sprintf("\treturn command.%s{\n", compensationName))
+			for _, field := range copied {
+				content.WriteString(fmt.Sprintf("\t\t%s: cmd.%s,\n", field, field))
+			}
+			content.WriteString("\t}\n")
+		}
+		content.WriteString("}\n\n")
+	}
+
+	return g.writer.WriteFile("application/sagas/steps.gen.go", strings.TrimSuffix(content.String(), "\n"))
+}
+
 // generateQueries генерирует запросы и handlers
 func (g *ApplicationGenerator) generateQueries(spec *ParsedSpec, config *GeneratorConfig) error {
 	for _, query := range spec.Queries {
@@ -251,7 +462,11 @@ func (g *ApplicationGenerator) generateQuery(query QuerySpec, spec *ParsedSpec,
 	hasReadModel := query.ReadModel != ""
 	var usesDomain bool
 	var foundAggregate *AggregateSpec
-	if !hasReadModel {
+	// Read model, объявленная в proto (potter.read_model), доступна handler через repository.ReadModelStore
+	var foundReadModel *ReadModelSpec
+	if hasReadModel {
+		foundReadModel = findReadModelByName(spec.ReadModels, query.ReadModel)
+	} else {
 		aggregateName := inferAggregateFromQueryName(query.Name)
 		if aggregateName != "" {
 			// Ищем агрегат в спецификации - только если найден, используем domain
@@ -280,12 +495,18 @@ func (g *ApplicationGenerator) generateQuery(query QuerySpec, spec *ParsedSpec,
 			}
 		}
 	}
+	if fieldsUseDecimal(query.RequestFields, query.ResponseFields) {
+		content.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
 	if usesDomain || needsDomainForResponse {
 		content.WriteString(fmt.Sprintf("\t\"%s/domain\"\n", config.ModulePath))
 	}
 	if query.Cacheable {
 		content.WriteString(fmt.Sprintf("\t\"%s/infrastructure/cache\"\n", config.ModulePath))
 	}
+	if foundReadModel != nil {
+		content.WriteString(fmt.Sprintf("\t\"%s/infrastructure/readmodel\"\n", config.ModulePath))
+	}
 	potterPath := ""
 	if config != nil {
 		potterPath = config.PotterImportPath
@@ -295,6 +516,9 @@ func (g *ApplicationGenerator) generateQuery(query QuerySpec, spec *ParsedSpec,
 	}
 	// Удаляем @main или другие суффиксы версии для import-путей
 	baseImportPath := strings.Split(potterPath, "@")[0]
+	if foundReadModel != nil {
+		content.WriteString(fmt.Sprintf("\t\"%s/framework/adapters/repository\"\n", baseImportPath))
+	}
 	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", baseImportPath))
 	content.WriteString(")\n\n")
 
@@ -342,7 +566,10 @@ func (g *ApplicationGenerator) generateQuery(query QuerySpec, spec *ParsedSpec,
 	content.WriteString(fmt.Sprintf("type %s struct {\n", handlerName))
 
 	// Определяем какой репозиторий использовать
-	if hasReadModel {
+	if foundReadModel != nil {
+		// Query использует сгенерированную read model - поле хранилища read model
+		content.WriteString(fmt.Sprintf("\tstore repository.ReadModelStore[*readmodel.%s]\n", foundReadModel.Name))
+	} else if hasReadModel {
 		// Query использует read model - НЕ добавляем поле репозитория агрегата
 		content.WriteString(fmt.Sprintf("\t// This query uses read model: %s\n", query.ReadModel))
 		content.WriteString("\t// TODO: Add read-model repository field here if needed\n")
@@ -373,7 +600,21 @@ func (g *ApplicationGenerator) generateQuery(query QuerySpec, spec *ParsedSpec,
 	content.WriteString(fmt.Sprintf("// New%s создает новый обработчик\n", handlerName))
 
 	// Генерируем конструктор в зависимости от наличия read_model и агрегата
-	if hasReadModel {
+	if foundReadModel != nil {
+		// Query имеет сгенерированную read model - параметры: хранилище и cache (если cacheable)
+		content.WriteString(fmt.Sprintf("func New%s(store repository.ReadModelStore[*readmodel.%s]", handlerName, foundReadModel.Name))
+		if query.Cacheable {
+			content.WriteString(", cache cache.CacheService")
+		}
+		content.WriteString(fmt.Sprintf(") *%s {\n", handlerName))
+		content.WriteString(fmt.Sprintf("\treturn &%s{\n", handlerName))
+		content.WriteString("\t\tstore: store,\n")
+		if query.Cacheable {
+			content.WriteString("\t\tcache: cache,\n")
+		}
+		content.WriteString("\t}\n")
+		content.WriteString("}\n\n")
+	} else if hasReadModel {
 		// Query имеет read_model - параметры: только cache (если cacheable)
 		content.WriteString(fmt.Sprintf("func New%s(", handlerName))
 		if query.Cacheable {
@@ -519,7 +760,12 @@ func (g *ApplicationGenerator) generateQueryUserCode(query QuerySpec, spec *Pars
 		loadDataFuncName, handlerName, queryName, responseName))
 	userContent.WriteString("\t// TODO: Load data from repository or read model\n")
 	userContent.WriteString("\t// Example:\n")
-	userContent.WriteString("\t// item, err := h.itemRepo.FindByID(ctx, q.ID)\n")
+	if findReadModelByName(spec.ReadModels, query.ReadModel) != nil {
+		userContent.WriteString("\t// item, err := h.store.Get(ctx, q.ID)\n")
+		userContent.WriteString("\t// items, err := h.store.List(ctx, repository.ReadModelFilter{Where: map[string]interface{}{\"status\": q.Status}, Limit: 100})\n")
+	} else {
+		userContent.WriteString("\t// item, err := h.itemRepo.FindByID(ctx, q.ID)\n")
+	}
 	userContent.WriteString("\t// if err != nil {\n")
 	userContent.WriteString("\t//     return nil, err\n")
 	userContent.WriteString("\t// }\n")
@@ -564,6 +810,20 @@ func inferAggregateFromQueryName(queryName string) string {
 	return queryName
 }
 
+// findReadModelByName ищет read model в списке по имени
+// Возвращает указатель на найденную read model или nil, если не найдена
+func findReadModelByName(readModels []ReadModelSpec, name string) *ReadModelSpec {
+	if name == "" {
+		return nil
+	}
+	for i := range readModels {
+		if readModels[i].Name == name {
+			return &readModels[i]
+		}
+	}
+	return nil
+}
+
 // findAggregateByName ищет агрегат в списке по имени без учета регистра
 // Возвращает указатель на найденный агрегат или nil, если не найден
 func findAggregateByName(aggregates []AggregateSpec, name string) *AggregateSpec {
@@ -593,6 +853,8 @@ func (g *ApplicationGenerator) protoToGoType(protoType string, repeated bool) st
 		goType = "float32"
 	case "[]byte":
 		goType = "[]byte"
+	case decimalType:
+		goType = decimalGoType
 	default:
 		// Для пользовательских типов (например, Item) возвращаем как есть
 		goType = protoType
@@ -631,6 +893,7 @@ func (g *ApplicationGenerator) isCustomType(protoType string) bool {
 		"float64": true,
 		"float32": true,
 		"[]byte":  true,
+		"decimal": true,
 	}
 	return !basicTypes[protoType]
 }
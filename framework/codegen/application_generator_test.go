@@ -0,0 +1,237 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestApplicationGenerator_CommandValidation(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	minPrice, maxName := 0.0, 255.0
+	spec := &ParsedSpec{
+		ModuleName: "test",
+		Commands: []CommandSpec{
+			{
+				Name:      "CreateProduct",
+				Aggregate: "Product",
+				RequestFields: []FieldSpec{
+					{Name: "name", Type: "string", Number: 1, Rules: &FieldRules{Required: true, Max: &maxName}},
+					{Name: "price", Type: "float64", Number: 2, Rules: &FieldRules{Min: &minPrice}},
+					{Name: "sku", Type: "string", Number: 3, Rules: &FieldRules{Regex: `^[A-Z]{3}-\d+$`}},
+					{Name: "description", Type: "string", Number: 4},
+				},
+			},
+			{
+				Name:          "DeleteProduct",
+				Aggregate:     "Product",
+				RequestFields: []FieldSpec{{Name: "id", Type: "string", Number: 1}},
+			},
+		},
+	}
+	config := &GeneratorConfig{ModulePath: "test", OutputDir: tmpDir, PackageName: "test", Overwrite: true}
+	require.NoError(t, NewApplicationGenerator(tmpDir).generateCommands(spec, config))
+
+	path := filepath.Join(tmpDir, "application/command/create_product.gen.go")
+	_, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "func (c CreateProductCommand) Validate() error {")
+	assert.Contains(t, content, `v.Required("name", c.Name)`)
+	assert.Contains(t, content, `v.MaxLength("name", len(c.Name), 255)`)
+	assert.Contains(t, content, `v.Min("price", float64(c.Price), 0)`)
+	assert.Contains(t, content, `var createProductSkuPattern = regexp.MustCompile("^[A-Z]{3}-\\d+$")`)
+	assert.Contains(t, content, `v.Match("sku", c.Sku, createProductSkuPattern)`)
+	assert.Contains(t, content, "if err := createproduct.Validate(); err != nil {")
+	assert.NotContains(t, content, `v.Required("description"`)
+
+	// Команда без правил не импортирует validation
+	data, err = os.ReadFile(filepath.Join(tmpDir, "application/command/delete_product.gen.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "framework/validation")
+	assert.NotContains(t, string(data), "Validate()")
+}
+
+func TestProtoParser_ParseFieldRules(t *testing.T) {
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, 1)
+	data = protowire.AppendTag(data, 2, protowire.Fixed64Type)
+	data = protowire.AppendFixed64(data, math.Float64bits(0.5))
+	data = protowire.AppendTag(data, 4, protowire.BytesType)
+	data = protowire.AppendString(data, "^[a-z]+$")
+	data = protowire.AppendTag(data, 5, protowire.BytesType)
+	data = protowire.AppendString(data, DecimalModeDecimal)
+	data = protowire.AppendTag(data, 6, protowire.VarintType)
+	data = protowire.AppendVarint(data, 4)
+
+	rules := NewProtoParser().parseFieldRules(data)
+	assert.True(t, rules.Required)
+	require.NotNil(t, rules.Min)
+	assert.Equal(t, 0.5, *rules.Min)
+	assert.Nil(t, rules.Max)
+	assert.Equal(t, "^[a-z]+$", rules.Regex)
+	assert.Equal(t, DecimalModeDecimal, rules.Decimal)
+	assert.Equal(t, 4, rules.Scale)
+
+	assert.Equal(t, "decimal", resolveDecimalType("string", rules))
+	assert.Equal(t, "int64", resolveDecimalType("string", &FieldRules{Decimal: DecimalModeMinorUnits}))
+	assert.Equal(t, "float64", resolveDecimalType("float64", nil))
+}
+
+func TestApplicationGenerator_DecimalFields(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	minPrice := 0.0
+	spec := &ParsedSpec{
+		ModuleName: "test",
+		Commands: []CommandSpec{
+			{
+				Name:      "CreateProduct",
+				Aggregate: "Product",
+				RequestFields: []FieldSpec{
+					{Name: "price", Type: "decimal", Number: 1, Rules: &FieldRules{Decimal: DecimalModeDecimal, Min: &minPrice}},
+					{Name: "cost", Type: "int64", Number: 2, Rules: &FieldRules{Decimal: DecimalModeMinorUnits}},
+				},
+			},
+			{
+				Name:      "RepriceProduct",
+				Aggregate: "Product",
+				RequestFields: []FieldSpec{
+					{Name: "price", Type: "decimal", Number: 1, Rules: &FieldRules{Decimal: DecimalModeDecimal}},
+				},
+			},
+		},
+	}
+	config := &GeneratorConfig{ModulePath: "test", OutputDir: tmpDir, PackageName: "test", Overwrite: true}
+	require.NoError(t, NewApplicationGenerator(tmpDir).generateCommands(spec, config))
+
+	path := filepath.Join(tmpDir, "application/command/create_product.gen.go")
+	_, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, `"github.com/shopspring/decimal"`)
+	assert.Contains(t, content, "Price decimal.Decimal `json:\"price\"`")
+	assert.Contains(t, content, "Cost int64 `json:\"cost\"`")
+	assert.Contains(t, content, `v.Min("price", c.Price.InexactFloat64(), 0)`)
+
+	// Опция decimal без правил валидации не генерирует Validate
+	data, err = os.ReadFile(filepath.Join(tmpDir, "application/command/reprice_product.gen.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Validate()")
+}
+
+func TestApplicationGenerator_QueryReadModelStore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	spec := &ParsedSpec{
+		ModuleName: "test",
+		Queries: []QuerySpec{
+			{
+				Name:           "ListProductView",
+				ReadModel:      "ProductView",
+				RequestFields:  []FieldSpec{{Name: "status", Type: "string", Number: 1}},
+				ResponseFields: []FieldSpec{{Name: "total", Type: "int64", Number: 1}},
+			},
+			{Name: "GetLegacyReport", ReadModel: "LegacyReport"},
+		},
+		ReadModels: []ReadModelSpec{{Name: "ProductView", Aggregate: "Product"}},
+	}
+	config := &GeneratorConfig{ModulePath: "test", OutputDir: tmpDir, PackageName: "test", Overwrite: true}
+	require.NoError(t, NewApplicationGenerator(tmpDir).generateQueries(spec, config))
+
+	path := filepath.Join(tmpDir, "application/query/list_product_view.gen.go")
+	_, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, `"test/infrastructure/readmodel"`)
+	assert.Contains(t, content, `"github.com/akriventsev/potter/framework/adapters/repository"`)
+	assert.Contains(t, content, "store repository.ReadModelStore[*readmodel.ProductView]")
+	assert.Contains(t, content, "func NewListProductViewHandler(store repository.ReadModelStore[*readmodel.ProductView]) *ListProductViewHandler")
+	assert.NotContains(t, content, "TODO")
+
+	// Read model, не объявленная в proto, оставляет поле хранилища пользователю
+	data, err = os.ReadFile(filepath.Join(tmpDir, "application/query/get_legacy_report.gen.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "// TODO: Add read-model repository field here if needed")
+	assert.NotContains(t, string(data), "adapters/repository")
+}
+
+func TestApplicationGenerator_SagaStepsWithCompensation(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	spec := &ParsedSpec{
+		ModuleName: "test",
+		Commands: []CommandSpec{
+			{
+				Name:          "ReserveStock",
+				Aggregate:     "Stock",
+				CompensatedBy: "ReleaseStock",
+				RequestFields: []FieldSpec{
+					{Name: "product_id", Type: "string", Number: 1},
+					{Name: "quantity", Type: "int32", Number: 2},
+				},
+			},
+			{
+				Name:      "ReleaseStock",
+				Aggregate: "Stock",
+				RequestFields: []FieldSpec{
+					{Name: "product_id", Type: "string", Number: 1},
+					{Name: "quantity", Type: "int64", Number: 2},
+					{Name: "reason", Type: "string", Number: 3},
+				},
+			},
+		},
+	}
+	config := &GeneratorConfig{ModulePath: "test", OutputDir: tmpDir, PackageName: "test", Overwrite: true}
+	require.NoError(t, NewApplicationGenerator(tmpDir).generateSagaSteps(spec, config))
+
+	path := filepath.Join(tmpDir, "application/sagas/steps.gen.go")
+	_, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, `"test/application/command"`)
+	assert.Contains(t, content, "func NewReserveStockStep(commandBus transport.CommandBus, cmd command.ReserveStockCommand) *saga.CommandStep {")
+	assert.Contains(t, content, `return saga.NewCommandStep("reserve_stock", commandBus, cmd, ReleaseStockCompensation(cmd))`)
+	assert.Contains(t, content, "func ReleaseStockCompensation(cmd command.ReserveStockCommand) command.ReleaseStockCommand {")
+	assert.Contains(t, content, "\t\tProductId: cmd.ProductId,\n")
+	// Поле с другим типом не копируется
+	assert.NotContains(t, content, "Quantity: cmd.Quantity")
+	assert.Contains(t, content, "остаются нулевыми: Quantity, Reason")
+	assert.NotContains(t, content, "NewReleaseStockStep")
+
+	spec.Commands[0].CompensatedBy = "RestockProduct"
+	assert.Error(t, NewApplicationGenerator(tmpDir).generateSagaSteps(spec, config))
+}
+
+func TestProtoParser_ParseCommandCompensation(t *testing.T) {
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.BytesType)
+	data = protowire.AppendString(data, "Stock")
+	data = protowire.AppendTag(data, 9, protowire.BytesType)
+	data = protowire.AppendString(data, "ReleaseStock")
+
+	opts := NewProtoParser().parseCommandOptions(data)
+	assert.Equal(t, "Stock", opts.Aggregate)
+	assert.Equal(t, "ReleaseStock", opts.CompensatedBy)
+}
@@ -0,0 +1,85 @@
+package codegen
+
+import "fmt"
+
+const (
+	// DecimalModeDecimal денежное/десятичное поле генерируется как github.com/shopspring/decimal.Decimal
+	DecimalModeDecimal = "decimal"
+	// DecimalModeMinorUnits денежное поле генерируется как int64 в минимальных единицах валюты (копейках, центах)
+	DecimalModeMinorUnits = "minor_units"
+
+	// decimalType тип FieldSpec для полей с (potter.field).decimal = "decimal"
+	decimalType = "decimal"
+	// decimalGoType Go тип decimal полей
+	decimalGoType = "decimal.Decimal"
+	// decimalImportPath пакет decimal типа в сгенерированном коде
+	decimalImportPath = "github.com/shopspring/decimal"
+	// defaultDecimalScale число знаков после запятой по умолчанию
+	defaultDecimalScale = 2
+)
+
+// resolveDecimalType возвращает тип поля с учетом опции decimal:
+// "decimal" для DecimalModeDecimal, "int64" для DecimalModeMinorUnits
+func resolveDecimalType(protoType string, rules *FieldRules) string {
+	if rules == nil {
+		return protoType
+	}
+	switch rules.Decimal {
+	case DecimalModeDecimal:
+		return decimalType
+	case DecimalModeMinorUnits:
+		return "int64"
+	}
+	return protoType
+}
+
+// decimalScale возвращает число знаков после запятой decimal поля
+func decimalScale(field FieldSpec) int {
+	if field.Rules != nil && field.Rules.Scale > 0 {
+		return field.Rules.Scale
+	}
+	return defaultDecimalScale
+}
+
+// decimalSQLType возвращает SQL тип колонки decimal поля
+func decimalSQLType(field FieldSpec) string {
+	return fmt.Sprintf("NUMERIC(38, %d)", decimalScale(field))
+}
+
+// fieldsUseDecimal проверяет, есть ли среди полей decimal поля
+func fieldsUseDecimal(fieldSets ...[]FieldSpec) bool {
+	for _, fields := range fieldSets {
+		for _, field := range fields {
+			if field.Type == decimalType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// specUsesDecimal проверяет, есть ли decimal поля в спецификации
+func specUsesDecimal(spec *ParsedSpec) bool {
+	if spec == nil {
+		return false
+	}
+	if aggregatesUseDecimal(spec.Aggregates) || eventsUseDecimal(spec.Events) {
+		return true
+	}
+	for _, cmd := range spec.Commands {
+		if fieldsUseDecimal(cmd.RequestFields) {
+			return true
+		}
+	}
+	for _, query := range spec.Queries {
+		if fieldsUseDecimal(query.RequestFields, query.ResponseFields) {
+			return true
+		}
+	}
+	for _, rm := range spec.ReadModels {
+		if fieldsUseDecimal(rm.Fields) {
+			return true
+		}
+	}
+	return false
+}
@@ -44,6 +44,9 @@ func (g *DomainGenerator) generateAggregates(spec *ParsedSpec, config *Generator
 	content.WriteString("\t\"time\"\n")
 	content.WriteString("\n")
 	content.WriteString("\t\"github.com/google/uuid\"\n")
+	if aggregatesUseDecimal(spec.Aggregates) {
+		content.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
 	content.WriteString(")\n\n")
 
 	// Генерация BaseAggregate
@@ -74,6 +77,9 @@ func (g *DomainGenerator) generateAggregatesUserCode(spec *ParsedSpec, config *G
 	userContent.WriteString("// Вы можете свободно редактировать этот файл - он не будет перезаписан при регенерации.\n\n")
 	userContent.WriteString("import (\n")
 	userContent.WriteString("\t// Add your imports here if needed\n")
+	if aggregatesUseDecimal(spec.Aggregates) {
+		userContent.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
 	userContent.WriteString(")\n\n")
 
 	// Генерация функций обновления для каждого агрегата
@@ -359,6 +365,9 @@ func (g *DomainGenerator) generateEvents(spec *ParsedSpec, config *GeneratorConf
 	content.WriteString("\t\"time\"\n")
 	content.WriteString("\n")
 	content.WriteString("\t\"github.com/google/uuid\"\n")
+	if eventsUseDecimal(spec.Events) {
+		content.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
 	potterPath := ""
 	if config != nil {
 		potterPath = config.PotterImportPath
@@ -598,11 +607,33 @@ func (g *DomainGenerator) protoToGoType(protoType string) string {
 		return "float64"
 	case "float32":
 		return "float32"
+	case decimalType:
+		return decimalGoType
 	default:
 		return protoType
 	}
 }
 
+// aggregatesUseDecimal проверяет, есть ли decimal поля в агрегатах
+func aggregatesUseDecimal(aggregates []AggregateSpec) bool {
+	for _, agg := range aggregates {
+		if fieldsUseDecimal(agg.Fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventsUseDecimal проверяет, есть ли decimal поля в событиях
+func eventsUseDecimal(events []EventSpec) bool {
+	for _, event := range events {
+		if fieldsUseDecimal(event.Fields) {
+			return true
+		}
+	}
+	return false
+}
+
 // toPrivateField конвертирует имя поля в приватное
 func (g *DomainGenerator) toPrivateField(name string) string {
 	if len(name) == 0 {
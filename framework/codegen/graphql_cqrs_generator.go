@@ -0,0 +1,515 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// graphQLDir директория GraphQL схемы, конфигурации gqlgen и резолверов
+const graphQLDir = "api/graphql"
+
+// GraphQLGenerator генератор gqlgen схемы и резолверов, подключенных к CQRS шинам Potter.
+// Запросы становятся полями Query, команды - полями Mutation; входные типы и ответы
+// связываются в gqlgen.yml с командами и запросами application слоя, агрегаты - с доменными
+// типами, поэтому после `gqlgen generate` резолверы не требуют ручной доработки.
+type GraphQLGenerator struct {
+	*BaseGenerator
+	typeMapper *TypeMapper
+}
+
+// NewGraphQLGenerator создает новый генератор GraphQL схемы и резолверов
+func NewGraphQLGenerator(outputDir string) *GraphQLGenerator {
+	return &GraphQLGenerator{
+		BaseGenerator: NewBaseGenerator("graphql-cqrs", outputDir),
+		typeMapper:    NewTypeMapper(),
+	}
+}
+
+// Generate генерирует схему, конфигурацию gqlgen и резолверы
+func (g *GraphQLGenerator) Generate(spec *ParsedSpec, config *GeneratorConfig) error {
+	if config == nil {
+		config = &GeneratorConfig{}
+	}
+
+	if err := g.generateSchema(spec); err != nil {
+		return fmt.Errorf("failed to generate GraphQL schema: %w", err)
+	}
+
+	if err := g.generateGqlgenConfig(spec, config); err != nil {
+		return fmt.Errorf("failed to generate gqlgen config: %w", err)
+	}
+
+	if g.usesDecimal(spec) {
+		if err := g.generateScalars(); err != nil {
+			return fmt.Errorf("failed to generate GraphQL scalars: %w", err)
+		}
+	}
+
+	if err := g.generateResolver(config); err != nil {
+		return fmt.Errorf("failed to generate GraphQL resolver: %w", err)
+	}
+
+	if err := g.generateResolvers(spec, config); err != nil {
+		return fmt.Errorf("failed to generate GraphQL resolvers: %w", err)
+	}
+
+	return nil
+}
+
+// generateSchema генерирует api/graphql/schema.graphql
+func (g *GraphQLGenerator) generateSchema(spec *ParsedSpec) error {
+	var content strings.Builder
+
+	content.WriteString("# Code generated by potter-gen. DO NOT EDIT.\n")
+	content.WriteString("# Запросы Potter - поля Query, команды - поля Mutation.\n\n")
+
+	if g.usesDecimal(spec) {
+		content.WriteString("# Decimal десятичное значение без потери точности, сериализуется строкой\n")
+		content.WriteString("scalar Decimal\n")
+	}
+	if g.usesAny(spec) {
+		content.WriteString("# Any значение без GraphQL типа (вложенные сообщения, ответы без полей)\n")
+		content.WriteString("scalar Any\n")
+	}
+
+	content.WriteString("\n# CommandResult результат отправки команды в CommandBus\n")
+	content.WriteString("type CommandResult {\n")
+	content.WriteString("  accepted: Boolean!\n")
+	content.WriteString("  command: String!\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("type Query {\n")
+	if len(spec.Queries) == 0 {
+		// Схема GraphQL должна содержать Query type
+		content.WriteString("  health: Boolean!\n")
+	}
+	for _, query := range spec.Queries {
+		content.WriteString(fmt.Sprintf("  %s: %s\n", g.queryFieldSignature(query), g.queryReturnType(query)))
+	}
+	content.WriteString("}\n")
+
+	if len(spec.Commands) > 0 {
+		content.WriteString("\ntype Mutation {\n")
+		for _, cmd := range spec.Commands {
+			content.WriteString(fmt.Sprintf("  %s: CommandResult!\n", g.commandFieldSignature(cmd)))
+		}
+		content.WriteString("}\n")
+	}
+
+	for _, agg := range spec.Aggregates {
+		content.WriteString(fmt.Sprintf("\ntype %s {\n", agg.Name))
+		for _, field := range agg.Fields {
+			content.WriteString(fmt.Sprintf("  %s: %s\n", g.typeMapper.toCamelCase(field.Name), g.fieldType(spec, field)))
+		}
+		content.WriteString("}\n")
+	}
+
+	for _, query := range spec.Queries {
+		if len(query.RequestFields) > 0 {
+			g.writeInputType(&content, spec, query.Name+"Input", query.RequestFields)
+		}
+		if len(query.ResponseFields) > 0 {
+			content.WriteString(fmt.Sprintf("\ntype %sResponse {\n", query.Name))
+			for _, field := range query.ResponseFields {
+				content.WriteString(fmt.Sprintf("  %s: %s\n", g.typeMapper.toCamelCase(field.Name), g.fieldType(spec, field)))
+			}
+			content.WriteString("}\n")
+		}
+	}
+
+	for _, cmd := range spec.Commands {
+		if len(cmd.RequestFields) > 0 {
+			g.writeInputType(&content, spec, cmd.Name+"Input", cmd.RequestFields)
+		}
+	}
+
+	return g.writer.WriteFile(graphQLDir+"/schema.graphql", content.String())
+}
+
+// writeInputType записывает input тип с полями сообщения запроса
+func (g *GraphQLGenerator) writeInputType(content *strings.Builder, spec *ParsedSpec, name string, fields []FieldSpec) {
+	content.WriteString(fmt.Sprintf("\ninput %s {\n", name))
+	for _, field := range fields {
+		fieldType := g.fieldType(spec, field)
+		// Агрегаты не могут быть полями input типов
+		if g.isAggregate(spec, field.Type) {
+			fieldType = g.typeMapper.MapProtoType("Any", field.Repeated, field.Optional)
+		}
+		content.WriteString(fmt.Sprintf("  %s: %s\n", g.typeMapper.toCamelCase(field.Name), fieldType))
+	}
+	content.WriteString("}\n")
+}
+
+// queryFieldSignature возвращает имя и аргументы поля Query
+func (g *GraphQLGenerator) queryFieldSignature(query QuerySpec) string {
+	fieldName := g.toLowerCamel(query.Name)
+	if len(query.RequestFields) == 0 {
+		return fieldName
+	}
+	return fmt.Sprintf("%s(input: %sInput!)", fieldName, query.Name)
+}
+
+// queryReturnType возвращает тип поля Query
+func (g *GraphQLGenerator) queryReturnType(query QuerySpec) string {
+	if len(query.ResponseFields) == 0 {
+		return "Any"
+	}
+	return query.Name + "Response"
+}
+
+// commandFieldSignature возвращает имя и аргументы поля Mutation
+func (g *GraphQLGenerator) commandFieldSignature(cmd CommandSpec) string {
+	fieldName := g.toLowerCamel(cmd.Name)
+	if len(cmd.RequestFields) == 0 {
+		return fieldName
+	}
+	return fmt.Sprintf("%s(input: %sInput!)", fieldName, cmd.Name)
+}
+
+// fieldType возвращает GraphQL тип поля: decimal - Decimal, агрегаты - их типы,
+// остальные вложенные сообщения - Any
+func (g *GraphQLGenerator) fieldType(spec *ParsedSpec, field FieldSpec) string {
+	protoType := resolveDecimalType(field.Type, field.Rules)
+	switch {
+	case protoType == decimalType:
+		protoType = "Decimal"
+	case g.isAggregate(spec, protoType):
+	case g.isCustom(protoType):
+		protoType = "Any"
+	}
+	return g.typeMapper.MapProtoType(protoType, field.Repeated, field.Optional)
+}
+
+// generateGqlgenConfig генерирует api/graphql/gqlgen.yml со связыванием типов схемы с Go типами
+func (g *GraphQLGenerator) generateGqlgenConfig(spec *ParsedSpec, config *GeneratorConfig) error {
+	var content strings.Builder
+
+	content.WriteString("# Code generated by potter-gen. DO NOT EDIT.\n")
+	content.WriteString("# Запуск: cd api/graphql && go run github.com/99designs/gqlgen generate\n")
+	content.WriteString("# Резолверы генерирует potter-gen (resolvers.gen.go), поэтому секция resolver не задана.\n")
+	content.WriteString("schema:\n")
+	content.WriteString("  - schema.graphql\n\n")
+	content.WriteString("exec:\n")
+	content.WriteString("  filename: generated.go\n")
+	content.WriteString("  package: graphql\n\n")
+	content.WriteString("model:\n")
+	content.WriteString("  filename: models_gen.go\n")
+	content.WriteString("  package: graphql\n\n")
+	content.WriteString("models:\n")
+
+	bind := func(schemaType, goType string) {
+		content.WriteString(fmt.Sprintf("  %s:\n", schemaType))
+		content.WriteString(fmt.Sprintf("    model: %s\n", goType))
+	}
+	if g.usesDecimal(spec) {
+		bind("Decimal", config.ModulePath+"/"+graphQLDir+".Decimal")
+	}
+	if g.usesAny(spec) {
+		bind("Any", "github.com/99designs/gqlgen/graphql.Any")
+	}
+	for _, agg := range spec.Aggregates {
+		bind(agg.Name, fmt.Sprintf("%s/domain.%s", config.ModulePath, agg.Name))
+	}
+	for _, query := range spec.Queries {
+		if len(query.RequestFields) > 0 {
+			bind(query.Name+"Input", fmt.Sprintf("%s/application/query.%sQuery", config.ModulePath, query.Name))
+		}
+		if len(query.ResponseFields) > 0 {
+			bind(query.Name+"Response", fmt.Sprintf("%s/application/query.%sResponse", config.ModulePath, query.Name))
+		}
+	}
+	for _, cmd := range spec.Commands {
+		if len(cmd.RequestFields) > 0 {
+			bind(cmd.Name+"Input", fmt.Sprintf("%s/application/command.%sCommand", config.ModulePath, cmd.Name))
+		}
+	}
+
+	return g.writer.WriteFile(graphQLDir+"/gqlgen.yml", content.String())
+}
+
+// generateScalars генерирует маршалинг скаляра Decimal для gqlgen
+func (g *GraphQLGenerator) generateScalars() error {
+	var content strings.Builder
+
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+	content.WriteString("package graphql\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"encoding/json\"\n")
+	content.WriteString("\t\"fmt\"\n\n")
+	content.WriteString("\tgqlgen \"github.com/99designs/gqlgen/graphql\"\n")
+	content.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	content.WriteString(")\n\n")
+
+	content.WriteString("// MarshalDecimal сериализует decimal.Decimal строкой без потери точности\n")
+	content.WriteString("func MarshalDecimal(value decimal.Decimal) gqlgen.Marshaler {\n")
+	content.WriteString("\treturn gqlgen.MarshalString(value.String())\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// UnmarshalDecimal разбирает Decimal из строки или числа\n")
+	content.WriteString("func UnmarshalDecimal(v interface{}) (decimal.Decimal, error) {\n")
+	content.WriteString("\tswitch value := v.(type) {\n")
+	content.WriteString("\tcase string:\n")
+	content.WriteString("\t\treturn decimal.NewFromString(value)\n")
+	content.WriteString("\tcase json.Number:\n")
+	content.WriteString("\t\treturn decimal.NewFromString(value.String())\n")
+	content.WriteString("\tcase int:\n")
+	content.WriteString("\t\treturn decimal.NewFromInt(int64(value)), nil\n")
+	content.WriteString("\tcase int64:\n")
+	content.WriteString("\t\treturn decimal.NewFromInt(value), nil\n")
+	content.WriteString("\tcase float64:\n")
+	content.WriteString("\t\treturn decimal.NewFromFloat(value), nil\n")
+	content.WriteString("\tdefault:\n")
+	content.WriteString("\t\treturn decimal.Decimal{}, fmt.Errorf(\"%T is not a decimal\", v)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n")
+
+	return g.writer.WriteFile(graphQLDir+"/scalars.gen.go", content.String())
+}
+
+// generateResolver генерирует корневой резолвер с зависимостями (пользовательский файл,
+// не перезаписывается)
+func (g *GraphQLGenerator) generateResolver(config *GeneratorConfig) error {
+	path := graphQLDir + "/resolver.go"
+	if g.writer.FileExists(path) {
+		return nil
+	}
+
+	var content strings.Builder
+
+	content.WriteString("package graphql\n\n")
+	content.WriteString("import (\n")
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", potterBaseImportPath(config)))
+	content.WriteString(")\n\n")
+
+	content.WriteString("// Resolver корневой GraphQL резолвер: запросы выполняются через QueryBus, мутации - через CommandBus.\n")
+	content.WriteString("// Зависимости собственных резолверов добавляйте в эту структуру.\n")
+	content.WriteString("type Resolver struct {\n")
+	content.WriteString("\tcommandBus transport.CommandBus\n")
+	content.WriteString("\tqueryBus   transport.QueryBus\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// NewResolver создает корневой GraphQL резолвер\n")
+	content.WriteString("func NewResolver(commandBus transport.CommandBus, queryBus transport.QueryBus) *Resolver {\n")
+	content.WriteString("\treturn &Resolver{\n")
+	content.WriteString("\t\tcommandBus: commandBus,\n")
+	content.WriteString("\t\tqueryBus:   queryBus,\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n")
+
+	return g.writer.WriteFile(path, content.String())
+}
+
+// generateResolvers генерирует резолверы Query и Mutation, отправляющие запросы и команды в шины
+func (g *GraphQLGenerator) generateResolvers(spec *ParsedSpec, config *GeneratorConfig) error {
+	var content strings.Builder
+
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+	content.WriteString("package graphql\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"context\"\n")
+	if len(spec.Queries) > 0 {
+		content.WriteString("\t\"fmt\"\n")
+	}
+	content.WriteString("\n")
+	content.WriteString("\tgqlgen \"github.com/99designs/gqlgen/graphql\"\n")
+	if len(spec.Commands) > 0 {
+		content.WriteString(fmt.Sprintf("\t\"%s/application/command\"\n", config.ModulePath))
+	}
+	if len(spec.Queries) > 0 {
+		content.WriteString(fmt.Sprintf("\t\"%s/application/query\"\n", config.ModulePath))
+	}
+	baseImportPath := potterBaseImportPath(config)
+	if len(spec.Commands) > 0 {
+		content.WriteString(fmt.Sprintf("\t\"%s/framework/invoke\"\n", baseImportPath))
+	}
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", baseImportPath))
+	content.WriteString(")\n\n")
+
+	content.WriteString("// NewSchema создает исполняемую GraphQL схему с резолверами поверх CommandBus и QueryBus\n")
+	content.WriteString("// (для graphqltransport.NewGraphQLAdapter)\n")
+	content.WriteString("func NewSchema(commandBus transport.CommandBus, queryBus transport.QueryBus) gqlgen.ExecutableSchema {\n")
+	content.WriteString("\treturn NewExecutableSchema(Config{Resolvers: NewResolver(commandBus, queryBus)})\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// Query возвращает резолвер запросов\n")
+	content.WriteString("func (r *Resolver) Query() QueryResolver {\n")
+	content.WriteString("\treturn &queryResolver{r}\n")
+	content.WriteString("}\n\n")
+	if len(spec.Commands) > 0 {
+		content.WriteString("// Mutation возвращает резолвер мутаций\n")
+		content.WriteString("func (r *Resolver) Mutation() MutationResolver {\n")
+		content.WriteString("\treturn &mutationResolver{r}\n")
+		content.WriteString("}\n\n")
+	}
+
+	content.WriteString("type queryResolver struct{ *Resolver }\n\n")
+	if len(spec.Commands) > 0 {
+		content.WriteString("type mutationResolver struct{ *Resolver }\n\n")
+	}
+
+	if len(spec.Queries) == 0 {
+		content.WriteString("// Health сообщает о доступности GraphQL API\n")
+		content.WriteString("func (r *queryResolver) Health(ctx context.Context) (bool, error) {\n")
+		content.WriteString("\treturn true, nil\n")
+		content.WriteString("}\n\n")
+	}
+	for _, query := range spec.Queries {
+		g.writeQueryResolver(&content, query)
+	}
+	for _, cmd := range spec.Commands {
+		g.writeMutationResolver(&content, cmd)
+	}
+
+	if len(spec.Queries) > 0 {
+		content.WriteString("// queryResult приводит результат QueryBus (значение или указатель) к ответу запроса\n")
+		content.WriteString("func queryResult[T any](result interface{}) (*T, error) {\n")
+		content.WriteString("\tswitch value := result.(type) {\n")
+		content.WriteString("\tcase nil:\n")
+		content.WriteString("\t\treturn nil, nil\n")
+		content.WriteString("\tcase *T:\n")
+		content.WriteString("\t\treturn value, nil\n")
+		content.WriteString("\tcase T:\n")
+		content.WriteString("\t\treturn &value, nil\n")
+		content.WriteString("\tdefault:\n")
+		content.WriteString("\t\treturn nil, fmt.Errorf(\"unexpected query result type %T\", result)\n")
+		content.WriteString("\t}\n")
+		content.WriteString("}\n")
+	}
+
+	return g.writer.WriteFile(graphQLDir+"/resolvers.gen.go", strings.TrimRight(content.String(), "\n")+"\n")
+}
+
+// writeQueryResolver записывает резолвер поля Query
+func (g *GraphQLGenerator) writeQueryResolver(content *strings.Builder, query QuerySpec) {
+	queryType := fmt.Sprintf("query.%sQuery", query.Name)
+	params := "ctx context.Context"
+	value := queryType + "{}"
+	if len(query.RequestFields) > 0 {
+		params += ", input " + queryType
+		value = "input"
+	}
+	returnType := fmt.Sprintf("*query.%sResponse", query.Name)
+	if len(query.ResponseFields) == 0 {
+		returnType = "interface{}"
+	}
+
+	content.WriteString(fmt.Sprintf("// %s выполняет запрос %s через QueryBus\n", query.Name, query.Name))
+	content.WriteString(fmt.Sprintf("func (r *queryResolver) %s(%s) (%s, error) {\n", query.Name, params, returnType))
+	content.WriteString(fmt.Sprintf("\tresult, err := r.queryBus.Ask(ctx, %s)\n", value))
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\treturn nil, err\n")
+	content.WriteString("\t}\n")
+	if len(query.ResponseFields) == 0 {
+		content.WriteString("\treturn result, nil\n")
+	} else {
+		content.WriteString(fmt.Sprintf("\treturn queryResult[query.%sResponse](result)\n", query.Name))
+	}
+	content.WriteString("}\n\n")
+}
+
+// writeMutationResolver записывает резолвер поля Mutation. Команды помечаются источником api,
+// как и команды REST API (см. invoke.SourcePolicyCommandHandler).
+func (g *GraphQLGenerator) writeMutationResolver(content *strings.Builder, cmd CommandSpec) {
+	commandType := fmt.Sprintf("command.%sCommand", cmd.Name)
+	params := "ctx context.Context"
+	value := commandType + "{}"
+	if len(cmd.RequestFields) > 0 {
+		params += ", input " + commandType
+		value = "input"
+	}
+
+	content.WriteString(fmt.Sprintf("// %s отправляет команду %s в CommandBus\n", cmd.Name, cmd.Name))
+	content.WriteString(fmt.Sprintf("func (r *mutationResolver) %s(%s) (*CommandResult, error) {\n", cmd.Name, params))
+	content.WriteString(fmt.Sprintf("\tcmd := %s\n", value))
+	content.WriteString("\tctx = invoke.WithCommandSource(ctx, invoke.CommandSourceAPI)\n")
+	content.WriteString("\tif err := r.commandBus.Send(ctx, cmd); err != nil {\n")
+	content.WriteString("\t\treturn nil, err\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn &CommandResult{Accepted: true, Command: cmd.CommandName()}, nil\n")
+	content.WriteString("}\n\n")
+}
+
+// usesDecimal проверяет, есть ли в схеме decimal поля
+func (g *GraphQLGenerator) usesDecimal(spec *ParsedSpec) bool {
+	found := false
+	g.eachField(spec, func(field FieldSpec) {
+		if resolveDecimalType(field.Type, field.Rules) == decimalType {
+			found = true
+		}
+	})
+	return found
+}
+
+// usesAny проверяет, нужен ли скаляр Any: вложенные сообщения, не являющиеся агрегатами,
+// агрегаты во входных типах или запросы без полей ответа
+func (g *GraphQLGenerator) usesAny(spec *ParsedSpec) bool {
+	for _, query := range spec.Queries {
+		if len(query.ResponseFields) == 0 {
+			return true
+		}
+		for _, field := range query.RequestFields {
+			if g.isAggregate(spec, field.Type) {
+				return true
+			}
+		}
+	}
+	for _, cmd := range spec.Commands {
+		for _, field := range cmd.RequestFields {
+			if g.isAggregate(spec, field.Type) {
+				return true
+			}
+		}
+	}
+	found := false
+	g.eachField(spec, func(field FieldSpec) {
+		protoType := resolveDecimalType(field.Type, field.Rules)
+		if g.isCustom(protoType) && !g.isAggregate(spec, protoType) {
+			found = true
+		}
+	})
+	return found
+}
+
+// eachField вызывает fn для всех полей, попадающих в схему
+func (g *GraphQLGenerator) eachField(spec *ParsedSpec, fn func(field FieldSpec)) {
+	for _, agg := range spec.Aggregates {
+		for _, field := range agg.Fields {
+			fn(field)
+		}
+	}
+	for _, query := range spec.Queries {
+		for _, field := range query.RequestFields {
+			fn(field)
+		}
+		for _, field := range query.ResponseFields {
+			fn(field)
+		}
+	}
+	for _, cmd := range spec.Commands {
+		for _, field := range cmd.RequestFields {
+			fn(field)
+		}
+	}
+}
+
+// isAggregate проверяет, является ли тип агрегатом
+func (g *GraphQLGenerator) isAggregate(spec *ParsedSpec, protoType string) bool {
+	return findAggregateByName(spec.Aggregates, protoType) != nil
+}
+
+// isCustom проверяет, является ли тип вложенным сообщением
+func (g *GraphQLGenerator) isCustom(protoType string) bool {
+	switch protoType {
+	case "string", "int32", "int64", "float32", "float64", "double", "bool", "bytes", "[]byte", decimalType:
+		return false
+	}
+	return true
+}
+
+// toLowerCamel конвертирует имя метода в имя GraphQL поля (CreateProduct -> createProduct)
+func (g *GraphQLGenerator) toLowerCamel(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
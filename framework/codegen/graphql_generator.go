@@ -243,6 +243,8 @@ func (tm *TypeMapper) MapProtoType(protoType string, repeated bool, optional boo
 		gqlType = "Float"
 	case "bool":
 		gqlType = "Boolean"
+	case decimalType:
+		gqlType = "String" // decimal.Decimal сериализуется строкой
 	case "bytes":
 		gqlType = "String" // Base64 encoded
 	default:
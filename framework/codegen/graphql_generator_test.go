@@ -1,6 +1,8 @@
 package codegen
 
 import (
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
 	"testing"
@@ -158,3 +160,78 @@ func TestSchemaBuilder_BuildSubscriptionField(t *testing.T) {
 	assert.Contains(t, field, "ProductCreatedEvent")
 }
 
+
+func TestGraphQLGenerator_Generate(t *testing.T) {
+	tmpDir := t.TempDir()
+	generator := NewGraphQLGenerator(tmpDir)
+
+	spec := &ParsedSpec{
+		ModuleName: "test",
+		Queries: []QuerySpec{
+			{
+				Name:           "GetProduct",
+				RequestFields:  []FieldSpec{{Name: "product_id", Type: "string", Number: 1}},
+				ResponseFields: []FieldSpec{{Name: "product", Type: "Product", Number: 1, Optional: true}},
+			},
+		},
+		Commands: []CommandSpec{
+			{
+				Name: "CreateProduct",
+				RequestFields: []FieldSpec{
+					{Name: "name", Type: "string", Number: 1},
+					{Name: "price", Type: "string", Number: 2, Rules: &FieldRules{Decimal: DecimalModeDecimal}},
+				},
+			},
+		},
+		Aggregates: []AggregateSpec{
+			{
+				Name: "Product",
+				Fields: []FieldSpec{
+					{Name: "id", Type: "string", Number: 1},
+					{Name: "name", Type: "string", Number: 2},
+				},
+			},
+		},
+	}
+
+	err := generator.Generate(spec, &GeneratorConfig{ModulePath: "example.com/shop"})
+	require.NoError(t, err)
+
+	schema, err := os.ReadFile(filepath.Join(tmpDir, "api/graphql/schema.graphql"))
+	require.NoError(t, err)
+	assert.Contains(t, string(schema), "scalar Decimal")
+	assert.NotContains(t, string(schema), "scalar Any")
+	assert.Contains(t, string(schema), "getProduct(input: GetProductInput!): GetProductResponse")
+	assert.Contains(t, string(schema), "createProduct(input: CreateProductInput!): CommandResult!")
+	assert.Contains(t, string(schema), "input CreateProductInput {\n  name: String!\n  price: Decimal!\n}")
+	assert.Contains(t, string(schema), "input GetProductInput {\n  productId: String!\n}")
+	assert.Contains(t, string(schema), "type GetProductResponse {\n  product: Product\n}")
+
+	gqlgenConfig, err := os.ReadFile(filepath.Join(tmpDir, "api/graphql/gqlgen.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(gqlgenConfig), "model: example.com/shop/application/command.CreateProductCommand")
+	assert.Contains(t, string(gqlgenConfig), "model: example.com/shop/application/query.GetProductQuery")
+	assert.Contains(t, string(gqlgenConfig), "model: example.com/shop/application/query.GetProductResponse")
+	assert.Contains(t, string(gqlgenConfig), "model: example.com/shop/domain.Product")
+	assert.Contains(t, string(gqlgenConfig), "model: example.com/shop/api/graphql.Decimal")
+
+	for _, file := range []string{"resolver.go", "resolvers.gen.go", "scalars.gen.go"} {
+		_, err := parser.ParseFile(token.NewFileSet(), filepath.Join(tmpDir, "api/graphql", file), nil, parser.AllErrors)
+		require.NoError(t, err, file)
+	}
+
+	resolvers, err := os.ReadFile(filepath.Join(tmpDir, "api/graphql/resolvers.gen.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(resolvers), "r.commandBus.Send(ctx, cmd)")
+	assert.Contains(t, string(resolvers), "invoke.WithCommandSource(ctx, invoke.CommandSourceAPI)")
+	assert.Contains(t, string(resolvers), "r.queryBus.Ask(ctx, input)")
+	assert.Contains(t, string(resolvers), "queryResult[query.GetProductResponse](result)")
+
+	// resolver.go принадлежит пользователю и не перезаписывается
+	resolverPath := filepath.Join(tmpDir, "api/graphql/resolver.go")
+	require.NoError(t, os.WriteFile(resolverPath, []byte("package graphql\n"), 0644))
+	require.NoError(t, generator.Generate(spec, &GeneratorConfig{ModulePath: "example.com/shop"}))
+	resolver, err := os.ReadFile(resolverPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package graphql\n", string(resolver))
+}
@@ -61,6 +61,9 @@ func (g *InfrastructureGenerator) generateRepository(agg AggregateSpec, config *
 	content.WriteString("\n")
 	content.WriteString("\t\"github.com/jackc/pgx/v5\"\n")
 	content.WriteString("\t\"github.com/jackc/pgx/v5/pgxpool\"\n")
+	if fieldsUseDecimal(agg.Fields) {
+		content.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
 	content.WriteString(fmt.Sprintf("\t\"%s/domain\"\n", config.ModulePath))
 	content.WriteString(fmt.Sprintf("\t\"%s/infrastructure/cache\"\n", config.ModulePath))
 	content.WriteString(")\n\n")
@@ -412,7 +415,7 @@ func (g *InfrastructureGenerator) generateMigrations(spec *ParsedSpec, _ *Genera
 			if field.Name == "id" {
 				continue
 			}
-			sqlType := g.protoToSQLType(field.Type)
+			sqlType := g.protoToSQLType(field)
 			content.WriteString(fmt.Sprintf("\t%s %s", g.converter.ToSnakeCase(field.Name), sqlType))
 			if field.Name == "id" || !field.Optional {
 				content.WriteString(" NOT NULL")
@@ -480,6 +483,7 @@ type Config struct {
 	NATS     NATSConfig
 	Metrics  MetricsConfig
 	GraphQL  GraphQLConfig
+	Debug    DebugConfig
 }
 
 // ServerConfig конфигурация сервера
@@ -510,6 +514,13 @@ type MetricsConfig struct {
 	Port    int
 }
 
+// DebugConfig конфигурация debug server (pprof, expvar, диагностика)
+type DebugConfig struct {
+	Enabled bool
+	Port    int
+	Token   string
+}
+
 // GraphQLConfig конфигурация GraphQL
 type GraphQLConfig struct {
 	Port              int
@@ -547,6 +558,11 @@ func LoadConfig() *Config {
 			ComplexityLimit:     getEnvAsInt("GRAPHQL_COMPLEXITY_LIMIT", 1000),
 			MaxDepth:            getEnvAsInt("GRAPHQL_MAX_DEPTH", 15),
 		},
+		Debug: DebugConfig{
+			Enabled: getEnvAsBool("DEBUG_ENABLED", false),
+			Port:    getEnvAsInt("DEBUG_PORT", 6060),
+			Token:   getEnv("DEBUG_TOKEN", ""),
+		},
 	}
 }
 
@@ -618,22 +634,29 @@ func (g *InfrastructureGenerator) protoToGoType(protoType string) string {
 		return "float64"
 	case "float32":
 		return "float32"
+	case decimalType:
+		return decimalGoType
 	default:
 		return protoType
 	}
 }
 
-// protoToSQLType конвертирует proto тип в SQL тип
-func (g *InfrastructureGenerator) protoToSQLType(protoType string) string {
-	switch protoType {
+// protoToSQLType конвертирует тип поля в SQL тип
+func (g *InfrastructureGenerator) protoToSQLType(field FieldSpec) string {
+	switch field.Type {
 	case "string":
 		return "VARCHAR(255)"
-	case "int32", "int64":
+	case "int32":
 		return "INTEGER"
+	case "int64":
+		// BIGINT вмещает суммы в минимальных единицах валюты (decimal = "minor_units")
+		return "BIGINT"
 	case "bool":
 		return "BOOLEAN"
 	case "float64", "float32":
 		return "DOUBLE PRECISION"
+	case decimalType:
+		return decimalSQLType(field)
 	default:
 		return "TEXT"
 	}
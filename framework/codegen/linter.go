@@ -0,0 +1,358 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LintSeverity уровень нарушения соглашения об именовании
+type LintSeverity string
+
+const (
+	// LintError нарушение, ломающее согласованность контрактов
+	LintError LintSeverity = "error"
+	// LintWarning рекомендация
+	LintWarning LintSeverity = "warning"
+)
+
+// Правила линтера
+const (
+	LintRuleAggregateName    = "aggregate-name"
+	LintRuleEventPastTense   = "event-past-tense"
+	LintRuleEventPrefix      = "event-aggregate-prefix"
+	LintRuleEventSubject     = "event-subject"
+	LintRuleCommandVerb      = "command-imperative"
+	LintRuleCommandAggregate = "command-aggregate"
+	LintRuleCompensation     = "command-compensation"
+	LintRuleErrorCode        = "error-code"
+)
+
+// LintIssue нарушение соглашения об именовании в proto спецификации
+type LintIssue struct {
+	Rule     string
+	Severity LintSeverity
+	// Element элемент спецификации, например "event OrderCreateEvent"
+	Element string
+	Message string
+	// Suggestion исправленное имя (пусто, если автоисправление невозможно)
+	Suggestion string
+}
+
+// String форматирует нарушение для вывода в консоль
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s %s: %s (%s)", i.Severity, i.Element, i.Message, i.Rule)
+}
+
+var (
+	pascalCasePattern     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	snakeCasePattern      = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnakeCasePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+)
+
+// irregularPastTense неправильные глаголы в прошедшем времени, допустимые в именах событий
+var irregularPastTense = map[string]bool{
+	"bought": true, "built": true, "chosen": true, "done": true, "drawn": true,
+	"frozen": true, "given": true, "held": true, "hidden": true, "kept": true,
+	"left": true, "lost": true, "made": true, "paid": true, "put": true,
+	"read": true, "rebuilt": true, "reset": true, "resent": true, "run": true,
+	"sent": true, "set": true, "shut": true, "sold": true, "spent": true,
+	"split": true, "taken": true, "undone": true, "withdrawn": true, "won": true,
+	"written": true,
+}
+
+// presentTenseEd глаголы в настоящем времени с окончанием -ed
+var presentTenseEd = map[string]bool{
+	"bleed": true, "breed": true, "embed": true, "exceed": true, "feed": true,
+	"need": true, "proceed": true, "seed": true, "shed": true, "shred": true,
+	"speed": true, "succeed": true,
+}
+
+// samePastTense глаголы, совпадающие в настоящем и прошедшем времени
+var samePastTense = map[string]bool{
+	"put": true, "read": true, "reset": true, "run": true, "set": true,
+	"shut": true, "split": true,
+}
+
+// Linter проверяет соглашения об именовании событий, команд, агрегатов и subjects:
+//   - агрегаты в PascalCase;
+//   - события в прошедшем времени с префиксом агрегата (OrderCreatedEvent);
+//   - event_type (subject) в snake_case сегментах через точку с префиксом агрегата (order.created);
+//   - команды в повелительном наклонении с именем агрегата (CreateOrder);
+//   - compensated_by ссылается на другую команду спецификации;
+//   - коды ошибок в UPPER_SNAKE_CASE.
+type Linter struct{}
+
+// NewLinter создает новый Linter
+func NewLinter() *Linter {
+	return &Linter{}
+}
+
+// Lint проверяет спецификацию и возвращает нарушения, упорядоченные по элементу
+func (l *Linter) Lint(spec *ParsedSpec) []LintIssue {
+	var issues []LintIssue
+
+	for _, aggregate := range spec.Aggregates {
+		issues = append(issues, l.lintAggregate(aggregate)...)
+	}
+	for _, event := range spec.Events {
+		issues = append(issues, l.lintEvent(event)...)
+	}
+	for _, command := range spec.Commands {
+		issues = append(issues, l.lintCommand(command)...)
+	}
+	issues = append(issues, l.lintCompensations(spec.Commands)...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Element < issues[j].Element
+	})
+	return issues
+}
+
+// HasLintErrors проверяет, есть ли среди нарушений ошибки
+func HasLintErrors(issues []LintIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Linter) lintAggregate(aggregate AggregateSpec) []LintIssue {
+	if pascalCasePattern.MatchString(aggregate.Name) {
+		return nil
+	}
+	return []LintIssue{{
+		Rule:       LintRuleAggregateName,
+		Severity:   LintError,
+		Element:    "aggregate " + aggregate.Name,
+		Message:    "aggregate name must be PascalCase",
+		Suggestion: toPascalCase(aggregate.Name),
+	}}
+}
+
+func (l *Linter) lintEvent(event EventSpec) []LintIssue {
+	var issues []LintIssue
+	element := "event " + event.Name
+
+	base := strings.TrimSuffix(event.Name, "Event")
+	words := splitWords(base)
+	suggested := base
+
+	if len(words) > 0 && !isPastTense(words[len(words)-1]) {
+		words[len(words)-1] = toPastTense(words[len(words)-1])
+		suggested = strings.Join(words, "")
+		issues = append(issues, LintIssue{
+			Rule:       LintRuleEventPastTense,
+			Severity:   LintError,
+			Element:    element,
+			Message:    "event name must describe a fact in past tense",
+			Suggestion: suggested + "Event",
+		})
+	}
+
+	if event.Aggregate != "" && !strings.HasPrefix(base, event.Aggregate) {
+		suggested = event.Aggregate + suggested
+		issues = append(issues, LintIssue{
+			Rule:       LintRuleEventPrefix,
+			Severity:   LintError,
+			Element:    element,
+			Message:    fmt.Sprintf("event name must start with aggregate name %s", event.Aggregate),
+			Suggestion: suggested + "Event",
+		})
+	}
+
+	if event.EventType != "" {
+		if issue, ok := l.lintEventSubject(event, suggested); ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	if event.IsError && event.ErrorCode != "" && !upperSnakeCasePattern.MatchString(event.ErrorCode) {
+		issues = append(issues, LintIssue{
+			Rule:       LintRuleErrorCode,
+			Severity:   LintError,
+			Element:    element,
+			Message:    fmt.Sprintf("error code %s must be UPPER_SNAKE_CASE", event.ErrorCode),
+			Suggestion: strings.ToUpper(toSnakeCase(event.ErrorCode)),
+		})
+	}
+
+	return issues
+}
+
+// lintEventSubject проверяет event_type, используемый как subject события.
+// suggestedName - имя события (без суффикса Event) после исправлений.
+func (l *Linter) lintEventSubject(event EventSpec, suggestedName string) (LintIssue, bool) {
+	segments := strings.Split(event.EventType, ".")
+	var problems []string
+
+	for _, segment := range segments {
+		if !snakeCasePattern.MatchString(segment) {
+			problems = append(problems, "segments must be snake_case")
+			break
+		}
+	}
+	if len(segments) < 2 {
+		problems = append(problems, "must have <aggregate>.<action> form")
+	}
+	if event.Aggregate != "" && segments[0] != toSnakeCase(event.Aggregate) {
+		problems = append(problems, fmt.Sprintf("must start with aggregate prefix %s", toSnakeCase(event.Aggregate)))
+	}
+	if last := strings.Split(segments[len(segments)-1], "_"); !isPastTense(last[len(last)-1]) {
+		problems = append(problems, "action must be in past tense")
+	}
+
+	if len(problems) == 0 {
+		return LintIssue{}, false
+	}
+
+	// Предлагаемый subject: префикс агрегата и действие из имени события
+	suggestion := toSnakeCase(suggestedName)
+	if event.Aggregate != "" {
+		prefix := toSnakeCase(event.Aggregate)
+		action := strings.TrimPrefix(strings.TrimPrefix(suggestion, prefix), "_")
+		if action != "" {
+			suggestion = prefix + "." + action
+		}
+	}
+
+	return LintIssue{
+		Rule:       LintRuleEventSubject,
+		Severity:   LintError,
+		Element:    "event " + event.Name,
+		Message:    fmt.Sprintf("event_type %q %s", event.EventType, strings.Join(problems, "; ")),
+		Suggestion: suggestion,
+	}, true
+}
+
+func (l *Linter) lintCommand(command CommandSpec) []LintIssue {
+	var issues []LintIssue
+	element := "command " + command.Name
+
+	words := splitWords(command.Name)
+	if len(words) > 0 && isPastTense(words[0]) && !samePastTense[strings.ToLower(words[0])] {
+		issues = append(issues, LintIssue{
+			Rule:     LintRuleCommandVerb,
+			Severity: LintError,
+			Element:  element,
+			Message:  "command name must start with an imperative verb (e.g. CreateOrder), past tense is reserved for events",
+		})
+	}
+
+	if command.Aggregate != "" && !strings.Contains(command.Name, command.Aggregate) {
+		suggestion := ""
+		if len(words) > 0 {
+			suggestion = words[0] + command.Aggregate + strings.Join(words[1:], "")
+		}
+		issues = append(issues, LintIssue{
+			Rule:       LintRuleCommandAggregate,
+			Severity:   LintWarning,
+			Element:    element,
+			Message:    fmt.Sprintf("command name should mention aggregate %s", command.Aggregate),
+			Suggestion: suggestion,
+		})
+	}
+
+	return issues
+}
+
+// lintCompensations проверяет, что компенсирующие команды (compensated_by) определены в спецификации
+func (l *Linter) lintCompensations(commands []CommandSpec) []LintIssue {
+	known := make(map[string]bool, len(commands))
+	for _, command := range commands {
+		known[command.Name] = true
+	}
+
+	var issues []LintIssue
+	for _, command := range commands {
+		switch {
+		case command.CompensatedBy == "":
+		case command.CompensatedBy == command.Name:
+			issues = append(issues, LintIssue{
+				Rule:     LintRuleCompensation,
+				Severity: LintError,
+				Element:  "command " + command.Name,
+				Message:  "command cannot compensate itself",
+			})
+		case !known[command.CompensatedBy]:
+			issues = append(issues, LintIssue{
+				Rule:     LintRuleCompensation,
+				Severity: LintError,
+				Element:  "command " + command.Name,
+				Message:  fmt.Sprintf("compensating command %s is not defined", command.CompensatedBy),
+			})
+		}
+	}
+	return issues
+}
+
+// splitWords разбивает PascalCase имя на слова, сохраняя аббревиатуры (HTTPRequest -> HTTP, Request)
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prevLower := !unicode.IsUpper(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if prevLower || nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// toSnakeCase конвертирует PascalCase в snake_case с учетом аббревиатур
+func toSnakeCase(name string) string {
+	name = strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name)
+	var parts []string
+	for _, part := range strings.Split(name, "_") {
+		for _, word := range splitWords(part) {
+			parts = append(parts, strings.ToLower(word))
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// toPascalCase конвертирует snake_case/camelCase имя в PascalCase
+func toPascalCase(name string) string {
+	var result strings.Builder
+	for _, part := range strings.Split(toSnakeCase(name), "_") {
+		if part != "" {
+			result.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return result.String()
+}
+
+// isPastTense проверяет, что слово - глагол в прошедшем времени
+func isPastTense(word string) bool {
+	lower := strings.ToLower(word)
+	if irregularPastTense[lower] {
+		return true
+	}
+	return strings.HasSuffix(lower, "ed") && !presentTenseEd[lower]
+}
+
+// toPastTense образует прошедшее время правильного глагола (Create -> Created, Apply -> Applied)
+func toPastTense(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "e"):
+		return word + "d"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return word[:len(word)-1] + "ied"
+	default:
+		return word + "ed"
+	}
+}
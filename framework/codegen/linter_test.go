@@ -0,0 +1,72 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinter_ValidSpec(t *testing.T) {
+	spec := &ParsedSpec{
+		Aggregates: []AggregateSpec{{Name: "Order"}},
+		Events: []EventSpec{
+			{Name: "OrderCreatedEvent", EventType: "order.created", Aggregate: "Order"},
+			{Name: "OrderPaidEvent", EventType: "order.paid", Aggregate: "Order"},
+			{Name: "OrderCreationFailedEvent", IsError: true, ErrorCode: "ORDER_CREATION_FAILED"},
+		},
+		Commands: []CommandSpec{
+			{Name: "CreateOrder", Aggregate: "Order"},
+			{Name: "ResetOrder", Aggregate: "Order"},
+			{Name: "ReserveOrder", Aggregate: "Order", CompensatedBy: "ReleaseOrder"},
+			{Name: "ReleaseOrder", Aggregate: "Order"},
+		},
+	}
+
+	issues := NewLinter().Lint(spec)
+	assert.Empty(t, issues)
+	assert.False(t, HasLintErrors(issues))
+}
+
+func TestLinter_Suggestions(t *testing.T) {
+	spec := &ParsedSpec{
+		Aggregates: []AggregateSpec{{Name: "order_item"}},
+		Events: []EventSpec{
+			{Name: "CreateEvent", EventType: "Order.Create", Aggregate: "Order"},
+			{Name: "PaymentFailedEvent", IsError: true, ErrorCode: "paymentFailed"},
+		},
+		Commands: []CommandSpec{
+			{Name: "CreatedOrder", Aggregate: "Order"},
+			{Name: "Cancel", Aggregate: "Order"},
+			{Name: "ChargeOrder", Aggregate: "Order", CompensatedBy: "RefundOrder"},
+		},
+	}
+
+	issues := NewLinter().Lint(spec)
+	assert.True(t, HasLintErrors(issues))
+
+	suggestions := make(map[string]string)
+	for _, issue := range issues {
+		suggestions[issue.Element+" "+issue.Rule] = issue.Suggestion
+	}
+
+	assert.Equal(t, "OrderItem", suggestions["aggregate order_item "+LintRuleAggregateName])
+	assert.Equal(t, "CreatedEvent", suggestions["event CreateEvent "+LintRuleEventPastTense])
+	assert.Equal(t, "OrderCreatedEvent", suggestions["event CreateEvent "+LintRuleEventPrefix])
+	assert.Equal(t, "order.created", suggestions["event CreateEvent "+LintRuleEventSubject])
+	assert.Equal(t, "PAYMENT_FAILED", suggestions["event PaymentFailedEvent "+LintRuleErrorCode])
+	assert.Equal(t, "CancelOrder", suggestions["command Cancel "+LintRuleCommandAggregate])
+
+	_, ok := suggestions["command CreatedOrder "+LintRuleCommandVerb]
+	assert.True(t, ok)
+	_, ok = suggestions["command ChargeOrder "+LintRuleCompensation]
+	assert.True(t, ok)
+}
+
+func TestLinter_NameHelpers(t *testing.T) {
+	assert.Equal(t, []string{"HTTP", "Request", "Sent"}, splitWords("HTTPRequestSent"))
+	assert.Equal(t, "http_request_sent", toSnakeCase("HTTPRequestSent"))
+	assert.Equal(t, "Applied", toPastTense("Apply"))
+	assert.Equal(t, "Created", toPastTense("Create"))
+	assert.False(t, isPastTense("Seed"))
+	assert.True(t, isPastTense("Sent"))
+}
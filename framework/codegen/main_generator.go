@@ -89,7 +89,6 @@ func (g *MainGenerator) generateMain(spec *ParsedSpec, config *GeneratorConfig)
 
 	content.WriteString("\t\"github.com/jackc/pgx/v5/pgxpool\"\n")
 	content.WriteString("\t\"github.com/redis/go-redis/v9\"\n")
-	content.WriteString("\t\"go.opentelemetry.io/otel/sdk/metric\"\n")
 	content.WriteString(fmt.Sprintf("\t\"%s/application/command\"\n", config.ModulePath))
 	content.WriteString(fmt.Sprintf("\t\"%s/application/query\"\n", config.ModulePath))
 	content.WriteString(fmt.Sprintf("\t\"%s/config\"\n", config.ModulePath))
@@ -118,7 +117,7 @@ func (g *MainGenerator) generateMain(spec *ParsedSpec, config *GeneratorConfig)
 		content.WriteString(fmt.Sprintf("\tgraphqltransport \"%s/framework/adapters/transport\"\n", baseImportPath))
 	}
 	content.WriteString(fmt.Sprintf("\t\"%s/framework/events\"\n", baseImportPath))
-	content.WriteString(fmt.Sprintf("\t\"%s/framework/metrics\"\n", baseImportPath))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/observability\"\n", baseImportPath))
 	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", baseImportPath))
 	content.WriteString(")\n\n")
 
@@ -127,25 +126,27 @@ func (g *MainGenerator) generateMain(spec *ParsedSpec, config *GeneratorConfig)
 	content.WriteString("\tcfg := config.LoadConfig()\n\n")
 	content.WriteString("\tctx, cancel := context.WithCancel(context.Background())\n")
 	content.WriteString("\tdefer cancel()\n\n")
-	content.WriteString("\t// Инициализация метрик\n")
-	content.WriteString("\tvar metricsProvider *metric.MeterProvider\n")
+	// Ресурс, сэмплирование и экспорт трейсов, метрик и логов настраиваются одним вызовом
+	// observability.Setup по стандартным переменным OTEL_* (см. .env.example)
+	content.WriteString("\t// Инициализация observability: трейсы, метрики и логи (переменные OTEL_*, LOG_LEVEL, LOG_FORMAT)\n")
+	content.WriteString(fmt.Sprintf("\tobservabilityConfig := observability.ConfigFromEnv(%q)\n", spec.ModuleName))
 	content.WriteString("\tif cfg.Metrics.Enabled {\n")
-	content.WriteString("\t\tmetricsConfig := &metrics.MetricsConfig{\n")
-	content.WriteString("\t\t\tExporterType:   \"prometheus\",\n")
-	content.WriteString("\t\t\tPrometheusPort: cfg.Metrics.Port,\n")
-	content.WriteString("\t\t\tSamplingRate:   1.0,\n")
-	content.WriteString("\t\t}\n")
-	content.WriteString("\t\tprovider, err := metrics.SetupMetrics(metricsConfig)\n")
-	content.WriteString("\t\tif err != nil {\n")
-	content.WriteString("\t\t\tlog.Fatalf(\"Failed to setup metrics: %v\", err)\n")
+	content.WriteString("\t\tobservabilityConfig.Metrics.PrometheusAddr = fmt.Sprintf(\":%d\", cfg.Metrics.Port)\n")
+	content.WriteString("\t\tobservabilityConfig.Metrics.RuntimeMetrics = true\n")
+	content.WriteString("\t} else {\n")
+	content.WriteString("\t\tobservabilityConfig.Metrics.Exporter = observability.ExporterNone\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\ttelemetry, err := observability.Setup(observabilityConfig)\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\tlog.Fatalf(\"Failed to setup observability: %v\", err)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tdefer func() {\n")
+	content.WriteString("\t\tshutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)\n")
+	content.WriteString("\t\tdefer shutdownCancel()\n")
+	content.WriteString("\t\tif err := telemetry.Shutdown(shutdownCtx); err != nil {\n")
+	content.WriteString("\t\t\tlog.Printf(\"Failed to shutdown observability: %v\", err)\n")
 	content.WriteString("\t\t}\n")
-	content.WriteString("\t\tmetricsProvider = provider\n")
-	content.WriteString("\t\tdefer func() {\n")
-	content.WriteString("\t\t\tif err := metrics.ShutdownMetrics(ctx, metricsProvider); err != nil {\n")
-	content.WriteString("\t\t\t\tlog.Printf(\"Failed to shutdown metrics: %v\", err)\n")
-	content.WriteString("\t\t\t}\n")
-	content.WriteString("\t\t}()\n")
-	content.WriteString("\t}\n\n")
+	content.WriteString("\t}()\n\n")
 	content.WriteString("\t// Создание PostgreSQL connection pool\n")
 	content.WriteString("\tdb, err := pgxpool.New(ctx, cfg.Database.DSN)\n")
 	content.WriteString("\tif err != nil {\n")
@@ -187,8 +188,8 @@ func (g *MainGenerator) generateMain(spec *ParsedSpec, config *GeneratorConfig)
 	// Создание EventPublisher и EventBus
 	content.WriteString("\t// Создание EventPublisher\n")
 	content.WriteString("\teventConfig := adapterevents.NATSEventConfig{\n")
-	content.WriteString("\t\tConn:          natsAdapter.Conn(),\n")
-	content.WriteString("\t\tSubjectPrefix: \"events\",\n")
+	content.WriteString("\t\tConn:   natsAdapter.Conn(),\n")
+	content.WriteString("\t\tNaming: events.NewSubjectNaming(events.DefaultSubjectTemplate),\n")
 	content.WriteString("\t}\n")
 	content.WriteString("\teventPublisher, err := adapterevents.NewNATSEventAdapter(eventConfig)\n")
 	content.WriteString("\tif err != nil {\n")
@@ -211,6 +212,23 @@ func (g *MainGenerator) generateMain(spec *ParsedSpec, config *GeneratorConfig)
 	}
 	content.WriteString("\n")
 
+	// Debug server (pprof, expvar, диагностика шины событий) включается явно и требует token
+	content.WriteString("\t// Debug server для диагностики production окружения (DEBUG_ENABLED, DEBUG_TOKEN)\n")
+	content.WriteString("\tvar debugServer *observability.DebugServer\n")
+	content.WriteString("\tif cfg.Debug.Enabled {\n")
+	content.WriteString("\t\tdebugServer = observability.NewDebugServer(observability.DebugServerConfig{\n")
+	content.WriteString("\t\t\tAddr:  fmt.Sprintf(\":%d\", cfg.Debug.Port),\n")
+	content.WriteString("\t\t\tToken: cfg.Debug.Token,\n")
+	content.WriteString("\t\t})\n")
+	content.WriteString("\t\tif statsBus, ok := interface{}(eventBus).(events.EventBusStatsProvider); ok {\n")
+	content.WriteString("\t\t\tdebugServer.WithEventBus(\"events\", statsBus)\n")
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\tif err := debugServer.Start(ctx); err != nil {\n")
+	content.WriteString("\t\t\tlog.Fatalf(\"Failed to start debug server: %v\", err)\n")
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\tlog.Printf(\"Debug server started on port %d\", cfg.Debug.Port)\n")
+	content.WriteString("\t}\n\n")
+
 	// Создание CommandBus и QueryBus
 	content.WriteString("\t// Создание CommandBus и QueryBus\n")
 	content.WriteString("\tcommandBus := transport.NewInMemoryCommandBus()\n")
@@ -294,10 +312,13 @@ func (g *MainGenerator) generateMain(spec *ParsedSpec, config *GeneratorConfig)
 	// Создание REST handler и сервера
 	if hasREST {
 		content.WriteString("\t// Создание REST handler\n")
-		content.WriteString("\trestHandler := rest.NewHandler(commandBus, queryBus)\n\n")
+		content.WriteString("\t// Сообщения об ошибках локализуются по Accept-Language (каталог в presentation/rest/messages.go)\n")
+		content.WriteString("\trestHandler := rest.NewHandler(commandBus, queryBus).WithErrorLocalizer(rest.NewErrorCatalog())\n\n")
 		content.WriteString("\t// Настройка Gin router\n")
 		content.WriteString("\trouter := gin.Default()\n")
-		content.WriteString("\trestHandler.RegisterRoutes(router)\n\n")
+		content.WriteString("\trestHandler.RegisterRoutes(router)\n")
+		content.WriteString("\t// Статус саг: маршруты отвечают после регистрации saga.SagaQueryHandler в queryBus\n")
+		content.WriteString("\trestHandler.RegisterSagaRoutes(router)\n\n")
 		content.WriteString("\t// Запуск REST HTTP сервера\n")
 		content.WriteString("\trestServer = &http.Server{\n")
 		content.WriteString("\t\tAddr:    fmt.Sprintf(\":%d\", cfg.Server.Port),\n")
@@ -378,6 +399,13 @@ func (g *MainGenerator) generateMain(spec *ParsedSpec, config *GeneratorConfig)
 		content.WriteString("\t}\n\n")
 	}
 
+	content.WriteString("\t// Остановка debug server\n")
+	content.WriteString("\tif debugServer != nil {\n")
+	content.WriteString("\t\tif err := debugServer.Stop(shutdownCtx); err != nil {\n")
+	content.WriteString("\t\t\tlog.Printf(\"Error during debug server shutdown: %v\", err)\n")
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t}\n\n")
+
 	content.WriteString("\tlog.Println(\"Application stopped\")\n")
 	content.WriteString("}\n")
 
@@ -571,10 +599,31 @@ REDIS_DB=0
 # NATS Configuration
 NATS_URL=nats://localhost:4222
 
-# Metrics Configuration
+# Metrics Configuration (Prometheus scrape endpoint /metrics)
 METRICS_ENABLED=true
 METRICS_PORT=2112
 
+# Observability Configuration (observability.Setup)
+# OTEL_SERVICE_NAME по умолчанию - имя модуля из спецификации
+OTEL_SERVICE_NAME=
+OTEL_SERVICE_VERSION=
+OTEL_DEPLOYMENT_ENVIRONMENT=development
+OTEL_RESOURCE_ATTRIBUTES=
+# otlp, jaeger, zipkin, stdout или none
+OTEL_TRACES_EXPORTER=otlp
+OTEL_EXPORTER_OTLP_ENDPOINT=localhost:4318
+OTEL_EXPORTER_OTLP_INSECURE=true
+OTEL_TRACES_SAMPLER=parentbased_traceidratio
+OTEL_TRACES_SAMPLER_ARG=1.0
+LOG_LEVEL=info
+LOG_FORMAT=json
+
+# Debug Server Configuration (pprof, expvar, event bus introspection)
+# Все endpoints требуют заголовок "Authorization: Bearer $DEBUG_TOKEN"
+DEBUG_ENABLED=false
+DEBUG_PORT=6060
+DEBUG_TOKEN=
+
 # GraphQL Configuration
 GRAPHQL_PORT=8082
 GRAPHQL_PLAYGROUND_ENABLED=true
@@ -621,7 +670,11 @@ func (g *MainGenerator) generateREADME(_ *ParsedSpec, config *GeneratorConfig) e
 }
 
 // generateGoMod генерирует go.mod
-func (g *MainGenerator) generateGoMod(_ *ParsedSpec, config *GeneratorConfig) error {
+func (g *MainGenerator) generateGoMod(spec *ParsedSpec, config *GeneratorConfig) error {
+	decimalRequire := ""
+	if specUsesDecimal(spec) {
+		decimalRequire = fmt.Sprintf("\t%s v1.4.0\n", decimalImportPath)
+	}
 	content := fmt.Sprintf(`module %s
 
 go 1.21
@@ -632,7 +685,7 @@ require (
 	github.com/jackc/pgx/v5 v5.5.1
 	github.com/nats-io/nats.go v1.31.0
 	github.com/redis/go-redis/v9 v9.3.0
-	go.opentelemetry.io/otel v1.37.0
+%s	go.opentelemetry.io/otel v1.37.0
 	go.opentelemetry.io/otel/exporters/prometheus v0.44.0
 	go.opentelemetry.io/otel/metric v1.37.0
 	go.opentelemetry.io/otel/sdk/metric v1.37.0
@@ -640,7 +693,7 @@ require (
 
 // Potter framework будет добавлен автоматически при инициализации модулей через 'go get @main'.
 // Если автоматическая инициализация не удалась, выполните: make deps
-`, config.ModulePath)
+`, config.ModulePath, decimalRequire)
 
 	return g.writer.WriteFile("go.mod", content)
 }
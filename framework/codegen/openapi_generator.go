@@ -98,6 +98,10 @@ func (g *OpenAPIGenerator) generateOpenAPISpec(spec *ParsedSpec, config *Generat
 		content.WriteString("\n")
 	}
 
+	// Маршруты статуса саг (presentation/rest/sagas.gen.go)
+	content.WriteString(g.schemaBuilder.BuildSagaPaths())
+	content.WriteString("\n")
+
 	// Components
 	content.WriteString("components:\n")
 	content.WriteString("  schemas:\n")
@@ -125,6 +129,9 @@ func (g *OpenAPIGenerator) generateOpenAPISpec(spec *ParsedSpec, config *Generat
 		}
 	}
 
+	content.WriteString(g.schemaBuilder.BuildSagaSchemas())
+	content.WriteString("\n")
+
 	// Генерация schemas из агрегатов
 	for _, agg := range spec.Aggregates {
 		schemaDef := g.schemaBuilder.BuildSchemaFromAggregate(agg)
@@ -207,6 +214,12 @@ func (tm *OpenAPITypeMapper) MapProtoType(protoType string, repeated bool, optio
 				return "float"
 			}(),
 		}
+	case decimalType:
+		// decimal.Decimal сериализуется в JSON строкой
+		schema = map[string]interface{}{
+			"type":   "string",
+			"format": "decimal",
+		}
 	case "bool":
 		schema = map[string]interface{}{
 			"type": "boolean",
@@ -445,6 +458,147 @@ func (sb *OpenAPISchemaBuilder) BuildPathFromQuery(query QuerySpec) string {
 	return builder.String()
 }
 
+// BuildSagaPaths генерирует GET paths статуса, истории и списка саг
+func (sb *OpenAPISchemaBuilder) BuildSagaPaths() string {
+	var builder strings.Builder
+
+	sagaOperation := func(operationID, summary, schema string, params []string) {
+		builder.WriteString("    get:\n")
+		builder.WriteString("      summary: " + summary + "\n")
+		builder.WriteString("      operationId: " + operationID + "\n")
+		builder.WriteString("      tags:\n")
+		builder.WriteString("        - sagas\n")
+		builder.WriteString("      parameters:\n")
+		for _, param := range params {
+			builder.WriteString(param)
+		}
+		builder.WriteString("      responses:\n")
+		builder.WriteString("        '200':\n")
+		builder.WriteString("          description: Success\n")
+		builder.WriteString("          content:\n")
+		builder.WriteString("            application/json:\n")
+		builder.WriteString("              schema:\n")
+		builder.WriteString(fmt.Sprintf("                $ref: '#/components/schemas/%s'\n", schema))
+		builder.WriteString("        '500':\n")
+		builder.WriteString("          description: Internal Server Error\n")
+	}
+	idParam := "        - name: id\n          in: path\n          required: true\n          schema:\n            type: string\n"
+	queryParam := func(name, schemaType string) string {
+		return fmt.Sprintf("        - name: %s\n          in: query\n          required: false\n          schema:\n            type: %s\n", name, schemaType)
+	}
+
+	builder.WriteString("  /sagas:\n")
+	sagaOperation("ListSagas", "List sagas", "SagaListResponse", []string{
+		queryParam("status", "string"),
+		queryParam("definition", "string"),
+		queryParam("correlation_id", "string"),
+		queryParam("limit", "integer"),
+		queryParam("offset", "integer"),
+	})
+	builder.WriteString("  /sagas/export:\n")
+	builder.WriteString("    get:\n")
+	builder.WriteString("      summary: Export sagas as CSV or NDJSON\n")
+	builder.WriteString("      operationId: ExportSagas\n")
+	builder.WriteString("      tags:\n")
+	builder.WriteString("        - sagas\n")
+	builder.WriteString("      parameters:\n")
+	for _, param := range []string{
+		queryParam("format", "string"),
+		queryParam("status", "string"),
+		queryParam("definition", "string"),
+		queryParam("correlation_id", "string"),
+		queryParam("limit", "integer"),
+		queryParam("offset", "integer"),
+	} {
+		builder.WriteString(param)
+	}
+	builder.WriteString("      responses:\n")
+	builder.WriteString("        '200':\n")
+	builder.WriteString("          description: Success\n")
+	builder.WriteString("          content:\n")
+	builder.WriteString("            text/csv:\n")
+	builder.WriteString("              schema:\n")
+	builder.WriteString("                type: string\n")
+	builder.WriteString("            application/x-ndjson:\n")
+	builder.WriteString("              schema:\n")
+	builder.WriteString("                type: string\n")
+	builder.WriteString("        '500':\n")
+	builder.WriteString("          description: Internal Server Error\n")
+	builder.WriteString("  /sagas/{id}:\n")
+	sagaOperation("GetSagaStatus", "Get saga status", "SagaStatusResponse", []string{idParam})
+	builder.WriteString("  /sagas/{id}/history:\n")
+	sagaOperation("GetSagaHistory", "Get saga step history", "SagaHistoryResponse", []string{idParam})
+
+	return builder.String()
+}
+
+// BuildSagaSchemas генерирует schemas ответов saga.SagaQueryHandler.
+// Поля ответов сериализуются без json тегов (имена Go полей), длительности - в наносекундах.
+func (sb *OpenAPISchemaBuilder) BuildSagaSchemas() string {
+	var builder strings.Builder
+
+	object := func(name string, properties [][2]string) {
+		builder.WriteString(fmt.Sprintf("    %s:\n", name))
+		builder.WriteString("      type: object\n")
+		builder.WriteString("      properties:\n")
+		for _, property := range properties {
+			builder.WriteString(fmt.Sprintf("        %s:\n", property[0]))
+			for _, line := range strings.Split(property[1], "\n") {
+				builder.WriteString("          " + line + "\n")
+			}
+		}
+	}
+	const (
+		str      = "type: string"
+		integer  = "type: integer"
+		dateTime = "type: string\nformat: date-time"
+		nullTime = "type: string\nformat: date-time\nnullable: true"
+		duration = "type: integer\nformat: int64\nnullable: true\ndescription: Duration in nanoseconds"
+		failure  = "allOf:\n  - $ref: '#/components/schemas/SagaFailure'\nnullable: true"
+		anyMap   = "type: object\nadditionalProperties: true"
+		status   = "$ref: '#/components/schemas/SagaStatus'"
+	)
+
+	builder.WriteString("    SagaStatus:\n")
+	builder.WriteString("      type: string\n")
+	builder.WriteString("      enum: [pending, running, completed, compensating, compensated, failed, compensation_stuck, waiting_approval]\n")
+
+	object("SagaFailure", [][2]string{
+		{"category", "type: string\nenum: [business, technical, timeout, compensation]"},
+		{"code", str},
+		{"message", str},
+		{"retryable", "type: boolean"},
+		{"details", anyMap},
+	})
+	object("SagaStatusResponse", [][2]string{
+		{"SagaID", str}, {"DefinitionName", str}, {"Status", status}, {"CurrentStep", str},
+		{"TotalSteps", integer}, {"CompletedSteps", integer}, {"FailedSteps", integer},
+		{"StartedAt", dateTime}, {"CompletedAt", nullTime}, {"Duration", duration},
+		{"CorrelationID", str}, {"Context", anyMap},
+		{"LastError", "type: string\nnullable: true"}, {"LastFailure", failure}, {"RetryCount", integer},
+		{"SLADeadline", nullTime}, {"SLARemaining", duration}, {"SLABreached", "type: boolean"},
+	})
+	object("SagaStepHistory", [][2]string{
+		{"StepName", str}, {"Status", str}, {"StartedAt", dateTime}, {"CompletedAt", nullTime},
+		{"Duration", duration}, {"RetryAttempt", integer}, {"Error", "type: string\nnullable: true"},
+		{"Failure", failure}, {"StackTrace", "type: string\nnullable: true"},
+	})
+	object("SagaHistoryResponse", [][2]string{
+		{"SagaID", str},
+		{"History", "type: array\nitems:\n  $ref: '#/components/schemas/SagaStepHistory'"},
+	})
+	object("SagaSummary", [][2]string{
+		{"SagaID", str}, {"DefinitionName", str}, {"Status", status}, {"CurrentStep", str},
+		{"StartedAt", dateTime}, {"CompletedAt", nullTime}, {"CorrelationID", str},
+	})
+	object("SagaListResponse", [][2]string{
+		{"Sagas", "type: array\nitems:\n  $ref: '#/components/schemas/SagaSummary'"},
+		{"Total", integer}, {"Limit", integer}, {"Offset", integer},
+	})
+
+	return builder.String()
+}
+
 // BuildSchemaFromAggregate генерирует schema из агрегата
 func (sb *OpenAPISchemaBuilder) BuildSchemaFromAggregate(agg AggregateSpec) string {
 	var builder strings.Builder
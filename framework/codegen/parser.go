@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
@@ -24,6 +25,7 @@ type ParsedSpec struct {
 	Events     []EventSpec
 	Commands   []CommandSpec
 	Queries    []QuerySpec
+	ReadModels []ReadModelSpec
 	ModuleName string
 	Transports []string
 }
@@ -60,6 +62,8 @@ type CommandSpec struct {
 	Summary        string
 	Description    string
 	Deprecated     bool
+	// CompensatedBy имя компенсирующей команды (potter.command compensated_by)
+	CompensatedBy string
 }
 
 // QuerySpec спецификация запроса
@@ -97,6 +101,16 @@ type AggregateSpec struct {
 	Fields     []FieldSpec
 }
 
+// ReadModelSpec спецификация read model
+type ReadModelSpec struct {
+	Name      string
+	Aggregate string
+	Table     string
+	Events    []string
+	KeyField  string
+	Fields    []FieldSpec
+}
+
 // FieldSpec спецификация поля
 type FieldSpec struct {
 	Name     string
@@ -104,6 +118,19 @@ type FieldSpec struct {
 	Number   int32
 	Repeated bool
 	Optional bool
+	Rules    *FieldRules // Правила валидации (potter.field), nil если не заданы
+}
+
+// FieldRules правила валидации поля
+type FieldRules struct {
+	Required bool
+	Min      *float64
+	Max      *float64
+	Regex    string
+	// Decimal представление денежного/десятичного поля: DecimalModeDecimal или DecimalModeMinorUnits
+	Decimal string
+	// Scale число знаков после запятой decimal поля (0 - по умолчанию 2)
+	Scale int
 }
 
 // MessageSpec спецификация сообщения
@@ -122,6 +149,7 @@ type CommandOptions struct {
 	Summary        string
 	Description    string
 	Deprecated     bool
+	CompensatedBy  string
 }
 
 // QueryOptions опции запроса
@@ -148,6 +176,15 @@ type AggregateOptions struct {
 	Repository string
 }
 
+// ReadModelOptions опции read model
+type ReadModelOptions struct {
+	Name      string
+	Aggregate string
+	Table     string
+	Events    []string
+	KeyField  string
+}
+
 // ErrorEventOptions опции события об ошибке
 type ErrorEventOptions struct {
 	ErrorCode string
@@ -168,6 +205,7 @@ func (p *ProtoParser) ParseProtoFile(file *descriptorpb.FileDescriptorProto) (*P
 		Events:     []EventSpec{},
 		Commands:   []CommandSpec{},
 		Queries:    []QuerySpec{},
+		ReadModels: []ReadModelSpec{},
 	}
 
 	// Парсинг сообщений для поиска агрегатов и событий
@@ -197,6 +235,22 @@ func (p *ProtoParser) ParseProtoFile(file *descriptorpb.FileDescriptorProto) (*P
 			})
 		}
 
+		// Проверка на read model
+		if readModelOpts := p.extractReadModelOptions(msg); readModelOpts != nil {
+			name := readModelOpts.Name
+			if name == "" {
+				name = msgSpec.Name
+			}
+			spec.ReadModels = append(spec.ReadModels, ReadModelSpec{
+				Name:      name,
+				Aggregate: readModelOpts.Aggregate,
+				Table:     readModelOpts.Table,
+				Events:    readModelOpts.Events,
+				KeyField:  readModelOpts.KeyField,
+				Fields:    msgSpec.Fields,
+			})
+		}
+
 		// Проверка на error event
 		if errorOpts := p.extractErrorEventOptions(msg); errorOpts != nil {
 			spec.Events = append(spec.Events, EventSpec{
@@ -261,6 +315,7 @@ func (p *ProtoParser) ParseProtoFile(file *descriptorpb.FileDescriptorProto) (*P
 					Summary:        cmdOpts.Summary,
 					Description:    cmdOpts.Description,
 					Deprecated:     cmdOpts.Deprecated,
+					CompensatedBy:  cmdOpts.CompensatedBy,
 				})
 			}
 
@@ -309,12 +364,14 @@ func (p *ProtoParser) parseMessage(msg *descriptorpb.DescriptorProto) *MessageSp
 	}
 
 	for _, field := range msg.Field {
+		rules := p.extractFieldRules(field)
 		spec.Fields = append(spec.Fields, FieldSpec{
 			Name:     *field.Name,
-			Type:     p.resolveFieldType(field),
+			Type:     resolveDecimalType(p.resolveFieldType(field), rules),
 			Number:   *field.Number,
 			Repeated: field.Label != nil && *field.Label == descriptorpb.FieldDescriptorProto_LABEL_REPEATED,
 			Optional: field.Label != nil && *field.Label == descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL,
+			Rules:    rules,
 		})
 	}
 
@@ -487,6 +544,14 @@ func (p *ProtoParser) parseCommandOptions(data []byte) *CommandOptions {
 					data = data[m:]
 				}
 			}
+		case 9: // compensated_by (string)
+			if wireType == protowire.BytesType {
+				val, m := protowire.ConsumeBytes(data)
+				if m >= 0 {
+					opts.CompensatedBy = string(val)
+					data = data[m:]
+				}
+			}
 		default:
 			// Пропускаем неизвестное поле
 			m := protowire.ConsumeFieldValue(tag, wireType, data)
@@ -719,6 +784,147 @@ func (p *ProtoParser) parseAggregateOptions(data []byte) *AggregateOptions {
 	return opts
 }
 
+// extractReadModelOptions извлекает potter.read_model опции (extension номер 50005 для MessageOptions)
+func (p *ProtoParser) extractReadModelOptions(msg *descriptorpb.DescriptorProto) *ReadModelOptions {
+	if msg.Options == nil {
+		return nil
+	}
+
+	optsReflect := msg.Options.ProtoReflect()
+	unknownFields := optsReflect.GetUnknown()
+
+	extData := p.findExtensionInUnknownFields(unknownFields, 50005)
+	if extData == nil {
+		return nil
+	}
+
+	return p.parseReadModelOptions(extData)
+}
+
+// parseReadModelOptions парсит ReadModelOptions из байтов
+func (p *ProtoParser) parseReadModelOptions(data []byte) *ReadModelOptions {
+	opts := &ReadModelOptions{}
+
+	for len(data) > 0 {
+		tag, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			break
+		}
+		data = data[n:]
+
+		if wireType != protowire.BytesType {
+			m := protowire.ConsumeFieldValue(tag, wireType, data)
+			if m < 0 {
+				return opts
+			}
+			data = data[m:]
+			continue
+		}
+
+		val, m := protowire.ConsumeBytes(data)
+		if m < 0 {
+			return opts
+		}
+		data = data[m:]
+
+		switch int(tag) {
+		case 1: // name (string)
+			opts.Name = string(val)
+		case 2: // aggregate (string)
+			opts.Aggregate = string(val)
+		case 3: // table (string)
+			opts.Table = string(val)
+		case 4: // events (repeated string)
+			opts.Events = append(opts.Events, string(val))
+		case 5: // key_field (string)
+			opts.KeyField = string(val)
+		}
+	}
+
+	return opts
+}
+
+// extractFieldRules извлекает potter.field правила (extension номер 50001 для FieldOptions)
+func (p *ProtoParser) extractFieldRules(field *descriptorpb.FieldDescriptorProto) *FieldRules {
+	if field.Options == nil {
+		return nil
+	}
+
+	optsReflect := field.Options.ProtoReflect()
+	unknownFields := optsReflect.GetUnknown()
+
+	extData := p.findExtensionInUnknownFields(unknownFields, 50001)
+	if extData == nil {
+		return nil
+	}
+
+	return p.parseFieldRules(extData)
+}
+
+// parseFieldRules парсит FieldRules из байтов
+func (p *ProtoParser) parseFieldRules(data []byte) *FieldRules {
+	rules := &FieldRules{}
+
+	for len(data) > 0 {
+		tag, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			break
+		}
+		data = data[n:]
+
+		switch {
+		case int(tag) == 1 && wireType == protowire.VarintType: // required (bool)
+			val, m := protowire.ConsumeVarint(data)
+			if m < 0 {
+				return rules
+			}
+			rules.Required = val != 0
+			data = data[m:]
+		case (int(tag) == 2 || int(tag) == 3) && wireType == protowire.Fixed64Type: // min, max (double)
+			val, m := protowire.ConsumeFixed64(data)
+			if m < 0 {
+				return rules
+			}
+			value := math.Float64frombits(val)
+			if int(tag) == 2 {
+				rules.Min = &value
+			} else {
+				rules.Max = &value
+			}
+			data = data[m:]
+		case int(tag) == 4 && wireType == protowire.BytesType: // regex (string)
+			val, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return rules
+			}
+			rules.Regex = string(val)
+			data = data[m:]
+		case int(tag) == 5 && wireType == protowire.BytesType: // decimal (string)
+			val, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return rules
+			}
+			rules.Decimal = string(val)
+			data = data[m:]
+		case int(tag) == 6 && wireType == protowire.VarintType: // scale (uint32)
+			val, m := protowire.ConsumeVarint(data)
+			if m < 0 {
+				return rules
+			}
+			rules.Scale = int(val)
+			data = data[m:]
+		default:
+			m := protowire.ConsumeFieldValue(tag, wireType, data)
+			if m < 0 {
+				return rules
+			}
+			data = data[m:]
+		}
+	}
+
+	return rules
+}
+
 // extractErrorEventOptions извлекает potter.error_event опции (extension номер 50004 для MessageOptions)
 func (p *ProtoParser) extractErrorEventOptions(msg *descriptorpb.DescriptorProto) *ErrorEventOptions {
 	if msg.Options == nil {
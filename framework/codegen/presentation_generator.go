@@ -50,6 +50,9 @@ func (g *PresentationGenerator) Generate(spec *ParsedSpec, config *GeneratorConf
 		if err := g.generateRESTHandler(spec, config); err != nil {
 			return fmt.Errorf("failed to generate REST handler: %w", err)
 		}
+		if err := g.generateSagaRoutes(config); err != nil {
+			return fmt.Errorf("failed to generate saga routes: %w", err)
+		}
 	}
 
 	// Генерация OpenAPI интеграции
@@ -88,6 +91,8 @@ func (g *PresentationGenerator) generateRESTHandler(spec *ParsedSpec, config *Ge
 	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
 	content.WriteString("package rest\n\n")
 	content.WriteString("import (\n")
+	content.WriteString("\t\"errors\"\n")
+	content.WriteString("\t\"net/http\"\n\n")
 	content.WriteString("\t\"github.com/gin-gonic/gin\"\n")
 	potterPath := ""
 	if config != nil {
@@ -98,13 +103,19 @@ func (g *PresentationGenerator) generateRESTHandler(spec *ParsedSpec, config *Ge
 	}
 	// Удаляем @main или другие суффиксы версии для import-путей
 	baseImportPath := strings.Split(potterPath, "@")[0]
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/i18n\"\n", baseImportPath))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/invoke\"\n", baseImportPath))
 	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", baseImportPath))
 	content.WriteString(")\n\n")
 
+	content.WriteString("// errIDRequired ошибка запроса без параметра id в пути\n")
+	content.WriteString("var errIDRequired = errors.New(\"id parameter is required\")\n\n")
+
 	content.WriteString("// Handler REST API handler\n")
 	content.WriteString("type Handler struct {\n")
 	content.WriteString("\tcommandBus transport.CommandBus\n")
 	content.WriteString("\tqueryBus   transport.QueryBus\n")
+	content.WriteString("\tlocalizer  i18n.ErrorLocalizer\n")
 	content.WriteString("}\n\n")
 
 	content.WriteString("// NewHandler создает новый REST handler\n")
@@ -115,9 +126,50 @@ func (g *PresentationGenerator) generateRESTHandler(spec *ParsedSpec, config *Ge
 	content.WriteString("\t}\n")
 	content.WriteString("}\n\n")
 
+	content.WriteString("// WithErrorLocalizer включает локализацию ошибок: язык ответа выбирается по Accept-Language,\n")
+	content.WriteString("// сообщения берутся из каталога по коду ошибки (см. NewErrorCatalog)\n")
+	content.WriteString("func (h *Handler) WithErrorLocalizer(localizer i18n.ErrorLocalizer) *Handler {\n")
+	content.WriteString("\th.localizer = localizer\n")
+	content.WriteString("\treturn h\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// respondError отправляет ошибку с HTTP статусом status. Без локализации ответ имеет вид\n")
+	content.WriteString("// {\"error\": err.Error()}, с локализацией - i18n.ErrorResponse на языке клиента;\n")
+	content.WriteString("// code используется для ошибок без собственного кода.\n")
+	content.WriteString("func (h *Handler) respondError(c *gin.Context, status int, code string, err error) {\n")
+	content.WriteString("\tif h.localizer == nil {\n")
+	content.WriteString("\t\tc.JSON(status, gin.H{\"error\": err.Error()})\n")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tlocale := h.localizer.Negotiate(c.GetHeader(\"Accept-Language\"))\n")
+	content.WriteString("\tc.Header(\"Content-Language\", locale)\n")
+	content.WriteString("\tc.JSON(status, h.localizer.LocalizeError(locale, code, err))\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// exportQuery потоково выгружает результат запроса в CSV/NDJSON (transport.ExportQuery).\n")
+	content.WriteString("// Ошибка до первой строки возвращается обычным ответом об ошибке.\n")
+	content.WriteString("func (h *Handler) exportQuery(c *gin.Context, q transport.Query, format transport.ExportFormat) {\n")
+	content.WriteString("\twriter, err := transport.NewExportWriter(c.Writer, format)\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\th.respondError(c, http.StatusBadRequest, i18n.CodeInvalidRequest, err)\n")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tc.Header(\"Content-Type\", format.ContentType())\n")
+	content.WriteString("\tif err := transport.ExportQuery(c.Request.Context(), h.queryBus, q, writer); err != nil {\n")
+	content.WriteString("\t\tif !c.Writer.Written() {\n")
+	content.WriteString("\t\t\tc.Writer.Header().Del(\"Content-Type\")\n")
+	content.WriteString("\t\t\th.respondError(c, http.StatusInternalServerError, i18n.CodeQueryFailed, err)\n")
+	content.WriteString("\t\t\treturn\n")
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\t_ = c.Error(err)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+
 	content.WriteString("// RegisterRoutes регистрирует все маршруты в соответствии с REST концепцией\n")
 	content.WriteString("func (h *Handler) RegisterRoutes(router *gin.Engine) {\n")
 	content.WriteString("\tapi := router.Group(\"/api/v1\")\n")
+	content.WriteString("\t// Команды из REST API помечаются источником api (см. invoke.SourcePolicyCommandHandler)\n")
+	content.WriteString("\tapi.Use(apiCommandSource)\n")
 	content.WriteString("\t{\n")
 
 	// Группируем команды и запросы по ресурсам (агрегатам)
@@ -126,22 +178,13 @@ func (g *PresentationGenerator) generateRESTHandler(spec *ParsedSpec, config *Ge
 
 	// Группируем команды по агрегатам
 	for _, cmd := range spec.Commands {
-		resourceName := g.converter.ToSnakeCase(cmd.Aggregate)
-		// Делаем множественное число для REST ресурсов
-		if !strings.HasSuffix(resourceName, "s") {
-			resourceName = resourceName + "s"
-		}
+		resourceName := g.commandResource(cmd)
 		resourceRoutes[resourceName] = append(resourceRoutes[resourceName], cmd)
 	}
 
 	// Группируем запросы по агрегатам (определяем по имени запроса или по связанному агрегату)
 	for _, query := range spec.Queries {
-		// Сначала пытаемся определить ресурс из имени запроса
-		resourceName := g.inferResourceFromQuery(query.Name)
-		// Нормализуем к множественному числу для REST
-		if !strings.HasSuffix(resourceName, "s") {
-			resourceName = resourceName + "s"
-		}
+		resourceName := g.queryResource(query)
 		resourceQueries[resourceName] = append(resourceQueries[resourceName], query)
 	}
 
@@ -174,6 +217,12 @@ func (g *PresentationGenerator) generateRESTHandler(spec *ParsedSpec, config *Ge
 	content.WriteString("\t// Swagger UI доступен на /swagger/ (если зарегистрирован)\n")
 	content.WriteString("}\n")
 
+	content.WriteString("\n// apiCommandSource помечает контекст запроса источником команд invoke.CommandSourceAPI\n")
+	content.WriteString("func apiCommandSource(c *gin.Context) {\n")
+	content.WriteString("\tc.Request = c.Request.WithContext(invoke.WithCommandSource(c.Request.Context(), invoke.CommandSourceAPI))\n")
+	content.WriteString("\tc.Next()\n")
+	content.WriteString("}\n")
+
 	path := "presentation/rest/handler.gen.go"
 	if err := g.writer.WriteFile(path, content.String()); err != nil {
 		return err
@@ -183,6 +232,100 @@ func (g *PresentationGenerator) generateRESTHandler(spec *ParsedSpec, config *Ge
 	return g.generateRESTHandlerUserCode(spec, config)
 }
 
+// generateSagaRoutes генерирует REST маршруты статуса саг поверх saga.SagaQueryHandler
+func (g *PresentationGenerator) generateSagaRoutes(config *GeneratorConfig) error {
+	var content strings.Builder
+
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+	content.WriteString("package rest\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"net/http\"\n")
+	content.WriteString("\t\"strconv\"\n")
+	content.WriteString("\n")
+	content.WriteString("\t\"github.com/gin-gonic/gin\"\n")
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/i18n\"\n", potterBaseImportPath(config)))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/saga\"\n", potterBaseImportPath(config)))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", potterBaseImportPath(config)))
+	content.WriteString(")\n\n")
+
+	content.WriteString("// RegisterSagaRoutes регистрирует маршруты статуса саг:\n")
+	content.WriteString("//\n")
+	content.WriteString("//\tGET /api/v1/sagas              - список саг (status, definition, correlation_id, limit, offset)\n")
+	content.WriteString("//\tGET /api/v1/sagas/export       - потоковая выгрузка саг в CSV (format=ndjson - NDJSON)\n")
+	content.WriteString("//\tGET /api/v1/sagas/:id          - статус саги\n")
+	content.WriteString("//\tGET /api/v1/sagas/:id/history  - история шагов саги\n")
+	content.WriteString("//\n")
+	content.WriteString("// Запросы выполняются через queryBus: зарегистрируйте saga.NewSagaQueryHandler\n")
+	content.WriteString("// для запросов GetSagaStatus, GetSagaHistory и ListSagas. Список саг выгружается\n")
+	content.WriteString("// в CSV/NDJSON и по заголовку Accept; выгрузка читает саги постранично.\n")
+	content.WriteString("func (h *Handler) RegisterSagaRoutes(router *gin.Engine) {\n")
+	content.WriteString("\tsagas := router.Group(\"/api/v1/sagas\")\n")
+	content.WriteString("\tsagas.GET(\"\", h.listSagas)\n")
+	content.WriteString("\tsagas.GET(\"/export\", h.exportSagas)\n")
+	content.WriteString("\tsagas.GET(\"/:id\", h.getSagaStatus)\n")
+	content.WriteString("\tsagas.GET(\"/:id/history\", h.getSagaHistory)\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("func (h *Handler) getSagaStatus(c *gin.Context) {\n")
+	content.WriteString("\th.askSaga(c, &saga.GetSagaStatusQuery{SagaID: c.Param(\"id\")})\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("func (h *Handler) getSagaHistory(c *gin.Context) {\n")
+	content.WriteString("\th.askSaga(c, &saga.GetSagaHistoryQuery{SagaID: c.Param(\"id\")})\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("func (h *Handler) listSagas(c *gin.Context) {\n")
+	content.WriteString("\tquery := sagaListQuery(c, 50)\n")
+	content.WriteString("\tif format := transport.NegotiateExportFormat(c.GetHeader(\"Accept\")); format.IsExport() {\n")
+	content.WriteString("\t\th.exportQuery(c, query, format)\n")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\th.askSaga(c, query)\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// exportSagas выгружает все саги, подходящие под фильтр (limit не ограничен по умолчанию)\n")
+	content.WriteString("func (h *Handler) exportSagas(c *gin.Context) {\n")
+	content.WriteString("\tformat := transport.ExportFormatCSV\n")
+	content.WriteString("\tif c.Query(\"format\") == string(transport.ExportFormatNDJSON) {\n")
+	content.WriteString("\t\tformat = transport.ExportFormatNDJSON\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\th.exportQuery(c, sagaListQuery(c, 0), format)\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// sagaListQuery формирует ListSagasQuery из параметров запроса\n")
+	content.WriteString("func sagaListQuery(c *gin.Context, defaultLimit int) *saga.ListSagasQuery {\n")
+	content.WriteString("\tquery := &saga.ListSagasQuery{Limit: defaultLimit}\n")
+	content.WriteString("\tif status := c.Query(\"status\"); status != \"\" {\n")
+	content.WriteString("\t\tsagaStatus := saga.SagaStatus(status)\n")
+	content.WriteString("\t\tquery.Status = &sagaStatus\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif definition := c.Query(\"definition\"); definition != \"\" {\n")
+	content.WriteString("\t\tquery.DefinitionName = &definition\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif correlationID := c.Query(\"correlation_id\"); correlationID != \"\" {\n")
+	content.WriteString("\t\tquery.CorrelationID = &correlationID\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif limit, err := strconv.Atoi(c.Query(\"limit\")); err == nil && limit > 0 {\n")
+	content.WriteString("\t\tquery.Limit = limit\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif offset, err := strconv.Atoi(c.Query(\"offset\")); err == nil && offset > 0 {\n")
+	content.WriteString("\t\tquery.Offset = offset\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn query\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("func (h *Handler) askSaga(c *gin.Context, query transport.Query) {\n")
+	content.WriteString("\tresult, err := h.queryBus.Ask(c.Request.Context(), query)\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString("\t\th.respondError(c, http.StatusInternalServerError, i18n.CodeQueryFailed, err)\n")
+	content.WriteString("\t\treturn\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tc.JSON(http.StatusOK, result)\n")
+	content.WriteString("}\n")
+
+	return g.writer.WriteFile("presentation/rest/sagas.gen.go", content.String())
+}
+
 // generateRESTHandlerUserCode генерирует пользовательский файл с обработчиками REST API
 func (g *PresentationGenerator) generateRESTHandlerUserCode(spec *ParsedSpec, config *GeneratorConfig) error {
 	var userContent strings.Builder
@@ -206,6 +349,15 @@ func (g *PresentationGenerator) generateRESTHandlerUserCode(spec *ParsedSpec, co
 		}
 	}
 
+	// Проверяем, есть ли list запросы с поддержкой выгрузки CSV/NDJSON
+	needsExport := false
+	for _, query := range spec.Queries {
+		if strings.HasPrefix(strings.ToLower(query.Name), "list") {
+			needsExport = true
+			break
+		}
+	}
+
 	userContent.WriteString("import (\n")
 	userContent.WriteString("\t\"net/http\"\n")
 	if needsStrconv {
@@ -213,8 +365,17 @@ func (g *PresentationGenerator) generateRESTHandlerUserCode(spec *ParsedSpec, co
 	}
 	userContent.WriteString("\n")
 	userContent.WriteString("\t\"github.com/gin-gonic/gin\"\n")
+	if queriesUseDecimal(spec.Queries) {
+		userContent.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
 	userContent.WriteString(fmt.Sprintf("\t\"%s/application/command\"\n", config.ModulePath))
 	userContent.WriteString(fmt.Sprintf("\t\"%s/application/query\"\n", config.ModulePath))
+	if len(spec.Commands) > 0 || len(spec.Queries) > 0 {
+		userContent.WriteString(fmt.Sprintf("\t\"%s/framework/i18n\"\n", potterBaseImportPath(config)))
+	}
+	if needsExport {
+		userContent.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", potterBaseImportPath(config)))
+	}
 	userContent.WriteString(")\n\n")
 
 	// Генерация handler методов для команд
@@ -230,7 +391,36 @@ func (g *PresentationGenerator) generateRESTHandlerUserCode(spec *ParsedSpec, co
 	}
 
 	userPath := "presentation/rest/handler.go"
-	return g.writer.WriteFile(userPath, userContent.String())
+	if err := g.writer.WriteFile(userPath, userContent.String()); err != nil {
+		return err
+	}
+
+	return g.generateErrorCatalog(config)
+}
+
+// generateErrorCatalog генерирует пользовательский каталог локализованных сообщений об ошибках
+func (g *PresentationGenerator) generateErrorCatalog(config *GeneratorConfig) error {
+	var content strings.Builder
+
+	content.WriteString("package rest\n\n")
+	content.WriteString("// Этот файл содержит каталог сообщений об ошибках REST API.\n")
+	content.WriteString("// Вы можете свободно редактировать этот файл - добавляйте локали и сообщения доменных ошибок.\n\n")
+	content.WriteString(fmt.Sprintf("import \"%s/framework/i18n\"\n\n", potterBaseImportPath(config)))
+
+	content.WriteString("// NewErrorCatalog создает каталог сообщений об ошибках со встроенными сообщениями (en, ru).\n")
+	content.WriteString("// Ключи каталога - коды ошибок (VALIDATION_FAILED, NOT_FOUND, коды core.FrameworkError\n")
+	content.WriteString("// и ErrorCode() доменных ошибок) и правила валидации: validation.<правило>\n")
+	content.WriteString("// или validation.<поле>.<правило> для сообщения конкретного поля.\n")
+	content.WriteString("func NewErrorCatalog() *i18n.Catalog {\n")
+	content.WriteString("\tcatalog := i18n.NewDefaultCatalog(\"en\")\n")
+	content.WriteString("\t// catalog.Add(\"ru\", map[string]string{\n")
+	content.WriteString("\t// \t\"INSUFFICIENT_STOCK\":  \"Недостаточно товара на складе\",\n")
+	content.WriteString("\t// \t\"validation.sku.regex\": \"Артикул должен иметь вид ABC-123\",\n")
+	content.WriteString("\t// })\n")
+	content.WriteString("\treturn catalog\n")
+	content.WriteString("}\n")
+
+	return g.writer.WriteFile("presentation/rest/messages.go", content.String())
 }
 
 // generateCommandHandler генерирует handler метод для команды
@@ -251,7 +441,7 @@ func (g *PresentationGenerator) generateCommandHandler(cmd CommandSpec) string {
 		builder.WriteString("\t// Извлечение ID из URL параметра\n")
 		builder.WriteString("\tid := c.Param(\"id\")\n")
 		builder.WriteString("\tif id == \"\" {\n")
-		builder.WriteString("\t\tc.JSON(http.StatusBadRequest, gin.H{\"error\": \"id parameter is required\"})\n")
+		builder.WriteString("\t\th.respondError(c, http.StatusBadRequest, i18n.CodeIDRequired, errIDRequired)\n")
 		builder.WriteString("\t\treturn\n")
 		builder.WriteString("\t}\n\n")
 	}
@@ -260,7 +450,7 @@ func (g *PresentationGenerator) generateCommandHandler(cmd CommandSpec) string {
 	if !isDelete {
 		builder.WriteString(fmt.Sprintf("\tvar req command.%sCommand\n", cmd.Name))
 		builder.WriteString("\tif err := c.ShouldBindJSON(&req); err != nil {\n")
-		builder.WriteString("\t\tc.JSON(http.StatusBadRequest, gin.H{\"error\": err.Error()})\n")
+		builder.WriteString("\t\th.respondError(c, http.StatusBadRequest, i18n.CodeInvalidRequest, err)\n")
 		builder.WriteString("\t\treturn\n")
 		builder.WriteString("\t}\n\n")
 	}
@@ -295,7 +485,7 @@ func (g *PresentationGenerator) generateCommandHandler(cmd CommandSpec) string {
 	}
 	builder.WriteString("\t}\n\n")
 	builder.WriteString("\tif err := h.commandBus.Send(c.Request.Context(), cmd); err != nil {\n")
-	builder.WriteString("\t\tc.JSON(http.StatusInternalServerError, gin.H{\"error\": err.Error()})\n")
+	builder.WriteString("\t\th.respondError(c, http.StatusInternalServerError, i18n.CodeCommandFailed, err)\n")
 	builder.WriteString("\t\treturn\n")
 	builder.WriteString("\t}\n\n")
 
@@ -329,7 +519,7 @@ func (g *PresentationGenerator) generateQueryHandler(query QuerySpec) string {
 		builder.WriteString("\t// Извлечение ID из URL параметра\n")
 		builder.WriteString("\tid := c.Param(\"id\")\n")
 		builder.WriteString("\tif id == \"\" {\n")
-		builder.WriteString("\t\tc.JSON(http.StatusBadRequest, gin.H{\"error\": \"id parameter is required\"})\n")
+		builder.WriteString("\t\th.respondError(c, http.StatusBadRequest, i18n.CodeIDRequired, errIDRequired)\n")
 		builder.WriteString("\t\treturn\n")
 		builder.WriteString("\t}\n\n")
 	}
@@ -368,6 +558,8 @@ func (g *PresentationGenerator) generateQueryHandler(query QuerySpec) string {
 				builder.WriteString("\t\t\t}\n")
 				builder.WriteString("\t\t\treturn 0\n")
 				builder.WriteString("\t\t}(),\n")
+			} else if goType == decimalGoType {
+				g.writeDecimalQueryParam(&builder, fieldName, fieldSnake)
 			} else {
 				builder.WriteString(fmt.Sprintf("\t\t%s: c.DefaultQuery(\"%s\", \"\"),\n", fieldName, fieldSnake))
 			}
@@ -390,15 +582,24 @@ func (g *PresentationGenerator) generateQueryHandler(query QuerySpec) string {
 				builder.WriteString("\t\t\t}\n")
 				builder.WriteString("\t\t\treturn 0\n")
 				builder.WriteString("\t\t}(),\n")
+			} else if goType == decimalGoType {
+				g.writeDecimalQueryParam(&builder, fieldName, fieldSnake)
 			} else {
 				builder.WriteString(fmt.Sprintf("\t\t%s: c.Query(\"%s\"),\n", fieldName, fieldSnake))
 			}
 		}
 	}
 	builder.WriteString("\t}\n\n")
+	if isList {
+		// Потоковая выгрузка в CSV/NDJSON по заголовку Accept
+		builder.WriteString("\tif format := transport.NegotiateExportFormat(c.GetHeader(\"Accept\")); format.IsExport() {\n")
+		builder.WriteString("\t\th.exportQuery(c, q, format)\n")
+		builder.WriteString("\t\treturn\n")
+		builder.WriteString("\t}\n\n")
+	}
 	builder.WriteString("\tresult, err := h.queryBus.Ask(c.Request.Context(), q)\n")
 	builder.WriteString("\tif err != nil {\n")
-	builder.WriteString("\t\tc.JSON(http.StatusNotFound, gin.H{\"error\": err.Error()})\n")
+	builder.WriteString("\t\th.respondError(c, http.StatusNotFound, i18n.CodeQueryFailed, err)\n")
 	builder.WriteString("\t\treturn\n")
 	builder.WriteString("\t}\n\n")
 	builder.WriteString("\tc.JSON(http.StatusOK, result)\n")
@@ -591,6 +792,26 @@ func (g *PresentationGenerator) toCamelCase(s string) string {
 	return strings.ToLower(s[:1]) + s[1:]
 }
 
+// commandResource возвращает REST ресурс команды (агрегат во множественном числе)
+func (g *PresentationGenerator) commandResource(cmd CommandSpec) string {
+	resourceName := g.converter.ToSnakeCase(cmd.Aggregate)
+	// Делаем множественное число для REST ресурсов
+	if !strings.HasSuffix(resourceName, "s") {
+		resourceName = resourceName + "s"
+	}
+	return resourceName
+}
+
+// queryResource возвращает REST ресурс запроса, определенный по имени запроса
+func (g *PresentationGenerator) queryResource(query QuerySpec) string {
+	resourceName := g.inferResourceFromQuery(query.Name)
+	// Нормализуем к множественному числу для REST
+	if !strings.HasSuffix(resourceName, "s") {
+		resourceName = resourceName + "s"
+	}
+	return resourceName
+}
+
 // getRESTRouteForCommand определяет HTTP метод и маршрут для команды в соответствии с REST
 func (g *PresentationGenerator) getRESTRouteForCommand(cmd CommandSpec, resourceName string) (string, string) {
 	cmdName := strings.ToLower(cmd.Name)
@@ -855,6 +1076,8 @@ func (g *PresentationGenerator) protoToGoType(protoType string, repeated bool) s
 		goType = "float32"
 	case "[]byte":
 		goType = "[]byte"
+	case decimalType:
+		goType = decimalGoType
 	default:
 		// Для пользовательских типов (например, Item) возвращаем как есть
 		goType = protoType
@@ -867,6 +1090,24 @@ func (g *PresentationGenerator) protoToGoType(protoType string, repeated bool) s
 	return goType
 }
 
+// writeDecimalQueryParam генерирует разбор decimal параметра запроса из URL
+func (g *PresentationGenerator) writeDecimalQueryParam(builder *strings.Builder, fieldName, fieldSnake string) {
+	builder.WriteString(fmt.Sprintf("\t\t%s: func() decimal.Decimal {\n", fieldName))
+	builder.WriteString(fmt.Sprintf("\t\t\tparsed, _ := decimal.NewFromString(c.Query(\"%s\"))\n", fieldSnake))
+	builder.WriteString("\t\t\treturn parsed\n")
+	builder.WriteString("\t\t}(),\n")
+}
+
+// queriesUseDecimal проверяет, есть ли decimal параметры у запросов
+func queriesUseDecimal(queries []QuerySpec) bool {
+	for _, query := range queries {
+		if fieldsUseDecimal(query.RequestFields) {
+			return true
+		}
+	}
+	return false
+}
+
 // toPublicField конвертирует имя поля в публичное (с заглавной буквы)
 func (g *PresentationGenerator) toPublicField(name string) string {
 	if len(name) == 0 {
@@ -0,0 +1,46 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresentationGenerator_ErrorLocalization(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := &GeneratorConfig{ModulePath: "github.com/acme/shop", OutputDir: tmpDir, Overwrite: true}
+	require.NoError(t, NewPresentationGenerator(tmpDir).Generate(typeScriptSDKTestSpec(), config))
+
+	// Сгенерированный Go код должен быть синтаксически корректным
+	files := map[string]string{}
+	for _, name := range []string{"handler.gen.go", "handler.go", "sagas.gen.go", "messages.go"} {
+		path := filepath.Join(tmpDir, "presentation/rest", name)
+		_, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors)
+		require.NoError(t, err, path)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		files[name] = string(data)
+	}
+
+	assert.Contains(t, files["handler.gen.go"], "func (h *Handler) WithErrorLocalizer(localizer i18n.ErrorLocalizer) *Handler {")
+	assert.Contains(t, files["handler.gen.go"], "locale := h.localizer.Negotiate(c.GetHeader(\"Accept-Language\"))")
+	assert.Contains(t, files["handler.go"], "h.respondError(c, http.StatusBadRequest, i18n.CodeIDRequired, errIDRequired)")
+	assert.Contains(t, files["handler.go"], "h.respondError(c, http.StatusBadRequest, i18n.CodeInvalidRequest, err)")
+	assert.Contains(t, files["handler.go"], "h.respondError(c, http.StatusInternalServerError, i18n.CodeCommandFailed, err)")
+	assert.Contains(t, files["handler.go"], "h.respondError(c, http.StatusNotFound, i18n.CodeQueryFailed, err)")
+	assert.NotContains(t, files["handler.go"], "gin.H{\"error\"")
+	assert.Contains(t, files["sagas.gen.go"], "h.respondError(c, http.StatusInternalServerError, i18n.CodeQueryFailed, err)")
+	assert.Contains(t, files["messages.go"], "func NewErrorCatalog() *i18n.Catalog {")
+
+	// Выгрузка CSV/NDJSON выполняется потоково через transport.ExportQuery
+	assert.Contains(t, files["handler.gen.go"], "transport.ExportQuery(c.Request.Context(), h.queryBus, q, writer)")
+	assert.Contains(t, files["sagas.gen.go"], "sagas.GET(\"/export\", h.exportSagas)")
+	assert.Contains(t, files["sagas.gen.go"], "h.exportQuery(c, query, format)")
+	assert.NotContains(t, files["handler.go"], "transport.WriteExport")
+}
@@ -0,0 +1,698 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReadModelGenerator генератор read models, аннотированных potter.read_model:
+// структура read model и PostgreSQL хранилище (repository.ReadModelStore),
+// миграция таблицы, проекция событий агрегата и query handlers
+type ReadModelGenerator struct {
+	*BaseGenerator
+}
+
+// NewReadModelGenerator создает новый генератор read models
+func NewReadModelGenerator(outputDir string) *ReadModelGenerator {
+	return &ReadModelGenerator{
+		BaseGenerator: NewBaseGenerator("read_model", outputDir),
+	}
+}
+
+// Generate генерирует read models
+func (g *ReadModelGenerator) Generate(spec *ParsedSpec, config *GeneratorConfig) error {
+	if len(spec.ReadModels) == 0 {
+		return nil
+	}
+	if config == nil {
+		config = &GeneratorConfig{}
+	}
+
+	for _, rm := range spec.ReadModels {
+		if err := g.generateReadModel(rm, spec, config); err != nil {
+			return fmt.Errorf("failed to generate read model %s: %w", rm.Name, err)
+		}
+		if err := g.generateReadModelUserCode(rm, config); err != nil {
+			return fmt.Errorf("failed to generate user code for read model %s: %w", rm.Name, err)
+		}
+		if err := g.generateQueryHandlers(rm, spec, config); err != nil {
+			return fmt.Errorf("failed to generate query handlers for read model %s: %w", rm.Name, err)
+		}
+	}
+
+	if err := g.generateMigration(spec); err != nil {
+		return fmt.Errorf("failed to generate read model migration: %w", err)
+	}
+
+	return nil
+}
+
+// readModelColumn колонка read model
+type readModelColumn struct {
+	field  FieldSpec
+	goName string
+	goType string
+	column string
+	json   bool // repeated или message поле, хранится в JSONB
+}
+
+// generateReadModel генерирует структуру read model, хранилище и проекцию
+func (g *ReadModelGenerator) generateReadModel(rm ReadModelSpec, spec *ParsedSpec, config *GeneratorConfig) error {
+	columns := g.columns(rm)
+	key := columns[0]
+	handled := g.handledEvents(rm, spec)
+	storeName := rm.Name + "Store"
+	projectionName := rm.Name + "Projection"
+	notFound := fmt.Sprintf("Err%sNotFound", rm.Name)
+	receiver := strings.ToLower(rm.Name[:1])
+
+	needsJSON, needsDecimal := false, false
+	for _, col := range columns {
+		if col.json {
+			needsJSON = true
+		}
+		if col.goType == decimalGoType {
+			needsDecimal = true
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+	content.WriteString("package readmodel\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"context\"\n")
+	if needsJSON {
+		content.WriteString("\t\"encoding/json\"\n")
+	}
+	content.WriteString("\t\"errors\"\n")
+	content.WriteString("\t\"fmt\"\n")
+	content.WriteString("\t\"strings\"\n")
+	content.WriteString("\t\"time\"\n")
+	content.WriteString("\n")
+	content.WriteString("\t\"github.com/jackc/pgx/v5\"\n")
+	content.WriteString("\t\"github.com/jackc/pgx/v5/pgxpool\"\n")
+	if needsDecimal {
+		content.WriteString(fmt.Sprintf("\t\"%s\"\n", decimalImportPath))
+	}
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/adapters/repository\"\n", potterBaseImportPath(config)))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/eventsourcing\"\n", potterBaseImportPath(config)))
+	if len(handled) > 0 {
+		content.WriteString(fmt.Sprintf("\t\"%s/domain\"\n", config.ModulePath))
+	}
+	content.WriteString(")\n\n")
+
+	// Структура read model
+	if rm.Aggregate != "" {
+		content.WriteString(fmt.Sprintf("// %s read model агрегата %s\n", rm.Name, rm.Aggregate))
+	} else {
+		content.WriteString(fmt.Sprintf("// %s read model\n", rm.Name))
+	}
+	content.WriteString(fmt.Sprintf("type %s struct {\n", rm.Name))
+	for _, col := range columns {
+		content.WriteString(fmt.Sprintf("\t%s %s `json:\"%s\" db:\"%s\"`\n", col.goName, col.goType, col.column, col.column))
+	}
+	content.WriteString("\tVersion   int64     `json:\"version\" db:\"version\"`\n")
+	content.WriteString("\tUpdatedAt time.Time `json:\"updated_at\" db:\"updated_at\"`\n")
+	content.WriteString("}\n\n")
+
+	var columnNames, scanArgs, placeholders, updates, filterColumns []string
+	for i, col := range columns {
+		columnNames = append(columnNames, col.column)
+		scanArgs = append(scanArgs, "&row."+col.goName)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		if i > 0 {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", col.column, col.column))
+		}
+		if !col.json {
+			filterColumns = append(filterColumns, col.column)
+		}
+	}
+	columnNames = append(columnNames, "version", "updated_at")
+	scanArgs = append(scanArgs, "&row.Version", "&row.UpdatedAt")
+	placeholders = append(placeholders, fmt.Sprintf("$%d", len(columns)+1), fmt.Sprintf("$%d", len(columns)+2))
+	updates = append(updates, "version = EXCLUDED.version", "updated_at = EXCLUDED.updated_at")
+	filterColumns = append(filterColumns, "version", "updated_at")
+	selectColumns := strings.Join(columnNames, ", ")
+	snakeName := g.converter.ToSnakeCase(rm.Name)
+	columnsVar := g.lowerFirst(rm.Name) + "Columns"
+
+	// Хранилище
+	content.WriteString(fmt.Sprintf("// %s read model не найдена (совместима с repository.ErrReadModelNotFound)\n", notFound))
+	content.WriteString(fmt.Sprintf("var %s = fmt.Errorf(\"%s: %%w\", repository.ErrReadModelNotFound)\n\n", notFound, snakeName))
+
+	content.WriteString(fmt.Sprintf("// %s колонки read model, доступные для фильтров и сортировки\n", columnsVar))
+	content.WriteString(fmt.Sprintf("var %s = map[string]bool{\n", columnsVar))
+	for _, column := range filterColumns {
+		content.WriteString(fmt.Sprintf("\t%q: true,\n", column))
+	}
+	content.WriteString("}\n\n")
+
+	content.WriteString(fmt.Sprintf("// %s хранилище read model %s в PostgreSQL (реализует repository.ReadModelStore)\n", storeName, rm.Name))
+	content.WriteString(fmt.Sprintf("type %s struct {\n", storeName))
+	content.WriteString("\tdb    *pgxpool.Pool\n")
+	content.WriteString("\ttable string\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString(fmt.Sprintf("// New%s создает новое хранилище read model\n", storeName))
+	content.WriteString(fmt.Sprintf("func New%s(db *pgxpool.Pool) *%s {\n", storeName, storeName))
+	content.WriteString(fmt.Sprintf("\treturn &%s{\n", storeName))
+	content.WriteString("\t\tdb:    db,\n")
+	content.WriteString(fmt.Sprintf("\t\ttable: %q,\n", g.tableName(rm)))
+	content.WriteString("\t}\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString(fmt.Sprintf("// New%sReadModelStore создает хранилище read model по конфигурации: для PostgreSQL -\n", rm.Name))
+	content.WriteString(fmt.Sprintf("// %s с таблицей из миграции, для остальных backends - документное хранилище repository\n", storeName))
+	content.WriteString(fmt.Sprintf("func New%sReadModelStore(config repository.ReadModelStoreConfig) (repository.ReadModelStore[*%s], error) {\n", rm.Name, rm.Name))
+	content.WriteString("\tif config.Backend == \"\" || config.Backend == repository.ReadModelBackendPostgres {\n")
+	content.WriteString("\t\tif config.Postgres == nil {\n")
+	content.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"postgres pool is required for read model %s\")\n", snakeName))
+	content.WriteString("\t\t}\n")
+	content.WriteString(fmt.Sprintf("\t\treturn New%s(config.Postgres), nil\n", storeName))
+	content.WriteString("\t}\n")
+	content.WriteString(fmt.Sprintf("\treturn repository.NewReadModelStore[*%s](config, %q)\n", rm.Name, g.tableName(rm)))
+	content.WriteString("}\n\n")
+
+	content.WriteString(fmt.Sprintf("// Get возвращает read model по %s\n", key.field.Name))
+	content.WriteString(fmt.Sprintf("func (s *%s) Get(ctx context.Context, id string) (*%s, error) {\n", storeName, rm.Name))
+	content.WriteString(fmt.Sprintf("\tquery := fmt.Sprintf(\"SELECT %s FROM %%s WHERE %s = $1\", s.table)\n\n", selectColumns, key.column))
+	content.WriteString(fmt.Sprintf("\trow := &%s{}\n", rm.Name))
+	content.WriteString(fmt.Sprintf("\terr := s.db.QueryRow(ctx, query, id).Scan(%s)\n", strings.Join(scanArgs, ", ")))
+	content.WriteString("\tif errors.Is(err, pgx.ErrNoRows) {\n")
+	content.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%%w: %%s\", %s, id)\n", notFound))
+	content.WriteString("\t}\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"failed to get %s: %%w\", err)\n", snakeName))
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn row, nil\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// List возвращает read models, подходящие под фильтр. Поля фильтра и сортировки -\n")
+	content.WriteString("// колонки таблицы (JSONB колонки не поддерживаются).\n")
+	content.WriteString(fmt.Sprintf("func (s *%s) List(ctx context.Context, filter repository.ReadModelFilter) ([]*%s, error) {\n", storeName, rm.Name))
+	content.WriteString("\tvar conditions []string\n")
+	content.WriteString("\tvar args []interface{}\n")
+	content.WriteString("\tfor field, value := range filter.Where {\n")
+	content.WriteString(fmt.Sprintf("\t\tif !%s[field] {\n", columnsVar))
+	content.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"unknown %s field: %%s\", field)\n", snakeName))
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\targs = append(args, value)\n")
+	content.WriteString("\t\tconditions = append(conditions, fmt.Sprintf(\"%s = $%d\", field, len(args)))\n")
+	content.WriteString("\t}\n")
+	content.WriteString(fmt.Sprintf("\torderBy := %q\n", key.column))
+	content.WriteString("\tif filter.OrderBy != \"\" {\n")
+	content.WriteString(fmt.Sprintf("\t\tif !%s[filter.OrderBy] {\n", columnsVar))
+	content.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"unknown %s field: %%s\", filter.OrderBy)\n", snakeName))
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\torderBy = filter.OrderBy\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tdirection := \"ASC\"\n")
+	content.WriteString("\tif filter.Desc {\n")
+	content.WriteString("\t\tdirection = \"DESC\"\n")
+	content.WriteString("\t}\n\n")
+	content.WriteString(fmt.Sprintf("\tquery := fmt.Sprintf(\"SELECT %s FROM %%s\", s.table)\n", selectColumns))
+	content.WriteString("\tif len(conditions) > 0 {\n")
+	content.WriteString("\t\tquery += \" WHERE \" + strings.Join(conditions, \" AND \")\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tquery += fmt.Sprintf(\" ORDER BY %s %s\", orderBy, direction)\n")
+	content.WriteString(fmt.Sprintf("\tif orderBy != %q {\n", key.column))
+	content.WriteString(fmt.Sprintf("\t\tquery += \", %s \" + direction\n", key.column))
+	content.WriteString("\t}\n")
+	content.WriteString("\tif filter.Limit > 0 {\n")
+	content.WriteString("\t\targs = append(args, filter.Limit)\n")
+	content.WriteString("\t\tquery += fmt.Sprintf(\" LIMIT $%d\", len(args))\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\tif filter.Offset > 0 {\n")
+	content.WriteString("\t\targs = append(args, filter.Offset)\n")
+	content.WriteString("\t\tquery += fmt.Sprintf(\" OFFSET $%d\", len(args))\n")
+	content.WriteString("\t}\n\n")
+	content.WriteString("\trows, err := s.db.Query(ctx, query, args...)\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"failed to list %s: %%w\", err)\n", snakeName))
+	content.WriteString("\t}\n")
+	content.WriteString("\tdefer rows.Close()\n\n")
+	content.WriteString(fmt.Sprintf("\tvar result []*%s\n", rm.Name))
+	content.WriteString("\tfor rows.Next() {\n")
+	content.WriteString(fmt.Sprintf("\t\trow := &%s{}\n", rm.Name))
+	content.WriteString(fmt.Sprintf("\t\tif err := rows.Scan(%s); err != nil {\n", strings.Join(scanArgs, ", ")))
+	content.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"failed to scan %s: %%w\", err)\n", snakeName))
+	content.WriteString("\t\t}\n")
+	content.WriteString("\t\tresult = append(result, row)\n")
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn result, rows.Err()\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString(fmt.Sprintf("// Upsert создает или обновляет read model. Ключ берется из поля %s read model.\n", key.goName))
+	content.WriteString(fmt.Sprintf("func (s *%s) Upsert(ctx context.Context, id string, row *%s) error {\n", storeName, rm.Name))
+	content.WriteString(fmt.Sprintf("\tquery := fmt.Sprintf(\"INSERT INTO %%s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s\", s.table)\n\n",
+		selectColumns, strings.Join(placeholders, ", "), key.column, strings.Join(updates, ", ")))
+	content.WriteString("\t_, err := s.db.Exec(ctx, query,\n")
+	for _, col := range columns {
+		content.WriteString(fmt.Sprintf("\t\trow.%s,\n", col.goName))
+	}
+	content.WriteString("\t\trow.Version,\n")
+	content.WriteString("\t\trow.UpdatedAt,\n")
+	content.WriteString("\t)\n")
+	content.WriteString("\tif err != nil {\n")
+	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to save %s %%s: %%w\", id, err)\n", snakeName))
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn nil\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// Delete удаляет read model\n")
+	content.WriteString(fmt.Sprintf("func (s *%s) Delete(ctx context.Context, id string) error {\n", storeName))
+	content.WriteString(fmt.Sprintf("\tquery := fmt.Sprintf(\"DELETE FROM %%s WHERE %s = $1\", s.table)\n", key.column))
+	content.WriteString("\tif _, err := s.db.Exec(ctx, query, id); err != nil {\n")
+	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to delete %s %%s: %%w\", id, err)\n", snakeName))
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn nil\n")
+	content.WriteString("}\n\n")
+
+	content.WriteString("// Clear удаляет все read models\n")
+	content.WriteString(fmt.Sprintf("func (s *%s) Clear(ctx context.Context) error {\n", storeName))
+	content.WriteString("\tif _, err := s.db.Exec(ctx, fmt.Sprintf(\"TRUNCATE TABLE %s\", s.table)); err != nil {\n")
+	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"failed to truncate %s: %%w\", err)\n", snakeName))
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn nil\n")
+	content.WriteString("}\n\n")
+
+	// Проекция
+	if rm.Aggregate != "" {
+		content.WriteString(fmt.Sprintf("// %s проекция событий агрегата %s в read model %s\n", projectionName, rm.Aggregate, rm.Name))
+	} else {
+		content.WriteString(fmt.Sprintf("// %s проекция событий в read model %s\n", projectionName, rm.Name))
+	}
+	content.WriteString(fmt.Sprintf("type %s struct {\n", projectionName))
+	content.WriteString(fmt.Sprintf("\tstore repository.ReadModelStore[*%s]\n", rm.Name))
+	content.WriteString("}\n\n")
+
+	content.WriteString(fmt.Sprintf("// New%s создает новую проекцию\n", projectionName))
+	content.WriteString(fmt.Sprintf("func New%s(store repository.ReadModelStore[*%s]) *%s {\n", projectionName, rm.Name, projectionName))
+	content.WriteString(fmt.Sprintf("\treturn &%s{store: store}\n", projectionName))
+	content.WriteString("}\n\n")
+
+	content.WriteString("// Name возвращает имя проекции\n")
+	content.WriteString(fmt.Sprintf("func (%s *%s) Name() string {\n", receiver, projectionName))
+	content.WriteString(fmt.Sprintf("\treturn %q\n", snakeName))
+	content.WriteString("}\n\n")
+
+	content.WriteString("// HandleEvent применяет событие к read model агрегата\n")
+	content.WriteString(fmt.Sprintf("func (%s *%s) HandleEvent(ctx context.Context, event eventsourcing.StoredEvent) error {\n", receiver, projectionName))
+	content.WriteString(fmt.Sprintf("\tapply := %sApplier(event)\n", g.lowerFirst(rm.Name)))
+	content.WriteString("\tif apply == nil {\n")
+	content.WriteString("\t\treturn nil\n")
+	content.WriteString("\t}\n\n")
+	content.WriteString(fmt.Sprintf("\trow, err := %s.store.Get(ctx, event.AggregateID)\n", receiver))
+	content.WriteString("\tif errors.Is(err, repository.ErrReadModelNotFound) {\n")
+	content.WriteString(fmt.Sprintf("\t\trow = &%s{%s: event.AggregateID}\n", rm.Name, key.goName))
+	content.WriteString("\t} else if err != nil {\n")
+	content.WriteString("\t\treturn err\n")
+	content.WriteString("\t}\n\n")
+	content.WriteString("\tapply(row)\n")
+	content.WriteString("\trow.Version = event.Version\n")
+	content.WriteString("\trow.UpdatedAt = event.OccurredAt\n")
+	content.WriteString("\tif row.UpdatedAt.IsZero() {\n")
+	content.WriteString("\t\trow.UpdatedAt = time.Now()\n")
+	content.WriteString("\t}\n")
+	content.WriteString(fmt.Sprintf("\treturn %s.store.Upsert(ctx, event.AggregateID, row)\n", receiver))
+	content.WriteString("}\n\n")
+
+	content.WriteString("// Reset очищает read model перед пересозданием проекции\n")
+	content.WriteString(fmt.Sprintf("func (%s *%s) Reset(ctx context.Context) error {\n", receiver, projectionName))
+	content.WriteString(fmt.Sprintf("\tclearer, ok := %s.store.(repository.ReadModelClearer)\n", receiver))
+	content.WriteString("\tif !ok {\n")
+	content.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"read model store %s does not support reset\")\n", snakeName))
+	content.WriteString("\t}\n")
+	content.WriteString("\treturn clearer.Clear(ctx)\n")
+	content.WriteString("}\n\n")
+
+	// Применение событий
+	content.WriteString(fmt.Sprintf("// %sApplier возвращает функцию применения события к read model или nil,\n", g.lowerFirst(rm.Name)))
+	content.WriteString("// если событие не обрабатывается проекцией\n")
+	content.WriteString(fmt.Sprintf("func %sApplier(event eventsourcing.StoredEvent) func(*%s) {\n", g.lowerFirst(rm.Name), rm.Name))
+	if len(handled) > 0 {
+		content.WriteString("\tswitch e := event.EventData.(type) {\n")
+		for _, event := range handled {
+			applyFunc := fmt.Sprintf("apply%s%s", rm.Name, event.Name)
+			content.WriteString(fmt.Sprintf("\tcase *domain.%s:\n", event.Name))
+			content.WriteString(fmt.Sprintf("\t\treturn func(row *%s) { %s(row, e) }\n", rm.Name, applyFunc))
+			content.WriteString(fmt.Sprintf("\tcase domain.%s:\n", event.Name))
+			content.WriteString(fmt.Sprintf("\t\treturn func(row *%s) { %s(row, &e) }\n", rm.Name, applyFunc))
+		}
+		content.WriteString("\t}\n")
+	}
+	content.WriteString(fmt.Sprintf("\treturn custom%sApplier(event)\n", rm.Name))
+	content.WriteString("}\n")
+
+	for _, event := range handled {
+		applyFunc := fmt.Sprintf("apply%s%s", rm.Name, event.Name)
+		content.WriteString("\n")
+		content.WriteString(fmt.Sprintf("// %s применяет событие %s к read model\n", applyFunc, event.Name))
+		content.WriteString(fmt.Sprintf("func %s(row *%s, e *domain.%s) {\n", applyFunc, rm.Name, event.Name))
+		mapped := 0
+		for _, col := range columns[1:] {
+			if eventField, ok := g.matchEventField(col, event); ok {
+				content.WriteString(fmt.Sprintf("\trow.%s = e.%s\n", col.goName, g.toPublicField(eventField.Name)))
+				mapped++
+			}
+		}
+		if mapped == 0 {
+			content.WriteString("\t// Событие не содержит полей read model, обновляются только версия и время\n")
+		}
+		content.WriteString("}\n")
+	}
+
+	path := fmt.Sprintf("infrastructure/readmodel/%s.gen.go", g.converter.ToSnakeCase(rm.Name))
+	return g.writer.WriteFile(path, content.String())
+}
+
+// generateReadModelUserCode генерирует файл пользовательского кода проекции (только если файла еще нет)
+func (g *ReadModelGenerator) generateReadModelUserCode(rm ReadModelSpec, config *GeneratorConfig) error {
+	userPath := fmt.Sprintf("infrastructure/readmodel/%s.go", g.converter.ToSnakeCase(rm.Name))
+	if g.writer.FileExists(userPath) {
+		return nil
+	}
+
+	var userContent strings.Builder
+	userContent.WriteString("package readmodel\n\n")
+	userContent.WriteString(fmt.Sprintf("import \"%s/framework/eventsourcing\"\n\n", potterBaseImportPath(config)))
+	userContent.WriteString(fmt.Sprintf("// Этот файл содержит пользовательский код для read model %s.\n", rm.Name))
+	userContent.WriteString("// Вы можете свободно редактировать этот файл - он не будет перезаписан при регенерации.\n\n")
+	userContent.WriteString(fmt.Sprintf("// custom%sApplier применяет к read model события, которые не описаны в proto\n", rm.Name))
+	userContent.WriteString("// или не сопоставляются с полями read model по имени.\n")
+	userContent.WriteString("// Верните nil, чтобы проекция пропустила событие.\n")
+	userContent.WriteString(fmt.Sprintf("func custom%sApplier(event eventsourcing.StoredEvent) func(*%s) {\n", rm.Name, rm.Name))
+	userContent.WriteString("\t// TODO: Добавьте обработку дополнительных событий\n")
+	userContent.WriteString("\treturn nil\n")
+	userContent.WriteString("}\n")
+
+	return g.writer.WriteFile(userPath, userContent.String())
+}
+
+// generateQueryHandlers генерирует Get/List query handlers read model.
+// Запросы, объявленные в proto с тем же именем, не генерируются.
+func (g *ReadModelGenerator) generateQueryHandlers(rm ReadModelSpec, spec *ParsedSpec, config *GeneratorConfig) error {
+	getName := "Get" + rm.Name
+	listName := "List" + rm.Name
+	generateGet := !g.hasQuery(spec, getName)
+	generateList := !g.hasQuery(spec, listName)
+	if !generateGet && !generateList {
+		return nil
+	}
+
+	storeType := fmt.Sprintf("repository.ReadModelStore[*readmodel.%s]", rm.Name)
+
+	var content strings.Builder
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+	content.WriteString("package query\n\n")
+	content.WriteString("import (\n")
+	content.WriteString("\t\"context\"\n")
+	content.WriteString("\t\"fmt\"\n")
+	content.WriteString("\n")
+	content.WriteString(fmt.Sprintf("\t\"%s/infrastructure/readmodel\"\n", config.ModulePath))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/adapters/repository\"\n", potterBaseImportPath(config)))
+	content.WriteString(fmt.Sprintf("\t\"%s/framework/transport\"\n", potterBaseImportPath(config)))
+	content.WriteString(")\n\n")
+
+	if generateGet {
+		queryName := getName + "Query"
+		handlerName := getName + "Handler"
+		content.WriteString(fmt.Sprintf("// %s запрос read model %s по ID\n", queryName, rm.Name))
+		content.WriteString(fmt.Sprintf("type %s struct {\n", queryName))
+		content.WriteString("\tID string `json:\"id\"`\n")
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("func (q %s) QueryName() string {\n", queryName))
+		content.WriteString(fmt.Sprintf("\treturn %q\n", g.converter.ToSnakeCase(getName)))
+		content.WriteString("}\n\n")
+
+		content.WriteString(fmt.Sprintf("// %s обработчик запроса\n", handlerName))
+		content.WriteString(fmt.Sprintf("type %s struct {\n", handlerName))
+		content.WriteString(fmt.Sprintf("\tstore %s\n", storeType))
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("// New%s создает новый обработчик\n", handlerName))
+		content.WriteString(fmt.Sprintf("func New%s(store %s) *%s {\n", handlerName, storeType, handlerName))
+		content.WriteString(fmt.Sprintf("\treturn &%s{store: store}\n", handlerName))
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("func (h *%s) Handle(ctx context.Context, q transport.Query) (interface{}, error) {\n", handlerName))
+		content.WriteString(fmt.Sprintf("\tquery, ok := q.(%s)\n", queryName))
+		content.WriteString("\tif !ok {\n")
+		content.WriteString("\t\treturn nil, fmt.Errorf(\"invalid query type: %T\", q)\n")
+		content.WriteString("\t}\n")
+		content.WriteString("\treturn h.store.Get(ctx, query.ID)\n")
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("func (h *%s) QueryName() string {\n", handlerName))
+		content.WriteString(fmt.Sprintf("\treturn %q\n", g.converter.ToSnakeCase(getName)))
+		content.WriteString("}\n\n")
+	}
+
+	if generateList {
+		queryName := listName + "Query"
+		handlerName := listName + "Handler"
+		content.WriteString(fmt.Sprintf("// %s запрос страницы read models %s с фильтром по полям\n", queryName, rm.Name))
+		content.WriteString(fmt.Sprintf("type %s struct {\n", queryName))
+		content.WriteString("\tFilter  map[string]interface{} `json:\"filter,omitempty\"`\n")
+		content.WriteString("\tOrderBy string                 `json:\"order_by,omitempty\"`\n")
+		content.WriteString("\tDesc    bool                   `json:\"desc,omitempty\"`\n")
+		content.WriteString("\tLimit   int                    `json:\"limit\"`\n")
+		content.WriteString("\tOffset  int                    `json:\"offset\"`\n")
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("func (q %s) QueryName() string {\n", queryName))
+		content.WriteString(fmt.Sprintf("\treturn %q\n", g.converter.ToSnakeCase(listName)))
+		content.WriteString("}\n\n")
+
+		content.WriteString(fmt.Sprintf("// %s обработчик запроса\n", handlerName))
+		content.WriteString(fmt.Sprintf("type %s struct {\n", handlerName))
+		content.WriteString(fmt.Sprintf("\tstore %s\n", storeType))
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("// New%s создает новый обработчик\n", handlerName))
+		content.WriteString(fmt.Sprintf("func New%s(store %s) *%s {\n", handlerName, storeType, handlerName))
+		content.WriteString(fmt.Sprintf("\treturn &%s{store: store}\n", handlerName))
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("func (h *%s) Handle(ctx context.Context, q transport.Query) (interface{}, error) {\n", handlerName))
+		content.WriteString(fmt.Sprintf("\tquery, ok := q.(%s)\n", queryName))
+		content.WriteString("\tif !ok {\n")
+		content.WriteString("\t\treturn nil, fmt.Errorf(\"invalid query type: %T\", q)\n")
+		content.WriteString("\t}\n")
+		content.WriteString("\tlimit := query.Limit\n")
+		content.WriteString("\tif limit <= 0 {\n")
+		content.WriteString("\t\tlimit = 100\n")
+		content.WriteString("\t}\n")
+		content.WriteString("\treturn h.store.List(ctx, repository.ReadModelFilter{\n")
+		content.WriteString("\t\tWhere:   query.Filter,\n")
+		content.WriteString("\t\tOrderBy: query.OrderBy,\n")
+		content.WriteString("\t\tDesc:    query.Desc,\n")
+		content.WriteString("\t\tLimit:   limit,\n")
+		content.WriteString("\t\tOffset:  query.Offset,\n")
+		content.WriteString("\t})\n")
+		content.WriteString("}\n\n")
+		content.WriteString(fmt.Sprintf("func (h *%s) QueryName() string {\n", handlerName))
+		content.WriteString(fmt.Sprintf("\treturn %q\n", g.converter.ToSnakeCase(listName)))
+		content.WriteString("}\n")
+	}
+
+	path := fmt.Sprintf("application/query/%s_read_model.gen.go", g.converter.ToSnakeCase(rm.Name))
+	return g.writer.WriteFile(path, strings.TrimRight(content.String(), "\n")+"\n")
+}
+
+// generateMigration генерирует миграцию таблиц read models в формате goose
+func (g *ReadModelGenerator) generateMigration(spec *ParsedSpec) error {
+	var content strings.Builder
+
+	content.WriteString("-- +goose Up\n")
+	content.WriteString("-- Migration: Create read model tables\n")
+	content.WriteString("-- Generated by potter-gen\n\n")
+
+	for _, rm := range spec.ReadModels {
+		tableName := g.tableName(rm)
+		columns := g.columns(rm)
+
+		content.WriteString(fmt.Sprintf("-- Read model %s\n", rm.Name))
+		content.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", tableName))
+		content.WriteString(fmt.Sprintf("\t%s VARCHAR(255) PRIMARY KEY,\n", columns[0].column))
+		for _, col := range columns[1:] {
+			content.WriteString(fmt.Sprintf("\t%s %s", col.column, g.protoToSQLType(col)))
+			if !col.json {
+				content.WriteString(" NOT NULL")
+			}
+			content.WriteString(",\n")
+		}
+		content.WriteString("\tversion BIGINT NOT NULL DEFAULT 0,\n")
+		content.WriteString("\tupdated_at TIMESTAMP NOT NULL DEFAULT NOW()\n")
+		content.WriteString(");\n\n")
+	}
+
+	content.WriteString("\n-- +goose Down\n")
+	content.WriteString("-- Migration: Drop read model tables\n")
+	content.WriteString("-- Generated by potter-gen\n\n")
+	for i := len(spec.ReadModels) - 1; i >= 0; i-- {
+		content.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", g.tableName(spec.ReadModels[i])))
+	}
+
+	migrationPath := "migrations/002_create_read_models.sql"
+	if err := g.writer.WriteFile(migrationPath, content.String()); err != nil {
+		return fmt.Errorf("failed to write migration: %w", err)
+	}
+	return nil
+}
+
+// columns возвращает колонки read model, первой - ключ (добавляется, если его нет среди полей)
+func (g *ReadModelGenerator) columns(rm ReadModelSpec) []readModelColumn {
+	keyField := rm.KeyField
+	if keyField == "" {
+		keyField = "id"
+	}
+
+	key := readModelColumn{
+		field:  FieldSpec{Name: keyField, Type: "string"},
+		goName: g.goFieldName(keyField),
+		goType: "string",
+		column: g.converter.ToSnakeCase(keyField),
+	}
+	columns := []readModelColumn{key}
+	for _, field := range rm.Fields {
+		if field.Name == keyField {
+			continue
+		}
+		col := readModelColumn{
+			field:  field,
+			goName: g.goFieldName(field.Name),
+			column: g.converter.ToSnakeCase(field.Name),
+		}
+		if goType, ok := readModelScalarTypes[field.Type]; ok && !field.Repeated {
+			col.goType = goType
+		} else {
+			col.goType = "json.RawMessage"
+			col.json = true
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+// readModelScalarTypes proto типы, которые хранятся в отдельных колонках и
+// заполняются из одноименных полей событий
+var readModelScalarTypes = map[string]string{
+	"string":  "string",
+	"int32":   "int32",
+	"int64":   "int64",
+	"bool":    "bool",
+	"float64": "float64",
+	"float32": "float32",
+	"decimal": decimalGoType,
+}
+
+// handledEvents возвращает события, которые обрабатывает проекция read model:
+// перечисленные в опции events (по имени или типу), иначе все события агрегата
+func (g *ReadModelGenerator) handledEvents(rm ReadModelSpec, spec *ParsedSpec) []EventSpec {
+	var result []EventSpec
+	for _, event := range spec.Events {
+		if event.IsError {
+			continue
+		}
+		if len(rm.Events) > 0 {
+			for _, name := range rm.Events {
+				if name == event.Name || name == event.EventType {
+					result = append(result, event)
+					break
+				}
+			}
+			continue
+		}
+		if rm.Aggregate != "" && event.Aggregate == rm.Aggregate {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// matchEventField находит поле события с тем же именем и типом, что и колонка read model
+func (g *ReadModelGenerator) matchEventField(col readModelColumn, event EventSpec) (FieldSpec, bool) {
+	if col.json {
+		return FieldSpec{}, false
+	}
+	for _, field := range event.Fields {
+		if field.Name == col.field.Name && field.Type == col.field.Type && !field.Repeated {
+			return field, true
+		}
+	}
+	return FieldSpec{}, false
+}
+
+// hasQuery проверяет, объявлен ли запрос в proto
+func (g *ReadModelGenerator) hasQuery(spec *ParsedSpec, name string) bool {
+	for _, query := range spec.Queries {
+		if query.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tableName возвращает имя таблицы read model
+func (g *ReadModelGenerator) tableName(rm ReadModelSpec) string {
+	if rm.Table != "" {
+		return rm.Table
+	}
+	return g.converter.ToSnakeCase(rm.Name) + "s"
+}
+
+// protoToSQLType конвертирует тип колонки read model в SQL тип
+func (g *ReadModelGenerator) protoToSQLType(col readModelColumn) string {
+	if col.json {
+		return "JSONB"
+	}
+	switch col.field.Type {
+	case "string":
+		return "TEXT"
+	case "int32":
+		return "INTEGER"
+	case "int64":
+		return "BIGINT"
+	case "bool":
+		return "BOOLEAN"
+	case "float64":
+		return "DOUBLE PRECISION"
+	case "float32":
+		return "REAL"
+	case decimalType:
+		return decimalSQLType(col.field)
+	default:
+		return "TEXT"
+	}
+}
+
+// goFieldName возвращает имя поля структуры read model
+func (g *ReadModelGenerator) goFieldName(name string) string {
+	if name == "id" {
+		return "ID"
+	}
+	return g.toPublicField(name)
+}
+
+// toPublicField конвертирует имя поля в публичное (как в доменных событиях)
+func (g *ReadModelGenerator) toPublicField(name string) string {
+	if len(name) == 0 {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// lowerFirst переводит первую букву в нижний регистр
+func (g *ReadModelGenerator) lowerFirst(name string) string {
+	if len(name) == 0 {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// potterBaseImportPath возвращает путь импорта Potter без суффикса версии
+func potterBaseImportPath(config *GeneratorConfig) string {
+	potterPath := ""
+	if config != nil {
+		potterPath = config.PotterImportPath
+	}
+	if potterPath == "" {
+		potterPath = "github.com/akriventsev/potter"
+	}
+	return strings.Split(potterPath, "@")[0]
+}
@@ -0,0 +1,149 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func readModelTestSpec() *ParsedSpec {
+	return &ParsedSpec{
+		ModuleName: "test",
+		Events: []EventSpec{
+			{
+				Name:      "ProductCreated",
+				EventType: "product.created",
+				Aggregate: "Product",
+				Fields: []FieldSpec{
+					{Name: "name", Type: "string", Number: 1},
+					{Name: "price", Type: "float64", Number: 2},
+					{Name: "cost", Type: "decimal", Number: 3, Rules: &FieldRules{Decimal: DecimalModeDecimal, Scale: 4}},
+				},
+			},
+			{
+				Name:      "ProductRenamed",
+				EventType: "product.renamed",
+				Aggregate: "Product",
+				Fields: []FieldSpec{
+					{Name: "name", Type: "string", Number: 1},
+				},
+			},
+			{
+				Name:      "OrderCreated",
+				EventType: "order.created",
+				Aggregate: "Order",
+			},
+		},
+		Queries: []QuerySpec{
+			{Name: "ListProductView", RequestType: "ListProductViewRequest", ResponseType: "ListProductViewResponse"},
+		},
+		ReadModels: []ReadModelSpec{
+			{
+				Name:      "ProductView",
+				Aggregate: "Product",
+				Fields: []FieldSpec{
+					{Name: "id", Type: "string", Number: 1},
+					{Name: "name", Type: "string", Number: 2},
+					{Name: "price", Type: "float64", Number: 3},
+					{Name: "tags", Type: "string", Number: 4, Repeated: true},
+					{Name: "cost", Type: "decimal", Number: 5, Rules: &FieldRules{Decimal: DecimalModeDecimal, Scale: 4}},
+				},
+			},
+		},
+	}
+}
+
+func TestReadModelGenerator_Generate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	generator := NewReadModelGenerator(tmpDir)
+	config := &GeneratorConfig{
+		ModulePath:  "test",
+		OutputDir:   tmpDir,
+		PackageName: "test",
+		Overwrite:   true,
+	}
+	require.NoError(t, generator.Generate(readModelTestSpec(), config))
+
+	readModelPath := filepath.Join(tmpDir, "infrastructure/readmodel/product_view.gen.go")
+	userCodePath := filepath.Join(tmpDir, "infrastructure/readmodel/product_view.go")
+	queryPath := filepath.Join(tmpDir, "application/query/product_view_read_model.gen.go")
+	migrationPath := filepath.Join(tmpDir, "migrations/002_create_read_models.sql")
+
+	// Сгенерированный Go код должен быть синтаксически корректным
+	for _, path := range []string{readModelPath, userCodePath, queryPath} {
+		_, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors)
+		require.NoError(t, err, path)
+	}
+
+	readModel, err := os.ReadFile(readModelPath)
+	require.NoError(t, err)
+	content := string(readModel)
+	assert.Contains(t, content, "type ProductView struct")
+	assert.Contains(t, content, "Tags json.RawMessage")
+	assert.Contains(t, content, "type ProductViewProjection struct")
+	assert.Contains(t, content, "case *domain.ProductCreated:")
+	assert.Contains(t, content, "case domain.ProductRenamed:")
+	assert.NotContains(t, content, "OrderCreated")
+	assert.Contains(t, content, "row.Price = e.Price")
+	assert.Contains(t, content, `"github.com/shopspring/decimal"`)
+	assert.Contains(t, content, "Cost decimal.Decimal")
+	assert.Contains(t, content, "row.Cost = e.Cost")
+	assert.Contains(t, content, "ON CONFLICT (id) DO UPDATE SET")
+	assert.Contains(t, content, "func NewProductViewReadModelStore(config repository.ReadModelStoreConfig) (repository.ReadModelStore[*ProductView], error)")
+	assert.Contains(t, content, "func (s *ProductViewStore) List(ctx context.Context, filter repository.ReadModelFilter) ([]*ProductView, error)")
+	assert.Contains(t, content, "store repository.ReadModelStore[*ProductView]")
+	assert.NotContains(t, content, `"tags": true`)
+
+	// ListProductView объявлен в proto и не генерируется повторно
+	query, err := os.ReadFile(queryPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(query), "type GetProductViewHandler struct")
+	assert.Contains(t, string(query), "store repository.ReadModelStore[*readmodel.ProductView]")
+	assert.NotContains(t, string(query), "ListProductViewHandler")
+
+	migration, err := os.ReadFile(migrationPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(migration), "CREATE TABLE IF NOT EXISTS product_views")
+	assert.Contains(t, string(migration), "tags JSONB")
+	assert.Contains(t, string(migration), "cost NUMERIC(38, 4)")
+	assert.Contains(t, string(migration), "DROP TABLE IF EXISTS product_views;")
+
+	// Пользовательский код не перезаписывается при регенерации
+	require.NoError(t, os.WriteFile(userCodePath, []byte("package readmodel\n// custom\n"), 0644))
+	require.NoError(t, generator.Generate(readModelTestSpec(), config))
+	userCode, err := os.ReadFile(userCodePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(userCode), "// custom")
+}
+
+func TestReadModelGenerator_NoReadModels(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, NewReadModelGenerator(tmpDir).Generate(&ParsedSpec{}, &GeneratorConfig{ModulePath: "test"}))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "migrations/002_create_read_models.sql"))
+}
+
+func TestProtoParser_ParseReadModelOptions(t *testing.T) {
+	var data []byte
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendString(data, "Product")
+	data = protowire.AppendTag(data, 4, protowire.BytesType)
+	data = protowire.AppendString(data, "product.created")
+	data = protowire.AppendTag(data, 4, protowire.BytesType)
+	data = protowire.AppendString(data, "ProductRenamed")
+	data = protowire.AppendTag(data, 5, protowire.BytesType)
+	data = protowire.AppendString(data, "product_id")
+
+	opts := NewProtoParser().parseReadModelOptions(data)
+	assert.Equal(t, "Product", opts.Aggregate)
+	assert.Equal(t, []string{"product.created", "ProductRenamed"}, opts.Events)
+	assert.Equal(t, "product_id", opts.KeyField)
+	assert.Empty(t, opts.Name)
+}
@@ -1139,6 +1139,9 @@ func (g *SDKGenerator) protoToGoTypeDTO(protoType string, repeated bool, spec *P
 		goType = "float32"
 	case "[]byte":
 		goType = "[]byte"
+	case decimalType:
+		// decimal.Decimal сериализуется в JSON строкой без потери точности
+		goType = "string"
 	default:
 		// Для пользовательских типов используем DTO
 		if g.isCustomType(protoType) && g.isAggregateType(spec, protoType) {
@@ -1166,6 +1169,7 @@ func (g *SDKGenerator) isCustomType(protoType string) bool {
 		"float64": true,
 		"float32": true,
 		"[]byte":  true,
+		"decimal": true,
 	}
 	return !basicTypes[protoType]
 }
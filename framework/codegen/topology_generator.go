@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// TopologyGenerator генератор спецификации топологии message bus (deploy/topology.yaml)
+// для провижининга через framework/topology и `potter-admin provision`:
+// JetStream stream команд (workqueue) с durable consumer сервиса, stream событий
+// агрегатов и Kafka topics для тех же subjects.
+type TopologyGenerator struct {
+	*BaseGenerator
+}
+
+// NewTopologyGenerator создает новый генератор топологии
+func NewTopologyGenerator(outputDir string) *TopologyGenerator {
+	return &TopologyGenerator{
+		BaseGenerator: NewBaseGenerator("topology", outputDir),
+	}
+}
+
+// Generate генерирует deploy/topology.yaml
+func (g *TopologyGenerator) Generate(spec *ParsedSpec, config *GeneratorConfig) error {
+	if len(spec.Commands) == 0 && len(spec.Events) == 0 {
+		return nil
+	}
+
+	service := g.serviceName(spec, config)
+	streamPrefix := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(service))
+	commandSubjects := g.commandSubjects(spec)
+	eventStreamSubjects, eventSubjects := g.eventSubjects(spec)
+
+	var content strings.Builder
+	content.WriteString("# Code generated by potter-gen. DO NOT EDIT.\n")
+	content.WriteString("# Топология message bus сервиса: potter-admin provision --spec deploy/topology.yaml\n")
+	content.WriteString("# Subjects команд: commands.{command} (invoke.DefaultSubjectResolver),\n")
+	content.WriteString("# событий: events.{aggregate}.{event} (events.DefaultSubjectTemplate).\n")
+
+	content.WriteString("nats:\n")
+	content.WriteString("  streams:\n")
+	if len(commandSubjects) > 0 {
+		content.WriteString(fmt.Sprintf("    - name: %s_COMMANDS\n", streamPrefix))
+		g.writeList(&content, "      subjects:", commandSubjects)
+		content.WriteString("      retention: workqueue\n")
+		content.WriteString("      storage: file\n")
+		content.WriteString("      replicas: 1\n")
+	}
+	if len(eventStreamSubjects) > 0 {
+		content.WriteString(fmt.Sprintf("    - name: %s_EVENTS\n", streamPrefix))
+		g.writeList(&content, "      subjects:", eventStreamSubjects)
+		content.WriteString("      retention: limits\n")
+		content.WriteString("      storage: file\n")
+		content.WriteString("      replicas: 1\n")
+		content.WriteString("      max_age: 168h\n")
+	}
+	if len(commandSubjects) > 0 {
+		content.WriteString("  consumers:\n")
+		content.WriteString(fmt.Sprintf("    - stream: %s_COMMANDS\n", streamPrefix))
+		content.WriteString(fmt.Sprintf("      name: %s-commands\n", service))
+		content.WriteString("      filter_subject: commands.>\n")
+		content.WriteString("      deliver_policy: all\n")
+		content.WriteString("      ack_wait: 30s\n")
+		content.WriteString("      max_deliver: 5\n")
+	}
+
+	content.WriteString("kafka:\n")
+	content.WriteString("  topics:\n")
+	for _, subject := range commandSubjects {
+		content.WriteString(fmt.Sprintf("    - name: %s\n", subject))
+		content.WriteString("      partitions: 3\n")
+		content.WriteString("      replication_factor: 1\n")
+	}
+	for _, subject := range eventSubjects {
+		content.WriteString(fmt.Sprintf("    - name: %s\n", subject))
+		content.WriteString("      partitions: 3\n")
+		content.WriteString("      replication_factor: 1\n")
+		content.WriteString("      retention: 168h\n")
+	}
+
+	return g.writer.WriteFile("deploy/topology.yaml", content.String())
+}
+
+// serviceName возвращает имя сервиса из module path (github.com/acme/order-service -> order-service)
+func (g *TopologyGenerator) serviceName(spec *ParsedSpec, config *GeneratorConfig) string {
+	modulePath := spec.ModuleName
+	if config != nil && config.ModulePath != "" {
+		modulePath = config.ModulePath
+	}
+	name := strings.ToLower(path.Base(modulePath))
+	if name == "" || name == "." || name == "/" {
+		return "service"
+	}
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// commandSubjects возвращает subjects команд
+func (g *TopologyGenerator) commandSubjects(spec *ParsedSpec) []string {
+	subjects := make([]string, 0, len(spec.Commands))
+	for _, cmd := range spec.Commands {
+		subjects = append(subjects, "commands."+g.converter.ToSnakeCase(cmd.Name))
+	}
+	return subjects
+}
+
+// eventSubjects возвращает subjects stream событий (по агрегатам) и subjects отдельных событий
+func (g *TopologyGenerator) eventSubjects(spec *ParsedSpec) ([]string, []string) {
+	streamSubjects := make([]string, 0)
+	eventSubjects := make([]string, 0, len(spec.Events))
+	seen := make(map[string]bool)
+	for _, event := range spec.Events {
+		aggregate := g.converter.ToSnakeCase(event.Aggregate)
+		if aggregate == "" {
+			aggregate = "unknown"
+		}
+		if !seen[aggregate] {
+			seen[aggregate] = true
+			streamSubjects = append(streamSubjects, fmt.Sprintf("events.%s.>", aggregate))
+		}
+		eventType := event.EventType
+		if eventType == "" {
+			eventType = g.converter.ToSnakeCase(event.Name)
+		}
+		eventSubjects = append(eventSubjects, fmt.Sprintf("events.%s.%s", aggregate, eventType))
+	}
+	return streamSubjects, eventSubjects
+}
+
+// writeList записывает YAML список в flow-стиле
+func (g *TopologyGenerator) writeList(content *strings.Builder, key string, items []string) {
+	content.WriteString(fmt.Sprintf("%s [%s]\n", key, strings.Join(items, ", ")))
+}
@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTopologyGenerator_Generate(t *testing.T) {
+	tmpDir := t.TempDir()
+	generator := NewTopologyGenerator(tmpDir)
+
+	spec := &ParsedSpec{
+		ModuleName: "github.com/acme/order-service",
+		Commands: []CommandSpec{
+			{Name: "CreateOrder"},
+			{Name: "CancelOrder"},
+		},
+		Events: []EventSpec{
+			{Name: "OrderCreated", EventType: "order.created", Aggregate: "Order"},
+			{Name: "OrderCancelled", EventType: "order.cancelled", Aggregate: "Order"},
+		},
+	}
+
+	err := generator.Generate(spec, &GeneratorConfig{ModulePath: "github.com/acme/order-service"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "deploy/topology.yaml"))
+	require.NoError(t, err)
+
+	var topology struct {
+		NATS struct {
+			Streams []struct {
+				Name      string   `yaml:"name"`
+				Subjects  []string `yaml:"subjects"`
+				Retention string   `yaml:"retention"`
+			} `yaml:"streams"`
+			Consumers []struct {
+				Stream        string `yaml:"stream"`
+				Name          string `yaml:"name"`
+				FilterSubject string `yaml:"filter_subject"`
+			} `yaml:"consumers"`
+		} `yaml:"nats"`
+		Kafka struct {
+			Topics []struct {
+				Name       string `yaml:"name"`
+				Partitions int    `yaml:"partitions"`
+			} `yaml:"topics"`
+		} `yaml:"kafka"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &topology))
+
+	require.Len(t, topology.NATS.Streams, 2)
+	assert.Equal(t, "ORDER_SERVICE_COMMANDS", topology.NATS.Streams[0].Name)
+	assert.Equal(t, []string{"commands.create_order", "commands.cancel_order"}, topology.NATS.Streams[0].Subjects)
+	assert.Equal(t, "workqueue", topology.NATS.Streams[0].Retention)
+	assert.Equal(t, "ORDER_SERVICE_EVENTS", topology.NATS.Streams[1].Name)
+	assert.Equal(t, []string{"events.order.>"}, topology.NATS.Streams[1].Subjects)
+
+	require.Len(t, topology.NATS.Consumers, 1)
+	assert.Equal(t, "ORDER_SERVICE_COMMANDS", topology.NATS.Consumers[0].Stream)
+	assert.Equal(t, "order-service-commands", topology.NATS.Consumers[0].Name)
+	assert.Equal(t, "commands.>", topology.NATS.Consumers[0].FilterSubject)
+
+	require.Len(t, topology.Kafka.Topics, 4)
+	assert.Equal(t, "commands.create_order", topology.Kafka.Topics[0].Name)
+	assert.Equal(t, "events.order.order.created", topology.Kafka.Topics[2].Name)
+	assert.Equal(t, 3, topology.Kafka.Topics[2].Partitions)
+}
+
+func TestTopologyGenerator_SkipsSpecWithoutMessages(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := NewTopologyGenerator(tmpDir).Generate(&ParsedSpec{ModuleName: "test"}, &GeneratorConfig{})
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(tmpDir, "deploy/topology.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
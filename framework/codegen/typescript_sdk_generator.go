@@ -0,0 +1,515 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tsSDKDir директория TypeScript SDK в выходной директории
+const tsSDKDir = "pkg/sdk-ts"
+
+// TypeScriptSDKGenerator генератор TypeScript клиента для REST presentation слоя:
+// команды отправляются POST/PUT/DELETE запросами, запросы - GET, статус саг - через /api/v1/sagas.
+// Маршруты совпадают с presentation/rest/handler.gen.go, типы - с api/openapi/openapi.yaml.
+type TypeScriptSDKGenerator struct {
+	*BaseGenerator
+	routes *PresentationGenerator
+}
+
+// NewTypeScriptSDKGenerator создает новый генератор TypeScript SDK
+func NewTypeScriptSDKGenerator(outputDir string) *TypeScriptSDKGenerator {
+	return &TypeScriptSDKGenerator{
+		BaseGenerator: NewBaseGenerator(tsSDKDir, outputDir),
+		routes:        NewPresentationGenerator(outputDir),
+	}
+}
+
+// Generate генерирует TypeScript SDK
+func (g *TypeScriptSDKGenerator) Generate(spec *ParsedSpec, config *GeneratorConfig) error {
+	if err := g.generateTypes(spec); err != nil {
+		return fmt.Errorf("failed to generate TypeScript types: %w", err)
+	}
+
+	if err := g.generateClient(spec); err != nil {
+		return fmt.Errorf("failed to generate TypeScript client: %w", err)
+	}
+
+	if err := g.generatePackage(spec, config); err != nil {
+		return fmt.Errorf("failed to generate TypeScript package: %w", err)
+	}
+
+	return nil
+}
+
+// generateTypes генерирует интерфейсы сообщений, агрегатов и ответов саг
+func (g *TypeScriptSDKGenerator) generateTypes(spec *ParsedSpec) error {
+	var content strings.Builder
+
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+
+	content.WriteString("/** Ответ REST API на команду */\n")
+	content.WriteString("export interface CommandResult {\n")
+	content.WriteString("  message: string;\n")
+	content.WriteString("}\n\n")
+
+	for _, agg := range spec.Aggregates {
+		g.writeInterface(&content, agg.Name, fmt.Sprintf("Агрегат %s", agg.Name), agg.Fields, spec)
+	}
+
+	written := make(map[string]bool)
+	for _, agg := range spec.Aggregates {
+		written[agg.Name] = true
+	}
+	writeMessage := func(name, kind string, fields []FieldSpec) {
+		if name == "" || written[name] {
+			return
+		}
+		written[name] = true
+		g.writeInterface(&content, name, kind, fields, spec)
+	}
+	for _, cmd := range spec.Commands {
+		writeMessage(cmd.RequestType, fmt.Sprintf("Тело команды %s", cmd.Name), cmd.RequestFields)
+	}
+	for _, query := range spec.Queries {
+		writeMessage(query.RequestType, fmt.Sprintf("Параметры запроса %s", query.Name), query.RequestFields)
+		writeMessage(query.ResponseType, fmt.Sprintf("Ответ на запрос %s", query.Name), query.ResponseFields)
+	}
+
+	content.WriteString(tsSagaTypes)
+
+	return g.writer.WriteFile(tsSDKDir+"/src/types.ts", content.String())
+}
+
+// writeInterface генерирует TypeScript интерфейс сообщения с snake_case полями (как json теги Go структур)
+func (g *TypeScriptSDKGenerator) writeInterface(content *strings.Builder, name, doc string, fields []FieldSpec, spec *ParsedSpec) {
+	content.WriteString(fmt.Sprintf("/** %s */\n", doc))
+	content.WriteString(fmt.Sprintf("export interface %s {\n", name))
+	for _, field := range fields {
+		optional := ""
+		if field.Optional {
+			optional = "?"
+		}
+		content.WriteString(fmt.Sprintf("  %s%s: %s;\n", g.converter.ToSnakeCase(field.Name), optional, g.protoToTSType(field, spec)))
+	}
+	content.WriteString("}\n\n")
+}
+
+// generateClient генерирует класс клиента с методами команд, запросов и статуса саг
+func (g *TypeScriptSDKGenerator) generateClient(spec *ParsedSpec) error {
+	var content strings.Builder
+	clientName := g.clientName(spec)
+
+	content.WriteString("// Code generated by potter-gen. DO NOT EDIT.\n\n")
+	content.WriteString("import * as types from './types';\n\n")
+	content.WriteString(tsClientPrelude)
+
+	content.WriteString(fmt.Sprintf("/** %s REST клиент сервиса %s */\n", clientName, spec.ModuleName))
+	content.WriteString(fmt.Sprintf("export class %s extends BaseClient {\n", clientName))
+
+	for _, cmd := range spec.Commands {
+		g.writeCommandMethod(&content, cmd)
+	}
+	for _, query := range spec.Queries {
+		g.writeQueryMethod(&content, query)
+	}
+	content.WriteString(tsSagaMethods)
+	content.WriteString("}\n")
+
+	if err := g.writer.WriteFile(tsSDKDir+"/src/client.ts", content.String()); err != nil {
+		return err
+	}
+
+	index := "// Code generated by potter-gen. DO NOT EDIT.\n\nexport * from './types';\nexport * from './client';\n"
+	return g.writer.WriteFile(tsSDKDir+"/src/index.ts", index)
+}
+
+// writeCommandMethod генерирует метод команды по маршруту REST handler
+func (g *TypeScriptSDKGenerator) writeCommandMethod(content *strings.Builder, cmd CommandSpec) {
+	method, route := g.routes.getRESTRouteForCommand(cmd, g.routes.commandResource(cmd))
+	path, params := g.tsPath(route)
+	methodName := g.lowerFirst(cmd.Name)
+
+	requestType := "types." + cmd.RequestType
+	if cmd.RequestType == "" {
+		requestType = "Record<string, unknown>"
+	}
+	// ID передается в URL, в теле команды он не нужен
+	if len(params) > 0 && g.hasField(cmd.RequestFields, "id") {
+		requestType = fmt.Sprintf("Omit<%s, 'id'>", requestType)
+	}
+
+	var args []string
+	for _, param := range params {
+		args = append(args, param+": string")
+	}
+	body := "undefined"
+	if method != "DELETE" {
+		args = append(args, "request: "+requestType)
+		body = "request"
+	}
+
+	doc := cmd.Summary
+	if doc == "" {
+		doc = fmt.Sprintf("Команда %s", cmd.Name)
+	}
+	content.WriteString(fmt.Sprintf("  /** %s (%s %s) */\n", doc, method, route))
+	if cmd.Deprecated {
+		content.WriteString("  /** @deprecated */\n")
+	}
+	content.WriteString(fmt.Sprintf("  %s(%s): Promise<types.CommandResult> {\n", methodName, strings.Join(args, ", ")))
+	content.WriteString(fmt.Sprintf("    return this.request<types.CommandResult>('%s', %s, { body: %s });\n", method, path, body))
+	content.WriteString("  }\n\n")
+}
+
+// writeQueryMethod генерирует GET метод запроса по маршруту REST handler
+func (g *TypeScriptSDKGenerator) writeQueryMethod(content *strings.Builder, query QuerySpec) {
+	route := g.routes.getRESTRouteForQuery(query, g.routes.queryResource(query))
+	path, params := g.tsPath(route)
+	methodName := g.lowerFirst(query.Name)
+
+	responseType := "types." + query.ResponseType
+	if query.ResponseType == "" {
+		responseType = "unknown"
+	}
+
+	var args []string
+	for _, param := range params {
+		args = append(args, param+": string")
+	}
+	// Get запросы получают только ID из URL, остальные - параметры строки запроса
+	queryArg := "undefined"
+	if len(params) == 0 {
+		paramsType := "Record<string, unknown>"
+		if query.RequestType != "" {
+			paramsType = fmt.Sprintf("Partial<types.%s>", query.RequestType)
+		}
+		args = append(args, fmt.Sprintf("params: %s = {}", paramsType))
+		queryArg = "{ ...params }"
+	}
+
+	doc := query.Summary
+	if doc == "" {
+		doc = fmt.Sprintf("Запрос %s", query.Name)
+	}
+	content.WriteString(fmt.Sprintf("  /** %s (GET %s) */\n", doc, route))
+	if query.Deprecated {
+		content.WriteString("  /** @deprecated */\n")
+	}
+	content.WriteString(fmt.Sprintf("  %s(%s): Promise<%s> {\n", methodName, strings.Join(args, ", "), responseType))
+	content.WriteString(fmt.Sprintf("    return this.request<%s>('GET', %s, { query: %s });\n", responseType, path, queryArg))
+	content.WriteString("  }\n\n")
+}
+
+// generatePackage генерирует package.json, tsconfig.json и README пакета
+func (g *TypeScriptSDKGenerator) generatePackage(spec *ParsedSpec, config *GeneratorConfig) error {
+	packageName := g.converter.ToSnakeCase(spec.ModuleName)
+	if config != nil && config.ModulePath != "" {
+		parts := strings.Split(config.ModulePath, "/")
+		packageName = parts[len(parts)-1]
+	}
+	packageName = strings.ReplaceAll(strings.ToLower(packageName), "_", "-") + "-sdk"
+
+	packageJSON := fmt.Sprintf(`{
+  "name": %q,
+  "version": "1.0.0",
+  "description": "TypeScript REST client generated by potter-gen",
+  "main": "dist/index.js",
+  "types": "dist/index.d.ts",
+  "files": ["dist"],
+  "scripts": {
+    "build": "tsc"
+  },
+  "devDependencies": {
+    "typescript": "^5.4.0"
+  }
+}
+`, packageName)
+	if err := g.writer.WriteFile(tsSDKDir+"/package.json", packageJSON); err != nil {
+		return err
+	}
+
+	tsconfig := `{
+  "compilerOptions": {
+    "target": "ES2020",
+    "module": "ES2020",
+    "moduleResolution": "node",
+    "lib": ["ES2020", "DOM"],
+    "declaration": true,
+    "outDir": "dist",
+    "strict": true
+  },
+  "include": ["src"]
+}
+`
+	if err := g.writer.WriteFile(tsSDKDir+"/tsconfig.json", tsconfig); err != nil {
+		return err
+	}
+
+	clientName := g.clientName(spec)
+	var readme strings.Builder
+	readme.WriteString(fmt.Sprintf("# %s\n\n", packageName))
+	readme.WriteString("TypeScript клиент REST API, сгенерированный potter-gen. Спецификация OpenAPI: `api/openapi/openapi.yaml`.\n\n")
+	readme.WriteString("```typescript\n")
+	readme.WriteString(fmt.Sprintf("import { %s, ApiError } from '%s';\n\n", clientName, packageName))
+	readme.WriteString(fmt.Sprintf("const client = new %s({ baseUrl: 'http://localhost:8080' });\n", clientName))
+	if len(spec.Commands) > 0 {
+		readme.WriteString(fmt.Sprintf("await client.%s(/* ... */);\n", g.lowerFirst(spec.Commands[0].Name)))
+	}
+	readme.WriteString("const status = await client.getSagaStatus(sagaId);\n")
+	readme.WriteString("```\n\n")
+	readme.WriteString("Ошибочные ответы (статус не 2xx) выбрасывают `ApiError` со статусом и телом ответа.\n")
+	return g.writer.WriteFile(tsSDKDir+"/README.md", readme.String())
+}
+
+// protoToTSType конвертирует тип поля в TypeScript тип
+func (g *TypeScriptSDKGenerator) protoToTSType(field FieldSpec, spec *ParsedSpec) string {
+	var tsType string
+	switch field.Type {
+	case "string", "[]byte", "bytes":
+		// bytes сериализуются в base64 строку
+		tsType = "string"
+	case "int32", "int64", "float32", "float64", "double":
+		tsType = "number"
+	case "bool":
+		tsType = "boolean"
+	case decimalType:
+		// decimal.Decimal сериализуется строкой без потери точности
+		tsType = "string"
+	default:
+		if findAggregateByName(spec.Aggregates, field.Type) != nil {
+			tsType = field.Type
+		} else {
+			tsType = "Record<string, unknown>"
+		}
+	}
+	if field.Repeated {
+		if strings.Contains(tsType, "<") {
+			return fmt.Sprintf("Array<%s>", tsType)
+		}
+		return tsType + "[]"
+	}
+	return tsType
+}
+
+// tsPath конвертирует маршрут gin (/products/:id) в TypeScript template literal и параметры пути
+func (g *TypeScriptSDKGenerator) tsPath(route string) (string, []string) {
+	var params []string
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			param := segment[1:]
+			params = append(params, param)
+			segments[i] = fmt.Sprintf("${encodeURIComponent(%s)}", param)
+		}
+	}
+	return "`/api/v1" + strings.Join(segments, "/") + "`", params
+}
+
+// clientName возвращает имя класса клиента
+func (g *TypeScriptSDKGenerator) clientName(spec *ParsedSpec) string {
+	if len(spec.Services) > 0 && spec.Services[0].Name != "" {
+		return spec.Services[0].Name + "Client"
+	}
+	return "Client"
+}
+
+// hasField проверяет наличие поля с указанным именем
+func (g *TypeScriptSDKGenerator) hasField(fields []FieldSpec, name string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// lowerFirst переводит первую букву в нижний регистр
+func (g *TypeScriptSDKGenerator) lowerFirst(name string) string {
+	if len(name) == 0 {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// tsClientPrelude базовый HTTP клиент на fetch
+const tsClientPrelude = `/** Настройки клиента */
+export interface ClientOptions {
+  /** Адрес сервиса, например http://localhost:8080 */
+  baseUrl: string;
+  /** Заголовки каждого запроса (например Authorization) */
+  headers?: Record<string, string>;
+  /** Реализация fetch (по умолчанию globalThis.fetch) */
+  fetch?: typeof fetch;
+}
+
+/** Ошибка REST API: ответ со статусом не 2xx */
+export class ApiError extends Error {
+  constructor(
+    public readonly status: number,
+    public readonly body: unknown,
+  ) {
+    const message =
+      body && typeof body === 'object' && 'error' in body ? String((body as { error: unknown }).error) : ` + "`HTTP ${status}`" + `;
+    super(message);
+    this.name = 'ApiError';
+  }
+}
+
+interface RequestOptions {
+  query?: Record<string, unknown>;
+  body?: unknown;
+}
+
+/** Базовый HTTP клиент на fetch */
+export class BaseClient {
+  private readonly baseUrl: string;
+  private readonly headers: Record<string, string>;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(options: ClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, '');
+    this.headers = options.headers ?? {};
+    this.fetchImpl = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  protected async request<T>(method: string, path: string, options: RequestOptions = {}): Promise<T> {
+    const search = new URLSearchParams();
+    for (const [key, value] of Object.entries(options.query ?? {})) {
+      if (value === undefined || value === null) {
+        continue;
+      }
+      for (const item of Array.isArray(value) ? value : [value]) {
+        search.append(key, String(item));
+      }
+    }
+    const query = search.toString();
+    const url = this.baseUrl + path + (query ? ` + "`?${query}`" + ` : '');
+
+    const headers: Record<string, string> = { Accept: 'application/json', ...this.headers };
+    if (options.body !== undefined) {
+      headers['Content-Type'] = 'application/json';
+    }
+    const response = await this.fetchImpl(url, {
+      method,
+      headers,
+      body: options.body === undefined ? undefined : JSON.stringify(options.body),
+    });
+
+    const text = await response.text();
+    const data: unknown = text ? JSON.parse(text) : undefined;
+    if (!response.ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+}
+
+`
+
+// tsSagaMethods методы статуса саг (presentation/rest/sagas.gen.go)
+const tsSagaMethods = `  /** Статус саги (GET /sagas/:id) */
+  getSagaStatus(sagaId: string): Promise<types.SagaStatusResponse> {
+    return this.request<types.SagaStatusResponse>('GET', ` + "`/api/v1/sagas/${encodeURIComponent(sagaId)}`" + `);
+  }
+
+  /** История шагов саги (GET /sagas/:id/history) */
+  getSagaHistory(sagaId: string): Promise<types.SagaHistoryResponse> {
+    return this.request<types.SagaHistoryResponse>('GET', ` + "`/api/v1/sagas/${encodeURIComponent(sagaId)}/history`" + `);
+  }
+
+  /** Список саг с фильтрами (GET /sagas) */
+  listSagas(params: types.ListSagasParams = {}): Promise<types.SagaListResponse> {
+    return this.request<types.SagaListResponse>('GET', '/api/v1/sagas', { query: { ...params } });
+  }
+`
+
+// tsSagaTypes типы ответов saga.SagaQueryHandler: поля без json тегов, длительности в наносекундах
+const tsSagaTypes = `/** Статус саги */
+export type SagaStatus =
+  | 'pending'
+  | 'running'
+  | 'completed'
+  | 'compensating'
+  | 'compensated'
+  | 'failed'
+  | 'compensation_stuck'
+  | 'waiting_approval';
+
+/** Структурированная ошибка шага саги */
+export interface SagaFailure {
+  category: 'business' | 'technical' | 'timeout' | 'compensation';
+  code: string;
+  message: string;
+  retryable: boolean;
+  details?: Record<string, unknown>;
+}
+
+/** Статус саги (длительности в наносекундах) */
+export interface SagaStatusResponse {
+  SagaID: string;
+  DefinitionName: string;
+  Status: SagaStatus;
+  CurrentStep: string;
+  TotalSteps: number;
+  CompletedSteps: number;
+  FailedSteps: number;
+  StartedAt: string;
+  CompletedAt: string | null;
+  Duration: number | null;
+  CorrelationID: string;
+  Context: Record<string, unknown>;
+  LastError: string | null;
+  LastFailure: SagaFailure | null;
+  RetryCount: number;
+  SLADeadline: string | null;
+  SLARemaining: number | null;
+  SLABreached: boolean;
+}
+
+/** Запись истории шага саги */
+export interface SagaStepHistory {
+  StepName: string;
+  Status: string;
+  StartedAt: string;
+  CompletedAt: string | null;
+  Duration: number | null;
+  RetryAttempt: number;
+  Error: string | null;
+  Failure: SagaFailure | null;
+  StackTrace: string | null;
+}
+
+/** История шагов саги */
+export interface SagaHistoryResponse {
+  SagaID: string;
+  History: SagaStepHistory[];
+}
+
+/** Краткая информация о саге */
+export interface SagaSummary {
+  SagaID: string;
+  DefinitionName: string;
+  Status: SagaStatus;
+  CurrentStep: string;
+  StartedAt: string;
+  CompletedAt: string | null;
+  CorrelationID: string;
+}
+
+/** Список саг */
+export interface SagaListResponse {
+  Sagas: SagaSummary[];
+  Total: number;
+  Limit: number;
+  Offset: number;
+}
+
+/** Фильтры списка саг */
+export interface ListSagasParams {
+  status?: SagaStatus;
+  definition?: string;
+  correlation_id?: string;
+  limit?: number;
+  offset?: number;
+}
+`
@@ -0,0 +1,107 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typeScriptSDKTestSpec() *ParsedSpec {
+	return &ParsedSpec{
+		ModuleName: "shop",
+		Services:   []ServiceSpec{{Name: "ProductService"}},
+		Aggregates: []AggregateSpec{
+			{
+				Name: "Product",
+				Fields: []FieldSpec{
+					{Name: "id", Type: "string", Number: 1},
+					{Name: "price", Type: "decimal", Number: 2},
+					{Name: "tags", Type: "string", Number: 3, Repeated: true},
+				},
+			},
+		},
+		Commands: []CommandSpec{
+			{
+				Name:          "CreateProduct",
+				Aggregate:     "Product",
+				RequestType:   "CreateProductRequest",
+				RequestFields: []FieldSpec{{Name: "name", Type: "string", Number: 1}, {Name: "stock", Type: "int32", Number: 2}},
+			},
+			{
+				Name:          "UpdateProduct",
+				Aggregate:     "Product",
+				RequestType:   "UpdateProductRequest",
+				RequestFields: []FieldSpec{{Name: "id", Type: "string", Number: 1}, {Name: "name", Type: "string", Number: 2}},
+			},
+			{
+				Name:          "DeleteProduct",
+				Aggregate:     "Product",
+				RequestType:   "DeleteProductRequest",
+				RequestFields: []FieldSpec{{Name: "id", Type: "string", Number: 1}},
+			},
+		},
+		Queries: []QuerySpec{
+			{
+				Name:           "GetProduct",
+				RequestType:    "GetProductRequest",
+				ResponseType:   "GetProductResponse",
+				RequestFields:  []FieldSpec{{Name: "id", Type: "string", Number: 1}},
+				ResponseFields: []FieldSpec{{Name: "product", Type: "Product", Number: 1}},
+			},
+			{
+				Name:           "ListProducts",
+				RequestType:    "ListProductsRequest",
+				ResponseType:   "ListProductsResponse",
+				RequestFields:  []FieldSpec{{Name: "page", Type: "int32", Number: 1}, {Name: "category", Type: "string", Number: 2, Optional: true}},
+				ResponseFields: []FieldSpec{{Name: "products", Type: "Product", Number: 1, Repeated: true}},
+			},
+		},
+	}
+}
+
+func TestTypeScriptSDKGenerator_Generate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := &GeneratorConfig{ModulePath: "github.com/acme/shop", OutputDir: tmpDir, Overwrite: true}
+	require.NoError(t, NewTypeScriptSDKGenerator(tmpDir).Generate(typeScriptSDKTestSpec(), config))
+
+	types, err := os.ReadFile(filepath.Join(tmpDir, "pkg/sdk-ts/src/types.ts"))
+	require.NoError(t, err)
+	assert.Contains(t, string(types), "export interface Product {\n  id: string;\n  price: string;\n  tags: string[];\n}")
+	assert.Contains(t, string(types), "export interface ListProductsRequest {\n  page: number;\n  category?: string;\n}")
+	assert.Contains(t, string(types), "  products: Product[];")
+	assert.Contains(t, string(types), "export interface SagaStatusResponse {")
+
+	client, err := os.ReadFile(filepath.Join(tmpDir, "pkg/sdk-ts/src/client.ts"))
+	require.NoError(t, err)
+	content := string(client)
+	assert.Contains(t, content, "export class ProductServiceClient extends BaseClient {")
+	assert.Contains(t, content, "createProduct(request: types.CreateProductRequest): Promise<types.CommandResult> {\n    return this.request<types.CommandResult>('POST', `/api/v1/products`, { body: request });")
+	assert.Contains(t, content, "updateProduct(id: string, request: Omit<types.UpdateProductRequest, 'id'>)")
+	assert.Contains(t, content, "this.request<types.CommandResult>('PUT', `/api/v1/products/${encodeURIComponent(id)}`, { body: request })")
+	assert.Contains(t, content, "deleteProduct(id: string): Promise<types.CommandResult>")
+	assert.Contains(t, content, "getProduct(id: string): Promise<types.GetProductResponse>")
+	assert.Contains(t, content, "listProducts(params: Partial<types.ListProductsRequest> = {}): Promise<types.ListProductsResponse> {\n    return this.request<types.ListProductsResponse>('GET', `/api/v1/products`, { query: { ...params } });")
+	assert.Contains(t, content, "getSagaStatus(sagaId: string): Promise<types.SagaStatusResponse>")
+
+	packageJSON, err := os.ReadFile(filepath.Join(tmpDir, "pkg/sdk-ts/package.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(packageJSON), `"name": "shop-sdk"`)
+}
+
+func TestOpenAPIGenerator_SagaPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, NewOpenAPIGenerator(tmpDir).Generate(typeScriptSDKTestSpec(), &GeneratorConfig{OutputDir: tmpDir}))
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "api/openapi/openapi.yaml"))
+	require.NoError(t, err)
+	spec := string(data)
+	assert.Contains(t, spec, "  /sagas/{id}:\n    get:")
+	assert.Contains(t, spec, "operationId: GetSagaHistory")
+	assert.Contains(t, spec, "    SagaStatusResponse:\n      type: object")
+	assert.Contains(t, spec, "  /products:\n    post:")
+}
@@ -330,6 +330,7 @@ func (u *CodeUpdater) UpdateGeneratedFiles(spec *ParsedSpec, config *GeneratorCo
 	domainGen := NewDomainGenerator(tempDir)
 	appGen := NewApplicationGenerator(tempDir)
 	infraGen := NewInfrastructureGenerator(tempDir)
+	readModelGen := NewReadModelGenerator(tempDir)
 	presentationGen := NewPresentationGenerator(tempDir)
 	mainGen := NewMainGenerator(tempDir)
 
@@ -343,6 +344,9 @@ func (u *CodeUpdater) UpdateGeneratedFiles(spec *ParsedSpec, config *GeneratorCo
 	if err := infraGen.Generate(spec, config); err != nil {
 		return nil, fmt.Errorf("failed to generate infrastructure: %w", err)
 	}
+	if err := readModelGen.Generate(spec, config); err != nil {
+		return nil, fmt.Errorf("failed to generate read models: %w", err)
+	}
 	if err := presentationGen.Generate(spec, config); err != nil {
 		return nil, fmt.Errorf("failed to generate presentation: %w", err)
 	}
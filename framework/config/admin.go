@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// AdminHandler HTTP API для просмотра и изменения настроек:
+//   - GET возвращает текущие значения всех настроек;
+//   - PATCH (или PUT) применяет JSON объект {"имя": значение, ...} атомарно.
+//
+// Каждый запрос проверяется authorizer; без authorizer все запросы отклоняются.
+// Изменения через API не сохраняются в файл и перезаписываются FileWatcher при изменении файла.
+type AdminHandler struct {
+	registry   *Registry
+	authorizer func(r *http.Request) bool
+}
+
+// NewAdminHandler создает административный API настроек
+func NewAdminHandler(registry *Registry, authorizer func(r *http.Request) bool) *AdminHandler {
+	return &AdminHandler{registry: registry, authorizer: authorizer}
+}
+
+// ServeHTTP обрабатывает запрос к API настроек
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorizer == nil || !h.authorizer(r) {
+		writeAdminJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminJSON(w, http.StatusOK, h.registry.Snapshot())
+	case http.MethodPatch, http.MethodPut:
+		var values map[string]json.RawMessage
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&values); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := h.registry.Apply(values); err != nil {
+			status := http.StatusUnprocessableEntity
+			if errors.Is(err, ErrUnknownSetting) {
+				status = http.StatusNotFound
+			}
+			writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, h.registry.Snapshot())
+	default:
+		w.Header().Set("Allow", "GET, PATCH, PUT")
+		writeAdminJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
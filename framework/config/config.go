@@ -0,0 +1,207 @@
+// Package config предоставляет настройки, изменяемые во время работы сервиса без перезапуска:
+// политики повторов, ограничения конкурентности и нагрузки, частоту снапшотов.
+// Значения обновляются из файла (FileWatcher) или через административный API (AdminHandler).
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrUnknownSetting возникает при обновлении незарегистрированной настройки
+	ErrUnknownSetting = errors.New("unknown setting")
+	// ErrInvalidSetting возникает когда новое значение настройки не проходит декодирование или валидацию
+	ErrInvalidSetting = errors.New("invalid setting")
+)
+
+// Reloadable настройка, которую можно обновить во время работы.
+// Обновление двухфазное: Prepare декодирует и проверяет значение, commit применяет его.
+// Это позволяет Registry применить набор настроек атомарно: либо все, либо ни одной.
+type Reloadable interface {
+	// Prepare декодирует и валидирует новое значение и возвращает функцию его применения
+	Prepare(raw json.RawMessage) (commit func(), err error)
+	// Current возвращает текущее значение для отображения
+	Current() interface{}
+}
+
+// Value типизированная настройка с атомарным обновлением.
+// Компоненты фреймворка принимают функции-провайдеры (например func() int),
+// поэтому в них передается метод Get: value.Get.
+type Value[T any] struct {
+	mu        sync.RWMutex
+	value     T
+	validate  func(T) error
+	listeners []func(old, new T)
+}
+
+// NewValue создает настройку с начальным значением
+func NewValue[T any](initial T) *Value[T] {
+	return &Value[T]{value: initial}
+}
+
+// WithValidator задает проверку новых значений; значения, не прошедшие проверку, не применяются
+func (v *Value[T]) WithValidator(validate func(T) error) *Value[T] {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.validate = validate
+	return v
+}
+
+// OnChange регистрирует обработчик изменения значения
+func (v *Value[T]) OnChange(listener func(old, new T)) *Value[T] {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.listeners = append(v.listeners, listener)
+	return v
+}
+
+// Get возвращает текущее значение
+func (v *Value[T]) Get() T {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.value
+}
+
+// Set проверяет и применяет новое значение
+func (v *Value[T]) Set(value T) error {
+	if err := v.check(value); err != nil {
+		return err
+	}
+	v.apply(value)
+	return nil
+}
+
+// Prepare декодирует JSON значение (реализация Reloadable). Значение декодируется поверх
+// текущего, поэтому частичное обновление ({"MaxAttempts": 5}) не сбрасывает остальные поля.
+// Поля time.Duration принимают как число наносекунд, так и строку ("100ms", "2s").
+func (v *Value[T]) Prepare(raw json.RawMessage) (func(), error) {
+	value, err := decodeOnto(v.Get(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSetting, err)
+	}
+	if err := v.check(value); err != nil {
+		return nil, err
+	}
+	return func() { v.apply(value) }, nil
+}
+
+// Current возвращает текущее значение (реализация Reloadable)
+func (v *Value[T]) Current() interface{} {
+	return v.Get()
+}
+
+func (v *Value[T]) check(value T) error {
+	v.mu.RLock()
+	validate := v.validate
+	v.mu.RUnlock()
+
+	if validate != nil {
+		if err := validate(value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSetting, err)
+		}
+	}
+	return nil
+}
+
+func (v *Value[T]) apply(value T) {
+	v.mu.Lock()
+	old := v.value
+	v.value = value
+	listeners := v.listeners
+	v.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(old, value)
+	}
+}
+
+// Registry именованный набор настроек, обновляемых во время работы
+type Registry struct {
+	mu       sync.RWMutex
+	settings map[string]Reloadable
+	// applyMu сериализует обновления, чтобы набор настроек применялся целиком
+	applyMu sync.Mutex
+}
+
+// NewRegistry создает пустой реестр настроек
+func NewRegistry() *Registry {
+	return &Registry{settings: make(map[string]Reloadable)}
+}
+
+// Register регистрирует настройку под именем name (например "orders.retry")
+func (r *Registry) Register(name string, setting Reloadable) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.settings[name]; exists {
+		return fmt.Errorf("setting %s already registered", name)
+	}
+	r.settings[name] = setting
+	return nil
+}
+
+// MustRegister регистрирует настройку и паникует при повторной регистрации
+func (r *Registry) MustRegister(name string, setting Reloadable) {
+	if err := r.Register(name, setting); err != nil {
+		panic(err)
+	}
+}
+
+// Apply применяет набор значений атомарно: если хотя бы одно значение неизвестно
+// или некорректно, ни одно значение не изменяется
+func (r *Registry) Apply(values map[string]json.RawMessage) error {
+	r.applyMu.Lock()
+	defer r.applyMu.Unlock()
+
+	r.mu.RLock()
+	commits := make([]func(), 0, len(values))
+	var errs []error
+	for _, name := range sortedNames(values) {
+		setting, exists := r.settings[name]
+		if !exists {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownSetting, name))
+			continue
+		}
+		commit, err := setting.Prepare(values[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("setting %s: %w", name, err))
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	r.mu.RUnlock()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, commit := range commits {
+		commit()
+	}
+	return nil
+}
+
+// Snapshot возвращает текущие значения всех настроек
+func (r *Registry) Snapshot() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := make(map[string]interface{}, len(r.settings))
+	for name, setting := range r.settings {
+		snapshot[name] = setting.Current()
+	}
+	return snapshot
+}
+
+func sortedNames[T any](values map[string]T) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type retrySettings struct {
+	MaxAttempts int
+	Backoff     float64
+}
+
+func newTestRegistry(t *testing.T) (*Registry, *Value[int], *Value[retrySettings]) {
+	t.Helper()
+
+	limit := NewValue(10).WithValidator(func(v int) error {
+		if v < 0 {
+			return fmt.Errorf("limit must be non-negative")
+		}
+		return nil
+	})
+	retry := NewValue(retrySettings{MaxAttempts: 3, Backoff: 2})
+
+	registry := NewRegistry()
+	registry.MustRegister("commands.max_concurrent", limit)
+	registry.MustRegister("orders.retry", retry)
+	return registry, limit, retry
+}
+
+func TestRegistry_ApplyIsAtomic(t *testing.T) {
+	registry, limit, retry := newTestRegistry(t)
+
+	var changed []int
+	limit.OnChange(func(old, new int) { changed = append(changed, old, new) })
+
+	err := registry.Apply(map[string]json.RawMessage{
+		"commands.max_concurrent": json.RawMessage(`20`),
+		"orders.retry":            json.RawMessage(`{"MaxAttempts": 5, "Backoff": 1.5}`),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if limit.Get() != 20 || retry.Get().MaxAttempts != 5 {
+		t.Errorf("Unexpected values: %d, %+v", limit.Get(), retry.Get())
+	}
+	if len(changed) != 2 || changed[0] != 10 || changed[1] != 20 {
+		t.Errorf("Expected change notification 10 -> 20, got %v", changed)
+	}
+
+	// Некорректное значение отменяет применение всего набора
+	err = registry.Apply(map[string]json.RawMessage{
+		"commands.max_concurrent": json.RawMessage(`-1`),
+		"orders.retry":            json.RawMessage(`{"MaxAttempts": 7}`),
+	})
+	if !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("Expected ErrInvalidSetting, got %v", err)
+	}
+	if limit.Get() != 20 || retry.Get().MaxAttempts != 5 {
+		t.Errorf("Values must not change on invalid update: %d, %+v", limit.Get(), retry.Get())
+	}
+
+	err = registry.Apply(map[string]json.RawMessage{"unknown": json.RawMessage(`1`)})
+	if !errors.Is(err, ErrUnknownSetting) {
+		t.Errorf("Expected ErrUnknownSetting, got %v", err)
+	}
+}
+
+type backoffSettings struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration
+	Codes          []string
+}
+
+func TestValue_PartialUpdate(t *testing.T) {
+	initial := &backoffSettings{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second, Codes: []string{"a", "b"}}
+	value := NewValue(initial)
+
+	commit, err := value.Prepare(json.RawMessage(`{"MaxAttempts": 5, "Codes": ["c"]}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	commit()
+	got := value.Get()
+	if got.MaxAttempts != 5 || got.InitialBackoff != 10*time.Millisecond || got.MaxBackoff != time.Second {
+		t.Errorf("Expected partial update to keep other fields, got %+v", got)
+	}
+	if len(got.Codes) != 1 || got.Codes[0] != "c" {
+		t.Errorf("Expected Codes to be replaced, got %v", got.Codes)
+	}
+	// Предыдущее значение не изменяется: слушатели получают его как old
+	if initial.MaxAttempts != 3 || initial.Codes[0] != "a" {
+		t.Errorf("Expected previous value to stay intact, got %+v", initial)
+	}
+
+	// Длительности принимаются строками time.ParseDuration и числом наносекунд
+	commit, err = value.Prepare(json.RawMessage(`{"initial_backoff": "100ms", "maxbackoff": 2000000000}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	commit()
+	if got := value.Get(); got.InitialBackoff != 100*time.Millisecond || got.MaxBackoff != 2*time.Second || got.MaxAttempts != 5 {
+		t.Errorf("Unexpected durations: %+v", got)
+	}
+
+	if _, err := value.Prepare(json.RawMessage(`{"MaxBackoff": "soon"}`)); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("Expected ErrInvalidSetting for invalid duration, got %v", err)
+	}
+}
+
+func TestLoadFile_YAMLDurations(t *testing.T) {
+	registry := NewRegistry()
+	timeout := NewValue(time.Second)
+	backoff := NewValue(backoffSettings{MaxAttempts: 3, MaxBackoff: time.Second})
+	registry.MustRegister("commands.timeout", timeout)
+	registry.MustRegister("orders.backoff", backoff)
+
+	path := filepath.Join(t.TempDir(), "settings.yaml")
+	content := "commands.timeout: 250ms\norders.backoff:\n  initial_backoff: 50ms\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadFile(registry, path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if timeout.Get() != 250*time.Millisecond {
+		t.Errorf("Expected 250ms timeout, got %s", timeout.Get())
+	}
+	if got := backoff.Get(); got.InitialBackoff != 50*time.Millisecond || got.MaxBackoff != time.Second || got.MaxAttempts != 3 {
+		t.Errorf("Unexpected backoff settings: %+v", got)
+	}
+}
+
+func TestFileWatcher_Reload(t *testing.T) {
+	registry, limit, retry := newTestRegistry(t)
+
+	path := filepath.Join(t.TempDir(), "settings.yaml")
+	if err := os.WriteFile(path, []byte("commands.max_concurrent: 5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan struct{}, 1)
+	errs := make(chan error, 1)
+	watcher := NewFileWatcher(registry, path).
+		WithInterval(10 * time.Millisecond).
+		WithReloadHandler(func() { reloaded <- struct{}{} }).
+		WithErrorHandler(func(err error) { errs <- err })
+
+	if err := watcher.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer watcher.Stop()
+
+	if limit.Get() != 5 {
+		t.Errorf("Expected initial file to be applied, got %d", limit.Get())
+	}
+
+	content := "commands.max_concurrent: 8\norders.retry:\n  MaxAttempts: 6\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected file to be reloaded")
+	}
+	if limit.Get() != 8 || retry.Get().MaxAttempts != 6 {
+		t.Errorf("Unexpected values after reload: %d, %+v", limit.Get(), retry.Get())
+	}
+
+	// Некорректный файл не применяется
+	if err := os.WriteFile(path, []byte("commands.max_concurrent: -3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrInvalidSetting) {
+			t.Errorf("Expected ErrInvalidSetting, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected reload error")
+	}
+	if limit.Get() != 8 {
+		t.Errorf("Expected previous value to be kept, got %d", limit.Get())
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	registry, limit, _ := newTestRegistry(t)
+	handler := NewAdminHandler(registry, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer secret"
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/admin/config", strings.NewReader(`{"commands.max_concurrent": 3}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/admin/config", strings.NewReader(`{"commands.max_concurrent": 3}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || limit.Get() != 3 {
+		t.Errorf("Expected update to be applied, got %d (limit %d)", rec.Code, limit.Get())
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/admin/config", strings.NewReader(`{"unknown": 1}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown setting, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var snapshot map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Expected JSON snapshot, got %v", err)
+	}
+	if string(snapshot["commands.max_concurrent"]) != "3" {
+		t.Errorf("Unexpected snapshot: %s", rec.Body.String())
+	}
+}
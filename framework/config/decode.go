@@ -0,0 +1,168 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decodeOnto декодирует JSON поверх копии current: поля, отсутствующие в raw, сохраняют
+// текущие значения. Поля time.Duration принимают строки в формате time.ParseDuration ("100ms").
+func decodeOnto[T any](current T, raw json.RawMessage) (T, error) {
+	value := cloneValue(reflect.ValueOf(&current).Elem()).Interface().(T)
+
+	var parsed interface{}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return value, err
+	}
+	normalized, err := normalizeDurations(parsed, reflect.TypeOf(&value).Elem())
+	if err != nil {
+		return value, err
+	}
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// cloneValue возвращает глубокую копию значения: json.Unmarshal изменяет указатели,
+// срезы и карты на месте, поэтому декодирование в неглубокую копию изменило бы текущее значение
+func cloneValue(src reflect.Value) reflect.Value {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.New(src.Type().Elem())
+		dst.Elem().Set(cloneValue(src.Elem()))
+		return dst
+	case reflect.Struct:
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(src)
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(cloneValue(src.Field(i)))
+			}
+		}
+		return dst
+	case reflect.Slice:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(cloneValue(src.Index(i)))
+		}
+		return dst
+	case reflect.Array:
+		dst := reflect.New(src.Type()).Elem()
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(cloneValue(src.Index(i)))
+		}
+		return dst
+	case reflect.Map:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return dst
+	}
+	return src
+}
+
+// normalizeDurations заменяет строковые значения полей time.Duration числом наносекунд
+func normalizeDurations(data interface{}, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		if s, ok := data.(string); ok {
+			duration, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, err
+			}
+			return int64(duration), nil
+		}
+		return data, nil
+	}
+
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			var itemType reflect.Type
+			switch t.Kind() {
+			case reflect.Struct:
+				field, ok := jsonField(t, key)
+				if !ok {
+					continue
+				}
+				itemType = field.Type
+			case reflect.Map:
+				itemType = t.Elem()
+			default:
+				continue
+			}
+			normalized, err := normalizeDurations(item, itemType)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			value[key] = normalized
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return data, nil
+		}
+		for i, item := range value {
+			normalized, err := normalizeDurations(item, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			value[i] = normalized
+		}
+	}
+	return data, nil
+}
+
+// jsonField находит поле структуры по ключу JSON так же, как encoding/json:
+// по имени из тега json или по имени поля без учета регистра
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fallback *reflect.StructField
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || (field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tagName, _, _ := strings.Cut(tag, ","); tagName != "" {
+				name = tagName
+			}
+		}
+		if name == key {
+			return field, true
+		}
+		if fallback == nil && strings.EqualFold(name, key) {
+			field := field
+			fallback = &field
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return reflect.StructField{}, false
+}
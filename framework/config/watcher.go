@@ -0,0 +1,179 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFile читает файл настроек (JSON или YAML по расширению .yaml/.yml) и применяет его к реестру.
+// Ключи верхнего уровня - имена настроек; файл может содержать только часть настроек.
+func LoadFile(registry *Registry, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values, err := parseSettings(path, data)
+	if err != nil {
+		return err
+	}
+	return registry.Apply(values)
+}
+
+// parseSettings разбирает файл настроек в набор JSON значений
+func parseSettings(path string, data []byte) (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var parsed map[string]interface{}
+		if err := yaml.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		for name, value := range parsed {
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert setting %s: %w", name, err)
+			}
+			values[name] = raw
+		}
+	default:
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	return values, nil
+}
+
+// FileWatcher периодически проверяет файл настроек и применяет его при изменении.
+// Используется опрос времени модификации и размера файла, что работает и для
+// ConfigMap в Kubernetes (файл заменяется через symlink). Некорректный файл не применяется,
+// текущие значения сохраняются до следующего изменения файла.
+type FileWatcher struct {
+	registry *Registry
+	path     string
+	interval time.Duration
+	onError  func(err error)
+	onReload func()
+	modTime  time.Time
+	size     int64
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+// NewFileWatcher создает наблюдатель за файлом настроек
+func NewFileWatcher(registry *Registry, path string) *FileWatcher {
+	return &FileWatcher{
+		registry: registry,
+		path:     path,
+		interval: 5 * time.Second,
+	}
+}
+
+// WithInterval задает период проверки файла (по умолчанию 5 секунд)
+func (w *FileWatcher) WithInterval(interval time.Duration) *FileWatcher {
+	w.interval = interval
+	return w
+}
+
+// WithErrorHandler задает обработчик ошибок чтения и применения файла
+func (w *FileWatcher) WithErrorHandler(onError func(err error)) *FileWatcher {
+	w.onError = onError
+	return w
+}
+
+// WithReloadHandler задает обработчик успешного применения файла
+func (w *FileWatcher) WithReloadHandler(onReload func()) *FileWatcher {
+	w.onReload = onReload
+	return w
+}
+
+// Start применяет файл и запускает наблюдение. Ошибка первого применения возвращается,
+// чтобы сервис не стартовал с некорректной конфигурацией.
+func (w *FileWatcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopCh != nil {
+		return fmt.Errorf("file watcher already started")
+	}
+
+	if _, err := w.reload(); err != nil {
+		return err
+	}
+
+	w.stopCh = make(chan struct{})
+	w.wg.Add(1)
+	go w.watch(ctx, w.stopCh)
+	return nil
+}
+
+// Stop останавливает наблюдение
+func (w *FileWatcher) Stop() {
+	w.mu.Lock()
+	stopCh := w.stopCh
+	w.stopCh = nil
+	w.mu.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+		w.wg.Wait()
+	}
+}
+
+func (w *FileWatcher) watch(ctx context.Context, stopCh chan struct{}) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			reloaded, err := w.reload()
+			if err != nil {
+				if w.onError != nil {
+					w.onError(err)
+				}
+				continue
+			}
+			if reloaded && w.onReload != nil {
+				w.onReload()
+			}
+		}
+	}
+}
+
+// reload применяет файл, если он изменился с последней проверки
+func (w *FileWatcher) reload() (bool, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat config file: %w", err)
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false, nil
+	}
+
+	// Запоминаем версию файла до применения: некорректный файл не перечитывается до следующего изменения
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	if err := LoadFile(w.registry, w.path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
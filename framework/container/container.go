@@ -3,9 +3,12 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/akriventsev/potter/framework/core"
 )
 
 // Container контейнер зависимостей
@@ -187,7 +190,10 @@ func (c *Container) AddActiveTransport(transport Transport) {
 	c.activeTransports = append(c.activeTransports, transport)
 }
 
-// Shutdown корректно завершает работу всех зависимостей
+// Shutdown корректно завершает работу всех зависимостей: останавливает транспорты,
+// дожидается подтверждения буферизованных публикаций (core.Flushable) и закрывает
+// зависимости, реализующие Disposable. Возвращает ошибки Flush - публикации,
+// которые могли быть потеряны.
 func (c *Container) Shutdown(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, c.Config.ShutdownTimeout)
 	defer cancel()
@@ -200,16 +206,27 @@ func (c *Container) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// Закрываем зависимости, реализующие Disposable
 	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// Дожидаемся публикации сообщений, принятых до остановки транспортов
+	var flushErrs []error
+	for name, dep := range c.dependencies {
+		if flushable, ok := dep.(core.Flushable); ok {
+			if err := flushable.Flush(ctx); err != nil {
+				flushErrs = append(flushErrs, fmt.Errorf("failed to flush %s: %w", name, err))
+			}
+		}
+	}
+
+	// Закрываем зависимости, реализующие Disposable
 	for _, dep := range c.dependencies {
 		if disposable, ok := dep.(interface{ Dispose(context.Context) error }); ok {
 			_ = disposable.Dispose(ctx)
 		}
 	}
-	c.mu.RUnlock()
 
-	return nil
+	return errors.Join(flushErrs...)
 }
 
 // DetectCircularDependencies обнаруживает циклические зависимости
@@ -2,6 +2,7 @@ package container
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -215,6 +216,57 @@ func TestContainer_Shutdown_Disposable(t *testing.T) {
 	}
 }
 
+// MockFlushable зависимость с буферизованными публикациями для тестирования
+type MockFlushable struct {
+	calls []string
+	err   error
+}
+
+func (m *MockFlushable) Flush(ctx context.Context) error {
+	m.calls = append(m.calls, "flush")
+	return m.err
+}
+
+func (m *MockFlushable) Dispose(ctx context.Context) error {
+	m.calls = append(m.calls, "dispose")
+	return nil
+}
+
+func TestContainer_Shutdown_FlushesBeforeDispose(t *testing.T) {
+	container := NewContainer(nil)
+
+	flushable := &MockFlushable{}
+	if err := Set[*MockFlushable](container, "publisher", flushable); err != nil {
+		t.Fatalf("Failed to set flushable: %v", err)
+	}
+
+	if err := container.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(flushable.calls) != 2 || flushable.calls[0] != "flush" || flushable.calls[1] != "dispose" {
+		t.Errorf("Expected flush before dispose, got %v", flushable.calls)
+	}
+}
+
+func TestContainer_Shutdown_FlushError(t *testing.T) {
+	container := NewContainer(nil)
+
+	flushErr := errors.New("broker unavailable")
+	flushable := &MockFlushable{err: flushErr}
+	if err := Set[*MockFlushable](container, "publisher", flushable); err != nil {
+		t.Fatalf("Failed to set flushable: %v", err)
+	}
+
+	err := container.Shutdown(context.Background())
+	if !errors.Is(err, flushErr) {
+		t.Errorf("Expected flush error, got %v", err)
+	}
+	// Зависимость закрывается и при ошибке Flush
+	if len(flushable.calls) != 2 {
+		t.Errorf("Expected dispose after failed flush, got %v", flushable.calls)
+	}
+}
+
 func TestContainer_ConcurrentAccess(t *testing.T) {
 	container := NewContainer(nil)
 
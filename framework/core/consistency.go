@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ConsistencyTokenHeader HTTP заголовок, в котором передается токен согласованности
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+// ErrConsistencyTimeout проекция не достигла позиции токена согласованности за отведенное время
+var ErrConsistencyTimeout = errors.New("projection did not reach consistency token in time")
+
+// ConsistencyToken токен согласованности: глобальная позиция последнего события, записанного командой.
+// Запрос с токеном ожидает, пока проекции обработают события до этой позиции (read-your-writes).
+type ConsistencyToken int64
+
+// String возвращает строковое представление токена (для заголовков и ответов API)
+func (t ConsistencyToken) String() string {
+	return strconv.FormatInt(int64(t), 10)
+}
+
+// ParseConsistencyToken разбирает токен согласованности
+func ParseConsistencyToken(value string) (ConsistencyToken, error) {
+	position, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || position < 0 {
+		return 0, fmt.Errorf("invalid consistency token %q", value)
+	}
+	return ConsistencyToken(position), nil
+}
+
+// ConsistencyTracker собирает позиции событий, записанных при обработке команды
+type ConsistencyTracker struct {
+	mu    sync.Mutex
+	token ConsistencyToken
+}
+
+// Observe учитывает позицию записанного события
+func (t *ConsistencyTracker) Observe(token ConsistencyToken) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if token > t.token {
+		t.token = token
+	}
+}
+
+// Token возвращает токен согласованности: максимальную позицию записанных событий (0 - событий не было)
+func (t *ConsistencyTracker) Token() ConsistencyToken {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token
+}
+
+type consistencyTrackerKey struct{}
+type consistencyTokenKey struct{}
+
+// TrackConsistency включает сбор токена согласованности для команды, выполняемой с возвращенным контекстом.
+// eventsourcing.EventSourcedRepository.Save сообщает трекеру позицию сохраненных событий.
+func TrackConsistency(ctx context.Context) (context.Context, *ConsistencyTracker) {
+	tracker := &ConsistencyTracker{}
+	return context.WithValue(ctx, consistencyTrackerKey{}, tracker), tracker
+}
+
+// ConsistencyTrackerFromContext возвращает трекер контекста (nil - токен не собирается)
+func ConsistencyTrackerFromContext(ctx context.Context) *ConsistencyTracker {
+	tracker, _ := ctx.Value(consistencyTrackerKey{}).(*ConsistencyTracker)
+	return tracker
+}
+
+// RecordConsistency сообщает трекеру контекста позицию записанного события
+// (для хранилищ и обработчиков, записывающих события в обход EventSourcedRepository)
+func RecordConsistency(ctx context.Context, token ConsistencyToken) {
+	if tracker := ConsistencyTrackerFromContext(ctx); tracker != nil {
+		tracker.Observe(token)
+	}
+}
+
+// WithConsistencyToken передает токен согласованности запросу (см. eventsourcing.ProjectionManager.WaitForContextToken)
+func WithConsistencyToken(ctx context.Context, token ConsistencyToken) context.Context {
+	return context.WithValue(ctx, consistencyTokenKey{}, token)
+}
+
+// ConsistencyTokenFromContext возвращает токен согласованности запроса
+func ConsistencyTokenFromContext(ctx context.Context) (ConsistencyToken, bool) {
+	token, ok := ctx.Value(consistencyTokenKey{}).(ConsistencyToken)
+	return token, ok && token > 0
+}
@@ -2,8 +2,10 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
+	"runtime/debug"
 	"strings"
 )
 
@@ -14,6 +16,7 @@ const (
 	ErrInvalidConfig       = "INVALID_CONFIG"
 	ErrInitializationFailed = "INITIALIZATION_FAILED"
 	ErrDependencyNotFound  = "DEPENDENCY_NOT_FOUND"
+	ErrPanicRecovered      = "PANIC_RECOVERED"
 )
 
 // FrameworkError базовый тип ошибки фреймворка
@@ -90,6 +93,65 @@ func WrapWithCode(err error, code string) *FrameworkError {
 	}
 }
 
+// NewPanicError создает ошибку из значения, полученного от recover().
+// StackTrace содержит стек горутины в момент паники; если паника вызвана ошибкой, она становится Cause.
+func NewPanicError(recovered interface{}) *FrameworkError {
+	err := &FrameworkError{
+		Code:       ErrPanicRecovered,
+		Message:    fmt.Sprintf("panic recovered: %v", recovered),
+		StackTrace: string(debug.Stack()),
+	}
+	if cause, ok := recovered.(error); ok {
+		err.Cause = cause
+	}
+	return err
+}
+
+// Recover преобразует панику в ошибку *errp. Вызывается только через defer:
+//
+//	defer core.Recover(&err)
+func Recover(errp *error) {
+	if r := recover(); r != nil {
+		*errp = NewPanicError(r)
+	}
+}
+
+// SafeCall выполняет fn и возвращает панику как ошибку с кодом ErrPanicRecovered
+func SafeCall(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+// IsPanic проверяет, является ли ошибка (или одна из ее причин) восстановленной паникой
+func IsPanic(err error) bool {
+	var frameworkErr *FrameworkError
+	for err != nil {
+		if !errors.As(err, &frameworkErr) {
+			return false
+		}
+		if frameworkErr.Code == ErrPanicRecovered {
+			return true
+		}
+		err = frameworkErr.Cause
+	}
+	return false
+}
+
+// StackTraceOf возвращает stack trace восстановленной паники из цепочки ошибок или пустую строку
+func StackTraceOf(err error) string {
+	var frameworkErr *FrameworkError
+	for err != nil {
+		if !errors.As(err, &frameworkErr) {
+			return ""
+		}
+		if frameworkErr.Code == ErrPanicRecovered {
+			return frameworkErr.StackTrace
+		}
+		err = frameworkErr.Cause
+	}
+	return ""
+}
+
 // captureStackTrace захватывает stack trace
 func captureStackTrace() string {
 	buf := make([]byte, 4096)
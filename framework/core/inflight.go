@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// InFlight счетчик выполняющихся операций для graceful shutdown: Wait дожидается
+// завершения начатых операций, после Close новые операции не начинаются.
+// Нулевое значение готово к использованию.
+type InFlight struct {
+	mu     sync.Mutex
+	count  int
+	closed bool
+	idle   chan struct{}
+}
+
+// Begin регистрирует начало операции. Возвращает false после Close -
+// операцию выполнять нельзя. Каждый успешный Begin завершается вызовом End.
+func (f *InFlight) Begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return false
+	}
+	if f.count == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.count++
+	return true
+}
+
+// End регистрирует завершение операции
+func (f *InFlight) End() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		return
+	}
+	f.count--
+	if f.count == 0 {
+		close(f.idle)
+	}
+}
+
+// Close запрещает начало новых операций (уже начатые продолжают выполняться)
+func (f *InFlight) Close() {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+}
+
+// Closed проверяет, вызван ли Close
+func (f *InFlight) Closed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// Count возвращает количество выполняющихся операций
+func (f *InFlight) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.count
+}
+
+// Wait дожидается завершения всех начатых операций или отмены контекста
+func (f *InFlight) Wait(ctx context.Context) error {
+	f.mu.Lock()
+	if f.count == 0 {
+		f.mu.Unlock()
+		return nil
+	}
+	idle := f.idle
+	f.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
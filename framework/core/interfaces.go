@@ -41,6 +41,12 @@ type Disposable interface {
 	Dispose(ctx context.Context) error
 }
 
+// Flushable интерфейс для компонентов, буферизующих исходящие сообщения
+type Flushable interface {
+	// Flush дожидается подтверждения всех буферизованных и выполняющихся публикаций
+	Flush(ctx context.Context) error
+}
+
 // HealthCheckable интерфейс для проверки здоровья компонентов
 type HealthCheckable interface {
 	// HealthCheck проверяет здоровье компонента
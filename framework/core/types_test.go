@@ -3,7 +3,10 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFrameworkContext_GetMetadata(t *testing.T) {
@@ -246,3 +249,62 @@ func TestOption_ValueOr(t *testing.T) {
 	}
 }
 
+
+func TestSafeCall_RecoversPanic(t *testing.T) {
+	cause := errors.New("nil map")
+	err := SafeCall(func() error {
+		panic(cause)
+	})
+	if !IsPanic(err) {
+		t.Fatalf("Expected panic error, got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected panic value to be the cause")
+	}
+	if !strings.Contains(StackTraceOf(fmt.Errorf("handler failed: %w", err)), "TestSafeCall_RecoversPanic") {
+		t.Errorf("Expected stack trace of the panicking goroutine, got %q", StackTraceOf(err))
+	}
+
+	plain := errors.New("plain")
+	if err := SafeCall(func() error { return plain }); err != plain || IsPanic(err) || StackTraceOf(err) != "" {
+		t.Errorf("Expected plain error to be returned unchanged, got %v", err)
+	}
+}
+
+func TestInFlight_WaitAndClose(t *testing.T) {
+	var inflight InFlight
+
+	// Без операций Wait возвращается сразу
+	if err := inflight.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !inflight.Begin() {
+		t.Fatal("Expected Begin to succeed before Close")
+	}
+	inflight.Close()
+	if inflight.Begin() {
+		t.Error("Expected Begin to fail after Close")
+	}
+	if inflight.Count() != 1 {
+		t.Errorf("Expected 1 in-flight operation, got %d", inflight.Count())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := inflight.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while operation is running, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- inflight.Wait(context.Background()) }()
+	inflight.End()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Wait to return after End")
+	}
+}
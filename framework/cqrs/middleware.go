@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/transport"
 )
 
@@ -88,26 +89,20 @@ func ValidationQueryMiddleware(validator func(ctx context.Context, q transport.Q
 	}
 }
 
-// RecoveryCommandMiddleware восстанавливает панику в обработчиках команд
+// RecoveryCommandMiddleware восстанавливает панику в обработчиках команд.
+// Паника возвращается как *core.FrameworkError с кодом core.ErrPanicRecovered и stack trace.
 func RecoveryCommandMiddleware() CommandMiddleware {
 	return func(ctx context.Context, cmd transport.Command, next func(ctx context.Context, cmd transport.Command) error) (err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				err = fmt.Errorf("panic recovered: %v", r)
-			}
-		}()
+		defer core.Recover(&err)
 		return next(ctx, cmd)
 	}
 }
 
-// RecoveryQueryMiddleware восстанавливает панику в обработчиках запросов
+// RecoveryQueryMiddleware восстанавливает панику в обработчиках запросов.
+// Паника возвращается как *core.FrameworkError с кодом core.ErrPanicRecovered и stack trace.
 func RecoveryQueryMiddleware() QueryMiddleware {
 	return func(ctx context.Context, q transport.Query, next func(ctx context.Context, q transport.Query) (interface{}, error)) (result interface{}, err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				err = fmt.Errorf("panic recovered: %v", r)
-			}
-		}()
+		defer core.Recover(&err)
 		return next(ctx, q)
 	}
 }
@@ -274,6 +269,161 @@ func RateLimitQueryMiddleware(maxConcurrent int) QueryMiddleware {
 	}
 }
 
+// DynamicRateLimitCommandMiddleware ограничивает число одновременно выполняемых команд.
+// Лимит читается при каждом запросе, поэтому его можно изменять во время работы
+// (например config.Value[int].Get). Лимит <= 0 снимает ограничение.
+func DynamicRateLimitCommandMiddleware(limit func() int) CommandMiddleware {
+	sem := newDynamicSemaphore(limit)
+
+	return func(ctx context.Context, cmd transport.Command, next func(ctx context.Context, cmd transport.Command) error) error {
+		if err := sem.acquire(ctx); err != nil {
+			return err
+		}
+		defer sem.release()
+		return next(ctx, cmd)
+	}
+}
+
+// DynamicRateLimitQueryMiddleware ограничивает число одновременно выполняемых запросов
+// (см. DynamicRateLimitCommandMiddleware)
+func DynamicRateLimitQueryMiddleware(limit func() int) QueryMiddleware {
+	sem := newDynamicSemaphore(limit)
+
+	return func(ctx context.Context, q transport.Query, next func(ctx context.Context, q transport.Query) (interface{}, error)) (interface{}, error) {
+		if err := sem.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer sem.release()
+		return next(ctx, q)
+	}
+}
+
+// ThrottleCommandMiddleware ограничивает частоту команд (команд в секунду, token bucket).
+// Частота читается при каждом запросе и может изменяться во время работы; <= 0 снимает ограничение.
+func ThrottleCommandMiddleware(ratePerSecond func() float64) CommandMiddleware {
+	bucket := newTokenBucket(ratePerSecond)
+
+	return func(ctx context.Context, cmd transport.Command, next func(ctx context.Context, cmd transport.Command) error) error {
+		if err := bucket.wait(ctx); err != nil {
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ThrottleQueryMiddleware ограничивает частоту запросов (см. ThrottleCommandMiddleware)
+func ThrottleQueryMiddleware(ratePerSecond func() float64) QueryMiddleware {
+	bucket := newTokenBucket(ratePerSecond)
+
+	return func(ctx context.Context, q transport.Query, next func(ctx context.Context, q transport.Query) (interface{}, error)) (interface{}, error) {
+		if err := bucket.wait(ctx); err != nil {
+			return nil, err
+		}
+		return next(ctx, q)
+	}
+}
+
+// dynamicSemaphore семафор с изменяемой емкостью
+type dynamicSemaphore struct {
+	limit    func() int
+	mu       sync.Mutex
+	active   int
+	released chan struct{}
+}
+
+func newDynamicSemaphore(limit func() int) *dynamicSemaphore {
+	return &dynamicSemaphore{limit: limit, released: make(chan struct{})}
+}
+
+func (s *dynamicSemaphore) acquire(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if limit := s.limit(); limit <= 0 || s.active < limit {
+			s.active++
+			s.mu.Unlock()
+			return nil
+		}
+		released := s.released
+		s.mu.Unlock()
+
+		// Ожидающие проверяют лимит заново после каждого освобождения
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *dynamicSemaphore) release() {
+	s.mu.Lock()
+	s.active--
+	close(s.released)
+	s.released = make(chan struct{})
+	s.mu.Unlock()
+}
+
+// tokenBucket token bucket с изменяемой частотой; емкость равна частоте за секунду (не меньше 1)
+type tokenBucket struct {
+	rate   func() float64
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate func() float64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: -1}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		delay, ok := b.take()
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// take забирает токен или возвращает время ожидания следующего токена
+func (b *tokenBucket) take() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rate := b.rate()
+	if rate <= 0 {
+		return 0, true
+	}
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := time.Now()
+	if b.tokens < 0 {
+		b.tokens = burst
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * rate
+	}
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second)), false
+}
+
 // TracingCommandMiddleware добавляет distributed tracing
 func TracingCommandMiddleware(tracer interface {
 	StartSpan(string) interface{ End() }
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// EventTypeAliases реестр переименований типов событий: старое имя типа отображается
+// на актуальное при чтении (event store, декодирование сообщений, проекции), поэтому
+// переименование OrderCreated -> OrderPlaced не ломает историю. Цепочки переименований
+// (A -> B, B -> C) разрешаются до последнего имени.
+type EventTypeAliases struct {
+	aliases map[string]string
+	mu      sync.RWMutex
+}
+
+// NewEventTypeAliases создает пустой реестр переименований
+func NewEventTypeAliases() *EventTypeAliases {
+	return &EventTypeAliases{aliases: make(map[string]string)}
+}
+
+// Register регистрирует переименование типа oldType в newType.
+// Возвращает ошибку при повторной регистрации oldType с другим именем или цикле переименований.
+func (a *EventTypeAliases) Register(oldType, newType string) error {
+	if oldType == "" || newType == "" || oldType == newType {
+		return fmt.Errorf("invalid event type alias %q -> %q", oldType, newType)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if current, exists := a.aliases[oldType]; exists {
+		if current == newType {
+			return nil
+		}
+		return fmt.Errorf("event type %s is already renamed to %s", oldType, current)
+	}
+	if a.resolve(newType) == oldType {
+		return fmt.Errorf("event type alias %s -> %s creates a cycle", oldType, newType)
+	}
+	a.aliases[oldType] = newType
+	return nil
+}
+
+// MustRegister регистрирует переименование и паникует при ошибке (для инициализации при старте)
+func (a *EventTypeAliases) MustRegister(oldType, newType string) *EventTypeAliases {
+	if err := a.Register(oldType, newType); err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Resolve возвращает актуальное имя типа события (eventType, если он не переименован)
+func (a *EventTypeAliases) Resolve(eventType string) string {
+	if a == nil {
+		return eventType
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.resolve(eventType)
+}
+
+// resolve разрешает цепочку переименований (вызывается под блокировкой)
+func (a *EventTypeAliases) resolve(eventType string) string {
+	for {
+		next, ok := a.aliases[eventType]
+		if !ok {
+			return eventType
+		}
+		eventType = next
+	}
+}
+
+// OldNames возвращает все прежние имена типа eventType (включая промежуточные имена цепочки)
+func (a *EventTypeAliases) OldNames(eventType string) []string {
+	if a == nil {
+		return nil
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var names []string
+	for oldType := range a.aliases {
+		if a.resolve(oldType) == eventType {
+			names = append(names, oldType)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Renames возвращает переименования: старое имя -> актуальное имя типа
+func (a *EventTypeAliases) Renames() map[string]string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	renames := make(map[string]string, len(a.aliases))
+	for oldType := range a.aliases {
+		renames[oldType] = a.resolve(oldType)
+	}
+	return renames
+}
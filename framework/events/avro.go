@@ -0,0 +1,266 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// avroMagicByte первый байт payload в wire format schema registry (Confluent)
+const avroMagicByte = 0
+
+// SchemaRegistry клиент schema registry Avro схем
+type SchemaRegistry interface {
+	// Register регистрирует схему в subject (повторная регистрация возвращает тот же идентификатор)
+	Register(ctx context.Context, subject, schema string) (int, error)
+	// Schema возвращает схему по идентификатору
+	Schema(ctx context.Context, id int) (string, error)
+}
+
+// AvroBinding кодирует значения в Avro binary по схеме. Реализуется адаптером
+// Avro библиотеки приложения (hamba/avro, linkedin/goavro), поэтому фреймворк
+// не зависит от конкретной реализации Avro.
+type AvroBinding interface {
+	// Marshal кодирует значение по схеме
+	Marshal(schema string, value interface{}) ([]byte, error)
+	// Unmarshal декодирует данные, записанные по схеме writerSchema, в значение
+	Unmarshal(writerSchema string, data []byte, value interface{}) error
+}
+
+// AvroCodec кодирует payload в Avro с идентификатором схемы в wire format schema registry:
+// магический байт 0, идентификатор схемы (4 байта big-endian), Avro binary.
+// Схема регистрируется в subject при первом кодировании; при чтении схема писателя
+// загружается по идентификатору из payload и кэшируется.
+type AvroCodec struct {
+	registry SchemaRegistry
+	binding  AvroBinding
+	subject  string
+	schema   string
+	timeout  time.Duration
+	schemaID int
+	schemas  map[int]string
+	mu       sync.Mutex
+}
+
+// NewAvroCodec создает AvroCodec для схемы schema в subject (обычно "<event_type>-value")
+func NewAvroCodec(registry SchemaRegistry, binding AvroBinding, subject, schema string) *AvroCodec {
+	return &AvroCodec{
+		registry: registry,
+		binding:  binding,
+		subject:  subject,
+		schema:   schema,
+		timeout:  10 * time.Second,
+		schemas:  make(map[int]string),
+	}
+}
+
+// WithTimeout ограничивает время обращения к schema registry
+func (c *AvroCodec) WithTimeout(timeout time.Duration) *AvroCodec {
+	c.timeout = timeout
+	return c
+}
+
+// ContentType возвращает "application/avro"
+func (c *AvroCodec) ContentType() string {
+	return ContentTypeAvro
+}
+
+// Marshal кодирует событие по схеме кодека
+func (c *AvroCodec) Marshal(event Event) ([]byte, error) {
+	id, err := c.registeredSchemaID()
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.binding.Marshal(c.schema, event)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, 5, 5+len(data))
+	payload[0] = avroMagicByte
+	binary.BigEndian.PutUint32(payload[1:5], uint32(id))
+	return append(payload, data...), nil
+}
+
+// Unmarshal декодирует событие по схеме писателя из payload
+func (c *AvroCodec) Unmarshal(data []byte, event Event) error {
+	if len(data) < 5 || data[0] != avroMagicByte {
+		return fmt.Errorf("avro payload has no schema registry header")
+	}
+	schema, err := c.writerSchema(int(binary.BigEndian.Uint32(data[1:5])))
+	if err != nil {
+		return err
+	}
+	return c.binding.Unmarshal(schema, data[5:], event)
+}
+
+// registeredSchemaID регистрирует схему кодека при первом использовании
+func (c *AvroCodec) registeredSchemaID() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.schemaID != 0 {
+		return c.schemaID, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	id, err := c.registry.Register(ctx, c.subject, c.schema)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register avro schema for subject %s: %w", c.subject, err)
+	}
+	c.schemaID = id
+	c.schemas[id] = c.schema
+	return id, nil
+}
+
+// writerSchema возвращает схему по идентификатору из кэша или schema registry
+func (c *AvroCodec) writerSchema(id int) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if schema, ok := c.schemas[id]; ok {
+		return schema, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	schema, err := c.registry.Schema(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch avro schema %d: %w", id, err)
+	}
+	c.schemas[id] = schema
+	return schema, nil
+}
+
+// InMemorySchemaRegistry schema registry в памяти для тестов и локальной разработки
+type InMemorySchemaRegistry struct {
+	schemas  []string
+	subjects map[string]map[string]int
+	mu       sync.RWMutex
+}
+
+// NewInMemorySchemaRegistry создает InMemorySchemaRegistry
+func NewInMemorySchemaRegistry() *InMemorySchemaRegistry {
+	return &InMemorySchemaRegistry{subjects: make(map[string]map[string]int)}
+}
+
+// Register регистрирует схему в subject
+func (r *InMemorySchemaRegistry) Register(ctx context.Context, subject, schema string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.subjects[subject] == nil {
+		r.subjects[subject] = make(map[string]int)
+	}
+	if id, ok := r.subjects[subject][schema]; ok {
+		return id, nil
+	}
+	r.schemas = append(r.schemas, schema)
+	id := len(r.schemas)
+	r.subjects[subject][schema] = id
+	return id, nil
+}
+
+// Schema возвращает схему по идентификатору
+func (r *InMemorySchemaRegistry) Schema(ctx context.Context, id int) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if id < 1 || id > len(r.schemas) {
+		return "", fmt.Errorf("schema %d not found", id)
+	}
+	return r.schemas[id-1], nil
+}
+
+// HTTPSchemaRegistry клиент REST API Confluent-совместимого schema registry
+// (Confluent Schema Registry, Redpanda, Apicurio в режиме совместимости)
+type HTTPSchemaRegistry struct {
+	baseURL  string
+	client   *http.Client
+	username string
+	password string
+}
+
+// NewHTTPSchemaRegistry создает клиент schema registry по базовому URL (http://localhost:8081)
+func NewHTTPSchemaRegistry(baseURL string) *HTTPSchemaRegistry {
+	return &HTTPSchemaRegistry{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  http.DefaultClient,
+	}
+}
+
+// WithHTTPClient задает HTTP клиент
+func (r *HTTPSchemaRegistry) WithHTTPClient(client *http.Client) *HTTPSchemaRegistry {
+	r.client = client
+	return r
+}
+
+// WithBasicAuth задает учетные данные basic auth
+func (r *HTTPSchemaRegistry) WithBasicAuth(username, password string) *HTTPSchemaRegistry {
+	r.username = username
+	r.password = password
+	return r
+}
+
+// Register регистрирует схему: POST /subjects/{subject}/versions
+func (r *HTTPSchemaRegistry) Register(ctx context.Context, subject, schema string) (int, error) {
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+	var response struct {
+		ID int `json:"id"`
+	}
+	path := "/subjects/" + url.PathEscape(subject) + "/versions"
+	if err := r.do(ctx, http.MethodPost, path, body, &response); err != nil {
+		return 0, err
+	}
+	return response.ID, nil
+}
+
+// Schema возвращает схему: GET /schemas/ids/{id}
+func (r *HTTPSchemaRegistry) Schema(ctx context.Context, id int) (string, error) {
+	var response struct {
+		Schema string `json:"schema"`
+	}
+	if err := r.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &response); err != nil {
+		return "", err
+	}
+	return response.Schema, nil
+}
+
+// do выполняет запрос к schema registry
+func (r *HTTPSchemaRegistry) do(ctx context.Context, method, path string, body []byte, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("schema registry %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/akriventsev/potter/framework/core"
 )
 
 // InMemoryEventBus реализация шины событий
@@ -24,7 +26,8 @@ type InMemoryEventBus struct {
 // EventMiddleware middleware для событий
 type EventMiddleware func(ctx context.Context, event Event, next func(ctx context.Context, event Event) error) error
 
-// DeadLetterQueue интерфейс для dead letter queue
+// DeadLetterQueue интерфейс для dead letter queue.
+// Если ошибка вызвана паникой обработчика, reason содержит stack trace после сообщения об ошибке.
 type DeadLetterQueue interface {
 	Publish(ctx context.Context, event Event, reason string) error
 }
@@ -37,6 +40,7 @@ func NewInMemoryEventBus() *InMemoryEventBus {
 	// Связываем publisher и subscriber через общий subscribers map
 	// Используем subscribers из subscriber для publisher
 	publisher.subscribers = subscriber.handlers
+	publisher.groups = subscriber
 
 	return &InMemoryEventBus{
 		publisher:  publisher,
@@ -91,17 +95,31 @@ func (b *InMemoryEventBus) Publish(ctx context.Context, event Event) error {
 
 	err := next(ctx, event)
 	if err != nil && b.dlq != nil {
-		_ = b.dlq.Publish(ctx, event, err.Error())
+		_ = b.dlq.Publish(ctx, event, deadLetterReason(err))
 	}
 
 	return err
 }
 
+// deadLetterReason формирует причину для DLQ: сообщение ошибки и stack trace паники, если она была
+func deadLetterReason(err error) string {
+	if stack := core.StackTraceOf(err); stack != "" {
+		return err.Error() + "\n" + stack
+	}
+	return err.Error()
+}
+
 // Subscribe подписывается на тип события
 func (b *InMemoryEventBus) Subscribe(eventType string, handler EventHandler) error {
 	return b.subscriber.Subscribe(eventType, handler)
 }
 
+// SubscribeWithGroup подписывается на тип события в группе потребителей:
+// каждое событие получает только один обработчик группы
+func (b *InMemoryEventBus) SubscribeWithGroup(eventType string, handler EventHandler, group string) error {
+	return b.subscriber.SubscribeWithGroup(eventType, handler, group)
+}
+
 // Unsubscribe отписывается от типа события
 func (b *InMemoryEventBus) Unsubscribe(eventType string, handler EventHandler) error {
 	return b.subscriber.Unsubscribe(eventType, handler)
@@ -146,3 +164,10 @@ func (b *InMemoryEventBus) Shutdown(ctx context.Context) error {
 	}
 }
 
+
+// Stats возвращает число подписчиков по типам событий
+func (b *InMemoryEventBus) Stats() EventBusStats {
+	b.subscriber.mu.RLock()
+	defer b.subscriber.mu.RUnlock()
+	return EventBusStats{Subscribers: countHandlers(b.subscriber.handlers)}
+}
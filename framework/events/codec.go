@@ -0,0 +1,276 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// Content types payload событий
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeAvro     = "application/avro"
+	ContentTypeMsgpack  = "application/msgpack"
+)
+
+// ContentTypeMetadataKey ключ метаданных события и заголовка сообщения с content type payload
+const ContentTypeMetadataKey = "content_type"
+
+var (
+	// ErrUnknownEventType возникает при десериализации типа события, не зарегистрированного в SerializerRegistry
+	ErrUnknownEventType = errors.New("unknown event type")
+	// ErrUnknownContentType возникает при десериализации payload с незарегистрированным content type
+	ErrUnknownContentType = errors.New("unknown content type")
+)
+
+// PayloadCodec кодирует payload события в формат content type
+type PayloadCodec interface {
+	// ContentType возвращает content type payload (сохраняется вместе с событием)
+	ContentType() string
+	// Marshal кодирует событие
+	Marshal(event Event) ([]byte, error)
+	// Unmarshal декодирует payload в событие (указатель на конкретный тип)
+	Unmarshal(data []byte, event Event) error
+}
+
+// JSONCodec кодирует payload в JSON (формат по умолчанию)
+type JSONCodec struct{}
+
+// NewJSONCodec создает JSONCodec
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{}
+}
+
+// ContentType возвращает "application/json"
+func (c *JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+// Marshal кодирует событие в JSON
+func (c *JSONCodec) Marshal(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Unmarshal декодирует JSON в событие
+func (c *JSONCodec) Unmarshal(data []byte, event Event) error {
+	return json.Unmarshal(data, event)
+}
+
+// ProtoEvent доменное событие с protobuf представлением: сообщение, сгенерированное
+// protoc-gen-go из proto спецификации сервиса (той же, по которой protoc-gen-potter
+// генерирует доменные события). События, которые сами реализуют proto.Message,
+// кодируются напрямую.
+type ProtoEvent interface {
+	// ToProto возвращает protobuf сообщение с данными события
+	ToProto() proto.Message
+	// FromProto заполняет событие из protobuf сообщения
+	FromProto(message proto.Message) error
+}
+
+// ProtobufCodec кодирует payload в protobuf
+type ProtobufCodec struct{}
+
+// NewProtobufCodec создает ProtobufCodec
+func NewProtobufCodec() *ProtobufCodec {
+	return &ProtobufCodec{}
+}
+
+// ContentType возвращает "application/x-protobuf"
+func (c *ProtobufCodec) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+// Marshal кодирует событие в protobuf
+func (c *ProtobufCodec) Marshal(event Event) ([]byte, error) {
+	switch e := event.(type) {
+	case proto.Message:
+		return proto.Marshal(e)
+	case ProtoEvent:
+		return proto.Marshal(e.ToProto())
+	default:
+		return nil, fmt.Errorf("event %T implements neither proto.Message nor ProtoEvent", event)
+	}
+}
+
+// Unmarshal декодирует protobuf в событие
+func (c *ProtobufCodec) Unmarshal(data []byte, event Event) error {
+	switch e := event.(type) {
+	case proto.Message:
+		return proto.Unmarshal(data, e)
+	case ProtoEvent:
+		message := e.ToProto().ProtoReflect().New().Interface()
+		if err := proto.Unmarshal(data, message); err != nil {
+			return err
+		}
+		return e.FromProto(message)
+	default:
+		return fmt.Errorf("event %T implements neither proto.Message nor ProtoEvent", event)
+	}
+}
+
+// MsgpackCodec кодирует payload в MessagePack. Имена полей берутся из тегов codec и json,
+// поэтому события кодируются с теми же именами полей, что и в JSON.
+type MsgpackCodec struct {
+	handle *codec.MsgpackHandle
+}
+
+// NewMsgpackCodec создает MsgpackCodec
+func NewMsgpackCodec() *MsgpackCodec {
+	handle := &codec.MsgpackHandle{WriteExt: true}
+	handle.RawToString = true
+	return &MsgpackCodec{handle: handle}
+}
+
+// ContentType возвращает "application/msgpack"
+func (c *MsgpackCodec) ContentType() string {
+	return ContentTypeMsgpack
+}
+
+// Marshal кодирует событие в MessagePack
+func (c *MsgpackCodec) Marshal(event Event) ([]byte, error) {
+	var data []byte
+	if err := codec.NewEncoderBytes(&data, c.handle).Encode(event); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Unmarshal декодирует MessagePack в событие
+func (c *MsgpackCodec) Unmarshal(data []byte, event Event) error {
+	return codec.NewDecoderBytes(data, c.handle).Decode(event)
+}
+
+// eventSerialization регистрация типа события
+type eventSerialization struct {
+	factory func() Event
+	codec   PayloadCodec
+}
+
+// SerializerRegistry выбирает кодек payload по типу события. Новые события кодируются
+// кодеком своего типа, а чтение выбирает кодек по сохраненному content type, поэтому события,
+// записанные до смены кодека типа, продолжают читаться (cross-codec reads).
+// Используется event store (eventsourcing.NewCodecEventStore, eventsourcing.NewCodecDeserializer)
+// и адаптерами публикации событий (поле Codecs конфигурации).
+type SerializerRegistry struct {
+	defaultCodec PayloadCodec
+	codecs       map[string]PayloadCodec
+	types        map[string]eventSerialization
+	aliases      *EventTypeAliases
+	mu           sync.RWMutex
+}
+
+// NewSerializerRegistry создает реестр с JSON кодеком по умолчанию.
+// Для чтения зарегистрированы JSON, protobuf и MessagePack; Avro кодеки добавляются
+// через Register или RegisterCodec, так как требуют schema registry.
+func NewSerializerRegistry() *SerializerRegistry {
+	r := &SerializerRegistry{
+		defaultCodec: NewJSONCodec(),
+		codecs:       make(map[string]PayloadCodec),
+		types:        make(map[string]eventSerialization),
+	}
+	r.RegisterCodec(r.defaultCodec)
+	r.RegisterCodec(NewProtobufCodec())
+	r.RegisterCodec(NewMsgpackCodec())
+	return r
+}
+
+// WithDefaultCodec задает кодек для типов событий, зарегистрированных без кодека
+func (r *SerializerRegistry) WithDefaultCodec(codec PayloadCodec) *SerializerRegistry {
+	r.RegisterCodec(codec)
+	r.mu.Lock()
+	r.defaultCodec = codec
+	r.mu.Unlock()
+	return r
+}
+
+// WithAliases задает переименования типов событий: payload старых типов декодируется
+// фабрикой и кодеком актуального типа
+func (r *SerializerRegistry) WithAliases(aliases *EventTypeAliases) *SerializerRegistry {
+	r.mu.Lock()
+	r.aliases = aliases
+	r.mu.Unlock()
+	return r
+}
+
+// RegisterCodec добавляет кодек для чтения payload с его content type
+func (r *SerializerRegistry) RegisterCodec(codec PayloadCodec) *SerializerRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs[codec.ContentType()] = codec
+	return r
+}
+
+// Register регистрирует тип события: factory создает пустое событие для декодирования,
+// codec кодирует новые события типа (nil - кодек по умолчанию)
+func (r *SerializerRegistry) Register(eventType string, factory func() Event, codec PayloadCodec) *SerializerRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if codec != nil {
+		if _, exists := r.codecs[codec.ContentType()]; !exists {
+			r.codecs[codec.ContentType()] = codec
+		}
+	}
+	r.types[eventType] = eventSerialization{factory: factory, codec: codec}
+	return r
+}
+
+// Codec возвращает кодек новых событий типа eventType
+func (r *SerializerRegistry) Codec(eventType string) PayloadCodec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if registration, ok := r.types[eventType]; ok && registration.codec != nil {
+		return registration.codec
+	}
+	return r.defaultCodec
+}
+
+// ContentType возвращает content type новых событий типа eventType
+func (r *SerializerRegistry) ContentType(eventType string) string {
+	return r.Codec(eventType).ContentType()
+}
+
+// Serialize кодирует payload события кодеком его типа и возвращает content type
+func (r *SerializerRegistry) Serialize(event Event) ([]byte, string, error) {
+	codec := r.Codec(event.EventType())
+	data, err := codec.Marshal(event)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode event %s as %s: %w", event.EventType(), codec.ContentType(), err)
+	}
+	return data, codec.ContentType(), nil
+}
+
+// Deserialize декодирует payload с content type в событие типа eventType.
+// Пустой content type - JSON (события, записанные до появления реестра).
+// Переименованные типы (WithAliases) декодируются как актуальный тип.
+func (r *SerializerRegistry) Deserialize(eventType, contentType string, data []byte) (Event, error) {
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	r.mu.RLock()
+	eventType = r.aliases.Resolve(eventType)
+	registration, registered := r.types[eventType]
+	codec := registration.codec
+	if codec == nil || codec.ContentType() != contentType {
+		codec = r.codecs[contentType]
+	}
+	r.mu.RUnlock()
+
+	if !registered {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	if codec == nil {
+		return nil, fmt.Errorf("%w: %s (event %s)", ErrUnknownContentType, contentType, eventType)
+	}
+
+	event := registration.factory()
+	if err := codec.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("failed to decode event %s from %s: %w", eventType, contentType, err)
+	}
+	return event, nil
+}
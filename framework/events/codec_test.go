@@ -0,0 +1,204 @@
+package events
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// orderPlaced событие для тестов кодеков
+type orderPlaced struct {
+	*BaseEvent
+	OrderID string `json:"order_id"`
+	Amount  int    `json:"amount"`
+}
+
+func newOrderPlaced(orderID string, amount int) *orderPlaced {
+	return &orderPlaced{BaseEvent: NewBaseEvent("order.placed", orderID), OrderID: orderID, Amount: amount}
+}
+
+func orderPlacedFactory() Event {
+	return &orderPlaced{BaseEvent: &BaseEvent{}}
+}
+
+// ToProto возвращает идентификатор заказа в protobuf сообщении
+func (e *orderPlaced) ToProto() proto.Message {
+	return wrapperspb.String(e.OrderID)
+}
+
+// FromProto заполняет событие из protobuf сообщения
+func (e *orderPlaced) FromProto(message proto.Message) error {
+	e.OrderID = message.(*wrapperspb.StringValue).GetValue()
+	return nil
+}
+
+// jsonAvroBinding тестовая Avro привязка, кодирующая значения в JSON
+type jsonAvroBinding struct {
+	schemas []string
+}
+
+func (b *jsonAvroBinding) Marshal(schema string, value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (b *jsonAvroBinding) Unmarshal(writerSchema string, data []byte, value interface{}) error {
+	b.schemas = append(b.schemas, writerSchema)
+	return json.Unmarshal(data, value)
+}
+
+func TestSerializerRegistry_RoundTrip(t *testing.T) {
+	codecs := []PayloadCodec{NewJSONCodec(), NewMsgpackCodec(), NewProtobufCodec()}
+	for _, codec := range codecs {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			registry := NewSerializerRegistry().Register("order.placed", orderPlacedFactory, codec)
+
+			data, contentType, err := registry.Serialize(newOrderPlaced("order-1", 42))
+			if err != nil {
+				t.Fatalf("Serialize failed: %v", err)
+			}
+			if contentType != codec.ContentType() {
+				t.Errorf("Expected content type %s, got %s", codec.ContentType(), contentType)
+			}
+
+			event, err := registry.Deserialize("order.placed", contentType, data)
+			if err != nil {
+				t.Fatalf("Deserialize failed: %v", err)
+			}
+			order := event.(*orderPlaced)
+			if order.OrderID != "order-1" {
+				t.Errorf("Expected order_id order-1, got %s", order.OrderID)
+			}
+			if codec.ContentType() != ContentTypeProtobuf && order.Amount != 42 {
+				t.Errorf("Expected amount 42, got %d", order.Amount)
+			}
+		})
+	}
+}
+
+func TestSerializerRegistry_CrossCodecRead(t *testing.T) {
+	legacy := NewSerializerRegistry().Register("order.placed", orderPlacedFactory, nil)
+	jsonData, jsonType, err := legacy.Serialize(newOrderPlaced("order-1", 1))
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	registry := NewSerializerRegistry().Register("order.placed", orderPlacedFactory, NewMsgpackCodec())
+	msgpackData, msgpackType, err := registry.Serialize(newOrderPlaced("order-2", 2))
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if msgpackType != ContentTypeMsgpack {
+		t.Fatalf("Expected content type %s, got %s", ContentTypeMsgpack, msgpackType)
+	}
+
+	// События, записанные до смены кодека, читаются по сохраненному content type
+	for _, tc := range []struct {
+		contentType string
+		data        []byte
+		orderID     string
+	}{
+		{jsonType, jsonData, "order-1"},
+		{"", jsonData, "order-1"},
+		{msgpackType, msgpackData, "order-2"},
+	} {
+		event, err := registry.Deserialize("order.placed", tc.contentType, tc.data)
+		if err != nil {
+			t.Fatalf("Deserialize %q failed: %v", tc.contentType, err)
+		}
+		if got := event.(*orderPlaced).OrderID; got != tc.orderID {
+			t.Errorf("Expected order_id %s, got %s", tc.orderID, got)
+		}
+	}
+}
+
+func TestSerializerRegistry_Errors(t *testing.T) {
+	registry := NewSerializerRegistry().Register("order.placed", orderPlacedFactory, nil)
+
+	if _, err := registry.Deserialize("order.cancelled", ContentTypeJSON, []byte("{}")); !errors.Is(err, ErrUnknownEventType) {
+		t.Errorf("Expected ErrUnknownEventType, got %v", err)
+	}
+	if _, err := registry.Deserialize("order.placed", "application/xml", []byte("<order/>")); !errors.Is(err, ErrUnknownContentType) {
+		t.Errorf("Expected ErrUnknownContentType, got %v", err)
+	}
+}
+
+func TestAvroCodec_WireFormat(t *testing.T) {
+	schemas := NewInMemorySchemaRegistry()
+	if _, err := schemas.Register(context.Background(), "other-value", `"string"`); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	schema := `{"type":"record","name":"OrderPlaced","fields":[{"name":"order_id","type":"string"}]}`
+	binding := &jsonAvroBinding{}
+	writer := NewAvroCodec(schemas, binding, "order.placed-value", schema)
+	registry := NewSerializerRegistry().Register("order.placed", orderPlacedFactory, writer)
+
+	data, contentType, err := registry.Serialize(newOrderPlaced("order-1", 42))
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	if contentType != ContentTypeAvro {
+		t.Errorf("Expected content type %s, got %s", ContentTypeAvro, contentType)
+	}
+	if data[0] != avroMagicByte {
+		t.Errorf("Expected magic byte 0, got %d", data[0])
+	}
+	if id := binary.BigEndian.Uint32(data[1:5]); id != 2 {
+		t.Errorf("Expected schema id 2, got %d", id)
+	}
+
+	// Читатель без кэша загружает схему писателя из schema registry
+	reader := NewAvroCodec(schemas, binding, "order.placed-value", schema)
+	event := orderPlacedFactory()
+	if err := reader.Unmarshal(data, event); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if event.(*orderPlaced).OrderID != "order-1" {
+		t.Errorf("Expected order_id order-1, got %s", event.(*orderPlaced).OrderID)
+	}
+	if len(binding.schemas) != 1 || binding.schemas[0] != schema {
+		t.Errorf("Expected writer schema %s, got %v", schema, binding.schemas)
+	}
+
+	if err := reader.Unmarshal([]byte("{}"), orderPlacedFactory()); err == nil {
+		t.Error("Expected error for payload without schema registry header")
+	}
+}
+
+func TestEventTypeAliases(t *testing.T) {
+	aliases := NewEventTypeAliases().
+		MustRegister("OrderCreated", "order.created").
+		MustRegister("order.created", "order.placed")
+
+	if got := aliases.Resolve("OrderCreated"); got != "order.placed" {
+		t.Errorf("Expected chain resolved to order.placed, got %s", got)
+	}
+	if got := aliases.Resolve("order.cancelled"); got != "order.cancelled" {
+		t.Errorf("Expected unknown type unchanged, got %s", got)
+	}
+	if names := aliases.OldNames("order.placed"); len(names) != 2 || names[0] != "OrderCreated" || names[1] != "order.created" {
+		t.Errorf("Expected old names [OrderCreated order.created], got %v", names)
+	}
+	if err := aliases.Register("order.placed", "OrderCreated"); err == nil {
+		t.Error("Expected error for alias cycle")
+	}
+	if err := aliases.Register("OrderCreated", "order.cancelled"); err == nil {
+		t.Error("Expected error for conflicting alias")
+	}
+
+	registry := NewSerializerRegistry().
+		Register("order.placed", orderPlacedFactory, nil).
+		WithAliases(aliases)
+	event, err := registry.Deserialize("OrderCreated", ContentTypeJSON, []byte(`{"order_id":"order-1","amount":5}`))
+	if err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if event.(*orderPlaced).OrderID != "order-1" {
+		t.Errorf("Expected order_id order-1, got %s", event.(*orderPlaced).OrderID)
+	}
+}
@@ -160,6 +160,15 @@ type EventSubscriber interface {
 	Unsubscribe(eventType string, handler EventHandler) error
 }
 
+// GroupSubscriber опциональный интерфейс подписчиков с группами потребителей
+// (competing consumers): каждое событие получает только один обработчик группы.
+// Экземпляры горизонтально масштабированного сервиса подписываются с одной группой,
+// чтобы не обрабатывать событие повторно.
+type GroupSubscriber interface {
+	// SubscribeWithGroup подписывает обработчик на тип события в группе потребителей
+	SubscribeWithGroup(eventType string, handler EventHandler, group string) error
+}
+
 // EventBus объединяет Publisher и Subscriber
 type EventBus interface {
 	EventPublisher
@@ -0,0 +1,177 @@
+package events
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Плейсхолдеры шаблона subject/topic
+const (
+	// SubjectPlaceholderEnv окружение (prod, staging)
+	SubjectPlaceholderEnv = "{env}"
+	// SubjectPlaceholderContext bounded context сервиса
+	SubjectPlaceholderContext = "{context}"
+	// SubjectPlaceholderAggregate тип агрегата
+	SubjectPlaceholderAggregate = "{aggregate}"
+	// SubjectPlaceholderEvent тип события
+	SubjectPlaceholderEvent = "{event}"
+	// SubjectPlaceholderVersion версия схемы события
+	SubjectPlaceholderVersion = "{version}"
+)
+
+// DefaultSubjectTemplate шаблон subject по умолчанию: events.{aggregate}.{event}
+const DefaultSubjectTemplate = "events.{aggregate}.{event}"
+
+// UnknownAggregateType тип агрегата, если его не удалось определить
+const UnknownAggregateType = "unknown"
+
+// SubjectNaming стратегия именования subjects/topics событий по шаблону,
+// например {env}.{context}.{aggregate}.{event}.v{version}.
+// Пустые сегменты (например, не заданное окружение) исключаются из subject.
+type SubjectNaming struct {
+	template          string
+	env               string
+	context           string
+	aggregateResolver func(event Event) string
+}
+
+// NewSubjectNaming создает стратегию именования по шаблону
+func NewSubjectNaming(template string) *SubjectNaming {
+	return &SubjectNaming{
+		template:          template,
+		aggregateResolver: AggregateTypeOf,
+	}
+}
+
+// DefaultSubjectNaming создает стратегию именования с шаблоном DefaultSubjectTemplate
+func DefaultSubjectNaming() *SubjectNaming {
+	return NewSubjectNaming(DefaultSubjectTemplate)
+}
+
+// PrefixSubjectNaming создает стратегию именования {prefix}.{aggregate}.{event}
+func PrefixSubjectNaming(prefix string) *SubjectNaming {
+	return NewSubjectNaming(prefix + "." + SubjectPlaceholderAggregate + "." + SubjectPlaceholderEvent)
+}
+
+// WithEnv устанавливает значение плейсхолдера {env}
+func (n *SubjectNaming) WithEnv(env string) *SubjectNaming {
+	n.env = env
+	return n
+}
+
+// WithContext устанавливает значение плейсхолдера {context}
+func (n *SubjectNaming) WithContext(context string) *SubjectNaming {
+	n.context = context
+	return n
+}
+
+// WithAggregateResolver устанавливает функцию определения типа агрегата события
+func (n *SubjectNaming) WithAggregateResolver(resolver func(event Event) string) *SubjectNaming {
+	n.aggregateResolver = resolver
+	return n
+}
+
+// Template возвращает шаблон subject
+func (n *SubjectNaming) Template() string {
+	return n.template
+}
+
+// Subject формирует subject для события
+func (n *SubjectNaming) Subject(event Event) string {
+	aggregate := UnknownAggregateType
+	if n.aggregateResolver != nil {
+		aggregate = n.aggregateResolver(event)
+	}
+	return n.Format(aggregate, event.EventType(), SchemaVersionOf(event))
+}
+
+// Format формирует subject по типу агрегата, типу события и версии схемы
+func (n *SubjectNaming) Format(aggregate, eventType string, version int) string {
+	if version <= 0 {
+		version = 1
+	}
+	subject := strings.NewReplacer(
+		SubjectPlaceholderEnv, n.env,
+		SubjectPlaceholderContext, n.context,
+		SubjectPlaceholderAggregate, aggregate,
+		SubjectPlaceholderEvent, eventType,
+		SubjectPlaceholderVersion, strconv.Itoa(version),
+	).Replace(n.template)
+
+	segments := strings.Split(subject, ".")
+	result := segments[:0]
+	for _, segment := range segments {
+		if segment != "" {
+			result = append(result, segment)
+		}
+	}
+	return strings.Join(result, ".")
+}
+
+// Pattern возвращает wildcard subject для подписки на тип события любых агрегатов
+// и версий схемы (events.*.order_created): сегменты с {aggregate} и {version}
+// заменяются на "*"
+func (n *SubjectNaming) Pattern(eventType string) string {
+	subject := strings.NewReplacer(
+		SubjectPlaceholderEnv, n.env,
+		SubjectPlaceholderContext, n.context,
+		SubjectPlaceholderEvent, eventType,
+	).Replace(n.template)
+
+	segments := strings.Split(subject, ".")
+	result := segments[:0]
+	for _, segment := range segments {
+		switch {
+		case segment == "":
+		case strings.Contains(segment, SubjectPlaceholderAggregate), strings.Contains(segment, SubjectPlaceholderVersion):
+			result = append(result, "*")
+		default:
+			result = append(result, segment)
+		}
+	}
+	return strings.Join(result, ".")
+}
+
+// AggregateTypeOf определяет тип агрегата события: из метаданных aggregate_type,
+// иначе по первой части aggregate ID (order-123 -> order)
+func AggregateTypeOf(event Event) string {
+	if metadata := event.Metadata(); metadata != nil {
+		if aggType, ok := metadata.Get("aggregate_type"); ok {
+			if str, ok := aggType.(string); ok && str != "" {
+				return str
+			}
+		}
+	}
+
+	aggregateID := event.AggregateID()
+	if aggregateID == "" {
+		return UnknownAggregateType
+	}
+	for _, sep := range []string{"-", "_"} {
+		if parts := strings.Split(aggregateID, sep); len(parts) > 1 && parts[0] != "" {
+			return parts[0]
+		}
+	}
+	return aggregateID
+}
+
+// SchemaVersionOf возвращает версию схемы события: из метода SchemaVersion(),
+// иначе из метаданных schema_version, по умолчанию 1
+func SchemaVersionOf(event Event) int {
+	if versioned, ok := event.(interface{ SchemaVersion() int }); ok {
+		return versioned.SchemaVersion()
+	}
+	if metadata := event.Metadata(); metadata != nil {
+		if val, ok := metadata.Get("schema_version"); ok {
+			switch v := val.(type) {
+			case int:
+				return v
+			case int64:
+				return int(v)
+			case float64:
+				return int(v)
+			}
+		}
+	}
+	return 1
+}
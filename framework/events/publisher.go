@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/akriventsev/potter/framework/core"
 )
 
 // RetryConfig конфигурация retry для публикатора
@@ -32,6 +34,8 @@ type InMemoryEventPublisher struct {
 	mu          sync.RWMutex
 	ordered     bool
 	retryConfig *RetryConfig
+	// groups выбирает обработчики групп потребителей (задается InMemoryEventBus)
+	groups *InMemoryEventSubscriber
 }
 
 // NewInMemoryEventPublisher создает новый in-memory публикатор
@@ -57,7 +61,7 @@ func (p *InMemoryEventPublisher) WithRetry(config RetryConfig) *InMemoryEventPub
 // retryPublish выполняет публикацию с retry
 func (p *InMemoryEventPublisher) retryPublish(ctx context.Context, event Event, handler EventHandler) error {
 	if p.retryConfig == nil {
-		return handler.Handle(ctx, event)
+		return handleEvent(ctx, handler, event)
 	}
 
 	var lastErr error
@@ -72,7 +76,7 @@ func (p *InMemoryEventPublisher) retryPublish(ctx context.Context, event Event,
 			}
 		}
 
-		err := handler.Handle(ctx, event)
+		err := handleEvent(ctx, handler, event)
 		if err == nil {
 			return nil
 		}
@@ -92,8 +96,12 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event Event) error
 	p.mu.RLock()
 	handlers := p.subscribers[event.EventType()]
 	ordered := p.ordered
+	groups := p.groups
 	p.mu.RUnlock()
 
+	if groups != nil {
+		handlers = groups.selectGroupHandlers(event.EventType(), handlers)
+	}
 	if len(handlers) == 0 {
 		return nil
 	}
@@ -106,7 +114,7 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event Event) error
 			if p.retryConfig != nil {
 				err = p.retryPublish(ctx, event, handler)
 			} else {
-				err = handler.Handle(ctx, event)
+				err = handleEvent(ctx, handler, event)
 			}
 			if err != nil {
 				errors = append(errors, fmt.Errorf("handler %s failed: %w", handler.EventType(), err))
@@ -114,7 +122,7 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event Event) error
 		}
 
 		if len(errors) > 0 {
-			return fmt.Errorf("publish failed: %v", errors)
+			return &publishError{errs: errors}
 		}
 		return nil
 	}
@@ -131,7 +139,7 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event Event) error
 			if p.retryConfig != nil {
 				err = p.retryPublish(ctx, event, h)
 			} else {
-				err = h.Handle(ctx, event)
+				err = handleEvent(ctx, h, event)
 			}
 			if err != nil {
 				errCh <- fmt.Errorf("handler %s failed: %w", h.EventType(), err)
@@ -149,12 +157,32 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event Event) error
 	}
 
 	if len(errors) > 0 {
-		return fmt.Errorf("publish failed: %v", errors)
+		return &publishError{errs: errors}
 	}
 
 	return nil
 }
 
+// handleEvent вызывает обработчик, преобразуя панику в ошибку с кодом core.ErrPanicRecovered,
+// чтобы паника в одном обработчике не завершала процесс и не прерывала доставку остальным
+func handleEvent(ctx context.Context, handler EventHandler, event Event) (err error) {
+	defer core.Recover(&err)
+	return handler.Handle(ctx, event)
+}
+
+// publishError ошибки обработчиков события; сохраняет цепочки ошибок для errors.Is/As
+type publishError struct {
+	errs []error
+}
+
+func (e *publishError) Error() string {
+	return fmt.Sprintf("publish failed: %v", e.errs)
+}
+
+func (e *publishError) Unwrap() []error {
+	return e.errs
+}
+
 // Subscribe подписывается на события (для совместимости с EventBus)
 // ВАЖНО: InMemoryEventPublisher должен использовать общий subscribers с EventSubscriber
 func (p *InMemoryEventPublisher) Subscribe(eventType string, handler EventHandler) error {
@@ -175,6 +203,7 @@ type AsyncEventPublisher struct {
 	stopCh   chan struct{}
 	wg       sync.WaitGroup
 	stopOnce sync.Once
+	inflight core.InFlight
 }
 
 type eventMessage struct {
@@ -208,13 +237,13 @@ func (p *AsyncEventPublisher) worker() {
 			if !ok {
 				return
 			}
-			_ = p.InMemoryEventPublisher.Publish(msg.ctx, msg.event)
+			p.deliver(msg)
 		case <-p.stopCh:
 			// Drain queue before stopping
 			for {
 				select {
 				case msg := <-p.queue:
-					_ = p.InMemoryEventPublisher.Publish(msg.ctx, msg.event)
+					p.deliver(msg)
 				default:
 					return
 				}
@@ -223,6 +252,12 @@ func (p *AsyncEventPublisher) worker() {
 	}
 }
 
+// deliver публикует событие из очереди и отмечает его обработанным для Flush
+func (p *AsyncEventPublisher) deliver(msg eventMessage) {
+	defer p.inflight.End()
+	_ = p.InMemoryEventPublisher.Publish(msg.ctx, msg.event)
+}
+
 // WithRetry настраивает retry логику для асинхронного публикатора
 func (p *AsyncEventPublisher) WithRetry(config RetryConfig) *AsyncEventPublisher {
 	p.InMemoryEventPublisher.WithRetry(config)
@@ -231,23 +266,38 @@ func (p *AsyncEventPublisher) WithRetry(config RetryConfig) *AsyncEventPublisher
 
 // Publish публикует событие асинхронно
 func (p *AsyncEventPublisher) Publish(ctx context.Context, event Event) error {
+	if !p.inflight.Begin() {
+		return fmt.Errorf("publisher is stopped")
+	}
 	select {
 	case p.queue <- eventMessage{ctx: ctx, event: event}:
 		return nil
 	case <-ctx.Done():
+		p.inflight.End()
 		return ctx.Err()
 	case <-p.stopCh:
+		p.inflight.End()
 		return fmt.Errorf("publisher is stopped")
 	}
 }
 
-// Stop останавливает публикатор с graceful shutdown
+// Flush дожидается публикации всех событий, принятых в очередь (реализация core.Flushable)
+func (p *AsyncEventPublisher) Flush(ctx context.Context) error {
+	return p.inflight.Wait(ctx)
+}
+
+// Stop останавливает публикатор с graceful shutdown: новые события не принимаются,
+// события из очереди публикуются до возврата.
 // Метод идемпотентен: повторные вызовы не приведут к panic
 func (p *AsyncEventPublisher) Stop(ctx context.Context) error {
 	var err error
 	p.stopOnce.Do(func() {
+		// Сначала публикуем все принятые события работающими воркерами: иначе событие,
+		// поставленное в очередь одновременно с остановкой, могло бы остаться в ней
+		p.inflight.Close()
+		flushErr := p.inflight.Wait(ctx)
 		close(p.stopCh)
-		
+
 		// Ждем завершения всех воркеров
 		done := make(chan struct{})
 		go func() {
@@ -257,7 +307,7 @@ func (p *AsyncEventPublisher) Stop(ctx context.Context) error {
 
 		select {
 		case <-done:
-			err = nil
+			err = flushErr
 		case <-ctx.Done():
 			err = ctx.Err()
 		}
@@ -347,3 +397,57 @@ func (p *BatchEventPublisher) WithRetry(config RetryConfig) *BatchEventPublisher
 	return p
 }
 
+
+// EventBusStats снимок состояния шины или публикатора событий для диагностики
+type EventBusStats struct {
+	// Subscribers число обработчиков по типам событий
+	Subscribers map[string]int `json:"subscribers"`
+	// Workers число воркеров асинхронной доставки (0 - синхронная доставка)
+	Workers int `json:"workers,omitempty"`
+	// QueueLength число событий, ожидающих доставки
+	QueueLength int `json:"queue_length"`
+	// QueueCapacity емкость очереди (0 - очереди нет)
+	QueueCapacity int `json:"queue_capacity,omitempty"`
+}
+
+// EventBusStatsProvider опциональный интерфейс шин и публикаторов, предоставляющих статистику
+type EventBusStatsProvider interface {
+	Stats() EventBusStats
+}
+
+// countHandlers возвращает число обработчиков по типам событий
+func countHandlers(handlers map[string][]EventHandler) map[string]int {
+	counts := make(map[string]int, len(handlers))
+	for eventType, list := range handlers {
+		if len(list) > 0 {
+			counts[eventType] = len(list)
+		}
+	}
+	return counts
+}
+
+// Stats возвращает число подписчиков по типам событий
+func (p *InMemoryEventPublisher) Stats() EventBusStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return EventBusStats{Subscribers: countHandlers(p.subscribers)}
+}
+
+// Stats возвращает число подписчиков, воркеров и заполненность очереди
+func (p *AsyncEventPublisher) Stats() EventBusStats {
+	stats := p.InMemoryEventPublisher.Stats()
+	stats.Workers = p.workers
+	stats.QueueLength = len(p.queue)
+	stats.QueueCapacity = cap(p.queue)
+	return stats
+}
+
+// Stats возвращает число подписчиков и событий в текущем пакете
+func (p *BatchEventPublisher) Stats() EventBusStats {
+	stats := p.InMemoryEventPublisher.Stats()
+	p.mu.Lock()
+	stats.QueueLength = len(p.batch)
+	p.mu.Unlock()
+	stats.QueueCapacity = p.batchSize
+	return stats
+}
@@ -3,10 +3,12 @@ package events
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/akriventsev/potter/framework/core"
 	"github.com/google/uuid"
 )
 
@@ -198,6 +200,39 @@ func TestAsyncEventPublisher_Stop(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 }
 
+func TestAsyncEventPublisher_FlushAndStop(t *testing.T) {
+	publisher := NewAsyncEventPublisher(2, 10)
+	handler := &MockEventHandler{delay: 10 * time.Millisecond}
+
+	if err := publisher.Subscribe("test_event", handler); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := publisher.Publish(ctx, newMockEvent("test_event", "agg-1")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// Flush дожидается публикации всех принятых событий
+	flushCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	if err := publisher.Flush(flushCtx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if handler.HandledCount() != 5 {
+		t.Errorf("Expected 5 handled events after Flush, got %d", handler.HandledCount())
+	}
+
+	if err := publisher.Stop(flushCtx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := publisher.Publish(ctx, newMockEvent("test_event", "agg-1")); err == nil {
+		t.Error("Expected error when publishing after Stop")
+	}
+}
+
 func TestAsyncEventPublisher_Stop_Idempotent(t *testing.T) {
 	publisher := NewAsyncEventPublisher(2, 10)
 	handler := &MockEventHandler{}
@@ -395,3 +430,138 @@ func TestAsyncEventPublisher_ConcurrentPublish(t *testing.T) {
 	}
 }
 
+
+func TestEventBusStats(t *testing.T) {
+	bus := NewInMemoryEventBus()
+	_ = bus.Subscribe("order.created", &MockEventHandler{})
+	_ = bus.Subscribe("order.created", &MockEventHandler{})
+	_ = bus.Subscribe("order.paid", &MockEventHandler{})
+
+	stats := bus.Stats()
+	if stats.Subscribers["order.created"] != 2 || stats.Subscribers["order.paid"] != 1 {
+		t.Errorf("Unexpected subscribers: %v", stats.Subscribers)
+	}
+
+	publisher := NewAsyncEventPublisher(3, 16)
+	defer publisher.Stop(context.Background())
+
+	var provider EventBusStatsProvider = publisher
+	asyncStats := provider.Stats()
+	if asyncStats.Workers != 3 || asyncStats.QueueCapacity != 16 || asyncStats.QueueLength != 0 {
+		t.Errorf("Unexpected async stats: %+v", asyncStats)
+	}
+}
+
+type panicEventHandler struct{}
+
+func (h *panicEventHandler) Handle(ctx context.Context, event Event) error {
+	panic("handler bug")
+}
+
+func (h *panicEventHandler) EventType() string {
+	return "test_event"
+}
+
+type recordingDLQ struct {
+	reasons []string
+}
+
+func (q *recordingDLQ) Publish(ctx context.Context, event Event, reason string) error {
+	q.reasons = append(q.reasons, reason)
+	return nil
+}
+
+func TestInMemoryEventBus_HandlerPanic(t *testing.T) {
+	dlq := &recordingDLQ{}
+	bus := NewInMemoryEventBus().WithDeadLetterQueue(dlq)
+	handler := &MockEventHandler{}
+	_ = bus.Subscribe("test_event", &panicEventHandler{})
+	_ = bus.Subscribe("test_event", handler)
+
+	err := bus.Publish(context.Background(), newMockEvent("test_event", "agg-1"))
+	if !core.IsPanic(err) {
+		t.Fatalf("Expected recovered panic error, got %v", err)
+	}
+	if handler.HandledCount() != 1 {
+		t.Errorf("Expected other handler to receive event, got %d", handler.HandledCount())
+	}
+	if len(dlq.reasons) != 1 || !strings.Contains(dlq.reasons[0], "handler bug") || !strings.Contains(dlq.reasons[0], "goroutine") {
+		t.Errorf("Expected DLQ entry with stack trace, got %v", dlq.reasons)
+	}
+}
+
+func TestInMemoryEventBus_ConsumerGroups(t *testing.T) {
+	bus := NewInMemoryEventBus()
+	replicas := []*MockEventHandler{{}, {}, {}}
+	for _, handler := range replicas {
+		if err := bus.SubscribeWithGroup("test_event", handler, "billing"); err != nil {
+			t.Fatalf("Failed to subscribe to group: %v", err)
+		}
+	}
+	audit := &MockEventHandler{}
+	if err := bus.Subscribe("test_event", audit); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 6; i++ {
+		if err := bus.Publish(ctx, newMockEvent("test_event", "agg-1")); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	// Каждое событие получает один обработчик группы, нагрузка распределяется по очереди
+	total := 0
+	for i, handler := range replicas {
+		if handler.HandledCount() != 2 {
+			t.Errorf("Expected replica %d to handle 2 events, got %d", i, handler.HandledCount())
+		}
+		total += handler.HandledCount()
+	}
+	if total != 6 {
+		t.Errorf("Expected group to handle each event once, got %d", total)
+	}
+	if audit.HandledCount() != 6 {
+		t.Errorf("Expected handler without group to receive all events, got %d", audit.HandledCount())
+	}
+
+	// Отписанный обработчик выходит из группы
+	if err := bus.Unsubscribe("test_event", replicas[0]); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_ = bus.Publish(ctx, newMockEvent("test_event", "agg-1"))
+	}
+	if replicas[0].HandledCount() != 2 || replicas[1].HandledCount()+replicas[2].HandledCount() != 6 {
+		t.Errorf("Expected remaining replicas to share events, got %d/%d/%d",
+			replicas[0].HandledCount(), replicas[1].HandledCount(), replicas[2].HandledCount())
+	}
+}
+
+func TestSubjectNaming(t *testing.T) {
+	event := NewBaseEvent("order.created", "order-123")
+
+	if subject := DefaultSubjectNaming().Subject(event); subject != "events.order.order.created" {
+		t.Errorf("Expected default subject events.order.order.created, got %s", subject)
+	}
+
+	naming := NewSubjectNaming("{env}.{context}.{aggregate}.{event}.v{version}").WithContext("sales")
+	if subject := naming.Subject(event); subject != "sales.order.order.created.v1" {
+		t.Errorf("Expected empty env segment to be dropped, got %s", subject)
+	}
+
+	naming.WithEnv("prod")
+	versioned := NewBaseEvent("order.created", "123").
+		WithMetadata("aggregate_type", "Order").
+		WithMetadata("schema_version", 2)
+	if subject := naming.Subject(versioned); subject != "prod.sales.Order.order.created.v2" {
+		t.Errorf("Expected prod.sales.Order.order.created.v2, got %s", subject)
+	}
+
+	if pattern := naming.Pattern("order_created"); pattern != "prod.sales.*.order_created.*" {
+		t.Errorf("Expected prod.sales.*.order_created.*, got %s", pattern)
+	}
+	if pattern := PrefixSubjectNaming("events").Pattern("order_created"); pattern != "events.*.order_created" {
+		t.Errorf("Expected events.*.order_created, got %s", pattern)
+	}
+}
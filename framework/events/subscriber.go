@@ -12,6 +12,8 @@ type InMemoryEventSubscriber struct {
 	handlers      map[string][]EventHandler
 	priorities    map[string]int
 	consumerGroups map[string]string
+	// groupCursors позиции round-robin выбора обработчика в группах ("{eventType}:{group}")
+	groupCursors  map[string]int
 	mu            sync.RWMutex
 }
 
@@ -21,6 +23,7 @@ func NewInMemoryEventSubscriber() *InMemoryEventSubscriber {
 		handlers:       make(map[string][]EventHandler),
 		priorities:     make(map[string]int),
 		consumerGroups: make(map[string]string),
+		groupCursors:   make(map[string]int),
 	}
 }
 
@@ -58,7 +61,9 @@ func (s *InMemoryEventSubscriber) SubscribeWithPriority(eventType string, handle
 	return nil
 }
 
-// SubscribeWithGroup подписывается с группой потребителей
+// SubscribeWithGroup подписывается с группой потребителей. При публикации через
+// InMemoryEventBus событие получает один обработчик группы (по очереди), обработчики
+// без группы получают все события.
 func (s *InMemoryEventSubscriber) SubscribeWithGroup(eventType string, handler EventHandler, group string) error {
 	if err := s.Subscribe(eventType, handler); err != nil {
 		return err
@@ -151,6 +156,40 @@ func (s *InMemoryEventSubscriber) GetHandlersByGroup(eventType string, group str
 	return result
 }
 
+// selectGroupHandlers оставляет из обработчиков события обработчики без группы и по одному
+// обработчику каждой группы потребителей (round-robin)
+func (s *InMemoryEventSubscriber) selectGroupHandlers(eventType string, handlers []EventHandler) []EventHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.consumerGroups) == 0 {
+		return handlers
+	}
+
+	selected := make([]EventHandler, 0, len(handlers))
+	members := make(map[string][]EventHandler)
+	groups := make([]string, 0)
+	for _, h := range handlers {
+		group, ok := s.consumerGroups[fmt.Sprintf("%s:%p", eventType, h)]
+		if !ok || group == "" {
+			selected = append(selected, h)
+			continue
+		}
+		if _, seen := members[group]; !seen {
+			groups = append(groups, group)
+		}
+		members[group] = append(members[group], h)
+	}
+
+	for _, group := range groups {
+		key := eventType + ":" + group
+		cursor := s.groupCursors[key]
+		selected = append(selected, members[group][cursor%len(members[group])])
+		s.groupCursors[key] = cursor + 1
+	}
+	return selected
+}
+
 // FilterHandlers фильтрует обработчики по метаданным
 func (s *InMemoryEventSubscriber) FilterHandlers(eventType string, filter func(EventHandler) bool) []EventHandler {
 	handlers := s.GetHandlers(eventType)
@@ -0,0 +1,158 @@
+package eventsourcing
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// DefaultAggregatePageSize размер страницы ListAggregates по умолчанию
+const DefaultAggregatePageSize = 100
+
+// AggregateFilter фильтр перечисления агрегатов.
+// Постраничное чтение выполняется по курсору: следующая страница запрашивается
+// с After = AggregatePage.NextCursor.
+type AggregateFilter struct {
+	// AggregateType тип агрегата ("" - любой)
+	AggregateType string
+	// CreatedFrom агрегаты, созданные не раньше (zero - без ограничения)
+	CreatedFrom time.Time
+	// CreatedTo агрегаты, созданные раньше (zero - без ограничения)
+	CreatedTo time.Time
+	// After ID агрегата, после которого начинается страница ("" - с начала)
+	After string
+	// Limit размер страницы (0 - DefaultAggregatePageSize)
+	Limit int
+}
+
+// AggregateSummary сводка потока событий агрегата
+type AggregateSummary struct {
+	AggregateID   string
+	AggregateType string
+	// Version версия последнего события
+	Version int64
+	// EventCount количество событий в хранилище (без перенесенных в архив)
+	EventCount int64
+	// CreatedAt время первого события потока
+	CreatedAt time.Time
+	// UpdatedAt время последнего события потока
+	UpdatedAt time.Time
+}
+
+// AggregatePage страница агрегатов в порядке возрастания ID
+type AggregatePage struct {
+	Aggregates []AggregateSummary
+	// NextCursor курсор следующей страницы ("" - страница последняя)
+	NextCursor string
+}
+
+// AggregateLister реализуется хранилищами, умеющими перечислять потоки агрегатов
+// без чтения глобального лога событий
+type AggregateLister interface {
+	ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error)
+}
+
+// ListAggregates возвращает страницу агрегатов хранилища. Если хранилище не реализует
+// AggregateLister, агрегаты собираются чтением глобального лога (GetAllEvents),
+// что приемлемо только для небольших хранилищ.
+func ListAggregates(ctx context.Context, store EventStore, filter AggregateFilter) (*AggregatePage, error) {
+	if lister, ok := store.(AggregateLister); ok {
+		return lister.ListAggregates(ctx, filter)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eventCh, err := store.GetAllEvents(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]*AggregateSummary)
+	for event := range eventCh {
+		addToAggregateSummary(summaries, event)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return pageAggregates(summaries, filter), nil
+}
+
+// addToAggregateSummary учитывает событие в сводке его агрегата
+func addToAggregateSummary(summaries map[string]*AggregateSummary, event StoredEvent) {
+	summary, exists := summaries[event.AggregateID]
+	if !exists {
+		summary = &AggregateSummary{
+			AggregateID:   event.AggregateID,
+			AggregateType: event.AggregateType,
+			CreatedAt:     event.OccurredAt,
+		}
+		summaries[event.AggregateID] = summary
+	}
+
+	summary.EventCount++
+	if event.Version > summary.Version {
+		summary.Version = event.Version
+	}
+	if summary.AggregateType == "" {
+		summary.AggregateType = event.AggregateType
+	}
+	if event.OccurredAt.Before(summary.CreatedAt) {
+		summary.CreatedAt = event.OccurredAt
+	}
+	if event.OccurredAt.After(summary.UpdatedAt) {
+		summary.UpdatedAt = event.OccurredAt
+	}
+}
+
+// pageAggregates применяет фильтр к сводкам и возвращает страницу
+func pageAggregates(summaries map[string]*AggregateSummary, filter AggregateFilter) *AggregatePage {
+	limit := aggregatePageLimit(filter)
+
+	matched := make([]AggregateSummary, 0)
+	for aggregateID, summary := range summaries {
+		if aggregateID <= filter.After || !filter.matches(*summary) {
+			continue
+		}
+		matched = append(matched, *summary)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].AggregateID < matched[j].AggregateID
+	})
+
+	return newAggregatePage(matched, limit)
+}
+
+// matches проверяет тип и время создания агрегата
+func (f AggregateFilter) matches(summary AggregateSummary) bool {
+	if f.AggregateType != "" && summary.AggregateType != f.AggregateType {
+		return false
+	}
+	if !f.CreatedFrom.IsZero() && summary.CreatedAt.Before(f.CreatedFrom) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && !summary.CreatedAt.Before(f.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// aggregatePageLimit возвращает размер страницы фильтра
+func aggregatePageLimit(filter AggregateFilter) int {
+	if filter.Limit <= 0 {
+		return DefaultAggregatePageSize
+	}
+	return filter.Limit
+}
+
+// newAggregatePage формирует страницу из отсортированных агрегатов, прочитанных
+// с запасом в одну запись (наличие лишней записи означает, что есть следующая страница)
+func newAggregatePage(aggregates []AggregateSummary, limit int) *AggregatePage {
+	page := &AggregatePage{Aggregates: aggregates}
+	if len(aggregates) > limit {
+		page.Aggregates = aggregates[:limit]
+		page.NextCursor = aggregates[limit-1].AggregateID
+	}
+	return page
+}
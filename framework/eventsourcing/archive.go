@@ -0,0 +1,433 @@
+package eventsourcing
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+var (
+	// ErrArchiveObjectNotFound возникает, когда объект архива отсутствует в хранилище
+	ErrArchiveObjectNotFound = errors.New("archive object not found")
+	// ErrArchiveCorrupted возникает, когда содержимое сегмента архива не совпадает с контрольной суммой
+	ErrArchiveCorrupted = errors.New("archive segment checksum mismatch")
+)
+
+// ObjectStorage хранилище объектов для архива событий (S3, GCS, файловая система).
+// Другие хранилища подключаются приложением через этот интерфейс.
+type ObjectStorage interface {
+	// Put сохраняет объект (существующий объект с тем же ключом перезаписывается)
+	Put(ctx context.Context, key string, data []byte) error
+	// Get возвращает объект или ErrArchiveObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete удаляет объект
+	Delete(ctx context.Context, key string) error
+}
+
+// ArchiveFormat формат сегмента архива (JSONL, Parquet и т.д.)
+type ArchiveFormat interface {
+	// Name возвращает имя формата, сохраняемое в ArchiveSegment
+	Name() string
+	// Extension возвращает расширение объектов формата
+	Extension() string
+	// Encode сериализует события сегмента
+	Encode(events []StoredEvent) ([]byte, error)
+	// Decode восстанавливает события сегмента
+	Decode(data []byte) ([]StoredEvent, error)
+}
+
+// ArchiveSegment указатель на сегмент потока агрегата, перенесенный в архив
+type ArchiveSegment struct {
+	AggregateID string
+	Key         string
+	Format      string
+	FromVersion int64
+	ToVersion   int64
+	// FromPosition и ToPosition глобальные позиции первого и последнего события сегмента
+	FromPosition int64
+	ToPosition   int64
+	EventCount   int64
+	Size         int64
+	Checksum     string
+	ArchivedAt   time.Time
+}
+
+// ArchiveIndex хранит указатели на сегменты архива рядом с локальным хранилищем событий
+type ArchiveIndex interface {
+	// AddSegment сохраняет указатель на сегмент (повторное добавление сегмента с тем же ключом идемпотентно)
+	AddSegment(ctx context.Context, segment ArchiveSegment) error
+	// GetSegments возвращает сегменты агрегата в порядке версий
+	GetSegments(ctx context.Context, aggregateID string) ([]ArchiveSegment, error)
+	// ListSegments возвращает сегменты всех агрегатов, содержащие события с позицией
+	// не меньше fromPosition, в порядке FromPosition
+	ListSegments(ctx context.Context, fromPosition int64) ([]ArchiveSegment, error)
+}
+
+// EventStreamTruncator реализуется хранилищами, поддерживающими удаление начала потока
+// после переноса событий в архив
+type EventStreamTruncator interface {
+	// TruncateStream удаляет события агрегата с версией меньше beforeVersion
+	TruncateStream(ctx context.Context, aggregateID string, beforeVersion int64) error
+}
+
+// StaleStreamLister реализуется хранилищами, умеющими находить потоки для архивации
+type StaleStreamLister interface {
+	// ListStaleStreams возвращает до limit агрегатов (по возрастанию ID, начиная после after),
+	// последнее событие которых произошло раньше before и в потоке больше одного события
+	ListStaleStreams(ctx context.Context, before time.Time, after string, limit int) ([]string, error)
+}
+
+// JSONLArchiveFormat формат архива: события построчно в JSON, сжатые gzip
+type JSONLArchiveFormat struct {
+	deserializer EventDeserializer
+}
+
+// NewJSONLArchiveFormat создает формат JSONL.
+// deserializer восстанавливает конкретные типы событий; nil - события читаются как events.BaseEvent.
+func NewJSONLArchiveFormat(deserializer EventDeserializer) *JSONLArchiveFormat {
+	return &JSONLArchiveFormat{deserializer: deserializer}
+}
+
+// archiveRecord событие сегмента архива (строка JSONL, строка Parquet)
+type archiveRecord struct {
+	ID            string                 `json:"id"`
+	AggregateID   string                 `json:"aggregate_id"`
+	AggregateType string                 `json:"aggregate_type"`
+	EventType     string                 `json:"event_type"`
+	EventData     json.RawMessage        `json:"event_data"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Version       int64                  `json:"version"`
+	Position      int64                  `json:"position"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	CreatedAt     time.Time              `json:"created_at"`
+	SchemaVersion int                    `json:"schema_version"`
+	TenantID      string                 `json:"tenant_id,omitempty"`
+}
+
+// newArchiveRecord подготавливает событие к записи в сегмент архива
+func newArchiveRecord(deserializer EventDeserializer, stored StoredEvent) (archiveRecord, error) {
+	eventData, err := json.Marshal(stored.EventData)
+	if err != nil {
+		return archiveRecord{}, fmt.Errorf("failed to marshal event %s: %w", stored.ID, err)
+	}
+	record := archiveRecord{
+		ID:            stored.ID,
+		AggregateID:   stored.AggregateID,
+		AggregateType: stored.AggregateType,
+		EventType:     stored.EventType,
+		EventData:     eventData,
+		Metadata:      stored.Metadata,
+		Version:       stored.Version,
+		Position:      stored.Position,
+		OccurredAt:    stored.OccurredAt,
+		CreatedAt:     stored.CreatedAt,
+		// Данные события уже приведены к актуальной схеме при чтении из хранилища
+		SchemaVersion: stored.SchemaVersion,
+		TenantID:      stored.TenantID,
+	}
+	if stored.EventData != nil {
+		record.SchemaVersion = eventSchemaVersion(deserializer, stored.EventData)
+	}
+	return record, nil
+}
+
+// storedEvent восстанавливает событие из записи сегмента архива
+func (r archiveRecord) storedEvent(deserializer EventDeserializer) (StoredEvent, error) {
+	stored := StoredEvent{
+		ID:            r.ID,
+		AggregateID:   r.AggregateID,
+		AggregateType: r.AggregateType,
+		EventType:     r.EventType,
+		Metadata:      r.Metadata,
+		Version:       r.Version,
+		Position:      r.Position,
+		OccurredAt:    r.OccurredAt,
+		CreatedAt:     r.CreatedAt,
+		SchemaVersion: r.SchemaVersion,
+		TenantID:      r.TenantID,
+	}
+
+	if deserializer != nil {
+		event, err := deserializeStoredEvent(deserializer, r.EventType, r.SchemaVersion, r.EventData)
+		if err != nil {
+			return StoredEvent{}, fmt.Errorf("%w: %w", ErrEventDeserializationFailed, err)
+		}
+		stored.EventData = event
+	} else {
+		var baseEvent events.BaseEvent
+		if err := json.Unmarshal(r.EventData, &baseEvent); err == nil {
+			stored.EventData = &baseEvent
+		}
+	}
+	return stored, nil
+}
+
+// Name возвращает имя формата
+func (f *JSONLArchiveFormat) Name() string {
+	return "jsonl+gzip"
+}
+
+// Extension возвращает расширение объектов формата
+func (f *JSONLArchiveFormat) Extension() string {
+	return "jsonl.gz"
+}
+
+// Encode сериализует события сегмента
+func (f *JSONLArchiveFormat) Encode(storedEvents []StoredEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(writer)
+
+	for _, stored := range storedEvents {
+		record, err := newArchiveRecord(f.deserializer, stored)
+		if err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(record); err != nil {
+			return nil, fmt.Errorf("failed to encode event %s: %w", stored.ID, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive segment: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode восстанавливает события сегмента
+func (f *JSONLArchiveFormat) Decode(data []byte) ([]StoredEvent, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive segment: %w", err)
+	}
+	defer reader.Close()
+
+	var result []StoredEvent
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var record archiveRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode archive record: %w", err)
+		}
+
+		stored, err := record.storedEvent(f.deserializer)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive segment: %w", err)
+	}
+
+	return result, nil
+}
+
+// InMemoryObjectStorage реализация ObjectStorage в памяти для тестирования
+type InMemoryObjectStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewInMemoryObjectStorage создает новое хранилище объектов в памяти
+func NewInMemoryObjectStorage() *InMemoryObjectStorage {
+	return &InMemoryObjectStorage{
+		objects: make(map[string][]byte),
+	}
+}
+
+// Put сохраняет объект
+func (s *InMemoryObjectStorage) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Get возвращает объект
+func (s *InMemoryObjectStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveObjectNotFound, key)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Delete удаляет объект
+func (s *InMemoryObjectStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// Keys возвращает ключи объектов в лексикографическом порядке
+func (s *InMemoryObjectStorage) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FileSystemObjectStorage реализация ObjectStorage в каталоге файловой системы
+// (локальный диск, NFS, смонтированный бакет)
+type FileSystemObjectStorage struct {
+	root string
+}
+
+// NewFileSystemObjectStorage создает хранилище объектов в каталоге root
+func NewFileSystemObjectStorage(root string) (*FileSystemObjectStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &FileSystemObjectStorage{root: root}, nil
+}
+
+// Put сохраняет объект. Запись атомарна: файл пишется во временный и переименовывается.
+func (s *FileSystemObjectStorage) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+	return nil
+}
+
+// Get возвращает объект
+func (s *FileSystemObjectStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrArchiveObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to read archive object: %w", err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// Delete удаляет объект
+func (s *FileSystemObjectStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete archive object: %w", err)
+	}
+	return nil
+}
+
+// path возвращает путь объекта, запрещая выход за пределы каталога
+func (s *FileSystemObjectStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" {
+		return "", fmt.Errorf("invalid archive object key: %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(strings.TrimPrefix(cleaned, "/"))), nil
+}
+
+// InMemoryArchiveIndex реализация ArchiveIndex в памяти
+type InMemoryArchiveIndex struct {
+	mu       sync.RWMutex
+	segments map[string][]ArchiveSegment
+}
+
+// NewInMemoryArchiveIndex создает новый индекс архива в памяти
+func NewInMemoryArchiveIndex() *InMemoryArchiveIndex {
+	return &InMemoryArchiveIndex{
+		segments: make(map[string][]ArchiveSegment),
+	}
+}
+
+// AddSegment сохраняет указатель на сегмент
+func (i *InMemoryArchiveIndex) AddSegment(ctx context.Context, segment ArchiveSegment) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	segments := i.segments[segment.AggregateID]
+	for idx, existing := range segments {
+		if existing.Key == segment.Key {
+			segments[idx] = segment
+			return nil
+		}
+	}
+	segments = append(segments, segment)
+	sort.Slice(segments, func(a, b int) bool {
+		return segments[a].FromVersion < segments[b].FromVersion
+	})
+	i.segments[segment.AggregateID] = segments
+	return nil
+}
+
+// GetSegments возвращает сегменты агрегата в порядке версий
+func (i *InMemoryArchiveIndex) GetSegments(ctx context.Context, aggregateID string) ([]ArchiveSegment, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]ArchiveSegment(nil), i.segments[aggregateID]...), nil
+}
+
+// ListSegments возвращает сегменты всех агрегатов с событиями начиная с позиции fromPosition
+func (i *InMemoryArchiveIndex) ListSegments(ctx context.Context, fromPosition int64) ([]ArchiveSegment, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var result []ArchiveSegment
+	for _, segments := range i.segments {
+		for _, segment := range segments {
+			if segment.ToPosition >= fromPosition {
+				result = append(result, segment)
+			}
+		}
+	}
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].FromPosition < result[b].FromPosition
+	})
+	return result, nil
+}
+
+// archiveChecksum возвращает SHA-256 содержимого сегмента
+func archiveChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
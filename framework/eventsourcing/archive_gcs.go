@@ -0,0 +1,150 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GCSConfig конфигурация GCSObjectStorage
+type GCSConfig struct {
+	// Bucket имя бакета
+	Bucket string
+	// Endpoint адрес JSON API; пустой - https://storage.googleapis.com.
+	// Для эмулятора (fake-gcs-server) указывается его адрес.
+	Endpoint string
+	// Prefix префикс имен объектов в бакете
+	Prefix string
+	// TokenSource возвращает OAuth2 access token для заголовка Authorization
+	// (например, обертка над golang.org/x/oauth2/google); nil - запросы без авторизации (эмулятор)
+	TokenSource func(ctx context.Context) (string, error)
+}
+
+// GCSObjectStorage реализация ObjectStorage поверх JSON API Google Cloud Storage
+// без зависимости от cloud.google.com/go/storage
+type GCSObjectStorage struct {
+	config   GCSConfig
+	endpoint string
+	client   *http.Client
+}
+
+// NewGCSObjectStorage создает хранилище объектов в бакете GCS
+func NewGCSObjectStorage(config GCSConfig) (*GCSObjectStorage, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("gcs bucket is required")
+	}
+	endpoint := strings.TrimRight(config.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	return &GCSObjectStorage{
+		config:   config,
+		endpoint: endpoint,
+		client:   http.DefaultClient,
+	}, nil
+}
+
+// WithHTTPClient задает HTTP клиент
+func (s *GCSObjectStorage) WithHTTPClient(client *http.Client) *GCSObjectStorage {
+	s.client = client
+	return s
+}
+
+// Put сохраняет объект: POST /upload/storage/v1/b/<bucket>/o?uploadType=media&name=<key>
+func (s *GCSObjectStorage) Put(ctx context.Context, key string, data []byte) error {
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", s.objectName(key))
+	target := s.endpoint + "/upload/storage/v1/b/" + url.PathEscape(s.config.Bucket) + "/o?" + query.Encode()
+
+	resp, err := s.do(ctx, http.MethodPost, target, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return gcsResponseError(http.MethodPost, key, resp)
+	}
+	return nil
+}
+
+// Get возвращает объект: GET /storage/v1/b/<bucket>/o/<key>?alt=media
+func (s *GCSObjectStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(key)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveObjectNotFound, key)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, gcsResponseError(http.MethodGet, key, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive object %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Delete удаляет объект: DELETE /storage/v1/b/<bucket>/o/<key>. Отсутствующий объект не считается ошибкой.
+func (s *GCSObjectStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+		return gcsResponseError(http.MethodDelete, key, resp)
+	}
+	return nil
+}
+
+// objectName возвращает имя объекта в бакете с учетом префикса
+func (s *GCSObjectStorage) objectName(key string) string {
+	return strings.TrimLeft(s.config.Prefix+key, "/")
+}
+
+// objectURL возвращает адрес метаданных объекта (имя кодируется целиком, включая "/")
+func (s *GCSObjectStorage) objectURL(key string) string {
+	return s.endpoint + "/storage/v1/b/" + url.PathEscape(s.config.Bucket) + "/o/" + url.PathEscape(s.objectName(key))
+}
+
+// do выполняет запрос к JSON API
+func (s *GCSObjectStorage) do(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	} else {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	req.ContentLength = int64(len(body))
+
+	if s.config.TokenSource != nil {
+		token, err := s.config.TokenSource(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gcs access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcs request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// gcsResponseError формирует ошибку по ответу GCS
+func gcsResponseError(method, key string, resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("gcs %s %s: status %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(message)))
+}
@@ -0,0 +1,819 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ParquetArchiveFormat формат архива Parquet для аналитики по архиву (Spark, DuckDB, Athena, BigQuery).
+// Сегмент записывается одной группой строк, каждая колонка одной страницей данных
+// с кодированием PLAIN и сжатием GZIP; все колонки обязательные (REQUIRED).
+// Чтение поддерживает файлы с этой же структурой: страницы со словарем, DATA_PAGE_V2,
+// необязательные колонки и другие кодеки сжатия отклоняются.
+//
+// Колонки: id, aggregate_id, aggregate_type, event_type, event_data (JSON),
+// metadata (JSON, пустая строка без метаданных), version, position,
+// occurred_at и created_at (TIMESTAMP в наносекундах, UTC), schema_version, tenant_id.
+type ParquetArchiveFormat struct {
+	deserializer EventDeserializer
+}
+
+// NewParquetArchiveFormat создает формат Parquet.
+// deserializer восстанавливает конкретные типы событий; nil - события читаются как events.BaseEvent.
+func NewParquetArchiveFormat(deserializer EventDeserializer) *ParquetArchiveFormat {
+	return &ParquetArchiveFormat{deserializer: deserializer}
+}
+
+// Name возвращает имя формата
+func (f *ParquetArchiveFormat) Name() string {
+	return "parquet"
+}
+
+// Extension возвращает расширение объектов формата
+func (f *ParquetArchiveFormat) Extension() string {
+	return "parquet"
+}
+
+// parquetMagic маркер начала и конца файла Parquet
+const parquetMagic = "PAR1"
+
+// Значения перечислений parquet.thrift
+const (
+	parquetTypeInt32     = 1
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetRepetitionRequired = 0
+
+	parquetConvertedUTF8 = 0
+	parquetConvertedJSON = 19
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetCodecGzip         = 2
+
+	parquetPageData = 0
+)
+
+// parquetLogical логический тип колонки
+type parquetLogical int
+
+const (
+	parquetLogicalNone parquetLogical = iota
+	parquetLogicalString
+	parquetLogicalJSON
+	parquetLogicalTimestamp
+)
+
+// parquetColumn колонка сегмента архива и ее отображение на archiveRecord
+type parquetColumn struct {
+	name     string
+	physical int64
+	logical  parquetLogical
+	// intOf и setInt для колонок INT32/INT64
+	intOf  func(r *archiveRecord) int64
+	setInt func(r *archiveRecord, v int64)
+	// bytesOf и setBytes для колонок BYTE_ARRAY
+	bytesOf  func(r *archiveRecord) ([]byte, error)
+	setBytes func(r *archiveRecord, v []byte) error
+}
+
+// parquetStringColumn колонка-строка
+func parquetStringColumn(name string, field func(r *archiveRecord) *string) parquetColumn {
+	return parquetColumn{
+		name:     name,
+		physical: parquetTypeByteArray,
+		logical:  parquetLogicalString,
+		bytesOf: func(r *archiveRecord) ([]byte, error) {
+			return []byte(*field(r)), nil
+		},
+		setBytes: func(r *archiveRecord, v []byte) error {
+			*field(r) = string(v)
+			return nil
+		},
+	}
+}
+
+// parquetTimestampColumn колонка времени в наносекундах (нулевое время хранится как 0)
+func parquetTimestampColumn(name string, field func(r *archiveRecord) *time.Time) parquetColumn {
+	return parquetColumn{
+		name:     name,
+		physical: parquetTypeInt64,
+		logical:  parquetLogicalTimestamp,
+		intOf: func(r *archiveRecord) int64 {
+			if field(r).IsZero() {
+				return 0
+			}
+			return field(r).UnixNano()
+		},
+		setInt: func(r *archiveRecord, v int64) {
+			if v != 0 {
+				*field(r) = time.Unix(0, v).UTC()
+			}
+		},
+	}
+}
+
+// parquetArchiveColumns колонки сегмента архива в порядке записи
+var parquetArchiveColumns = []parquetColumn{
+	parquetStringColumn("id", func(r *archiveRecord) *string { return &r.ID }),
+	parquetStringColumn("aggregate_id", func(r *archiveRecord) *string { return &r.AggregateID }),
+	parquetStringColumn("aggregate_type", func(r *archiveRecord) *string { return &r.AggregateType }),
+	parquetStringColumn("event_type", func(r *archiveRecord) *string { return &r.EventType }),
+	{
+		name:     "event_data",
+		physical: parquetTypeByteArray,
+		logical:  parquetLogicalJSON,
+		bytesOf: func(r *archiveRecord) ([]byte, error) {
+			return r.EventData, nil
+		},
+		setBytes: func(r *archiveRecord, v []byte) error {
+			r.EventData = append(json.RawMessage(nil), v...)
+			return nil
+		},
+	},
+	{
+		name:     "metadata",
+		physical: parquetTypeByteArray,
+		logical:  parquetLogicalJSON,
+		bytesOf: func(r *archiveRecord) ([]byte, error) {
+			if len(r.Metadata) == 0 {
+				return nil, nil
+			}
+			return json.Marshal(r.Metadata)
+		},
+		setBytes: func(r *archiveRecord, v []byte) error {
+			if len(v) == 0 {
+				return nil
+			}
+			return json.Unmarshal(v, &r.Metadata)
+		},
+	},
+	{
+		name:     "version",
+		physical: parquetTypeInt64,
+		intOf:    func(r *archiveRecord) int64 { return r.Version },
+		setInt:   func(r *archiveRecord, v int64) { r.Version = v },
+	},
+	{
+		name:     "position",
+		physical: parquetTypeInt64,
+		intOf:    func(r *archiveRecord) int64 { return r.Position },
+		setInt:   func(r *archiveRecord, v int64) { r.Position = v },
+	},
+	parquetTimestampColumn("occurred_at", func(r *archiveRecord) *time.Time { return &r.OccurredAt }),
+	parquetTimestampColumn("created_at", func(r *archiveRecord) *time.Time { return &r.CreatedAt }),
+	{
+		name:     "schema_version",
+		physical: parquetTypeInt32,
+		intOf:    func(r *archiveRecord) int64 { return int64(r.SchemaVersion) },
+		setInt:   func(r *archiveRecord, v int64) { r.SchemaVersion = int(v) },
+	},
+	parquetStringColumn("tenant_id", func(r *archiveRecord) *string { return &r.TenantID }),
+}
+
+// Encode сериализует события сегмента в файл Parquet
+func (f *ParquetArchiveFormat) Encode(storedEvents []StoredEvent) ([]byte, error) {
+	records := make([]archiveRecord, len(storedEvents))
+	for i, stored := range storedEvents {
+		record, err := newArchiveRecord(f.deserializer, stored)
+		if err != nil {
+			return nil, err
+		}
+		records[i] = record
+	}
+
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	var chunks []interface{}
+	var totalByteSize int64
+	for _, column := range parquetArchiveColumns {
+		page, err := encodeParquetPlain(column, records)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode parquet column %s: %w", column.name, err)
+		}
+		compressed, err := gzipBytes(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress parquet column %s: %w", column.name, err)
+		}
+
+		var header bytes.Buffer
+		thriftStruct{
+			{1, int32(parquetPageData)},
+			{2, int32(len(page))},
+			{3, int32(len(compressed))},
+			{5, thriftStruct{
+				{1, int32(len(records))},
+				{2, int32(parquetEncodingPlain)},
+				{3, int32(parquetEncodingRLE)},
+				{4, int32(parquetEncodingRLE)},
+			}},
+		}.encode(&header)
+
+		offset := int64(file.Len())
+		file.Write(header.Bytes())
+		file.Write(compressed)
+
+		uncompressedSize := int64(header.Len() + len(page))
+		totalByteSize += uncompressedSize
+		chunks = append(chunks, thriftStruct{
+			{2, offset},
+			{3, thriftStruct{
+				{1, int32(column.physical)},
+				{2, thriftList{elem: thriftTypeI32, items: []interface{}{int32(parquetEncodingPlain), int32(parquetEncodingRLE)}}},
+				{3, thriftList{elem: thriftTypeBinary, items: []interface{}{column.name}}},
+				{4, int32(parquetCodecGzip)},
+				{5, int64(len(records))},
+				{6, uncompressedSize},
+				{7, int64(header.Len() + len(compressed))},
+				{9, offset},
+			}},
+		})
+	}
+
+	schema := []interface{}{thriftStruct{
+		{4, "schema"},
+		{5, int32(len(parquetArchiveColumns))},
+	}}
+	for _, column := range parquetArchiveColumns {
+		schema = append(schema, column.schemaElement())
+	}
+
+	var footer bytes.Buffer
+	thriftStruct{
+		{1, int32(1)},
+		{2, thriftList{elem: thriftTypeStruct, items: schema}},
+		{3, int64(len(records))},
+		{4, thriftList{elem: thriftTypeStruct, items: []interface{}{thriftStruct{
+			{1, thriftList{elem: thriftTypeStruct, items: chunks}},
+			{2, totalByteSize},
+			{3, int64(len(records))},
+		}}}},
+		{6, "potter"},
+	}.encode(&footer)
+
+	file.Write(footer.Bytes())
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(footer.Len()))
+	file.Write(length[:])
+	file.WriteString(parquetMagic)
+	return file.Bytes(), nil
+}
+
+// schemaElement возвращает описание колонки в схеме файла
+func (c parquetColumn) schemaElement() thriftStruct {
+	element := thriftStruct{
+		{1, int32(c.physical)},
+		{3, int32(parquetRepetitionRequired)},
+		{4, c.name},
+	}
+	switch c.logical {
+	case parquetLogicalString:
+		element = append(element,
+			thriftField{6, int32(parquetConvertedUTF8)},
+			thriftField{10, thriftStruct{{1, thriftStruct{}}}})
+	case parquetLogicalJSON:
+		element = append(element,
+			thriftField{6, int32(parquetConvertedJSON)},
+			thriftField{10, thriftStruct{{12, thriftStruct{}}}})
+	case parquetLogicalTimestamp:
+		// TIMESTAMP(isAdjustedToUTC=true, unit=NANOS)
+		element = append(element,
+			thriftField{10, thriftStruct{{8, thriftStruct{{1, true}, {2, thriftStruct{{3, thriftStruct{}}}}}}}})
+	}
+	return element
+}
+
+// encodeParquetPlain кодирует значения колонки в PLAIN
+func encodeParquetPlain(column parquetColumn, records []archiveRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	for i := range records {
+		switch column.physical {
+		case parquetTypeInt32:
+			var value [4]byte
+			binary.LittleEndian.PutUint32(value[:], uint32(int32(column.intOf(&records[i]))))
+			buf.Write(value[:])
+		case parquetTypeInt64:
+			var value [8]byte
+			binary.LittleEndian.PutUint64(value[:], uint64(column.intOf(&records[i])))
+			buf.Write(value[:])
+		default:
+			data, err := column.bytesOf(&records[i])
+			if err != nil {
+				return nil, err
+			}
+			var length [4]byte
+			binary.LittleEndian.PutUint32(length[:], uint32(len(data)))
+			buf.Write(length[:])
+			buf.Write(data)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode восстанавливает события сегмента из файла Parquet
+func (f *ParquetArchiveFormat) Decode(data []byte) ([]StoredEvent, error) {
+	records, err := decodeParquetArchive(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parquet archive segment: %w", err)
+	}
+
+	result := make([]StoredEvent, 0, len(records))
+	for _, record := range records {
+		stored, err := record.storedEvent(f.deserializer)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored)
+	}
+	return result, nil
+}
+
+// decodeParquetArchive читает строки сегмента архива из файла Parquet
+func decodeParquetArchive(data []byte) ([]archiveRecord, error) {
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		return nil, errors.New("missing PAR1 magic")
+	}
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if footerLength > len(data)-12 {
+		return nil, errors.New("footer length out of range")
+	}
+	footerReader := &thriftReader{data: data[len(data)-8-footerLength : len(data)-8]}
+	metadata, err := footerReader.readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read footer: %w", err)
+	}
+
+	columns := make(map[string]parquetColumn, len(parquetArchiveColumns))
+	for _, column := range parquetArchiveColumns {
+		columns[column.name] = column
+	}
+	schema := thriftListField(metadata, 2)
+	if len(schema) == 0 {
+		return nil, errors.New("missing schema")
+	}
+	found := make(map[string]bool, len(columns))
+	for _, item := range schema[1:] {
+		element, _ := item.(map[int16]interface{})
+		name := string(thriftBytesField(element, 4))
+		if thriftIntField(element, 5) > 0 {
+			return nil, fmt.Errorf("nested column %s is not supported", name)
+		}
+		if thriftIntField(element, 3) != parquetRepetitionRequired {
+			return nil, fmt.Errorf("column %s must be REQUIRED", name)
+		}
+		column, ok := columns[name]
+		if !ok {
+			continue
+		}
+		if thriftIntField(element, 1) != column.physical {
+			return nil, fmt.Errorf("column %s has unexpected physical type %d", name, thriftIntField(element, 1))
+		}
+		found[name] = true
+	}
+	for name := range columns {
+		if !found[name] {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	numRows := thriftIntField(metadata, 3)
+	if numRows < 0 || numRows > int64(len(data)) {
+		return nil, fmt.Errorf("invalid row count %d", numRows)
+	}
+	records := make([]archiveRecord, numRows)
+
+	var base int64
+	for _, item := range thriftListField(metadata, 4) {
+		rowGroup, _ := item.(map[int16]interface{})
+		rows := thriftIntField(rowGroup, 3)
+		if rows < 0 || base+rows > numRows {
+			return nil, errors.New("row group exceeds file row count")
+		}
+		for _, chunkItem := range thriftListField(rowGroup, 1) {
+			chunk, _ := chunkItem.(map[int16]interface{})
+			meta := thriftStructField(chunk, 3)
+			path := thriftListField(meta, 3)
+			if len(path) != 1 {
+				return nil, errors.New("invalid column path")
+			}
+			name, _ := path[0].([]byte)
+			column, ok := columns[string(name)]
+			if !ok {
+				continue
+			}
+			if thriftIntField(meta, 5) != rows {
+				return nil, fmt.Errorf("column %s has %d values, expected %d", name, thriftIntField(meta, 5), rows)
+			}
+			if err := decodeParquetColumnChunk(data, column, thriftIntField(meta, 4), thriftIntField(meta, 9), records[base:base+rows]); err != nil {
+				return nil, fmt.Errorf("column %s: %w", name, err)
+			}
+		}
+		base += rows
+	}
+	if base != numRows {
+		return nil, errors.New("row groups do not cover file row count")
+	}
+	return records, nil
+}
+
+// decodeParquetColumnChunk читает страницы данных колонки начиная с offset
+func decodeParquetColumnChunk(data []byte, column parquetColumn, codec, offset int64, records []archiveRecord) error {
+	if offset < 4 || offset >= int64(len(data)) {
+		return errors.New("data page offset out of range")
+	}
+	reader := &thriftReader{data: data, pos: int(offset)}
+
+	for decoded := 0; decoded < len(records); {
+		header, err := reader.readStruct()
+		if err != nil {
+			return fmt.Errorf("failed to read page header: %w", err)
+		}
+		if pageType := thriftIntField(header, 1); pageType != parquetPageData {
+			return fmt.Errorf("page type %d is not supported", pageType)
+		}
+		pageHeader := thriftStructField(header, 5)
+		if encoding := thriftIntField(pageHeader, 2); encoding != parquetEncodingPlain {
+			return fmt.Errorf("encoding %d is not supported", encoding)
+		}
+		size := int(thriftIntField(header, 3))
+		if size < 0 || size > len(data)-reader.pos {
+			return errors.New("page size out of range")
+		}
+		page := data[reader.pos : reader.pos+size]
+		reader.pos += size
+
+		switch codec {
+		case parquetCodecUncompressed:
+		case parquetCodecGzip:
+			if page, err = gunzipBytes(page); err != nil {
+				return fmt.Errorf("failed to decompress page: %w", err)
+			}
+		default:
+			return fmt.Errorf("compression codec %d is not supported", codec)
+		}
+
+		count := int(thriftIntField(pageHeader, 1))
+		if count < 0 || count > len(records)-decoded {
+			return errors.New("page value count out of range")
+		}
+		if err := decodeParquetPlain(column, page, records[decoded:decoded+count]); err != nil {
+			return err
+		}
+		decoded += count
+		if count == 0 && decoded < len(records) {
+			return errors.New("empty data page")
+		}
+	}
+	return nil
+}
+
+// decodeParquetPlain декодирует значения страницы PLAIN в строки
+func decodeParquetPlain(column parquetColumn, page []byte, records []archiveRecord) error {
+	for i := range records {
+		switch column.physical {
+		case parquetTypeInt32:
+			if len(page) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			column.setInt(&records[i], int64(int32(binary.LittleEndian.Uint32(page))))
+			page = page[4:]
+		case parquetTypeInt64:
+			if len(page) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			column.setInt(&records[i], int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		default:
+			if len(page) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			length := binary.LittleEndian.Uint32(page)
+			if uint64(length) > uint64(len(page)-4) {
+				return io.ErrUnexpectedEOF
+			}
+			if err := column.setBytes(&records[i], page[4:4+length]); err != nil {
+				return err
+			}
+			page = page[4+length:]
+		}
+	}
+	return nil
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// Типы Thrift Compact Protocol
+const (
+	thriftTypeBoolTrue  = 1
+	thriftTypeBoolFalse = 2
+	thriftTypeByte      = 3
+	thriftTypeI16       = 4
+	thriftTypeI32       = 5
+	thriftTypeI64       = 6
+	thriftTypeDouble    = 7
+	thriftTypeBinary    = 8
+	thriftTypeList      = 9
+	thriftTypeSet       = 10
+	thriftTypeMap       = 11
+	thriftTypeStruct    = 12
+)
+
+// thriftField поле структуры Thrift. Значение: bool, int32, int64, string, []byte,
+// thriftStruct или thriftList.
+type thriftField struct {
+	id    int16
+	value interface{}
+}
+
+// thriftStruct структура Thrift для записи в Compact Protocol (поля в порядке возрастания id)
+type thriftStruct []thriftField
+
+// thriftList список Thrift с элементами типа elem
+type thriftList struct {
+	elem  byte
+	items []interface{}
+}
+
+// encode записывает структуру в Compact Protocol
+func (s thriftStruct) encode(buf *bytes.Buffer) {
+	var last int16
+	for _, field := range s {
+		fieldType := thriftValueType(field.value)
+		if value, ok := field.value.(bool); ok && !value {
+			fieldType = thriftTypeBoolFalse
+		}
+		if delta := field.id - last; delta > 0 && delta <= 15 {
+			buf.WriteByte(byte(delta)<<4 | fieldType)
+		} else {
+			buf.WriteByte(fieldType)
+			writeThriftVarint(buf, zigzag(int64(field.id)))
+		}
+		last = field.id
+		if _, ok := field.value.(bool); !ok {
+			writeThriftValue(buf, field.value)
+		}
+	}
+	buf.WriteByte(0)
+}
+
+func thriftValueType(value interface{}) byte {
+	switch value.(type) {
+	case bool:
+		return thriftTypeBoolTrue
+	case int32:
+		return thriftTypeI32
+	case int64:
+		return thriftTypeI64
+	case string, []byte:
+		return thriftTypeBinary
+	case thriftList:
+		return thriftTypeList
+	default:
+		return thriftTypeStruct
+	}
+}
+
+func writeThriftValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case int32:
+		writeThriftVarint(buf, zigzag(int64(v)))
+	case int64:
+		writeThriftVarint(buf, zigzag(v))
+	case string:
+		writeThriftVarint(buf, uint64(len(v)))
+		buf.WriteString(v)
+	case []byte:
+		writeThriftVarint(buf, uint64(len(v)))
+		buf.Write(v)
+	case thriftStruct:
+		v.encode(buf)
+	case thriftList:
+		if len(v.items) < 15 {
+			buf.WriteByte(byte(len(v.items))<<4 | v.elem)
+		} else {
+			buf.WriteByte(0xf0 | v.elem)
+			writeThriftVarint(buf, uint64(len(v.items)))
+		}
+		for _, item := range v.items {
+			writeThriftValue(buf, item)
+		}
+	}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func writeThriftVarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+// thriftReader читает Compact Protocol. Структуры читаются в map[int16]interface{}
+// (значения: bool, int64, float64, []byte, []interface{}, map[int16]interface{}).
+type thriftReader struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) readVarint() (uint64, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) readZigzag() (int64, error) {
+	v, err := r.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (r *thriftReader) readStruct() (map[int16]interface{}, error) {
+	if r.depth++; r.depth > 64 {
+		return nil, errors.New("thrift structure is too deep")
+	}
+	defer func() { r.depth-- }()
+
+	result := make(map[int16]interface{})
+	var last int16
+	for {
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return result, nil
+		}
+		fieldType := header & 0x0f
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			v, err := r.readZigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+
+		var value interface{}
+		switch fieldType {
+		case thriftTypeBoolTrue:
+			value = true
+		case thriftTypeBoolFalse:
+			value = false
+		default:
+			if value, err = r.readValue(fieldType); err != nil {
+				return nil, err
+			}
+		}
+		result[id] = value
+	}
+}
+
+func (r *thriftReader) readValue(valueType byte) (interface{}, error) {
+	switch valueType {
+	case thriftTypeBoolTrue, thriftTypeBoolFalse:
+		// Элементы коллекций типа bool записываются отдельным байтом
+		b, err := r.readByte()
+		return b == thriftTypeBoolTrue, err
+	case thriftTypeByte:
+		b, err := r.readByte()
+		return int64(int8(b)), err
+	case thriftTypeI16, thriftTypeI32, thriftTypeI64:
+		return r.readZigzag()
+	case thriftTypeDouble:
+		if len(r.data)-r.pos < 8 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case thriftTypeBinary:
+		length, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if length > uint64(len(r.data)-r.pos) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := r.data[r.pos : r.pos+int(length)]
+		r.pos += int(length)
+		return v, nil
+	case thriftTypeList, thriftTypeSet:
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = r.readVarint(); err != nil {
+				return nil, err
+			}
+		}
+		if size > uint64(len(r.data)-r.pos) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		items := make([]interface{}, 0, size)
+		for i := uint64(0); i < size; i++ {
+			item, err := r.readValue(header & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case thriftTypeMap:
+		size, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, nil
+		}
+		if size > uint64(len(r.data)-r.pos) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < size; i++ {
+			if _, err := r.readValue(types >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(types & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftTypeStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("unknown thrift type %d", valueType)
+	}
+}
+
+func thriftIntField(s map[int16]interface{}, id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func thriftBytesField(s map[int16]interface{}, id int16) []byte {
+	v, _ := s[id].([]byte)
+	return v
+}
+
+func thriftStructField(s map[int16]interface{}, id int16) map[int16]interface{} {
+	v, _ := s[id].(map[int16]interface{})
+	return v
+}
+
+func thriftListField(s map[int16]interface{}, id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
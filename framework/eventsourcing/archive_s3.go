@@ -0,0 +1,268 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Credentials учетные данные AWS для подписи запросов (Signature Version 4)
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken токен временных учетных данных (STS, IAM роль), пустой для постоянных ключей
+	SessionToken string
+}
+
+// S3Config конфигурация S3ObjectStorage
+type S3Config struct {
+	// Bucket имя бакета
+	Bucket string
+	// Region регион бакета (us-east-1, eu-central-1)
+	Region string
+	// Endpoint адрес S3 API; пустой - https://s3.<region>.amazonaws.com.
+	// Для S3-совместимых хранилищ (MinIO, Ceph, Yandex Object Storage, GCS в режиме
+	// interoperability с HMAC ключами) указывается их адрес.
+	Endpoint string
+	// PathStyle адресует бакет в пути (<endpoint>/<bucket>/<key>) вместо поддомена;
+	// требуется MinIO и большинству S3-совместимых хранилищ
+	PathStyle bool
+	// Prefix префикс ключей объектов в бакете
+	Prefix string
+	// Credentials постоянные учетные данные; для ротируемых используйте WithCredentialsProvider
+	Credentials S3Credentials
+}
+
+// S3ObjectStorage реализация ObjectStorage поверх REST API Amazon S3 и S3-совместимых хранилищ.
+// Запросы подписываются AWS Signature Version 4 без зависимости от aws-sdk-go.
+type S3ObjectStorage struct {
+	config      S3Config
+	endpoint    string
+	client      *http.Client
+	credentials func(ctx context.Context) (S3Credentials, error)
+	now         func() time.Time
+}
+
+// NewS3ObjectStorage создает хранилище объектов в бакете S3
+func NewS3ObjectStorage(config S3Config) (*S3ObjectStorage, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if config.Region == "" {
+		return nil, fmt.Errorf("s3 region is required")
+	}
+
+	endpoint := strings.TrimRight(config.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	static := config.Credentials
+	return &S3ObjectStorage{
+		config:   config,
+		endpoint: endpoint,
+		client:   http.DefaultClient,
+		credentials: func(ctx context.Context) (S3Credentials, error) {
+			return static, nil
+		},
+		now: time.Now,
+	}, nil
+}
+
+// WithHTTPClient задает HTTP клиент
+func (s *S3ObjectStorage) WithHTTPClient(client *http.Client) *S3ObjectStorage {
+	s.client = client
+	return s
+}
+
+// WithCredentialsProvider задает источник учетных данных, вызываемый перед каждым запросом
+// (например, обертка над credentials provider aws-sdk-go-v2 для IAM ролей)
+func (s *S3ObjectStorage) WithCredentialsProvider(provider func(ctx context.Context) (S3Credentials, error)) *S3ObjectStorage {
+	s.credentials = provider
+	return s
+}
+
+// Put сохраняет объект: PUT /<key>
+func (s *S3ObjectStorage) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return s3ResponseError(http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+// Get возвращает объект: GET /<key>
+func (s *S3ObjectStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveObjectNotFound, key)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, s3ResponseError(http.MethodGet, key, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive object %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Delete удаляет объект: DELETE /<key>. Отсутствующий объект не считается ошибкой.
+func (s *S3ObjectStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+		return s3ResponseError(http.MethodDelete, key, resp)
+	}
+	return nil
+}
+
+// do выполняет подписанный запрос к объекту
+func (s *S3ObjectStorage) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	objectPath := s3EscapePath(strings.TrimLeft(s.config.Prefix+key, "/"))
+	target := s.endpoint + "/" + s3EscapePath(s.config.Bucket) + "/" + objectPath
+	if !s.config.PathStyle {
+		scheme, host, _ := strings.Cut(s.endpoint, "://")
+		target = scheme + "://" + s.config.Bucket + "." + host + "/" + objectPath
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	req.ContentLength = int64(len(body))
+
+	credentials, err := s.credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3 credentials: %w", err)
+	}
+	signS3Request(req, sha256Hex(body), credentials, s.config.Region, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// s3ResponseError формирует ошибку по ответу S3 с кодом ошибки
+func s3ResponseError(method, key string, resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("s3 %s %s: status %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// signS3Request подписывает запрос AWS Signature Version 4. Подписываются заголовок Host
+// и все заголовки запроса; x-amz-date, x-amz-content-sha256 и x-amz-security-token
+// устанавливаются здесь.
+func signS3Request(req *http.Request, payloadHash string, credentials S3Credentials, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3EscapePath кодирует путь объекта по правилам URI encoding S3 (разделители "/" сохраняются)
+func s3EscapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,553 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// ArchiveConfig конфигурация архивации потоков событий
+type ArchiveConfig struct {
+	// MinAge возраст последнего события, после которого поток архивируется
+	MinAge time.Duration
+	// KeyPrefix префикс ключей объектов архива
+	KeyPrefix string
+	// BatchSize количество агрегатов, запрашиваемых у хранилища за одну страницу Run
+	BatchSize int
+	// Interval период фоновой архивации (Start)
+	Interval time.Duration
+}
+
+// DefaultArchiveConfig возвращает конфигурацию по умолчанию: потоки без событий
+// за 90 дней, ключи "events/...", страницы по 100 агрегатов, проход раз в сутки
+func DefaultArchiveConfig() ArchiveConfig {
+	return ArchiveConfig{
+		MinAge:    90 * 24 * time.Hour,
+		KeyPrefix: "events",
+		BatchSize: 100,
+		Interval:  24 * time.Hour,
+	}
+}
+
+// ArchiveRunResult результат прохода архивации
+type ArchiveRunResult struct {
+	Streams int
+	Events  int64
+	Bytes   int64
+}
+
+// StreamArchiver переносит начало потоков давно не изменявшихся агрегатов в объектное хранилище.
+//
+// Архивируются события до версии снапшота агрегата: локально остаются снапшот, указатель
+// на сегмент (ArchiveIndex) и последнее событие потока, по которому хранилище продолжает
+// проверять expected version при записи. Порядок операций (объект, указатель, удаление)
+// делает архивацию безопасной к сбоям: повторный проход перезаписывает тот же объект,
+// а события, оставшиеся локально, имеют приоритет при чтении через ArchivedEventStore.
+type StreamArchiver struct {
+	store     EventStore
+	truncator EventStreamTruncator
+	snapshots SnapshotStore
+	storage   ObjectStorage
+	index     ArchiveIndex
+	format    ArchiveFormat
+	config    ArchiveConfig
+
+	mu     sync.Mutex
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewStreamArchiver создает архиватор. store должен поддерживать EventStreamTruncator,
+// snapshots обязателен: без снапшота агрегат пришлось бы восстанавливать из архива.
+func NewStreamArchiver(store EventStore, snapshots SnapshotStore, storage ObjectStorage, index ArchiveIndex, config ArchiveConfig) (*StreamArchiver, error) {
+	truncator, ok := store.(EventStreamTruncator)
+	if !ok {
+		return nil, fmt.Errorf("event store %T does not support stream truncation", store)
+	}
+	if snapshots == nil {
+		return nil, fmt.Errorf("snapshot store is required for archiving")
+	}
+	if storage == nil || index == nil {
+		return nil, fmt.Errorf("object storage and archive index are required")
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "events"
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+
+	return &StreamArchiver{
+		store:     store,
+		truncator: truncator,
+		snapshots: snapshots,
+		storage:   storage,
+		index:     index,
+		format:    NewJSONLArchiveFormat(nil),
+		config:    config,
+	}, nil
+}
+
+// WithFormat задает формат сегментов архива (по умолчанию JSONL + gzip).
+// При использовании upcaster'ов передайте формат с десериализатором хранилища, чтобы
+// в архиве сохранялась актуальная версия схемы событий.
+func (a *StreamArchiver) WithFormat(format ArchiveFormat) *StreamArchiver {
+	a.format = format
+	return a
+}
+
+// ArchiveStream архивирует поток агрегата, если он удовлетворяет условиям архивации.
+// Возвращает nil сегмент, если архивировать нечего (поток свежий, нет снапшота,
+// локально осталось только последнее событие).
+func (a *StreamArchiver) ArchiveStream(ctx context.Context, aggregateID string) (*ArchiveSegment, error) {
+	stored, err := a.store.GetEvents(ctx, aggregateID, 0)
+	if err != nil {
+		if errors.Is(err, ErrStreamNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load stream %s: %w", aggregateID, err)
+	}
+	if len(stored) < 2 {
+		return nil, nil
+	}
+
+	last := stored[len(stored)-1]
+	if last.OccurredAt.After(time.Now().Add(-a.config.MinAge)) {
+		return nil, nil
+	}
+
+	snapshot, err := a.snapshots.GetSnapshot(ctx, aggregateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot for %s: %w", aggregateID, err)
+	}
+	if snapshot == nil {
+		return nil, nil
+	}
+
+	// Последнее событие остается локально, остальное - до версии снапшота
+	upTo := min(last.Version-1, snapshot.Version)
+	var archived []StoredEvent
+	for _, event := range stored {
+		if event.Version <= upTo {
+			archived = append(archived, event)
+		}
+	}
+	if len(archived) == 0 {
+		return nil, nil
+	}
+
+	data, err := a.format.Encode(archived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode archive segment for %s: %w", aggregateID, err)
+	}
+
+	segment := ArchiveSegment{
+		AggregateID:  aggregateID,
+		Format:       a.format.Name(),
+		FromVersion:  archived[0].Version,
+		ToVersion:    archived[len(archived)-1].Version,
+		FromPosition: archived[0].Position,
+		ToPosition:   archived[len(archived)-1].Position,
+		EventCount:   int64(len(archived)),
+		Size:         int64(len(data)),
+		Checksum:     archiveChecksum(data),
+		ArchivedAt:   time.Now(),
+	}
+	segment.Key = a.segmentKey(ctx, segment)
+
+	if err := a.storage.Put(ctx, segment.Key, data); err != nil {
+		return nil, fmt.Errorf("failed to upload archive segment %s: %w", segment.Key, err)
+	}
+	if err := a.index.AddSegment(ctx, segment); err != nil {
+		return nil, fmt.Errorf("failed to save archive pointer for %s: %w", aggregateID, err)
+	}
+	if err := a.truncator.TruncateStream(ctx, aggregateID, segment.ToVersion+1); err != nil {
+		return nil, fmt.Errorf("failed to truncate stream %s: %w", aggregateID, err)
+	}
+
+	return &segment, nil
+}
+
+// Run архивирует все потоки, последнее событие которых старше MinAge.
+// Хранилище событий должно поддерживать StaleStreamLister.
+func (a *StreamArchiver) Run(ctx context.Context) (ArchiveRunResult, error) {
+	var result ArchiveRunResult
+
+	lister, ok := a.store.(StaleStreamLister)
+	if !ok {
+		return result, fmt.Errorf("event store %T does not support listing stale streams", a.store)
+	}
+
+	before := time.Now().Add(-a.config.MinAge)
+	after := ""
+	for {
+		aggregateIDs, err := lister.ListStaleStreams(ctx, before, after, a.config.BatchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to list stale streams: %w", err)
+		}
+
+		for _, aggregateID := range aggregateIDs {
+			segment, err := a.ArchiveStream(ctx, aggregateID)
+			if err != nil {
+				return result, err
+			}
+			if segment != nil {
+				result.Streams++
+				result.Events += segment.EventCount
+				result.Bytes += segment.Size
+			}
+		}
+
+		if len(aggregateIDs) < a.config.BatchSize {
+			return result, nil
+		}
+		after = aggregateIDs[len(aggregateIDs)-1]
+	}
+}
+
+// Start запускает фоновую архивацию с периодом ArchiveConfig.Interval
+func (a *StreamArchiver) Start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stopCh != nil {
+		return fmt.Errorf("archiver already started")
+	}
+	if a.config.Interval <= 0 {
+		return fmt.Errorf("archive interval must be positive")
+	}
+
+	stopCh := make(chan struct{})
+	a.stopCh = stopCh
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				// Необработанные потоки будут архивированы на следующем проходе
+				_, _ = a.Run(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop останавливает фоновую архивацию и ждет завершения текущего прохода
+func (a *StreamArchiver) Stop() {
+	a.mu.Lock()
+	stopCh := a.stopCh
+	a.stopCh = nil
+	a.mu.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+	}
+	a.wg.Wait()
+}
+
+// segmentKey формирует ключ объекта: <prefix>/[<tenant>/]<aggregate>/<from>-<to>.<ext>
+func (a *StreamArchiver) segmentKey(ctx context.Context, segment ArchiveSegment) string {
+	prefix := a.config.KeyPrefix
+	if tenantID := TenantFromContext(ctx); tenantID != "" {
+		prefix += "/" + tenantID
+	}
+	return fmt.Sprintf("%s/%s/%020d-%020d.%s", prefix, segment.AggregateID, segment.FromVersion, segment.ToVersion, a.format.Extension())
+}
+
+// ArchivedEventStore EventStore, прозрачно дочитывающий из архива события,
+// перенесенные StreamArchiver в объектное хранилище.
+//
+// Все методы чтения объединяют сегменты архива с локальными событиями, поэтому
+// проекции и ReplayService, перестраиваемые после архивации, должны читать через
+// ArchivedEventStore: локальное хранилище после TruncateStream не содержит начала потоков.
+type ArchivedEventStore struct {
+	store   EventStore
+	storage ObjectStorage
+	index   ArchiveIndex
+
+	mu      sync.RWMutex
+	formats map[string]ArchiveFormat
+}
+
+// NewArchivedEventStore создает EventStore с чтением из архива.
+// deserializer используется форматом JSONL для восстановления типов событий.
+func NewArchivedEventStore(store EventStore, storage ObjectStorage, index ArchiveIndex, deserializer EventDeserializer) *ArchivedEventStore {
+	jsonl := NewJSONLArchiveFormat(deserializer)
+	return &ArchivedEventStore{
+		store:   store,
+		storage: storage,
+		index:   index,
+		formats: map[string]ArchiveFormat{jsonl.Name(): jsonl},
+	}
+}
+
+// WithFormat регистрирует формат сегментов архива для чтения
+func (s *ArchivedEventStore) WithFormat(format ArchiveFormat) *ArchivedEventStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.formats[format.Name()] = format
+	return s
+}
+
+// AppendEvents добавляет события в локальное хранилище
+func (s *ArchivedEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, events []events.Event) error {
+	return s.store.AppendEvents(ctx, aggregateID, expectedVersion, events)
+}
+
+// GetEvents возвращает события агрегата, дочитывая архивированную часть потока
+func (s *ArchivedEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	local, localErr := s.store.GetEvents(ctx, aggregateID, fromVersion)
+	if localErr != nil && !errors.Is(localErr, ErrStreamNotFound) {
+		return nil, localErr
+	}
+
+	archived, err := s.readArchived(ctx, aggregateID, fromVersion, local, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return local, localErr
+	}
+	return append(archived, local...), nil
+}
+
+// GetEventsPage возвращает страницу событий агрегата, дочитывая архивированную часть потока
+// (реализация EventPageReader). Сегменты архива читаются, только если страница начинается
+// до первого локального события.
+func (s *ArchivedEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	local, localErr := GetEventsPage(ctx, s.store, aggregateID, fromVersion, limit)
+	if localErr != nil && !errors.Is(localErr, ErrStreamNotFound) {
+		return nil, localErr
+	}
+	// Версии потока начинаются с 1: страница, начинающаяся с fromVersion, целиком локальная
+	if len(local) > 0 && local[0].Version <= max(fromVersion, 1) {
+		return local, nil
+	}
+
+	archived, err := s.readArchived(ctx, aggregateID, fromVersion, local, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return local, localErr
+	}
+	result := append(archived, local...)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// readArchived читает из сегментов архива события агрегата с версии fromVersion до первого
+// локального события (не более limit, 0 - без ограничения)
+func (s *ArchivedEventStore) readArchived(ctx context.Context, aggregateID string, fromVersion int64, local []StoredEvent, limit int) ([]StoredEvent, error) {
+	segments, err := s.index.GetSegments(ctx, aggregateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archive pointers for %s: %w", aggregateID, err)
+	}
+
+	// Локальные события имеют приоритет (сбой между загрузкой сегмента и удалением событий)
+	localFrom := int64(-1)
+	if len(local) > 0 {
+		localFrom = local[0].Version
+	}
+
+	var result []StoredEvent
+	for _, segment := range segments {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		if segment.ToVersion < fromVersion || (localFrom >= 0 && segment.FromVersion >= localFrom) {
+			continue
+		}
+
+		archived, err := s.readSegment(ctx, segment)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range archived {
+			if event.Version < fromVersion || (localFrom >= 0 && event.Version >= localFrom) {
+				continue
+			}
+			if len(result) > 0 && event.Version <= result[len(result)-1].Version {
+				continue
+			}
+			result = append(result, event)
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// GetEventsByType возвращает события определенного типа, дочитывая их из всех сегментов архива
+func (s *ArchivedEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	local, err := s.store.GetEventsByType(ctx, eventType, fromTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := s.index.ListSegments(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive segments: %w", err)
+	}
+	if len(segments) == 0 {
+		return local, nil
+	}
+
+	// Глобальная позиция однозначно определяет событие; локальная копия имеет приоритет
+	seen := make(map[int64]struct{}, len(local))
+	for _, event := range local {
+		seen[event.Position] = struct{}{}
+	}
+	result := local
+	for _, segment := range segments {
+		archived, err := s.readSegment(ctx, segment)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range archived {
+			if event.EventType != eventType || !event.OccurredAt.After(fromTimestamp) {
+				continue
+			}
+			if _, ok := seen[event.Position]; ok {
+				continue
+			}
+			seen[event.Position] = struct{}{}
+			result = append(result, event)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Position < result[j].Position
+	})
+	return result, nil
+}
+
+// GetAllEvents возвращает события начиная с позиции, дочитывая архивированные события в
+// порядке глобальной позиции. Сегменты загружаются по мере продвижения по локальному логу;
+// при ошибке чтения сегмента канал закрывается, не пропуская архивированные события.
+func (s *ArchivedEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	segments, err := s.index.ListSegments(ctx, fromPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive segments: %w", err)
+	}
+
+	local, err := s.store.GetAllEvents(ctx, fromPosition)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return local, nil
+	}
+
+	ch := make(chan StoredEvent, 100)
+	go func() {
+		defer close(ch)
+
+		var pending []StoredEvent
+		// loadUpTo загружает сегменты, начинающиеся не позже position
+		loadUpTo := func(position int64, all bool) bool {
+			loaded := false
+			for len(segments) > 0 && (all || segments[0].FromPosition <= position) {
+				archived, err := s.readSegment(ctx, segments[0])
+				if err != nil {
+					return false
+				}
+				segments = segments[1:]
+				for _, event := range archived {
+					if event.Position >= fromPosition {
+						pending = append(pending, event)
+						loaded = true
+					}
+				}
+			}
+			if loaded {
+				sort.SliceStable(pending, func(i, j int) bool {
+					return pending[i].Position < pending[j].Position
+				})
+			}
+			return true
+		}
+		send := func(event StoredEvent) bool {
+			select {
+			case ch <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for event := range local {
+			if !loadUpTo(event.Position, false) {
+				return
+			}
+			for len(pending) > 0 && pending[0].Position <= event.Position {
+				// Локальная копия события имеет приоритет (сбой до удаления событий потока)
+				if pending[0].Position < event.Position && !send(pending[0]) {
+					return
+				}
+				pending = pending[1:]
+			}
+			if !send(event) {
+				return
+			}
+		}
+
+		if !loadUpTo(0, true) {
+			return
+		}
+		for _, event := range pending {
+			if !send(event) {
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// ListAggregates возвращает страницу агрегатов локального хранилища.
+// Архивированная часть потока не учитывается в EventCount и CreatedAt.
+func (s *ArchivedEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	return ListAggregates(ctx, s.store, filter)
+}
+
+// readSegment загружает и проверяет сегмент архива
+func (s *ArchivedEventStore) readSegment(ctx context.Context, segment ArchiveSegment) ([]StoredEvent, error) {
+	s.mu.RLock()
+	format, ok := s.formats[segment.Format]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown archive format %q for segment %s", segment.Format, segment.Key)
+	}
+
+	data, err := s.storage.Get(ctx, segment.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download archive segment %s: %w", segment.Key, err)
+	}
+	if segment.Checksum != "" && archiveChecksum(data) != segment.Checksum {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveCorrupted, segment.Key)
+	}
+
+	archived, err := format.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode archive segment %s: %w", segment.Key, err)
+	}
+	return archived, nil
+}
@@ -0,0 +1,135 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/google/uuid"
+)
+
+// ErrBackfillNotSupported проекция не поддерживает загрузку данных в обход потока событий
+var ErrBackfillNotSupported = errors.New("projection does not support backfill")
+
+// Provenance происхождение записи read model, загруженной backfill из внешней системы.
+// Проекция сохраняет его вместе с данными, чтобы отличать загруженные записи от построенных по событиям.
+type Provenance struct {
+	// Source внешняя система (например, legacy-crm)
+	Source string
+	// SourceID идентификатор записи во внешней системе
+	SourceID string
+	// BackfillID идентификатор запуска backfill
+	BackfillID string
+	// ImportedAt время загрузки
+	ImportedAt time.Time
+}
+
+// BackfillRecord запись исторических данных для загрузки в read model
+type BackfillRecord struct {
+	// Key ключ записи read model (обычно ID агрегата)
+	Key string
+	// Data данные записи
+	Data map[string]interface{}
+	// Provenance происхождение записи; пустые поля заполняются из BackfillOptions
+	Provenance Provenance
+}
+
+// BackfillProjection проекция, read model которой может быть заполнена историческими
+// данными напрямую, без потока событий (например, до появления истории событий)
+type BackfillProjection interface {
+	Projection
+	Backfill(ctx context.Context, record BackfillRecord) error
+}
+
+// BackfillProgress прогресс backfill
+type BackfillProgress struct {
+	BackfillID      string
+	RecordsImported int64
+}
+
+// BackfillOptions настройки backfill
+type BackfillOptions struct {
+	// BackfillID идентификатор запуска (по умолчанию генерируется)
+	BackfillID string
+	// Source внешняя система для записей без Provenance.Source
+	Source string
+	// CheckpointPosition позиция, до которой события считаются покрытыми загруженными данными.
+	// Если больше 0, после backfill checkpoint проекции устанавливается на эту позицию,
+	// и проекция продолжит обработку только более поздних событий.
+	CheckpointPosition int64
+	// OnProgress вызывается каждые ProgressInterval записей и по завершении
+	OnProgress func(progress BackfillProgress)
+	// ProgressInterval интервал вызова OnProgress в записях (по умолчанию 1000)
+	ProgressInterval int
+}
+
+// Backfill загружает записи из source в read model проекции в обход потока событий.
+// Каждая запись помечается Provenance. Загрузка завершается, когда source закрыт;
+// при ошибке возвращается прогресс на момент последней загруженной записи.
+func (m *ProjectionManager) Backfill(ctx context.Context, projectionName string, source <-chan BackfillRecord, opts BackfillOptions) (BackfillProgress, error) {
+	m.mu.RLock()
+	projection, exists := m.projections[projectionName]
+	m.mu.RUnlock()
+
+	if !exists {
+		return BackfillProgress{}, fmt.Errorf("projection %s not found", projectionName)
+	}
+	backfillable, ok := projection.(BackfillProjection)
+	if !ok {
+		return BackfillProgress{}, fmt.Errorf("%w: %s", ErrBackfillNotSupported, projectionName)
+	}
+
+	if opts.BackfillID == "" {
+		opts.BackfillID = uuid.New().String()
+	}
+	if opts.ProgressInterval <= 0 {
+		opts.ProgressInterval = 1000
+	}
+
+	progress := BackfillProgress{BackfillID: opts.BackfillID}
+	reportProgress := func() {
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return progress, ctx.Err()
+		case record, ok := <-source:
+			if !ok {
+				if opts.CheckpointPosition > 0 {
+					if err := m.ResetCheckpoint(ctx, projectionName, opts.CheckpointPosition); err != nil {
+						return progress, fmt.Errorf("failed to save checkpoint: %w", err)
+					}
+				}
+				reportProgress()
+				return progress, nil
+			}
+
+			if record.Provenance.Source == "" {
+				record.Provenance.Source = opts.Source
+			}
+			if record.Provenance.SourceID == "" {
+				record.Provenance.SourceID = record.Key
+			}
+			record.Provenance.BackfillID = opts.BackfillID
+			record.Provenance.ImportedAt = time.Now()
+
+			err := core.SafeCall(func() error {
+				return backfillable.Backfill(ctx, record)
+			})
+			if err != nil {
+				return progress, fmt.Errorf("failed to backfill record %s: %w", record.Key, err)
+			}
+
+			progress.RecordsImported++
+			if progress.RecordsImported%int64(opts.ProgressInterval) == 0 {
+				reportProgress()
+			}
+		}
+	}
+}
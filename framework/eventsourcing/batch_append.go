@@ -0,0 +1,180 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// EventBatch события одного потока агрегата для пакетной записи
+type EventBatch struct {
+	AggregateID     string
+	ExpectedVersion int64
+	Events          []events.Event
+}
+
+// BatchAppender реализуется хранилищами, поддерживающими пакетную запись событий
+// нескольких агрегатов (например, через COPY в PostgreSQL).
+// Пакет записывается атомарно: при конфликте версий любого потока не записывается ни одно событие.
+type BatchAppender interface {
+	AppendEventsBatch(ctx context.Context, batches []EventBatch) error
+}
+
+// AppendEventsBatch записывает пакет событий. Если хранилище не реализует BatchAppender,
+// события записываются последовательно через AppendEvents без гарантии атомарности пакета.
+func AppendEventsBatch(ctx context.Context, store EventStore, batches []EventBatch) error {
+	if appender, ok := store.(BatchAppender); ok {
+		return appender.AppendEventsBatch(ctx, batches)
+	}
+
+	for _, batch := range batches {
+		if len(batch.Events) == 0 {
+			continue
+		}
+		if err := store.AppendEvents(ctx, batch.AggregateID, batch.ExpectedVersion, batch.Events); err != nil {
+			return fmt.Errorf("failed to append events for aggregate %s: %w", batch.AggregateID, err)
+		}
+	}
+	return nil
+}
+
+// checkBatchVersions проверяет ожидаемые версии пакета относительно текущих версий потоков.
+// Несколько записей одного агрегата в пакете должны следовать друг за другом по версиям.
+// currentVersions дополняется версиями после записи пакета.
+func checkBatchVersions(batches []EventBatch, currentVersions map[string]int64) error {
+	for _, batch := range batches {
+		if len(batch.Events) == 0 {
+			continue
+		}
+		current := currentVersions[batch.AggregateID]
+		if batch.ExpectedVersion != current {
+			return fmt.Errorf("%w: aggregate %s expected %d, got %d", ErrConcurrencyConflict, batch.AggregateID, batch.ExpectedVersion, current)
+		}
+		currentVersions[batch.AggregateID] = current + int64(len(batch.Events))
+	}
+	return nil
+}
+
+// ImportRecord событие из внешнего источника для импорта в event store
+type ImportRecord struct {
+	AggregateID string
+	Event       events.Event
+}
+
+// ImportProgress прогресс импорта событий
+type ImportProgress struct {
+	EventsImported int64
+	BatchesWritten int64
+}
+
+// ImportOptions настройки импорта событий
+type ImportOptions struct {
+	// BatchSize количество событий в одном пакете записи (по умолчанию 1000)
+	BatchSize int
+	// StartVersion возвращает текущую версию потока агрегата при первой встрече агрегата.
+	// Nil - потоки считаются пустыми (импорт в новый event store).
+	StartVersion func(ctx context.Context, aggregateID string) (int64, error)
+	// OnProgress вызывается после записи каждого пакета
+	OnProgress func(progress ImportProgress)
+}
+
+// DefaultImportOptions возвращает настройки импорта по умолчанию
+func DefaultImportOptions() ImportOptions {
+	return ImportOptions{
+		BatchSize: 1000,
+	}
+}
+
+// StreamVersionResolver возвращает ImportOptions.StartVersion, определяющий текущую версию
+// потока через GetEvents. Позволяет продолжить прерванный импорт в непустой event store.
+func StreamVersionResolver(store EventStore) func(ctx context.Context, aggregateID string) (int64, error) {
+	return func(ctx context.Context, aggregateID string) (int64, error) {
+		stored, err := store.GetEvents(ctx, aggregateID, 0)
+		if errors.Is(err, ErrStreamNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(stored) == 0 {
+			return 0, nil
+		}
+		return stored[len(stored)-1].Version, nil
+	}
+}
+
+// ImportEvents читает события из source и записывает их в store пакетами по BatchSize событий.
+// Используется для миграции исторических данных: события одного агрегата должны поступать
+// в порядке возникновения, версии назначаются последовательно. Импорт завершается, когда
+// source закрыт; при ошибке возвращается прогресс на момент последнего записанного пакета.
+func ImportEvents(ctx context.Context, store EventStore, source <-chan ImportRecord, options ImportOptions) (ImportProgress, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultImportOptions().BatchSize
+	}
+
+	var progress ImportProgress
+	versions := make(map[string]int64)
+	batches := make([]EventBatch, 0)
+	pending := 0
+
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+		if err := AppendEventsBatch(ctx, store, batches); err != nil {
+			return err
+		}
+		progress.EventsImported += int64(pending)
+		progress.BatchesWritten++
+		if options.OnProgress != nil {
+			options.OnProgress(progress)
+		}
+		batches = batches[:0]
+		pending = 0
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return progress, ctx.Err()
+		case record, ok := <-source:
+			if !ok {
+				if err := flush(); err != nil {
+					return progress, fmt.Errorf("failed to write batch: %w", err)
+				}
+				return progress, nil
+			}
+
+			version, seen := versions[record.AggregateID]
+			if !seen && options.StartVersion != nil {
+				startVersion, err := options.StartVersion(ctx, record.AggregateID)
+				if err != nil {
+					return progress, fmt.Errorf("failed to get version of aggregate %s: %w", record.AggregateID, err)
+				}
+				version = startVersion
+			}
+			versions[record.AggregateID] = version + 1
+
+			// Подряд идущие события одного агрегата объединяются в одну запись пакета
+			if last := len(batches) - 1; last >= 0 && batches[last].AggregateID == record.AggregateID {
+				batches[last].Events = append(batches[last].Events, record.Event)
+			} else {
+				batches = append(batches, EventBatch{
+					AggregateID:     record.AggregateID,
+					ExpectedVersion: version,
+					Events:          []events.Event{record.Event},
+				})
+			}
+			pending++
+
+			if pending >= options.BatchSize {
+				if err := flush(); err != nil {
+					return progress, fmt.Errorf("failed to write batch: %w", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,537 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BatchExportFormat формат файла пакетной выгрузки (CSV, XML). JSONLArchiveFormat
+// также реализует интерфейс и может использоваться для выгрузки.
+type BatchExportFormat interface {
+	// Name возвращает имя формата, сохраняемое в манифесте
+	Name() string
+	// Extension возвращает расширение файлов формата
+	Extension() string
+	// Encode сериализует события пакета
+	Encode(events []StoredEvent) ([]byte, error)
+}
+
+// BatchColumn колонка CSV выгрузки
+type BatchColumn struct {
+	Header string
+	Value  func(event StoredEvent) (string, error)
+}
+
+// DefaultBatchColumns возвращает колонки CSV выгрузки по умолчанию
+func DefaultBatchColumns() []BatchColumn {
+	return []BatchColumn{
+		{Header: "position", Value: func(e StoredEvent) (string, error) { return strconv.FormatInt(e.Position, 10), nil }},
+		{Header: "event_id", Value: func(e StoredEvent) (string, error) { return e.ID, nil }},
+		{Header: "event_type", Value: func(e StoredEvent) (string, error) { return e.EventType, nil }},
+		{Header: "aggregate_id", Value: func(e StoredEvent) (string, error) { return e.AggregateID, nil }},
+		{Header: "aggregate_type", Value: func(e StoredEvent) (string, error) { return e.AggregateType, nil }},
+		{Header: "version", Value: func(e StoredEvent) (string, error) { return strconv.FormatInt(e.Version, 10), nil }},
+		{Header: "occurred_at", Value: func(e StoredEvent) (string, error) { return e.OccurredAt.UTC().Format(time.RFC3339Nano), nil }},
+		{Header: "event_data", Value: batchEventData},
+	}
+}
+
+// batchEventData возвращает данные события в JSON
+func batchEventData(event StoredEvent) (string, error) {
+	data, err := json.Marshal(event.EventData)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event %s: %w", event.ID, err)
+	}
+	return string(data), nil
+}
+
+// CSVBatchFormat формат выгрузки CSV: строка заголовков и строка на событие
+type CSVBatchFormat struct {
+	columns []BatchColumn
+	comma   rune
+}
+
+// NewCSVBatchFormat создает формат CSV. Без колонок используются DefaultBatchColumns.
+func NewCSVBatchFormat(columns ...BatchColumn) *CSVBatchFormat {
+	if len(columns) == 0 {
+		columns = DefaultBatchColumns()
+	}
+	return &CSVBatchFormat{columns: columns, comma: ','}
+}
+
+// WithComma задает разделитель полей (например, ';' для партнеров с локалью RU)
+func (f *CSVBatchFormat) WithComma(comma rune) *CSVBatchFormat {
+	f.comma = comma
+	return f
+}
+
+// Name возвращает имя формата
+func (f *CSVBatchFormat) Name() string {
+	return "csv"
+}
+
+// Extension возвращает расширение файлов формата
+func (f *CSVBatchFormat) Extension() string {
+	return "csv"
+}
+
+// Encode сериализует события пакета
+func (f *CSVBatchFormat) Encode(storedEvents []StoredEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = f.comma
+
+	record := make([]string, len(f.columns))
+	for i, column := range f.columns {
+		record[i] = column.Header
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, stored := range storedEvents {
+		for i, column := range f.columns {
+			value, err := column.Value(stored)
+			if err != nil {
+				return nil, err
+			}
+			record[i] = value
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write event %s: %w", stored.ID, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv batch: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// XMLBatchFormat формат выгрузки XML: <batch><event>...</event></batch>
+type XMLBatchFormat struct{}
+
+// NewXMLBatchFormat создает формат XML
+func NewXMLBatchFormat() *XMLBatchFormat {
+	return &XMLBatchFormat{}
+}
+
+// xmlBatch корневой элемент XML выгрузки
+type xmlBatch struct {
+	XMLName xml.Name        `xml:"batch"`
+	Count   int             `xml:"count,attr"`
+	Events  []xmlBatchEvent `xml:"event"`
+}
+
+// xmlBatchEvent событие XML выгрузки
+type xmlBatchEvent struct {
+	ID            string `xml:"id,attr"`
+	Type          string `xml:"type,attr"`
+	Position      int64  `xml:"position"`
+	AggregateID   string `xml:"aggregate_id"`
+	AggregateType string `xml:"aggregate_type"`
+	Version       int64  `xml:"version"`
+	OccurredAt    string `xml:"occurred_at"`
+	Data          string `xml:"data"`
+}
+
+// Name возвращает имя формата
+func (f *XMLBatchFormat) Name() string {
+	return "xml"
+}
+
+// Extension возвращает расширение файлов формата
+func (f *XMLBatchFormat) Extension() string {
+	return "xml"
+}
+
+// Encode сериализует события пакета
+func (f *XMLBatchFormat) Encode(storedEvents []StoredEvent) ([]byte, error) {
+	batch := xmlBatch{Count: len(storedEvents), Events: make([]xmlBatchEvent, 0, len(storedEvents))}
+	for _, stored := range storedEvents {
+		data, err := batchEventData(stored)
+		if err != nil {
+			return nil, err
+		}
+		batch.Events = append(batch.Events, xmlBatchEvent{
+			ID:            stored.ID,
+			Type:          stored.EventType,
+			Position:      stored.Position,
+			AggregateID:   stored.AggregateID,
+			AggregateType: stored.AggregateType,
+			Version:       stored.Version,
+			OccurredAt:    stored.OccurredAt.UTC().Format(time.RFC3339Nano),
+			Data:          data,
+		})
+	}
+
+	data, err := xml.MarshalIndent(batch, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode xml batch: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// BatchManifest манифест выгруженного пакета. Манифест записывается после файла пакета:
+// получатель обрабатывает только файлы с манифестом и сверяет контрольную сумму.
+type BatchManifest struct {
+	BatchID       string    `json:"batch_id"`
+	Exporter      string    `json:"exporter"`
+	Format        string    `json:"format"`
+	DataKey       string    `json:"data_key"`
+	AfterPosition int64     `json:"after_position"`
+	ToPosition    int64     `json:"to_position"`
+	EventCount    int       `json:"event_count"`
+	Checksum      string    `json:"checksum"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BatchExportConfig конфигурация пакетной выгрузки
+type BatchExportConfig struct {
+	// Name имя выгрузки, используется как ключ позиции в CheckpointStore и префикс имен файлов
+	Name string
+	// Prefix префикс ключей файлов в хранилище (каталог SFTP, префикс бакета S3)
+	Prefix string
+	// EventTypes типы выгружаемых событий (пусто - все события)
+	EventTypes []string
+	// Filter дополнительный отбор событий (nil - без отбора)
+	Filter func(event StoredEvent) bool
+	// MaxBatchEvents максимальное количество событий в одном файле
+	MaxBatchEvents int
+	// Interval период выгрузки в фоновом режиме
+	Interval time.Duration
+}
+
+// DefaultBatchExportConfig возвращает конфигурацию выгрузки по умолчанию
+func DefaultBatchExportConfig() BatchExportConfig {
+	return BatchExportConfig{
+		Name:           "batch_export",
+		MaxBatchEvents: 10000,
+		Interval:       time.Hour,
+	}
+}
+
+// BatchExportStatus состояние выгрузки
+type BatchExportStatus struct {
+	Name             string
+	Running          bool
+	ExportedPosition int64
+	BatchesExported  int64
+	EventsExported   int64
+	LastBatchKey     string
+	LastExportedAt   time.Time
+	LastError        string
+}
+
+// BatchExporter периодически выгружает отобранные события глобального лога в файлы
+// пакетов (CSV, XML) в ObjectStorage - SFTP или S3 подключаются приложением через
+// реализацию ObjectStorage. Каждый пакет выгружается ровно один раз: ключи файла и
+// манифеста определяются позицией, после которой начинается пакет, поэтому повторная
+// выгрузка после сбоя перезаписывает файл без манифеста или пропускает пакет с манифестом.
+type BatchExporter struct {
+	eventStore      EventStore
+	storage         ObjectStorage
+	format          BatchExportFormat
+	checkpointStore CheckpointStore
+	config          BatchExportConfig
+	eventTypes      map[string]bool
+
+	mu     sync.RWMutex
+	status BatchExportStatus
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewBatchExporter создает новый BatchExporter
+func NewBatchExporter(eventStore EventStore, storage ObjectStorage, format BatchExportFormat, checkpointStore CheckpointStore, config BatchExportConfig) *BatchExporter {
+	defaults := DefaultBatchExportConfig()
+	if config.Name == "" {
+		config.Name = defaults.Name
+	}
+	if config.MaxBatchEvents <= 0 {
+		config.MaxBatchEvents = defaults.MaxBatchEvents
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+
+	var eventTypes map[string]bool
+	if len(config.EventTypes) > 0 {
+		eventTypes = make(map[string]bool, len(config.EventTypes))
+		for _, eventType := range config.EventTypes {
+			eventTypes[eventType] = true
+		}
+	}
+
+	return &BatchExporter{
+		eventStore:      eventStore,
+		storage:         storage,
+		format:          format,
+		checkpointStore: checkpointStore,
+		config:          config,
+		eventTypes:      eventTypes,
+		status:          BatchExportStatus{Name: config.Name},
+	}
+}
+
+// ExportOnce выгружает все отобранные события, появившиеся после сохраненной позиции,
+// пакетами до MaxBatchEvents событий. Возвращает количество записанных пакетов.
+func (e *BatchExporter) ExportOnce(ctx context.Context) (int, error) {
+	exported := 0
+	for {
+		position, err := e.checkpointStore.GetCheckpoint(ctx, e.config.Name)
+		if err != nil {
+			position = 0
+		}
+
+		// Пакет с манифестом уже выгружен до сбоя: продвигаем позицию без повторной записи
+		manifest, err := e.getManifest(ctx, position)
+		if err == nil {
+			if err := e.saveCheckpoint(ctx, manifest.ToPosition); err != nil {
+				return exported, err
+			}
+			continue
+		}
+		if !errors.Is(err, ErrArchiveObjectNotFound) {
+			return exported, e.fail(err)
+		}
+
+		batch, toPosition, full, err := e.collect(ctx, position)
+		if err != nil {
+			return exported, e.fail(err)
+		}
+		if len(batch) == 0 {
+			if toPosition > position {
+				return exported, e.saveCheckpoint(ctx, toPosition)
+			}
+			return exported, nil
+		}
+
+		if err := e.writeBatch(ctx, position, toPosition, batch); err != nil {
+			return exported, e.fail(err)
+		}
+		exported++
+		if !full {
+			return exported, nil
+		}
+	}
+}
+
+// collect читает события после позиции до заполнения пакета или конца лога.
+// Возвращает отобранные события, позицию последнего прочитанного события и признак
+// заполненного пакета.
+func (e *BatchExporter) collect(ctx context.Context, position int64) ([]StoredEvent, int64, bool, error) {
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// GetAllEvents возвращает события включительно, начинаем со следующей позиции
+	eventsChan, err := e.eventStore.GetAllEvents(readCtx, position+1)
+	if err != nil {
+		return nil, position, false, fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	var batch []StoredEvent
+	toPosition := position
+	for stored := range eventsChan {
+		toPosition = stored.Position
+		if !e.matches(stored) {
+			continue
+		}
+		batch = append(batch, stored)
+		if len(batch) >= e.config.MaxBatchEvents {
+			return batch, toPosition, true, nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, position, false, err
+	}
+	return batch, toPosition, false, nil
+}
+
+// matches проверяет, выгружается ли событие
+func (e *BatchExporter) matches(stored StoredEvent) bool {
+	if e.eventTypes != nil && !e.eventTypes[stored.EventType] {
+		return false
+	}
+	return e.config.Filter == nil || e.config.Filter(stored)
+}
+
+// writeBatch записывает файл пакета, затем манифест, затем сохраняет позицию
+func (e *BatchExporter) writeBatch(ctx context.Context, afterPosition, toPosition int64, batch []StoredEvent) error {
+	data, err := e.format.Encode(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	batchID := e.batchID(afterPosition)
+	dataKey := e.config.Prefix + batchID + "." + e.format.Extension()
+	if err := e.storage.Put(ctx, dataKey, data); err != nil {
+		return fmt.Errorf("failed to write batch %s: %w", dataKey, err)
+	}
+
+	checksum := sha256.Sum256(data)
+	manifest := BatchManifest{
+		BatchID:       batchID,
+		Exporter:      e.config.Name,
+		Format:        e.format.Name(),
+		DataKey:       dataKey,
+		AfterPosition: afterPosition,
+		ToPosition:    toPosition,
+		EventCount:    len(batch),
+		Checksum:      hex.EncodeToString(checksum[:]),
+		CreatedAt:     time.Now().UTC(),
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode batch manifest: %w", err)
+	}
+	if err := e.storage.Put(ctx, e.manifestKey(afterPosition), manifestData); err != nil {
+		return fmt.Errorf("failed to write batch manifest %s: %w", batchID, err)
+	}
+
+	if err := e.saveCheckpoint(ctx, toPosition); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.status.BatchesExported++
+	e.status.EventsExported += int64(len(batch))
+	e.status.LastBatchKey = dataKey
+	e.status.LastExportedAt = manifest.CreatedAt
+	e.mu.Unlock()
+	return nil
+}
+
+// getManifest возвращает манифест пакета, начинающегося после позиции
+func (e *BatchExporter) getManifest(ctx context.Context, afterPosition int64) (*BatchManifest, error) {
+	data, err := e.storage.Get(ctx, e.manifestKey(afterPosition))
+	if err != nil {
+		return nil, err
+	}
+	var manifest BatchManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode batch manifest: %w", err)
+	}
+	if manifest.ToPosition <= afterPosition {
+		return nil, fmt.Errorf("invalid batch manifest %s: to_position %d", manifest.BatchID, manifest.ToPosition)
+	}
+	return &manifest, nil
+}
+
+// saveCheckpoint сохраняет позицию выгрузки
+func (e *BatchExporter) saveCheckpoint(ctx context.Context, position int64) error {
+	if err := e.checkpointStore.SaveCheckpoint(ctx, e.config.Name, position); err != nil {
+		return e.fail(fmt.Errorf("failed to save batch export position: %w", err))
+	}
+	e.mu.Lock()
+	e.status.ExportedPosition = position
+	e.status.LastError = ""
+	e.mu.Unlock()
+	return nil
+}
+
+// batchID возвращает идентификатор пакета, начинающегося после позиции
+func (e *BatchExporter) batchID(afterPosition int64) string {
+	return fmt.Sprintf("%s-%020d", e.config.Name, afterPosition)
+}
+
+// manifestKey возвращает ключ манифеста пакета
+func (e *BatchExporter) manifestKey(afterPosition int64) string {
+	return e.config.Prefix + e.batchID(afterPosition) + ".manifest.json"
+}
+
+// fail сохраняет ошибку в статусе и возвращает ее
+func (e *BatchExporter) fail(err error) error {
+	e.mu.Lock()
+	e.status.LastError = err.Error()
+	e.mu.Unlock()
+	return err
+}
+
+// Start запускает периодическую выгрузку
+func (e *BatchExporter) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.status.Running {
+		return nil
+	}
+	e.status.Running = true
+	e.stopCh = make(chan struct{})
+	e.doneCh = make(chan struct{})
+
+	go e.run(e.stopCh, e.doneCh)
+	return nil
+}
+
+// Stop останавливает периодическую выгрузку
+func (e *BatchExporter) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.status.Running {
+		e.mu.Unlock()
+		return nil
+	}
+	e.status.Running = false
+	close(e.stopCh)
+	doneCh := e.doneCh
+	e.mu.Unlock()
+
+	select {
+	case <-doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRunning проверяет, запущена ли выгрузка
+func (e *BatchExporter) IsRunning() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.status.Running
+}
+
+// Status возвращает состояние выгрузки
+func (e *BatchExporter) Status() BatchExportStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.status
+}
+
+// run периодически выгружает новые события
+func (e *BatchExporter) run(stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer close(doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Ошибки сохраняются в статусе, выгрузка повторяется на следующем тике
+		_, _ = e.ExportOnce(ctx)
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
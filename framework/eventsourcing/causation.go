@@ -0,0 +1,463 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CausationNodeKind тип узла графа причинности
+type CausationNodeKind string
+
+const (
+	// CausationNodeCommand команда, вызвавшая события (восстанавливается по causation ID событий)
+	CausationNodeCommand CausationNodeKind = "command"
+	// CausationNodeEvent сохраненное событие
+	CausationNodeEvent CausationNodeKind = "event"
+	// CausationNodeSaga сага, в рамках которой возникли события и команды
+	CausationNodeSaga CausationNodeKind = "saga"
+)
+
+// CausationProjectionName имя встроенной проекции индекса причинности
+const CausationProjectionName = "causation_graph"
+
+// CausationRecord запись индекса причинности для одного события
+type CausationRecord struct {
+	EventID       string
+	EventType     string
+	AggregateID   string
+	AggregateType string
+	CorrelationID string
+	CausationID   string
+	// SagaID сага, к которой относится событие ("" - событие вне саги)
+	SagaID     string
+	Position   int64
+	OccurredAt time.Time
+}
+
+// NewCausationRecord строит запись индекса из сохраненного события.
+// Correlation и causation ID берутся из метаданных хранилища, а при их отсутствии - из метаданных события.
+// SagaID - ID агрегата для событий саг (AggregateType "saga") или метаданные "saga_id".
+func NewCausationRecord(event StoredEvent) CausationRecord {
+	record := CausationRecord{
+		EventID:       event.ID,
+		EventType:     event.EventType,
+		AggregateID:   event.AggregateID,
+		AggregateType: event.AggregateType,
+		CorrelationID: storedCorrelationID(event),
+		CausationID:   storedCausationID(event),
+		Position:      event.Position,
+		OccurredAt:    event.OccurredAt,
+	}
+	if event.AggregateType == "saga" {
+		record.SagaID = event.AggregateID
+	} else if sagaID, ok := event.Metadata["saga_id"].(string); ok {
+		record.SagaID = sagaID
+	}
+	return record
+}
+
+// storedCausationID извлекает causation ID из сохраненного события
+func storedCausationID(stored StoredEvent) string {
+	if id, ok := stored.Metadata["causation_id"].(string); ok && id != "" {
+		return id
+	}
+	if stored.EventData != nil && stored.EventData.Metadata() != nil {
+		return stored.EventData.Metadata().CausationID()
+	}
+	return ""
+}
+
+// CausationIndex хранилище индекса причинности
+type CausationIndex interface {
+	// Index сохраняет записи (повторное сохранение события заменяет запись)
+	Index(ctx context.Context, records []CausationRecord) error
+	// GetByEventID возвращает запись события (nil, если событие не проиндексировано)
+	GetByEventID(ctx context.Context, eventID string) (*CausationRecord, error)
+	// ListByCorrelationID возвращает записи всех событий с заданным correlation ID
+	ListByCorrelationID(ctx context.Context, correlationID string) ([]CausationRecord, error)
+	// ListByCausationID возвращает записи событий, вызванных событием или командой с заданным ID
+	ListByCausationID(ctx context.Context, causationID string) ([]CausationRecord, error)
+	// Reset удаляет все записи
+	Reset(ctx context.Context) error
+}
+
+// CausationProjection встроенная проекция, поддерживающая CausationIndex
+type CausationProjection struct {
+	index CausationIndex
+}
+
+// NewCausationProjection создает новую CausationProjection
+func NewCausationProjection(index CausationIndex) *CausationProjection {
+	return &CausationProjection{index: index}
+}
+
+// Name возвращает имя проекции
+func (p *CausationProjection) Name() string {
+	return CausationProjectionName
+}
+
+// HandleEvent индексирует событие
+func (p *CausationProjection) HandleEvent(ctx context.Context, event StoredEvent) error {
+	return p.index.Index(ctx, []CausationRecord{NewCausationRecord(event)})
+}
+
+// Reset очищает индекс
+func (p *CausationProjection) Reset(ctx context.Context) error {
+	return p.index.Reset(ctx)
+}
+
+// CausationNode узел дерева причинности
+type CausationNode struct {
+	// ID ID события, команды или саги
+	ID   string
+	Kind CausationNodeKind
+	// Record запись события (nil для команд и саг)
+	Record   *CausationRecord
+	Children []*CausationNode
+}
+
+// CausationTree дерево команд, событий и саг одной цепочки
+type CausationTree struct {
+	CorrelationID string
+	Roots         []*CausationNode
+	// EventCount количество событий в дереве
+	EventCount int
+}
+
+// Find возвращает узел с заданным ID (nil, если узла нет)
+func (t *CausationTree) Find(id string) *CausationNode {
+	var find func(nodes []*CausationNode) *CausationNode
+	find = func(nodes []*CausationNode) *CausationNode {
+		for _, node := range nodes {
+			if node.ID == id {
+				return node
+			}
+			if found := find(node.Children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(t.Roots)
+}
+
+// CausationGraph сервис запросов к графу причинности по индексу CausationProjection
+type CausationGraph struct {
+	index CausationIndex
+}
+
+// NewCausationGraph создает новый CausationGraph
+func NewCausationGraph(index CausationIndex) *CausationGraph {
+	return &CausationGraph{index: index}
+}
+
+// ByCorrelationID возвращает дерево всех событий с заданным correlation ID
+func (g *CausationGraph) ByCorrelationID(ctx context.Context, correlationID string) (*CausationTree, error) {
+	records, err := g.index.ListByCorrelationID(ctx, correlationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events of correlation %s: %w", correlationID, err)
+	}
+	tree := BuildCausationTree(records)
+	tree.CorrelationID = correlationID
+	return tree, nil
+}
+
+// ByEventID возвращает дерево, в которое входит событие: всю цепочку его correlation ID,
+// а для события без correlation ID - само событие и все события, вызванные им.
+func (g *CausationGraph) ByEventID(ctx context.Context, eventID string) (*CausationTree, error) {
+	record, err := g.index.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event %s: %w", eventID, err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("event %s is not indexed", eventID)
+	}
+	if record.CorrelationID != "" {
+		return g.ByCorrelationID(ctx, record.CorrelationID)
+	}
+
+	records := []CausationRecord{*record}
+	visited := map[string]bool{record.EventID: true}
+	for queue := []string{record.EventID}; len(queue) > 0; queue = queue[1:] {
+		children, err := g.index.ListByCausationID(ctx, queue[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events caused by %s: %w", queue[0], err)
+		}
+		for _, child := range children {
+			if visited[child.EventID] {
+				continue
+			}
+			visited[child.EventID] = true
+			records = append(records, child)
+			queue = append(queue, child.EventID)
+		}
+	}
+	return BuildCausationTree(records), nil
+}
+
+// BuildCausationTree строит дерево из записей индекса.
+// Событие становится потомком события, указанного в его causation ID; если causation ID
+// не является событием из записей, он считается командой. Команды и события без причины,
+// относящиеся к саге, становятся потомками узла саги. Потомки упорядочены по времени возникновения.
+func BuildCausationTree(records []CausationRecord) *CausationTree {
+	tree := &CausationTree{EventCount: len(records)}
+
+	events := make(map[string]*CausationNode, len(records))
+	for i := range records {
+		record := &records[i]
+		events[record.EventID] = &CausationNode{ID: record.EventID, Kind: CausationNodeEvent, Record: record}
+	}
+
+	commands := make(map[string]*CausationNode)
+	sagas := make(map[string]*CausationNode)
+	attachToSaga := func(node *CausationNode, sagaID string) {
+		if sagaID == "" {
+			tree.Roots = append(tree.Roots, node)
+			return
+		}
+		saga, ok := sagas[sagaID]
+		if !ok {
+			saga = &CausationNode{ID: sagaID, Kind: CausationNodeSaga}
+			sagas[sagaID] = saga
+			tree.Roots = append(tree.Roots, saga)
+		}
+		saga.Children = append(saga.Children, node)
+	}
+
+	for i := range records {
+		record := &records[i]
+		node := events[record.EventID]
+		switch {
+		case record.CausationID == "" || record.CausationID == record.EventID:
+			attachToSaga(node, record.SagaID)
+		case events[record.CausationID] != nil:
+			parent := events[record.CausationID]
+			parent.Children = append(parent.Children, node)
+		default:
+			command, ok := commands[record.CausationID]
+			if !ok {
+				command = &CausationNode{ID: record.CausationID, Kind: CausationNodeCommand}
+				commands[record.CausationID] = command
+				attachToSaga(command, record.SagaID)
+			}
+			command.Children = append(command.Children, node)
+		}
+	}
+
+	sortCausationNodes(tree.Roots)
+	return tree
+}
+
+// causationNodeTime время узла: время события или самого раннего потомка
+func causationNodeTime(node *CausationNode) (time.Time, int64) {
+	if node.Record != nil {
+		return node.Record.OccurredAt, node.Record.Position
+	}
+	var earliest time.Time
+	var position int64
+	for _, child := range node.Children {
+		childTime, childPosition := causationNodeTime(child)
+		if earliest.IsZero() || childTime.Before(earliest) {
+			earliest, position = childTime, childPosition
+		}
+	}
+	return earliest, position
+}
+
+// sortCausationNodes упорядочивает узлы и их потомков по времени возникновения
+func sortCausationNodes(nodes []*CausationNode) {
+	for _, node := range nodes {
+		sortCausationNodes(node.Children)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		ti, pi := causationNodeTime(nodes[i])
+		tj, pj := causationNodeTime(nodes[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return pi < pj
+	})
+}
+
+// InMemoryCausationIndex in-memory реализация CausationIndex
+type InMemoryCausationIndex struct {
+	mu      sync.RWMutex
+	records map[string]CausationRecord
+}
+
+// NewInMemoryCausationIndex создает новый InMemoryCausationIndex
+func NewInMemoryCausationIndex() *InMemoryCausationIndex {
+	return &InMemoryCausationIndex{records: make(map[string]CausationRecord)}
+}
+
+func (i *InMemoryCausationIndex) Index(ctx context.Context, records []CausationRecord) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, record := range records {
+		i.records[record.EventID] = record
+	}
+	return nil
+}
+
+func (i *InMemoryCausationIndex) GetByEventID(ctx context.Context, eventID string) (*CausationRecord, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	record, ok := i.records[eventID]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (i *InMemoryCausationIndex) ListByCorrelationID(ctx context.Context, correlationID string) ([]CausationRecord, error) {
+	return i.list(func(record CausationRecord) bool { return record.CorrelationID == correlationID }), nil
+}
+
+func (i *InMemoryCausationIndex) ListByCausationID(ctx context.Context, causationID string) ([]CausationRecord, error) {
+	return i.list(func(record CausationRecord) bool { return record.CausationID == causationID }), nil
+}
+
+// list возвращает записи, удовлетворяющие условию, в порядке позиции
+func (i *InMemoryCausationIndex) list(match func(CausationRecord) bool) []CausationRecord {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var result []CausationRecord
+	for _, record := range i.records {
+		if match(record) {
+			result = append(result, record)
+		}
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Position < result[b].Position })
+	return result
+}
+
+func (i *InMemoryCausationIndex) Reset(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.records = make(map[string]CausationRecord)
+	return nil
+}
+
+// PostgresCausationIndex реализация CausationIndex для PostgreSQL (таблица causation_index)
+type PostgresCausationIndex struct {
+	conn *pgx.Conn
+}
+
+// NewPostgresCausationIndex создает новый PostgresCausationIndex
+func NewPostgresCausationIndex(dsn string) (*PostgresCausationIndex, error) {
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	index := &PostgresCausationIndex{conn: conn}
+	if err := index.ensureTable(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure table: %w", err)
+	}
+
+	return index, nil
+}
+
+func (i *PostgresCausationIndex) ensureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS causation_index (
+			event_id VARCHAR(255) PRIMARY KEY,
+			event_type VARCHAR(255) NOT NULL,
+			aggregate_id VARCHAR(255) NOT NULL,
+			aggregate_type VARCHAR(255) NOT NULL DEFAULT '',
+			correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+			causation_id VARCHAR(255) NOT NULL DEFAULT '',
+			saga_id VARCHAR(255) NOT NULL DEFAULT '',
+			position BIGINT NOT NULL,
+			occurred_at TIMESTAMP NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_causation_correlation ON causation_index(correlation_id) WHERE correlation_id <> '';
+		CREATE INDEX IF NOT EXISTS idx_causation_causation ON causation_index(causation_id) WHERE causation_id <> '';
+	`
+	_, err := i.conn.Exec(ctx, query)
+	return err
+}
+
+func (i *PostgresCausationIndex) Index(ctx context.Context, records []CausationRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO causation_index (event_id, event_type, aggregate_id, aggregate_type, correlation_id, causation_id, saga_id, position, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (event_id) DO UPDATE SET
+			event_type = EXCLUDED.event_type,
+			aggregate_id = EXCLUDED.aggregate_id,
+			aggregate_type = EXCLUDED.aggregate_type,
+			correlation_id = EXCLUDED.correlation_id,
+			causation_id = EXCLUDED.causation_id,
+			saga_id = EXCLUDED.saga_id,
+			position = EXCLUDED.position,
+			occurred_at = EXCLUDED.occurred_at
+	`
+	batch := &pgx.Batch{}
+	for _, record := range records {
+		batch.Queue(query, record.EventID, record.EventType, record.AggregateID, record.AggregateType,
+			record.CorrelationID, record.CausationID, record.SagaID, record.Position, record.OccurredAt)
+	}
+	return i.conn.SendBatch(ctx, batch).Close()
+}
+
+const postgresCausationColumns = "event_id, event_type, aggregate_id, aggregate_type, correlation_id, causation_id, saga_id, position, occurred_at"
+
+func (i *PostgresCausationIndex) GetByEventID(ctx context.Context, eventID string) (*CausationRecord, error) {
+	records, err := i.query(ctx, "event_id", eventID)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[0], nil
+}
+
+func (i *PostgresCausationIndex) ListByCorrelationID(ctx context.Context, correlationID string) ([]CausationRecord, error) {
+	return i.query(ctx, "correlation_id", correlationID)
+}
+
+func (i *PostgresCausationIndex) ListByCausationID(ctx context.Context, causationID string) ([]CausationRecord, error) {
+	return i.query(ctx, "causation_id", causationID)
+}
+
+// query возвращает записи с заданным значением колонки в порядке позиции
+func (i *PostgresCausationIndex) query(ctx context.Context, column, value string) ([]CausationRecord, error) {
+	query := fmt.Sprintf("SELECT %s FROM causation_index WHERE %s = $1 ORDER BY position", postgresCausationColumns, column)
+	rows, err := i.conn.Query(ctx, query, value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []CausationRecord
+	for rows.Next() {
+		var record CausationRecord
+		if err := rows.Scan(
+			&record.EventID,
+			&record.EventType,
+			&record.AggregateID,
+			&record.AggregateType,
+			&record.CorrelationID,
+			&record.CausationID,
+			&record.SagaID,
+			&record.Position,
+			&record.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func (i *PostgresCausationIndex) Reset(ctx context.Context) error {
+	_, err := i.conn.Exec(ctx, `TRUNCATE TABLE causation_index`)
+	return err
+}
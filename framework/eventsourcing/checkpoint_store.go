@@ -4,9 +4,12 @@ package eventsourcing
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/akriventsev/potter/framework/migrations"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -20,9 +23,11 @@ type CheckpointStore interface {
 	ListCheckpoints(ctx context.Context) (map[string]int64, error)
 }
 
-// PostgresCheckpointStore реализация CheckpointStore для PostgreSQL
+// PostgresCheckpointStore реализация CheckpointStore для PostgreSQL.
+// Реализует TransactionalCheckpointStore: checkpoint сохраняется в транзакции обработчика проекции.
 type PostgresCheckpointStore struct {
 	conn *pgx.Conn
+	mu   sync.Mutex // pgx.Conn не поддерживает конкурентное использование
 }
 
 // NewPostgresCheckpointStore создает новый PostgresCheckpointStore
@@ -40,19 +45,21 @@ func NewPostgresCheckpointStore(dsn string) (*PostgresCheckpointStore, error) {
 	return store, nil
 }
 
+// ensureTable создает таблицу миграцией 010 через отдельное соединение database/sql
 func (s *PostgresCheckpointStore) ensureTable(ctx context.Context) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS projection_checkpoints (
-			projection_name VARCHAR(255) PRIMARY KEY,
-			position BIGINT NOT NULL,
-			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-		);
-	`
-	_, err := s.conn.Exec(ctx, query)
-	return err
+	set, err := Migrations("postgres")
+	if err != nil {
+		return err
+	}
+	db := stdlib.OpenDB(*s.conn.Config())
+	defer db.Close()
+	return migrations.EnsureSchema(ctx, db, set, projectionCheckpointsMigration)
 }
 
 func (s *PostgresCheckpointStore) SaveCheckpoint(ctx context.Context, projectionName string, position int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	query := `
 		INSERT INTO projection_checkpoints (projection_name, position, updated_at)
 		VALUES ($1, $2, NOW())
@@ -64,6 +71,9 @@ func (s *PostgresCheckpointStore) SaveCheckpoint(ctx context.Context, projection
 }
 
 func (s *PostgresCheckpointStore) GetCheckpoint(ctx context.Context, projectionName string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	query := `SELECT position FROM projection_checkpoints WHERE projection_name = $1`
 	var position int64
 	err := s.conn.QueryRow(ctx, query, projectionName).Scan(&position)
@@ -77,12 +87,18 @@ func (s *PostgresCheckpointStore) GetCheckpoint(ctx context.Context, projectionN
 }
 
 func (s *PostgresCheckpointStore) DeleteCheckpoint(ctx context.Context, projectionName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	query := `DELETE FROM projection_checkpoints WHERE projection_name = $1`
 	_, err := s.conn.Exec(ctx, query, projectionName)
 	return err
 }
 
 func (s *PostgresCheckpointStore) ListCheckpoints(ctx context.Context) (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	query := `SELECT projection_name, position FROM projection_checkpoints`
 	rows, err := s.conn.Query(ctx, query)
 	if err != nil {
@@ -103,6 +119,53 @@ func (s *PostgresCheckpointStore) ListCheckpoints(ctx context.Context) (map[stri
 	return checkpoints, nil
 }
 
+// ApplyWithCheckpoint выполняет apply и сохраняет checkpoint в одной транзакции.
+// Строка checkpoint блокируется (SELECT ... FOR UPDATE), поэтому событие, уже примененное
+// другим runner'ом, не применяется повторно.
+func (s *PostgresCheckpointStore) ApplyWithCheckpoint(ctx context.Context, projectionName string, position int64, apply func(tx pgx.Tx) error) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	insertQuery := `
+		INSERT INTO projection_checkpoints (projection_name, position, updated_at)
+		VALUES ($1, 0, NOW())
+		ON CONFLICT (projection_name) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, insertQuery, projectionName); err != nil {
+		return false, fmt.Errorf("failed to lock checkpoint: %w", err)
+	}
+
+	var current int64
+	lockQuery := `SELECT position FROM projection_checkpoints WHERE projection_name = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, lockQuery, projectionName).Scan(&current); err != nil {
+		return false, fmt.Errorf("failed to lock checkpoint: %w", err)
+	}
+	if current >= position {
+		return false, nil
+	}
+
+	if err := apply(tx); err != nil {
+		return false, err
+	}
+
+	updateQuery := `UPDATE projection_checkpoints SET position = $2, updated_at = NOW() WHERE projection_name = $1`
+	if _, err := tx.Exec(ctx, updateQuery, projectionName, position); err != nil {
+		return false, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
 // MongoCheckpointStore реализация CheckpointStore для MongoDB
 type MongoCheckpointStore struct {
 	collection *mongo.Collection
@@ -202,6 +265,7 @@ func (s *MongoCheckpointStore) ListCheckpoints(ctx context.Context) (map[string]
 // InMemoryCheckpointStore реализация CheckpointStore в памяти для тестирования
 type InMemoryCheckpointStore struct {
 	checkpoints map[string]int64
+	mu          sync.RWMutex
 }
 
 // NewInMemoryCheckpointStore создает новый InMemoryCheckpointStore
@@ -212,11 +276,17 @@ func NewInMemoryCheckpointStore() *InMemoryCheckpointStore {
 }
 
 func (s *InMemoryCheckpointStore) SaveCheckpoint(ctx context.Context, projectionName string, position int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.checkpoints[projectionName] = position
 	return nil
 }
 
 func (s *InMemoryCheckpointStore) GetCheckpoint(ctx context.Context, projectionName string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	position, exists := s.checkpoints[projectionName]
 	if !exists {
 		return 0, nil
@@ -225,11 +295,17 @@ func (s *InMemoryCheckpointStore) GetCheckpoint(ctx context.Context, projectionN
 }
 
 func (s *InMemoryCheckpointStore) DeleteCheckpoint(ctx context.Context, projectionName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	delete(s.checkpoints, projectionName)
 	return nil
 }
 
 func (s *InMemoryCheckpointStore) ListCheckpoints(ctx context.Context) (map[string]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	result := make(map[string]int64)
 	for k, v := range s.checkpoints {
 		result[k] = v
@@ -0,0 +1,112 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+)
+
+// recordStreamConsistency сообщает трекеру контекста (см. core.TrackConsistency) позицию
+// последнего сохраненного события агрегата. Хранилище не возвращает позиции при записи,
+// поэтому читается одно событие версии version и только если токен собирается.
+// Ошибка чтения не влияет на сохранение: события уже записаны, команда просто не получит токен.
+func recordStreamConsistency(ctx context.Context, store EventStore, aggregateID string, version int64) {
+	tracker := core.ConsistencyTrackerFromContext(ctx)
+	if tracker == nil {
+		return
+	}
+	var stored []StoredEvent
+	var err error
+	if reader, ok := store.(EventPageReader); ok {
+		stored, err = reader.GetEventsPage(ctx, aggregateID, version, 1)
+	} else {
+		stored, err = store.GetEvents(ctx, aggregateID, version)
+	}
+	if err != nil {
+		return
+	}
+	for _, event := range stored {
+		tracker.Observe(core.ConsistencyToken(event.Position))
+	}
+}
+
+// consistencyPollInterval интервал проверки позиций проекций при ожидании токена
+const consistencyPollInterval = 10 * time.Millisecond
+
+// WaitForToken ожидает, пока проекции projectionNames (по умолчанию все зарегистрированные)
+// обработают события до позиции token. Ожидание ограничено timeout: по его истечении
+// возвращается core.ErrConsistencyTimeout, и вызывающий может вернуть данные с пометкой о задержке.
+func (m *ProjectionManager) WaitForToken(ctx context.Context, token core.ConsistencyToken, timeout time.Duration, projectionNames ...string) error {
+	if token <= 0 {
+		return nil
+	}
+	if len(projectionNames) == 0 {
+		m.mu.RLock()
+		for name := range m.projections {
+			projectionNames = append(projectionNames, name)
+		}
+		m.mu.RUnlock()
+	}
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(consistencyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		lagging := ""
+		for _, name := range projectionNames {
+			position, err := m.projectionPosition(ctx, name)
+			if err != nil {
+				return err
+			}
+			if position < int64(token) {
+				lagging = name
+				break
+			}
+		}
+		if lagging == "" {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("%w: projection %s is behind position %d", core.ErrConsistencyTimeout, lagging, token)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForContextToken ожидает токен согласованности, переданный в контексте запроса
+// через core.WithConsistencyToken. Без токена возвращается сразу.
+func (m *ProjectionManager) WaitForContextToken(ctx context.Context, timeout time.Duration, projectionNames ...string) error {
+	token, ok := core.ConsistencyTokenFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return m.WaitForToken(ctx, token, timeout, projectionNames...)
+}
+
+// projectionPosition возвращает позицию проекции: запущенной в этом процессе или по checkpoint
+func (m *ProjectionManager) projectionPosition(ctx context.Context, name string) (int64, error) {
+	m.mu.RLock()
+	runner, running := m.runners[name]
+	m.mu.RUnlock()
+
+	var position int64
+	if running {
+		position = runner.GetStatus().LastProcessedPosition
+	}
+	checkpoint, err := m.checkpointStore.GetCheckpoint(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get checkpoint of projection %s: %w", name, err)
+	}
+	if checkpoint > position {
+		position = checkpoint
+	}
+	return position, nil
+}
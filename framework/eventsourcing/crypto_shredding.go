@@ -0,0 +1,504 @@
+// Package eventsourcing предоставляет шифрование payload событий ключами субъектов (crypto-shredding).
+package eventsourcing
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrEncryptionKeyNotFound ключ шифрования субъекта не найден (не создавался или удален)
+	ErrEncryptionKeyNotFound = errors.New("encryption key not found")
+	// ErrSubjectForgotten данные субъекта удалены: новые события с его данными не шифруются
+	// и не записываются
+	ErrSubjectForgotten = errors.New("subject is forgotten")
+)
+
+// encryptedKey ключ JSON-конверта зашифрованного значения
+const encryptedKey = "$encrypted"
+
+// encryptionKeySize размер ключа AES-256
+const encryptionKeySize = 32
+
+// encryptedValue JSON-конверт зашифрованного значения: весь payload события
+// или отдельное поле с персональными данными
+type encryptedValue struct {
+	// Ciphertext nonce и шифротекст AES-GCM
+	Ciphertext []byte `json:"$encrypted"`
+	// Subject субъект, ключом которого зашифровано значение
+	Subject string `json:"$subject"`
+}
+
+// KeyStore хранилище ключей шифрования субъектов (клиентов, агрегатов).
+// Forget удаляет ключ, и данные субъекта во всех событиях становятся нечитаемыми;
+// хранилище запоминает удаление, чтобы ключ не был создан заново.
+// Ключи рекомендуется хранить отдельно от событий (отдельная БД, KMS), иначе резервная
+// копия хранилища событий будет содержать и ключи.
+type KeyStore interface {
+	SubjectForgetter
+	// GetOrCreateKey возвращает ключ субъекта, создавая его при первом обращении.
+	// Для удаленного субъекта возвращает ErrSubjectForgotten.
+	GetOrCreateKey(ctx context.Context, subjectID string) ([]byte, error)
+	// GetKey возвращает ключ субъекта или ErrEncryptionKeyNotFound
+	GetKey(ctx context.Context, subjectID string) ([]byte, error)
+}
+
+// EventEncryptor шифрует payload событий ключами субъектов из KeyStore (AES-256-GCM).
+// По умолчанию субъект - агрегат события, а payload шифруется целиком; WithEncryptedFields
+// ограничивает шифрование типа события полями с персональными данными, остальные поля
+// остаются доступны проекциям и после удаления ключа.
+//
+// Записанные события читаются через DecryptingDeserializer: после удаления ключа субъекта
+// (Forget) зашифрованные поля отбрасываются, и событие десериализуется с нулевыми значениями
+// этих полей. Поток событий сохраняет версии и порядок, агрегаты восстанавливаются без ошибок.
+type EventEncryptor struct {
+	keys            KeyStore
+	fields          map[string][]string
+	plaintext       map[string]bool
+	subjectResolver func(aggregateID string, event events.Event) string
+	mu              sync.RWMutex
+}
+
+// NewEventEncryptor создает EventEncryptor
+func NewEventEncryptor(keys KeyStore) *EventEncryptor {
+	return &EventEncryptor{
+		keys:      keys,
+		fields:    make(map[string][]string),
+		plaintext: make(map[string]bool),
+		subjectResolver: func(aggregateID string, event events.Event) string {
+			return aggregateID
+		},
+	}
+}
+
+// WithEncryptedFields шифрует у событий типа eventType только поля верхнего уровня fields
+// (имена полей JSON)
+func (e *EventEncryptor) WithEncryptedFields(eventType string, fields ...string) *EventEncryptor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fields[eventType] = append(e.fields[eventType], fields...)
+	return e
+}
+
+// WithPlaintextEvents отключает шифрование событий типов eventTypes (события без персональных данных)
+func (e *EventEncryptor) WithPlaintextEvents(eventTypes ...string) *EventEncryptor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, eventType := range eventTypes {
+		e.plaintext[eventType] = true
+	}
+	return e
+}
+
+// WithSubjectResolver задает субъекта события: например, ID клиента из события заказа,
+// чтобы удаление клиента сделало нечитаемыми данные всех его агрегатов.
+// Пустой субъект - событие не шифруется.
+func (e *EventEncryptor) WithSubjectResolver(resolver func(aggregateID string, event events.Event) string) *EventEncryptor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subjectResolver = resolver
+	return e
+}
+
+// Forget удаляет ключ субъекта (реализация SubjectForgetter для ErasureCoordinator)
+func (e *EventEncryptor) Forget(ctx context.Context, subjectID string) error {
+	return e.keys.Forget(ctx, subjectID)
+}
+
+// EncryptEvent шифрует сериализованный payload события агрегата aggregateID
+func (e *EventEncryptor) EncryptEvent(ctx context.Context, aggregateID string, event events.Event, data []byte) ([]byte, error) {
+	e.mu.RLock()
+	fields, fieldMode := e.fields[event.EventType()]
+	plaintext := e.plaintext[event.EventType()]
+	subject := e.subjectResolver(aggregateID, event)
+	e.mu.RUnlock()
+
+	if plaintext || subject == "" {
+		return data, nil
+	}
+	key, err := e.keys.GetOrCreateKey(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key of subject %s: %w", subject, err)
+	}
+
+	if !fieldMode {
+		return encryptValue(key, subject, data)
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to encrypt fields of %s: payload is not a JSON object: %w", event.EventType(), err)
+	}
+	for _, field := range fields {
+		value, ok := payload[field]
+		if !ok {
+			continue
+		}
+		if payload[field], err = encryptValue(key, subject, value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// DecryptPayload расшифровывает payload события. Значения субъектов с удаленным ключом
+// отбрасываются: payload, зашифрованный целиком, становится пустым объектом.
+func (e *EventEncryptor) DecryptPayload(ctx context.Context, data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(encryptedKey)) {
+		return data, nil
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		// Payload не JSON-объект - шифрованных значений в нем нет
+		return data, nil
+	}
+	if envelope, whole := parseEncryptedValue(data); whole {
+		plain, err := e.decryptValue(ctx, envelope)
+		if errors.Is(err, ErrEncryptionKeyNotFound) {
+			return []byte("{}"), nil
+		}
+		return plain, err
+	}
+
+	for field, value := range payload {
+		envelope, encrypted := parseEncryptedValue(value)
+		if !encrypted {
+			continue
+		}
+		plain, err := e.decryptValue(ctx, envelope)
+		switch {
+		case errors.Is(err, ErrEncryptionKeyNotFound):
+			delete(payload, field)
+		case err != nil:
+			return nil, fmt.Errorf("failed to decrypt field %s: %w", field, err)
+		default:
+			payload[field] = plain
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// parseEncryptedValue разбирает JSON-конверт зашифрованного значения
+func parseEncryptedValue(data []byte) (encryptedValue, bool) {
+	var envelope encryptedValue
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) || !bytes.Contains(data, []byte(encryptedKey)) {
+		return envelope, false
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Subject == "" || len(envelope.Ciphertext) == 0 {
+		return envelope, false
+	}
+	return envelope, true
+}
+
+// decryptValue расшифровывает значение из JSON-конверта
+func (e *EventEncryptor) decryptValue(ctx context.Context, envelope encryptedValue) ([]byte, error) {
+	key, err := e.keys.GetKey(ctx, envelope.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key of subject %s: %w", envelope.Subject, err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted value of subject %s is too short", envelope.Subject)
+	}
+	nonce, ciphertext := envelope.Ciphertext[:gcm.NonceSize()], envelope.Ciphertext[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(envelope.Subject))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value of subject %s: %w", envelope.Subject, err)
+	}
+	return plain, nil
+}
+
+// encryptValue шифрует значение ключом субъекта в JSON-конверт
+func encryptValue(key []byte, subject string, plain []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key of subject %s: %w", subject, err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// Субъект - дополнительные данные AEAD: конверт нельзя переназначить другому ключу
+	sealed := gcm.Seal(nonce, nonce, plain, []byte(subject))
+	return json.Marshal(encryptedValue{Ciphertext: sealed, Subject: subject})
+}
+
+// newEncryptionKey генерирует ключ AES-256
+func newEncryptionKey() ([]byte, error) {
+	key := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// EncryptedEventStore шифрует payload новых событий через EventEncryptor.
+// Хранилище должно читать события через DecryptingDeserializer с тем же EventEncryptor.
+// С CodecEventStore шифрование подключается внутри: NewCodecEventStore(NewEncryptedEventStore(store, encryptor), registry).
+type EncryptedEventStore struct {
+	EventStore
+	encryptor *EventEncryptor
+}
+
+// NewEncryptedEventStore оборачивает хранилище шифрованием payload событий
+func NewEncryptedEventStore(store EventStore, encryptor *EventEncryptor) *EncryptedEventStore {
+	return &EncryptedEventStore{EventStore: store, encryptor: encryptor}
+}
+
+// TenantIsolation проверяет изоляцию тенантов хранилища (реализация TenantIsolatedStore)
+func (s *EncryptedEventStore) TenantIsolation() bool {
+	return SupportsTenantIsolation(s.EventStore)
+}
+
+// AppendEvents шифрует payload событий и добавляет их в поток агрегата
+func (s *EncryptedEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, evts []events.Event) error {
+	encrypted, err := s.encryptEvents(ctx, aggregateID, evts)
+	if err != nil {
+		return err
+	}
+	return s.EventStore.AppendEvents(ctx, aggregateID, expectedVersion, encrypted)
+}
+
+// AppendEventsBatch шифрует payload событий пакета и записывает его (реализация BatchAppender)
+func (s *EncryptedEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	encrypted := make([]EventBatch, len(batches))
+	for i, batch := range batches {
+		evts, err := s.encryptEvents(ctx, batch.AggregateID, batch.Events)
+		if err != nil {
+			return err
+		}
+		encrypted[i] = EventBatch{AggregateID: batch.AggregateID, ExpectedVersion: batch.ExpectedVersion, Events: evts}
+	}
+	return AppendEventsBatch(ctx, s.EventStore, encrypted)
+}
+
+// encryptEvents оборачивает события зашифрованным payload
+func (s *EncryptedEventStore) encryptEvents(ctx context.Context, aggregateID string, evts []events.Event) ([]events.Event, error) {
+	encrypted := make([]events.Event, len(evts))
+	for i, event := range evts {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize event: %w", err)
+		}
+		data, err = s.encryptor.EncryptEvent(ctx, aggregateID, event, data)
+		if err != nil {
+			return nil, err
+		}
+
+		wrapped := &encodedEvent{Event: event, data: data, metadata: event.Metadata()}
+		if versioned, ok := event.(SchemaVersionedEvent); ok {
+			encrypted[i] = &encodedVersionedEvent{encodedEvent: wrapped, version: versioned.SchemaVersion()}
+		} else {
+			encrypted[i] = wrapped
+		}
+	}
+	return encrypted, nil
+}
+
+// DecryptingDeserializer расшифровывает payload событий, записанных EncryptedEventStore,
+// и передает его десериализатору (upcaster'ы применяются к расшифрованным данным)
+type DecryptingDeserializer struct {
+	deserializer EventDeserializer
+	encryptor    *EventEncryptor
+}
+
+// NewDecryptingDeserializer оборачивает десериализатор расшифровкой payload
+func NewDecryptingDeserializer(deserializer EventDeserializer, encryptor *EventEncryptor) *DecryptingDeserializer {
+	return &DecryptingDeserializer{deserializer: deserializer, encryptor: encryptor}
+}
+
+// DeserializeEvent расшифровывает и десериализует событие
+func (d *DecryptingDeserializer) DeserializeEvent(eventType string, data []byte) (events.Event, error) {
+	plain, err := d.encryptor.DecryptPayload(context.Background(), data)
+	if err != nil {
+		return nil, err
+	}
+	return d.deserializer.DeserializeEvent(eventType, plain)
+}
+
+// DeserializeVersionedEvent расшифровывает и десериализует событие указанной версии схемы
+func (d *DecryptingDeserializer) DeserializeVersionedEvent(eventType string, schemaVersion int, data []byte) (events.Event, error) {
+	plain, err := d.encryptor.DecryptPayload(context.Background(), data)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeStoredEvent(d.deserializer, eventType, schemaVersion, plain)
+}
+
+// CurrentSchemaVersion возвращает версию схемы для новых событий типа
+func (d *DecryptingDeserializer) CurrentSchemaVersion(eventType string) int {
+	if versioned, ok := d.deserializer.(VersionedEventDeserializer); ok {
+		return versioned.CurrentSchemaVersion(eventType)
+	}
+	return DefaultSchemaVersion
+}
+
+// InMemoryKeyStore хранилище ключей в памяти (для тестов и разработки)
+type InMemoryKeyStore struct {
+	keys      map[string][]byte
+	forgotten map[string]bool
+	mu        sync.RWMutex
+}
+
+// NewInMemoryKeyStore создает хранилище ключей в памяти
+func NewInMemoryKeyStore() *InMemoryKeyStore {
+	return &InMemoryKeyStore{
+		keys:      make(map[string][]byte),
+		forgotten: make(map[string]bool),
+	}
+}
+
+func (s *InMemoryKeyStore) GetOrCreateKey(ctx context.Context, subjectID string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.forgotten[subjectID] {
+		return nil, ErrSubjectForgotten
+	}
+	if key, ok := s.keys[subjectID]; ok {
+		return key, nil
+	}
+	key, err := newEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	s.keys[subjectID] = key
+	return key, nil
+}
+
+func (s *InMemoryKeyStore) GetKey(ctx context.Context, subjectID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[subjectID]
+	if !ok {
+		return nil, ErrEncryptionKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *InMemoryKeyStore) Forget(ctx context.Context, subjectID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, subjectID)
+	s.forgotten[subjectID] = true
+	return nil
+}
+
+// PostgresKeyStore хранилище ключей в PostgreSQL (таблица event_encryption_keys).
+// Удаленный ключ заменяется отметкой forgotten_at.
+type PostgresKeyStore struct {
+	conn *pgx.Conn
+}
+
+// NewPostgresKeyStore создает хранилище ключей в PostgreSQL
+func NewPostgresKeyStore(dsn string) (*PostgresKeyStore, error) {
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	store := &PostgresKeyStore{conn: conn}
+	if err := store.ensureTable(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure table: %w", err)
+	}
+
+	return store, nil
+}
+
+func (s *PostgresKeyStore) ensureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS event_encryption_keys (
+			subject_id VARCHAR(255) PRIMARY KEY,
+			encryption_key BYTEA,
+			created_at TIMESTAMP NOT NULL,
+			forgotten_at TIMESTAMP
+		)
+	`
+	_, err := s.conn.Exec(ctx, query)
+	return err
+}
+
+func (s *PostgresKeyStore) GetOrCreateKey(ctx context.Context, subjectID string) ([]byte, error) {
+	key, err := newEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// Конкурентное создание ключа: выигрывает первая запись, остальные читают ее ключ
+	query := `
+		INSERT INTO event_encryption_keys (subject_id, encryption_key, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subject_id) DO NOTHING
+	`
+	if _, err := s.conn.Exec(ctx, query, subjectID, key, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to create encryption key: %w", err)
+	}
+
+	var stored []byte
+	var forgottenAt *time.Time
+	query = `SELECT encryption_key, forgotten_at FROM event_encryption_keys WHERE subject_id = $1`
+	if err := s.conn.QueryRow(ctx, query, subjectID).Scan(&stored, &forgottenAt); err != nil {
+		return nil, fmt.Errorf("failed to load encryption key: %w", err)
+	}
+	if forgottenAt != nil {
+		return nil, ErrSubjectForgotten
+	}
+	return stored, nil
+}
+
+func (s *PostgresKeyStore) GetKey(ctx context.Context, subjectID string) ([]byte, error) {
+	var key []byte
+	query := `SELECT encryption_key FROM event_encryption_keys WHERE subject_id = $1`
+	err := s.conn.QueryRow(ctx, query, subjectID).Scan(&key)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && key == nil) {
+		return nil, ErrEncryptionKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption key: %w", err)
+	}
+	return key, nil
+}
+
+func (s *PostgresKeyStore) Forget(ctx context.Context, subjectID string) error {
+	query := `
+		INSERT INTO event_encryption_keys (subject_id, encryption_key, created_at, forgotten_at)
+		VALUES ($1, NULL, $2, $2)
+		ON CONFLICT (subject_id) DO UPDATE
+		SET encryption_key = NULL, forgotten_at = COALESCE(event_encryption_keys.forgotten_at, EXCLUDED.forgotten_at)
+	`
+	if _, err := s.conn.Exec(ctx, query, subjectID, time.Now()); err != nil {
+		return fmt.Errorf("failed to forget encryption key: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с базой данных
+func (s *PostgresKeyStore) Close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
@@ -0,0 +1,458 @@
+// Package eventsourcing предоставляет удаление персональных данных субъекта из read models (GDPR).
+package eventsourcing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrErasureNotSupported проекция не поддерживает удаление данных субъекта
+var ErrErasureNotSupported = errors.New("projection does not support erasure")
+
+// ReadModelEraser хранилище read model, умеющее удалять или обезличивать данные субъекта
+// (клиента, пользователя). Возвращает количество затронутых записей.
+// Повторный вызов для уже удаленного субъекта должен завершаться без ошибки.
+type ReadModelEraser interface {
+	Erase(ctx context.Context, subjectID string) (int64, error)
+}
+
+// ReadModelEraserFunc функциональный адаптер для ReadModelEraser
+type ReadModelEraserFunc func(ctx context.Context, subjectID string) (int64, error)
+
+// Erase вызывает функцию удаления
+func (f ReadModelEraserFunc) Erase(ctx context.Context, subjectID string) (int64, error) {
+	return f(ctx, subjectID)
+}
+
+// ErasableProjection проекция, read model которой поддерживает удаление данных субъекта
+type ErasableProjection interface {
+	Projection
+	ReadModelEraser
+}
+
+// SubjectForgetter делает данные субъекта в хранилище событий нечитаемыми
+// (crypto-shredding: удаление ключа шифрования субъекта).
+// Вызывается ErasureCoordinator до очистки read models, чтобы rebuild проекций
+// не восстановил удаленные данные.
+type SubjectForgetter interface {
+	Forget(ctx context.Context, subjectID string) error
+}
+
+// ErasureStatus статус удаления данных субъекта
+type ErasureStatus string
+
+const (
+	// ErasureStatusCompleted данные удалены из всех хранилищ
+	ErasureStatusCompleted ErasureStatus = "completed"
+	// ErasureStatusFailed удаление завершилось ошибкой хотя бы в одном хранилище;
+	// повторный запрос удаления безопасен
+	ErasureStatusFailed ErasureStatus = "failed"
+)
+
+// ErasureResult результат удаления данных субъекта в одном хранилище
+type ErasureResult struct {
+	// ReadModel имя read model (имя проекции или имя, указанное при RegisterReadModel)
+	ReadModel string `json:"read_model"`
+	// RowsAffected количество удаленных или обезличенных записей
+	RowsAffected int64 `json:"rows_affected"`
+	// Error текст ошибки (пустой - успешно)
+	Error string `json:"error,omitempty"`
+}
+
+// ErasureRequest запрос удаления данных субъекта
+type ErasureRequest struct {
+	// SubjectID идентификатор субъекта
+	SubjectID string
+	// Reason основание удаления (например, номер обращения)
+	Reason string
+	// RequestedBy инициатор удаления
+	RequestedBy string
+}
+
+// ErasureRecord запись журнала удалений.
+// Журнал подтверждает выполнение запроса и не содержит удаленных данных.
+type ErasureRecord struct {
+	ID          string        `json:"id"`
+	SubjectID   string        `json:"subject_id"`
+	Reason      string        `json:"reason,omitempty"`
+	RequestedBy string        `json:"requested_by,omitempty"`
+	Status      ErasureStatus `json:"status"`
+	// EventStoreForgotten данные субъекта в хранилище событий сделаны нечитаемыми (SubjectForgetter)
+	EventStoreForgotten bool            `json:"event_store_forgotten"`
+	EventStoreError     string          `json:"event_store_error,omitempty"`
+	Results             []ErasureResult `json:"results"`
+	RequestedAt         time.Time       `json:"requested_at"`
+	CompletedAt         time.Time       `json:"completed_at"`
+}
+
+// ErasureAuditStore журнал удалений данных субъектов
+type ErasureAuditStore interface {
+	SaveErasure(ctx context.Context, record ErasureRecord) error
+	// ListErasures возвращает удаления субъекта в порядке выполнения
+	ListErasures(ctx context.Context, subjectID string) ([]ErasureRecord, error)
+}
+
+// Erase удаляет данные субъекта из read models всех зарегистрированных проекций,
+// реализующих ErasableProjection. Ошибка одной проекции не останавливает удаление в остальных;
+// результаты возвращаются в порядке имен проекций.
+func (m *ProjectionManager) Erase(ctx context.Context, subjectID string) ([]ErasureResult, error) {
+	m.mu.RLock()
+	erasers := make(map[string]ReadModelEraser)
+	for name, projection := range m.projections {
+		if erasable, ok := projection.(ErasableProjection); ok {
+			erasers[name] = erasable
+		}
+	}
+	m.mu.RUnlock()
+
+	return eraseAll(ctx, subjectID, erasers)
+}
+
+// eraseAll вызывает erasers в порядке имен и собирает результаты и ошибки
+func eraseAll(ctx context.Context, subjectID string, erasers map[string]ReadModelEraser) ([]ErasureResult, error) {
+	names := make([]string, 0, len(erasers))
+	for name := range erasers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]ErasureResult, 0, len(names))
+	var errs []error
+	for _, name := range names {
+		var rows int64
+		err := core.SafeCall(func() error {
+			var eraseErr error
+			rows, eraseErr = erasers[name].Erase(ctx, subjectID)
+			return eraseErr
+		})
+
+		if errors.Is(err, ErrErasureNotSupported) {
+			// Проекция не хранит данных субъектов (например, builder-проекция без OnErase)
+			continue
+		}
+
+		result := ErasureResult{ReadModel: name, RowsAffected: rows}
+		if err != nil {
+			result.Error = err.Error()
+			errs = append(errs, fmt.Errorf("failed to erase subject from %s: %w", name, err))
+		}
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// ErasureCoordinator выполняет запрос удаления данных субъекта: делает данные нечитаемыми
+// в хранилище событий (SubjectForgetter), очищает read models проекций и зарегистрированных
+// хранилищ и записывает результат в журнал удалений
+type ErasureCoordinator struct {
+	audit      ErasureAuditStore
+	manager    *ProjectionManager
+	forgetter  SubjectForgetter
+	readModels map[string]ReadModelEraser
+	mu         sync.RWMutex
+}
+
+// NewErasureCoordinator создает координатор удалений (audit == nil - журнал в памяти)
+func NewErasureCoordinator(audit ErasureAuditStore) *ErasureCoordinator {
+	if audit == nil {
+		audit = NewInMemoryErasureAuditStore()
+	}
+	return &ErasureCoordinator{
+		audit:      audit,
+		readModels: make(map[string]ReadModelEraser),
+	}
+}
+
+// WithProjectionManager подключает проекции менеджера, реализующие ErasableProjection
+func (c *ErasureCoordinator) WithProjectionManager(manager *ProjectionManager) *ErasureCoordinator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manager = manager
+	return c
+}
+
+// WithForgetter подключает crypto-shredding хранилища событий
+func (c *ErasureCoordinator) WithForgetter(forgetter SubjectForgetter) *ErasureCoordinator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forgetter = forgetter
+	return c
+}
+
+// RegisterReadModel регистрирует хранилище read model, не являющееся проекцией менеджера
+// (например, таблицу, заполняемую обработчиком событий напрямую)
+func (c *ErasureCoordinator) RegisterReadModel(name string, eraser ReadModelEraser) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.readModels[name]; exists {
+		return fmt.Errorf("read model %s already registered", name)
+	}
+	c.readModels[name] = eraser
+	return nil
+}
+
+// Audit возвращает журнал удалений
+func (c *ErasureCoordinator) Audit() ErasureAuditStore {
+	return c.audit
+}
+
+// Erase удаляет данные субъекта. Удаление выполняется во всех хранилищах, даже если
+// часть из них вернула ошибку; запись журнала сохраняется в любом случае, а ошибки
+// возвращаются вместе с ней. Повторный запрос для того же субъекта безопасен.
+func (c *ErasureCoordinator) Erase(ctx context.Context, req ErasureRequest) (*ErasureRecord, error) {
+	if req.SubjectID == "" {
+		return nil, fmt.Errorf("subject ID must be specified")
+	}
+
+	c.mu.RLock()
+	manager := c.manager
+	forgetter := c.forgetter
+	readModels := make(map[string]ReadModelEraser, len(c.readModels))
+	for name, eraser := range c.readModels {
+		readModels[name] = eraser
+	}
+	c.mu.RUnlock()
+
+	record := &ErasureRecord{
+		ID:          uuid.New().String(),
+		SubjectID:   req.SubjectID,
+		Reason:      req.Reason,
+		RequestedBy: req.RequestedBy,
+		RequestedAt: time.Now(),
+	}
+	var errs []error
+
+	// Сначала хранилище событий: иначе проекция, обработавшая событие после очистки,
+	// записала бы данные субъекта обратно
+	if forgetter != nil {
+		if err := forgetter.Forget(ctx, req.SubjectID); err != nil {
+			record.EventStoreError = err.Error()
+			errs = append(errs, fmt.Errorf("failed to forget subject in event store: %w", err))
+		} else {
+			record.EventStoreForgotten = true
+		}
+	}
+
+	if manager != nil {
+		results, err := manager.Erase(ctx, req.SubjectID)
+		record.Results = append(record.Results, results...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	results, err := eraseAll(ctx, req.SubjectID, readModels)
+	record.Results = append(record.Results, results...)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	record.CompletedAt = time.Now()
+	record.Status = ErasureStatusCompleted
+	if len(errs) > 0 {
+		record.Status = ErasureStatusFailed
+	}
+
+	if err := c.audit.SaveErasure(ctx, *record); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save erasure audit record: %w", err))
+	}
+
+	return record, errors.Join(errs...)
+}
+
+// InMemoryErasureAuditStore журнал удалений в памяти (для тестов и разработки)
+type InMemoryErasureAuditStore struct {
+	records map[string][]ErasureRecord
+	mu      sync.RWMutex
+}
+
+// NewInMemoryErasureAuditStore создает журнал удалений в памяти
+func NewInMemoryErasureAuditStore() *InMemoryErasureAuditStore {
+	return &InMemoryErasureAuditStore{
+		records: make(map[string][]ErasureRecord),
+	}
+}
+
+func (s *InMemoryErasureAuditStore) SaveErasure(ctx context.Context, record ErasureRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.SubjectID] = append(s.records[record.SubjectID], record)
+	return nil
+}
+
+func (s *InMemoryErasureAuditStore) ListErasures(ctx context.Context, subjectID string) ([]ErasureRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ErasureRecord(nil), s.records[subjectID]...), nil
+}
+
+// PostgresErasureAuditStore журнал удалений в PostgreSQL
+type PostgresErasureAuditStore struct {
+	conn *pgx.Conn
+}
+
+// NewPostgresErasureAuditStore создает журнал удалений в PostgreSQL
+func NewPostgresErasureAuditStore(dsn string) (*PostgresErasureAuditStore, error) {
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	store := &PostgresErasureAuditStore{conn: conn}
+	if err := store.ensureTable(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure table: %w", err)
+	}
+
+	return store, nil
+}
+
+func (s *PostgresErasureAuditStore) ensureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS erasure_audit (
+			id VARCHAR(255) PRIMARY KEY,
+			subject_id VARCHAR(255) NOT NULL,
+			reason TEXT,
+			requested_by VARCHAR(255),
+			status VARCHAR(50) NOT NULL,
+			event_store_forgotten BOOLEAN NOT NULL DEFAULT FALSE,
+			event_store_error TEXT,
+			results JSONB NOT NULL,
+			requested_at TIMESTAMP NOT NULL,
+			completed_at TIMESTAMP NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_erasure_audit_subject ON erasure_audit (subject_id, requested_at);
+	`
+	_, err := s.conn.Exec(ctx, query)
+	return err
+}
+
+func (s *PostgresErasureAuditStore) SaveErasure(ctx context.Context, record ErasureRecord) error {
+	results, err := json.Marshal(record.Results)
+	if err != nil {
+		return fmt.Errorf("failed to marshal erasure results: %w", err)
+	}
+
+	query := `
+		INSERT INTO erasure_audit (id, subject_id, reason, requested_by, status,
+			event_store_forgotten, event_store_error, results, requested_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err = s.conn.Exec(ctx, query, record.ID, record.SubjectID, record.Reason, record.RequestedBy,
+		string(record.Status), record.EventStoreForgotten, record.EventStoreError, results,
+		record.RequestedAt, record.CompletedAt)
+	return err
+}
+
+func (s *PostgresErasureAuditStore) ListErasures(ctx context.Context, subjectID string) ([]ErasureRecord, error) {
+	query := `
+		SELECT id, subject_id, reason, requested_by, status, event_store_forgotten,
+			event_store_error, results, requested_at, completed_at
+		FROM erasure_audit WHERE subject_id = $1 ORDER BY requested_at
+	`
+	rows, err := s.conn.Query(ctx, query, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]ErasureRecord, 0)
+	for rows.Next() {
+		var record ErasureRecord
+		var reason, requestedBy, eventStoreError *string
+		var status string
+		var results []byte
+		if err := rows.Scan(&record.ID, &record.SubjectID, &reason, &requestedBy, &status,
+			&record.EventStoreForgotten, &eventStoreError, &results, &record.RequestedAt, &record.CompletedAt); err != nil {
+			return nil, err
+		}
+		if reason != nil {
+			record.Reason = *reason
+		}
+		if requestedBy != nil {
+			record.RequestedBy = *requestedBy
+		}
+		if eventStoreError != nil {
+			record.EventStoreError = *eventStoreError
+		}
+		record.Status = ErasureStatus(status)
+		if err := json.Unmarshal(results, &record.Results); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal erasure results: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// PostgresReadModelEraser удаляет или обезличивает строки таблицы read model,
+// принадлежащие субъекту (строки, у которых SubjectColumn равен ID субъекта)
+type PostgresReadModelEraser struct {
+	conn          *pgx.Conn
+	table         string
+	subjectColumn string
+	anonymize     map[string]interface{}
+}
+
+// NewPostgresReadModelEraser создает eraser, удаляющий строки субъекта из table
+func NewPostgresReadModelEraser(conn *pgx.Conn, table, subjectColumn string) *PostgresReadModelEraser {
+	return &PostgresReadModelEraser{
+		conn:          conn,
+		table:         table,
+		subjectColumn: subjectColumn,
+	}
+}
+
+// WithAnonymize заменяет удаление строк обезличиванием: указанным колонкам присваиваются
+// значения values (nil - NULL). Используется, когда строки нужны для агрегированной
+// статистики. Колонка субъекта должна быть среди обезличиваемых, иначе повторное
+// удаление снова найдет строки.
+func (e *PostgresReadModelEraser) WithAnonymize(values map[string]interface{}) *PostgresReadModelEraser {
+	e.anonymize = values
+	return e
+}
+
+// Erase удаляет или обезличивает строки субъекта
+func (e *PostgresReadModelEraser) Erase(ctx context.Context, subjectID string) (int64, error) {
+	table := pgx.Identifier(strings.Split(e.table, ".")).Sanitize()
+	subjectColumn := pgx.Identifier{e.subjectColumn}.Sanitize()
+
+	if len(e.anonymize) == 0 {
+		tag, err := e.conn.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, table, subjectColumn), subjectID)
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	}
+
+	columns := make([]string, 0, len(e.anonymize))
+	for column := range e.anonymize {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	assignments := make([]string, 0, len(columns))
+	args := []interface{}{subjectID}
+	for _, column := range columns {
+		args = append(args, e.anonymize[column])
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", pgx.Identifier{column}.Sanitize(), len(args)))
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE %s = $1`, table, strings.Join(assignments, ", "), subjectColumn)
+	tag, err := e.conn.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,164 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// codecEnvelopeKey ключ JSON-конверта payload, закодированного не в JSON
+const codecEnvelopeKey = "$content_type"
+
+// codecEnvelope JSON-конверт payload в формате protobuf, Avro или MessagePack.
+// Колонки event_data хранилищ имеют тип JSON, поэтому бинарный payload сохраняется
+// в base64 вместе с content type.
+type codecEnvelope struct {
+	ContentType string `json:"$content_type"`
+	Payload     []byte `json:"$payload"`
+}
+
+// CodecEventStore кодирует payload новых событий кодеком их типа из SerializerRegistry
+// и добавляет content type в метаданные события (events.ContentTypeMetadataKey).
+// Payload в JSON сохраняется как раньше; остальные форматы - в JSON-конверте, который
+// разбирает CodecDeserializer. Хранилище должно читать события через CodecDeserializer
+// с тем же реестром.
+//
+// Upcaster'ы применяются к JSON данным, поэтому для типов с бинарными кодеками
+// эволюция схемы выполняется средствами формата (поля protobuf, разрешение схем Avro).
+type CodecEventStore struct {
+	EventStore
+	registry *events.SerializerRegistry
+}
+
+// NewCodecEventStore оборачивает хранилище кодированием payload событий
+func NewCodecEventStore(store EventStore, registry *events.SerializerRegistry) *CodecEventStore {
+	return &CodecEventStore{EventStore: store, registry: registry}
+}
+
+// TenantIsolation проверяет изоляцию тенантов хранилища (реализация TenantIsolatedStore)
+func (s *CodecEventStore) TenantIsolation() bool {
+	return SupportsTenantIsolation(s.EventStore)
+}
+
+// AppendEvents кодирует payload событий и добавляет их в поток агрегата
+func (s *CodecEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, evts []events.Event) error {
+	encoded, err := s.encodeEvents(evts)
+	if err != nil {
+		return err
+	}
+	return s.EventStore.AppendEvents(ctx, aggregateID, expectedVersion, encoded)
+}
+
+// AppendEventsBatch кодирует payload событий пакета и записывает его (реализация BatchAppender)
+func (s *CodecEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	encoded := make([]EventBatch, len(batches))
+	for i, batch := range batches {
+		evts, err := s.encodeEvents(batch.Events)
+		if err != nil {
+			return err
+		}
+		encoded[i] = EventBatch{AggregateID: batch.AggregateID, ExpectedVersion: batch.ExpectedVersion, Events: evts}
+	}
+	return AppendEventsBatch(ctx, s.EventStore, encoded)
+}
+
+// encodeEvents оборачивает события закодированным payload
+func (s *CodecEventStore) encodeEvents(evts []events.Event) ([]events.Event, error) {
+	encoded := make([]events.Event, len(evts))
+	for i, event := range evts {
+		data, contentType, err := s.registry.Serialize(event)
+		if err != nil {
+			return nil, err
+		}
+		if contentType != events.ContentTypeJSON {
+			data, err = json.Marshal(codecEnvelope{ContentType: contentType, Payload: data})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode event envelope: %w", err)
+			}
+		}
+
+		metadata := make(events.EventMetadata, len(event.Metadata())+1)
+		for key, value := range event.Metadata() {
+			metadata[key] = value
+		}
+		metadata[events.ContentTypeMetadataKey] = contentType
+
+		wrapped := &encodedEvent{Event: event, data: data, metadata: metadata}
+		if versioned, ok := event.(SchemaVersionedEvent); ok {
+			encoded[i] = &encodedVersionedEvent{encodedEvent: wrapped, version: versioned.SchemaVersion()}
+		} else {
+			encoded[i] = wrapped
+		}
+	}
+	return encoded, nil
+}
+
+// encodedEvent событие с закодированным payload: хранилища сериализуют его через MarshalJSON
+type encodedEvent struct {
+	events.Event
+	data     json.RawMessage
+	metadata events.EventMetadata
+}
+
+// Metadata возвращает метаданные события с content type
+func (e *encodedEvent) Metadata() events.EventMetadata {
+	return e.metadata
+}
+
+// MarshalJSON возвращает закодированный payload
+func (e *encodedEvent) MarshalJSON() ([]byte, error) {
+	return e.data, nil
+}
+
+// encodedVersionedEvent закодированное событие с явной версией схемы
+type encodedVersionedEvent struct {
+	*encodedEvent
+	version int
+}
+
+// SchemaVersion возвращает версию схемы исходного события
+func (e *encodedVersionedEvent) SchemaVersion() int {
+	return e.version
+}
+
+// CodecDeserializer десериализует события, записанные CodecEventStore: payload в JSON-конверте
+// декодируется кодеком его content type, остальные данные - как JSON. Типы, не
+// зарегистрированные в реестре, передаются fallback десериализатору (если задан).
+type CodecDeserializer struct {
+	registry *events.SerializerRegistry
+	fallback EventDeserializer
+}
+
+// NewCodecDeserializer создает CodecDeserializer
+func NewCodecDeserializer(registry *events.SerializerRegistry) *CodecDeserializer {
+	return &CodecDeserializer{registry: registry}
+}
+
+// WithFallback задает десериализатор типов, не зарегистрированных в реестре
+func (d *CodecDeserializer) WithFallback(fallback EventDeserializer) *CodecDeserializer {
+	d.fallback = fallback
+	return d
+}
+
+// DeserializeEvent декодирует payload события
+func (d *CodecDeserializer) DeserializeEvent(eventType string, data []byte) (events.Event, error) {
+	contentType := events.ContentTypeJSON
+	payload := data
+	if bytes.Contains(data, []byte(codecEnvelopeKey)) {
+		var envelope codecEnvelope
+		if err := json.Unmarshal(data, &envelope); err == nil && envelope.ContentType != "" {
+			contentType = envelope.ContentType
+			payload = envelope.Payload
+		}
+	}
+
+	event, err := d.registry.Deserialize(eventType, contentType, payload)
+	if errors.Is(err, events.ErrUnknownEventType) && d.fallback != nil {
+		return d.fallback.DeserializeEvent(eventType, data)
+	}
+	return event, err
+}
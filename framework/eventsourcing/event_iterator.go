@@ -0,0 +1,170 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultEventStreamBatchSize размер пачки потокового чтения событий по умолчанию
+const DefaultEventStreamBatchSize = 500
+
+// EventPageReader реализуется хранилищами, читающими поток агрегата страницами.
+// Используется StreamEvents, чтобы не материализовать длинный поток целиком.
+type EventPageReader interface {
+	// GetEventsPage возвращает не более limit событий агрегата начиная с версии fromVersion
+	// (по возрастанию версии). Отсутствие потока обрабатывается так же, как в GetEvents.
+	GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error)
+}
+
+// GetEventsPage читает страницу событий агрегата: через EventPageReader, если хранилище
+// его реализует, иначе первые limit событий GetEvents. Используется обертками хранилищ,
+// чтобы чтение страницами проходило через них к хранилищу.
+func GetEventsPage(ctx context.Context, store EventStore, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	if reader, ok := store.(EventPageReader); ok {
+		return reader.GetEventsPage(ctx, aggregateID, fromVersion, limit)
+	}
+	stored, err := store.GetEvents(ctx, aggregateID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(stored) > limit {
+		stored = stored[:limit]
+	}
+	return stored, nil
+}
+
+// EventIterator потоковое чтение событий агрегата пачками. В памяти находится только
+// текущая пачка, поэтому память при восстановлении агрегата не зависит от длины потока.
+// Хранилища без EventPageReader читаются одним вызовом GetEvents.
+//
+//	stream := StreamEvents(ctx, store, aggregateID, 0, 0)
+//	for stream.Next() {
+//		apply(stream.Event())
+//	}
+//	if err := stream.Err(); err != nil {
+//		return err
+//	}
+type EventIterator struct {
+	ctx         context.Context
+	store       EventStore
+	reader      EventPageReader
+	aggregateID string
+	batchSize   int
+
+	next    int64
+	batch   []StoredEvent
+	index   int
+	current StoredEvent
+	read    int64
+	started bool
+	done    bool
+	err     error
+}
+
+// StreamEvents открывает потоковое чтение событий агрегата начиная с версии fromVersion.
+// batchSize <= 0 - DefaultEventStreamBatchSize.
+func StreamEvents(ctx context.Context, store EventStore, aggregateID string, fromVersion int64, batchSize int) *EventIterator {
+	if batchSize <= 0 {
+		batchSize = DefaultEventStreamBatchSize
+	}
+	stream := &EventIterator{
+		ctx:         ctx,
+		store:       store,
+		aggregateID: aggregateID,
+		batchSize:   batchSize,
+		next:        fromVersion,
+	}
+	if reader, ok := store.(EventPageReader); ok {
+		stream.reader = reader
+	}
+	return stream
+}
+
+// Next переходит к следующему событию, при необходимости читая следующую пачку.
+// Возвращает false по окончании потока или при ошибке (см. Err).
+func (s *EventIterator) Next() bool {
+	for s.index >= len(s.batch) {
+		if s.done || s.err != nil {
+			return false
+		}
+		s.fetch()
+	}
+	s.current = s.batch[s.index]
+	// Освобождаем ссылку на событие в пачке, чтобы прочитанные события собирались GC
+	s.batch[s.index] = StoredEvent{}
+	s.index++
+	s.read++
+	return true
+}
+
+// Event возвращает текущее событие
+func (s *EventIterator) Event() StoredEvent {
+	return s.current
+}
+
+// Read возвращает количество прочитанных событий
+func (s *EventIterator) Read() int64 {
+	return s.read
+}
+
+// Err возвращает ошибку чтения потока. ErrStreamNotFound возвращается, только если
+// хранилище не нашло поток при чтении первой пачки.
+func (s *EventIterator) Err() error {
+	return s.err
+}
+
+// fetch читает следующую пачку событий
+func (s *EventIterator) fetch() {
+	first := !s.started
+	s.started = true
+	s.batch, s.index = nil, 0
+
+	if err := s.ctx.Err(); err != nil {
+		s.err = err
+		return
+	}
+
+	if s.reader == nil {
+		s.batch, s.err = s.store.GetEvents(s.ctx, s.aggregateID, s.next)
+		s.done = true
+		return
+	}
+
+	batch, err := s.reader.GetEventsPage(s.ctx, s.aggregateID, s.next, s.batchSize)
+	if errors.Is(err, ErrStreamNotFound) && !first {
+		// Хранилища возвращают ErrStreamNotFound для пустого хвоста потока - это его конец
+		batch, err = nil, nil
+	}
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	s.batch = batch
+	// Конец потока - пустая пачка: неполная пачка не означает конец, если хранилище
+	// ограничивает размер страницы сильнее запрошенного
+	if len(batch) == 0 {
+		s.done = true
+		return
+	}
+	s.next = batch[len(batch)-1].Version + 1
+}
+
+// streamEvents открывает потоковое чтение событий агрегата с размером пачки из конфигурации
+func (r *EventSourcedRepository[T]) streamEvents(ctx context.Context, aggregateID string, fromVersion int64) *EventIterator {
+	return StreamEvents(ctx, r.eventStore, aggregateID, fromVersion, r.config.StreamBatchSize)
+}
+
+// applyStored применяет сохраненное событие к агрегату (события без данных пропускаются).
+// Возвращает false, если событие пропущено.
+func applyStored[T AggregateInterface](aggregate T, stored StoredEvent) (bool, error) {
+	if stored.EventData == nil {
+		return false, nil
+	}
+	if err := aggregate.Apply(stored.EventData); err != nil {
+		return false, fmt.Errorf("%w: failed to apply event %s: %w", ErrRehydrationFailed, stored.EventData.EventType(), err)
+	}
+	aggregate.SetVersion(aggregate.Version() + 1)
+	return true, nil
+}
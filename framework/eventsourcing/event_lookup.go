@@ -0,0 +1,62 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// EventStoreLookup ищет уже сохраненные события по correlation ID в EventStore.
+// Реализует интерфейс invoke.EventLookup и используется для backfill в EventAwaiter.
+type EventStoreLookup struct {
+	eventStore EventStore
+	lookback   time.Duration
+}
+
+// NewEventStoreLookup создает новый EventStoreLookup.
+// lookback ограничивает окно поиска по времени возникновения события.
+func NewEventStoreLookup(eventStore EventStore, lookback time.Duration) *EventStoreLookup {
+	if lookback <= 0 {
+		lookback = 5 * time.Minute
+	}
+	return &EventStoreLookup{
+		eventStore: eventStore,
+		lookback:   lookback,
+	}
+}
+
+// FindEvent ищет событие одного из указанных типов с заданным correlation ID
+func (l *EventStoreLookup) FindEvent(ctx context.Context, correlationID string, eventTypes []string) (events.Event, error) {
+	from := time.Now().Add(-l.lookback)
+
+	for _, eventType := range eventTypes {
+		storedEvents, err := l.eventStore.GetEventsByType(ctx, eventType, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events by type %s: %w", eventType, err)
+		}
+
+		for _, stored := range storedEvents {
+			if stored.EventData == nil {
+				continue
+			}
+			if storedCorrelationID(stored) == correlationID {
+				return stored.EventData, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// storedCorrelationID извлекает correlation ID из сохраненного события
+func storedCorrelationID(stored StoredEvent) string {
+	if id, ok := stored.Metadata["correlation_id"].(string); ok && id != "" {
+		return id
+	}
+	if stored.EventData != nil && stored.EventData.Metadata() != nil {
+		return stored.EventData.Metadata().CorrelationID()
+	}
+	return ""
+}
@@ -30,6 +30,10 @@ type StoredEvent struct {
 	Position     int64
 	OccurredAt   time.Time
 	CreatedAt    time.Time
+	// SchemaVersion версия схемы данных события в хранилище (до upcasting)
+	SchemaVersion int
+	// TenantID тенант, которому принадлежит событие ("" - тенант по умолчанию)
+	TenantID string
 }
 
 // EventStream представляет поток событий агрегата
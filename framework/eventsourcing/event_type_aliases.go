@@ -0,0 +1,201 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// ErrEventTypeRenameNotSupported возвращается RewriteEventTypes для хранилищ без EventTypeRenamer
+var ErrEventTypeRenameNotSupported = errors.New("event store does not support event type renaming")
+
+// EventTypeRenamer реализуется хранилищами, умеющими переписывать тип сохраненных событий
+type EventTypeRenamer interface {
+	// RenameEventType заменяет тип oldType на newType у событий всех тенантов
+	// и возвращает количество переименованных событий
+	RenameEventType(ctx context.Context, oldType, newType string) (int64, error)
+}
+
+// RewriteEventTypes переписывает в хранилище старые имена типов событий на актуальные.
+// Возвращает количество переименованных событий по старому имени типа.
+// После миграции переименования можно оставить в реестре: они не влияют на новые события.
+func RewriteEventTypes(ctx context.Context, store EventStore, aliases *events.EventTypeAliases) (map[string]int64, error) {
+	renamer, ok := store.(EventTypeRenamer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrEventTypeRenameNotSupported, store)
+	}
+
+	renames := aliases.Renames()
+	oldTypes := make([]string, 0, len(renames))
+	for oldType := range renames {
+		oldTypes = append(oldTypes, oldType)
+	}
+	sort.Strings(oldTypes)
+
+	result := make(map[string]int64, len(oldTypes))
+	for _, oldType := range oldTypes {
+		count, err := renamer.RenameEventType(ctx, oldType, renames[oldType])
+		if err != nil {
+			return result, fmt.Errorf("failed to rename event type %s to %s: %w", oldType, renames[oldType], err)
+		}
+		result[oldType] = count
+	}
+	return result, nil
+}
+
+// AliasingEventStore возвращает события переименованных типов под актуальным именем
+// (StoredEvent.EventType), а GetEventsByType читает события актуального типа вместе
+// с событиями его прежних имен. Хранилище должно десериализовать события через
+// AliasingDeserializer с тем же реестром, чтобы EventData имели актуальный тип.
+type AliasingEventStore struct {
+	EventStore
+	aliases *events.EventTypeAliases
+}
+
+// NewAliasingEventStore оборачивает хранилище переименованиями типов событий
+func NewAliasingEventStore(store EventStore, aliases *events.EventTypeAliases) *AliasingEventStore {
+	return &AliasingEventStore{EventStore: store, aliases: aliases}
+}
+
+// GetEvents возвращает события агрегата с актуальными именами типов
+func (s *AliasingEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	stored, err := s.EventStore.GetEvents(ctx, aggregateID, fromVersion)
+	for i := range stored {
+		stored[i].EventType = s.aliases.Resolve(stored[i].EventType)
+	}
+	return stored, err
+}
+
+// GetEventsPage возвращает страницу событий агрегата с актуальными именами типов (реализация EventPageReader)
+func (s *AliasingEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	stored, err := GetEventsPage(ctx, s.EventStore, aggregateID, fromVersion, limit)
+	for i := range stored {
+		stored[i].EventType = s.aliases.Resolve(stored[i].EventType)
+	}
+	return stored, err
+}
+
+// TenantIsolation проверяет изоляцию тенантов хранилища (реализация TenantIsolatedStore)
+func (s *AliasingEventStore) TenantIsolation() bool {
+	return SupportsTenantIsolation(s.EventStore)
+}
+
+// GetEventsByType возвращает события типа и его прежних имен в порядке позиции
+func (s *AliasingEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	eventType = s.aliases.Resolve(eventType)
+	eventTypes := append([]string{eventType}, s.aliases.OldNames(eventType)...)
+
+	var result []StoredEvent
+	for _, name := range eventTypes {
+		stored, err := s.EventStore.GetEventsByType(ctx, name, fromTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored...)
+	}
+	for i := range result {
+		result[i].EventType = eventType
+	}
+
+	if len(eventTypes) > 1 {
+		sort.SliceStable(result, func(i, j int) bool {
+			if result[i].Position != result[j].Position {
+				return result[i].Position < result[j].Position
+			}
+			return result[i].OccurredAt.Before(result[j].OccurredAt)
+		})
+	}
+	return result, nil
+}
+
+// GetAllEvents возвращает все события с актуальными именами типов
+func (s *AliasingEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	ch, err := s.EventStore.GetAllEvents(ctx, fromPosition)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveChannel(ctx, ch), nil
+}
+
+// AppendEventsBatch записывает пакет событий (реализация BatchAppender)
+func (s *AliasingEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	return AppendEventsBatch(ctx, s.EventStore, batches)
+}
+
+// SubscribeToStream подписывается на события агрегата (реализация EventSubscriber)
+func (s *AliasingEventStore) SubscribeToStream(ctx context.Context, aggregateID string, fromVersion int64) (<-chan StoredEvent, error) {
+	ch, err := NewEventSubscriber(s.EventStore).SubscribeToStream(ctx, aggregateID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveChannel(ctx, ch), nil
+}
+
+// SubscribeToAll подписывается на все события (реализация EventSubscriber)
+func (s *AliasingEventStore) SubscribeToAll(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	ch, err := NewEventSubscriber(s.EventStore).SubscribeToAll(ctx, fromPosition)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveChannel(ctx, ch), nil
+}
+
+// RenameEventType переименовывает тип событий в обернутом хранилище (реализация EventTypeRenamer)
+func (s *AliasingEventStore) RenameEventType(ctx context.Context, oldType, newType string) (int64, error) {
+	renamer, ok := s.EventStore.(EventTypeRenamer)
+	if !ok {
+		return 0, fmt.Errorf("%w: %T", ErrEventTypeRenameNotSupported, s.EventStore)
+	}
+	return renamer.RenameEventType(ctx, oldType, newType)
+}
+
+// resolveChannel переименовывает типы событий канала
+func (s *AliasingEventStore) resolveChannel(ctx context.Context, in <-chan StoredEvent) <-chan StoredEvent {
+	out := make(chan StoredEvent, subscriptionBufferSize)
+	go func() {
+		defer close(out)
+		for event := range in {
+			event.EventType = s.aliases.Resolve(event.EventType)
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// AliasingDeserializer десериализует события переименованных типов как актуальный тип.
+// Upcaster'ы применяются к актуальному имени типа, поэтому регистрируются под ним.
+type AliasingDeserializer struct {
+	deserializer EventDeserializer
+	aliases      *events.EventTypeAliases
+}
+
+// NewAliasingDeserializer оборачивает десериализатор переименованиями типов событий
+func NewAliasingDeserializer(deserializer EventDeserializer, aliases *events.EventTypeAliases) *AliasingDeserializer {
+	return &AliasingDeserializer{deserializer: deserializer, aliases: aliases}
+}
+
+// DeserializeEvent десериализует событие под актуальным именем типа
+func (d *AliasingDeserializer) DeserializeEvent(eventType string, data []byte) (events.Event, error) {
+	return d.deserializer.DeserializeEvent(d.aliases.Resolve(eventType), data)
+}
+
+// DeserializeVersionedEvent десериализует событие указанной версии схемы под актуальным именем типа
+func (d *AliasingDeserializer) DeserializeVersionedEvent(eventType string, schemaVersion int, data []byte) (events.Event, error) {
+	return deserializeStoredEvent(d.deserializer, d.aliases.Resolve(eventType), schemaVersion, data)
+}
+
+// CurrentSchemaVersion возвращает версию схемы актуального типа
+func (d *AliasingDeserializer) CurrentSchemaVersion(eventType string) int {
+	if versioned, ok := d.deserializer.(VersionedEventDeserializer); ok {
+		return versioned.CurrentSchemaVersion(d.aliases.Resolve(eventType))
+	}
+	return DefaultSchemaVersion
+}
@@ -3,22 +3,36 @@ package eventsourcing
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"strings"
 	"time"
 
+	"github.com/EventStore/EventStore-Client-Go/v4/esdb"
+	"github.com/google/uuid"
+
 	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/events"
 )
 
 // EventStoreDBConfig конфигурация для EventStoreDB
 type EventStoreDBConfig struct {
-	ConnectionString   string
-	Username           string
-	Password           string
+	// ConnectionString строка подключения вида esdb://host:2113?tls=false
+	ConnectionString    string
+	Username            string
+	Password            string
 	MaxDiscoverAttempts int
-	DiscoveryInterval  time.Duration
-	KeepAliveInterval  time.Duration
-	KeepAliveTimeout   time.Duration
+	DiscoveryInterval   time.Duration
+	KeepAliveInterval   time.Duration
+	KeepAliveTimeout    time.Duration
+	// StreamPrefix префикс потоков агрегатов (по умолчанию "potter"): поток агрегата называется
+	// "<prefix>-<aggregateID>", поэтому системная проекция $by_category группирует их в категорию $ce-<prefix>
+	StreamPrefix string
+	// SnapshotMaxCount сколько последних снапшотов хранить в потоке снапшотов агрегата
+	SnapshotMaxCount uint64
 }
 
 // Validate проверяет корректность конфигурации
@@ -26,111 +40,166 @@ func (c EventStoreDBConfig) Validate() error {
 	if c.ConnectionString == "" {
 		return fmt.Errorf("connection string cannot be empty")
 	}
+	if strings.Contains(c.StreamPrefix, "-") {
+		return fmt.Errorf("stream prefix cannot contain '-'")
+	}
 	return nil
 }
 
 // DefaultEventStoreDBConfig возвращает конфигурацию по умолчанию
 func DefaultEventStoreDBConfig() EventStoreDBConfig {
 	return EventStoreDBConfig{
+		ConnectionString:    "esdb://localhost:2113?tls=false",
 		MaxDiscoverAttempts: 10,
 		DiscoveryInterval:   100 * time.Millisecond,
 		KeepAliveInterval:   10 * time.Second,
-		KeepAliveTimeout:   10 * time.Second,
+		KeepAliveTimeout:    10 * time.Second,
+		StreamPrefix:        "potter",
+		SnapshotMaxCount:    1,
 	}
 }
 
-// EventStoreDBStore реализация EventStore для EventStoreDB
-//
-// ⚠️ ВНИМАНИЕ: ЭКСПЕРИМЕНТАЛЬНЫЙ КОД - НЕ ГОТОВ К PRODUCTION ИСПОЛЬЗОВАНИЮ
-//
-// Данный адаптер является плейсхолдером и не готов к использованию в production окружении.
-// Все методы возвращают ошибку "EventStoreDB adapter not fully implemented - requires stable Go client".
-//
-// Текущий статус:
-//   - Базовая структура адаптера реализована
-//   - Конфигурация и валидация готовы
-//   - Все методы EventStore интерфейса имеют заглушки с TODO комментариями
-//   - Интеграция с официальным Go client для EventStoreDB не завершена
+// eventStoreDBByEventTypeProjection системная проекция, поддерживающая потоки $et-<eventType>
+const eventStoreDBByEventTypeProjection = "$by_event_type"
+
+// eventStoreDBSnapshotEventType тип события снапшота в потоке снапшотов
+const eventStoreDBSnapshotEventType = "PotterSnapshot"
+
+// eventStoreDBMetadata метаданные события, хранимые в пользовательских метаданных EventStoreDB
+type eventStoreDBMetadata struct {
+	AggregateID   string                 `json:"aggregate_id"`
+	AggregateType string                 `json:"aggregate_type"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	SchemaVersion int                    `json:"schema_version"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// withDefaultStreamPrefix подставляет префикс потоков по умолчанию
+func (c EventStoreDBConfig) withDefaultStreamPrefix() EventStoreDBConfig {
+	if c.StreamPrefix == "" {
+		c.StreamPrefix = DefaultEventStoreDBConfig().StreamPrefix
+	}
+	return c
+}
+
+// newEventStoreDBClient создает клиент EventStoreDB по конфигурации
+func newEventStoreDBClient(config EventStoreDBConfig) (*esdb.Client, error) {
+	settings, err := esdb.ParseConnectionString(config.ConnectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+
+	if config.Username != "" {
+		settings.Username = config.Username
+		settings.Password = config.Password
+	}
+	if config.MaxDiscoverAttempts > 0 {
+		settings.MaxDiscoverAttempts = config.MaxDiscoverAttempts
+	}
+	if config.DiscoveryInterval > 0 {
+		settings.DiscoveryInterval = int(config.DiscoveryInterval.Milliseconds())
+	}
+	if config.KeepAliveInterval > 0 {
+		settings.KeepAliveInterval = config.KeepAliveInterval
+	}
+	if config.KeepAliveTimeout > 0 {
+		settings.KeepAliveTimeout = config.KeepAliveTimeout
+	}
+
+	client, err := esdb.NewClient(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EventStoreDB client: %w", err)
+	}
+	return client, nil
+}
+
+// isEventStoreDBError проверяет код ошибки EventStoreDB
+func isEventStoreDBError(err error, code esdb.ErrorCode) bool {
+	var esdbErr *esdb.Error
+	return errors.As(err, &esdbErr) && esdbErr.IsErrorCode(code)
+}
+
+// EventStoreDBStore реализация EventStore для EventStoreDB.
 //
-// Блокирующий фактор:
-//   - Отсутствие стабильной версии официального Go client для EventStoreDB
-//   - Требуется проверка статуса клиента: https://github.com/EventStore/EventStore-Client-Go
+// Каждый агрегат хранится в собственном потоке "<prefix>-<aggregateID>", оптимистичная
+// конкурентность обеспечивается ожидаемой ревизией потока. GetEventsByType читает
+// потоки $et-<eventType>, поэтому на сервере должна быть включена системная проекция
+// $by_event_type (см. EnsureByEventTypeProjection).
 //
-// После появления стабильного клиента потребуется:
-//   - Интеграция с официальным Go client
-//   - Реализация всех методов EventStore интерфейса
-//   - Comprehensive тесты с testcontainers
-//   - Обновление документации и примеров
+// StoredEvent.Position для событий из $all содержит commit position журнала EventStoreDB:
+// позиции возрастают, но не идут подряд, поэтому для checkpoint'ов следует использовать
+// только значения, полученные из StoredEvent.
 //
-// Использование данного адаптера в production не рекомендуется до завершения интеграции.
+// Изоляция тенантов не поддерживается: тенант из контекста игнорируется,
+// а NewTenantEventStore поверх хранилища возвращает ErrTenantIsolationUnsupported.
 type EventStoreDBStore struct {
 	config       EventStoreDBConfig
+	client       *esdb.Client
+	projections  *esdb.ProjectionClient
 	deserializer EventDeserializer
-	// client будет добавлен после интеграции с официальным клиентом
-	// client *esdb.Client
 }
 
 // NewEventStoreDBStore создает новый EventStoreDB Store
-//
-// ⚠️ ВНИМАНИЕ: ЭКСПЕРИМЕНТАЛЬНЫЙ КОД - НЕ ГОТОВ К PRODUCTION ИСПОЛЬЗОВАНИЮ
-//
-// Данная функция создает плейсхолдер адаптера, который не готов к использованию.
-// Все методы адаптера будут возвращать ошибки при попытке использования.
-//
-// Использование в production не рекомендуется до завершения интеграции с официальным Go client.
 func NewEventStoreDBStore(config EventStoreDBConfig) (*EventStoreDBStore, error) {
 	return NewEventStoreDBStoreWithDeserializer(config, nil)
 }
 
 // NewEventStoreDBStoreWithDeserializer создает новый EventStoreDB Store с десериализатором
-//
-// ⚠️ ВНИМАНИЕ: ЭКСПЕРИМЕНТАЛЬНЫЙ КОД - НЕ ГОТОВ К PRODUCTION ИСПОЛЬЗОВАНИЮ
-//
-// Данная функция создает плейсхолдер адаптера, который не готов к использованию.
-// Все методы адаптера будут возвращать ошибки при попытке использования.
-//
-// Использование в production не рекомендуется до завершения интеграции с официальным Go client.
 func NewEventStoreDBStoreWithDeserializer(config EventStoreDBConfig, deserializer EventDeserializer) (*EventStoreDBStore, error) {
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid eventstoredb config: %w", err)
 	}
+	config = config.withDefaultStreamPrefix()
 
-	// TODO: Инициализация клиента EventStoreDB после интеграции с официальным клиентом
-	// Блокирующий фактор: отсутствие стабильной версии официального Go client
-	// settings, err := esdb.ParseConnectionString(config.ConnectionString)
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to parse connection string: %w", err)
-	// }
-	// client, err := esdb.NewClient(settings)
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to create EventStoreDB client: %w", err)
-	// }
+	client, err := newEventStoreDBClient(config)
+	if err != nil {
+		return nil, err
+	}
 
 	return &EventStoreDBStore{
 		config:       config,
+		client:       client,
+		projections:  esdb.NewProjectionClientFromExistingClient(client),
 		deserializer: deserializer,
 	}, nil
 }
 
+// WithUpcasters включает приведение старых версий событий к актуальной схеме.
+// Upcaster'ы применяются перед десериализацией, поэтому требуется десериализатор
+// (NewEventStoreDBStoreWithDeserializer).
+func (s *EventStoreDBStore) WithUpcasters(chain *UpcasterChain) *EventStoreDBStore {
+	if s.deserializer != nil {
+		s.deserializer = NewUpcastingDeserializer(s.deserializer, chain)
+	}
+	return s
+}
+
 // Start запускает адаптер (реализация core.Lifecycle)
 func (s *EventStoreDBStore) Start(ctx context.Context) error {
-	// TODO: Проверка подключения к EventStoreDB
+	// Проверяем подключение чтением одного события из $all
+	stream, err := s.client.ReadAll(ctx, esdb.ReadAllOptions{From: esdb.Start{}}, 1)
+	if err != nil {
+		return fmt.Errorf("failed to connect to EventStoreDB: %w", err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Recv(); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to connect to EventStoreDB: %w", err)
+	}
 	return nil
 }
 
 // Stop останавливает адаптер (реализация core.Lifecycle)
 func (s *EventStoreDBStore) Stop(ctx context.Context) error {
-	// TODO: Закрытие соединения с EventStoreDB
-	// if s.client != nil {
-	//     return s.client.Close()
-	// }
+	if s.client != nil {
+		return s.client.Close()
+	}
 	return nil
 }
 
 // IsRunning проверяет, запущен ли адаптер (реализация core.Lifecycle)
 func (s *EventStoreDBStore) IsRunning() bool {
-	// TODO: Проверка состояния клиента
-	return false
+	return s.client != nil
 }
 
 // Name возвращает имя компонента (реализация core.Component)
@@ -143,139 +212,620 @@ func (s *EventStoreDBStore) Type() core.ComponentType {
 	return core.ComponentTypeAdapter
 }
 
+// StreamName возвращает имя потока агрегата
+func (s *EventStoreDBStore) StreamName(aggregateID string) string {
+	return s.config.StreamPrefix + "-" + aggregateID
+}
+
+// aggregateIDFromStream возвращает ID агрегата по имени потока (false - поток не принадлежит хранилищу)
+func (s *EventStoreDBStore) aggregateIDFromStream(streamID string) (string, bool) {
+	prefix := s.config.StreamPrefix + "-"
+	if !strings.HasPrefix(streamID, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(streamID, prefix), true
+}
+
 // AppendEvents добавляет события в поток агрегата
 func (s *EventStoreDBStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, events []events.Event) error {
-	// TODO: Реализация через EventStoreDB client
-	// streamName := fmt.Sprintf("%s-%s", aggregateType, aggregateID)
-	// 
-	// var expectedRevision esdb.StreamRevision
-	// if expectedVersion == 0 {
-	//     expectedRevision = esdb.NoStream{}
-	// } else {
-	//     expectedRevision = esdb.StreamRevision{Revision: uint64(expectedVersion)}
-	// }
-	// 
-	// eventData := make([]esdb.EventData, len(events))
-	// for i, event := range events {
-	//     data, err := json.Marshal(event)
-	//     if err != nil {
-	//         return fmt.Errorf("failed to marshal event: %w", err)
-	//     }
-	//     eventData[i] = esdb.EventData{
-	//         EventType: event.EventType(),
-	//         Data:      data,
-	//     }
-	// }
-	// 
-	// _, err := s.client.AppendToStream(ctx, streamName, expectedRevision, eventData)
-	// return err
-
-	return fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
-}
-
-// GetEvents возвращает все события агрегата
+	if len(events) == 0 {
+		return nil
+	}
+
+	var expectedRevision esdb.ExpectedRevision = esdb.NoStream{}
+	if expectedVersion > 0 {
+		// Ревизии EventStoreDB начинаются с 0, версии агрегата - с 1
+		expectedRevision = esdb.Revision(uint64(expectedVersion - 1))
+	}
+
+	eventData := make([]esdb.EventData, len(events))
+	for i, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		metadata, err := json.Marshal(eventStoreDBMetadata{
+			AggregateID:   aggregateID,
+			AggregateType: getAggregateType(event),
+			OccurredAt:    event.OccurredAt(),
+			SchemaVersion: eventSchemaVersion(s.deserializer, event),
+			Metadata:      convertMetadata(event.Metadata()),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+
+		eventID, err := uuid.Parse(event.EventID())
+		if err != nil {
+			eventID = uuid.New()
+		}
+
+		eventData[i] = esdb.EventData{
+			EventID:     eventID,
+			EventType:   event.EventType(),
+			ContentType: esdb.ContentTypeJson,
+			Data:        data,
+			Metadata:    metadata,
+		}
+	}
+
+	_, err := s.client.AppendToStream(ctx, s.StreamName(aggregateID), esdb.AppendToStreamOptions{
+		ExpectedRevision: expectedRevision,
+	}, eventData...)
+	if err != nil {
+		if isEventStoreDBError(err, esdb.ErrorCodeWrongExpectedVersion) {
+			return fmt.Errorf("%w: aggregate %s, expected version %d", ErrConcurrencyConflict, aggregateID, expectedVersion)
+		}
+		return fmt.Errorf("failed to append events: %w", err)
+	}
+
+	return nil
+}
+
+// GetEvents возвращает события агрегата начиная с версии fromVersion
 func (s *EventStoreDBStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
-	// TODO: Реализация через EventStoreDB client
-	// streamName := fmt.Sprintf("%s-%s", aggregateType, aggregateID)
-	// 
-	// stream, err := s.client.ReadStream(ctx, streamName, esdb.ReadStreamOptions{
-	//     Direction: esdb.Forwards,
-	//     From:      esdb.StreamRevision{Revision: uint64(fromVersion)},
-	// })
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to read stream: %w", err)
-	// }
-	// defer stream.Close()
-	// 
-	// var storedEvents []StoredEvent
-	// for {
-	//     event, err := stream.Recv()
-	//     if err == io.EOF {
-	//         break
-	//     }
-	//     if err != nil {
-	//         return nil, fmt.Errorf("failed to read event: %w", err)
-	//     }
-	//     
-	//     storedEvent := s.convertToStoredEvent(event)
-	//     storedEvents = append(storedEvents, storedEvent)
-	// }
-	// 
-	// return storedEvents, nil
-
-	return nil, fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
-}
-
-// GetEventsByType возвращает события определенного типа
+	return s.readStream(ctx, aggregateID, fromVersion, math.MaxInt64)
+}
+
+// GetEventsPage возвращает не более limit событий агрегата начиная с версии fromVersion
+// (реализация EventPageReader)
+func (s *EventStoreDBStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	if limit <= 0 {
+		return s.GetEvents(ctx, aggregateID, fromVersion)
+	}
+	return s.readStream(ctx, aggregateID, fromVersion, uint64(limit))
+}
+
+// readStream читает не более count событий потока агрегата начиная с версии fromVersion
+func (s *EventStoreDBStore) readStream(ctx context.Context, aggregateID string, fromVersion int64, count uint64) ([]StoredEvent, error) {
+	var from esdb.StreamPosition = esdb.Start{}
+	if fromVersion > 1 {
+		from = esdb.Revision(uint64(fromVersion - 1))
+	}
+
+	stream, err := s.client.ReadStream(ctx, s.StreamName(aggregateID), esdb.ReadStreamOptions{
+		Direction: esdb.Forwards,
+		From:      from,
+	}, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	defer stream.Close()
+
+	var result []StoredEvent
+	for {
+		resolved, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if isEventStoreDBError(err, esdb.ErrorCodeResourceNotFound) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read event: %w", err)
+		}
+
+		stored, err := s.toStoredEvent(resolved.OriginalEvent())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored)
+	}
+
+	if len(result) == 0 && fromVersion > 0 {
+		return nil, ErrStreamNotFound
+	}
+
+	return result, nil
+}
+
+// GetEventsByType возвращает события определенного типа из потока $et-<eventType>
 func (s *EventStoreDBStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
-	// TODO: Реализация через EventStoreDB client с фильтрацией по типу
-	return nil, fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
+	stream, err := s.client.ReadStream(ctx, "$et-"+eventType, esdb.ReadStreamOptions{
+		Direction:      esdb.Forwards,
+		From:           esdb.Start{},
+		ResolveLinkTos: true,
+	}, math.MaxInt64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event type stream: %w", err)
+	}
+	defer stream.Close()
+
+	var result []StoredEvent
+	for {
+		resolved, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if isEventStoreDBError(err, esdb.ErrorCodeResourceNotFound) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read event: %w", err)
+		}
+
+		// Ссылка на событие удаленного потока не разрешается
+		if resolved.Event == nil {
+			continue
+		}
+		if _, ok := s.aggregateIDFromStream(resolved.Event.StreamID); !ok {
+			continue
+		}
+
+		stored, err := s.toStoredEvent(resolved.Event)
+		if err != nil {
+			return nil, err
+		}
+		if stored.OccurredAt.Before(fromTimestamp) {
+			continue
+		}
+		result = append(result, stored)
+	}
+
+	return result, nil
 }
 
-// GetAllEvents возвращает все события для replay
+// GetAllEvents возвращает все события агрегатов хранилища из $all начиная с позиции fromPosition.
+// fromPosition должна быть позицией ранее полученного события (или 0 - с начала журнала).
 func (s *EventStoreDBStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
-	// TODO: Реализация через EventStoreDB client ReadAll
-	// stream, err := s.client.ReadAll(ctx, esdb.ReadAllOptions{
-	//     Direction: esdb.Forwards,
-	//     From:      esdb.Position{Commit: uint64(fromPosition)},
-	// })
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to read all events: %w", err)
-	// }
-	// 
-	// eventsChan := make(chan StoredEvent, 100)
-	// go func() {
-	//     defer close(eventsChan)
-	//     for {
-	//         event, err := stream.Recv()
-	//         if err == io.EOF {
-	//             break
-	//         }
-	//         if err != nil {
-	//             return
-	//         }
-	//         eventsChan <- s.convertToStoredEvent(event)
-	//     }
-	// }()
-	// 
-	// return eventsChan, nil
-
-	return nil, fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
-}
-
-// SubscribeToStream подписывается на события потока
-func (s *EventStoreDBStore) SubscribeToStream(streamName string, handler func(context.Context, StoredEvent) error) error {
-	// TODO: Реализация подписки через EventStoreDB client
-	return fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
-}
-
-// SubscribeToAll подписывается на все события
-func (s *EventStoreDBStore) SubscribeToAll(handler func(context.Context, StoredEvent) error) error {
-	// TODO: Реализация подписки через EventStoreDB client
-	return fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
-}
-
-// SubscribeToPersistent подписывается на persistent subscription
-func (s *EventStoreDBStore) SubscribeToPersistent(groupName, streamName string, handler func(context.Context, StoredEvent) error) error {
-	// TODO: Реализация persistent subscription через EventStoreDB client
-	return fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
-}
-
-// CreateProjection создает проекцию
-func (s *EventStoreDBStore) CreateProjection(name, query string) error {
-	// TODO: Реализация создания проекции через EventStoreDB HTTP API или gRPC
-	return fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
+	stream, err := s.client.ReadAll(ctx, esdb.ReadAllOptions{
+		Direction: esdb.Forwards,
+		From:      eventStoreDBAllPosition(fromPosition),
+	}, math.MaxInt64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read all events: %w", err)
+	}
+
+	ch := make(chan StoredEvent, 100)
+	go func() {
+		defer close(ch)
+		defer stream.Close()
+
+		for {
+			resolved, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			stored, ok, err := s.fromAllStream(resolved)
+			if err != nil || !ok {
+				continue
+			}
+
+			select {
+			case ch <- stored:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// SubscribeToStream создает catch-up подписку на поток агрегата начиная с версии fromVersion
+// (реализация EventSubscriber). Канал закрывается при отмене ctx или разрыве подписки.
+func (s *EventStoreDBStore) SubscribeToStream(ctx context.Context, aggregateID string, fromVersion int64) (<-chan StoredEvent, error) {
+	return subscribeWithHandler(ctx, func(ctx context.Context, handler func(context.Context, StoredEvent) error) error {
+		return s.SubscribeToStreamWithHandler(ctx, aggregateID, fromVersion, handler)
+	}), nil
+}
+
+// SubscribeToAll создает catch-up подписку на все события агрегатов хранилища начиная с позиции
+// fromPosition (реализация EventSubscriber). fromPosition должна быть позицией ранее полученного
+// события (или 0 - с начала журнала). Канал закрывается при отмене ctx или разрыве подписки.
+func (s *EventStoreDBStore) SubscribeToAll(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	return subscribeWithHandler(ctx, func(ctx context.Context, handler func(context.Context, StoredEvent) error) error {
+		if fromPosition > 0 {
+			// Подписка начинается после позиции, поэтому событие fromPosition читается отдельно
+			readCtx, cancel := context.WithCancel(ctx)
+			first, err := s.GetAllEvents(readCtx, fromPosition)
+			if err != nil {
+				cancel()
+				return err
+			}
+			event, ok := <-first
+			cancel()
+			if ok && event.Position == fromPosition {
+				if err := handler(ctx, event); err != nil {
+					return err
+				}
+			}
+		}
+		return s.SubscribeToAllWithHandler(ctx, fromPosition, handler)
+	}), nil
+}
+
+// subscribeWithHandler передает события подписки с обработчиком в канал
+func subscribeWithHandler(ctx context.Context, subscribe func(context.Context, func(context.Context, StoredEvent) error) error) <-chan StoredEvent {
+	ch := make(chan StoredEvent, subscriptionBufferSize)
+	go func() {
+		defer close(ch)
+		_ = subscribe(ctx, func(ctx context.Context, event StoredEvent) error {
+			select {
+			case ch <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return ch
+}
+
+// SubscribeToStreamWithHandler создает catch-up подписку на поток агрегата, начиная с версии fromVersion.
+// Блокирует выполнение до отмены ctx, ошибки обработчика или разрыва подписки.
+func (s *EventStoreDBStore) SubscribeToStreamWithHandler(ctx context.Context, aggregateID string, fromVersion int64, handler func(context.Context, StoredEvent) error) error {
+	var from esdb.StreamPosition = esdb.Start{}
+	if fromVersion > 1 {
+		// Подписка начинается после указанной ревизии
+		from = esdb.Revision(uint64(fromVersion - 2))
+	}
+
+	sub, err := s.client.SubscribeToStream(ctx, s.StreamName(aggregateID), esdb.SubscribeToStreamOptions{
+		From: from,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to stream: %w", err)
+	}
+	defer sub.Close()
+
+	for {
+		event := sub.Recv()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if event.SubscriptionDropped != nil {
+			return fmt.Errorf("subscription dropped: %w", event.SubscriptionDropped.Error)
+		}
+		if event.EventAppeared == nil {
+			continue
+		}
+
+		stored, err := s.toStoredEvent(event.EventAppeared.OriginalEvent())
+		if err != nil {
+			return err
+		}
+		if err := handler(ctx, stored); err != nil {
+			return err
+		}
+	}
+}
+
+// SubscribeToAllWithHandler создает catch-up подписку на все события агрегатов хранилища после позиции fromPosition
+// (позиции последнего обработанного события, 0 - с начала журнала). Системные события отфильтровываются на сервере.
+// Блокирует выполнение до отмены ctx, ошибки обработчика или разрыва подписки.
+func (s *EventStoreDBStore) SubscribeToAllWithHandler(ctx context.Context, fromPosition int64, handler func(context.Context, StoredEvent) error) error {
+	sub, err := s.client.SubscribeToAll(ctx, esdb.SubscribeToAllOptions{
+		From:   eventStoreDBAllPosition(fromPosition),
+		Filter: esdb.ExcludeSystemEventsFilter(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to $all: %w", err)
+	}
+	defer sub.Close()
+
+	for {
+		event := sub.Recv()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if event.SubscriptionDropped != nil {
+			return fmt.Errorf("subscription dropped: %w", event.SubscriptionDropped.Error)
+		}
+		if event.EventAppeared == nil {
+			continue
+		}
+
+		stored, ok, err := s.fromAllStream(event.EventAppeared)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := handler(ctx, stored); err != nil {
+			return err
+		}
+	}
+}
+
+// SubscribeToPersistent подключается к persistent subscription groupName потока агрегата.
+// Группа должна быть создана заранее. Успешно обработанные события подтверждаются (ack),
+// при ошибке обработчика событие возвращается на повторную доставку (nack retry).
+// Блокирует выполнение до отмены ctx или разрыва подписки.
+func (s *EventStoreDBStore) SubscribeToPersistent(ctx context.Context, groupName, aggregateID string, handler func(context.Context, StoredEvent) error) error {
+	sub, err := s.client.SubscribeToPersistentSubscription(ctx, s.StreamName(aggregateID), groupName, esdb.SubscribeToPersistentSubscriptionOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to persistent subscription: %w", err)
+	}
+	defer sub.Close()
+
+	for {
+		event := sub.Recv()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if event.SubscriptionDropped != nil {
+			return fmt.Errorf("subscription dropped: %w", event.SubscriptionDropped.Error)
+		}
+		if event.EventAppeared == nil {
+			continue
+		}
+
+		resolved := event.EventAppeared.Event
+		stored, err := s.toStoredEvent(resolved.OriginalEvent())
+		if err == nil {
+			err = handler(ctx, stored)
+		}
+		if err != nil {
+			if nackErr := sub.Nack(err.Error(), esdb.NackActionRetry, resolved); nackErr != nil {
+				return fmt.Errorf("failed to nack event: %w", nackErr)
+			}
+			continue
+		}
+		if err := sub.Ack(resolved); err != nil {
+			return fmt.Errorf("failed to ack event: %w", err)
+		}
+	}
+}
+
+// CreateProjection создает continuous проекцию EventStoreDB
+func (s *EventStoreDBStore) CreateProjection(ctx context.Context, name, query string) error {
+	if err := s.projections.Create(ctx, name, query, esdb.CreateProjectionOptions{}); err != nil {
+		return fmt.Errorf("failed to create projection %s: %w", name, err)
+	}
+	return nil
 }
 
 // EnableProjection включает проекцию
-func (s *EventStoreDBStore) EnableProjection(name string) error {
-	// TODO: Реализация через EventStoreDB HTTP API
-	return fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
+func (s *EventStoreDBStore) EnableProjection(ctx context.Context, name string) error {
+	if err := s.projections.Enable(ctx, name, esdb.GenericProjectionOptions{}); err != nil {
+		return fmt.Errorf("failed to enable projection %s: %w", name, err)
+	}
+	return nil
 }
 
 // DisableProjection отключает проекцию
-func (s *EventStoreDBStore) DisableProjection(name string) error {
-	// TODO: Реализация через EventStoreDB HTTP API
-	return fmt.Errorf("EventStoreDB adapter not fully implemented - requires stable Go client")
+func (s *EventStoreDBStore) DisableProjection(ctx context.Context, name string) error {
+	if err := s.projections.Disable(ctx, name, esdb.GenericProjectionOptions{}); err != nil {
+		return fmt.Errorf("failed to disable projection %s: %w", name, err)
+	}
+	return nil
+}
+
+// EnsureByEventTypeProjection включает системную проекцию $by_event_type,
+// которая необходима для GetEventsByType
+func (s *EventStoreDBStore) EnsureByEventTypeProjection(ctx context.Context) error {
+	return s.EnableProjection(ctx, eventStoreDBByEventTypeProjection)
+}
+
+// fromAllStream преобразует событие из $all (false - событие не принадлежит агрегатам хранилища)
+func (s *EventStoreDBStore) fromAllStream(resolved *esdb.ResolvedEvent) (StoredEvent, bool, error) {
+	recorded := resolved.OriginalEvent()
+	if recorded == nil || strings.HasPrefix(recorded.EventType, "$") {
+		return StoredEvent{}, false, nil
+	}
+	if _, ok := s.aggregateIDFromStream(recorded.StreamID); !ok {
+		return StoredEvent{}, false, nil
+	}
+
+	stored, err := s.toStoredEvent(recorded)
+	if err != nil {
+		return StoredEvent{}, false, err
+	}
+	return stored, true, nil
+}
+
+// toStoredEvent преобразует событие EventStoreDB в StoredEvent
+func (s *EventStoreDBStore) toStoredEvent(recorded *esdb.RecordedEvent) (StoredEvent, error) {
+	stored := StoredEvent{
+		ID:            recorded.EventID.String(),
+		EventType:     recorded.EventType,
+		Version:       int64(recorded.EventNumber) + 1,
+		Position:      int64(recorded.Position.Commit),
+		CreatedAt:     recorded.CreatedDate,
+		SchemaVersion: DefaultSchemaVersion,
+	}
+
+	if len(recorded.UserMetadata) > 0 {
+		var metadata eventStoreDBMetadata
+		if err := json.Unmarshal(recorded.UserMetadata, &metadata); err != nil {
+			return StoredEvent{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+		stored.AggregateID = metadata.AggregateID
+		stored.AggregateType = metadata.AggregateType
+		stored.OccurredAt = metadata.OccurredAt
+		stored.Metadata = metadata.Metadata
+		if metadata.SchemaVersion > 0 {
+			stored.SchemaVersion = metadata.SchemaVersion
+		}
+	}
+
+	if stored.AggregateID == "" {
+		stored.AggregateID, _ = s.aggregateIDFromStream(recorded.StreamID)
+	}
+	if stored.OccurredAt.IsZero() {
+		stored.OccurredAt = recorded.CreatedDate
+	}
+
+	if s.deserializer != nil {
+		event, err := deserializeStoredEvent(s.deserializer, stored.EventType, stored.SchemaVersion, recorded.Data)
+		if err != nil {
+			return StoredEvent{}, fmt.Errorf("%w: %w", ErrEventDeserializationFailed, err)
+		}
+		stored.EventData = event
+	} else {
+		var baseEvent events.BaseEvent
+		if err := json.Unmarshal(recorded.Data, &baseEvent); err == nil {
+			stored.EventData = &baseEvent
+		}
+	}
+
+	return stored, nil
 }
 
+// eventStoreDBAllPosition преобразует позицию StoredEvent в позицию журнала $all
+func eventStoreDBAllPosition(position int64) esdb.AllPosition {
+	if position <= 0 {
+		return esdb.Start{}
+	}
+	return esdb.Position{Commit: uint64(position), Prepare: uint64(position)}
+}
+
+// EventStoreDBSnapshotStore реализация SnapshotStore для EventStoreDB.
+// Снапшоты агрегата хранятся в потоке "<prefix>_snapshot-<aggregateID>",
+// размер которого ограничивается метаданными потока ($maxCount).
+type EventStoreDBSnapshotStore struct {
+	config EventStoreDBConfig
+	client *esdb.Client
+	signer SnapshotSigner
+}
+
+// NewEventStoreDBSnapshotStore создает новый EventStoreDB Snapshot Store
+func NewEventStoreDBSnapshotStore(config EventStoreDBConfig) (*EventStoreDBSnapshotStore, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid eventstoredb config: %w", err)
+	}
+	config = config.withDefaultStreamPrefix()
+
+	client, err := newEventStoreDBClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventStoreDBSnapshotStore{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// WithSigner включает подпись снапшотов и проверку подписи при загрузке
+func (s *EventStoreDBSnapshotStore) WithSigner(signer SnapshotSigner) *EventStoreDBSnapshotStore {
+	s.signer = signer
+	return s
+}
+
+// streamName возвращает имя потока снапшотов агрегата
+func (s *EventStoreDBSnapshotStore) streamName(aggregateID string) string {
+	return s.config.StreamPrefix + "_snapshot-" + aggregateID
+}
+
+// SaveSnapshot сохраняет снапшот
+func (s *EventStoreDBSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	if err := SealSnapshot(&snapshot, s.signer); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	streamName := s.streamName(snapshot.AggregateID)
+	result, err := s.client.AppendToStream(ctx, streamName, esdb.AppendToStreamOptions{
+		ExpectedRevision: esdb.Any{},
+	}, esdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   eventStoreDBSnapshotEventType,
+		ContentType: esdb.ContentTypeJson,
+		Data:        data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	// Первый снапшот агрегата: ограничиваем размер потока
+	if result.NextExpectedVersion == 0 && s.config.SnapshotMaxCount > 0 {
+		var metadata esdb.StreamMetadata
+		metadata.SetMaxCount(s.config.SnapshotMaxCount)
+		if _, err := s.client.SetStreamMetadata(ctx, streamName, esdb.AppendToStreamOptions{
+			ExpectedRevision: esdb.Any{},
+		}, metadata); err != nil {
+			return fmt.Errorf("failed to set snapshot stream metadata: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetSnapshot возвращает последний снапшот агрегата
+func (s *EventStoreDBSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
+	stream, err := s.client.ReadStream(ctx, s.streamName(aggregateID), esdb.ReadStreamOptions{
+		Direction: esdb.Backwards,
+		From:      esdb.End{},
+	}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot stream: %w", err)
+	}
+	defer stream.Close()
+
+	resolved, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) ||
+			isEventStoreDBError(err, esdb.ErrorCodeResourceNotFound) ||
+			isEventStoreDBError(err, esdb.ErrorCodeStreamDeleted) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(resolved.OriginalEvent().Data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	if err := VerifySnapshot(&snapshot, s.signer); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// DeleteSnapshots удаляет поток снапшотов, если последний снапшот старше beforeVersion
+func (s *EventStoreDBSnapshotStore) DeleteSnapshots(ctx context.Context, aggregateID string, beforeVersion int64) error {
+	snapshot, err := s.GetSnapshot(ctx, aggregateID)
+	if err != nil {
+		return err
+	}
+	if snapshot == nil || snapshot.Version >= beforeVersion {
+		return nil
+	}
+
+	_, err = s.client.DeleteStream(ctx, s.streamName(aggregateID), esdb.DeleteStreamOptions{
+		ExpectedRevision: esdb.Any{},
+	})
+	if err != nil && !isEventStoreDBError(err, esdb.ErrorCodeResourceNotFound) {
+		return fmt.Errorf("failed to delete snapshots: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с EventStoreDB
+func (s *EventStoreDBSnapshotStore) Close() error {
+	return s.client.Close()
+}
@@ -10,7 +10,9 @@ import (
 //   - InMemory (для тестов)
 //   - Postgres (production-ready)
 //   - MongoDB (production-ready)
-//   - EventStoreDB (базовая реализация готова, требует стабильного Go клиента)
+//   - EventStoreDB (production-ready)
+//   - SQLite (локальная разработка, CLI, тесты)
+//   - MySQL (MySQL 8)
 type EventStoreFactory struct{}
 
 // NewEventStoreFactory создает новую фабрику Event Store
@@ -38,6 +40,16 @@ func (f *EventStoreFactory) CreateEventStoreDB(config EventStoreDBConfig) (*Even
 	return NewEventStoreDBStore(config)
 }
 
+// CreateSQLite создает SQLite Event Store
+func (f *EventStoreFactory) CreateSQLite(config SQLiteEventStoreConfig) (*SQLiteEventStore, error) {
+	return NewSQLiteEventStore(config)
+}
+
+// CreateMySQL создает MySQL Event Store
+func (f *EventStoreFactory) CreateMySQL(config MySQLEventStoreConfig) (*MySQLEventStore, error) {
+	return NewMySQLEventStore(config)
+}
+
 // SnapshotStoreFactory фабрика для создания Snapshot Store адаптеров
 type SnapshotStoreFactory struct{}
 
@@ -61,6 +73,31 @@ func (f *SnapshotStoreFactory) CreateMongoDB(config MongoDBEventStoreConfig) (*M
 	return NewMongoDBSnapshotStore(config)
 }
 
+// CreateEventStoreDB создает EventStoreDB Snapshot Store
+func (f *SnapshotStoreFactory) CreateEventStoreDB(config EventStoreDBConfig) (*EventStoreDBSnapshotStore, error) {
+	return NewEventStoreDBSnapshotStore(config)
+}
+
+// CreateSQLite создает SQLite Snapshot Store
+func (f *SnapshotStoreFactory) CreateSQLite(config SQLiteEventStoreConfig) (*SQLiteSnapshotStore, error) {
+	return NewSQLiteSnapshotStore(config)
+}
+
+// CreateMySQL создает MySQL Snapshot Store
+func (f *SnapshotStoreFactory) CreateMySQL(config MySQLEventStoreConfig) (*MySQLSnapshotStore, error) {
+	return NewMySQLSnapshotStore(config)
+}
+
+// CreateRedis создает Redis Snapshot Store
+func (f *SnapshotStoreFactory) CreateRedis(config RedisSnapshotStoreConfig) (*RedisSnapshotStore, error) {
+	return NewRedisSnapshotStore(config)
+}
+
+// CreateTiered создает двухуровневый Snapshot Store (hot tier + durable tier)
+func (f *SnapshotStoreFactory) CreateTiered(hot, durable SnapshotStore) *TieredSnapshotStore {
+	return NewTieredSnapshotStore(hot, durable)
+}
+
 // RepositoryFactory фабрика для создания Event Sourced репозиториев
 type RepositoryFactory struct{}
 
@@ -132,6 +169,18 @@ func (b *EventSourcingBuilder) WithSnapshotFrequency(frequency int) *EventSourci
 	return b
 }
 
+// WithConflictRetry устанавливает политику повторов при конфликте версий
+func (b *EventSourcingBuilder) WithConflictRetry(policy *ConflictRetryPolicy) *EventSourcingBuilder {
+	b.config.ConflictRetry = policy
+	return b
+}
+
+// WithQuarantine включает карантин агрегатов, которые не удается восстановить из событий
+func (b *EventSourcingBuilder) WithQuarantine(policy *QuarantinePolicy) *EventSourcingBuilder {
+	b.config.Quarantine = policy
+	return b
+}
+
 // WithSnapshotsEnabled включает/выключает использование снапшотов
 func (b *EventSourcingBuilder) WithSnapshotsEnabled(enabled bool) *EventSourcingBuilder {
 	b.config.UseSnapshots = enabled
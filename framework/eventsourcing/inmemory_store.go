@@ -3,6 +3,7 @@ package eventsourcing
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,32 +22,61 @@ func DefaultInMemoryEventStoreConfig() InMemoryEventStoreConfig {
 	}
 }
 
+// inMemoryStreamKey идентификатор потока агрегата в рамках тенанта
+type inMemoryStreamKey struct {
+	tenantID    string
+	aggregateID string
+}
+
 // InMemoryEventStore реализация EventStore в памяти для тестирования и разработки
 type InMemoryEventStore struct {
-	mu          sync.RWMutex
-	streams     map[string][]StoredEvent
-	allEvents   []StoredEvent
-	position    int64
-	config      InMemoryEventStoreConfig
+	mu             sync.RWMutex
+	streams        map[inMemoryStreamKey][]StoredEvent
+	allEvents      []StoredEvent
+	byType         map[string][]int // индексы allEvents по типу события
+	position       int64
+	config         InMemoryEventStoreConfig
+	changes        changeFeed
+	tenantResolver TenantResolver
 }
 
 // NewInMemoryEventStore создает новый InMemory Event Store
 func NewInMemoryEventStore(config InMemoryEventStoreConfig) *InMemoryEventStore {
 	return &InMemoryEventStore{
-		streams:   make(map[string][]StoredEvent),
+		streams:   make(map[inMemoryStreamKey][]StoredEvent),
 		allEvents: make([]StoredEvent, 0),
+		byType:    make(map[string][]int),
 		position:  0,
 		config:    config,
 	}
 }
 
+// WithTenantResolver включает изоляцию тенантов (см. PostgresEventStore.WithTenantResolver):
+// потоки агрегатов идентифицируются парой (тенант, агрегат), а чтение глобального
+// потока, событий по типу и списка агрегатов ограничено тенантом операции
+func (s *InMemoryEventStore) WithTenantResolver(resolver TenantResolver) *InMemoryEventStore {
+	s.tenantResolver = resolver
+	return s
+}
+
+// TenantIsolation сообщает, включена ли изоляция тенантов (реализация TenantIsolatedStore)
+func (s *InMemoryEventStore) TenantIsolation() bool {
+	return s.tenantResolver != nil
+}
+
 // AppendEvents добавляет события в поток агрегата
 func (s *InMemoryEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, events []events.Event) error {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// Получаем текущий поток
-	stream, exists := s.streams[aggregateID]
+	key := inMemoryStreamKey{tenantID: tenantID, aggregateID: aggregateID}
+	stream, exists := s.streams[key]
 	currentVersion := int64(0)
 	if exists {
 		if len(stream) > 0 {
@@ -81,21 +111,84 @@ func (s *InMemoryEventStore) AppendEvents(ctx context.Context, aggregateID strin
 			Position:     s.position,
 			OccurredAt:   event.OccurredAt(),
 			CreatedAt:    time.Now(),
+			TenantID:     tenantID,
 		}
 		stream = append(stream, storedEvent)
-		s.allEvents = append(s.allEvents, storedEvent)
+		s.appendToAll(storedEvent)
+	}
+
+	s.streams[key] = stream
+	return nil
+}
+
+// AppendEventsBatch атомарно добавляет события нескольких агрегатов (реализация BatchAppender)
+func (s *InMemoryEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
 	}
 
-	s.streams[aggregateID] = stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Проверяем все потоки до записи, чтобы пакет применился целиком
+	currentVersions := make(map[string]int64, len(batches))
+	for _, batch := range batches {
+		if _, checked := currentVersions[batch.AggregateID]; checked {
+			continue
+		}
+		if stream := s.streams[inMemoryStreamKey{tenantID: tenantID, aggregateID: batch.AggregateID}]; len(stream) > 0 {
+			currentVersions[batch.AggregateID] = stream[len(stream)-1].Version
+		} else {
+			currentVersions[batch.AggregateID] = 0
+		}
+	}
+	if err := checkBatchVersions(batches, currentVersions); err != nil {
+		return err
+	}
+	if s.config.MaxEventsPerStream > 0 {
+		for aggregateID, version := range currentVersions {
+			if version > s.config.MaxEventsPerStream {
+				return fmt.Errorf("max events per stream exceeded for aggregate %s: %d (limit: %d)", aggregateID, version, s.config.MaxEventsPerStream)
+			}
+		}
+	}
+
+	for _, batch := range batches {
+		for i, event := range batch.Events {
+			s.position++
+			storedEvent := StoredEvent{
+				ID:            event.EventID(),
+				AggregateID:   batch.AggregateID,
+				AggregateType: getAggregateType(event),
+				EventType:     event.EventType(),
+				EventData:     event,
+				Metadata:      convertMetadata(event.Metadata()),
+				Version:       batch.ExpectedVersion + int64(i) + 1,
+				Position:      s.position,
+				OccurredAt:    event.OccurredAt(),
+				CreatedAt:     time.Now(),
+				TenantID:      tenantID,
+			}
+			key := inMemoryStreamKey{tenantID: tenantID, aggregateID: batch.AggregateID}
+			s.streams[key] = append(s.streams[key], storedEvent)
+			s.appendToAll(storedEvent)
+		}
+	}
 	return nil
 }
 
 // GetEvents возвращает события агрегата начиная с указанной версии
 func (s *InMemoryEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	stream, exists := s.streams[aggregateID]
+	stream, exists := s.streams[inMemoryStreamKey{tenantID: tenantID, aggregateID: aggregateID}]
 	if !exists {
 		return nil, ErrStreamNotFound
 	}
@@ -110,14 +203,47 @@ func (s *InMemoryEventStore) GetEvents(ctx context.Context, aggregateID string,
 	return result, nil
 }
 
+// GetEventsPage возвращает не более limit событий агрегата начиная с указанной версии
+func (s *InMemoryEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stream, exists := s.streams[inMemoryStreamKey{tenantID: tenantID, aggregateID: aggregateID}]
+	if !exists {
+		return nil, ErrStreamNotFound
+	}
+
+	// События потока упорядочены по версии, начало страницы находится бинарным поиском
+	start := sort.Search(len(stream), func(i int) bool {
+		return stream[i].Version >= fromVersion
+	})
+	end := len(stream)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	result := make([]StoredEvent, end-start)
+	copy(result, stream[start:end])
+	return result, nil
+}
+
 // GetEventsByType возвращает события определенного типа
 func (s *InMemoryEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var result []StoredEvent
-	for _, event := range s.allEvents {
-		if event.EventType == eventType && event.OccurredAt.After(fromTimestamp) {
+	for _, i := range s.byType[eventType] {
+		if event := s.allEvents[i]; event.TenantID == tenantID && event.OccurredAt.After(fromTimestamp) {
 			result = append(result, event)
 		}
 	}
@@ -125,8 +251,62 @@ func (s *InMemoryEventStore) GetEventsByType(ctx context.Context, eventType stri
 	return result, nil
 }
 
-// GetAllEvents возвращает все события начиная с указанной позиции
+// RenameEventType заменяет тип сохраненных событий (реализация EventTypeRenamer)
+func (s *InMemoryEventStore) RenameEventType(ctx context.Context, oldType, newType string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	renamed := s.byType[oldType]
+	for _, i := range renamed {
+		s.allEvents[i].EventType = newType
+	}
+	for _, stream := range s.streams {
+		for i := range stream {
+			if stream[i].EventType == oldType {
+				stream[i].EventType = newType
+			}
+		}
+	}
+
+	if len(renamed) > 0 {
+		indexes := append(s.byType[newType], renamed...)
+		sort.Ints(indexes)
+		s.byType[newType] = indexes
+		delete(s.byType, oldType)
+	}
+	return int64(len(renamed)), nil
+}
+
+// appendToAll добавляет событие в глобальный поток и индекс по типу (вызывается под s.mu)
+// и уведомляет подписки о новом событии
+func (s *InMemoryEventStore) appendToAll(event StoredEvent) {
+	s.byType[event.EventType] = append(s.byType[event.EventType], len(s.allEvents))
+	s.allEvents = append(s.allEvents, event)
+	s.changes.notify()
+}
+
+// WatchChanges возвращает канал сигналов о записи событий (реализация ChangeNotifier)
+func (s *InMemoryEventStore) WatchChanges(ctx context.Context) (<-chan struct{}, error) {
+	return s.changes.watch(ctx, nil), nil
+}
+
+// SubscribeToStream подписывается на события агрегата начиная с версии fromVersion (реализация EventSubscriber)
+func (s *InMemoryEventStore) SubscribeToStream(ctx context.Context, aggregateID string, fromVersion int64) (<-chan StoredEvent, error) {
+	return NewPollingSubscriber(s).SubscribeToStream(ctx, aggregateID, fromVersion)
+}
+
+// SubscribeToAll подписывается на все события начиная с позиции fromPosition (реализация EventSubscriber)
+func (s *InMemoryEventStore) SubscribeToAll(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	return NewPollingSubscriber(s).SubscribeToAll(ctx, fromPosition)
+}
+
+// GetAllEvents возвращает все события тенанта начиная с указанной позиции
 func (s *InMemoryEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -138,7 +318,7 @@ func (s *InMemoryEventStore) GetAllEvents(ctx context.Context, fromPosition int6
 		defer s.mu.RUnlock()
 
 		for _, event := range s.allEvents {
-			if event.Position >= fromPosition {
+			if event.Position >= fromPosition && event.TenantID == tenantID {
 				select {
 				case ch <- event:
 				case <-ctx.Done():
@@ -151,12 +331,90 @@ func (s *InMemoryEventStore) GetAllEvents(ctx context.Context, fromPosition int6
 	return ch, nil
 }
 
+// TruncateStream удаляет из потока агрегата события с версией меньше beforeVersion
+// (реализация EventStreamTruncator). Глобальный лог GetAllEvents не изменяется.
+func (s *InMemoryEventStore) TruncateStream(ctx context.Context, aggregateID string, beforeVersion int64) error {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := inMemoryStreamKey{tenantID: tenantID, aggregateID: aggregateID}
+	stream, exists := s.streams[key]
+	if !exists {
+		return nil
+	}
+
+	kept := make([]StoredEvent, 0, len(stream))
+	for _, event := range stream {
+		if event.Version >= beforeVersion {
+			kept = append(kept, event)
+		}
+	}
+	s.streams[key] = kept
+	return nil
+}
+
+// ListStaleStreams возвращает агрегаты, последнее событие которых произошло раньше before
+// (реализация StaleStreamLister)
+func (s *InMemoryEventStore) ListStaleStreams(ctx context.Context, before time.Time, after string, limit int) ([]string, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []string
+	for key, stream := range s.streams {
+		if key.tenantID != tenantID || key.aggregateID <= after || len(stream) < 2 {
+			continue
+		}
+		if stream[len(stream)-1].OccurredAt.Before(before) {
+			result = append(result, key.aggregateID)
+		}
+	}
+
+	sort.Strings(result)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// ListAggregates возвращает страницу агрегатов (реализация AggregateLister)
+func (s *InMemoryEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make(map[string]*AggregateSummary, len(s.streams))
+	for key, stream := range s.streams {
+		if key.tenantID != tenantID {
+			continue
+		}
+		for _, event := range stream {
+			addToAggregateSummary(summaries, event)
+		}
+	}
+	return pageAggregates(summaries, filter), nil
+}
+
 // Clear очищает все события (для тестов)
 func (s *InMemoryEventStore) Clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.streams = make(map[string][]StoredEvent)
+	s.streams = make(map[inMemoryStreamKey][]StoredEvent)
 	s.allEvents = make([]StoredEvent, 0)
+	s.byType = make(map[string][]int)
 	s.position = 0
 }
 
@@ -0,0 +1,19 @@
+package eventsourcing
+
+import (
+	"embed"
+
+	"github.com/akriventsev/potter/framework/migrations"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migrations возвращает встроенные SQL миграции пакета для диалекта (postgres, mysql)
+// для применения через migrations.RunMigrationSets при старте сервиса
+func Migrations(dialect string) (migrations.MigrationSet, error) {
+	return migrations.NewEmbeddedMigrationSet("eventsourcing", migrationFiles, "migrations", dialect)
+}
+
+// projectionCheckpointsMigration версия миграции таблицы projection_checkpoints (PostgresCheckpointStore)
+const projectionCheckpointsMigration = 10
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Миграция для создания таблиц Event Store и Snapshots в MySQL 8
+-- Версия: 001
+-- Схема соответствует таблицам, которые MySQLEventStore создает при открытии базы
+
+-- Таблица для хранения событий
+CREATE TABLE IF NOT EXISTS event_store (
+    position BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    id VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    aggregate_id VARCHAR(255) NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL DEFAULT '',
+    event_type VARCHAR(255) NOT NULL,
+    event_data JSON NOT NULL,
+    metadata JSON NOT NULL,
+    version BIGINT NOT NULL,
+    schema_version INT NOT NULL DEFAULT 1,
+    occurred_at DATETIME(6) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    UNIQUE KEY uq_event_store_stream (tenant_id, aggregate_id, version),
+    KEY idx_event_store_event_type (tenant_id, event_type, occurred_at),
+    KEY idx_event_store_tenant_position (tenant_id, position)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Хранит все события агрегатов в порядке добавления';
+
+-- Таблица для хранения снапшотов
+CREATE TABLE IF NOT EXISTS snapshots (
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    aggregate_id VARCHAR(255) NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL DEFAULT '',
+    version BIGINT NOT NULL,
+    state LONGBLOB,
+    state_encoding VARCHAR(255) NOT NULL DEFAULT '',
+    metadata JSON NOT NULL,
+    checksum VARCHAR(128) NOT NULL DEFAULT '',
+    signature VARCHAR(512) NOT NULL DEFAULT '',
+    created_at DATETIME(6) NOT NULL,
+    updated_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (tenant_id, aggregate_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Хранит последний снапшот каждого агрегата';
@@ -1,3 +1,4 @@
+-- +goose Up
 -- Миграция для создания таблиц Event Store и Snapshots в PostgreSQL
 -- Версия: 001
 -- Дата: 2025-01-XX
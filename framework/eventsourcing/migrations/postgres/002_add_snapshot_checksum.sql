@@ -0,0 +1,9 @@
+-- +goose Up
+-- Миграция для добавления контрольных сумм и подписей снапшотов
+-- Версия: 002
+
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS checksum VARCHAR(64);
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS signature VARCHAR(128);
+
+COMMENT ON COLUMN snapshots.checksum IS 'SHA-256 контрольная сумма состояния снапшота';
+COMMENT ON COLUMN snapshots.signature IS 'Опциональная подпись контрольной суммы (HMAC-SHA256)';
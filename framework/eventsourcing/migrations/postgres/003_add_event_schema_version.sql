@@ -0,0 +1,8 @@
+-- +goose Up
+-- Миграция для добавления версии схемы событий (upcasting)
+-- Версия: 003
+
+-- События, сохраненные до миграции, считаются версией 1
+ALTER TABLE event_store ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN event_store.schema_version IS 'Версия схемы данных события для upcasting';
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Миграция для изоляции тенантов в общем Event Store
+-- Версия: 004
+
+-- События и снапшоты, сохраненные до миграции, принадлежат тенанту по умолчанию ('')
+ALTER TABLE event_store ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Поток агрегата идентифицируется парой (tenant_id, aggregate_id)
+DROP INDEX IF EXISTS idx_event_store_aggregate_version;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_store_tenant_aggregate_version
+    ON event_store(tenant_id, aggregate_id, version);
+
+CREATE INDEX IF NOT EXISTS idx_event_store_tenant_position
+    ON event_store(tenant_id, position);
+
+CREATE INDEX IF NOT EXISTS idx_event_store_tenant_event_type
+    ON event_store(tenant_id, event_type);
+
+COMMENT ON COLUMN event_store.tenant_id IS 'Идентификатор тенанта (пустая строка - тенант по умолчанию)';
+
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE snapshots DROP CONSTRAINT IF EXISTS snapshots_pkey;
+ALTER TABLE snapshots ADD PRIMARY KEY (tenant_id, aggregate_id);
+
+COMMENT ON COLUMN snapshots.tenant_id IS 'Идентификатор тенанта (пустая строка - тенант по умолчанию)';
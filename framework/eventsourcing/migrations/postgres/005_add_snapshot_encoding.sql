@@ -0,0 +1,11 @@
+-- +goose Up
+-- Миграция для хранения сжатого и зашифрованного состояния снапшотов (SnapshotCodec)
+-- Версия: 005
+
+ALTER TABLE snapshots ALTER COLUMN state DROP NOT NULL;
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS state_encoded BYTEA;
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS state_encoding VARCHAR(128) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN snapshots.state IS 'Сериализованное состояние агрегата (NULL, если состояние закодировано)';
+COMMENT ON COLUMN snapshots.state_encoded IS 'Состояние агрегата, закодированное SnapshotCodec (сжатие, шифрование)';
+COMMENT ON COLUMN snapshots.state_encoding IS 'Кодеки состояния через "+", например zstd+aes-gcm (пустая строка - без кодирования)';
@@ -0,0 +1,66 @@
+-- +goose Up
+-- Миграция для чтения событий по типу (GetEventsByType) без полного сканирования
+-- Версия: 006
+
+-- Индекс покрывает фильтр GetEventsByType (tenant_id, event_type, occurred_at >= $3)
+-- и заменяет индекс (tenant_id, event_type)
+CREATE INDEX IF NOT EXISTS idx_event_store_tenant_event_type_occurred_at
+    ON event_store(tenant_id, event_type, occurred_at);
+
+DROP INDEX IF EXISTS idx_event_store_tenant_event_type;
+
+-- Типы событий, для которых ведется материализованный поток
+-- (регистрируются через PostgresEventStore.MaterializeEventType)
+CREATE TABLE IF NOT EXISTS event_store_materialized_types (
+    event_type VARCHAR(255) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE event_store_materialized_types IS 'Типы событий с материализованным потоком';
+
+-- Материализованные потоки: копии событий высокочастотных типов,
+-- физически сгруппированные по (tenant_id, event_type)
+CREATE TABLE IF NOT EXISTS event_store_type_streams (
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    event_type VARCHAR(255) NOT NULL,
+    position BIGINT NOT NULL,
+    id UUID NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL,
+    event_data JSONB NOT NULL,
+    metadata JSONB DEFAULT '{}',
+    version BIGINT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    schema_version INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (tenant_id, event_type, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_store_type_streams_occurred_at
+    ON event_store_type_streams(tenant_id, event_type, occurred_at);
+
+COMMENT ON TABLE event_store_type_streams IS 'Материализованные потоки событий высокочастотных типов';
+
+-- Копирование событий материализованных типов при записи (в т.ч. через COPY)
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION event_store_materialize_type_stream() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM event_store_materialized_types WHERE event_type = NEW.event_type) THEN
+        INSERT INTO event_store_type_streams (
+            tenant_id, event_type, position, id, aggregate_id, aggregate_type,
+            event_data, metadata, version, occurred_at, created_at, schema_version
+        ) VALUES (
+            NEW.tenant_id, NEW.event_type, NEW.position, NEW.id, NEW.aggregate_id, NEW.aggregate_type,
+            NEW.event_data, NEW.metadata, NEW.version, NEW.occurred_at, NEW.created_at, NEW.schema_version
+        )
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_event_store_materialize_type_stream ON event_store;
+CREATE TRIGGER trg_event_store_materialize_type_stream
+    AFTER INSERT ON event_store
+    FOR EACH ROW EXECUTE FUNCTION event_store_materialize_type_stream();
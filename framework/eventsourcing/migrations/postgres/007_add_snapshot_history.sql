@@ -0,0 +1,28 @@
+-- +goose Up
+-- Миграция для политики хранения снапшотов (SnapshotRetentionPolicy)
+-- Версия: 007
+
+-- История замененных снапшотов (ведется при KeepLast > 1)
+CREATE TABLE IF NOT EXISTS snapshot_history (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    aggregate_id VARCHAR(255) NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL,
+    version BIGINT NOT NULL,
+    state JSONB,
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    checksum VARCHAR(64),
+    signature VARCHAR(128),
+    state_encoded BYTEA,
+    state_encoding VARCHAR(128) NOT NULL DEFAULT '',
+    UNIQUE (tenant_id, aggregate_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshot_history_created_at ON snapshot_history(created_at);
+
+-- Очистка снапшотов по возрасту (SnapshotRetentionPolicy.MaxAge)
+CREATE INDEX IF NOT EXISTS idx_snapshots_created_at ON snapshots(created_at);
+
+COMMENT ON TABLE snapshot_history IS 'Замененные снапшоты агрегатов, очищаются по политике хранения';
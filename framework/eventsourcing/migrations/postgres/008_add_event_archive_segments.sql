@@ -0,0 +1,33 @@
+-- +goose Up
+-- Миграция для архивации потоков событий в объектное хранилище (StreamArchiver)
+-- Версия: 008
+
+-- Указатели на сегменты потоков, перенесенные в архив (PostgresArchiveIndex)
+CREATE TABLE IF NOT EXISTS event_store_archive_segments (
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    aggregate_id VARCHAR(255) NOT NULL,
+    object_key TEXT NOT NULL,
+    format VARCHAR(64) NOT NULL,
+    from_version BIGINT NOT NULL,
+    to_version BIGINT NOT NULL,
+    from_position BIGINT NOT NULL DEFAULT 0,
+    to_position BIGINT NOT NULL DEFAULT 0,
+    event_count BIGINT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64) NOT NULL DEFAULT '',
+    archived_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, object_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_store_archive_segments_aggregate
+    ON event_store_archive_segments(tenant_id, aggregate_id, from_version);
+
+-- Чтение архива в порядке глобальной позиции (ArchivedEventStore.GetAllEvents)
+CREATE INDEX IF NOT EXISTS idx_event_store_archive_segments_position
+    ON event_store_archive_segments(tenant_id, to_position);
+
+COMMENT ON TABLE event_store_archive_segments IS 'Сегменты потоков событий, перенесенные в объектное хранилище';
+
+-- Поиск потоков для архивации (ListStaleStreams)
+CREATE INDEX IF NOT EXISTS idx_event_store_tenant_aggregate_occurred_at
+    ON event_store(tenant_id, aggregate_id, occurred_at);
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Миграция для индекса причинности (CausationProjection / PostgresCausationIndex)
+-- Версия: 009
+-- Индекс заполняется проекцией; для существующих событий выполните rebuild проекции causation_graph
+
+CREATE TABLE IF NOT EXISTS causation_index (
+    event_id VARCHAR(255) PRIMARY KEY,
+    event_type VARCHAR(255) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    causation_id VARCHAR(255) NOT NULL DEFAULT '',
+    saga_id VARCHAR(255) NOT NULL DEFAULT '',
+    position BIGINT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_causation_correlation ON causation_index(correlation_id) WHERE correlation_id <> '';
+CREATE INDEX IF NOT EXISTS idx_causation_causation ON causation_index(causation_id) WHERE causation_id <> '';
+
+COMMENT ON TABLE causation_index IS 'Связи событий с командами, событиями-причинами и сагами для запросов графа причинности';
+COMMENT ON COLUMN causation_index.causation_id IS 'ID события или команды, вызвавшей событие';
+COMMENT ON COLUMN causation_index.saga_id IS 'Сага, в рамках которой возникло событие';
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Миграция для хранения позиций проекций (PostgresCheckpointStore)
+-- Версия: 010
+-- PostgresCheckpointStore выполняет эту миграцию при подключении, поэтому она идемпотентна
+
+CREATE TABLE IF NOT EXISTS projection_checkpoints (
+    projection_name VARCHAR(255) PRIMARY KEY,
+    position BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE projection_checkpoints IS 'Позиции проекций в глобальном потоке событий';
@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/metrics"
 )
 
 // MongoDBEventStoreConfig конфигурация для MongoDB Event Store
@@ -48,7 +50,9 @@ func DefaultMongoDBEventStoreConfig() MongoDBEventStoreConfig {
 	}
 }
 
-// MongoDBEventStore реализация EventStore для MongoDB
+// MongoDBEventStore реализация EventStore для MongoDB.
+// Изоляция тенантов не поддерживается: тенант из контекста игнорируется,
+// а NewTenantEventStore поверх хранилища возвращает ErrTenantIsolationUnsupported.
 type MongoDBEventStore struct {
 	config       MongoDBEventStoreConfig
 	client       *mongo.Client
@@ -215,6 +219,7 @@ func (s *MongoDBEventStore) AppendEvents(ctx context.Context, aggregateID string
 				"position":      position,
 				"occurred_at":   event.OccurredAt(),
 				"created_at":    time.Now(),
+				"schema_version": eventSchemaVersion(s.deserializer, event),
 			}
 			docs[i] = doc
 		}
@@ -231,13 +236,44 @@ func (s *MongoDBEventStore) AppendEvents(ctx context.Context, aggregateID string
 	return err
 }
 
+// WithUpcasters включает приведение старых версий событий к актуальной схеме.
+// Upcaster'ы применяются перед десериализацией, поэтому требуется десериализатор
+// (NewMongoDBEventStoreWithDeserializer).
+func (s *MongoDBEventStore) WithUpcasters(chain *UpcasterChain) *MongoDBEventStore {
+	if s.deserializer != nil {
+		s.deserializer = NewUpcastingDeserializer(s.deserializer, chain)
+	}
+	return s
+}
+
+// mongoSchemaVersion возвращает версию схемы события (документы без поля считаются версией 1)
+func mongoSchemaVersion(doc bson.M) int {
+	if version := getInt64(doc, "schema_version"); version > 0 {
+		return int(version)
+	}
+	return DefaultSchemaVersion
+}
+
 // GetEvents возвращает события агрегата
 func (s *MongoDBEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, 0)
+}
+
+// GetEventsPage возвращает не более limit событий агрегата начиная с указанной версии
+func (s *MongoDBEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, limit)
+}
+
+// getEvents читает события агрегата (limit <= 0 - без ограничения)
+func (s *MongoDBEventStore) getEvents(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
 	filter := bson.M{
 		"aggregate_id": aggregateID,
 		"version":      bson.M{"$gte": fromVersion},
 	}
 	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
 
 	cursor, err := s.collection.Find(ctx, filter, opts)
 	if err != nil {
@@ -259,6 +295,7 @@ func (s *MongoDBEventStore) GetEvents(ctx context.Context, aggregateID string, f
 			Position:     getInt64(doc, "position"),
 			OccurredAt:   getTime(doc, "occurred_at"),
 			CreatedAt:    getTime(doc, "created_at"),
+			SchemaVersion: mongoSchemaVersion(doc),
 		}
 
 		if id, ok := doc["_id"].(string); ok {
@@ -283,7 +320,7 @@ func (s *MongoDBEventStore) GetEvents(ctx context.Context, aggregateID string, f
 				}
 			}
 			if len(eventDataBytes) > 0 {
-				event, err := s.deserializer.DeserializeEvent(stored.EventType, eventDataBytes)
+				event, err := deserializeStoredEvent(s.deserializer, stored.EventType, stored.SchemaVersion, eventDataBytes)
 				if err == nil {
 					stored.EventData = event
 				}
@@ -329,6 +366,7 @@ func (s *MongoDBEventStore) GetEventsByType(ctx context.Context, eventType strin
 			Position:     getInt64(doc, "position"),
 			OccurredAt:   getTime(doc, "occurred_at"),
 			CreatedAt:    getTime(doc, "created_at"),
+			SchemaVersion: mongoSchemaVersion(doc),
 		}
 
 		if id, ok := doc["_id"].(string); ok {
@@ -345,6 +383,15 @@ func (s *MongoDBEventStore) GetEventsByType(ctx context.Context, eventType strin
 	return result, nil
 }
 
+// RenameEventType заменяет тип событий (реализация EventTypeRenamer)
+func (s *MongoDBEventStore) RenameEventType(ctx context.Context, oldType, newType string) (int64, error) {
+	result, err := s.collection.UpdateMany(ctx, bson.M{"event_type": oldType}, bson.M{"$set": bson.M{"event_type": newType}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename event type: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
 // GetAllEvents возвращает все события начиная с указанной позиции
 func (s *MongoDBEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
 	ch := make(chan StoredEvent, 100)
@@ -374,6 +421,7 @@ func (s *MongoDBEventStore) GetAllEvents(ctx context.Context, fromPosition int64
 				Position:     getInt64(doc, "position"),
 				OccurredAt:   getTime(doc, "occurred_at"),
 				CreatedAt:    getTime(doc, "created_at"),
+				SchemaVersion: mongoSchemaVersion(doc),
 			}
 
 			if id, ok := doc["_id"].(string); ok {
@@ -395,11 +443,81 @@ func (s *MongoDBEventStore) GetAllEvents(ctx context.Context, fromPosition int64
 	return ch, nil
 }
 
+// ListAggregates возвращает страницу агрегатов (реализация AggregateLister)
+func (s *MongoDBEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	limit := aggregatePageLimit(filter)
+
+	match := bson.M{"aggregate_id": bson.M{"$gt": filter.After}}
+	if filter.AggregateType != "" {
+		match["aggregate_type"] = filter.AggregateType
+	}
+	created := bson.M{}
+	if !filter.CreatedFrom.IsZero() {
+		created["$gte"] = filter.CreatedFrom
+	}
+	if !filter.CreatedTo.IsZero() {
+		created["$lt"] = filter.CreatedTo
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":            "$aggregate_id",
+			"aggregate_type": bson.M{"$max": "$aggregate_type"},
+			"version":        bson.M{"$max": "$version"},
+			"event_count":    bson.M{"$sum": 1},
+			"created_at":     bson.M{"$min": "$occurred_at"},
+			"updated_at":     bson.M{"$max": "$occurred_at"},
+		}},
+	}
+	if len(created) > 0 {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"created_at": created}})
+	}
+	pipeline = append(pipeline, bson.M{"$sort": bson.M{"_id": 1}}, bson.M{"$limit": limit + 1})
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var aggregates []AggregateSummary
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID            string    `bson:"_id"`
+			AggregateType string    `bson:"aggregate_type"`
+			Version       int64     `bson:"version"`
+			EventCount    int64     `bson:"event_count"`
+			CreatedAt     time.Time `bson:"created_at"`
+			UpdatedAt     time.Time `bson:"updated_at"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode aggregate: %w", err)
+		}
+		aggregates = append(aggregates, AggregateSummary{
+			AggregateID:   doc.ID,
+			AggregateType: doc.AggregateType,
+			Version:       doc.Version,
+			EventCount:    doc.EventCount,
+			CreatedAt:     doc.CreatedAt,
+			UpdatedAt:     doc.UpdatedAt,
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+
+	return newAggregatePage(aggregates, limit), nil
+}
+
 // MongoDBSnapshotStore реализация SnapshotStore для MongoDB
 type MongoDBSnapshotStore struct {
 	config     MongoDBEventStoreConfig
 	client     *mongo.Client
 	collection *mongo.Collection
+	history    *mongo.Collection
+	signer     SnapshotSigner
+	retention  snapshotRetention
 }
 
 // NewMongoDBSnapshotStore создает новый MongoDB Snapshot Store
@@ -439,15 +557,36 @@ func NewMongoDBSnapshotStore(config MongoDBEventStoreConfig) (*MongoDBSnapshotSt
 		return nil, fmt.Errorf("failed to create TTL index: %w", err)
 	}
 
+	// История замененных снапшотов (ведется при SnapshotRetentionPolicy.KeepLast > 1)
+	history := client.Database(config.Database).Collection(collectionName + "_history")
+	historyIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "aggregate_id", Value: 1}, {Key: "version", Value: -1}},
+	}
+	if _, err := history.Indexes().CreateOne(ctx, historyIndex); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot history index: %w", err)
+	}
+
 	return &MongoDBSnapshotStore{
 		config:     config,
 		client:     client,
 		collection: collection,
+		history:    history,
+		retention:  snapshotRetention{store: "mongodb"},
 	}, nil
 }
 
+// WithSigner включает подпись снапшотов и проверку подписи при загрузке
+func (s *MongoDBSnapshotStore) WithSigner(signer SnapshotSigner) *MongoDBSnapshotStore {
+	s.signer = signer
+	return s
+}
+
 // SaveSnapshot сохраняет снапшот
 func (s *MongoDBSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	if err := SealSnapshot(&snapshot, s.signer); err != nil {
+		return err
+	}
+
 	doc := bson.M{
 		"_id":            snapshot.AggregateID,
 		"aggregate_type": snapshot.AggregateType,
@@ -456,6 +595,14 @@ func (s *MongoDBSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapsh
 		"metadata":       snapshot.Metadata,
 		"created_at":     snapshot.CreatedAt,
 		"updated_at":     time.Now(),
+		"checksum":       snapshot.Checksum,
+		"signature":      snapshot.Signature,
+	}
+
+	if s.retention.currentPolicy().keepsHistory() {
+		if err := s.archiveSnapshot(ctx, snapshot.AggregateID, snapshot.Version); err != nil {
+			return err
+		}
 	}
 
 	opts := options.Replace().SetUpsert(true)
@@ -467,6 +614,30 @@ func (s *MongoDBSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapsh
 	return nil
 }
 
+// archiveSnapshot копирует текущий снапшот агрегата в историю перед заменой.
+// Копирование идемпотентно (_id истории - "<aggregate_id>@<version>"), поэтому
+// повтор SaveSnapshot после сбоя не создает дубликатов.
+func (s *MongoDBSnapshotStore) archiveSnapshot(ctx context.Context, aggregateID string, version int64) error {
+	var current bson.M
+	err := s.collection.FindOne(ctx, bson.M{"_id": aggregateID, "version": bson.M{"$lt": version}}).Decode(&current)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return fmt.Errorf("failed to load snapshot for archiving: %w", err)
+	}
+
+	historyID := fmt.Sprintf("%s@%d", aggregateID, getInt64(current, "version"))
+	current["_id"] = historyID
+	current["aggregate_id"] = aggregateID
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := s.history.ReplaceOne(ctx, bson.M{"_id": historyID}, current, opts); err != nil {
+		return fmt.Errorf("failed to archive snapshot: %w", err)
+	}
+	return nil
+}
+
 // GetSnapshot возвращает последний снапшот
 func (s *MongoDBSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
 	var doc bson.M
@@ -483,9 +654,16 @@ func (s *MongoDBSnapshotStore) GetSnapshot(ctx context.Context, aggregateID stri
 		AggregateType: getString(doc, "aggregate_type"),
 		Version:      getInt64(doc, "version"),
 		CreatedAt:    getTime(doc, "created_at"),
+		Checksum:     getString(doc, "checksum"),
+		Signature:    getString(doc, "signature"),
 	}
 
-	if state, ok := doc["state"].(bson.Raw); ok {
+	switch state := doc["state"].(type) {
+	case bson.Raw:
+		snapshot.State = state
+	case primitive.Binary:
+		snapshot.State = state.Data
+	case []byte:
 		snapshot.State = state
 	}
 
@@ -493,6 +671,10 @@ func (s *MongoDBSnapshotStore) GetSnapshot(ctx context.Context, aggregateID stri
 		snapshot.Metadata = convertBSONToMap(metadata)
 	}
 
+	if err := VerifySnapshot(snapshot, s.signer); err != nil {
+		return nil, err
+	}
+
 	return snapshot, nil
 }
 
@@ -508,9 +690,152 @@ func (s *MongoDBSnapshotStore) DeleteSnapshots(ctx context.Context, aggregateID
 		return fmt.Errorf("failed to delete snapshots: %w", err)
 	}
 
+	historyFilter := bson.M{
+		"aggregate_id": aggregateID,
+		"version":      bson.M{"$lt": beforeVersion},
+	}
+	if _, err := s.history.DeleteMany(ctx, historyFilter); err != nil {
+		return fmt.Errorf("failed to delete snapshot history: %w", err)
+	}
+
 	return nil
 }
 
+// WithRetention задает политику хранения снапшотов.
+// TTL индекс коллекции снапшотов (90 дней) продолжает действовать независимо от политики.
+func (s *MongoDBSnapshotStore) WithRetention(policy SnapshotRetentionPolicy) *MongoDBSnapshotStore {
+	s.retention.setPolicy(policy)
+	return s
+}
+
+// WithMetrics включает метрики очистки снапшотов (eventsourcing_snapshot_prune_*)
+func (s *MongoDBSnapshotStore) WithMetrics(recorder metrics.Recorder) *MongoDBSnapshotStore {
+	s.retention.setRecorder(recorder)
+	return s
+}
+
+// Prune удаляет снапшоты, не попадающие под политику хранения: историю сверх
+// KeepLast-1 версий на агрегат и снапшоты старше MaxAge.
+// BytesReclaimed оценивается по $bsonSize удаленных документов.
+func (s *MongoDBSnapshotStore) Prune(ctx context.Context) (SnapshotPruneResult, error) {
+	return s.retention.prune(ctx, s.prune)
+}
+
+// StartPruner запускает фоновую очистку с периодом SnapshotRetentionPolicy.Interval
+func (s *MongoDBSnapshotStore) StartPruner(ctx context.Context) error {
+	return s.retention.start(ctx, s.prune)
+}
+
+// StopPruner останавливает фоновую очистку
+func (s *MongoDBSnapshotStore) StopPruner() {
+	s.retention.stop()
+}
+
+func (s *MongoDBSnapshotStore) prune(ctx context.Context, policy SnapshotRetentionPolicy) (SnapshotPruneResult, error) {
+	var result SnapshotPruneResult
+
+	var cutoff time.Time
+	if policy.MaxAge > 0 {
+		cutoff = time.Now().Add(-policy.MaxAge)
+	}
+
+	// История чистится всегда: при KeepLast <= 1 она не ведется и удаляется целиком
+	expired := []interface{}{bson.M{"rank": bson.M{"$gte": max(policy.KeepLast-1, 0)}}}
+	if !cutoff.IsZero() {
+		expired = append(expired, bson.M{"docs.created_at": bson.M{"$lt": cutoff}})
+	}
+	pipeline := []bson.M{
+		{"$sort": bson.D{{Key: "aggregate_id", Value: 1}, {Key: "version", Value: -1}}},
+		{"$group": bson.M{
+			"_id":  "$aggregate_id",
+			"docs": bson.M{"$push": bson.M{"id": "$_id", "created_at": "$created_at", "size": bson.M{"$bsonSize": "$$ROOT"}}},
+		}},
+		{"$unwind": bson.M{"path": "$docs", "includeArrayIndex": "rank"}},
+		{"$match": bson.M{"$or": expired}},
+		{"$project": bson.M{"_id": "$docs.id", "size": "$docs.size"}},
+	}
+
+	cursor, err := s.history.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return result, fmt.Errorf("failed to find expired snapshot history: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	ids := make([]interface{}, 0, mongoPruneBatchSize)
+	var size int64
+	flush := func() error {
+		if len(ids) == 0 {
+			return nil
+		}
+		deleted, err := s.history.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return fmt.Errorf("failed to prune snapshot history: %w", err)
+		}
+		result.Deleted += deleted.DeletedCount
+		result.BytesReclaimed += size
+		ids, size = ids[:0], 0
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID   string `bson:"_id"`
+			Size int64  `bson:"size"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return result, fmt.Errorf("failed to decode snapshot history: %w", err)
+		}
+		ids = append(ids, doc.ID)
+		size += doc.Size
+		if len(ids) >= mongoPruneBatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return result, fmt.Errorf("failed to read snapshot history: %w", err)
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+
+	if cutoff.IsZero() {
+		return result, nil
+	}
+
+	filter := bson.M{"created_at": bson.M{"$lt": cutoff}}
+	sizeCursor, err := s.collection.Aggregate(ctx, []bson.M{
+		{"$match": filter},
+		{"$group": bson.M{"_id": nil, "size": bson.M{"$sum": bson.M{"$bsonSize": "$$ROOT"}}}},
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to measure expired snapshots: %w", err)
+	}
+	defer sizeCursor.Close(ctx)
+
+	var expiredSize struct {
+		Size int64 `bson:"size"`
+	}
+	if sizeCursor.Next(ctx) {
+		if err := sizeCursor.Decode(&expiredSize); err != nil {
+			return result, fmt.Errorf("failed to decode expired snapshots size: %w", err)
+		}
+	}
+
+	deleted, err := s.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return result, fmt.Errorf("failed to prune expired snapshots: %w", err)
+	}
+	result.Deleted += deleted.DeletedCount
+	result.BytesReclaimed += expiredSize.Size
+
+	return result, nil
+}
+
+// mongoPruneBatchSize количество документов истории, удаляемых одним DeleteMany
+const mongoPruneBatchSize = 1000
+
 // Вспомогательные функции
 func getString(doc bson.M, key string) string {
 	if val, ok := doc[key].(string); ok {
@@ -0,0 +1,722 @@
+package eventsourcing
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// MySQLEventStoreConfig конфигурация для MySQL Event Store (MySQL 8.0.19+).
+//
+// Драйвер MySQL не входит в зависимости фреймворка: приложение подключает его
+// blank-импортом _ "github.com/go-sql-driver/mysql" (DriverName "mysql").
+// DSN указывается в формате драйвера, префикс mysql:// (как в potter-migrate) допускается;
+// parseTime=true добавляется автоматически.
+type MySQLEventStoreConfig struct {
+	// DSN строка подключения, например "user:pass@tcp(localhost:3306)/potter"
+	DSN string
+	// DriverName имя зарегистрированного драйвера database/sql
+	DriverName string
+	TableName  string
+	// SnapshotTableName таблица снапшотов MySQLSnapshotStore
+	SnapshotTableName string
+	MaxOpenConns      int
+	MaxIdleConns      int
+	ConnMaxLifetime   time.Duration
+	// SnapshotCodec кодирует состояние снапшотов MySQLSnapshotStore (сжатие, шифрование)
+	SnapshotCodec SnapshotCodec
+}
+
+// Validate проверяет корректность конфигурации
+func (c MySQLEventStoreConfig) Validate() error {
+	if c.DSN == "" {
+		return fmt.Errorf("DSN cannot be empty")
+	}
+	if c.DriverName == "" {
+		return fmt.Errorf("driver name cannot be empty")
+	}
+	if c.TableName == "" || c.SnapshotTableName == "" {
+		return fmt.Errorf("table names cannot be empty")
+	}
+	return nil
+}
+
+// DefaultMySQLEventStoreConfig возвращает конфигурацию по умолчанию
+func DefaultMySQLEventStoreConfig() MySQLEventStoreConfig {
+	return MySQLEventStoreConfig{
+		DSN:               "root@tcp(localhost:3306)/potter",
+		DriverName:        "mysql",
+		TableName:         "event_store",
+		SnapshotTableName: "snapshots",
+		MaxOpenConns:      25,
+		MaxIdleConns:      5,
+		ConnMaxLifetime:   5 * time.Minute,
+	}
+}
+
+// mysqlReadBatchSize размер страницы чтения GetAllEvents
+const mysqlReadBatchSize = 500
+
+// MySQLDSN приводит строку подключения к формату драйвера go-sql-driver/mysql:
+// убирает префикс mysql:// и включает parseTime, необходимый для колонок DATETIME
+func MySQLDSN(dsn string) string {
+	dsn = strings.TrimPrefix(dsn, "mysql://")
+	if strings.Contains(dsn, "parseTime=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&parseTime=true"
+	}
+	return dsn + "?parseTime=true"
+}
+
+// openMySQL открывает пул соединений и создает таблицы, если их нет
+func openMySQL(config MySQLEventStoreConfig) (*sql.DB, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mysql config: %w", err)
+	}
+
+	db, err := sql.Open(config.DriverName, MySQLDSN(config.DSN))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MySQL (is driver %q imported?): %w", config.DriverName, err)
+	}
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+
+	ctx := context.Background()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			position BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+			id VARCHAR(255) NOT NULL,
+			tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+			aggregate_id VARCHAR(255) NOT NULL,
+			aggregate_type VARCHAR(255) NOT NULL DEFAULT '',
+			event_type VARCHAR(255) NOT NULL,
+			event_data JSON NOT NULL,
+			metadata JSON NOT NULL,
+			version BIGINT NOT NULL,
+			schema_version INT NOT NULL DEFAULT 1,
+			occurred_at DATETIME(6) NOT NULL,
+			created_at DATETIME(6) NOT NULL,
+			UNIQUE KEY uq_%s_stream (tenant_id, aggregate_id, version),
+			KEY idx_%s_event_type (tenant_id, event_type, occurred_at),
+			KEY idx_%s_tenant_position (tenant_id, position)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+			config.TableName, config.TableName, config.TableName, config.TableName),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+			aggregate_id VARCHAR(255) NOT NULL,
+			aggregate_type VARCHAR(255) NOT NULL DEFAULT '',
+			version BIGINT NOT NULL,
+			state LONGBLOB,
+			state_encoding VARCHAR(255) NOT NULL DEFAULT '',
+			metadata JSON NOT NULL,
+			checksum VARCHAR(128) NOT NULL DEFAULT '',
+			signature VARCHAR(512) NOT NULL DEFAULT '',
+			created_at DATETIME(6) NOT NULL,
+			updated_at DATETIME(6) NOT NULL,
+			PRIMARY KEY (tenant_id, aggregate_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`, config.SnapshotTableName),
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize MySQL schema: %w", err)
+		}
+	}
+
+	return db, nil
+}
+
+// isMySQLConflict проверяет нарушение уникальности (1062) или взаимную блокировку (1213),
+// возникающую при параллельной записи в один поток под gap-блокировками InnoDB
+func isMySQLConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "Error 1062") || strings.Contains(message, "Error 1213")
+}
+
+// MySQLEventStore реализация EventStore для MySQL 8
+type MySQLEventStore struct {
+	config         MySQLEventStoreConfig
+	db             *sql.DB
+	deserializer   EventDeserializer
+	tenantResolver TenantResolver
+}
+
+// NewMySQLEventStore создает новый MySQL Event Store
+func NewMySQLEventStore(config MySQLEventStoreConfig) (*MySQLEventStore, error) {
+	return NewMySQLEventStoreWithDeserializer(config, nil)
+}
+
+// NewMySQLEventStoreWithDeserializer создает новый MySQL Event Store с десериализатором
+func NewMySQLEventStoreWithDeserializer(config MySQLEventStoreConfig, deserializer EventDeserializer) (*MySQLEventStore, error) {
+	db, err := openMySQL(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MySQLEventStore{
+		config:       config,
+		db:           db,
+		deserializer: deserializer,
+	}, nil
+}
+
+// WithUpcasters включает приведение старых версий событий к актуальной схеме
+// (требуется десериализатор, см. PostgresEventStore.WithUpcasters)
+func (s *MySQLEventStore) WithUpcasters(chain *UpcasterChain) *MySQLEventStore {
+	if s.deserializer != nil {
+		s.deserializer = NewUpcastingDeserializer(s.deserializer, chain)
+	}
+	return s
+}
+
+// WithTenantResolver включает изоляцию тенантов (см. PostgresEventStore.WithTenantResolver)
+func (s *MySQLEventStore) WithTenantResolver(resolver TenantResolver) *MySQLEventStore {
+	s.tenantResolver = resolver
+	return s
+}
+
+// TenantIsolation сообщает, включена ли изоляция тенантов (реализация TenantIsolatedStore)
+func (s *MySQLEventStore) TenantIsolation() bool {
+	return s.tenantResolver != nil
+}
+
+// SnapshotStore возвращает MySQLSnapshotStore, использующий пул соединений event store
+func (s *MySQLEventStore) SnapshotStore() *MySQLSnapshotStore {
+	return &MySQLSnapshotStore{
+		config:         s.config,
+		db:             s.db,
+		tenantResolver: s.tenantResolver,
+	}
+}
+
+// Start запускает адаптер
+func (s *MySQLEventStore) Start(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Stop останавливает адаптер
+func (s *MySQLEventStore) Stop(ctx context.Context) error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// IsRunning проверяет, запущен ли адаптер
+func (s *MySQLEventStore) IsRunning() bool {
+	return s.db != nil
+}
+
+// Name возвращает имя компонента
+func (s *MySQLEventStore) Name() string {
+	return "mysql-event-store"
+}
+
+// Type возвращает тип компонента
+func (s *MySQLEventStore) Type() core.ComponentType {
+	return core.ComponentTypeAdapter
+}
+
+// AppendEvents добавляет события в поток агрегата
+func (s *MySQLEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, events []events.Event) error {
+	return s.AppendEventsBatch(ctx, []EventBatch{{
+		AggregateID:     aggregateID,
+		ExpectedVersion: expectedVersion,
+		Events:          events,
+	}})
+}
+
+// AppendEventsBatch атомарно добавляет события нескольких агрегатов (реализация BatchAppender).
+// Версии проверяются в транзакции с блокировкой потоков (SELECT ... FOR UPDATE), а уникальный
+// индекс (tenant_id, aggregate_id, version) гарантирует обнаружение конфликта.
+func (s *MySQLEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	currentVersions := make(map[string]int64, len(batches))
+	checkQuery := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s WHERE tenant_id = ? AND aggregate_id = ? FOR UPDATE", s.config.TableName)
+	for _, batch := range batches {
+		if _, exists := currentVersions[batch.AggregateID]; exists {
+			continue
+		}
+		var version int64
+		if err := tx.QueryRowContext(ctx, checkQuery, tenantID, batch.AggregateID).Scan(&version); err != nil {
+			if isMySQLConflict(err) {
+				return fmt.Errorf("%w: %v", ErrConcurrencyConflict, err)
+			}
+			return fmt.Errorf("failed to check version: %w", err)
+		}
+		currentVersions[batch.AggregateID] = version
+	}
+
+	if err := checkBatchVersions(batches, currentVersions); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO %s (id, tenant_id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.config.TableName)
+
+	now := time.Now().UTC()
+	for _, batch := range batches {
+		for i, event := range batch.Events {
+			eventData, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+
+			metadata, err := json.Marshal(convertMetadata(event.Metadata()))
+			if err != nil {
+				return fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+
+			version := batch.ExpectedVersion + int64(i) + 1
+			_, err = tx.ExecContext(ctx, insertQuery,
+				event.EventID(),
+				tenantID,
+				batch.AggregateID,
+				getAggregateType(event),
+				event.EventType(),
+				eventData,
+				metadata,
+				version,
+				eventSchemaVersion(s.deserializer, event),
+				event.OccurredAt().UTC(),
+				now,
+			)
+			if isMySQLConflict(err) {
+				return fmt.Errorf("%w: version %d of aggregate %s already exists", ErrConcurrencyConflict, version, batch.AggregateID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to insert event: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if isMySQLConflict(err) {
+			return fmt.Errorf("%w: %v", ErrConcurrencyConflict, err)
+		}
+		return fmt.Errorf("failed to commit events: %w", err)
+	}
+	return nil
+}
+
+// mysqlEventColumns колонки событий в порядке scanMySQLEvent
+const mysqlEventColumns = "id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at, schema_version, tenant_id"
+
+// scanMySQLEvent читает строку события. Без десериализатора EventData восстанавливается
+// как events.BaseEvent (как в PostgresEventStore).
+func (s *MySQLEventStore) scanMySQLEvent(rows *sql.Rows) (StoredEvent, error) {
+	var stored StoredEvent
+	var eventDataJSON, metadataJSON []byte
+
+	if err := rows.Scan(
+		&stored.ID,
+		&stored.AggregateID,
+		&stored.AggregateType,
+		&stored.EventType,
+		&eventDataJSON,
+		&metadataJSON,
+		&stored.Version,
+		&stored.Position,
+		&stored.OccurredAt,
+		&stored.CreatedAt,
+		&stored.SchemaVersion,
+		&stored.TenantID,
+	); err != nil {
+		return StoredEvent{}, fmt.Errorf("failed to scan event: %w", err)
+	}
+
+	if err := json.Unmarshal(metadataJSON, &stored.Metadata); err != nil {
+		return StoredEvent{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	if s.deserializer != nil {
+		event, err := deserializeStoredEvent(s.deserializer, stored.EventType, stored.SchemaVersion, eventDataJSON)
+		if err != nil {
+			return StoredEvent{}, fmt.Errorf("%w: %w", ErrEventDeserializationFailed, err)
+		}
+		stored.EventData = event
+	} else {
+		var baseEvent events.BaseEvent
+		if err := json.Unmarshal(eventDataJSON, &baseEvent); err == nil {
+			stored.EventData = &baseEvent
+		}
+	}
+	return stored, nil
+}
+
+// queryEvents выполняет запрос событий и читает все строки
+func (s *MySQLEventStore) queryEvents(ctx context.Context, query string, args ...interface{}) ([]StoredEvent, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var result []StoredEvent
+	for rows.Next() {
+		stored, err := s.scanMySQLEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	return result, nil
+}
+
+// GetEvents возвращает события агрегата
+func (s *MySQLEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, 0)
+}
+
+// GetEventsPage возвращает не более limit событий агрегата начиная с указанной версии
+func (s *MySQLEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, limit)
+}
+
+// getEvents читает события агрегата (limit <= 0 - без ограничения)
+func (s *MySQLEventStore) getEvents(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE tenant_id = ? AND aggregate_id = ? AND version >= ?
+		ORDER BY version ASC
+	`, mysqlEventColumns, s.config.TableName)
+	if limit > 0 {
+		query += fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	result, err := s.queryEvents(ctx, query, tenantID, aggregateID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 && fromVersion > 0 {
+		return nil, ErrStreamNotFound
+	}
+	return result, nil
+}
+
+// GetEventsByType возвращает события определенного типа
+func (s *MySQLEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Нулевое время драйвер передает как '0000-00-00', поэтому условие по времени опускается
+	args := []interface{}{tenantID, eventType}
+	timeCondition := ""
+	if !fromTimestamp.IsZero() {
+		args = append(args, fromTimestamp.UTC())
+		timeCondition = "AND occurred_at >= ?"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE tenant_id = ? AND event_type = ? %s
+		ORDER BY position ASC
+	`, mysqlEventColumns, s.config.TableName, timeCondition)
+
+	return s.queryEvents(ctx, query, args...)
+}
+
+// RenameEventType заменяет тип событий всех тенантов (реализация EventTypeRenamer)
+func (s *MySQLEventStore) RenameEventType(ctx context.Context, oldType, newType string) (int64, error) {
+	query := fmt.Sprintf("UPDATE %s SET event_type = ? WHERE event_type = ?", s.config.TableName)
+	result, err := s.db.ExecContext(ctx, query, newType, oldType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename event type: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetAllEvents возвращает все события начиная с указанной позиции.
+// События читаются страницами по позиции, чтобы не удерживать соединение и снимок
+// транзакции, пока потребитель обрабатывает события.
+func (s *MySQLEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE tenant_id = ? AND position >= ?
+		ORDER BY position ASC
+		LIMIT %d
+	`, mysqlEventColumns, s.config.TableName, mysqlReadBatchSize)
+
+	ch := make(chan StoredEvent, 100)
+
+	go func() {
+		defer close(ch)
+		position := fromPosition
+		for {
+			batch, err := s.queryEvents(ctx, query, tenantID, position)
+			if err != nil {
+				return
+			}
+			for _, stored := range batch {
+				select {
+				case ch <- stored:
+				case <-ctx.Done():
+					return
+				}
+				position = stored.Position + 1
+			}
+			if len(batch) < mysqlReadBatchSize {
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// ListAggregates возвращает страницу агрегатов тенанта (реализация AggregateLister)
+func (s *MySQLEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := aggregatePageLimit(filter)
+	args := []interface{}{tenantID, filter.After}
+	conditions := []string{"tenant_id = ?", "aggregate_id > ?"}
+	if filter.AggregateType != "" {
+		args = append(args, filter.AggregateType)
+		conditions = append(conditions, "aggregate_type = ?")
+	}
+	having := []string{"1 = 1"}
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom.UTC())
+		having = append(having, "MIN(occurred_at) >= ?")
+	}
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo.UTC())
+		having = append(having, "MIN(occurred_at) < ?")
+	}
+	args = append(args, limit+1)
+
+	query := fmt.Sprintf(`
+		SELECT aggregate_id, MAX(aggregate_type), MAX(version), COUNT(*), MIN(occurred_at), MAX(occurred_at)
+		FROM %s
+		WHERE %s
+		GROUP BY aggregate_id
+		HAVING %s
+		ORDER BY aggregate_id
+		LIMIT ?
+	`, s.config.TableName, strings.Join(conditions, " AND "), strings.Join(having, " AND "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []AggregateSummary
+	for rows.Next() {
+		var summary AggregateSummary
+		if err := rows.Scan(&summary.AggregateID, &summary.AggregateType, &summary.Version,
+			&summary.EventCount, &summary.CreatedAt, &summary.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate: %w", err)
+		}
+		aggregates = append(aggregates, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+
+	return newAggregatePage(aggregates, limit), nil
+}
+
+// MySQLSnapshotStore реализация SnapshotStore для MySQL 8
+type MySQLSnapshotStore struct {
+	config         MySQLEventStoreConfig
+	db             *sql.DB
+	signer         SnapshotSigner
+	tenantResolver TenantResolver
+}
+
+// NewMySQLSnapshotStore создает новый MySQL Snapshot Store
+func NewMySQLSnapshotStore(config MySQLEventStoreConfig) (*MySQLSnapshotStore, error) {
+	db, err := openMySQL(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MySQLSnapshotStore{
+		config: config,
+		db:     db,
+	}, nil
+}
+
+// WithSigner включает подпись снапшотов и проверку подписи при загрузке
+func (s *MySQLSnapshotStore) WithSigner(signer SnapshotSigner) *MySQLSnapshotStore {
+	s.signer = signer
+	return s
+}
+
+// WithTenantResolver включает изоляцию снапшотов по тенантам
+func (s *MySQLSnapshotStore) WithTenantResolver(resolver TenantResolver) *MySQLSnapshotStore {
+	s.tenantResolver = resolver
+	return s
+}
+
+// SaveSnapshot сохраняет снапшот
+func (s *MySQLSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (tenant_id, aggregate_id, aggregate_type, version, state, state_encoding, metadata, checksum, signature, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE aggregate_type = new.aggregate_type, version = new.version, state = new.state,
+			state_encoding = new.state_encoding, metadata = new.metadata, checksum = new.checksum,
+			signature = new.signature, updated_at = new.updated_at
+	`, s.config.SnapshotTableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	if err := SealSnapshot(&snapshot, s.signer); err != nil {
+		return err
+	}
+
+	metadataJSON, err := json.Marshal(snapshot.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	state, encoding, err := EncodeSnapshotState(s.config.SnapshotCodec, snapshot.State)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, query,
+		tenantID,
+		snapshot.AggregateID,
+		snapshot.AggregateType,
+		snapshot.Version,
+		state,
+		encoding,
+		metadataJSON,
+		snapshot.Checksum,
+		snapshot.Signature,
+		snapshot.CreatedAt.UTC(),
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshot возвращает последний снапшот
+func (s *MySQLSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
+	query := fmt.Sprintf(`
+		SELECT aggregate_id, aggregate_type, version, state, state_encoding, metadata, checksum, signature, created_at
+		FROM %s
+		WHERE tenant_id = ? AND aggregate_id = ?
+	`, s.config.SnapshotTableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	var state, metadataJSON []byte
+	var encoding string
+
+	err = s.db.QueryRowContext(ctx, query, tenantID, aggregateID).Scan(
+		&snapshot.AggregateID,
+		&snapshot.AggregateType,
+		&snapshot.Version,
+		&state,
+		&encoding,
+		&metadataJSON,
+		&snapshot.Checksum,
+		&snapshot.Signature,
+		&snapshot.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(metadataJSON, &snapshot.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	snapshot.State = state
+	if encoding != "" {
+		decoded, err := DecodeSnapshotState(s.config.SnapshotCodec, encoding, state)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate %s: %w", snapshot.AggregateID, err)
+		}
+		snapshot.State = decoded
+	}
+
+	if err := VerifySnapshot(&snapshot, s.signer); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// DeleteSnapshots удаляет старые снапшоты
+func (s *MySQLSnapshotStore) DeleteSnapshots(ctx context.Context, aggregateID string, beforeVersion int64) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE tenant_id = ? AND aggregate_id = ? AND version < ?
+	`, s.config.SnapshotTableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, tenantID, aggregateID, beforeVersion); err != nil {
+		return fmt.Errorf("failed to delete snapshots: %w", err)
+	}
+	return nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/metrics"
 )
 
 // PostgresEventStoreConfig конфигурация для PostgreSQL Event Store
@@ -19,6 +22,13 @@ type PostgresEventStoreConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime int // в секундах
+	// SnapshotCodec кодирует состояние снапшотов PostgresSnapshotStore (сжатие, шифрование).
+	// Nil - состояние хранится в JSONB без изменений.
+	SnapshotCodec SnapshotCodec
+	// NotifyChannel канал NOTIFY, в который сообщается о записи событий. Подписки
+	// (NewEventSubscriber) слушают его через LISTEN и получают новые события без ожидания опроса.
+	// Пустое значение отключает уведомления.
+	NotifyChannel string
 }
 
 // Validate проверяет корректность конфигурации
@@ -43,6 +53,7 @@ func DefaultPostgresEventStoreConfig() PostgresEventStoreConfig {
 		MaxOpenConns:    25,
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 300,
+		NotifyChannel:   "potter_events",
 	}
 }
 
@@ -51,6 +62,14 @@ type PostgresEventStore struct {
 	config      PostgresEventStoreConfig
 	pool        *pgx.Conn
 	deserializer EventDeserializer
+	tenantResolver TenantResolver
+
+	materializedMu    sync.RWMutex
+	materializedTypes map[string]bool
+
+	changes        changeFeed
+	listenerMu     sync.Mutex
+	listenerCancel context.CancelFunc
 }
 
 // NewPostgresEventStore создает новый PostgreSQL Event Store
@@ -76,6 +95,103 @@ func NewPostgresEventStoreWithDeserializer(config PostgresEventStoreConfig, dese
 	}, nil
 }
 
+// WithUpcasters включает приведение старых версий событий к актуальной схеме.
+// Upcaster'ы применяются перед десериализацией, поэтому требуется десериализатор
+// (NewPostgresEventStoreWithDeserializer).
+func (s *PostgresEventStore) WithUpcasters(chain *UpcasterChain) *PostgresEventStore {
+	if s.deserializer != nil {
+		s.deserializer = NewUpcastingDeserializer(s.deserializer, chain)
+	}
+	return s
+}
+
+// WithTenantResolver включает изоляцию тенантов: все операции выполняются в рамках
+// тенанта, определенного resolver по контексту (например, ContextTenantResolver).
+// Потоки агрегатов идентифицируются парой (tenant_id, aggregate_id).
+// Без resolver используется тенант по умолчанию ("").
+func (s *PostgresEventStore) WithTenantResolver(resolver TenantResolver) *PostgresEventStore {
+	s.tenantResolver = resolver
+	return s
+}
+
+// TenantIsolation сообщает, включена ли изоляция тенантов (реализация TenantIsolatedStore)
+func (s *PostgresEventStore) TenantIsolation() bool {
+	return s.tenantResolver != nil
+}
+
+// WithMaterializedEventTypes включает чтение GetEventsByType для указанных типов из
+// материализованных потоков (migrations/postgres/006_add_event_type_streams.sql).
+// Типы должны быть зарегистрированы в БД через MaterializeEventType.
+func (s *PostgresEventStore) WithMaterializedEventTypes(eventTypes ...string) *PostgresEventStore {
+	s.materializedMu.Lock()
+	defer s.materializedMu.Unlock()
+
+	if s.materializedTypes == nil {
+		s.materializedTypes = make(map[string]bool)
+	}
+	for _, eventType := range eventTypes {
+		s.materializedTypes[eventType] = true
+	}
+	return s
+}
+
+// MaterializeEventType регистрирует материализованный поток для типа события и заполняет его
+// уже сохраненными событиями. Дальнейшие события копируются в поток триггером при записи.
+// На время заполнения запись в event store блокируется, чтение продолжается.
+func (s *PostgresEventStore) MaterializeEventType(ctx context.Context, eventType string) error {
+	tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", tableName)); err != nil {
+		return fmt.Errorf("failed to lock event store: %w", err)
+	}
+
+	registerQuery := fmt.Sprintf("INSERT INTO %s (event_type) VALUES ($1) ON CONFLICT DO NOTHING", s.materializedTypesTable())
+	if _, err := tx.Exec(ctx, registerQuery, eventType); err != nil {
+		return fmt.Errorf("failed to register materialized event type: %w", err)
+	}
+
+	backfillQuery := fmt.Sprintf(`
+		INSERT INTO %s (tenant_id, event_type, position, id, aggregate_id, aggregate_type, event_data, metadata, version, occurred_at, created_at, schema_version)
+		SELECT tenant_id, event_type, position, id, aggregate_id, aggregate_type, event_data, metadata, version, occurred_at, created_at, schema_version
+		FROM %s
+		WHERE event_type = $1
+		ON CONFLICT DO NOTHING
+	`, s.typeStreamsTable(), tableName)
+	if _, err := tx.Exec(ctx, backfillQuery, eventType); err != nil {
+		return fmt.Errorf("failed to backfill materialized stream: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit materialized stream: %w", err)
+	}
+
+	s.WithMaterializedEventTypes(eventType)
+	return nil
+}
+
+// isMaterialized проверяет, читаются ли события типа из материализованного потока
+func (s *PostgresEventStore) isMaterialized(eventType string) bool {
+	s.materializedMu.RLock()
+	defer s.materializedMu.RUnlock()
+	return s.materializedTypes[eventType]
+}
+
+// typeStreamsTable возвращает таблицу материализованных потоков
+func (s *PostgresEventStore) typeStreamsTable() string {
+	return fmt.Sprintf("%s.%s_type_streams", s.config.SchemaName, s.config.TableName)
+}
+
+// materializedTypesTable возвращает таблицу зарегистрированных материализованных типов
+func (s *PostgresEventStore) materializedTypesTable() string {
+	return fmt.Sprintf("%s.%s_materialized_types", s.config.SchemaName, s.config.TableName)
+}
+
 // Start запускает адаптер
 func (s *PostgresEventStore) Start(ctx context.Context) error {
 	return nil
@@ -83,6 +199,13 @@ func (s *PostgresEventStore) Start(ctx context.Context) error {
 
 // Stop останавливает адаптер
 func (s *PostgresEventStore) Stop(ctx context.Context) error {
+	s.listenerMu.Lock()
+	if s.listenerCancel != nil {
+		s.listenerCancel()
+		s.listenerCancel = nil
+	}
+	s.listenerMu.Unlock()
+
 	if s.pool != nil {
 		return s.pool.Close(ctx)
 	}
@@ -108,6 +231,11 @@ func (s *PostgresEventStore) Type() core.ComponentType {
 func (s *PostgresEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, events []events.Event) error {
 	tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
 
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -116,8 +244,8 @@ func (s *PostgresEventStore) AppendEvents(ctx context.Context, aggregateID strin
 
 	// Проверяем текущую версию
 	var currentVersion int64
-	checkQuery := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s WHERE aggregate_id = $1", tableName)
-	err = tx.QueryRow(ctx, checkQuery, aggregateID).Scan(&currentVersion)
+	checkQuery := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s WHERE tenant_id = $1 AND aggregate_id = $2", tableName)
+	err = tx.QueryRow(ctx, checkQuery, tenantID, aggregateID).Scan(&currentVersion)
 	if err != nil {
 		return fmt.Errorf("failed to check version: %w", err)
 	}
@@ -131,8 +259,8 @@ func (s *PostgresEventStore) AppendEvents(ctx context.Context, aggregateID strin
 
 	// Вставляем события
 	insertQuery := fmt.Sprintf(`
-		INSERT INTO %s (aggregate_id, aggregate_type, event_type, event_data, metadata, version, occurred_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO %s (aggregate_id, aggregate_type, event_type, event_data, metadata, version, occurred_at, schema_version, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`, tableName)
 
 	for i, event := range events {
@@ -155,26 +283,226 @@ func (s *PostgresEventStore) AppendEvents(ctx context.Context, aggregateID strin
 			metadata,
 			version,
 			event.OccurredAt(),
+			eventSchemaVersion(s.deserializer, event),
+			tenantID,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert event: %w", err)
 		}
 	}
 
+	if err := s.notifyChanges(ctx, tx, aggregateID); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
+// AppendEventsBatch атомарно добавляет события нескольких агрегатов (реализация BatchAppender).
+// Версии всех потоков проверяются одним запросом, события записываются через COPY
+// в одной транзакции, что значительно быстрее построчного INSERT при replay и импорте.
+func (s *PostgresEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	aggregateIDs := make([]string, 0, len(batches))
+	currentVersions := make(map[string]int64, len(batches))
+	eventCount := 0
+	for _, batch := range batches {
+		if _, exists := currentVersions[batch.AggregateID]; !exists {
+			currentVersions[batch.AggregateID] = 0
+			aggregateIDs = append(aggregateIDs, batch.AggregateID)
+		}
+		eventCount += len(batch.Events)
+	}
+	if eventCount == 0 {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Проверяем текущие версии всех потоков пакета
+	checkQuery := fmt.Sprintf(`
+		SELECT aggregate_id, MAX(version) FROM %s
+		WHERE tenant_id = $1 AND aggregate_id = ANY($2)
+		GROUP BY aggregate_id
+	`, tableName)
+	rows, err := tx.Query(ctx, checkQuery, tenantID, aggregateIDs)
+	if err != nil {
+		return fmt.Errorf("failed to check versions: %w", err)
+	}
+	for rows.Next() {
+		var aggregateID string
+		var version int64
+		if err := rows.Scan(&aggregateID, &version); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan version: %w", err)
+		}
+		currentVersions[aggregateID] = version
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to check versions: %w", err)
+	}
+
+	if err := checkBatchVersions(batches, currentVersions); err != nil {
+		return err
+	}
+
+	copyRows := make([][]interface{}, 0, eventCount)
+	for _, batch := range batches {
+		for i, event := range batch.Events {
+			eventData, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+
+			metadata, err := json.Marshal(convertMetadata(event.Metadata()))
+			if err != nil {
+				return fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+
+			copyRows = append(copyRows, []interface{}{
+				batch.AggregateID,
+				getAggregateType(event),
+				event.EventType(),
+				eventData,
+				metadata,
+				batch.ExpectedVersion + int64(i) + 1,
+				event.OccurredAt(),
+				eventSchemaVersion(s.deserializer, event),
+				tenantID,
+			})
+		}
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{s.config.SchemaName, s.config.TableName},
+		[]string{"aggregate_id", "aggregate_type", "event_type", "event_data", "metadata", "version", "occurred_at", "schema_version", "tenant_id"},
+		pgx.CopyFromRows(copyRows),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy events: %w", err)
+	}
+
+	if err := s.notifyChanges(ctx, tx, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// notifyChanges отправляет NOTIFY о записи событий агрегата; уведомление доставляется после commit
+func (s *PostgresEventStore) notifyChanges(ctx context.Context, tx pgx.Tx, aggregateID string) error {
+	if s.config.NotifyChannel == "" {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, "SELECT pg_notify($1, $2)", s.config.NotifyChannel, aggregateID); err != nil {
+		return fmt.Errorf("failed to notify event subscribers: %w", err)
+	}
+	return nil
+}
+
+// WatchChanges возвращает канал сигналов о записи событий (реализация ChangeNotifier).
+// Уведомления читаются одним LISTEN соединением, открытым на время жизни наблюдателей.
+func (s *PostgresEventStore) WatchChanges(ctx context.Context) (<-chan struct{}, error) {
+	if s.config.NotifyChannel == "" {
+		return nil, ErrChangeNotificationsDisabled
+	}
+
+	s.listenerMu.Lock()
+	defer s.listenerMu.Unlock()
+
+	if s.listenerCancel == nil {
+		listenCtx, cancel := context.WithCancel(context.Background())
+		s.listenerCancel = cancel
+		go s.listenChanges(listenCtx)
+	}
+
+	return s.changes.watch(ctx, func() {
+		s.listenerMu.Lock()
+		defer s.listenerMu.Unlock()
+		if s.changes.size() == 0 && s.listenerCancel != nil {
+			s.listenerCancel()
+			s.listenerCancel = nil
+		}
+	}), nil
+}
+
+// SubscribeToStream подписывается на события агрегата начиная с версии fromVersion (реализация EventSubscriber).
+// Новые события доставляются по NOTIFY, при отключенных уведомлениях - опросом.
+func (s *PostgresEventStore) SubscribeToStream(ctx context.Context, aggregateID string, fromVersion int64) (<-chan StoredEvent, error) {
+	return NewPollingSubscriber(s).SubscribeToStream(ctx, aggregateID, fromVersion)
+}
+
+// SubscribeToAll подписывается на все события тенанта начиная с позиции fromPosition (реализация EventSubscriber).
+// Новые события доставляются по NOTIFY, при отключенных уведомлениях - опросом.
+func (s *PostgresEventStore) SubscribeToAll(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	return NewPollingSubscriber(s).SubscribeToAll(ctx, fromPosition)
+}
+
+// listenChanges слушает канал NotifyChannel и переподключается при разрывах соединения.
+// После переподключения наблюдатели получают сигнал, чтобы перечитать пропущенные события.
+func (s *PostgresEventStore) listenChanges(ctx context.Context) {
+	listenQuery := "LISTEN " + pgx.Identifier{s.config.NotifyChannel}.Sanitize()
+	for ctx.Err() == nil {
+		conn, err := pgx.Connect(ctx, s.config.DSN)
+		if err == nil {
+			_, err = conn.Exec(ctx, listenQuery)
+			for err == nil {
+				if _, err = conn.WaitForNotification(ctx); err == nil {
+					s.changes.notify()
+				}
+			}
+			conn.Close(context.Background())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(subscriptionRetryDelay):
+		}
+		s.changes.notify()
+	}
+}
+
 // GetEvents возвращает события агрегата
 func (s *PostgresEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, 0)
+}
+
+// GetEventsPage возвращает не более limit событий агрегата начиная с указанной версии
+func (s *PostgresEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, limit)
+}
+
+// getEvents читает события агрегата (limit <= 0 - без ограничения)
+func (s *PostgresEventStore) getEvents(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
 	tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
 	query := fmt.Sprintf(`
-		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at
+		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at, schema_version, tenant_id
 		FROM %s
-		WHERE aggregate_id = $1 AND version >= $2
+		WHERE tenant_id = $1 AND aggregate_id = $2 AND version >= $3
 		ORDER BY version ASC
 	`, tableName)
+	if limit > 0 {
+		query += fmt.Sprintf("LIMIT %d", limit)
+	}
 
-	rows, err := s.pool.Query(ctx, query, aggregateID, fromVersion)
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, query, tenantID, aggregateID, fromVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query events: %w", err)
 	}
@@ -197,6 +525,8 @@ func (s *PostgresEventStore) GetEvents(ctx context.Context, aggregateID string,
 			&stored.Position,
 			&stored.OccurredAt,
 			&stored.CreatedAt,
+			&stored.SchemaVersion,
+			&stored.TenantID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan event: %w", err)
@@ -209,9 +539,9 @@ func (s *PostgresEventStore) GetEvents(ctx context.Context, aggregateID string,
 
 		// Десериализуем eventData обратно в events.Event
 		if s.deserializer != nil {
-			event, err := s.deserializer.DeserializeEvent(stored.EventType, eventDataJSON)
+			event, err := deserializeStoredEvent(s.deserializer, stored.EventType, stored.SchemaVersion, eventDataJSON)
 			if err != nil {
-				return nil, fmt.Errorf("failed to deserialize event: %w", err)
+				return nil, fmt.Errorf("%w: %w", ErrEventDeserializationFailed, err)
 			}
 			stored.EventData = event
 		} else {
@@ -235,17 +565,27 @@ func (s *PostgresEventStore) GetEvents(ctx context.Context, aggregateID string,
 	return result, nil
 }
 
-// GetEventsByType возвращает события определенного типа
+// GetEventsByType возвращает события определенного типа.
+// Запрос использует индекс (tenant_id, event_type, occurred_at); для типов из
+// WithMaterializedEventTypes события читаются из материализованного потока.
 func (s *PostgresEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
 	tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
+	if s.isMaterialized(eventType) {
+		tableName = s.typeStreamsTable()
+	}
 	query := fmt.Sprintf(`
-		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at
+		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at, schema_version, tenant_id
 		FROM %s
-		WHERE event_type = $1 AND occurred_at >= $2
+		WHERE tenant_id = $1 AND event_type = $2 AND occurred_at >= $3
 		ORDER BY position ASC
 	`, tableName)
 
-	rows, err := s.pool.Query(ctx, query, eventType, fromTimestamp)
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, query, tenantID, eventType, fromTimestamp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query events by type: %w", err)
 	}
@@ -268,6 +608,8 @@ func (s *PostgresEventStore) GetEventsByType(ctx context.Context, eventType stri
 			&stored.Position,
 			&stored.OccurredAt,
 			&stored.CreatedAt,
+			&stored.SchemaVersion,
+			&stored.TenantID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan event: %w", err)
@@ -280,9 +622,9 @@ func (s *PostgresEventStore) GetEventsByType(ctx context.Context, eventType stri
 
 		// Десериализуем eventData обратно в events.Event
 		if s.deserializer != nil {
-			event, err := s.deserializer.DeserializeEvent(stored.EventType, eventDataJSON)
+			event, err := deserializeStoredEvent(s.deserializer, stored.EventType, stored.SchemaVersion, eventDataJSON)
 			if err != nil {
-				return nil, fmt.Errorf("failed to deserialize event: %w", err)
+				return nil, fmt.Errorf("%w: %w", ErrEventDeserializationFailed, err)
 			}
 			stored.EventData = event
 		}
@@ -292,21 +634,90 @@ func (s *PostgresEventStore) GetEventsByType(ctx context.Context, eventType stri
 	return result, nil
 }
 
+// RenameEventType заменяет тип событий всех тенантов в журнале и материализованных
+// потоках (реализация EventTypeRenamer). Если новый тип материализован, его поток
+// дополняется переименованными событиями.
+func (s *PostgresEventStore) RenameEventType(ctx context.Context, oldType, newType string) (int64, error) {
+	tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", tableName)); err != nil {
+		return 0, fmt.Errorf("failed to lock event store: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf("UPDATE %s SET event_type = $2 WHERE event_type = $1", tableName), oldType, newType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename event type: %w", err)
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf("UPDATE %s SET event_type = $2 WHERE event_type = $1", s.typeStreamsTable()), oldType, newType); err != nil {
+		return 0, fmt.Errorf("failed to rename event type in materialized stream: %w", err)
+	}
+
+	registerQuery := fmt.Sprintf(`
+		INSERT INTO %[1]s (event_type)
+		SELECT $2 WHERE EXISTS (SELECT 1 FROM %[1]s WHERE event_type = $1)
+		ON CONFLICT DO NOTHING
+	`, s.materializedTypesTable())
+	if _, err := tx.Exec(ctx, registerQuery, oldType, newType); err != nil {
+		return 0, fmt.Errorf("failed to register materialized event type: %w", err)
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE event_type = $1", s.materializedTypesTable()), oldType); err != nil {
+		return 0, fmt.Errorf("failed to unregister materialized event type: %w", err)
+	}
+
+	var materialized bool
+	existsQuery := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE event_type = $1)", s.materializedTypesTable())
+	if err := tx.QueryRow(ctx, existsQuery, newType).Scan(&materialized); err != nil {
+		return 0, fmt.Errorf("failed to check materialized event type: %w", err)
+	}
+	if materialized {
+		backfillQuery := fmt.Sprintf(`
+			INSERT INTO %s (tenant_id, event_type, position, id, aggregate_id, aggregate_type, event_data, metadata, version, occurred_at, created_at, schema_version)
+			SELECT tenant_id, event_type, position, id, aggregate_id, aggregate_type, event_data, metadata, version, occurred_at, created_at, schema_version
+			FROM %s
+			WHERE event_type = $1
+			ON CONFLICT DO NOTHING
+		`, s.typeStreamsTable(), tableName)
+		if _, err := tx.Exec(ctx, backfillQuery, newType); err != nil {
+			return 0, fmt.Errorf("failed to backfill materialized stream: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit event type rename: %w", err)
+	}
+
+	if materialized {
+		s.WithMaterializedEventTypes(newType)
+	}
+	return tag.RowsAffected(), nil
+}
+
 // GetAllEvents возвращает все события начиная с указанной позиции
 func (s *PostgresEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
 	ch := make(chan StoredEvent, 100)
 
 	go func() {
 		defer close(ch)
 		tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
 		query := fmt.Sprintf(`
-			SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at
+			SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at, schema_version, tenant_id
 			FROM %s
-			WHERE position >= $1
+			WHERE tenant_id = $1 AND position >= $2
 			ORDER BY position ASC
 		`, tableName)
 
-		rows, err := s.pool.Query(ctx, query, fromPosition)
+		rows, err := s.pool.Query(ctx, query, tenantID, fromPosition)
 		if err != nil {
 			return
 		}
@@ -328,6 +739,8 @@ func (s *PostgresEventStore) GetAllEvents(ctx context.Context, fromPosition int6
 				&stored.Position,
 				&stored.OccurredAt,
 				&stored.CreatedAt,
+				&stored.SchemaVersion,
+				&stored.TenantID,
 			); err != nil {
 				return
 			}
@@ -339,7 +752,7 @@ func (s *PostgresEventStore) GetAllEvents(ctx context.Context, fromPosition int6
 
 			// Десериализуем eventData обратно в events.Event
 			if s.deserializer != nil {
-				event, err := s.deserializer.DeserializeEvent(stored.EventType, eventDataJSON)
+				event, err := deserializeStoredEvent(s.deserializer, stored.EventType, stored.SchemaVersion, eventDataJSON)
 				if err != nil {
 					continue
 				}
@@ -357,10 +770,258 @@ func (s *PostgresEventStore) GetAllEvents(ctx context.Context, fromPosition int6
 	return ch, nil
 }
 
+// TruncateStream удаляет события агрегата с версией меньше beforeVersion
+// (реализация EventStreamTruncator, используется StreamArchiver)
+func (s *PostgresEventStore) TruncateStream(ctx context.Context, aggregateID string, beforeVersion int64) error {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tables := []string{fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)}
+	s.materializedMu.RLock()
+	if len(s.materializedTypes) > 0 {
+		tables = append(tables, s.typeStreamsTable())
+	}
+	s.materializedMu.RUnlock()
+
+	for _, table := range tables {
+		query := fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE tenant_id = $1 AND aggregate_id = $2 AND version < $3
+		`, table)
+		if _, err := tx.Exec(ctx, query, tenantID, aggregateID, beforeVersion); err != nil {
+			return fmt.Errorf("failed to truncate stream: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListStaleStreams возвращает агрегаты, последнее событие которых произошло раньше before
+// (реализация StaleStreamLister)
+func (s *PostgresEventStore) ListStaleStreams(ctx context.Context, before time.Time, after string, limit int) ([]string, error) {
+	tableName := fmt.Sprintf("%s.%s", s.config.SchemaName, s.config.TableName)
+	query := fmt.Sprintf(`
+		SELECT aggregate_id FROM %s
+		WHERE tenant_id = $1 AND aggregate_id > $2
+		GROUP BY aggregate_id
+		HAVING MAX(occurred_at) < $3 AND COUNT(*) > 1
+		ORDER BY aggregate_id
+		LIMIT $4
+	`, tableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, query, tenantID, after, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale streams: %w", err)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var aggregateID string
+		if err := rows.Scan(&aggregateID); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate id: %w", err)
+		}
+		result = append(result, aggregateID)
+	}
+	return result, rows.Err()
+}
+
+// ListAggregates возвращает страницу агрегатов тенанта (реализация AggregateLister).
+// Время создания агрегата - время его первого события, оставшегося в хранилище.
+func (s *PostgresEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := aggregatePageLimit(filter)
+	args := []interface{}{tenantID, filter.After}
+	conditions := []string{"tenant_id = $1", "aggregate_id > $2"}
+	if filter.AggregateType != "" {
+		args = append(args, filter.AggregateType)
+		conditions = append(conditions, fmt.Sprintf("aggregate_type = $%d", len(args)))
+	}
+	having := []string{"TRUE"}
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom)
+		having = append(having, fmt.Sprintf("MIN(occurred_at) >= $%d", len(args)))
+	}
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo)
+		having = append(having, fmt.Sprintf("MIN(occurred_at) < $%d", len(args)))
+	}
+	args = append(args, limit+1)
+
+	query := fmt.Sprintf(`
+		SELECT aggregate_id, MAX(aggregate_type), MAX(version), COUNT(*), MIN(occurred_at), MAX(occurred_at)
+		FROM %s.%s
+		WHERE %s
+		GROUP BY aggregate_id
+		HAVING %s
+		ORDER BY aggregate_id
+		LIMIT $%d
+	`, s.config.SchemaName, s.config.TableName, strings.Join(conditions, " AND "), strings.Join(having, " AND "), len(args))
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []AggregateSummary
+	for rows.Next() {
+		var summary AggregateSummary
+		if err := rows.Scan(&summary.AggregateID, &summary.AggregateType, &summary.Version,
+			&summary.EventCount, &summary.CreatedAt, &summary.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate: %w", err)
+		}
+		aggregates = append(aggregates, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+
+	return newAggregatePage(aggregates, limit), nil
+}
+
+// PostgresArchiveIndex реализация ArchiveIndex в таблице <table>_archive_segments
+// рядом с событиями (миграция 008). Использует соединение и тенанта PostgresEventStore.
+type PostgresArchiveIndex struct {
+	store *PostgresEventStore
+}
+
+// NewPostgresArchiveIndex создает индекс архива для хранилища событий
+func NewPostgresArchiveIndex(store *PostgresEventStore) *PostgresArchiveIndex {
+	return &PostgresArchiveIndex{store: store}
+}
+
+// AddSegment сохраняет указатель на сегмент
+func (i *PostgresArchiveIndex) AddSegment(ctx context.Context, segment ArchiveSegment) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (tenant_id, aggregate_id, object_key, format, from_version, to_version, from_position, to_position, event_count, size_bytes, checksum, archived_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (tenant_id, object_key)
+		DO UPDATE SET format = $4, from_version = $5, to_version = $6, from_position = $7, to_position = $8,
+			event_count = $9, size_bytes = $10, checksum = $11, archived_at = $12
+	`, i.table())
+
+	tenantID, err := resolveTenant(ctx, i.store.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.store.pool.Exec(ctx, query,
+		tenantID,
+		segment.AggregateID,
+		segment.Key,
+		segment.Format,
+		segment.FromVersion,
+		segment.ToVersion,
+		segment.FromPosition,
+		segment.ToPosition,
+		segment.EventCount,
+		segment.Size,
+		segment.Checksum,
+		segment.ArchivedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save archive segment: %w", err)
+	}
+	return nil
+}
+
+// GetSegments возвращает сегменты агрегата в порядке версий
+func (i *PostgresArchiveIndex) GetSegments(ctx context.Context, aggregateID string) ([]ArchiveSegment, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE tenant_id = $1 AND aggregate_id = $2
+		ORDER BY from_version ASC
+	`, archiveSegmentColumns, i.table())
+
+	tenantID, err := resolveTenant(ctx, i.store.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+	return i.querySegments(ctx, query, tenantID, aggregateID)
+}
+
+// ListSegments возвращает сегменты всех агрегатов с событиями начиная с позиции fromPosition
+func (i *PostgresArchiveIndex) ListSegments(ctx context.Context, fromPosition int64) ([]ArchiveSegment, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE tenant_id = $1 AND to_position >= $2
+		ORDER BY from_position ASC
+	`, archiveSegmentColumns, i.table())
+
+	tenantID, err := resolveTenant(ctx, i.store.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+	return i.querySegments(ctx, query, tenantID, fromPosition)
+}
+
+// archiveSegmentColumns колонки указателя на сегмент в порядке querySegments
+const archiveSegmentColumns = "aggregate_id, object_key, format, from_version, to_version, from_position, to_position, event_count, size_bytes, checksum, archived_at"
+
+// querySegments выполняет запрос указателей на сегменты
+func (i *PostgresArchiveIndex) querySegments(ctx context.Context, query string, args ...interface{}) ([]ArchiveSegment, error) {
+	rows, err := i.store.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive segments: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ArchiveSegment
+	for rows.Next() {
+		var segment ArchiveSegment
+		err := rows.Scan(
+			&segment.AggregateID,
+			&segment.Key,
+			&segment.Format,
+			&segment.FromVersion,
+			&segment.ToVersion,
+			&segment.FromPosition,
+			&segment.ToPosition,
+			&segment.EventCount,
+			&segment.Size,
+			&segment.Checksum,
+			&segment.ArchivedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan archive segment: %w", err)
+		}
+		result = append(result, segment)
+	}
+	return result, rows.Err()
+}
+
+// table возвращает таблицу указателей на сегменты архива
+func (i *PostgresArchiveIndex) table() string {
+	return fmt.Sprintf("%s.%s_archive_segments", i.store.config.SchemaName, i.store.config.TableName)
+}
+
 // PostgresSnapshotStore реализация SnapshotStore для PostgreSQL
 type PostgresSnapshotStore struct {
-	config PostgresEventStoreConfig
-	pool   *pgx.Conn
+	config         PostgresEventStoreConfig
+	pool           *pgx.Conn
+	signer         SnapshotSigner
+	tenantResolver TenantResolver
+	retention      snapshotRetention
 }
 
 // NewPostgresSnapshotStore создает новый PostgreSQL Snapshot Store
@@ -375,39 +1036,109 @@ func NewPostgresSnapshotStore(config PostgresEventStoreConfig) (*PostgresSnapsho
 	}
 
 	return &PostgresSnapshotStore{
-		config: config,
-		pool:   conn,
+		config:    config,
+		pool:      conn,
+		retention: snapshotRetention{store: "postgres"},
 	}, nil
 }
 
+// WithSigner включает подпись снапшотов и проверку подписи при загрузке
+func (s *PostgresSnapshotStore) WithSigner(signer SnapshotSigner) *PostgresSnapshotStore {
+	s.signer = signer
+	return s
+}
+
+// WithTenantResolver включает изоляцию снапшотов по тенантам (см. PostgresEventStore.WithTenantResolver)
+func (s *PostgresSnapshotStore) WithTenantResolver(resolver TenantResolver) *PostgresSnapshotStore {
+	s.tenantResolver = resolver
+	return s
+}
+
 // SaveSnapshot сохраняет снапшот
 func (s *PostgresSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
 	tableName := fmt.Sprintf("%s.snapshots", s.config.SchemaName)
 	query := fmt.Sprintf(`
-		INSERT INTO %s (aggregate_id, aggregate_type, version, state, metadata, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (aggregate_id) 
-		DO UPDATE SET version = $3, state = $4, metadata = $5, updated_at = $7
+		INSERT INTO %s (aggregate_id, aggregate_type, version, state, metadata, created_at, updated_at, checksum, signature, tenant_id, state_encoded, state_encoding)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (tenant_id, aggregate_id) 
+		DO UPDATE SET version = $3, state = $4, metadata = $5, updated_at = $7, checksum = $8, signature = $9,
+			state_encoded = $11, state_encoding = $12
 	`, tableName)
 
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	if err := SealSnapshot(&snapshot, s.signer); err != nil {
+		return err
+	}
+
 	metadataJSON, err := json.Marshal(snapshot.Metadata)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	_, err = s.pool.Exec(ctx, query,
+	// Закодированное состояние хранится в state_encoded, state (JSONB) остается пустым
+	state := snapshot.State
+	encoded, encoding, err := EncodeSnapshotState(s.config.SnapshotCodec, snapshot.State)
+	if err != nil {
+		return err
+	}
+	if encoding != "" {
+		state = nil
+	} else {
+		encoded = nil
+	}
+
+	args := []interface{}{
 		snapshot.AggregateID,
 		snapshot.AggregateType,
 		snapshot.Version,
-		snapshot.State,
+		state,
 		metadataJSON,
 		snapshot.CreatedAt,
 		time.Now(),
-	)
+		snapshot.Checksum,
+		snapshot.Signature,
+		tenantID,
+		encoded,
+		encoding,
+	}
+
+	if !s.retention.currentPolicy().keepsHistory() {
+		if _, err := s.pool.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		return nil
+	}
+
+	// Заменяемый снапшот переносится в историю в той же транзакции
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	historyQuery := fmt.Sprintf(`
+		INSERT INTO %s (tenant_id, aggregate_id, aggregate_type, version, state, metadata, created_at, updated_at, checksum, signature, state_encoded, state_encoding)
+		SELECT tenant_id, aggregate_id, aggregate_type, version, state, metadata, created_at, updated_at, checksum, signature, state_encoded, state_encoding
+		FROM %s
+		WHERE tenant_id = $1 AND aggregate_id = $2 AND version < $3
+		ON CONFLICT (tenant_id, aggregate_id, version) DO NOTHING
+	`, s.historyTable(), tableName)
+	if _, err := tx.Exec(ctx, historyQuery, tenantID, snapshot.AggregateID, snapshot.Version); err != nil {
+		return fmt.Errorf("failed to archive snapshot: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
 		return fmt.Errorf("failed to save snapshot: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -415,21 +1146,33 @@ func (s *PostgresSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snaps
 func (s *PostgresSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
 	tableName := fmt.Sprintf("%s.snapshots", s.config.SchemaName)
 	query := fmt.Sprintf(`
-		SELECT aggregate_id, aggregate_type, version, state, metadata, created_at
+		SELECT aggregate_id, aggregate_type, version, state, metadata, created_at,
+			COALESCE(checksum, ''), COALESCE(signature, ''), state_encoded, state_encoding
 		FROM %s
-		WHERE aggregate_id = $1
+		WHERE tenant_id = $1 AND aggregate_id = $2
 	`, tableName)
 
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
 	var snapshot Snapshot
 	var metadataJSON []byte
+	var encoded []byte
+	var encoding string
 
-	err := s.pool.QueryRow(ctx, query, aggregateID).Scan(
+	err = s.pool.QueryRow(ctx, query, tenantID, aggregateID).Scan(
 		&snapshot.AggregateID,
 		&snapshot.AggregateType,
 		&snapshot.Version,
 		&snapshot.State,
 		&metadataJSON,
 		&snapshot.CreatedAt,
+		&snapshot.Checksum,
+		&snapshot.Signature,
+		&encoded,
+		&encoding,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -442,6 +1185,18 @@ func (s *PostgresSnapshotStore) GetSnapshot(ctx context.Context, aggregateID str
 		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
 
+	if encoding != "" {
+		state, err := DecodeSnapshotState(s.config.SnapshotCodec, encoding, encoded)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate %s: %w", snapshot.AggregateID, err)
+		}
+		snapshot.State = state
+	}
+
+	if err := VerifySnapshot(&snapshot, s.signer); err != nil {
+		return nil, err
+	}
+
 	return &snapshot, nil
 }
 
@@ -450,14 +1205,116 @@ func (s *PostgresSnapshotStore) DeleteSnapshots(ctx context.Context, aggregateID
 	tableName := fmt.Sprintf("%s.snapshots", s.config.SchemaName)
 	query := fmt.Sprintf(`
 		DELETE FROM %s
-		WHERE aggregate_id = $1 AND version < $2
+		WHERE tenant_id = $1 AND aggregate_id = $2 AND version < $3
 	`, tableName)
 
-	_, err := s.pool.Exec(ctx, query, aggregateID, beforeVersion)
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, query, tenantID, aggregateID, beforeVersion)
 	if err != nil {
 		return fmt.Errorf("failed to delete snapshots: %w", err)
 	}
 
+	if s.retention.currentPolicy().keepsHistory() {
+		historyQuery := fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE tenant_id = $1 AND aggregate_id = $2 AND version < $3
+		`, s.historyTable())
+		if _, err := s.pool.Exec(ctx, historyQuery, tenantID, aggregateID, beforeVersion); err != nil {
+			return fmt.Errorf("failed to delete snapshot history: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// WithRetention задает политику хранения снапшотов.
+// При KeepLast > 1 требуется таблица snapshot_history (миграция 007).
+func (s *PostgresSnapshotStore) WithRetention(policy SnapshotRetentionPolicy) *PostgresSnapshotStore {
+	s.retention.setPolicy(policy)
+	return s
+}
+
+// WithMetrics включает метрики очистки снапшотов (eventsourcing_snapshot_prune_*)
+func (s *PostgresSnapshotStore) WithMetrics(recorder metrics.Recorder) *PostgresSnapshotStore {
+	s.retention.setRecorder(recorder)
+	return s
+}
+
+// Prune удаляет снапшоты, не попадающие под политику хранения, во всех тенантах:
+// историю сверх KeepLast-1 версий на агрегат и снапшоты старше MaxAge.
+// BytesReclaimed оценивается по pg_column_size удаленных строк; место на диске
+// возвращается после VACUUM.
+func (s *PostgresSnapshotStore) Prune(ctx context.Context) (SnapshotPruneResult, error) {
+	return s.retention.prune(ctx, s.prune)
+}
+
+// StartPruner запускает фоновую очистку с периодом SnapshotRetentionPolicy.Interval
+func (s *PostgresSnapshotStore) StartPruner(ctx context.Context) error {
+	return s.retention.start(ctx, s.prune)
+}
+
+// StopPruner останавливает фоновую очистку
+func (s *PostgresSnapshotStore) StopPruner() {
+	s.retention.stop()
+}
+
+func (s *PostgresSnapshotStore) prune(ctx context.Context, policy SnapshotRetentionPolicy) (SnapshotPruneResult, error) {
+	var result SnapshotPruneResult
+
+	var cutoff *time.Time
+	if policy.MaxAge > 0 {
+		t := time.Now().Add(-policy.MaxAge)
+		cutoff = &t
+	}
+
+	if policy.keepsHistory() {
+		query := fmt.Sprintf(`
+			WITH ranked AS (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY tenant_id, aggregate_id ORDER BY version DESC) AS rn
+				FROM %[1]s
+			), deleted AS (
+				DELETE FROM %[1]s h
+				USING ranked
+				WHERE h.id = ranked.id AND (ranked.rn > $1 OR ($2::timestamp IS NOT NULL AND h.created_at < $2))
+				RETURNING pg_column_size(h.*) AS size
+			)
+			SELECT COUNT(*), COALESCE(SUM(size), 0) FROM deleted
+		`, s.historyTable())
+
+		var deleted, bytes int64
+		if err := s.pool.QueryRow(ctx, query, policy.KeepLast-1, cutoff).Scan(&deleted, &bytes); err != nil {
+			return result, fmt.Errorf("failed to prune snapshot history: %w", err)
+		}
+		result.Deleted += deleted
+		result.BytesReclaimed += bytes
+	}
+
+	if cutoff != nil {
+		query := fmt.Sprintf(`
+			WITH deleted AS (
+				DELETE FROM %s.snapshots s
+				WHERE s.created_at < $1
+				RETURNING pg_column_size(s.*) AS size
+			)
+			SELECT COUNT(*), COALESCE(SUM(size), 0) FROM deleted
+		`, s.config.SchemaName)
+
+		var deleted, bytes int64
+		if err := s.pool.QueryRow(ctx, query, *cutoff).Scan(&deleted, &bytes); err != nil {
+			return result, fmt.Errorf("failed to prune expired snapshots: %w", err)
+		}
+		result.Deleted += deleted
+		result.BytesReclaimed += bytes
+	}
+
+	return result, nil
+}
+
+// historyTable возвращает имя таблицы истории снапшотов
+func (s *PostgresSnapshotStore) historyTable() string {
+	return fmt.Sprintf("%s.snapshot_history", s.config.SchemaName)
+}
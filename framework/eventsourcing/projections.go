@@ -4,8 +4,13 @@ package eventsourcing
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/events"
 )
 
 // Projection интерфейс для проекций
@@ -15,6 +20,23 @@ type Projection interface {
 	Reset(ctx context.Context) error
 }
 
+// ShadowProjection проекция с поддержкой теневого rebuild.
+// Shadow возвращает копию проекции, которая пишет во временное хранилище,
+// Promote атомарно заменяет основную проекцию теневой после завершения rebuild.
+type ShadowProjection interface {
+	Projection
+	Shadow() Projection
+	Promote(ctx context.Context, shadow Projection) error
+}
+
+// RebuildOptions опции пересоздания проекции
+type RebuildOptions struct {
+	// FromPosition позиция, с которой начинается rebuild (0 - с начала, с вызовом Reset)
+	FromPosition int64
+	// Shadow выполнять rebuild в теневую копию без остановки чтения основной проекции
+	Shadow bool
+}
+
 // ProjectionStatus статус проекции
 type ProjectionStatus struct {
 	Name                string
@@ -23,7 +45,13 @@ type ProjectionStatus struct {
 	LastProcessedAt     time.Time
 	EventsProcessed      int64
 	ErrorCount          int64
+	// LastError последняя ошибка обработки события
+	LastError string
+	// LastErrorStackTrace stack trace, если последняя ошибка вызвана паникой в обработчике
+	LastErrorStackTrace string
 	Progress            float64 // для rebuild, 0-100
+	// PartitionPositions позиции checkpoint партиций (только для PartitionedProjection)
+	PartitionPositions []int64
 }
 
 // ProjectionManager управляет проекциями
@@ -32,6 +60,10 @@ type ProjectionManager struct {
 	checkpointStore CheckpointStore
 	projections     map[string]Projection
 	runners         map[string]*ProjectionRunner
+	priorities      map[string]ProjectionPriority
+	groups          map[ProjectionPriority]*projectionGroup
+	tenantID        string
+	tenantErr       error
 	mu              sync.RWMutex
 }
 
@@ -45,6 +77,47 @@ func NewProjectionManager(eventStore EventStore, checkpointStore CheckpointStore
 	}
 }
 
+// WithTenant ограничивает менеджер одним тенантом: проекции читают только события тенанта
+// (хранилище событий должно быть настроено через TenantResolver, например
+// PostgresEventStore.WithTenantResolver(ContextTenantResolver(true))), а checkpoints
+// хранятся отдельно для каждого тенанта (см. TenantCheckpointStore).
+// Если хранилище не поддерживает изоляцию тенантов (см. TenantIsolatedStore),
+// Start возвращает ErrTenantIsolationUnsupported. Должен вызываться до Start.
+func (m *ProjectionManager) WithTenant(tenantID string) *ProjectionManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if scoped, ok := m.eventStore.(*TenantEventStore); ok {
+		m.eventStore = scoped.store
+	}
+	if scoped, ok := m.checkpointStore.(*TenantCheckpointStore); ok {
+		m.checkpointStore = scoped.store
+	}
+	scoped := NewTenantEventStore(m.eventStore, tenantID)
+	m.tenantID = tenantID
+	m.tenantErr = scoped.Err()
+	m.eventStore = scoped
+	m.checkpointStore = NewTenantCheckpointStore(m.checkpointStore, tenantID)
+	return m
+}
+
+// WithEventTypeAliases передает проекциям события переименованных типов под актуальным
+// именем (см. AliasingEventStore): обработчики регистрируются только для нового имени.
+// Должен вызываться до Start.
+func (m *ProjectionManager) WithEventTypeAliases(aliases *events.EventTypeAliases) *ProjectionManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventStore = NewAliasingEventStore(m.eventStore, aliases)
+	return m
+}
+
+// TenantID возвращает тенант менеджера ("" - тенант по умолчанию)
+func (m *ProjectionManager) TenantID() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tenantID
+}
+
 // Register регистрирует проекцию
 func (m *ProjectionManager) Register(projection Projection) error {
 	m.mu.Lock()
@@ -59,13 +132,18 @@ func (m *ProjectionManager) Register(projection Projection) error {
 	return nil
 }
 
-// Start запускает все проекции
+// Start запускает все проекции: каждая проекция обрабатывается собственным воркером
+// с независимым checkpoint, группы запускаются в порядке приоритета (см. WithGroup)
 func (m *ProjectionManager) Start(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for name, projection := range m.projections {
-		runner := NewProjectionRunner(projection, m.eventStore, m.checkpointStore)
+	if m.tenantErr != nil {
+		return fmt.Errorf("failed to start projections of tenant %q: %w", m.tenantID, m.tenantErr)
+	}
+
+	for _, name := range m.startOrder() {
+		runner := m.newGroupRunner(m.projections[name])
 		m.runners[name] = runner
 
 		go func(r *ProjectionRunner) {
@@ -96,6 +174,11 @@ func (m *ProjectionManager) Stop(ctx context.Context) error {
 
 // Rebuild пересоздает проекцию
 func (m *ProjectionManager) Rebuild(ctx context.Context, projectionName string) error {
+	return m.RebuildWithOptions(ctx, projectionName, RebuildOptions{})
+}
+
+// RebuildWithOptions пересоздает проекцию с указанной позиции и/или в теневую копию
+func (m *ProjectionManager) RebuildWithOptions(ctx context.Context, projectionName string, opts RebuildOptions) error {
 	m.mu.Lock()
 	projection, exists := m.projections[projectionName]
 	m.mu.Unlock()
@@ -104,19 +187,174 @@ func (m *ProjectionManager) Rebuild(ctx context.Context, projectionName string)
 		return fmt.Errorf("projection %s not found", projectionName)
 	}
 
-	// Сбрасываем состояние проекции
-	if err := projection.Reset(ctx); err != nil {
-		return fmt.Errorf("failed to reset projection: %w", err)
+	if opts.Shadow {
+		return m.rebuildShadow(ctx, projection, opts)
+	}
+
+	// Сбрасываем состояние проекции только при полном rebuild
+	if opts.FromPosition <= 0 {
+		if err := projection.Reset(ctx); err != nil {
+			return fmt.Errorf("failed to reset projection: %w", err)
+		}
 	}
 
 	// Удаляем checkpoint
-	if err := m.checkpointStore.DeleteCheckpoint(ctx, projectionName); err != nil {
-		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	if err := m.deleteCheckpoints(ctx, projection); err != nil {
+		return err
 	}
 
 	// Запускаем rebuild
 	runner := NewProjectionRunner(projection, m.eventStore, m.checkpointStore)
-	return runner.Rebuild(ctx)
+	return runner.RebuildFrom(ctx, opts.FromPosition)
+}
+
+// rebuildShadow пересоздает проекцию в теневую копию и продвигает ее после завершения
+func (m *ProjectionManager) rebuildShadow(ctx context.Context, projection Projection, opts RebuildOptions) error {
+	shadowable, ok := projection.(ShadowProjection)
+	if !ok {
+		return fmt.Errorf("projection %s does not support shadow rebuild", projection.Name())
+	}
+
+	shadow := shadowable.Shadow()
+	if shadow == nil || shadow.Name() == projection.Name() {
+		return fmt.Errorf("projection %s returned invalid shadow projection", projection.Name())
+	}
+
+	if opts.FromPosition <= 0 {
+		if err := shadow.Reset(ctx); err != nil {
+			return fmt.Errorf("failed to reset shadow projection: %w", err)
+		}
+	}
+
+	if err := m.deleteCheckpoints(ctx, shadow); err != nil {
+		return fmt.Errorf("failed to delete shadow checkpoint: %w", err)
+	}
+
+	runner := NewProjectionRunner(shadow, m.eventStore, m.checkpointStore)
+	if err := runner.RebuildFrom(ctx, opts.FromPosition); err != nil {
+		return fmt.Errorf("shadow rebuild failed: %w", err)
+	}
+
+	if err := shadowable.Promote(ctx, shadow); err != nil {
+		return fmt.Errorf("failed to promote shadow projection: %w", err)
+	}
+
+	// Переносим checkpoint теневой проекции на основную
+	position, err := m.checkpointStore.GetCheckpoint(ctx, shadow.Name())
+	if err != nil {
+		return fmt.Errorf("failed to get shadow checkpoint: %w", err)
+	}
+	if err := m.checkpointStore.SaveCheckpoint(ctx, projection.Name(), position); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if partitions := projectionPartitions(shadow); partitions > 1 {
+		for i := 0; i < partitions; i++ {
+			position, err := m.checkpointStore.GetCheckpoint(ctx, PartitionCheckpointName(shadow.Name(), i))
+			if err != nil {
+				return fmt.Errorf("failed to get shadow checkpoint: %w", err)
+			}
+			if err := m.checkpointStore.SaveCheckpoint(ctx, PartitionCheckpointName(projection.Name(), i), position); err != nil {
+				return fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+		}
+	}
+	return m.deleteCheckpoints(ctx, shadow)
+}
+
+// deleteCheckpoints удаляет checkpoint проекции и checkpoints ее партиций
+func (m *ProjectionManager) deleteCheckpoints(ctx context.Context, projection Projection) error {
+	if err := m.checkpointStore.DeleteCheckpoint(ctx, projection.Name()); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	if partitions := projectionPartitions(projection); partitions > 1 {
+		for i := 0; i < partitions; i++ {
+			if err := m.checkpointStore.DeleteCheckpoint(ctx, PartitionCheckpointName(projection.Name(), i)); err != nil {
+				return fmt.Errorf("failed to delete partition checkpoint: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResetCheckpoint устанавливает checkpoint проекции на указанную позицию.
+// Для партиционированной проекции позиция устанавливается всем партициям.
+// Запущенный runner продолжит обработку с новой позиции после перезапуска.
+func (m *ProjectionManager) ResetCheckpoint(ctx context.Context, projectionName string, position int64) error {
+	m.mu.RLock()
+	projection, exists := m.projections[projectionName]
+	m.mu.RUnlock()
+
+	if position <= 0 {
+		if exists {
+			return m.deleteCheckpoints(ctx, projection)
+		}
+		return m.checkpointStore.DeleteCheckpoint(ctx, projectionName)
+	}
+
+	if err := m.checkpointStore.SaveCheckpoint(ctx, projectionName, position); err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	if partitions := projectionPartitions(projection); partitions > 1 {
+		for i := 0; i < partitions; i++ {
+			if err := m.checkpointStore.SaveCheckpoint(ctx, PartitionCheckpointName(projectionName, i), position); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// IsRegistered проверяет, зарегистрирована ли проекция
+func (m *ProjectionManager) IsRegistered(projectionName string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.projections[projectionName]
+	return exists
+}
+
+// ListStatuses возвращает статусы всех известных проекций:
+// зарегистрированных, запущенных и имеющих checkpoint
+func (m *ProjectionManager) ListStatuses(ctx context.Context) ([]ProjectionStatus, error) {
+	checkpoints, err := m.checkpointStore.ListCheckpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	m.mu.RLock()
+	statuses := make(map[string]ProjectionStatus)
+	for name := range m.projections {
+		statuses[name] = ProjectionStatus{Name: name, State: "stopped"}
+	}
+	for name, runner := range m.runners {
+		statuses[name] = *runner.GetStatus()
+	}
+	m.mu.RUnlock()
+
+	for name, position := range checkpoints {
+		// Checkpoints партиций отражаются в PartitionPositions статуса проекции
+		if _, _, ok := parsePartitionCheckpointName(name); ok {
+			continue
+		}
+		status, exists := statuses[name]
+		if !exists {
+			status = ProjectionStatus{Name: name, State: "unknown"}
+		}
+		if status.LastProcessedPosition < position {
+			status.LastProcessedPosition = position
+		}
+		statuses[name] = status
+	}
+
+	result := make([]ProjectionStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
 }
 
 // GetStatus возвращает статус проекции
@@ -137,8 +375,14 @@ type ProjectionRunner struct {
 	projection      Projection
 	eventStore      EventStore
 	checkpointStore CheckpointStore
+	txCheckpoints   TransactionalCheckpointStore // nil - checkpoint сохраняется после обработки
 	status          *ProjectionStatus
+	partitions      int
+	group           *projectionGroup // nil - runner вне групп (rebuild)
+	ahead           func() int64     // позиция более приоритетных групп
+	aheadPosition   atomic.Int64
 	mu              sync.RWMutex
+	checkpointMu    sync.Mutex // сериализует запись checkpoints воркерами партиций
 	stopChan        chan struct{}
 }
 
@@ -148,12 +392,14 @@ func NewProjectionRunner(projection Projection, eventStore EventStore, checkpoin
 		projection:      projection,
 		eventStore:      eventStore,
 		checkpointStore: checkpointStore,
+		txCheckpoints:   transactionalCheckpoints(projection, checkpointStore),
 		status: &ProjectionStatus{
 			Name:   projection.Name(),
 			State:  "stopped",
 			EventsProcessed: 0,
 			ErrorCount: 0,
 		},
+		partitions: projectionPartitions(projection),
 		stopChan: make(chan struct{}),
 	}
 }
@@ -165,16 +411,26 @@ func (r *ProjectionRunner) Run(ctx context.Context) error {
 	r.status.LastProcessedAt = time.Now()
 	r.mu.Unlock()
 
+	if r.partitions > 1 {
+		return r.runPartitioned(ctx, true, 0)
+	}
+
 	// Получаем последнюю позицию
 	position, err := r.checkpointStore.GetCheckpoint(ctx, r.projection.Name())
 	if err != nil {
 		position = 0
 	}
+	r.mu.Lock()
+	if position > r.status.LastProcessedPosition {
+		r.status.LastProcessedPosition = position
+	}
+	r.mu.Unlock()
 
-	// Получаем события начиная с позиции
-	eventsChan, err := r.eventStore.GetAllEvents(ctx, position)
+	// Подписка сначала отдает события после checkpoint, затем новые события по мере записи
+	subscriber := NewEventSubscriber(r.eventStore)
+	eventsChan, err := subscriber.SubscribeToAll(ctx, position)
 	if err != nil {
-		return fmt.Errorf("failed to get events: %w", err)
+		return fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
 	for {
@@ -185,35 +441,32 @@ func (r *ProjectionRunner) Run(ctx context.Context) error {
 			return nil
 		case event, ok := <-eventsChan:
 			if !ok {
-				// Канал закрыт, пересоздаем поток с последней позиции
-				position, err := r.checkpointStore.GetCheckpoint(ctx, r.projection.Name())
-				if err != nil {
-					position = r.status.LastProcessedPosition
+				// Подписка разорвана, переподписываемся с последней обработанной позиции
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-r.stopChan:
+					return nil
+				case <-time.After(subscriptionRetryDelay):
 				}
-				// Пересоздаем канал событий
-				newChan, err := r.eventStore.GetAllEvents(ctx, position)
-				if err != nil {
-					time.Sleep(1 * time.Second)
-					continue
+				newChan, err := subscriber.SubscribeToAll(ctx, position)
+				if err == nil {
+					eventsChan = newChan
 				}
-				eventsChan = newChan
 				continue
 			}
 
-			// Обрабатываем событие
-			if err := r.projection.HandleEvent(ctx, event); err != nil {
-				r.mu.Lock()
-				r.status.ErrorCount++
-				r.mu.Unlock()
-				// Продолжаем обработку несмотря на ошибку
+			// Событие checkpoint уже обработано
+			if position > 0 && event.Position <= position {
 				continue
 			}
 
-			// Сохраняем checkpoint
-			if err := r.checkpointStore.SaveCheckpoint(ctx, r.projection.Name(), event.Position); err != nil {
-				// Логируем ошибку, но продолжаем
+			// Обрабатываем событие и сохраняем checkpoint
+			if err := r.applyEvent(ctx, r.projection.Name(), event); err != nil {
+				// Продолжаем обработку несмотря на ошибку
 				continue
 			}
+			position = event.Position
 
 			r.mu.Lock()
 			r.status.LastProcessedPosition = event.Position
@@ -236,13 +489,29 @@ func (r *ProjectionRunner) Stop(ctx context.Context) error {
 
 // Rebuild пересоздает проекцию
 func (r *ProjectionRunner) Rebuild(ctx context.Context) error {
+	return r.RebuildFrom(ctx, 0)
+}
+
+// RebuildFrom пересоздает проекцию начиная с указанной позиции
+func (r *ProjectionRunner) RebuildFrom(ctx context.Context, fromPosition int64) error {
 	r.mu.Lock()
 	r.status.State = "rebuilding"
 	r.status.Progress = 0
 	r.mu.Unlock()
 
-	// Получаем все события с начала
-	eventsChan, err := r.eventStore.GetAllEvents(ctx, 0)
+	if r.partitions > 1 {
+		if err := r.runPartitioned(ctx, false, fromPosition); err != nil {
+			return err
+		}
+		r.mu.Lock()
+		r.status.State = "running"
+		r.status.Progress = 100
+		r.mu.Unlock()
+		return nil
+	}
+
+	// Получаем события начиная с позиции
+	eventsChan, err := r.eventStore.GetAllEvents(ctx, fromPosition)
 	if err != nil {
 		return fmt.Errorf("failed to get events: %w", err)
 	}
@@ -262,14 +531,7 @@ func (r *ProjectionRunner) Rebuild(ctx context.Context) error {
 		default:
 		}
 
-		if err := r.projection.HandleEvent(ctx, event); err != nil {
-			r.mu.Lock()
-			r.status.ErrorCount++
-			r.mu.Unlock()
-			continue
-		}
-
-		if err := r.checkpointStore.SaveCheckpoint(ctx, r.projection.Name(), event.Position); err != nil {
+		if err := r.applyEvent(ctx, r.projection.Name(), event); err != nil {
 			continue
 		}
 
@@ -294,12 +556,42 @@ func (r *ProjectionRunner) Rebuild(ctx context.Context) error {
 	return nil
 }
 
+// handleEvent передает событие проекции после ожидания очереди группы (см. admit).
+// Паника обработчика преобразуется в ошибку с кодом core.ErrPanicRecovered,
+// чтобы не останавливать горутину runner'а. Ошибка учитывается в статусе проекции.
+func (r *ProjectionRunner) handleEvent(ctx context.Context, event StoredEvent) error {
+	release, err := r.admit(ctx, event.Position)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = core.SafeCall(func() error {
+		return r.projection.HandleEvent(ctx, event)
+	})
+	if err != nil {
+		r.recordError(err)
+	}
+	return err
+}
+
+// recordError учитывает ошибку обработки события в статусе проекции
+func (r *ProjectionRunner) recordError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.ErrorCount++
+	r.status.LastError = err.Error()
+	r.status.LastErrorStackTrace = core.StackTraceOf(err)
+}
+
 // GetStatus возвращает статус проекции
 func (r *ProjectionRunner) GetStatus() *ProjectionStatus {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	status := *r.status
+	status.PartitionPositions = append([]int64(nil), r.status.PartitionPositions...)
 	return &status
 }
 
@@ -307,8 +599,12 @@ func (r *ProjectionRunner) GetStatus() *ProjectionStatus {
 type ProjectionBuilder struct {
 	name            string
 	eventHandlers   map[string]func(context.Context, StoredEvent) error
+	backfill        func(context.Context, BackfillRecord) error
+	erase           func(context.Context, string) (int64, error)
 	checkpointStore CheckpointStore
 	batchSize       int
+	partitions      int
+	priority        ProjectionPriority
 }
 
 // NewProjectionBuilder создает новый ProjectionBuilder
@@ -326,6 +622,18 @@ func (b *ProjectionBuilder) OnEvent(eventType string, handler func(context.Conte
 	return b
 }
 
+// OnBackfill регистрирует обработчик записей backfill (см. ProjectionManager.Backfill)
+func (b *ProjectionBuilder) OnBackfill(handler func(context.Context, BackfillRecord) error) *ProjectionBuilder {
+	b.backfill = handler
+	return b
+}
+
+// OnErase регистрирует обработчик удаления данных субъекта из read model (см. ErasureCoordinator)
+func (b *ProjectionBuilder) OnErase(handler func(ctx context.Context, subjectID string) (int64, error)) *ProjectionBuilder {
+	b.erase = handler
+	return b
+}
+
 // WithCheckpointStore устанавливает checkpoint store
 func (b *ProjectionBuilder) WithCheckpointStore(store CheckpointStore) *ProjectionBuilder {
 	b.checkpointStore = store
@@ -338,11 +646,28 @@ func (b *ProjectionBuilder) WithBatchSize(size int) *ProjectionBuilder {
 	return b
 }
 
+// WithPartitions включает партиционированную обработку событий по ID агрегата
+// в указанном числе воркеров (обработчики должны быть безопасны для конкурентного вызова)
+func (b *ProjectionBuilder) WithPartitions(partitions int) *ProjectionBuilder {
+	b.partitions = partitions
+	return b
+}
+
+// WithPriority устанавливает приоритет группы проекции (см. ProjectionManager.WithGroup)
+func (b *ProjectionBuilder) WithPriority(priority ProjectionPriority) *ProjectionBuilder {
+	b.priority = priority
+	return b
+}
+
 // Build создает проекцию
 func (b *ProjectionBuilder) Build() Projection {
 	return &BuilderProjection{
 		name:          b.name,
 		eventHandlers: b.eventHandlers,
+		backfill:      b.backfill,
+		erase:         b.erase,
+		partitions:    b.partitions,
+		priority:      b.priority,
 	}
 }
 
@@ -350,12 +675,26 @@ func (b *ProjectionBuilder) Build() Projection {
 type BuilderProjection struct {
 	name          string
 	eventHandlers map[string]func(context.Context, StoredEvent) error
+	backfill      func(context.Context, BackfillRecord) error
+	erase         func(context.Context, string) (int64, error)
+	partitions    int
+	priority      ProjectionPriority
 }
 
 func (p *BuilderProjection) Name() string {
 	return p.name
 }
 
+// Priority возвращает приоритет группы проекции
+func (p *BuilderProjection) Priority() ProjectionPriority {
+	return p.priority
+}
+
+// Partitions возвращает число партиций обработки (0 или 1 - последовательная обработка)
+func (p *BuilderProjection) Partitions() int {
+	return p.partitions
+}
+
 func (p *BuilderProjection) HandleEvent(ctx context.Context, event StoredEvent) error {
 	handler, exists := p.eventHandlers[event.EventType]
 	if !exists {
@@ -364,6 +703,22 @@ func (p *BuilderProjection) HandleEvent(ctx context.Context, event StoredEvent)
 	return handler(ctx, event)
 }
 
+// Backfill загружает запись исторических данных обработчиком OnBackfill
+func (p *BuilderProjection) Backfill(ctx context.Context, record BackfillRecord) error {
+	if p.backfill == nil {
+		return fmt.Errorf("%w: %s", ErrBackfillNotSupported, p.name)
+	}
+	return p.backfill(ctx, record)
+}
+
+// Erase удаляет данные субъекта обработчиком OnErase
+func (p *BuilderProjection) Erase(ctx context.Context, subjectID string) (int64, error) {
+	if p.erase == nil {
+		return 0, fmt.Errorf("%w: %s", ErrErasureNotSupported, p.name)
+	}
+	return p.erase(ctx, subjectID)
+}
+
 func (p *BuilderProjection) Reset(ctx context.Context) error {
 	// Для builder проекций reset не требуется
 	return nil
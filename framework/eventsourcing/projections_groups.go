@@ -0,0 +1,360 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ProjectionPriority приоритет группы проекций
+type ProjectionPriority int
+
+const (
+	// ProjectionPriorityCritical read models, которые обновляются первыми (приоритет по умолчанию)
+	ProjectionPriorityCritical ProjectionPriority = iota
+	// ProjectionPriorityBestEffort аналитические проекции, которые обрабатывают событие
+	// только после того, как его обработали все critical проекции
+	ProjectionPriorityBestEffort
+)
+
+// String возвращает имя приоритета
+func (p ProjectionPriority) String() string {
+	switch p {
+	case ProjectionPriorityCritical:
+		return "critical"
+	case ProjectionPriorityBestEffort:
+		return "best-effort"
+	default:
+		return fmt.Sprintf("priority-%d", int(p))
+	}
+}
+
+// projectionGroupPollInterval интервал проверки позиций более приоритетных групп
+const projectionGroupPollInterval = 50 * time.Millisecond
+
+// errProjectionStopped runner остановлен во время ожидания очереди группы
+var errProjectionStopped = errors.New("projection runner stopped")
+
+// PrioritizedProjection проекция с приоритетом группы.
+// Проекции, не реализующие интерфейс, относятся к ProjectionPriorityCritical.
+type PrioritizedProjection interface {
+	Projection
+	Priority() ProjectionPriority
+}
+
+// ProjectionGroupConfig настройки группы проекций одного приоритета
+type ProjectionGroupConfig struct {
+	// Workers число проекций группы, одновременно обрабатывающих события
+	// (0 - без ограничения, каждая проекция обрабатывается собственным воркером)
+	Workers int
+	// LagSLO допустимое отставание проекций группы: возраст самого старого
+	// необработанного события (0 - SLO не задан)
+	LagSLO time.Duration
+}
+
+// projectionGroup группа проекций одного приоритета
+type projectionGroup struct {
+	config ProjectionGroupConfig
+	slots  chan struct{} // nil - без ограничения числа воркеров
+}
+
+func newProjectionGroup(config ProjectionGroupConfig) *projectionGroup {
+	group := &projectionGroup{config: config}
+	if config.Workers > 0 {
+		group.slots = make(chan struct{}, config.Workers)
+	}
+	return group
+}
+
+// ProjectionLag отставание проекции от потока событий
+type ProjectionLag struct {
+	Name     string
+	Position int64
+	// Lag возраст самого старого необработанного события (0 - проекция догнала поток)
+	Lag         time.Duration
+	SLOBreached bool
+}
+
+// ProjectionGroupStatus статус группы проекций
+type ProjectionGroupStatus struct {
+	Priority    ProjectionPriority
+	Workers     int
+	LagSLO      time.Duration
+	Projections []ProjectionLag
+	// SLOBreached хотя бы одна проекция группы отстает больше LagSLO
+	SLOBreached bool
+}
+
+// WithGroup настраивает группу проекций приоритета priority.
+// Должен вызываться до Start.
+func (m *ProjectionManager) WithGroup(priority ProjectionPriority, config ProjectionGroupConfig) *ProjectionManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.groups == nil {
+		m.groups = make(map[ProjectionPriority]*projectionGroup)
+	}
+	m.groups[priority] = newProjectionGroup(config)
+	return m
+}
+
+// RegisterWithPriority регистрирует проекцию в группе priority
+// (переопределяет приоритет PrioritizedProjection)
+func (m *ProjectionManager) RegisterWithPriority(projection Projection, priority ProjectionPriority) error {
+	if err := m.Register(projection); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.priorities == nil {
+		m.priorities = make(map[string]ProjectionPriority)
+	}
+	m.priorities[projection.Name()] = priority
+	return nil
+}
+
+// Priority возвращает приоритет зарегистрированной проекции
+func (m *ProjectionManager) Priority(projectionName string) ProjectionPriority {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.priorityOf(projectionName)
+}
+
+// priorityOf возвращает приоритет проекции (вызывается под m.mu)
+func (m *ProjectionManager) priorityOf(name string) ProjectionPriority {
+	if priority, ok := m.priorities[name]; ok {
+		return priority
+	}
+	if prioritized, ok := m.projections[name].(PrioritizedProjection); ok {
+		return prioritized.Priority()
+	}
+	return ProjectionPriorityCritical
+}
+
+// groupOf возвращает группу приоритета, создавая ее с настройками по умолчанию (вызывается под m.mu)
+func (m *ProjectionManager) groupOf(priority ProjectionPriority) *projectionGroup {
+	if m.groups == nil {
+		m.groups = make(map[ProjectionPriority]*projectionGroup)
+	}
+	group, ok := m.groups[priority]
+	if !ok {
+		group = newProjectionGroup(ProjectionGroupConfig{})
+		m.groups[priority] = group
+	}
+	return group
+}
+
+// startOrder возвращает имена проекций в порядке запуска: сначала более приоритетные группы
+// (вызывается под m.mu)
+func (m *ProjectionManager) startOrder() []string {
+	names := make([]string, 0, len(m.projections))
+	for name := range m.projections {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := m.priorityOf(names[i]), m.priorityOf(names[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// newGroupRunner создает runner проекции, подчиненный очереди ее группы (вызывается под m.mu)
+func (m *ProjectionManager) newGroupRunner(projection Projection) *ProjectionRunner {
+	runner := NewProjectionRunner(projection, m.eventStore, m.checkpointStore)
+	priority := m.priorityOf(projection.Name())
+	runner.group = m.groupOf(priority)
+	if priority > ProjectionPriorityCritical {
+		runner.ahead = func() int64 { return m.aheadPosition(priority) }
+	}
+	return runner
+}
+
+// aheadPosition возвращает минимальную позицию запущенных проекций более приоритетных групп
+func (m *ProjectionManager) aheadPosition(priority ProjectionPriority) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	low := int64(math.MaxInt64)
+	for name, runner := range m.runners {
+		if m.priorityOf(name) >= priority {
+			continue
+		}
+		if position := runner.GetStatus().LastProcessedPosition; position < low {
+			low = position
+		}
+	}
+	return low
+}
+
+// GroupStatuses возвращает статусы групп проекций с отставанием каждой проекции
+// от потока событий и признаком нарушения LagSLO группы
+func (m *ProjectionManager) GroupStatuses(ctx context.Context) ([]ProjectionGroupStatus, error) {
+	m.mu.RLock()
+	members := make(map[ProjectionPriority][]string)
+	for _, name := range m.startOrder() {
+		priority := m.priorityOf(name)
+		members[priority] = append(members[priority], name)
+	}
+	configs := make(map[ProjectionPriority]ProjectionGroupConfig)
+	for priority, group := range m.groups {
+		configs[priority] = group.config
+	}
+	m.mu.RUnlock()
+
+	for priority := range members {
+		if _, ok := configs[priority]; !ok {
+			configs[priority] = ProjectionGroupConfig{}
+		}
+	}
+
+	result := make([]ProjectionGroupStatus, 0, len(configs))
+	for priority, config := range configs {
+		status := ProjectionGroupStatus{
+			Priority: priority,
+			Workers:  config.Workers,
+			LagSLO:   config.LagSLO,
+		}
+		for _, name := range members[priority] {
+			position, err := m.projectionPosition(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			lag, err := m.projectionLag(ctx, position)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get lag of projection %s: %w", name, err)
+			}
+			projectionLag := ProjectionLag{
+				Name:        name,
+				Position:    position,
+				Lag:         lag,
+				SLOBreached: config.LagSLO > 0 && lag > config.LagSLO,
+			}
+			if projectionLag.SLOBreached {
+				status.SLOBreached = true
+			}
+			status.Projections = append(status.Projections, projectionLag)
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Priority < result[j].Priority })
+
+	return result, nil
+}
+
+// projectionLag возвращает возраст первого события после позиции проекции
+func (m *ProjectionManager) projectionLag(ctx context.Context, position int64) (time.Duration, error) {
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eventsChan, err := m.eventStore.GetAllEvents(readCtx, position+1)
+	if err != nil {
+		return 0, err
+	}
+	for event := range eventsChan {
+		if event.Position <= position {
+			continue
+		}
+		at := event.CreatedAt
+		if at.IsZero() {
+			at = event.OccurredAt
+		}
+		if at.IsZero() {
+			return 0, nil
+		}
+		return time.Since(at), nil
+	}
+	return 0, nil
+}
+
+// EventsBehind возвращает отставание каждой зарегистрированной проекции от последнего события
+// потока в числе событий (для metrics.PrometheusExporter.RegisterProjectionLag).
+// Поток читается один раз, начиная с позиции самой отстающей проекции.
+func (m *ProjectionManager) EventsBehind(ctx context.Context) (map[string]int64, error) {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.projections))
+	for name := range m.projections {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	positions := make(map[string]int64, len(names))
+	low := int64(math.MaxInt64)
+	for _, name := range names {
+		position, err := m.projectionPosition(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		positions[name] = position
+		low = min(low, position)
+	}
+
+	behind := make(map[string]int64, len(names))
+	for _, name := range names {
+		behind[name] = 0
+	}
+	if len(names) == 0 {
+		return behind, nil
+	}
+
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	eventsChan, err := m.eventStore.GetAllEvents(readCtx, low+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event stream: %w", err)
+	}
+	for event := range eventsChan {
+		for name, position := range positions {
+			if event.Position > position {
+				behind[name]++
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return behind, nil
+}
+
+// admit ожидает очереди группы на обработку события: проекция более низкого приоритета
+// обрабатывает событие только после того, как до его позиции продвинулись проекции
+// более приоритетных групп, а Workers группы ограничивает число одновременно
+// обрабатывающих события проекций. Возвращает функцию освобождения воркера.
+func (r *ProjectionRunner) admit(ctx context.Context, position int64) (func(), error) {
+	if r.group == nil {
+		return func() {}, nil
+	}
+
+	for r.ahead != nil && r.aheadPosition.Load() < position {
+		if ahead := r.ahead(); ahead > r.aheadPosition.Load() {
+			r.aheadPosition.Store(ahead)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-r.stopChan:
+			return nil, errProjectionStopped
+		case <-time.After(projectionGroupPollInterval):
+		}
+	}
+
+	if r.group.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case r.group.slots <- struct{}{}:
+		return func() { <-r.group.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.stopChan:
+		return nil, errProjectionStopped
+	}
+}
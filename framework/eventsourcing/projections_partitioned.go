@@ -0,0 +1,311 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// partitionCheckpointSeparator разделитель имени проекции и номера партиции в имени checkpoint
+const partitionCheckpointSeparator = "#partition-"
+
+const (
+	// partitionQueueSize размер очереди событий одной партиции
+	partitionQueueSize = 100
+	// partitionCheckpointInterval число распределенных событий, после которого
+	// все партиции (включая простаивающие) фиксируют checkpoint
+	partitionCheckpointInterval = 100
+)
+
+// PartitionedProjection проекция с партиционированной обработкой.
+// События распределяются по Partitions() воркерам по ID агрегата: события одного агрегата
+// всегда обрабатываются одним воркером в порядке записи, события разных агрегатов - параллельно.
+// HandleEvent должен быть безопасен для конкурентного вызова.
+// Каждая партиция хранит собственный checkpoint (см. PartitionCheckpointName).
+type PartitionedProjection interface {
+	Projection
+	Partitions() int
+}
+
+// projectionPartitions возвращает число партиций проекции (1 - последовательная обработка)
+func projectionPartitions(projection Projection) int {
+	if partitioned, ok := projection.(PartitionedProjection); ok && partitioned.Partitions() > 1 {
+		return partitioned.Partitions()
+	}
+	return 1
+}
+
+// PartitionCheckpointName возвращает имя checkpoint партиции проекции
+func PartitionCheckpointName(projectionName string, partition int) string {
+	return projectionName + partitionCheckpointSeparator + strconv.Itoa(partition)
+}
+
+// parsePartitionCheckpointName разбирает имя checkpoint партиции
+func parsePartitionCheckpointName(name string) (projectionName string, partition int, ok bool) {
+	idx := strings.LastIndex(name, partitionCheckpointSeparator)
+	if idx < 0 {
+		return "", 0, false
+	}
+	partition, err := strconv.Atoi(name[idx+len(partitionCheckpointSeparator):])
+	if err != nil || partition < 0 {
+		return "", 0, false
+	}
+	return name[:idx], partition, true
+}
+
+// EventPartition возвращает номер партиции для агрегата
+func EventPartition(aggregateID string, partitions int) int {
+	if partitions <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(aggregateID))
+	return int(h.Sum32() % uint32(partitions))
+}
+
+// partitionItem элемент очереди партиции: событие или отметка checkpoint
+type partitionItem struct {
+	event    StoredEvent
+	marker   bool
+	position int64
+}
+
+// runPartitioned обрабатывает события партиционированной проекции.
+// В режиме follow ожидает новые события до остановки, иначе завершается
+// после обработки всех событий, начиная с fromPosition.
+func (r *ProjectionRunner) runPartitioned(ctx context.Context, follow bool, fromPosition int64) error {
+	name := r.projection.Name()
+	checkpoints := make([]int64, r.partitions)
+	start := fromPosition
+
+	if follow {
+		// Проекция, переведенная в партиционированный режим, продолжает с общего checkpoint
+		base, err := r.checkpointStore.GetCheckpoint(ctx, name)
+		if err != nil {
+			base = 0
+		}
+		start = -1
+		for i := range checkpoints {
+			position, err := r.checkpointStore.GetCheckpoint(ctx, PartitionCheckpointName(name, i))
+			if err != nil || position < base {
+				position = base
+			}
+			checkpoints[i] = position
+			if start < 0 || position < start {
+				start = position
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.status.PartitionPositions = append([]int64(nil), checkpoints...)
+	if follow {
+		r.status.LastProcessedPosition = start
+	}
+	r.mu.Unlock()
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queues := make([]chan partitionItem, r.partitions)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan partitionItem, partitionQueueSize)
+		wg.Add(1)
+		go func(partition int) {
+			defer wg.Done()
+			r.runPartition(workerCtx, partition, checkpoints[partition], queues[partition])
+		}(i)
+	}
+
+	err := r.dispatchPartitions(ctx, follow, start, queues)
+	if err != nil || r.stopped() {
+		// Необработанные события будут прочитаны повторно после перезапуска
+		cancel()
+	}
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+
+	return err
+}
+
+// dispatchPartitions читает поток событий и распределяет их по очередям партиций
+func (r *ProjectionRunner) dispatchPartitions(ctx context.Context, follow bool, position int64, queues []chan partitionItem) error {
+	send := func(queue chan partitionItem, item partitionItem) bool {
+		select {
+		case queue <- item:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-r.stopChan:
+			return false
+		}
+	}
+	broadcast := func(markerPosition int64) bool {
+		for _, queue := range queues {
+			if !send(queue, partitionItem{marker: true, position: markerPosition}) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// В режиме follow события читаются live подпиской, иначе - однократным чтением потока
+	open := func() (<-chan StoredEvent, error) {
+		if follow {
+			return NewEventSubscriber(r.eventStore).SubscribeToAll(ctx, position)
+		}
+		return r.eventStore.GetAllEvents(ctx, position)
+	}
+
+	eventsChan, err := open()
+	if err != nil {
+		if !follow {
+			return fmt.Errorf("failed to get events: %w", err)
+		}
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	sinceMarker := 0
+	for {
+		var event StoredEvent
+		var ok bool
+		select {
+		case event, ok = <-eventsChan:
+		default:
+			// Новых событий пока нет - фиксируем checkpoints партиций перед ожиданием
+			if sinceMarker > 0 {
+				if !broadcast(position - 1) {
+					return ctx.Err()
+				}
+				sinceMarker = 0
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-r.stopChan:
+				return nil
+			case event, ok = <-eventsChan:
+			}
+		}
+
+		if !ok {
+			if sinceMarker > 0 && !broadcast(position-1) {
+				return ctx.Err()
+			}
+			sinceMarker = 0
+			if !follow {
+				return nil
+			}
+
+			// Подписка разорвана, переподписываемся с позиции следующего события
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-r.stopChan:
+				return nil
+			case <-time.After(subscriptionRetryDelay):
+			}
+			if newChan, err := open(); err == nil {
+				eventsChan = newChan
+			}
+			continue
+		}
+
+		if !send(queues[EventPartition(event.AggregateID, len(queues))], partitionItem{event: event}) {
+			return ctx.Err()
+		}
+		position = event.Position + 1
+		sinceMarker++
+		if sinceMarker >= partitionCheckpointInterval {
+			if !broadcast(event.Position) {
+				return ctx.Err()
+			}
+			sinceMarker = 0
+		}
+	}
+}
+
+// runPartition обрабатывает очередь событий одной партиции
+func (r *ProjectionRunner) runPartition(ctx context.Context, partition int, checkpoint int64, queue <-chan partitionItem) {
+	checkpointName := PartitionCheckpointName(r.projection.Name(), partition)
+
+	for item := range queue {
+		if ctx.Err() != nil {
+			continue
+		}
+
+		if item.marker {
+			if item.position <= checkpoint {
+				continue
+			}
+			if err := r.saveCheckpoint(ctx, checkpointName, item.position); err != nil {
+				continue
+			}
+			checkpoint = item.position
+			r.advancePartition(ctx, partition, checkpoint, false)
+			continue
+		}
+
+		event := item.event
+		if event.Position <= checkpoint {
+			// Событие уже обработано до перезапуска
+			continue
+		}
+
+		if err := r.applyEvent(ctx, checkpointName, event); err != nil {
+			continue
+		}
+		checkpoint = event.Position
+		r.advancePartition(ctx, partition, checkpoint, true)
+	}
+}
+
+// advancePartition обновляет статус после продвижения checkpoint партиции.
+// Общий checkpoint проекции сохраняется как минимальная позиция среди партиций.
+func (r *ProjectionRunner) advancePartition(ctx context.Context, partition int, position int64, processed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.PartitionPositions[partition] = position
+	r.status.LastProcessedAt = time.Now()
+	if processed {
+		r.status.EventsProcessed++
+	}
+
+	low := position
+	for _, p := range r.status.PartitionPositions {
+		if p < low {
+			low = p
+		}
+	}
+	if low > r.status.LastProcessedPosition {
+		if err := r.saveCheckpoint(ctx, r.projection.Name(), low); err == nil {
+			r.status.LastProcessedPosition = low
+		}
+	}
+}
+
+// saveCheckpoint сохраняет checkpoint; хранилища checkpoints (например, PostgresCheckpointStore
+// с одним соединением) не обязаны поддерживать конкурентную запись
+func (r *ProjectionRunner) saveCheckpoint(ctx context.Context, name string, position int64) error {
+	r.checkpointMu.Lock()
+	defer r.checkpointMu.Unlock()
+	return r.checkpointStore.SaveCheckpoint(ctx, name, position)
+}
+
+// stopped проверяет, был ли runner остановлен
+func (r *ProjectionRunner) stopped() bool {
+	select {
+	case <-r.stopChan:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,11 +2,13 @@ package eventsourcing
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/akriventsev/potter/framework/events"
+	"github.com/jackc/pgx/v5"
 )
 
 // TestProjection тестовая проекция
@@ -365,3 +367,788 @@ func TestProjectionManager_ResumeFromCheckpoint(t *testing.T) {
 	
 	_ = initialCount
 }
+
+// shadowTestProjection проекция с поддержкой теневого rebuild
+type shadowTestProjection struct {
+	*TestProjection
+	promoted *TestProjection
+}
+
+func (p *shadowTestProjection) Shadow() Projection {
+	return NewTestProjection(p.name + "_shadow")
+}
+
+func (p *shadowTestProjection) Promote(ctx context.Context, shadow Projection) error {
+	p.promoted = shadow.(*TestProjection)
+	return nil
+}
+
+func TestProjectionManager_RebuildWithOptions(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	checkpointStore := NewInMemoryCheckpointStore()
+	ctx := context.Background()
+
+	for _, id := range []string{"agg-1", "agg-2", "agg-3"} {
+		if err := eventStore.AppendEvents(ctx, id, 0, []events.Event{events.NewBaseEvent("test.event", id)}); err != nil {
+			t.Fatalf("Failed to append events: %v", err)
+		}
+	}
+
+	manager := NewProjectionManager(eventStore, checkpointStore)
+	projection := NewTestProjection("partial")
+	shadowable := &shadowTestProjection{TestProjection: NewTestProjection("shadowed")}
+	_ = manager.Register(projection)
+	_ = manager.Register(shadowable)
+
+	// Rebuild с позиции не сбрасывает проекцию и обрабатывает только хвост
+	if err := manager.RebuildWithOptions(ctx, "partial", RebuildOptions{FromPosition: 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if projection.WasReset() {
+		t.Error("Expected Reset() not to be called for partial rebuild")
+	}
+	if count := projection.GetProcessedCount(); count != 2 {
+		t.Errorf("Expected 2 events processed, got %d", count)
+	}
+
+	// Теневой rebuild не затрагивает основную проекцию до Promote
+	if err := manager.RebuildWithOptions(ctx, "shadowed", RebuildOptions{Shadow: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if shadowable.GetProcessedCount() != 0 {
+		t.Error("Expected main projection to be untouched by shadow rebuild")
+	}
+	if shadowable.promoted == nil || shadowable.promoted.GetProcessedCount() != 3 {
+		t.Error("Expected shadow projection with 3 events to be promoted")
+	}
+	if position, _ := checkpointStore.GetCheckpoint(ctx, "shadowed"); position != 3 {
+		t.Errorf("Expected checkpoint 3 after promote, got %d", position)
+	}
+
+	// Проекция без поддержки shadow возвращает ошибку
+	if err := manager.RebuildWithOptions(ctx, "partial", RebuildOptions{Shadow: true}); err == nil {
+		t.Error("Expected error for projection without shadow support")
+	}
+
+	statuses, err := manager.ListStatuses(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "partial" || statuses[0].LastProcessedPosition != 3 {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+}
+
+// partitionedTestProjection тестовая проекция с партиционированной обработкой
+type partitionedTestProjection struct {
+	*TestProjection
+	partitions int
+}
+
+func (p *partitionedTestProjection) Partitions() int {
+	return p.partitions
+}
+
+func TestProjectionManager_PartitionedRebuild(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	checkpointStore := NewInMemoryCheckpointStore()
+	ctx := context.Background()
+
+	aggregates := []string{"agg-1", "agg-2", "agg-3", "agg-4", "agg-5"}
+	for version := 0; version < 30; version++ {
+		for _, id := range aggregates {
+			if err := eventStore.AppendEvents(ctx, id, int64(version), []events.Event{events.NewBaseEvent("test.event", id)}); err != nil {
+				t.Fatalf("Failed to append events: %v", err)
+			}
+		}
+	}
+
+	manager := NewProjectionManager(eventStore, checkpointStore)
+	projection := &partitionedTestProjection{TestProjection: NewTestProjection("partitioned"), partitions: 3}
+	_ = manager.Register(projection)
+
+	if err := manager.Rebuild(ctx, "partitioned"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count := projection.GetProcessedCount(); count != 150 {
+		t.Fatalf("Expected 150 events processed, got %d", count)
+	}
+
+	// События одного агрегата обрабатываются по порядку
+	projection.mu.RLock()
+	lastVersion := make(map[string]int64)
+	for _, event := range projection.processedEvents {
+		if event.Version <= lastVersion[event.AggregateID] {
+			t.Errorf("Events of %s processed out of order", event.AggregateID)
+		}
+		lastVersion[event.AggregateID] = event.Version
+	}
+	projection.mu.RUnlock()
+
+	// Каждая партиция зафиксировала checkpoint, общий checkpoint - минимальная позиция
+	for i := 0; i < 3; i++ {
+		if position, _ := checkpointStore.GetCheckpoint(ctx, PartitionCheckpointName("partitioned", i)); position != 150 {
+			t.Errorf("Expected partition %d checkpoint 150, got %d", i, position)
+		}
+	}
+	if position, _ := checkpointStore.GetCheckpoint(ctx, "partitioned"); position != 150 {
+		t.Errorf("Expected projection checkpoint 150, got %d", position)
+	}
+
+	statuses, err := manager.ListStatuses(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Name != "partitioned" {
+		t.Errorf("Expected partition checkpoints to be folded into projection status, got %+v", statuses)
+	}
+
+	// После перезапуска уже обработанные события не обрабатываются повторно
+	if err := eventStore.AppendEvents(ctx, "agg-1", 30, []events.Event{events.NewBaseEvent("test.event", "agg-1")}); err != nil {
+		t.Fatalf("Failed to append events: %v", err)
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if count := projection.GetProcessedCount(); count != 151 {
+		t.Errorf("Expected 151 events processed after restart, got %d", count)
+	}
+	_ = manager.Stop(ctx)
+}
+
+func TestProjectionManager_TenantCheckpoints(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig()).WithTenantResolver(ContextTenantResolver(false))
+	checkpointStore := NewInMemoryCheckpointStore()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := eventStore.AppendEvents(WithTenant(ctx, "acme"), "agg-1", int64(i), []events.Event{events.NewBaseEvent("test.event", "agg-1")}); err != nil {
+			t.Fatalf("Failed to append events: %v", err)
+		}
+	}
+
+	acme := NewProjectionManager(eventStore, checkpointStore).WithTenant("acme")
+	globex := NewProjectionManager(eventStore, checkpointStore).WithTenant("globex")
+	_ = acme.Register(NewTestProjection("orders"))
+	_ = globex.Register(NewTestProjection("orders"))
+
+	if err := acme.Rebuild(ctx, "orders"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := globex.ResetCheckpoint(ctx, "orders", 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if position, _ := checkpointStore.GetCheckpoint(ctx, TenantCheckpointName("acme", "orders")); position != 3 {
+		t.Errorf("Expected acme checkpoint 3, got %d", position)
+	}
+	if position, _ := checkpointStore.GetCheckpoint(ctx, TenantCheckpointName("globex", "orders")); position != 1 {
+		t.Errorf("Expected globex checkpoint 1, got %d", position)
+	}
+	if position, _ := checkpointStore.GetCheckpoint(ctx, "orders"); position != 0 {
+		t.Errorf("Expected no checkpoint for default tenant, got %d", position)
+	}
+
+	statuses, err := globex.ListStatuses(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Name != "orders" || statuses[0].LastProcessedPosition != 1 {
+		t.Errorf("Expected only globex checkpoint in statuses, got %+v", statuses)
+	}
+
+	// Повторный WithTenant переключает тенант, а не вкладывает префиксы
+	globex.WithTenant("initech")
+	if err := globex.ResetCheckpoint(ctx, "orders", 2); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if position, _ := checkpointStore.GetCheckpoint(ctx, TenantCheckpointName("initech", "orders")); position != 2 {
+		t.Errorf("Expected initech checkpoint 2, got %d", position)
+	}
+}
+
+// txCheckpointStore транзакционное хранилище checkpoints в памяти: checkpoint и записи
+// обработчика фиксируются вместе, только если apply завершился без ошибки
+type txCheckpointStore struct {
+	*InMemoryCheckpointStore
+	applied []int64
+}
+
+func (s *txCheckpointStore) ApplyWithCheckpoint(ctx context.Context, projectionName string, position int64, apply func(tx pgx.Tx) error) (bool, error) {
+	current, _ := s.GetCheckpoint(ctx, projectionName)
+	if current >= position {
+		return false, nil
+	}
+	if err := apply(nil); err != nil {
+		return false, err
+	}
+	s.applied = append(s.applied, position)
+	return true, s.SaveCheckpoint(ctx, projectionName, position)
+}
+
+// txTestProjection транзакционная проекция, падающая на событии failAt
+type txTestProjection struct {
+	*TestProjection
+	failAt int64
+}
+
+func (p *txTestProjection) HandleEventTx(ctx context.Context, tx pgx.Tx, event StoredEvent) error {
+	if event.Position == p.failAt {
+		return errors.New("read model write failed")
+	}
+	return p.HandleEvent(ctx, event)
+}
+
+func TestProjectionRunner_TransactionalCheckpoints(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	checkpointStore := &txCheckpointStore{InMemoryCheckpointStore: NewInMemoryCheckpointStore()}
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		if err := eventStore.AppendEvents(ctx, "agg-1", int64(i), []events.Event{events.NewBaseEvent("test.event", "agg-1")}); err != nil {
+			t.Fatalf("Failed to append events: %v", err)
+		}
+	}
+
+	// Событие 2 уже применено в предыдущей транзакции
+	if err := checkpointStore.SaveCheckpoint(ctx, TenantCheckpointName("acme", "orders"), 2); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	projection := &txTestProjection{TestProjection: NewTestProjection("orders"), failAt: 4}
+	tenantStore := NewTenantCheckpointStore(checkpointStore, "acme")
+	runner := NewProjectionRunner(projection, eventStore, tenantStore)
+	if err := runner.RebuildFrom(ctx, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(checkpointStore.applied) != 1 || checkpointStore.applied[0] != 3 {
+		t.Errorf("Expected only event 3 applied in transaction, got %v", checkpointStore.applied)
+	}
+	if count := projection.GetProcessedCount(); count != 1 {
+		t.Errorf("Expected 1 event processed, got %d", count)
+	}
+	// Ошибка обработчика откатывает транзакцию: checkpoint не продвигается за событие 4
+	if position, _ := tenantStore.GetCheckpoint(ctx, "orders"); position != 3 {
+		t.Errorf("Expected checkpoint 3, got %d", position)
+	}
+	if status := runner.GetStatus(); status.ErrorCount != 1 {
+		t.Errorf("Expected 1 error, got %d", status.ErrorCount)
+	}
+
+	// Хранилище без транзакций - обычная обработка через HandleEvent
+	plain := NewProjectionRunner(projection, eventStore, NewInMemoryCheckpointStore())
+	if plain.txCheckpoints != nil {
+		t.Error("Expected non-transactional runner for InMemoryCheckpointStore")
+	}
+}
+
+func TestContextTenantResolver(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := resolveTenant(ctx, ContextTenantResolver(true)); !errors.Is(err, ErrTenantNotResolved) {
+		t.Errorf("Expected ErrTenantNotResolved, got %v", err)
+	}
+	if tenantID, err := resolveTenant(ctx, ContextTenantResolver(false)); err != nil || tenantID != "" {
+		t.Errorf("Expected default tenant, got %q (%v)", tenantID, err)
+	}
+	if tenantID, err := resolveTenant(WithTenant(ctx, "acme"), ContextTenantResolver(true)); err != nil || tenantID != "acme" {
+		t.Errorf("Expected tenant acme, got %q (%v)", tenantID, err)
+	}
+	if tenantID, err := resolveTenant(ctx, nil); err != nil || tenantID != "" {
+		t.Errorf("Expected default tenant without resolver, got %q (%v)", tenantID, err)
+	}
+}
+
+func TestTenantEventStore_Isolation(t *testing.T) {
+	ctx := context.Background()
+
+	// Хранилище без TenantResolver игнорирует тенант: ограничение тенантом отклоняется
+	shared := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	if _, err := NewTenantEventStore(shared, "acme").GetEvents(ctx, "agg-1", 0); !errors.Is(err, ErrTenantIsolationUnsupported) {
+		t.Errorf("Expected ErrTenantIsolationUnsupported, got %v", err)
+	}
+	manager := NewProjectionManager(shared, NewInMemoryCheckpointStore()).WithTenant("acme")
+	if err := manager.Start(ctx); !errors.Is(err, ErrTenantIsolationUnsupported) {
+		t.Errorf("Expected ErrTenantIsolationUnsupported on start, got %v", err)
+	}
+	if SupportsTenantIsolation(NewAliasingEventStore(shared, events.NewEventTypeAliases())) {
+		t.Error("Expected wrapper to report missing tenant isolation of the wrapped store")
+	}
+
+	store := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig()).WithTenantResolver(ContextTenantResolver(true))
+	acme := NewTenantEventStore(NewAliasingEventStore(store, events.NewEventTypeAliases()), "acme")
+	globex := NewTenantEventStore(store, "globex")
+	if err := acme.AppendEvents(ctx, "agg-1", 0, generateEvents(2, "agg-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := globex.AppendEvents(ctx, "agg-1", 0, generateEvents(3, "agg-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Потоки с одинаковым ID у разных тенантов независимы
+	if stored, err := acme.GetEvents(ctx, "agg-1", 0); err != nil || len(stored) != 2 || stored[0].TenantID != "acme" {
+		t.Errorf("Expected 2 acme events, got %d (%v)", len(stored), err)
+	}
+	if byType, _ := globex.GetEventsByType(ctx, "test.updated", time.Time{}); len(byType) != 3 {
+		t.Errorf("Expected 3 globex events by type, got %d", len(byType))
+	}
+	eventCh, err := globex.GetAllEvents(ctx, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	count := 0
+	for event := range eventCh {
+		if event.TenantID != "globex" {
+			t.Errorf("Expected only globex events, got tenant %q", event.TenantID)
+		}
+		count++
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 globex events, got %d", count)
+	}
+	if _, err := store.GetEvents(ctx, "agg-1", 0); !errors.Is(err, ErrTenantNotResolved) {
+		t.Errorf("Expected ErrTenantNotResolved without tenant, got %v", err)
+	}
+}
+
+func TestProjectionManager_HandlerPanic(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	checkpointStore := NewInMemoryCheckpointStore()
+
+	ctx := context.Background()
+	if err := eventStore.AppendEvents(ctx, "agg-1", 0, []events.Event{events.NewBaseEvent("test.panic", "agg-1")}); err != nil {
+		t.Fatalf("Failed to append events: %v", err)
+	}
+	if err := eventStore.AppendEvents(ctx, "agg-2", 0, []events.Event{events.NewBaseEvent("test.event", "agg-2")}); err != nil {
+		t.Fatalf("Failed to append events: %v", err)
+	}
+
+	processed := make(chan struct{}, 1)
+	projection := NewProjectionBuilder("panic-projection").
+		OnEvent("test.panic", func(ctx context.Context, event StoredEvent) error {
+			panic("projection bug")
+		}).
+		OnEvent("test.event", func(ctx context.Context, event StoredEvent) error {
+			processed <- struct{}{}
+			return nil
+		}).
+		Build()
+
+	manager := NewProjectionManager(eventStore, checkpointStore)
+	if err := manager.Register(projection); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Событие после паники обрабатывается: runner продолжает работу
+	select {
+	case <-processed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected event after panic to be processed")
+	}
+
+	status, err := manager.GetStatus("panic-projection")
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	if status.ErrorCount != 1 || status.LastErrorStackTrace == "" {
+		t.Errorf("Expected recovered panic in status, got %+v", status)
+	}
+}
+
+func TestProjectionManager_Backfill(t *testing.T) {
+	ctx := context.Background()
+	checkpoints := NewInMemoryCheckpointStore()
+	manager := NewProjectionManager(NewInMemoryEventStore(DefaultInMemoryEventStoreConfig()), checkpoints)
+
+	readModel := make(map[string]Provenance)
+	projection := NewProjectionBuilder("customers").
+		OnBackfill(func(ctx context.Context, record BackfillRecord) error {
+			readModel[record.Key] = record.Provenance
+			return nil
+		}).
+		Build()
+	if err := manager.Register(projection); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+	if err := manager.Register(NewTestProjection("plain")); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+
+	source := make(chan BackfillRecord, 2)
+	source <- BackfillRecord{Key: "customer-1", Data: map[string]interface{}{"name": "Alice"}}
+	source <- BackfillRecord{Key: "customer-2", Provenance: Provenance{Source: "billing", SourceID: "B-2"}}
+	close(source)
+
+	progress, err := manager.Backfill(ctx, "customers", source, BackfillOptions{
+		BackfillID:         "backfill-1",
+		Source:             "legacy-crm",
+		CheckpointPosition: 42,
+	})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if progress.RecordsImported != 2 {
+		t.Errorf("Expected 2 records imported, got %d", progress.RecordsImported)
+	}
+
+	first := readModel["customer-1"]
+	if first.Source != "legacy-crm" || first.SourceID != "customer-1" || first.BackfillID != "backfill-1" || first.ImportedAt.IsZero() {
+		t.Errorf("Unexpected provenance of customer-1: %+v", first)
+	}
+	if second := readModel["customer-2"]; second.Source != "billing" || second.SourceID != "B-2" {
+		t.Errorf("Expected record provenance to be kept, got %+v", second)
+	}
+
+	position, err := checkpoints.GetCheckpoint(ctx, "customers")
+	if err != nil || position != 42 {
+		t.Errorf("Expected checkpoint 42 after backfill, got %d (%v)", position, err)
+	}
+
+	empty := make(chan BackfillRecord)
+	close(empty)
+	if _, err := manager.Backfill(ctx, "plain", empty, BackfillOptions{}); !errors.Is(err, ErrBackfillNotSupported) {
+		t.Errorf("Expected ErrBackfillNotSupported, got %v", err)
+	}
+}
+
+func TestErasureCoordinator_Erase(t *testing.T) {
+	ctx := context.Background()
+	manager := NewProjectionManager(NewInMemoryEventStore(DefaultInMemoryEventStoreConfig()), NewInMemoryCheckpointStore())
+
+	customers := map[string]string{"customer-1": "Alice", "customer-2": "Bob"}
+	projection := NewProjectionBuilder("customers").
+		OnErase(func(ctx context.Context, subjectID string) (int64, error) {
+			if _, exists := customers[subjectID]; !exists {
+				return 0, nil
+			}
+			delete(customers, subjectID)
+			return 1, nil
+		}).
+		Build()
+	if err := manager.Register(projection); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+	// Builder-проекция без OnErase не попадает в результаты удаления
+	if err := manager.Register(NewProjectionBuilder("totals").Build()); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+
+	var forgotten []string
+	audit := NewInMemoryErasureAuditStore()
+	coordinator := NewErasureCoordinator(audit).
+		WithProjectionManager(manager).
+		WithForgetter(forgetterFunc(func(ctx context.Context, subjectID string) error {
+			forgotten = append(forgotten, subjectID)
+			return nil
+		}))
+	if err := coordinator.RegisterReadModel("search", ReadModelEraserFunc(func(ctx context.Context, subjectID string) (int64, error) {
+		return 0, errors.New("index unavailable")
+	})); err != nil {
+		t.Fatalf("Failed to register read model: %v", err)
+	}
+
+	record, err := coordinator.Erase(ctx, ErasureRequest{SubjectID: "customer-1", Reason: "ticket-7"})
+	if err == nil {
+		t.Fatal("Expected error from failing read model")
+	}
+	if record.Status != ErasureStatusFailed || !record.EventStoreForgotten {
+		t.Errorf("Unexpected erasure record: %+v", record)
+	}
+	if _, exists := customers["customer-1"]; exists || len(customers) != 1 {
+		t.Errorf("Expected only customer-1 to be erased, got %v", customers)
+	}
+	if len(forgotten) != 1 || forgotten[0] != "customer-1" {
+		t.Errorf("Expected event store to forget customer-1, got %v", forgotten)
+	}
+
+	if len(record.Results) != 2 {
+		t.Fatalf("Expected 2 erasure results, got %+v", record.Results)
+	}
+	if result := record.Results[0]; result.ReadModel != "customers" || result.RowsAffected != 1 || result.Error != "" {
+		t.Errorf("Unexpected customers result: %+v", result)
+	}
+	if result := record.Results[1]; result.ReadModel != "search" || result.Error == "" {
+		t.Errorf("Expected search read model to fail, got %+v", result)
+	}
+
+	records, err := audit.ListErasures(ctx, "customer-1")
+	if err != nil || len(records) != 1 || records[0].ID != record.ID || records[0].Reason != "ticket-7" {
+		t.Errorf("Expected erasure to be recorded in audit trail, got %+v (%v)", records, err)
+	}
+}
+
+// forgetterFunc функциональный SubjectForgetter для тестов
+type forgetterFunc func(ctx context.Context, subjectID string) error
+
+func (f forgetterFunc) Forget(ctx context.Context, subjectID string) error {
+	return f(ctx, subjectID)
+}
+
+// failingProjection проекция, падающая на событиях указанного типа
+type failingProjection struct {
+	*TestProjection
+	failOn string
+}
+
+func (p *failingProjection) HandleEvent(ctx context.Context, event StoredEvent) error {
+	if event.EventType == p.failOn {
+		return errors.New("boom")
+	}
+	return p.TestProjection.HandleEvent(ctx, event)
+}
+
+func TestReplayService(t *testing.T) {
+	ctx := context.Background()
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	for _, aggregateID := range []string{"order-1", "order-2"} {
+		if err := eventStore.AppendEvents(ctx, aggregateID, 0, []events.Event{
+			events.NewBaseEvent("order.created", aggregateID),
+			events.NewBaseEvent("order.paid", aggregateID),
+		}); err != nil {
+			t.Fatalf("Failed to append events: %v", err)
+		}
+	}
+	checkpoints := NewInMemoryCheckpointStore()
+	service := NewReplayService(eventStore).WithCheckpointStore(checkpoints)
+
+	byAggregate := NewTestProjection("by-aggregate")
+	if _, err := service.ReplayAggregate(ctx, "order-2", byAggregate, DefaultReplayServiceOptions()); err != nil {
+		t.Fatalf("ReplayAggregate failed: %v", err)
+	}
+	if byAggregate.GetProcessedCount() != 2 {
+		t.Errorf("Expected 2 events of order-2, got %d", byAggregate.GetProcessedCount())
+	}
+
+	byType := NewTestProjection("by-type")
+	if _, err := service.ReplayByType(ctx, byType, DefaultReplayServiceOptions(), "order.paid"); err != nil {
+		t.Fatalf("ReplayByType failed: %v", err)
+	}
+	if byType.GetProcessedCount() != 2 {
+		t.Errorf("Expected 2 order.paid events, got %d", byType.GetProcessedCount())
+	}
+
+	// Первый replay проекции прерывается ошибкой на order.paid, повторный продолжает с checkpoint
+	projection := &failingProjection{TestProjection: NewTestProjection("orders"), failOn: "order.paid"}
+	if _, err := service.ReplayProjection(ctx, projection, ReplayFilter{}, DefaultReplayServiceOptions()); err == nil {
+		t.Fatal("Expected replay to stop on handler error")
+	}
+	if projection.GetProcessedCount() != 1 {
+		t.Fatalf("Expected 1 event before failure, got %d", projection.GetProcessedCount())
+	}
+	projection.failOn = ""
+	progress, err := service.ReplayProjection(ctx, projection, ReplayFilter{}, DefaultReplayServiceOptions())
+	if err != nil {
+		t.Fatalf("ReplayProjection failed: %v", err)
+	}
+	if projection.GetProcessedCount() != 4 || progress.SkippedEvents != 1 {
+		t.Errorf("Expected each event applied once (4) with 1 skipped, got %d applied, %d skipped",
+			projection.GetProcessedCount(), progress.SkippedEvents)
+	}
+	if position, _ := checkpoints.GetCheckpoint(ctx, ReplayCheckpointName("orders")); position != progress.CurrentPosition {
+		t.Errorf("Expected replay checkpoint %d, got %d", progress.CurrentPosition, position)
+	}
+	if position, _ := checkpoints.GetCheckpoint(ctx, "orders"); position != 0 {
+		t.Errorf("Expected projection checkpoint to be untouched, got %d", position)
+	}
+
+	// Приостановленный replay не обрабатывает события до Resume
+	service.Pause()
+	paused := NewTestProjection("paused")
+	done := make(chan error, 1)
+	go func() {
+		_, err := service.ReplayAll(ctx, paused, ReplayServiceOptions{EventsPerSecond: 1000})
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if paused.GetProcessedCount() != 0 {
+		t.Errorf("Expected no events while paused, got %d", paused.GetProcessedCount())
+	}
+	service.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ReplayAll failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Replay did not finish after resume")
+	}
+	if paused.GetProcessedCount() != 4 {
+		t.Errorf("Expected 4 events after resume, got %d", paused.GetProcessedCount())
+	}
+}
+
+func TestProjectionManager_PriorityGroups(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	checkpointStore := NewInMemoryCheckpointStore()
+
+	ctx := context.Background()
+	for _, id := range []string{"agg-1", "agg-2", "agg-3"} {
+		if err := eventStore.AppendEvents(ctx, id, 0, []events.Event{events.NewBaseEvent("test.event", id)}); err != nil {
+			t.Fatalf("Failed to append events: %v", err)
+		}
+	}
+
+	// Critical проекция блокируется на втором событии
+	release := make(chan struct{})
+	critical := NewProjectionBuilder("read-model").
+		OnEvent("test.event", func(ctx context.Context, event StoredEvent) error {
+			if event.AggregateID == "agg-2" {
+				<-release
+			}
+			return nil
+		}).
+		Build()
+	analytics := NewTestProjection("analytics")
+
+	manager := NewProjectionManager(eventStore, checkpointStore).
+		WithGroup(ProjectionPriorityBestEffort, ProjectionGroupConfig{Workers: 1, LagSLO: time.Millisecond})
+	if err := manager.Register(critical); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+	if err := manager.RegisterWithPriority(analytics, ProjectionPriorityBestEffort); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+	if manager.Priority("analytics") != ProjectionPriorityBestEffort || manager.Priority("read-model") != ProjectionPriorityCritical {
+		t.Fatal("Expected projection priorities to be registered")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := manager.Start(runCtx); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+
+	// Runner повторно читает событие checkpoint, поэтому учитываются позиции, а не число вызовов
+	processedUpTo := func() int64 {
+		analytics.mu.RLock()
+		defer analytics.mu.RUnlock()
+		var position int64
+		for _, event := range analytics.processedEvents {
+			if event.Position > position {
+				position = event.Position
+			}
+		}
+		return position
+	}
+
+	// Best-effort проекция не обгоняет critical
+	time.Sleep(200 * time.Millisecond)
+	if position := processedUpTo(); position != 1 {
+		t.Fatalf("Expected best-effort projection to stop behind critical one, processed up to %d", position)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	statuses, err := manager.GroupStatuses(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get group statuses: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Priority != ProjectionPriorityCritical || statuses[1].Priority != ProjectionPriorityBestEffort {
+		t.Fatalf("Expected critical and best-effort groups, got %+v", statuses)
+	}
+	if !statuses[1].SLOBreached || statuses[1].Workers != 1 || statuses[1].Projections[0].Lag <= 0 {
+		t.Errorf("Expected lagging best-effort group to breach SLO, got %+v", statuses[1])
+	}
+	if statuses[0].SLOBreached {
+		t.Errorf("Expected critical group without SLO not to breach it, got %+v", statuses[0])
+	}
+
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for processedUpTo() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if position := processedUpTo(); position != 3 {
+		t.Errorf("Expected best-effort projection to catch up, processed up to %d", position)
+	}
+}
+
+func TestCausationGraph(t *testing.T) {
+	ctx := context.Background()
+	index := NewInMemoryCausationIndex()
+	projection := NewCausationProjection(index)
+	base := time.Now()
+
+	stored := func(id, eventType, aggregateType string, position int64, metadata map[string]interface{}) StoredEvent {
+		return StoredEvent{
+			ID:            id,
+			AggregateID:   "agg-" + id,
+			AggregateType: aggregateType,
+			EventType:     eventType,
+			Metadata:      metadata,
+			Position:      position,
+			OccurredAt:    base.Add(time.Duration(position) * time.Millisecond),
+		}
+	}
+	incoming := []StoredEvent{
+		stored("e1", "order.created", "order", 1, map[string]interface{}{"correlation_id": "corr-1", "causation_id": "cmd-create"}),
+		stored("e2", "saga.started", "saga", 2, map[string]interface{}{"correlation_id": "corr-1", "causation_id": "e1"}),
+		stored("e3", "payment.charged", "payment", 3, map[string]interface{}{"correlation_id": "corr-1", "causation_id": "cmd-charge", "saga_id": "saga-1"}),
+		stored("e4", "payment.receipt_sent", "payment", 4, map[string]interface{}{"correlation_id": "corr-1", "causation_id": "e3"}),
+		stored("e5", "audit.recorded", "audit", 5, map[string]interface{}{"causation_id": "e4"}),
+		stored("e6", "other.created", "other", 6, map[string]interface{}{"correlation_id": "corr-2"}),
+	}
+	for _, event := range incoming {
+		if err := projection.HandleEvent(ctx, event); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	graph := NewCausationGraph(index)
+	tree, err := graph.ByCorrelationID(ctx, "corr-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tree.EventCount != 4 || len(tree.Roots) != 2 {
+		t.Fatalf("Expected 4 events in 2 roots, got %d events in %d roots", tree.EventCount, len(tree.Roots))
+	}
+
+	command := tree.Roots[0]
+	if command.Kind != CausationNodeCommand || command.ID != "cmd-create" || len(command.Children) != 1 {
+		t.Fatalf("Expected command cmd-create as first root, got %+v", command)
+	}
+	if created := command.Children[0]; created.ID != "e1" || len(created.Children) != 1 || created.Children[0].ID != "e2" {
+		t.Errorf("Expected e1 caused by cmd-create to cause e2, got %+v", created)
+	}
+
+	saga := tree.Roots[1]
+	if saga.Kind != CausationNodeSaga || saga.ID != "saga-1" || len(saga.Children) != 1 {
+		t.Fatalf("Expected saga-1 as second root, got %+v", saga)
+	}
+	if charge := saga.Children[0]; charge.Kind != CausationNodeCommand || charge.ID != "cmd-charge" {
+		t.Errorf("Expected saga command cmd-charge, got %+v", charge)
+	}
+	if receipt := tree.Find("e4"); receipt == nil || receipt.Record.EventType != "payment.receipt_sent" {
+		t.Errorf("Expected e4 in tree, got %+v", receipt)
+	}
+
+	// Событие без correlation ID: само событие и вызванные им
+	if err := projection.HandleEvent(ctx, stored("e7", "audit.archived", "audit", 7, map[string]interface{}{"causation_id": "e5"})); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lineage, err := graph.ByEventID(ctx, "e5")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lineage.EventCount != 2 || lineage.Find("e7") == nil {
+		t.Errorf("Expected e5 and e7 in lineage, got %d events", lineage.EventCount)
+	}
+
+	if _, err := graph.ByEventID(ctx, "missing"); err == nil {
+		t.Error("Expected error for event that is not indexed")
+	}
+	if err := projection.Reset(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tree, _ := graph.ByCorrelationID(ctx, "corr-1"); tree.EventCount != 0 {
+		t.Errorf("Expected empty index after reset, got %d events", tree.EventCount)
+	}
+}
@@ -0,0 +1,96 @@
+// Package eventsourcing предоставляет полную поддержку Event Sourcing паттерна.
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrCheckpointTransactionsUnsupported возвращается, если хранилище checkpoints не поддерживает транзакции
+var ErrCheckpointTransactionsUnsupported = errors.New("checkpoint store does not support transactions")
+
+// TransactionalProjection проекция с exactly-once обновлением read model. Если хранилище
+// checkpoints реализует TransactionalCheckpointStore, ProjectionRunner вызывает HandleEventTx
+// вместо HandleEvent и сохраняет checkpoint в той же транзакции: сбой между записью read model
+// и сохранением checkpoint откатывает обе записи, и событие не применяется повторно.
+// Read model должен храниться в той же базе данных, что и checkpoints.
+type TransactionalProjection interface {
+	Projection
+	// HandleEventTx применяет событие к read model в транзакции tx
+	HandleEventTx(ctx context.Context, tx pgx.Tx, event StoredEvent) error
+}
+
+// TransactionalCheckpointStore хранилище checkpoints, сохраняющее checkpoint в транзакции
+// обработчика проекции (PostgresCheckpointStore)
+type TransactionalCheckpointStore interface {
+	CheckpointStore
+	// ApplyWithCheckpoint выполняет apply и сохраняет checkpoint projectionName = position
+	// в одной транзакции. Если сохраненный checkpoint не меньше position, apply не вызывается
+	// и возвращается false.
+	ApplyWithCheckpoint(ctx context.Context, projectionName string, position int64, apply func(tx pgx.Tx) error) (bool, error)
+}
+
+// ApplyWithCheckpoint выполняет apply и сохраняет checkpoint проекции тенанта в одной транзакции
+func (s *TenantCheckpointStore) ApplyWithCheckpoint(ctx context.Context, projectionName string, position int64, apply func(tx pgx.Tx) error) (bool, error) {
+	store, ok := s.store.(TransactionalCheckpointStore)
+	if !ok {
+		return false, ErrCheckpointTransactionsUnsupported
+	}
+	return store.ApplyWithCheckpoint(ctx, TenantCheckpointName(s.tenantID, projectionName), position, apply)
+}
+
+// transactionalCheckpoints возвращает хранилище транзакционных checkpoints проекции
+// или nil, если проекция или хранилище не поддерживают транзакции
+func transactionalCheckpoints(projection Projection, store CheckpointStore) TransactionalCheckpointStore {
+	if _, ok := projection.(TransactionalProjection); !ok {
+		return nil
+	}
+	// TenantCheckpointStore поддерживает транзакции, если их поддерживает общее хранилище
+	underlying := store
+	for {
+		tenant, ok := underlying.(*TenantCheckpointStore)
+		if !ok {
+			break
+		}
+		underlying = tenant.store
+	}
+	if _, ok := underlying.(TransactionalCheckpointStore); !ok {
+		return nil
+	}
+	transactional, _ := store.(TransactionalCheckpointStore)
+	return transactional
+}
+
+// applyEvent обрабатывает событие и сохраняет checkpoint name. Для TransactionalProjection
+// с транзакционным хранилищем checkpoints обработка и checkpoint выполняются в одной транзакции.
+func (r *ProjectionRunner) applyEvent(ctx context.Context, name string, event StoredEvent) error {
+	if r.txCheckpoints == nil {
+		if err := r.handleEvent(ctx, event); err != nil {
+			return err
+		}
+		return r.saveCheckpoint(ctx, name, event.Position)
+	}
+
+	release, err := r.admit(ctx, event.Position)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	projection := r.projection.(TransactionalProjection)
+	// false - событие уже применено (checkpoint в базе впереди), повторно не применяется
+	_, err = r.txCheckpoints.ApplyWithCheckpoint(ctx, name, event.Position, func(tx pgx.Tx) error {
+		return core.SafeCall(func() error {
+			return projection.HandleEventTx(ctx, tx, event)
+		})
+	})
+	if err != nil {
+		r.recordError(err)
+		return fmt.Errorf("failed to apply event %d: %w", event.Position, err)
+	}
+	return nil
+}
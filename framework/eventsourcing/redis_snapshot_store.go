@@ -0,0 +1,183 @@
+package eventsourcing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisSnapshotStoreConfig конфигурация Redis Snapshot Store
+type RedisSnapshotStoreConfig struct {
+	Addr      string
+	Password  string
+	DB        int
+	PoolSize  int
+	KeyPrefix string
+	// TTL время жизни снапшота в Redis (0 - без ограничения).
+	// При чтении TTL продлевается, поэтому в Redis остаются только часто загружаемые агрегаты.
+	TTL time.Duration
+}
+
+// Validate проверяет корректность конфигурации
+func (c RedisSnapshotStoreConfig) Validate() error {
+	if c.Addr == "" {
+		return fmt.Errorf("addr cannot be empty")
+	}
+	if c.TTL < 0 {
+		return fmt.Errorf("ttl cannot be negative")
+	}
+	return nil
+}
+
+// DefaultRedisSnapshotStoreConfig возвращает конфигурацию по умолчанию
+func DefaultRedisSnapshotStoreConfig() RedisSnapshotStoreConfig {
+	return RedisSnapshotStoreConfig{
+		Addr:      "localhost:6379",
+		PoolSize:  10,
+		KeyPrefix: "potter:snapshot:",
+		TTL:       time.Hour,
+	}
+}
+
+// redisSaveSnapshotScript сохраняет снапшот, только если его версия не старше сохраненной
+var redisSaveSnapshotScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'version')
+if current and tonumber(current) > tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'version', ARGV[1], 'data', ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// redisDeleteSnapshotScript удаляет снапшот, если его версия меньше указанной
+var redisDeleteSnapshotScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'version')
+if current and tonumber(current) < tonumber(ARGV[1]) then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisSnapshotStore реализация SnapshotStore для Redis.
+// Предназначен для использования как hot tier в TieredSnapshotStore:
+// хранит только последний снапшот агрегата и не является источником истины.
+type RedisSnapshotStore struct {
+	config RedisSnapshotStoreConfig
+	client *redis.Client
+	signer SnapshotSigner
+}
+
+// NewRedisSnapshotStore создает новый Redis Snapshot Store
+func NewRedisSnapshotStore(config RedisSnapshotStoreConfig) (*RedisSnapshotStore, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid redis config: %w", err)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+		PoolSize: config.PoolSize,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &RedisSnapshotStore{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// WithSigner включает подпись снапшотов и проверку подписи при загрузке
+func (s *RedisSnapshotStore) WithSigner(signer SnapshotSigner) *RedisSnapshotStore {
+	s.signer = signer
+	return s
+}
+
+// key возвращает ключ Redis для агрегата
+func (s *RedisSnapshotStore) key(aggregateID string) string {
+	return s.config.KeyPrefix + aggregateID
+}
+
+// SaveSnapshot сохраняет снапшот (более старая версия не перезаписывает более новую)
+func (s *RedisSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	if err := SealSnapshot(&snapshot, s.signer); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	err = redisSaveSnapshotScript.Run(ctx, s.client,
+		[]string{s.key(snapshot.AggregateID)},
+		snapshot.Version, data, s.config.TTL.Milliseconds(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshot возвращает снапшот агрегата и продлевает его TTL
+func (s *RedisSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
+	key := s.key(aggregateID)
+
+	data, err := s.client.HGet(ctx, key, "data").Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	if err := VerifySnapshot(&snapshot, s.signer); err != nil {
+		return nil, err
+	}
+
+	if s.config.TTL > 0 {
+		_ = s.client.PExpire(ctx, key, s.config.TTL).Err()
+	}
+
+	return &snapshot, nil
+}
+
+// DeleteSnapshots удаляет снапшот, если его версия меньше beforeVersion
+func (s *RedisSnapshotStore) DeleteSnapshots(ctx context.Context, aggregateID string, beforeVersion int64) error {
+	err := redisDeleteSnapshotScript.Run(ctx, s.client, []string{s.key(aggregateID)}, beforeVersion).Err()
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshots: %w", err)
+	}
+	return nil
+}
+
+// Evict удаляет снапшот агрегата из Redis независимо от версии
+func (s *RedisSnapshotStore) Evict(ctx context.Context, aggregateID string) error {
+	if err := s.client.Del(ctx, s.key(aggregateID)).Err(); err != nil {
+		return fmt.Errorf("failed to evict snapshot: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с Redis
+func (s *RedisSnapshotStore) Close() error {
+	return s.client.Close()
+}
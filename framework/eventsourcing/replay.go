@@ -19,6 +19,10 @@ type ReplayProgress struct {
 	CurrentPosition int64
 	StartTime       time.Time
 	ElapsedTime     time.Duration
+	// SkippedEvents события, пропущенные как уже обработанные (ReplayService с checkpoint)
+	SkippedEvents int64
+	// FailedEvents события, обработка которых завершилась ошибкой (при StopOnError = false)
+	FailedEvents int64
 }
 
 // ReplayOptions опции для replay операций
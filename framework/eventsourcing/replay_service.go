@@ -0,0 +1,320 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+)
+
+// ReplayHandlerFunc функция-адаптер ReplayHandler
+type ReplayHandlerFunc func(ctx context.Context, event StoredEvent) error
+
+// HandleEvent вызывает f(ctx, event)
+func (f ReplayHandlerFunc) HandleEvent(ctx context.Context, event StoredEvent) error {
+	return f(ctx, event)
+}
+
+// ReplayFilter отбор событий для replay. Пустые поля не ограничивают выборку.
+type ReplayFilter struct {
+	// AggregateID replay только событий одного агрегата
+	AggregateID string
+	// EventTypes replay только событий указанных типов
+	EventTypes []string
+	// Since replay событий, возникших не раньше указанного времени
+	Since time.Time
+	// FromPosition replay событий с глобальной позиции (включительно)
+	FromPosition int64
+}
+
+func (f ReplayFilter) matches(event StoredEvent) bool {
+	if f.AggregateID != "" && event.AggregateID != f.AggregateID {
+		return false
+	}
+	if !f.Since.IsZero() && event.OccurredAt.Before(f.Since) {
+		return false
+	}
+	if f.FromPosition > 0 && event.Position < f.FromPosition {
+		return false
+	}
+	if len(f.EventTypes) == 0 {
+		return true
+	}
+	for _, eventType := range f.EventTypes {
+		if event.EventType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplayServiceOptions опции запуска ReplayService
+type ReplayServiceOptions struct {
+	// EventsPerSecond ограничение скорости replay (0 - без ограничения)
+	EventsPerSecond float64
+	// ProgressInterval вызов OnProgress и сохранение checkpoint каждые N событий (по умолчанию 1000)
+	ProgressInterval int
+	// OnProgress вызывается каждые ProgressInterval событий и по завершении replay
+	OnProgress func(progress ReplayProgress)
+	// StopOnError прерывать replay при ошибке обработчика; иначе ошибка учитывается в FailedEvents
+	StopOnError bool
+	// CheckpointName имя checkpoint для идемпотентного replay: события до сохраненной позиции
+	// пропускаются, позиция сохраняется по ходу replay. Требует WithCheckpointStore.
+	CheckpointName string
+}
+
+// DefaultReplayServiceOptions возвращает опции по умолчанию
+func DefaultReplayServiceOptions() ReplayServiceOptions {
+	return ReplayServiceOptions{
+		ProgressInterval: 1000,
+		StopOnError:      true,
+	}
+}
+
+// ReplayService воспроизводит события из event store в обработчик или проекцию
+// с ограничением скорости, отчетом о прогрессе и возможностью приостановки.
+// Используется как библиотека и через CLI potter-replay.
+type ReplayService struct {
+	eventStore      EventStore
+	checkpointStore CheckpointStore
+
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+// NewReplayService создает новый ReplayService
+func NewReplayService(eventStore EventStore) *ReplayService {
+	return &ReplayService{
+		eventStore: eventStore,
+		resume:     make(chan struct{}),
+	}
+}
+
+// WithCheckpointStore устанавливает хранилище checkpoints для идемпотентного replay
+func (s *ReplayService) WithCheckpointStore(store CheckpointStore) *ReplayService {
+	s.checkpointStore = store
+	return s
+}
+
+// Pause приостанавливает выполняемые replay перед обработкой следующего события
+func (s *ReplayService) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+// Resume продолжает приостановленные replay
+func (s *ReplayService) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		s.paused = false
+		close(s.resume)
+		s.resume = make(chan struct{})
+	}
+}
+
+// IsPaused проверяет, приостановлен ли replay
+func (s *ReplayService) IsPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// waitIfPaused блокируется, пока replay приостановлен
+func (s *ReplayService) waitIfPaused(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		paused, resume := s.paused, s.resume
+		s.mu.Unlock()
+		if !paused {
+			return nil
+		}
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ReplayAll воспроизводит все события
+func (s *ReplayService) ReplayAll(ctx context.Context, handler ReplayHandler, opts ReplayServiceOptions) (ReplayProgress, error) {
+	return s.Replay(ctx, ReplayFilter{}, handler, opts)
+}
+
+// ReplayAggregate воспроизводит события одного агрегата
+func (s *ReplayService) ReplayAggregate(ctx context.Context, aggregateID string, handler ReplayHandler, opts ReplayServiceOptions) (ReplayProgress, error) {
+	return s.Replay(ctx, ReplayFilter{AggregateID: aggregateID}, handler, opts)
+}
+
+// ReplayByType воспроизводит события указанных типов
+func (s *ReplayService) ReplayByType(ctx context.Context, handler ReplayHandler, opts ReplayServiceOptions, eventTypes ...string) (ReplayProgress, error) {
+	return s.Replay(ctx, ReplayFilter{EventTypes: eventTypes}, handler, opts)
+}
+
+// ReplaySince воспроизводит события, возникшие начиная с since
+func (s *ReplayService) ReplaySince(ctx context.Context, since time.Time, handler ReplayHandler, opts ReplayServiceOptions) (ReplayProgress, error) {
+	return s.Replay(ctx, ReplayFilter{Since: since}, handler, opts)
+}
+
+// ReplayProjection воспроизводит события в проекцию идемпотентно: позиция replay хранится
+// в checkpoint ReplayCheckpointName(projection.Name()), поэтому повторный запуск после
+// прерывания не применяет к проекции уже обработанные события
+func (s *ReplayService) ReplayProjection(ctx context.Context, projection Projection, filter ReplayFilter, opts ReplayServiceOptions) (ReplayProgress, error) {
+	if opts.CheckpointName == "" {
+		opts.CheckpointName = ReplayCheckpointName(projection.Name())
+	}
+	return s.Replay(ctx, filter, projection, opts)
+}
+
+// ReplayCheckpointName возвращает имя checkpoint replay проекции.
+// Отличается от checkpoint ProjectionRunner, чтобы replay по фильтру не сдвигал позицию проекции.
+func ReplayCheckpointName(projectionName string) string {
+	return projectionName + "@replay"
+}
+
+// Replay воспроизводит события, соответствующие фильтру, в handler
+func (s *ReplayService) Replay(ctx context.Context, filter ReplayFilter, handler ReplayHandler, opts ReplayServiceOptions) (ReplayProgress, error) {
+	if opts.ProgressInterval <= 0 {
+		opts.ProgressInterval = DefaultReplayServiceOptions().ProgressInterval
+	}
+	if opts.CheckpointName != "" && s.checkpointStore == nil {
+		return ReplayProgress{}, fmt.Errorf("checkpoint store is required for replay with checkpoint %s", opts.CheckpointName)
+	}
+
+	var checkpoint int64
+	if opts.CheckpointName != "" {
+		position, err := s.checkpointStore.GetCheckpoint(ctx, opts.CheckpointName)
+		if err != nil {
+			return ReplayProgress{}, fmt.Errorf("failed to get checkpoint: %w", err)
+		}
+		checkpoint = position
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	source, err := s.openSource(ctx, filter)
+	if err != nil {
+		return ReplayProgress{}, err
+	}
+
+	progress := ReplayProgress{StartTime: time.Now()}
+	var interval time.Duration
+	var next time.Time
+	if opts.EventsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / opts.EventsPerSecond)
+	}
+
+	// Checkpoint сохраняется и при отмене контекста, чтобы повторный запуск продолжил с места остановки
+	report := func() error {
+		progress.ElapsedTime = time.Since(progress.StartTime)
+		if opts.CheckpointName != "" && progress.CurrentPosition > checkpoint {
+			if err := s.checkpointStore.SaveCheckpoint(context.WithoutCancel(ctx), opts.CheckpointName, progress.CurrentPosition); err != nil {
+				return fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+			checkpoint = progress.CurrentPosition
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+		return nil
+	}
+
+	for event := range source {
+		if !filter.matches(event) {
+			continue
+		}
+		progress.TotalEvents++
+		if event.Position <= checkpoint {
+			progress.SkippedEvents++
+			continue
+		}
+
+		if err := s.waitIfPaused(ctx); err != nil {
+			_ = report()
+			return progress, err
+		}
+		if interval > 0 {
+			// Интервал отсчитывается от предыдущего события: после паузы replay не ускоряется
+			if wait := time.Until(next); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					_ = report()
+					return progress, ctx.Err()
+				}
+			}
+			next = time.Now().Add(interval)
+		}
+
+		err := core.SafeCall(func() error {
+			return handler.HandleEvent(ctx, event)
+		})
+		if err != nil {
+			if opts.StopOnError {
+				_ = report()
+				return progress, fmt.Errorf("failed to replay event %s at position %d: %w", event.ID, event.Position, err)
+			}
+			progress.FailedEvents++
+		}
+
+		progress.ProcessedEvents++
+		progress.CurrentPosition = event.Position
+		if progress.ProcessedEvents%int64(opts.ProgressInterval) == 0 {
+			if err := report(); err != nil {
+				return progress, err
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		_ = report()
+		return progress, err
+	}
+	return progress, report()
+}
+
+// openSource выбирает наиболее узкий запрос к event store для фильтра
+func (s *ReplayService) openSource(ctx context.Context, filter ReplayFilter) (<-chan StoredEvent, error) {
+	if filter.AggregateID != "" {
+		stored, err := s.eventStore.GetEvents(ctx, filter.AggregateID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events of aggregate %s: %w", filter.AggregateID, err)
+		}
+		return sliceSource(ctx, stored), nil
+	}
+
+	if len(filter.EventTypes) == 1 {
+		stored, err := s.eventStore.GetEventsByType(ctx, filter.EventTypes[0], filter.Since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get events by type %s: %w", filter.EventTypes[0], err)
+		}
+		return sliceSource(ctx, stored), nil
+	}
+
+	source, err := s.eventStore.GetAllEvents(ctx, filter.FromPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all events: %w", err)
+	}
+	return source, nil
+}
+
+func sliceSource(ctx context.Context, stored []StoredEvent) <-chan StoredEvent {
+	ch := make(chan StoredEvent)
+	go func() {
+		defer close(ch)
+		for _, event := range stored {
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,378 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+var (
+	// ErrReadOnlyReplica возникает при попытке записи в event store в режиме follower
+	ErrReadOnlyReplica = errors.New("event store is a read-only replica")
+)
+
+// ReplicationRole роль event store в active-passive репликации
+type ReplicationRole string
+
+const (
+	// ReplicationRolePrimary основной регион, принимает запись
+	ReplicationRolePrimary ReplicationRole = "primary"
+	// ReplicationRoleFollower регион-реплика, только чтение
+	ReplicationRoleFollower ReplicationRole = "follower"
+)
+
+// ReadOnlyAware реализуется хранилищами, которые могут работать в режиме только чтения.
+// Компоненты с записью (например, оркестратор саг) проверяют его перед выполнением.
+type ReadOnlyAware interface {
+	IsReadOnly() bool
+}
+
+// ReplicationConfig конфигурация асинхронной репликации
+type ReplicationConfig struct {
+	// Name имя репликации, используется как ключ позиции в CheckpointStore
+	Name string
+	// PollInterval интервал опроса глобального лога основного региона
+	PollInterval time.Duration
+}
+
+// DefaultReplicationConfig возвращает конфигурацию репликации по умолчанию
+func DefaultReplicationConfig() ReplicationConfig {
+	return ReplicationConfig{
+		Name:         "replication",
+		PollInterval: time.Second,
+	}
+}
+
+// ReplicationStatus состояние репликации
+type ReplicationStatus struct {
+	Name               string
+	Running            bool
+	ReplicatedPosition int64
+	EventsReplicated   int64
+	LastReplicatedAt   time.Time
+	LastError          string
+}
+
+// EventReplicator асинхронно копирует глобальный лог событий из source (primary)
+// в target (follower), отслеживая позицию source в CheckpointStore.
+// Повторная доставка события безопасна: уже реплицированные версии пропускаются.
+type EventReplicator struct {
+	source          EventStore
+	target          EventStore
+	checkpointStore CheckpointStore
+	config          ReplicationConfig
+
+	mu     sync.RWMutex
+	status ReplicationStatus
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewEventReplicator создает новый EventReplicator
+func NewEventReplicator(source, target EventStore, checkpointStore CheckpointStore, config ReplicationConfig) *EventReplicator {
+	if config.Name == "" {
+		config.Name = "replication"
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	return &EventReplicator{
+		source:          source,
+		target:          target,
+		checkpointStore: checkpointStore,
+		config:          config,
+		status:          ReplicationStatus{Name: config.Name},
+	}
+}
+
+// ReplicateOnce копирует все события, появившиеся в source после сохраненной позиции.
+// Возвращает количество реплицированных событий.
+func (r *EventReplicator) ReplicateOnce(ctx context.Context) (int, error) {
+	position, err := r.checkpointStore.GetCheckpoint(ctx, r.config.Name)
+	if err != nil {
+		position = 0
+	}
+
+	// Чтение отменяется при выходе по ошибке: иначе горутина чтения source
+	// осталась бы заблокированной на отправке в канал
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// GetAllEvents возвращает события включительно, начинаем со следующей позиции
+	eventsChan, err := r.source.GetAllEvents(readCtx, position+1)
+	if err != nil {
+		return 0, r.fail(fmt.Errorf("failed to read source log: %w", err))
+	}
+
+	replicated := 0
+	for stored := range eventsChan {
+		if err := r.replicateEvent(ctx, stored); err != nil {
+			return replicated, r.fail(fmt.Errorf("failed to replicate event at position %d: %w", stored.Position, err))
+		}
+		if err := r.checkpointStore.SaveCheckpoint(ctx, r.config.Name, stored.Position); err != nil {
+			return replicated, r.fail(fmt.Errorf("failed to save replication position: %w", err))
+		}
+
+		replicated++
+		r.mu.Lock()
+		r.status.ReplicatedPosition = stored.Position
+		r.status.EventsReplicated++
+		r.status.LastReplicatedAt = time.Now()
+		r.status.LastError = ""
+		r.mu.Unlock()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return replicated, err
+	}
+	return replicated, nil
+}
+
+// replicateEvent записывает событие в target с той же версией агрегата
+func (r *EventReplicator) replicateEvent(ctx context.Context, stored StoredEvent) error {
+	if stored.EventData == nil {
+		return fmt.Errorf("event %s of aggregate %s is not deserialized, configure EventDeserializer on the source store",
+			stored.EventType, stored.AggregateID)
+	}
+
+	err := r.target.AppendEvents(ctx, stored.AggregateID, stored.Version-1, []events.Event{stored.EventData})
+	if err == nil || !errors.Is(err, ErrConcurrencyConflict) {
+		return err
+	}
+
+	// Конфликт версий: событие уже реплицировано (повторная доставка после сбоя)
+	existing, getErr := r.target.GetEvents(ctx, stored.AggregateID, stored.Version)
+	if getErr == nil && len(existing) > 0 && existing[0].Version == stored.Version {
+		return nil
+	}
+	return err
+}
+
+// fail сохраняет ошибку в статусе и возвращает ее
+func (r *EventReplicator) fail(err error) error {
+	r.mu.Lock()
+	r.status.LastError = err.Error()
+	r.mu.Unlock()
+	return err
+}
+
+// Start запускает фоновую репликацию
+func (r *EventReplicator) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status.Running {
+		return nil
+	}
+	r.status.Running = true
+	r.stopCh = make(chan struct{})
+	r.doneCh = make(chan struct{})
+
+	go r.run(r.stopCh, r.doneCh)
+	return nil
+}
+
+// Stop останавливает фоновую репликацию
+func (r *EventReplicator) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.status.Running {
+		r.mu.Unlock()
+		return nil
+	}
+	r.status.Running = false
+	close(r.stopCh)
+	doneCh := r.doneCh
+	r.mu.Unlock()
+
+	select {
+	case <-doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRunning проверяет, запущена ли репликация
+func (r *EventReplicator) IsRunning() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status.Running
+}
+
+// Status возвращает состояние репликации
+func (r *EventReplicator) Status() ReplicationStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
+
+// run периодически реплицирует новые события
+func (r *EventReplicator) run(stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer close(doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Ошибки сохраняются в статусе, репликация продолжается на следующем тике
+		_, _ = r.ReplicateOnce(ctx)
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PromoteOptions опции переключения follower в primary
+type PromoteOptions struct {
+	// CatchUp перед переключением дореплицировать оставшиеся события из старого primary
+	CatchUp bool
+	// Force переключиться даже если дорепликация не удалась (старый primary недоступен)
+	Force bool
+}
+
+// RegionalEventStore event store региона с ролью primary или follower.
+// В роли follower запись запрещена (ErrReadOnlyReplica), чтение выполняется из локального
+// хранилища, которое наполняется EventReplicator. Promote выполняет failover.
+type RegionalEventStore struct {
+	local      EventStore
+	mu         sync.RWMutex
+	role       ReplicationRole
+	replicator *EventReplicator
+}
+
+// NewPrimaryEventStore создает event store основного региона
+func NewPrimaryEventStore(local EventStore) *RegionalEventStore {
+	return &RegionalEventStore{
+		local: local,
+		role:  ReplicationRolePrimary,
+	}
+}
+
+// NewFollowerEventStore создает event store региона-реплики.
+// replicator должен писать в local; его запуском управляет вызывающий код (Start/Stop).
+func NewFollowerEventStore(local EventStore, replicator *EventReplicator) *RegionalEventStore {
+	return &RegionalEventStore{
+		local:      local,
+		role:       ReplicationRoleFollower,
+		replicator: replicator,
+	}
+}
+
+// Role возвращает текущую роль
+func (s *RegionalEventStore) Role() ReplicationRole {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.role
+}
+
+// IsReadOnly проверяет, работает ли хранилище в режиме только чтения (реализация ReadOnlyAware)
+func (s *RegionalEventStore) IsReadOnly() bool {
+	return s.Role() == ReplicationRoleFollower
+}
+
+// Replicator возвращает репликатор follower'а (nil для primary)
+func (s *RegionalEventStore) Replicator() *EventReplicator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.replicator
+}
+
+// Promote переключает follower в primary (failover).
+// Репликация останавливается, после чего хранилище начинает принимать запись.
+func (s *RegionalEventStore) Promote(ctx context.Context, opts PromoteOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.role == ReplicationRolePrimary {
+		return nil
+	}
+
+	if s.replicator != nil {
+		if err := s.replicator.Stop(ctx); err != nil {
+			return fmt.Errorf("failed to stop replication: %w", err)
+		}
+		if opts.CatchUp {
+			if _, err := s.replicator.ReplicateOnce(ctx); err != nil && !opts.Force {
+				return fmt.Errorf("failed to catch up before promotion: %w", err)
+			}
+		}
+	}
+
+	s.role = ReplicationRolePrimary
+	s.replicator = nil
+	return nil
+}
+
+// Demote переключает хранилище в follower (например, восстановленный старый primary)
+// и запускает репликацию из нового primary.
+func (s *RegionalEventStore) Demote(ctx context.Context, replicator *EventReplicator) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.role = ReplicationRoleFollower
+	s.replicator = replicator
+	if replicator != nil {
+		return replicator.Start(ctx)
+	}
+	return nil
+}
+
+// AppendEvents добавляет события (только в роли primary)
+func (s *RegionalEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, evts []events.Event) error {
+	if s.IsReadOnly() {
+		return fmt.Errorf("%w: cannot append events to aggregate %s", ErrReadOnlyReplica, aggregateID)
+	}
+	return s.local.AppendEvents(ctx, aggregateID, expectedVersion, evts)
+}
+
+// AppendEventsBatch добавляет пакет событий (только в роли primary)
+func (s *RegionalEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	if s.IsReadOnly() {
+		return fmt.Errorf("%w: cannot append events batch", ErrReadOnlyReplica)
+	}
+	return AppendEventsBatch(ctx, s.local, batches)
+}
+
+// GetEvents возвращает события агрегата из локального хранилища
+func (s *RegionalEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	return s.local.GetEvents(ctx, aggregateID, fromVersion)
+}
+
+// GetEventsPage возвращает страницу событий агрегата из локального хранилища (реализация EventPageReader)
+func (s *RegionalEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	return GetEventsPage(ctx, s.local, aggregateID, fromVersion, limit)
+}
+
+// TenantIsolation проверяет изоляцию тенантов локального хранилища (реализация TenantIsolatedStore)
+func (s *RegionalEventStore) TenantIsolation() bool {
+	return SupportsTenantIsolation(s.local)
+}
+
+// GetEventsByType возвращает события определенного типа из локального хранилища
+func (s *RegionalEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	return s.local.GetEventsByType(ctx, eventType, fromTimestamp)
+}
+
+// GetAllEvents возвращает глобальный лог локального хранилища
+func (s *RegionalEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	return s.local.GetAllEvents(ctx, fromPosition)
+}
+
+// ListAggregates возвращает страницу агрегатов локального хранилища
+func (s *RegionalEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	return ListAggregates(ctx, s.local, filter)
+}
@@ -2,8 +2,10 @@ package eventsourcing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/akriventsev/potter/framework/events"
@@ -28,6 +30,28 @@ type RepositoryConfig struct {
 	UseSnapshots      bool
 	SnapshotStrategy  SnapshotStrategy
 	Serializer        SnapshotSerializer
+	// ConflictRetry политика повторов для Update/SaveWithRetry (nil - политика по умолчанию)
+	ConflictRetry *ConflictRetryPolicy
+	// ConflictRetryProvider возвращает текущую политику повторов и имеет приоритет над ConflictRetry.
+	// Позволяет менять политику во время работы (например config.Value[*ConflictRetryPolicy].Get).
+	ConflictRetryProvider func() *ConflictRetryPolicy
+	// Quarantine политика карантина агрегатов с поврежденным потоком событий (nil - карантин выключен)
+	Quarantine *QuarantinePolicy
+	// Cache read-through кэш восстановленных агрегатов (nil - кэш выключен).
+	// Состояние агрегата сохраняется через Serializer, как в снапшотах.
+	Cache AggregateCache
+	// SnapshotOnly режим эфемерных агрегатов: снапшот при каждом Save и короткое
+	// хранение событий (nil - полная история событий)
+	SnapshotOnly *SnapshotOnlyPolicy
+	// LoadCounter счетчик загрузок и сохранений для SnapshotAdvisor
+	// (LoadProfileTracker.For, nil - нагрузка не собирается)
+	LoadCounter *AggregateLoadCounter
+	// StreamBatchSize размер пачки потокового чтения событий при восстановлении агрегата
+	// (0 - DefaultEventStreamBatchSize). Используется хранилищами с EventPageReader.
+	StreamBatchSize int
+	// OnSnapshotCorrupted вызывается, когда снапшот не прошел проверку целостности
+	// (ErrSnapshotCorrupted) и агрегат восстанавливается из полной истории (алертинг)
+	OnSnapshotCorrupted func(ctx context.Context, aggregateID string, err error)
 }
 
 // DefaultRepositoryConfig возвращает конфигурацию по умолчанию
@@ -46,6 +70,9 @@ type EventSourcedRepository[T AggregateInterface] struct {
 	snapshotStore SnapshotStore
 	config        RepositoryConfig
 	factory       AggregateFactory[T]
+	quarantine    *AggregateQuarantine
+
+	corruptedSnapshots atomic.Int64
 }
 
 // NewEventSourcedRepository создает новый Event Sourced репозиторий
@@ -62,12 +89,27 @@ func NewEventSourcedRepository[T AggregateInterface](
 		config.SnapshotStrategy = NewFrequencySnapshotStrategy(int64(config.SnapshotFrequency))
 	}
 
-	return &EventSourcedRepository[T]{
+	repo := &EventSourcedRepository[T]{
 		eventStore:    eventStore,
 		snapshotStore:  snapshotStore,
 		config:        config,
 		factory:       factory,
 	}
+	if config.Quarantine != nil {
+		repo.quarantine = NewAggregateQuarantine(*config.Quarantine)
+	}
+
+	return repo
+}
+
+// CorruptedSnapshots возвращает количество снапшотов, не прошедших проверку целостности при загрузке
+func (r *EventSourcedRepository[T]) CorruptedSnapshots() int64 {
+	return r.corruptedSnapshots.Load()
+}
+
+// Quarantine возвращает трекер карантина агрегатов (nil если карантин выключен)
+func (r *EventSourcedRepository[T]) Quarantine() *AggregateQuarantine {
+	return r.quarantine
 }
 
 // Save сохраняет агрегат, добавляя uncommitted события в EventStore
@@ -76,6 +118,9 @@ func (r *EventSourcedRepository[T]) Save(ctx context.Context, aggregate T) error
 	if len(uncommittedEvents) == 0 {
 		return nil
 	}
+	if r.config.SnapshotOnly != nil && r.snapshotStore == nil {
+		return fmt.Errorf("snapshot-only mode requires a snapshot store")
+	}
 
 	expectedVersion := aggregate.Version() - int64(len(uncommittedEvents))
 	if expectedVersion < 0 {
@@ -87,9 +132,14 @@ func (r *EventSourcedRepository[T]) Save(ctx context.Context, aggregate T) error
 	if err != nil {
 		return fmt.Errorf("failed to append events: %w", err)
 	}
+	if r.config.LoadCounter != nil {
+		r.config.LoadCounter.RecordWrite(int64(len(uncommittedEvents)))
+	}
 
 	// Создаем снапшот если нужно
-	if r.config.UseSnapshots && r.snapshotStore != nil {
+	if r.config.SnapshotOnly != nil {
+		r.saveSnapshotOnly(ctx, aggregate)
+	} else if r.config.UseSnapshots && r.snapshotStore != nil {
 		eventCount := aggregate.Version()
 		// Передаем агрегат как интерфейс для стратегии
 		if r.config.SnapshotStrategy.ShouldCreateSnapshot(aggregate, eventCount) {
@@ -100,122 +150,258 @@ func (r *EventSourcedRepository[T]) Save(ctx context.Context, aggregate T) error
 		}
 	}
 
+	// Запись в кэше устарела - следующая загрузка восстановит агрегат и обновит кэш
+	if r.config.Cache != nil {
+		_ = r.config.Cache.Invalidate(ctx, aggregate.ID())
+	}
+
+	// Позиция последнего события - токен согласованности команды (если собирается)
+	recordStreamConsistency(ctx, r.eventStore, aggregate.ID(), aggregate.Version())
+
 	// Помечаем события как сохраненные
 	aggregate.MarkEventsAsCommitted()
 	return nil
 }
 
-// GetByID загружает агрегат по ID, восстанавливая состояние из событий
+// GetByID загружает агрегат по ID, восстанавливая состояние из событий.
+// Если включен карантин, агрегат в карантине сразу возвращает QuarantinedError.
 func (r *EventSourcedRepository[T]) GetByID(ctx context.Context, aggregateID string) (T, error) {
+	if r.quarantine == nil {
+		return r.load(ctx, aggregateID)
+	}
+
+	var zero T
+	if err := r.quarantine.Check(aggregateID); err != nil {
+		return zero, err
+	}
+
+	aggregate, err := r.load(ctx, aggregateID)
+	if err != nil {
+		if isRehydrationFailure(err) {
+			r.quarantine.RecordFailure(ctx, aggregateID, err)
+		} else {
+			r.quarantine.CancelProbe(aggregateID)
+		}
+		return zero, err
+	}
+	r.quarantine.RecordSuccess(aggregateID)
+	return aggregate, nil
+}
+
+// load восстанавливает агрегат из кэша (если включен), снапшота и/или событий
+func (r *EventSourcedRepository[T]) load(ctx context.Context, aggregateID string) (T, error) {
 	var zero T
 
 	if r.factory == nil {
 		return zero, fmt.Errorf("aggregate factory not set")
 	}
+	if r.config.Cache == nil {
+		return r.rehydrate(ctx, aggregateID)
+	}
 
-	// Пытаемся загрузить из снапшота
-	var fromVersion int64 = 0
-	if r.config.UseSnapshots && r.snapshotStore != nil {
-		snapshot, err := r.snapshotStore.GetSnapshot(ctx, aggregateID)
-		if err == nil && snapshot != nil {
-			// Создаем новый агрегат через фабрику
-			aggregate := r.factory(aggregateID)
-			
-			// Десериализуем состояние из снапшота
-			if err := r.config.Serializer.Deserialize(snapshot.State, aggregate); err != nil {
-				// Если не удалось десериализовать, загружаем с начала
-				fromVersion = 0
-			} else {
-				aggregate.SetVersion(snapshot.Version)
-				fromVersion = snapshot.Version + 1
-			}
+	aggregate, ok, err := r.loadFromCache(ctx, aggregateID)
+	if err != nil {
+		return zero, err
+	}
+	if ok {
+		return aggregate, nil
+	}
 
-			// Загружаем события после снапшота
-			storedEvents, err := r.eventStore.GetEvents(ctx, aggregateID, fromVersion)
-			if err != nil && err != ErrStreamNotFound {
-				return zero, fmt.Errorf("failed to get events: %w", err)
-			}
+	aggregate, err = r.rehydrate(ctx, aggregateID)
+	if err != nil {
+		return zero, err
+	}
+	r.cacheAggregate(ctx, aggregate)
+	return aggregate, nil
+}
 
-			// Применяем события для восстановления состояния
-			if len(storedEvents) > 0 {
-				eventList := make([]events.Event, 0, len(storedEvents))
-				for _, stored := range storedEvents {
-					if stored.EventData != nil {
-						eventList = append(eventList, stored.EventData)
-					}
-				}
-				// Применяем события последовательно
-				for _, event := range eventList {
-					if err := aggregate.Apply(event); err != nil {
-						return zero, fmt.Errorf("failed to apply event: %w", err)
-					}
-					aggregate.SetVersion(aggregate.Version() + 1)
-				}
-			}
+// rehydrate восстанавливает агрегат из снапшота и/или событий
+func (r *EventSourcedRepository[T]) rehydrate(ctx context.Context, aggregateID string) (T, error) {
+	var zero T
 
+	// Пытаемся загрузить из снапшота
+	if (r.config.UseSnapshots || r.config.SnapshotOnly != nil) && r.snapshotStore != nil {
+		aggregate, ok, err := r.loadFromSnapshot(ctx, aggregateID)
+		if err != nil {
+			return zero, err
+		}
+		if ok {
 			return aggregate, nil
 		}
+		// Снапшот отсутствует или поврежден - восстанавливаем из полной истории событий
 	}
 
-	// Загружаем все события с начала
-	storedEvents, err := r.eventStore.GetEvents(ctx, aggregateID, 0)
-	if err != nil {
-		if err == ErrStreamNotFound {
+	// Создаем новый агрегат через фабрику
+	aggregate := r.factory(aggregateID)
+
+	// Применяем события с начала потока пачками, не загружая всю историю в память
+	stream := r.streamEvents(ctx, aggregateID, 0)
+	for stream.Next() {
+		stored := stream.Event()
+		if r.config.SnapshotOnly != nil && stream.Read() == 1 {
+			if err := checkSnapshotOnlyStream(aggregateID, []StoredEvent{stored}); err != nil {
+				return zero, err
+			}
+		}
+		if _, err := applyStored(aggregate, stored); err != nil {
+			return zero, err
+		}
+	}
+	if err := stream.Err(); err != nil {
+		if errors.Is(err, ErrStreamNotFound) {
 			return zero, fmt.Errorf("aggregate not found: %s", aggregateID)
 		}
 		return zero, fmt.Errorf("failed to get events: %w", err)
 	}
+	r.recordRead(stream.Read())
+
+	return aggregate, nil
+}
+
+// loadFromSnapshot восстанавливает агрегат из снапшота и последующих событий.
+// Возвращает ok=false если снапшот отсутствует, поврежден или не может быть десериализован.
+func (r *EventSourcedRepository[T]) loadFromSnapshot(ctx context.Context, aggregateID string) (T, bool, error) {
+	var zero T
+
+	snapshot, err := r.snapshotStore.GetSnapshot(ctx, aggregateID)
+	if err != nil || snapshot == nil {
+		// Ошибки чтения не фатальны - используем полный replay. Нарушение целостности
+		// (поврежденный или подмененный снапшот) учитывается и сообщается OnSnapshotCorrupted.
+		if errors.Is(err, ErrSnapshotCorrupted) {
+			r.corruptedSnapshots.Add(1)
+			if r.config.OnSnapshotCorrupted != nil {
+				r.config.OnSnapshotCorrupted(ctx, aggregateID, err)
+			}
+		}
+		return zero, false, nil
+	}
 
 	// Создаем новый агрегат через фабрику
 	aggregate := r.factory(aggregateID)
 
-	// Применяем события для восстановления состояния
-	if len(storedEvents) > 0 {
-		eventList := make([]events.Event, 0, len(storedEvents))
-		for _, stored := range storedEvents {
-			if stored.EventData != nil {
-				eventList = append(eventList, stored.EventData)
-			}
+	// Десериализуем состояние из снапшота
+	if err := r.config.Serializer.Deserialize(snapshot.State, aggregate); err != nil {
+		return zero, false, nil
+	}
+	aggregate.SetVersion(snapshot.Version)
+
+	// Применяем события после снапшота
+	stream := r.streamEvents(ctx, aggregateID, snapshot.Version+1)
+	for stream.Next() {
+		if _, err := applyStored(aggregate, stream.Event()); err != nil {
+			return zero, false, err
 		}
-		// Применяем события последовательно
-		for _, event := range eventList {
-			if err := aggregate.Apply(event); err != nil {
-				return zero, fmt.Errorf("failed to apply event: %w", err)
-			}
-			aggregate.SetVersion(aggregate.Version() + 1)
+	}
+	if err := stream.Err(); err != nil && !errors.Is(err, ErrStreamNotFound) {
+		return zero, false, fmt.Errorf("failed to get events: %w", err)
+	}
+	r.recordRead(stream.Read())
+
+	return aggregate, true, nil
+}
+
+// loadFromCache восстанавливает агрегат из записи кэша и событий после ее версии.
+// Возвращает ok=false если записи нет, она не десериализуется или поток агрегата удален.
+func (r *EventSourcedRepository[T]) loadFromCache(ctx context.Context, aggregateID string) (T, bool, error) {
+	var zero T
+
+	entry, err := r.config.Cache.Get(ctx, aggregateID)
+	if err != nil || entry == nil {
+		// Ошибки кэша не фатальны - восстанавливаем агрегат из хранилища
+		return zero, false, nil
+	}
+
+	aggregate := r.factory(aggregateID)
+	if err := r.config.Serializer.Deserialize(entry.State, aggregate); err != nil {
+		_ = r.config.Cache.Invalidate(ctx, aggregateID)
+		return zero, false, nil
+	}
+	aggregate.SetVersion(entry.Version)
+
+	// Дочитываем события, записанные после кэширования (в том числе другими инстансами)
+	applied := 0
+	stream := r.streamEvents(ctx, aggregateID, entry.Version+1)
+	for stream.Next() {
+		ok, err := applyStored(aggregate, stream.Event())
+		if err != nil {
+			_ = r.config.Cache.Invalidate(ctx, aggregateID)
+			return zero, false, err
+		}
+		if ok {
+			applied++
+		}
+	}
+	if err := stream.Err(); err != nil {
+		if errors.Is(err, ErrStreamNotFound) {
+			_ = r.config.Cache.Invalidate(ctx, aggregateID)
+			return zero, false, nil
 		}
+		return zero, false, fmt.Errorf("failed to get events: %w", err)
+	}
+	if applied > 0 {
+		r.cacheAggregate(ctx, aggregate)
 	}
+	r.recordRead(int64(applied))
 
-	return aggregate, nil
+	return aggregate, true, nil
 }
 
-// GetVersion возвращает текущую версию агрегата
-func (r *EventSourcedRepository[T]) GetVersion(ctx context.Context, aggregateID string) (int64, error) {
-	events, err := r.eventStore.GetEvents(ctx, aggregateID, 0)
+// recordRead учитывает загрузку агрегата в LoadCounter
+func (r *EventSourcedRepository[T]) recordRead(replayed int64) {
+	if r.config.LoadCounter != nil {
+		r.config.LoadCounter.RecordRead(replayed)
+	}
+}
+
+// cacheAggregate сохраняет состояние агрегата в кэш (ошибки кэша не прерывают загрузку)
+func (r *EventSourcedRepository[T]) cacheAggregate(ctx context.Context, aggregate T) {
+	state, err := r.config.Serializer.Serialize(aggregate)
 	if err != nil {
-		if err == ErrStreamNotFound {
-			return 0, nil
-		}
-		return 0, fmt.Errorf("failed to get events: %w", err)
+		return
 	}
+	_ = r.config.Cache.Set(ctx, CachedAggregate{
+		AggregateID: aggregate.ID(),
+		Version:     aggregate.Version(),
+		State:       state,
+	})
+}
 
-	if len(events) == 0 {
-		return 0, nil
+// InvalidateCache удаляет агрегат из кэша. Нужен, когда поток агрегата изменяется
+// в обход репозитория (миграции, удаление данных субъекта).
+func (r *EventSourcedRepository[T]) InvalidateCache(ctx context.Context, aggregateID string) error {
+	if r.config.Cache == nil {
+		return nil
 	}
+	return r.config.Cache.Invalidate(ctx, aggregateID)
+}
 
-	return events[len(events)-1].Version, nil
+// GetVersion возвращает текущую версию агрегата. Поток читается пачками, поэтому
+// в памяти находится не больше одной пачки событий.
+func (r *EventSourcedRepository[T]) GetVersion(ctx context.Context, aggregateID string) (int64, error) {
+	var version int64
+	stream := r.streamEvents(ctx, aggregateID, 0)
+	for stream.Next() {
+		version = stream.Event().Version
+	}
+	if err := stream.Err(); err != nil {
+		if errors.Is(err, ErrStreamNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get events: %w", err)
+	}
+	return version, nil
 }
 
-// Exists проверяет существование агрегата
+// Exists проверяет существование агрегата по первому событию потока
 func (r *EventSourcedRepository[T]) Exists(ctx context.Context, aggregateID string) (bool, error) {
-	events, err := r.eventStore.GetEvents(ctx, aggregateID, 0)
+	stored, err := GetEventsPage(ctx, r.eventStore, aggregateID, 0, 1)
 	if err != nil {
-		if err == ErrStreamNotFound {
+		if errors.Is(err, ErrStreamNotFound) {
 			return false, nil
 		}
 		return false, err
 	}
-	return len(events) > 0, nil
+	return len(stored) > 0, nil
 }
 
 // createSnapshot создает снапшот агрегата
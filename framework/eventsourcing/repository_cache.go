@@ -0,0 +1,283 @@
+package eventsourcing
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CachedAggregate закэшированное состояние агрегата: запись идентифицируется парой
+// (AggregateID, Version) и хранит состояние, сериализованное SnapshotSerializer репозитория
+type CachedAggregate struct {
+	AggregateID string
+	Version     int64
+	State       []byte
+}
+
+// AggregateCache read-through кэш восстановленных агрегатов EventSourcedRepository.
+// Кэш не является источником истины: при загрузке репозиторий дочитывает события после
+// версии записи, поэтому устаревшая запись (например, после записи другим инстансом)
+// дает корректный агрегат, а не старое состояние.
+//
+// Записи разделяются по тенанту из контекста (см. WithTenant): хранилища с изоляцией
+// тенантов допускают одинаковые идентификаторы агрегатов у разных тенантов.
+type AggregateCache interface {
+	// Get возвращает запись агрегата (nil если записи нет или истек TTL)
+	Get(ctx context.Context, aggregateID string) (*CachedAggregate, error)
+	// Set сохраняет запись (более старая версия не перезаписывает более новую)
+	Set(ctx context.Context, entry CachedAggregate) error
+	// Invalidate удаляет запись агрегата
+	Invalidate(ctx context.Context, aggregateID string) error
+}
+
+// AggregateCacheConfig конфигурация in-memory LRU кэша агрегатов
+type AggregateCacheConfig struct {
+	// MaxSize максимальное количество агрегатов в кэше (при превышении вытесняются давно не читанные)
+	MaxSize int
+	// TTL время жизни записи (0 - без ограничения)
+	TTL time.Duration
+}
+
+// DefaultAggregateCacheConfig возвращает конфигурацию по умолчанию
+func DefaultAggregateCacheConfig() AggregateCacheConfig {
+	return AggregateCacheConfig{
+		MaxSize: 10000,
+		TTL:     5 * time.Minute,
+	}
+}
+
+// aggregateCacheKey ключ записи кэша: агрегат тенанта
+type aggregateCacheKey struct {
+	tenantID    string
+	aggregateID string
+}
+
+func newAggregateCacheKey(ctx context.Context, aggregateID string) aggregateCacheKey {
+	return aggregateCacheKey{tenantID: TenantFromContext(ctx), aggregateID: aggregateID}
+}
+
+// lruCacheEntry запись LRU кэша
+type lruCacheEntry struct {
+	key       aggregateCacheKey
+	entry     CachedAggregate
+	expiresAt time.Time
+}
+
+// LRUAggregateCache in-memory LRU кэш агрегатов одного инстанса сервиса
+type LRUAggregateCache struct {
+	config  AggregateCacheConfig
+	entries map[aggregateCacheKey]*list.Element
+	order   *list.List
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// NewLRUAggregateCache создает in-memory LRU кэш агрегатов
+func NewLRUAggregateCache(config AggregateCacheConfig) *LRUAggregateCache {
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultAggregateCacheConfig().MaxSize
+	}
+	return &LRUAggregateCache{
+		config:  config,
+		entries: make(map[aggregateCacheKey]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get возвращает запись агрегата и отмечает ее как недавно использованную
+func (c *LRUAggregateCache) Get(ctx context.Context, aggregateID string) (*CachedAggregate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[newAggregateCacheKey(ctx, aggregateID)]
+	if !ok {
+		return nil, nil
+	}
+	cached := element.Value.(*lruCacheEntry)
+	if !cached.expiresAt.IsZero() && !c.now().Before(cached.expiresAt) {
+		c.remove(element)
+		return nil, nil
+	}
+	c.order.MoveToFront(element)
+
+	entry := cached.entry
+	return &entry, nil
+}
+
+// Set сохраняет запись и вытесняет давно не использованные записи при превышении MaxSize
+func (c *LRUAggregateCache) Set(ctx context.Context, entry CachedAggregate) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.config.TTL > 0 {
+		expiresAt = c.now().Add(c.config.TTL)
+	}
+
+	key := newAggregateCacheKey(ctx, entry.AggregateID)
+	if element, ok := c.entries[key]; ok {
+		cached := element.Value.(*lruCacheEntry)
+		if cached.entry.Version > entry.Version {
+			return nil
+		}
+		cached.entry = entry
+		cached.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruCacheEntry{key: key, entry: entry, expiresAt: expiresAt})
+	for c.order.Len() > c.config.MaxSize {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Invalidate удаляет запись агрегата
+func (c *LRUAggregateCache) Invalidate(ctx context.Context, aggregateID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[newAggregateCacheKey(ctx, aggregateID)]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+// Len возвращает количество записей в кэше
+func (c *LRUAggregateCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove удаляет элемент из кэша (вызывается под блокировкой)
+func (c *LRUAggregateCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruCacheEntry).key)
+}
+
+// RedisAggregateCacheConfig конфигурация Redis кэша агрегатов
+type RedisAggregateCacheConfig struct {
+	Addr      string
+	Password  string
+	DB        int
+	PoolSize  int
+	KeyPrefix string
+	// TTL время жизни записи (0 - без ограничения). Ограничение размера задается
+	// политикой вытеснения Redis (maxmemory-policy allkeys-lru или volatile-lru).
+	TTL time.Duration
+}
+
+// Validate проверяет корректность конфигурации
+func (c RedisAggregateCacheConfig) Validate() error {
+	if c.Addr == "" {
+		return fmt.Errorf("addr cannot be empty")
+	}
+	if c.TTL < 0 {
+		return fmt.Errorf("ttl cannot be negative")
+	}
+	return nil
+}
+
+// DefaultRedisAggregateCacheConfig возвращает конфигурацию по умолчанию
+func DefaultRedisAggregateCacheConfig() RedisAggregateCacheConfig {
+	return RedisAggregateCacheConfig{
+		Addr:      "localhost:6379",
+		PoolSize:  10,
+		KeyPrefix: "potter:aggregate:",
+		TTL:       5 * time.Minute,
+	}
+}
+
+// RedisAggregateCache кэш агрегатов в Redis, общий для инстансов сервиса
+type RedisAggregateCache struct {
+	config RedisAggregateCacheConfig
+	client *redis.Client
+}
+
+// NewRedisAggregateCache создает Redis кэш агрегатов
+func NewRedisAggregateCache(config RedisAggregateCacheConfig) (*RedisAggregateCache, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid redis config: %w", err)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+		PoolSize: config.PoolSize,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &RedisAggregateCache{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// key возвращает ключ Redis для агрегата: <prefix>[<tenant>:]<aggregate>
+func (c *RedisAggregateCache) key(ctx context.Context, aggregateID string) string {
+	if tenantID := TenantFromContext(ctx); tenantID != "" {
+		return c.config.KeyPrefix + tenantID + ":" + aggregateID
+	}
+	return c.config.KeyPrefix + aggregateID
+}
+
+// Get возвращает запись агрегата
+func (c *RedisAggregateCache) Get(ctx context.Context, aggregateID string) (*CachedAggregate, error) {
+	values, err := c.client.HMGet(ctx, c.key(ctx, aggregateID), "version", "data").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached aggregate: %w", err)
+	}
+	version, ok := values[0].(string)
+	if !ok {
+		return nil, nil
+	}
+	data, ok := values[1].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cached aggregate version %q: %w", version, err)
+	}
+	return &CachedAggregate{AggregateID: aggregateID, Version: parsed, State: []byte(data)}, nil
+}
+
+// Set сохраняет запись (более старая версия не перезаписывает более новую)
+func (c *RedisAggregateCache) Set(ctx context.Context, entry CachedAggregate) error {
+	err := redisSaveSnapshotScript.Run(ctx, c.client,
+		[]string{c.key(ctx, entry.AggregateID)},
+		entry.Version, entry.State, c.config.TTL.Milliseconds(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to cache aggregate: %w", err)
+	}
+	return nil
+}
+
+// Invalidate удаляет запись агрегата
+func (c *RedisAggregateCache) Invalidate(ctx context.Context, aggregateID string) error {
+	if err := c.client.Del(ctx, c.key(ctx, aggregateID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached aggregate: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с Redis
+func (c *RedisAggregateCache) Close() error {
+	return c.client.Close()
+}
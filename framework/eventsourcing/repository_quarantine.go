@@ -0,0 +1,228 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRehydrationFailed возникает когда событие из потока не удается применить к агрегату
+	ErrRehydrationFailed = errors.New("aggregate rehydration failed")
+	// ErrAggregateQuarantined возникает при загрузке агрегата, находящегося в карантине
+	ErrAggregateQuarantined = errors.New("aggregate is quarantined")
+)
+
+// QuarantinedError типизированная ошибка загрузки агрегата из карантина.
+// errors.Is(err, ErrAggregateQuarantined) возвращает true.
+type QuarantinedError struct {
+	AggregateID string
+	Failures    int
+	Until       time.Time
+	LastError   error
+}
+
+// Error возвращает текст ошибки
+func (e *QuarantinedError) Error() string {
+	return fmt.Sprintf("aggregate %s is quarantined until %s after %d failed rehydrations: %v",
+		e.AggregateID, e.Until.Format(time.RFC3339), e.Failures, e.LastError)
+}
+
+// Is позволяет сравнивать ошибку с ErrAggregateQuarantined
+func (e *QuarantinedError) Is(target error) bool {
+	return target == ErrAggregateQuarantined
+}
+
+// Unwrap возвращает последнюю ошибку восстановления
+func (e *QuarantinedError) Unwrap() error {
+	return e.LastError
+}
+
+// QuarantineInfo состояние агрегата в карантине
+type QuarantineInfo struct {
+	AggregateID   string
+	Failures      int
+	Quarantines   int
+	LastError     error
+	QuarantinedAt time.Time
+	Until         time.Time
+}
+
+// QuarantinePolicy политика карантина "отравленных" агрегатов.
+// Неудачным восстановлением считаются ошибки применения события (ErrRehydrationFailed),
+// десериализации (ErrEventDeserializationFailed) и upcast (ErrUpcastFailed).
+// После FailureThreshold подряд неудачных восстановлений агрегат помещается в карантин
+// на Cooldown; загрузки в это время сразу возвращают QuarantinedError без обращения к хранилищу.
+// По истечении карантина разрешается одна пробная загрузка, остальные загрузки до ее
+// завершения получают QuarantinedError: при неудаче карантин продлевается с экспоненциально
+// растущей задержкой (до MaxCooldown). Проба, не завершившаяся за Cooldown, считается потерянной,
+// и следующая загрузка становится новой пробой.
+type QuarantinePolicy struct {
+	FailureThreshold  int
+	Cooldown          time.Duration
+	MaxCooldown       time.Duration
+	BackoffMultiplier float64
+	// OnQuarantine вызывается при помещении агрегата в карантин (алертинг)
+	OnQuarantine func(ctx context.Context, info QuarantineInfo)
+}
+
+// DefaultQuarantinePolicy возвращает политику карантина по умолчанию
+func DefaultQuarantinePolicy() *QuarantinePolicy {
+	return &QuarantinePolicy{
+		FailureThreshold:  3,
+		Cooldown:          time.Minute,
+		MaxCooldown:       time.Hour,
+		BackoffMultiplier: 2.0,
+	}
+}
+
+// cooldown вычисляет длительность карантина для quarantines-го попадания (начиная с 1)
+func (p *QuarantinePolicy) cooldown(quarantines int) time.Duration {
+	delay := p.Cooldown
+	for i := 1; i < quarantines; i++ {
+		delay = time.Duration(float64(delay) * p.BackoffMultiplier)
+		if p.MaxCooldown > 0 && delay > p.MaxCooldown {
+			return p.MaxCooldown
+		}
+	}
+	return delay
+}
+
+// isRehydrationFailure проверяет, вызвана ли ошибка загрузки поврежденным потоком событий,
+// а не недоступностью хранилища
+func isRehydrationFailure(err error) bool {
+	return errors.Is(err, ErrRehydrationFailed) ||
+		errors.Is(err, ErrEventDeserializationFailed) ||
+		errors.Is(err, ErrUpcastFailed)
+}
+
+// quarantineEntry состояние агрегата с неудачными восстановлениями
+type quarantineEntry struct {
+	QuarantineInfo
+	// probeStarted время начала пробной загрузки после карантина (нулевое - пробы нет)
+	probeStarted time.Time
+}
+
+// AggregateQuarantine отслеживает неудачные восстановления агрегатов
+type AggregateQuarantine struct {
+	policy  QuarantinePolicy
+	mu      sync.Mutex
+	entries map[string]*quarantineEntry
+	now     func() time.Time
+}
+
+// NewAggregateQuarantine создает новый трекер карантина
+func NewAggregateQuarantine(policy QuarantinePolicy) *AggregateQuarantine {
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = 1
+	}
+	if policy.BackoffMultiplier < 1 {
+		policy.BackoffMultiplier = 1
+	}
+	return &AggregateQuarantine{
+		policy:  policy,
+		entries: make(map[string]*quarantineEntry),
+		now:     time.Now,
+	}
+}
+
+// Check возвращает QuarantinedError если агрегат находится в карантине.
+// После истечения карантина Check разрешает одну пробную загрузку; ее результат
+// передается в RecordSuccess, RecordFailure или CancelProbe.
+func (q *AggregateQuarantine) Check(aggregateID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.entries[aggregateID]
+	if !ok || entry.Until.IsZero() {
+		return nil
+	}
+	now := q.now()
+	if !now.Before(entry.Until) {
+		if entry.probeStarted.IsZero() || now.Sub(entry.probeStarted) >= q.policy.Cooldown {
+			entry.probeStarted = now
+			return nil
+		}
+	}
+	return &QuarantinedError{
+		AggregateID: aggregateID,
+		Failures:    entry.Failures,
+		Until:       entry.Until,
+		LastError:   entry.LastError,
+	}
+}
+
+// RecordFailure регистрирует неудачное восстановление агрегата
+func (q *AggregateQuarantine) RecordFailure(ctx context.Context, aggregateID string, err error) {
+	q.mu.Lock()
+
+	entry, ok := q.entries[aggregateID]
+	if !ok {
+		entry = &quarantineEntry{QuarantineInfo: QuarantineInfo{AggregateID: aggregateID}}
+		q.entries[aggregateID] = entry
+	}
+	entry.Failures++
+	entry.LastError = err
+	entry.probeStarted = time.Time{}
+
+	// Пробная загрузка после карантина сразу возвращает агрегат в карантин
+	probeFailed := entry.Quarantines > 0
+	if !probeFailed && entry.Failures < q.policy.FailureThreshold {
+		q.mu.Unlock()
+		return
+	}
+
+	now := q.now()
+	entry.Quarantines++
+	entry.QuarantinedAt = now
+	entry.Until = now.Add(q.policy.cooldown(entry.Quarantines))
+	info := entry.QuarantineInfo
+	q.mu.Unlock()
+
+	if q.policy.OnQuarantine != nil {
+		q.policy.OnQuarantine(ctx, info)
+	}
+}
+
+// RecordSuccess сбрасывает счетчик неудач после успешного восстановления
+func (q *AggregateQuarantine) RecordSuccess(aggregateID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.entries, aggregateID)
+}
+
+// CancelProbe завершает пробную загрузку без результата (ошибка не связана с потоком событий,
+// например хранилище недоступно): агрегат остается в текущем состоянии, и следующая
+// загрузка снова выполняет пробу
+func (q *AggregateQuarantine) CancelProbe(aggregateID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if entry, ok := q.entries[aggregateID]; ok {
+		entry.probeStarted = time.Time{}
+	}
+}
+
+// Release досрочно выводит агрегат из карантина (например, после исправления потока событий)
+func (q *AggregateQuarantine) Release(aggregateID string) {
+	q.RecordSuccess(aggregateID)
+}
+
+// List возвращает агрегаты, находящиеся в карантине
+func (q *AggregateQuarantine) List() []QuarantineInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := make([]QuarantineInfo, 0, len(q.entries))
+	for _, entry := range q.entries {
+		if entry.Quarantines > 0 {
+			result = append(result, entry.QuarantineInfo)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AggregateID < result[j].AggregateID
+	})
+	return result
+}
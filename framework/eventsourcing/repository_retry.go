@@ -0,0 +1,141 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConflictRetryPolicy политика повторов при конфликте оптимистичной конкурентности
+type ConflictRetryPolicy struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+// DefaultConflictRetryPolicy возвращает политику повторов по умолчанию
+func DefaultConflictRetryPolicy() *ConflictRetryPolicy {
+	return &ConflictRetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    10 * time.Millisecond,
+		MaxBackoff:        500 * time.Millisecond,
+		BackoffMultiplier: 2.0,
+	}
+}
+
+// backoff вычисляет задержку перед попыткой attempt (начиная с 1)
+func (p *ConflictRetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * p.BackoffMultiplier)
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// CommandFunc применяет команду к агрегату, вызывая RaiseEvent для новых событий.
+// Функция должна быть идемпотентной относительно состояния агрегата, так как
+// при конфликте она вызывается повторно на перезагруженном агрегате.
+type CommandFunc[T AggregateInterface] func(ctx context.Context, aggregate T) error
+
+// Update загружает агрегат, применяет команду и сохраняет его.
+// При ErrConcurrencyConflict агрегат перезагружается и команда применяется повторно
+// согласно ConflictRetryPolicy из конфигурации репозитория.
+func (r *EventSourcedRepository[T]) Update(ctx context.Context, aggregateID string, command CommandFunc[T]) (T, error) {
+	return r.update(ctx, aggregateID, command, r.conflictRetryPolicy())
+}
+
+// update выполняет цикл загрузка-команда-сохранение с указанной политикой повторов
+func (r *EventSourcedRepository[T]) update(ctx context.Context, aggregateID string, command CommandFunc[T], policy *ConflictRetryPolicy) (T, error) {
+	var zero T
+
+	var lastErr error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		aggregate, err := r.GetByID(ctx, aggregateID)
+		if err != nil {
+			return zero, err
+		}
+
+		if err := command(ctx, aggregate); err != nil {
+			return zero, err
+		}
+
+		lastErr = r.Save(ctx, aggregate)
+		if lastErr == nil {
+			return aggregate, nil
+		}
+		if !errors.Is(lastErr, ErrConcurrencyConflict) {
+			return zero, lastErr
+		}
+
+		if attempt < policy.MaxAttempts {
+			if err := r.waitBackoff(ctx, policy, attempt); err != nil {
+				return zero, err
+			}
+		}
+	}
+
+	return zero, fmt.Errorf("update of aggregate %s failed after %d attempts: %w", aggregateID, policy.MaxAttempts, lastErr)
+}
+
+// SaveWithRetry сохраняет агрегат, а при конфликте версий перезагружает его
+// и повторно применяет команду через reapply.
+func (r *EventSourcedRepository[T]) SaveWithRetry(ctx context.Context, aggregate T, reapply CommandFunc[T]) (T, error) {
+	var zero T
+
+	err := r.Save(ctx, aggregate)
+	if err == nil {
+		return aggregate, nil
+	}
+	if !errors.Is(err, ErrConcurrencyConflict) || reapply == nil {
+		return zero, err
+	}
+
+	policy := r.conflictRetryPolicy()
+	if policy.MaxAttempts <= 1 {
+		return zero, err
+	}
+	if err := r.waitBackoff(ctx, policy, 1); err != nil {
+		return zero, err
+	}
+
+	// Первая попытка уже выполнена через Save
+	retryPolicy := *policy
+	retryPolicy.MaxAttempts--
+	return r.update(ctx, aggregate.ID(), reapply, &retryPolicy)
+}
+
+// conflictRetryPolicy возвращает политику повторов из конфигурации
+func (r *EventSourcedRepository[T]) conflictRetryPolicy() *ConflictRetryPolicy {
+	policy := r.config.ConflictRetry
+	if r.config.ConflictRetryProvider != nil {
+		policy = r.config.ConflictRetryProvider()
+	}
+	if policy == nil {
+		policy = DefaultConflictRetryPolicy()
+	}
+	if policy.MaxAttempts <= 0 {
+		p := *policy
+		p.MaxAttempts = 1
+		policy = &p
+	}
+	return policy
+}
+
+// waitBackoff ожидает перед следующей попыткой
+func (r *EventSourcedRepository[T]) waitBackoff(ctx context.Context, policy *ConflictRetryPolicy, attempt int) error {
+	delay := policy.backoff(attempt)
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSnapshotRequired возникает при загрузке агрегата в snapshot-only режиме без снапшота,
+// когда начало потока событий уже удалено
+var ErrSnapshotRequired = errors.New("snapshot required to load aggregate with truncated stream")
+
+// SnapshotOnlyPolicy режим для эфемерных агрегатов, история которых не нужна (корзины,
+// черновики): при каждом Save сохраняется снапшот, а события старше EventRetention
+// удаляются из потока. Последнее событие потока сохраняется всегда - по нему хранилище
+// проверяет expected version при записи.
+//
+// Политика задается в RepositoryConfig репозитория типа агрегата. Требуется SnapshotStore;
+// события удаляются, если хранилище реализует EventStreamTruncator (иначе поток не сокращается).
+type SnapshotOnlyPolicy struct {
+	// EventRetention время хранения событий (проекции и подписки успевают их прочитать).
+	// 0 - события удаляются сразу после записи снапшота.
+	EventRetention time.Duration
+}
+
+// DefaultSnapshotOnlyPolicy возвращает политику по умолчанию: события хранятся сутки
+func DefaultSnapshotOnlyPolicy() *SnapshotOnlyPolicy {
+	return &SnapshotOnlyPolicy{EventRetention: 24 * time.Hour}
+}
+
+// saveSnapshotOnly сохраняет снапшот агрегата и удаляет события старше окна хранения
+func (r *EventSourcedRepository[T]) saveSnapshotOnly(ctx context.Context, aggregate T) {
+	// Без снапшота события нельзя удалять - при ошибке поток сократится при следующем Save
+	if err := r.createSnapshot(ctx, aggregate); err != nil {
+		return
+	}
+	truncator, ok := r.eventStore.(EventStreamTruncator)
+	if !ok {
+		return
+	}
+
+	stored, err := r.eventStore.GetEvents(ctx, aggregate.ID(), 0)
+	if err != nil || len(stored) < 2 {
+		return
+	}
+
+	cutoff := time.Now().Add(-r.config.SnapshotOnly.EventRetention)
+	last := stored[len(stored)-1].Version
+	beforeVersion := int64(0)
+	for _, event := range stored {
+		if event.Version >= last || event.Version > aggregate.Version() || !event.OccurredAt.Before(cutoff) {
+			break
+		}
+		beforeVersion = event.Version + 1
+	}
+	if beforeVersion == 0 {
+		return
+	}
+
+	// Ошибка удаления не прерывает сохранение: события будут удалены при следующем Save
+	_ = truncator.TruncateStream(ctx, aggregate.ID(), beforeVersion)
+}
+
+// checkSnapshotOnlyStream проверяет, что поток без снапшота содержит полную историю агрегата
+func checkSnapshotOnlyStream(aggregateID string, stored []StoredEvent) error {
+	if len(stored) > 0 && stored[0].Version > 1 {
+		return fmt.Errorf("%w: %s (stream starts at version %d)", ErrSnapshotRequired, aggregateID, stored[0].Version)
+	}
+	return nil
+}
@@ -2,8 +2,14 @@ package eventsourcing
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/events"
 )
 
@@ -276,3 +282,1032 @@ func TestEventSourcedRepository_Exists(t *testing.T) {
 	}
 }
 
+
+// corruptedSnapshotStore возвращает ошибку целостности для любого снапшота
+type corruptedSnapshotStore struct {
+	*InMemorySnapshotStore
+}
+
+func (s *corruptedSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
+	snapshot, err := s.InMemorySnapshotStore.GetSnapshot(ctx, aggregateID)
+	if err != nil || snapshot == nil {
+		return snapshot, err
+	}
+	tampered := *snapshot
+	tampered.State = []byte(`{"tampered":true}`)
+	if err := VerifySnapshot(&tampered, nil); err != nil {
+		return nil, err
+	}
+	return &tampered, nil
+}
+
+func TestEventSourcedRepository_CorruptedSnapshotFallsBackToReplay(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	snapshotStore := &corruptedSnapshotStore{InMemorySnapshotStore: NewInMemorySnapshotStore()}
+	config := DefaultRepositoryConfig()
+	config.SnapshotStrategy = NewFrequencySnapshotStrategy(1)
+	var reported []string
+	config.OnSnapshotCorrupted = func(ctx context.Context, aggregateID string, err error) {
+		if errors.Is(err, ErrSnapshotCorrupted) {
+			reported = append(reported, aggregateID)
+		}
+	}
+	repo := NewEventSourcedRepository[*TestAggregate](eventStore, snapshotStore, config, NewTestAggregate)
+	ctx := context.Background()
+
+	agg := createTestAggregate("test-1")
+	if err := repo.Save(ctx, agg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Снапшот сохранен с контрольной суммой
+	stored, _ := snapshotStore.InMemorySnapshotStore.GetSnapshot(ctx, "test-1")
+	if stored == nil {
+		t.Fatal("Expected snapshot to be created")
+	}
+	if err := SealSnapshot(stored, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded, err := repo.GetByID(ctx, "test-1")
+	if err != nil {
+		t.Fatalf("Expected fallback to replay, got %v", err)
+	}
+	if loaded.name != "Test" || loaded.value != 10 {
+		t.Errorf("Expected state restored from events, got name=%q value=%d", loaded.name, loaded.value)
+	}
+	if loaded.Version() != 1 {
+		t.Errorf("Expected version 1, got %d", loaded.Version())
+	}
+	if repo.CorruptedSnapshots() != 1 || len(reported) != 1 || reported[0] != "test-1" {
+		t.Errorf("Expected integrity failure to be counted and reported, got %d %v", repo.CorruptedSnapshots(), reported)
+	}
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	signer := NewHMACSnapshotSigner([]byte("secret"))
+	snapshot := &Snapshot{AggregateID: "test-1", State: []byte(`{"b":1, "a":"x"}`)}
+	if err := SealSnapshot(snapshot, signer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Изменение порядка ключей и пробелов (как в JSONB) не нарушает целостность
+	snapshot.State = []byte(`{"a": "x", "b": 1}`)
+	if err := VerifySnapshot(snapshot, signer); err != nil {
+		t.Errorf("Expected valid snapshot, got %v", err)
+	}
+
+	snapshot.State = []byte(`{"a": "y", "b": 1}`)
+	if err := VerifySnapshot(snapshot, signer); !errors.Is(err, ErrSnapshotCorrupted) {
+		t.Errorf("Expected ErrSnapshotCorrupted, got %v", err)
+	}
+
+	snapshot.State = []byte(`{"a": "x", "b": 1}`)
+	if err := VerifySnapshot(snapshot, NewHMACSnapshotSigner([]byte("other"))); !errors.Is(err, ErrSnapshotCorrupted) {
+		t.Errorf("Expected signature mismatch, got %v", err)
+	}
+
+	// Подписанный снапшот, перенесенный на другой агрегат, тип или версию, отклоняется
+	for name, move := range map[string]func(*Snapshot){
+		"aggregate id":   func(s *Snapshot) { s.AggregateID = "test-2" },
+		"aggregate type": func(s *Snapshot) { s.AggregateType = "Account" },
+		"version":        func(s *Snapshot) { s.Version = 7 },
+	} {
+		moved := *snapshot
+		move(&moved)
+		if err := VerifySnapshot(&moved, signer); !errors.Is(err, ErrSnapshotCorrupted) {
+			t.Errorf("Expected snapshot with changed %s to fail verification, got %v", name, err)
+		}
+	}
+}
+
+// conflictingEventStore имитирует конкурентную запись перед первыми N вызовами AppendEvents
+type conflictingEventStore struct {
+	*InMemoryEventStore
+	conflicts int
+}
+
+func (s *conflictingEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, evts []events.Event) error {
+	if s.conflicts > 0 {
+		s.conflicts--
+		concurrent := &TestUpdatedEvent{BaseEvent: events.NewBaseEvent("test.updated", aggregateID), Value: 100}
+		if err := s.InMemoryEventStore.AppendEvents(ctx, aggregateID, expectedVersion, []events.Event{concurrent}); err != nil {
+			return err
+		}
+	}
+	return s.InMemoryEventStore.AppendEvents(ctx, aggregateID, expectedVersion, evts)
+}
+
+func TestEventSourcedRepository_UpdateRetriesOnConflict(t *testing.T) {
+	eventStore := &conflictingEventStore{InMemoryEventStore: NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())}
+	config := DefaultRepositoryConfig()
+	config.UseSnapshots = false
+	config.ConflictRetry = &ConflictRetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, BackoffMultiplier: 2}
+	repo := NewEventSourcedRepository[*TestAggregate](eventStore, nil, config, NewTestAggregate)
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, createTestAggregate("test-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	eventStore.conflicts = 2
+	calls := 0
+	updated, err := repo.Update(ctx, "test-1", func(ctx context.Context, agg *TestAggregate) error {
+		calls++
+		agg.RaiseEvent(&TestUpdatedEvent{BaseEvent: events.NewBaseEvent("test.updated", "test-1"), Value: agg.value + 1})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected update to succeed after retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected command to be applied 3 times, got %d", calls)
+	}
+	if updated.value != 101 {
+		t.Errorf("Expected value 101 computed from reloaded state, got %d", updated.value)
+	}
+
+	eventStore.conflicts = 5
+	_, err = repo.Update(ctx, "test-1", func(ctx context.Context, agg *TestAggregate) error {
+		agg.RaiseEvent(&TestUpdatedEvent{BaseEvent: events.NewBaseEvent("test.updated", "test-1"), Value: 1})
+		return nil
+	})
+	if !errors.Is(err, ErrConcurrencyConflict) {
+		t.Errorf("Expected ErrConcurrencyConflict after exhausting attempts, got %v", err)
+	}
+}
+
+// poisonTestAggregate агрегат, который не может применить события (поврежденный поток)
+type poisonTestAggregate struct {
+	*TestAggregate
+	failures *int
+}
+
+func (a *poisonTestAggregate) Apply(event events.Event) error {
+	*a.failures++
+	return errors.New("unknown event schema")
+}
+
+func TestEventSourcedRepository_QuarantinesPoisonAggregate(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	ctx := context.Background()
+	if err := eventStore.AppendEvents(ctx, "poison-1", 0, []events.Event{createTestAggregate("poison-1").GetUncommittedEvents()[0]}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	applyCalls := 0
+	var alerts []QuarantineInfo
+	config := DefaultRepositoryConfig()
+	config.UseSnapshots = false
+	config.Quarantine = &QuarantinePolicy{
+		FailureThreshold:  2,
+		Cooldown:          time.Minute,
+		BackoffMultiplier: 2,
+		OnQuarantine: func(ctx context.Context, info QuarantineInfo) {
+			alerts = append(alerts, info)
+		},
+	}
+	repo := NewEventSourcedRepository[*poisonTestAggregate](eventStore, nil, config, func(id string) *poisonTestAggregate {
+		return &poisonTestAggregate{TestAggregate: NewTestAggregate(id), failures: &applyCalls}
+	})
+
+	now := time.Now()
+	repo.Quarantine().now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetByID(ctx, "poison-1"); !errors.Is(err, ErrRehydrationFailed) {
+			t.Fatalf("Expected ErrRehydrationFailed, got %v", err)
+		}
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 quarantine alert, got %d", len(alerts))
+	}
+
+	// Загрузки в карантине не обращаются к хранилищу
+	_, err := repo.GetByID(ctx, "poison-1")
+	var quarantined *QuarantinedError
+	if !errors.As(err, &quarantined) || !errors.Is(err, ErrAggregateQuarantined) {
+		t.Fatalf("Expected QuarantinedError, got %v", err)
+	}
+	if applyCalls != 2 {
+		t.Errorf("Expected 2 apply attempts, got %d", applyCalls)
+	}
+
+	// После истечения карантина пробная загрузка снова неудачна - карантин удваивается
+	now = now.Add(time.Minute)
+	if _, err := repo.GetByID(ctx, "poison-1"); !errors.Is(err, ErrRehydrationFailed) {
+		t.Fatalf("Expected ErrRehydrationFailed on probe, got %v", err)
+	}
+	if len(alerts) != 2 || alerts[1].Until.Sub(now) != 2*time.Minute {
+		t.Errorf("Expected second quarantine for 2m, got %+v", alerts)
+	}
+
+	repo.Quarantine().Release("poison-1")
+	if len(repo.Quarantine().List()) != 0 {
+		t.Errorf("Expected no quarantined aggregates after release")
+	}
+}
+
+// undecodableEventStore возвращает ошибку десериализации событий, как SQL хранилища при сбое upcast
+type undecodableEventStore struct {
+	EventStore
+	err error
+}
+
+func (s undecodableEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	return nil, fmt.Errorf("%w: %w", ErrEventDeserializationFailed, s.err)
+}
+
+func TestEventSourcedRepository_QuarantinesUndecodableStream(t *testing.T) {
+	config := DefaultRepositoryConfig()
+	config.UseSnapshots = false
+	config.Quarantine = &QuarantinePolicy{FailureThreshold: 2, Cooldown: time.Minute, BackoffMultiplier: 2}
+	ctx := context.Background()
+
+	upcastErr := fmt.Errorf("%w: order.created v1: missing field", ErrUpcastFailed)
+	for name, store := range map[string]EventStore{
+		"deserialization": undecodableEventStore{EventStore: NewInMemoryEventStore(DefaultInMemoryEventStoreConfig()), err: errors.New("invalid character")},
+		"upcast":          undecodableEventStore{EventStore: NewInMemoryEventStore(DefaultInMemoryEventStoreConfig()), err: upcastErr},
+	} {
+		repo := NewEventSourcedRepository[*TestAggregate](store, nil, config, NewTestAggregate)
+		for i := 0; i < 2; i++ {
+			if _, err := repo.GetByID(ctx, "test-1"); !errors.Is(err, ErrEventDeserializationFailed) {
+				t.Fatalf("%s: expected ErrEventDeserializationFailed, got %v", name, err)
+			}
+		}
+		if _, err := repo.GetByID(ctx, "test-1"); !errors.Is(err, ErrAggregateQuarantined) {
+			t.Errorf("%s: expected QuarantinedError, got %v", name, err)
+		}
+	}
+
+	// Недоступность хранилища не помещает агрегат в карантин
+	repo := NewEventSourcedRepository[*TestAggregate](unreadableEventStore{NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())}, nil, config, NewTestAggregate)
+	for i := 0; i < 3; i++ {
+		if _, err := repo.GetByID(ctx, "test-1"); err == nil || errors.Is(err, ErrAggregateQuarantined) {
+			t.Fatalf("Expected store error, got %v", err)
+		}
+	}
+	if len(repo.Quarantine().List()) != 0 {
+		t.Error("Expected no quarantined aggregates after store errors")
+	}
+}
+
+func TestAggregateQuarantine_SingleProbe(t *testing.T) {
+	quarantine := NewAggregateQuarantine(QuarantinePolicy{FailureThreshold: 1, Cooldown: time.Minute, BackoffMultiplier: 2})
+	now := time.Now()
+	quarantine.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	quarantine.RecordFailure(ctx, "poison-1", ErrRehydrationFailed)
+	if err := quarantine.Check("poison-1"); !errors.Is(err, ErrAggregateQuarantined) {
+		t.Fatalf("Expected QuarantinedError, got %v", err)
+	}
+
+	// После карантина пробу получает только одна из одновременных загрузок
+	now = now.Add(time.Minute)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if quarantine.Check("poison-1") == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 1 {
+		t.Fatalf("Expected a single probe, got %d", allowed)
+	}
+
+	// Проба, прерванная ошибкой хранилища, освобождает место для следующей
+	quarantine.CancelProbe("poison-1")
+	if err := quarantine.Check("poison-1"); err != nil {
+		t.Fatalf("Expected probe after cancel, got %v", err)
+	}
+	if err := quarantine.Check("poison-1"); !errors.Is(err, ErrAggregateQuarantined) {
+		t.Fatalf("Expected QuarantinedError while probe runs, got %v", err)
+	}
+
+	// Потерянная проба истекает через Cooldown
+	now = now.Add(time.Minute)
+	if err := quarantine.Check("poison-1"); err != nil {
+		t.Fatalf("Expected new probe after stale one, got %v", err)
+	}
+
+	// Неудачная проба продлевает карантин
+	quarantine.RecordFailure(ctx, "poison-1", ErrRehydrationFailed)
+	if err := quarantine.Check("poison-1"); !errors.Is(err, ErrAggregateQuarantined) {
+		t.Fatalf("Expected QuarantinedError after failed probe, got %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if err := quarantine.Check("poison-1"); err != nil {
+		t.Fatalf("Expected probe after extended quarantine, got %v", err)
+	}
+	quarantine.RecordSuccess("poison-1")
+	if err := quarantine.Check("poison-1"); err != nil {
+		t.Errorf("Expected no quarantine after successful probe, got %v", err)
+	}
+}
+
+// testAggregateSerializer сериализует неэкспортируемое состояние TestAggregate
+type testAggregateSerializer struct{}
+
+func (s testAggregateSerializer) Serialize(aggregate interface{}) ([]byte, error) {
+	agg := aggregate.(*TestAggregate)
+	return json.Marshal(map[string]interface{}{"name": agg.name, "value": agg.value})
+}
+
+func (s testAggregateSerializer) Deserialize(data []byte, aggregate interface{}) error {
+	var state struct {
+		Name  string `json:"name"`
+		Value int    `json:"value"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	agg := aggregate.(*TestAggregate)
+	agg.name, agg.value = state.Name, state.Value
+	return nil
+}
+
+func TestEventSourcedRepository_AggregateCache(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	cache := NewLRUAggregateCache(AggregateCacheConfig{MaxSize: 10, TTL: time.Minute})
+	config := DefaultRepositoryConfig()
+	config.UseSnapshots = false
+	config.Serializer = testAggregateSerializer{}
+	config.Cache = cache
+	repo := NewEventSourcedRepository[*TestAggregate](eventStore, nil, config, NewTestAggregate)
+	ctx := context.Background()
+
+	agg := createTestAggregate("test-1")
+	if err := repo.Save(ctx, agg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, "test-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	entry, _ := cache.Get(ctx, "test-1")
+	if entry == nil || entry.Version != 1 {
+		t.Fatalf("Expected aggregate cached at version 1, got %+v", entry)
+	}
+
+	// Загрузка использует состояние из кэша
+	_ = cache.Set(ctx, CachedAggregate{AggregateID: "test-1", Version: 1, State: []byte(`{"name":"Cached","value":10}`)})
+	loaded, err := repo.GetByID(ctx, "test-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.name != "Cached" {
+		t.Errorf("Expected state from cache, got name=%q", loaded.name)
+	}
+
+	// События, записанные в обход репозитория, дочитываются поверх записи кэша
+	concurrent := &TestUpdatedEvent{BaseEvent: events.NewBaseEvent("test.updated", "test-1"), Value: 99}
+	if err := eventStore.AppendEvents(ctx, "test-1", 1, []events.Event{concurrent}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	loaded, err = repo.GetByID(ctx, "test-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.name != "Cached" || loaded.value != 99 || loaded.Version() != 2 {
+		t.Errorf("Expected caught-up aggregate {Cached 99} at version 2, got {%s %d} at %d", loaded.name, loaded.value, loaded.Version())
+	}
+	if entry, _ := cache.Get(ctx, "test-1"); entry == nil || entry.Version != 2 {
+		t.Errorf("Expected cache refreshed to version 2, got %+v", entry)
+	}
+
+	// Save инвалидирует запись
+	loaded.RaiseEvent(&TestUpdatedEvent{BaseEvent: events.NewBaseEvent("test.updated", "test-1"), Value: 100})
+	if err := repo.Save(ctx, loaded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if entry, _ := cache.Get(ctx, "test-1"); entry != nil {
+		t.Errorf("Expected cache invalidated on save, got %+v", entry)
+	}
+	loaded, err = repo.GetByID(ctx, "test-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.name != "Test" || loaded.value != 100 || loaded.Version() != 3 {
+		t.Errorf("Expected rehydrated aggregate {Test 100} at version 3, got {%s %d} at %d", loaded.name, loaded.value, loaded.Version())
+	}
+}
+
+func TestLRUAggregateCache_EvictionAndTTL(t *testing.T) {
+	cache := NewLRUAggregateCache(AggregateCacheConfig{MaxSize: 2, TTL: time.Minute})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, CachedAggregate{AggregateID: "a", Version: 1})
+	_ = cache.Set(ctx, CachedAggregate{AggregateID: "b", Version: 1})
+	_, _ = cache.Get(ctx, "a")
+	_ = cache.Set(ctx, CachedAggregate{AggregateID: "c", Version: 1})
+
+	if entry, _ := cache.Get(ctx, "b"); entry != nil {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Len())
+	}
+
+	// Более старая версия не перезаписывает новую
+	_ = cache.Set(ctx, CachedAggregate{AggregateID: "a", Version: 5})
+	_ = cache.Set(ctx, CachedAggregate{AggregateID: "a", Version: 3})
+	if entry, _ := cache.Get(ctx, "a"); entry == nil || entry.Version != 5 {
+		t.Errorf("Expected version 5, got %+v", entry)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if entry, _ := cache.Get(ctx, "a"); entry != nil {
+		t.Error("Expected expired entry to be removed")
+	}
+}
+
+func TestAggregateCache_TenantsShareAggregateID(t *testing.T) {
+	cache := NewLRUAggregateCache(AggregateCacheConfig{MaxSize: 10})
+	tenantA := WithTenant(context.Background(), "tenant-a")
+	tenantB := WithTenant(context.Background(), "tenant-b")
+
+	_ = cache.Set(tenantA, CachedAggregate{AggregateID: "order-1", Version: 1, State: []byte("a")})
+	if entry, _ := cache.Get(tenantB, "order-1"); entry != nil {
+		t.Fatalf("Expected no entry for another tenant, got %+v", entry)
+	}
+	_ = cache.Set(tenantB, CachedAggregate{AggregateID: "order-1", Version: 1, State: []byte("b")})
+	if entry, _ := cache.Get(tenantA, "order-1"); entry == nil || string(entry.State) != "a" {
+		t.Errorf("Expected tenant-a state, got %+v", entry)
+	}
+
+	_ = cache.Invalidate(tenantB, "order-1")
+	if entry, _ := cache.Get(tenantA, "order-1"); entry == nil {
+		t.Error("Expected invalidation to keep entry of another tenant")
+	}
+	if entry, _ := cache.Get(tenantB, "order-1"); entry != nil {
+		t.Errorf("Expected tenant-b entry invalidated, got %+v", entry)
+	}
+
+	redisCache := &RedisAggregateCache{config: DefaultRedisAggregateCacheConfig()}
+	if keyA, keyB := redisCache.key(tenantA, "order-1"), redisCache.key(tenantB, "order-1"); keyA == keyB {
+		t.Errorf("Expected distinct redis keys per tenant, got %q", keyA)
+	}
+	if key := redisCache.key(context.Background(), "order-1"); key != "potter:aggregate:order-1" {
+		t.Errorf("Expected key without tenant, got %q", key)
+	}
+}
+
+func TestEventSourcedRepository_SnapshotOnly(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	snapshotStore := NewInMemorySnapshotStore()
+	config := DefaultRepositoryConfig()
+	config.Serializer = testAggregateSerializer{}
+	config.SnapshotOnly = &SnapshotOnlyPolicy{}
+	repo := NewEventSourcedRepository[*TestAggregate](eventStore, snapshotStore, config, NewTestAggregate)
+	ctx := context.Background()
+
+	agg := createTestAggregate("cart-1")
+	for _, event := range generateEvents(2, "cart-1") {
+		agg.RaiseEvent(event)
+	}
+	if err := repo.Save(ctx, agg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// В потоке остается только последнее событие, состояние - в снапшоте
+	stored, _ := eventStore.GetEvents(ctx, "cart-1", 0)
+	if len(stored) != 1 || stored[0].Version != 3 {
+		t.Fatalf("Expected only last event (version 3) in stream, got %d events", len(stored))
+	}
+	loaded, err := repo.GetByID(ctx, "cart-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.name != "Test" || loaded.value != 11 || loaded.Version() != 3 {
+		t.Errorf("Expected {Test 11} at version 3, got {%s %d} at %d", loaded.name, loaded.value, loaded.Version())
+	}
+
+	loaded.RaiseEvent(&TestUpdatedEvent{BaseEvent: events.NewBaseEvent("test.updated", "cart-1"), Value: 50})
+	if err := repo.Save(ctx, loaded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored, _ := eventStore.GetEvents(ctx, "cart-1", 0); len(stored) != 1 || stored[0].Version != 4 {
+		t.Errorf("Expected only version 4 in stream, got %d events", len(stored))
+	}
+
+	// Без снапшота сокращенный поток не восстанавливается
+	withoutSnapshots := NewEventSourcedRepository[*TestAggregate](eventStore, NewInMemorySnapshotStore(), config, NewTestAggregate)
+	if _, err := withoutSnapshots.GetByID(ctx, "cart-1"); !errors.Is(err, ErrSnapshotRequired) {
+		t.Errorf("Expected ErrSnapshotRequired, got %v", err)
+	}
+
+	// События в окне хранения не удаляются
+	config.SnapshotOnly = &SnapshotOnlyPolicy{EventRetention: time.Hour}
+	retaining := NewEventSourcedRepository[*TestAggregate](eventStore, snapshotStore, config, NewTestAggregate)
+	if err := retaining.Save(ctx, createTestAggregate("cart-2")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cart, _ := retaining.GetByID(ctx, "cart-2")
+	cart.RaiseEvent(&TestUpdatedEvent{BaseEvent: events.NewBaseEvent("test.updated", "cart-2"), Value: 1})
+	if err := retaining.Save(ctx, cart); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored, _ := eventStore.GetEvents(ctx, "cart-2", 0); len(stored) != 2 {
+		t.Errorf("Expected events within retention window to be kept, got %d", len(stored))
+	}
+
+	if err := NewEventSourcedRepository[*TestAggregate](eventStore, nil, config, NewTestAggregate).Save(ctx, createTestAggregate("cart-3")); err == nil {
+		t.Error("Expected error without snapshot store")
+	}
+}
+
+func TestEventSourcedRepository_ConsistencyToken(t *testing.T) {
+	repo, eventStore, _ := createTestRepository()
+	checkpointStore := NewInMemoryCheckpointStore()
+	manager := NewProjectionManager(eventStore, checkpointStore)
+	if err := manager.Register(NewTestProjection("read-model")); err != nil {
+		t.Fatalf("Failed to register projection: %v", err)
+	}
+
+	ctx, tracker := core.TrackConsistency(context.Background())
+	if err := repo.Save(ctx, createTestAggregate("test-1")); err != nil {
+		t.Fatalf("Failed to save aggregate: %v", err)
+	}
+	token := tracker.Token()
+	if token <= 0 {
+		t.Fatalf("Expected positive consistency token, got %d", token)
+	}
+
+	parsed, err := core.ParseConsistencyToken(token.String())
+	if err != nil || parsed != token {
+		t.Fatalf("Expected token %d after round trip, got %d (%v)", token, parsed, err)
+	}
+
+	// Проекция отстает: ожидание ограничено таймаутом
+	queryCtx := core.WithConsistencyToken(context.Background(), token)
+	err = manager.WaitForContextToken(queryCtx, 30*time.Millisecond)
+	if !errors.Is(err, core.ErrConsistencyTimeout) {
+		t.Fatalf("Expected ErrConsistencyTimeout, got %v", err)
+	}
+
+	// Проекция догоняет позицию токена во время ожидания
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = checkpointStore.SaveCheckpoint(context.Background(), "read-model", int64(token))
+	}()
+	if err := manager.WaitForContextToken(queryCtx, time.Second); err != nil {
+		t.Fatalf("Expected projection to reach token, got %v", err)
+	}
+
+	// Без токена запрос не ждет
+	if err := manager.WaitForContextToken(context.Background(), 0); err != nil {
+		t.Fatalf("Expected no wait without token, got %v", err)
+	}
+}
+
+// unreadableEventStore записывает события, но не читает их
+type unreadableEventStore struct {
+	EventStore
+}
+
+func (s unreadableEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	return nil, errors.New("read replica unavailable")
+}
+
+func TestEventSourcedRepository_ConsistencyTokenLookupFailure(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	cache := NewLRUAggregateCache(AggregateCacheConfig{MaxSize: 10})
+	config := DefaultRepositoryConfig()
+	config.UseSnapshots = false
+	config.Cache = cache
+	repo := NewEventSourcedRepository[*TestAggregate](unreadableEventStore{eventStore}, nil, config, NewTestAggregate)
+
+	ctx, tracker := core.TrackConsistency(context.Background())
+	_ = cache.Set(ctx, CachedAggregate{AggregateID: "test-1", Version: 0})
+	aggregate := createTestAggregate("test-1")
+
+	// События уже записаны: ошибка чтения позиции не делает Save неуспешным
+	if err := repo.Save(ctx, aggregate); err != nil {
+		t.Fatalf("Expected save to succeed, got %v", err)
+	}
+	if len(aggregate.GetUncommittedEvents()) != 0 {
+		t.Error("Expected events to be marked as committed")
+	}
+	if entry, _ := cache.Get(ctx, "test-1"); entry != nil {
+		t.Errorf("Expected cache invalidated, got %+v", entry)
+	}
+	if token := tracker.Token(); token != 0 {
+		t.Errorf("Expected no consistency token, got %d", token)
+	}
+	if stored, err := eventStore.GetEvents(ctx, "test-1", 0); err != nil || len(stored) != 1 {
+		t.Errorf("Expected 1 stored event, got %d (%v)", len(stored), err)
+	}
+}
+
+// verifiableTestAggregate агрегат с сериализуемым состоянием; bonus имитирует изменение логики Apply
+type verifiableTestAggregate struct {
+	*EventSourcedAggregate
+	Value int
+	bonus int
+}
+
+func (a *verifiableTestAggregate) Apply(event events.Event) error {
+	switch e := event.(type) {
+	case *TestCreatedEvent:
+		a.Value = e.Value
+	case *TestUpdatedEvent:
+		a.Value = e.Value + a.bonus
+	}
+	return nil
+}
+
+func TestAggregateVerifier(t *testing.T) {
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	snapshotStore := NewInMemorySnapshotStore()
+	ctx := context.Background()
+
+	history := append([]events.Event{createTestAggregate("agg-1").GetUncommittedEvents()[0]}, generateEvents(2, "agg-1")...)
+	if err := eventStore.AppendEvents(ctx, "agg-1", 0, history); err != nil {
+		t.Fatalf("Failed to append events: %v", err)
+	}
+	// Снапшот версии 2, созданный исходной логикой Apply
+	state, _ := NewJSONSnapshotSerializer().Serialize(&verifiableTestAggregate{Value: 10})
+	if err := snapshotStore.SaveSnapshot(ctx, Snapshot{AggregateID: "agg-1", Version: 2, State: state}); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+
+	newVerifier := func(bonus int, readModelValue int) *AggregateVerifier[*verifiableTestAggregate] {
+		return NewAggregateVerifier(eventStore, func(id string) *verifiableTestAggregate {
+			return &verifiableTestAggregate{EventSourcedAggregate: NewEventSourcedAggregate(id), bonus: bonus}
+		}).
+			WithSnapshotStore(snapshotStore).
+			WithReadModel("summary", ReadModelComparatorFunc[*verifiableTestAggregate](
+				func(ctx context.Context, agg *verifiableTestAggregate) ([]Divergence, error) {
+					return CompareStates("", map[string]int{"value": agg.Value}, map[string]int{"value": readModelValue})
+				}))
+	}
+
+	verification, err := newVerifier(0, 11).Verify(ctx, "agg-1")
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if verification.Diverged() || verification.Error != nil {
+		t.Fatalf("Expected no divergences, got %v (error %v)", verification.Divergences, verification.Error)
+	}
+	if verification.Version != 3 || verification.SnapshotVersion != 2 {
+		t.Errorf("Expected version 3 and snapshot version 2, got %d and %d", verification.Version, verification.SnapshotVersion)
+	}
+
+	// Измененная логика Apply расходится со снапшотом и read model
+	verification, err = newVerifier(1, 11).Verify(ctx, "agg-1")
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	sources := make(map[string]Divergence)
+	for _, divergence := range verification.Divergences {
+		sources[divergence.Source] = divergence
+	}
+	if d, ok := sources[DivergenceSourceSnapshot]; !ok || d.Path != "Value" || d.Expected != float64(11) || d.Actual != float64(10) {
+		t.Errorf("Expected snapshot divergence on Value, got %v", verification.Divergences)
+	}
+	if d, ok := sources["summary"]; !ok || d.Path != "value" {
+		t.Errorf("Expected read model divergence on value, got %v", verification.Divergences)
+	}
+
+	report, err := newVerifier(1, 11).VerifyAll(ctx, AggregateFilter{})
+	if err != nil {
+		t.Fatalf("VerifyAll failed: %v", err)
+	}
+	if len(report.Aggregates) != 1 || report.Diverged != 1 || report.Verified != 0 {
+		t.Errorf("Expected 1 diverged aggregate, got %+v", report)
+	}
+}
+
+func TestSnapshotAdvisor_RecommendsFrequencyFromLoadProfile(t *testing.T) {
+	ctx := context.Background()
+	eventStore := NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())
+	tracker := NewLoadProfileTracker()
+
+	config := DefaultRepositoryConfig()
+	config.UseSnapshots = false
+	config.LoadCounter = tracker.For("test")
+	repo := NewEventSourcedRepository[*TestAggregate](eventStore, nil, config, NewTestAggregate)
+
+	newAggregate := func(id, aggregateType string, count int) *TestAggregate {
+		agg := NewTestAggregate(id)
+		for i := 0; i < count; i++ {
+			agg.RaiseEvent(&TestUpdatedEvent{
+				BaseEvent: events.NewBaseEvent("test.updated", id).WithMetadata("aggregate_type", aggregateType),
+				Value:     i,
+			})
+		}
+		return agg
+	}
+
+	// Длинные потоки, которые часто загружаются
+	for _, id := range []string{"test-1", "test-2", "test-3"} {
+		if err := repo.Save(ctx, newAggregate(id, "test", 200)); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		for i := 0; i < 10; i++ {
+			if _, err := repo.GetByID(ctx, id); err != nil {
+				t.Fatalf("GetByID failed: %v", err)
+			}
+		}
+	}
+	// Короткие потоки без наблюдаемой нагрузки
+	for _, id := range []string{"short-1", "short-2"} {
+		if err := eventStore.AppendEvents(ctx, id, 0, newAggregate(id, "short", 2).GetUncommittedEvents()); err != nil {
+			t.Fatalf("AppendEvents failed: %v", err)
+		}
+	}
+
+	stats := tracker.Stats()["test"]
+	if stats.Reads != 30 || stats.Writes != 3 || stats.EventsWritten != 600 || stats.EventsReplayed != 6000 {
+		t.Fatalf("Unexpected load stats: %+v", stats)
+	}
+
+	advisor := NewSnapshotAdvisor(eventStore, DefaultSnapshotAdvisorConfig()).WithLoadTracker(tracker)
+	if got := advisor.Frequency("test", 100)(); got != 100 {
+		t.Errorf("Expected fallback frequency before analysis, got %d", got)
+	}
+
+	advice, err := advisor.Analyze(ctx, AggregateFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(advice.Recommendations) != 2 {
+		t.Fatalf("Expected 2 recommendations, got %d", len(advice.Recommendations))
+	}
+
+	recommendation, ok := advice.For("test")
+	if !ok {
+		t.Fatal("Expected recommendation for test aggregates")
+	}
+	// sqrt(2 * 20 * 600 / 30) = 28.28
+	if !recommendation.UseSnapshots || recommendation.Frequency != 28 {
+		t.Errorf("Expected snapshots every 28 events, got %+v", recommendation)
+	}
+	if !recommendation.Profile.Observed || recommendation.Profile.Aggregates != 3 || recommendation.Profile.P95StreamLength != 200 {
+		t.Errorf("Unexpected profile: %+v", recommendation.Profile)
+	}
+	if ratio := recommendation.Profile.ReadWriteRatio(); ratio != 10 {
+		t.Errorf("Expected read/write ratio 10, got %v", ratio)
+	}
+
+	short, ok := advice.For("short")
+	if !ok || short.UseSnapshots || short.Frequency != 0 {
+		t.Errorf("Expected no snapshots for short streams, got %+v", short)
+	}
+
+	applied := DefaultRepositoryConfig()
+	if !advice.Apply("test", &applied) {
+		t.Fatal("Expected recommendation to be applied")
+	}
+	if !applied.UseSnapshots || applied.SnapshotFrequency != 28 || !applied.SnapshotStrategy.ShouldCreateSnapshot(nil, 56) {
+		t.Errorf("Unexpected applied config: %+v", applied)
+	}
+	if advice.Apply("missing", &applied) {
+		t.Error("Expected no recommendation for unknown aggregate type")
+	}
+
+	if got := advisor.Frequency("test", 100)(); got != 28 {
+		t.Errorf("Expected dynamic frequency 28, got %d", got)
+	}
+	if got := advisor.Frequency("short", 100)(); got != 0 {
+		t.Errorf("Expected snapshots disabled for short streams, got %d", got)
+	}
+}
+
+// pageCountingStore считает страницы потокового чтения и максимальный размер
+// загруженной в память части потока
+type pageCountingStore struct {
+	*InMemoryEventStore
+	pages   int
+	maxHeld int
+}
+
+func (s *pageCountingStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	stored, err := s.InMemoryEventStore.GetEvents(ctx, aggregateID, fromVersion)
+	s.hold(len(stored))
+	return stored, err
+}
+
+func (s *pageCountingStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	stored, err := s.InMemoryEventStore.GetEventsPage(ctx, aggregateID, fromVersion, limit)
+	s.pages++
+	s.hold(len(stored))
+	return stored, err
+}
+
+func (s *pageCountingStore) hold(n int) {
+	if n > s.maxHeld {
+		s.maxHeld = n
+	}
+}
+
+// materializedStore скрывает EventPageReader хранилища (чтение потока целиком)
+type materializedStore struct {
+	EventStore
+}
+
+func (s materializedStore) TenantIsolation() bool {
+	return SupportsTenantIsolation(s.EventStore)
+}
+
+func TestStreamEvents_ReadsInBatches(t *testing.T) {
+	ctx := context.Background()
+	store := &pageCountingStore{InMemoryEventStore: NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())}
+	if err := store.AppendEvents(ctx, "agg-1", 0, generateEvents(25, "agg-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stream := StreamEvents(ctx, store, "agg-1", 0, 10)
+	var versions []int64
+	for stream.Next() {
+		versions = append(versions, stream.Event().Version)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(versions) != 25 || versions[0] != 1 || versions[24] != 25 || stream.Read() != 25 {
+		t.Fatalf("Expected versions 1..25, got %v", versions)
+	}
+	// Три пачки событий и пустая пачка - конец потока
+	if store.pages != 4 || store.maxHeld != 10 {
+		t.Errorf("Expected 4 pages of at most 10 events, got %d pages, max %d", store.pages, store.maxHeld)
+	}
+
+	// Чтение с версии, хвост потока ровно по границе пачки
+	stream = StreamEvents(ctx, store, "agg-1", 16, 10)
+	count := 0
+	for stream.Next() {
+		count++
+	}
+	if stream.Err() != nil || count != 10 {
+		t.Errorf("Expected 10 events from version 16, got %d (%v)", count, stream.Err())
+	}
+
+	stream = StreamEvents(ctx, store, "missing", 0, 10)
+	if stream.Next() || !errors.Is(stream.Err(), ErrStreamNotFound) {
+		t.Errorf("Expected ErrStreamNotFound for missing stream, got %v", stream.Err())
+	}
+
+	// Хранилище без EventPageReader читается одним вызовом GetEvents
+	stream = StreamEvents(ctx, materializedStore{store}, "agg-1", 0, 10)
+	count = 0
+	for stream.Next() {
+		count++
+	}
+	if stream.Err() != nil || count != 25 {
+		t.Errorf("Expected 25 events from materialized store, got %d (%v)", count, stream.Err())
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	stream = StreamEvents(cancelled, store, "agg-1", 0, 10)
+	if stream.Next() || !errors.Is(stream.Err(), context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", stream.Err())
+	}
+}
+
+// cappedPageStore отдает страницы не больше maxPage событий независимо от запрошенного размера
+type cappedPageStore struct {
+	*pageCountingStore
+	maxPage int
+}
+
+func (s cappedPageStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	return s.pageCountingStore.GetEventsPage(ctx, aggregateID, fromVersion, s.maxPage)
+}
+
+func TestStreamEvents_TenantStorePages(t *testing.T) {
+	ctx := context.Background()
+	inner := &pageCountingStore{InMemoryEventStore: NewInMemoryEventStore(DefaultInMemoryEventStoreConfig()).WithTenantResolver(ContextTenantResolver(true))}
+	store := NewTenantEventStore(inner, "tenant-1")
+	if err := store.AppendEvents(ctx, "agg-1", 0, generateEvents(25, "agg-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Тенант передает чтение страницами хранилищу
+	stream := StreamEvents(ctx, store, "agg-1", 0, 10)
+	count := 0
+	for stream.Next() {
+		count++
+	}
+	if stream.Err() != nil || count != 25 {
+		t.Fatalf("Expected 25 events, got %d (%v)", count, stream.Err())
+	}
+	if inner.pages != 4 || inner.maxHeld != 10 {
+		t.Errorf("Expected 4 pages of at most 10 events, got %d pages, max %d", inner.pages, inner.maxHeld)
+	}
+
+	// Неполная пачка не завершает поток: хранилище может ограничивать размер страницы
+	inner.pages = 0
+	stream = StreamEvents(ctx, NewTenantEventStore(cappedPageStore{inner, 4}, "tenant-1"), "agg-1", 0, 10)
+	count = 0
+	for stream.Next() {
+		count++
+	}
+	if stream.Err() != nil || count != 25 || inner.pages != 8 {
+		t.Errorf("Expected 25 events in 8 pages, got %d in %d (%v)", count, inner.pages, stream.Err())
+	}
+
+	page, err := store.GetEventsPage(ctx, "agg-1", 5, 3)
+	if err != nil || len(page) != 3 || page[0].Version != 5 {
+		t.Errorf("Expected 3 events from version 5, got %d (%v)", len(page), err)
+	}
+	// Страница хранилища без EventPageReader ограничена запрошенным размером
+	page, err = NewTenantEventStore(materializedStore{inner}, "tenant-1").GetEventsPage(ctx, "agg-1", 5, 3)
+	if err != nil || len(page) != 3 || page[2].Version != 7 {
+		t.Errorf("Expected versions 5..7 from materialized store, got %d (%v)", len(page), err)
+	}
+}
+
+func TestEventSourcedRepository_GetByIDStreamsEvents(t *testing.T) {
+	ctx := context.Background()
+	store := &pageCountingStore{InMemoryEventStore: NewInMemoryEventStore(DefaultInMemoryEventStoreConfig())}
+	config := DefaultRepositoryConfig()
+	config.UseSnapshots = false
+	config.StreamBatchSize = 10
+	repo := NewEventSourcedRepository[*TestAggregate](store, nil, config, NewTestAggregate)
+
+	if err := repo.Save(ctx, createTestAggregate("agg-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.AppendEvents(ctx, "agg-1", 1, generateEvents(24, "agg-1")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded, err := repo.GetByID(ctx, "agg-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.Version() != 25 || loaded.name != "Test" || loaded.value != 33 {
+		t.Errorf("Unexpected aggregate state: version %d, name %q, value %d", loaded.Version(), loaded.name, loaded.value)
+	}
+	if store.maxHeld > 10 {
+		t.Errorf("Expected at most 10 events in memory, got %d", store.maxHeld)
+	}
+
+	// GetVersion и Exists не загружают поток целиком
+	if version, err := repo.GetVersion(ctx, "agg-1"); err != nil || version != 25 {
+		t.Errorf("Expected version 25, got %d (%v)", version, err)
+	}
+	if exists, err := repo.Exists(ctx, "agg-1"); err != nil || !exists {
+		t.Errorf("Expected aggregate to exist, got %v (%v)", exists, err)
+	}
+	if store.maxHeld > 10 {
+		t.Errorf("Expected at most 10 events in memory, got %d", store.maxHeld)
+	}
+	if version, err := repo.GetVersion(ctx, "missing"); err != nil || version != 0 {
+		t.Errorf("Expected version 0 for missing aggregate, got %d (%v)", version, err)
+	}
+	if exists, err := repo.Exists(ctx, "missing"); err != nil || exists {
+		t.Errorf("Expected missing aggregate not to exist, got %v (%v)", exists, err)
+	}
+
+	if _, err := repo.GetByID(ctx, "missing"); err == nil {
+		t.Error("Expected error for missing aggregate")
+	}
+}
+
+// BenchmarkEventSourcedRepository_Rehydrate сравнивает восстановление агрегата из
+// материализованного потока и потоковым чтением: max-events-held растет с длиной
+// потока при GetEvents и ограничен размером пачки при StreamEvents
+func BenchmarkEventSourcedRepository_Rehydrate(b *testing.B) {
+	for _, length := range []int{1000, 10000, 100000} {
+		ctx := context.Background()
+		inner := NewInMemoryEventStore(InMemoryEventStoreConfig{})
+		if err := inner.AppendEvents(ctx, "agg-1", 0, generateEvents(length, "agg-1")); err != nil {
+			b.Fatal(err)
+		}
+
+		for _, mode := range []string{"materialized", "streamed"} {
+			b.Run(fmt.Sprintf("%s/events=%d", mode, length), func(b *testing.B) {
+				counting := &pageCountingStore{InMemoryEventStore: inner}
+				var store EventStore = counting
+				if mode == "materialized" {
+					store = materializedStore{counting}
+				}
+				config := DefaultRepositoryConfig()
+				config.UseSnapshots = false
+				repo := NewEventSourcedRepository[*TestAggregate](store, nil, config, NewTestAggregate)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := repo.GetByID(ctx, "agg-1"); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(counting.maxHeld), "max-events-held")
+			})
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// ErrMultiStoreGlobalLog глобальный лог RoutingEventStore с несколькими физическими хранилищами
+// не имеет единой последовательности позиций
+var ErrMultiStoreGlobalLog = errors.New("global event log spans multiple event stores")
+
+// AggregateTypeResolver определяет тип агрегата по его ID.
+// Методы чтения EventStore получают только aggregateID, поэтому маршрутизация
+// выполняется по ID (например, по префиксу "telemetry-42").
+type AggregateTypeResolver func(aggregateID string) string
+
+// NewPrefixTypeResolver создает resolver, использующий часть ID до separator как тип агрегата
+func NewPrefixTypeResolver(separator string) AggregateTypeResolver {
+	return func(aggregateID string) string {
+		if idx := strings.Index(aggregateID, separator); idx > 0 {
+			return aggregateID[:idx]
+		}
+		return ""
+	}
+}
+
+// RoutingEventStore направляет агрегаты разных типов в разные физические хранилища
+// (например, телеметрию в MongoDB, финансовые агрегаты в PostgreSQL) за единым интерфейсом EventStore.
+//
+// Позиции глобального лога (StoredEvent.Position) у каждого хранилища свои, поэтому
+// GetAllEvents доступен, только если все маршруты ведут в одно хранилище. Проекции,
+// репликация и подписки с checkpoint'ами подключаются к каждому физическому
+// хранилищу отдельно (см. Stores) со своим checkpoint'ом.
+type RoutingEventStore struct {
+	mu           sync.RWMutex
+	routes       map[string]EventStore
+	defaultStore EventStore
+	resolver     AggregateTypeResolver
+}
+
+// NewRoutingEventStore создает новый RoutingEventStore.
+// defaultStore используется для типов агрегатов без явного маршрута.
+func NewRoutingEventStore(defaultStore EventStore, resolver AggregateTypeResolver) *RoutingEventStore {
+	return &RoutingEventStore{
+		routes:       make(map[string]EventStore),
+		defaultStore: defaultStore,
+		resolver:     resolver,
+	}
+}
+
+// WithRoute направляет агрегаты типа aggregateType в store
+func (s *RoutingEventStore) WithRoute(aggregateType string, store EventStore) *RoutingEventStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[aggregateType] = store
+	return s
+}
+
+// StoreFor возвращает хранилище для агрегата
+func (s *RoutingEventStore) StoreFor(aggregateID string) (EventStore, error) {
+	aggregateType := ""
+	if s.resolver != nil {
+		aggregateType = s.resolver(aggregateID)
+	}
+	return s.storeForType(aggregateType, aggregateID)
+}
+
+// storeForType возвращает хранилище для типа агрегата
+func (s *RoutingEventStore) storeForType(aggregateType, aggregateID string) (EventStore, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if store, ok := s.routes[aggregateType]; ok {
+		return store, nil
+	}
+	if s.defaultStore == nil {
+		return nil, fmt.Errorf("no event store route for aggregate %s (type %q)", aggregateID, aggregateType)
+	}
+	return s.defaultStore, nil
+}
+
+// Stores возвращает все физические хранилища (без дубликатов)
+func (s *RoutingEventStore) Stores() []EventStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	types := make([]string, 0, len(s.routes))
+	for aggregateType := range s.routes {
+		types = append(types, aggregateType)
+	}
+	sort.Strings(types)
+
+	stores := make([]EventStore, 0, len(s.routes)+1)
+	seen := make(map[EventStore]bool)
+	if s.defaultStore != nil {
+		stores = append(stores, s.defaultStore)
+		seen[s.defaultStore] = true
+	}
+	for _, aggregateType := range types {
+		store := s.routes[aggregateType]
+		if !seen[store] {
+			stores = append(stores, store)
+			seen[store] = true
+		}
+	}
+	return stores
+}
+
+// AppendEvents добавляет события в хранилище, соответствующее типу агрегата.
+// Хранилище выбирается только по ID (см. StoreFor), как и при чтении: маршрут по
+// метаданным событий записывал бы агрегат туда, где GetEvents его не найдет.
+func (s *RoutingEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, evts []events.Event) error {
+	store, err := s.StoreFor(aggregateID)
+	if err != nil {
+		return err
+	}
+	return store.AppendEvents(ctx, aggregateID, expectedVersion, evts)
+}
+
+// AppendEventsBatch распределяет пакет по хранилищам типов агрегатов.
+// Атомарность гарантируется только в пределах одного хранилища.
+func (s *RoutingEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	var stores []EventStore
+	grouped := make(map[EventStore][]EventBatch)
+	for _, batch := range batches {
+		store, err := s.StoreFor(batch.AggregateID)
+		if err != nil {
+			return err
+		}
+		if _, exists := grouped[store]; !exists {
+			stores = append(stores, store)
+		}
+		grouped[store] = append(grouped[store], batch)
+	}
+
+	for _, store := range stores {
+		if err := AppendEventsBatch(ctx, store, grouped[store]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEvents возвращает события агрегата из его хранилища
+func (s *RoutingEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	store, err := s.StoreFor(aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetEvents(ctx, aggregateID, fromVersion)
+}
+
+// GetEventsPage возвращает страницу событий агрегата из его хранилища (реализация EventPageReader)
+func (s *RoutingEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	store, err := s.StoreFor(aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	return GetEventsPage(ctx, store, aggregateID, fromVersion, limit)
+}
+
+// TenantIsolation проверяет, что все хранилища маршрутов разделяют данные тенантов
+// (реализация TenantIsolatedStore)
+func (s *RoutingEventStore) TenantIsolation() bool {
+	stores := s.Stores()
+	for _, store := range stores {
+		if !SupportsTenantIsolation(store) {
+			return false
+		}
+	}
+	return len(stores) > 0
+}
+
+// GetEventsByType возвращает события определенного типа из всех хранилищ, упорядоченные по времени
+func (s *RoutingEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	var result []StoredEvent
+	for _, store := range s.Stores() {
+		stored, err := store.GetEventsByType(ctx, eventType, fromTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored...)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].OccurredAt.Before(result[j].OccurredAt)
+	})
+	return result, nil
+}
+
+// ListAggregates объединяет страницы агрегатов всех хранилищ в порядке ID
+func (s *RoutingEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	limit := aggregatePageLimit(filter)
+
+	var aggregates []AggregateSummary
+	hasMore := false
+	for _, store := range s.Stores() {
+		page, err := ListAggregates(ctx, store, filter)
+		if err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, page.Aggregates...)
+		hasMore = hasMore || page.NextCursor != ""
+	}
+
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].AggregateID < aggregates[j].AggregateID
+	})
+	page := newAggregatePage(aggregates, limit)
+	if page.NextCursor == "" && hasMore && len(page.Aggregates) > 0 {
+		page.NextCursor = page.Aggregates[len(page.Aggregates)-1].AggregateID
+	}
+	return page, nil
+}
+
+// GetAllEvents возвращает глобальный лог единственного физического хранилища.
+// Для нескольких хранилищ возвращает ErrMultiStoreGlobalLog: позиции разных хранилищ
+// независимы, и потребитель, сохраняющий checkpoint по позиции, пропускал бы или
+// повторно получал события. Читайте лог каждого хранилища из Stores отдельно.
+func (s *RoutingEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	stores := s.Stores()
+	if len(stores) != 1 {
+		return nil, fmt.Errorf("%w: read each store from Stores() with its own checkpoint", ErrMultiStoreGlobalLog)
+	}
+	return stores[0].GetAllEvents(ctx, fromPosition)
+}
@@ -14,6 +14,10 @@ type Snapshot struct {
 	State        []byte
 	Metadata     map[string]interface{}
 	CreatedAt    time.Time
+	// Checksum SHA-256 контрольная сумма состояния (заполняется хранилищем)
+	Checksum string
+	// Signature опциональная подпись контрольной суммы, идентификатора, типа и версии агрегата
+	Signature string
 }
 
 // SnapshotStore интерфейс для хранения снапшотов
@@ -81,6 +85,26 @@ func (s *FrequencySnapshotStrategy) ShouldCreateSnapshot(aggregate AggregateInte
 	return eventCount > 0 && eventCount%s.Frequency == 0
 }
 
+// DynamicFrequencySnapshotStrategy создает снапшот каждые N событий, где N читается
+// при каждой проверке и может изменяться во время работы (например config.Value[int64].Get)
+type DynamicFrequencySnapshotStrategy struct {
+	frequency func() int64
+}
+
+// NewDynamicFrequencySnapshotStrategy создает стратегию с изменяемой частотой
+func NewDynamicFrequencySnapshotStrategy(frequency func() int64) *DynamicFrequencySnapshotStrategy {
+	return &DynamicFrequencySnapshotStrategy{frequency: frequency}
+}
+
+// ShouldCreateSnapshot проверяет, нужно ли создать снапшот
+func (s *DynamicFrequencySnapshotStrategy) ShouldCreateSnapshot(aggregate AggregateInterface, eventCount int64) bool {
+	frequency := s.frequency()
+	if frequency <= 0 {
+		return false
+	}
+	return eventCount > 0 && eventCount%frequency == 0
+}
+
 // TimeBasedSnapshotStrategy создает снапшот по времени
 type TimeBasedSnapshotStrategy struct {
 	Interval time.Duration
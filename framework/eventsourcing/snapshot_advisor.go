@@ -0,0 +1,424 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AggregateLoadCounter счетчик нагрузки репозитория одного типа агрегата: загрузки,
+// сохранения и количество событий, примененных при восстановлении состояния.
+// Подключается через RepositoryConfig.LoadCounter.
+type AggregateLoadCounter struct {
+	aggregateType  string
+	reads          atomic.Int64
+	writes         atomic.Int64
+	eventsWritten  atomic.Int64
+	eventsReplayed atomic.Int64
+}
+
+// AggregateType возвращает тип агрегата счетчика
+func (c *AggregateLoadCounter) AggregateType() string {
+	return c.aggregateType
+}
+
+// RecordRead учитывает загрузку агрегата с применением replayed событий
+func (c *AggregateLoadCounter) RecordRead(replayed int64) {
+	c.reads.Add(1)
+	c.eventsReplayed.Add(replayed)
+}
+
+// RecordWrite учитывает сохранение агрегата с events новыми событиями
+func (c *AggregateLoadCounter) RecordWrite(events int64) {
+	c.writes.Add(1)
+	c.eventsWritten.Add(events)
+}
+
+// LoadStats возвращает накопленные значения счетчика
+func (c *AggregateLoadCounter) LoadStats() AggregateLoadStats {
+	return AggregateLoadStats{
+		Reads:          c.reads.Load(),
+		Writes:         c.writes.Load(),
+		EventsWritten:  c.eventsWritten.Load(),
+		EventsReplayed: c.eventsReplayed.Load(),
+	}
+}
+
+// reset обнуляет счетчик
+func (c *AggregateLoadCounter) reset() {
+	c.reads.Store(0)
+	c.writes.Store(0)
+	c.eventsWritten.Store(0)
+	c.eventsReplayed.Store(0)
+}
+
+// AggregateLoadStats нагрузка репозитория типа агрегата за период наблюдения
+type AggregateLoadStats struct {
+	// Reads количество загрузок (GetByID)
+	Reads int64
+	// Writes количество сохранений (Save)
+	Writes int64
+	// EventsWritten количество записанных событий
+	EventsWritten int64
+	// EventsReplayed количество событий, примененных при загрузках (после снапшота или кэша)
+	EventsReplayed int64
+}
+
+// LoadProfileTracker собирает нагрузку репозиториев по типам агрегатов для SnapshotAdvisor
+type LoadProfileTracker struct {
+	mu       sync.RWMutex
+	counters map[string]*AggregateLoadCounter
+	since    time.Time
+}
+
+// NewLoadProfileTracker создает новый LoadProfileTracker
+func NewLoadProfileTracker() *LoadProfileTracker {
+	return &LoadProfileTracker{
+		counters: make(map[string]*AggregateLoadCounter),
+		since:    time.Now(),
+	}
+}
+
+// For возвращает счетчик типа агрегата. aggregateType должен совпадать с типом агрегата
+// в хранилище событий (метаданные aggregate_type), чтобы нагрузка сопоставлялась с потоками.
+func (t *LoadProfileTracker) For(aggregateType string) *AggregateLoadCounter {
+	t.mu.RLock()
+	counter, ok := t.counters[aggregateType]
+	t.mu.RUnlock()
+	if ok {
+		return counter
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if counter, ok := t.counters[aggregateType]; ok {
+		return counter
+	}
+	counter = &AggregateLoadCounter{aggregateType: aggregateType}
+	t.counters[aggregateType] = counter
+	return counter
+}
+
+// Stats возвращает нагрузку по типам агрегатов
+func (t *LoadProfileTracker) Stats() map[string]AggregateLoadStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make(map[string]AggregateLoadStats, len(t.counters))
+	for aggregateType, counter := range t.counters {
+		result[aggregateType] = counter.LoadStats()
+	}
+	return result
+}
+
+// Since возвращает начало периода наблюдения
+func (t *LoadProfileTracker) Since() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.since
+}
+
+// Reset обнуляет счетчики и начинает новый период наблюдения
+func (t *LoadProfileTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, counter := range t.counters {
+		counter.reset()
+	}
+	t.since = time.Now()
+}
+
+// AggregateLoadProfile профиль нагрузки типа агрегата: статистика потоков хранилища
+// и нагрузка репозитория (если подключен LoadProfileTracker)
+type AggregateLoadProfile struct {
+	AggregateType string
+	// Aggregates количество потоков агрегатов
+	Aggregates int64
+	// TotalEvents количество событий во всех потоках
+	TotalEvents int64
+	// AvgStreamLength средняя длина потока
+	AvgStreamLength float64
+	// P95StreamLength 95-й перцентиль длины потока
+	P95StreamLength int64
+	// MaxStreamLength максимальная длина потока
+	MaxStreamLength int64
+	// Load нагрузка репозитория за период наблюдения (нули без LoadProfileTracker)
+	Load AggregateLoadStats
+	// Observed нагрузка получена от LoadProfileTracker; иначе соотношение чтений и записей
+	// взято из SnapshotAdvisorConfig.AssumedReadsPerEvent
+	Observed bool
+}
+
+// ReadWriteRatio возвращает отношение загрузок к сохранениям (0 - нет данных о записях)
+func (p AggregateLoadProfile) ReadWriteRatio() float64 {
+	if p.Load.Writes == 0 {
+		return 0
+	}
+	return float64(p.Load.Reads) / float64(p.Load.Writes)
+}
+
+// AvgReplayedPerRead возвращает среднее число событий, применяемых при загрузке
+func (p AggregateLoadProfile) AvgReplayedPerRead() float64 {
+	if p.Load.Reads == 0 {
+		return 0
+	}
+	return float64(p.Load.EventsReplayed) / float64(p.Load.Reads)
+}
+
+// SnapshotRecommendation рекомендация стратегии снапшотов для типа агрегата
+type SnapshotRecommendation struct {
+	AggregateType string
+	Profile       AggregateLoadProfile
+	// UseSnapshots нужны ли снапшоты
+	UseSnapshots bool
+	// Frequency рекомендуемая частота снапшотов в событиях (0 если снапшоты не нужны)
+	Frequency int64
+	// Reason объяснение рекомендации
+	Reason string
+}
+
+// Strategy возвращает стратегию снапшотов рекомендации (nil если снапшоты не нужны)
+func (r SnapshotRecommendation) Strategy() SnapshotStrategy {
+	if !r.UseSnapshots {
+		return nil
+	}
+	return NewFrequencySnapshotStrategy(r.Frequency)
+}
+
+// Apply применяет рекомендацию к конфигурации репозитория типа агрегата
+func (r SnapshotRecommendation) Apply(config *RepositoryConfig) {
+	config.UseSnapshots = r.UseSnapshots
+	config.SnapshotFrequency = int(r.Frequency)
+	if r.UseSnapshots {
+		config.SnapshotStrategy = r.Strategy()
+	} else {
+		config.SnapshotStrategy = NewFrequencySnapshotStrategy(0)
+	}
+}
+
+// SnapshotAdvice результат анализа SnapshotAdvisor
+type SnapshotAdvice struct {
+	GeneratedAt time.Time
+	// Recommendations рекомендации в порядке типов агрегатов
+	Recommendations []SnapshotRecommendation
+}
+
+// For возвращает рекомендацию для типа агрегата
+func (a *SnapshotAdvice) For(aggregateType string) (SnapshotRecommendation, bool) {
+	for _, recommendation := range a.Recommendations {
+		if recommendation.AggregateType == aggregateType {
+			return recommendation, true
+		}
+	}
+	return SnapshotRecommendation{}, false
+}
+
+// Apply применяет рекомендацию для типа агрегата к конфигурации репозитория.
+// Возвращает false, если рекомендации для типа нет (конфигурация не изменяется).
+func (a *SnapshotAdvice) Apply(aggregateType string, config *RepositoryConfig) bool {
+	recommendation, ok := a.For(aggregateType)
+	if !ok {
+		return false
+	}
+	recommendation.Apply(config)
+	return true
+}
+
+// SnapshotAdvisorConfig параметры модели стоимости SnapshotAdvisor.
+//
+// Загрузка агрегата со снапшотом каждые F событий применяет в среднем F/2 событий,
+// а каждые F записанных событий стоят одного снапшота. Суммарная стоимость
+// Reads*F/2 + EventsWritten*SnapshotCost/F минимальна при F = sqrt(2*SnapshotCost*EventsWritten/Reads).
+type SnapshotAdvisorConfig struct {
+	// SnapshotCost стоимость записи снапшота относительно применения одного события
+	SnapshotCost float64
+	// MinFrequency минимальная рекомендуемая частота
+	MinFrequency int64
+	// MaxFrequency максимальная рекомендуемая частота
+	MaxFrequency int64
+	// AssumedReadsPerEvent предполагаемое число загрузок на записанное событие для типов
+	// без наблюдаемой нагрузки (обычно команда загружает агрегат и записывает одно событие)
+	AssumedReadsPerEvent float64
+}
+
+// DefaultSnapshotAdvisorConfig возвращает параметры по умолчанию
+func DefaultSnapshotAdvisorConfig() SnapshotAdvisorConfig {
+	return SnapshotAdvisorConfig{
+		SnapshotCost:         20,
+		MinFrequency:         10,
+		MaxFrequency:         1000,
+		AssumedReadsPerEvent: 1,
+	}
+}
+
+// Validate проверяет параметры
+func (c SnapshotAdvisorConfig) Validate() error {
+	if c.SnapshotCost <= 0 {
+		return fmt.Errorf("snapshot cost must be positive")
+	}
+	if c.MinFrequency <= 0 || c.MaxFrequency < c.MinFrequency {
+		return fmt.Errorf("invalid frequency bounds: min=%d max=%d", c.MinFrequency, c.MaxFrequency)
+	}
+	if c.AssumedReadsPerEvent <= 0 {
+		return fmt.Errorf("assumed reads per event must be positive")
+	}
+	return nil
+}
+
+// SnapshotAdvisor анализирует статистику хранилища событий (длины потоков по типам агрегатов)
+// и нагрузку репозиториев (LoadProfileTracker) и рекомендует стратегию и частоту снапшотов
+// для каждого типа агрегата вместо подобранных вручную значений SnapshotFrequency.
+type SnapshotAdvisor struct {
+	eventStore EventStore
+	config     SnapshotAdvisorConfig
+	tracker    *LoadProfileTracker
+
+	mu   sync.RWMutex
+	last *SnapshotAdvice
+}
+
+// NewSnapshotAdvisor создает новый SnapshotAdvisor
+func NewSnapshotAdvisor(eventStore EventStore, config SnapshotAdvisorConfig) *SnapshotAdvisor {
+	return &SnapshotAdvisor{
+		eventStore: eventStore,
+		config:     config,
+	}
+}
+
+// WithLoadTracker подключает наблюдаемую нагрузку репозиториев
+func (a *SnapshotAdvisor) WithLoadTracker(tracker *LoadProfileTracker) *SnapshotAdvisor {
+	a.tracker = tracker
+	return a
+}
+
+// Analyze строит профили нагрузки агрегатов, попадающих в filter (Limit задает размер
+// страницы перечисления), и рекомендации для каждого типа
+func (a *SnapshotAdvisor) Analyze(ctx context.Context, filter AggregateFilter) (*SnapshotAdvice, error) {
+	if err := a.config.Validate(); err != nil {
+		return nil, err
+	}
+
+	lengths := make(map[string][]int64)
+	for {
+		page, err := ListAggregates(ctx, a.eventStore, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list aggregates: %w", err)
+		}
+		for _, summary := range page.Aggregates {
+			lengths[summary.AggregateType] = append(lengths[summary.AggregateType], summary.EventCount)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.After = page.NextCursor
+	}
+
+	var loads map[string]AggregateLoadStats
+	if a.tracker != nil {
+		loads = a.tracker.Stats()
+	}
+
+	advice := &SnapshotAdvice{GeneratedAt: time.Now()}
+	for aggregateType, streamLengths := range lengths {
+		profile := buildLoadProfile(aggregateType, streamLengths)
+		if load, ok := loads[aggregateType]; ok && (load.Reads > 0 || load.Writes > 0) {
+			profile.Load = load
+			profile.Observed = true
+		}
+		advice.Recommendations = append(advice.Recommendations, a.recommend(profile))
+	}
+	sort.Slice(advice.Recommendations, func(i, j int) bool {
+		return advice.Recommendations[i].AggregateType < advice.Recommendations[j].AggregateType
+	})
+
+	a.mu.Lock()
+	a.last = advice
+	a.mu.Unlock()
+	return advice, nil
+}
+
+// LastAdvice возвращает результат последнего Analyze (nil до первого анализа)
+func (a *SnapshotAdvisor) LastAdvice() *SnapshotAdvice {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.last
+}
+
+// Frequency возвращает функцию частоты снапшотов типа агрегата по последнему анализу
+// для NewDynamicFrequencySnapshotStrategy: частота меняется после каждого Analyze
+// без пересоздания репозитория. До анализа и для неизвестных типов возвращается fallback.
+func (a *SnapshotAdvisor) Frequency(aggregateType string, fallback int64) func() int64 {
+	return func() int64 {
+		advice := a.LastAdvice()
+		if advice == nil {
+			return fallback
+		}
+		recommendation, ok := advice.For(aggregateType)
+		if !ok {
+			return fallback
+		}
+		return recommendation.Frequency
+	}
+}
+
+// recommend рассчитывает рекомендацию для профиля
+func (a *SnapshotAdvisor) recommend(profile AggregateLoadProfile) SnapshotRecommendation {
+	recommendation := SnapshotRecommendation{AggregateType: profile.AggregateType, Profile: profile}
+
+	readsPerEvent := a.config.AssumedReadsPerEvent
+	if profile.Observed {
+		if profile.Load.Reads == 0 {
+			recommendation.Reason = "aggregates are written but never loaded"
+			return recommendation
+		}
+		if profile.Load.EventsWritten == 0 {
+			readsPerEvent = math.Inf(1)
+		} else {
+			readsPerEvent = float64(profile.Load.Reads) / float64(profile.Load.EventsWritten)
+		}
+	}
+
+	optimal := math.Sqrt(2 * a.config.SnapshotCost / readsPerEvent)
+	frequency := int64(math.Round(optimal))
+	if frequency < a.config.MinFrequency {
+		frequency = a.config.MinFrequency
+	}
+	if frequency > a.config.MaxFrequency {
+		frequency = a.config.MaxFrequency
+	}
+
+	// Снапшот не создается для потоков короче частоты - для типичного потока он бесполезен
+	if profile.P95StreamLength < frequency {
+		recommendation.Reason = fmt.Sprintf("p95 stream length %d is below optimal frequency %d", profile.P95StreamLength, frequency)
+		return recommendation
+	}
+
+	recommendation.UseSnapshots = true
+	recommendation.Frequency = frequency
+	recommendation.Reason = fmt.Sprintf("%.2f loads per written event, snapshot cost %.0f events", readsPerEvent, a.config.SnapshotCost)
+	return recommendation
+}
+
+// buildLoadProfile строит профиль по длинам потоков типа агрегата
+func buildLoadProfile(aggregateType string, streamLengths []int64) AggregateLoadProfile {
+	sort.Slice(streamLengths, func(i, j int) bool { return streamLengths[i] < streamLengths[j] })
+
+	profile := AggregateLoadProfile{
+		AggregateType: aggregateType,
+		Aggregates:    int64(len(streamLengths)),
+	}
+	if len(streamLengths) == 0 {
+		return profile
+	}
+	for _, length := range streamLengths {
+		profile.TotalEvents += length
+	}
+	profile.AvgStreamLength = float64(profile.TotalEvents) / float64(len(streamLengths))
+	profile.P95StreamLength = streamLengths[int(math.Ceil(0.95*float64(len(streamLengths))))-1]
+	profile.MaxStreamLength = streamLengths[len(streamLengths)-1]
+	return profile
+}
@@ -0,0 +1,326 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	// ErrSnapshotCodec возникает когда состояние снапшота не удается закодировать или декодировать
+	ErrSnapshotCodec = errors.New("snapshot codec error")
+)
+
+// SnapshotCodec преобразует сериализованное состояние снапшота перед сохранением
+// (сжатие, шифрование) и восстанавливает его при загрузке.
+// Name сохраняется вместе со снапшотом и определяет, как декодировать состояние.
+type SnapshotCodec interface {
+	// Name возвращает идентификатор кодека (например "gzip", "zstd", "aes-gcm")
+	Name() string
+	// Encode кодирует состояние снапшота
+	Encode(data []byte) ([]byte, error)
+	// Decode декодирует состояние снапшота
+	Decode(data []byte) ([]byte, error)
+}
+
+// snapshotCodecSeparator разделяет имена кодеков цепочки в сохраненном encoding
+const snapshotCodecSeparator = "+"
+
+// GzipSnapshotCodec сжимает состояние снапшота gzip
+type GzipSnapshotCodec struct {
+	level int
+}
+
+// NewGzipSnapshotCodec создает gzip кодек с уровнем сжатия level (gzip.DefaultCompression, gzip.BestSpeed и т.д.)
+func NewGzipSnapshotCodec(level int) *GzipSnapshotCodec {
+	return &GzipSnapshotCodec{level: level}
+}
+
+// Name возвращает "gzip"
+func (c *GzipSnapshotCodec) Name() string {
+	return "gzip"
+}
+
+// Encode сжимает данные
+func (c *GzipSnapshotCodec) Encode(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCodec, err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("%w: gzip: %v", ErrSnapshotCodec, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("%w: gzip: %v", ErrSnapshotCodec, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode распаковывает данные
+func (c *GzipSnapshotCodec) Decode(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: gzip: %v", ErrSnapshotCodec, err)
+	}
+	defer reader.Close()
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: gzip: %v", ErrSnapshotCodec, err)
+	}
+	return decoded, nil
+}
+
+// ZstdSnapshotCodec сжимает состояние снапшота zstd.
+// Сжимает лучше и быстрее gzip, рекомендуется для больших снапшотов.
+type ZstdSnapshotCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// NewZstdSnapshotCodec создает zstd кодек с уровнем сжатия level
+func NewZstdSnapshotCodec(level zstd.EncoderLevel) (*ZstdSnapshotCodec, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	return &ZstdSnapshotCodec{encoder: encoder, decoder: decoder}, nil
+}
+
+// Name возвращает "zstd"
+func (c *ZstdSnapshotCodec) Name() string {
+	return "zstd"
+}
+
+// Encode сжимает данные
+func (c *ZstdSnapshotCodec) Encode(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+// Decode распаковывает данные
+func (c *ZstdSnapshotCodec) Decode(data []byte) ([]byte, error) {
+	decoded, err := c.decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: zstd: %v", ErrSnapshotCodec, err)
+	}
+	return decoded, nil
+}
+
+// AESGCMSnapshotCodec шифрует состояние снапшота AES-GCM.
+// Поддерживает ротацию ключей: снапшоты шифруются активным ключом, идентификатор ключа
+// сохраняется в зашифрованных данных, поэтому снапшоты, зашифрованные предыдущими ключами,
+// расшифровываются, пока эти ключи остаются в наборе. Снапшот перешифровывается
+// активным ключом при следующем сохранении.
+type AESGCMSnapshotCodec struct {
+	activeKeyID string
+	ciphers     map[string]cipher.AEAD
+}
+
+// NewAESGCMSnapshotCodec создает AES-GCM кодек с набором ключей (16, 24 или 32 байта)
+// и идентификатором активного ключа
+func NewAESGCMSnapshotCodec(keys map[string][]byte, activeKeyID string) (*AESGCMSnapshotCodec, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q not found", activeKeyID)
+	}
+
+	ciphers := make(map[string]cipher.AEAD, len(keys))
+	for keyID, key := range keys {
+		if keyID == "" || len(keyID) > 255 {
+			return nil, fmt.Errorf("invalid key id %q: must be 1-255 bytes", keyID)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", keyID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", keyID, err)
+		}
+		ciphers[keyID] = aead
+	}
+
+	return &AESGCMSnapshotCodec{activeKeyID: activeKeyID, ciphers: ciphers}, nil
+}
+
+// Name возвращает "aes-gcm"
+func (c *AESGCMSnapshotCodec) Name() string {
+	return "aes-gcm"
+}
+
+// Encode шифрует данные активным ключом.
+// Формат: длина идентификатора ключа (1 байт), идентификатор ключа, nonce, шифротекст.
+func (c *AESGCMSnapshotCodec) Encode(data []byte) ([]byte, error) {
+	aead := c.ciphers[c.activeKeyID]
+
+	header := make([]byte, 0, 1+len(c.activeKeyID)+aead.NonceSize())
+	header = append(header, byte(len(c.activeKeyID)))
+	header = append(header, c.activeKeyID...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("%w: failed to generate nonce: %v", ErrSnapshotCodec, err)
+	}
+	header = append(header, nonce...)
+
+	// Идентификатор ключа аутентифицируется вместе с данными
+	return aead.Seal(header, nonce, data, header[:1+len(c.activeKeyID)]), nil
+}
+
+// Decode расшифровывает данные ключом, идентификатор которого указан в данных
+func (c *AESGCMSnapshotCodec) Decode(data []byte) ([]byte, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return nil, fmt.Errorf("%w: aes-gcm: malformed data", ErrSnapshotCodec)
+	}
+	keyIDEnd := 1 + int(data[0])
+	keyID := string(data[1:keyIDEnd])
+
+	aead, ok := c.ciphers[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: aes-gcm: unknown key %q", ErrSnapshotCodec, keyID)
+	}
+	if len(data) < keyIDEnd+aead.NonceSize() {
+		return nil, fmt.Errorf("%w: aes-gcm: malformed data", ErrSnapshotCodec)
+	}
+
+	nonce := data[keyIDEnd : keyIDEnd+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, data[keyIDEnd+aead.NonceSize():], data[:keyIDEnd])
+	if err != nil {
+		return nil, fmt.Errorf("%w: aes-gcm: %v", ErrSnapshotCodec, err)
+	}
+	return plaintext, nil
+}
+
+// ChainSnapshotCodec последовательно применяет несколько кодеков.
+// Сжатие должно предшествовать шифрованию: зашифрованные данные не сжимаются.
+type ChainSnapshotCodec struct {
+	codecs []SnapshotCodec
+}
+
+// NewChainSnapshotCodec создает цепочку кодеков, применяемых при кодировании в указанном порядке
+// (например NewChainSnapshotCodec(zstdCodec, aesCodec))
+func NewChainSnapshotCodec(codecs ...SnapshotCodec) *ChainSnapshotCodec {
+	return &ChainSnapshotCodec{codecs: codecs}
+}
+
+// Name возвращает имена кодеков цепочки через "+" (например "zstd+aes-gcm")
+func (c *ChainSnapshotCodec) Name() string {
+	names := make([]string, len(c.codecs))
+	for i, codec := range c.codecs {
+		names[i] = codec.Name()
+	}
+	return strings.Join(names, snapshotCodecSeparator)
+}
+
+// Encode применяет кодеки в порядке цепочки
+func (c *ChainSnapshotCodec) Encode(data []byte) ([]byte, error) {
+	var err error
+	for _, codec := range c.codecs {
+		if data, err = codec.Encode(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Decode применяет кодеки в обратном порядке
+func (c *ChainSnapshotCodec) Decode(data []byte) ([]byte, error) {
+	var err error
+	for i := len(c.codecs) - 1; i >= 0; i-- {
+		if data, err = c.codecs[i].Decode(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// EncodeSnapshotState кодирует состояние снапшота и возвращает закодированные данные
+// и encoding для сохранения вместе со снапшотом. Nil codec - состояние не изменяется, encoding пустой.
+func EncodeSnapshotState(codec SnapshotCodec, state []byte) ([]byte, string, error) {
+	if codec == nil {
+		return state, "", nil
+	}
+	encoded, err := codec.Encode(state)
+	if err != nil {
+		return nil, "", err
+	}
+	return encoded, codec.Name(), nil
+}
+
+// DecodeSnapshotState декодирует состояние снапшота, сохраненное с указанным encoding.
+// Пустой encoding - состояние сохранено без кодирования (например до включения кодека).
+// Кодеки, которые использовались при сохранении, ищутся среди кодеков codec,
+// а сжатие gzip и zstd декодируется всегда, поэтому смена алгоритма сжатия
+// не требует перезаписи существующих снапшотов.
+func DecodeSnapshotState(codec SnapshotCodec, encoding string, data []byte) ([]byte, error) {
+	if encoding == "" {
+		return data, nil
+	}
+
+	available := make(map[string]SnapshotCodec)
+	collectSnapshotCodecs(codec, available)
+
+	names := strings.Split(encoding, snapshotCodecSeparator)
+	var err error
+	for i := len(names) - 1; i >= 0; i-- {
+		decoder, ok := available[names[i]]
+		if !ok {
+			decoder, err = builtinSnapshotCodec(names[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if data, err = decoder.Decode(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// collectSnapshotCodecs собирает кодеки, в том числе входящие в цепочки, по имени
+func collectSnapshotCodecs(codec SnapshotCodec, available map[string]SnapshotCodec) {
+	switch c := codec.(type) {
+	case nil:
+	case *ChainSnapshotCodec:
+		for _, inner := range c.codecs {
+			collectSnapshotCodecs(inner, available)
+		}
+	default:
+		available[c.Name()] = c
+	}
+}
+
+// builtinZstd zstd кодек для декодирования, создается один раз: zstd декодер запускает фоновые горутины
+var (
+	builtinZstd     *ZstdSnapshotCodec
+	builtinZstdErr  error
+	builtinZstdOnce sync.Once
+)
+
+// builtinSnapshotCodec возвращает кодек сжатия, не требующий настройки, для декодирования
+func builtinSnapshotCodec(name string) (SnapshotCodec, error) {
+	switch name {
+	case "gzip":
+		return NewGzipSnapshotCodec(gzip.DefaultCompression), nil
+	case "zstd":
+		builtinZstdOnce.Do(func() {
+			builtinZstd, builtinZstdErr = NewZstdSnapshotCodec(zstd.SpeedDefault)
+		})
+		return builtinZstd, builtinZstdErr
+	default:
+		return nil, fmt.Errorf("%w: no codec configured for encoding %q", ErrSnapshotCodec, name)
+	}
+}
@@ -0,0 +1,133 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSnapshotCorrupted возникает когда контрольная сумма или подпись снапшота не совпадает
+	ErrSnapshotCorrupted = errors.New("snapshot integrity check failed")
+)
+
+// SnapshotSigner интерфейс для подписи и проверки подписи снапшотов
+type SnapshotSigner interface {
+	// Sign возвращает подпись для данных снапшота
+	Sign(data []byte) (string, error)
+	// Verify проверяет подпись данных снапшота
+	Verify(data []byte, signature string) error
+}
+
+// HMACSnapshotSigner подписывает снапшоты с помощью HMAC-SHA256
+type HMACSnapshotSigner struct {
+	key []byte
+}
+
+// NewHMACSnapshotSigner создает новый HMAC signer
+func NewHMACSnapshotSigner(key []byte) *HMACSnapshotSigner {
+	return &HMACSnapshotSigner{key: key}
+}
+
+// Sign вычисляет HMAC-SHA256 подпись
+func (s *HMACSnapshotSigner) Sign(data []byte) (string, error) {
+	if len(s.key) == 0 {
+		return "", fmt.Errorf("hmac key is empty")
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify проверяет HMAC-SHA256 подпись
+func (s *HMACSnapshotSigner) Verify(data []byte, signature string) error {
+	expected, err := s.Sign(data)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("%w: signature mismatch", ErrSnapshotCorrupted)
+	}
+	return nil
+}
+
+// ComputeSnapshotChecksum вычисляет SHA-256 контрольную сумму состояния снапшота.
+// JSON состояние приводится к каноническому виду, так как хранилища (например JSONB)
+// могут изменить порядок ключей и пробелы без изменения содержимого.
+func ComputeSnapshotChecksum(state []byte) string {
+	sum := sha256.Sum256(canonicalSnapshotState(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// snapshotSigningPayload возвращает подписываемые данные снапшота: канонический кортеж
+// (aggregate_id, aggregate_type, version, checksum). Подпись только контрольной суммы
+// позволяла бы перенести подписанный снапшот на другой агрегат или версию.
+func snapshotSigningPayload(snapshot *Snapshot) []byte {
+	payload, _ := json.Marshal([]interface{}{snapshot.AggregateID, snapshot.AggregateType, snapshot.Version, snapshot.Checksum})
+	return payload
+}
+
+// SealSnapshot заполняет контрольную сумму и (опционально) подпись снапшота.
+// Подпись покрывает идентификатор, тип и версию агрегата вместе с контрольной суммой состояния.
+func SealSnapshot(snapshot *Snapshot, signer SnapshotSigner) error {
+	snapshot.Checksum = ComputeSnapshotChecksum(snapshot.State)
+	snapshot.Signature = ""
+	if signer != nil {
+		signature, err := signer.Sign(snapshotSigningPayload(snapshot))
+		if err != nil {
+			return fmt.Errorf("failed to sign snapshot: %w", err)
+		}
+		snapshot.Signature = signature
+	}
+	return nil
+}
+
+// VerifySnapshot проверяет контрольную сумму и подпись снапшота. Снапшот, перенесенный
+// на другой агрегат, тип или версию, не проходит проверку подписи.
+// Снапшоты без контрольной суммы (созданные до включения проверки) считаются валидными,
+// если signer не задан.
+func VerifySnapshot(snapshot *Snapshot, signer SnapshotSigner) error {
+	if snapshot == nil {
+		return nil
+	}
+
+	if snapshot.Checksum == "" {
+		if signer != nil {
+			return fmt.Errorf("%w: snapshot %s has no checksum", ErrSnapshotCorrupted, snapshot.AggregateID)
+		}
+		return nil
+	}
+
+	if actual := ComputeSnapshotChecksum(snapshot.State); actual != snapshot.Checksum {
+		return fmt.Errorf("%w: checksum mismatch for aggregate %s", ErrSnapshotCorrupted, snapshot.AggregateID)
+	}
+
+	if signer != nil {
+		if err := signer.Verify(snapshotSigningPayload(snapshot), snapshot.Signature); err != nil {
+			return fmt.Errorf("aggregate %s: %w", snapshot.AggregateID, err)
+		}
+	}
+
+	return nil
+}
+
+// canonicalSnapshotState возвращает канонический JSON или исходные байты для не-JSON состояния
+func canonicalSnapshotState(state []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(state))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return state
+	}
+
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return state
+	}
+	return canonical
+}
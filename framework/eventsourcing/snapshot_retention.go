@@ -0,0 +1,182 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/metrics"
+)
+
+// SnapshotRetentionMetricsPrefix префикс метрик очистки снапшотов
+const SnapshotRetentionMetricsPrefix = "eventsourcing_snapshot_prune"
+
+// SnapshotRetentionPolicy политика хранения снапшотов.
+//
+// Хранилище держит текущий снапшот агрегата; при KeepLast > 1 замененные снапшоты
+// сохраняются в историю, и очистка оставляет в ней KeepLast-1 последних версий.
+// MaxAge удаляет снапшоты (включая текущие) старше заданного возраста - такие
+// агрегаты восстанавливаются из событий и получают новый снапшот по стратегии.
+type SnapshotRetentionPolicy struct {
+	// KeepLast количество хранимых снапшотов на агрегат (0 или 1 - только текущий)
+	KeepLast int
+	// MaxAge максимальный возраст снапшота (0 - без ограничения)
+	MaxAge time.Duration
+	// Interval период фоновой очистки (0 - только ручной вызов Prune)
+	Interval time.Duration
+}
+
+// DefaultSnapshotRetentionPolicy возвращает политику: 3 снапшота на агрегат,
+// возраст до 30 дней, очистка раз в час
+func DefaultSnapshotRetentionPolicy() SnapshotRetentionPolicy {
+	return SnapshotRetentionPolicy{
+		KeepLast: 3,
+		MaxAge:   30 * 24 * time.Hour,
+		Interval: time.Hour,
+	}
+}
+
+// Validate проверяет корректность политики
+func (p SnapshotRetentionPolicy) Validate() error {
+	if p.KeepLast < 0 {
+		return fmt.Errorf("keep last cannot be negative")
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("max age cannot be negative")
+	}
+	if p.Interval < 0 {
+		return fmt.Errorf("prune interval cannot be negative")
+	}
+	return nil
+}
+
+// keepsHistory сообщает, нужно ли сохранять замененные снапшоты в историю
+func (p SnapshotRetentionPolicy) keepsHistory() bool {
+	return p.KeepLast > 1
+}
+
+// SnapshotPruneResult результат очистки снапшотов
+type SnapshotPruneResult struct {
+	// Deleted количество удаленных снапшотов
+	Deleted int64
+	// BytesReclaimed оценка освобожденного места (размер удаленных записей)
+	BytesReclaimed int64
+	// Duration длительность очистки
+	Duration time.Duration
+}
+
+// SnapshotPruner реализуется хранилищами снапшотов с поддержкой политики хранения
+type SnapshotPruner interface {
+	// Prune удаляет снапшоты, не попадающие под политику хранения
+	Prune(ctx context.Context) (SnapshotPruneResult, error)
+}
+
+// snapshotRetention общая часть политики хранения для хранилищ снапшотов:
+// фоновая очистка и метрики
+type snapshotRetention struct {
+	mu       sync.Mutex
+	policy   SnapshotRetentionPolicy
+	recorder metrics.Recorder
+	store    string
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// setPolicy задает политику хранения
+func (r *snapshotRetention) setPolicy(policy SnapshotRetentionPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = policy
+}
+
+// currentPolicy возвращает текущую политику хранения
+func (r *snapshotRetention) currentPolicy() SnapshotRetentionPolicy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.policy
+}
+
+// setRecorder задает recorder метрик
+func (r *snapshotRetention) setRecorder(recorder metrics.Recorder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recorder = recorder
+}
+
+// prune выполняет очистку и записывает метрики
+func (r *snapshotRetention) prune(ctx context.Context, prune func(ctx context.Context, policy SnapshotRetentionPolicy) (SnapshotPruneResult, error)) (SnapshotPruneResult, error) {
+	r.mu.Lock()
+	policy, recorder := r.policy, r.recorder
+	r.mu.Unlock()
+
+	if err := policy.Validate(); err != nil {
+		return SnapshotPruneResult{}, fmt.Errorf("invalid snapshot retention policy: %w", err)
+	}
+
+	start := time.Now()
+	result, err := prune(ctx, policy)
+	result.Duration = time.Since(start)
+
+	labels := metrics.Labels{"store": r.store}
+	metrics.RecordOperation(ctx, recorder, SnapshotRetentionMetricsPrefix, "prune", start, err, labels)
+	if recorder != nil && result.Deleted > 0 {
+		recorder.Counter(ctx, SnapshotRetentionMetricsPrefix+"_deleted_total", result.Deleted, labels)
+		recorder.Counter(ctx, SnapshotRetentionMetricsPrefix+"_reclaimed_bytes_total", result.BytesReclaimed, labels)
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("failed to prune snapshots: %w", err)
+	}
+	return result, nil
+}
+
+// start запускает фоновую очистку с периодом policy.Interval
+func (r *snapshotRetention) start(ctx context.Context, prune func(ctx context.Context, policy SnapshotRetentionPolicy) (SnapshotPruneResult, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopCh != nil {
+		return fmt.Errorf("snapshot pruner already started")
+	}
+	if r.policy.Interval <= 0 {
+		return fmt.Errorf("snapshot prune interval must be positive")
+	}
+
+	stopCh := make(chan struct{})
+	r.stopCh = stopCh
+	interval := r.policy.Interval
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				// Ошибка учитывается в метриках, следующая попытка - на следующем тике
+				_, _ = r.prune(ctx, prune)
+			}
+		}
+	}()
+	return nil
+}
+
+// stop останавливает фоновую очистку и ждет завершения текущего прохода
+func (r *snapshotRetention) stop() {
+	r.mu.Lock()
+	stopCh := r.stopCh
+	r.stopCh = nil
+	r.mu.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+	}
+	r.wg.Wait()
+}
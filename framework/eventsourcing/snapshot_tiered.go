@@ -0,0 +1,145 @@
+package eventsourcing
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+)
+
+// TieredSnapshotConfig конфигурация многоуровневого хранилища снапшотов
+type TieredSnapshotConfig struct {
+	// PromoteOnRead записывать в hot tier снапшоты, прочитанные из durable tier
+	PromoteOnRead bool
+	// HotFilter определяет, какие агрегаты хранить в hot tier (nil - все)
+	HotFilter func(aggregateID string) bool
+	// OnHotTierError вызывается при ошибке hot tier; такие ошибки не прерывают операцию
+	OnHotTierError func(ctx context.Context, aggregateID string, err error)
+}
+
+// DefaultTieredSnapshotConfig возвращает конфигурацию по умолчанию
+func DefaultTieredSnapshotConfig() TieredSnapshotConfig {
+	return TieredSnapshotConfig{
+		PromoteOnRead: true,
+	}
+}
+
+// TieredSnapshotStats статистика обращений к уровням хранилища
+type TieredSnapshotStats struct {
+	HotHits       int64
+	DurableHits   int64
+	Misses        int64
+	HotTierErrors int64
+}
+
+// TieredSnapshotStore двухуровневое хранилище снапшотов: быстрый hot tier (например, Redis)
+// и надежный durable tier (например, PostgreSQL).
+// GetSnapshot сначала обращается к hot tier и только при промахе - к durable tier.
+// Durable tier является источником истины: снапшот сохраняется в него первым,
+// а недоступность hot tier не приводит к ошибкам, только к обращению в durable tier.
+type TieredSnapshotStore struct {
+	hot     SnapshotStore
+	durable SnapshotStore
+	config  TieredSnapshotConfig
+
+	hotHits       atomic.Int64
+	durableHits   atomic.Int64
+	misses        atomic.Int64
+	hotTierErrors atomic.Int64
+}
+
+// NewTieredSnapshotStore создает новый TieredSnapshotStore
+func NewTieredSnapshotStore(hot, durable SnapshotStore) *TieredSnapshotStore {
+	return &TieredSnapshotStore{
+		hot:     hot,
+		durable: durable,
+		config:  DefaultTieredSnapshotConfig(),
+	}
+}
+
+// WithConfig устанавливает конфигурацию
+func (s *TieredSnapshotStore) WithConfig(config TieredSnapshotConfig) *TieredSnapshotStore {
+	s.config = config
+	return s
+}
+
+// SaveSnapshot сохраняет снапшот в durable tier, затем в hot tier
+func (s *TieredSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	if err := s.durable.SaveSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+
+	if s.isHot(snapshot.AggregateID) {
+		if err := s.hot.SaveSnapshot(ctx, snapshot); err != nil {
+			s.hotTierError(ctx, snapshot.AggregateID, err)
+		}
+	}
+	return nil
+}
+
+// GetSnapshot возвращает снапшот из hot tier, при промахе - из durable tier
+func (s *TieredSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
+	if s.isHot(aggregateID) {
+		snapshot, err := s.hot.GetSnapshot(ctx, aggregateID)
+		if err == nil && snapshot != nil {
+			s.hotHits.Add(1)
+			return snapshot, nil
+		}
+		if err != nil {
+			// Поврежденный или недоступный снапшот в hot tier: вытесняем и читаем из durable tier
+			s.hotTierError(ctx, aggregateID, err)
+			_ = s.hot.DeleteSnapshots(ctx, aggregateID, math.MaxInt64)
+		}
+	}
+
+	snapshot, err := s.durable.GetSnapshot(ctx, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		s.misses.Add(1)
+		return nil, nil
+	}
+	s.durableHits.Add(1)
+
+	if s.config.PromoteOnRead && s.isHot(aggregateID) {
+		if err := s.hot.SaveSnapshot(ctx, *snapshot); err != nil {
+			s.hotTierError(ctx, aggregateID, err)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// DeleteSnapshots удаляет старые снапшоты из обоих уровней
+func (s *TieredSnapshotStore) DeleteSnapshots(ctx context.Context, aggregateID string, beforeVersion int64) error {
+	if err := s.durable.DeleteSnapshots(ctx, aggregateID, beforeVersion); err != nil {
+		return err
+	}
+	if err := s.hot.DeleteSnapshots(ctx, aggregateID, beforeVersion); err != nil {
+		s.hotTierError(ctx, aggregateID, err)
+	}
+	return nil
+}
+
+// Stats возвращает статистику обращений
+func (s *TieredSnapshotStore) Stats() TieredSnapshotStats {
+	return TieredSnapshotStats{
+		HotHits:       s.hotHits.Load(),
+		DurableHits:   s.durableHits.Load(),
+		Misses:        s.misses.Load(),
+		HotTierErrors: s.hotTierErrors.Load(),
+	}
+}
+
+// isHot проверяет, хранится ли агрегат в hot tier
+func (s *TieredSnapshotStore) isHot(aggregateID string) bool {
+	return s.config.HotFilter == nil || s.config.HotFilter(aggregateID)
+}
+
+// hotTierError регистрирует ошибку hot tier
+func (s *TieredSnapshotStore) hotTierError(ctx context.Context, aggregateID string, err error) {
+	s.hotTierErrors.Add(1)
+	if s.config.OnHotTierError != nil {
+		s.config.OnHotTierError(ctx, aggregateID, err)
+	}
+}
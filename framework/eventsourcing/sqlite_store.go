@@ -0,0 +1,703 @@
+package eventsourcing
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// SQLiteEventStoreConfig конфигурация для SQLite Event Store.
+//
+// Драйвер SQLite не входит в зависимости фреймворка: приложение подключает его
+// blank-импортом и указывает имя драйвера в DriverName, например
+// _ "modernc.org/sqlite" (DriverName "sqlite", без cgo) или
+// _ "github.com/mattn/go-sqlite3" (DriverName "sqlite3").
+type SQLiteEventStoreConfig struct {
+	// Path путь к файлу базы данных (":memory:" - база в памяти)
+	Path string
+	// DriverName имя зарегистрированного драйвера database/sql
+	DriverName string
+	TableName  string
+	// SnapshotTableName таблица снапшотов SQLiteSnapshotStore
+	SnapshotTableName string
+	// BusyTimeout время ожидания блокировки базы другим процессом в миллисекундах
+	BusyTimeout int
+	// SnapshotCodec кодирует состояние снапшотов SQLiteSnapshotStore (сжатие, шифрование)
+	SnapshotCodec SnapshotCodec
+}
+
+// Validate проверяет корректность конфигурации
+func (c SQLiteEventStoreConfig) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if c.DriverName == "" {
+		return fmt.Errorf("driver name cannot be empty")
+	}
+	if c.TableName == "" || c.SnapshotTableName == "" {
+		return fmt.Errorf("table names cannot be empty")
+	}
+	return nil
+}
+
+// DefaultSQLiteEventStoreConfig возвращает конфигурацию по умолчанию
+func DefaultSQLiteEventStoreConfig() SQLiteEventStoreConfig {
+	return SQLiteEventStoreConfig{
+		Path:              "potter.db",
+		DriverName:        "sqlite",
+		TableName:         "event_store",
+		SnapshotTableName: "snapshots",
+		BusyTimeout:       5000,
+	}
+}
+
+// sqliteReadBatchSize размер страницы чтения GetAllEvents
+const sqliteReadBatchSize = 500
+
+// openSQLite открывает базу в режиме WAL и создает таблицы, если их нет.
+// SQLite допускает одного писателя, поэтому пул ограничен одним соединением:
+// запись сериализуется внутри процесса, а busy_timeout защищает от блокировок других процессов.
+func openSQLite(config SQLiteEventStoreConfig) (*sql.DB, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid sqlite config: %w", err)
+	}
+
+	db, err := sql.Open(config.DriverName, config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite (is driver %q imported?): %w", config.DriverName, err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+
+	statements := []string{
+		"PRAGMA journal_mode=WAL",
+		fmt.Sprintf("PRAGMA busy_timeout=%d", config.BusyTimeout),
+		"PRAGMA synchronous=NORMAL",
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			position INTEGER PRIMARY KEY AUTOINCREMENT,
+			id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT '',
+			aggregate_id TEXT NOT NULL,
+			aggregate_type TEXT NOT NULL DEFAULT '',
+			event_type TEXT NOT NULL,
+			event_data BLOB NOT NULL,
+			metadata BLOB NOT NULL,
+			version INTEGER NOT NULL,
+			schema_version INTEGER NOT NULL DEFAULT 1,
+			occurred_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL,
+			UNIQUE (tenant_id, aggregate_id, version)
+		)`, config.TableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_event_type ON %s (tenant_id, event_type, occurred_at)",
+			config.TableName, config.TableName),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			tenant_id TEXT NOT NULL DEFAULT '',
+			aggregate_id TEXT NOT NULL,
+			aggregate_type TEXT NOT NULL DEFAULT '',
+			version INTEGER NOT NULL,
+			state BLOB,
+			state_encoding TEXT NOT NULL DEFAULT '',
+			metadata BLOB NOT NULL,
+			checksum TEXT NOT NULL DEFAULT '',
+			signature TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (tenant_id, aggregate_id)
+		)`, config.SnapshotTableName),
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize SQLite schema: %w", err)
+		}
+	}
+
+	return db, nil
+}
+
+// isSQLiteUniqueViolation проверяет нарушение уникальности (формат ошибки общий для драйверов SQLite)
+func isSQLiteUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// SQLiteEventStore реализация EventStore для SQLite.
+// Предназначена для локальной разработки, CLI инструментов и тестов без Docker.
+type SQLiteEventStore struct {
+	config         SQLiteEventStoreConfig
+	db             *sql.DB
+	deserializer   EventDeserializer
+	tenantResolver TenantResolver
+}
+
+// NewSQLiteEventStore создает новый SQLite Event Store
+func NewSQLiteEventStore(config SQLiteEventStoreConfig) (*SQLiteEventStore, error) {
+	return NewSQLiteEventStoreWithDeserializer(config, nil)
+}
+
+// NewSQLiteEventStoreWithDeserializer создает новый SQLite Event Store с десериализатором
+func NewSQLiteEventStoreWithDeserializer(config SQLiteEventStoreConfig, deserializer EventDeserializer) (*SQLiteEventStore, error) {
+	db, err := openSQLite(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteEventStore{
+		config:       config,
+		db:           db,
+		deserializer: deserializer,
+	}, nil
+}
+
+// WithUpcasters включает приведение старых версий событий к актуальной схеме
+// (требуется десериализатор, см. PostgresEventStore.WithUpcasters)
+func (s *SQLiteEventStore) WithUpcasters(chain *UpcasterChain) *SQLiteEventStore {
+	if s.deserializer != nil {
+		s.deserializer = NewUpcastingDeserializer(s.deserializer, chain)
+	}
+	return s
+}
+
+// WithTenantResolver включает изоляцию тенантов (см. PostgresEventStore.WithTenantResolver)
+func (s *SQLiteEventStore) WithTenantResolver(resolver TenantResolver) *SQLiteEventStore {
+	s.tenantResolver = resolver
+	return s
+}
+
+// TenantIsolation сообщает, включена ли изоляция тенантов (реализация TenantIsolatedStore)
+func (s *SQLiteEventStore) TenantIsolation() bool {
+	return s.tenantResolver != nil
+}
+
+// SnapshotStore возвращает SQLiteSnapshotStore, использующий соединение event store.
+// Необходим для базы ":memory:", которая не разделяется между соединениями.
+func (s *SQLiteEventStore) SnapshotStore() *SQLiteSnapshotStore {
+	return &SQLiteSnapshotStore{
+		config:         s.config,
+		db:             s.db,
+		tenantResolver: s.tenantResolver,
+	}
+}
+
+// Start запускает адаптер
+func (s *SQLiteEventStore) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop останавливает адаптер
+func (s *SQLiteEventStore) Stop(ctx context.Context) error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// IsRunning проверяет, запущен ли адаптер
+func (s *SQLiteEventStore) IsRunning() bool {
+	return s.db != nil
+}
+
+// Name возвращает имя компонента
+func (s *SQLiteEventStore) Name() string {
+	return "sqlite-event-store"
+}
+
+// Type возвращает тип компонента
+func (s *SQLiteEventStore) Type() core.ComponentType {
+	return core.ComponentTypeAdapter
+}
+
+// AppendEvents добавляет события в поток агрегата
+func (s *SQLiteEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, events []events.Event) error {
+	return s.AppendEventsBatch(ctx, []EventBatch{{
+		AggregateID:     aggregateID,
+		ExpectedVersion: expectedVersion,
+		Events:          events,
+	}})
+}
+
+// AppendEventsBatch атомарно добавляет события нескольких агрегатов (реализация BatchAppender).
+// Версии проверяются в транзакции, а уникальный индекс (tenant_id, aggregate_id, version)
+// гарантирует обнаружение конфликта при записи из другого процесса.
+func (s *SQLiteEventStore) AppendEventsBatch(ctx context.Context, batches []EventBatch) error {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	currentVersions := make(map[string]int64, len(batches))
+	checkQuery := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s WHERE tenant_id = ? AND aggregate_id = ?", s.config.TableName)
+	for _, batch := range batches {
+		if _, exists := currentVersions[batch.AggregateID]; exists {
+			continue
+		}
+		var version int64
+		if err := tx.QueryRowContext(ctx, checkQuery, tenantID, batch.AggregateID).Scan(&version); err != nil {
+			return fmt.Errorf("failed to check version: %w", err)
+		}
+		currentVersions[batch.AggregateID] = version
+	}
+
+	if err := checkBatchVersions(batches, currentVersions); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO %s (id, tenant_id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.config.TableName)
+
+	now := time.Now().UnixNano()
+	for _, batch := range batches {
+		for i, event := range batch.Events {
+			eventData, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+
+			metadata, err := json.Marshal(convertMetadata(event.Metadata()))
+			if err != nil {
+				return fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+
+			version := batch.ExpectedVersion + int64(i) + 1
+			_, err = tx.ExecContext(ctx, insertQuery,
+				event.EventID(),
+				tenantID,
+				batch.AggregateID,
+				getAggregateType(event),
+				event.EventType(),
+				eventData,
+				metadata,
+				version,
+				eventSchemaVersion(s.deserializer, event),
+				event.OccurredAt().UnixNano(),
+				now,
+			)
+			if isSQLiteUniqueViolation(err) {
+				return fmt.Errorf("%w: version %d of aggregate %s already exists", ErrConcurrencyConflict, version, batch.AggregateID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to insert event: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if isSQLiteUniqueViolation(err) {
+			return fmt.Errorf("%w: %v", ErrConcurrencyConflict, err)
+		}
+		return fmt.Errorf("failed to commit events: %w", err)
+	}
+	return nil
+}
+
+// sqliteEventColumns колонки событий в порядке scanSQLiteEvent
+const sqliteEventColumns = "id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, position, occurred_at, created_at, schema_version, tenant_id"
+
+// scanSQLiteEvent читает строку события. Без десериализатора EventData восстанавливается
+// как events.BaseEvent (как в PostgresEventStore).
+func (s *SQLiteEventStore) scanSQLiteEvent(rows *sql.Rows) (StoredEvent, error) {
+	var stored StoredEvent
+	var eventDataJSON, metadataJSON []byte
+	var occurredAt, createdAt int64
+
+	if err := rows.Scan(
+		&stored.ID,
+		&stored.AggregateID,
+		&stored.AggregateType,
+		&stored.EventType,
+		&eventDataJSON,
+		&metadataJSON,
+		&stored.Version,
+		&stored.Position,
+		&occurredAt,
+		&createdAt,
+		&stored.SchemaVersion,
+		&stored.TenantID,
+	); err != nil {
+		return StoredEvent{}, fmt.Errorf("failed to scan event: %w", err)
+	}
+
+	stored.OccurredAt = time.Unix(0, occurredAt)
+	stored.CreatedAt = time.Unix(0, createdAt)
+	if err := json.Unmarshal(metadataJSON, &stored.Metadata); err != nil {
+		return StoredEvent{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	if s.deserializer != nil {
+		event, err := deserializeStoredEvent(s.deserializer, stored.EventType, stored.SchemaVersion, eventDataJSON)
+		if err != nil {
+			return StoredEvent{}, fmt.Errorf("%w: %w", ErrEventDeserializationFailed, err)
+		}
+		stored.EventData = event
+	} else {
+		var baseEvent events.BaseEvent
+		if err := json.Unmarshal(eventDataJSON, &baseEvent); err == nil {
+			stored.EventData = &baseEvent
+		}
+	}
+	return stored, nil
+}
+
+// queryEvents выполняет запрос событий и читает все строки
+func (s *SQLiteEventStore) queryEvents(ctx context.Context, query string, args ...interface{}) ([]StoredEvent, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var result []StoredEvent
+	for rows.Next() {
+		stored, err := s.scanSQLiteEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stored)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	return result, nil
+}
+
+// GetEvents возвращает события агрегата
+func (s *SQLiteEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, 0)
+}
+
+// GetEventsPage возвращает не более limit событий агрегата начиная с указанной версии
+func (s *SQLiteEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	return s.getEvents(ctx, aggregateID, fromVersion, limit)
+}
+
+// getEvents читает события агрегата (limit <= 0 - без ограничения)
+func (s *SQLiteEventStore) getEvents(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE tenant_id = ? AND aggregate_id = ? AND version >= ?
+		ORDER BY version ASC
+	`, sqliteEventColumns, s.config.TableName)
+	if limit > 0 {
+		query += fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	result, err := s.queryEvents(ctx, query, tenantID, aggregateID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 && fromVersion > 0 {
+		return nil, ErrStreamNotFound
+	}
+	return result, nil
+}
+
+// GetEventsByType возвращает события определенного типа
+func (s *SQLiteEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE tenant_id = ? AND event_type = ? AND occurred_at >= ?
+		ORDER BY position ASC
+	`, sqliteEventColumns, s.config.TableName)
+
+	var from int64
+	if !fromTimestamp.IsZero() {
+		from = fromTimestamp.UnixNano()
+	}
+	return s.queryEvents(ctx, query, tenantID, eventType, from)
+}
+
+// RenameEventType заменяет тип событий всех тенантов (реализация EventTypeRenamer)
+func (s *SQLiteEventStore) RenameEventType(ctx context.Context, oldType, newType string) (int64, error) {
+	query := fmt.Sprintf("UPDATE %s SET event_type = ? WHERE event_type = ?", s.config.TableName)
+	result, err := s.db.ExecContext(ctx, query, newType, oldType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename event type: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetAllEvents возвращает все события начиная с указанной позиции.
+// События читаются страницами, чтобы единственное соединение не удерживалось,
+// пока потребитель обрабатывает события (и, например, добавляет новые).
+func (s *SQLiteEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE tenant_id = ? AND position >= ?
+		ORDER BY position ASC
+		LIMIT %d
+	`, sqliteEventColumns, s.config.TableName, sqliteReadBatchSize)
+
+	ch := make(chan StoredEvent, 100)
+
+	go func() {
+		defer close(ch)
+		position := fromPosition
+		for {
+			batch, err := s.queryEvents(ctx, query, tenantID, position)
+			if err != nil {
+				return
+			}
+			for _, stored := range batch {
+				select {
+				case ch <- stored:
+				case <-ctx.Done():
+					return
+				}
+				position = stored.Position + 1
+			}
+			if len(batch) < sqliteReadBatchSize {
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// ListAggregates возвращает страницу агрегатов тенанта (реализация AggregateLister)
+func (s *SQLiteEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := aggregatePageLimit(filter)
+	args := []interface{}{tenantID, filter.After}
+	conditions := []string{"tenant_id = ?", "aggregate_id > ?"}
+	if filter.AggregateType != "" {
+		args = append(args, filter.AggregateType)
+		conditions = append(conditions, "aggregate_type = ?")
+	}
+	having := []string{"1 = 1"}
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom.UnixNano())
+		having = append(having, "MIN(occurred_at) >= ?")
+	}
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo.UnixNano())
+		having = append(having, "MIN(occurred_at) < ?")
+	}
+	args = append(args, limit+1)
+
+	query := fmt.Sprintf(`
+		SELECT aggregate_id, MAX(aggregate_type), MAX(version), COUNT(*), MIN(occurred_at), MAX(occurred_at)
+		FROM %s
+		WHERE %s
+		GROUP BY aggregate_id
+		HAVING %s
+		ORDER BY aggregate_id
+		LIMIT ?
+	`, s.config.TableName, strings.Join(conditions, " AND "), strings.Join(having, " AND "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []AggregateSummary
+	for rows.Next() {
+		var summary AggregateSummary
+		var createdAt, updatedAt int64
+		if err := rows.Scan(&summary.AggregateID, &summary.AggregateType, &summary.Version,
+			&summary.EventCount, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate: %w", err)
+		}
+		summary.CreatedAt = time.Unix(0, createdAt)
+		summary.UpdatedAt = time.Unix(0, updatedAt)
+		aggregates = append(aggregates, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list aggregates: %w", err)
+	}
+
+	return newAggregatePage(aggregates, limit), nil
+}
+
+// SQLiteSnapshotStore реализация SnapshotStore для SQLite
+type SQLiteSnapshotStore struct {
+	config         SQLiteEventStoreConfig
+	db             *sql.DB
+	signer         SnapshotSigner
+	tenantResolver TenantResolver
+}
+
+// NewSQLiteSnapshotStore создает новый SQLite Snapshot Store.
+// Для базы ":memory:" используйте SQLiteEventStore.SnapshotStore().
+func NewSQLiteSnapshotStore(config SQLiteEventStoreConfig) (*SQLiteSnapshotStore, error) {
+	db, err := openSQLite(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteSnapshotStore{
+		config: config,
+		db:     db,
+	}, nil
+}
+
+// WithSigner включает подпись снапшотов и проверку подписи при загрузке
+func (s *SQLiteSnapshotStore) WithSigner(signer SnapshotSigner) *SQLiteSnapshotStore {
+	s.signer = signer
+	return s
+}
+
+// WithTenantResolver включает изоляцию снапшотов по тенантам
+func (s *SQLiteSnapshotStore) WithTenantResolver(resolver TenantResolver) *SQLiteSnapshotStore {
+	s.tenantResolver = resolver
+	return s
+}
+
+// SaveSnapshot сохраняет снапшот
+func (s *SQLiteSnapshotStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (tenant_id, aggregate_id, aggregate_type, version, state, state_encoding, metadata, checksum, signature, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id, aggregate_id)
+		DO UPDATE SET aggregate_type = excluded.aggregate_type, version = excluded.version, state = excluded.state,
+			state_encoding = excluded.state_encoding, metadata = excluded.metadata, checksum = excluded.checksum,
+			signature = excluded.signature, updated_at = excluded.updated_at
+	`, s.config.SnapshotTableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	if err := SealSnapshot(&snapshot, s.signer); err != nil {
+		return err
+	}
+
+	metadataJSON, err := json.Marshal(snapshot.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	state, encoding, err := EncodeSnapshotState(s.config.SnapshotCodec, snapshot.State)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, query,
+		tenantID,
+		snapshot.AggregateID,
+		snapshot.AggregateType,
+		snapshot.Version,
+		state,
+		encoding,
+		metadataJSON,
+		snapshot.Checksum,
+		snapshot.Signature,
+		snapshot.CreatedAt.UnixNano(),
+		time.Now().UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetSnapshot возвращает последний снапшот
+func (s *SQLiteSnapshotStore) GetSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error) {
+	query := fmt.Sprintf(`
+		SELECT aggregate_id, aggregate_type, version, state, state_encoding, metadata, checksum, signature, created_at
+		FROM %s
+		WHERE tenant_id = ? AND aggregate_id = ?
+	`, s.config.SnapshotTableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	var state, metadataJSON []byte
+	var encoding string
+	var createdAt int64
+
+	err = s.db.QueryRowContext(ctx, query, tenantID, aggregateID).Scan(
+		&snapshot.AggregateID,
+		&snapshot.AggregateType,
+		&snapshot.Version,
+		&state,
+		&encoding,
+		&metadataJSON,
+		&snapshot.Checksum,
+		&snapshot.Signature,
+		&createdAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	snapshot.CreatedAt = time.Unix(0, createdAt)
+	if err := json.Unmarshal(metadataJSON, &snapshot.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	snapshot.State = state
+	if encoding != "" {
+		decoded, err := DecodeSnapshotState(s.config.SnapshotCodec, encoding, state)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate %s: %w", snapshot.AggregateID, err)
+		}
+		snapshot.State = decoded
+	}
+
+	if err := VerifySnapshot(&snapshot, s.signer); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// DeleteSnapshots удаляет старые снапшоты
+func (s *SQLiteSnapshotStore) DeleteSnapshots(ctx context.Context, aggregateID string, beforeVersion int64) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE tenant_id = ? AND aggregate_id = ? AND version < ?
+	`, s.config.SnapshotTableName)
+
+	tenantID, err := resolveTenant(ctx, s.tenantResolver)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, tenantID, aggregateID, beforeVersion); err != nil {
+		return fmt.Errorf("failed to delete snapshots: %w", err)
+	}
+	return nil
+}
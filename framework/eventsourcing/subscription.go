@@ -0,0 +1,215 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSubscriptionPollInterval интервал опроса хранилища подпиской. Если хранилище
+	// реализует ChangeNotifier, опрос лишь страхует от потерянных уведомлений.
+	defaultSubscriptionPollInterval = 500 * time.Millisecond
+	// subscriptionBufferSize размер буфера канала подписки
+	subscriptionBufferSize = 100
+	// subscriptionRetryDelay задержка переподписки после разрыва
+	subscriptionRetryDelay = time.Second
+)
+
+// ErrChangeNotificationsDisabled возвращается ChangeNotifier, если уведомления о записи не настроены
+var ErrChangeNotificationsDisabled = errors.New("change notifications are disabled")
+
+// EventSubscriber реализуется хранилищами с live catch-up подписками: подписка сначала
+// отдает сохраненные события, затем переключается на новые по мере их записи.
+// Канал закрывается при отмене ctx или разрыве подписки.
+type EventSubscriber interface {
+	// SubscribeToStream подписывается на события агрегата начиная с версии fromVersion (включительно)
+	SubscribeToStream(ctx context.Context, aggregateID string, fromVersion int64) (<-chan StoredEvent, error)
+	// SubscribeToAll подписывается на все события начиная с позиции fromPosition (включительно)
+	SubscribeToAll(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error)
+}
+
+// ChangeNotifier реализуется хранилищами, уведомляющими о записи новых событий
+// (PostgreSQL LISTEN/NOTIFY, InMemory). PollingSubscriber использует уведомления,
+// чтобы не ждать следующего интервала опроса.
+type ChangeNotifier interface {
+	// WatchChanges возвращает канал, получающий сигнал после записи событий, до отмены ctx.
+	// Сигналы могут объединяться: один сигнал означает "были изменения".
+	WatchChanges(ctx context.Context) (<-chan struct{}, error)
+}
+
+// NewEventSubscriber возвращает подписки хранилища: собственные, если хранилище
+// реализует EventSubscriber, иначе PollingSubscriber поверх GetEvents/GetAllEvents
+func NewEventSubscriber(store EventStore) EventSubscriber {
+	if subscriber, ok := store.(EventSubscriber); ok {
+		return subscriber
+	}
+	return NewPollingSubscriber(store)
+}
+
+// PollingSubscriber реализует EventSubscriber для любого EventStore: догоняет историю
+// через GetEvents/GetAllEvents и затем перечитывает хранилище по уведомлениям
+// ChangeNotifier или с интервалом опроса
+type PollingSubscriber struct {
+	store        EventStore
+	pollInterval time.Duration
+}
+
+// NewPollingSubscriber создает PollingSubscriber с интервалом опроса по умолчанию (500ms)
+func NewPollingSubscriber(store EventStore) *PollingSubscriber {
+	return &PollingSubscriber{
+		store:        store,
+		pollInterval: defaultSubscriptionPollInterval,
+	}
+}
+
+// WithPollInterval устанавливает интервал опроса хранилища
+func (s *PollingSubscriber) WithPollInterval(interval time.Duration) *PollingSubscriber {
+	if interval > 0 {
+		s.pollInterval = interval
+	}
+	return s
+}
+
+// SubscribeToStream подписывается на события агрегата начиная с версии fromVersion.
+// Подписка на еще не созданный поток ожидает его первых событий.
+func (s *PollingSubscriber) SubscribeToStream(ctx context.Context, aggregateID string, fromVersion int64) (<-chan StoredEvent, error) {
+	next := fromVersion
+	return s.follow(ctx, func(emit func(StoredEvent) bool) error {
+		stored, err := s.store.GetEvents(ctx, aggregateID, next)
+		if err != nil {
+			if errors.Is(err, ErrStreamNotFound) {
+				return nil
+			}
+			return err
+		}
+		for _, event := range stored {
+			if event.Version < next {
+				continue
+			}
+			if !emit(event) {
+				return ctx.Err()
+			}
+			next = event.Version + 1
+		}
+		return nil
+	})
+}
+
+// SubscribeToAll подписывается на все события хранилища начиная с позиции fromPosition
+func (s *PollingSubscriber) SubscribeToAll(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	next := fromPosition
+	return s.follow(ctx, func(emit func(StoredEvent) bool) error {
+		eventsChan, err := s.store.GetAllEvents(ctx, next)
+		if err != nil {
+			return err
+		}
+		for event := range eventsChan {
+			if event.Position < next {
+				continue
+			}
+			if !emit(event) {
+				return ctx.Err()
+			}
+			next = event.Position + 1
+		}
+		return nil
+	})
+}
+
+// follow запускает цикл чтения: read отдает новые события через emit и запоминает позицию,
+// после чего подписка ждет уведомления об изменениях или интервала опроса
+func (s *PollingSubscriber) follow(ctx context.Context, read func(emit func(StoredEvent) bool) error) (<-chan StoredEvent, error) {
+	var changes <-chan struct{}
+	if notifier, ok := s.store.(ChangeNotifier); ok {
+		// Без уведомлений подписка продолжает работать опросом
+		if watched, err := notifier.WatchChanges(ctx); err == nil {
+			changes = watched
+		}
+	}
+
+	ch := make(chan StoredEvent, subscriptionBufferSize)
+	emit := func(event StoredEvent) bool {
+		select {
+		case ch <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(ch)
+
+		timer := time.NewTimer(s.pollInterval)
+		defer timer.Stop()
+		for {
+			// Ошибки чтения повторяются на следующей итерации
+			_ = read(emit)
+			if ctx.Err() != nil {
+				return
+			}
+
+			timer.Reset(s.pollInterval)
+			select {
+			case <-ctx.Done():
+				return
+			case <-changes:
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// changeFeed рассылает сигналы о записи событий наблюдателям WatchChanges
+type changeFeed struct {
+	mu       sync.Mutex
+	watchers map[chan struct{}]struct{}
+}
+
+// watch регистрирует наблюдателя до отмены ctx; onRemove вызывается после удаления наблюдателя
+func (f *changeFeed) watch(ctx context.Context, onRemove func()) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	f.mu.Lock()
+	if f.watchers == nil {
+		f.watchers = make(map[chan struct{}]struct{})
+	}
+	f.watchers[ch] = struct{}{}
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		delete(f.watchers, ch)
+		f.mu.Unlock()
+		if onRemove != nil {
+			onRemove()
+		}
+	}()
+
+	return ch
+}
+
+// size возвращает число наблюдателей
+func (f *changeFeed) size() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.watchers)
+}
+
+// notify сигнализирует всем наблюдателям, не блокируясь на необработанных сигналах
+func (f *changeFeed) notify() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
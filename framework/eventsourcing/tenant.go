@@ -0,0 +1,263 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// ErrTenantNotResolved возникает, когда хранилище с включенной изоляцией тенантов
+// не может определить тенант операции
+var ErrTenantNotResolved = errors.New("tenant is not resolved")
+
+// ErrTenantIsolationUnsupported возникает при ограничении тенантом хранилища событий,
+// которое не разделяет данные тенантов (см. TenantIsolatedStore)
+var ErrTenantIsolationUnsupported = errors.New("event store does not support tenant isolation")
+
+// tenantCheckpointSeparator разделитель тенанта и имени проекции в имени checkpoint
+const tenantCheckpointSeparator = "/"
+
+// tenantContextKey ключ тенанта в контексте
+type tenantContextKey struct{}
+
+// WithTenant добавляет идентификатор тенанта в контекст
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext извлекает идентификатор тенанта из контекста
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok {
+		return tenantID
+	}
+	return ""
+}
+
+// TenantResolver определяет тенант операции хранилища по контексту запроса.
+// Пустой тенант без ошибки означает тенант по умолчанию ("").
+type TenantResolver func(ctx context.Context) (string, error)
+
+// ContextTenantResolver возвращает resolver, использующий тенант из контекста (см. WithTenant).
+// Если required, операции без тенанта в контексте завершаются ошибкой ErrTenantNotResolved.
+func ContextTenantResolver(required bool) TenantResolver {
+	return func(ctx context.Context) (string, error) {
+		tenantID := TenantFromContext(ctx)
+		if tenantID == "" && required {
+			return "", ErrTenantNotResolved
+		}
+		return tenantID, nil
+	}
+}
+
+// TenantIsolatedStore реализуется хранилищами событий, которые могут разделять данные
+// тенантов. TenantIsolation возвращает true, если изоляция включена (задан TenantResolver):
+// только тогда тенант из контекста операции учитывается хранилищем.
+//
+// Изоляцию поддерживают PostgresEventStore, MySQLEventStore, SQLiteEventStore и
+// InMemoryEventStore (WithTenantResolver); обертки (AliasingEventStore, EncryptedEventStore,
+// CodecEventStore, RegionalEventStore, RoutingEventStore) передают проверку хранилищу.
+// MongoDBEventStore, EventStoreDBStore и ArchivedEventStore (архив не разделен по тенантам)
+// изоляцию не поддерживают.
+type TenantIsolatedStore interface {
+	TenantIsolation() bool
+}
+
+// SupportsTenantIsolation проверяет, что хранилище событий разделяет данные тенантов
+func SupportsTenantIsolation(store EventStore) bool {
+	isolated, ok := store.(TenantIsolatedStore)
+	return ok && isolated.TenantIsolation()
+}
+
+// resolveTenant определяет тенант с помощью resolver; без resolver используется тенант по умолчанию
+func resolveTenant(ctx context.Context, resolver TenantResolver) (string, error) {
+	if resolver == nil {
+		return "", nil
+	}
+	tenantID, err := resolver(ctx)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotResolved) {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: %v", ErrTenantNotResolved, err)
+	}
+	return tenantID, nil
+}
+
+// TenantCheckpointName возвращает имя checkpoint проекции в рамках тенанта
+func TenantCheckpointName(tenantID, projectionName string) string {
+	if tenantID == "" {
+		return projectionName
+	}
+	return tenantID + tenantCheckpointSeparator + projectionName
+}
+
+// TenantCheckpointStore ограничивает CheckpointStore одним тенантом: имена checkpoints
+// хранятся с префиксом тенанта (см. TenantCheckpointName), а ListCheckpoints
+// возвращает только checkpoints тенанта без префикса.
+type TenantCheckpointStore struct {
+	store    CheckpointStore
+	tenantID string
+}
+
+// NewTenantCheckpointStore создает CheckpointStore тенанта поверх общего хранилища
+func NewTenantCheckpointStore(store CheckpointStore, tenantID string) *TenantCheckpointStore {
+	return &TenantCheckpointStore{store: store, tenantID: tenantID}
+}
+
+// TenantID возвращает тенант хранилища
+func (s *TenantCheckpointStore) TenantID() string {
+	return s.tenantID
+}
+
+// SaveCheckpoint сохраняет checkpoint проекции тенанта
+func (s *TenantCheckpointStore) SaveCheckpoint(ctx context.Context, projectionName string, position int64) error {
+	return s.store.SaveCheckpoint(ctx, TenantCheckpointName(s.tenantID, projectionName), position)
+}
+
+// GetCheckpoint возвращает checkpoint проекции тенанта
+func (s *TenantCheckpointStore) GetCheckpoint(ctx context.Context, projectionName string) (int64, error) {
+	return s.store.GetCheckpoint(ctx, TenantCheckpointName(s.tenantID, projectionName))
+}
+
+// DeleteCheckpoint удаляет checkpoint проекции тенанта
+func (s *TenantCheckpointStore) DeleteCheckpoint(ctx context.Context, projectionName string) error {
+	return s.store.DeleteCheckpoint(ctx, TenantCheckpointName(s.tenantID, projectionName))
+}
+
+// ListCheckpoints возвращает checkpoints тенанта
+func (s *TenantCheckpointStore) ListCheckpoints(ctx context.Context) (map[string]int64, error) {
+	all, err := s.store.ListCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := TenantCheckpointName(s.tenantID, "")
+	checkpoints := make(map[string]int64)
+	for name, position := range all {
+		if s.tenantID == "" {
+			// Тенант по умолчанию: checkpoints без префикса тенанта
+			if !strings.Contains(name, tenantCheckpointSeparator) {
+				checkpoints[name] = position
+			}
+			continue
+		}
+		if strings.HasPrefix(name, prefix) {
+			checkpoints[strings.TrimPrefix(name, prefix)] = position
+		}
+	}
+	return checkpoints, nil
+}
+
+// TenantEventStore ограничивает EventStore одним тенантом, добавляя тенант
+// в контекст каждой операции. Используется компонентами, работающими вне контекста
+// запроса (проекции, replay), вместе с хранилищем, настроенным через TenantResolver.
+//
+// Хранилище без изоляции тенантов (см. TenantIsolatedStore) игнорировало бы тенант
+// и отдавало события всех тенантов, поэтому все операции с ним завершаются ошибкой
+// ErrTenantIsolationUnsupported (см. Err).
+type TenantEventStore struct {
+	store    EventStore
+	tenantID string
+	err      error
+}
+
+// NewTenantEventStore создает EventStore тенанта
+func NewTenantEventStore(store EventStore, tenantID string) *TenantEventStore {
+	scoped := &TenantEventStore{store: store, tenantID: tenantID}
+	if !SupportsTenantIsolation(store) {
+		scoped.err = fmt.Errorf("%w: %T", ErrTenantIsolationUnsupported, store)
+	}
+	return scoped
+}
+
+// TenantID возвращает тенант хранилища
+func (s *TenantEventStore) TenantID() string {
+	return s.tenantID
+}
+
+// Err возвращает ErrTenantIsolationUnsupported, если хранилище не разделяет данные тенантов
+func (s *TenantEventStore) Err() error {
+	return s.err
+}
+
+// AppendEvents добавляет события в поток агрегата тенанта
+func (s *TenantEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, events []events.Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.store.AppendEvents(WithTenant(ctx, s.tenantID), aggregateID, expectedVersion, events)
+}
+
+// GetEvents возвращает события агрегата тенанта
+func (s *TenantEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]StoredEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.store.GetEvents(WithTenant(ctx, s.tenantID), aggregateID, fromVersion)
+}
+
+// GetEventsPage возвращает страницу событий агрегата тенанта (реализация EventPageReader)
+func (s *TenantEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]StoredEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return GetEventsPage(WithTenant(ctx, s.tenantID), s.store, aggregateID, fromVersion, limit)
+}
+
+// GetEventsByType возвращает события тенанта определенного типа
+func (s *TenantEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]StoredEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.store.GetEventsByType(WithTenant(ctx, s.tenantID), eventType, fromTimestamp)
+}
+
+// GetAllEvents возвращает события тенанта начиная с указанной позиции
+func (s *TenantEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.store.GetAllEvents(WithTenant(ctx, s.tenantID), fromPosition)
+}
+
+// WatchChanges передает сигналы о записи событий хранилища, если оно реализует ChangeNotifier
+func (s *TenantEventStore) WatchChanges(ctx context.Context) (<-chan struct{}, error) {
+	if notifier, ok := s.store.(ChangeNotifier); ok {
+		return notifier.WatchChanges(ctx)
+	}
+	return nil, ErrChangeNotificationsDisabled
+}
+
+// SubscribeToStream подписывается на события агрегата тенанта (реализация EventSubscriber)
+func (s *TenantEventStore) SubscribeToStream(ctx context.Context, aggregateID string, fromVersion int64) (<-chan StoredEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if subscriber, ok := s.store.(EventSubscriber); ok {
+		return subscriber.SubscribeToStream(WithTenant(ctx, s.tenantID), aggregateID, fromVersion)
+	}
+	return NewPollingSubscriber(s).SubscribeToStream(ctx, aggregateID, fromVersion)
+}
+
+// SubscribeToAll подписывается на все события тенанта (реализация EventSubscriber)
+func (s *TenantEventStore) SubscribeToAll(ctx context.Context, fromPosition int64) (<-chan StoredEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if subscriber, ok := s.store.(EventSubscriber); ok {
+		return subscriber.SubscribeToAll(WithTenant(ctx, s.tenantID), fromPosition)
+	}
+	return NewPollingSubscriber(s).SubscribeToAll(ctx, fromPosition)
+}
+
+// ListAggregates возвращает страницу агрегатов тенанта
+func (s *TenantEventStore) ListAggregates(ctx context.Context, filter AggregateFilter) (*AggregatePage, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return ListAggregates(WithTenant(ctx, s.tenantID), s.store, filter)
+}
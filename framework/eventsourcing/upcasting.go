@@ -0,0 +1,234 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+var (
+	// ErrUpcastFailed возникает когда не удается привести событие к актуальной версии схемы
+	ErrUpcastFailed = errors.New("event upcast failed")
+	// ErrEventDeserializationFailed возникает когда хранилище не может десериализовать сохраненное событие
+	ErrEventDeserializationFailed = errors.New("failed to deserialize event")
+)
+
+// DefaultSchemaVersion версия схемы событий, сохраненных до появления версионирования
+const DefaultSchemaVersion = 1
+
+// SchemaVersionedEvent событие, явно указывающее версию своей схемы
+type SchemaVersionedEvent interface {
+	SchemaVersion() int
+}
+
+// Upcaster преобразует сериализованное событие из версии FromVersion в FromVersion+1
+type Upcaster interface {
+	// EventType возвращает тип события, к которому применяется upcaster
+	EventType() string
+	// FromVersion возвращает исходную версию схемы
+	FromVersion() int
+	// Upcast преобразует данные события
+	Upcast(data []byte) ([]byte, error)
+}
+
+// JSONUpcaster upcaster, работающий с событием как с JSON объектом
+type JSONUpcaster struct {
+	eventType   string
+	fromVersion int
+	fn          func(data map[string]interface{}) error
+	useNumber   bool
+}
+
+// NewJSONUpcaster создает upcaster на основе функции, изменяющей JSON объект события
+func NewJSONUpcaster(eventType string, fromVersion int, fn func(data map[string]interface{}) error) *JSONUpcaster {
+	return &JSONUpcaster{
+		eventType:   eventType,
+		fromVersion: fromVersion,
+		fn:          fn,
+	}
+}
+
+// WithUseNumber передает числа в fn как json.Number вместо float64: суммы в минимальных
+// единицах валюты и другие int64 больше 2^53 не теряют точность при upcasting
+func (u *JSONUpcaster) WithUseNumber() *JSONUpcaster {
+	u.useNumber = true
+	return u
+}
+
+// NewRenameFieldUpcaster создает upcaster, переименовывающий поле события
+// (числовые поля копируются без преобразования в float64)
+func NewRenameFieldUpcaster(eventType string, fromVersion int, oldName, newName string) *JSONUpcaster {
+	return NewJSONUpcaster(eventType, fromVersion, func(data map[string]interface{}) error {
+		if value, ok := data[oldName]; ok {
+			data[newName] = value
+			delete(data, oldName)
+		}
+		return nil
+	}).WithUseNumber()
+}
+
+// EventType возвращает тип события
+func (u *JSONUpcaster) EventType() string {
+	return u.eventType
+}
+
+// FromVersion возвращает исходную версию схемы
+func (u *JSONUpcaster) FromVersion() int {
+	return u.fromVersion
+}
+
+// Upcast преобразует данные события
+func (u *JSONUpcaster) Upcast(data []byte) ([]byte, error) {
+	var obj map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if u.useNumber {
+		decoder.UseNumber()
+	}
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	if err := u.fn(obj); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// UpcasterChain цепочка upcaster'ов, последовательно приводящая события к актуальной версии схемы
+type UpcasterChain struct {
+	mu        sync.RWMutex
+	upcasters map[string]map[int]Upcaster
+}
+
+// NewUpcasterChain создает новую цепочку upcaster'ов
+func NewUpcasterChain(upcasters ...Upcaster) *UpcasterChain {
+	chain := &UpcasterChain{
+		upcasters: make(map[string]map[int]Upcaster),
+	}
+	for _, upcaster := range upcasters {
+		// Дубликаты в конструкторе - ошибка программиста
+		if err := chain.Register(upcaster); err != nil {
+			panic(err)
+		}
+	}
+	return chain
+}
+
+// Register регистрирует upcaster
+func (c *UpcasterChain) Register(upcaster Upcaster) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	eventType := upcaster.EventType()
+	if c.upcasters[eventType] == nil {
+		c.upcasters[eventType] = make(map[int]Upcaster)
+	}
+	if _, exists := c.upcasters[eventType][upcaster.FromVersion()]; exists {
+		return fmt.Errorf("upcaster for %s v%d already registered", eventType, upcaster.FromVersion())
+	}
+	c.upcasters[eventType][upcaster.FromVersion()] = upcaster
+	return nil
+}
+
+// CurrentVersion возвращает актуальную версию схемы для типа события
+func (c *UpcasterChain) CurrentVersion(eventType string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	version := DefaultSchemaVersion
+	for from := range c.upcasters[eventType] {
+		if from+1 > version {
+			version = from + 1
+		}
+	}
+	return version
+}
+
+// Upcast приводит данные события к актуальной версии схемы.
+// Возвращает преобразованные данные и итоговую версию.
+func (c *UpcasterChain) Upcast(eventType string, schemaVersion int, data []byte) ([]byte, int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if schemaVersion <= 0 {
+		schemaVersion = DefaultSchemaVersion
+	}
+
+	for {
+		upcaster, ok := c.upcasters[eventType][schemaVersion]
+		if !ok {
+			return data, schemaVersion, nil
+		}
+		upcasted, err := upcaster.Upcast(data)
+		if err != nil {
+			return nil, schemaVersion, fmt.Errorf("%w: %s v%d: %v", ErrUpcastFailed, eventType, schemaVersion, err)
+		}
+		data = upcasted
+		schemaVersion++
+	}
+}
+
+// VersionedEventDeserializer десериализатор, учитывающий версию схемы события
+type VersionedEventDeserializer interface {
+	EventDeserializer
+	// DeserializeVersionedEvent десериализует событие указанной версии схемы
+	DeserializeVersionedEvent(eventType string, schemaVersion int, data []byte) (events.Event, error)
+	// CurrentSchemaVersion возвращает версию схемы для новых событий типа eventType
+	CurrentSchemaVersion(eventType string) int
+}
+
+// UpcastingDeserializer оборачивает EventDeserializer и перед десериализацией
+// приводит события к актуальной версии схемы через UpcasterChain
+type UpcastingDeserializer struct {
+	deserializer EventDeserializer
+	chain        *UpcasterChain
+}
+
+// NewUpcastingDeserializer создает новый UpcastingDeserializer
+func NewUpcastingDeserializer(deserializer EventDeserializer, chain *UpcasterChain) *UpcastingDeserializer {
+	return &UpcastingDeserializer{
+		deserializer: deserializer,
+		chain:        chain,
+	}
+}
+
+// DeserializeEvent десериализует событие, считая его данными версии DefaultSchemaVersion
+func (d *UpcastingDeserializer) DeserializeEvent(eventType string, data []byte) (events.Event, error) {
+	return d.DeserializeVersionedEvent(eventType, DefaultSchemaVersion, data)
+}
+
+// DeserializeVersionedEvent приводит событие к актуальной версии и десериализует его
+func (d *UpcastingDeserializer) DeserializeVersionedEvent(eventType string, schemaVersion int, data []byte) (events.Event, error) {
+	upcasted, _, err := d.chain.Upcast(eventType, schemaVersion, data)
+	if err != nil {
+		return nil, err
+	}
+	return d.deserializer.DeserializeEvent(eventType, upcasted)
+}
+
+// CurrentSchemaVersion возвращает актуальную версию схемы для типа события
+func (d *UpcastingDeserializer) CurrentSchemaVersion(eventType string) int {
+	return d.chain.CurrentVersion(eventType)
+}
+
+// deserializeStoredEvent десериализует данные события с учетом версии схемы
+func deserializeStoredEvent(deserializer EventDeserializer, eventType string, schemaVersion int, data []byte) (events.Event, error) {
+	if versioned, ok := deserializer.(VersionedEventDeserializer); ok {
+		return versioned.DeserializeVersionedEvent(eventType, schemaVersion, data)
+	}
+	return deserializer.DeserializeEvent(eventType, data)
+}
+
+// eventSchemaVersion определяет версию схемы для сохранения нового события
+func eventSchemaVersion(deserializer EventDeserializer, event events.Event) int {
+	if versioned, ok := event.(SchemaVersionedEvent); ok {
+		return versioned.SchemaVersion()
+	}
+	if versioned, ok := deserializer.(VersionedEventDeserializer); ok {
+		return versioned.CurrentSchemaVersion(event.EventType())
+	}
+	return DefaultSchemaVersion
+}
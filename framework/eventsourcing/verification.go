@@ -0,0 +1,403 @@
+package eventsourcing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Источники расхождений при верификации агрегата
+const (
+	// DivergenceSourceSnapshot состояние после replay не совпадает с последним снапшотом
+	DivergenceSourceSnapshot = "snapshot"
+	// DivergenceSourceDeterminism повторный replay потока дал другое состояние
+	// (Apply зависит от времени, случайных значений или порядка обхода map)
+	DivergenceSourceDeterminism = "determinism"
+	// DivergenceSourceVersion версия агрегата после replay не совпадает с версией потока
+	DivergenceSourceVersion = "version"
+)
+
+// Divergence расхождение состояния агрегата, восстановленного replay, с сохраненными данными
+type Divergence struct {
+	// Source источник расхождения: DivergenceSource* или имя read model
+	Source string
+	// Path путь к полю в JSON-представлении ("" - корень)
+	Path string
+	// Expected значение, полученное replay текущей логикой Apply
+	Expected interface{}
+	// Actual значение в снапшоте или read model
+	Actual interface{}
+}
+
+// String возвращает читаемое описание расхождения
+func (d Divergence) String() string {
+	path := d.Path
+	if path == "" {
+		path = "$"
+	}
+	return fmt.Sprintf("%s %s: expected %v, actual %v", d.Source, path, d.Expected, d.Actual)
+}
+
+// AggregateVerification результат верификации одного агрегата
+type AggregateVerification struct {
+	AggregateID string
+	// Version версия агрегата после replay
+	Version int64
+	// EventCount количество событий потока
+	EventCount int
+	// SnapshotVersion версия проверенного снапшота (0 - снапшот не проверялся)
+	SnapshotVersion int64
+	Divergences     []Divergence
+	// Error ошибка replay (например, Apply вернул ошибку); расхождения в этом случае не проверяются
+	Error error
+}
+
+// Diverged возвращает true, если обнаружены расхождения
+func (v *AggregateVerification) Diverged() bool {
+	return len(v.Divergences) > 0
+}
+
+// VerificationReport сводный результат верификации агрегатов
+type VerificationReport struct {
+	Aggregates []AggregateVerification
+	// Verified агрегаты без расхождений
+	Verified int
+	// Diverged агрегаты с расхождениями
+	Diverged int
+	// Failed агрегаты, replay которых завершился ошибкой
+	Failed int
+}
+
+// Add учитывает результат верификации агрегата в отчете
+func (r *VerificationReport) Add(verification AggregateVerification) {
+	r.Aggregates = append(r.Aggregates, verification)
+	switch {
+	case verification.Error != nil:
+		r.Failed++
+	case verification.Diverged():
+		r.Diverged++
+	default:
+		r.Verified++
+	}
+}
+
+// StreamVerifier проверяет поток событий агрегата (реализуется AggregateVerifier)
+type StreamVerifier interface {
+	Verify(ctx context.Context, aggregateID string) (*AggregateVerification, error)
+}
+
+// ReadModelComparator сравнивает read model с состоянием агрегата, восстановленным replay
+type ReadModelComparator[T AggregateInterface] interface {
+	CompareReadModel(ctx context.Context, aggregate T) ([]Divergence, error)
+}
+
+// ReadModelComparatorFunc адаптер функции к ReadModelComparator
+type ReadModelComparatorFunc[T AggregateInterface] func(ctx context.Context, aggregate T) ([]Divergence, error)
+
+// CompareReadModel вызывает функцию
+func (f ReadModelComparatorFunc[T]) CompareReadModel(ctx context.Context, aggregate T) ([]Divergence, error) {
+	return f(ctx, aggregate)
+}
+
+// namedComparator read model, зарегистрированная в верификаторе
+type namedComparator[T AggregateInterface] struct {
+	name       string
+	comparator ReadModelComparator[T]
+}
+
+// AggregateVerifier детерминированный replay потока агрегата через текущую логику Apply
+// со сравнением результата с последним снапшотом и read models.
+// Используется для проверки рефакторинга доменной логики: расхождение означает,
+// что новая логика Apply восстанавливает состояние иначе, чем старая.
+type AggregateVerifier[T AggregateInterface] struct {
+	eventStore    EventStore
+	factory       AggregateFactory[T]
+	snapshotStore SnapshotStore
+	serializer    SnapshotSerializer
+	readModels    []namedComparator[T]
+}
+
+// NewAggregateVerifier создает новый верификатор агрегатов
+func NewAggregateVerifier[T AggregateInterface](eventStore EventStore, factory AggregateFactory[T]) *AggregateVerifier[T] {
+	return &AggregateVerifier[T]{
+		eventStore: eventStore,
+		factory:    factory,
+		serializer: NewJSONSnapshotSerializer(),
+	}
+}
+
+// WithSnapshotStore включает сравнение с последним снапшотом агрегата
+func (v *AggregateVerifier[T]) WithSnapshotStore(store SnapshotStore) *AggregateVerifier[T] {
+	v.snapshotStore = store
+	return v
+}
+
+// WithSerializer устанавливает сериализатор состояния (должен совпадать с RepositoryConfig.Serializer)
+func (v *AggregateVerifier[T]) WithSerializer(serializer SnapshotSerializer) *AggregateVerifier[T] {
+	v.serializer = serializer
+	return v
+}
+
+// WithReadModel регистрирует read model для сравнения с восстановленным агрегатом
+func (v *AggregateVerifier[T]) WithReadModel(name string, comparator ReadModelComparator[T]) *AggregateVerifier[T] {
+	v.readModels = append(v.readModels, namedComparator[T]{name: name, comparator: comparator})
+	return v
+}
+
+// Verify восстанавливает агрегат из полного потока событий и сравнивает его со снапшотом
+// и read models. Ошибка возвращается только при недоступности хранилищ; ошибки Apply
+// записываются в AggregateVerification.Error.
+func (v *AggregateVerifier[T]) Verify(ctx context.Context, aggregateID string) (*AggregateVerification, error) {
+	if v.factory == nil {
+		return nil, fmt.Errorf("aggregate factory not set")
+	}
+
+	storedEvents, err := v.eventStore.GetEvents(ctx, aggregateID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events of aggregate %s: %w", aggregateID, err)
+	}
+
+	verification := &AggregateVerification{AggregateID: aggregateID}
+
+	aggregate, err := v.replay(aggregateID, storedEvents, -1)
+	if err != nil {
+		verification.Error = err
+		return verification, nil
+	}
+	verification.Version = aggregate.Version()
+	verification.EventCount = len(storedEvents)
+
+	if len(storedEvents) > 0 {
+		if streamVersion := storedEvents[len(storedEvents)-1].Version; streamVersion != aggregate.Version() {
+			verification.Divergences = append(verification.Divergences, Divergence{
+				Source:   DivergenceSourceVersion,
+				Expected: aggregate.Version(),
+				Actual:   streamVersion,
+			})
+		}
+	}
+
+	divergences, err := v.checkDeterminism(aggregateID, storedEvents, aggregate)
+	if err != nil {
+		verification.Error = err
+		return verification, nil
+	}
+	verification.Divergences = append(verification.Divergences, divergences...)
+
+	if v.snapshotStore != nil {
+		divergences, snapshotVersion, err := v.compareSnapshot(ctx, aggregateID, storedEvents)
+		if err != nil {
+			verification.Error = err
+			return verification, nil
+		}
+		verification.SnapshotVersion = snapshotVersion
+		verification.Divergences = append(verification.Divergences, divergences...)
+	}
+
+	for _, readModel := range v.readModels {
+		divergences, err := readModel.comparator.CompareReadModel(ctx, aggregate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare read model %s for aggregate %s: %w", readModel.name, aggregateID, err)
+		}
+		for _, divergence := range divergences {
+			if divergence.Source == "" {
+				divergence.Source = readModel.name
+			}
+			verification.Divergences = append(verification.Divergences, divergence)
+		}
+	}
+
+	return verification, nil
+}
+
+// VerifyAll верифицирует агрегаты хранилища, соответствующие фильтру (см. VerifyAggregates)
+func (v *AggregateVerifier[T]) VerifyAll(ctx context.Context, filter AggregateFilter) (*VerificationReport, error) {
+	return VerifyAggregates(ctx, v.eventStore, v, filter)
+}
+
+// VerifyAggregates верифицирует все агрегаты хранилища, соответствующие фильтру,
+// читая их постранично через ListAggregates начиная с filter.After
+func VerifyAggregates(ctx context.Context, store EventStore, verifier StreamVerifier, filter AggregateFilter) (*VerificationReport, error) {
+	report := &VerificationReport{}
+	for {
+		page, err := ListAggregates(ctx, store, filter)
+		if err != nil {
+			return report, fmt.Errorf("failed to list aggregates: %w", err)
+		}
+
+		for _, summary := range page.Aggregates {
+			verification, err := verifier.Verify(ctx, summary.AggregateID)
+			if err != nil {
+				return report, err
+			}
+			report.Add(*verification)
+		}
+
+		if page.NextCursor == "" {
+			return report, nil
+		}
+		filter.After = page.NextCursor
+	}
+}
+
+// replay применяет события к новому агрегату так же, как EventSourcedRepository.
+// upToVersion ограничивает replay версией (-1 - весь поток).
+func (v *AggregateVerifier[T]) replay(aggregateID string, storedEvents []StoredEvent, upToVersion int64) (T, error) {
+	aggregate := v.factory(aggregateID)
+	for _, stored := range storedEvents {
+		if upToVersion >= 0 && stored.Version > upToVersion {
+			break
+		}
+		if stored.EventData == nil {
+			continue
+		}
+		if err := aggregate.Apply(stored.EventData); err != nil {
+			var zero T
+			return zero, fmt.Errorf("%w: failed to apply event %s (version %d): %w",
+				ErrRehydrationFailed, stored.EventData.EventType(), stored.Version, err)
+		}
+		aggregate.SetVersion(aggregate.Version() + 1)
+	}
+	return aggregate, nil
+}
+
+// checkDeterminism повторяет replay и сравнивает сериализованные состояния
+func (v *AggregateVerifier[T]) checkDeterminism(aggregateID string, storedEvents []StoredEvent, aggregate T) ([]Divergence, error) {
+	again, err := v.replay(aggregateID, storedEvents, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := v.serializer.Serialize(aggregate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize aggregate %s: %w", aggregateID, err)
+	}
+	second, err := v.serializer.Serialize(again)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize aggregate %s: %w", aggregateID, err)
+	}
+	return CompareStates(DivergenceSourceDeterminism, first, second)
+}
+
+// compareSnapshot сравнивает последний снапшот с состоянием после replay до версии снапшота
+func (v *AggregateVerifier[T]) compareSnapshot(ctx context.Context, aggregateID string, storedEvents []StoredEvent) ([]Divergence, int64, error) {
+	snapshot, err := v.snapshotStore.GetSnapshot(ctx, aggregateID)
+	if err != nil || snapshot == nil {
+		// Снапшот отсутствует или поврежден - сравнивать не с чем
+		return nil, 0, nil
+	}
+
+	aggregate, err := v.replay(aggregateID, storedEvents, snapshot.Version)
+	if err != nil {
+		return nil, snapshot.Version, err
+	}
+	if aggregate.Version() != snapshot.Version {
+		return []Divergence{{
+			Source:   DivergenceSourceSnapshot,
+			Path:     "version",
+			Expected: aggregate.Version(),
+			Actual:   snapshot.Version,
+		}}, snapshot.Version, nil
+	}
+
+	state, err := v.serializer.Serialize(aggregate)
+	if err != nil {
+		return nil, snapshot.Version, fmt.Errorf("failed to serialize aggregate %s: %w", aggregateID, err)
+	}
+	divergences, err := CompareStates(DivergenceSourceSnapshot, state, snapshot.State)
+	return divergences, snapshot.Version, err
+}
+
+// CompareStates сравнивает два JSON-состояния и возвращает расхождения по полям.
+// expected и actual могут быть JSON ([]byte, json.RawMessage) или значениями,
+// которые сериализуются в JSON (например, структурой read model).
+func CompareStates(source string, expected, actual interface{}) ([]Divergence, error) {
+	expectedValue, err := normalizeState(expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode expected state: %w", err)
+	}
+	actualValue, err := normalizeState(actual)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode actual state: %w", err)
+	}
+
+	var divergences []Divergence
+	diffStates(source, "", expectedValue, actualValue, &divergences)
+	return divergences, nil
+}
+
+// normalizeState приводит состояние к обобщенному JSON-представлению (map, slice, скаляры)
+func normalizeState(state interface{}) (interface{}, error) {
+	var data []byte
+	switch s := state.(type) {
+	case []byte:
+		data = s
+	case json.RawMessage:
+		data = s
+	default:
+		var err error
+		if data, err = json.Marshal(state); err != nil {
+			return nil, err
+		}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// diffStates рекурсивно сравнивает JSON-значения
+func diffStates(source, path string, expected, actual interface{}, divergences *[]Divergence) {
+	expectedMap, expectedIsMap := expected.(map[string]interface{})
+	actualMap, actualIsMap := actual.(map[string]interface{})
+	if expectedIsMap && actualIsMap {
+		keys := make(map[string]struct{}, len(expectedMap)+len(actualMap))
+		for key := range expectedMap {
+			keys[key] = struct{}{}
+		}
+		for key := range actualMap {
+			keys[key] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			diffStates(source, joinStatePath(path, key), expectedMap[key], actualMap[key], divergences)
+		}
+		return
+	}
+
+	expectedSlice, expectedIsSlice := expected.([]interface{})
+	actualSlice, actualIsSlice := actual.([]interface{})
+	if expectedIsSlice && actualIsSlice && len(expectedSlice) == len(actualSlice) {
+		for i := range expectedSlice {
+			diffStates(source, path+"["+strconv.Itoa(i)+"]", expectedSlice[i], actualSlice[i], divergences)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*divergences = append(*divergences, Divergence{
+			Source:   source,
+			Path:     path,
+			Expected: expected,
+			Actual:   actual,
+		})
+	}
+}
+
+// joinStatePath добавляет поле к пути
+func joinStatePath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -122,3 +122,17 @@ type TransitionHook func(ctx context.Context, from State, to State, event Event)
 // Guard функция-охранник, проверяющая возможность перехода
 type Guard func(ctx context.Context, from State, to State, event Event) (bool, error)
 
+// AllGuards объединяет охранники: переход разрешен, если его разрешают все охранники (nil пропускаются)
+func AllGuards(guards ...Guard) Guard {
+	return func(ctx context.Context, from State, to State, event Event) (bool, error) {
+		for _, guard := range guards {
+			if guard == nil {
+				continue
+			}
+			if can, err := guard(ctx, from, to, event); err != nil || !can {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
@@ -157,7 +157,8 @@ func (f *FSM) Trigger(ctx context.Context, event Event) error {
 	defer f.mu.Unlock()
 
 	current := f.currentState
-	transitions := f.GetTransitions(current, event.Name())
+	// Блокировка уже захвачена - GetTransitions (RLock) здесь привел бы к взаимоблокировке
+	transitions := f.transitions[fmt.Sprintf("%s:%s", current.Name(), event.Name())]
 
 	if len(transitions) == 0 {
 		// Добавляем в очередь, если переход не найден
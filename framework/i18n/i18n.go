@@ -0,0 +1,295 @@
+// Package i18n предоставляет каталоги сообщений об ошибках и выбор языка ответа по Accept-Language.
+package i18n
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/validation"
+)
+
+// Коды ошибок сгенерированных REST обработчиков
+const (
+	CodeInvalidRequest = "INVALID_REQUEST"
+	CodeIDRequired     = "ID_REQUIRED"
+	CodeCommandFailed  = "COMMAND_FAILED"
+	CodeQueryFailed    = "QUERY_FAILED"
+)
+
+// validationKeyPrefix префикс ключей сообщений правил валидации полей
+const validationKeyPrefix = "validation."
+
+// ErrorResponse локализованное тело ответа с ошибкой.
+// Поле error совпадает с ответом без локализации ({"error": "..."}).
+type ErrorResponse struct {
+	Error  string       `json:"error"`
+	Code   string       `json:"code"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError локализованное нарушение правила валидации поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ErrorLocalizer хук локализации ошибок REST обработчиков
+type ErrorLocalizer interface {
+	// Negotiate выбирает локаль ответа по заголовку Accept-Language
+	Negotiate(acceptLanguage string) string
+	// LocalizeError формирует ответ на языке locale; fallbackCode используется для ошибок без кода
+	LocalizeError(locale, fallbackCode string, err error) ErrorResponse
+}
+
+// Catalog каталог сообщений по локалям, ключами служат коды ошибок
+// (VALIDATION_FAILED, NOT_FOUND, коды доменных ошибок) и правила валидации
+// ("validation.required", "validation.min_length", "validation.<field>.<rule>").
+// Сообщения могут содержать параметры в фигурных скобках: {field}, {min}, {max},
+// {min_length}, {max_length}, {pattern}.
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// NewCatalog создает каталог с локалью по умолчанию, которая выбирается,
+// если ни одна локаль из Accept-Language не поддерживается
+func NewCatalog(defaultLocale string) *Catalog {
+	return &Catalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		messages:      make(map[string]map[string]string),
+	}
+}
+
+// NewDefaultCatalog создает каталог со встроенными сообщениями на английском и русском
+func NewDefaultCatalog(defaultLocale string) *Catalog {
+	return NewCatalog(defaultLocale).
+		Add("en", DefaultMessages("en")).
+		Add("ru", DefaultMessages("ru"))
+}
+
+// Add добавляет сообщения локали, заменяя сообщения с теми же ключами
+func (c *Catalog) Add(locale string, messages map[string]string) *Catalog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = normalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]string, len(messages))
+	}
+	for key, message := range messages {
+		c.messages[locale][key] = message
+	}
+	return c
+}
+
+// DefaultLocale возвращает локаль по умолчанию
+func (c *Catalog) DefaultLocale() string {
+	return c.defaultLocale
+}
+
+// Locales возвращает локали каталога в алфавитном порядке
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Negotiate выбирает локаль по заголовку Accept-Language с учетом весов q.
+// Региональная локаль (ru-RU) сопоставляется с языком каталога (ru), если она не добавлена явно.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			return c.defaultLocale
+		}
+		if _, ok := c.messages[tag]; ok {
+			return tag
+		}
+		if base, _, found := strings.Cut(tag, "-"); found {
+			if _, ok := c.messages[base]; ok {
+				return base
+			}
+		}
+	}
+	return c.defaultLocale
+}
+
+// Translate возвращает сообщение key на языке locale, подставляя параметры.
+// Если сообщения нет в локали, используется локаль по умолчанию.
+func (c *Catalog) Translate(locale, key string, params map[string]string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	message, ok := c.messages[normalizeLocale(locale)][key]
+	if !ok {
+		message, ok = c.messages[c.defaultLocale][key]
+	}
+	if !ok {
+		return "", false
+	}
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message, true
+}
+
+// LocalizeError формирует ответ с ошибкой на языке locale. Код ошибки определяется ErrorCode;
+// нарушения правил *validation.Error переводятся по отдельности. Ошибки без сообщения
+// в каталоге возвращают исходный текст.
+func (c *Catalog) LocalizeError(locale, fallbackCode string, err error) ErrorResponse {
+	code := ErrorCode(err, fallbackCode)
+	response := ErrorResponse{Error: err.Error(), Code: code}
+	if message, ok := c.Translate(locale, code, nil); ok {
+		response.Error = message
+	}
+
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		for _, field := range validationErr.Fields {
+			response.Fields = append(response.Fields, c.localizeField(locale, field))
+		}
+	}
+	return response
+}
+
+// localizeField переводит нарушение правила: сначала сообщение для поля, затем для правила
+func (c *Catalog) localizeField(locale string, field validation.FieldError) FieldError {
+	params := map[string]string{"field": field.Field}
+	for name, value := range field.Params {
+		params[name] = value
+	}
+
+	keys := []string{validationKeyPrefix + field.Field + "." + field.Rule}
+	for _, variant := range []string{"min_length", "max_length"} {
+		if _, ok := field.Params[variant]; ok {
+			keys = append(keys, validationKeyPrefix+variant)
+		}
+	}
+	keys = append(keys, validationKeyPrefix+field.Rule)
+
+	localized := FieldError{Field: field.Field, Rule: field.Rule, Message: field.Message}
+	for _, key := range keys {
+		if message, ok := c.Translate(locale, key, params); ok {
+			localized.Message = message
+			break
+		}
+	}
+	return localized
+}
+
+// ErrorCode возвращает код ошибки: VALIDATION_FAILED для *validation.Error, Code для
+// *core.FrameworkError, результат ErrorCode() для доменных ошибок, иначе fallback
+func ErrorCode(err error, fallback string) string {
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		return validation.ErrorCode
+	}
+	var frameworkErr *core.FrameworkError
+	if errors.As(err, &frameworkErr) && frameworkErr.Code != "" {
+		return frameworkErr.Code
+	}
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) && coded.ErrorCode() != "" {
+		return coded.ErrorCode()
+	}
+	return fallback
+}
+
+// parseAcceptLanguage возвращает теги Accept-Language в порядке убывания веса q
+func parseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag    string
+		weight float64
+	}
+
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalizeLocale(tag)
+		if tag == "" {
+			continue
+		}
+		weight := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = parsed
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, weight: weight})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].weight > tags[j].weight
+	})
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+// normalizeLocale приводит тег языка к виду "ru" или "pt-br"
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// DefaultMessages возвращает встроенные сообщения локали ("en" или "ru")
+// для кодов ошибок фреймворка и правил валидации
+func DefaultMessages(locale string) map[string]string {
+	messages := defaultMessages[normalizeLocale(locale)]
+	result := make(map[string]string, len(messages))
+	for key, message := range messages {
+		result[key] = message
+	}
+	return result
+}
+
+var defaultMessages = map[string]map[string]string{
+	"en": {
+		validation.ErrorCode:    "Request validation failed",
+		core.ErrNotFound:        "Resource not found",
+		core.ErrAlreadyExists:   "Resource already exists",
+		CodeInvalidRequest:      "Invalid request",
+		CodeIDRequired:          "id parameter is required",
+		CodeCommandFailed:       "Command failed",
+		CodeQueryFailed:         "Query failed",
+		"validation.required":   "{field} is required",
+		"validation.min":        "{field} must be greater than or equal to {min}",
+		"validation.max":        "{field} must be less than or equal to {max}",
+		"validation.min_length": "{field} length must be at least {min_length}",
+		"validation.max_length": "{field} length must be at most {max_length}",
+		"validation.regex":      "{field} has invalid format",
+	},
+	"ru": {
+		validation.ErrorCode:    "Ошибка валидации запроса",
+		core.ErrNotFound:        "Ресурс не найден",
+		core.ErrAlreadyExists:   "Ресурс уже существует",
+		CodeInvalidRequest:      "Некорректный запрос",
+		CodeIDRequired:          "Не указан параметр id",
+		CodeCommandFailed:       "Не удалось выполнить команду",
+		CodeQueryFailed:         "Не удалось выполнить запрос",
+		"validation.required":   "Поле {field} обязательно",
+		"validation.min":        "Значение {field} должно быть не меньше {min}",
+		"validation.max":        "Значение {field} должно быть не больше {max}",
+		"validation.min_length": "Длина {field} должна быть не меньше {min_length}",
+		"validation.max_length": "Длина {field} должна быть не больше {max_length}",
+		"validation.regex":      "Поле {field} имеет неверный формат",
+	},
+}
@@ -0,0 +1,82 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/validation"
+)
+
+func TestCatalog_Negotiate(t *testing.T) {
+	catalog := NewDefaultCatalog("en").Add("pt-BR", map[string]string{CodeInvalidRequest: "Requisição inválida"})
+
+	cases := map[string]string{
+		"":                        "en",
+		"ru-RU,ru;q=0.9,en;q=0.8": "ru",
+		"de-DE,en;q=0.5,ru;q=0.7": "ru",
+		"pt-BR":                   "pt-br",
+		"fr, *;q=0.1":             "en",
+		"ru;q=0, en-US":           "en",
+		"ja":                      "en",
+	}
+	for header, expected := range cases {
+		if actual := catalog.Negotiate(header); actual != expected {
+			t.Errorf("Negotiate(%q) = %q, expected %q", header, actual, expected)
+		}
+	}
+}
+
+func TestCatalog_LocalizeValidationError(t *testing.T) {
+	catalog := NewDefaultCatalog("en").Add("ru", map[string]string{
+		"validation.sku.regex": "Артикул должен иметь вид ABC-123",
+	})
+
+	v := validation.New()
+	v.Required("name", "")
+	v.MaxLength("tags", 3, 2)
+	v.Min("price", -1, 0)
+	v.Match("sku", "abc", regexp.MustCompile(`^[A-Z]{3}-\d+$`))
+	err := fmt.Errorf("send command: %w", v.Err("create_product"))
+
+	response := catalog.LocalizeError("ru", CodeCommandFailed, err)
+	if response.Code != validation.ErrorCode || response.Error != "Ошибка валидации запроса" {
+		t.Fatalf("Expected localized validation error, got %+v", response)
+	}
+	expected := []string{
+		"Поле name обязательно",
+		"Длина tags должна быть не больше 2",
+		"Значение price должно быть не меньше 0",
+		"Артикул должен иметь вид ABC-123",
+	}
+	if len(response.Fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %+v", len(expected), response.Fields)
+	}
+	for i, field := range response.Fields {
+		if field.Message != expected[i] {
+			t.Errorf("Field %s message = %q, expected %q", field.Field, field.Message, expected[i])
+		}
+	}
+}
+
+func TestCatalog_LocalizeDomainErrors(t *testing.T) {
+	catalog := NewDefaultCatalog("en").Add("ru", map[string]string{"INSUFFICIENT_STOCK": "Недостаточно товара"})
+
+	response := catalog.LocalizeError("ru", CodeCommandFailed, core.NewError("INSUFFICIENT_STOCK", "not enough stock"))
+	if response.Code != "INSUFFICIENT_STOCK" || response.Error != "Недостаточно товара" {
+		t.Errorf("Expected localized domain error, got %+v", response)
+	}
+
+	// Сообщения нет в каталоге - возвращается исходный текст ошибки
+	response = catalog.LocalizeError("ru", CodeCommandFailed, core.NewError("ORDER_CLOSED", "order is closed"))
+	if response.Code != "ORDER_CLOSED" || response.Error != "[ORDER_CLOSED] order is closed" {
+		t.Errorf("Expected original message for unknown code, got %+v", response)
+	}
+
+	response = catalog.LocalizeError("en", CodeQueryFailed, errors.New("connection refused"))
+	if response.Code != CodeQueryFailed || response.Error != "Query failed" {
+		t.Errorf("Expected fallback code message, got %+v", response)
+	}
+}
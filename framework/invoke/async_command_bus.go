@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/metrics"
 	"github.com/akriventsev/potter/framework/transport"
 )
@@ -17,6 +18,7 @@ type AsyncCommandBus struct {
 	subjectResolver SubjectResolver
 	idGenerator    func() string
 	metrics        *metrics.Metrics
+	inflight       core.InFlight
 }
 
 // NewAsyncCommandBus создает новый AsyncCommandBus
@@ -76,8 +78,14 @@ func (b *AsyncCommandBus) WithMetrics(m *metrics.Metrics) *AsyncCommandBus {
 	return b
 }
 
-// SendAsync публикует команду асинхронно (pure produce)
+// SendAsync публикует команду асинхронно (pure produce).
+// После Close возвращает ошибку с кодом ErrCommandBusClosed.
 func (b *AsyncCommandBus) SendAsync(ctx context.Context, cmd transport.Command, metadata *transport.BaseCommandMetadata) error {
+	if !b.inflight.Begin() {
+		return NewCommandBusClosedError(cmd.CommandName())
+	}
+	defer b.inflight.End()
+
 	start := time.Now()
 
 	// Генерируем ID и correlation ID если не указаны
@@ -113,6 +121,12 @@ func (b *AsyncCommandBus) SendAsync(ctx context.Context, cmd transport.Command,
 		"timestamp":      metadata.Timestamp().Format(time.RFC3339),
 		"command_name":   cmd.CommandName(),
 	}
+	if idempotencyKey := ExtractIdempotencyKey(ctx); idempotencyKey != "" {
+		headers[IdempotencyKeyKey] = idempotencyKey
+	}
+	if source := ExtractCommandSource(ctx); source != CommandSourceUnknown {
+		headers[CommandSourceKey] = string(source)
+	}
 
 	// Публикуем команду (fire-and-forget)
 	err = b.pubSub.Publish(ctx, subject, data, headers)
@@ -130,3 +144,28 @@ func (b *AsyncCommandBus) SendAsync(ctx context.Context, cmd transport.Command,
 	return nil
 }
 
+// Flush дожидается завершения выполняющихся SendAsync и, если publisher буферизует
+// сообщения (core.Flushable, например NATS), подтверждения их получения брокером
+func (b *AsyncCommandBus) Flush(ctx context.Context) error {
+	if err := b.inflight.Wait(ctx); err != nil {
+		return fmt.Errorf("failed to wait for in-flight commands: %w", err)
+	}
+	if flusher, ok := b.pubSub.(core.Flushable); ok {
+		if err := flusher.Flush(ctx); err != nil {
+			return fmt.Errorf("failed to flush command publisher: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close прекращает прием новых команд и выполняет Flush. Повторный вызов
+// только дожидается публикации уже принятых команд.
+func (b *AsyncCommandBus) Close(ctx context.Context) error {
+	b.inflight.Close()
+	return b.Flush(ctx)
+}
+
+// Dispose закрывает шину при остановке контейнера (реализация core.Disposable)
+func (b *AsyncCommandBus) Dispose(ctx context.Context) error {
+	return b.Close(ctx)
+}
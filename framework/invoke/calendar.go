@@ -0,0 +1,155 @@
+// Package invoke предоставляет бизнес-календари для планирования команд.
+package invoke
+
+import (
+	"sync"
+	"time"
+)
+
+// BusinessCalendar бизнес-календарь: определяет, когда разрешено отправлять отложенные команды
+// (рабочие дни, праздники, рабочие часы). Календари регистрируются в планировщике по имени
+// (CommandScheduler.WithCalendar) и выбираются для каждой отложенной команды (OnCalendar).
+type BusinessCalendar interface {
+	// IsBusinessTime проверяет, является ли момент t рабочим
+	IsBusinessTime(t time.Time) bool
+	// NextBusinessTime возвращает t, если момент рабочий, иначе ближайший следующий рабочий момент
+	NextBusinessTime(t time.Time) time.Time
+}
+
+// dateLayout формат ключа праздничного дня
+const dateLayout = "2006-01-02"
+
+// calendarSearchDays максимальное количество дней, просматриваемых в поиске рабочего дня
+const calendarSearchDays = 366 * 2
+
+// WorkCalendar бизнес-календарь с выходными днями недели, праздниками и рабочими часами
+// в часовом поясе календаря. По умолчанию выходные - суббота и воскресенье, рабочие часы не ограничены.
+type WorkCalendar struct {
+	mu       sync.RWMutex
+	location *time.Location
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool
+	// workStart и workEnd смещения начала и конца рабочего дня (workEnd == 0 - без ограничений)
+	workStart time.Duration
+	workEnd   time.Duration
+}
+
+// NewWorkCalendar создает новый WorkCalendar в часовом поясе location (nil - UTC)
+func NewWorkCalendar(location *time.Location) *WorkCalendar {
+	if location == nil {
+		location = time.UTC
+	}
+	return &WorkCalendar{
+		location: location,
+		weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		holidays: make(map[string]bool),
+	}
+}
+
+// WithWeekend заменяет выходные дни недели
+func (c *WorkCalendar) WithWeekend(days ...time.Weekday) *WorkCalendar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.weekend = make(map[time.Weekday]bool, len(days))
+	for _, day := range days {
+		c.weekend[day] = true
+	}
+	return c
+}
+
+// WithHolidays добавляет праздничные дни. Учитывается только дата (год, месяц, день) значения,
+// например time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC).
+func (c *WorkCalendar) WithHolidays(dates ...time.Time) *WorkCalendar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, date := range dates {
+		c.holidays[date.Format(dateLayout)] = true
+	}
+	return c
+}
+
+// WithWorkingHours ограничивает рабочий день интервалом [start, end) от начала суток,
+// например WithWorkingHours(9*time.Hour, 18*time.Hour)
+func (c *WorkCalendar) WithWorkingHours(start, end time.Duration) *WorkCalendar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.workStart = start
+	c.workEnd = end
+	return c
+}
+
+// Location возвращает часовой пояс календаря
+func (c *WorkCalendar) Location() *time.Location {
+	return c.location
+}
+
+// IsBusinessDay проверяет, является ли дата t рабочим днем
+func (c *WorkCalendar) IsBusinessDay(t time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isBusinessDay(t.In(c.location))
+}
+
+// IsBusinessTime проверяет, приходится ли t на рабочий день и рабочие часы
+func (c *WorkCalendar) IsBusinessTime(t time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	local := t.In(c.location)
+	if !c.isBusinessDay(local) {
+		return false
+	}
+	if c.workEnd == 0 {
+		return true
+	}
+	offset := clockOffset(local)
+	return offset >= c.workStart && offset < c.workEnd
+}
+
+// NextBusinessTime возвращает t, если момент рабочий, иначе ближайший рабочий момент.
+// До начала рабочих часов момент переносится на их начало в тот же день. При переносе на
+// другой день сохраняется время суток, а если заданы рабочие часы - выбирается их начало.
+func (c *WorkCalendar) NextBusinessTime(t time.Time) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	local := t.In(c.location)
+	offset := clockOffset(local)
+
+	if c.isBusinessDay(local) {
+		if c.workEnd == 0 || (offset >= c.workStart && offset < c.workEnd) {
+			return t
+		}
+		if offset < c.workStart {
+			return atClockOffset(local, 0, c.workStart)
+		}
+	}
+	if c.workEnd != 0 {
+		offset = c.workStart
+	}
+
+	for i := 1; i <= calendarSearchDays; i++ {
+		next := atClockOffset(local, i, 0)
+		if c.isBusinessDay(next) {
+			return atClockOffset(local, i, offset)
+		}
+	}
+	// Рабочих дней нет (все дни недели выходные) - момент не переносится
+	return t
+}
+
+// isBusinessDay проверяет день без блокировки (t в часовом поясе календаря)
+func (c *WorkCalendar) isBusinessDay(t time.Time) bool {
+	return !c.weekend[t.Weekday()] && !c.holidays[t.Format(dateLayout)]
+}
+
+// clockOffset возвращает время суток t (по часам, без учета перехода на летнее время)
+func clockOffset(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// atClockOffset возвращает момент через days дней после даты t со временем суток offset
+func atClockOffset(t time.Time, days int, offset time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, 0, 0, 0, int(offset), t.Location())
+}
@@ -0,0 +1,87 @@
+// Package invoke предоставляет CommandConsumer для приема команд, опубликованных AsyncCommandBus.
+package invoke
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// CommandFactory создает пустой экземпляр команды для десериализации сообщения
+type CommandFactory func() transport.Command
+
+// ContextFromCommandHeaders переносит метаданные команды из заголовков сообщения,
+// заданных AsyncCommandBus, в контекст получателя: correlation, causation и command ID,
+// ключ идемпотентности (для DedupCommandHandler) и источник команды.
+func ContextFromCommandHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if id := headers[CorrelationIDKey]; id != "" {
+		ctx = WithCorrelationID(ctx, id)
+	}
+	if id := headers[CausationIDKey]; id != "" {
+		ctx = WithCausationID(ctx, id)
+	}
+	if id := headers[CommandIDKey]; id != "" {
+		ctx = WithCommandID(ctx, id)
+	}
+	if key := IdempotencyKeyFromHeaders(headers); key != "" {
+		ctx = WithIdempotencyKey(ctx, key)
+	}
+	return ContextWithCommandSourceFromHeaders(ctx, headers)
+}
+
+// CommandConsumer принимает команды из брокера (NATS, Kafka, message bus) и передает их
+// в CommandBus с метаданными из заголовков сообщения (см. ContextFromCommandHeaders).
+// Subject команды определяется тем же SubjectResolver, что и у AsyncCommandBus.
+type CommandConsumer struct {
+	subscriber      transport.Subscriber
+	bus             transport.CommandBus
+	serializer      transport.MessageSerializer
+	subjectResolver SubjectResolver
+}
+
+// NewCommandConsumer создает новый CommandConsumer
+func NewCommandConsumer(subscriber transport.Subscriber, bus transport.CommandBus) *CommandConsumer {
+	return &CommandConsumer{
+		subscriber:      subscriber,
+		bus:             bus,
+		serializer:      DefaultSerializer(),
+		subjectResolver: NewDefaultSubjectResolver("commands", "events"),
+	}
+}
+
+// WithSerializer устанавливает сериализатор (должен совпадать с сериализатором AsyncCommandBus)
+func (c *CommandConsumer) WithSerializer(serializer transport.MessageSerializer) *CommandConsumer {
+	c.serializer = serializer
+	return c
+}
+
+// WithSubjectResolver устанавливает SubjectResolver (должен совпадать с resolver AsyncCommandBus)
+func (c *CommandConsumer) WithSubjectResolver(resolver SubjectResolver) *CommandConsumer {
+	c.subjectResolver = resolver
+	return c
+}
+
+// Subscribe подписывается на subject команды, создаваемой factory
+func (c *CommandConsumer) Subscribe(ctx context.Context, factory CommandFactory) error {
+	subject := c.subjectResolver.ResolveCommandSubject(factory())
+	if subject == "" {
+		return fmt.Errorf("failed to resolve subject for command: %s", factory().CommandName())
+	}
+	if err := c.subscriber.Subscribe(ctx, subject, c.Handler(factory)); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	return nil
+}
+
+// Handler возвращает обработчик сообщений команды, создаваемой factory. Используется напрямую
+// с QueueSubscribe или вместе с Inbox: inbox.Wrap(consumer.Handler(factory)).
+func (c *CommandConsumer) Handler(factory CommandFactory) transport.MessageHandler {
+	return func(ctx context.Context, msg *transport.Message) error {
+		cmd := factory()
+		if err := c.serializer.Deserialize(msg.Data, cmd); err != nil {
+			return fmt.Errorf("failed to deserialize command %s: %w", cmd.CommandName(), err)
+		}
+		return c.bus.Send(ContextFromCommandHeaders(ctx, msg.Headers), cmd)
+	}
+}
@@ -71,6 +71,9 @@ func NewCommandInvokerWithOptions[TCommand transport.Command, TSuccessEvent even
 
 	// Создаем EventAwaiter из EventSource
 	awaiter := NewEventAwaiterFromEventSource(opts.EventSource)
+	if opts.EventLookup != nil {
+		awaiter.WithEventLookup(opts.EventLookup)
+	}
 
 	// Определяем типы событий
 	successEventType := opts.SuccessEventType
@@ -0,0 +1,124 @@
+// Package invoke предоставляет отслеживание источника команд.
+package invoke
+
+import (
+	"context"
+
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// CommandSource источник команды: внешний API, шаг саги, планировщик, replay
+type CommandSource string
+
+const (
+	// CommandSourceUnknown источник не указан
+	CommandSourceUnknown CommandSource = ""
+	// CommandSourceAPI команда получена из внешнего API (REST, gRPC)
+	CommandSourceAPI CommandSource = "api"
+	// CommandSourceSaga команда отправлена шагом саги
+	CommandSourceSaga CommandSource = "saga"
+	// CommandSourceScheduler отложенная команда, отправленная CommandScheduler
+	CommandSourceScheduler CommandSource = "scheduler"
+	// CommandSourceReplay команда, повторно выполняемая при replay
+	CommandSourceReplay CommandSource = "replay"
+)
+
+// CommandSourceKey ключ контекста и заголовка сообщения с источником команды
+const CommandSourceKey = "command_source"
+
+// IsInternal проверяет, что команда отправлена самой системой (сага, планировщик, replay)
+func (s CommandSource) IsInternal() bool {
+	switch s {
+	case CommandSourceSaga, CommandSourceScheduler, CommandSourceReplay:
+		return true
+	}
+	return false
+}
+
+// WithCommandSource добавляет источник команды в контекст
+func WithCommandSource(ctx context.Context, source CommandSource) context.Context {
+	return context.WithValue(ctx, CommandSourceKey, source)
+}
+
+// ExtractCommandSource извлекает источник команды из контекста.
+// Если источник не задан явно, но в контексте есть шаг саги, источником считается сага.
+func ExtractCommandSource(ctx context.Context) CommandSource {
+	if source, ok := ctx.Value(CommandSourceKey).(CommandSource); ok && source != CommandSourceUnknown {
+		return source
+	}
+	if _, ok := ctx.Value(SagaStepKey).(sagaStepInfo); ok {
+		return CommandSourceSaga
+	}
+	return CommandSourceUnknown
+}
+
+// IsReplay проверяет, что команда выполняется при replay.
+// Обработчики используют его, например, чтобы не отправлять уведомления повторно.
+func IsReplay(ctx context.Context) bool {
+	return ExtractCommandSource(ctx) == CommandSourceReplay
+}
+
+// CommandSourceFromHeaders извлекает источник команды из заголовков сообщения
+func CommandSourceFromHeaders(headers map[string]string) CommandSource {
+	return CommandSource(headers[CommandSourceKey])
+}
+
+// ContextWithCommandSourceFromHeaders переносит источник команды из заголовков сообщения в контекст.
+// Используется на стороне получателя асинхронных команд.
+func ContextWithCommandSourceFromHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if source := CommandSourceFromHeaders(headers); source != CommandSourceUnknown {
+		return WithCommandSource(ctx, source)
+	}
+	return ctx
+}
+
+// CommandSourcePolicy решает, разрешено ли выполнение команды из источника source
+type CommandSourcePolicy func(ctx context.Context, cmd transport.Command, source CommandSource) bool
+
+// InternalOnly разрешает только команды, отправленные самой системой (сага, планировщик, replay)
+func InternalOnly() CommandSourcePolicy {
+	return func(ctx context.Context, cmd transport.Command, source CommandSource) bool {
+		return source.IsInternal()
+	}
+}
+
+// AllowSources разрешает только команды из перечисленных источников
+func AllowSources(sources ...CommandSource) CommandSourcePolicy {
+	return func(ctx context.Context, cmd transport.Command, source CommandSource) bool {
+		for _, allowed := range sources {
+			if source == allowed {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SourcePolicyCommandHandler обертка над CommandHandler, отклоняющая команды
+// из источников, не разрешенных политикой (например, внешние команды для внутренних операций)
+type SourcePolicyCommandHandler struct {
+	handler transport.CommandHandler
+	policy  CommandSourcePolicy
+}
+
+// NewSourcePolicyCommandHandler создает обработчик с проверкой источника команды
+func NewSourcePolicyCommandHandler(handler transport.CommandHandler, policy CommandSourcePolicy) *SourcePolicyCommandHandler {
+	return &SourcePolicyCommandHandler{
+		handler: handler,
+		policy:  policy,
+	}
+}
+
+// CommandName возвращает имя команды обернутого обработчика
+func (h *SourcePolicyCommandHandler) CommandName() string {
+	return h.handler.CommandName()
+}
+
+// Handle обрабатывает команду, если ее источник разрешен политикой
+func (h *SourcePolicyCommandHandler) Handle(ctx context.Context, cmd transport.Command) error {
+	source := ExtractCommandSource(ctx)
+	if !h.policy(ctx, cmd, source) {
+		return NewCommandSourceNotAllowedError(cmd.CommandName(), source)
+	}
+	return h.handler.Handle(ctx, cmd)
+}
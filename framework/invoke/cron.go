@@ -0,0 +1,203 @@
+// Package invoke предоставляет cron-расписания для периодических команд.
+package invoke
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears горизонт поиска следующего срабатывания cron-расписания
+const cronSearchYears = 5
+
+// cronDescriptors сокращения стандартных cron-выражений
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	cronWeekdayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// CronSchedule расписание в формате cron из пяти полей: минута, час, день месяца, месяц,
+// день недели (0-7, 0 и 7 - воскресенье). Поддерживаются *, списки, диапазоны, шаги (*/15, 1-5/2),
+// имена месяцев и дней недели (JAN, MON) и сокращения @daily, @hourly и т.д.
+// Время срабатывания вычисляется в часовом поясе расписания.
+type CronSchedule struct {
+	spec     string
+	location *time.Location
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+	// anyDay и anyWeekday - поле задано как *: если ограничены оба поля дня, достаточно совпадения одного
+	anyDay     bool
+	anyWeekday bool
+}
+
+// ParseCron разбирает cron-выражение. Время срабатывания вычисляется в location (nil - UTC).
+func ParseCron(spec string, location *time.Location) (*CronSchedule, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	expr := strings.TrimSpace(spec)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, NewInvalidScheduleError(fmt.Sprintf("cron expression %q must have 5 fields", spec))
+	}
+
+	schedule := &CronSchedule{
+		spec:       spec,
+		location:   location,
+		anyDay:     fields[2] == "*" || fields[2] == "?",
+		anyWeekday: fields[4] == "*" || fields[4] == "?",
+	}
+	var err error
+	if schedule.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, NewInvalidScheduleError(fmt.Sprintf("cron expression %q: minute: %v", spec, err))
+	}
+	if schedule.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, NewInvalidScheduleError(fmt.Sprintf("cron expression %q: hour: %v", spec, err))
+	}
+	if schedule.days, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, NewInvalidScheduleError(fmt.Sprintf("cron expression %q: day of month: %v", spec, err))
+	}
+	if schedule.months, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, NewInvalidScheduleError(fmt.Sprintf("cron expression %q: month: %v", spec, err))
+	}
+	if schedule.weekdays, err = parseCronField(fields[4], 0, 7, cronWeekdayNames); err != nil {
+		return nil, NewInvalidScheduleError(fmt.Sprintf("cron expression %q: day of week: %v", spec, err))
+	}
+	// 7 - альтернативное обозначение воскресенья
+	if schedule.weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+	return schedule, nil
+}
+
+// String возвращает исходное cron-выражение
+func (c *CronSchedule) String() string {
+	return c.spec
+}
+
+// Location возвращает часовой пояс расписания
+func (c *CronSchedule) Location() *time.Location {
+	return c.location
+}
+
+// Next возвращает первое срабатывание расписания строго после after или нулевое время,
+// если срабатываний нет (например, 30 февраля). Несуществующее при переходе на летнее
+// время локальное время пропускается.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	local := after.In(c.location)
+	t := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, c.location).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if !c.months[t.Month()] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.location)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+			continue
+		}
+		if !c.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.location)
+			continue
+		}
+		if !c.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches проверяет день месяца и день недели по правилам cron
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	dayMatch := c.days[t.Day()]
+	weekdayMatch := c.weekdays[t.Weekday()]
+	if c.anyDay || c.anyWeekday {
+		return dayMatch && weekdayMatch
+	}
+	return dayMatch || weekdayMatch
+}
+
+// parseCronField разбирает поле cron-выражения в набор допустимых значений [0, max]
+func parseCronField(field string, min, max int, names map[string]int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], names); err != nil {
+				return nil, err
+			}
+			if end, err = parseCronValue(bounds[1], names); err != nil {
+				return nil, err
+			}
+		default:
+			value, err := parseCronValue(rangePart, names)
+			if err != nil {
+				return nil, err
+			}
+			start = value
+			// Значение с шагом (5/15) означает диапазон от значения до максимума
+			if !strings.Contains(part, "/") {
+				end = value
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// parseCronValue разбирает число или имя месяца/дня недели
+func parseCronValue(value string, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
@@ -17,6 +17,14 @@ const (
 	ErrInvalidSubjectResolver  = "INVALID_SUBJECT_RESOLVER"
 	ErrEventSourceNotConfigured = "EVENT_SOURCE_NOT_CONFIGURED"
 	ErrErrorEventReceived      = "ERROR_EVENT_RECEIVED"
+	ErrSchedulerNotConfigured  = "SCHEDULER_NOT_CONFIGURED"
+	ErrScheduledCommandNotFound = "SCHEDULED_COMMAND_NOT_FOUND"
+	ErrScheduledCommandClaimLost = "SCHEDULED_COMMAND_CLAIM_LOST"
+	ErrDuplicateCommand        = "DUPLICATE_COMMAND"
+	ErrCommandSourceNotAllowed = "COMMAND_SOURCE_NOT_ALLOWED"
+	ErrInboxMessageInProgress  = "INBOX_MESSAGE_IN_PROGRESS"
+	ErrInvalidSchedule         = "INVALID_SCHEDULE"
+	ErrCommandBusClosed        = "COMMAND_BUS_CLOSED"
 )
 
 // NewEventTimeoutError создает ошибку таймаута ожидания события
@@ -44,6 +52,14 @@ func NewCommandPublishFailedError(commandName string, cause error) *core.Framewo
 	)
 }
 
+// NewCommandBusClosedError создает ошибку отправки команды в закрытый AsyncCommandBus
+func NewCommandBusClosedError(commandName string) *core.FrameworkError {
+	return core.NewError(
+		ErrCommandBusClosed,
+		"command bus is closed: "+commandName,
+	)
+}
+
 // NewValidationFailedError создает ошибку валидации
 func NewValidationFailedError(cause error) *core.FrameworkError {
 	return core.Wrap(
@@ -93,6 +109,68 @@ func NewEventSourceNotConfiguredError() *core.FrameworkError {
 	)
 }
 
+// NewSchedulerNotConfiguredError создает ошибку для отсутствующего планировщика команд
+func NewSchedulerNotConfiguredError() *core.FrameworkError {
+	return core.NewError(
+		ErrSchedulerNotConfigured,
+		"command scheduler is not configured",
+	)
+}
+
+// NewScheduledCommandNotFoundError создает ошибку отсутствия отложенной команды
+func NewScheduledCommandNotFoundError(id string) *core.FrameworkError {
+	return core.NewError(
+		ErrScheduledCommandNotFound,
+		"scheduled command not found: id="+id,
+	)
+}
+
+// NewScheduledCommandClaimLostError создает ошибку завершения отложенной команды, которая
+// больше не захвачена этим dispatcher'ом (захват истек и команду забрал другой dispatcher,
+// команда отменена или уже завершена)
+func NewScheduledCommandClaimLostError(id string) *core.FrameworkError {
+	return core.NewError(
+		ErrScheduledCommandClaimLost,
+		"scheduled command is not claimed by this dispatcher: id="+id,
+	)
+}
+
+// NewInvalidScheduleError создает ошибку некорректного расписания (cron-выражение, календарь, хранилище)
+func NewInvalidScheduleError(reason string) *core.FrameworkError {
+	return core.NewError(
+		ErrInvalidSchedule,
+		"invalid schedule: "+reason,
+	)
+}
+
+// NewDuplicateCommandError создает ошибку повторной команды с уже обработанным ключом идемпотентности
+func NewDuplicateCommandError(commandName, idempotencyKey string) *core.FrameworkError {
+	return core.NewError(
+		ErrDuplicateCommand,
+		"duplicate command: command="+commandName+", idempotency_key="+idempotencyKey,
+	)
+}
+
+// NewInboxMessageInProgressError создает ошибку сообщения, обрабатываемого другим консьюмером
+func NewInboxMessageInProgressError(messageID string) *core.FrameworkError {
+	return core.NewError(
+		ErrInboxMessageInProgress,
+		"inbox message is being processed by another consumer: message_id="+messageID,
+	)
+}
+
+// NewCommandSourceNotAllowedError создает ошибку выполнения команды из неразрешенного источника
+func NewCommandSourceNotAllowedError(commandName string, source CommandSource) *core.FrameworkError {
+	sourceName := string(source)
+	if sourceName == "" {
+		sourceName = "unknown"
+	}
+	return core.NewError(
+		ErrCommandSourceNotAllowed,
+		"command source not allowed: command="+commandName+", source="+sourceName,
+	)
+}
+
 // NewErrorEventReceivedError создает ошибку-обертку для полученного ошибочного события
 func NewErrorEventReceivedError(errorEvent ErrorEvent) *core.FrameworkError {
 	var cause error
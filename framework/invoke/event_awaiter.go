@@ -15,10 +15,12 @@ import (
 type EventAwaiter struct {
 	eventSource EventSource
 	waiters     map[string]*eventWaiter
+	groups      map[*eventGroup]struct{} // активные AwaitAll/AwaitAnyOf
 	mu          sync.RWMutex
 	stopCh      chan struct{}
 	stopped     bool
 	wg          sync.WaitGroup
+	backfill    *BackfillConfig
 }
 
 // eventWaiter структура для ожидания события
@@ -37,6 +39,7 @@ func NewEventAwaiter(eventSource EventSource) *EventAwaiter {
 	awaiter := &EventAwaiter{
 		eventSource: eventSource,
 		waiters:     make(map[string]*eventWaiter),
+		groups:      make(map[*eventGroup]struct{}),
 		stopCh:      make(chan struct{}),
 		stopped:     false,
 	}
@@ -67,6 +70,20 @@ func NewEventAwaiterFromEventSource(eventSource EventSource) *EventAwaiter {
 	return NewEventAwaiter(eventSource)
 }
 
+// WithBackfill включает проверку уже сохраненных событий перед блокирующим ожиданием.
+// Позволяет не зависать до timeout, если событие было опубликовано до вызова Await.
+func (a *EventAwaiter) WithBackfill(config BackfillConfig) *EventAwaiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.backfill = &config
+	return a
+}
+
+// WithEventLookup включает backfill с конфигурацией по умолчанию
+func (a *EventAwaiter) WithEventLookup(lookup EventLookup) *EventAwaiter {
+	return a.WithBackfill(DefaultBackfillConfig(lookup))
+}
+
 // Await ожидает событие по correlation ID с timeout
 func (a *EventAwaiter) Await(ctx context.Context, correlationID string, eventType string, timeout time.Duration) (events.Event, error) {
 	a.mu.Lock()
@@ -105,6 +122,11 @@ func (a *EventAwaiter) Await(ctx context.Context, correlationID string, eventTyp
 	// Сохраняем handler в waiter для последующей отписки
 	waiter.handler = handler
 
+	// Проверяем уже сохраненные события (после подписки, чтобы не пропустить событие между проверкой и подпиской)
+	backfillCtx, cancelBackfill := context.WithCancel(ctx)
+	defer cancelBackfill()
+	go a.runBackfill(backfillCtx, waiter, []string{eventType})
+
 	// Ждем событие или timeout
 	select {
 	case event := <-waiter.ch:
@@ -213,6 +235,11 @@ func (a *EventAwaiter) AwaitAny(ctx context.Context, correlationID string, event
 		handlers = append(handlers, handler)
 	}
 
+	// Проверяем уже сохраненные события
+	backfillCtx, cancelBackfill := context.WithCancel(ctx)
+	defer cancelBackfill()
+	go a.runBackfill(backfillCtx, waiter, eventTypes)
+
 	// Ждем событие или timeout
 	select {
 	case event := <-waiter.ch:
@@ -278,12 +305,18 @@ func (a *EventAwaiter) AwaitSuccessOrError(ctx context.Context, correlationID st
 }
 
 // Cancel активно отменяет ожидание события по correlation ID.
-// Метод прерывает блокирующее ожидание в Await/AwaitAny, отправляя сигнал отмены.
+// Метод прерывает блокирующее ожидание в Await/AwaitAny/AwaitAll/AwaitAnyOf, отправляя сигнал отмены.
 // После вызова Cancel waiter будет удален из map, и ожидание немедленно завершится.
 func (a *EventAwaiter) Cancel(correlationID string) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	for group := range a.groups {
+		if group.correlatedWith(correlationID) {
+			group.cancel()
+		}
+	}
+
 	waiter, exists := a.waiters[correlationID]
 	if !exists {
 		return
@@ -0,0 +1,313 @@
+// Package invoke предоставляет групповое ожидание нескольких коррелированных событий.
+package invoke
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// EventExpectation описание ожидаемого события для AwaitAll и AwaitAnyOf
+type EventExpectation struct {
+	// EventType тип события
+	EventType string
+	// CorrelationID correlation ID события (пустой - correlation ID вызова)
+	CorrelationID string
+	// Match дополнительное условие на событие (nil - подходит любое событие типа)
+	Match func(event events.Event) bool
+}
+
+// ExpectEvent создает ожидание события указанного типа
+func ExpectEvent(eventType string) EventExpectation {
+	return EventExpectation{EventType: eventType}
+}
+
+// WithCorrelationID задает correlation ID ожидаемого события
+func (e EventExpectation) WithCorrelationID(correlationID string) EventExpectation {
+	e.CorrelationID = correlationID
+	return e
+}
+
+// WithMatch задает условие, которому должно удовлетворять ожидаемое событие
+func (e EventExpectation) WithMatch(match func(event events.Event) bool) EventExpectation {
+	e.Match = match
+	return e
+}
+
+// matches проверяет, что событие удовлетворяет ожиданию
+func (e EventExpectation) matches(event events.Event, correlationID string) bool {
+	if event.EventType() != e.EventType {
+		return false
+	}
+	if event.Metadata().CorrelationID() != correlationID {
+		return false
+	}
+	return e.Match == nil || e.Match(event)
+}
+
+// eventGroup ожидание группы событий.
+// В отличие от eventWaiter не хранится в waiters по correlation ID,
+// поэтому несколько групп с одним correlation ID не вытесняют друг друга.
+type eventGroup struct {
+	correlationID  string // correlation ID вызова, по нему группу прерывает Cancel
+	expectations   []EventExpectation
+	correlationIDs []string // эффективный correlation ID каждого ожидания
+	results        []events.Event
+	remaining      int
+	anyOf          bool
+	first          int // индекс первого выполненного ожидания (для AwaitAnyOf)
+	finished       bool
+	done           chan struct{}
+	cancelCh       chan struct{}
+	mu             sync.Mutex
+}
+
+// offer сопоставляет событие с первым невыполненным подходящим ожиданием.
+// Одно событие выполняет не больше одного ожидания, поэтому N одинаковых ожиданий
+// требуют N разных событий.
+func (g *eventGroup) offer(event events.Event) {
+	if event == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.finished {
+		return
+	}
+
+	for i, expectation := range g.expectations {
+		if g.results[i] != nil || !expectation.matches(event, g.correlationIDs[i]) {
+			continue
+		}
+		// Повторная доставка того же события (подписка и backfill) не засчитывается дважды
+		if g.delivered(event) {
+			return
+		}
+
+		g.results[i] = event
+		g.remaining--
+		if g.anyOf || g.remaining == 0 {
+			g.first = i
+			g.finished = true
+			close(g.done)
+		}
+		return
+	}
+}
+
+// delivered проверяет, что событие с тем же ID уже выполнило одно из ожиданий
+func (g *eventGroup) delivered(event events.Event) bool {
+	if event.EventID() == "" {
+		return false
+	}
+	for _, result := range g.results {
+		if result != nil && result.EventID() == event.EventID() {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot возвращает копию результатов и типы невыполненных ожиданий
+func (g *eventGroup) snapshot() ([]events.Event, []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	results := make([]events.Event, len(g.results))
+	copy(results, g.results)
+
+	missing := make([]string, 0, g.remaining)
+	for i, result := range g.results {
+		if result == nil {
+			missing = append(missing, g.expectations[i].EventType)
+		}
+	}
+	return results, missing
+}
+
+// correlatedWith проверяет, что группа ожидает события с указанным correlation ID
+func (g *eventGroup) correlatedWith(correlationID string) bool {
+	return g.correlationID == correlationID || slices.Contains(g.correlationIDs, correlationID)
+}
+
+// cancel прерывает ожидание группы
+func (g *eventGroup) cancel() {
+	select {
+	case g.cancelCh <- struct{}{}:
+	default:
+	}
+}
+
+// groupEventHandler доставляет события одного типа группе
+type groupEventHandler struct {
+	group     *eventGroup
+	eventType string
+}
+
+func (h *groupEventHandler) Handle(ctx context.Context, event events.Event) error {
+	h.group.offer(event)
+	return nil
+}
+
+func (h *groupEventHandler) EventType() string {
+	return h.eventType
+}
+
+// AwaitAll ожидает все события, описанные expectations, с общим timeout.
+// Ожидания без CorrelationID сопоставляются по correlationID вызова, Match позволяет
+// различать однотипные события (например, завершения N параллельных команд).
+// Результаты возвращаются в порядке expectations; при timeout возвращаются уже
+// полученные события (nil на месте невыполненных ожиданий) и ошибка EVENT_TIMEOUT.
+func (a *EventAwaiter) AwaitAll(ctx context.Context, correlationID string, expectations []EventExpectation, timeout time.Duration) ([]events.Event, error) {
+	group, err := a.awaitGroup(ctx, correlationID, expectations, timeout, false)
+	if group == nil {
+		return nil, err
+	}
+	results, _ := group.snapshot()
+	return results, err
+}
+
+// AwaitAnyOf ожидает первое событие, удовлетворяющее одному из expectations.
+// Возвращает событие и индекс выполненного ожидания.
+func (a *EventAwaiter) AwaitAnyOf(ctx context.Context, correlationID string, expectations []EventExpectation, timeout time.Duration) (events.Event, int, error) {
+	group, err := a.awaitGroup(ctx, correlationID, expectations, timeout, true)
+	if err != nil {
+		return nil, -1, err
+	}
+	return group.results[group.first], group.first, nil
+}
+
+// awaitGroup подписывается на типы событий группы и блокируется до выполнения группы,
+// timeout, отмены ctx, Cancel по correlation ID или остановки EventAwaiter
+func (a *EventAwaiter) awaitGroup(ctx context.Context, correlationID string, expectations []EventExpectation, timeout time.Duration, anyOf bool) (*eventGroup, error) {
+	if len(expectations) == 0 {
+		return nil, fmt.Errorf("at least one event expectation must be specified")
+	}
+
+	group := &eventGroup{
+		correlationID:  correlationID,
+		expectations:   expectations,
+		correlationIDs: make([]string, len(expectations)),
+		results:        make([]events.Event, len(expectations)),
+		remaining:      len(expectations),
+		anyOf:          anyOf,
+		done:           make(chan struct{}),
+		cancelCh:       make(chan struct{}, 1),
+	}
+	eventTypes := make([]string, 0, len(expectations))
+	for i, expectation := range expectations {
+		if expectation.EventType == "" {
+			return nil, fmt.Errorf("event expectation %d: event type must be specified", i)
+		}
+		group.correlationIDs[i] = expectation.CorrelationID
+		if group.correlationIDs[i] == "" {
+			group.correlationIDs[i] = correlationID
+		}
+		if group.correlationIDs[i] == "" {
+			return nil, NewCorrelationIDNotFoundError()
+		}
+		if !slices.Contains(eventTypes, expectation.EventType) {
+			eventTypes = append(eventTypes, expectation.EventType)
+		}
+	}
+
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return nil, NewEventAwaiterStoppedError()
+	}
+	a.groups[group] = struct{}{}
+	a.mu.Unlock()
+
+	// Одна подписка на каждый тип события независимо от количества ожиданий этого типа
+	handlers := make([]events.EventHandler, 0, len(eventTypes))
+	defer func() {
+		for _, handler := range handlers {
+			_ = a.eventSource.Unsubscribe(handler.EventType(), handler)
+		}
+		a.mu.Lock()
+		delete(a.groups, group)
+		a.mu.Unlock()
+	}()
+	for _, eventType := range eventTypes {
+		handler := &groupEventHandler{group: group, eventType: eventType}
+		if err := a.eventSource.Subscribe(eventType, handler); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to event type %s: %w", eventType, err)
+		}
+		handlers = append(handlers, handler)
+	}
+
+	backfillCtx, cancelBackfill := context.WithCancel(ctx)
+	defer cancelBackfill()
+	go a.runGroupBackfill(backfillCtx, group)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-group.done:
+		return group, nil
+	case <-timer.C:
+		_, missing := group.snapshot()
+		return group, NewEventTimeoutError(correlationID, timeout.String()).WithContext("awaiting " + strings.Join(missing, ", "))
+	case <-ctx.Done():
+		return group, ctx.Err()
+	case <-group.cancelCh:
+		return group, NewEventAwaiterStoppedError()
+	case <-a.stopCh:
+		return group, NewEventAwaiterStoppedError()
+	}
+}
+
+// runGroupBackfill ищет уже сохраненные события для невыполненных ожиданий группы.
+// EventLookup возвращает одно событие, поэтому backfill выполняет ожидание, только если
+// найденное событие удовлетворяет его условию Match.
+func (a *EventAwaiter) runGroupBackfill(ctx context.Context, group *eventGroup) {
+	a.mu.RLock()
+	config := a.backfill
+	a.mu.RUnlock()
+
+	if config == nil || config.Lookup == nil {
+		return
+	}
+
+	attempts := 0
+	for {
+		attempts++
+		for i, expectation := range group.expectations {
+			group.mu.Lock()
+			pending := group.results[i] == nil && !group.finished
+			group.mu.Unlock()
+			if !pending {
+				continue
+			}
+
+			event, err := config.Lookup.FindEvent(ctx, group.correlationIDs[i], []string{expectation.EventType})
+			if err == nil && event != nil {
+				group.offer(event)
+			}
+		}
+
+		select {
+		case <-group.done:
+			return
+		default:
+		}
+		if config.RetryInterval <= 0 || (config.MaxAttempts > 0 && attempts >= config.MaxAttempts) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(config.RetryInterval):
+		}
+	}
+}
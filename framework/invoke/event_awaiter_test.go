@@ -3,10 +3,12 @@ package invoke
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/events"
 )
 
 func TestEventAwaiter_Await_Success(t *testing.T) {
@@ -195,6 +197,110 @@ func TestEventAwaiter_AwaitSuccessOrError(t *testing.T) {
 	}
 }
 
+// TestEventAwaiter_AwaitAll проверяет ожидание группы однотипных событий с предикатами
+func TestEventAwaiter_AwaitAll(t *testing.T) {
+	ctx := context.Background()
+	mockBus := NewMockEventBus()
+	awaiter := NewEventAwaiterFromEventBus(mockBus)
+	defer awaiter.Stop(ctx)
+
+	correlationID := "test-correlation-id"
+	withData := func(data string) EventExpectation {
+		return ExpectEvent("test_event").WithMatch(func(e events.Event) bool {
+			return e.(*TestEvent).Data == data
+		})
+	}
+
+	// События публикуются в порядке, отличном от порядка ожиданий
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		for _, data := range []string{"c", "a", "unexpected", "b"} {
+			event := NewTestEvent(data)
+			event.WithCorrelationID(correlationID)
+			_ = mockBus.Publish(ctx, event)
+		}
+	}()
+
+	results, err := awaiter.AwaitAll(ctx, correlationID, []EventExpectation{withData("a"), withData("b"), withData("c")}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, data := range []string{"a", "b", "c"} {
+		if got := results[i].(*TestEvent).Data; got != data {
+			t.Errorf("expected result %d to be '%s', got '%s'", i, data, got)
+		}
+	}
+
+	if len(mockBus.handlers["test_event"]) != 0 {
+		t.Errorf("expected handlers to be unsubscribed, got %d", len(mockBus.handlers["test_event"]))
+	}
+}
+
+// TestEventAwaiter_AwaitAll_Timeout проверяет возврат частичных результатов при общем timeout
+func TestEventAwaiter_AwaitAll_Timeout(t *testing.T) {
+	ctx := context.Background()
+	mockBus := NewMockEventBus()
+	awaiter := NewEventAwaiterFromEventBus(mockBus)
+	defer awaiter.Stop(ctx)
+
+	correlationID := "test-correlation-id"
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		event := NewTestEvent("first")
+		event.WithCorrelationID(correlationID)
+		_ = mockBus.Publish(ctx, event)
+
+		// Событие с другим correlation ID не выполняет ожидание
+		other := NewTestEvent("second")
+		other.WithCorrelationID("other-correlation-id")
+		_ = mockBus.Publish(ctx, other)
+	}()
+
+	results, err := awaiter.AwaitAll(ctx, correlationID, []EventExpectation{ExpectEvent("test_event"), ExpectEvent("test_event")}, 300*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if !core.WrapWithCode(err, ErrEventTimeout).Is(err) {
+		t.Errorf("expected EVENT_TIMEOUT error, got: %v", err)
+	}
+
+	if len(results) != 2 || results[0] == nil || results[1] != nil {
+		t.Errorf("expected only first expectation to be fulfilled, got %v", results)
+	}
+}
+
+// TestEventAwaiter_AwaitAnyOf проверяет, что возвращается индекс выполненного ожидания
+func TestEventAwaiter_AwaitAnyOf(t *testing.T) {
+	ctx := context.Background()
+	mockBus := NewMockEventBus()
+	awaiter := NewEventAwaiterFromEventBus(mockBus)
+	defer awaiter.Stop(ctx)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		event := NewTestEvent("data")
+		event.WithCorrelationID("step-2")
+		_ = mockBus.Publish(ctx, event)
+	}()
+
+	event, index, err := awaiter.AwaitAnyOf(ctx, "step-1", []EventExpectation{
+		ExpectEvent("test_event"),
+		ExpectEvent("test_event").WithCorrelationID("step-2"),
+	}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if index != 1 {
+		t.Errorf("expected expectation index 1, got %d", index)
+	}
+	if event.Metadata().CorrelationID() != "step-2" {
+		t.Errorf("expected event with correlation ID 'step-2', got '%s'", event.Metadata().CorrelationID())
+	}
+}
+
 // TestEventAwaiter_Await_Unsubscribe проверяет, что подписчики отписываются после завершения ожидания
 func TestEventAwaiter_Await_Unsubscribe(t *testing.T) {
 	ctx := context.Background()
@@ -262,3 +368,87 @@ func TestEventAwaiter_Await_Timeout_Unsubscribe(t *testing.T) {
 		}
 	}
 }
+
+// TestEventAwaiter_Await_Backfill проверяет получение события, опубликованного до начала ожидания
+func TestEventAwaiter_Await_Backfill(t *testing.T) {
+	ctx := context.Background()
+	mockBus := NewMockEventBus()
+
+	correlationID := "test-correlation-id"
+
+	// Событие опубликовано до вызова Await
+	event := NewTestEvent("already published")
+	event.WithCorrelationID(correlationID)
+	_ = mockBus.Publish(ctx, event)
+
+	lookup := EventLookupFunc(func(ctx context.Context, id string, eventTypes []string) (events.Event, error) {
+		for _, e := range mockBus.events {
+			for _, eventType := range eventTypes {
+				if e.EventType() == eventType && e.Metadata().CorrelationID() == id {
+					return e, nil
+				}
+			}
+		}
+		return nil, nil
+	})
+
+	awaiter := NewEventAwaiterFromEventBus(mockBus).WithEventLookup(lookup)
+	defer awaiter.Stop(ctx)
+
+	received, err := awaiter.Await(ctx, correlationID, "test_event", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.EventID() != event.EventID() {
+		t.Errorf("expected backfilled event %s, got %s", event.EventID(), received.EventID())
+	}
+}
+
+// TestEventAwaiter_Await_BackfillRetry проверяет повторную проверку источника до появления события
+func TestEventAwaiter_Await_BackfillRetry(t *testing.T) {
+	ctx := context.Background()
+	mockBus := NewMockEventBus()
+
+	correlationID := "test-correlation-id"
+	event := NewTestEvent("persisted later")
+	event.WithCorrelationID(correlationID)
+
+	var calls int32
+	lookup := EventLookupFunc(func(ctx context.Context, id string, eventTypes []string) (events.Event, error) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return nil, nil
+		}
+		return event, nil
+	})
+
+	awaiter := NewEventAwaiterFromEventBus(mockBus).WithBackfill(BackfillConfig{
+		Lookup:        lookup,
+		RetryInterval: 10 * time.Millisecond,
+	})
+	defer awaiter.Stop(ctx)
+
+	received, err := awaiter.Await(ctx, correlationID, "test_event", time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.EventID() != event.EventID() {
+		t.Errorf("expected event %s, got %s", event.EventID(), received.EventID())
+	}
+	if atomic.LoadInt32(&calls) < 3 {
+		t.Errorf("expected at least 3 lookup attempts, got %d", calls)
+	}
+}
+
+func TestNamingSubjectResolver(t *testing.T) {
+	naming := events.NewSubjectNaming("{context}.{aggregate}.{event}.v{version}").WithContext("sales")
+	resolver := NewNamingSubjectResolver("commands", naming).WithEventVersion("order.cancelled", 2)
+
+	// Subject подписки совпадает с subject, под которым адаптер публикует событие
+	published := naming.Subject(events.NewBaseEvent("order.created", "order-1"))
+	if subject := resolver.ResolveEventSubject("order.created"); subject != published {
+		t.Errorf("Expected %s, got %s", published, subject)
+	}
+	if subject := resolver.ResolveEventSubject("order.cancelled"); subject != "sales.order.order.cancelled.v2" {
+		t.Errorf("Expected sales.order.order.cancelled.v2, got %s", subject)
+	}
+}
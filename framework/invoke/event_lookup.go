@@ -0,0 +1,95 @@
+// Package invoke предоставляет backfill уже опубликованных событий для EventAwaiter.
+package invoke
+
+import (
+	"context"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// EventLookup источник уже сохраненных событий (event store, read model).
+// Используется EventAwaiter для поиска события, которое было опубликовано
+// до начала ожидания (гонка в быстрых сценариях).
+type EventLookup interface {
+	// FindEvent ищет сохраненное событие одного из указанных типов по correlation ID.
+	// Возвращает nil, nil если событие не найдено.
+	FindEvent(ctx context.Context, correlationID string, eventTypes []string) (events.Event, error)
+}
+
+// EventLookupFunc функциональный адаптер для EventLookup
+type EventLookupFunc func(ctx context.Context, correlationID string, eventTypes []string) (events.Event, error)
+
+// FindEvent вызывает функцию поиска
+func (f EventLookupFunc) FindEvent(ctx context.Context, correlationID string, eventTypes []string) (events.Event, error) {
+	return f(ctx, correlationID, eventTypes)
+}
+
+// BackfillConfig конфигурация backfill для EventAwaiter
+type BackfillConfig struct {
+	// Lookup источник сохраненных событий
+	Lookup EventLookup
+	// RetryInterval интервал повторной проверки (0 - только однократная проверка)
+	RetryInterval time.Duration
+	// MaxAttempts максимальное количество проверок (0 - без ограничений до timeout)
+	MaxAttempts int
+}
+
+// DefaultBackfillConfig возвращает конфигурацию backfill по умолчанию
+func DefaultBackfillConfig(lookup EventLookup) BackfillConfig {
+	return BackfillConfig{
+		Lookup:        lookup,
+		RetryInterval: 500 * time.Millisecond,
+		MaxAttempts:   0,
+	}
+}
+
+// runBackfill проверяет источник сохраненных событий и доставляет найденное событие waiter'у.
+// Проверка выполняется сразу после подписки и повторяется с RetryInterval,
+// пока ctx не будет отменен (ожидание завершилось).
+func (a *EventAwaiter) runBackfill(ctx context.Context, waiter *eventWaiter, eventTypes []string) {
+	a.mu.RLock()
+	config := a.backfill
+	a.mu.RUnlock()
+
+	if config == nil || config.Lookup == nil {
+		return
+	}
+
+	attempts := 0
+	for {
+		attempts++
+		event, err := config.Lookup.FindEvent(ctx, waiter.correlationID, eventTypes)
+		if err == nil && event != nil {
+			a.deliver(waiter, event)
+			return
+		}
+
+		if config.RetryInterval <= 0 || (config.MaxAttempts > 0 && attempts >= config.MaxAttempts) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(config.RetryInterval):
+		}
+	}
+}
+
+// deliver отправляет событие waiter'у, если он все еще активен
+func (a *EventAwaiter) deliver(waiter *eventWaiter, event events.Event) {
+	// Удерживаем блокировку, чтобы Stop/cleanup не закрыли канал во время отправки
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if current, exists := a.waiters[waiter.correlationID]; !exists || current != waiter {
+		return
+	}
+
+	select {
+	case waiter.ch <- event:
+	default:
+		// Событие уже доставлено через подписку
+	}
+}
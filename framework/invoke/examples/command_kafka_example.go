@@ -137,7 +137,9 @@ func ExampleCommandInvokerWithKafka() {
 	// 2. Создание Kafka Event Publisher
 	kafkaEventConfig := eventsadapters.DefaultKafkaEventConfig()
 	kafkaEventConfig.Brokers = []string{"localhost:9092"}
-	kafkaEventConfig.TopicPrefix = "events"
+	// Стратегия именования topics событий, общая для публикации и подписки
+	eventNaming := events.DefaultSubjectNaming()
+	kafkaEventConfig.Naming = eventNaming
 	kafkaEventConfig.Compression = "snappy"
 	kafkaEventConfig.IdempotentWrites = true
 
@@ -159,18 +161,8 @@ func ExampleCommandInvokerWithKafka() {
 	asyncBus := invoke.NewAsyncCommandBus(kafkaAdapter)
 
 	// 4. Настройка SubjectResolver для маппинга команд/событий на Kafka topics
-	subjectResolver := invoke.NewFunctionSubjectResolver(
-		func(cmd transport.Command) string {
-			return fmt.Sprintf("commands.%s", cmd.CommandName())
-		},
-		func(eventType string) string {
-			// Для Kafka события публикуются через KafkaEventAdapter
-			// Формат темы: events.{aggregate_type}.{event_type}
-			// Для упрощения используем формат events.{event_type}
-			// В реальном приложении aggregate_type извлекается из aggregate_id события
-			return fmt.Sprintf("events.%s", eventType)
-		},
-	)
+	// Темы событий формируются той же стратегией именования, что и в KafkaEventAdapter
+	subjectResolver := invoke.NewNamingSubjectResolver("commands", eventNaming)
 	asyncBus.WithSubjectResolver(subjectResolver)
 
 	// 5. Создание EventAwaiter из Kafka subscriber через NewEventAwaiterFromTransport
@@ -212,7 +204,10 @@ func ExampleCommandInvokerWithKafka() {
 	// Подписка на события через Kafka (для EventAwaiter)
 	// EventAwaiter автоматически подписывается на события через TransportSubscriberAdapter
 	// Используем реальные имена тем без wildcard
-	eventTopics := []string{"events.order.created", "events.order.creation_failed"}
+	eventTopics := []string{
+		subjectResolver.ResolveEventSubject("order.created"),
+		subjectResolver.ResolveEventSubject("order.creation_failed"),
+	}
 	for _, topic := range eventTopics {
 		_ = kafkaAdapter.Subscribe(ctx, topic, func(ctx context.Context, msg *transport.Message) error {
 			// EventAwaiter обрабатывает события автоматически
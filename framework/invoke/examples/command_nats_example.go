@@ -179,6 +179,7 @@ func ExampleCommandInvokerWithNATS() {
 		if commandID, ok := msg.Headers["command_id"]; ok {
 			ctx = invoke.WithCommandID(ctx, commandID)
 		}
+		ctx = invoke.ContextWithCommandSourceFromHeaders(ctx, msg.Headers)
 
 		// Обрабатываем команду
 		return handler.Handle(ctx, cmd)
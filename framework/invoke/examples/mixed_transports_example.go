@@ -44,7 +44,9 @@ func ExampleMixedTransports() {
 	fmt.Println("2. Настройка Kafka для событий...")
 	kafkaEventConfig := eventsadapters.DefaultKafkaEventConfig()
 	kafkaEventConfig.Brokers = []string{"localhost:9092"}
-	kafkaEventConfig.TopicPrefix = "events"
+	// Стратегия именования topics событий, общая для публикации и подписки
+	eventNaming := events.DefaultSubjectNaming()
+	kafkaEventConfig.Naming = eventNaming
 	kafkaEventConfig.Compression = "snappy"
 	kafkaEventConfig.IdempotentWrites = true
 
@@ -84,18 +86,8 @@ func ExampleMixedTransports() {
 		_ = kafkaAdapter.Stop(ctx)
 	}()
 
-	kafkaSubjectResolver := invoke.NewFunctionSubjectResolver(
-		func(cmd transport.Command) string {
-			return fmt.Sprintf("commands.%s", cmd.CommandName())
-		},
-		func(eventType string) string {
-			// Для Kafka события публикуются через KafkaEventAdapter
-			// Формат темы: events.{aggregate_type}.{event_type}
-			// Для упрощения используем формат events.{event_type}
-			// В реальном приложении aggregate_type извлекается из aggregate_id события
-			return fmt.Sprintf("events.%s", eventType)
-		},
-	)
+	// Темы событий формируются той же стратегией именования, что и в KafkaEventAdapter
+	kafkaSubjectResolver := invoke.NewNamingSubjectResolver("commands", eventNaming)
 
 	serializer := invoke.NewJSONSerializer()
 	kafkaEventAwaiter := invoke.NewEventAwaiterFromTransport(
@@ -0,0 +1,126 @@
+// Package invoke предоставляет HybridCommandBus для маршрутизации команд между локальными и удаленными обработчиками.
+package invoke
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// CommandRoute маршрут команды в HybridCommandBus
+type CommandRoute string
+
+const (
+	// CommandRouteAuto команда обрабатывается локально, если обработчик зарегистрирован в процессе,
+	// иначе публикуется через AsyncCommandBus (по умолчанию)
+	CommandRouteAuto CommandRoute = ""
+	// CommandRouteLocal команда всегда обрабатывается локальной шиной
+	CommandRouteLocal CommandRoute = "local"
+	// CommandRouteRemote команда всегда публикуется через AsyncCommandBus,
+	// даже если локальный обработчик зарегистрирован (например, после выноса в отдельный сервис)
+	CommandRouteRemote CommandRoute = "remote"
+)
+
+// HybridCommandBus шина команд, которая передает команду обработчику в процессе,
+// если он зарегистрирован, и публикует ее через AsyncCommandBus для удаленных обработчиков.
+// Реализует transport.CommandBus, поэтому при выносе обработчика в отдельный сервис
+// код отправителей команд не меняется.
+type HybridCommandBus struct {
+	mu       sync.RWMutex
+	local    transport.CommandBus
+	remote   *AsyncCommandBus
+	handlers map[string]bool
+	routes   map[string]CommandRoute
+}
+
+// NewHybridCommandBus создает новый HybridCommandBus.
+// local - шина обработчиков в процессе (например, transport.NewInMemoryCommandBus()),
+// remote - producer команд для удаленных сервисов.
+func NewHybridCommandBus(local transport.CommandBus, remote *AsyncCommandBus) *HybridCommandBus {
+	return &HybridCommandBus{
+		local:    local,
+		remote:   remote,
+		handlers: make(map[string]bool),
+		routes:   make(map[string]CommandRoute),
+	}
+}
+
+// WithRoute явно задает маршрут команды, переопределяя выбор по зарегистрированным обработчикам
+func (b *HybridCommandBus) WithRoute(commandName string, route CommandRoute) *HybridCommandBus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if route == CommandRouteAuto {
+		delete(b.routes, commandName)
+	} else {
+		b.routes[commandName] = route
+	}
+	return b
+}
+
+// Register регистрирует локальный обработчик команды
+func (b *HybridCommandBus) Register(handler transport.CommandHandler) error {
+	if err := b.local.Register(handler); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[handler.CommandName()] = true
+	return nil
+}
+
+// Send передает команду локальному обработчику или публикует ее для удаленного сервиса.
+// Удаленная отправка асинхронна: ошибка означает только неудачную публикацию.
+func (b *HybridCommandBus) Send(ctx context.Context, cmd transport.Command) error {
+	if b.IsLocal(cmd.CommandName()) {
+		return b.local.Send(ctx, cmd)
+	}
+
+	if b.remote == nil {
+		return fmt.Errorf("no local handler registered for command %s and remote bus is not configured", cmd.CommandName())
+	}
+	return b.remote.SendAsync(ctx, cmd, remoteCommandMetadata(ctx, cmd))
+}
+
+// IsLocal проверяет, будет ли команда обработана в процессе
+func (b *HybridCommandBus) IsLocal(commandName string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	switch b.routes[commandName] {
+	case CommandRouteLocal:
+		return true
+	case CommandRouteRemote:
+		return false
+	default:
+		return b.handlers[commandName]
+	}
+}
+
+// remoteCommandMetadata возвращает метаданные публикуемой команды: метаданные самой команды
+// (transport.BaseCommand), дополненные correlation/causation ID из контекста
+func remoteCommandMetadata(ctx context.Context, cmd transport.Command) *transport.BaseCommandMetadata {
+	fromContext := CreateMetadataFromContext(ctx)
+
+	withMetadata, ok := cmd.(interface{ Metadata() transport.CommandMetadata })
+	if !ok || withMetadata.Metadata() == nil {
+		return fromContext
+	}
+	metadata := withMetadata.Metadata()
+
+	id := metadata.ID()
+	if id == "" {
+		id = fromContext.ID()
+	}
+	correlationID := metadata.CorrelationID()
+	if correlationID == "" {
+		correlationID = fromContext.CorrelationID()
+	}
+	causationID := metadata.CausationID()
+	if causationID == "" {
+		causationID = fromContext.CausationID()
+	}
+	return transport.NewBaseCommandMetadata(id, correlationID, causationID)
+}
@@ -0,0 +1,60 @@
+// Package invoke предоставляет тесты для HybridCommandBus.
+package invoke
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+func TestHybridCommandBus_RoutesLocalAndRemote(t *testing.T) {
+	publisher := &MockPublisher{}
+	bus := NewHybridCommandBus(transport.NewInMemoryCommandBus(), NewAsyncCommandBus(publisher))
+
+	// Обработчик не зарегистрирован - команда публикуется для удаленного сервиса
+	ctx := WithCorrelationID(context.Background(), "corr-1")
+	if err := bus.Send(ctx, TestCommand{Name: "remote"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("expected 1 published command, got %d", len(publisher.published))
+	}
+	if got := publisher.published[0].headers["correlation_id"]; got != "corr-1" {
+		t.Errorf("correlation_id = %q, want corr-1", got)
+	}
+
+	// После регистрации локального обработчика команда обрабатывается в процессе
+	handler := &countingHandler{}
+	if err := bus.Register(handler); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if !bus.IsLocal("test_command") {
+		t.Fatal("expected test_command to be routed locally")
+	}
+	if err := bus.Send(ctx, TestCommand{Name: "local"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if handler.calls != 1 {
+		t.Errorf("expected 1 local call, got %d", handler.calls)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("expected no new published commands, got %d", len(publisher.published))
+	}
+
+	// Явный удаленный маршрут переопределяет локальный обработчик
+	bus.WithRoute("test_command", CommandRouteRemote)
+	if err := bus.Send(ctx, TestCommand{Name: "moved"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if handler.calls != 1 || len(publisher.published) != 2 {
+		t.Errorf("expected command to be published, local calls = %d, published = %d", handler.calls, len(publisher.published))
+	}
+}
+
+func TestHybridCommandBus_NoRemote(t *testing.T) {
+	bus := NewHybridCommandBus(transport.NewInMemoryCommandBus(), nil)
+	if err := bus.Send(context.Background(), TestCommand{}); err == nil {
+		t.Fatal("expected error for command without local handler and remote bus")
+	}
+}
@@ -0,0 +1,253 @@
+// Package invoke предоставляет ключи идемпотентности команд и хранилище дедупликации.
+package invoke
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/transport"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ключи контекста и заголовков для идемпотентности
+const (
+	IdempotencyKeyKey = "idempotency_key"
+	SagaStepKey       = "saga_step"
+)
+
+// sagaStepInfo шаг саги, в рамках которого отправляется команда
+type sagaStepInfo struct {
+	sagaID   string
+	stepName string
+	attempt  int
+}
+
+// GenerateIdempotencyKey генерирует детерминированный ключ идемпотентности для попытки шага саги.
+// Повторная отправка той же попытки (например, после восстановления саги после сбоя)
+// получает тот же ключ, что позволяет получателю отбросить дубликат.
+func GenerateIdempotencyKey(sagaID, stepName string, attempt int) string {
+	return fmt.Sprintf("saga:%s:step:%s:attempt:%d", sagaID, stepName, attempt)
+}
+
+// WithIdempotencyKey добавляет ключ идемпотентности в контекст
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, IdempotencyKeyKey, key)
+}
+
+// WithSagaStep добавляет в контекст информацию о попытке шага саги.
+// AsyncCommandBus генерирует по ней ключ идемпотентности, если он не задан явно.
+func WithSagaStep(ctx context.Context, sagaID, stepName string, attempt int) context.Context {
+	return context.WithValue(ctx, SagaStepKey, sagaStepInfo{sagaID: sagaID, stepName: stepName, attempt: attempt})
+}
+
+// WithSagaSubStep уточняет шаг саги в контексте вложенным шагом (например, внутри ParallelStep),
+// чтобы команды вложенных шагов получали разные ключи идемпотентности
+func WithSagaSubStep(ctx context.Context, subStepName string) context.Context {
+	step, ok := ctx.Value(SagaStepKey).(sagaStepInfo)
+	if !ok {
+		return ctx
+	}
+	step.stepName = step.stepName + "/" + subStepName
+	return context.WithValue(ctx, SagaStepKey, step)
+}
+
+// ExtractIdempotencyKey извлекает ключ идемпотентности из контекста.
+// Если ключ не задан, но в контексте есть шаг саги, ключ генерируется автоматически.
+func ExtractIdempotencyKey(ctx context.Context) string {
+	if val := ctx.Value(IdempotencyKeyKey); val != nil {
+		if key, ok := val.(string); ok && key != "" {
+			return key
+		}
+	}
+	if val := ctx.Value(SagaStepKey); val != nil {
+		if step, ok := val.(sagaStepInfo); ok {
+			return GenerateIdempotencyKey(step.sagaID, step.stepName, step.attempt)
+		}
+	}
+	return ""
+}
+
+// IdempotencyKeyFromHeaders извлекает ключ идемпотентности из заголовков сообщения
+func IdempotencyKeyFromHeaders(headers map[string]string) string {
+	return headers[IdempotencyKeyKey]
+}
+
+// DedupStore хранилище обработанных ключей идемпотентности.
+// Используется обработчиками команд для отклонения дубликатов.
+type DedupStore interface {
+	// MarkProcessed атомарно отмечает ключ как обработанный.
+	// Возвращает false, если ключ уже был отмечен (дубликат).
+	MarkProcessed(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// IsProcessed проверяет, был ли ключ уже обработан
+	IsProcessed(ctx context.Context, key string) (bool, error)
+	// Forget удаляет ключ (например, если обработка команды завершилась ошибкой)
+	Forget(ctx context.Context, key string) error
+}
+
+// DedupCommandHandler обертка над CommandHandler, отклоняющая повторные команды
+// с тем же ключом идемпотентности. Команды без ключа обрабатываются как обычно.
+type DedupCommandHandler struct {
+	handler transport.CommandHandler
+	store   DedupStore
+	ttl     time.Duration
+}
+
+// NewDedupCommandHandler создает обработчик с дедупликацией по ключу идемпотентности.
+// ttl определяет, сколько хранится ключ (0 - бессрочно).
+func NewDedupCommandHandler(handler transport.CommandHandler, store DedupStore, ttl time.Duration) *DedupCommandHandler {
+	return &DedupCommandHandler{
+		handler: handler,
+		store:   store,
+		ttl:     ttl,
+	}
+}
+
+// CommandName возвращает имя команды обернутого обработчика
+func (h *DedupCommandHandler) CommandName() string {
+	return h.handler.CommandName()
+}
+
+// Handle обрабатывает команду, если ее ключ идемпотентности еще не встречался
+func (h *DedupCommandHandler) Handle(ctx context.Context, cmd transport.Command) error {
+	key := ExtractIdempotencyKey(ctx)
+	if key == "" {
+		return h.handler.Handle(ctx, cmd)
+	}
+
+	first, err := h.store.MarkProcessed(ctx, key, h.ttl)
+	if err != nil {
+		return fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	if !first {
+		return NewDuplicateCommandError(cmd.CommandName(), key)
+	}
+
+	if err := h.handler.Handle(ctx, cmd); err != nil {
+		// Неудачная обработка не должна блокировать повторную попытку с тем же ключом
+		_ = h.store.Forget(ctx, key)
+		return err
+	}
+	return nil
+}
+
+// InMemoryDedupStore in-memory реализация DedupStore
+type InMemoryDedupStore struct {
+	mu   sync.Mutex
+	keys map[string]time.Time
+	now  func() time.Time
+}
+
+// NewInMemoryDedupStore создает новый InMemoryDedupStore
+func NewInMemoryDedupStore() *InMemoryDedupStore {
+	return &InMemoryDedupStore{
+		keys: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// MarkProcessed отмечает ключ как обработанный
+func (s *InMemoryDedupStore) MarkProcessed(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if expiresAt, ok := s.keys[key]; ok && (expiresAt.IsZero() || now.Before(expiresAt)) {
+		return false, nil
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+	s.keys[key] = expiresAt
+	return true, nil
+}
+
+// IsProcessed проверяет, был ли ключ уже обработан
+func (s *InMemoryDedupStore) IsProcessed(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.keys[key]
+	if !ok {
+		return false, nil
+	}
+	return expiresAt.IsZero() || s.now().Before(expiresAt), nil
+}
+
+// Forget удаляет ключ
+func (s *InMemoryDedupStore) Forget(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+	return nil
+}
+
+// PostgresDedupStore реализация DedupStore через PostgreSQL.
+// Схема таблицы: migrations/postgres/002_create_processed_commands.sql
+type PostgresDedupStore struct {
+	conn *pgx.Conn
+}
+
+// NewPostgresDedupStore создает новый PostgresDedupStore
+func NewPostgresDedupStore(dsn string) (*PostgresDedupStore, error) {
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	return &PostgresDedupStore{conn: conn}, nil
+}
+
+// MarkProcessed отмечает ключ как обработанный.
+// Просроченный ключ перезаписывается, живой - оставляется без изменений.
+func (s *PostgresDedupStore) MarkProcessed(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	query := `
+		INSERT INTO processed_commands (idempotency_key, processed_at, expires_at)
+		VALUES ($1, NOW(), $2)
+		ON CONFLICT (idempotency_key) DO UPDATE
+		SET processed_at = NOW(), expires_at = EXCLUDED.expires_at
+		WHERE processed_commands.expires_at IS NOT NULL AND processed_commands.expires_at <= NOW()
+	`
+	tag, err := s.conn.Exec(ctx, query, key, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark idempotency key: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// IsProcessed проверяет, был ли ключ уже обработан
+func (s *PostgresDedupStore) IsProcessed(ctx context.Context, key string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM processed_commands
+			WHERE idempotency_key = $1 AND (expires_at IS NULL OR expires_at > NOW())
+		)
+	`
+	var exists bool
+	if err := s.conn.QueryRow(ctx, query, key).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	return exists, nil
+}
+
+// Forget удаляет ключ
+func (s *PostgresDedupStore) Forget(ctx context.Context, key string) error {
+	if _, err := s.conn.Exec(ctx, `DELETE FROM processed_commands WHERE idempotency_key = $1`, key); err != nil {
+		return fmt.Errorf("failed to forget idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с базой данных
+func (s *PostgresDedupStore) Close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
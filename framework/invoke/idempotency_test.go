@@ -0,0 +1,278 @@
+// Package invoke предоставляет тесты для ключей идемпотентности и DedupStore.
+package invoke
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// countingHandler обработчик, считающий вызовы
+type countingHandler struct {
+	calls int
+}
+
+func (h *countingHandler) Handle(ctx context.Context, cmd transport.Command) error {
+	h.calls++
+	return nil
+}
+
+func (h *countingHandler) CommandName() string {
+	return "test_command"
+}
+
+func TestAsyncCommandBus_IdempotencyKeyHeader(t *testing.T) {
+	publisher := &MockPublisher{}
+	bus := NewAsyncCommandBus(publisher)
+
+	ctx := WithSagaStep(context.Background(), "saga-1", "reserve", 0)
+	if err := bus.SendAsync(ctx, TestCommand{}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := bus.SendAsync(ctx, TestCommand{}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := GenerateIdempotencyKey("saga-1", "reserve", 0)
+	for _, msg := range publisher.published {
+		if got := IdempotencyKeyFromHeaders(msg.headers); got != expected {
+			t.Errorf("Expected idempotency key %s, got %s", expected, got)
+		}
+	}
+
+	if GenerateIdempotencyKey("saga-1", "reserve", 1) == expected {
+		t.Error("Expected different keys for different attempts")
+	}
+	if ExtractIdempotencyKey(WithSagaSubStep(ctx, "payment")) == expected {
+		t.Error("Expected sub-step to get its own key")
+	}
+}
+
+// flushingPublisher публикатор с буферизацией, считающий вызовы Flush
+type flushingPublisher struct {
+	MockPublisher
+	flushed int
+}
+
+func (p *flushingPublisher) Flush(ctx context.Context) error {
+	p.flushed++
+	return nil
+}
+
+func TestAsyncCommandBus_CloseFlushesPublisher(t *testing.T) {
+	publisher := &flushingPublisher{}
+	bus := NewAsyncCommandBus(publisher)
+	ctx := context.Background()
+
+	if err := bus.SendAsync(ctx, TestCommand{}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := bus.Close(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if publisher.flushed != 1 {
+		t.Errorf("Expected publisher to be flushed once, got %d", publisher.flushed)
+	}
+
+	err := bus.SendAsync(ctx, TestCommand{}, nil)
+	var frameworkErr *core.FrameworkError
+	if !errors.As(err, &frameworkErr) || frameworkErr.Code != ErrCommandBusClosed {
+		t.Fatalf("Expected %s error, got %v", ErrCommandBusClosed, err)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("Expected 1 published command, got %d", len(publisher.published))
+	}
+}
+
+func TestDedupCommandHandler(t *testing.T) {
+	handler := &countingHandler{}
+	dedup := NewDedupCommandHandler(handler, NewInMemoryDedupStore(), 0)
+	ctx := WithIdempotencyKey(context.Background(), "key-1")
+
+	if err := dedup.Handle(ctx, TestCommand{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err := dedup.Handle(ctx, TestCommand{})
+	var frameworkErr *core.FrameworkError
+	if !errors.As(err, &frameworkErr) || frameworkErr.Code != ErrDuplicateCommand {
+		t.Fatalf("Expected %s error, got %v", ErrDuplicateCommand, err)
+	}
+
+	// Команды без ключа не дедуплицируются
+	if err := dedup.Handle(context.Background(), TestCommand{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if handler.calls != 2 {
+		t.Errorf("Expected 2 handler calls, got %d", handler.calls)
+	}
+}
+
+// loopbackTransport брокер в памяти: подписчик получает сообщение с новым контекстом,
+// как после передачи по сети, ошибки обработки сохраняются
+type loopbackTransport struct {
+	handlers map[string]transport.MessageHandler
+	errs     []error
+}
+
+func (l *loopbackTransport) Publish(ctx context.Context, subject string, data []byte, headers map[string]string) error {
+	if handler, ok := l.handlers[subject]; ok {
+		l.errs = append(l.errs, handler(context.Background(), &transport.Message{Subject: subject, Data: data, Headers: headers}))
+	}
+	return nil
+}
+
+func (l *loopbackTransport) Subscribe(ctx context.Context, subject string, handler transport.MessageHandler) error {
+	if l.handlers == nil {
+		l.handlers = make(map[string]transport.MessageHandler)
+	}
+	l.handlers[subject] = handler
+	return nil
+}
+
+func (l *loopbackTransport) Unsubscribe(subject string) error {
+	delete(l.handlers, subject)
+	return nil
+}
+
+func TestCommandConsumer_DeduplicatesRedeliveredSagaStep(t *testing.T) {
+	broker := &loopbackTransport{}
+	handler := &countingHandler{}
+	commandBus := transport.NewInMemoryCommandBus()
+	if err := commandBus.Register(NewDedupCommandHandler(handler, NewInMemoryDedupStore(), 0)); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+	consumer := NewCommandConsumer(broker, commandBus)
+	if err := consumer.Subscribe(context.Background(), func() transport.Command { return &TestCommand{} }); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	// Шаг саги отправляет команду повторно после восстановления
+	bus := NewAsyncCommandBus(broker)
+	ctx := WithSagaStep(context.Background(), "saga-1", "reserve", 0)
+	for i := 0; i < 2; i++ {
+		if err := bus.SendAsync(ctx, TestCommand{Name: "reserve"}, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if handler.calls != 1 {
+		t.Errorf("Expected 1 handler call, got %d", handler.calls)
+	}
+	if len(broker.errs) != 2 || broker.errs[0] != nil {
+		t.Fatalf("Expected first delivery to succeed, got %v", broker.errs)
+	}
+	var frameworkErr *core.FrameworkError
+	if !errors.As(broker.errs[1], &frameworkErr) || frameworkErr.Code != ErrDuplicateCommand {
+		t.Errorf("Expected %s error for redelivery, got %v", ErrDuplicateCommand, broker.errs[1])
+	}
+}
+
+func TestInbox_SkipsRedeliveredMessages(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryInboxStore()
+	config := DefaultInboxConfig()
+	config.Consumer = "billing"
+	inbox := NewInbox(store, config)
+
+	calls := 0
+	fail := true
+	handler := inbox.Wrap(func(ctx context.Context, msg *transport.Message) error {
+		calls++
+		if fail {
+			return errors.New("temporary error")
+		}
+		return nil
+	})
+	msg := &transport.Message{Subject: "commands.charge", Headers: map[string]string{CommandIDKey: "cmd-1"}}
+
+	// Ошибка обработки снимает захват: повторная доставка обрабатывается
+	if err := handler(ctx, msg); err == nil {
+		t.Fatal("Expected handler error")
+	}
+	fail = false
+	if err := handler(ctx, msg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := handler(ctx, msg); err != nil {
+		t.Fatalf("Expected duplicate to be acknowledged, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 handler calls, got %d", calls)
+	}
+
+	// Сообщение, захваченное другим консьюмером, возвращается на повторную доставку
+	if _, err := store.Acquire(ctx, "billing:cmd-2", time.Minute); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err := handler(ctx, &transport.Message{Headers: map[string]string{CommandIDKey: "cmd-2"}})
+	var frameworkErr *core.FrameworkError
+	if !errors.As(err, &frameworkErr) || frameworkErr.Code != ErrInboxMessageInProgress {
+		t.Fatalf("Expected %s error, got %v", ErrInboxMessageInProgress, err)
+	}
+
+	// Истекшие захват и отметка обработки удаляются Cleanup
+	store.now = func() time.Time { return time.Now().Add(config.Retention + time.Hour) }
+	removed, err := inbox.Cleanup(ctx)
+	if err != nil || removed != 2 {
+		t.Fatalf("Expected 2 expired inbox entries removed, got %d (%v)", removed, err)
+	}
+	if err := handler(ctx, msg); err != nil || calls != 3 {
+		t.Errorf("Expected message to be processed after retention, got %d calls (%v)", calls, err)
+	}
+}
+
+func TestAsyncCommandBus_CommandSourceHeader(t *testing.T) {
+	publisher := &MockPublisher{}
+	bus := NewAsyncCommandBus(publisher)
+
+	if err := bus.SendAsync(WithSagaStep(context.Background(), "saga-1", "reserve", 0), TestCommand{}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := bus.SendAsync(WithCommandSource(context.Background(), CommandSourceReplay), TestCommand{}, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := CommandSourceFromHeaders(publisher.published[0].headers); got != CommandSourceSaga {
+		t.Errorf("Expected saga source, got %q", got)
+	}
+	ctx := ContextWithCommandSourceFromHeaders(context.Background(), publisher.published[1].headers)
+	if !IsReplay(ctx) {
+		t.Errorf("Expected replay source to be propagated, got %q", ExtractCommandSource(ctx))
+	}
+}
+
+func TestSourcePolicyCommandHandler(t *testing.T) {
+	handler := &countingHandler{}
+	internal := NewSourcePolicyCommandHandler(handler, InternalOnly())
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		WithCommandSource(context.Background(), CommandSourceAPI),
+	} {
+		err := internal.Handle(ctx, TestCommand{})
+		var frameworkErr *core.FrameworkError
+		if !errors.As(err, &frameworkErr) || frameworkErr.Code != ErrCommandSourceNotAllowed {
+			t.Errorf("Expected %s error, got %v", ErrCommandSourceNotAllowed, err)
+		}
+	}
+
+	if err := internal.Handle(WithSagaStep(context.Background(), "saga-1", "reserve", 0), TestCommand{}); err != nil {
+		t.Errorf("Expected saga command to be allowed, got %v", err)
+	}
+	if err := internal.Handle(WithCommandSource(context.Background(), CommandSourceScheduler), TestCommand{}); err != nil {
+		t.Errorf("Expected scheduled command to be allowed, got %v", err)
+	}
+
+	apiOnly := NewSourcePolicyCommandHandler(handler, AllowSources(CommandSourceAPI))
+	if err := apiOnly.Handle(WithCommandSource(context.Background(), CommandSourceAPI), TestCommand{}); err != nil {
+		t.Errorf("Expected API command to be allowed, got %v", err)
+	}
+	if handler.calls != 3 {
+		t.Errorf("Expected 3 handler calls, got %d", handler.calls)
+	}
+}
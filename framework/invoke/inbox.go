@@ -0,0 +1,364 @@
+// Package invoke предоставляет Inbox для однократной обработки команд, полученных из брокера.
+package invoke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/transport"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// InboxState состояние сообщения в Inbox
+type InboxState string
+
+const (
+	// InboxStateAcquired сообщение захвачено для обработки текущим консьюмером
+	InboxStateAcquired InboxState = "acquired"
+	// InboxStateProcessing сообщение обрабатывается другим консьюмером (lease не истек)
+	InboxStateProcessing InboxState = "processing"
+	// InboxStateProcessed сообщение уже обработано (дубликат)
+	InboxStateProcessed InboxState = "processed"
+)
+
+// InboxStore хранилище обработанных сообщений Inbox.
+// Сообщение сначала захватывается на время lease, после успешной обработки отмечается
+// обработанным на время хранения. Захват, не завершенный до истечения lease (падение
+// консьюмера), снимается автоматически, и повторная доставка обрабатывается заново.
+type InboxStore interface {
+	// Acquire захватывает сообщение для обработки на время lease
+	Acquire(ctx context.Context, messageID string, lease time.Duration) (InboxState, error)
+	// Complete отмечает сообщение обработанным (retention 0 - бессрочно)
+	Complete(ctx context.Context, messageID string, retention time.Duration) error
+	// Release снимает захват сообщения после ошибки обработки
+	Release(ctx context.Context, messageID string) error
+	// Cleanup удаляет отметки с истекшим сроком хранения и возвращает их количество
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+// InboxConfig конфигурация Inbox
+type InboxConfig struct {
+	// Consumer имя консьюмера: сервисы с разными именами обрабатывают одно сообщение независимо
+	Consumer string
+	// Retention время хранения отметок обработанных сообщений (должно превышать окно повторной
+	// доставки брокера, 0 - бессрочно)
+	Retention time.Duration
+	// Lease максимальное время обработки сообщения, после которого захват снимается
+	Lease time.Duration
+	// MessageID извлекает идентификатор сообщения (по умолчанию ключ идемпотентности
+	// из заголовков, иначе command_id). Сообщения без идентификатора обрабатываются без Inbox.
+	MessageID func(msg *transport.Message) string
+}
+
+// DefaultInboxConfig возвращает конфигурацию Inbox по умолчанию
+func DefaultInboxConfig() InboxConfig {
+	return InboxConfig{
+		Retention: 7 * 24 * time.Hour,
+		Lease:     5 * time.Minute,
+	}
+}
+
+// InboxMessageID возвращает идентификатор сообщения команды: ключ идемпотентности,
+// а при его отсутствии - command_id, заданный AsyncCommandBus
+func InboxMessageID(msg *transport.Message) string {
+	if key := IdempotencyKeyFromHeaders(msg.Headers); key != "" {
+		return key
+	}
+	return msg.Headers[CommandIDKey]
+}
+
+// Inbox middleware консьюмеров команд (AsyncCommandBus -> NATS/Kafka), обеспечивающий
+// однократную обработку: повторно доставленные брокером сообщения с уже обработанным
+// идентификатором подтверждаются без вызова обработчика.
+type Inbox struct {
+	store  InboxStore
+	config InboxConfig
+}
+
+// NewInbox создает Inbox
+func NewInbox(store InboxStore, config InboxConfig) *Inbox {
+	if config.Lease <= 0 {
+		config.Lease = DefaultInboxConfig().Lease
+	}
+	if config.MessageID == nil {
+		config.MessageID = InboxMessageID
+	}
+	return &Inbox{store: store, config: config}
+}
+
+// Wrap оборачивает обработчик сообщений дедупликацией по идентификатору сообщения.
+// Дубликат возвращает nil (сообщение подтверждается), сообщение, обрабатываемое другим
+// консьюмером, - ошибку INBOX_MESSAGE_IN_PROGRESS для повторной доставки позже.
+func (i *Inbox) Wrap(handler transport.MessageHandler) transport.MessageHandler {
+	return func(ctx context.Context, msg *transport.Message) error {
+		messageID := i.config.MessageID(msg)
+		if messageID == "" {
+			return handler(ctx, msg)
+		}
+		key := i.key(messageID)
+
+		state, err := i.store.Acquire(ctx, key, i.config.Lease)
+		if err != nil {
+			return fmt.Errorf("failed to acquire inbox message: %w", err)
+		}
+		switch state {
+		case InboxStateProcessed:
+			return nil
+		case InboxStateProcessing:
+			return NewInboxMessageInProgressError(messageID)
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			// Неудачная обработка не должна блокировать повторную доставку
+			_ = i.store.Release(ctx, key)
+			return err
+		}
+		if err := i.store.Complete(ctx, key, i.config.Retention); err != nil {
+			return fmt.Errorf("failed to complete inbox message: %w", err)
+		}
+		return nil
+	}
+}
+
+// Cleanup удаляет отметки обработанных сообщений с истекшим сроком хранения
+func (i *Inbox) Cleanup(ctx context.Context) (int64, error) {
+	return i.store.Cleanup(ctx)
+}
+
+// key возвращает ключ сообщения в хранилище с учетом консьюмера
+func (i *Inbox) key(messageID string) string {
+	if i.config.Consumer == "" {
+		return messageID
+	}
+	return i.config.Consumer + ":" + messageID
+}
+
+// inboxEntry запись InMemoryInboxStore
+type inboxEntry struct {
+	state     InboxState
+	expiresAt time.Time
+}
+
+// InMemoryInboxStore in-memory реализация InboxStore
+type InMemoryInboxStore struct {
+	mu      sync.Mutex
+	entries map[string]inboxEntry
+	now     func() time.Time
+}
+
+// NewInMemoryInboxStore создает новый InMemoryInboxStore
+func NewInMemoryInboxStore() *InMemoryInboxStore {
+	return &InMemoryInboxStore{
+		entries: make(map[string]inboxEntry),
+		now:     time.Now,
+	}
+}
+
+// Acquire захватывает сообщение для обработки
+func (s *InMemoryInboxStore) Acquire(ctx context.Context, messageID string, lease time.Duration) (InboxState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.entries[messageID]; ok && (entry.expiresAt.IsZero() || now.Before(entry.expiresAt)) {
+		return entry.state, nil
+	}
+	s.entries[messageID] = inboxEntry{state: InboxStateProcessing, expiresAt: now.Add(lease)}
+	return InboxStateAcquired, nil
+}
+
+// Complete отмечает сообщение обработанным
+func (s *InMemoryInboxStore) Complete(ctx context.Context, messageID string, retention time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if retention > 0 {
+		expiresAt = s.now().Add(retention)
+	}
+	s.entries[messageID] = inboxEntry{state: InboxStateProcessed, expiresAt: expiresAt}
+	return nil
+}
+
+// Release снимает захват сообщения
+func (s *InMemoryInboxStore) Release(ctx context.Context, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[messageID]; ok && entry.state == InboxStateProcessing {
+		delete(s.entries, messageID)
+	}
+	return nil
+}
+
+// Cleanup удаляет просроченные записи
+func (s *InMemoryInboxStore) Cleanup(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var removed int64
+	for messageID, entry := range s.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(s.entries, messageID)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// PostgresInboxStore реализация InboxStore через PostgreSQL.
+// Схема таблицы: migrations/postgres/003_create_inbox_messages.sql
+type PostgresInboxStore struct {
+	conn *pgx.Conn
+}
+
+// NewPostgresInboxStore создает новый PostgresInboxStore
+func NewPostgresInboxStore(dsn string) (*PostgresInboxStore, error) {
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+	return &PostgresInboxStore{conn: conn}, nil
+}
+
+// Acquire захватывает сообщение: новая запись, истекший захват или истекшая отметка
+// обработки перезаписываются, живая запись оставляется без изменений
+func (s *PostgresInboxStore) Acquire(ctx context.Context, messageID string, lease time.Duration) (InboxState, error) {
+	query := `
+		INSERT INTO inbox_messages (message_id, status, received_at, expires_at)
+		VALUES ($1, 'processing', NOW(), $2)
+		ON CONFLICT (message_id) DO UPDATE
+		SET status = 'processing', received_at = NOW(), processed_at = NULL, expires_at = EXCLUDED.expires_at
+		WHERE inbox_messages.expires_at IS NOT NULL AND inbox_messages.expires_at <= NOW()
+	`
+	tag, err := s.conn.Exec(ctx, query, messageID, time.Now().Add(lease))
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire inbox message: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return InboxStateAcquired, nil
+	}
+
+	var status string
+	err = s.conn.QueryRow(ctx, `SELECT status FROM inbox_messages WHERE message_id = $1`, messageID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Запись удалена между вставкой и чтением (Release другого консьюмера) - повторим позже
+		return InboxStateProcessing, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read inbox message: %w", err)
+	}
+	return InboxState(status), nil
+}
+
+// Complete отмечает сообщение обработанным
+func (s *PostgresInboxStore) Complete(ctx context.Context, messageID string, retention time.Duration) error {
+	var expiresAt *time.Time
+	if retention > 0 {
+		t := time.Now().Add(retention)
+		expiresAt = &t
+	}
+
+	query := `
+		UPDATE inbox_messages
+		SET status = 'processed', processed_at = NOW(), expires_at = $2
+		WHERE message_id = $1
+	`
+	if _, err := s.conn.Exec(ctx, query, messageID, expiresAt); err != nil {
+		return fmt.Errorf("failed to complete inbox message: %w", err)
+	}
+	return nil
+}
+
+// Release снимает захват сообщения
+func (s *PostgresInboxStore) Release(ctx context.Context, messageID string) error {
+	query := `DELETE FROM inbox_messages WHERE message_id = $1 AND status = 'processing'`
+	if _, err := s.conn.Exec(ctx, query, messageID); err != nil {
+		return fmt.Errorf("failed to release inbox message: %w", err)
+	}
+	return nil
+}
+
+// Cleanup удаляет записи с истекшим сроком хранения
+func (s *PostgresInboxStore) Cleanup(ctx context.Context) (int64, error) {
+	tag, err := s.conn.Exec(ctx, `DELETE FROM inbox_messages WHERE expires_at IS NOT NULL AND expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup inbox messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Close закрывает соединение с базой данных
+func (s *PostgresInboxStore) Close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
+
+// RedisInboxStore реализация InboxStore через Redis: срок хранения записей задается TTL
+// ключей, поэтому Cleanup не требуется
+type RedisInboxStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisInboxStore создает RedisInboxStore поверх клиента приложения
+func NewRedisInboxStore(client *redis.Client, keyPrefix string) *RedisInboxStore {
+	if keyPrefix == "" {
+		keyPrefix = "potter:inbox:"
+	}
+	return &RedisInboxStore{client: client, keyPrefix: keyPrefix}
+}
+
+// Acquire захватывает сообщение (SET NX с TTL lease)
+func (s *RedisInboxStore) Acquire(ctx context.Context, messageID string, lease time.Duration) (InboxState, error) {
+	key := s.keyPrefix + messageID
+	acquired, err := s.client.SetNX(ctx, key, string(InboxStateProcessing), lease).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire inbox message: %w", err)
+	}
+	if acquired {
+		return InboxStateAcquired, nil
+	}
+
+	status, err := s.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return InboxStateProcessing, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read inbox message: %w", err)
+	}
+	return InboxState(status), nil
+}
+
+// Complete отмечает сообщение обработанным
+func (s *RedisInboxStore) Complete(ctx context.Context, messageID string, retention time.Duration) error {
+	if err := s.client.Set(ctx, s.keyPrefix+messageID, string(InboxStateProcessed), retention).Err(); err != nil {
+		return fmt.Errorf("failed to complete inbox message: %w", err)
+	}
+	return nil
+}
+
+// redisReleaseScript удаляет ключ, только если сообщение еще не обработано
+var redisReleaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Release снимает захват сообщения
+func (s *RedisInboxStore) Release(ctx context.Context, messageID string) error {
+	err := redisReleaseScript.Run(ctx, s.client, []string{s.keyPrefix + messageID}, string(InboxStateProcessing)).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to release inbox message: %w", err)
+	}
+	return nil
+}
+
+// Cleanup ничего не делает: записи удаляются Redis по TTL
+func (s *RedisInboxStore) Cleanup(ctx context.Context) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,16 @@
+package invoke
+
+import (
+	"embed"
+
+	"github.com/akriventsev/potter/framework/migrations"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migrations возвращает встроенные SQL миграции пакета для диалекта (postgres)
+// для применения через migrations.RunMigrationSets при старте сервиса
+func Migrations(dialect string) (migrations.MigrationSet, error) {
+	return migrations.NewEmbeddedMigrationSet("invoke", migrationFiles, "migrations", dialect)
+}
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Миграция для создания таблицы отложенных команд (CommandScheduler)
+
+CREATE TABLE IF NOT EXISTS scheduled_commands (
+    id VARCHAR(255) PRIMARY KEY,
+    command_name VARCHAR(255) NOT NULL,
+    subject VARCHAR(512) NOT NULL,
+    payload BYTEA NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMPTZ,
+    dispatched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Индекс для выборки наступивших команд dispatcher'ом
+CREATE INDEX IF NOT EXISTS idx_scheduled_commands_due ON scheduled_commands(status, scheduled_at);
+
+COMMENT ON TABLE scheduled_commands IS 'Отложенные команды, публикуемые CommandScheduler в заданное время';
+COMMENT ON COLUMN scheduled_commands.status IS 'Статус команды (pending, dispatching, dispatched, failed, cancelled)';
+COMMENT ON COLUMN scheduled_commands.locked_until IS 'Время, до которого команда захвачена dispatcher''ом';
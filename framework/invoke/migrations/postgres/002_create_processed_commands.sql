@@ -0,0 +1,14 @@
+-- +goose Up
+-- Миграция для создания таблицы обработанных ключей идемпотентности (DedupStore)
+
+CREATE TABLE IF NOT EXISTS processed_commands (
+    idempotency_key VARCHAR(512) PRIMARY KEY,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ
+);
+
+-- Индекс для очистки просроченных ключей
+CREATE INDEX IF NOT EXISTS idx_processed_commands_expires_at ON processed_commands(expires_at) WHERE expires_at IS NOT NULL;
+
+COMMENT ON TABLE processed_commands IS 'Ключи идемпотентности обработанных команд для отклонения дубликатов';
+COMMENT ON COLUMN processed_commands.expires_at IS 'Время истечения ключа (NULL - бессрочно)';
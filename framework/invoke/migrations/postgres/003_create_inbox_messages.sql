@@ -0,0 +1,17 @@
+-- +goose Up
+-- Миграция для создания таблицы Inbox консьюмеров команд (PostgresInboxStore)
+
+CREATE TABLE IF NOT EXISTS inbox_messages (
+    message_id VARCHAR(512) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+-- Индекс для очистки просроченных записей
+CREATE INDEX IF NOT EXISTS idx_inbox_messages_expires_at ON inbox_messages(expires_at) WHERE expires_at IS NOT NULL;
+
+COMMENT ON TABLE inbox_messages IS 'Сообщения команд, захваченные или обработанные консьюмерами (Inbox)';
+COMMENT ON COLUMN inbox_messages.status IS 'processing - обрабатывается до expires_at, processed - обработано';
+COMMENT ON COLUMN inbox_messages.expires_at IS 'Окончание захвата или срока хранения отметки (NULL - бессрочно)';
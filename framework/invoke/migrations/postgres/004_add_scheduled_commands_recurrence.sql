@@ -0,0 +1,10 @@
+-- +goose Up
+-- Миграция для периодических команд и бизнес-календарей CommandScheduler
+
+ALTER TABLE scheduled_commands ADD COLUMN IF NOT EXISTS cron_expression VARCHAR(255);
+ALTER TABLE scheduled_commands ADD COLUMN IF NOT EXISTS time_zone VARCHAR(100);
+ALTER TABLE scheduled_commands ADD COLUMN IF NOT EXISTS calendar VARCHAR(255);
+
+COMMENT ON COLUMN scheduled_commands.cron_expression IS 'Cron-выражение периодической команды (NULL - однократная команда)';
+COMMENT ON COLUMN scheduled_commands.time_zone IS 'Часовой пояс cron-выражения (имя IANA)';
+COMMENT ON COLUMN scheduled_commands.calendar IS 'Имя бизнес-календаря, зарегистрированного в CommandScheduler';
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Миграция для токена захвата отложенных команд: завершение команды проверяет,
+-- что ее захватил этот же dispatcher и захват не истек
+
+ALTER TABLE scheduled_commands ADD COLUMN IF NOT EXISTS claim_token VARCHAR(64);
+
+COMMENT ON COLUMN scheduled_commands.claim_token IS 'Токен захвата, выданный ClaimDue (NULL - команда не захвачена)';
@@ -19,6 +19,7 @@ type InvokeOptions struct {
 	EventSource      EventSource
 	SuccessEventType string
 	ErrorEventType   string
+	EventLookup      EventLookup
 }
 
 // RetryPolicy политика повторов
@@ -105,6 +106,13 @@ func WithErrorEventType(eventType string) InvokeOption {
 	}
 }
 
+// WithEventLookup устанавливает источник сохраненных событий для backfill в EventAwaiter
+func WithEventLookup(lookup EventLookup) InvokeOption {
+	return func(opts *InvokeOptions) {
+		opts.EventLookup = lookup
+	}
+}
+
 // WithTransportSubscriber создает EventSource через TransportSubscriberAdapter
 func WithTransportSubscriber(
 	subscriber transport.Subscriber,
@@ -0,0 +1,404 @@
+// Package invoke предоставляет хранилища отложенных команд.
+package invoke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// InMemoryScheduleStore in-memory реализация ScheduleStore (для тестов и разработки)
+type InMemoryScheduleStore struct {
+	mu       sync.Mutex
+	commands map[string]*ScheduledCommand
+	locks    map[string]time.Time
+	claims   map[string]string
+}
+
+// NewInMemoryScheduleStore создает новый InMemoryScheduleStore
+func NewInMemoryScheduleStore() *InMemoryScheduleStore {
+	return &InMemoryScheduleStore{
+		commands: make(map[string]*ScheduledCommand),
+		locks:    make(map[string]time.Time),
+		claims:   make(map[string]string),
+	}
+}
+
+// Save сохраняет новую отложенную команду
+func (s *InMemoryScheduleStore) Save(ctx context.Context, cmd *ScheduledCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.commands[cmd.ID]; exists {
+		return fmt.Errorf("scheduled command %s already exists", cmd.ID)
+	}
+	copied := *cmd
+	s.commands[cmd.ID] = &copied
+	return nil
+}
+
+// ClaimDue захватывает наступившие команды
+func (s *InMemoryScheduleStore) ClaimDue(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*ScheduledCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make([]*ScheduledCommand, 0)
+	for id, cmd := range s.commands {
+		if cmd.ScheduledAt.After(now) {
+			continue
+		}
+		if cmd.Status == ScheduledCommandPending ||
+			(cmd.Status == ScheduledCommandDispatching && s.locks[id].Before(now)) {
+			due = append(due, cmd)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].ScheduledAt.Before(due[j].ScheduledAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimToken := uuid.NewString()
+	claimed := make([]*ScheduledCommand, 0, len(due))
+	for _, cmd := range due {
+		cmd.Status = ScheduledCommandDispatching
+		cmd.UpdatedAt = now
+		s.locks[cmd.ID] = lockUntil
+		s.claims[cmd.ID] = claimToken
+		copied := *cmd
+		copied.ClaimToken = claimToken
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+// claimed возвращает команду, если она захвачена с claimToken
+func (s *InMemoryScheduleStore) claimed(id string, claimToken string) (*ScheduledCommand, error) {
+	cmd, exists := s.commands[id]
+	if !exists {
+		return nil, NewScheduledCommandNotFoundError(id)
+	}
+	if cmd.Status != ScheduledCommandDispatching || s.claims[id] != claimToken {
+		return nil, NewScheduledCommandClaimLostError(id)
+	}
+	return cmd, nil
+}
+
+// release снимает захват команды
+func (s *InMemoryScheduleStore) release(id string) {
+	delete(s.locks, id)
+	delete(s.claims, id)
+}
+
+// MarkDispatched отмечает захваченную команду как отправленную
+func (s *InMemoryScheduleStore) MarkDispatched(ctx context.Context, id string, claimToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd, err := s.claimed(id, claimToken)
+	if err != nil {
+		return err
+	}
+	cmd.Status = ScheduledCommandDispatched
+	cmd.Attempts++
+	cmd.UpdatedAt = time.Now()
+	s.release(id)
+	return nil
+}
+
+// MarkFailed возвращает захваченную команду в очередь или помечает ее как failed
+func (s *InMemoryScheduleStore) MarkFailed(ctx context.Context, id string, claimToken string, errMsg string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd, err := s.claimed(id, claimToken)
+	if err != nil {
+		return err
+	}
+	cmd.Attempts++
+	cmd.LastError = errMsg
+	cmd.UpdatedAt = time.Now()
+	if retryAt.IsZero() {
+		cmd.Status = ScheduledCommandFailed
+	} else {
+		cmd.Status = ScheduledCommandPending
+		cmd.ScheduledAt = retryAt
+	}
+	s.release(id)
+	return nil
+}
+
+// Reschedule возвращает захваченную периодическую команду в очередь на следующее срабатывание
+func (s *InMemoryScheduleStore) Reschedule(ctx context.Context, id string, claimToken string, at time.Time, headers map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd, err := s.claimed(id, claimToken)
+	if err != nil {
+		return err
+	}
+	cmd.Status = ScheduledCommandPending
+	cmd.ScheduledAt = at
+	cmd.Headers = headers
+	cmd.Attempts = 0
+	cmd.LastError = ""
+	cmd.UpdatedAt = time.Now()
+	s.release(id)
+	return nil
+}
+
+// Cancel отменяет команду, если она еще не отправлена
+func (s *InMemoryScheduleStore) Cancel(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd, exists := s.commands[id]
+	if !exists || cmd.Status != ScheduledCommandPending {
+		return NewScheduledCommandNotFoundError(id)
+	}
+	cmd.Status = ScheduledCommandCancelled
+	cmd.UpdatedAt = time.Now()
+	return nil
+}
+
+// Get возвращает отложенную команду по ID
+func (s *InMemoryScheduleStore) Get(ctx context.Context, id string) (*ScheduledCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd, exists := s.commands[id]
+	if !exists {
+		return nil, NewScheduledCommandNotFoundError(id)
+	}
+	copied := *cmd
+	copied.ClaimToken = s.claims[id]
+	return &copied, nil
+}
+
+// PostgresScheduleStore реализация ScheduleStore через PostgreSQL.
+// Захват команд выполняется через FOR UPDATE SKIP LOCKED, поэтому
+// несколько экземпляров dispatcher'а могут работать одновременно. Завершение команды
+// (MarkDispatched, MarkFailed, Reschedule) проверяет статус dispatching и claim_token,
+// поэтому dispatcher, чей захват истек, не перезапишет результат нового владельца.
+// Схема таблицы: migrations/postgres/001_create_scheduled_commands.sql
+type PostgresScheduleStore struct {
+	conn *pgx.Conn
+}
+
+// NewPostgresScheduleStore создает новый PostgresScheduleStore
+func NewPostgresScheduleStore(dsn string) (*PostgresScheduleStore, error) {
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	return &PostgresScheduleStore{conn: conn}, nil
+}
+
+// Save сохраняет новую отложенную команду
+func (s *PostgresScheduleStore) Save(ctx context.Context, cmd *ScheduledCommand) error {
+	return insertScheduledCommand(ctx, s.conn, cmd)
+}
+
+// SaveScheduledCommandTx сохраняет отложенную команду в таблицу scheduled_commands в транзакции tx.
+// Позволяет использовать таблицу как transactional outbox: команда записывается атомарно с
+// изменениями вызывающей стороны (например, PostgresPersistence саг) и отправляется
+// dispatcher'ом CommandScheduler с PostgresScheduleStore той же базы после коммита.
+func SaveScheduledCommandTx(ctx context.Context, tx pgx.Tx, cmd *ScheduledCommand) error {
+	return insertScheduledCommand(ctx, tx, cmd)
+}
+
+// scheduledCommandExecutor выполняет запрос через соединение или транзакцию
+type scheduledCommandExecutor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// insertScheduledCommand вставляет отложенную команду
+func insertScheduledCommand(ctx context.Context, exec scheduledCommandExecutor, cmd *ScheduledCommand) error {
+	headersJSON, err := json.Marshal(cmd.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal headers: %w", err)
+	}
+
+	query := `
+		INSERT INTO scheduled_commands (id, command_name, subject, payload, headers, scheduled_at, status, attempts, created_at, updated_at, cron_expression, time_zone, calendar)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $8, NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''))
+	`
+	_, err = exec.Exec(ctx, query,
+		cmd.ID, cmd.CommandName, cmd.Subject, cmd.Payload, headersJSON, cmd.ScheduledAt, string(cmd.Status), cmd.CreatedAt,
+		cmd.Cron, cmd.TimeZone, cmd.Calendar)
+	if err != nil {
+		return fmt.Errorf("failed to save scheduled command: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue захватывает наступившие команды
+func (s *PostgresScheduleStore) ClaimDue(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*ScheduledCommand, error) {
+	query := `
+		UPDATE scheduled_commands SET status = 'dispatching', locked_until = $2, claim_token = $4, updated_at = $1
+		WHERE id IN (
+			SELECT id FROM scheduled_commands
+			WHERE scheduled_at <= $1
+			  AND (status = 'pending' OR (status = 'dispatching' AND locked_until < $1))
+			ORDER BY scheduled_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, command_name, subject, payload, headers, scheduled_at, status, attempts, COALESCE(last_error, ''), created_at, updated_at,
+			COALESCE(cron_expression, ''), COALESCE(time_zone, ''), COALESCE(calendar, ''), COALESCE(claim_token, '')
+	`
+	rows, err := s.conn.Query(ctx, query, now, lockUntil, limit, uuid.NewString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim scheduled commands: %w", err)
+	}
+	defer rows.Close()
+
+	claimed := make([]*ScheduledCommand, 0)
+	for rows.Next() {
+		cmd, err := scanScheduledCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, cmd)
+	}
+	return claimed, rows.Err()
+}
+
+// MarkDispatched отмечает захваченную команду как отправленную
+func (s *PostgresScheduleStore) MarkDispatched(ctx context.Context, id string, claimToken string) error {
+	query := `
+		UPDATE scheduled_commands
+		SET status = 'dispatched', attempts = attempts + 1, locked_until = NULL, claim_token = NULL, dispatched_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'dispatching' AND claim_token = $2
+	`
+	tag, err := s.conn.Exec(ctx, query, id, claimToken)
+	if err != nil {
+		return fmt.Errorf("failed to mark scheduled command as dispatched: %w", err)
+	}
+	return s.checkClaimed(ctx, id, tag)
+}
+
+// MarkFailed возвращает захваченную команду в очередь или помечает ее как failed
+func (s *PostgresScheduleStore) MarkFailed(ctx context.Context, id string, claimToken string, errMsg string, retryAt time.Time) error {
+	query := `
+		UPDATE scheduled_commands
+		SET status = 'failed', attempts = attempts + 1, last_error = $3, locked_until = NULL, claim_token = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'dispatching' AND claim_token = $2
+	`
+	args := []interface{}{id, claimToken, errMsg}
+	if !retryAt.IsZero() {
+		query = `
+			UPDATE scheduled_commands
+			SET status = 'pending', attempts = attempts + 1, last_error = $3, scheduled_at = $4, locked_until = NULL, claim_token = NULL, updated_at = NOW()
+			WHERE id = $1 AND status = 'dispatching' AND claim_token = $2
+		`
+		args = append(args, retryAt)
+	}
+
+	tag, err := s.conn.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to mark scheduled command as failed: %w", err)
+	}
+	return s.checkClaimed(ctx, id, tag)
+}
+
+// Reschedule возвращает захваченную периодическую команду в очередь на следующее срабатывание
+func (s *PostgresScheduleStore) Reschedule(ctx context.Context, id string, claimToken string, at time.Time, headers map[string]string) error {
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal headers: %w", err)
+	}
+
+	query := `
+		UPDATE scheduled_commands
+		SET status = 'pending', scheduled_at = $3, headers = $4, attempts = 0, last_error = NULL,
+		    locked_until = NULL, claim_token = NULL, dispatched_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'dispatching' AND claim_token = $2
+	`
+	tag, err := s.conn.Exec(ctx, query, id, claimToken, at, headersJSON)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule scheduled command: %w", err)
+	}
+	return s.checkClaimed(ctx, id, tag)
+}
+
+// checkClaimed проверяет, что завершение команды изменило строку. Если нет, различает
+// отсутствующую команду и потерянный захват (истек и перехвачен, команда отменена или завершена).
+func (s *PostgresScheduleStore) checkClaimed(ctx context.Context, id string, tag pgconn.CommandTag) error {
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+	var exists bool
+	if err := s.conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM scheduled_commands WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check scheduled command: %w", err)
+	}
+	if !exists {
+		return NewScheduledCommandNotFoundError(id)
+	}
+	return NewScheduledCommandClaimLostError(id)
+}
+
+// Cancel отменяет команду, если она еще не отправлена
+func (s *PostgresScheduleStore) Cancel(ctx context.Context, id string) error {
+	query := `
+		UPDATE scheduled_commands SET status = 'cancelled', updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`
+	tag, err := s.conn.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel scheduled command: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return NewScheduledCommandNotFoundError(id)
+	}
+	return nil
+}
+
+// Get возвращает отложенную команду по ID
+func (s *PostgresScheduleStore) Get(ctx context.Context, id string) (*ScheduledCommand, error) {
+	query := `
+		SELECT id, command_name, subject, payload, headers, scheduled_at, status, attempts, COALESCE(last_error, ''), created_at, updated_at,
+			COALESCE(cron_expression, ''), COALESCE(time_zone, ''), COALESCE(calendar, ''), COALESCE(claim_token, '')
+		FROM scheduled_commands WHERE id = $1
+	`
+	cmd, err := scanScheduledCommand(s.conn.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, NewScheduledCommandNotFoundError(id)
+	}
+	return cmd, err
+}
+
+// Close закрывает соединение с базой данных
+func (s *PostgresScheduleStore) Close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
+
+// scanScheduledCommand читает отложенную команду из строки результата
+func scanScheduledCommand(row pgx.Row) (*ScheduledCommand, error) {
+	var cmd ScheduledCommand
+	var status string
+	var headersJSON []byte
+	if err := row.Scan(&cmd.ID, &cmd.CommandName, &cmd.Subject, &cmd.Payload, &headersJSON,
+		&cmd.ScheduledAt, &status, &cmd.Attempts, &cmd.LastError, &cmd.CreatedAt, &cmd.UpdatedAt,
+		&cmd.Cron, &cmd.TimeZone, &cmd.Calendar, &cmd.ClaimToken); err != nil {
+		return nil, err
+	}
+	cmd.Status = ScheduledCommandStatus(status)
+	if len(headersJSON) > 0 {
+		if err := json.Unmarshal(headersJSON, &cmd.Headers); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal headers: %w", err)
+		}
+	}
+	return &cmd, nil
+}
@@ -0,0 +1,588 @@
+// Package invoke предоставляет планировщик отложенных команд.
+package invoke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// ScheduledCommandStatus статус отложенной команды
+type ScheduledCommandStatus string
+
+const (
+	ScheduledCommandPending     ScheduledCommandStatus = "pending"
+	ScheduledCommandDispatching ScheduledCommandStatus = "dispatching"
+	ScheduledCommandDispatched  ScheduledCommandStatus = "dispatched"
+	ScheduledCommandFailed      ScheduledCommandStatus = "failed"
+	ScheduledCommandCancelled   ScheduledCommandStatus = "cancelled"
+)
+
+// ScheduledCommand отложенная команда. Команда сериализуется в момент планирования,
+// поэтому dispatcher публикует ее без знания конкретного типа.
+type ScheduledCommand struct {
+	ID          string
+	CommandName string
+	Subject     string
+	Payload     []byte
+	Headers     map[string]string
+	ScheduledAt time.Time
+	Status      ScheduledCommandStatus
+	Attempts    int
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	// Cron cron-выражение периодической команды ("" - однократная команда)
+	Cron string
+	// TimeZone часовой пояс cron-выражения (имя из базы IANA)
+	TimeZone string
+	// Calendar имя бизнес-календаря команды (CommandScheduler.WithCalendar)
+	Calendar string
+	// ClaimToken токен захвата, выданный ClaimDue. Передается в MarkDispatched, MarkFailed
+	// и Reschedule, чтобы команду завершил только dispatcher, владеющий захватом.
+	ClaimToken string
+}
+
+// RecurringScheduleStore реализуется хранилищами с поддержкой периодических команд
+// (CommandScheduler.ScheduleCron)
+type RecurringScheduleStore interface {
+	// Reschedule возвращает отправленную периодическую команду, захваченную с claimToken,
+	// в очередь на следующее срабатывание at с заголовками срабатывания и сбросом счетчика попыток
+	Reschedule(ctx context.Context, id string, claimToken string, at time.Time, headers map[string]string) error
+}
+
+// ScheduleOption опция отдельной отложенной команды
+type ScheduleOption func(*scheduleOptions)
+
+// scheduleOptions опции отложенной команды
+type scheduleOptions struct {
+	calendar string
+	location *time.Location
+}
+
+// OnCalendar задает бизнес-календарь команды, зарегистрированный в планировщике (WithCalendar).
+// Однократная команда, попадающая на нерабочее время, переносится на ближайший рабочий момент;
+// срабатывания cron-расписания в нерабочее время пропускаются.
+func OnCalendar(name string) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.calendar = name
+	}
+}
+
+// InLocation задает часовой пояс cron-расписания (по умолчанию - UTC). Часовой пояс сохраняется
+// по имени, поэтому должен загружаться через time.LoadLocation (например, "Europe/Moscow").
+func InLocation(location *time.Location) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.location = location
+	}
+}
+
+// ScheduleStore персистентное хранилище отложенных команд
+type ScheduleStore interface {
+	// Save сохраняет новую отложенную команду
+	Save(ctx context.Context, cmd *ScheduledCommand) error
+	// ClaimDue атомарно захватывает до limit команд, время которых наступило.
+	// Захваченные команды переводятся в статус dispatching до lockUntil и получают ClaimToken;
+	// если dispatcher упал, команды снова становятся доступны после lockUntil.
+	ClaimDue(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*ScheduledCommand, error)
+	// MarkDispatched отмечает команду, захваченную с claimToken, как отправленную.
+	// Если команда больше не захвачена с этим токеном, возвращается ошибка ErrScheduledCommandClaimLost.
+	MarkDispatched(ctx context.Context, id string, claimToken string) error
+	// MarkFailed возвращает команду, захваченную с claimToken, в очередь на retryAt или,
+	// если retryAt нулевое, помечает ее как failed (ErrScheduledCommandClaimLost - как в MarkDispatched)
+	MarkFailed(ctx context.Context, id string, claimToken string, errMsg string, retryAt time.Time) error
+	// Cancel отменяет команду, если она еще не отправлена
+	Cancel(ctx context.Context, id string) error
+	// Get возвращает отложенную команду по ID
+	Get(ctx context.Context, id string) (*ScheduledCommand, error)
+}
+
+// SchedulerConfig конфигурация CommandScheduler
+type SchedulerConfig struct {
+	// PollInterval интервал опроса хранилища dispatcher'ом
+	PollInterval time.Duration
+	// BatchSize максимальное количество команд, захватываемых за один опрос
+	BatchSize int
+	// LockTimeout время, на которое команда захватывается dispatcher'ом
+	LockTimeout time.Duration
+	// MaxAttempts максимальное количество попыток публикации
+	MaxAttempts int
+	// RetryBackoff задержка перед повторной публикацией
+	RetryBackoff time.Duration
+}
+
+// DefaultSchedulerConfig возвращает конфигурацию планировщика по умолчанию
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		LockTimeout:  30 * time.Second,
+		MaxAttempts:  5,
+		RetryBackoff: 5 * time.Second,
+	}
+}
+
+// CommandScheduler планирует отправку команд в заданное время.
+// Команды сохраняются в ScheduleStore, а встроенный dispatcher периодически
+// публикует наступившие команды через transport.Publisher (реализация core.Lifecycle).
+type CommandScheduler struct {
+	store           ScheduleStore
+	publisher       transport.Publisher
+	serializer      transport.MessageSerializer
+	subjectResolver SubjectResolver
+	idGenerator     func() string
+	config          SchedulerConfig
+	now             func() time.Time
+	calendars       map[string]BusinessCalendar
+
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+// NewCommandScheduler создает новый CommandScheduler
+func NewCommandScheduler(store ScheduleStore, publisher transport.Publisher) *CommandScheduler {
+	return &CommandScheduler{
+		store:           store,
+		publisher:       publisher,
+		serializer:      DefaultSerializer(),
+		subjectResolver: NewDefaultSubjectResolver("commands", "events"),
+		idGenerator:     GenerateCorrelationID,
+		config:          DefaultSchedulerConfig(),
+		now:             time.Now,
+		calendars:       make(map[string]BusinessCalendar),
+	}
+}
+
+// WithConfig устанавливает конфигурацию планировщика
+func (s *CommandScheduler) WithConfig(config SchedulerConfig) *CommandScheduler {
+	s.config = config
+	return s
+}
+
+// WithSerializer устанавливает сериализатор
+func (s *CommandScheduler) WithSerializer(serializer transport.MessageSerializer) *CommandScheduler {
+	s.serializer = serializer
+	return s
+}
+
+// WithSubjectResolver устанавливает кастомный SubjectResolver
+func (s *CommandScheduler) WithSubjectResolver(resolver SubjectResolver) *CommandScheduler {
+	s.subjectResolver = resolver
+	return s
+}
+
+// WithIDGenerator устанавливает генератор ID отложенных команд
+func (s *CommandScheduler) WithIDGenerator(generator func() string) *CommandScheduler {
+	s.idGenerator = generator
+	return s
+}
+
+// WithCalendar регистрирует бизнес-календарь под именем name для выбора в опции OnCalendar
+func (s *CommandScheduler) WithCalendar(name string, calendar BusinessCalendar) *CommandScheduler {
+	s.calendars[name] = calendar
+	return s
+}
+
+// Schedule сохраняет команду для отправки в момент at и возвращает ID отложенной команды.
+// Correlation ID и causation ID берутся из контекста, если они там есть.
+// С опцией OnCalendar момент отправки переносится на ближайшее рабочее время календаря.
+func (s *CommandScheduler) Schedule(ctx context.Context, cmd transport.Command, at time.Time, opts ...ScheduleOption) (string, error) {
+	scheduled, err := s.Prepare(ctx, cmd, at, opts...)
+	if err != nil {
+		return "", err
+	}
+	return s.save(ctx, scheduled)
+}
+
+// Prepare сериализует команду в отложенную команду на момент at без сохранения в хранилище.
+// Используется для записи команды в outbox в транзакции вызывающей стороны
+// (см. SaveScheduledCommandTx): после коммита команду отправит dispatcher планировщика.
+func (s *CommandScheduler) Prepare(ctx context.Context, cmd transport.Command, at time.Time, opts ...ScheduleOption) (*ScheduledCommand, error) {
+	options := newScheduleOptions(opts)
+	if options.calendar != "" {
+		calendar, err := s.calendar(options.calendar)
+		if err != nil {
+			return nil, err
+		}
+		at = calendar.NextBusinessTime(at)
+	}
+
+	scheduled, err := s.newScheduledCommand(ctx, cmd, at)
+	if err != nil {
+		return nil, err
+	}
+	scheduled.Calendar = options.calendar
+	return scheduled, nil
+}
+
+// ScheduleCron сохраняет периодическую команду, отправляемую по cron-расписанию spec
+// (см. ParseCron), и возвращает ID отложенной команды. Часовой пояс расписания задается
+// опцией InLocation, бизнес-календарь - опцией OnCalendar. После каждой отправки команда
+// возвращается в очередь на следующее срабатывание; срабатывание, не отправленное за
+// MaxAttempts попыток, пропускается. Требует хранилища с RecurringScheduleStore.
+func (s *CommandScheduler) ScheduleCron(ctx context.Context, cmd transport.Command, spec string, opts ...ScheduleOption) (string, error) {
+	if _, ok := s.store.(RecurringScheduleStore); !ok {
+		return "", NewInvalidScheduleError("schedule store does not support recurring commands")
+	}
+
+	options := newScheduleOptions(opts)
+	location := options.location
+	if location == nil {
+		location = time.UTC
+	}
+	scheduled := &ScheduledCommand{Cron: spec, TimeZone: location.String(), Calendar: options.calendar}
+	at, err := s.nextOccurrence(scheduled, s.now())
+	if err != nil {
+		return "", err
+	}
+	if at.IsZero() {
+		return "", NewInvalidScheduleError(fmt.Sprintf("cron expression %q has no upcoming occurrences", spec))
+	}
+
+	recurring, err := s.newScheduledCommand(ctx, cmd, at)
+	if err != nil {
+		return "", err
+	}
+	recurring.Cron = scheduled.Cron
+	recurring.TimeZone = scheduled.TimeZone
+	recurring.Calendar = scheduled.Calendar
+	recurring.Headers = occurrenceHeaders(recurring.Headers, recurring.ID, at)
+	return s.save(ctx, recurring)
+}
+
+// newScheduledCommand сериализует команду в отложенную команду на момент at
+func (s *CommandScheduler) newScheduledCommand(ctx context.Context, cmd transport.Command, at time.Time) (*ScheduledCommand, error) {
+	data, err := s.serializer.Serialize(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize command: %w", err)
+	}
+
+	subject := s.subjectResolver.ResolveCommandSubject(cmd)
+	if subject == "" {
+		return nil, fmt.Errorf("failed to resolve subject for command: %s", cmd.CommandName())
+	}
+
+	correlationID := ExtractCorrelationID(ctx)
+	if correlationID == "" {
+		correlationID = GenerateCorrelationID()
+	}
+
+	now := s.now()
+	id := s.idGenerator()
+	return &ScheduledCommand{
+		ID:          id,
+		CommandName: cmd.CommandName(),
+		Subject:     subject,
+		Payload:     data,
+		Headers: map[string]string{
+			"command_id":     id,
+			"correlation_id": correlationID,
+			"causation_id":   ExtractCausationID(ctx),
+			"command_name":   cmd.CommandName(),
+			"scheduled_at":   at.UTC().Format(time.RFC3339),
+			CommandSourceKey: string(CommandSourceScheduler),
+		},
+		ScheduledAt: at,
+		Status:      ScheduledCommandPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// save сохраняет отложенную команду в хранилище
+func (s *CommandScheduler) save(ctx context.Context, scheduled *ScheduledCommand) (string, error) {
+	if err := s.store.Save(ctx, scheduled); err != nil {
+		return "", fmt.Errorf("failed to save scheduled command: %w", err)
+	}
+	return scheduled.ID, nil
+}
+
+// calendar возвращает зарегистрированный бизнес-календарь
+func (s *CommandScheduler) calendar(name string) (BusinessCalendar, error) {
+	calendar, ok := s.calendars[name]
+	if !ok {
+		return nil, NewInvalidScheduleError(fmt.Sprintf("calendar %q is not registered", name))
+	}
+	return calendar, nil
+}
+
+// nextOccurrence возвращает следующее после after срабатывание периодической команды
+// в рабочее время ее календаря (нулевое время - срабатываний больше нет)
+func (s *CommandScheduler) nextOccurrence(cmd *ScheduledCommand, after time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(cmd.TimeZone)
+	if err != nil {
+		return time.Time{}, NewInvalidScheduleError(fmt.Sprintf("unknown time zone %q", cmd.TimeZone))
+	}
+	cron, err := ParseCron(cmd.Cron, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var calendar BusinessCalendar
+	if cmd.Calendar != "" {
+		if calendar, err = s.calendar(cmd.Calendar); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	limit := after.AddDate(cronSearchYears, 0, 0)
+	next := cron.Next(after)
+	for !next.IsZero() && calendar != nil && !calendar.IsBusinessTime(next) {
+		if next.After(limit) {
+			return time.Time{}, nil
+		}
+		next = cron.Next(next)
+	}
+	return next, nil
+}
+
+// newScheduleOptions применяет опции отложенной команды
+func newScheduleOptions(opts []ScheduleOption) scheduleOptions {
+	var options scheduleOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// occurrenceHeaders возвращает заголовки срабатывания периодической команды: command_id
+// уникален для каждого срабатывания, чтобы не отсекаться идемпотентностью получателя
+func occurrenceHeaders(headers map[string]string, id string, at time.Time) map[string]string {
+	result := make(map[string]string, len(headers))
+	for k, v := range headers {
+		result[k] = v
+	}
+	result["command_id"] = id + "@" + at.UTC().Format(time.RFC3339)
+	result["scheduled_at"] = at.UTC().Format(time.RFC3339)
+	return result
+}
+
+// Cancel отменяет отложенную команду
+func (s *CommandScheduler) Cancel(ctx context.Context, id string) error {
+	return s.store.Cancel(ctx, id)
+}
+
+// DispatchDue публикует все наступившие команды и возвращает количество отправленных.
+// Вызывается dispatcher'ом по таймеру, но может использоваться и напрямую (например, из cron job).
+func (s *CommandScheduler) DispatchDue(ctx context.Context) (int, error) {
+	now := s.now()
+	claimed, err := s.store.ClaimDue(ctx, now, now.Add(s.config.LockTimeout), s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim scheduled commands: %w", err)
+	}
+
+	dispatched := 0
+	for _, cmd := range claimed {
+		headers := make(map[string]string, len(cmd.Headers)+1)
+		for k, v := range cmd.Headers {
+			headers[k] = v
+		}
+		headers["timestamp"] = now.Format(time.RFC3339)
+
+		if err := s.publisher.Publish(ctx, cmd.Subject, cmd.Payload, headers); err != nil {
+			var retryAt time.Time
+			if cmd.Attempts+1 < s.config.MaxAttempts {
+				retryAt = now.Add(s.config.RetryBackoff)
+			} else if cmd.Cron != "" {
+				// Неотправленное срабатывание пропускается, расписание продолжается
+				if err := s.reschedule(ctx, cmd, now); err != nil {
+					return dispatched, err
+				}
+				continue
+			}
+			publishErr := NewCommandPublishFailedError(cmd.CommandName, err)
+			if markErr := s.store.MarkFailed(ctx, cmd.ID, cmd.ClaimToken, publishErr.Error(), retryAt); markErr != nil && !isClaimLost(markErr) {
+				return dispatched, fmt.Errorf("failed to mark scheduled command %s as failed: %w", cmd.ID, markErr)
+			}
+			continue
+		}
+
+		if cmd.Cron != "" {
+			if err := s.reschedule(ctx, cmd, now); err != nil {
+				return dispatched, err
+			}
+		} else if err := s.store.MarkDispatched(ctx, cmd.ID, cmd.ClaimToken); err != nil && !isClaimLost(err) {
+			return dispatched, fmt.Errorf("failed to mark scheduled command %s as dispatched: %w", cmd.ID, err)
+		}
+		dispatched++
+	}
+
+	return dispatched, nil
+}
+
+// reschedule возвращает периодическую команду в очередь на следующее после now срабатывание
+// или завершает ее, если срабатываний больше нет
+func (s *CommandScheduler) reschedule(ctx context.Context, cmd *ScheduledCommand, now time.Time) error {
+	next, err := s.nextOccurrence(cmd, now)
+	if err == nil && !next.IsZero() {
+		recurring, ok := s.store.(RecurringScheduleStore)
+		if !ok {
+			return NewInvalidScheduleError("schedule store does not support recurring commands")
+		}
+		if err := recurring.Reschedule(ctx, cmd.ID, cmd.ClaimToken, next, occurrenceHeaders(cmd.Headers, cmd.ID, next)); err != nil && !isClaimLost(err) {
+			return fmt.Errorf("failed to reschedule scheduled command %s: %w", cmd.ID, err)
+		}
+		return nil
+	}
+	if err != nil {
+		if markErr := s.store.MarkFailed(ctx, cmd.ID, cmd.ClaimToken, err.Error(), time.Time{}); markErr != nil && !isClaimLost(markErr) {
+			return fmt.Errorf("failed to mark scheduled command %s as failed: %w", cmd.ID, markErr)
+		}
+		return nil
+	}
+	if err := s.store.MarkDispatched(ctx, cmd.ID, cmd.ClaimToken); err != nil && !isClaimLost(err) {
+		return fmt.Errorf("failed to mark scheduled command %s as dispatched: %w", cmd.ID, err)
+	}
+	return nil
+}
+
+// isClaimLost проверяет, что захват команды истек и ее завершает другой dispatcher:
+// такая команда пропускается, не прерывая отправку остальных
+func isClaimLost(err error) bool {
+	var frameworkErr *core.FrameworkError
+	return errors.As(err, &frameworkErr) && frameworkErr.Code == ErrScheduledCommandClaimLost
+}
+
+// Start запускает dispatcher (реализация core.Lifecycle)
+func (s *CommandScheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+
+	go s.dispatchLoop(s.stopCh, s.doneCh)
+	return nil
+}
+
+// Stop останавливает dispatcher (реализация core.Lifecycle)
+func (s *CommandScheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	doneCh := s.doneCh
+	s.mu.Unlock()
+
+	select {
+	case <-doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRunning проверяет, запущен ли dispatcher (реализация core.Lifecycle)
+func (s *CommandScheduler) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Name возвращает имя компонента (реализация core.Component)
+func (s *CommandScheduler) Name() string {
+	return "command-scheduler"
+}
+
+// Type возвращает тип компонента (реализация core.Component)
+func (s *CommandScheduler) Type() core.ComponentType {
+	return core.ComponentTypeTransport
+}
+
+// dispatchLoop периодически публикует наступившие команды
+func (s *CommandScheduler) dispatchLoop(stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer close(doneCh)
+
+	interval := s.config.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			// Ошибки хранилища не останавливают dispatcher: команды будут захвачены на следующем тике
+			_, _ = s.DispatchDue(ctx)
+		}
+	}
+}
+
+// schedulerContextKey ключ контекста для CommandScheduler
+type schedulerContextKey struct{}
+
+var (
+	defaultSchedulerMu sync.RWMutex
+	defaultScheduler   *CommandScheduler
+)
+
+// SetDefaultScheduler устанавливает планировщик, используемый ScheduleCommand
+// когда в контексте нет планировщика
+func SetDefaultScheduler(scheduler *CommandScheduler) {
+	defaultSchedulerMu.Lock()
+	defer defaultSchedulerMu.Unlock()
+	defaultScheduler = scheduler
+}
+
+// WithScheduler добавляет планировщик в контекст
+func WithScheduler(ctx context.Context, scheduler *CommandScheduler) context.Context {
+	return context.WithValue(ctx, schedulerContextKey{}, scheduler)
+}
+
+// SchedulerFromContext возвращает планировщик из контекста или планировщик по умолчанию
+func SchedulerFromContext(ctx context.Context) *CommandScheduler {
+	if scheduler, ok := ctx.Value(schedulerContextKey{}).(*CommandScheduler); ok && scheduler != nil {
+		return scheduler
+	}
+	defaultSchedulerMu.RLock()
+	defer defaultSchedulerMu.RUnlock()
+	return defaultScheduler
+}
+
+// ScheduleCommand планирует отправку команды в момент at.
+// Используется планировщик из контекста (WithScheduler) или планировщик по умолчанию (SetDefaultScheduler).
+func ScheduleCommand(ctx context.Context, cmd transport.Command, at time.Time, opts ...ScheduleOption) (string, error) {
+	scheduler := SchedulerFromContext(ctx)
+	if scheduler == nil {
+		return "", NewSchedulerNotConfiguredError()
+	}
+	return scheduler.Schedule(ctx, cmd, at, opts...)
+}
+
+// ScheduleCommandAfter планирует отправку команды через delay
+func ScheduleCommandAfter(ctx context.Context, cmd transport.Command, delay time.Duration, opts ...ScheduleOption) (string, error) {
+	return ScheduleCommand(ctx, cmd, time.Now().Add(delay), opts...)
+}
+
+// ScheduleCommandCron планирует периодическую отправку команды по cron-расписанию
+// (см. CommandScheduler.ScheduleCron)
+func ScheduleCommandCron(ctx context.Context, cmd transport.Command, spec string, opts ...ScheduleOption) (string, error) {
+	scheduler := SchedulerFromContext(ctx)
+	if scheduler == nil {
+		return "", NewSchedulerNotConfiguredError()
+	}
+	return scheduler.ScheduleCron(ctx, cmd, spec, opts...)
+}
@@ -0,0 +1,239 @@
+// Package invoke предоставляет тесты для CommandScheduler.
+package invoke
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+)
+
+// failingPublisher publisher, возвращающий ошибку
+type failingPublisher struct{}
+
+func (p *failingPublisher) Publish(ctx context.Context, subject string, data []byte, headers map[string]string) error {
+	return errors.New("broker unavailable")
+}
+
+func TestCommandScheduler_DispatchDue(t *testing.T) {
+	ctx := WithCorrelationID(context.Background(), "corr-1")
+	store := NewInMemoryScheduleStore()
+	publisher := &MockPublisher{}
+	scheduler := NewCommandScheduler(store, publisher)
+
+	now := time.Now()
+	scheduler.now = func() time.Time { return now }
+
+	dueID, err := scheduler.Schedule(ctx, TestCommand{Name: "due"}, now.Add(-time.Second))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := scheduler.Schedule(ctx, TestCommand{Name: "later"}, now.Add(15*time.Minute)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	dispatched, err := scheduler.DispatchDue(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dispatched != 1 || len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 dispatched command, got %d (published %d)", dispatched, len(publisher.published))
+	}
+
+	msg := publisher.published[0]
+	if msg.subject != "commands.test_command" {
+		t.Errorf("Expected subject commands.test_command, got %s", msg.subject)
+	}
+	if msg.headers["correlation_id"] != "corr-1" {
+		t.Errorf("Expected correlation_id corr-1, got %s", msg.headers["correlation_id"])
+	}
+
+	stored, err := store.Get(ctx, dueID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.Status != ScheduledCommandDispatched {
+		t.Errorf("Expected status dispatched, got %s", stored.Status)
+	}
+
+	// Повторный опрос не должен отправлять команду снова
+	if dispatched, _ := scheduler.DispatchDue(ctx); dispatched != 0 {
+		t.Errorf("Expected no commands on second dispatch, got %d", dispatched)
+	}
+}
+
+func TestCommandScheduler_RetryAndFail(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryScheduleStore()
+	scheduler := NewCommandScheduler(store, &failingPublisher{}).WithConfig(SchedulerConfig{
+		BatchSize:    10,
+		LockTimeout:  time.Second,
+		MaxAttempts:  2,
+		RetryBackoff: time.Minute,
+	})
+
+	now := time.Now()
+	scheduler.now = func() time.Time { return now }
+
+	id, err := scheduler.Schedule(ctx, TestCommand{}, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := scheduler.DispatchDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ := store.Get(ctx, id)
+	if stored.Status != ScheduledCommandPending || !stored.ScheduledAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("Expected command to be rescheduled, got status %s at %v", stored.Status, stored.ScheduledAt)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := scheduler.DispatchDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ = store.Get(ctx, id)
+	if stored.Status != ScheduledCommandFailed || stored.Attempts != 2 {
+		t.Errorf("Expected failed status after 2 attempts, got %s after %d", stored.Status, stored.Attempts)
+	}
+}
+
+func TestInMemoryScheduleStore_ClaimToken(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryScheduleStore()
+	now := time.Now()
+
+	if err := store.Save(ctx, &ScheduledCommand{ID: "cmd-1", ScheduledAt: now, Status: ScheduledCommandPending}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	isClaimLostErr := func(err error) bool {
+		var frameworkErr *core.FrameworkError
+		return errors.As(err, &frameworkErr) && frameworkErr.Code == ErrScheduledCommandClaimLost
+	}
+
+	// Первый dispatcher захватывает команду, захват истекает, и ее перехватывает второй
+	first, err := store.ClaimDue(ctx, now, now.Add(time.Second), 10)
+	if err != nil || len(first) != 1 || first[0].ClaimToken == "" {
+		t.Fatalf("Expected 1 claimed command with token, got %v (err %v)", first, err)
+	}
+	second, err := store.ClaimDue(ctx, now.Add(2*time.Second), now.Add(time.Minute), 10)
+	if err != nil || len(second) != 1 || second[0].ClaimToken == first[0].ClaimToken {
+		t.Fatalf("Expected command to be reclaimed with a new token, got %v (err %v)", second, err)
+	}
+
+	// Устаревший захват не может завершить команду
+	if err := store.MarkFailed(ctx, "cmd-1", first[0].ClaimToken, "timeout", time.Time{}); !isClaimLostErr(err) {
+		t.Fatalf("Expected %s error, got %v", ErrScheduledCommandClaimLost, err)
+	}
+	if err := store.MarkDispatched(ctx, "cmd-1", second[0].ClaimToken); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stored, _ := store.Get(ctx, "cmd-1")
+	if stored.Status != ScheduledCommandDispatched || stored.Attempts != 1 || stored.LastError != "" {
+		t.Errorf("Expected dispatched command after 1 attempt, got %s after %d (%q)", stored.Status, stored.Attempts, stored.LastError)
+	}
+
+	// Завершенная команда больше не захвачена
+	if err := store.MarkDispatched(ctx, "cmd-1", second[0].ClaimToken); !isClaimLostErr(err) {
+		t.Errorf("Expected %s error, got %v", ErrScheduledCommandClaimLost, err)
+	}
+	if err := store.MarkDispatched(ctx, "missing", second[0].ClaimToken); err == nil {
+		t.Error("Expected not found error for missing command")
+	}
+}
+
+func TestScheduleCommand_Context(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := ScheduleCommand(ctx, TestCommand{}, time.Now())
+	var frameworkErr *core.FrameworkError
+	if !errors.As(err, &frameworkErr) || frameworkErr.Code != ErrSchedulerNotConfigured {
+		t.Fatalf("Expected %s error, got %v", ErrSchedulerNotConfigured, err)
+	}
+
+	store := NewInMemoryScheduleStore()
+	scheduler := NewCommandScheduler(store, &MockPublisher{})
+	id, err := ScheduleCommandAfter(WithScheduler(ctx, scheduler), TestCommand{}, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := scheduler.Cancel(ctx, id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ := store.Get(ctx, id)
+	if stored.Status != ScheduledCommandCancelled {
+		t.Errorf("Expected status cancelled, got %s", stored.Status)
+	}
+}
+
+func TestCommandScheduler_Calendar(t *testing.T) {
+	ctx := context.Background()
+	location := time.FixedZone("UTC+3", 3*60*60)
+	calendar := NewWorkCalendar(location).
+		WithHolidays(time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)).
+		WithWorkingHours(9*time.Hour, 18*time.Hour)
+
+	store := NewInMemoryScheduleStore()
+	scheduler := NewCommandScheduler(store, &MockPublisher{}).WithCalendar("ru", calendar)
+
+	// Суббота 20:00 -> выходные и праздничный понедельник пропускаются -> вторник 09:00
+	id, err := scheduler.Schedule(ctx, TestCommand{}, time.Date(2026, time.March, 7, 20, 0, 0, 0, location), OnCalendar("ru"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ := store.Get(ctx, id)
+	if expected := time.Date(2026, time.March, 10, 9, 0, 0, 0, location); !stored.ScheduledAt.Equal(expected) {
+		t.Errorf("Expected command at %v, got %v", expected, stored.ScheduledAt)
+	}
+
+	if _, err := scheduler.Schedule(ctx, TestCommand{}, time.Now(), OnCalendar("unknown")); err == nil {
+		t.Error("Expected error for unregistered calendar")
+	}
+}
+
+func TestCommandScheduler_ScheduleCron(t *testing.T) {
+	ctx := context.Background()
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+
+	store := NewInMemoryScheduleStore()
+	publisher := &MockPublisher{}
+	scheduler := NewCommandScheduler(store, publisher).WithCalendar("us", NewWorkCalendar(location))
+
+	// Пятница 10:00 по Нью-Йорку (летнее время)
+	now := time.Date(2026, time.July, 3, 10, 0, 0, 0, location)
+	scheduler.now = func() time.Time { return now }
+
+	id, err := scheduler.ScheduleCron(ctx, TestCommand{}, "30 9 * * *", InLocation(location), OnCalendar("us"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ := store.Get(ctx, id)
+	// Срабатывания в выходные пропускаются
+	if expected := time.Date(2026, time.July, 6, 9, 30, 0, 0, location); !stored.ScheduledAt.Equal(expected) {
+		t.Fatalf("Expected first occurrence at %v, got %v", expected, stored.ScheduledAt)
+	}
+
+	now = stored.ScheduledAt
+	if dispatched, err := scheduler.DispatchDue(ctx); err != nil || dispatched != 1 {
+		t.Fatalf("Expected 1 dispatched command, got %d (%v)", dispatched, err)
+	}
+	stored, _ = store.Get(ctx, id)
+	if stored.Status != ScheduledCommandPending || !stored.ScheduledAt.Equal(time.Date(2026, time.July, 7, 9, 30, 0, 0, location)) {
+		t.Errorf("Expected command to be rescheduled to next day, got status %s at %v", stored.Status, stored.ScheduledAt)
+	}
+	if publisher.published[0].headers["command_id"] == stored.Headers["command_id"] {
+		t.Error("Expected unique command_id for each occurrence")
+	}
+
+	if _, err := ParseCron("61 * * * *", nil); err == nil {
+		t.Error("Expected error for invalid cron expression")
+	}
+}
@@ -3,7 +3,9 @@ package invoke
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/akriventsev/potter/framework/events"
 	"github.com/akriventsev/potter/framework/transport"
 )
 
@@ -106,3 +108,51 @@ func (r *StaticSubjectResolver) ResolveEventSubject(eventType string) string {
 	return ""
 }
 
+// NamingSubjectResolver реализация SubjectResolver, формирующая subjects событий по
+// стратегии именования events.SubjectNaming - той же, что используют адаптеры публикации событий
+type NamingSubjectResolver struct {
+	commandPrefix string
+	naming        *events.SubjectNaming
+	aggregateFunc func(eventType string) string
+	versions      map[string]int
+}
+
+// NewNamingSubjectResolver создает новый NamingSubjectResolver
+func NewNamingSubjectResolver(commandPrefix string, naming *events.SubjectNaming) *NamingSubjectResolver {
+	return &NamingSubjectResolver{
+		commandPrefix: commandPrefix,
+		naming:        naming,
+		aggregateFunc: AggregateTypeFromEventType,
+		versions:      make(map[string]int),
+	}
+}
+
+// WithAggregateFunc устанавливает функцию определения типа агрегата по типу события
+func (r *NamingSubjectResolver) WithAggregateFunc(fn func(eventType string) string) *NamingSubjectResolver {
+	r.aggregateFunc = fn
+	return r
+}
+
+// WithEventVersion устанавливает версию схемы события для плейсхолдера {version} (по умолчанию 1)
+func (r *NamingSubjectResolver) WithEventVersion(eventType string, version int) *NamingSubjectResolver {
+	r.versions[eventType] = version
+	return r
+}
+
+// ResolveCommandSubject формирует subject как {prefix}.{commandName}
+func (r *NamingSubjectResolver) ResolveCommandSubject(cmd transport.Command) string {
+	return fmt.Sprintf("%s.%s", r.commandPrefix, cmd.CommandName())
+}
+
+// ResolveEventSubject формирует subject события по шаблону стратегии именования
+func (r *NamingSubjectResolver) ResolveEventSubject(eventType string) string {
+	return r.naming.Format(r.aggregateFunc(eventType), eventType, r.versions[eventType])
+}
+
+// AggregateTypeFromEventType определяет тип агрегата по префиксу типа события (order.created -> order)
+func AggregateTypeFromEventType(eventType string) string {
+	if idx := strings.Index(eventType, "."); idx > 0 {
+		return eventType[:idx]
+	}
+	return events.UnknownAggregateType
+}
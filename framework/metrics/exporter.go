@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultNamespace префикс имен метрик фреймворка в PrometheusExporter (potter_saga_count)
+const DefaultNamespace = "potter"
+
+// Имена метрик, опрашиваемых при каждом scrape (см. CountsCollector)
+const (
+	// SagaCountMetric число саг по статусам: potter_saga_count{status}
+	SagaCountMetric = "saga_count"
+	// ProjectionLagMetric отставание проекций от последнего события (событий): potter_projection_lag_events{projection}
+	ProjectionLagMetric = "projection_lag_events"
+)
+
+// defaultCollectTimeout ограничение времени опроса источника при scrape
+const defaultCollectTimeout = 5 * time.Second
+
+// PrometheusExporter готовый экспорт метрик фреймворка в Prometheus: собственный Registry,
+// Recorder для инструментации компонентов и HTTP handler для scrape.
+//
+// Компоненты записывают метрики через Recorder() или Metrics():
+//   - DefaultOrchestrator.WithMetrics - события саг (events_total{event="saga.compensated"}),
+//     длительности шагов (saga_step_duration_seconds) и компенсации (saga_step_compensations_total);
+//   - TracingEventStore.WithMetrics - задержка записи в event store
+//     (eventstore_operation_duration_seconds{operation="append"});
+//   - AsyncCommandBus.WithMetrics, CommandHandlerBuilder.WithMetrics - пропускная способность команд (commands_total);
+//   - NATS адаптеры (WithMetricsBackend) - ошибки транспорта (transport_errors_total).
+//
+// Состояние, которое дешевле прочитать, чем отслеживать событиями (число саг по статусам,
+// отставание проекций), опрашивается при scrape: RegisterSagaCounts, RegisterProjectionLag.
+type PrometheusExporter struct {
+	registry       *prometheus.Registry
+	recorder       *PrometheusRecorder
+	metrics        *Metrics
+	collectTimeout time.Duration
+}
+
+// NewPrometheusExporter создает exporter; nil registry - новый prometheus.Registry
+func NewPrometheusExporter(registry *prometheus.Registry) *PrometheusExporter {
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+	}
+	recorder := NewPrometheusRecorder(registry).WithNamespace(DefaultNamespace)
+	return &PrometheusExporter{
+		registry:       registry,
+		recorder:       recorder,
+		metrics:        NewMetricsWithRecorder(recorder),
+		collectTimeout: defaultCollectTimeout,
+	}
+}
+
+// WithBuckets задает границы бакетов гистограмм
+func (e *PrometheusExporter) WithBuckets(buckets []float64) *PrometheusExporter {
+	e.recorder.WithBuckets(buckets)
+	return e
+}
+
+// WithCollectTimeout ограничивает время опроса источников CountsCollector при scrape
+func (e *PrometheusExporter) WithCollectTimeout(timeout time.Duration) *PrometheusExporter {
+	e.collectTimeout = timeout
+	return e
+}
+
+// WithRuntimeMetrics добавляет метрики Go runtime и процесса (go_*, process_*)
+func (e *PrometheusExporter) WithRuntimeMetrics() *PrometheusExporter {
+	_ = e.Register(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return e
+}
+
+// Registry возвращает Registry exporter'а
+func (e *PrometheusExporter) Registry() *prometheus.Registry {
+	return e.registry
+}
+
+// Recorder возвращает Recorder для инструментации компонентов
+func (e *PrometheusExporter) Recorder() Recorder {
+	return e.recorder
+}
+
+// Metrics возвращает сборщик метрик для компонентов, принимающих *Metrics
+func (e *PrometheusExporter) Metrics() *Metrics {
+	return e.metrics
+}
+
+// Register регистрирует дополнительные коллекторы; уже зарегистрированные пропускаются
+func (e *PrometheusExporter) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := e.registry.Register(c); err != nil {
+			var already prometheus.AlreadyRegisteredError
+			if errors.As(err, &already) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterSagaCounts регистрирует potter_saga_count{status}, опрашивающий source при scrape
+// (например, SagaQueryHandler.StatusCounts)
+func (e *PrometheusExporter) RegisterSagaCounts(source CountsSource) error {
+	return e.registerCounts(SagaCountMetric, "Number of sagas by status.", "status", source)
+}
+
+// RegisterProjectionLag регистрирует potter_projection_lag_events{projection}, опрашивающий
+// source при scrape (например, ProjectionManager.EventsBehind)
+func (e *PrometheusExporter) RegisterProjectionLag(source CountsSource) error {
+	return e.registerCounts(ProjectionLagMetric, "Number of events a projection is behind the head of the event stream.", "projection", source)
+}
+
+// registerCounts регистрирует CountsCollector
+func (e *PrometheusExporter) registerCounts(name, help, label string, source CountsSource) error {
+	collector := NewCountsCollector(DefaultNamespace, name, help, label, source).WithTimeout(e.collectTimeout)
+	return e.registry.Register(collector)
+}
+
+// Handler возвращает HTTP handler для scrape (обычно монтируется на /metrics)
+func (e *PrometheusExporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+		Registry:      e.registry,
+	})
+}
+
+// CountsSource возвращает значения метрики по значениям одной метки
+// (статус саги -> число саг, имя проекции -> отставание)
+type CountsSource func(ctx context.Context) (map[string]int64, error)
+
+// CountsCollector prometheus.Collector, опрашивающий CountsSource при каждом scrape.
+// Ошибка источника возвращается scrape как invalid metric, не прерывая сбор остальных метрик.
+type CountsCollector struct {
+	desc    *prometheus.Desc
+	source  CountsSource
+	timeout time.Duration
+}
+
+// NewCountsCollector создает коллектор gauge namespace_name{label}
+func NewCountsCollector(namespace, name, help, label string, source CountsSource) *CountsCollector {
+	return &CountsCollector{
+		desc:    prometheus.NewDesc(prometheus.BuildFQName(namespace, "", promName(name)), help, []string{label}, nil),
+		source:  source,
+		timeout: defaultCollectTimeout,
+	}
+}
+
+// WithTimeout ограничивает время опроса источника
+func (c *CountsCollector) WithTimeout(timeout time.Duration) *CountsCollector {
+	c.timeout = timeout
+	return c
+}
+
+// Describe реализует prometheus.Collector
+func (c *CountsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect реализует prometheus.Collector
+func (c *CountsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	counts, err := c.source(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for value, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), value)
+	}
+}
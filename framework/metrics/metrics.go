@@ -1,178 +1,114 @@
-// Package metrics предоставляет систему метрик на основе OpenTelemetry.
+// Package metrics предоставляет систему метрик с подключаемым backend'ом (OpenTelemetry, Prometheus, StatsD).
 package metrics
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/metric"
 )
 
-// Metrics сборщик метрик приложения
+// Metrics сборщик метрик приложения.
+// Метрики записываются через Recorder, поэтому backend выбирается при создании
+// (см. NewMetricsWithRecorder); NewMetrics использует OpenTelemetry.
 type Metrics struct {
-	meter           metric.Meter
-	commandsTotal   metric.Int64Counter
-	queriesTotal    metric.Int64Counter
-	eventsTotal     metric.Int64Counter
-	commandDuration metric.Float64Histogram
-	queryDuration   metric.Float64Histogram
-	errorsTotal     metric.Int64Counter
-	activeCommands  metric.Int64UpDownCounter
-	activeQueries   metric.Int64UpDownCounter
-	customMetrics   map[string]interface{}
-	mu              sync.RWMutex
-}
-
-// NewMetrics создает новый сборщик метрик
-func NewMetrics() (*Metrics, error) {
-	meter := otel.Meter("potter")
-
-	commandsTotal, err := meter.Int64Counter(
-		"commands_total",
-		metric.WithDescription("Total number of commands processed"),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	queriesTotal, err := meter.Int64Counter(
-		"queries_total",
-		metric.WithDescription("Total number of queries processed"),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	eventsTotal, err := meter.Int64Counter(
-		"events_total",
-		metric.WithDescription("Total number of events published"),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	commandDuration, err := meter.Float64Histogram(
-		"command_duration_seconds",
-		metric.WithDescription("Command processing duration in seconds"),
-		metric.WithUnit("s"),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	queryDuration, err := meter.Float64Histogram(
-		"query_duration_seconds",
-		metric.WithDescription("Query processing duration in seconds"),
-		metric.WithUnit("s"),
-	)
-	if err != nil {
-		return nil, err
-	}
+	recorder      Recorder
+	customMetrics map[string]interface{}
+	mu            sync.RWMutex
+}
 
-	errorsTotal, err := meter.Int64Counter(
-		"errors_total",
-		metric.WithDescription("Total number of errors"),
-	)
-	if err != nil {
-		return nil, err
-	}
+// NewMetrics создает новый сборщик метрик на основе глобального OpenTelemetry MeterProvider
+func NewMetrics() (*Metrics, error) {
+	return NewMetricsWithRecorder(NewOTelRecorder(otel.Meter("potter"))), nil
+}
 
-	activeCommands, err := meter.Int64UpDownCounter(
-		"active_commands",
-		metric.WithDescription("Number of active commands being processed"),
-	)
-	if err != nil {
-		return nil, err
+// NewMetricsWithRecorder создает сборщик метрик, записывающий метрики через recorder
+// (PrometheusRecorder, OTLPRecorder, StatsDRecorder или собственную реализацию)
+func NewMetricsWithRecorder(recorder Recorder) *Metrics {
+	if recorder == nil {
+		recorder = NopRecorder{}
 	}
-
-	activeQueries, err := meter.Int64UpDownCounter(
-		"active_queries",
-		metric.WithDescription("Number of active queries being processed"),
-	)
-	if err != nil {
-		return nil, err
+	return &Metrics{
+		recorder:      recorder,
+		customMetrics: make(map[string]interface{}),
 	}
+}
 
-	return &Metrics{
-		meter:           meter,
-		commandsTotal:   commandsTotal,
-		queriesTotal:     queriesTotal,
-		eventsTotal:      eventsTotal,
-		commandDuration: commandDuration,
-		queryDuration:   queryDuration,
-		errorsTotal:     errorsTotal,
-		activeCommands:  activeCommands,
-		activeQueries:   activeQueries,
-		customMetrics:   make(map[string]interface{}),
-	}, nil
+// Recorder возвращает backend метрик
+func (m *Metrics) Recorder() Recorder {
+	return m.recorder
 }
 
 // RecordCommand записывает метрику команды
 func (m *Metrics) RecordCommand(ctx context.Context, commandName string, duration time.Duration, success bool) {
-	attrs := []attribute.KeyValue{
-		attribute.String("command", commandName),
-		attribute.Bool("success", success),
+	labels := Labels{
+		"command": commandName,
+		"success": strconv.FormatBool(success),
 	}
 
-	m.commandsTotal.Add(ctx, 1, metric.WithAttributes(attrs...))
-	m.commandDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
+	m.recorder.Counter(ctx, "commands_total", 1, labels)
+	ObserveDuration(ctx, m.recorder, "command_duration_seconds", duration, labels)
 
 	if !success {
-		m.errorsTotal.Add(ctx, 1, metric.WithAttributes(
-			attribute.String("type", "command"),
-			attribute.String("command", commandName),
-		))
+		m.recordError(ctx, "command", "command", commandName)
 	}
 }
 
 // RecordQuery записывает метрику запроса
 func (m *Metrics) RecordQuery(ctx context.Context, queryName string, duration time.Duration, success bool) {
-	attrs := []attribute.KeyValue{
-		attribute.String("query", queryName),
-		attribute.Bool("success", success),
+	labels := Labels{
+		"query":   queryName,
+		"success": strconv.FormatBool(success),
 	}
 
-	m.queriesTotal.Add(ctx, 1, metric.WithAttributes(attrs...))
-	m.queryDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
+	m.recorder.Counter(ctx, "queries_total", 1, labels)
+	ObserveDuration(ctx, m.recorder, "query_duration_seconds", duration, labels)
 
 	if !success {
-		m.errorsTotal.Add(ctx, 1, metric.WithAttributes(
-			attribute.String("type", "query"),
-			attribute.String("query", queryName),
-		))
+		m.recordError(ctx, "query", "query", queryName)
 	}
 }
 
 // RecordEvent записывает метрику события
 func (m *Metrics) RecordEvent(ctx context.Context, eventType string) {
-	attrs := []attribute.KeyValue{
-		attribute.String("event", eventType),
-	}
-
-	m.eventsTotal.Add(ctx, 1, metric.WithAttributes(attrs...))
+	m.recorder.Counter(ctx, "events_total", 1, Labels{"event": eventType})
 }
 
 // IncrementActiveCommands увеличивает счетчик активных команд
 func (m *Metrics) IncrementActiveCommands(ctx context.Context) {
-	m.activeCommands.Add(ctx, 1)
+	m.recorder.Gauge(ctx, "active_commands", 1, nil)
 }
 
 // DecrementActiveCommands уменьшает счетчик активных команд
 func (m *Metrics) DecrementActiveCommands(ctx context.Context) {
-	m.activeCommands.Add(ctx, -1)
+	m.recorder.Gauge(ctx, "active_commands", -1, nil)
 }
 
 // IncrementActiveQueries увеличивает счетчик активных запросов
 func (m *Metrics) IncrementActiveQueries(ctx context.Context) {
-	m.activeQueries.Add(ctx, 1)
+	m.recorder.Gauge(ctx, "active_queries", 1, nil)
 }
 
 // DecrementActiveQueries уменьшает счетчик активных запросов
 func (m *Metrics) DecrementActiveQueries(ctx context.Context) {
-	m.activeQueries.Add(ctx, -1)
+	m.recorder.Gauge(ctx, "active_queries", -1, nil)
+}
+
+// errorLabelKeys ключи меток errors_total по типу ошибки
+var errorLabelKeys = []string{"command", "query", "transport", "operation"}
+
+// recordError увеличивает errors_total с меткой type и меткой источника ошибки
+// (command, query, transport или operation). Набор ключей меток одинаков для всех
+// типов ошибок (Prometheus требует этого): ключи других типов передаются пустыми.
+func (m *Metrics) recordError(ctx context.Context, errorType, key, name string) {
+	labels := Labels{"type": errorType}
+	for _, k := range errorLabelKeys {
+		labels[k] = ""
+	}
+	labels[key] = name
+	m.recorder.Counter(ctx, "errors_total", 1, labels)
 }
 
 // Register регистрирует кастомную метрику
@@ -193,25 +129,39 @@ func (m *Metrics) Unregister(name string) error {
 
 // RecordTransport записывает метрику транспорта
 func (m *Metrics) RecordTransport(ctx context.Context, transportName string, duration time.Duration, success bool) {
-	// Записываем метрики транспорта (нужно добавить отдельные счетчики)
-	// Пока используем errorsTotal только для ошибок
+	labels := Labels{
+		"transport": transportName,
+		"success":   strconv.FormatBool(success),
+	}
+
+	m.recorder.Counter(ctx, "transport_operations_total", 1, labels)
+	ObserveDuration(ctx, m.recorder, "transport_operation_duration_seconds", duration, labels)
+
 	if !success {
-		m.errorsTotal.Add(ctx, 1, metric.WithAttributes(
-			attribute.String("type", "transport"),
-			attribute.String("transport", transportName),
-		))
+		m.recordError(ctx, "transport", "transport", transportName)
 	}
 }
 
+// RecordTransportError увеличивает transport_errors_total для ошибок транспорта,
+// не привязанных к измеряемой операции (отключение, ошибка обработчика подписки)
+func (m *Metrics) RecordTransportError(ctx context.Context, transportName, operation string) {
+	m.recorder.Counter(ctx, "transport_errors_total", 1, Labels{
+		"transport": transportName,
+		"operation": operation,
+	})
+}
+
 // RecordContainer записывает метрику контейнера
 func (m *Metrics) RecordContainer(ctx context.Context, operation string, duration time.Duration, success bool) {
-	// Записываем метрики контейнера (нужно добавить отдельные счетчики)
-	// Пока используем errorsTotal только для ошибок
+	labels := Labels{
+		"operation": operation,
+		"success":   strconv.FormatBool(success),
+	}
+
+	m.recorder.Counter(ctx, "container_operations_total", 1, labels)
+	ObserveDuration(ctx, m.recorder, "container_operation_duration_seconds", duration, labels)
+
 	if !success {
-		m.errorsTotal.Add(ctx, 1, metric.WithAttributes(
-			attribute.String("type", "container"),
-			attribute.String("operation", operation),
-		))
+		m.recordError(ctx, "container", "operation", operation)
 	}
 }
-
@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrLabelMismatch запись метрики с набором ключей меток, отличным от зафиксированного первой записью
+var ErrLabelMismatch = errors.New("metric label keys mismatch")
+
+// PrometheusRecorder Recorder на основе клиента Prometheus без OpenTelemetry.
+// Метрики регистрируются в Registerer при первом использовании; набор ключей меток
+// метрики фиксируется первой записью. Записи с другим набором и ошибки регистрации
+// не применяются и передаются обработчику ошибок (по умолчанию - в стандартный лог).
+// Запись в уже зарегистрированную метрику не берет блокировок рекордера:
+// коллекторы хранятся в sync.Map, мьютекс защищает только регистрацию.
+type PrometheusRecorder struct {
+	registerer prometheus.Registerer
+	namespace  string
+	buckets    []float64
+	counters   sync.Map // name -> *promMetric[*prometheus.CounterVec]
+	gauges     sync.Map // name -> *promMetric[*prometheus.GaugeVec]
+	histograms sync.Map // name -> *promMetric[*prometheus.HistogramVec]
+	onError    func(err error)
+	mu         sync.Mutex
+}
+
+// promMetric зарегистрированный коллектор и зафиксированный набор ключей меток
+type promMetric[T prometheus.Collector] struct {
+	keys []string
+	vec  T
+}
+
+// NewPrometheusRecorder создает PrometheusRecorder; nil registerer - prometheus.DefaultRegisterer
+func NewPrometheusRecorder(registerer prometheus.Registerer) *PrometheusRecorder {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	return &PrometheusRecorder{
+		registerer: registerer,
+		buckets:    prometheus.DefBuckets,
+		onError: func(err error) {
+			log.Printf("metrics: %v", err)
+		},
+	}
+}
+
+// WithNamespace задает префикс имен метрик (namespace_name)
+func (r *PrometheusRecorder) WithNamespace(namespace string) *PrometheusRecorder {
+	r.namespace = namespace
+	return r
+}
+
+// WithBuckets задает границы бакетов гистограмм (по умолчанию prometheus.DefBuckets)
+func (r *PrometheusRecorder) WithBuckets(buckets []float64) *PrometheusRecorder {
+	r.buckets = buckets
+	return r
+}
+
+// WithErrorHandler задает обработчик записей, которые не удалось применить
+// (ErrLabelMismatch, ошибки регистрации коллектора)
+func (r *PrometheusRecorder) WithErrorHandler(onError func(err error)) *PrometheusRecorder {
+	r.onError = onError
+	return r
+}
+
+// Counter увеличивает счетчик
+func (r *PrometheusRecorder) Counter(ctx context.Context, name string, value int64, labels Labels) {
+	vec, ok := loadCollector(r, &r.counters, name, labels, func(keys []string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: r.namespace, Name: promName(name)}, keys)
+	})
+	if ok {
+		vec.With(prometheus.Labels(labels)).Add(float64(value))
+	}
+}
+
+// Gauge изменяет значение gauge
+func (r *PrometheusRecorder) Gauge(ctx context.Context, name string, delta int64, labels Labels) {
+	vec, ok := loadCollector(r, &r.gauges, name, labels, func(keys []string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: r.namespace, Name: promName(name)}, keys)
+	})
+	if ok {
+		vec.With(prometheus.Labels(labels)).Add(float64(delta))
+	}
+}
+
+// Histogram записывает наблюдение
+func (r *PrometheusRecorder) Histogram(ctx context.Context, name string, value float64, labels Labels) {
+	vec, ok := loadCollector(r, &r.histograms, name, labels, func(keys []string) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: r.namespace, Name: promName(name), Buckets: r.buckets}, keys)
+	})
+	if ok {
+		vec.With(prometheus.Labels(labels)).Observe(value)
+	}
+}
+
+// loadCollector возвращает коллектор метрики, регистрируя его при первой записи,
+// и проверяет, что labels соответствует зафиксированному набору ключей.
+// Ошибки передаются обработчику после освобождения блокировки регистрации.
+func loadCollector[T prometheus.Collector](r *PrometheusRecorder, metrics *sync.Map, name string, labels Labels, create func(keys []string) T) (T, bool) {
+	var zero T
+	value, exists := metrics.Load(name)
+	if !exists {
+		metric, err := registerMetric(r, metrics, name, sortedLabelKeys(labels), create)
+		if err != nil {
+			r.reportError(err)
+			return zero, false
+		}
+		value = metric
+	}
+
+	metric := value.(*promMetric[T])
+	if !labelKeysMatch(metric.keys, labels) {
+		r.reportError(fmt.Errorf("%w: %s recorded with labels %v, registered with %v", ErrLabelMismatch, name, sortedLabelKeys(labels), metric.keys))
+		return zero, false
+	}
+	return metric.vec, true
+}
+
+// registerMetric регистрирует коллектор метрики под блокировкой регистрации;
+// если коллектор уже зарегистрирован конкурентной записью, возвращается он
+func registerMetric[T prometheus.Collector](r *PrometheusRecorder, metrics *sync.Map, name string, keys []string, create func(keys []string) T) (*promMetric[T], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if value, exists := metrics.Load(name); exists {
+		return value.(*promMetric[T]), nil
+	}
+	vec, err := registerCollector(r.registerer, create(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register metric %s: %w", name, err)
+	}
+	metric := &promMetric[T]{keys: keys, vec: vec}
+	metrics.Store(name, metric)
+	return metric, nil
+}
+
+// labelKeysMatch проверяет, что labels содержит ровно ключи keys
+func labelKeysMatch(keys []string, labels Labels) bool {
+	if len(keys) != len(labels) {
+		return false
+	}
+	for _, key := range keys {
+		if _, ok := labels[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// reportError передает ошибку записи обработчику ошибок
+func (r *PrometheusRecorder) reportError(err error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+}
+
+// registerCollector регистрирует коллектор; если такой же коллектор уже зарегистрирован
+// (например, другим PrometheusRecorder), используется существующий
+func registerCollector[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		var zero T
+		return zero, err
+	}
+	return collector, nil
+}
+
+// promName приводит имя метрики к допустимому в Prometheus виду (saga.started -> saga_started)
+func promName(name string) string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(name)
+}
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// Labels метки (измерения) метрики.
+// Метрика с одним именем должна записываться с одинаковым набором ключей меток:
+// Prometheus не допускает разные наборы меток у одной метрики. Метки с пустым значением
+// не передаются в OpenTelemetry и StatsD (в Prometheus пустая метка равнозначна отсутствующей).
+type Labels map[string]string
+
+// Recorder абстракция backend'а метрик. Инструментация саг, event sourcing и транспорта
+// записывает метрики только через Recorder, поэтому backend (Prometheus, OTLP, StatsD)
+// выбирается при старте приложения. Реализации должны быть безопасны для конкурентного использования.
+type Recorder interface {
+	// Counter увеличивает монотонный счетчик name на value
+	Counter(ctx context.Context, name string, value int64, labels Labels)
+	// Gauge изменяет значение name на delta (например, число активных операций)
+	Gauge(ctx context.Context, name string, delta int64, labels Labels)
+	// Histogram записывает наблюдение value в распределение name
+	Histogram(ctx context.Context, name string, value float64, labels Labels)
+}
+
+// ObserveDuration записывает длительность операции в секундах в гистограмму name
+func ObserveDuration(ctx context.Context, recorder Recorder, name string, duration time.Duration, labels Labels) {
+	recorder.Histogram(ctx, name, duration.Seconds(), labels)
+}
+
+// RecordOperation записывает счетчик <prefix>_operations_total и гистограмму
+// <prefix>_operation_duration_seconds для операции компонента. Метки: operation, success
+// и дополнительные labels. Nil recorder игнорируется.
+func RecordOperation(ctx context.Context, recorder Recorder, prefix, operation string, start time.Time, err error, labels Labels) {
+	if recorder == nil {
+		return
+	}
+
+	all := make(Labels, len(labels)+2)
+	for k, v := range labels {
+		all[k] = v
+	}
+	all["operation"] = operation
+	all["success"] = strconv.FormatBool(err == nil)
+
+	recorder.Counter(ctx, prefix+"_operations_total", 1, all)
+	ObserveDuration(ctx, recorder, prefix+"_operation_duration_seconds", time.Since(start), all)
+}
+
+// sortedLabelKeys возвращает ключи меток в детерминированном порядке
+func sortedLabelKeys(labels Labels) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NopRecorder Recorder, отбрасывающий все метрики
+type NopRecorder struct{}
+
+// Counter ничего не делает
+func (NopRecorder) Counter(context.Context, string, int64, Labels) {}
+
+// Gauge ничего не делает
+func (NopRecorder) Gauge(context.Context, string, int64, Labels) {}
+
+// Histogram ничего не делает
+func (NopRecorder) Histogram(context.Context, string, float64, Labels) {}
+
+// OTelRecorder Recorder на основе OpenTelemetry Metrics API.
+// Экспорт определяется MeterProvider: Prometheus exporter (SetupMetrics) или OTLP (NewOTLPRecorder).
+type OTelRecorder struct {
+	meter      metric.Meter
+	counters   map[string]metric.Int64Counter
+	gauges     map[string]metric.Int64UpDownCounter
+	histograms map[string]metric.Float64Histogram
+	mu         sync.RWMutex
+}
+
+// NewOTelRecorder создает Recorder поверх meter; nil meter - глобальный meter "potter"
+func NewOTelRecorder(meter metric.Meter) *OTelRecorder {
+	if meter == nil {
+		meter = otel.Meter("potter")
+	}
+	return &OTelRecorder{
+		meter:      meter,
+		counters:   make(map[string]metric.Int64Counter),
+		gauges:     make(map[string]metric.Int64UpDownCounter),
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+// Counter увеличивает счетчик
+func (r *OTelRecorder) Counter(ctx context.Context, name string, value int64, labels Labels) {
+	counter, err := otelInstrument(&r.mu, r.counters, name, func() (metric.Int64Counter, error) {
+		return r.meter.Int64Counter(name)
+	})
+	if err != nil {
+		return
+	}
+	counter.Add(ctx, value, metric.WithAttributes(otelAttributes(labels)...))
+}
+
+// Gauge изменяет значение up-down счетчика
+func (r *OTelRecorder) Gauge(ctx context.Context, name string, delta int64, labels Labels) {
+	gauge, err := otelInstrument(&r.mu, r.gauges, name, func() (metric.Int64UpDownCounter, error) {
+		return r.meter.Int64UpDownCounter(name)
+	})
+	if err != nil {
+		return
+	}
+	gauge.Add(ctx, delta, metric.WithAttributes(otelAttributes(labels)...))
+}
+
+// Histogram записывает наблюдение; для имен с суффиксом _seconds единица измерения "s"
+func (r *OTelRecorder) Histogram(ctx context.Context, name string, value float64, labels Labels) {
+	histogram, err := otelInstrument(&r.mu, r.histograms, name, func() (metric.Float64Histogram, error) {
+		if strings.HasSuffix(name, "_seconds") {
+			return r.meter.Float64Histogram(name, metric.WithUnit("s"))
+		}
+		return r.meter.Float64Histogram(name)
+	})
+	if err != nil {
+		return
+	}
+	histogram.Record(ctx, value, metric.WithAttributes(otelAttributes(labels)...))
+}
+
+// otelInstrument возвращает инструмент из кэша, создавая его при первом использовании
+func otelInstrument[T any](mu *sync.RWMutex, cache map[string]T, name string, create func() (T, error)) (T, error) {
+	mu.RLock()
+	instrument, ok := cache[name]
+	mu.RUnlock()
+	if ok {
+		return instrument, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if instrument, ok := cache[name]; ok {
+		return instrument, nil
+	}
+	instrument, err := create()
+	if err != nil {
+		return instrument, err
+	}
+	cache[name] = instrument
+	return instrument, nil
+}
+
+// otelAttributes конвертирует метки в атрибуты OpenTelemetry
+func otelAttributes(labels Labels) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for _, k := range sortedLabelKeys(labels) {
+		if labels[k] != "" {
+			attrs = append(attrs, attribute.String(k, labels[k]))
+		}
+	}
+	return attrs
+}
+
+// OTLPRecorder Recorder, периодически отправляющий метрики через OTLP exporter.
+// Exporter создается приложением (например, otlpmetrichttp.New или otlpmetricgrpc.New),
+// поэтому фреймворк не зависит от конкретного протокола OTLP.
+type OTLPRecorder struct {
+	*OTelRecorder
+	provider *sdkmetric.MeterProvider
+}
+
+// NewOTLPRecorder создает OTLPRecorder с собственным MeterProvider (глобальный provider не изменяется)
+func NewOTLPRecorder(exporter sdkmetric.Exporter, opts ...sdkmetric.PeriodicReaderOption) *OTLPRecorder {
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, opts...)),
+	)
+	return &OTLPRecorder{
+		OTelRecorder: NewOTelRecorder(provider.Meter("potter")),
+		provider:     provider,
+	}
+}
+
+// Shutdown отправляет накопленные метрики и останавливает exporter
+func (r *OTLPRecorder) Shutdown(ctx context.Context) error {
+	return r.provider.Shutdown(ctx)
+}
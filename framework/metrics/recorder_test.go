@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// loadVec возвращает зарегистрированный коллектор метрики рекордера
+func loadVec[T prometheus.Collector](metrics *sync.Map, name string) T {
+	value, _ := metrics.Load(name)
+	return value.(*promMetric[T]).vec
+}
+
+func TestPrometheusRecorder_RecordsMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder := NewPrometheusRecorder(registry).WithNamespace("potter")
+	ctx := context.Background()
+
+	recorder.Counter(ctx, "commands_total", 2, Labels{"command": "create_order", "success": "true"})
+	recorder.Counter(ctx, "commands_total", 1, Labels{"command": "create_order", "success": "true"})
+	recorder.Gauge(ctx, "active_commands", 3, nil)
+	recorder.Gauge(ctx, "active_commands", -1, nil)
+	recorder.Histogram(ctx, "command_duration_seconds", 0.2, Labels{"command": "create_order"})
+
+	if value := testutil.ToFloat64(loadVec[*prometheus.CounterVec](&recorder.counters, "commands_total").WithLabelValues("create_order", "true")); value != 3 {
+		t.Errorf("Expected commands_total = 3, got %v", value)
+	}
+	if value := testutil.ToFloat64(loadVec[*prometheus.GaugeVec](&recorder.gauges, "active_commands")); value != 2 {
+		t.Errorf("Expected active_commands = 2, got %v", value)
+	}
+	if count, err := testutil.GatherAndCount(registry, "potter_commands_total", "potter_command_duration_seconds"); err != nil || count != 2 {
+		t.Errorf("Expected namespaced metrics in registry, got %d (%v)", count, err)
+	}
+	if count := testutil.CollectAndCount(loadVec[*prometheus.HistogramVec](&recorder.histograms, "command_duration_seconds")); count != 1 {
+		t.Errorf("Expected 1 histogram series, got %d", count)
+	}
+}
+
+func TestPrometheusRecorder_LabelMismatch(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	var reported []error
+	recorder := NewPrometheusRecorder(registry).WithErrorHandler(func(err error) {
+		reported = append(reported, err)
+	})
+	ctx := context.Background()
+
+	recorder.Counter(ctx, "events_total", 1, Labels{"event": "OrderCreated"})
+	recorder.Counter(ctx, "events_total", 1, Labels{"event": "OrderCreated", "tenant": "acme"})
+
+	if len(reported) != 1 || !errors.Is(reported[0], ErrLabelMismatch) {
+		t.Fatalf("Expected ErrLabelMismatch, got %v", reported)
+	}
+	if !strings.Contains(reported[0].Error(), "events_total") {
+		t.Errorf("Expected metric name in error, got %v", reported[0])
+	}
+	if value := testutil.ToFloat64(loadVec[*prometheus.CounterVec](&recorder.counters, "events_total").WithLabelValues("OrderCreated")); value != 1 {
+		t.Errorf("Expected counter to keep first write only, got %v", value)
+	}
+}
+
+func TestPrometheusRecorder_ErrorHandlerRecordsMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	ctx := context.Background()
+	var recorder *PrometheusRecorder
+	// Обработчик вызывается без блокировок рекордера и может сам записывать метрики
+	recorder = NewPrometheusRecorder(registry).WithErrorHandler(func(err error) {
+		recorder.Counter(ctx, "metric_errors_total", 1, nil)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				recorder.Counter(ctx, "events_total", 1, Labels{"event": "OrderCreated"})
+				recorder.Histogram(ctx, "event_duration_seconds", 0.1, nil)
+			}
+		}()
+	}
+	wg.Wait()
+	recorder.Counter(ctx, "events_total", 1, Labels{"tenant": "acme"})
+
+	if value := testutil.ToFloat64(loadVec[*prometheus.CounterVec](&recorder.counters, "events_total").WithLabelValues("OrderCreated")); value != 800 {
+		t.Errorf("Expected events_total = 800, got %v", value)
+	}
+	if value := testutil.ToFloat64(loadVec[*prometheus.CounterVec](&recorder.counters, "metric_errors_total")); value != 1 {
+		t.Errorf("Expected label mismatch to be counted once, got %v", value)
+	}
+}
+
+func TestPrometheusRecorder_SharedRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	ctx := context.Background()
+
+	NewPrometheusRecorder(registry).Counter(ctx, "queries_total", 1, Labels{"query": "get_order"})
+	NewPrometheusRecorder(registry).Counter(ctx, "queries_total", 1, Labels{"query": "get_order"})
+
+	if count, err := testutil.GatherAndCount(registry, "queries_total"); err != nil || count != 1 {
+		t.Fatalf("Expected one shared series, got %d (%v)", count, err)
+	}
+}
+
+func TestMetrics_ErrorLabels(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	var reported []error
+	recorder := NewPrometheusRecorder(registry).WithErrorHandler(func(err error) {
+		reported = append(reported, err)
+	})
+	m := NewMetricsWithRecorder(recorder)
+	ctx := context.Background()
+
+	m.RecordCommand(ctx, "create_order", time.Millisecond, false)
+	m.RecordQuery(ctx, "get_order", time.Millisecond, false)
+	m.RecordTransport(ctx, "nats", time.Millisecond, false)
+	m.RecordContainer(ctx, "resolve", time.Millisecond, false)
+
+	if len(reported) != 0 {
+		t.Fatalf("Expected consistent errors_total labels, got %v", reported)
+	}
+	errorsTotal := loadVec[*prometheus.CounterVec](&recorder.counters, "errors_total")
+	for _, labels := range []prometheus.Labels{
+		{"type": "command", "command": "create_order", "query": "", "transport": "", "operation": ""},
+		{"type": "query", "command": "", "query": "get_order", "transport": "", "operation": ""},
+		{"type": "transport", "command": "", "query": "", "transport": "nats", "operation": ""},
+		{"type": "container", "command": "", "query": "", "transport": "", "operation": "resolve"},
+	} {
+		if value := testutil.ToFloat64(errorsTotal.With(labels)); value != 1 {
+			t.Errorf("Expected errors_total%v = 1, got %v", labels, value)
+		}
+	}
+}
+
+func TestStatsDRecorder_Send(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	recorder, err := NewStatsDRecorder(listener.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	defer recorder.Close()
+	recorder.WithPrefix("potter")
+	ctx := context.Background()
+
+	recorder.Counter(ctx, "commands_total", 2, Labels{"success": "true", "command": "create:order"})
+	recorder.Gauge(ctx, "active_commands", -1, nil)
+	recorder.Gauge(ctx, "active_commands", 1, nil)
+	recorder.Histogram(ctx, "command_duration_seconds", 0.25, Labels{"command": "create_order", "query": ""})
+
+	expected := []string{
+		"potter.commands_total:2|c|#command:create_order,success:true",
+		"potter.active_commands:-1|g",
+		"potter.active_commands:+1|g",
+		"potter.command_duration_seconds:0.25|h|#command:create_order",
+	}
+	buf := make([]byte, 1024)
+	for _, want := range expected {
+		_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := listener.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Failed to read packet: %v", err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StatsDRecorder Recorder, отправляющий метрики по UDP в StatsD агент.
+// Метки передаются в формате DogStatsD (|#key:value), поддерживаемом Datadog,
+// Telegraf и statsd_exporter. Ошибки отправки игнорируются, как принято для StatsD.
+type StatsDRecorder struct {
+	conn   net.Conn
+	prefix string
+	mu     sync.Mutex
+}
+
+// NewStatsDRecorder создает StatsDRecorder для агента по адресу addr (например, "localhost:8125")
+func NewStatsDRecorder(addr string) (*StatsDRecorder, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd: %w", err)
+	}
+	return &StatsDRecorder{conn: conn}, nil
+}
+
+// WithPrefix задает префикс имен метрик (prefix.name)
+func (r *StatsDRecorder) WithPrefix(prefix string) *StatsDRecorder {
+	r.prefix = prefix
+	return r
+}
+
+// Counter отправляет счетчик (тип c)
+func (r *StatsDRecorder) Counter(ctx context.Context, name string, value int64, labels Labels) {
+	r.send(name, strconv.FormatInt(value, 10), "c", labels)
+}
+
+// Gauge отправляет изменение gauge (тип g со знаком)
+func (r *StatsDRecorder) Gauge(ctx context.Context, name string, delta int64, labels Labels) {
+	value := strconv.FormatInt(delta, 10)
+	if delta >= 0 {
+		value = "+" + value
+	}
+	r.send(name, value, "g", labels)
+}
+
+// Histogram отправляет наблюдение (тип h)
+func (r *StatsDRecorder) Histogram(ctx context.Context, name string, value float64, labels Labels) {
+	r.send(name, strconv.FormatFloat(value, 'f', -1, 64), "h", labels)
+}
+
+// Close закрывает соединение
+func (r *StatsDRecorder) Close() error {
+	return r.conn.Close()
+}
+
+// send форматирует и отправляет одну строку протокола StatsD
+func (r *StatsDRecorder) send(name, value, metricType string, labels Labels) {
+	var line strings.Builder
+	if r.prefix != "" {
+		line.WriteString(r.prefix)
+		line.WriteByte('.')
+	}
+	line.WriteString(statsdSanitize(name))
+	line.WriteByte(':')
+	line.WriteString(value)
+	line.WriteByte('|')
+	line.WriteString(metricType)
+
+	tags := 0
+	for _, k := range sortedLabelKeys(labels) {
+		if labels[k] == "" {
+			continue
+		}
+		if tags == 0 {
+			line.WriteString("|#")
+		} else {
+			line.WriteByte(',')
+		}
+		line.WriteString(statsdSanitize(k))
+		line.WriteByte(':')
+		line.WriteString(statsdSanitize(labels[k]))
+		tags++
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.conn.Write([]byte(line.String()))
+}
+
+// statsdSanitize заменяет символы, зарезервированные протоколом StatsD
+func statsdSanitize(s string) string {
+	return strings.NewReplacer(":", "_", "|", "_", "@", "_", ",", "_", "#", "_", "\n", "_").Replace(s)
+}
@@ -0,0 +1,248 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// currentDialect диалект, установленный SetDialect (используется RunMigrationsFS)
+var currentDialect = "postgres"
+
+// ErrDialectNotSupported возникает, когда у набора нет встроенных миграций для диалекта
+var ErrDialectNotSupported = errors.New("embedded migrations are not available for dialect")
+
+// MigrationSet набор миграций из fs.FS (обычно встроенных через go:embed) с собственной
+// таблицей версий. Наборы разных модулей нумеруют миграции независимо (001, 002, ...),
+// поэтому каждый набор версионируется в отдельной таблице.
+type MigrationSet struct {
+	// Name имя набора (eventsourcing, saga, invoke)
+	Name string
+	// Dialect диалект БД (postgres, mysql, sqlite3)
+	Dialect string
+	// FS файловая система с .sql файлами миграций в корне
+	FS fs.FS
+	// TableName таблица версий набора (по умолчанию potter_<name>_db_version)
+	TableName string
+}
+
+// NewEmbeddedMigrationSet создает набор из встроенных миграций, разложенных по диалектам:
+// fsys/<root>/<dialect>/*.sql. Если для диалекта нет миграций, возвращает ErrDialectNotSupported
+// со списком доступных диалектов. Встроенных миграций для sqlite3 нет: SQLite хранилища
+// создают схему при открытии, так как имена их таблиц задаются конфигурацией.
+func NewEmbeddedMigrationSet(name string, fsys fs.FS, root, dialect string) (MigrationSet, error) {
+	if dialect == "" {
+		dialect = "postgres"
+	}
+	dir := path.Join(root, dialectDir(dialect))
+	if _, err := fs.Stat(fsys, dir); err != nil {
+		return MigrationSet{}, fmt.Errorf("%w %s: %s migrations exist for %s",
+			ErrDialectNotSupported, dialect, name, strings.Join(embeddedDialects(fsys, root), ", "))
+	}
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return MigrationSet{}, fmt.Errorf("failed to open %s migrations: %w", name, err)
+	}
+	return MigrationSet{Name: name, Dialect: dialect, FS: sub}, nil
+}
+
+// tableName возвращает таблицу версий набора
+func (s MigrationSet) tableName() string {
+	if s.TableName != "" {
+		return s.TableName
+	}
+	return fmt.Sprintf("potter_%s_db_version", s.Name)
+}
+
+// RunMigrationsFS применяет все pending миграции из fsys (например, embed.FS сервиса) с
+// диалектом, установленным SetDialect. Версии хранятся в goose_db_version, как у RunMigrations,
+// поэтому сервис может перейти с директории миграций на встроенные без пересоздания таблицы.
+func RunMigrationsFS(db *sql.DB, fsys fs.FS) error {
+	return RunMigrationSets(context.Background(), db, MigrationSet{
+		Name:      "service",
+		Dialect:   currentDialect,
+		FS:        fsys,
+		TableName: goose.DefaultTablename,
+	})
+}
+
+// RunMigrationSets последовательно применяет pending миграции наборов. Для PostgreSQL
+// миграции выполняются под advisory lock, поэтому несколько инстансов сервиса могут
+// мигрировать схему при старте одновременно.
+func RunMigrationSets(ctx context.Context, db *sql.DB, sets ...MigrationSet) error {
+	for _, set := range sets {
+		provider, err := newProvider(db, set)
+		if err != nil {
+			return err
+		}
+		if _, err := provider.Up(ctx); err != nil {
+			return fmt.Errorf("failed to run %s migrations: %w", set.Name, err)
+		}
+	}
+	return nil
+}
+
+// EnsureSchema применяет миграции набора с версиями versions (все, если версии не указаны)
+// без записи в таблицу версий. Хранилища создают так свои таблицы при подключении из тех же
+// файлов, что применяет RunMigrationSets: такие миграции идемпотентны (IF NOT EXISTS), поэтому
+// последующий запуск набора их не нарушает.
+func EnsureSchema(ctx context.Context, db *sql.DB, set MigrationSet, versions ...int64) error {
+	if set.FS == nil {
+		return fmt.Errorf("%s migrations: filesystem is not set", set.Name)
+	}
+	options := []goose.ProviderOption{
+		goose.WithDisableVersioning(true),
+		goose.WithDisableGlobalRegistry(true),
+	}
+	if len(versions) > 0 {
+		excluded, err := excludedVersions(set, versions)
+		if err != nil {
+			return err
+		}
+		options = append(options, goose.WithExcludeVersions(excluded))
+	}
+
+	provider, err := goose.NewProvider(providerDialect(set.Dialect), db, set.FS, options...)
+	if err != nil {
+		return fmt.Errorf("failed to create %s migration provider: %w", set.Name, err)
+	}
+	if _, err := provider.Up(ctx); err != nil {
+		return fmt.Errorf("failed to apply %s schema: %w", set.Name, err)
+	}
+	return nil
+}
+
+// excludedVersions возвращает версии миграций набора, не входящие в versions
+func excludedVersions(set MigrationSet, versions []int64) ([]int64, error) {
+	files, err := fs.Glob(set.FS, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s migrations: %w", set.Name, err)
+	}
+
+	wanted := make(map[int64]bool, len(versions))
+	for _, version := range versions {
+		wanted[version] = false
+	}
+	var excluded []int64
+	for _, file := range files {
+		version, err := goose.NumericComponent(file)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s migration %s: %w", set.Name, file, err)
+		}
+		if _, ok := wanted[version]; ok {
+			wanted[version] = true
+		} else {
+			excluded = append(excluded, version)
+		}
+	}
+	for version, found := range wanted {
+		if !found {
+			return nil, fmt.Errorf("%s migration %d not found for dialect %s", set.Name, version, set.Dialect)
+		}
+	}
+	return excluded, nil
+}
+
+// GetMigrationSetStatus возвращает статус миграций набора
+func GetMigrationSetStatus(ctx context.Context, db *sql.DB, set MigrationSet) ([]MigrationStatus, error) {
+	provider, err := newProvider(db, set)
+	if err != nil {
+		return nil, err
+	}
+	results, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s migration status: %w", set.Name, err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(results))
+	for _, result := range results {
+		status := MigrationStatus{
+			Version: result.Source.Version,
+			Name:    path.Base(result.Source.Path),
+			Status:  string(result.State),
+		}
+		if result.State == goose.StateApplied && !result.AppliedAt.IsZero() {
+			appliedAt := result.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// newProvider создает goose provider набора
+func newProvider(db *sql.DB, set MigrationSet) (*goose.Provider, error) {
+	if set.FS == nil {
+		return nil, fmt.Errorf("%s migrations: filesystem is not set", set.Name)
+	}
+	dialect := providerDialect(set.Dialect)
+
+	options := []goose.ProviderOption{
+		goose.WithTableName(set.tableName()),
+		// Go миграции глобального реестра goose относятся к директории сервиса, а не к набору
+		goose.WithDisableGlobalRegistry(true),
+	}
+	if dialect == goose.DialectPostgres {
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create migration lock: %w", err)
+		}
+		options = append(options, goose.WithSessionLocker(locker))
+	}
+
+	provider, err := goose.NewProvider(dialect, db, set.FS, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s migration provider: %w", set.Name, err)
+	}
+	return provider, nil
+}
+
+// providerDialect приводит имя диалекта (в т.ч. синонимы goose.SetDialect) к диалекту goose
+func providerDialect(dialect string) goose.Dialect {
+	switch dialect {
+	case "", "postgres", "postgresql", "pgx":
+		return goose.DialectPostgres
+	case "mysql":
+		return goose.DialectMySQL
+	case "sqlite", "sqlite3":
+		return goose.DialectSQLite3
+	default:
+		return goose.Dialect(dialect)
+	}
+}
+
+// dialectDir возвращает директорию встроенных миграций диалекта
+func dialectDir(dialect string) string {
+	switch providerDialect(dialect) {
+	case goose.DialectPostgres:
+		return "postgres"
+	case goose.DialectSQLite3:
+		return "sqlite3"
+	default:
+		return dialect
+	}
+}
+
+// embeddedDialects возвращает диалекты, для которых в fsys/<root> есть миграции
+func embeddedDialects(fsys fs.FS, root string) []string {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil
+	}
+	var dialects []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dialects = append(dialects, entry.Name())
+		}
+	}
+	sort.Strings(dialects)
+	return dialects
+}
@@ -0,0 +1,177 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+)
+
+// recordingConnector database/sql коннектор, запоминающий выполненные выражения вместо БД
+type recordingConnector struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (c *recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return recordingDriver{}
+}
+
+// take возвращает и сбрасывает выполненные выражения
+func (c *recordingConnector) take() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	statements := c.statements
+	c.statements = nil
+	return statements
+}
+
+type recordingDriver struct{}
+
+func (recordingDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("recording driver is used through a connector")
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return recordingTx{}, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.connector.mu.Lock()
+	defer c.connector.mu.Unlock()
+	c.connector.statements = append(c.connector.statements, strings.TrimSpace(query))
+	return driver.RowsAffected(0), nil
+}
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+// frameworkMigrations встроенные миграции модулей фреймворка (корни go:embed пакетов)
+var frameworkMigrations = os.DirFS("..")
+
+func TestEmbeddedMigrationSets_ParseWithGoose(t *testing.T) {
+	ctx := context.Background()
+	connector := &recordingConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	unsupported := map[string]bool{
+		"eventsourcing/sqlite3": true,
+		"saga/sqlite3":          true,
+		"invoke/mysql":          true,
+		"invoke/sqlite3":        true,
+	}
+	for _, module := range []string{"eventsourcing", "saga", "invoke"} {
+		for _, dialect := range []string{"postgres", "mysql", "sqlite3"} {
+			set, err := NewEmbeddedMigrationSet(module, frameworkMigrations, module+"/migrations", dialect)
+			if unsupported[module+"/"+dialect] {
+				if !errors.Is(err, ErrDialectNotSupported) {
+					t.Errorf("%s/%s: expected ErrDialectNotSupported, got %v", module, dialect, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s/%s: %v", module, dialect, err)
+			}
+
+			files, err := fs.Glob(set.FS, "*.sql")
+			if err != nil || len(files) == 0 {
+				t.Fatalf("%s/%s: expected migrations, got %v (%v)", module, dialect, files, err)
+			}
+			// Каждый файл разбирается goose и содержит выражения секции Up
+			for _, file := range files {
+				version, err := goose.NumericComponent(file)
+				if err != nil {
+					t.Fatalf("%s/%s: invalid migration name %s: %v", module, dialect, file, err)
+				}
+				if err := EnsureSchema(ctx, db, set, version); err != nil {
+					t.Errorf("%s/%s: %v", module, dialect, err)
+					continue
+				}
+				if statements := connector.take(); len(statements) == 0 {
+					t.Errorf("%s/%s: migration %s has no statements", module, dialect, file)
+				}
+			}
+		}
+	}
+
+	if _, err := NewEmbeddedMigrationSet("saga", frameworkMigrations, "saga/migrations", "sqlite3"); err == nil ||
+		!strings.Contains(err.Error(), "saga migrations exist for mysql, postgres") {
+		t.Errorf("Expected error to list available dialects, got %v", err)
+	}
+}
+
+func TestEnsureSchema_RuntimeMigrationsAreIdempotent(t *testing.T) {
+	ctx := context.Background()
+	connector := &recordingConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	// Миграции, которые хранилища выполняют при подключении
+	cases := []struct {
+		module   string
+		dialect  string
+		versions []int64
+		tables   []string
+	}{
+		{"saga", "postgres", []int64{6, 8}, []string{"saga_read_models", "saga_step_read_models", "last_heartbeat_at"}},
+		{"saga", "mysql", []int64{3}, []string{"saga_read_models", "saga_step_read_models", "last_heartbeat_at"}},
+		{"eventsourcing", "postgres", []int64{10}, []string{"projection_checkpoints"}},
+	}
+	for _, tc := range cases {
+		set, err := NewEmbeddedMigrationSet(tc.module, frameworkMigrations, tc.module+"/migrations", tc.dialect)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", tc.module, tc.dialect, err)
+		}
+		if err := EnsureSchema(ctx, db, set, tc.versions...); err != nil {
+			t.Fatalf("%s/%s: %v", tc.module, tc.dialect, err)
+		}
+
+		statements := connector.take()
+		joined := strings.Join(statements, "\n")
+		for _, table := range tc.tables {
+			if !strings.Contains(joined, table) {
+				t.Errorf("%s/%s: expected %s in schema", tc.module, tc.dialect, table)
+			}
+		}
+		for _, statement := range statements {
+			upper := strings.ToUpper(statement)
+			if (strings.HasPrefix(upper, "CREATE") || strings.HasPrefix(upper, "ALTER")) && !strings.Contains(upper, "IF NOT EXISTS") {
+				t.Errorf("%s/%s: statement is not idempotent: %s", tc.module, tc.dialect, statement)
+			}
+		}
+	}
+
+	set, err := NewEmbeddedMigrationSet("saga", frameworkMigrations, "saga/migrations", "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureSchema(ctx, db, set, 99); err == nil {
+		t.Error("Expected error for unknown migration version")
+	}
+}
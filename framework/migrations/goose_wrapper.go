@@ -205,7 +205,11 @@ func SetDialect(dialect string) error {
 	if dialect == "" {
 		dialect = "postgres"
 	}
-	return goose.SetDialect(dialect)
+	if err := goose.SetDialect(dialect); err != nil {
+		return err
+	}
+	currentDialect = dialect
+	return nil
 }
 
 // SetVersion устанавливает версию миграции в БД без выполнения SQL миграций.
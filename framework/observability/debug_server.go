@@ -0,0 +1,305 @@
+// Copyright 2024 Potter Framework Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/saga"
+)
+
+// ErrDebugServerUnauthenticated возникает при запуске debug server без настроенной аутентификации
+var ErrDebugServerUnauthenticated = errors.New("debug server requires a token or an authorizer")
+
+// DebugServerConfig конфигурация debug server
+type DebugServerConfig struct {
+	// Addr адрес отдельного listener'а (по умолчанию "localhost:6060"); не используется,
+	// если Handler монтируется в существующий router
+	Addr string
+	// Token bearer token, который должен передаваться в заголовке Authorization
+	Token string
+	// AllowUnauthenticated отключает аутентификацию (только для локальной разработки)
+	AllowUnauthenticated bool
+}
+
+// DefaultDebugServerConfig возвращает конфигурацию по умолчанию
+func DefaultDebugServerConfig() DebugServerConfig {
+	return DebugServerConfig{
+		Addr: "localhost:6060",
+	}
+}
+
+// RunningSagasProvider оркестратор, предоставляющий список выполняющихся саг (saga.DefaultOrchestrator)
+type RunningSagasProvider interface {
+	RunningSagas() []string
+}
+
+// DebugServer opt-in сервер диагностики production окружения:
+//   - /debug/pprof/ - профилирование (CPU, heap, goroutine, block, mutex, trace);
+//   - /debug/vars - expvar;
+//   - /debug/potter/runtime - горутины, память, GC;
+//   - /debug/potter/sagas - выполняющиеся саги оркестраторов;
+//   - /debug/potter/sagas/goroutines - goroutine dump горутин саг (по pprof меткам saga.SagaPprofLabelID);
+//   - /debug/potter/eventbus - подписчики и очереди шин событий.
+//
+// Все endpoints требуют аутентификации: bearer token или собственный authorizer.
+type DebugServer struct {
+	config        DebugServerConfig
+	authorizer    func(r *http.Request) bool
+	orchestrators map[string]RunningSagasProvider
+	eventBuses    map[string]events.EventBusStatsProvider
+	server        *http.Server
+	mu            sync.RWMutex
+}
+
+// NewDebugServer создает debug server. Без Token и AllowUnauthenticated
+// необходимо задать authorizer через WithAuthorizer до обслуживания запросов.
+func NewDebugServer(config DebugServerConfig) *DebugServer {
+	if config.Addr == "" {
+		config.Addr = DefaultDebugServerConfig().Addr
+	}
+	return &DebugServer{
+		config:        config,
+		orchestrators: make(map[string]RunningSagasProvider),
+		eventBuses:    make(map[string]events.EventBusStatsProvider),
+	}
+}
+
+// WithAuthorizer задает собственную проверку доступа (например JWT администратора) вместо bearer token
+func (s *DebugServer) WithAuthorizer(authorizer func(r *http.Request) bool) *DebugServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authorizer = authorizer
+	return s
+}
+
+// WithOrchestrator добавляет оркестратор для просмотра выполняющихся саг
+func (s *DebugServer) WithOrchestrator(name string, orchestrator RunningSagasProvider) *DebugServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orchestrators[name] = orchestrator
+	return s
+}
+
+// WithEventBus добавляет шину или публикатор событий для просмотра подписчиков и очередей
+func (s *DebugServer) WithEventBus(name string, bus events.EventBusStatsProvider) *DebugServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventBuses[name] = bus
+	return s
+}
+
+// Handler возвращает http.Handler с debug endpoints под /debug/.
+// Может монтироваться в существующий router, например router.Any("/debug/*path", gin.WrapH(server.Handler())).
+func (s *DebugServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/debug/potter/runtime", s.handleRuntime)
+	mux.HandleFunc("/debug/potter/sagas", s.handleSagas)
+	mux.HandleFunc("/debug/potter/sagas/goroutines", s.handleSagaGoroutines)
+	mux.HandleFunc("/debug/potter/eventbus", s.handleEventBuses)
+
+	return s.authenticate(mux)
+}
+
+// Start запускает debug server на отдельном адресе
+func (s *DebugServer) Start(ctx context.Context) error {
+	if err := s.checkAuth(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.server != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("debug server already started")
+	}
+	s.server = &http.Server{
+		Addr:              s.config.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server := s.server
+	s.mu.Unlock()
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			// Логируем ошибку
+			_ = err
+		}
+	}()
+
+	return nil
+}
+
+// Stop останавливает debug server
+func (s *DebugServer) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	server := s.server
+	s.server = nil
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// checkAuth проверяет, что аутентификация настроена
+func (s *DebugServer) checkAuth() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.config.Token == "" && s.authorizer == nil && !s.config.AllowUnauthenticated {
+		return ErrDebugServerUnauthenticated
+	}
+	return nil
+}
+
+// authenticate проверяет bearer token или authorizer перед обработкой запроса
+func (s *DebugServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="potter-debug"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *DebugServer) authorized(r *http.Request) bool {
+	s.mu.RLock()
+	authorizer := s.authorizer
+	s.mu.RUnlock()
+
+	if authorizer != nil {
+		return authorizer(r)
+	}
+	if s.config.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) == 1
+	}
+	// Без token и authorizer доступ открыт только при явном AllowUnauthenticated
+	return s.config.AllowUnauthenticated
+}
+
+// RuntimeStats снимок состояния runtime
+type RuntimeStats struct {
+	Goroutines   int       `json:"goroutines"`
+	GOMAXPROCS   int       `json:"gomaxprocs"`
+	HeapAlloc    uint64    `json:"heap_alloc_bytes"`
+	HeapObjects  uint64    `json:"heap_objects"`
+	Sys          uint64    `json:"sys_bytes"`
+	NumGC        uint32    `json:"num_gc"`
+	PauseTotalNs uint64    `json:"gc_pause_total_ns"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func (s *DebugServer) handleRuntime(w http.ResponseWriter, r *http.Request) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	writeDebugJSON(w, RuntimeStats{
+		Goroutines:   runtime.NumGoroutine(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    m.HeapAlloc,
+		HeapObjects:  m.HeapObjects,
+		Sys:          m.Sys,
+		NumGC:        m.NumGC,
+		PauseTotalNs: m.PauseTotalNs,
+		Timestamp:    time.Now(),
+	})
+}
+
+func (s *DebugServer) handleSagas(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	result := make(map[string][]string, len(s.orchestrators))
+	for name, orchestrator := range s.orchestrators {
+		result[name] = orchestrator.RunningSagas()
+	}
+	s.mu.RUnlock()
+
+	writeDebugJSON(w, result)
+}
+
+// handleSagaGoroutines возвращает goroutine dump, отфильтрованный по pprof меткам саг
+func (s *DebugServer) handleSagaGoroutines(w http.ResponseWriter, r *http.Request) {
+	var dump bytes.Buffer
+	if err := runtimepprof.Lookup("goroutine").WriteTo(&dump, 1); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, block := range filterGoroutineDump(dump.String(), saga.SagaPprofLabelID) {
+		fmt.Fprintln(w, block)
+	}
+}
+
+func (s *DebugServer) handleEventBuses(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	result := make(map[string]events.EventBusStats, len(s.eventBuses))
+	for name, bus := range s.eventBuses {
+		result[name] = bus.Stats()
+	}
+	s.mu.RUnlock()
+
+	writeDebugJSON(w, result)
+}
+
+// filterGoroutineDump возвращает блоки goroutine dump (debug=1), содержащие метку label
+func filterGoroutineDump(dump, label string) []string {
+	var blocks []string
+	for _, block := range strings.Split(dump, "\n\n") {
+		if strings.Contains(block, `"`+label+`"`) {
+			blocks = append(blocks, strings.TrimSpace(block)+"\n")
+		}
+	}
+	sort.Strings(blocks)
+	return blocks
+}
+
+func writeDebugJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
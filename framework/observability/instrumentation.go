@@ -0,0 +1,577 @@
+// Copyright 2024 Potter Framework Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/eventsourcing"
+	"github.com/akriventsev/potter/framework/metrics"
+	"github.com/akriventsev/potter/framework/saga"
+	"github.com/akriventsev/potter/framework/transport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Имена tracer'ов инструментации
+const (
+	TransportTracerName     = "potter.transport"
+	EventSourcingTracerName = "potter.eventsourcing"
+	SagaTracerName          = "potter.saga"
+)
+
+// Префиксы метрик инструментации (<prefix>_operations_total, <prefix>_operation_duration_seconds)
+const (
+	MessagingMetricsPrefix  = "messaging"
+	EventStoreMetricsPrefix = "eventstore"
+	SagaMetricsPrefix       = "saga"
+)
+
+// InjectTraceHeaders добавляет trace context в заголовки сообщения (NATS, Kafka, Redis)
+func InjectTraceHeaders(ctx context.Context, headers map[string]string) {
+	if headers == nil {
+		return
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// ExtractTraceHeaders извлекает trace context из заголовков сообщения
+func ExtractTraceHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// InjectTraceMetadata добавляет trace context в метаданные события
+func InjectTraceMetadata(ctx context.Context, metadata events.EventMetadata) {
+	if metadata == nil {
+		return
+	}
+	otel.GetTextMapPropagator().Inject(ctx, eventMetadataCarrier(metadata))
+}
+
+// ExtractTraceMetadata извлекает trace context из метаданных события
+func ExtractTraceMetadata(ctx context.Context, metadata events.EventMetadata) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, eventMetadataCarrier(metadata))
+}
+
+// eventMetadataCarrier адаптер для propagation через метаданные события
+type eventMetadataCarrier events.EventMetadata
+
+func (m eventMetadataCarrier) Get(key string) string {
+	if value, ok := m[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (m eventMetadataCarrier) Set(key, value string) {
+	m[key] = value
+}
+
+func (m eventMetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// endSpan фиксирует результат операции и завершает span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TracingPublisher middleware для transport.Publisher: создает producer span
+// и передает trace context получателю через заголовки сообщения
+type TracingPublisher struct {
+	publisher transport.Publisher
+	tracer    trace.Tracer
+	recorder  metrics.Recorder
+}
+
+// NewTracingPublisher создает новый TracingPublisher
+func NewTracingPublisher(publisher transport.Publisher) *TracingPublisher {
+	return &TracingPublisher{
+		publisher: publisher,
+		tracer:    otel.Tracer(TransportTracerName),
+	}
+}
+
+// WithMetrics включает запись метрик публикации (messaging_operations_total{operation="publish"})
+func (p *TracingPublisher) WithMetrics(recorder metrics.Recorder) *TracingPublisher {
+	p.recorder = recorder
+	return p
+}
+
+// Publish публикует сообщение с trace context в заголовках
+func (p *TracingPublisher) Publish(ctx context.Context, subject string, data []byte, headers map[string]string) error {
+	start := time.Now()
+	ctx, span := p.tracer.Start(ctx, fmt.Sprintf("publish %s", subject),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", subject),
+			attribute.Int("messaging.message.body.size", len(data)),
+		),
+	)
+
+	// Копируем заголовки, чтобы не изменять map вызывающего кода
+	traced := make(map[string]string, len(headers)+2)
+	for k, v := range headers {
+		traced[k] = v
+	}
+	InjectTraceHeaders(ctx, traced)
+
+	err := p.publisher.Publish(ctx, subject, data, traced)
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, p.recorder, MessagingMetricsPrefix, "publish", start, err, metrics.Labels{"subject": subject})
+	return err
+}
+
+// TracingSubscriber middleware для transport.Subscriber: продолжает trace из заголовков
+// сообщения и создает consumer span для каждого обработанного сообщения
+type TracingSubscriber struct {
+	subscriber transport.Subscriber
+	tracer     trace.Tracer
+	recorder   metrics.Recorder
+}
+
+// NewTracingSubscriber создает новый TracingSubscriber
+func NewTracingSubscriber(subscriber transport.Subscriber) *TracingSubscriber {
+	return &TracingSubscriber{
+		subscriber: subscriber,
+		tracer:     otel.Tracer(TransportTracerName),
+	}
+}
+
+// WithMetrics включает запись метрик обработки сообщений (messaging_operations_total{operation="process"})
+func (s *TracingSubscriber) WithMetrics(recorder metrics.Recorder) *TracingSubscriber {
+	s.recorder = recorder
+	return s
+}
+
+// Subscribe подписывается на subject, оборачивая handler в consumer span
+func (s *TracingSubscriber) Subscribe(ctx context.Context, subject string, handler transport.MessageHandler) error {
+	return s.subscriber.Subscribe(ctx, subject, func(msgCtx context.Context, msg *transport.Message) error {
+		start := time.Now()
+		msgCtx = ExtractTraceHeaders(msgCtx, msg.Headers)
+		msgCtx, span := s.tracer.Start(msgCtx, fmt.Sprintf("process %s", msg.Subject),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attribute.String("messaging.destination.name", msg.Subject)),
+		)
+		err := handler(msgCtx, msg)
+		endSpan(span, err)
+		metrics.RecordOperation(msgCtx, s.recorder, MessagingMetricsPrefix, "process", start, err, metrics.Labels{"subject": msg.Subject})
+		return err
+	})
+}
+
+// Unsubscribe отписывается от subject
+func (s *TracingSubscriber) Unsubscribe(subject string) error {
+	return s.subscriber.Unsubscribe(subject)
+}
+
+// TracingCommandBus middleware для transport.CommandBus: spans для отправки и обработки команд
+type TracingCommandBus struct {
+	bus    transport.CommandBus
+	tracer trace.Tracer
+}
+
+// NewTracingCommandBus создает новый TracingCommandBus
+func NewTracingCommandBus(bus transport.CommandBus) *TracingCommandBus {
+	return &TracingCommandBus{
+		bus:    bus,
+		tracer: otel.Tracer(TransportTracerName),
+	}
+}
+
+// Send отправляет команду в рамках span
+func (b *TracingCommandBus) Send(ctx context.Context, cmd transport.Command) error {
+	ctx, span := b.tracer.Start(ctx, fmt.Sprintf("command.send %s", cmd.CommandName()),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("command.name", cmd.CommandName())),
+	)
+	err := b.bus.Send(ctx, cmd)
+	endSpan(span, err)
+	return err
+}
+
+// Register регистрирует обработчик, оборачивая его в span
+func (b *TracingCommandBus) Register(handler transport.CommandHandler) error {
+	return b.bus.Register(&tracingCommandHandler{handler: handler, tracer: b.tracer})
+}
+
+// tracingCommandHandler обработчик команды со span
+type tracingCommandHandler struct {
+	handler transport.CommandHandler
+	tracer  trace.Tracer
+}
+
+func (h *tracingCommandHandler) Handle(ctx context.Context, cmd transport.Command) error {
+	ctx, span := h.tracer.Start(ctx, fmt.Sprintf("command.handle %s", cmd.CommandName()),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("command.name", cmd.CommandName())),
+	)
+	err := h.handler.Handle(ctx, cmd)
+	endSpan(span, err)
+	return err
+}
+
+func (h *tracingCommandHandler) CommandName() string {
+	return h.handler.CommandName()
+}
+
+// TracingEventBus middleware для events.EventBus: span при публикации,
+// trace context передается обработчикам через метаданные события
+type TracingEventBus struct {
+	bus      events.EventBus
+	tracer   trace.Tracer
+	mu       sync.Mutex
+	wrappers map[events.EventHandler]events.EventHandler
+}
+
+// NewTracingEventBus создает новый TracingEventBus
+func NewTracingEventBus(bus events.EventBus) *TracingEventBus {
+	return &TracingEventBus{
+		bus:      bus,
+		tracer:   otel.Tracer(TransportTracerName),
+		wrappers: make(map[events.EventHandler]events.EventHandler),
+	}
+}
+
+// Publish публикует событие в рамках span
+func (b *TracingEventBus) Publish(ctx context.Context, event events.Event) error {
+	ctx, span := b.tracer.Start(ctx, fmt.Sprintf("event.publish %s", event.EventType()),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("event.type", event.EventType()),
+			attribute.String("event.aggregate_id", event.AggregateID()),
+		),
+	)
+	InjectTraceMetadata(ctx, event.Metadata())
+	err := b.bus.Publish(ctx, event)
+	endSpan(span, err)
+	return err
+}
+
+// Subscribe подписывает обработчик, продолжающий trace из метаданных события
+func (b *TracingEventBus) Subscribe(eventType string, handler events.EventHandler) error {
+	return b.bus.Subscribe(eventType, b.wrap(handler))
+}
+
+// Unsubscribe отписывает обработчик (вместе с созданной для него оберткой)
+func (b *TracingEventBus) Unsubscribe(eventType string, handler events.EventHandler) error {
+	b.mu.Lock()
+	wrapped, ok := b.wrappers[handler]
+	b.mu.Unlock()
+	if !ok {
+		return b.bus.Unsubscribe(eventType, handler)
+	}
+	return b.bus.Unsubscribe(eventType, wrapped)
+}
+
+// wrap оборачивает обработчик события в consumer span.
+// Для одного обработчика всегда возвращается одна и та же обертка.
+func (b *TracingEventBus) wrap(handler events.EventHandler) events.EventHandler {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if wrapped, ok := b.wrappers[handler]; ok {
+		return wrapped
+	}
+	wrapped := &tracingEventHandler{handler: handler, tracer: b.tracer}
+	b.wrappers[handler] = wrapped
+	return wrapped
+}
+
+// tracingEventHandler обработчик события со span
+type tracingEventHandler struct {
+	handler events.EventHandler
+	tracer  trace.Tracer
+}
+
+func (h *tracingEventHandler) Handle(ctx context.Context, event events.Event) error {
+	ctx = ExtractTraceMetadata(ctx, event.Metadata())
+	ctx, span := h.tracer.Start(ctx, fmt.Sprintf("event.handle %s", event.EventType()),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("event.type", event.EventType())),
+	)
+	err := h.handler.Handle(ctx, event)
+	endSpan(span, err)
+	return err
+}
+
+func (h *tracingEventHandler) EventType() string {
+	return h.handler.EventType()
+}
+
+// TracingEventStore middleware для eventsourcing.EventStore: spans для append/load.
+// При добавлении trace context сохраняется в метаданных событий, что позволяет
+// связать проекции и обработчики с исходной операцией.
+type TracingEventStore struct {
+	store    eventsourcing.EventStore
+	tracer   trace.Tracer
+	recorder metrics.Recorder
+}
+
+// NewTracingEventStore создает новый TracingEventStore
+func NewTracingEventStore(store eventsourcing.EventStore) *TracingEventStore {
+	return &TracingEventStore{
+		store:  store,
+		tracer: otel.Tracer(EventSourcingTracerName),
+	}
+}
+
+// WithMetrics включает запись метрик операций хранилища (eventstore_operations_total)
+func (s *TracingEventStore) WithMetrics(recorder metrics.Recorder) *TracingEventStore {
+	s.recorder = recorder
+	return s
+}
+
+// AppendEvents добавляет события в рамках span
+func (s *TracingEventStore) AppendEvents(ctx context.Context, aggregateID string, expectedVersion int64, evts []events.Event) error {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "eventstore.append",
+		trace.WithAttributes(
+			attribute.String("aggregate.id", aggregateID),
+			attribute.Int64("aggregate.expected_version", expectedVersion),
+			attribute.Int("events.count", len(evts)),
+		),
+	)
+	for _, event := range evts {
+		InjectTraceMetadata(ctx, event.Metadata())
+	}
+	err := s.store.AppendEvents(ctx, aggregateID, expectedVersion, evts)
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, s.recorder, EventStoreMetricsPrefix, "append", start, err, nil)
+	if err == nil && s.recorder != nil {
+		s.recorder.Counter(ctx, "eventstore_events_appended_total", int64(len(evts)), nil)
+	}
+	return err
+}
+
+// GetEvents загружает события агрегата в рамках span
+func (s *TracingEventStore) GetEvents(ctx context.Context, aggregateID string, fromVersion int64) ([]eventsourcing.StoredEvent, error) {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "eventstore.load",
+		trace.WithAttributes(
+			attribute.String("aggregate.id", aggregateID),
+			attribute.Int64("aggregate.from_version", fromVersion),
+		),
+	)
+	stored, err := s.store.GetEvents(ctx, aggregateID, fromVersion)
+	span.SetAttributes(attribute.Int("events.count", len(stored)))
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, s.recorder, EventStoreMetricsPrefix, "load", start, err, nil)
+	return stored, err
+}
+
+// GetEventsPage загружает страницу событий агрегата в рамках span (реализация eventsourcing.EventPageReader)
+func (s *TracingEventStore) GetEventsPage(ctx context.Context, aggregateID string, fromVersion int64, limit int) ([]eventsourcing.StoredEvent, error) {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "eventstore.load_page",
+		trace.WithAttributes(
+			attribute.String("aggregate.id", aggregateID),
+			attribute.Int64("aggregate.from_version", fromVersion),
+			attribute.Int("events.limit", limit),
+		),
+	)
+	stored, err := eventsourcing.GetEventsPage(ctx, s.store, aggregateID, fromVersion, limit)
+	span.SetAttributes(attribute.Int("events.count", len(stored)))
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, s.recorder, EventStoreMetricsPrefix, "load_page", start, err, nil)
+	return stored, err
+}
+
+// GetEventsByType загружает события по типу в рамках span
+func (s *TracingEventStore) GetEventsByType(ctx context.Context, eventType string, fromTimestamp time.Time) ([]eventsourcing.StoredEvent, error) {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "eventstore.load_by_type",
+		trace.WithAttributes(attribute.String("event.type", eventType)),
+	)
+	stored, err := s.store.GetEventsByType(ctx, eventType, fromTimestamp)
+	span.SetAttributes(attribute.Int("events.count", len(stored)))
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, s.recorder, EventStoreMetricsPrefix, "load_by_type", start, err, nil)
+	return stored, err
+}
+
+// GetAllEvents открывает глобальный лог событий (span покрывает только открытие потока)
+func (s *TracingEventStore) GetAllEvents(ctx context.Context, fromPosition int64) (<-chan eventsourcing.StoredEvent, error) {
+	start := time.Now()
+	_, span := s.tracer.Start(ctx, "eventstore.stream",
+		trace.WithAttributes(attribute.Int64("eventstore.from_position", fromPosition)),
+	)
+	ch, err := s.store.GetAllEvents(ctx, fromPosition)
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, s.recorder, EventStoreMetricsPrefix, "stream", start, err, nil)
+	return ch, err
+}
+
+// TracingOrchestrator middleware для saga.SagaOrchestrator: span на выполнение,
+// компенсацию и возобновление саги. Spans шагов создаются InstrumentSagaDefinition.
+type TracingOrchestrator struct {
+	orchestrator saga.SagaOrchestrator
+	tracer       trace.Tracer
+	recorder     metrics.Recorder
+}
+
+// NewTracingOrchestrator создает новый TracingOrchestrator
+func NewTracingOrchestrator(orchestrator saga.SagaOrchestrator) *TracingOrchestrator {
+	return &TracingOrchestrator{
+		orchestrator: orchestrator,
+		tracer:       otel.Tracer(SagaTracerName),
+	}
+}
+
+// WithMetrics включает запись метрик операций оркестратора (saga_operations_total{saga})
+func (o *TracingOrchestrator) WithMetrics(recorder metrics.Recorder) *TracingOrchestrator {
+	o.recorder = recorder
+	return o
+}
+
+// Execute выполняет сагу в рамках span
+func (o *TracingOrchestrator) Execute(ctx context.Context, instance saga.Saga) error {
+	start := time.Now()
+	ctx, span := o.tracer.Start(ctx, fmt.Sprintf("saga.execute %s", instance.Definition().Name()),
+		trace.WithAttributes(sagaAttributes(instance)...),
+	)
+	err := o.orchestrator.Execute(ctx, instance)
+	span.SetAttributes(attribute.String("saga.status", string(instance.Status())))
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, o.recorder, SagaMetricsPrefix, "execute", start, err, metrics.Labels{"saga": instance.Definition().Name()})
+	return err
+}
+
+// Compensate компенсирует сагу в рамках span
+func (o *TracingOrchestrator) Compensate(ctx context.Context, instance saga.Saga) error {
+	start := time.Now()
+	ctx, span := o.tracer.Start(ctx, fmt.Sprintf("saga.compensate %s", instance.Definition().Name()),
+		trace.WithAttributes(sagaAttributes(instance)...),
+	)
+	err := o.orchestrator.Compensate(ctx, instance)
+	endSpan(span, err)
+	metrics.RecordOperation(ctx, o.recorder, SagaMetricsPrefix, "compensate", start, err, metrics.Labels{"saga": instance.Definition().Name()})
+	return err
+}
+
+// Resume возобновляет сагу в рамках span
+func (o *TracingOrchestrator) Resume(ctx context.Context, sagaID string) error {
+	start := time.Now()
+	ctx, span := o.tracer.Start(ctx, "saga.resume",
+		trace.WithAttributes(attribute.String("saga.id", sagaID)),
+	)
+	err := o.orchestrator.Resume(ctx, sagaID)
+	endSpan(span, err)
+	// Определение саги при возобновлении неизвестно до загрузки состояния
+	metrics.RecordOperation(ctx, o.recorder, SagaMetricsPrefix, "resume", start, err, metrics.Labels{"saga": ""})
+	return err
+}
+
+// IsReadOnly сохраняет режим только чтения обернутого оркестратора
+func (o *TracingOrchestrator) IsReadOnly() bool {
+	if aware, ok := o.orchestrator.(eventsourcing.ReadOnlyAware); ok {
+		return aware.IsReadOnly()
+	}
+	return false
+}
+
+// GetStatus возвращает статус саги
+func (o *TracingOrchestrator) GetStatus(ctx context.Context, sagaID string) (saga.SagaStatus, error) {
+	return o.orchestrator.GetStatus(ctx, sagaID)
+}
+
+// Cancel отменяет сагу
+func (o *TracingOrchestrator) Cancel(ctx context.Context, sagaID string) error {
+	return o.orchestrator.Cancel(ctx, sagaID)
+}
+
+// sagaAttributes возвращает атрибуты span для саги
+func sagaAttributes(instance saga.Saga) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("saga.id", instance.ID()),
+		attribute.String("saga.definition", instance.Definition().Name()),
+		attribute.String("saga.correlation_id", instance.Context().CorrelationID()),
+	}
+}
+
+// InstrumentSagaDefinition возвращает копию определения саги, шаги которой создают
+// spans на выполнение и компенсацию. Настройки определения (стратегия компенсации, SLA,
+// настройки шагов по умолчанию, перехватчики) сохраняются. Возвращается *saga.BaseSagaDefinition,
+// поэтому определение можно регистрировать в DefaultOrchestrator как обычно.
+func InstrumentSagaDefinition(definition saga.SagaDefinition) *saga.BaseSagaDefinition {
+	return saga.WrapDefinitionSteps(definition, TraceSagaStep)
+}
+
+// TraceSagaStep оборачивает шаг саги в spans выполнения и компенсации.
+// Необязательные интерфейсы шага (ветвления, дедлайны, heartbeat, зависимости и т.д.)
+// доступны оркестратору через Unwrap.
+func TraceSagaStep(step saga.SagaStep) saga.SagaStep {
+	return &tracingSagaStep{
+		SagaStep: step,
+		tracer:   otel.Tracer(SagaTracerName),
+	}
+}
+
+// tracingSagaStep шаг саги со spans
+type tracingSagaStep struct {
+	saga.SagaStep
+	tracer trace.Tracer
+}
+
+// Unwrap возвращает обернутый шаг (см. saga.StepUnwrapper)
+func (s *tracingSagaStep) Unwrap() saga.SagaStep {
+	return s.SagaStep
+}
+
+func (s *tracingSagaStep) Execute(ctx context.Context, sagaCtx saga.SagaContext) error {
+	ctx, span := s.tracer.Start(ctx, fmt.Sprintf("saga.step %s", s.Name()),
+		trace.WithAttributes(
+			attribute.String("saga.step", s.Name()),
+			attribute.String("saga.correlation_id", sagaCtx.CorrelationID()),
+			attribute.String("saga.idempotency_key", sagaCtx.IdempotencyKey()),
+		),
+	)
+	err := s.SagaStep.Execute(ctx, sagaCtx)
+	endSpan(span, err)
+	return err
+}
+
+func (s *tracingSagaStep) Compensate(ctx context.Context, sagaCtx saga.SagaContext) error {
+	ctx, span := s.tracer.Start(ctx, fmt.Sprintf("saga.compensate_step %s", s.Name()),
+		trace.WithAttributes(
+			attribute.String("saga.step", s.Name()),
+			attribute.String("saga.correlation_id", sagaCtx.CorrelationID()),
+		),
+	)
+	err := s.SagaStep.Compensate(ctx, sagaCtx)
+	endSpan(span, err)
+	return err
+}
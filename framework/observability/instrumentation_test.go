@@ -0,0 +1,209 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/eventsourcing"
+	"github.com/akriventsev/potter/framework/saga"
+)
+
+// newOrderSagaDefinition создает сагу с ветвлением, дедлайном шага, стратегией
+// прямого восстановления, SLA, настройками по умолчанию и перехватчиком
+func newOrderSagaDefinition(executed *[]string, intercepted *int, shipFailures int) *saga.BaseSagaDefinition {
+	record := func(name string) func(ctx context.Context, sagaCtx saga.SagaContext) error {
+		return func(ctx context.Context, sagaCtx saga.SagaContext) error {
+			*executed = append(*executed, name)
+			return nil
+		}
+	}
+
+	check := saga.NewBaseStep("check")
+	check.WithExecute(func(ctx context.Context, sagaCtx saga.SagaContext) error {
+		*executed = append(*executed, "check")
+		return saga.SetStepResult(sagaCtx, "check", "auto")
+	}).NextStepOn("auto", "ship")
+	review := saga.NewBaseStep("review").WithExecute(record("review"))
+	vip := saga.NewBaseStep("vip").WithExecute(record("vip")).WithCondition(func(sagaCtx saga.SagaContext) bool {
+		return sagaCtx.GetBool("vip")
+	})
+	attempts := 0
+	ship := saga.NewBaseStep("ship").WithExecute(func(ctx context.Context, sagaCtx saga.SagaContext) error {
+		*executed = append(*executed, "ship")
+		attempts++
+		if attempts <= shipFailures {
+			return errors.New("carrier unavailable")
+		}
+		return nil
+	})
+	confirm := saga.NewBaseStep("confirm").
+		WithExecute(func(ctx context.Context, sagaCtx saga.SagaContext) error {
+			*executed = append(*executed, "confirm")
+			<-ctx.Done()
+			return ctx.Err()
+		}).
+		WithDeadline(20*time.Millisecond, saga.EscalateCompensate)
+
+	definition := saga.NewBaseSagaDefinition("order-saga").
+		WithVersion(3).
+		WithSLA(time.Minute).
+		WithDefaults(time.Second, saga.NoRetry()).
+		WithCompensationStrategy(saga.NewForwardRecovery(time.Millisecond, 2*time.Millisecond).WithMaxAttempts(5)).
+		WithInterceptors(countingInterceptor{count: intercepted})
+	definition.AddStep(check)
+	definition.AddStep(review)
+	definition.AddStep(vip)
+	definition.AddStep(ship)
+	definition.AddStep(confirm)
+	return definition
+}
+
+type countingInterceptor struct {
+	saga.BaseStepInterceptor
+	count *int
+}
+
+func (i countingInterceptor) Before(ctx context.Context, invocation saga.StepInvocation) error {
+	*i.count++
+	return nil
+}
+
+func TestInstrumentSagaDefinition_PreservesBehaviour(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	type outcome struct {
+		status      saga.SagaStatus
+		executed    string
+		intercepted int
+		deadlineErr bool
+	}
+	run := func(instrument bool) outcome {
+		var executed []string
+		intercepted := 0
+		var definition saga.SagaDefinition = newOrderSagaDefinition(&executed, &intercepted, 2)
+		if instrument {
+			definition = InstrumentSagaDefinition(definition)
+		}
+		instance, err := saga.NewBaseSaga("order-1", definition, saga.NewSagaContext(), nil)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		err = instance.Execute(context.Background())
+		return outcome{
+			status:      instance.Status(),
+			executed:    strings.Join(executed, ","),
+			intercepted: intercepted,
+			deadlineErr: errors.Is(err, saga.ErrStepDeadlineExceeded),
+		}
+	}
+
+	expected := run(false)
+	if expected.executed != "check,ship,ship,ship,confirm" || !expected.deadlineErr {
+		t.Fatalf("Unexpected baseline outcome: %+v", expected)
+	}
+
+	if got := run(true); got != expected {
+		t.Errorf("Instrumented saga behaves differently: got %+v, want %+v", got, expected)
+	}
+
+	var spans []string
+	for _, span := range recorder.Ended() {
+		spans = append(spans, span.Name())
+	}
+	if joined := strings.Join(spans, ","); !strings.Contains(joined, "saga.step check") || !strings.Contains(joined, "saga.step confirm") {
+		t.Errorf("Expected step spans, got %v", spans)
+	}
+}
+
+func TestInstrumentSagaDefinition_CopiesSettings(t *testing.T) {
+	var executed []string
+	intercepted := 0
+	definition := newOrderSagaDefinition(&executed, &intercepted, 0).
+		WithStepOverride(func(stepName string, settings saga.StepSettings) saga.StepSettings {
+			if stepName == "ship" {
+				settings.Timeout = 5 * time.Second
+			}
+			return settings
+		})
+
+	instrumented := InstrumentSagaDefinition(definition)
+	if instrumented == definition {
+		t.Fatal("Expected a copy of the definition")
+	}
+	if instrumented.Version() != 3 || instrumented.SLA() != time.Minute {
+		t.Errorf("Expected version and SLA to be copied, got %d, %s", instrumented.Version(), instrumented.SLA())
+	}
+	if _, ok := instrumented.CompensationStrategy().(*saga.ForwardRecovery); !ok {
+		t.Errorf("Expected ForwardRecovery strategy, got %T", instrumented.CompensationStrategy())
+	}
+	if len(instrumented.StepInterceptors()) != 1 {
+		t.Errorf("Expected interceptors to be copied, got %d", len(instrumented.StepInterceptors()))
+	}
+
+	steps := instrumented.Steps()
+	if len(steps) != 5 || steps[0] == definition.Steps()[0] {
+		t.Fatalf("Expected instrumented steps, got %v", steps)
+	}
+	if settings := instrumented.StepSettings(steps[3]); settings.Timeout != 5*time.Second || settings.RetryPolicy == nil {
+		t.Errorf("Expected defaults and overrides to apply, got %+v", settings)
+	}
+	if settings := instrumented.StepSettings(steps[4]); settings.Deadline != 20*time.Millisecond || settings.Escalation != saga.EscalateCompensate {
+		t.Errorf("Expected deadline of wrapped step, got %+v", settings)
+	}
+	if saga.UnwrapStep(steps[4]) != definition.Steps()[4] {
+		t.Error("Expected UnwrapStep to return the original step")
+	}
+}
+
+func TestTracingEventStore_StreamsPages(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	ctx := context.Background()
+	store := NewTracingEventStore(eventsourcing.NewInMemoryEventStore(eventsourcing.DefaultInMemoryEventStoreConfig()))
+	evts := make([]events.Event, 5)
+	for i := range evts {
+		evts[i] = events.NewBaseEvent("OrderUpdated", "order-1")
+	}
+	if err := store.AppendEvents(ctx, "order-1", 0, evts); err != nil {
+		t.Fatalf("Failed to append events: %v", err)
+	}
+
+	// Восстановление агрегата через TracingEventStore читает поток пачками
+	stream := eventsourcing.StreamEvents(ctx, store, "order-1", 0, 2)
+	count := 0
+	for stream.Next() {
+		count++
+	}
+	if stream.Err() != nil || count != 5 {
+		t.Fatalf("Expected 5 events, got %d (%v)", count, stream.Err())
+	}
+
+	pages := 0
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "eventstore.load_page":
+			pages++
+		case "eventstore.load":
+			t.Error("Expected stream not to be loaded at once")
+		}
+	}
+	if pages != 4 {
+		t.Errorf("Expected 4 page spans, got %d", pages)
+	}
+}
@@ -0,0 +1,527 @@
+// Copyright 2024 Potter Framework Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akriventsev/potter/framework/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Экспортеры сигналов в Config
+const (
+	ExporterNone       = "none"
+	ExporterOTLP       = "otlp"
+	ExporterPrometheus = "prometheus"
+	ExporterStdout     = "stdout"
+)
+
+// Сэмплеры трейсов (значения OTEL_TRACES_SAMPLER)
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// Config единая конфигурация observability сервиса: ресурс, сэмплирование и экспорт
+// трейсов, метрик и логов для всей инструментации Potter (TracingCommandBus,
+// TracingEventStore, TracingOrchestrator, Recorder'ы компонентов)
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string // "development", "staging", "production"
+	// ResourceAttributes дополнительные атрибуты ресурса (k8s.namespace.name, team и т.д.).
+	// Атрибуты из OTEL_RESOURCE_ATTRIBUTES добавляются автоматически, явные имеют приоритет.
+	ResourceAttributes map[string]string
+
+	Traces  TracesConfig
+	Metrics MetricsConfig
+	Logs    LogsConfig
+}
+
+// TracesConfig экспорт и сэмплирование трейсов
+type TracesConfig struct {
+	// Exporter "otlp" (по умолчанию), "jaeger", "zipkin", "stdout" или "none"
+	Exporter string
+	// Endpoint адрес exporter'а. Для OTLP допускается host:port или URL;
+	// пустой адрес - OTEL_EXPORTER_OTLP_ENDPOINT или localhost:4318.
+	Endpoint string
+	// Insecure отключает TLS для OTLP
+	Insecure bool
+	// Sampler один из Sampler* (по умолчанию parentbased_traceidratio)
+	Sampler string
+	// SamplingRatio доля сэмплируемых трейсов для *traceidratio (0.0 - 1.0).
+	// 0 (не задано) - сэмплируются все трейсы; отключить сэмплирование можно сэмплером always_off.
+	SamplingRatio float64
+}
+
+// MetricsConfig экспорт метрик
+type MetricsConfig struct {
+	// Exporter "prometheus" (по умолчанию), "otlp" или "none"
+	Exporter string
+	// PrometheusAddr адрес HTTP сервера со scrape endpoint /metrics; пустой - сервер не запускается,
+	// handler доступен через Telemetry.MetricsHandler
+	PrometheusAddr string
+	// RuntimeMetrics добавляет метрики Go runtime и процесса (go_*, process_*)
+	RuntimeMetrics bool
+	// OTLPExporter exporter для "otlp" (otlpmetrichttp.New или otlpmetricgrpc.New).
+	// Создается приложением, поэтому фреймворк не зависит от протокола OTLP.
+	OTLPExporter sdkmetric.Exporter
+	// ExportInterval период отправки OTLP метрик (по умолчанию 60s)
+	ExportInterval time.Duration
+}
+
+// LogsConfig структурированные логи
+type LogsConfig struct {
+	// Level "debug", "info" (по умолчанию), "warn" или "error"
+	Level string
+	// Format "json" (по умолчанию) или "text"
+	Format string
+	// Output куда пишутся логи (по умолчанию os.Stdout)
+	Output io.Writer
+	// KeepDefault не заменяет slog.Default настроенным логгером
+	KeepDefault bool
+}
+
+// Telemetry настроенные провайдеры observability сервиса
+type Telemetry struct {
+	resource       *resource.Resource
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	prometheus     *metrics.PrometheusExporter
+	recorder       metrics.Recorder
+	logger         *slog.Logger
+	metricsServer  *http.Server
+}
+
+// ConfigFromEnv собирает конфигурацию из стандартных переменных OpenTelemetry:
+// OTEL_SERVICE_NAME, OTEL_SERVICE_VERSION, OTEL_DEPLOYMENT_ENVIRONMENT, OTEL_TRACES_EXPORTER,
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (OTEL_EXPORTER_OTLP_ENDPOINT), OTEL_EXPORTER_OTLP_INSECURE,
+// OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG, OTEL_METRICS_EXPORTER, а также LOG_LEVEL и LOG_FORMAT.
+// serviceName используется, если OTEL_SERVICE_NAME не задан.
+func ConfigFromEnv(serviceName string) Config {
+	config := Config{
+		ServiceName:    envOr("OTEL_SERVICE_NAME", serviceName),
+		ServiceVersion: os.Getenv("OTEL_SERVICE_VERSION"),
+		Environment:    os.Getenv("OTEL_DEPLOYMENT_ENVIRONMENT"),
+		Traces: TracesConfig{
+			Exporter: os.Getenv("OTEL_TRACES_EXPORTER"),
+			Endpoint: envOr("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")),
+			Sampler:  os.Getenv("OTEL_TRACES_SAMPLER"),
+		},
+		Metrics: MetricsConfig{
+			Exporter: os.Getenv("OTEL_METRICS_EXPORTER"),
+		},
+		Logs: LogsConfig{
+			Level:  os.Getenv("LOG_LEVEL"),
+			Format: os.Getenv("LOG_FORMAT"),
+		},
+	}
+	config.Traces.Insecure, _ = strconv.ParseBool(os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"))
+	config.Traces.SamplingRatio = 1.0
+	if arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); arg != "" {
+		if ratio, err := strconv.ParseFloat(arg, 64); err == nil {
+			config.Traces.SamplingRatio = ratio
+		}
+	}
+	// OTEL_TRACES_SAMPLER_ARG=0 отключает сэмплирование, а нулевой SamplingRatio в Config
+	// означает значение по умолчанию, поэтому нулевая доля заменяется сэмплером always_off
+	if config.Traces.SamplingRatio == 0 {
+		switch config.Traces.Sampler {
+		case SamplerTraceIDRatio:
+			config.Traces.Sampler = SamplerAlwaysOff
+		case "", SamplerParentBasedTraceIDRatio:
+			config.Traces.Sampler = SamplerParentBasedAlwaysOff
+		}
+	}
+	return config
+}
+
+// Setup настраивает ресурс, трейсы, метрики и логи сервиса и регистрирует глобальные
+// TracerProvider, MeterProvider и propagator (W3C TraceContext + Baggage), через которые
+// пишет вся инструментация Potter. При ошибке уже созданные провайдеры останавливаются.
+func Setup(config Config) (*Telemetry, error) {
+	if config.ServiceName == "" {
+		return nil, fmt.Errorf("service name is required")
+	}
+
+	res, err := newResource(config)
+	if err != nil {
+		return nil, err
+	}
+	telemetry := &Telemetry{resource: res}
+
+	if err := telemetry.setupTraces(config.Traces); err != nil {
+		_ = telemetry.Shutdown(context.Background())
+		return nil, err
+	}
+	if err := telemetry.setupMetrics(config.Metrics); err != nil {
+		_ = telemetry.Shutdown(context.Background())
+		return nil, err
+	}
+	if err := telemetry.setupLogs(config); err != nil {
+		_ = telemetry.Shutdown(context.Background())
+		return nil, err
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return telemetry, nil
+}
+
+// newResource создает ресурс сервиса: атрибуты SDK, хоста, OTEL_RESOURCE_ATTRIBUTES и явные атрибуты
+func newResource(config Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(config.ServiceName),
+	}
+	if config.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(config.ServiceVersion))
+	}
+	if config.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentKey.String(config.Environment))
+	}
+	keys := make([]string, 0, len(config.ResourceAttributes))
+	for key := range config.ResourceAttributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attrs = append(attrs, attribute.String(key, config.ResourceAttributes[key]))
+	}
+
+	res, err := resource.New(context.Background(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithFromEnv(),
+		resource.WithAttributes(attrs...),
+	)
+	if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	return res, nil
+}
+
+// setupTraces создает TracerProvider с exporter'ом и сэмплером
+func (t *Telemetry) setupTraces(config TracesConfig) error {
+	if config.Exporter == ExporterNone {
+		return nil
+	}
+
+	sampler, err := newSampler(config.Sampler, config.SamplingRatio)
+	if err != nil {
+		return err
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch config.Exporter {
+	case "", ExporterOTLP:
+		exporter, err = newOTLPTraceExporter(config.Endpoint, config.Insecure)
+	case "jaeger", "zipkin", ExporterStdout:
+		exporter, err = createExporter(TracingConfig{Exporter: config.Exporter, ExporterEndpoint: config.Endpoint})
+	default:
+		return fmt.Errorf("unknown traces exporter: %s", config.Exporter)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create traces exporter: %w", err)
+	}
+
+	t.tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(t.resource),
+		sdktrace.WithSampler(sampler),
+	)
+	otel.SetTracerProvider(t.tracerProvider)
+	return nil
+}
+
+// newOTLPTraceExporter создает OTLP/HTTP exporter; пустой endpoint - настройки из окружения
+func newOTLPTraceExporter(endpoint string, insecure bool) (sdktrace.SpanExporter, error) {
+	var opts []otlptracehttp.Option
+	switch {
+	case strings.Contains(endpoint, "://"):
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	case endpoint != "":
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptrace.New(context.Background(), otlptracehttp.NewClient(opts...))
+}
+
+// newSampler создает сэмплер по имени из OTEL_TRACES_SAMPLER; нулевая доля - 1.0
+func newSampler(name string, ratio float64) (sdktrace.Sampler, error) {
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("traces sampling ratio must be between 0 and 1, got %v", ratio)
+	}
+	if ratio == 0 {
+		ratio = 1.0
+	}
+
+	switch name {
+	case SamplerAlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case SamplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case SamplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case SamplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "", SamplerParentBasedTraceIDRatio:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("unknown traces sampler: %s", name)
+	}
+}
+
+// setupMetrics создает MeterProvider и Recorder для инструментации компонентов
+func (t *Telemetry) setupMetrics(config MetricsConfig) error {
+	switch config.Exporter {
+	case ExporterNone:
+		t.recorder = metrics.NopRecorder{}
+		return nil
+	case "", ExporterPrometheus:
+		// Recorder компонентов и глобальный MeterProvider пишут в один Registry,
+		// поэтому все метрики сервиса отдаются одним scrape endpoint
+		t.prometheus = metrics.NewPrometheusExporter(nil)
+		if config.RuntimeMetrics {
+			t.prometheus.WithRuntimeMetrics()
+		}
+		reader, err := otelprometheus.New(otelprometheus.WithRegisterer(t.prometheus.Registry()))
+		if err != nil {
+			return fmt.Errorf("failed to create prometheus exporter: %w", err)
+		}
+		t.meterProvider = sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(reader),
+			sdkmetric.WithResource(t.resource),
+		)
+		t.recorder = t.prometheus.Recorder()
+		if config.PrometheusAddr != "" {
+			if err := t.serveMetrics(config.PrometheusAddr); err != nil {
+				return err
+			}
+		}
+	case ExporterOTLP:
+		if config.OTLPExporter == nil {
+			return fmt.Errorf("otlp metrics exporter is not configured")
+		}
+		var opts []sdkmetric.PeriodicReaderOption
+		if config.ExportInterval > 0 {
+			opts = append(opts, sdkmetric.WithInterval(config.ExportInterval))
+		}
+		t.meterProvider = sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(config.OTLPExporter, opts...)),
+			sdkmetric.WithResource(t.resource),
+		)
+		t.recorder = metrics.NewOTelRecorder(t.meterProvider.Meter("potter"))
+	default:
+		return fmt.Errorf("unknown metrics exporter: %s", config.Exporter)
+	}
+
+	otel.SetMeterProvider(t.meterProvider)
+	return nil
+}
+
+// serveMetrics запускает HTTP сервер со scrape endpoint /metrics
+func (t *Telemetry) serveMetrics(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen metrics address %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", t.prometheus.Handler())
+	t.metricsServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := t.metricsServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// setupLogs создает slog.Logger с атрибутами ресурса и корреляцией с трейсами
+func (t *Telemetry) setupLogs(config Config) error {
+	var level slog.Level
+	switch strings.ToLower(config.Logs.Level) {
+	case "debug":
+		level = slog.LevelDebug
+	case "", "info":
+		level = slog.LevelInfo
+	case "warn", "warning":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		return fmt.Errorf("unknown log level: %s", config.Logs.Level)
+	}
+
+	output := config.Logs.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch config.Logs.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(output, options)
+	case "text":
+		handler = slog.NewTextHandler(output, options)
+	default:
+		return fmt.Errorf("unknown log format: %s", config.Logs.Format)
+	}
+
+	attrs := []slog.Attr{slog.String(string(semconv.ServiceNameKey), config.ServiceName)}
+	if config.ServiceVersion != "" {
+		attrs = append(attrs, slog.String(string(semconv.ServiceVersionKey), config.ServiceVersion))
+	}
+	if config.Environment != "" {
+		attrs = append(attrs, slog.String(string(semconv.DeploymentEnvironmentKey), config.Environment))
+	}
+	t.logger = slog.New(&traceLogHandler{Handler: handler.WithAttrs(attrs)})
+	if !config.Logs.KeepDefault {
+		slog.SetDefault(t.logger)
+	}
+	return nil
+}
+
+// traceLogHandler добавляет в записи trace_id, span_id и correlation_id из контекста
+type traceLogHandler struct {
+	slog.Handler
+}
+
+// Handle дополняет запись идентификаторами трейса
+func (h *traceLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	if correlationID := ExtractCorrelationID(ctx); correlationID != "" {
+		record.AddAttrs(slog.String("correlation_id", correlationID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs возвращает handler с дополнительными атрибутами
+func (h *traceLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceLogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup возвращает handler с группой атрибутов
+func (h *traceLogHandler) WithGroup(name string) slog.Handler {
+	return &traceLogHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Resource возвращает ресурс сервиса
+func (t *Telemetry) Resource() *resource.Resource {
+	return t.resource
+}
+
+// TracerProvider возвращает TracerProvider; nil, если трейсы отключены
+func (t *Telemetry) TracerProvider() *sdktrace.TracerProvider {
+	return t.tracerProvider
+}
+
+// MeterProvider возвращает MeterProvider; nil, если метрики отключены
+func (t *Telemetry) MeterProvider() *sdkmetric.MeterProvider {
+	return t.meterProvider
+}
+
+// Recorder возвращает Recorder для инструментации компонентов (WithMetrics)
+func (t *Telemetry) Recorder() metrics.Recorder {
+	return t.recorder
+}
+
+// PrometheusExporter возвращает Prometheus exporter (RegisterSagaCounts, RegisterProjectionLag);
+// nil, если метрики экспортируются не в Prometheus
+func (t *Telemetry) PrometheusExporter() *metrics.PrometheusExporter {
+	return t.prometheus
+}
+
+// MetricsHandler возвращает HTTP handler для scrape; nil, если метрики экспортируются не в Prometheus
+func (t *Telemetry) MetricsHandler() http.Handler {
+	if t.prometheus == nil {
+		return nil
+	}
+	return t.prometheus.Handler()
+}
+
+// Logger возвращает настроенный логгер
+func (t *Telemetry) Logger() *slog.Logger {
+	return t.logger
+}
+
+// Shutdown останавливает scrape сервер и отправляет накопленные трейсы и метрики
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+	if t.metricsServer != nil {
+		if err := t.metricsServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("metrics server: %w", err))
+		}
+	}
+	if t.tracerProvider != nil {
+		if err := t.tracerProvider.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("tracer provider: %w", err))
+		}
+	}
+	if t.meterProvider != nil {
+		if err := t.meterProvider.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("meter provider: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// envOr возвращает значение переменной окружения или defaultValue
+func envOr(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,295 @@
+package observability
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/akriventsev/potter/framework/metrics"
+)
+
+// setupForTest вызывает Setup и восстанавливает глобальные провайдеры после теста
+func setupForTest(t *testing.T, config Config) *Telemetry {
+	t.Helper()
+	tracerProvider := otel.GetTracerProvider()
+	meterProvider := otel.GetMeterProvider()
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetMeterProvider(meterProvider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	if config.ServiceName == "" {
+		config.ServiceName = "orders"
+	}
+	config.Logs = LogsConfig{Output: io.Discard, KeepDefault: true}
+	telemetry, err := Setup(config)
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	return telemetry
+}
+
+// collectorServer HTTP сервер, запоминающий пути запросов exporter'ов
+type collectorServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	paths []string
+}
+
+func newCollectorServer(t *testing.T) *collectorServer {
+	t.Helper()
+	collector := &collectorServer{}
+	collector.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		collector.mu.Lock()
+		collector.paths = append(collector.paths, r.Method+" "+r.URL.Path)
+		collector.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(collector.Close)
+	return collector
+}
+
+func (c *collectorServer) requests() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.paths...)
+}
+
+func TestNewSampler(t *testing.T) {
+	low := trace.TraceID{}
+	high := trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	cases := []struct {
+		name    string
+		sampler string
+		ratio   float64
+		traceID trace.TraceID
+		sampled bool
+	}{
+		{"default with unset ratio", "", 0, high, true},
+		{"parentbased ratio with unset ratio", SamplerParentBasedTraceIDRatio, 0, high, true},
+		{"traceidratio with unset ratio", SamplerTraceIDRatio, 0, high, true},
+		{"ratio samples low trace id", SamplerParentBasedTraceIDRatio, 0.5, low, true},
+		{"ratio drops high trace id", SamplerParentBasedTraceIDRatio, 0.5, high, false},
+		{"always on", SamplerAlwaysOn, 0, high, true},
+		{"always off", SamplerAlwaysOff, 1, low, false},
+		{"parentbased always off", SamplerParentBasedAlwaysOff, 0, low, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sampler, err := newSampler(tc.sampler, tc.ratio)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       tc.traceID,
+				Name:          "operation",
+			})
+			if sampled := result.Decision == sdktrace.RecordAndSample; sampled != tc.sampled {
+				t.Errorf("Expected sampled = %v, got %v", tc.sampled, sampled)
+			}
+		})
+	}
+
+	if _, err := newSampler("probabilistic", 1); err == nil {
+		t.Error("Expected error for unknown sampler")
+	}
+	if _, err := newSampler(SamplerTraceIDRatio, 1.5); err == nil {
+		t.Error("Expected error for ratio above 1")
+	}
+}
+
+func TestConfigFromEnv_ZeroSamplerArg(t *testing.T) {
+	t.Setenv("OTEL_TRACES_SAMPLER", "")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0")
+	if config := ConfigFromEnv("orders"); config.Traces.Sampler != SamplerParentBasedAlwaysOff {
+		t.Errorf("Expected zero ratio to disable sampling, got %q", config.Traces.Sampler)
+	}
+
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "")
+	if config := ConfigFromEnv("orders"); config.Traces.Sampler != "" || config.Traces.SamplingRatio != 1.0 {
+		t.Errorf("Expected default sampler with ratio 1.0, got %q %v", config.Traces.Sampler, config.Traces.SamplingRatio)
+	}
+}
+
+func TestSetup_DefaultTracesSampledAndExported(t *testing.T) {
+	collector := newCollectorServer(t)
+	telemetry := setupForTest(t, Config{
+		Traces:  TracesConfig{Endpoint: collector.URL},
+		Metrics: MetricsConfig{Exporter: ExporterNone},
+	})
+
+	_, span := otel.Tracer("test").Start(context.Background(), "create_order")
+	if !span.SpanContext().IsSampled() {
+		t.Error("Expected span to be sampled with default config")
+	}
+	span.End()
+
+	if err := telemetry.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if requests := collector.requests(); len(requests) != 1 || requests[0] != "POST /v1/traces" {
+		t.Errorf("Expected span to be exported over OTLP on shutdown, got %v", requests)
+	}
+}
+
+func TestSetup_TracesExporters(t *testing.T) {
+	collector := newCollectorServer(t)
+	telemetry := setupForTest(t, Config{
+		Traces: TracesConfig{
+			Exporter: "zipkin",
+			Endpoint: collector.URL + "/api/v2/spans",
+			Sampler:  SamplerAlwaysOn,
+		},
+		Metrics: MetricsConfig{Exporter: ExporterNone},
+	})
+	_, span := telemetry.TracerProvider().Tracer("test").Start(context.Background(), "create_order")
+	span.End()
+	if err := telemetry.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if requests := collector.requests(); len(requests) != 1 || requests[0] != "POST /api/v2/spans" {
+		t.Errorf("Expected span to be exported to zipkin, got %v", requests)
+	}
+
+	disabled := setupForTest(t, Config{
+		Traces:  TracesConfig{Exporter: ExporterNone},
+		Metrics: MetricsConfig{Exporter: ExporterNone},
+	})
+	if disabled.TracerProvider() != nil {
+		t.Error("Expected no tracer provider for exporter none")
+	}
+
+	for _, traces := range []TracesConfig{{Exporter: "datadog"}, {Sampler: "probabilistic"}} {
+		if _, err := Setup(Config{ServiceName: "orders", Traces: traces}); err == nil {
+			t.Errorf("Expected error for %+v", traces)
+		}
+	}
+}
+
+// fakeMetricExporter запоминает экспортированные метрики
+type fakeMetricExporter struct {
+	mu       sync.Mutex
+	names    []string
+	shutdown bool
+}
+
+func (e *fakeMetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (e *fakeMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *fakeMetricExporter) Export(ctx context.Context, data *metricdata.ResourceMetrics) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, scope := range data.ScopeMetrics {
+		for _, metric := range scope.Metrics {
+			e.names = append(e.names, metric.Name)
+		}
+	}
+	return nil
+}
+
+func (e *fakeMetricExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func (e *fakeMetricExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdown = true
+	return nil
+}
+
+func TestSetup_MetricsExporters(t *testing.T) {
+	ctx := context.Background()
+
+	prometheus := setupForTest(t, Config{Traces: TracesConfig{Exporter: ExporterNone}})
+	if prometheus.PrometheusExporter() == nil || prometheus.MeterProvider() == nil {
+		t.Fatal("Expected prometheus exporter by default")
+	}
+	prometheus.Recorder().Counter(ctx, "orders_total", 1, nil)
+	response := httptest.NewRecorder()
+	prometheus.MetricsHandler().ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(response.Body.String(), "orders_total") {
+		t.Errorf("Expected recorder metric in scrape output, got %s", response.Body.String())
+	}
+
+	exporter := &fakeMetricExporter{}
+	otlp := setupForTest(t, Config{
+		Traces:  TracesConfig{Exporter: ExporterNone},
+		Metrics: MetricsConfig{Exporter: ExporterOTLP, OTLPExporter: exporter},
+	})
+	if otlp.PrometheusExporter() != nil || otlp.MetricsHandler() != nil {
+		t.Error("Expected no prometheus exporter for otlp")
+	}
+	otlp.Recorder().Counter(ctx, "orders_total", 1, nil)
+	if err := otlp.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	exporter.mu.Lock()
+	if !exporter.shutdown || len(exporter.names) != 1 || exporter.names[0] != "orders_total" {
+		t.Errorf("Expected metrics to be exported on shutdown, got %v (shutdown %v)", exporter.names, exporter.shutdown)
+	}
+	exporter.mu.Unlock()
+
+	disabled := setupForTest(t, Config{Traces: TracesConfig{Exporter: ExporterNone}, Metrics: MetricsConfig{Exporter: ExporterNone}})
+	if _, ok := disabled.Recorder().(metrics.NopRecorder); !ok || disabled.MeterProvider() != nil {
+		t.Errorf("Expected NopRecorder without meter provider, got %T", disabled.Recorder())
+	}
+
+	for _, config := range []MetricsConfig{{Exporter: ExporterOTLP}, {Exporter: "statsd"}} {
+		if _, err := Setup(Config{ServiceName: "orders", Traces: TracesConfig{Exporter: ExporterNone}, Metrics: config}); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
+
+func TestTelemetry_ShutdownStopsMetricsServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve port: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	telemetry := setupForTest(t, Config{
+		Traces:  TracesConfig{Exporter: ExporterNone},
+		Metrics: MetricsConfig{PrometheusAddr: addr},
+	})
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatalf("Expected scrape endpoint to serve, got %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	if err := telemetry.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if resp, err := http.Get("http://" + addr + "/metrics"); err == nil {
+		_ = resp.Body.Close()
+		t.Error("Expected scrape endpoint to be stopped after shutdown")
+	}
+}
@@ -0,0 +1,242 @@
+// Package saga предоставляет административный доступ к сохраненным сагам без загрузки определений.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FailureCodeForceFailed код ошибки саги, переведенной в failed администратором
+const FailureCodeForceFailed = "FORCE_FAILED"
+
+// SagaRecord сохраненное состояние саги без восстановления определения.
+// Позволяет администрировать саги вне сервиса, где зарегистрированы определения.
+type SagaRecord struct {
+	SagaID            string
+	DefinitionName    string
+	DefinitionVersion int
+	Status            SagaStatus
+	CurrentStep       string
+	CorrelationID     string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// SagaRecordFilter фильтр перечисления сохраненных саг
+type SagaRecordFilter struct {
+	// Statuses статусы саг (пустой - любые)
+	Statuses []SagaStatus
+	// DefinitionName имя определения ("" - любое)
+	DefinitionName string
+	// UpdatedBefore саги, не обновлявшиеся с указанного момента (zero - без ограничения);
+	// используется для поиска зависших саг
+	UpdatedBefore time.Time
+	// Limit максимальное количество саг (0 - без ограничения)
+	Limit int
+}
+
+// matches проверяет, что сага соответствует фильтру
+func (f SagaRecordFilter) matches(record SagaRecord) bool {
+	if !sagaStatusIn(record.Status, f.Statuses) {
+		return false
+	}
+	if f.DefinitionName != "" && record.DefinitionName != f.DefinitionName {
+		return false
+	}
+	if !f.UpdatedBefore.IsZero() && !record.UpdatedAt.Before(f.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+// SagaAdminStore persistence, поддерживающая административные операции над сохраненными
+// сагами без определений. Реализуется InMemoryPersistence и PostgresPersistence.
+type SagaAdminStore interface {
+	// ListSagaRecords возвращает саги, соответствующие фильтру, от давно обновленных к недавним
+	ListSagaRecords(ctx context.Context, filter SagaRecordFilter) ([]SagaRecord, error)
+	// ForceFail переводит незавершенную сагу в статус failed без компенсации,
+	// записывая причину в историю текущего шага
+	ForceFail(ctx context.Context, sagaID, reason string) error
+}
+
+// forceFailFailure возвращает структурированную ошибку принудительного завершения
+func forceFailFailure(reason string) *SagaFailure {
+	return &SagaFailure{
+		Category:  FailureCategoryTechnical,
+		Code:      FailureCodeForceFailed,
+		Message:   "force-failed: " + reason,
+		Retryable: false,
+		Details:   map[string]interface{}{"reason": reason},
+	}
+}
+
+// checkForceFailable проверяет, что сагу в статусе status можно перевести в failed
+func checkForceFailable(sagaID string, status SagaStatus) error {
+	switch status {
+	case SagaStatusCompleted, SagaStatusCompensated, SagaStatusFailed:
+		return fmt.Errorf("saga %s is already finished, current status: %s", sagaID, status)
+	}
+	return nil
+}
+
+// ListSagaRecords возвращает саги, соответствующие фильтру (реализация SagaAdminStore)
+func (p *InMemoryPersistence) ListSagaRecords(ctx context.Context, filter SagaRecordFilter) ([]SagaRecord, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var records []SagaRecord
+	for _, saga := range p.sagas {
+		metadata := saga.Context().Metadata()
+		record := SagaRecord{
+			SagaID:            saga.ID(),
+			DefinitionName:    saga.Definition().Name(),
+			DefinitionVersion: SagaDefinitionVersion(saga.Definition()),
+			Status:            saga.Status(),
+			CurrentStep:       saga.CurrentStep(),
+			CorrelationID:     saga.Context().CorrelationID(),
+			CreatedAt:         metadata.CreatedAt,
+			UpdatedAt:         metadata.UpdatedAt,
+		}
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].UpdatedAt.Before(records[j].UpdatedAt)
+	})
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[:filter.Limit]
+	}
+	return records, nil
+}
+
+// ForceFail переводит сагу в статус failed (реализация SagaAdminStore)
+func (p *InMemoryPersistence) ForceFail(ctx context.Context, sagaID, reason string) error {
+	saga, err := p.Load(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+	if err := checkForceFailable(sagaID, saga.Status()); err != nil {
+		return err
+	}
+
+	baseSaga, ok := saga.(*BaseSaga)
+	if !ok {
+		return fmt.Errorf("saga %s does not support force fail", sagaID)
+	}
+	baseSaga.forceFail(forceFailFailure(reason), time.Now())
+	return nil
+}
+
+// forceFail переводит сагу в статус failed, добавляя в историю запись текущего шага с причиной
+func (s *BaseSaga) forceFail(failure *SagaFailure, now time.Time) {
+	s.mu.Lock()
+	s.status = SagaStatusFailed
+	s.completedAt = &now
+	s.history = append(s.history, SagaHistory{
+		StepName:    s.currentStep,
+		Status:      StepStatusFailed,
+		StartedAt:   now,
+		CompletedAt: &now,
+		Error:       fmt.Errorf("%s", failure.Message),
+		Failure:     failure,
+	})
+	s.mu.Unlock()
+
+	if ctxImpl, ok := s.context.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.UpdatedAt = now
+		ctxImpl.mu.Unlock()
+	}
+}
+
+// ListSagaRecords возвращает саги, соответствующие фильтру (реализация SagaAdminStore)
+func (p *PostgresPersistence) ListSagaRecords(ctx context.Context, filter SagaRecordFilter) ([]SagaRecord, error) {
+	query := `
+		SELECT id, definition_name, definition_version, status, COALESCE(current_step, ''), COALESCE(correlation_id, ''), created_at, updated_at
+		FROM saga_instances
+		WHERE ($1::text[] IS NULL OR status = ANY($1))
+			AND ($2 = '' OR definition_name = $2)
+			AND ($3::timestamp IS NULL OR updated_at < $3)
+		ORDER BY updated_at ASC
+	`
+	var statuses []string
+	for _, status := range filter.Statuses {
+		statuses = append(statuses, string(status))
+	}
+	var updatedBefore *time.Time
+	if !filter.UpdatedBefore.IsZero() {
+		updatedBefore = &filter.UpdatedBefore
+	}
+	args := []interface{}{statuses, filter.DefinitionName, updatedBefore}
+	if filter.Limit > 0 {
+		query += ` LIMIT $4`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := p.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var records []SagaRecord
+	for rows.Next() {
+		var record SagaRecord
+		var status string
+		if err := rows.Scan(&record.SagaID, &record.DefinitionName, &record.DefinitionVersion, &status,
+			&record.CurrentStep, &record.CorrelationID, &record.CreatedAt, &record.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		record.Status = SagaStatus(status)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// ForceFail переводит сагу в статус failed (реализация SagaAdminStore).
+// Статус и запись истории сохраняются в одной транзакции.
+func (p *PostgresPersistence) ForceFail(ctx context.Context, sagaID, reason string) error {
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status, currentStep string
+	err = tx.QueryRow(ctx,
+		`SELECT status, COALESCE(current_step, '') FROM saga_instances WHERE id = $1 FOR UPDATE`, sagaID).
+		Scan(&status, &currentStep)
+	if err != nil {
+		return fmt.Errorf("failed to load saga %s: %w", sagaID, err)
+	}
+	if err := checkForceFailable(sagaID, SagaStatus(status)); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(ctx,
+		`UPDATE saga_instances SET status = $2, updated_at = $3, completed_at = $3 WHERE id = $1`,
+		sagaID, string(SagaStatusFailed), now); err != nil {
+		return fmt.Errorf("failed to update saga %s: %w", sagaID, err)
+	}
+
+	failure := forceFailFailure(reason)
+	failureJSON, err := json.Marshal(failure)
+	if err != nil {
+		return fmt.Errorf("failed to marshal failure: %w", err)
+	}
+	histID := fmt.Sprintf("%s:%s:%d", sagaID, currentStep, now.UnixNano())
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO saga_history (id, saga_id, step_name, status, error, retry_attempt, started_at, completed_at, stack_trace, failure)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $6, '', $7)
+	`, histID, sagaID, currentStep, string(StepStatusFailed), failure.Message, now, failureJSON); err != nil {
+		return fmt.Errorf("failed to save saga %s history: %w", sagaID, err)
+	}
+
+	return tx.Commit(ctx)
+}
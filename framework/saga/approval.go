@@ -0,0 +1,185 @@
+// Package saga предоставляет шаг ручного подтверждения (human approval) для саг.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+var (
+	// ErrApprovalPending возвращается WaitForApprovalStep, пока решение по шагу не принято
+	ErrApprovalPending = errors.New("saga step is waiting for approval")
+	// ErrApprovalRejected возвращается WaitForApprovalStep при отклонении; запускает компенсацию саги
+	ErrApprovalRejected = errors.New("saga step approval rejected")
+	// ErrSagaAwaitingApproval возвращается BaseSaga.Execute, когда сага остановилась
+	// в статусе SagaStatusWaitingApproval до вызова DefaultOrchestrator.Approve
+	ErrSagaAwaitingApproval = errors.New("saga is waiting for approval")
+)
+
+// Ключи решения в пространстве имен шага подтверждения (сохраняются вместе с контекстом саги)
+const (
+	approvalDecidedKey  = "approval_decided"
+	approvalApprovedKey = "approval_approved"
+	approverKey         = "approval_approver"
+	approvalCommentKey  = "approval_comment"
+)
+
+// ApprovalDecision решение по шагу ручного подтверждения
+type ApprovalDecision struct {
+	// Approved true - продолжить выполнение саги, false - отклонить и компенсировать
+	Approved bool
+	// Approver идентификатор принявшего решение
+	Approver string
+	// Comment комментарий к решению
+	Comment string
+}
+
+// WaitForApprovalStep шаг, приостанавливающий сагу до ручного решения.
+//
+// Первое выполнение шага публикует ApprovalRequested и сохраняет сагу в статусе
+// SagaStatusWaitingApproval. Выполнение продолжается вызовом
+// DefaultOrchestrator.Approve: при одобрении сага переходит к следующему шагу,
+// при отклонении шаг завершается ошибкой ErrApprovalRejected и выполненные шаги компенсируются.
+type WaitForApprovalStep struct {
+	*BaseStep
+}
+
+// NewWaitForApprovalStep создает шаг ручного подтверждения
+func NewWaitForApprovalStep(name string) *WaitForApprovalStep {
+	step := &WaitForApprovalStep{BaseStep: NewBaseStep(name)}
+
+	step.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		decision, ok := ApprovalDecisionOf(sagaCtx, name)
+		if !ok {
+			return ErrApprovalPending
+		}
+		if !decision.Approved {
+			return fmt.Errorf("%w by %q: %s", ErrApprovalRejected, decision.Approver, decision.Comment)
+		}
+		return nil
+	})
+
+	// Подтверждение не меняет внешнего состояния - компенсировать нечего
+	step.WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+		return nil
+	})
+
+	return step
+}
+
+// ApprovalDecisionOf возвращает решение по шагу подтверждения, если оно принято
+func ApprovalDecisionOf(sagaCtx SagaContext, stepName string) (ApprovalDecision, bool) {
+	stepCtx := sagaCtx.ForStep(stepName)
+	if !stepCtx.GetBool(approvalDecidedKey) {
+		return ApprovalDecision{}, false
+	}
+	return ApprovalDecision{
+		Approved: stepCtx.GetBool(approvalApprovedKey),
+		Approver: stepCtx.GetString(approverKey),
+		Comment:  stepCtx.GetString(approvalCommentKey),
+	}, true
+}
+
+// setApprovalDecision сохраняет решение в пространстве имен шага
+func setApprovalDecision(sagaCtx SagaContext, stepName string, decision ApprovalDecision) error {
+	stepCtx := sagaCtx.ForStep(stepName)
+	values := []struct {
+		key   string
+		value interface{}
+	}{
+		{approvalApprovedKey, decision.Approved},
+		{approverKey, decision.Approver},
+		{approvalCommentKey, decision.Comment},
+		{approvalDecidedKey, true},
+	}
+	for _, v := range values {
+		if err := stepCtx.Set(v.key, v.value); err != nil {
+			return fmt.Errorf("failed to store approval decision for step %s: %w", stepName, err)
+		}
+	}
+	return nil
+}
+
+// Approve передает решение по шагу, на котором сага ожидает подтверждения, и продолжает выполнение
+// с этого шага. Возвращает ErrSagaAwaitingApproval, если сага остановилась на следующем шаге подтверждения.
+func (s *BaseSaga) Approve(ctx context.Context, stepName string, decision ApprovalDecision) error {
+	s.mu.Lock()
+	if s.status != SagaStatusWaitingApproval {
+		s.mu.Unlock()
+		return fmt.Errorf("saga %s is not waiting for approval, current status: %s", s.id, s.status)
+	}
+	if s.currentStep != stepName {
+		s.mu.Unlock()
+		return fmt.Errorf("saga %s is waiting for approval of step %s, not %s", s.id, s.currentStep, stepName)
+	}
+	s.mu.Unlock()
+
+	stepIndex := -1
+	for i, step := range s.definition.Steps() {
+		if step.Name() == stepName {
+			stepIndex = i
+			break
+		}
+	}
+	if stepIndex < 0 {
+		return fmt.Errorf("step %s not found in saga %s", stepName, s.id)
+	}
+
+	if err := setApprovalDecision(s.context, stepName, decision); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.status = SagaStatusRunning
+	s.mu.Unlock()
+
+	if ctxImpl, ok := s.context.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.UpdatedAt = now
+		ctxImpl.mu.Unlock()
+	}
+
+	return s.runSteps(ctx, stepIndex)
+}
+
+// awaitApproval останавливает сагу на шаге подтверждения: сохраняет статус
+// SagaStatusWaitingApproval и публикует ApprovalRequested
+func (s *BaseSaga) awaitApproval(ctx context.Context, step SagaStep, historyEntry SagaHistory) error {
+	requestedAt := time.Now()
+	historyEntry.Status = StepStatusWaitingApproval
+	s.updateHistory(historyEntry)
+
+	s.mu.Lock()
+	s.status = SagaStatusWaitingApproval
+	s.mu.Unlock()
+
+	if ctxImpl, ok := s.context.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.UpdatedAt = requestedAt
+		ctxImpl.mu.Unlock()
+	}
+
+	if s.persistence != nil {
+		if err := s.persistence.Save(ctx, s); err != nil {
+			return fmt.Errorf("failed to save saga state at approval step %s: %w", step.Name(), err)
+		}
+	}
+
+	if s.eventBus != nil {
+		requestedEvent := &ApprovalRequestedEvent{
+			BaseEvent: events.NewBaseEvent("ApprovalRequested", s.id),
+			SagaID:    s.id,
+			StepName:  step.Name(),
+			Timestamp: requestedAt,
+		}
+		requestedEvent.WithCorrelationID(s.context.CorrelationID())
+		_ = s.eventBus.Publish(ctx, requestedEvent)
+	}
+
+	return ErrSagaAwaitingApproval
+}
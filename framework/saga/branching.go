@@ -0,0 +1,208 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/fsm"
+)
+
+// stepResultKey ключ результата шага в его пространстве имен (см. SetStepResult)
+const stepResultKey = "branch_result"
+
+// BranchingStep реализуется шагами с условием выполнения и переходами по результату (BaseStep).
+//
+// Условие проверяется перед шагом: если оно не выполнено, шаг пропускается (StepStatusSkipped)
+// и сага переходит к следующему шагу. После успешного шага его результат (SetStepResult)
+// выбирает следующий шаг по NextStepOn; шаги между ними пропускаются. Результат без перехода
+// продолжает сагу следующим по порядку шагом. Переходы возможны только вперед.
+type BranchingStep interface {
+	// Condition возвращает условие выполнения шага (nil - шаг выполняется всегда)
+	Condition() func(sagaCtx SagaContext) bool
+	// NextStepMappings возвращает следующий шаг по результату шага
+	NextStepMappings() map[string]string
+}
+
+// WithCondition устанавливает условие выполнения шага. В отличие от WithGuard
+// невыполненное условие не завершает сагу ошибкой, а пропускает шаг.
+func (s *BaseStep) WithCondition(condition func(sagaCtx SagaContext) bool) *BaseStep {
+	s.condition = condition
+	return s
+}
+
+// Condition возвращает условие выполнения шага
+func (s *BaseStep) Condition() func(sagaCtx SagaContext) bool {
+	return s.condition
+}
+
+// NextStepOn задает шаг, к которому переходит сага, если шаг завершился с результатом result
+func (s *BaseStep) NextStepOn(result, stepName string) *BaseStep {
+	if s.nextSteps == nil {
+		s.nextSteps = make(map[string]string)
+	}
+	s.nextSteps[result] = stepName
+	return s
+}
+
+// NextStepMappings возвращает переходы шага по результату
+func (s *BaseStep) NextStepMappings() map[string]string {
+	result := make(map[string]string, len(s.nextSteps))
+	for r, stepName := range s.nextSteps {
+		result[r] = stepName
+	}
+	return result
+}
+
+// SetStepResult сохраняет результат шага, по которому выбирается следующий шаг (NextStepOn)
+func SetStepResult(sagaCtx SagaContext, stepName, result string) error {
+	if err := sagaCtx.ForStep(stepName).Set(stepResultKey, result); err != nil {
+		return fmt.Errorf("failed to store result of step %s: %w", stepName, err)
+	}
+	return nil
+}
+
+// StepResultOf возвращает результат шага ("" - результат не задан)
+func StepResultOf(sagaCtx SagaContext, stepName string) string {
+	return sagaCtx.ForStep(stepName).GetString(stepResultKey)
+}
+
+// stepCondition возвращает условие выполнения шага
+func stepCondition(step SagaStep) func(sagaCtx SagaContext) bool {
+	if branching, ok := stepAs[BranchingStep](step); ok {
+		return branching.Condition()
+	}
+	return nil
+}
+
+// stepNextSteps возвращает переходы шага по результату
+func stepNextSteps(step SagaStep) map[string]string {
+	if branching, ok := stepAs[BranchingStep](step); ok {
+		return branching.NextStepMappings()
+	}
+	return nil
+}
+
+// stepIndexByName возвращает индекс шага определения (-1 - шаг не найден)
+func stepIndexByName(steps []SagaStep, name string) int {
+	for i, step := range steps {
+		if step.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextStepIndex возвращает индекс шага, следующего за выполненным шагом index, и описание
+// перехода для истории ("" - переход по порядку)
+func nextStepIndex(steps []SagaStep, index int, sagaCtx SagaContext) (int, string) {
+	step := steps[index]
+	mappings := stepNextSteps(step)
+	if len(mappings) == 0 {
+		return index + 1, ""
+	}
+	result := StepResultOf(sagaCtx, step.Name())
+	target, ok := mappings[result]
+	if !ok {
+		return index + 1, ""
+	}
+	return stepIndexByName(steps, target), fmt.Sprintf("%s -> %s", result, target)
+}
+
+// skipStep записывает в историю пропущенный шаг с причиной пропуска
+func (s *BaseSaga) skipStep(step SagaStep, branch string) {
+	now := time.Now()
+	s.addHistory(SagaHistory{
+		StepName:    step.Name(),
+		Status:      StepStatusSkipped,
+		StartedAt:   now,
+		CompletedAt: &now,
+		Branch:      branch,
+	})
+}
+
+// sagaContextKey ключ контекста саги в context.Context охранников FSM
+type sagaContextKey struct{}
+
+// withSagaContext передает контекст саги охранникам переходов FSM
+func withSagaContext(ctx context.Context, sagaCtx SagaContext) context.Context {
+	return context.WithValue(ctx, sagaContextKey{}, sagaCtx)
+}
+
+// conditionGuard охранник перехода к шагу с условием выполнения
+func conditionGuard(condition func(sagaCtx SagaContext) bool) fsm.Guard {
+	if condition == nil {
+		return nil
+	}
+	return func(ctx context.Context, from fsm.State, to fsm.State, event fsm.Event) (bool, error) {
+		sagaCtx, ok := ctx.Value(sagaContextKey{}).(SagaContext)
+		if !ok {
+			return true, nil
+		}
+		return condition(sagaCtx), nil
+	}
+}
+
+// resultGuard охранник перехода по результату шага stepName: matches - результат входит
+// в results (переход NextStepOn), иначе - не входит (переход по порядку)
+func resultGuard(stepName string, results map[string]bool, matches bool) fsm.Guard {
+	return func(ctx context.Context, from fsm.State, to fsm.State, event fsm.Event) (bool, error) {
+		sagaCtx, ok := ctx.Value(sagaContextKey{}).(SagaContext)
+		if !ok {
+			return true, nil
+		}
+		return results[StepResultOf(sagaCtx, stepName)] == matches, nil
+	}
+}
+
+// stepRoute путь из состояния FSM к следующему шагу с охранником выбора пути
+type stepRoute struct {
+	index int
+	guard fsm.Guard
+}
+
+// addStepTransitions добавляет в FSM переходы из состояния шага from (-1 - начальное состояние)
+// к шагам, которые могут выполняться следующими: переходы по результату, по порядку и через
+// шаги, пропускаемые по условию
+func addStepTransitions(fsmInstance *fsm.FSM, steps []SagaStep, states []fsm.State, from int) error {
+	var routes []stepRoute
+	mappings := map[string]string{}
+	if from >= 0 {
+		mappings = stepNextSteps(steps[from])
+	}
+	if len(mappings) == 0 {
+		routes = append(routes, stepRoute{index: from + 1})
+	} else {
+		for result, target := range mappings {
+			index := stepIndexByName(steps, target)
+			if index < 0 {
+				return fmt.Errorf("step %s: next step %s for result %q not found", steps[from].Name(), target, result)
+			}
+			if index <= from {
+				return fmt.Errorf("step %s: next step %s for result %q must follow the step", steps[from].Name(), target, result)
+			}
+			routes = append(routes, stepRoute{index: index, guard: resultGuard(steps[from].Name(), map[string]bool{result: true}, true)})
+		}
+		mapped := make(map[string]bool, len(mappings))
+		for result := range mappings {
+			mapped[result] = true
+		}
+		routes = append(routes, stepRoute{index: from + 1, guard: resultGuard(steps[from].Name(), mapped, false)})
+	}
+
+	for _, route := range routes {
+		for i := route.index; i < len(steps); i++ {
+			condition := stepCondition(steps[i])
+			transition := fsm.NewTransition(states[from+1], states[i+1], fmt.Sprintf("execute_%s", steps[i].Name())).
+				WithGuard(fsm.AllGuards(route.guard, conditionGuard(condition)))
+			if err := fsmInstance.AddTransition(transition); err != nil {
+				return fmt.Errorf("failed to add transition for step %s: %w", steps[i].Name(), err)
+			}
+			// Шаг без условия выполняется всегда - следующие шаги достижимы только через него
+			if condition == nil {
+				break
+			}
+		}
+	}
+	return nil
+}
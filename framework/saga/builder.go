@@ -12,25 +12,50 @@ import (
 
 // SagaBuilder построитель саги
 type SagaBuilder struct {
-	name         string
-	steps        []SagaStep
-	timeout      time.Duration
-	retryPolicy  *RetryPolicy
-	persistence  SagaPersistence
-	eventBus     events.EventBus
-	commandBus   transport.CommandBus
-	metadata     map[string]interface{}
+	name                 string
+	version              int
+	compensationOrder    CompensationOrder
+	compensationStrategy CompensationStrategy
+	steps                []SagaStep
+	timeout              time.Duration
+	retryPolicy          *RetryPolicy
+	persistence          SagaPersistence
+	eventBus             events.EventBus
+	commandBus           transport.CommandBus
+	metadata             map[string]interface{}
+	sla               time.Duration
+	overrides         []StepSettingsOverride
 }
 
 // NewSagaBuilder создает новый построитель саги
 func NewSagaBuilder(name string) *SagaBuilder {
 	return &SagaBuilder{
 		name:     name,
+		version:  DefaultSagaDefinitionVersion,
 		steps:    make([]SagaStep, 0),
 		metadata: make(map[string]interface{}),
 	}
 }
 
+// WithVersion устанавливает версию определения саги
+func (b *SagaBuilder) WithVersion(version int) *SagaBuilder {
+	b.version = version
+	return b
+}
+
+// WithCompensationOrder устанавливает стратегию порядка компенсации шагов
+func (b *SagaBuilder) WithCompensationOrder(order CompensationOrder) *SagaBuilder {
+	b.compensationOrder = order
+	return b
+}
+
+// WithCompensationStrategy устанавливает стратегию восстановления после ошибки шага
+// (см. BaseSagaDefinition.WithCompensationStrategy)
+func (b *SagaBuilder) WithCompensationStrategy(strategy CompensationStrategy) *SagaBuilder {
+	b.compensationStrategy = strategy
+	return b
+}
+
 // AddStep добавляет шаг в сагу
 func (b *SagaBuilder) AddStep(step SagaStep) *SagaBuilder {
 	b.steps = append(b.steps, step)
@@ -43,12 +68,25 @@ func (b *SagaBuilder) WithTimeout(timeout time.Duration) *SagaBuilder {
 	return b
 }
 
+// WithSLA устанавливает SLA саги (см. BaseSagaDefinition.WithSLA)
+func (b *SagaBuilder) WithSLA(sla time.Duration) *SagaBuilder {
+	b.sla = sla
+	return b
+}
+
 // WithRetryPolicy устанавливает политику повторов
 func (b *SagaBuilder) WithRetryPolicy(policy *RetryPolicy) *SagaBuilder {
 	b.retryPolicy = policy
 	return b
 }
 
+// WithStepOverride добавляет хук переопределения настроек шагов
+// (см. BaseSagaDefinition.WithStepOverride, EnvStepOverride)
+func (b *SagaBuilder) WithStepOverride(override StepSettingsOverride) *SagaBuilder {
+	b.overrides = append(b.overrides, override)
+	return b
+}
+
 // WithPersistence устанавливает persistence
 func (b *SagaBuilder) WithPersistence(persistence SagaPersistence) *SagaBuilder {
 	b.persistence = persistence
@@ -95,23 +133,45 @@ func (b *SagaBuilder) Build() (SagaDefinition, error) {
 		// В реальности можно сделать опциональным
 	}
 
+	// Проверка стратегии компенсации и зависимостей шагов
+	if err := validateCompensationOrder(b.compensationOrder); err != nil {
+		return nil, err
+	}
+	for _, step := range b.steps {
+		for _, dep := range stepDependencies(step) {
+			if !stepNames[dep] {
+				return nil, fmt.Errorf("step %s depends on unknown step: %s", step.Name(), dep)
+			}
+		}
+	}
+	if _, err := compensationWaves(b.steps, b.compensationOrder); err != nil {
+		return nil, err
+	}
+
 	definition := &BaseSagaDefinition{
-		name:  b.name,
-		steps: b.steps,
+		name:                 b.name,
+		version:              b.version,
+		compensationOrder:    b.compensationOrder,
+		compensationStrategy: b.compensationStrategy,
+		steps:                b.steps,
+		sla:                  b.sla,
+		overrides:            b.overrides,
 	}
+	// Общие настройки применяются и к шагам, не основанным на *BaseStep
+	definition.WithDefaults(b.timeout, b.retryPolicy)
 
 	// Применяем общие настройки к шагам
 	for _, step := range b.steps {
 		// Применяем общий timeout если задан и у шага нет своего
 		if b.timeout > 0 && step.Timeout() == 0 {
-			if baseStep, ok := step.(*BaseStep); ok {
+			if baseStep, ok := stepAs[*BaseStep](step); ok {
 				baseStep.WithTimeout(b.timeout)
 			}
 		}
 
 		// Применяем общую retry policy если задана и у шага нет своей
 		if b.retryPolicy != nil && step.RetryPolicy() == nil {
-			if baseStep, ok := step.(*BaseStep); ok {
+			if baseStep, ok := stepAs[*BaseStep](step); ok {
 				baseStep.WithRetry(b.retryPolicy)
 			}
 		}
@@ -127,7 +187,9 @@ type StepBuilder struct {
 	compensateAction func(ctx context.Context, sagaCtx SagaContext) error
 	guard           func(ctx context.Context, sagaCtx SagaContext) bool
 	timeout         time.Duration
+	heartbeatTimeout time.Duration
 	retryPolicy     *RetryPolicy
+	compensationRetry *RetryPolicy
 	metadata        map[string]interface{}
 }
 
@@ -163,12 +225,24 @@ func (b *StepBuilder) WithTimeout(timeout time.Duration) *StepBuilder {
 	return b
 }
 
+// WithHeartbeatTimeout устанавливает максимальный интервал между heartbeat шага
+func (b *StepBuilder) WithHeartbeatTimeout(timeout time.Duration) *StepBuilder {
+	b.heartbeatTimeout = timeout
+	return b
+}
+
 // WithRetry устанавливает retry policy
 func (b *StepBuilder) WithRetry(policy *RetryPolicy) *StepBuilder {
 	b.retryPolicy = policy
 	return b
 }
 
+// WithCompensationRetry устанавливает политику повторов компенсации
+func (b *StepBuilder) WithCompensationRetry(policy *RetryPolicy) *StepBuilder {
+	b.compensationRetry = policy
+	return b
+}
+
 // WithMetadata добавляет метаданные
 func (b *StepBuilder) WithMetadata(key string, value interface{}) *StepBuilder {
 	if b.metadata == nil {
@@ -210,11 +284,17 @@ func (b *StepBuilder) Build() (SagaStep, error) {
 	if b.timeout > 0 {
 		step.WithTimeout(b.timeout)
 	}
+	if b.heartbeatTimeout > 0 {
+		step.WithHeartbeatTimeout(b.heartbeatTimeout)
+	}
 
 	// Устанавливаем retry policy
 	if b.retryPolicy != nil {
 		step.WithRetry(b.retryPolicy)
 	}
+	if b.compensationRetry != nil {
+		step.WithCompensationRetry(b.compensationRetry)
+	}
 
 	// Устанавливаем метаданные
 	for k, v := range b.metadata {
@@ -0,0 +1,467 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SagaBundleFormat формат переносимой выгрузки саг
+const SagaBundleFormat = "potter-saga-bundle/v1"
+
+// RedactedValue значение, которым заменяются секреты в выгрузке
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedKeys подстроки ключей контекста и деталей ошибок, значения которых
+// по умолчанию заменяются RedactedValue при выгрузке
+var DefaultRedactedKeys = []string{
+	"password", "secret", "token", "api_key", "apikey", "authorization",
+	"credential", "private_key", "card_number", "cvv",
+}
+
+// ErrSagaAlreadyExists сага с импортируемым ID уже сохранена
+var ErrSagaAlreadyExists = errors.New("saga already exists")
+
+// SagaBundle переносимая выгрузка саг: состояние, контекст и история шагов.
+// Используется для переноса зависших саг между окружениями и приложения к обращениям в поддержку.
+type SagaBundle struct {
+	Format     string    `json:"format"`
+	ExportedAt time.Time `json:"exported_at"`
+	// RedactedKeys подстроки ключей, значения которых заменены RedactedValue
+	RedactedKeys []string          `json:"redacted_keys,omitempty"`
+	Sagas        []SagaBundleEntry `json:"sagas"`
+}
+
+// SagaBundleEntry сохраненное состояние саги в выгрузке
+type SagaBundleEntry struct {
+	SagaID            string     `json:"saga_id"`
+	DefinitionName    string     `json:"definition_name"`
+	DefinitionVersion int        `json:"definition_version"`
+	Status            SagaStatus `json:"status"`
+	CurrentStep       string     `json:"current_step,omitempty"`
+	CorrelationID     string     `json:"correlation_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	// Context контекст в сохраненном представлении (с кодеками типов, см. ContextSerializer)
+	Context map[string]interface{} `json:"context"`
+	History []SagaBundleHistory    `json:"history"`
+}
+
+// SagaBundleHistory запись истории шага в выгрузке
+type SagaBundleHistory struct {
+	StepName     string       `json:"step_name"`
+	Status       StepStatus   `json:"status"`
+	StartedAt    time.Time    `json:"started_at"`
+	CompletedAt  *time.Time   `json:"completed_at,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	Failure      *SagaFailure `json:"failure,omitempty"`
+	RetryAttempt int          `json:"retry_attempt"`
+	StackTrace   string       `json:"stack_trace,omitempty"`
+	Branch       string       `json:"branch,omitempty"`
+}
+
+// SagaBundleStore persistence, выгружающая и загружающая сохраненные саги без восстановления
+// определений. Реализуется InMemoryPersistence, PostgresPersistence и MySQLPersistence.
+type SagaBundleStore interface {
+	// ExportSaga возвращает сохраненное состояние саги
+	ExportSaga(ctx context.Context, sagaID string) (SagaBundleEntry, error)
+	// ImportSaga сохраняет сагу из выгрузки. Если сага уже существует, возвращается
+	// ErrSagaAlreadyExists, а при overwrite = true состояние и история заменяются.
+	ImportSaga(ctx context.Context, entry SagaBundleEntry, overwrite bool) error
+}
+
+// SagaExportOptions параметры выгрузки саг
+type SagaExportOptions struct {
+	// SagaIDs выгружаемые саги; если не заданы, саги выбираются по Filter
+	SagaIDs []string
+	// Filter фильтр саг (требует SagaAdminStore)
+	Filter SagaRecordFilter
+	// RedactKeys подстроки ключей, значения которых заменяются RedactedValue
+	// (пустой список - без маскирования)
+	RedactKeys []string
+}
+
+// ExportSagaBundle выгружает саги из persistence в переносимый формат
+func ExportSagaBundle(ctx context.Context, persistence SagaPersistence, options SagaExportOptions) (*SagaBundle, error) {
+	store, ok := persistence.(SagaBundleStore)
+	if !ok {
+		return nil, fmt.Errorf("persistence %T does not support saga export", persistence)
+	}
+
+	sagaIDs := options.SagaIDs
+	if len(sagaIDs) == 0 {
+		admin, ok := persistence.(SagaAdminStore)
+		if !ok {
+			return nil, fmt.Errorf("persistence %T does not support listing sagas", persistence)
+		}
+		records, err := admin.ListSagaRecords(ctx, options.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sagas: %w", err)
+		}
+		for _, record := range records {
+			sagaIDs = append(sagaIDs, record.SagaID)
+		}
+	}
+
+	bundle := &SagaBundle{
+		Format:       SagaBundleFormat,
+		ExportedAt:   time.Now().UTC(),
+		RedactedKeys: options.RedactKeys,
+		Sagas:        make([]SagaBundleEntry, 0, len(sagaIDs)),
+	}
+	for _, sagaID := range sagaIDs {
+		entry, err := store.ExportSaga(ctx, sagaID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export saga %s: %w", sagaID, err)
+		}
+		bundle.Sagas = append(bundle.Sagas, RedactSagaBundleEntry(entry, options.RedactKeys))
+	}
+	return bundle, nil
+}
+
+// ImportSagaBundle загружает саги из выгрузки в persistence. Ошибка импорта одной саги
+// не прерывает импорт остальных; возвращается число импортированных саг и объединенная ошибка.
+func ImportSagaBundle(ctx context.Context, persistence SagaPersistence, bundle *SagaBundle, overwrite bool) (int, error) {
+	store, ok := persistence.(SagaBundleStore)
+	if !ok {
+		return 0, fmt.Errorf("persistence %T does not support saga import", persistence)
+	}
+	if bundle.Format != SagaBundleFormat {
+		return 0, fmt.Errorf("unsupported saga bundle format %q, expected %q", bundle.Format, SagaBundleFormat)
+	}
+
+	imported := 0
+	var errs []error
+	for _, entry := range bundle.Sagas {
+		if err := store.ImportSaga(ctx, entry, overwrite); err != nil {
+			errs = append(errs, fmt.Errorf("saga %s: %w", entry.SagaID, err))
+			continue
+		}
+		imported++
+	}
+	return imported, errors.Join(errs...)
+}
+
+// WriteSagaBundle записывает выгрузку в JSON
+func WriteSagaBundle(out io.Writer, bundle *SagaBundle) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return fmt.Errorf("failed to write saga bundle: %w", err)
+	}
+	return nil
+}
+
+// ReadSagaBundle читает выгрузку из JSON и проверяет формат
+func ReadSagaBundle(in io.Reader) (*SagaBundle, error) {
+	var bundle SagaBundle
+	if err := json.NewDecoder(in).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("failed to read saga bundle: %w", err)
+	}
+	if bundle.Format != SagaBundleFormat {
+		return nil, fmt.Errorf("unsupported saga bundle format %q, expected %q", bundle.Format, SagaBundleFormat)
+	}
+	return &bundle, nil
+}
+
+// RedactSagaBundleEntry возвращает копию записи, в которой значения контекста и деталей ошибок
+// с ключами, содержащими одну из подстрок keys (без учета регистра), заменены RedactedValue.
+// Вложенные объекты и массивы обрабатываются рекурсивно.
+func RedactSagaBundleEntry(entry SagaBundleEntry, keys []string) SagaBundleEntry {
+	if len(keys) == 0 {
+		return entry
+	}
+
+	entry.Context = redactMap(entry.Context, keys)
+	history := make([]SagaBundleHistory, len(entry.History))
+	for i, hist := range entry.History {
+		if hist.Failure != nil {
+			failure := *hist.Failure
+			failure.Details = redactMap(failure.Details, keys)
+			hist.Failure = &failure
+		}
+		history[i] = hist
+	}
+	entry.History = history
+	return entry
+}
+
+// redactMap возвращает копию map с замаскированными значениями
+func redactMap(data map[string]interface{}, keys []string) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if redactedKey(key, keys) {
+			result[key] = RedactedValue
+			continue
+		}
+		result[key] = redactValue(value, keys)
+	}
+	return result
+}
+
+// redactValue маскирует значения во вложенных объектах и массивах
+func redactValue(value interface{}, keys []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactMap(v, keys)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redactValue(item, keys)
+		}
+		return result
+	}
+	return value
+}
+
+// redactedKey проверяет, содержит ли ключ одну из подстрок
+func redactedKey(key string, keys []string) bool {
+	key = strings.ToLower(key)
+	for _, substring := range keys {
+		if substring != "" && strings.Contains(key, strings.ToLower(substring)) {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleHistory переводит историю шагов в формат выгрузки
+func bundleHistory(history []SagaHistory) []SagaBundleHistory {
+	result := make([]SagaBundleHistory, 0, len(history))
+	for _, hist := range history {
+		entry := SagaBundleHistory{
+			StepName:     hist.StepName,
+			Status:       hist.Status,
+			StartedAt:    hist.StartedAt,
+			CompletedAt:  hist.CompletedAt,
+			Failure:      hist.Failure,
+			RetryAttempt: hist.RetryAttempt,
+			StackTrace:   hist.StackTrace,
+			Branch:       hist.Branch,
+		}
+		if hist.Error != nil {
+			entry.Error = hist.Error.Error()
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// sagaHistory восстанавливает историю шагов из выгрузки
+func (e SagaBundleEntry) sagaHistory() []SagaHistory {
+	history := make([]SagaHistory, 0, len(e.History))
+	for _, hist := range e.History {
+		entry := SagaHistory{
+			StepName:     hist.StepName,
+			Status:       hist.Status,
+			StartedAt:    hist.StartedAt,
+			CompletedAt:  hist.CompletedAt,
+			Failure:      hist.Failure,
+			RetryAttempt: hist.RetryAttempt,
+			StackTrace:   hist.StackTrace,
+			Branch:       hist.Branch,
+		}
+		if hist.Error != "" {
+			entry.Error = errors.New(hist.Error)
+		}
+		history = append(history, entry)
+	}
+	return history
+}
+
+// historyID возвращает детерминированный идентификатор записи истории (как в Save)
+func (e SagaBundleEntry) historyID(hist SagaBundleHistory) string {
+	return fmt.Sprintf("%s:%s:%d", e.SagaID, hist.StepName, hist.StartedAt.UnixNano())
+}
+
+// ExportSaga возвращает сохраненное состояние саги (реализация SagaBundleStore)
+func (p *InMemoryPersistence) ExportSaga(ctx context.Context, sagaID string) (SagaBundleEntry, error) {
+	saga, err := p.Load(ctx, sagaID)
+	if err != nil {
+		return SagaBundleEntry{}, err
+	}
+
+	contextData, err := encodeSagaContext(saga.Context())
+	if err != nil {
+		return SagaBundleEntry{}, fmt.Errorf("failed to encode context: %w", err)
+	}
+	metadata := saga.Context().Metadata()
+	entry := SagaBundleEntry{
+		SagaID:            saga.ID(),
+		DefinitionName:    saga.Definition().Name(),
+		DefinitionVersion: SagaDefinitionVersion(saga.Definition()),
+		Status:            saga.Status(),
+		CurrentStep:       saga.CurrentStep(),
+		CorrelationID:     saga.Context().CorrelationID(),
+		CreatedAt:         metadata.CreatedAt,
+		UpdatedAt:         metadata.UpdatedAt,
+		Context:           contextData,
+		History:           bundleHistory(saga.GetHistory()),
+	}
+	if baseSaga, ok := saga.(*BaseSaga); ok {
+		baseSaga.mu.RLock()
+		entry.CompletedAt = baseSaga.completedAt
+		baseSaga.mu.RUnlock()
+	}
+	return entry, nil
+}
+
+// ImportSaga сохраняет сагу из выгрузки (реализация SagaBundleStore). Определение берется
+// из реестра (WithRegistry); если версия не зарегистрирована, сага восстанавливается
+// по шагам из истории и доступна только для чтения (см. ErrSagaDefinitionMissing).
+func (p *InMemoryPersistence) ImportSaga(ctx context.Context, entry SagaBundleEntry, overwrite bool) error {
+	p.mu.RLock()
+	_, exists := p.sagas[entry.SagaID]
+	registry := p.registry
+	p.mu.RUnlock()
+	if exists && !overwrite {
+		return fmt.Errorf("%w: %s", ErrSagaAlreadyExists, entry.SagaID)
+	}
+
+	var definition SagaDefinition
+	if registry != nil {
+		definition, _ = registry.GetSagaVersion(entry.DefinitionName, entry.DefinitionVersion)
+	}
+	if definition == nil {
+		definition = newMissingSagaDefinition(entry.definitionMetadata())
+	}
+
+	sagaCtx := NewSagaContext()
+	if err := restoreSagaContext(sagaCtx, entry.Context); err != nil {
+		return fmt.Errorf("failed to restore context: %w", err)
+	}
+	if entry.CorrelationID != "" {
+		sagaCtx.SetCorrelationID(entry.CorrelationID)
+	}
+	if ctxImpl, ok := sagaCtx.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.CreatedAt = entry.CreatedAt
+		ctxImpl.metadata.UpdatedAt = entry.UpdatedAt
+		ctxImpl.mu.Unlock()
+	}
+
+	saga, err := NewBaseSaga(entry.SagaID, definition, sagaCtx, p)
+	if err != nil {
+		return fmt.Errorf("failed to create saga instance: %w", err)
+	}
+	saga.mu.Lock()
+	saga.status = entry.Status
+	saga.currentStep = entry.CurrentStep
+	saga.history = entry.sagaHistory()
+	saga.startedAt = entry.CreatedAt
+	saga.completedAt = entry.CompletedAt
+	saga.mu.Unlock()
+
+	return p.Save(ctx, saga)
+}
+
+// definitionMetadata возвращает метаданные определения по шагам из истории саги
+func (e SagaBundleEntry) definitionMetadata() SagaDefinitionMetadata {
+	metadata := SagaDefinitionMetadata{Name: e.DefinitionName, Version: e.DefinitionVersion}
+	seen := make(map[string]bool)
+	for _, hist := range e.History {
+		if !seen[hist.StepName] {
+			seen[hist.StepName] = true
+			metadata.Steps = append(metadata.Steps, hist.StepName)
+		}
+	}
+	if e.CurrentStep != "" && !seen[e.CurrentStep] {
+		metadata.Steps = append(metadata.Steps, e.CurrentStep)
+	}
+	return metadata
+}
+
+// ExportSaga возвращает сохраненное состояние саги (реализация SagaBundleStore)
+func (p *PostgresPersistence) ExportSaga(ctx context.Context, sagaID string) (SagaBundleEntry, error) {
+	entry := SagaBundleEntry{}
+	var status string
+	var contextJSON []byte
+	err := p.conn.QueryRow(ctx, `
+		SELECT id, definition_name, definition_version, status, context, COALESCE(correlation_id, ''), COALESCE(current_step, ''), created_at, updated_at, completed_at
+		FROM saga_instances
+		WHERE id = $1
+	`, sagaID).Scan(&entry.SagaID, &entry.DefinitionName, &entry.DefinitionVersion, &status, &contextJSON,
+		&entry.CorrelationID, &entry.CurrentStep, &entry.CreatedAt, &entry.UpdatedAt, &entry.CompletedAt)
+	if err != nil {
+		return SagaBundleEntry{}, fmt.Errorf("failed to load saga: %w", err)
+	}
+	entry.Status = SagaStatus(status)
+	if err := json.Unmarshal(contextJSON, &entry.Context); err != nil {
+		return SagaBundleEntry{}, fmt.Errorf("failed to unmarshal context: %w", err)
+	}
+
+	history, err := p.GetHistory(ctx, sagaID)
+	if err != nil {
+		return SagaBundleEntry{}, fmt.Errorf("failed to load history: %w", err)
+	}
+	entry.History = bundleHistory(history)
+	return entry, nil
+}
+
+// ImportSaga сохраняет сагу из выгрузки в одной транзакции (реализация SagaBundleStore).
+// При overwrite = true история саги заменяется историей из выгрузки.
+func (p *PostgresPersistence) ImportSaga(ctx context.Context, entry SagaBundleEntry, overwrite bool) error {
+	contextJSON, err := json.Marshal(entry.Context)
+	if err != nil {
+		return fmt.Errorf("failed to marshal context: %w", err)
+	}
+
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM saga_instances WHERE id = $1)`, entry.SagaID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check saga: %w", err)
+	}
+	if exists && !overwrite {
+		return fmt.Errorf("%w: %s", ErrSagaAlreadyExists, entry.SagaID)
+	}
+	if exists {
+		if _, err := tx.Exec(ctx, `DELETE FROM saga_history WHERE saga_id = $1`, entry.SagaID); err != nil {
+			return fmt.Errorf("failed to delete saga history: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO saga_instances (id, definition_name, definition_version, status, context, correlation_id, current_step, created_at, updated_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			definition_name = $2,
+			definition_version = $3,
+			status = $4,
+			context = $5,
+			correlation_id = $6,
+			current_step = $7,
+			created_at = $8,
+			updated_at = $9,
+			completed_at = $10
+	`, entry.SagaID, entry.DefinitionName, entry.DefinitionVersion, string(entry.Status), contextJSON,
+		entry.CorrelationID, entry.CurrentStep, entry.CreatedAt, entry.UpdatedAt, entry.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save saga: %w", err)
+	}
+
+	for _, hist := range entry.History {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO saga_history (id, saga_id, step_name, status, error, retry_attempt, started_at, completed_at, stack_trace, failure, branch)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, entry.historyID(hist), entry.SagaID, hist.StepName, string(hist.Status), hist.Error, hist.RetryAttempt,
+			hist.StartedAt, hist.CompletedAt, hist.StackTrace, failureJSON(hist.Failure), hist.Branch)
+		if err != nil {
+			return fmt.Errorf("failed to save saga history: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,72 @@
+// Package saga предоставляет кооперативную отмену выполняющихся шагов саги.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+var (
+	// ErrSagaCancelled причина отмены контекста выполняющегося шага при DefaultOrchestrator.Cancel
+	ErrSagaCancelled = errors.New("saga cancelled")
+	// ErrCompensationRequested причина отмены контекста выполняющегося шага
+	// при DefaultOrchestrator.Compensate для выполняющейся саги
+	ErrCompensationRequested = errors.New("saga compensation requested")
+)
+
+// IsCancellation сообщает, что выполнение саги остановлено кооперативной отменой
+// (Cancel или Compensate выполняющейся саги), а не ошибкой шага
+func IsCancellation(err error) bool {
+	return errors.Is(err, ErrSagaCancelled) || errors.Is(err, ErrCompensationRequested)
+}
+
+// cancellationCause возвращает причину отмены контекста саги оркестратором (nil - отмены не было).
+// Отмена родительского контекста без причины кооперативной отменой не считается.
+func cancellationCause(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); IsCancellation(cause) {
+		return cause
+	}
+	return nil
+}
+
+// cancelAt останавливает сагу, отмененную на шаге с индексом stepIndex: записывает шаг
+// как отмененный (historyEntry == nil - отмена между шагами) и компенсирует выполненные шаги.
+// Компенсация выполняется с контекстом без отмены, иначе шаги компенсации получили бы
+// уже отмененный контекст.
+func (s *BaseSaga) cancelAt(ctx context.Context, step SagaStep, stepIndex int, historyEntry *SagaHistory, cause error) error {
+	compensateCtx := context.WithoutCancel(ctx)
+
+	if historyEntry != nil {
+		cancelledAt := time.Now()
+		historyEntry.Status = StepStatusCancelled
+		historyEntry.Error = cause
+		historyEntry.CompletedAt = &cancelledAt
+		s.updateHistory(*historyEntry)
+		s.recordStepDuration(compensateCtx, step.Name(), StepStatusCancelled, cancelledAt.Sub(historyEntry.StartedAt))
+
+		if s.eventBus != nil {
+			cancelledEvent := &StepCancelledEvent{
+				BaseEvent: events.NewBaseEvent("StepCancelled", s.id),
+				SagaID:    s.id,
+				StepName:  step.Name(),
+				Reason:    cause.Error(),
+				Timestamp: cancelledAt,
+			}
+			cancelledEvent.WithCorrelationID(s.context.CorrelationID())
+			_ = s.eventBus.Publish(compensateCtx, cancelledEvent)
+		}
+	}
+
+	// Отмененный шаг не завершился, поэтому компенсируются только выполненные до него
+	if err := s.compensateSteps(compensateCtx, stepIndex-1, step.Name()); err != nil {
+		return fmt.Errorf("saga %s stopped at step %s: %w, compensation also failed: %w", s.id, step.Name(), cause, err)
+	}
+	return fmt.Errorf("saga %s stopped at step %s: %w", s.id, step.Name(), cause)
+}
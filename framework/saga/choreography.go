@@ -0,0 +1,657 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/invoke"
+	"github.com/akriventsev/potter/framework/metrics"
+	"github.com/akriventsev/potter/framework/transport"
+
+	"github.com/google/uuid"
+)
+
+// ChoreographyReaction реакция шага хореографической саги на событие-триггер.
+// Возвращает команды, которые Choreographer отправит после сохранения состояния саги.
+type ChoreographyReaction func(ctx context.Context, instance Saga, event events.Event) ([]transport.Command, error)
+
+// ChoreographyCompensation компенсация шага хореографической саги: возвращает компенсирующие команды
+type ChoreographyCompensation func(ctx context.Context, instance Saga) ([]transport.Command, error)
+
+// ChoreographyCorrelation определяет ID саги, к которой относится событие
+type ChoreographyCorrelation func(event events.Event) string
+
+// DefaultChoreographyCorrelation использует метаданные события "saga_id", а при их отсутствии - correlation ID.
+// Choreographer отправляет команды с correlation ID саги, поэтому события, опубликованные
+// обработчиками команд с распространением correlation ID, связываются с сагой автоматически.
+func DefaultChoreographyCorrelation(event events.Event) string {
+	metadata := event.Metadata()
+	if sagaID, ok := metadata["saga_id"].(string); ok && sagaID != "" {
+		return sagaID
+	}
+	return metadata.CorrelationID()
+}
+
+// choreographyStep шаг хореографической саги. Шаг выполняется не оркестратором,
+// а Choreographer при получении события-триггера.
+type choreographyStep struct {
+	name         string
+	trigger      string
+	reaction     ChoreographyReaction
+	compensation ChoreographyCompensation
+}
+
+func (s *choreographyStep) Name() string {
+	return s.name
+}
+
+// Execute не поддерживается: шаг выполняется по событию-триггеру
+func (s *choreographyStep) Execute(ctx context.Context, sagaCtx SagaContext) error {
+	return fmt.Errorf("choreography step %s is triggered by event %s and cannot be executed by an orchestrator", s.name, s.trigger)
+}
+
+// Compensate запрашивает компенсирующие команды; команды отправляются Choreographer
+// после сохранения состояния саги
+func (s *choreographyStep) Compensate(ctx context.Context, sagaCtx SagaContext) error {
+	if s.compensation == nil {
+		return nil
+	}
+	run := choreographyRunFromContext(ctx)
+	if run == nil {
+		return fmt.Errorf("choreography step %s can only be compensated by Choreographer", s.name)
+	}
+	commands, err := s.compensation(ctx, run.instance)
+	if err != nil {
+		return err
+	}
+	run.addCommands(commands)
+	return nil
+}
+
+func (s *choreographyStep) CanExecute(ctx context.Context, sagaCtx SagaContext) bool {
+	return true
+}
+
+func (s *choreographyStep) Timeout() time.Duration {
+	return 0
+}
+
+func (s *choreographyStep) RetryPolicy() *RetryPolicy {
+	return nil
+}
+
+// ChoreographyDefinition определение хореографической (event-driven) саги.
+// Сага не управляется оркестратором: каждый шаг выполняется при получении события-триггера
+// из EventBus и отправляет команды, результаты которых приходят следующими событиями.
+// Первый шаг запускает сагу, выполнение последнего шага завершает ее.
+// Состояние и история хранятся так же, как у BaseSaga, через SagaPersistence.
+type ChoreographyDefinition struct {
+	*BaseSagaDefinition
+	triggers      map[string]*choreographyStep
+	failureEvents []string
+	correlation   ChoreographyCorrelation
+}
+
+// NewChoreographyDefinition создает определение хореографической саги
+func NewChoreographyDefinition(name string) *ChoreographyDefinition {
+	return &ChoreographyDefinition{
+		BaseSagaDefinition: NewBaseSagaDefinition(name),
+		triggers:           make(map[string]*choreographyStep),
+		correlation:        DefaultChoreographyCorrelation,
+	}
+}
+
+// On добавляет шаг stepName, выполняемый при получении события eventType.
+// Шаги выполняются в порядке добавления; событие для еще не достигнутого
+// или уже выполненного шага игнорируется.
+func (d *ChoreographyDefinition) On(eventType, stepName string, reaction ChoreographyReaction) *ChoreographyDefinition {
+	step := &choreographyStep{name: stepName, trigger: eventType, reaction: reaction}
+	d.triggers[eventType] = step
+	d.BaseSagaDefinition.AddStep(step)
+	return d
+}
+
+// WithCompensation задает компенсацию шага stepName
+func (d *ChoreographyDefinition) WithCompensation(stepName string, compensation ChoreographyCompensation) *ChoreographyDefinition {
+	for _, step := range d.triggers {
+		if step.name == stepName {
+			step.compensation = compensation
+		}
+	}
+	return d
+}
+
+// FailOn задает события, при получении которых выполненные шаги саги компенсируются
+func (d *ChoreographyDefinition) FailOn(eventTypes ...string) *ChoreographyDefinition {
+	d.failureEvents = append(d.failureEvents, eventTypes...)
+	return d
+}
+
+// WithCorrelation задает функцию определения ID саги по событию (по умолчанию DefaultChoreographyCorrelation)
+func (d *ChoreographyDefinition) WithCorrelation(correlation ChoreographyCorrelation) *ChoreographyDefinition {
+	d.correlation = correlation
+	return d
+}
+
+// WithVersion устанавливает версию определения
+func (d *ChoreographyDefinition) WithVersion(version int) *ChoreographyDefinition {
+	d.BaseSagaDefinition.WithVersion(version)
+	return d
+}
+
+// WithCompensationOrder устанавливает стратегию порядка компенсации шагов
+func (d *ChoreographyDefinition) WithCompensationOrder(order CompensationOrder) *ChoreographyDefinition {
+	d.BaseSagaDefinition.WithCompensationOrder(order)
+	return d
+}
+
+// AddStep добавляет шаг; хореографические шаги добавляются через On
+func (d *ChoreographyDefinition) AddStep(step SagaStep) SagaDefinition {
+	if choreographed, ok := step.(*choreographyStep); ok {
+		d.triggers[choreographed.trigger] = choreographed
+	}
+	d.BaseSagaDefinition.AddStep(step)
+	return d
+}
+
+// validate проверяет определение перед регистрацией
+func (d *ChoreographyDefinition) validate() error {
+	steps := d.Steps()
+	if len(steps) == 0 {
+		return fmt.Errorf("choreography saga %s has no steps", d.Name())
+	}
+	names := make(map[string]bool, len(steps))
+	triggers := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		choreographed, ok := step.(*choreographyStep)
+		if !ok {
+			return fmt.Errorf("choreography saga %s: step %s is not triggered by an event, use On", d.Name(), step.Name())
+		}
+		if names[step.Name()] {
+			return fmt.Errorf("choreography saga %s: duplicate step %s", d.Name(), step.Name())
+		}
+		if triggers[choreographed.trigger] {
+			return fmt.Errorf("choreography saga %s: event %s triggers more than one step", d.Name(), choreographed.trigger)
+		}
+		names[step.Name()] = true
+		triggers[choreographed.trigger] = true
+	}
+	for _, eventType := range d.failureEvents {
+		if triggers[eventType] {
+			return fmt.Errorf("choreography saga %s: event %s is both a step trigger and a failure event", d.Name(), eventType)
+		}
+	}
+	return validateCompensationOrder(d.CompensationOrder())
+}
+
+// stepIndex возвращает индекс шага, запускаемого событием (-1, если событие не является триггером)
+func (d *ChoreographyDefinition) stepIndex(eventType string) int {
+	for i, step := range d.Steps() {
+		if choreographed, ok := step.(*choreographyStep); ok && choreographed.trigger == eventType {
+			return i
+		}
+	}
+	return -1
+}
+
+// choreographyRunKey ключ выполнения хореографии в контексте
+type choreographyRunKey struct{}
+
+// choreographyRun накапливает команды и события обработки одного события,
+// которые отправляются после сохранения состояния саги и освобождения блокировки
+type choreographyRun struct {
+	instance *BaseSaga
+	mu       sync.Mutex
+	commands []transport.Command
+}
+
+func (r *choreographyRun) addCommands(commands []transport.Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, commands...)
+}
+
+func choreographyRunFromContext(ctx context.Context) *choreographyRun {
+	run, _ := ctx.Value(choreographyRunKey{}).(*choreographyRun)
+	return run
+}
+
+// bufferedEventBus откладывает публикацию событий жизненного цикла саги до освобождения
+// блокировки Choreographer: синхронные подписчики не должны повторно входить в Choreographer
+type bufferedEventBus struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (b *bufferedEventBus) Publish(ctx context.Context, event events.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, event)
+	return nil
+}
+
+func (b *bufferedEventBus) Subscribe(eventType string, handler events.EventHandler) error {
+	return fmt.Errorf("buffered event bus does not support subscriptions")
+}
+
+func (b *bufferedEventBus) Unsubscribe(eventType string, handler events.EventHandler) error {
+	return fmt.Errorf("buffered event bus does not support subscriptions")
+}
+
+// Choreographer выполняет хореографические саги: подписывается на события-триггеры
+// зарегистрированных определений, продвигает состояние саг и отправляет команды шагов.
+// Команды и события жизненного цикла саги отправляются после сохранения состояния,
+// поэтому доставка at-least-once: обработчики команд должны быть идемпотентными
+// (ключ идемпотентности шага передается в контексте, см. invoke.WithSagaStep).
+type Choreographer struct {
+	mu          sync.Mutex
+	persistence SagaPersistence
+	eventBus    events.EventBus
+	commandBus  transport.CommandBus
+	registry    *SagaRegistry
+	metrics     *metrics.Metrics
+	definitions map[string]*ChoreographyDefinition
+}
+
+// NewChoreographer создает Choreographer; nil persistence - InMemoryPersistence
+func NewChoreographer(persistence SagaPersistence, eventBus events.EventBus, commandBus transport.CommandBus) *Choreographer {
+	if persistence == nil {
+		persistence = NewInMemoryPersistence()
+	}
+	return &Choreographer{
+		persistence: persistence,
+		eventBus:    eventBus,
+		commandBus:  commandBus,
+		registry:    NewSagaRegistry(),
+		definitions: make(map[string]*ChoreographyDefinition),
+	}
+}
+
+// WithRegistry устанавливает реестр саг. Реестр должен совпадать с реестром persistence
+// (EventStorePersistence.WithRegistry, PostgresPersistence.WithRegistry) для загрузки саг.
+func (c *Choreographer) WithRegistry(registry *SagaRegistry) *Choreographer {
+	c.registry = registry
+	return c
+}
+
+// WithMetrics добавляет метрики к Choreographer
+func (c *Choreographer) WithMetrics(m *metrics.Metrics) *Choreographer {
+	c.metrics = m
+	return c
+}
+
+// Register регистрирует определение и подписывается на его события
+func (c *Choreographer) Register(definition *ChoreographyDefinition) error {
+	if err := definition.validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if _, exists := c.definitions[definition.Name()]; exists {
+		c.mu.Unlock()
+		return fmt.Errorf("choreography saga %s already registered", definition.Name())
+	}
+	c.definitions[definition.Name()] = definition
+	c.mu.Unlock()
+
+	if err := c.registry.RegisterSaga(definition.Name(), definition); err != nil {
+		return err
+	}
+
+	eventTypes := make([]string, 0, len(definition.triggers)+len(definition.failureEvents))
+	for _, step := range definition.Steps() {
+		eventTypes = append(eventTypes, step.(*choreographyStep).trigger)
+	}
+	eventTypes = append(eventTypes, definition.failureEvents...)
+
+	for _, eventType := range eventTypes {
+		handler := &choreographyHandler{choreographer: c, definition: definition, eventType: eventType}
+		if err := c.eventBus.Subscribe(eventType, handler); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// GetStatus возвращает статус саги
+func (c *Choreographer) GetStatus(ctx context.Context, sagaID string) (SagaStatus, error) {
+	instance, err := c.persistence.Load(ctx, sagaID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load saga: %w", err)
+	}
+	return instance.Status(), nil
+}
+
+// HandleEvent обрабатывает событие определения definitionName. Вызывается подписками,
+// созданными Register; может использоваться для доставки событий из внешних источников.
+func (c *Choreographer) HandleEvent(ctx context.Context, definitionName string, event events.Event) error {
+	c.mu.Lock()
+	definition, exists := c.definitions[definitionName]
+	c.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("choreography saga %s not registered", definitionName)
+	}
+	return c.handle(ctx, definition, event)
+}
+
+// handle продвигает сагу по событию и отправляет накопленные команды и события
+func (c *Choreographer) handle(ctx context.Context, definition *ChoreographyDefinition, event events.Event) error {
+	sagaID := definition.correlation(event)
+	stepIndex := definition.stepIndex(event.EventType())
+
+	c.mu.Lock()
+	run, buffer, err := c.advance(ctx, definition, sagaID, stepIndex, event)
+	c.mu.Unlock()
+	if err != nil || run == nil {
+		return err
+	}
+
+	c.publish(ctx, buffer)
+	if sendErr := c.send(ctx, run); sendErr != nil {
+		// Команды шага не доставлены: компенсируем уже выполненные шаги
+		return c.fail(ctx, definition, run.instance.ID(), sendErr)
+	}
+	return nil
+}
+
+// advance изменяет состояние саги под блокировкой Choreographer.
+// Возвращает nil run, если событие не относится к саге или уже обработано.
+func (c *Choreographer) advance(ctx context.Context, definition *ChoreographyDefinition, sagaID string, stepIndex int, event events.Event) (*choreographyRun, *bufferedEventBus, error) {
+	buffer := &bufferedEventBus{}
+
+	instance := c.load(ctx, definition, sagaID)
+	if instance == nil {
+		if stepIndex != 0 {
+			// Событие не относится к известной саге этого определения
+			return nil, nil, nil
+		}
+		var err error
+		instance, err = c.create(ctx, definition, sagaID, event, buffer)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	instance.eventBus = buffer
+	defer func() { instance.eventBus = c.eventBus }()
+
+	run := &choreographyRun{instance: instance}
+	runCtx := context.WithValue(ctx, choreographyRunKey{}, run)
+
+	if stepIndex < 0 {
+		// Событие неудачи: компенсируем выполненные шаги
+		status := instance.Status()
+		if status != SagaStatusRunning && status != SagaStatusCompleted {
+			return nil, nil, nil
+		}
+		if err := c.compensate(runCtx, instance, event.EventType(), buffer); err != nil {
+			return nil, nil, err
+		}
+		return run, buffer, nil
+	}
+
+	if instance.Status() != SagaStatusRunning || choreographyNextStep(instance) != stepIndex {
+		// Повторная доставка или событие для шага, который еще не достигнут
+		return nil, nil, nil
+	}
+
+	step := definition.Steps()[stepIndex].(*choreographyStep)
+	if err := c.executeStep(runCtx, instance, step, event, buffer); err != nil {
+		if compErr := c.compensate(runCtx, instance, err.Error(), buffer); compErr != nil {
+			return nil, nil, compErr
+		}
+		return run, buffer, nil
+	}
+
+	if stepIndex == len(definition.Steps())-1 {
+		c.complete(ctx, instance, buffer)
+	}
+
+	if err := c.persistence.Save(ctx, instance); err != nil {
+		return nil, nil, fmt.Errorf("failed to save saga state: %w", err)
+	}
+	return run, buffer, nil
+}
+
+// load загружает сагу определения; nil, если сага не найдена
+func (c *Choreographer) load(ctx context.Context, definition *ChoreographyDefinition, sagaID string) *BaseSaga {
+	if sagaID == "" {
+		return nil
+	}
+	loaded, err := c.persistence.Load(ctx, sagaID)
+	if err != nil || loaded == nil {
+		return nil
+	}
+	instance, ok := loaded.(*BaseSaga)
+	if !ok || instance.Definition().Name() != definition.Name() {
+		return nil
+	}
+	return instance
+}
+
+// create создает сагу по событию первого шага
+func (c *Choreographer) create(ctx context.Context, definition *ChoreographyDefinition, sagaID string, event events.Event, buffer *bufferedEventBus) (*BaseSaga, error) {
+	if sagaID == "" {
+		sagaID = uuid.New().String()
+	}
+
+	sagaCtx := NewSagaContext()
+	correlationID := event.Metadata().CorrelationID()
+	if correlationID == "" {
+		correlationID = sagaID
+	}
+	sagaCtx.SetCorrelationID(correlationID)
+
+	instance, err := NewBaseSagaWithEventBus(sagaID, definition, sagaCtx, c.persistence, buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saga instance: %w", err)
+	}
+
+	now := time.Now()
+	instance.mu.Lock()
+	instance.status = SagaStatusRunning
+	instance.startedAt = now
+	instance.mu.Unlock()
+
+	startedEvent := &SagaStartedEvent{
+		BaseEvent:      events.NewBaseEvent("SagaStarted", sagaID),
+		SagaID:         sagaID,
+		DefinitionName: definition.Name(),
+		Timestamp:      now,
+		CorrelationID:  correlationID,
+	}
+	startedEvent.WithCorrelationID(correlationID)
+	_ = buffer.Publish(ctx, startedEvent)
+
+	if c.metrics != nil {
+		c.metrics.RecordEvent(ctx, "saga.started")
+	}
+	return instance, nil
+}
+
+// executeStep выполняет реакцию шага и записывает результат в историю саги
+func (c *Choreographer) executeStep(ctx context.Context, instance *BaseSaga, step *choreographyStep, event events.Event, buffer *bufferedEventBus) error {
+	startedAt := time.Now()
+	instance.mu.Lock()
+	instance.currentStep = step.name
+	instance.mu.Unlock()
+
+	instance.context.SetIdempotencyKey(invoke.GenerateIdempotencyKey(instance.id, step.name, 0))
+	stepCtx := invoke.WithCorrelationID(invoke.WithSagaStep(ctx, instance.id, step.name, 0), instance.context.CorrelationID())
+
+	var commands []transport.Command
+	var err error
+	if step.reaction != nil {
+		err = core.SafeCall(func() error {
+			var reactionErr error
+			commands, reactionErr = step.reaction(stepCtx, instance, event)
+			return reactionErr
+		})
+	}
+
+	completedAt := time.Now()
+	entry := SagaHistory{
+		StepName:    step.name,
+		Status:      StepStatusCompleted,
+		StartedAt:   startedAt,
+		CompletedAt: &completedAt,
+	}
+
+	if err != nil {
+		entry.Status = StepStatusFailed
+		entry.Error = err
+		entry.Failure = ClassifyFailure(err)
+		entry.StackTrace = core.StackTraceOf(err)
+		instance.addHistory(entry)
+
+		failedEvent := &StepFailedEvent{
+			BaseEvent:  events.NewBaseEvent("StepFailed", instance.id),
+			SagaID:     instance.id,
+			StepName:   step.name,
+			Error:      err.Error(),
+			Failure:    entry.Failure,
+			StackTrace: entry.StackTrace,
+			Timestamp:  completedAt,
+		}
+		failedEvent.WithCorrelationID(instance.context.CorrelationID())
+		_ = buffer.Publish(ctx, failedEvent)
+
+		return fmt.Errorf("step %s failed: %w", step.name, err)
+	}
+
+	instance.addHistory(entry)
+	choreographyRunFromContext(ctx).addCommands(commands)
+
+	completedEvent := &StepCompletedEvent{
+		BaseEvent: events.NewBaseEvent("StepCompleted", instance.id),
+		SagaID:    instance.id,
+		StepName:  step.name,
+		Duration:  completedAt.Sub(startedAt),
+		Timestamp: completedAt,
+	}
+	completedEvent.WithCorrelationID(instance.context.CorrelationID())
+	_ = buffer.Publish(ctx, completedEvent)
+	return nil
+}
+
+// complete завершает сагу после выполнения последнего шага
+func (c *Choreographer) complete(ctx context.Context, instance *BaseSaga, buffer *bufferedEventBus) {
+	now := time.Now()
+	instance.mu.Lock()
+	instance.status = SagaStatusCompleted
+	instance.completedAt = &now
+	duration := now.Sub(instance.startedAt)
+	instance.mu.Unlock()
+
+	completedEvent := &SagaCompletedEvent{
+		BaseEvent:      events.NewBaseEvent("SagaCompleted", instance.id),
+		SagaID:         instance.id,
+		Duration:       duration,
+		StepsCompleted: choreographyNextStep(instance),
+		Timestamp:      now,
+	}
+	completedEvent.WithCorrelationID(instance.context.CorrelationID())
+	_ = buffer.Publish(ctx, completedEvent)
+
+	if c.metrics != nil {
+		c.metrics.RecordEvent(ctx, "saga.completed")
+	}
+}
+
+// compensate компенсирует выполненные шаги саги (состояние сохраняется BaseSaga)
+func (c *Choreographer) compensate(ctx context.Context, instance *BaseSaga, reason string, buffer *bufferedEventBus) error {
+	compensatingEvent := &SagaCompensatingEvent{
+		BaseEvent: events.NewBaseEvent("SagaCompensating", instance.id),
+		SagaID:    instance.id,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+	compensatingEvent.WithCorrelationID(instance.context.CorrelationID())
+	_ = buffer.Publish(ctx, compensatingEvent)
+
+	if err := instance.Compensate(ctx); err != nil {
+		if c.metrics != nil {
+			c.metrics.RecordEvent(ctx, "saga.compensation.failed")
+		}
+		return fmt.Errorf("saga %s compensation failed: %w", instance.id, err)
+	}
+
+	if c.metrics != nil {
+		c.metrics.RecordEvent(ctx, "saga.compensated")
+	}
+	return nil
+}
+
+// fail компенсирует сагу после ошибки, обнаруженной вне блокировки (например, при отправке команд)
+func (c *Choreographer) fail(ctx context.Context, definition *ChoreographyDefinition, sagaID string, cause error) error {
+	c.mu.Lock()
+	instance := c.load(ctx, definition, sagaID)
+	if instance == nil {
+		c.mu.Unlock()
+		return cause
+	}
+	buffer := &bufferedEventBus{}
+	instance.eventBus = buffer
+	run := &choreographyRun{instance: instance}
+	err := c.compensate(context.WithValue(ctx, choreographyRunKey{}, run), instance, cause.Error(), buffer)
+	instance.eventBus = c.eventBus
+	c.mu.Unlock()
+
+	c.publish(ctx, buffer)
+	return errors.Join(cause, err, c.send(ctx, run))
+}
+
+// publish публикует накопленные события жизненного цикла саги
+func (c *Choreographer) publish(ctx context.Context, buffer *bufferedEventBus) {
+	for _, event := range buffer.events {
+		_ = c.eventBus.Publish(ctx, event)
+	}
+}
+
+// send отправляет накопленные команды с correlation ID саги
+func (c *Choreographer) send(ctx context.Context, run *choreographyRun) error {
+	if c.commandBus == nil || len(run.commands) == 0 {
+		return nil
+	}
+	sendCtx := invoke.WithCommandSource(invoke.WithCorrelationID(ctx, run.instance.context.CorrelationID()), invoke.CommandSourceSaga)
+	for _, cmd := range run.commands {
+		if err := c.commandBus.Send(sendCtx, cmd); err != nil {
+			return fmt.Errorf("failed to send command %s: %w", cmd.CommandName(), err)
+		}
+	}
+	return nil
+}
+
+// choreographyNextStep возвращает индекс следующего шага саги по истории
+func choreographyNextStep(instance *BaseSaga) int {
+	history := instance.GetHistory()
+	next := 0
+	for i, step := range instance.definition.Steps() {
+		for _, hist := range history {
+			if hist.StepName == step.Name() && hist.Status == StepStatusCompleted {
+				next = i + 1
+				break
+			}
+		}
+	}
+	return next
+}
+
+// choreographyHandler подписка Choreographer на событие определения
+type choreographyHandler struct {
+	choreographer *Choreographer
+	definition    *ChoreographyDefinition
+	eventType     string
+}
+
+func (h *choreographyHandler) Handle(ctx context.Context, event events.Event) error {
+	return h.choreographer.handle(ctx, h.definition, event)
+}
+
+func (h *choreographyHandler) EventType() string {
+	return h.eventType
+}
@@ -0,0 +1,277 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CompensationOrder стратегия порядка компенсации выполненных шагов саги
+type CompensationOrder string
+
+const (
+	// CompensationOrderReverse последовательная компенсация в порядке, обратном выполнению (по умолчанию)
+	CompensationOrderReverse CompensationOrder = "reverse"
+	// CompensationOrderDependency последовательная компенсация по графу зависимостей шагов:
+	// шаг компенсируется только после компенсации всех зависящих от него шагов
+	CompensationOrderDependency CompensationOrder = "dependency"
+	// CompensationOrderParallel компенсация по графу зависимостей, при которой
+	// независимые друг от друга шаги компенсируются параллельно
+	CompensationOrderParallel CompensationOrder = "parallel"
+)
+
+// StepDependencies шаг, объявляющий зависимости от других шагов саги.
+// Реализуется BaseStep (см. BaseStep.WithDependsOn).
+type StepDependencies interface {
+	// DependsOn возвращает имена шагов, результаты которых использует шаг
+	DependsOn() []string
+}
+
+// CompensationOrderedDefinition определение саги с собственной стратегией компенсации.
+// Определения, не реализующие интерфейс, компенсируются в обратном порядке (CompensationOrderReverse).
+type CompensationOrderedDefinition interface {
+	SagaDefinition
+	// CompensationOrder возвращает стратегию порядка компенсации
+	CompensationOrder() CompensationOrder
+}
+
+// SagaCompensationOrder возвращает стратегию порядка компенсации определения саги
+func SagaCompensationOrder(definition SagaDefinition) CompensationOrder {
+	if ordered, ok := definition.(CompensationOrderedDefinition); ok && ordered.CompensationOrder() != "" {
+		return ordered.CompensationOrder()
+	}
+	return CompensationOrderReverse
+}
+
+// validateCompensationOrder проверяет, что стратегия компенсации известна
+func validateCompensationOrder(order CompensationOrder) error {
+	switch order {
+	case "", CompensationOrderReverse, CompensationOrderDependency, CompensationOrderParallel:
+		return nil
+	}
+	return fmt.Errorf("unknown compensation order: %s", order)
+}
+
+// stepDependencies возвращает зависимости шага
+func stepDependencies(step SagaStep) []string {
+	if deps, ok := stepAs[StepDependencies](step); ok {
+		return deps.DependsOn()
+	}
+	return nil
+}
+
+// compensationWaves разбивает выполненные шаги (в порядке выполнения) на волны компенсации.
+// Шаги одной волны не зависят друг от друга; внутри волны шаги упорядочены
+// в порядке, обратном выполнению. Для последовательных стратегий каждая волна содержит один шаг.
+// Зависимости от невыполненных шагов игнорируются.
+func compensationWaves(executed []SagaStep, order CompensationOrder) ([][]SagaStep, error) {
+	if err := validateCompensationOrder(order); err != nil {
+		return nil, err
+	}
+
+	if order == "" || order == CompensationOrderReverse {
+		waves := make([][]SagaStep, 0, len(executed))
+		for i := len(executed) - 1; i >= 0; i-- {
+			waves = append(waves, []SagaStep{executed[i]})
+		}
+		return waves, nil
+	}
+
+	index := make(map[string]int, len(executed))
+	for i, step := range executed {
+		index[step.Name()] = i
+	}
+
+	// pending[i] - число еще не компенсированных шагов, зависящих от шага i
+	pending := make([]int, len(executed))
+	dependencies := make([][]int, len(executed))
+	for i, step := range executed {
+		for _, name := range stepDependencies(step) {
+			dep, ok := index[name]
+			if !ok || dep == i {
+				continue
+			}
+			dependencies[i] = append(dependencies[i], dep)
+			pending[dep]++
+		}
+	}
+
+	compensated := make([]bool, len(executed))
+	remaining := len(executed)
+	waves := make([][]SagaStep, 0)
+	for remaining > 0 {
+		ready := make([]int, 0)
+		for i := range executed {
+			if !compensated[i] && pending[i] == 0 {
+				ready = append(ready, i)
+			}
+		}
+		if len(ready) == 0 {
+			cycle := make([]string, 0, remaining)
+			for i, step := range executed {
+				if !compensated[i] {
+					cycle = append(cycle, step.Name())
+				}
+			}
+			return nil, fmt.Errorf("compensation dependency cycle between steps: %s", strings.Join(cycle, ", "))
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(ready)))
+
+		wave := make([]SagaStep, 0, len(ready))
+		for _, i := range ready {
+			compensated[i] = true
+			remaining--
+			for _, dep := range dependencies[i] {
+				pending[dep]--
+			}
+			wave = append(wave, executed[i])
+		}
+
+		if order == CompensationOrderParallel {
+			waves = append(waves, wave)
+			continue
+		}
+		for _, step := range wave {
+			waves = append(waves, []SagaStep{step})
+		}
+	}
+	return waves, nil
+}
+
+// CompensationRequest выполненные шаги саги, которые стратегия должна компенсировать
+type CompensationRequest struct {
+	Definition SagaDefinition
+	Context    SagaContext
+	// Executed выполненные и еще не компенсированные шаги в порядке выполнения
+	Executed []SagaStep
+	// FailedStep шаг, на котором остановилась сага (ошибка или отмена);
+	// пусто при ручной компенсации и возобновлении компенсации
+	FailedStep string
+}
+
+// CompensationStrategy стратегия восстановления саги после ошибки шага.
+// PlanCompensation возвращает волны компенсации: шаги одной волны компенсируются параллельно,
+// волны - последовательно. Шаги, не вошедшие в план, не компенсируются (частичная компенсация).
+type CompensationStrategy interface {
+	PlanCompensation(ctx context.Context, request CompensationRequest) ([][]SagaStep, error)
+}
+
+// ForwardRecoveryStrategy стратегия, которая вместо компенсации повторяет упавший шаг
+// после исчерпания его политики повторов. Если повторы прекращены, выполненные шаги
+// компенсируются по плану PlanCompensation.
+type ForwardRecoveryStrategy interface {
+	CompensationStrategy
+	// RecoveryDelay возвращает задержку перед повтором attempt (с 0) шага, завершившегося ошибкой err;
+	// false - прекратить повторы и компенсировать сагу
+	RecoveryDelay(step SagaStep, attempt int, err error) (time.Duration, bool)
+}
+
+// CompensationStrategyDefinition определение саги с собственной стратегией восстановления.
+// Определения, не реализующие интерфейс, используют BackwardCompensation.
+type CompensationStrategyDefinition interface {
+	SagaDefinition
+	// CompensationStrategy возвращает стратегию восстановления (nil - BackwardCompensation)
+	CompensationStrategy() CompensationStrategy
+}
+
+// SagaCompensationStrategy возвращает стратегию восстановления определения саги
+func SagaCompensationStrategy(definition SagaDefinition) CompensationStrategy {
+	if configured, ok := definition.(CompensationStrategyDefinition); ok && configured.CompensationStrategy() != nil {
+		return configured.CompensationStrategy()
+	}
+	return BackwardCompensation()
+}
+
+// backwardCompensation компенсирует выполненные шаги в порядке CompensationOrder определения
+type backwardCompensation struct{}
+
+// BackwardCompensation возвращает стратегию по умолчанию: все выполненные шаги компенсируются
+// в порядке, заданном CompensationOrder определения (обратный, по зависимостям или параллельный)
+func BackwardCompensation() CompensationStrategy {
+	return backwardCompensation{}
+}
+
+// PlanCompensation строит волны компенсации по CompensationOrder определения
+func (backwardCompensation) PlanCompensation(ctx context.Context, request CompensationRequest) ([][]SagaStep, error) {
+	return compensationWaves(request.Executed, SagaCompensationOrder(request.Definition))
+}
+
+// ForwardRecovery стратегия прямого восстановления: упавший шаг повторяется с нарастающей
+// задержкой, пока не выполнится, а сага остается в статусе running. Ошибки, классифицированные
+// как неповторяемые (SagaFailure.Retryable = false), и исчерпание MaxAttempts приводят
+// к компенсации выполненных шагов (BackwardCompensation).
+type ForwardRecovery struct {
+	// InitialDelay задержка перед первым повтором
+	InitialDelay time.Duration
+	// MaxDelay максимальная задержка между повторами
+	MaxDelay time.Duration
+	// Multiplier множитель задержки
+	Multiplier float64
+	// MaxAttempts максимальное число повторов (0 - без ограничения)
+	MaxAttempts int
+}
+
+// NewForwardRecovery создает стратегию прямого восстановления с удвоением задержки
+// от initialDelay до maxDelay и без ограничения числа повторов
+func NewForwardRecovery(initialDelay, maxDelay time.Duration) *ForwardRecovery {
+	return &ForwardRecovery{
+		InitialDelay: initialDelay,
+		MaxDelay:     maxDelay,
+		Multiplier:   2.0,
+	}
+}
+
+// WithMaxAttempts ограничивает число повторов, после которого сага компенсируется
+func (f *ForwardRecovery) WithMaxAttempts(maxAttempts int) *ForwardRecovery {
+	f.MaxAttempts = maxAttempts
+	return f
+}
+
+// PlanCompensation строит волны компенсации по CompensationOrder определения
+func (f *ForwardRecovery) PlanCompensation(ctx context.Context, request CompensationRequest) ([][]SagaStep, error) {
+	return BackwardCompensation().PlanCompensation(ctx, request)
+}
+
+// RecoveryDelay возвращает экспоненциально растущую задержку, ограниченную MaxDelay
+func (f *ForwardRecovery) RecoveryDelay(step SagaStep, attempt int, err error) (time.Duration, bool) {
+	if f.MaxAttempts > 0 && attempt >= f.MaxAttempts {
+		return 0, false
+	}
+	if failure := ClassifyFailure(err); failure != nil && !failure.Retryable {
+		return 0, false
+	}
+
+	multiplier := f.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := time.Duration(float64(f.InitialDelay) * math.Pow(multiplier, float64(attempt)))
+	if f.MaxDelay > 0 && (delay > f.MaxDelay || delay < 0) {
+		delay = f.MaxDelay
+	}
+	return delay, true
+}
+
+// validateCompensationPlan проверяет, что план содержит только выполненные шаги без повторов
+func validateCompensationPlan(executed []SagaStep, waves [][]SagaStep) error {
+	known := make(map[string]bool, len(executed))
+	for _, step := range executed {
+		known[step.Name()] = true
+	}
+	planned := make(map[string]bool, len(executed))
+	for _, wave := range waves {
+		for _, step := range wave {
+			if !known[step.Name()] {
+				return fmt.Errorf("compensation plan contains step %s that was not executed", step.Name())
+			}
+			if planned[step.Name()] {
+				return fmt.Errorf("compensation plan contains step %s more than once", step.Name())
+			}
+			planned[step.Name()] = true
+		}
+	}
+	return nil
+}
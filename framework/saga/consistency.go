@@ -0,0 +1,152 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrRegistryInconsistent реестр саг не содержит определений, необходимых сохраненным сагам
+var ErrRegistryInconsistent = errors.New("saga registry is inconsistent with persisted sagas")
+
+// ActiveSagaStatuses статусы незавершенных саг, которые могут быть возобновлены
+var ActiveSagaStatuses = []SagaStatus{SagaStatusPending, SagaStatusRunning, SagaStatusWaitingApproval, SagaStatusWaitingMessage, SagaStatusCompensating, SagaStatusCompensationStuck}
+
+// PersistedSagaRef сведения о сохраненной саге, доступные без восстановления определения
+type PersistedSagaRef struct {
+	SagaID            string
+	DefinitionName    string
+	DefinitionVersion int
+	Status            SagaStatus
+	CurrentStep       string
+}
+
+// SagaRefLister persistence, перечисляющая сохраненные саги без загрузки определений.
+// Реализуется InMemoryPersistence, EventStorePersistence и PostgresPersistence.
+type SagaRefLister interface {
+	// ListSagaRefs возвращает сведения о сагах с указанными статусами
+	ListSagaRefs(ctx context.Context, statuses ...SagaStatus) ([]PersistedSagaRef, error)
+}
+
+// ConsistencyCheckMode режим проверки согласованности реестра при старте оркестратора
+type ConsistencyCheckMode string
+
+const (
+	// ConsistencyCheckDisabled проверка не выполняется (по умолчанию)
+	ConsistencyCheckDisabled ConsistencyCheckMode = ""
+	// ConsistencyCheckWarn отчет о проблемах передается обработчику, старт продолжается
+	ConsistencyCheckWarn ConsistencyCheckMode = "warn"
+	// ConsistencyCheckFailFast старт завершается ошибкой ErrRegistryInconsistent
+	ConsistencyCheckFailFast ConsistencyCheckMode = "fail_fast"
+)
+
+// ConsistencyIssue проблема восстановления сохраненной саги
+type ConsistencyIssue struct {
+	PersistedSagaRef
+	// Reason описание проблемы
+	Reason string
+}
+
+// ConsistencyReport отчет о согласованности реестра саг и сохраненных саг
+type ConsistencyReport struct {
+	// CheckedSagas число проверенных незавершенных саг
+	CheckedSagas int
+	// Issues саги, которые не удастся восстановить с текущим реестром
+	Issues []ConsistencyIssue
+}
+
+// OK проверяет, что проблем не обнаружено
+func (r *ConsistencyReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// String возвращает отчет в читаемом виде
+func (r *ConsistencyReport) String() string {
+	if r.OK() {
+		return fmt.Sprintf("%d active sagas checked, no issues", r.CheckedSagas)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d active sagas checked, %d cannot be restored:", r.CheckedSagas, len(r.Issues))
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&b, "\n  - saga %s (%s v%d, %s): %s",
+			issue.SagaID, issue.DefinitionName, issue.DefinitionVersion, issue.Status, issue.Reason)
+	}
+	return b.String()
+}
+
+// Err возвращает ErrRegistryInconsistent с отчетом, если обнаружены проблемы
+func (r *ConsistencyReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrRegistryInconsistent, r.String())
+}
+
+// CheckRegistryConsistency проверяет, что для каждой незавершенной саги в persistence
+// зарегистрирована версия определения, с которой сага была запущена, и что текущий шаг
+// саги присутствует в этой версии
+func CheckRegistryConsistency(ctx context.Context, registry *SagaRegistry, persistence SagaPersistence) (*ConsistencyReport, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("registry not configured")
+	}
+	lister, ok := persistence.(SagaRefLister)
+	if !ok {
+		return nil, fmt.Errorf("persistence %T does not support listing persisted sagas", persistence)
+	}
+
+	refs, err := lister.ListSagaRefs(ctx, ActiveSagaStatuses...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persisted sagas: %w", err)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].SagaID < refs[j].SagaID })
+
+	report := &ConsistencyReport{CheckedSagas: len(refs)}
+	for _, ref := range refs {
+		if reason := checkSagaRef(registry, ref); reason != "" {
+			report.Issues = append(report.Issues, ConsistencyIssue{PersistedSagaRef: ref, Reason: reason})
+		}
+	}
+	return report, nil
+}
+
+// checkSagaRef возвращает причину, по которой сагу не удастся восстановить, или пустую строку
+func checkSagaRef(registry *SagaRegistry, ref PersistedSagaRef) string {
+	versions := registry.ListVersions(ref.DefinitionName)
+	if len(versions) == 0 {
+		return "definition is not registered"
+	}
+
+	definition, err := registry.GetSagaVersion(ref.DefinitionName, ref.DefinitionVersion)
+	if err != nil {
+		return fmt.Sprintf("definition version %d is not registered (registered versions: %v)", ref.DefinitionVersion, versions)
+	}
+	if metadata, missing := MissingDefinitionMetadata(definition); missing {
+		return fmt.Sprintf("definition code is missing, saga is read-only (persisted %s)", metadata)
+	}
+
+	if ref.CurrentStep == "" {
+		return ""
+	}
+	for _, step := range definition.Steps() {
+		if step.Name() == ref.CurrentStep {
+			return ""
+		}
+	}
+	return fmt.Sprintf("current step %s is missing in definition version %d", ref.CurrentStep, SagaDefinitionVersion(definition))
+}
+
+// sagaStatusIn проверяет, входит ли статус в список (пустой список - любой статус)
+func sagaStatusIn(status SagaStatus, statuses []SagaStatus) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
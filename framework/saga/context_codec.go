@@ -0,0 +1,358 @@
+package saga
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// contextCodecTypeKey ключ имени кодека в закодированном значении контекста
+	contextCodecTypeKey = "$type"
+	// contextCodecValueKey ключ закодированного значения
+	contextCodecValueKey = "$value"
+)
+
+// ContextCodec кодирует значения одного Go типа контекста саги в JSON-совместимое представление,
+// которое восстанавливается без потерь (без float timestamps и строк RFC3339 в коде шагов)
+type ContextCodec interface {
+	// Name имя типа в сохраненном контексте
+	Name() string
+	// Type Go тип значений, которые кодирует кодек
+	Type() reflect.Type
+	// Encode кодирует значение типа Type
+	Encode(value interface{}) (interface{}, error)
+	// Decode восстанавливает значение типа Type из результата Encode после JSON round-trip
+	Decode(encoded interface{}) (interface{}, error)
+}
+
+// ContextSerializer кодирует значения контекста саги зарегистрированными кодеками при сохранении
+// и восстанавливает их при загрузке. Значения без кодека сохраняются как есть.
+// Закодированное значение хранится как {"$type": имя кодека, "$value": значение}.
+type ContextSerializer struct {
+	mu     sync.RWMutex
+	byType map[reflect.Type]ContextCodec
+	byName map[string]ContextCodec
+}
+
+// NewContextSerializer создает ContextSerializer со встроенными кодеками:
+// time.Time ("time"), time.Duration ("duration"), int и int64 ("int", "int64") и *big.Rat ("decimal")
+func NewContextSerializer() *ContextSerializer {
+	s := &ContextSerializer{
+		byType: make(map[reflect.Type]ContextCodec),
+		byName: make(map[string]ContextCodec),
+	}
+	s.Register(timeContextCodec{})
+	s.Register(durationContextCodec{})
+	s.Register(intContextCodec{})
+	s.Register(int64ContextCodec{})
+	s.Register(ratContextCodec{})
+	return s
+}
+
+// Register регистрирует кодек (заменяет кодек с тем же именем или типом)
+func (s *ContextSerializer) Register(codec ContextCodec) *ContextSerializer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byType[codec.Type()] = codec
+	s.byName[codec.Name()] = codec
+	return s
+}
+
+// Encode кодирует значения контекста, включая вложенные map и слайсы
+func (s *ContextSerializer) Encode(data map[string]interface{}) (map[string]interface{}, error) {
+	encoded := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		encodedValue, err := s.encodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode context value %q: %w", key, err)
+		}
+		encoded[key] = encodedValue
+	}
+	return encoded, nil
+}
+
+// Decode восстанавливает значения, закодированные Encode. Контекст, сохраненный
+// до регистрации кодеков, и значения неизвестных кодеков возвращаются как есть.
+func (s *ContextSerializer) Decode(data map[string]interface{}) (map[string]interface{}, error) {
+	decoded := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		decodedValue, err := s.decodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode context value %q: %w", key, err)
+		}
+		decoded[key] = decodedValue
+	}
+	return decoded, nil
+}
+
+func (s *ContextSerializer) encodeValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	codec, ok := s.byType[reflect.TypeOf(value)]
+	s.mu.RUnlock()
+	if ok {
+		encoded, err := codec.Encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("codec %s: %w", codec.Name(), err)
+		}
+		return map[string]interface{}{contextCodecTypeKey: codec.Name(), contextCodecValueKey: encoded}, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.Encode(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			encoded, err := s.encodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = encoded
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func (s *ContextSerializer) decodeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if name, ok := v[contextCodecTypeKey].(string); ok && len(v) == 2 {
+			if encoded, ok := v[contextCodecValueKey]; ok {
+				s.mu.RLock()
+				codec, known := s.byName[name]
+				s.mu.RUnlock()
+				if known {
+					decoded, err := codec.Decode(encoded)
+					if err != nil {
+						return nil, fmt.Errorf("codec %s: %w", name, err)
+					}
+					return decoded, nil
+				}
+			}
+		}
+		return s.Decode(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			decoded, err := s.decodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = decoded
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// defaultContextSerializer сериализатор контекста, используемый persistence адаптерами
+var defaultContextSerializer = NewContextSerializer()
+
+// RegisterContextCodec регистрирует кодек в сериализаторе контекста, используемом
+// PostgresPersistence, MySQLPersistence и EventStorePersistence
+func RegisterContextCodec(codec ContextCodec) {
+	defaultContextSerializer.Register(codec)
+}
+
+// encodeSagaContext возвращает контекст саги в представлении для сохранения
+func encodeSagaContext(sagaCtx SagaContext) (map[string]interface{}, error) {
+	return defaultContextSerializer.Encode(sagaCtx.ToMap())
+}
+
+// restoreSagaContext восстанавливает контекст саги из сохраненного представления
+func restoreSagaContext(sagaCtx SagaContext, data map[string]interface{}) error {
+	decoded, err := defaultContextSerializer.Decode(data)
+	if err != nil {
+		return err
+	}
+	return sagaCtx.FromMap(decoded)
+}
+
+// ContextTime возвращает значение контекста как time.Time. Помимо time.Time
+// принимает строки RFC3339, сохраненные до регистрации кодеков.
+func ContextTime(sagaCtx SagaContext, key string) (time.Time, bool) {
+	switch v := sagaCtx.Get(key).(type) {
+	case time.Time:
+		return v, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+// ContextDuration возвращает значение контекста как time.Duration. Помимо time.Duration
+// принимает числа наносекунд и строки формата time.ParseDuration.
+func ContextDuration(sagaCtx SagaContext, key string) (time.Duration, bool) {
+	switch v := sagaCtx.Get(key).(type) {
+	case time.Duration:
+		return v, true
+	case int64:
+		return time.Duration(v), true
+	case int:
+		return time.Duration(v), true
+	case float64:
+		return time.Duration(v), true
+	case string:
+		parsed, err := time.ParseDuration(v)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+// textContextCodec кодек для типов с encoding.TextMarshaler/TextUnmarshaler
+type textContextCodec[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}] struct {
+	name string
+}
+
+// NewTextContextCodec создает кодек для типа T, сохраняемого через MarshalText/UnmarshalText,
+// например decimal.Decimal из github.com/shopspring/decimal:
+//
+//	saga.RegisterContextCodec(saga.NewTextContextCodec[decimal.Decimal]("decimal"))
+func NewTextContextCodec[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](name string) ContextCodec {
+	return textContextCodec[T, PT]{name: name}
+}
+
+func (c textContextCodec[T, PT]) Name() string { return c.name }
+
+func (c textContextCodec[T, PT]) Type() reflect.Type { return reflect.TypeOf((*T)(nil)).Elem() }
+
+func (c textContextCodec[T, PT]) Encode(value interface{}) (interface{}, error) {
+	marshaler, ok := value.(encoding.TextMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement encoding.TextMarshaler", value)
+	}
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+func (c textContextCodec[T, PT]) Decode(encoded interface{}) (interface{}, error) {
+	text, ok := encoded.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got %T", encoded)
+	}
+	var value T
+	if err := PT(&value).UnmarshalText([]byte(text)); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// timeContextCodec хранит time.Time строкой RFC3339Nano (с наносекундами и смещением зоны)
+type timeContextCodec struct{}
+
+func (timeContextCodec) Name() string { return "time" }
+
+func (timeContextCodec) Type() reflect.Type { return reflect.TypeOf(time.Time{}) }
+
+func (timeContextCodec) Encode(value interface{}) (interface{}, error) {
+	return value.(time.Time).Format(time.RFC3339Nano), nil
+}
+
+func (timeContextCodec) Decode(encoded interface{}) (interface{}, error) {
+	text, ok := encoded.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got %T", encoded)
+	}
+	return time.Parse(time.RFC3339Nano, text)
+}
+
+// durationContextCodec хранит time.Duration строкой с числом наносекунд (JSON number теряет точность)
+type durationContextCodec struct{}
+
+func (durationContextCodec) Name() string { return "duration" }
+
+func (durationContextCodec) Type() reflect.Type { return reflect.TypeOf(time.Duration(0)) }
+
+func (durationContextCodec) Encode(value interface{}) (interface{}, error) {
+	return strconv.FormatInt(int64(value.(time.Duration)), 10), nil
+}
+
+func (durationContextCodec) Decode(encoded interface{}) (interface{}, error) {
+	nanos, err := parseContextInt(encoded)
+	return time.Duration(nanos), err
+}
+
+// intContextCodec сохраняет тип int, который JSON round-trip превращает в float64
+type intContextCodec struct{}
+
+func (intContextCodec) Name() string { return "int" }
+
+func (intContextCodec) Type() reflect.Type { return reflect.TypeOf(0) }
+
+func (intContextCodec) Encode(value interface{}) (interface{}, error) {
+	return strconv.Itoa(value.(int)), nil
+}
+
+func (intContextCodec) Decode(encoded interface{}) (interface{}, error) {
+	value, err := parseContextInt(encoded)
+	return int(value), err
+}
+
+// int64ContextCodec хранит int64 строкой (значения больше 2^53 не представимы в float64)
+type int64ContextCodec struct{}
+
+func (int64ContextCodec) Name() string { return "int64" }
+
+func (int64ContextCodec) Type() reflect.Type { return reflect.TypeOf(int64(0)) }
+
+func (int64ContextCodec) Encode(value interface{}) (interface{}, error) {
+	return strconv.FormatInt(value.(int64), 10), nil
+}
+
+func (int64ContextCodec) Decode(encoded interface{}) (interface{}, error) {
+	return parseContextInt(encoded)
+}
+
+// ratContextCodec хранит десятичные значения *big.Rat в виде точной дроби
+type ratContextCodec struct{}
+
+func (ratContextCodec) Name() string { return "decimal" }
+
+func (ratContextCodec) Type() reflect.Type { return reflect.TypeOf((*big.Rat)(nil)) }
+
+func (ratContextCodec) Encode(value interface{}) (interface{}, error) {
+	return value.(*big.Rat).RatString(), nil
+}
+
+func (ratContextCodec) Decode(encoded interface{}) (interface{}, error) {
+	text, ok := encoded.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string, got %T", encoded)
+	}
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", text)
+	}
+	return value, nil
+}
+
+// parseContextInt разбирает целое, сохраненное строкой или числом
+func parseContextInt(encoded interface{}) (int64, error) {
+	switch v := encoded.(type) {
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case float64:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("expected integer string, got %T", encoded)
+}
@@ -0,0 +1,225 @@
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StepContextKeyPrefix префикс ключей пространств имен шагов в данных контекста саги.
+// Ключ "amount" шага "reserve_stock" хранится как "step:reserve_stock:amount".
+const StepContextKeyPrefix = "step:"
+
+// ErrStepContextAccess запись в чужое пространство имен шага или чтение без объявленного доступа
+var ErrStepContextAccess = errors.New("step context access denied")
+
+// StepContext пространство имен шага в контексте саги.
+//
+// Правила доступа внутри выполняющегося шага:
+//   - собственное пространство имен доступно на чтение и запись;
+//   - пространства шагов, перечисленных в StepContextReader.ReadsFrom, доступны только на чтение;
+//   - остальные пространства недоступны.
+//
+// Вне шагов (например, при подготовке контекста перед запуском саги) доступ не ограничен.
+type StepContext interface {
+	// Step возвращает имя шага - владельца пространства имен
+	Step() string
+	// Get получает значение по ключу (nil, если ключ не задан или чтение запрещено)
+	Get(key string) interface{}
+	// Set устанавливает значение по ключу
+	Set(key string, value interface{}) error
+	// Delete удаляет ключ
+	Delete(key string) error
+	// GetString получает строковое значение
+	GetString(key string) string
+	// GetInt получает целочисленное значение
+	GetInt(key string) int
+	// GetBool получает булево значение
+	GetBool(key string) bool
+	// GetFloat64 получает значение float64
+	GetFloat64(key string) float64
+	// ToMap возвращает данные пространства имен
+	ToMap() map[string]interface{}
+	// Heartbeat сообщает о прогрессе долгого шага (progress - доля выполнения от 0 до 1):
+	// продлевает окно WithHeartbeatTimeout шага и публикует StepHeartbeatEvent.
+	// Доступен только в собственном пространстве имен.
+	Heartbeat(progress float64) error
+}
+
+// StepContextReader шаг, читающий пространства имен других шагов.
+// Реализуется BaseStep (см. BaseStep.WithReadAccess).
+type StepContextReader interface {
+	// ReadsFrom возвращает имена шагов, пространства имен которых шаг может читать
+	ReadsFrom() []string
+}
+
+// stepContextKey возвращает ключ контекста саги для ключа пространства имен шага
+func stepContextKey(stepName, key string) string {
+	return StepContextKeyPrefix + stepName + ":" + key
+}
+
+// StepContextData группирует данные пространств имен шагов по имени шага
+// (например, для отображения истории саги)
+func StepContextData(sagaCtx SagaContext) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	for fullKey, value := range sagaCtx.ToMap() {
+		stepName, key, ok := splitStepContextKey(fullKey)
+		if !ok {
+			continue
+		}
+		if result[stepName] == nil {
+			result[stepName] = make(map[string]interface{})
+		}
+		result[stepName][key] = value
+	}
+	return result
+}
+
+// splitStepContextKey разбирает ключ пространства имен шага
+func splitStepContextKey(fullKey string) (stepName, key string, ok bool) {
+	if !strings.HasPrefix(fullKey, StepContextKeyPrefix) {
+		return "", "", false
+	}
+	return strings.Cut(strings.TrimPrefix(fullKey, StepContextKeyPrefix), ":")
+}
+
+// stepContext реализация StepContext поверх данных контекста саги
+type stepContext struct {
+	sagaCtx  SagaContext
+	step     string
+	readable bool
+	writable bool
+	// heartbeat обработчик heartbeat выполняющейся попытки шага (nil вне выполнения)
+	heartbeat func(progress float64)
+}
+
+func (c *stepContext) Step() string {
+	return c.step
+}
+
+func (c *stepContext) Get(key string) interface{} {
+	if !c.readable {
+		return nil
+	}
+	return c.sagaCtx.Get(stepContextKey(c.step, key))
+}
+
+func (c *stepContext) Set(key string, value interface{}) error {
+	if !c.writable {
+		return fmt.Errorf("%w: cannot write %q to namespace of step %s", ErrStepContextAccess, key, c.step)
+	}
+	c.sagaCtx.Set(stepContextKey(c.step, key), value)
+	return nil
+}
+
+func (c *stepContext) Delete(key string) error {
+	if !c.writable {
+		return fmt.Errorf("%w: cannot delete %q from namespace of step %s", ErrStepContextAccess, key, c.step)
+	}
+	return c.sagaCtx.Update(func(view SagaContextView) error {
+		view.Delete(stepContextKey(c.step, key))
+		return nil
+	})
+}
+
+func (c *stepContext) GetString(key string) string {
+	if str, ok := c.Get(key).(string); ok {
+		return str
+	}
+	return ""
+}
+
+func (c *stepContext) GetInt(key string) int {
+	switch v := c.Get(key).(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func (c *stepContext) GetBool(key string) bool {
+	if b, ok := c.Get(key).(bool); ok {
+		return b
+	}
+	return false
+}
+
+func (c *stepContext) GetFloat64(key string) float64 {
+	switch v := c.Get(key).(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
+
+func (c *stepContext) Heartbeat(progress float64) error {
+	if !c.writable {
+		return fmt.Errorf("%w: cannot report heartbeat of step %s", ErrStepContextAccess, c.step)
+	}
+	Heartbeat(c.sagaCtx)
+	if c.heartbeat != nil {
+		c.heartbeat(progress)
+	}
+	return nil
+}
+
+func (c *stepContext) ToMap() map[string]interface{} {
+	result := make(map[string]interface{})
+	if !c.readable {
+		return result
+	}
+	for fullKey, value := range c.sagaCtx.ToMap() {
+		if stepName, key, ok := splitStepContextKey(fullKey); ok && stepName == c.step {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// stepScopedContext контекст саги, передаваемый шагу: ForStep применяет правила доступа шага
+type stepScopedContext struct {
+	SagaContext
+	step SagaStep
+	// heartbeat обработчик heartbeat попытки шага (см. withHeartbeat)
+	heartbeat func(progress float64)
+}
+
+// newStepScopedContext создает контекст саги для выполнения шага
+func newStepScopedContext(sagaCtx SagaContext, step SagaStep) *stepScopedContext {
+	return &stepScopedContext{
+		SagaContext: sagaCtx,
+		step:        step,
+	}
+}
+
+// withHeartbeat возвращает копию контекста, передающую heartbeat шага обработчику попытки
+func (c *stepScopedContext) withHeartbeat(heartbeat func(progress float64)) *stepScopedContext {
+	scoped := *c
+	scoped.heartbeat = heartbeat
+	return &scoped
+}
+
+func (c *stepScopedContext) ForStep(stepName string) StepContext {
+	if stepName == c.step.Name() {
+		return &stepContext{sagaCtx: c.SagaContext, step: stepName, readable: true, writable: true, heartbeat: c.heartbeat}
+	}
+
+	readable := false
+	if reader, ok := stepAs[StepContextReader](c.step); ok {
+		for _, name := range reader.ReadsFrom() {
+			if name == stepName {
+				readable = true
+				break
+			}
+		}
+	}
+	return &stepContext{sagaCtx: c.SagaContext, step: stepName, readable: readable}
+}
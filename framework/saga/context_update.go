@@ -0,0 +1,169 @@
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrContextConflict запись одного ключа контекста несколькими параллельными шагами
+var ErrContextConflict = errors.New("saga context conflict")
+
+// SagaContextView представление контекста саги внутри SagaContext.Update.
+// Чтения видят собственные изменения, изменения применяются к контексту только после успешного Update.
+type SagaContextView interface {
+	// Get получает значение по ключу
+	Get(key string) interface{}
+	// Set устанавливает значение по ключу
+	Set(key string, value interface{})
+	// Delete удаляет ключ
+	Delete(key string)
+}
+
+// contextView буфер изменений поверх данных контекста
+type contextView struct {
+	base    map[string]interface{}
+	writes  map[string]interface{}
+	deletes map[string]bool
+}
+
+// newContextView создает представление поверх данных контекста
+func newContextView(base map[string]interface{}) *contextView {
+	return &contextView{
+		base:    base,
+		writes:  make(map[string]interface{}),
+		deletes: make(map[string]bool),
+	}
+}
+
+func (v *contextView) Get(key string) interface{} {
+	if v.deletes[key] {
+		return nil
+	}
+	if value, ok := v.writes[key]; ok {
+		return value
+	}
+	return v.base[key]
+}
+
+func (v *contextView) Set(key string, value interface{}) {
+	delete(v.deletes, key)
+	v.writes[key] = value
+}
+
+func (v *contextView) Delete(key string) {
+	delete(v.writes, key)
+	v.deletes[key] = true
+}
+
+// apply применяет изменения к данным контекста
+func (v *contextView) apply(data map[string]interface{}) {
+	for key := range v.deletes {
+		delete(data, key)
+	}
+	for key, value := range v.writes {
+		data[key] = value
+	}
+}
+
+// trackingView представление, запоминающее измененные ключи
+type trackingView struct {
+	SagaContextView
+	changed map[string]bool
+}
+
+func (v *trackingView) Set(key string, value interface{}) {
+	v.changed[key] = true
+	v.SagaContextView.Set(key, value)
+}
+
+func (v *trackingView) Delete(key string) {
+	v.changed[key] = true
+	v.SagaContextView.Delete(key)
+}
+
+// keys возвращает измененные ключи в детерминированном порядке
+func (v *trackingView) keys() []string {
+	keys := make([]string, 0, len(v.changed))
+	for key := range v.changed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// contextWriteTracker отслеживает, какой из параллельных шагов записал каждый ключ контекста
+type contextWriteTracker struct {
+	mu        sync.Mutex
+	owners    map[string]string
+	conflicts []error
+}
+
+// newContextWriteTracker создает трекер записей для группы параллельных шагов
+func newContextWriteTracker() *contextWriteTracker {
+	return &contextWriteTracker{
+		owners: make(map[string]string),
+	}
+}
+
+// claim закрепляет ключи за шагом. Если хотя бы один ключ уже записан другим шагом,
+// ни один ключ не закрепляется и возвращается ErrContextConflict.
+func (t *contextWriteTracker) claim(stepName string, keys ...string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, key := range keys {
+		if owner, ok := t.owners[key]; ok && owner != stepName {
+			err := fmt.Errorf("%w: key %q written by parallel steps %s and %s", ErrContextConflict, key, owner, stepName)
+			t.conflicts = append(t.conflicts, err)
+			return err
+		}
+	}
+	for _, key := range keys {
+		t.owners[key] = stepName
+	}
+	return nil
+}
+
+// err возвращает все обнаруженные конфликты
+func (t *contextWriteTracker) err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return errors.Join(t.conflicts...)
+}
+
+// parallelBranchContext контекст саги, выдаваемый шагу внутри ParallelStep.
+// Запись ключа, уже записанного другим шагом группы, не применяется и приводит к ErrContextConflict
+// (для Set конфликт возвращается ParallelStep после завершения группы).
+type parallelBranchContext struct {
+	SagaContext
+	stepName string
+	tracker  *contextWriteTracker
+}
+
+// newParallelBranchContext создает контекст шага параллельной группы
+func newParallelBranchContext(sagaCtx SagaContext, stepName string, tracker *contextWriteTracker) *parallelBranchContext {
+	return &parallelBranchContext{
+		SagaContext: sagaCtx,
+		stepName:    stepName,
+		tracker:     tracker,
+	}
+}
+
+func (c *parallelBranchContext) Set(key string, value interface{}) {
+	if err := c.tracker.claim(c.stepName, key); err != nil {
+		return
+	}
+	c.SagaContext.Set(key, value)
+}
+
+func (c *parallelBranchContext) Update(fn func(view SagaContextView) error) error {
+	return c.SagaContext.Update(func(view SagaContextView) error {
+		tracking := &trackingView{SagaContextView: view, changed: make(map[string]bool)}
+		if err := fn(tracking); err != nil {
+			return err
+		}
+		return c.tracker.claim(c.stepName, tracking.keys()...)
+	})
+}
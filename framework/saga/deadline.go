@@ -0,0 +1,132 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/metrics"
+)
+
+// ErrStepDeadlineExceeded шаг не завершился за дедлайн (BaseStep.WithDeadline) с эскалацией
+// EscalateCompensate. Классифицируется как ошибка категории timeout без повторов.
+var ErrStepDeadlineExceeded = errors.New("step deadline exceeded")
+
+// DeadlineEscalation действие при превышении дедлайна шага
+type DeadlineEscalation string
+
+const (
+	// EscalateNotify публикует StepDeadlineExceededEvent и вызывает обработчики эскалации,
+	// шаг продолжает выполняться
+	EscalateNotify DeadlineEscalation = "notify"
+	// EscalateCompensate дополнительно отменяет контекст шага: шаг завершается ошибкой
+	// ErrStepDeadlineExceeded и выполненные шаги компенсируются
+	EscalateCompensate DeadlineEscalation = "compensate"
+)
+
+// DeadlineStep реализуется шагами с дедлайном (см. BaseStep.WithDeadline)
+type DeadlineStep interface {
+	// Deadline возвращает дедлайн шага с учетом повторов (0 - не задан)
+	Deadline() time.Duration
+	// DeadlineEscalation возвращает действие при превышении дедлайна
+	DeadlineEscalation() DeadlineEscalation
+}
+
+// EscalationHandler обработчик превышения дедлайна шага (например, уведомление дежурных).
+// Вызывается синхронно из таймера дедлайна; паника обработчика перехватывается.
+type EscalationHandler func(ctx context.Context, event *StepDeadlineExceededEvent)
+
+// stepDeadline возвращает дедлайн шага и действие по его истечении, если шаг их задает
+func stepDeadline(step SagaStep) (time.Duration, DeadlineEscalation) {
+	if withDeadline, ok := stepAs[DeadlineStep](step); ok {
+		escalation := withDeadline.DeadlineEscalation()
+		if escalation == "" {
+			escalation = EscalateNotify
+		}
+		return withDeadline.Deadline(), escalation
+	}
+	return 0, EscalateNotify
+}
+
+// WithEscalationHandler добавляет обработчик превышения дедлайнов шагов.
+// Обработчики вызываются по порядку добавления для саг, запущенных оркестратором.
+func (o *DefaultOrchestrator) WithEscalationHandler(handler EscalationHandler) *DefaultOrchestrator {
+	o.escalationHandlers = append(o.escalationHandlers, handler)
+	return o
+}
+
+// watchDeadline запускает таймер дедлайна шага. Возвращает контекст выполнения шага
+// (отменяется с причиной ErrStepDeadlineExceeded при EscalateCompensate) и функцию
+// остановки таймера, которую нужно вызвать по завершении шага.
+func (s *BaseSaga) watchDeadline(ctx context.Context, step SagaStep, settings StepSettings) (context.Context, func()) {
+	if settings.Deadline <= 0 {
+		return ctx, func() {}
+	}
+
+	deadlineCtx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(settings.Deadline, func() {
+		s.escalateDeadline(context.WithoutCancel(ctx), step, settings)
+		if settings.Escalation == EscalateCompensate {
+			cancel(fmt.Errorf("%w: step %s did not complete within %s", ErrStepDeadlineExceeded, step.Name(), settings.Deadline))
+		}
+	})
+
+	stop := func() {
+		timer.Stop()
+		cancel(nil)
+	}
+	return deadlineCtx, stop
+}
+
+// deadlineCause возвращает причину отмены шага по дедлайну (nil - дедлайн не отменял шаг)
+func deadlineCause(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, ErrStepDeadlineExceeded) {
+		return cause
+	}
+	return nil
+}
+
+// escalateDeadline публикует StepDeadlineExceededEvent, учитывает метрику
+// saga_step_deadline_exceeded_total и вызывает обработчики эскалации
+func (s *BaseSaga) escalateDeadline(ctx context.Context, step SagaStep, settings StepSettings) {
+	exceededEvent := &StepDeadlineExceededEvent{
+		BaseEvent:      events.NewBaseEvent("StepDeadlineExceeded", s.id),
+		SagaID:         s.id,
+		DefinitionName: s.definition.Name(),
+		StepName:       step.Name(),
+		Deadline:       settings.Deadline,
+		Escalation:     settings.Escalation,
+		Timestamp:      time.Now(),
+	}
+	exceededEvent.WithCorrelationID(s.context.CorrelationID())
+
+	s.mu.RLock()
+	eventBus := s.eventBus
+	recorder := s.recorder
+	handlers := s.escalationHandlers
+	s.mu.RUnlock()
+
+	if eventBus != nil {
+		_ = eventBus.Publish(ctx, exceededEvent)
+	}
+	if recorder != nil {
+		recorder.Counter(ctx, "saga_step_deadline_exceeded_total", 1, metrics.Labels{
+			"saga":       s.definition.Name(),
+			"step":       step.Name(),
+			"escalation": string(settings.Escalation),
+		})
+	}
+	for _, handler := range handlers {
+		handler := handler
+		_ = core.SafeCall(func() error {
+			handler(ctx, exceededEvent)
+			return nil
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrSagaDefinitionMissing код определения саги отсутствует в реестре. Сага восстановлена
+// по сохраненным метаданным определения и доступна только для чтения: статус, контекст
+// и история загружаются, а выполнение, компенсация и подтверждение шагов невозможны.
+var ErrSagaDefinitionMissing = errors.New("saga definition code is missing")
+
+// SagaDefinitionMetadata сохраненные метаданные версии определения саги
+type SagaDefinitionMetadata struct {
+	Name    string
+	Version int
+	// Steps имена шагов в порядке выполнения
+	Steps []string
+	// RegisteredAt время первого сохранения версии
+	RegisteredAt time.Time
+}
+
+// String возвращает метаданные в читаемом виде
+func (m SagaDefinitionMetadata) String() string {
+	return fmt.Sprintf("%s v%d (steps: %s, registered at %s)",
+		m.Name, m.Version, strings.Join(m.Steps, ", "), m.RegisteredAt.Format(time.RFC3339))
+}
+
+// DefinitionMetadataOf возвращает метаданные определения саги
+func DefinitionMetadataOf(definition SagaDefinition) SagaDefinitionMetadata {
+	steps := make([]string, 0, len(definition.Steps()))
+	for _, step := range definition.Steps() {
+		steps = append(steps, step.Name())
+	}
+	return SagaDefinitionMetadata{
+		Name:    definition.Name(),
+		Version: SagaDefinitionVersion(definition),
+		Steps:   steps,
+	}
+}
+
+// SagaDefinitionStore persistence, сохраняющая метаданные определений саг рядом с экземплярами.
+// Реализуется InMemoryPersistence, PostgresPersistence и MySQLPersistence.
+type SagaDefinitionStore interface {
+	// SaveDefinitionMetadata сохраняет метаданные версий определений. Для уже сохраненных
+	// версий обновляется список шагов, время регистрации сохраняется.
+	SaveDefinitionMetadata(ctx context.Context, definitions []SagaDefinitionMetadata) error
+	// ListDefinitionMetadata возвращает метаданные всех сохраненных версий
+	ListDefinitionMetadata(ctx context.Context) ([]SagaDefinitionMetadata, error)
+}
+
+// WarmUpReport результат прогрева реестра саг
+type WarmUpReport struct {
+	// Saved метаданные зарегистрированных определений, сохраненные в persistence
+	Saved []SagaDefinitionMetadata
+	// Missing сохраненные версии, код которых отсутствует в реестре.
+	// Они загружены в реестр как определения только для чтения.
+	Missing []SagaDefinitionMetadata
+}
+
+// WarmUpRegistry сверяет реестр с метаданными определений в persistence. Если persist = true,
+// метаданные зарегистрированных определений сохраняются. Сохраненные версии, которых нет
+// в реестре (код удален или не попал в сборку), регистрируются как определения только
+// для чтения: саги этих версий загружаются для запросов статуса и истории, а попытка
+// их выполнения возвращает ErrSagaDefinitionMissing с описанием сохраненной версии.
+func WarmUpRegistry(ctx context.Context, registry *SagaRegistry, persistence SagaPersistence, persist bool) (*WarmUpReport, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("registry not configured")
+	}
+	store, ok := persistence.(SagaDefinitionStore)
+	if !ok {
+		return nil, fmt.Errorf("persistence %T does not support saga definition metadata", persistence)
+	}
+
+	report := &WarmUpReport{}
+	if persist {
+		report.Saved = registeredDefinitionMetadata(registry)
+		if err := store.SaveDefinitionMetadata(ctx, report.Saved); err != nil {
+			return nil, fmt.Errorf("failed to save saga definition metadata: %w", err)
+		}
+	}
+
+	persisted, err := store.ListDefinitionMetadata(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saga definition metadata: %w", err)
+	}
+	sort.Slice(persisted, func(i, j int) bool {
+		if persisted[i].Name != persisted[j].Name {
+			return persisted[i].Name < persisted[j].Name
+		}
+		return persisted[i].Version < persisted[j].Version
+	})
+
+	for _, metadata := range persisted {
+		if registry.registerIfAbsent(metadata.Name, newMissingSagaDefinition(metadata)) {
+			report.Missing = append(report.Missing, metadata)
+		}
+	}
+	return report, nil
+}
+
+// registeredDefinitionMetadata возвращает метаданные зарегистрированных определений
+// (определения только для чтения пропускаются)
+func registeredDefinitionMetadata(registry *SagaRegistry) []SagaDefinitionMetadata {
+	names := registry.ListSagas()
+	sort.Strings(names)
+
+	var result []SagaDefinitionMetadata
+	for _, name := range names {
+		for _, version := range registry.ListVersions(name) {
+			definition, err := registry.GetSagaVersion(name, version)
+			if err != nil {
+				continue
+			}
+			if _, missing := MissingDefinitionMetadata(definition); missing {
+				continue
+			}
+			result = append(result, DefinitionMetadataOf(definition))
+		}
+	}
+	return result
+}
+
+// MissingDefinitionMetadata возвращает сохраненные метаданные, если определение загружено
+// в реестр при прогреве и его код отсутствует
+func MissingDefinitionMetadata(definition SagaDefinition) (SagaDefinitionMetadata, bool) {
+	missing, ok := definition.(*missingSagaDefinition)
+	if !ok {
+		return SagaDefinitionMetadata{}, false
+	}
+	return missing.metadata, true
+}
+
+// missingSagaDefinition определение только для чтения, восстановленное по сохраненным
+// метаданным. Шаги сохраняют имена, чтобы сага загружалась с текущим шагом и историей.
+type missingSagaDefinition struct {
+	*BaseSagaDefinition
+	metadata SagaDefinitionMetadata
+}
+
+func newMissingSagaDefinition(metadata SagaDefinitionMetadata) *missingSagaDefinition {
+	definition := &missingSagaDefinition{
+		BaseSagaDefinition: NewBaseSagaDefinition(metadata.Name).WithVersion(metadata.Version),
+		metadata:           metadata,
+	}
+	for _, name := range metadata.Steps {
+		definition.BaseSagaDefinition.AddStep(NewBaseStep(name).
+			WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+				return definition.err()
+			}).
+			WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+				return definition.err()
+			}))
+	}
+	return definition
+}
+
+// CreateInstance возвращает ErrSagaDefinitionMissing: новые саги по определению без кода не создаются
+func (d *missingSagaDefinition) CreateInstance(ctx context.Context, sagaCtx SagaContext) (Saga, error) {
+	return nil, d.err()
+}
+
+// err возвращает ErrSagaDefinitionMissing с описанием сохраненной версии
+func (d *missingSagaDefinition) err() error {
+	return fmt.Errorf("%w: %s is persisted but not registered", ErrSagaDefinitionMissing, d.metadata)
+}
+
+// checkDefinitionAvailable возвращает ErrSagaDefinitionMissing, если сага восстановлена
+// по определению без кода
+func checkDefinitionAvailable(saga Saga) error {
+	if missing, ok := saga.Definition().(*missingSagaDefinition); ok {
+		return fmt.Errorf("saga %s: %w", saga.ID(), missing.err())
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DiagramFormat формат экспорта диаграммы саги
+type DiagramFormat string
+
+const (
+	// DiagramFormatMermaid flowchart Mermaid
+	DiagramFormatMermaid DiagramFormat = "mermaid"
+	// DiagramFormatDOT граф Graphviz DOT
+	DiagramFormatDOT DiagramFormat = "dot"
+)
+
+// ErrUnsupportedDiagramFormat неизвестный формат диаграммы
+var ErrUnsupportedDiagramFormat = errors.New("unsupported diagram format")
+
+// ExportDiagram возвращает граф шагов саги в формате Mermaid или Graphviz DOT
+func (d *BaseSagaDefinition) ExportDiagram(format DiagramFormat) (string, error) {
+	return ExportDiagram(d, format)
+}
+
+// ExportDiagram возвращает граф шагов определения саги в формате Mermaid или Graphviz DOT.
+// Диаграмма включает параллельные ветки (ParallelStep), условия (ConditionalStep и
+// BaseStep.WithCondition), переходы по результату шага (BaseStep.NextStepOn)
+// и компенсации шагов (пунктирные связи).
+func ExportDiagram(definition SagaDefinition, format DiagramFormat) (string, error) {
+	var sb strings.Builder
+	if err := WriteDiagram(&sb, definition, format); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// WriteDiagram записывает граф шагов определения саги в out в формате Mermaid или Graphviz DOT
+func WriteDiagram(out io.Writer, definition SagaDefinition, format DiagramFormat) error {
+	graph := buildDiagramGraph(definition)
+
+	var text string
+	switch format {
+	case DiagramFormatMermaid:
+		text = graph.mermaid()
+	case DiagramFormatDOT:
+		text = graph.dot()
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedDiagramFormat, format)
+	}
+
+	_, err := io.WriteString(out, text)
+	return err
+}
+
+// diagramShape форма узла диаграммы
+type diagramShape int
+
+const (
+	diagramShapeTerminal diagramShape = iota
+	diagramShapeStep
+	diagramShapeDecision
+	diagramShapeFork
+	diagramShapeCompensation
+)
+
+type diagramNode struct {
+	id    string
+	label string
+	shape diagramShape
+}
+
+type diagramEdge struct {
+	from         string
+	to           string
+	label        string
+	compensation bool
+}
+
+// diagramTail выход из фрагмента графа, к которому присоединяется следующий шаг
+type diagramTail struct {
+	id    string
+	label string
+}
+
+type diagramGraph struct {
+	title             string
+	compensationOrder CompensationOrder
+	nodes             []diagramNode
+	edges             []diagramEdge
+}
+
+func buildDiagramGraph(definition SagaDefinition) *diagramGraph {
+	g := &diagramGraph{
+		title:             fmt.Sprintf("%s v%d", definition.Name(), SagaDefinitionVersion(definition)),
+		compensationOrder: SagaCompensationOrder(definition),
+	}
+
+	tails := []diagramTail{{id: g.addNode("Start", diagramShapeTerminal)}}
+	// Переходы по результату шага (NextStepOn) к шагам, которые еще не добавлены
+	branches := make(map[string][]diagramTail)
+	for _, step := range definition.Steps() {
+		tails = append(tails, branches[step.Name()]...)
+		tails = g.addBranchingStep(step, tails, branches)
+	}
+	g.connect(tails, g.addNode("End", diagramShapeTerminal))
+	return g
+}
+
+// addBranchingStep добавляет шаг определения с условием выполнения (узел условия с веткой "no"
+// в обход шага) и переходами по результату (выходы шага с подписью результата в branches)
+func (g *diagramGraph) addBranchingStep(step SagaStep, tails []diagramTail, branches map[string][]diagramTail) []diagramTail {
+	var bypass []diagramTail
+	if stepCondition(step) != nil {
+		decision := g.addNode(step.Name()+"?", diagramShapeDecision)
+		g.connect(tails, decision)
+		tails = []diagramTail{{id: decision, label: "yes"}}
+		bypass = []diagramTail{{id: decision, label: "no"}}
+	}
+	stepTails := g.addStep(step, tails)
+
+	mappings := stepNextSteps(step)
+	results := make([]string, 0, len(mappings))
+	for result := range mappings {
+		results = append(results, result)
+	}
+	sort.Strings(results)
+	for _, result := range results {
+		for _, tail := range stepTails {
+			branches[mappings[result]] = append(branches[mappings[result]], diagramTail{id: tail.id, label: result})
+		}
+	}
+	return append(stepTails, bypass...)
+}
+
+func (g *diagramGraph) addNode(label string, shape diagramShape) string {
+	id := fmt.Sprintf("n%d", len(g.nodes))
+	g.nodes = append(g.nodes, diagramNode{id: id, label: label, shape: shape})
+	return id
+}
+
+func (g *diagramGraph) connect(tails []diagramTail, to string) {
+	for _, tail := range tails {
+		g.edges = append(g.edges, diagramEdge{from: tail.id, to: to, label: tail.label})
+	}
+}
+
+// addStep добавляет шаг после tails и возвращает выходы добавленного фрагмента
+func (g *diagramGraph) addStep(step SagaStep, tails []diagramTail) []diagramTail {
+	switch s := UnwrapStep(step).(type) {
+	case *ParallelStep:
+		fork := g.addNode(s.Name(), diagramShapeFork)
+		g.connect(tails, fork)
+		var branchTails []diagramTail
+		for _, branch := range s.steps {
+			branchTails = append(branchTails, g.addStep(branch, []diagramTail{{id: fork}})...)
+		}
+		if len(branchTails) == 0 {
+			branchTails = []diagramTail{{id: fork}}
+		}
+		join := g.addNode("join "+s.Name(), diagramShapeFork)
+		g.connect(branchTails, join)
+		return []diagramTail{{id: join}}
+
+	case *ConditionalStep:
+		decision := g.addNode(s.Name()+"?", diagramShapeDecision)
+		g.connect(tails, decision)
+		result := g.addStep(s.step, []diagramTail{{id: decision, label: "yes"}})
+		return append(result, diagramTail{id: decision, label: "no"})
+	}
+
+	node := g.addNode(diagramStepLabel(step), diagramShapeStep)
+	g.connect(tails, node)
+	if label, ok := diagramCompensationLabel(step); ok {
+		compensation := g.addNode(label, diagramShapeCompensation)
+		g.edges = append(g.edges, diagramEdge{from: node, to: compensation, label: "compensate", compensation: true})
+	}
+	return []diagramTail{{id: node}}
+}
+
+// diagramStepLabel формирует подпись шага с командой, событием или триггером
+func diagramStepLabel(step SagaStep) string {
+	label := step.Name()
+	switch s := UnwrapStep(step).(type) {
+	case *CommandStep:
+		if s.forwardCommand != nil {
+			label += "\ncommand: " + s.forwardCommand.CommandName()
+		}
+	case *EventStep:
+		if s.event != nil {
+			label += "\nevent: " + s.event.EventType()
+		}
+	case *TwoPhaseCommitStep:
+		label += "\n2PC"
+	case *choreographyStep:
+		label += "\non: " + s.trigger
+	}
+	return label
+}
+
+// diagramCompensationLabel возвращает подпись компенсации шага и false, если компенсация не задана
+func diagramCompensationLabel(step SagaStep) (string, bool) {
+	switch s := UnwrapStep(step).(type) {
+	case *CommandStep:
+		if s.compensateCommand == nil {
+			return "", false
+		}
+		return "command: " + s.compensateCommand.CommandName(), true
+	case *EventStep:
+		if s.compensateEvent == nil {
+			return "", false
+		}
+		return "event: " + s.compensateEvent.EventType(), true
+	case *choreographyStep:
+		return "compensate " + s.name, s.compensation != nil
+	case interface{ baseStep() *BaseStep }:
+		return "compensate " + step.Name(), s.baseStep().compensateAction != nil
+	}
+	// Для пользовательских реализаций SagaStep наличие компенсации неизвестно
+	return "compensate " + step.Name(), true
+}
+
+func (g *diagramGraph) mermaid() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%%%% saga %s, compensation order: %s\n", g.title, g.compensationOrder)
+	sb.WriteString("flowchart TD\n")
+
+	var compensations []string
+	for _, node := range g.nodes {
+		label := mermaidEscape(node.label)
+		switch node.shape {
+		case diagramShapeTerminal:
+			fmt.Fprintf(&sb, "    %s([\"%s\"])\n", node.id, label)
+		case diagramShapeDecision:
+			fmt.Fprintf(&sb, "    %s{\"%s\"}\n", node.id, label)
+		case diagramShapeFork:
+			fmt.Fprintf(&sb, "    %s{{\"%s\"}}\n", node.id, label)
+		default:
+			fmt.Fprintf(&sb, "    %s[\"%s\"]\n", node.id, label)
+		}
+		if node.shape == diagramShapeCompensation {
+			compensations = append(compensations, node.id)
+		}
+	}
+
+	for _, edge := range g.edges {
+		arrow := "-->"
+		if edge.compensation {
+			arrow = "-.->"
+		}
+		if edge.label != "" {
+			fmt.Fprintf(&sb, "    %s %s|%s| %s\n", edge.from, arrow, mermaidEscape(edge.label), edge.to)
+		} else {
+			fmt.Fprintf(&sb, "    %s %s %s\n", edge.from, arrow, edge.to)
+		}
+	}
+
+	if len(compensations) > 0 {
+		sb.WriteString("    classDef compensation stroke-dasharray: 5 5\n")
+		fmt.Fprintf(&sb, "    class %s compensation\n", strings.Join(compensations, ","))
+	}
+	return sb.String()
+}
+
+func (g *diagramGraph) dot() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph \"%s\" {\n", dotEscape(g.title))
+	fmt.Fprintf(&sb, "    label=\"%s\\ncompensation order: %s\";\n", dotEscape(g.title), g.compensationOrder)
+	sb.WriteString("    rankdir=TB;\n")
+
+	for _, node := range g.nodes {
+		attrs := "shape=box"
+		switch node.shape {
+		case diagramShapeTerminal:
+			attrs = "shape=oval"
+		case diagramShapeDecision:
+			attrs = "shape=diamond"
+		case diagramShapeFork:
+			attrs = "shape=hexagon"
+		case diagramShapeCompensation:
+			attrs = "shape=box, style=dashed"
+		}
+		fmt.Fprintf(&sb, "    %s [label=\"%s\", %s];\n", node.id, dotEscape(node.label), attrs)
+	}
+
+	for _, edge := range g.edges {
+		var attrs []string
+		if edge.label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", dotEscape(edge.label)))
+		}
+		if edge.compensation {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, "    %s -> %s [%s];\n", edge.from, edge.to, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&sb, "    %s -> %s;\n", edge.from, edge.to)
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer("\"", "#quot;", "|", "#124;", "\n", "<br/>").Replace(s)
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s)
+}
@@ -14,6 +14,8 @@ type SagaStartedEvent struct {
 	DefinitionName string
 	Timestamp     time.Time
 	CorrelationID string
+	// SLADeadline срок завершения саги по SLA определения (nil - SLA не задан)
+	SLADeadline *time.Time
 }
 
 // SagaCompletedEvent событие успешного завершения саги
@@ -31,9 +33,38 @@ type SagaFailedEvent struct {
 	SagaID    string
 	Error     string
 	FailedStep string
+	// Failure структурированная ошибка, на которой остановилась сага
+	Failure   *SagaFailure
 	Timestamp time.Time
 }
 
+// SagaSLABreachedEvent событие превышения SLA саги: экземпляр не завершился к сроку.
+// Публикуется один раз на экземпляр для мониторинга и эскалации.
+type SagaSLABreachedEvent struct {
+	*events.BaseEvent
+	SagaID         string
+	DefinitionName string
+	CurrentStep    string
+	SLA            time.Duration
+	Deadline       time.Time
+	Elapsed        time.Duration
+	Timestamp      time.Time
+}
+
+// SagaStuckEvent событие зависания саги: ни один шаг не завершился (и не было heartbeat)
+// дольше окна монитора. Публикуется StuckSagaMonitor один раз до следующего прогресса саги.
+type SagaStuckEvent struct {
+	*events.BaseEvent
+	SagaID         string
+	DefinitionName string
+	Status         SagaStatus
+	CurrentStep    string
+	LastProgressAt time.Time
+	StuckFor       time.Duration
+	Window         time.Duration
+	Timestamp      time.Time
+}
+
 // SagaCompensatingEvent событие начала компенсации саги
 type SagaCompensatingEvent struct {
 	*events.BaseEvent
@@ -50,6 +81,19 @@ type SagaCompensatedEvent struct {
 	Timestamp       time.Time
 }
 
+// SagaCompensationStuckEvent событие остановки компенсации саги: компенсация шага не удалась
+// после всех повторов, и сага ожидает ручного возобновления (DefaultOrchestrator.ResumeCompensation)
+type SagaCompensationStuckEvent struct {
+	*events.BaseEvent
+	SagaID    string
+	StepName  string
+	Error     string
+	// Failure структурированная ошибка компенсации (категория compensation)
+	Failure   *SagaFailure
+	Attempts  int
+	Timestamp time.Time
+}
+
 // StepStartedEvent событие начала выполнения шага
 type StepStartedEvent struct {
 	*events.BaseEvent
@@ -58,6 +102,28 @@ type StepStartedEvent struct {
 	Timestamp time.Time
 }
 
+// StepHeartbeatEvent событие heartbeat долгого шага (StepContext.Heartbeat)
+// с долей выполнения шага от 0 до 1
+type StepHeartbeatEvent struct {
+	*events.BaseEvent
+	SagaID    string
+	StepName  string
+	Progress  float64
+	Timestamp time.Time
+}
+
+// StepDeadlineExceededEvent событие превышения дедлайна шага (BaseStep.WithDeadline):
+// шаг выполняется (с повторами) дольше Deadline. Публикуется один раз на запуск шага.
+type StepDeadlineExceededEvent struct {
+	*events.BaseEvent
+	SagaID         string
+	DefinitionName string
+	StepName       string
+	Deadline       time.Duration
+	Escalation     DeadlineEscalation
+	Timestamp      time.Time
+}
+
 // StepCompletedEvent событие успешного завершения шага
 type StepCompletedEvent struct {
 	*events.BaseEvent
@@ -73,7 +139,11 @@ type StepFailedEvent struct {
 	SagaID      string
 	StepName    string
 	Error       string
+	// StackTrace stack trace, если шаг завершился паникой
+	StackTrace  string
 	RetryAttempt int
+	// Failure структурированная ошибка шага
+	Failure     *SagaFailure
 	Timestamp   time.Time
 }
 
@@ -93,3 +163,52 @@ type StepCompensatedEvent struct {
 	Timestamp time.Time
 }
 
+// StepCancelledEvent событие прерывания выполняющегося шага отменой саги
+type StepCancelledEvent struct {
+	*events.BaseEvent
+	SagaID    string
+	StepName  string
+	// Reason причина отмены (ErrSagaCancelled, ErrCompensationRequested)
+	Reason    string
+	Timestamp time.Time
+}
+
+// ApprovalRequestedEvent событие остановки саги на шаге ручного подтверждения
+type ApprovalRequestedEvent struct {
+	*events.BaseEvent
+	SagaID    string
+	StepName  string
+	Timestamp time.Time
+}
+
+// ApprovalDecidedEvent событие принятия решения по шагу ручного подтверждения
+type ApprovalDecidedEvent struct {
+	*events.BaseEvent
+	SagaID    string
+	StepName  string
+	Approved  bool
+	Approver  string
+	Comment   string
+	Timestamp time.Time
+}
+
+// MessageAwaitedEvent событие остановки саги на шаге ожидания сообщения от другой саги
+type MessageAwaitedEvent struct {
+	*events.BaseEvent
+	SagaID      string
+	StepName    string
+	MessageType string
+	Timestamp   time.Time
+}
+
+// SagaMessageReceivedEvent событие доставки сообщения другой саги
+type SagaMessageReceivedEvent struct {
+	*events.BaseEvent
+	SagaID      string
+	MessageID   string
+	MessageType string
+	FromSagaID  string
+	// StepName шаг ожидания, с которого продолжается сага (пусто, если сага не ожидала сообщение)
+	StepName  string
+	Timestamp time.Time
+}
@@ -0,0 +1,81 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// DefaultExportPageSize размер страницы при потоковой выгрузке саг
+const DefaultExportPageSize = 500
+
+// ExportSagas потоково выгружает список саг в CSV или NDJSON.
+// Саги читаются постранично, поэтому выгрузка не держит весь список в памяти.
+// Limit запроса ограничивает общее количество выгружаемых саг (0 - без ограничения),
+// Offset задает начальную позицию.
+func (h *SagaQueryHandler) ExportSagas(ctx context.Context, query *ListSagasQuery, out io.Writer, format transport.ExportFormat) error {
+	writer, err := transport.NewExportWriter(out, format)
+	if err != nil {
+		return err
+	}
+	return h.exportSagas(ctx, query, writer)
+}
+
+// Export выгружает результат запроса (реализация transport.QueryExporter):
+// ListSagasQuery выгружается постранично (см. ExportSagas), остальные запросы - после Handle
+func (h *SagaQueryHandler) Export(ctx context.Context, q transport.Query, w *transport.ExportWriter) error {
+	if query, ok := q.(*ListSagasQuery); ok {
+		return h.exportSagas(ctx, query, w)
+	}
+
+	result, err := h.Handle(ctx, q)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteResult(result); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// exportSagas постранично записывает саги в writer
+func (h *SagaQueryHandler) exportSagas(ctx context.Context, query *ListSagasQuery, writer *transport.ExportWriter) error {
+	if err := writer.WriteHeader(SagaSummary{}); err != nil {
+		return err
+	}
+
+	remaining := query.Limit
+	page := *query
+	for {
+		page.Limit = DefaultExportPageSize
+		if query.Limit > 0 && remaining < page.Limit {
+			page.Limit = remaining
+		}
+
+		result, err := h.handleListSagas(ctx, &page)
+		if err != nil {
+			return fmt.Errorf("failed to list sagas at offset %d: %w", page.Offset, err)
+		}
+
+		for _, summary := range result.Sagas {
+			if err := writer.WriteRow(summary); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+
+		page.Offset += len(result.Sagas)
+		remaining -= len(result.Sagas)
+		if len(result.Sagas) < page.Limit || page.Offset >= result.Total || (query.Limit > 0 && remaining <= 0) {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
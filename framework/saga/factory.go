@@ -4,6 +4,8 @@ package saga
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/akriventsev/potter/framework/events"
 	"github.com/akriventsev/potter/framework/eventsourcing"
@@ -147,36 +149,161 @@ func (f *StepFactory) NewConditionalStep(
 	return NewConditionalStep(name, condition, step)
 }
 
-// SagaRegistry реестр для регистрации saga definitions
+// DefaultSagaDefinitionVersion версия определений саг, для которых версия не задана явно
+const DefaultSagaDefinitionVersion = 1
+
+// VersionedSagaDefinition определение саги с версией.
+// Определения, не реализующие интерфейс, считаются версией DefaultSagaDefinitionVersion.
+type VersionedSagaDefinition interface {
+	SagaDefinition
+	// Version возвращает версию определения саги
+	Version() int
+}
+
+// SagaDefinitionVersion возвращает версию определения саги
+func SagaDefinitionVersion(definition SagaDefinition) int {
+	if versioned, ok := definition.(VersionedSagaDefinition); ok && versioned.Version() > 0 {
+		return versioned.Version()
+	}
+	return DefaultSagaDefinitionVersion
+}
+
+// SagaRegistry реестр для регистрации saga definitions.
+// Под одним именем может быть зарегистрировано несколько версий определения:
+// новые экземпляры создаются по последней версии, а саги, восстановленные из persistence,
+// продолжают выполняться по версии, с которой были запущены.
+// Регистрация безопасна во время работы (hot-reload).
 type SagaRegistry struct {
-	definitions map[string]SagaDefinition
+	mu          sync.RWMutex
+	definitions map[string]map[int]SagaDefinition
 }
 
 // NewSagaRegistry создает новый реестр саг
 func NewSagaRegistry() *SagaRegistry {
 	return &SagaRegistry{
-		definitions: make(map[string]SagaDefinition),
+		definitions: make(map[string]map[int]SagaDefinition),
 	}
 }
 
-// RegisterSaga регистрирует saga definition
+// RegisterSaga регистрирует saga definition.
+// Повторная регистрация той же версии заменяет определение.
 func (r *SagaRegistry) RegisterSaga(name string, definition SagaDefinition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if r.definitions == nil {
-		r.definitions = make(map[string]SagaDefinition)
+		r.definitions = make(map[string]map[int]SagaDefinition)
+	}
+	if r.definitions[name] == nil {
+		r.definitions[name] = make(map[int]SagaDefinition)
 	}
-	r.definitions[name] = definition
+	r.definitions[name][SagaDefinitionVersion(definition)] = definition
 	return nil
 }
 
-// GetSaga получает definition по имени
+// registerIfAbsent регистрирует definition, если версия еще не зарегистрирована
+func (r *SagaRegistry) registerIfAbsent(name string, definition SagaDefinition) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	version := SagaDefinitionVersion(definition)
+	if _, exists := r.definitions[name][version]; exists {
+		return false
+	}
+	if r.definitions == nil {
+		r.definitions = make(map[string]map[int]SagaDefinition)
+	}
+	if r.definitions[name] == nil {
+		r.definitions[name] = make(map[int]SagaDefinition)
+	}
+	r.definitions[name][version] = definition
+	return true
+}
+
+// UnregisterSagaVersion удаляет версию определения (например, после завершения всех саг этой версии)
+func (r *SagaRegistry) UnregisterSagaVersion(name string, version int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.definitions[name], version)
+	if len(r.definitions[name]) == 0 {
+		delete(r.definitions, name)
+	}
+}
+
+// GetSaga получает последнюю версию definition по имени
+// (версии без кода, загруженные WarmUpRegistry, не выбираются при наличии других)
 func (r *SagaRegistry) GetSaga(name string) (SagaDefinition, error) {
-	definition, exists := r.definitions[name]
-	if !exists {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, exists := r.definitions[name]
+	if !exists || len(versions) == 0 {
+		return nil, fmt.Errorf("saga definition %s not found", name)
+	}
+	return versions[latestVersion(versions)], nil
+}
+
+// GetSagaVersion получает definition по имени и версии.
+// Версия 0 (сага сохранена до появления версионирования) означает версию
+// DefaultSagaDefinitionVersion, а если она не зарегистрирована - последнюю версию.
+func (r *SagaRegistry) GetSagaVersion(name string, version int) (SagaDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, exists := r.definitions[name]
+	if !exists || len(versions) == 0 {
 		return nil, fmt.Errorf("saga definition %s not found", name)
 	}
+
+	if version <= 0 {
+		if definition, ok := versions[DefaultSagaDefinitionVersion]; ok {
+			return definition, nil
+		}
+		return versions[latestVersion(versions)], nil
+	}
+
+	definition, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("saga definition %s version %d not found", name, version)
+	}
 	return definition, nil
 }
 
+// ListVersions возвращает зарегистрированные версии definition в порядке возрастания
+func (r *SagaRegistry) ListVersions(name string) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := make([]int, 0, len(r.definitions[name]))
+	for version := range r.definitions[name] {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// latestVersion возвращает максимальную версию. Версии, загруженные при прогреве
+// без кода (WarmUpRegistry), выбираются, только если других версий нет.
+func latestVersion(versions map[int]SagaDefinition) int {
+	latest, latestMissing := 0, 0
+	for version, definition := range versions {
+		if _, missing := definition.(*missingSagaDefinition); missing {
+			if version > latestMissing {
+				latestMissing = version
+			}
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	if latest == 0 {
+		return latestMissing
+	}
+	return latest
+}
+
 // CreateInstance создает instance саги
 func (r *SagaRegistry) CreateInstance(ctx context.Context, name string, sagaCtx SagaContext) (Saga, error) {
 	return r.CreateInstanceWithPersistence(ctx, name, sagaCtx, nil)
@@ -196,6 +323,9 @@ func (r *SagaRegistry) CreateInstanceWithPersistence(ctx context.Context, name s
 
 // ListSagas возвращает список всех зарегистрированных саг
 func (r *SagaRegistry) ListSagas() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	names := make([]string, 0, len(r.definitions))
 	for name := range r.definitions {
 		names = append(names, name)
@@ -0,0 +1,254 @@
+// Package saga предоставляет структурированную классификацию ошибок шагов саги.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/akriventsev/potter/framework/core"
+)
+
+// FailureCategory категория ошибки шага саги
+type FailureCategory string
+
+const (
+	// FailureCategoryBusiness ошибка бизнес-правила (отказ в оплате, нет товара); повтор не поможет
+	FailureCategoryBusiness FailureCategory = "business"
+	// FailureCategoryTechnical техническая ошибка (сеть, недоступность сервиса, паника)
+	FailureCategoryTechnical FailureCategory = "technical"
+	// FailureCategoryTimeout превышен таймаут шага
+	FailureCategoryTimeout FailureCategory = "timeout"
+	// FailureCategoryCompensation не удалась компенсация шага
+	FailureCategoryCompensation FailureCategory = "compensation"
+)
+
+// Коды ошибок, которые назначает классификация встроенных ошибок
+const (
+	FailureCodeUnknown          = "UNKNOWN"
+	FailureCodeDeadlineExceeded = "DEADLINE_EXCEEDED"
+	FailureCodeCanceled         = "CANCELED"
+	FailureCodeGuardRejected    = "GUARD_REJECTED"
+	FailureCodeApprovalRejected = "APPROVAL_REJECTED"
+	FailureCodeHeartbeatTimeout = "HEARTBEAT_TIMEOUT"
+	FailureCodeStepDeadline     = "STEP_DEADLINE"
+)
+
+// SagaFailure структурированная запись об ошибке шага: сохраняется в истории саги
+// и read model, позволяя агрегировать причины ошибок по категориям и кодам
+type SagaFailure struct {
+	Category  FailureCategory        `json:"category" bson:"category"`
+	Code      string                 `json:"code" bson:"code"`
+	Message   string                 `json:"message" bson:"message"`
+	Retryable bool                   `json:"retryable" bson:"retryable"`
+	Details   map[string]interface{} `json:"details,omitempty" bson:"details,omitempty"`
+}
+
+// StepError ошибка шага с классификацией. Шаги возвращают ее, чтобы задать категорию,
+// код и детали ошибки; ошибка с Retryable = false не повторяется политикой повторов.
+type StepError struct {
+	Category  FailureCategory
+	Code      string
+	Retryable bool
+	Details   map[string]interface{}
+	Err       error
+}
+
+// NewBusinessError создает неповторяемую ошибку бизнес-правила
+func NewBusinessError(code string, err error) *StepError {
+	return &StepError{Category: FailureCategoryBusiness, Code: code, Err: err}
+}
+
+// NewTechnicalError создает повторяемую техническую ошибку
+func NewTechnicalError(code string, err error) *StepError {
+	return &StepError{Category: FailureCategoryTechnical, Code: code, Retryable: true, Err: err}
+}
+
+// Error реализует интерфейс error
+func (e *StepError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("[%s] %s", e.Category, e.Code)
+	}
+	return fmt.Sprintf("[%s] %s: %v", e.Category, e.Code, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// WithRetryable задает, имеет ли смысл повторять шаг
+func (e *StepError) WithRetryable(retryable bool) *StepError {
+	e.Retryable = retryable
+	return e
+}
+
+// WithDetail добавляет деталь ошибки
+func (e *StepError) WithDetail(key string, value interface{}) *StepError {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// ClassifyFailure строит SagaFailure по ошибке шага.
+// StepError в цепочке задает категорию явно; для остальных ошибок:
+// context.DeadlineExceeded, ErrStepHeartbeatTimeout и ErrStepDeadlineExceeded - timeout, отклонение подтверждения - business,
+// прочие (включая паники и core.FrameworkError) - technical.
+func ClassifyFailure(err error) *SagaFailure {
+	if err == nil {
+		return nil
+	}
+
+	failure := &SagaFailure{
+		Category:  FailureCategoryTechnical,
+		Code:      FailureCodeUnknown,
+		Message:   err.Error(),
+		Retryable: true,
+	}
+
+	var stepErr *StepError
+	var frameworkErr *core.FrameworkError
+	switch {
+	case errors.As(err, &stepErr):
+		failure.Category = stepErr.Category
+		failure.Code = stepErr.Code
+		failure.Retryable = stepErr.Retryable
+		if len(stepErr.Details) > 0 {
+			failure.Details = make(map[string]interface{}, len(stepErr.Details))
+			for k, v := range stepErr.Details {
+				failure.Details[k] = v
+			}
+		}
+	case errors.Is(err, ErrStepDeadlineExceeded):
+		failure.Category = FailureCategoryTimeout
+		failure.Code = FailureCodeStepDeadline
+		failure.Retryable = false
+	case errors.Is(err, ErrStepHeartbeatTimeout):
+		failure.Category = FailureCategoryTimeout
+		failure.Code = FailureCodeHeartbeatTimeout
+	case errors.Is(err, context.DeadlineExceeded):
+		failure.Category = FailureCategoryTimeout
+		failure.Code = FailureCodeDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		failure.Code = FailureCodeCanceled
+		failure.Retryable = false
+	case errors.Is(err, ErrApprovalRejected):
+		failure.Category = FailureCategoryBusiness
+		failure.Code = FailureCodeApprovalRejected
+		failure.Retryable = false
+	case core.IsPanic(err):
+		failure.Code = core.ErrPanicRecovered
+		failure.Retryable = false
+	case errors.As(err, &frameworkErr):
+		failure.Code = frameworkErr.Code
+	}
+
+	return failure
+}
+
+// classifyCompensationFailure строит SagaFailure по ошибке компенсации шага.
+// Исходная категория сохраняется в Details["cause_category"].
+func classifyCompensationFailure(err error) *SagaFailure {
+	failure := ClassifyFailure(err)
+	if failure == nil {
+		return nil
+	}
+	if failure.Details == nil {
+		failure.Details = make(map[string]interface{})
+	}
+	failure.Details["cause_category"] = string(failure.Category)
+	failure.Category = FailureCategoryCompensation
+	// Компенсацию можно возобновить вручную (ResumeCompensation)
+	failure.Retryable = true
+	return failure
+}
+
+// guardFailure возвращает SagaFailure для шага, не прошедшего guard
+func guardFailure(err error) *SagaFailure {
+	return &SagaFailure{
+		Category: FailureCategoryBusiness,
+		Code:     FailureCodeGuardRejected,
+		Message:  err.Error(),
+	}
+}
+
+// isNonRetryable сообщает, что ошибка явно помечена как неповторяемая (StepError)
+func isNonRetryable(err error) bool {
+	var stepErr *StepError
+	return errors.As(err, &stepErr) && !stepErr.Retryable
+}
+
+// lastFailure возвращает последнюю структурированную ошибку из истории саги
+func lastFailure(history []SagaHistory) *SagaFailure {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Failure != nil {
+			return history[i].Failure
+		}
+	}
+	return nil
+}
+
+// failureFromValue восстанавливает SagaFailure из JSON-представления
+// (map из метаданных событий, JSONB, []byte или string)
+func failureFromValue(value interface{}) *SagaFailure {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *SagaFailure:
+		return v
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		data = encoded
+	}
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	var failure SagaFailure
+	if err := json.Unmarshal(data, &failure); err != nil || failure.Category == "" {
+		return nil
+	}
+	return &failure
+}
+
+// addFailure учитывает ошибку неуспешной саги в агрегатах по категориям и кодам
+func (r *SagaMetricsResponse) addFailure(failure *SagaFailure) {
+	if failure == nil {
+		return
+	}
+	r.addFailureCount(failure.Category, failure.Code, 1)
+}
+
+// addFailureCount добавляет count неуспешных саг с категорией и кодом ошибки
+func (r *SagaMetricsResponse) addFailureCount(category FailureCategory, code string, count int) {
+	if r.FailuresByCategory == nil {
+		r.FailuresByCategory = make(map[FailureCategory]int)
+	}
+	if r.FailuresByCode == nil {
+		r.FailuresByCode = make(map[string]int)
+	}
+	r.FailuresByCategory[category] += count
+	if code != "" {
+		r.FailuresByCode[code] += count
+	}
+}
+
+// eventFailure извлекает SagaFailure из JSON-представления события:
+// из метаданных (failure) или из данных события (Failure)
+func eventFailure(eventData map[string]interface{}) *SagaFailure {
+	if failure := failureFromValue(eventData["failure"]); failure != nil {
+		return failure
+	}
+	return failureFromValue(eventData["Failure"])
+}
@@ -0,0 +1,86 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// ErrStepHeartbeatTimeout шаг не сообщил о прогрессе (StepContext.Heartbeat) дольше
+// HeartbeatTimeout шага. Классифицируется как ошибка категории timeout и повторяется
+// по retry policy шага.
+var ErrStepHeartbeatTimeout = errors.New("step heartbeat timeout")
+
+// HeartbeatStep реализуется шагами с контролем heartbeat (см. BaseStep.WithHeartbeatTimeout)
+type HeartbeatStep interface {
+	// HeartbeatTimeout возвращает максимальный интервал между heartbeat шага (0 - не отслеживается)
+	HeartbeatTimeout() time.Duration
+}
+
+// stepHeartbeatTimeout возвращает интервал heartbeat шага, если шаг его задает
+func stepHeartbeatTimeout(step SagaStep) time.Duration {
+	if hb, ok := stepAs[HeartbeatStep](step); ok {
+		return hb.HeartbeatTimeout()
+	}
+	return 0
+}
+
+// watchHeartbeat отменяет контекст попытки шага с причиной ErrStepHeartbeatTimeout, если
+// между heartbeat (и от начала попытки) прошло больше timeout. Возвращает контекст попытки,
+// обработчик heartbeat, продлевающий окно и вызывающий onBeat, и функцию остановки контроля.
+func watchHeartbeat(ctx context.Context, timeout time.Duration, onBeat func(progress float64)) (context.Context, func(progress float64), func()) {
+	watchCtx, cancel := context.WithCancelCause(ctx)
+	beats := make(chan struct{}, 1)
+	done := make(chan struct{})
+
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		for {
+			select {
+			case <-beats:
+				timer.Reset(timeout)
+			case <-timer.C:
+				cancel(fmt.Errorf("%w: no heartbeat within %s", ErrStepHeartbeatTimeout, timeout))
+				return
+			case <-done:
+				return
+			case <-watchCtx.Done():
+				return
+			}
+		}
+	}()
+
+	beat := func(progress float64) {
+		select {
+		case beats <- struct{}{}:
+		default:
+		}
+		onBeat(progress)
+	}
+	stop := func() {
+		close(done)
+		cancel(nil)
+	}
+	return watchCtx, beat, stop
+}
+
+// publishStepHeartbeat публикует heartbeat шага для read model
+func (s *BaseSaga) publishStepHeartbeat(ctx context.Context, stepName string, progress float64) {
+	if s.eventBus == nil {
+		return
+	}
+
+	heartbeatEvent := &StepHeartbeatEvent{
+		BaseEvent: events.NewBaseEvent("StepHeartbeat", s.id),
+		SagaID:    s.id,
+		StepName:  stepName,
+		Progress:  progress,
+		Timestamp: time.Now(),
+	}
+	heartbeatEvent.WithCorrelationID(s.context.CorrelationID())
+	_ = s.eventBus.Publish(ctx, heartbeatEvent)
+}
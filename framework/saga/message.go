@@ -0,0 +1,445 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrMessagePending возвращается WaitForMessageStep, пока сообщение ожидаемого типа не получено
+	ErrMessagePending = errors.New("saga step is waiting for message")
+	// ErrSagaAwaitingMessage возвращается BaseSaga.Execute, когда сага остановилась
+	// в статусе SagaStatusWaitingMessage до доставки сообщения (DefaultOrchestrator.SendMessage)
+	ErrSagaAwaitingMessage = errors.New("saga is waiting for message")
+	// ErrSagaMessageTargetBusy сага-получатель выполняется в оркестраторе; сообщение можно
+	// отправить повторно после остановки саги (например, политикой повторов шага-отправителя)
+	ErrSagaMessageTargetBusy = errors.New("saga message target is executing")
+	// ErrSagaMessageUndeliverable сага-получатель завершена или компенсируется
+	ErrSagaMessageUndeliverable = errors.New("saga message cannot be delivered")
+)
+
+// Ключи сообщений в контексте саги: почтовый ящик недоставленных шагам сообщений
+// и сообщение, полученное шагом ожидания (в пространстве имен шага)
+const (
+	sagaMessagesKey    = "saga_messages"
+	receivedMessageKey = "message"
+)
+
+// SagaMessage типизированное сообщение от одной саги другой (например, сага доставки
+// уведомляет сагу биллинга об отгрузке). Сообщение сохраняется в контексте саги-получателя
+// и передается шагу WaitForMessageStep с тем же типом.
+type SagaMessage struct {
+	// ID идентификатор сообщения; повторная отправка с тем же ID не доставляет сообщение дважды.
+	// Пустой ID генерируется при отправке.
+	ID string
+	// Type тип сообщения, по которому его ожидает WaitForMessageStep
+	Type string
+	// FromSagaID ID саги-отправителя
+	FromSagaID string
+	// Payload данные сообщения (сохраняются вместе с контекстом саги, должны сериализоваться в JSON)
+	Payload map[string]interface{}
+	// SentAt время отправки; пустое время заполняется при отправке
+	SentAt time.Time
+}
+
+// SagaMessageTarget адресат сообщения: конкретная сага или все незавершенные саги
+// с correlation ID (кроме саги-отправителя)
+type SagaMessageTarget struct {
+	SagaID        string
+	CorrelationID string
+}
+
+// ToSaga адресует сообщение саге по ID
+func ToSaga(sagaID string) SagaMessageTarget {
+	return SagaMessageTarget{SagaID: sagaID}
+}
+
+// ToCorrelation адресует сообщение сагам с correlation ID
+func ToCorrelation(correlationID string) SagaMessageTarget {
+	return SagaMessageTarget{CorrelationID: correlationID}
+}
+
+// String возвращает адресат для сообщений об ошибках
+func (t SagaMessageTarget) String() string {
+	if t.SagaID != "" {
+		return t.SagaID
+	}
+	return "correlation:" + t.CorrelationID
+}
+
+// WaitForMessageStep шаг, приостанавливающий сагу до получения сообщения от другой саги.
+//
+// Если сообщение типа уже доставлено (отправитель опередил получателя), шаг завершается сразу.
+// Иначе сага сохраняется в статусе SagaStatusWaitingMessage и публикует MessageAwaited;
+// выполнение продолжается при доставке сообщения через DefaultOrchestrator.SendMessage.
+// Полученное сообщение доступно через ReceivedMessageOf.
+type WaitForMessageStep struct {
+	*BaseStep
+	messageType string
+}
+
+// NewWaitForMessageStep создает шаг ожидания сообщения типа messageType
+func NewWaitForMessageStep(name, messageType string) *WaitForMessageStep {
+	step := &WaitForMessageStep{BaseStep: NewBaseStep(name), messageType: messageType}
+
+	step.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		if _, ok := ReceivedMessageOf(sagaCtx, name); ok {
+			return nil
+		}
+		message, ok := takeSagaMessage(sagaCtx, messageType)
+		if !ok {
+			return ErrMessagePending
+		}
+		if err := sagaCtx.ForStep(name).Set(receivedMessageKey, sagaMessageToMap(message)); err != nil {
+			return fmt.Errorf("failed to store message %s for step %s: %w", message.ID, name, err)
+		}
+		return nil
+	})
+
+	// Получение сообщения не меняет внешнего состояния - компенсировать нечего
+	step.WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+		return nil
+	})
+
+	return step
+}
+
+// MessageType возвращает тип ожидаемого сообщения
+func (s *WaitForMessageStep) MessageType() string {
+	return s.messageType
+}
+
+// ReceivedMessageOf возвращает сообщение, полученное шагом ожидания
+func ReceivedMessageOf(sagaCtx SagaContext, stepName string) (SagaMessage, bool) {
+	raw, ok := sagaCtx.ForStep(stepName).Get(receivedMessageKey).(map[string]interface{})
+	if !ok {
+		return SagaMessage{}, false
+	}
+	return sagaMessageFromMap(raw), true
+}
+
+// PendingMessages возвращает доставленные саге сообщения, еще не полученные шагами ожидания
+func PendingMessages(sagaCtx SagaContext) []SagaMessage {
+	raw := sagaMessageMaps(sagaCtx)
+	result := make([]SagaMessage, 0, len(raw))
+	for _, m := range raw {
+		result = append(result, sagaMessageFromMap(m))
+	}
+	return result
+}
+
+// sagaMessageMaps возвращает почтовый ящик саги. После загрузки из persistence
+// сообщения представлены как []interface{} с map[string]interface{}.
+func sagaMessageMaps(sagaCtx SagaContext) []map[string]interface{} {
+	var result []map[string]interface{}
+	switch mailbox := sagaCtx.Get(sagaMessagesKey).(type) {
+	case []map[string]interface{}:
+		result = append(result, mailbox...)
+	case []interface{}:
+		for _, item := range mailbox {
+			if m, ok := item.(map[string]interface{}); ok {
+				result = append(result, m)
+			}
+		}
+	}
+	return result
+}
+
+// setSagaMessageMaps сохраняет почтовый ящик саги
+func setSagaMessageMaps(sagaCtx SagaContext, mailbox []map[string]interface{}) {
+	items := make([]interface{}, 0, len(mailbox))
+	for _, m := range mailbox {
+		items = append(items, m)
+	}
+	sagaCtx.Set(sagaMessagesKey, items)
+}
+
+// takeSagaMessage извлекает из почтового ящика первое сообщение типа
+func takeSagaMessage(sagaCtx SagaContext, messageType string) (SagaMessage, bool) {
+	mailbox := sagaMessageMaps(sagaCtx)
+	for i, m := range mailbox {
+		if t, _ := m["type"].(string); t == messageType {
+			setSagaMessageMaps(sagaCtx, append(mailbox[:i:i], mailbox[i+1:]...))
+			return sagaMessageFromMap(m), true
+		}
+	}
+	return SagaMessage{}, false
+}
+
+// hasSagaMessage проверяет, доставлено ли сообщение саге (в почтовый ящик или шагу ожидания)
+func hasSagaMessage(sagaCtx SagaContext, messageID string) bool {
+	for _, m := range sagaMessageMaps(sagaCtx) {
+		if id, _ := m["id"].(string); id == messageID {
+			return true
+		}
+	}
+	for _, data := range StepContextData(sagaCtx) {
+		if m, ok := data[receivedMessageKey].(map[string]interface{}); ok {
+			if id, _ := m["id"].(string); id == messageID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sagaMessageToMap представляет сообщение значениями, сохраняемыми в JSON без потерь
+func sagaMessageToMap(message SagaMessage) map[string]interface{} {
+	payload := message.Payload
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"id":           message.ID,
+		"type":         message.Type,
+		"from_saga_id": message.FromSagaID,
+		"payload":      payload,
+		"sent_at":      message.SentAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// sagaMessageFromMap восстанавливает сообщение из контекста саги
+func sagaMessageFromMap(m map[string]interface{}) SagaMessage {
+	message := SagaMessage{}
+	message.ID, _ = m["id"].(string)
+	message.Type, _ = m["type"].(string)
+	message.FromSagaID, _ = m["from_saga_id"].(string)
+	message.Payload, _ = m["payload"].(map[string]interface{})
+	if sentAt, ok := m["sent_at"].(string); ok {
+		message.SentAt, _ = time.Parse(time.RFC3339Nano, sentAt)
+	}
+	return message
+}
+
+// DeliverMessage добавляет сообщение в почтовый ящик саги и публикует SagaMessageReceived.
+// Если сага ожидает сообщение этого типа на текущем шаге, выполнение продолжается с этого
+// шага (resumed = true, err - результат выполнения); иначе сообщение получит следующий шаг
+// ожидания. Повторная доставка сообщения с тем же ID не добавляет его в почтовый ящик.
+func (s *BaseSaga) DeliverMessage(ctx context.Context, message SagaMessage) (bool, error) {
+	if !hasSagaMessage(s.context, message.ID) {
+		mailbox := sagaMessageMaps(s.context)
+		setSagaMessageMaps(s.context, append(mailbox, sagaMessageToMap(message)))
+	}
+
+	stepIndex := s.messageStepIndex(message.Type)
+	now := time.Now()
+
+	if s.eventBus != nil {
+		receivedEvent := &SagaMessageReceivedEvent{
+			BaseEvent:   events.NewBaseEvent("SagaMessageReceived", s.id),
+			SagaID:      s.id,
+			MessageID:   message.ID,
+			MessageType: message.Type,
+			FromSagaID:  message.FromSagaID,
+			Timestamp:   now,
+		}
+		if stepIndex >= 0 {
+			receivedEvent.StepName = s.definition.Steps()[stepIndex].Name()
+		}
+		receivedEvent.WithCorrelationID(s.context.CorrelationID())
+		_ = s.eventBus.Publish(ctx, receivedEvent)
+	}
+
+	if stepIndex < 0 {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	s.status = SagaStatusRunning
+	s.mu.Unlock()
+
+	if ctxImpl, ok := s.context.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.UpdatedAt = now
+		ctxImpl.mu.Unlock()
+	}
+
+	return true, s.runSteps(ctx, stepIndex)
+}
+
+// messageStepIndex возвращает индекс текущего шага, если сага ожидает на нем сообщение типа, иначе -1
+func (s *BaseSaga) messageStepIndex(messageType string) int {
+	s.mu.RLock()
+	status, currentStep := s.status, s.currentStep
+	s.mu.RUnlock()
+	if status != SagaStatusWaitingMessage {
+		return -1
+	}
+
+	for i, step := range s.definition.Steps() {
+		if step.Name() != currentStep {
+			continue
+		}
+		if awaiting, ok := stepAs[interface{ MessageType() string }](step); ok && awaiting.MessageType() == messageType {
+			return i
+		}
+		return -1
+	}
+	return -1
+}
+
+// awaitMessage останавливает сагу на шаге ожидания сообщения: сохраняет статус
+// SagaStatusWaitingMessage и публикует MessageAwaited
+func (s *BaseSaga) awaitMessage(ctx context.Context, step SagaStep, historyEntry SagaHistory) error {
+	awaitedAt := time.Now()
+	historyEntry.Status = StepStatusWaitingMessage
+	s.updateHistory(historyEntry)
+
+	s.mu.Lock()
+	s.status = SagaStatusWaitingMessage
+	s.mu.Unlock()
+
+	if ctxImpl, ok := s.context.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.UpdatedAt = awaitedAt
+		ctxImpl.mu.Unlock()
+	}
+
+	if s.persistence != nil {
+		if err := s.persistence.Save(ctx, s); err != nil {
+			return fmt.Errorf("failed to save saga state at message step %s: %w", step.Name(), err)
+		}
+	}
+
+	if s.eventBus != nil {
+		awaitedEvent := &MessageAwaitedEvent{
+			BaseEvent: events.NewBaseEvent("MessageAwaited", s.id),
+			SagaID:    s.id,
+			StepName:  step.Name(),
+			Timestamp: awaitedAt,
+		}
+		if awaiting, ok := stepAs[interface{ MessageType() string }](step); ok {
+			awaitedEvent.MessageType = awaiting.MessageType()
+		}
+		awaitedEvent.WithCorrelationID(s.context.CorrelationID())
+		_ = s.eventBus.Publish(ctx, awaitedEvent)
+	}
+
+	return ErrSagaAwaitingMessage
+}
+
+// isStepWaiting проверяет, остановлен ли шаг в ожидании внешнего решения или сообщения.
+// Ожидание не считается ни успехом, ни ошибкой шага.
+func isStepWaiting(err error) bool {
+	return errors.Is(err, ErrApprovalPending) || errors.Is(err, ErrMessagePending)
+}
+
+// messageDeliverableStatuses статусы саг, которым доставляются сообщения
+var messageDeliverableStatuses = []SagaStatus{SagaStatusPending, SagaStatusRunning, SagaStatusWaitingApproval, SagaStatusWaitingMessage}
+
+// SendMessage сохраняет сообщение в контексте саги-получателя (или всех незавершенных саг
+// с correlation ID адресата, кроме отправителя) и продолжает выполнение получателей,
+// ожидающих сообщение этого типа. Возвращает ID саг, которым сообщение доставлено.
+//
+// Саге, выполняющейся в этом оркестраторе, сообщение не доставляется (ErrSagaMessageTargetBusy):
+// ее состояние перезапишется при сохранении. Завершенным сагам сообщение не доставляется
+// (ErrSagaMessageUndeliverable); при адресации по correlation ID такие саги пропускаются.
+func (o *DefaultOrchestrator) SendMessage(ctx context.Context, target SagaMessageTarget, message SagaMessage) ([]string, error) {
+	if err := o.checkWritable("send saga message", target.String()); err != nil {
+		return nil, err
+	}
+	if o.persistence == nil {
+		return nil, fmt.Errorf("persistence not configured, cannot send saga message")
+	}
+	if message.Type == "" {
+		return nil, fmt.Errorf("saga message type is required")
+	}
+	if target.SagaID == "" && target.CorrelationID == "" {
+		return nil, fmt.Errorf("saga message target is required")
+	}
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+	if message.SentAt.IsZero() {
+		message.SentAt = time.Now()
+	}
+
+	if target.SagaID != "" {
+		saga, err := o.persistence.Load(ctx, target.SagaID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load saga %s: %w", target.SagaID, err)
+		}
+		if err := o.deliverMessage(ctx, saga, message); err != nil {
+			return nil, err
+		}
+		return []string{target.SagaID}, nil
+	}
+
+	var delivered []string
+	var errs []error
+	for _, status := range messageDeliverableStatuses {
+		sagas, err := o.persistence.LoadAll(ctx, status)
+		if err != nil {
+			return delivered, fmt.Errorf("failed to load sagas with status %s: %w", status, err)
+		}
+		for _, saga := range sagas {
+			if saga.ID() == message.FromSagaID || saga.Context().CorrelationID() != target.CorrelationID {
+				continue
+			}
+			if err := o.deliverMessage(ctx, saga, message); err != nil {
+				if !errors.Is(err, ErrSagaMessageUndeliverable) {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			delivered = append(delivered, saga.ID())
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// deliverMessage доставляет сообщение загруженной саге и сохраняет ее состояние
+func (o *DefaultOrchestrator) deliverMessage(ctx context.Context, saga Saga, message SagaMessage) error {
+	sagaID := saga.ID()
+	if err := checkDefinitionAvailable(saga); err != nil {
+		return err
+	}
+
+	deliverable := false
+	status := saga.Status()
+	for _, s := range messageDeliverableStatuses {
+		if status == s {
+			deliverable = true
+			break
+		}
+	}
+	if !deliverable {
+		return fmt.Errorf("%w: saga %s, current status: %s", ErrSagaMessageUndeliverable, sagaID, status)
+	}
+
+	receiver, ok := saga.(interface {
+		DeliverMessage(ctx context.Context, message SagaMessage) (bool, error)
+	})
+	if !ok {
+		return fmt.Errorf("saga %s does not support messages", sagaID)
+	}
+	o.attachSaga(saga)
+
+	o.mu.Lock()
+	if _, running := o.runningSagas[sagaID]; running {
+		o.mu.Unlock()
+		return fmt.Errorf("%w: saga %s", ErrSagaMessageTargetBusy, sagaID)
+	}
+	sagaCtx, run := o.trackRunning(ctx, saga)
+	o.mu.Unlock()
+	defer run.cancel(nil)
+
+	stopSLA := o.watchSLA(ctx, saga)
+	resumed, err := receiver.DeliverMessage(sagaCtx, message)
+	stopSLA()
+
+	if resumed {
+		return o.finishExecution(ctx, saga, err)
+	}
+
+	defer o.untrackRunning(sagaID)
+	if err := o.persistence.Save(ctx, saga); err != nil {
+		return fmt.Errorf("failed to save saga %s with message %s: %w", sagaID, message.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,580 @@
+// Package saga предоставляет механизмы для работы с сагами.
+package saga
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/eventsourcing"
+)
+
+// MetricsGranularity размер временного бакета материализованных метрик саг
+type MetricsGranularity string
+
+const (
+	// MetricsGranularityHour часовые бакеты
+	MetricsGranularityHour MetricsGranularity = "hour"
+	// MetricsGranularityDay суточные бакеты (UTC)
+	MetricsGranularityDay MetricsGranularity = "day"
+)
+
+// metricsGranularities гранулярности, которые поддерживает SagaMetricsProjection
+var metricsGranularities = []MetricsGranularity{MetricsGranularityHour, MetricsGranularityDay}
+
+// Truncate возвращает начало бакета, в который попадает t (в UTC)
+func (g MetricsGranularity) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	if g == MetricsGranularityDay {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// sagaDurationBounds верхние границы интервалов гистограммы длительности саг.
+// Последний интервал гистограммы - длительности больше последней границы.
+var sagaDurationBounds = []time.Duration{
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// SagaMetricsBucket агрегаты саг одного определения за временной бакет.
+// Запуски учитываются в бакете времени старта, исходы и длительности - в бакете времени завершения.
+// Длительности хранятся гистограммой, поэтому бакеты складываются без потери перцентилей.
+type SagaMetricsBucket struct {
+	DefinitionName string
+	Granularity    MetricsGranularity
+	BucketStart    time.Time
+	Started        int
+	Completed      int
+	Failed         int
+	Compensated    int
+	// DurationCount число завершенных саг с известной длительностью
+	DurationCount int
+	DurationSum   time.Duration
+	DurationMax   time.Duration
+	// DurationHistogram число саг по интервалам sagaDurationBounds (последний элемент - больше максимума)
+	DurationHistogram []int
+	// FailuresByCategory число неуспешных саг по категории ошибки
+	FailuresByCategory map[FailureCategory]int
+	// FailuresByCode число неуспешных саг по коду ошибки
+	FailuresByCode map[string]int
+	UpdatedAt      time.Time
+}
+
+// newSagaMetricsBucket создает пустой бакет
+func newSagaMetricsBucket(definitionName string, granularity MetricsGranularity, start time.Time) *SagaMetricsBucket {
+	return &SagaMetricsBucket{
+		DefinitionName:     definitionName,
+		Granularity:        granularity,
+		BucketStart:        start,
+		DurationHistogram:  make([]int, len(sagaDurationBounds)+1),
+		FailuresByCategory: make(map[FailureCategory]int),
+		FailuresByCode:     make(map[string]int),
+	}
+}
+
+// observeDuration учитывает длительность завершенной саги
+func (b *SagaMetricsBucket) observeDuration(duration time.Duration) {
+	if len(b.DurationHistogram) < len(sagaDurationBounds)+1 {
+		b.DurationHistogram = append(b.DurationHistogram, make([]int, len(sagaDurationBounds)+1-len(b.DurationHistogram))...)
+	}
+	index := sort.Search(len(sagaDurationBounds), func(i int) bool {
+		return duration <= sagaDurationBounds[i]
+	})
+	b.DurationHistogram[index]++
+	b.DurationCount++
+	b.DurationSum += duration
+	if duration > b.DurationMax {
+		b.DurationMax = duration
+	}
+}
+
+// addFailure учитывает ошибку неуспешной саги
+func (b *SagaMetricsBucket) addFailure(failure *SagaFailure) {
+	if failure == nil {
+		return
+	}
+	if b.FailuresByCategory == nil {
+		b.FailuresByCategory = make(map[FailureCategory]int)
+	}
+	if b.FailuresByCode == nil {
+		b.FailuresByCode = make(map[string]int)
+	}
+	b.FailuresByCategory[failure.Category]++
+	if failure.Code != "" {
+		b.FailuresByCode[failure.Code]++
+	}
+}
+
+// Merge прибавляет к бакету значения other (того же или другого определения)
+func (b *SagaMetricsBucket) Merge(other *SagaMetricsBucket) {
+	b.Started += other.Started
+	b.Completed += other.Completed
+	b.Failed += other.Failed
+	b.Compensated += other.Compensated
+	b.DurationCount += other.DurationCount
+	b.DurationSum += other.DurationSum
+	if other.DurationMax > b.DurationMax {
+		b.DurationMax = other.DurationMax
+	}
+	if len(b.DurationHistogram) < len(other.DurationHistogram) {
+		b.DurationHistogram = append(b.DurationHistogram, make([]int, len(other.DurationHistogram)-len(b.DurationHistogram))...)
+	}
+	for i, count := range other.DurationHistogram {
+		b.DurationHistogram[i] += count
+	}
+	if len(other.FailuresByCategory) > 0 && b.FailuresByCategory == nil {
+		b.FailuresByCategory = make(map[FailureCategory]int)
+	}
+	for category, count := range other.FailuresByCategory {
+		b.FailuresByCategory[category] += count
+	}
+	if len(other.FailuresByCode) > 0 && b.FailuresByCode == nil {
+		b.FailuresByCode = make(map[string]int)
+	}
+	for code, count := range other.FailuresByCode {
+		b.FailuresByCode[code] += count
+	}
+	if other.UpdatedAt.After(b.UpdatedAt) {
+		b.UpdatedAt = other.UpdatedAt
+	}
+}
+
+// AvgDuration возвращает среднюю длительность завершенных саг бакета
+func (b *SagaMetricsBucket) AvgDuration() time.Duration {
+	if b.DurationCount == 0 {
+		return 0
+	}
+	return b.DurationSum / time.Duration(b.DurationCount)
+}
+
+// DurationPercentile возвращает оценку перцентиля p длительности по гистограмме: верхнюю
+// границу интервала, в который попадает перцентиль (не больше максимальной длительности)
+func (b *SagaMetricsBucket) DurationPercentile(p float64) time.Duration {
+	total := 0
+	for _, count := range b.DurationHistogram {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p * float64(total)))
+	if rank < 1 {
+		rank = 1
+	}
+	cumulative := 0
+	for i, count := range b.DurationHistogram {
+		cumulative += count
+		if cumulative < rank {
+			continue
+		}
+		if i < len(sagaDurationBounds) && sagaDurationBounds[i] < b.DurationMax {
+			return sagaDurationBounds[i]
+		}
+		return b.DurationMax
+	}
+	return b.DurationMax
+}
+
+// SuccessRate возвращает долю успешно завершенных саг среди завершенных в бакете, в процентах
+func (b *SagaMetricsBucket) SuccessRate() float64 {
+	finished := b.Completed + b.Failed + b.Compensated
+	if finished == 0 {
+		return 0
+	}
+	return float64(b.Completed) / float64(finished) * 100
+}
+
+// MetricsBucketFilter фильтр бакетов материализованных метрик
+type MetricsBucketFilter struct {
+	DefinitionName *string
+	Granularity    MetricsGranularity
+	// From и To ограничивают начало бакета (включительно)
+	From *time.Time
+	To   *time.Time
+}
+
+// matches проверяет, попадает ли бакет в фильтр
+func (f MetricsBucketFilter) matches(bucket *SagaMetricsBucket) bool {
+	if f.Granularity != "" && bucket.Granularity != f.Granularity {
+		return false
+	}
+	if f.DefinitionName != nil && bucket.DefinitionName != *f.DefinitionName {
+		return false
+	}
+	if f.From != nil && bucket.BucketStart.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && bucket.BucketStart.After(*f.To) {
+		return false
+	}
+	return true
+}
+
+// SagaMetricsStore хранилище материализованных метрик саг по временным бакетам
+type SagaMetricsStore interface {
+	// AddMetrics прибавляет delta к бакету (DefinitionName, Granularity, BucketStart), создавая его при необходимости
+	AddMetrics(ctx context.Context, delta *SagaMetricsBucket) error
+	// GetMetricsBuckets возвращает бакеты, отсортированные по началу бакета и имени определения
+	GetMetricsBuckets(ctx context.Context, filter MetricsBucketFilter) ([]*SagaMetricsBucket, error)
+	// ResetMetrics удаляет все бакеты (перестроение проекции)
+	ResetMetrics(ctx context.Context) error
+}
+
+// sagaMetricsStart данные запуска саги, необходимые для учета ее исхода
+type sagaMetricsStart struct {
+	definitionName string
+	startedAt      time.Time
+}
+
+// SagaMetricsProjection проекция, поддерживающая часовые и суточные бакеты метрик саг
+// (запуски, исходы, перцентили длительности, причины ошибок) в SagaMetricsStore.
+// Дашборды читают тренды из бакетов (GetSagaMetricsTrendQuery), не сканируя read models всех саг.
+// Определение и время старта саги запоминаются по SagaStarted до ее завершения; для саг,
+// запущенных до старта процесса, они берутся из read model store (WithReadModelStore).
+type SagaMetricsProjection struct {
+	store          SagaMetricsStore
+	readModelStore SagaReadModelStore
+
+	mu      sync.Mutex
+	started map[string]sagaMetricsStart
+}
+
+// NewSagaMetricsProjection создает проекцию метрик саг
+func NewSagaMetricsProjection(store SagaMetricsStore) *SagaMetricsProjection {
+	return &SagaMetricsProjection{
+		store:   store,
+		started: make(map[string]sagaMetricsStart),
+	}
+}
+
+// WithReadModelStore задает read model store для определения и времени старта саг,
+// SagaStarted которых проекция не видела
+func (p *SagaMetricsProjection) WithReadModelStore(store SagaReadModelStore) *SagaMetricsProjection {
+	p.readModelStore = store
+	return p
+}
+
+// Name возвращает имя проекции
+func (p *SagaMetricsProjection) Name() string {
+	return "SagaMetricsProjection"
+}
+
+// HandleEvent обрабатывает событие из EventStore (реализация eventsourcing.Projection)
+func (p *SagaMetricsProjection) HandleEvent(ctx context.Context, event eventsourcing.StoredEvent) error {
+	switch event.EventType {
+	case "SagaStarted", "SagaCompleted", "SagaFailed", "SagaCompensated":
+	default:
+		return nil
+	}
+
+	eventData := storedSagaEventData(event)
+	sagaID, _ := eventData["saga_id"].(string)
+	at, ok := parseMapTime(eventData["timestamp"])
+	if !ok {
+		at = time.Now()
+	}
+
+	switch event.EventType {
+	case "SagaStarted":
+		definitionName, _ := eventData["definition_name"].(string)
+		if definitionName == "" {
+			definitionName, _ = eventData["DefinitionName"].(string)
+		}
+		return p.recordStarted(ctx, sagaID, definitionName, at)
+	case "SagaCompleted":
+		return p.recordFinished(ctx, sagaID, SagaStatusCompleted, at, mapDuration(eventData), nil)
+	case "SagaFailed":
+		return p.recordFinished(ctx, sagaID, SagaStatusFailed, at, 0, eventFailure(eventData))
+	default:
+		return p.recordFinished(ctx, sagaID, SagaStatusCompensated, at, 0, nil)
+	}
+}
+
+// Reset удаляет бакеты перед перестроением проекции
+func (p *SagaMetricsProjection) Reset(ctx context.Context) error {
+	p.mu.Lock()
+	p.started = make(map[string]sagaMetricsStart)
+	p.mu.Unlock()
+
+	if p.store == nil {
+		return nil
+	}
+	return p.store.ResetMetrics(ctx)
+}
+
+// HandleSagaStarted учитывает запуск саги
+func (p *SagaMetricsProjection) HandleSagaStarted(ctx context.Context, event *SagaStartedEvent) error {
+	return p.recordStarted(ctx, event.SagaID, event.DefinitionName, eventTime(event.Timestamp))
+}
+
+// HandleSagaCompleted учитывает успешное завершение саги
+func (p *SagaMetricsProjection) HandleSagaCompleted(ctx context.Context, event *SagaCompletedEvent) error {
+	return p.recordFinished(ctx, event.SagaID, SagaStatusCompleted, eventTime(event.Timestamp), event.Duration, nil)
+}
+
+// HandleSagaFailed учитывает неудачное завершение саги и его причину
+func (p *SagaMetricsProjection) HandleSagaFailed(ctx context.Context, event *SagaFailedEvent) error {
+	return p.recordFinished(ctx, event.SagaID, SagaStatusFailed, eventTime(event.Timestamp), 0, event.Failure)
+}
+
+// HandleSagaCompensated учитывает завершение компенсации саги
+func (p *SagaMetricsProjection) HandleSagaCompensated(ctx context.Context, event *SagaCompensatedEvent) error {
+	return p.recordFinished(ctx, event.SagaID, SagaStatusCompensated, eventTime(event.Timestamp), 0, nil)
+}
+
+// recordStarted запоминает запуск саги и учитывает его в бакетах времени старта
+func (p *SagaMetricsProjection) recordStarted(ctx context.Context, sagaID, definitionName string, at time.Time) error {
+	p.mu.Lock()
+	p.started[sagaID] = sagaMetricsStart{definitionName: definitionName, startedAt: at}
+	p.mu.Unlock()
+
+	return p.add(ctx, definitionName, at, func(bucket *SagaMetricsBucket) {
+		bucket.Started = 1
+	})
+}
+
+// recordFinished учитывает исход саги в бакетах времени завершения. Длительность, если она
+// не передана в событии, вычисляется от времени старта саги.
+func (p *SagaMetricsProjection) recordFinished(ctx context.Context, sagaID string, status SagaStatus, at time.Time, duration time.Duration, failure *SagaFailure) error {
+	start := p.takeStart(ctx, sagaID)
+	if duration <= 0 && !start.startedAt.IsZero() && at.After(start.startedAt) {
+		duration = at.Sub(start.startedAt)
+	}
+
+	return p.add(ctx, start.definitionName, at, func(bucket *SagaMetricsBucket) {
+		switch status {
+		case SagaStatusCompleted:
+			bucket.Completed = 1
+		case SagaStatusFailed:
+			bucket.Failed = 1
+			bucket.addFailure(failure)
+		case SagaStatusCompensated:
+			bucket.Compensated = 1
+		}
+		if duration > 0 {
+			bucket.observeDuration(duration)
+		}
+	})
+}
+
+// takeStart возвращает и забывает данные запуска саги; неизвестный запуск ищется в read model store
+func (p *SagaMetricsProjection) takeStart(ctx context.Context, sagaID string) sagaMetricsStart {
+	p.mu.Lock()
+	start, ok := p.started[sagaID]
+	delete(p.started, sagaID)
+	p.mu.Unlock()
+
+	if ok || p.readModelStore == nil {
+		return start
+	}
+	status, err := p.readModelStore.GetSagaStatus(ctx, sagaID)
+	if err != nil || status == nil {
+		return start
+	}
+	return sagaMetricsStart{definitionName: status.DefinitionName, startedAt: status.StartedAt}
+}
+
+// add прибавляет изменение ко всем гранулярностям бакетов момента at
+func (p *SagaMetricsProjection) add(ctx context.Context, definitionName string, at time.Time, apply func(bucket *SagaMetricsBucket)) error {
+	if p.store == nil {
+		return nil
+	}
+
+	for _, granularity := range metricsGranularities {
+		delta := newSagaMetricsBucket(definitionName, granularity, granularity.Truncate(at))
+		apply(delta)
+		delta.UpdatedAt = time.Now()
+		if err := p.store.AddMetrics(ctx, delta); err != nil {
+			return fmt.Errorf("failed to add %s saga metrics: %w", granularity, err)
+		}
+	}
+	return nil
+}
+
+// eventTime возвращает время события или текущее время, если оно не задано
+func eventTime(timestamp time.Time) time.Time {
+	if timestamp.IsZero() {
+		return time.Now()
+	}
+	return timestamp
+}
+
+// mapDuration извлекает длительность саги из JSON-представления события
+// (строка time.Duration или число наносекунд)
+func mapDuration(eventData map[string]interface{}) time.Duration {
+	for _, key := range []string{"duration", "Duration"} {
+		switch value := eventData[key].(type) {
+		case string:
+			if parsed, err := time.ParseDuration(value); err == nil {
+				return parsed
+			}
+		case float64:
+			return time.Duration(value)
+		}
+	}
+	return 0
+}
+
+// SagaMetricsSubscriber подписчик на события саги для обновления материализованных метрик
+type SagaMetricsSubscriber struct {
+	projection *SagaMetricsProjection
+}
+
+// NewSagaMetricsSubscriber создает подписчик проекции метрик саг
+func NewSagaMetricsSubscriber(projection *SagaMetricsProjection) *SagaMetricsSubscriber {
+	return &SagaMetricsSubscriber{projection: projection}
+}
+
+// EventType возвращает тип события, которое обрабатывает подписчик
+func (s *SagaMetricsSubscriber) EventType() string {
+	return "SagaEvent"
+}
+
+// Handle обрабатывает события саги
+func (s *SagaMetricsSubscriber) Handle(ctx context.Context, event events.Event) error {
+	switch e := event.(type) {
+	case *SagaStartedEvent:
+		return s.projection.HandleSagaStarted(ctx, e)
+	case *SagaCompletedEvent:
+		return s.projection.HandleSagaCompleted(ctx, e)
+	case *SagaFailedEvent:
+		return s.projection.HandleSagaFailed(ctx, e)
+	case *SagaCompensatedEvent:
+		return s.projection.HandleSagaCompensated(ctx, e)
+	default:
+		return nil
+	}
+}
+
+// EventTypes возвращает типы событий, на которые подписывается подписчик
+func (s *SagaMetricsSubscriber) EventTypes() []string {
+	return []string{"SagaStarted", "SagaCompleted", "SagaFailed", "SagaCompensated"}
+}
+
+// RegisterSagaMetricsSubscriber подписывает проекцию метрик саг на EventBus
+func RegisterSagaMetricsSubscriber(eventBus events.EventBus, projection *SagaMetricsProjection) error {
+	subscriber := NewSagaMetricsSubscriber(projection)
+	for _, eventType := range subscriber.EventTypes() {
+		if err := eventBus.Subscribe(eventType, subscriber); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// GetSagaMetricsTrendQuery запрос тренда метрик саг по материализованным бакетам
+// (требует SagaQueryHandler.WithMetricsStore)
+type GetSagaMetricsTrendQuery struct {
+	// DefinitionName ограничивает тренд одним определением; nil - бакеты всех определений суммируются
+	DefinitionName *string
+	// Granularity размер бакета (по умолчанию MetricsGranularityHour)
+	Granularity MetricsGranularity
+	From        *time.Time
+	To          *time.Time
+}
+
+func (q *GetSagaMetricsTrendQuery) QueryName() string {
+	return "GetSagaMetricsTrend"
+}
+
+// SagaMetricsTrendPoint метрики саг за один бакет тренда
+type SagaMetricsTrendPoint struct {
+	BucketStart time.Time
+	Started     int
+	Completed   int
+	Failed      int
+	Compensated int
+	// SuccessRate доля успешно завершенных среди завершенных в бакете саг, в процентах
+	SuccessRate float64
+	AvgDuration time.Duration
+	// P50Duration и P95Duration оценки перцентилей длительности по гистограмме бакета
+	P50Duration        time.Duration
+	P95Duration        time.Duration
+	FailuresByCategory map[FailureCategory]int
+	FailuresByCode     map[string]int
+}
+
+// SagaMetricsTrendResponse тренд метрик саг в хронологическом порядке бакетов
+type SagaMetricsTrendResponse struct {
+	Granularity MetricsGranularity
+	Points      []SagaMetricsTrendPoint
+}
+
+// handleGetMetricsTrend строит тренд метрик саг из бакетов SagaMetricsStore
+func (h *SagaQueryHandler) handleGetMetricsTrend(ctx context.Context, query *GetSagaMetricsTrendQuery) (*SagaMetricsTrendResponse, error) {
+	if h.metricsStore == nil {
+		return nil, fmt.Errorf("saga metrics store is not configured")
+	}
+
+	granularity := query.Granularity
+	if granularity == "" {
+		granularity = MetricsGranularityHour
+	}
+	buckets, err := h.metricsStore.GetMetricsBuckets(ctx, MetricsBucketFilter{
+		DefinitionName: query.DefinitionName,
+		Granularity:    granularity,
+		From:           query.From,
+		To:             query.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga metrics buckets: %w", err)
+	}
+
+	// Бакеты разных определений за один период суммируются
+	merged := make(map[time.Time]*SagaMetricsBucket)
+	starts := make([]time.Time, 0)
+	for _, bucket := range buckets {
+		start := bucket.BucketStart.UTC()
+		total, ok := merged[start]
+		if !ok {
+			total = newSagaMetricsBucket(bucket.DefinitionName, granularity, start)
+			merged[start] = total
+			starts = append(starts, start)
+		}
+		total.Merge(bucket)
+	}
+	sort.Slice(starts, func(i, j int) bool {
+		return starts[i].Before(starts[j])
+	})
+
+	response := &SagaMetricsTrendResponse{
+		Granularity: granularity,
+		Points:      make([]SagaMetricsTrendPoint, 0, len(starts)),
+	}
+	for _, start := range starts {
+		bucket := merged[start]
+		response.Points = append(response.Points, SagaMetricsTrendPoint{
+			BucketStart:        start,
+			Started:            bucket.Started,
+			Completed:          bucket.Completed,
+			Failed:             bucket.Failed,
+			Compensated:        bucket.Compensated,
+			SuccessRate:        bucket.SuccessRate(),
+			AvgDuration:        bucket.AvgDuration(),
+			P50Duration:        bucket.DurationPercentile(0.5),
+			P95Duration:        bucket.DurationPercentile(0.95),
+			FailuresByCategory: bucket.FailuresByCategory,
+			FailuresByCode:     bucket.FailuresByCode,
+		})
+	}
+	return response, nil
+}
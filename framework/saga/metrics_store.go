@@ -0,0 +1,271 @@
+// Package saga предоставляет механизмы для работы с сагами.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// sagaMetricsBucketKey ключ бакета метрик
+type sagaMetricsBucketKey struct {
+	definitionName string
+	granularity    MetricsGranularity
+	start          time.Time
+}
+
+// InMemorySagaMetricsStore реализация SagaMetricsStore в памяти для тестирования
+type InMemorySagaMetricsStore struct {
+	mu      sync.RWMutex
+	buckets map[sagaMetricsBucketKey]*SagaMetricsBucket
+}
+
+// NewInMemorySagaMetricsStore создает новый InMemorySagaMetricsStore
+func NewInMemorySagaMetricsStore() *InMemorySagaMetricsStore {
+	return &InMemorySagaMetricsStore{
+		buckets: make(map[sagaMetricsBucketKey]*SagaMetricsBucket),
+	}
+}
+
+// AddMetrics прибавляет delta к бакету
+func (s *InMemorySagaMetricsStore) AddMetrics(ctx context.Context, delta *SagaMetricsBucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := delta.BucketStart.UTC()
+	key := sagaMetricsBucketKey{definitionName: delta.DefinitionName, granularity: delta.Granularity, start: start}
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = newSagaMetricsBucket(delta.DefinitionName, delta.Granularity, start)
+		s.buckets[key] = bucket
+	}
+	bucket.Merge(delta)
+	return nil
+}
+
+// GetMetricsBuckets возвращает копии бакетов, попадающих в фильтр
+func (s *InMemorySagaMetricsStore) GetMetricsBuckets(ctx context.Context, filter MetricsBucketFilter) ([]*SagaMetricsBucket, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*SagaMetricsBucket, 0)
+	for _, bucket := range s.buckets {
+		if !filter.matches(bucket) {
+			continue
+		}
+		copied := newSagaMetricsBucket(bucket.DefinitionName, bucket.Granularity, bucket.BucketStart)
+		copied.Merge(bucket)
+		result = append(result, copied)
+	}
+	sortSagaMetricsBuckets(result)
+	return result, nil
+}
+
+// ResetMetrics удаляет все бакеты
+func (s *InMemorySagaMetricsStore) ResetMetrics(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets = make(map[sagaMetricsBucketKey]*SagaMetricsBucket)
+	return nil
+}
+
+// sortSagaMetricsBuckets сортирует бакеты по началу и имени определения
+func sortSagaMetricsBuckets(buckets []*SagaMetricsBucket) {
+	sort.Slice(buckets, func(i, j int) bool {
+		if !buckets[i].BucketStart.Equal(buckets[j].BucketStart) {
+			return buckets[i].BucketStart.Before(buckets[j].BucketStart)
+		}
+		return buckets[i].DefinitionName < buckets[j].DefinitionName
+	})
+}
+
+// PostgresSagaMetricsStore реализация SagaMetricsStore для PostgreSQL (таблица saga_metrics_buckets).
+// Гистограмма длительностей и причины ошибок хранятся в JSONB; бакет обновляется
+// в транзакции под блокировкой строки, поэтому проекцию можно запускать в нескольких экземплярах.
+type PostgresSagaMetricsStore struct {
+	conn *pgx.Conn
+}
+
+// NewPostgresSagaMetricsStore создает новый PostgresSagaMetricsStore
+func NewPostgresSagaMetricsStore(dsn string) (*PostgresSagaMetricsStore, error) {
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	store := &PostgresSagaMetricsStore{conn: conn}
+	if err := store.ensureTable(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure table: %w", err)
+	}
+
+	return store, nil
+}
+
+func (s *PostgresSagaMetricsStore) ensureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS saga_metrics_buckets (
+			definition_name VARCHAR(255) NOT NULL,
+			granularity VARCHAR(10) NOT NULL,
+			bucket_start TIMESTAMPTZ NOT NULL,
+			started INTEGER NOT NULL DEFAULT 0,
+			completed INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0,
+			compensated INTEGER NOT NULL DEFAULT 0,
+			duration_count INTEGER NOT NULL DEFAULT 0,
+			duration_sum_ms BIGINT NOT NULL DEFAULT 0,
+			duration_max_ms BIGINT NOT NULL DEFAULT 0,
+			duration_histogram JSONB NOT NULL DEFAULT '[]',
+			failures_by_category JSONB NOT NULL DEFAULT '{}',
+			failures_by_code JSONB NOT NULL DEFAULT '{}',
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (definition_name, granularity, bucket_start)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_saga_metrics_buckets_start ON saga_metrics_buckets(granularity, bucket_start);
+	`
+	_, err := s.conn.Exec(ctx, query)
+	return err
+}
+
+// AddMetrics прибавляет delta к бакету: строка создается при необходимости, блокируется
+// и перезаписывается суммой в одной транзакции
+func (s *PostgresSagaMetricsStore) AddMetrics(ctx context.Context, delta *SagaMetricsBucket) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	start := delta.BucketStart.UTC()
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO saga_metrics_buckets (definition_name, granularity, bucket_start)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (definition_name, granularity, bucket_start) DO NOTHING
+	`, delta.DefinitionName, string(delta.Granularity), start); err != nil {
+		return fmt.Errorf("failed to create saga metrics bucket: %w", err)
+	}
+
+	row := tx.QueryRow(ctx, postgresSagaMetricsSelect+`
+		WHERE definition_name = $1 AND granularity = $2 AND bucket_start = $3
+		FOR UPDATE
+	`, delta.DefinitionName, string(delta.Granularity), start)
+	bucket, err := scanSagaMetricsBucket(row)
+	if err != nil {
+		return fmt.Errorf("failed to lock saga metrics bucket: %w", err)
+	}
+	bucket.Merge(delta)
+
+	histogram, _ := json.Marshal(bucket.DurationHistogram)
+	byCategory, _ := json.Marshal(bucket.FailuresByCategory)
+	byCode, _ := json.Marshal(bucket.FailuresByCode)
+	if _, err := tx.Exec(ctx, `
+		UPDATE saga_metrics_buckets
+		SET started = $4, completed = $5, failed = $6, compensated = $7,
+		    duration_count = $8, duration_sum_ms = $9, duration_max_ms = $10,
+		    duration_histogram = $11, failures_by_category = $12, failures_by_code = $13,
+		    updated_at = $14
+		WHERE definition_name = $1 AND granularity = $2 AND bucket_start = $3
+	`, delta.DefinitionName, string(delta.Granularity), start,
+		bucket.Started, bucket.Completed, bucket.Failed, bucket.Compensated,
+		bucket.DurationCount, bucket.DurationSum.Milliseconds(), bucket.DurationMax.Milliseconds(),
+		histogram, byCategory, byCode, time.Now()); err != nil {
+		return fmt.Errorf("failed to update saga metrics bucket: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetMetricsBuckets возвращает бакеты, попадающие в фильтр
+func (s *PostgresSagaMetricsStore) GetMetricsBuckets(ctx context.Context, filter MetricsBucketFilter) ([]*SagaMetricsBucket, error) {
+	query := postgresSagaMetricsSelect + ` WHERE 1=1`
+	args := []interface{}{}
+	argPos := 1
+
+	if filter.Granularity != "" {
+		query += fmt.Sprintf(" AND granularity = $%d", argPos)
+		args = append(args, string(filter.Granularity))
+		argPos++
+	}
+	if filter.DefinitionName != nil {
+		query += fmt.Sprintf(" AND definition_name = $%d", argPos)
+		args = append(args, *filter.DefinitionName)
+		argPos++
+	}
+	if filter.From != nil {
+		query += fmt.Sprintf(" AND bucket_start >= $%d", argPos)
+		args = append(args, *filter.From)
+		argPos++
+	}
+	if filter.To != nil {
+		query += fmt.Sprintf(" AND bucket_start <= $%d", argPos)
+		args = append(args, *filter.To)
+	}
+	query += " ORDER BY bucket_start, definition_name"
+
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saga metrics buckets: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*SagaMetricsBucket, 0)
+	for rows.Next() {
+		bucket, err := scanSagaMetricsBucket(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saga metrics bucket: %w", err)
+		}
+		result = append(result, bucket)
+	}
+	return result, rows.Err()
+}
+
+// ResetMetrics удаляет все бакеты
+func (s *PostgresSagaMetricsStore) ResetMetrics(ctx context.Context) error {
+	_, err := s.conn.Exec(ctx, "DELETE FROM saga_metrics_buckets")
+	return err
+}
+
+// postgresSagaMetricsSelect выборка колонок бакета для scanSagaMetricsBucket
+const postgresSagaMetricsSelect = `
+	SELECT definition_name, granularity, bucket_start, started, completed, failed, compensated,
+	       duration_count, duration_sum_ms, duration_max_ms, duration_histogram,
+	       failures_by_category, failures_by_code, updated_at
+	FROM saga_metrics_buckets`
+
+// scanSagaMetricsBucket читает бакет из строки postgresSagaMetricsSelect
+func scanSagaMetricsBucket(row pgx.Row) (*SagaMetricsBucket, error) {
+	var (
+		bucket                        SagaMetricsBucket
+		granularity                   string
+		durationSumMs, durationMaxMs  int64
+		histogram, byCategory, byCode []byte
+	)
+	if err := row.Scan(
+		&bucket.DefinitionName, &granularity, &bucket.BucketStart,
+		&bucket.Started, &bucket.Completed, &bucket.Failed, &bucket.Compensated,
+		&bucket.DurationCount, &durationSumMs, &durationMaxMs,
+		&histogram, &byCategory, &byCode, &bucket.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	bucket.Granularity = MetricsGranularity(granularity)
+	bucket.BucketStart = bucket.BucketStart.UTC()
+	bucket.DurationSum = time.Duration(durationSumMs) * time.Millisecond
+	bucket.DurationMax = time.Duration(durationMaxMs) * time.Millisecond
+	if err := json.Unmarshal(histogram, &bucket.DurationHistogram); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal duration histogram: %w", err)
+	}
+	if err := json.Unmarshal(byCategory, &bucket.FailuresByCategory); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal failures by category: %w", err)
+	}
+	if err := json.Unmarshal(byCode, &bucket.FailuresByCode); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal failures by code: %w", err)
+	}
+	return &bucket, nil
+}
@@ -0,0 +1,34 @@
+package saga
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+
+	"github.com/akriventsev/potter/framework/migrations"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migrations возвращает встроенные SQL миграции пакета для диалекта (postgres, mysql)
+// для применения через migrations.RunMigrationSets при старте сервиса
+func Migrations(dialect string) (migrations.MigrationSet, error) {
+	return migrations.NewEmbeddedMigrationSet("saga", migrationFiles, "migrations", dialect)
+}
+
+// readModelMigrations версии миграций таблиц read model саг по диалектам
+var readModelMigrations = map[string][]int64{
+	"postgres": {6, 8},
+	"mysql":    {3},
+}
+
+// ensureReadModelSchema создает таблицы read model теми же миграциями, что применяет
+// RunMigrationSets, чтобы схема хранилища и миграций не расходилась
+func ensureReadModelSchema(ctx context.Context, db *sql.DB, dialect string) error {
+	set, err := Migrations(dialect)
+	if err != nil {
+		return err
+	}
+	return migrations.EnsureSchema(ctx, db, set, readModelMigrations[dialect]...)
+}
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Миграция для создания таблиц Saga Pattern в MySQL 8
+-- Версия: 001
+-- Включает колонки, добавленные в PostgreSQL миграциями 002-004 (definition_version, stack_trace, failure)
+
+-- Таблица для хранения экземпляров саг
+CREATE TABLE IF NOT EXISTS saga_instances (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    definition_name VARCHAR(255) NOT NULL,
+    definition_version INT NOT NULL DEFAULT 1,
+    status VARCHAR(50) NOT NULL,
+    context JSON NOT NULL,
+    correlation_id VARCHAR(255),
+    current_step VARCHAR(255),
+    created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    completed_at DATETIME(6),
+    KEY idx_saga_status (status),
+    KEY idx_saga_correlation (correlation_id),
+    KEY idx_saga_created (created_at),
+    KEY idx_saga_updated (updated_at),
+    KEY idx_saga_definition_version (definition_name, definition_version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Хранит экземпляры саг с их текущим состоянием';
+
+-- Таблица для хранения истории выполнения шагов
+CREATE TABLE IF NOT EXISTS saga_history (
+    id VARCHAR(512) NOT NULL PRIMARY KEY,
+    saga_id VARCHAR(255) NOT NULL,
+    step_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    error TEXT,
+    retry_attempt INT DEFAULT 0,
+    started_at DATETIME(6) NOT NULL,
+    completed_at DATETIME(6),
+    stack_trace TEXT,
+    failure JSON,
+    KEY idx_history_saga (saga_id, started_at),
+    KEY idx_history_step (step_name),
+    CONSTRAINT fk_saga_history_saga FOREIGN KEY (saga_id) REFERENCES saga_instances(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Хранит историю выполнения шагов саги';
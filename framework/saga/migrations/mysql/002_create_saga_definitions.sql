@@ -0,0 +1,13 @@
+-- +goose Up
+-- Миграция для хранения метаданных определений саг (шаги и версии) в MySQL 8
+-- Версия: 002
+-- Аналог migrations/postgres/005_create_saga_definitions.sql
+
+CREATE TABLE IF NOT EXISTS saga_definitions (
+    name VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    steps JSON NOT NULL,
+    registered_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (name, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Метаданные версий определений саг';
@@ -0,0 +1,49 @@
+-- +goose Up
+-- Миграция для создания read models саг в MySQL 8 (MySQLSagaReadModelStore)
+-- Версия: 003
+-- Аналог migrations/postgres/006_create_saga_read_models.sql и 008_add_saga_read_model_heartbeat.sql
+-- MySQLSagaReadModelStore выполняет эту миграцию при подключении, поэтому она идемпотентна
+
+CREATE TABLE IF NOT EXISTS saga_read_models (
+    saga_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    definition_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    current_step VARCHAR(255),
+    total_steps INT,
+    completed_steps INT,
+    failed_steps INT,
+    started_at DATETIME(6) NOT NULL,
+    completed_at DATETIME(6),
+    duration_ms BIGINT,
+    correlation_id VARCHAR(255),
+    context JSON,
+    last_error TEXT,
+    retry_count INT,
+    sla_deadline DATETIME(6),
+    sla_breached BOOLEAN NOT NULL DEFAULT FALSE,
+    last_failure JSON,
+    failure_category VARCHAR(50) AS (last_failure->>'$.category') VIRTUAL,
+    last_heartbeat_at DATETIME(6) NULL COMMENT 'Время последнего heartbeat текущего шага',
+    step_progress DOUBLE NULL COMMENT 'Доля выполнения текущего шага из последнего heartbeat (от 0 до 1)',
+    updated_at DATETIME(6) NOT NULL,
+    KEY idx_saga_rm_status (status),
+    KEY idx_saga_rm_definition (definition_name),
+    KEY idx_saga_rm_correlation (correlation_id),
+    KEY idx_saga_rm_started_at (started_at),
+    KEY idx_saga_rm_failure_category (failure_category)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Read model статусов саг';
+
+CREATE TABLE IF NOT EXISTS saga_step_read_models (
+    saga_id VARCHAR(255) NOT NULL,
+    step_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    started_at DATETIME(6) NOT NULL,
+    completed_at DATETIME(6),
+    duration_ms BIGINT,
+    retry_attempt INT DEFAULT 0,
+    error TEXT,
+    failure JSON,
+    updated_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (saga_id, step_name, started_at),
+    KEY idx_saga_step_rm_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Read model шагов саг';
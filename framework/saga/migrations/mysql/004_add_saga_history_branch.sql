@@ -0,0 +1,6 @@
+-- +goose Up
+-- Миграция для сохранения ветвления в истории шагов саги (переходы по результату шага, пропуск по условию)
+-- Версия: 004
+-- Записи, сохраненные до миграции, получают пустое значение
+
+ALTER TABLE saga_history ADD COLUMN branch VARCHAR(512) NOT NULL DEFAULT '' COMMENT 'Переход по результату шага или причина пропуска шага';
@@ -1,3 +1,4 @@
+-- +goose Up
 -- Миграция для создания таблиц Saga Pattern в PostgreSQL
 -- Версия: 1.0
 -- Дата: 2024-01-01
@@ -86,6 +87,7 @@ COMMENT ON COLUMN saga_snapshots.state IS 'Состояние саги в фор
 COMMENT ON COLUMN saga_snapshots.created_at IS 'Время создания snapshot';
 
 -- Функция для автоматического обновления updated_at
+-- +goose StatementBegin
 CREATE OR REPLACE FUNCTION update_saga_updated_at()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -93,6 +95,7 @@ BEGIN
     RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
+-- +goose StatementEnd
 
 -- Триггер для автоматического обновления updated_at
 CREATE TRIGGER trigger_update_saga_updated_at
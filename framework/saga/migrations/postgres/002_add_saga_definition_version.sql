@@ -0,0 +1,9 @@
+-- +goose Up
+-- Миграция для версионирования определений саг
+-- Саги, сохраненные до миграции, получают версию 1
+
+ALTER TABLE saga_instances ADD COLUMN IF NOT EXISTS definition_version INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_saga_definition_version ON saga_instances(definition_name, definition_version);
+
+COMMENT ON COLUMN saga_instances.definition_version IS 'Версия определения саги, с которой был запущен экземпляр';
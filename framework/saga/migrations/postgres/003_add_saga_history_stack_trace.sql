@@ -0,0 +1,7 @@
+-- +goose Up
+-- Миграция для сохранения stack trace паник в истории шагов саги
+-- Записи, сохраненные до миграции, получают пустой stack trace
+
+ALTER TABLE saga_history ADD COLUMN IF NOT EXISTS stack_trace TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN saga_history.stack_trace IS 'Stack trace, если шаг или компенсация завершились паникой';
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Миграция для структурированных ошибок шагов саги (категория, код, повторяемость, детали)
+-- Записи, сохраненные до миграции, содержат только текст ошибки в колонке error
+
+ALTER TABLE saga_history ADD COLUMN IF NOT EXISTS failure JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_history_failure_category ON saga_history ((failure->>'category'));
+
+COMMENT ON COLUMN saga_history.failure IS 'Структурированная ошибка шага: category (business/technical/timeout/compensation), code, message, retryable, details';
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Миграция для хранения метаданных определений саг (шаги и версии)
+-- Используется прогревом реестра (WarmUpRegistry): сохраненные версии, код которых
+-- отсутствует после деплоя, загружаются в реестр только для чтения
+
+CREATE TABLE IF NOT EXISTS saga_definitions (
+    name VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    steps JSONB NOT NULL,
+    registered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (name, version)
+);
+
+COMMENT ON TABLE saga_definitions IS 'Метаданные версий определений саг';
+COMMENT ON COLUMN saga_definitions.steps IS 'Имена шагов версии в порядке выполнения';
@@ -0,0 +1,51 @@
+-- +goose Up
+-- Миграция для создания read models саг (PostgresSagaReadModelStore, SagaQueryHandler)
+-- Версия: 006
+-- PostgresSagaReadModelStore выполняет эту миграцию и 008 при подключении, поэтому они идемпотентны
+
+CREATE TABLE IF NOT EXISTS saga_read_models (
+    saga_id VARCHAR(255) PRIMARY KEY,
+    definition_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    current_step VARCHAR(255),
+    total_steps INTEGER,
+    completed_steps INTEGER,
+    failed_steps INTEGER,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    duration_ms INTEGER,
+    correlation_id VARCHAR(255),
+    context JSONB,
+    last_error TEXT,
+    retry_count INTEGER,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS saga_step_read_models (
+    saga_id VARCHAR(255) NOT NULL,
+    step_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    duration_ms INTEGER,
+    retry_attempt INTEGER DEFAULT 0,
+    error TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (saga_id, step_name, started_at)
+);
+
+ALTER TABLE saga_read_models ADD COLUMN IF NOT EXISTS sla_deadline TIMESTAMP;
+ALTER TABLE saga_read_models ADD COLUMN IF NOT EXISTS sla_breached BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE saga_read_models ADD COLUMN IF NOT EXISTS last_failure JSONB;
+ALTER TABLE saga_step_read_models ADD COLUMN IF NOT EXISTS failure JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_saga_rm_status ON saga_read_models(status);
+CREATE INDEX IF NOT EXISTS idx_saga_rm_definition ON saga_read_models(definition_name);
+CREATE INDEX IF NOT EXISTS idx_saga_rm_correlation ON saga_read_models(correlation_id);
+CREATE INDEX IF NOT EXISTS idx_saga_rm_started_at ON saga_read_models(started_at);
+CREATE INDEX IF NOT EXISTS idx_saga_step_rm_saga_id ON saga_step_read_models(saga_id);
+CREATE INDEX IF NOT EXISTS idx_saga_step_rm_status ON saga_step_read_models(status);
+CREATE INDEX IF NOT EXISTS idx_saga_rm_failure_category ON saga_read_models((last_failure->>'category'));
+
+COMMENT ON TABLE saga_read_models IS 'Read model статусов саг';
+COMMENT ON TABLE saga_step_read_models IS 'Read model шагов саг';
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Миграция для сохранения ветвления в истории шагов саги (переходы по результату шага, пропуск по условию)
+-- Записи, сохраненные до миграции, получают пустое значение
+
+ALTER TABLE saga_history ADD COLUMN IF NOT EXISTS branch TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN saga_history.branch IS 'Переход по результату шага или причина пропуска шага';
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Миграция для heartbeat и прогресса текущего шага в read model саг
+-- Записи, сохраненные до миграции, получают NULL (шаг не сообщал о прогрессе)
+
+ALTER TABLE saga_read_models ADD COLUMN IF NOT EXISTS last_heartbeat_at TIMESTAMP;
+ALTER TABLE saga_read_models ADD COLUMN IF NOT EXISTS step_progress DOUBLE PRECISION;
+
+COMMENT ON COLUMN saga_read_models.last_heartbeat_at IS 'Время последнего heartbeat текущего шага';
+COMMENT ON COLUMN saga_read_models.step_progress IS 'Доля выполнения текущего шага из последнего heartbeat (от 0 до 1)';
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Миграция для материализованных метрик саг (SagaMetricsProjection, PostgresSagaMetricsStore)
+-- Версия: 009
+-- Бакеты заполняются проекцией по мере поступления событий; для учета истории
+-- проекцию нужно перестроить из event store
+
+CREATE TABLE IF NOT EXISTS saga_metrics_buckets (
+    definition_name VARCHAR(255) NOT NULL,
+    granularity VARCHAR(10) NOT NULL,
+    bucket_start TIMESTAMPTZ NOT NULL,
+    started INTEGER NOT NULL DEFAULT 0,
+    completed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    compensated INTEGER NOT NULL DEFAULT 0,
+    duration_count INTEGER NOT NULL DEFAULT 0,
+    duration_sum_ms BIGINT NOT NULL DEFAULT 0,
+    duration_max_ms BIGINT NOT NULL DEFAULT 0,
+    duration_histogram JSONB NOT NULL DEFAULT '[]',
+    failures_by_category JSONB NOT NULL DEFAULT '{}',
+    failures_by_code JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (definition_name, granularity, bucket_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saga_metrics_buckets_start ON saga_metrics_buckets(granularity, bucket_start);
+
+COMMENT ON TABLE saga_metrics_buckets IS 'Часовые и суточные бакеты метрик саг по определениям';
+COMMENT ON COLUMN saga_metrics_buckets.duration_histogram IS 'Число саг по интервалам длительности (для оценки p50/p95)';
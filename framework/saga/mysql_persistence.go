@@ -0,0 +1,556 @@
+// Package saga предоставляет persistence и read model store саг для MySQL 8.
+package saga
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akriventsev/potter/framework/eventsourcing"
+)
+
+// MySQLPersistence реализация persistence через MySQL 8 (таблицы saga_instances и saga_history,
+// миграция migrations/mysql/001_create_saga_tables.sql).
+// Драйвер MySQL подключается приложением: _ "github.com/go-sql-driver/mysql".
+type MySQLPersistence struct {
+	db       *sql.DB
+	registry *SagaRegistry // реестр для восстановления определений саг
+}
+
+// NewMySQLPersistence создает новую MySQL persistence.
+// DSN указывается в формате драйвера go-sql-driver/mysql (префикс mysql:// допускается).
+func NewMySQLPersistence(dsn string) (*MySQLPersistence, error) {
+	db, err := sql.Open("mysql", eventsourcing.MySQLDSN(dsn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MySQL (is driver \"mysql\" imported?): %w", err)
+	}
+	if err := db.PingContext(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+
+	return NewMySQLPersistenceWithDB(db), nil
+}
+
+// NewMySQLPersistenceWithDB создает MySQL persistence поверх существующего пула соединений.
+// Пул должен быть открыт с parseTime=true (см. eventsourcing.MySQLDSN).
+func NewMySQLPersistenceWithDB(db *sql.DB) *MySQLPersistence {
+	return &MySQLPersistence{
+		db:       db,
+		registry: NewSagaRegistry(),
+	}
+}
+
+// WithRegistry устанавливает реестр саг
+func (p *MySQLPersistence) WithRegistry(registry *SagaRegistry) *MySQLPersistence {
+	p.registry = registry
+	return p
+}
+
+// Save сохраняет состояние саги и историю шагов в одной транзакции
+func (p *MySQLPersistence) Save(ctx context.Context, saga Saga) error {
+	contextData, err := encodeSagaContext(saga.Context())
+	if err != nil {
+		return fmt.Errorf("failed to encode context: %w", err)
+	}
+	contextJSON, err := json.Marshal(contextData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal context: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO saga_instances (id, definition_name, status, context, correlation_id, current_step, created_at, updated_at, definition_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE
+			status = new.status,
+			context = new.context,
+			current_step = new.current_step,
+			updated_at = new.updated_at
+	`, saga.ID(), saga.Definition().Name(), string(saga.Status()), contextJSON, saga.Context().CorrelationID(),
+		saga.CurrentStep(), now, now, SagaDefinitionVersion(saga.Definition()))
+	if err != nil {
+		return fmt.Errorf("failed to save saga: %w", err)
+	}
+
+	for _, hist := range saga.GetHistory() {
+		// Детерминированный идентификатор исключает дубликаты при повторных вызовах Save
+		histID := fmt.Sprintf("%s:%s:%d", saga.ID(), hist.StepName, hist.StartedAt.UnixNano())
+
+		errorStr := ""
+		if hist.Error != nil {
+			errorStr = hist.Error.Error()
+		}
+		var completedAt *time.Time
+		if hist.CompletedAt != nil {
+			utc := hist.CompletedAt.UTC()
+			completedAt = &utc
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO saga_history (id, saga_id, step_name, status, error, retry_attempt, started_at, completed_at, stack_trace, failure, branch)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) AS new
+			ON DUPLICATE KEY UPDATE
+				status = new.status,
+				error = new.error,
+				completed_at = new.completed_at,
+				stack_trace = new.stack_trace,
+				failure = new.failure,
+				branch = new.branch
+		`, histID, saga.ID(), hist.StepName, string(hist.Status), errorStr, hist.RetryAttempt,
+			hist.StartedAt.UTC(), completedAt, hist.StackTrace, failureJSON(hist.Failure), hist.Branch)
+		if err != nil {
+			return fmt.Errorf("failed to save saga history: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// mysqlSagaColumns колонки saga_instances в порядке scanMySQLSaga
+const mysqlSagaColumns = "id, definition_name, definition_version, status, context, COALESCE(correlation_id, ''), COALESCE(current_step, ''), created_at, updated_at, completed_at"
+
+// mysqlSagaRow строка saga_instances
+type mysqlSagaRow struct {
+	id, definitionName, status, correlationID, currentStep string
+	definitionVersion                                      int
+	contextJSON                                            []byte
+	createdAt, updatedAt                                   time.Time
+	completedAt                                            *time.Time
+}
+
+// scanMySQLSaga читает строку saga_instances
+func scanMySQLSaga(scan func(dest ...interface{}) error) (mysqlSagaRow, error) {
+	var row mysqlSagaRow
+	err := scan(&row.id, &row.definitionName, &row.definitionVersion, &row.status, &row.contextJSON,
+		&row.correlationID, &row.currentStep, &row.createdAt, &row.updatedAt, &row.completedAt)
+	return row, err
+}
+
+// restore восстанавливает сагу из строки saga_instances и истории шагов
+func (p *MySQLPersistence) restore(ctx context.Context, row mysqlSagaRow) (*BaseSaga, error) {
+	// Получаем из registry версию definition, с которой была запущена сага
+	definition, err := p.registry.GetSagaVersion(row.definitionName, row.definitionVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga definition %s: %w", row.definitionName, err)
+	}
+
+	sagaCtx := NewSagaContext()
+	var contextData map[string]interface{}
+	if err := json.Unmarshal(row.contextJSON, &contextData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal context: %w", err)
+	}
+	if err := restoreSagaContext(sagaCtx, contextData); err != nil {
+		return nil, fmt.Errorf("failed to restore context: %w", err)
+	}
+	if row.correlationID != "" {
+		sagaCtx.SetCorrelationID(row.correlationID)
+	}
+	if ctxImpl, ok := sagaCtx.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.CreatedAt = row.createdAt
+		ctxImpl.metadata.UpdatedAt = row.updatedAt
+		ctxImpl.mu.Unlock()
+	}
+
+	history, err := p.GetHistory(ctx, row.id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	saga, err := NewBaseSaga(row.id, definition, sagaCtx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saga instance: %w", err)
+	}
+
+	saga.mu.Lock()
+	saga.status = SagaStatus(row.status)
+	saga.currentStep = row.currentStep
+	saga.history = history
+	saga.startedAt = row.createdAt
+	saga.completedAt = row.completedAt
+	saga.mu.Unlock()
+
+	return saga, nil
+}
+
+// Load загружает сагу по ID
+func (p *MySQLPersistence) Load(ctx context.Context, sagaID string) (Saga, error) {
+	query := fmt.Sprintf("SELECT %s FROM saga_instances WHERE id = ?", mysqlSagaColumns)
+	row, err := scanMySQLSaga(p.db.QueryRowContext(ctx, query, sagaID).Scan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saga: %w", err)
+	}
+	return p.restore(ctx, row)
+}
+
+// LoadAll загружает саги с указанным статусом. Саги с неизвестными определениями пропускаются.
+func (p *MySQLPersistence) LoadAll(ctx context.Context, status SagaStatus) ([]Saga, error) {
+	query := fmt.Sprintf("SELECT %s FROM saga_instances WHERE status = ? ORDER BY created_at DESC", mysqlSagaColumns)
+	rows, err := p.db.QueryContext(ctx, query, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sagas: %w", err)
+	}
+
+	var sagaRows []mysqlSagaRow
+	for rows.Next() {
+		row, err := scanMySQLSaga(rows.Scan)
+		if err != nil {
+			continue
+		}
+		sagaRows = append(sagaRows, row)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sagas: %w", err)
+	}
+
+	// История загружается после закрытия курсора, чтобы не удерживать два соединения на сагу
+	var sagas []Saga
+	for _, row := range sagaRows {
+		saga, err := p.restore(ctx, row)
+		if err != nil {
+			continue
+		}
+		sagas = append(sagas, saga)
+	}
+	return sagas, nil
+}
+
+// ListSagaRefs возвращает сведения о сагах с указанными статусами (реализация SagaRefLister)
+func (p *MySQLPersistence) ListSagaRefs(ctx context.Context, statuses ...SagaStatus) ([]PersistedSagaRef, error) {
+	query := `SELECT id, definition_name, definition_version, status, COALESCE(current_step, '') FROM saga_instances`
+	condition, args := mysqlStatusCondition(statuses)
+	if condition != "" {
+		query += " WHERE " + condition
+	}
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []PersistedSagaRef
+	for rows.Next() {
+		var ref PersistedSagaRef
+		var statusStr string
+		if err := rows.Scan(&ref.SagaID, &ref.DefinitionName, &ref.DefinitionVersion, &statusStr, &ref.CurrentStep); err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		ref.Status = SagaStatus(statusStr)
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// ListSagaRecords возвращает саги, соответствующие фильтру (реализация SagaAdminStore)
+func (p *MySQLPersistence) ListSagaRecords(ctx context.Context, filter SagaRecordFilter) ([]SagaRecord, error) {
+	conditions := []string{"1 = 1"}
+	var args []interface{}
+	if condition, statusArgs := mysqlStatusCondition(filter.Statuses); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, statusArgs...)
+	}
+	if filter.DefinitionName != "" {
+		conditions = append(conditions, "definition_name = ?")
+		args = append(args, filter.DefinitionName)
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		conditions = append(conditions, "updated_at < ?")
+		args = append(args, filter.UpdatedBefore.UTC())
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, definition_name, definition_version, status, COALESCE(current_step, ''), COALESCE(correlation_id, ''), created_at, updated_at
+		FROM saga_instances
+		WHERE %s
+		ORDER BY updated_at ASC
+	`, strings.Join(conditions, " AND "))
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var records []SagaRecord
+	for rows.Next() {
+		var record SagaRecord
+		var status string
+		if err := rows.Scan(&record.SagaID, &record.DefinitionName, &record.DefinitionVersion, &status,
+			&record.CurrentStep, &record.CorrelationID, &record.CreatedAt, &record.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		record.Status = SagaStatus(status)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// ForceFail переводит сагу в статус failed (реализация SagaAdminStore).
+// Статус и запись истории сохраняются в одной транзакции.
+func (p *MySQLPersistence) ForceFail(ctx context.Context, sagaID, reason string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status, currentStep string
+	err = tx.QueryRowContext(ctx,
+		`SELECT status, COALESCE(current_step, '') FROM saga_instances WHERE id = ? FOR UPDATE`, sagaID).
+		Scan(&status, &currentStep)
+	if err != nil {
+		return fmt.Errorf("failed to load saga %s: %w", sagaID, err)
+	}
+	if err := checkForceFailable(sagaID, SagaStatus(status)); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE saga_instances SET status = ?, updated_at = ?, completed_at = ? WHERE id = ?`,
+		string(SagaStatusFailed), now, now, sagaID); err != nil {
+		return fmt.Errorf("failed to update saga %s: %w", sagaID, err)
+	}
+
+	failure := forceFailFailure(reason)
+	histID := fmt.Sprintf("%s:%s:%d", sagaID, currentStep, now.UnixNano())
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO saga_history (id, saga_id, step_name, status, error, retry_attempt, started_at, completed_at, stack_trace, failure)
+		VALUES (?, ?, ?, ?, ?, 0, ?, ?, '', ?)
+	`, histID, sagaID, currentStep, string(StepStatusFailed), failure.Message, now, now, failureJSON(failure)); err != nil {
+		return fmt.Errorf("failed to save saga %s history: %w", sagaID, err)
+	}
+
+	return tx.Commit()
+}
+
+// ExportSaga возвращает сохраненное состояние саги (реализация SagaBundleStore)
+func (p *MySQLPersistence) ExportSaga(ctx context.Context, sagaID string) (SagaBundleEntry, error) {
+	row, err := scanMySQLSaga(p.db.QueryRowContext(ctx,
+		`SELECT `+mysqlSagaColumns+` FROM saga_instances WHERE id = ?`, sagaID).Scan)
+	if err != nil {
+		return SagaBundleEntry{}, fmt.Errorf("failed to load saga: %w", err)
+	}
+
+	entry := SagaBundleEntry{
+		SagaID:            row.id,
+		DefinitionName:    row.definitionName,
+		DefinitionVersion: row.definitionVersion,
+		Status:            SagaStatus(row.status),
+		CurrentStep:       row.currentStep,
+		CorrelationID:     row.correlationID,
+		CreatedAt:         row.createdAt,
+		UpdatedAt:         row.updatedAt,
+		CompletedAt:       row.completedAt,
+	}
+	if err := json.Unmarshal(row.contextJSON, &entry.Context); err != nil {
+		return SagaBundleEntry{}, fmt.Errorf("failed to unmarshal context: %w", err)
+	}
+
+	history, err := p.GetHistory(ctx, sagaID)
+	if err != nil {
+		return SagaBundleEntry{}, fmt.Errorf("failed to load history: %w", err)
+	}
+	entry.History = bundleHistory(history)
+	return entry, nil
+}
+
+// ImportSaga сохраняет сагу из выгрузки в одной транзакции (реализация SagaBundleStore).
+// При overwrite = true история саги заменяется историей из выгрузки.
+func (p *MySQLPersistence) ImportSaga(ctx context.Context, entry SagaBundleEntry, overwrite bool) error {
+	contextJSON, err := json.Marshal(entry.Context)
+	if err != nil {
+		return fmt.Errorf("failed to marshal context: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM saga_instances WHERE id = ?)`, entry.SagaID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check saga: %w", err)
+	}
+	if exists && !overwrite {
+		return fmt.Errorf("%w: %s", ErrSagaAlreadyExists, entry.SagaID)
+	}
+	if exists {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM saga_history WHERE saga_id = ?`, entry.SagaID); err != nil {
+			return fmt.Errorf("failed to delete saga history: %w", err)
+		}
+	}
+
+	var completedAt *time.Time
+	if entry.CompletedAt != nil {
+		utc := entry.CompletedAt.UTC()
+		completedAt = &utc
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO saga_instances (id, definition_name, definition_version, status, context, correlation_id, current_step, created_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE
+			definition_name = new.definition_name,
+			definition_version = new.definition_version,
+			status = new.status,
+			context = new.context,
+			correlation_id = new.correlation_id,
+			current_step = new.current_step,
+			created_at = new.created_at,
+			updated_at = new.updated_at,
+			completed_at = new.completed_at
+	`, entry.SagaID, entry.DefinitionName, entry.DefinitionVersion, string(entry.Status), contextJSON,
+		entry.CorrelationID, entry.CurrentStep, entry.CreatedAt.UTC(), entry.UpdatedAt.UTC(), completedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save saga: %w", err)
+	}
+
+	for _, hist := range entry.History {
+		var histCompletedAt *time.Time
+		if hist.CompletedAt != nil {
+			utc := hist.CompletedAt.UTC()
+			histCompletedAt = &utc
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO saga_history (id, saga_id, step_name, status, error, retry_attempt, started_at, completed_at, stack_trace, failure, branch)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, entry.historyID(hist), entry.SagaID, hist.StepName, string(hist.Status), hist.Error, hist.RetryAttempt,
+			hist.StartedAt.UTC(), histCompletedAt, hist.StackTrace, failureJSON(hist.Failure), hist.Branch)
+		if err != nil {
+			return fmt.Errorf("failed to save saga history: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveDefinitionMetadata сохраняет метаданные версий определений в таблицу saga_definitions
+// (миграция migrations/mysql/002_create_saga_definitions.sql, реализация SagaDefinitionStore)
+func (p *MySQLPersistence) SaveDefinitionMetadata(ctx context.Context, definitions []SagaDefinitionMetadata) error {
+	query := `
+		INSERT INTO saga_definitions (name, version, steps, registered_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP(6), CURRENT_TIMESTAMP(6)) AS new
+		ON DUPLICATE KEY UPDATE steps = new.steps, updated_at = new.updated_at
+	`
+	for _, metadata := range definitions {
+		stepsJSON, err := json.Marshal(metadata.Steps)
+		if err != nil {
+			return fmt.Errorf("failed to marshal steps of %s v%d: %w", metadata.Name, metadata.Version, err)
+		}
+		if _, err := p.db.ExecContext(ctx, query, metadata.Name, metadata.Version, stepsJSON); err != nil {
+			return fmt.Errorf("failed to save saga definition %s v%d: %w", metadata.Name, metadata.Version, err)
+		}
+	}
+	return nil
+}
+
+// ListDefinitionMetadata возвращает метаданные сохраненных версий определений (реализация SagaDefinitionStore)
+func (p *MySQLPersistence) ListDefinitionMetadata(ctx context.Context) ([]SagaDefinitionMetadata, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT name, version, steps, registered_at FROM saga_definitions ORDER BY name, version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saga definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var result []SagaDefinitionMetadata
+	for rows.Next() {
+		var metadata SagaDefinitionMetadata
+		var stepsJSON []byte
+		if err := rows.Scan(&metadata.Name, &metadata.Version, &stepsJSON, &metadata.RegisteredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saga definition: %w", err)
+		}
+		if err := json.Unmarshal(stepsJSON, &metadata.Steps); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal steps of %s v%d: %w", metadata.Name, metadata.Version, err)
+		}
+		result = append(result, metadata)
+	}
+	return result, rows.Err()
+}
+
+// Delete удаляет сагу (история удаляется каскадно)
+func (p *MySQLPersistence) Delete(ctx context.Context, sagaID string) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM saga_instances WHERE id = ?`, sagaID)
+	return err
+}
+
+// GetHistory возвращает историю выполнения саги
+func (p *MySQLPersistence) GetHistory(ctx context.Context, sagaID string) ([]SagaHistory, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT step_name, status, COALESCE(error, ''), retry_attempt, started_at, completed_at, COALESCE(stack_trace, ''), failure, branch
+		FROM saga_history
+		WHERE saga_id = ?
+		ORDER BY started_at ASC
+	`, sagaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []SagaHistory
+	for rows.Next() {
+		var stepName, statusStr, errorStr, stackTrace, branch string
+		var retryAttempt int
+		var startedAt time.Time
+		var completedAt *time.Time
+		var failure []byte
+
+		if err := rows.Scan(&stepName, &statusStr, &errorStr, &retryAttempt, &startedAt, &completedAt, &stackTrace, &failure, &branch); err != nil {
+			continue
+		}
+
+		var stepErr error
+		if errorStr != "" {
+			stepErr = errors.New(errorStr)
+		}
+
+		history = append(history, SagaHistory{
+			StepName:     stepName,
+			Status:       StepStatus(statusStr),
+			StartedAt:    startedAt,
+			CompletedAt:  completedAt,
+			Error:        stepErr,
+			Failure:      failureFromValue(failure),
+			RetryAttempt: retryAttempt,
+			StackTrace:   stackTrace,
+			Branch:       branch,
+		})
+	}
+
+	return history, rows.Err()
+}
+
+// Close закрывает пул соединений
+func (p *MySQLPersistence) Close(ctx context.Context) error {
+	return p.db.Close()
+}
+
+// mysqlStatusCondition возвращает условие status IN (...) и его аргументы ("" - без ограничения)
+func mysqlStatusCondition(statuses []SagaStatus) (string, []interface{}) {
+	if len(statuses) == 0 {
+		return "", nil
+	}
+	args := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		args[i] = string(status)
+	}
+	return "status IN (?" + strings.Repeat(", ?", len(statuses)-1) + ")", args
+}
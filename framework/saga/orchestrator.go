@@ -3,14 +3,29 @@ package saga
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/pprof"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/eventsourcing"
+	"github.com/akriventsev/potter/framework/invoke"
 	"github.com/akriventsev/potter/framework/metrics"
 )
 
+// pprof метки горутин саг, запущенных через StartSaga
+const (
+	SagaPprofLabelID         = "potter_saga_id"
+	SagaPprofLabelDefinition = "potter_saga"
+)
+
+// ErrReadOnlyOrchestrator возникает при попытке выполнения саги на оркестраторе в режиме только чтения
+var ErrReadOnlyOrchestrator = errors.New("saga orchestrator is a read-only replica")
+
 // SagaOrchestrator интерфейс оркестратора саг
 type SagaOrchestrator interface {
 	// Execute запускает выполнение саги
@@ -32,7 +47,19 @@ type DefaultOrchestrator struct {
 	eventBus    events.EventBus
 	metrics     *metrics.Metrics
 	registry    *SagaRegistry
-	runningSagas map[string]context.CancelFunc
+	runningSagas map[string]*runningSaga
+	readOnly    bool
+	consistencyCheck ConsistencyCheckMode
+	onConsistencyReport func(report *ConsistencyReport)
+	consistencyReport *ConsistencyReport
+	definitionWarmUp bool
+	onWarmUpReport func(report *WarmUpReport)
+	warmUpReport *WarmUpReport
+	compensationRetry *RetryPolicy
+	stepInterceptors []StepInterceptor
+	// escalationHandlers обработчики превышения дедлайнов шагов (см. WithEscalationHandler)
+	escalationHandlers []EscalationHandler
+	outbox *invoke.CommandScheduler
 }
 
 // NewDefaultOrchestrator создает новый оркестратор
@@ -41,7 +68,57 @@ func NewDefaultOrchestrator(persistence SagaPersistence, eventBus events.EventBu
 		persistence:  persistence,
 		eventBus:     eventBus,
 		registry:     NewSagaRegistry(),
-		runningSagas: make(map[string]context.CancelFunc),
+		runningSagas: make(map[string]*runningSaga),
+	}
+}
+
+// runningSaga выполнение саги в оркестраторе: отмена контекста с причиной
+// (ErrSagaCancelled, ErrCompensationRequested), сигнал завершения выполнения
+// и сам экземпляр (для проверки прогресса в ListStuck)
+type runningSaga struct {
+	saga   Saga
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// trackRunning регистрирует выполнение саги и возвращает его контекст.
+// Вызывается под o.mu.
+func (o *DefaultOrchestrator) trackRunning(ctx context.Context, saga Saga) (context.Context, *runningSaga) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	run := &runningSaga{saga: saga, cancel: cancel, done: make(chan struct{})}
+	o.runningSagas[saga.ID()] = run
+	return runCtx, run
+}
+
+// untrackRunning снимает регистрацию выполнения саги и сообщает о его завершении
+func (o *DefaultOrchestrator) untrackRunning(sagaID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if run, ok := o.runningSagas[sagaID]; ok {
+		delete(o.runningSagas, sagaID)
+		run.cancel(nil)
+		close(run.done)
+	}
+}
+
+// stopRunning отменяет контекст выполняющегося шага саги с причиной cause и ожидает,
+// пока сага запишет шаг как отмененный и компенсирует выполненные шаги.
+// Шаг должен учитывать отмену ctx: ожидание ограничено контекстом вызывающего.
+// Возвращает false, если сага не выполняется в этом оркестраторе.
+func (o *DefaultOrchestrator) stopRunning(ctx context.Context, sagaID string, cause error) (bool, error) {
+	o.mu.RLock()
+	run, exists := o.runningSagas[sagaID]
+	o.mu.RUnlock()
+	if !exists {
+		return false, nil
+	}
+
+	run.cancel(cause)
+	select {
+	case <-run.done:
+		return true, nil
+	case <-ctx.Done():
+		return true, fmt.Errorf("saga %s did not stop after %v: %w", sagaID, cause, ctx.Err())
 	}
 }
 
@@ -51,6 +128,195 @@ func (o *DefaultOrchestrator) WithRegistry(registry *SagaRegistry) *DefaultOrche
 	return o
 }
 
+// WithCompensationRetry устанавливает политику повторов компенсации по умолчанию
+// для шагов без собственной политики (см. BaseStep.WithCompensationRetry).
+// Без политики компенсация шага выполняется один раз.
+func (o *DefaultOrchestrator) WithCompensationRetry(policy *RetryPolicy) *DefaultOrchestrator {
+	o.compensationRetry = policy
+	return o
+}
+
+// WithStepInterceptors добавляет перехватчики шагов всех саг оркестратора.
+// Вызываются перед перехватчиками определения саги (см. StepInterceptor).
+func (o *DefaultOrchestrator) WithStepInterceptors(interceptors ...StepInterceptor) *DefaultOrchestrator {
+	o.stepInterceptors = append(o.stepInterceptors, interceptors...)
+	return o
+}
+
+// attachSaga передает саге EventBus, политику повторов компенсации, backend метрик,
+// перехватчики шагов и обработчики эскалации оркестратора, если они не заданы для саги
+func (o *DefaultOrchestrator) attachSaga(saga Saga) {
+	baseSaga, ok := saga.(*BaseSaga)
+	if !ok {
+		return
+	}
+	baseSaga.mu.Lock()
+	defer baseSaga.mu.Unlock()
+	if baseSaga.eventBus == nil && o.eventBus != nil {
+		baseSaga.eventBus = o.eventBus
+	}
+	if baseSaga.compensationRetry == nil && o.compensationRetry != nil {
+		baseSaga.compensationRetry = o.compensationRetry
+	}
+	if baseSaga.recorder == nil && o.metrics != nil {
+		baseSaga.recorder = o.metrics.Recorder()
+	}
+	if baseSaga.interceptors == nil && len(o.stepInterceptors) > 0 {
+		baseSaga.interceptors = o.stepInterceptors
+	}
+	if baseSaga.escalationHandlers == nil && len(o.escalationHandlers) > 0 {
+		baseSaga.escalationHandlers = o.escalationHandlers
+	}
+}
+
+// WithReadOnly переводит оркестратор в режим только чтения: экземпляр обслуживает
+// запросы статуса и мониторинг, а запуск, компенсация, возобновление и отмена саг
+// возвращают ErrReadOnlyOrchestrator. Используется для реплик, развернутых рядом
+// с пользователями, при выполнении саг в одном регионе.
+func (o *DefaultOrchestrator) WithReadOnly(readOnly bool) *DefaultOrchestrator {
+	o.readOnly = readOnly
+	return o
+}
+
+// IsReadOnly проверяет, работает ли оркестратор в режиме только чтения
+// (реализация eventsourcing.ReadOnlyAware). Оркестратор также доступен только для чтения,
+// если persistence работает поверх реплики event store (например, RegionalEventStore в роли follower).
+func (o *DefaultOrchestrator) IsReadOnly() bool {
+	if o.readOnly {
+		return true
+	}
+	if aware, ok := o.persistence.(eventsourcing.ReadOnlyAware); ok {
+		return aware.IsReadOnly()
+	}
+	return false
+}
+
+// checkWritable возвращает ErrReadOnlyOrchestrator, если оркестратор работает в режиме только чтения
+func (o *DefaultOrchestrator) checkWritable(operation, target string) error {
+	if o.IsReadOnly() {
+		return fmt.Errorf("%w: cannot %s %s", ErrReadOnlyOrchestrator, operation, target)
+	}
+	return nil
+}
+
+// WithConsistencyCheck включает проверку согласованности реестра и сохраненных саг в Start.
+// onReport получает отчет о найденных проблемах в режиме ConsistencyCheckWarn (может быть nil).
+// Отчет последней проверки доступен через LastConsistencyReport, каждая проблема
+// учитывается в метриках как событие saga.consistency_issue.
+func (o *DefaultOrchestrator) WithConsistencyCheck(mode ConsistencyCheckMode, onReport func(report *ConsistencyReport)) *DefaultOrchestrator {
+	o.consistencyCheck = mode
+	o.onConsistencyReport = onReport
+	return o
+}
+
+// WithDefinitionWarmUp включает прогрев реестра в Start (см. WarmUpRegistry): метаданные
+// зарегистрированных определений сохраняются в persistence (кроме режима только чтения),
+// а сохраненные версии без кода загружаются в реестр как определения только для чтения.
+// onReport получает отчет, если найдены версии без кода (может быть nil). Отчет последнего
+// прогрева доступен через LastWarmUpReport, каждая версия без кода учитывается в метриках
+// как событие saga.definition_missing.
+// Persistence должна реализовывать SagaDefinitionStore.
+func (o *DefaultOrchestrator) WithDefinitionWarmUp(enabled bool, onReport func(report *WarmUpReport)) *DefaultOrchestrator {
+	o.definitionWarmUp = enabled
+	o.onWarmUpReport = onReport
+	return o
+}
+
+// CheckConsistency проверяет, что все незавершенные саги в persistence могут быть восстановлены
+// с текущим реестром (см. CheckRegistryConsistency)
+func (o *DefaultOrchestrator) CheckConsistency(ctx context.Context) (*ConsistencyReport, error) {
+	if o.persistence == nil {
+		return nil, fmt.Errorf("persistence not configured, cannot check consistency")
+	}
+	return CheckRegistryConsistency(ctx, o.registry, o.persistence)
+}
+
+// Start подготавливает оркестратор к работе. Если включен прогрев реестра, метаданные
+// определений сохраняются и сверяются с реестром до проверки согласованности.
+// Если включена проверка согласованности, саги с незарегистрированными определениями
+// или версиями обнаруживаются при старте, а не при первой загрузке: в режиме
+// ConsistencyCheckFailFast возвращается ErrRegistryInconsistent.
+func (o *DefaultOrchestrator) Start(ctx context.Context) error {
+	if o.definitionWarmUp {
+		if err := o.warmUpRegistry(ctx); err != nil {
+			return err
+		}
+	}
+	if o.consistencyCheck == ConsistencyCheckDisabled {
+		return nil
+	}
+
+	report, err := o.CheckConsistency(ctx)
+	if err != nil {
+		return fmt.Errorf("consistency check failed: %w", err)
+	}
+	o.mu.Lock()
+	o.consistencyReport = report
+	o.mu.Unlock()
+	if report.OK() {
+		return nil
+	}
+
+	if o.metrics != nil {
+		for range report.Issues {
+			o.metrics.RecordEvent(ctx, "saga.consistency_issue")
+		}
+	}
+	if o.consistencyCheck == ConsistencyCheckFailFast {
+		return report.Err()
+	}
+	if o.onConsistencyReport != nil {
+		o.onConsistencyReport(report)
+	}
+	return nil
+}
+
+// LastConsistencyReport возвращает отчет проверки согласованности, выполненной в Start
+// (nil, если проверка не выполнялась)
+func (o *DefaultOrchestrator) LastConsistencyReport() *ConsistencyReport {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.consistencyReport
+}
+
+// warmUpRegistry выполняет прогрев реестра и сообщает о версиях без кода
+func (o *DefaultOrchestrator) warmUpRegistry(ctx context.Context) error {
+	if o.persistence == nil {
+		return fmt.Errorf("persistence not configured, cannot warm up saga registry")
+	}
+	if o.registry == nil {
+		o.registry = NewSagaRegistry()
+	}
+
+	report, err := WarmUpRegistry(ctx, o.registry, o.persistence, !o.IsReadOnly())
+	if err != nil {
+		return fmt.Errorf("saga registry warm-up failed: %w", err)
+	}
+	o.mu.Lock()
+	o.warmUpReport = report
+	o.mu.Unlock()
+	if len(report.Missing) == 0 {
+		return nil
+	}
+	if o.metrics != nil {
+		for range report.Missing {
+			o.metrics.RecordEvent(ctx, "saga.definition_missing")
+		}
+	}
+	if o.onWarmUpReport != nil {
+		o.onWarmUpReport(report)
+	}
+	return nil
+}
+
+// LastWarmUpReport возвращает отчет прогрева реестра, выполненного в Start
+// (nil, если прогрев не выполнялся)
+func (o *DefaultOrchestrator) LastWarmUpReport() *WarmUpReport {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.warmUpReport
+}
+
 // RegisterSaga регистрирует определение саги в реестре
 func (o *DefaultOrchestrator) RegisterSaga(name string, definition SagaDefinition) error {
 	if o.registry == nil {
@@ -62,6 +328,20 @@ func (o *DefaultOrchestrator) RegisterSaga(name string, definition SagaDefinitio
 // StartSaga convenience-метод для запуска саги по имени definition
 // Автоматически получает definition из registry, создает instance и запускает выполнение
 func (o *DefaultOrchestrator) StartSaga(ctx context.Context, definitionName string, sagaCtx SagaContext) (Saga, error) {
+	if err := o.checkWritable("start saga", definitionName); err != nil {
+		return nil, err
+	}
+
+	instance, err := o.newInstance(ctx, definitionName, sagaCtx)
+	if err != nil {
+		return nil, err
+	}
+	o.launch(ctx, instance)
+	return instance, nil
+}
+
+// newInstance создает экземпляр саги по определению из registry
+func (o *DefaultOrchestrator) newInstance(ctx context.Context, definitionName string, sagaCtx SagaContext) (Saga, error) {
 	if o.registry == nil {
 		return nil, fmt.Errorf("registry not configured")
 	}
@@ -91,23 +371,27 @@ func (o *DefaultOrchestrator) StartSaga(ctx context.Context, definitionName stri
 	if instance == nil {
 		return nil, fmt.Errorf("failed to create saga instance: returned nil")
 	}
+	return instance, nil
+}
 
+// launch запускает асинхронное выполнение созданной саги
+func (o *DefaultOrchestrator) launch(ctx context.Context, instance Saga) {
 	sagaID := instance.ID()
 
 	// Создаем контекст с отменой для саги заранее
-	sagaContext, cancel := context.WithCancel(ctx)
 	o.mu.Lock()
-	o.runningSagas[sagaID] = cancel
+	sagaContext, _ := o.trackRunning(ctx, instance)
 	o.mu.Unlock()
 
-	// Запускаем выполнение в горутине для асинхронности
-	go func() {
-		if err := o.Execute(sagaContext, instance); err != nil {
+	// Запускаем выполнение в горутине для асинхронности.
+	// pprof метки позволяют найти горутину саги в goroutine dump (/debug/pprof/goroutine?debug=1)
+	labels := pprof.Labels(SagaPprofLabelID, sagaID, SagaPprofLabelDefinition, instance.Definition().Name())
+	go pprof.Do(sagaContext, labels, func(labeledCtx context.Context) {
+		// Паника вне шагов не должна завершать процесс: Execute уже восстанавливает панику шагов
+		if err := core.SafeCall(func() error { return o.Execute(labeledCtx, instance) }); err != nil {
 			// Ошибка уже залогирована в Execute
 		}
-	}()
-
-	return instance, nil
+	})
 }
 
 // RegisterDefinition алиас для RegisterSaga для обратной совместимости
@@ -124,13 +408,16 @@ func (o *DefaultOrchestrator) WithMetrics(m *metrics.Metrics) *DefaultOrchestrat
 func (o *DefaultOrchestrator) Execute(ctx context.Context, saga Saga) error {
 	sagaID := saga.ID()
 
-	// Устанавливаем eventBus в сагу, если она поддерживает это
-	if baseSaga, ok := saga.(*BaseSaga); ok && baseSaga.eventBus == nil && o.eventBus != nil {
-		baseSaga.mu.Lock()
-		baseSaga.eventBus = o.eventBus
-		baseSaga.mu.Unlock()
+	if err := o.checkWritable("execute saga", sagaID); err != nil {
+		return err
+	}
+	if err := checkDefinitionAvailable(saga); err != nil {
+		return err
 	}
 
+	// Устанавливаем eventBus в сагу, если она поддерживает это
+	o.attachSaga(saga)
+
 	// Проверяем, есть ли уже контекст с отменой для этой саги
 	o.mu.Lock()
 	_, exists := o.runningSagas[sagaID]
@@ -142,10 +429,8 @@ func (o *DefaultOrchestrator) Execute(ctx context.Context, saga Saga) error {
 		sagaCtx = ctx
 	} else {
 		// Создаем контекст с отменой для саги (для прямых вызовов Execute)
-		var cancel context.CancelFunc
-		sagaCtx, cancel = context.WithCancel(ctx)
 		o.mu.Lock()
-		o.runningSagas[sagaID] = cancel
+		sagaCtx, _ = o.trackRunning(ctx, saga)
 		o.mu.Unlock()
 	}
 
@@ -158,6 +443,9 @@ func (o *DefaultOrchestrator) Execute(ctx context.Context, saga Saga) error {
 			Timestamp: time.Now(),
 			CorrelationID: saga.Context().CorrelationID(),
 		}
+		if deadline, ok := sagaSLADeadline(saga); ok {
+			startedEvent.SLADeadline = &deadline
+		}
 		startedEvent.WithCorrelationID(saga.Context().CorrelationID())
 		_ = o.eventBus.Publish(ctx, startedEvent)
 	}
@@ -167,13 +455,45 @@ func (o *DefaultOrchestrator) Execute(ctx context.Context, saga Saga) error {
 		o.metrics.RecordEvent(ctx, "saga.started")
 	}
 
-	// Выполняем сагу
+	// Выполняем сагу, отслеживая SLA определения
+	stopSLA := o.watchSLA(ctx, saga)
 	err := saga.Execute(sagaCtx)
+	stopSLA()
 
-	// Удаляем из running sagas
-	o.mu.Lock()
-	delete(o.runningSagas, sagaID)
-	o.mu.Unlock()
+	return o.finishExecution(ctx, saga, err)
+}
+
+// finishExecution завершает выполнение саги: публикует событие завершения и сохраняет
+// финальное состояние. Сага, остановленная на шаге подтверждения или ожидания сообщения,
+// не считается завершенной.
+func (o *DefaultOrchestrator) finishExecution(ctx context.Context, saga Saga, err error) error {
+	sagaID := saga.ID()
+
+	// Удаляем из running sagas после сохранения итогового состояния (Cancel ожидает его)
+	defer o.untrackRunning(sagaID)
+
+	// Итоговое состояние сохраняется и после отмены контекста выполнения
+	ctx = context.WithoutCancel(ctx)
+
+	if errors.Is(err, ErrSagaAwaitingApproval) {
+		// Состояние уже сохранено сагой; выполнение продолжится в Approve
+		if o.metrics != nil {
+			o.metrics.RecordEvent(ctx, "saga.waiting_approval")
+		}
+		return nil
+	}
+	if errors.Is(err, ErrSagaAwaitingMessage) {
+		// Состояние уже сохранено сагой; выполнение продолжится при доставке сообщения (SendMessage)
+		if o.metrics != nil {
+			o.metrics.RecordEvent(ctx, "saga.waiting_message")
+		}
+		return nil
+	}
+
+	if IsCancellation(err) && saga.Status() == SagaStatusCompensated {
+		// Отмена - штатный исход: прерванный шаг записан как отмененный, выполненные шаги компенсированы
+		return o.finishCancelled(ctx, saga, err)
+	}
 
 	// Публикуем событие завершения
 	if o.eventBus != nil {
@@ -183,6 +503,7 @@ func (o *DefaultOrchestrator) Execute(ctx context.Context, saga Saga) error {
 				SagaID:    sagaID,
 				Error:     err.Error(),
 				FailedStep: saga.CurrentStep(),
+				Failure:   lastFailure(saga.GetHistory()),
 				Timestamp: time.Now(),
 			}
 			failedEvent.WithCorrelationID(saga.Context().CorrelationID())
@@ -222,15 +543,115 @@ func (o *DefaultOrchestrator) Execute(ctx context.Context, saga Saga) error {
 	return err
 }
 
+// finishCancelled публикует завершение саги, компенсированной после отмены
+func (o *DefaultOrchestrator) finishCancelled(ctx context.Context, saga Saga, err error) error {
+	sagaID := saga.ID()
+
+	if o.eventBus != nil {
+		compensatedEvent := &SagaCompensatedEvent{
+			BaseEvent:        events.NewBaseEvent("SagaCompensated", sagaID),
+			SagaID:           sagaID,
+			CompensatedSteps: len(saga.GetHistory()),
+			Timestamp:        time.Now(),
+		}
+		compensatedEvent.WithCorrelationID(saga.Context().CorrelationID())
+		_ = o.eventBus.Publish(ctx, compensatedEvent)
+	}
+
+	if o.metrics != nil {
+		if errors.Is(err, ErrCompensationRequested) {
+			o.metrics.RecordEvent(ctx, "saga.compensated")
+		} else {
+			o.metrics.RecordEvent(ctx, "saga.cancelled")
+		}
+	}
+
+	if o.persistence != nil {
+		_ = o.persistence.Save(ctx, saga)
+	}
+
+	return err
+}
+
+// Compensate компенсирует сагу. Если сага выполняется в этом оркестраторе, контекст
+// текущего шага отменяется с причиной ErrCompensationRequested: шаг записывается
+// как отмененный, а выполненные шаги компенсирует сама выполняющаяся сага.
 func (o *DefaultOrchestrator) Compensate(ctx context.Context, saga Saga) error {
 	sagaID := saga.ID()
 
+	if err := o.checkWritable("compensate saga", sagaID); err != nil {
+		return err
+	}
+	if err := checkDefinitionAvailable(saga); err != nil {
+		return err
+	}
+
+	running, err := o.stopRunning(ctx, sagaID, ErrCompensationRequested)
+	if err != nil {
+		return err
+	}
+	if running {
+		stopped, err := o.reloadSaga(ctx, saga)
+		if err != nil {
+			return err
+		}
+		switch stopped.Status() {
+		case SagaStatusCompensated:
+			return nil
+		case SagaStatusCompensationStuck:
+			return fmt.Errorf("saga %s compensation is stuck, resume it with ResumeCompensation", sagaID)
+		}
+		// Сага завершилась раньше, чем шаг заметил отмену - компенсируем как обычно
+		saga = stopped
+	}
+	o.attachSaga(saga)
+
+	return o.runCompensation(ctx, saga, "manual_compensation", saga.Compensate)
+}
+
+// ResumeCompensation возобновляет компенсацию саги, остановленную в статусе
+// SagaStatusCompensationStuck (например, после исправления причины ошибки вручную).
+// Уже компенсированные шаги повторно не компенсируются.
+func (o *DefaultOrchestrator) ResumeCompensation(ctx context.Context, sagaID string) error {
+	if err := o.checkWritable("resume compensation of saga", sagaID); err != nil {
+		return err
+	}
+	if o.persistence == nil {
+		return fmt.Errorf("persistence not configured, cannot resume compensation")
+	}
+
+	saga, err := o.persistence.Load(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to load saga %s: %w", sagaID, err)
+	}
+	if err := checkDefinitionAvailable(saga); err != nil {
+		return err
+	}
+	if status := saga.Status(); status != SagaStatusCompensationStuck {
+		return fmt.Errorf("saga %s compensation cannot be resumed, current status: %s", sagaID, status)
+	}
+
+	resumable, ok := saga.(interface {
+		ResumeCompensation(ctx context.Context) error
+	})
+	if !ok {
+		return fmt.Errorf("saga %s does not support compensation resume", sagaID)
+	}
+	o.attachSaga(saga)
+
+	return o.runCompensation(ctx, saga, "compensation_resumed", resumable.ResumeCompensation)
+}
+
+// runCompensation выполняет компенсацию саги, публикуя события начала и завершения компенсации
+func (o *DefaultOrchestrator) runCompensation(ctx context.Context, saga Saga, reason string, compensate func(ctx context.Context) error) error {
+	sagaID := saga.ID()
+
 	// Публикуем событие начала компенсации
 	if o.eventBus != nil {
 		compensatingEvent := &SagaCompensatingEvent{
 			BaseEvent: events.NewBaseEvent("SagaCompensating", sagaID),
 			SagaID:    sagaID,
-			Reason:    "manual_compensation",
+			Reason:    reason,
 			Timestamp: time.Now(),
 		}
 		compensatingEvent.WithCorrelationID(saga.Context().CorrelationID())
@@ -238,7 +659,7 @@ func (o *DefaultOrchestrator) Compensate(ctx context.Context, saga Saga) error {
 	}
 
 	// Выполняем компенсацию
-	err := saga.Compensate(ctx)
+	err := compensate(ctx)
 
 	// Публикуем событие завершения компенсации
 	if o.eventBus != nil {
@@ -272,6 +693,10 @@ func (o *DefaultOrchestrator) Compensate(ctx context.Context, saga Saga) error {
 }
 
 func (o *DefaultOrchestrator) Resume(ctx context.Context, sagaID string) error {
+	if err := o.checkWritable("resume saga", sagaID); err != nil {
+		return err
+	}
+
 	// Загружаем сагу из persistence
 	if o.persistence == nil {
 		return fmt.Errorf("persistence not configured, cannot resume saga")
@@ -292,6 +717,75 @@ func (o *DefaultOrchestrator) Resume(ctx context.Context, sagaID string) error {
 	return o.Execute(ctx, saga)
 }
 
+// Approve передает решение по шагу подтверждения саги в статусе SagaStatusWaitingApproval
+// и продолжает ее выполнение. При отклонении выполненные шаги компенсируются; ошибка
+// возвращается, только если компенсация не удалась. Если сага остановилась на следующем
+// шаге подтверждения, Approve возвращает nil.
+func (o *DefaultOrchestrator) Approve(ctx context.Context, sagaID, stepName string, decision ApprovalDecision) error {
+	if err := o.checkWritable("approve saga", sagaID); err != nil {
+		return err
+	}
+	if o.persistence == nil {
+		return fmt.Errorf("persistence not configured, cannot approve saga")
+	}
+
+	saga, err := o.persistence.Load(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to load saga %s: %w", sagaID, err)
+	}
+	if err := checkDefinitionAvailable(saga); err != nil {
+		return err
+	}
+	if status := saga.Status(); status != SagaStatusWaitingApproval {
+		return fmt.Errorf("saga %s is not waiting for approval, current status: %s", sagaID, status)
+	}
+	if current := saga.CurrentStep(); current != stepName {
+		return fmt.Errorf("saga %s is waiting for approval of step %s, not %s", sagaID, current, stepName)
+	}
+
+	approvable, ok := saga.(interface {
+		Approve(ctx context.Context, stepName string, decision ApprovalDecision) error
+	})
+	if !ok {
+		return fmt.Errorf("saga %s does not support approval", sagaID)
+	}
+	o.attachSaga(saga)
+
+	o.mu.Lock()
+	if _, running := o.runningSagas[sagaID]; running {
+		o.mu.Unlock()
+		return fmt.Errorf("saga %s is already running", sagaID)
+	}
+	sagaCtx, run := o.trackRunning(ctx, saga)
+	o.mu.Unlock()
+	defer run.cancel(nil)
+
+	if o.eventBus != nil {
+		decidedEvent := &ApprovalDecidedEvent{
+			BaseEvent: events.NewBaseEvent("ApprovalDecided", sagaID),
+			SagaID:    sagaID,
+			StepName:  stepName,
+			Approved:  decision.Approved,
+			Approver:  decision.Approver,
+			Comment:   decision.Comment,
+			Timestamp: time.Now(),
+		}
+		decidedEvent.WithCorrelationID(saga.Context().CorrelationID())
+		_ = o.eventBus.Publish(ctx, decidedEvent)
+	}
+
+	stopSLA := o.watchSLA(ctx, saga)
+	err = approvable.Approve(sagaCtx, stepName, decision)
+	stopSLA()
+
+	err = o.finishExecution(ctx, saga, err)
+	if err != nil && errors.Is(err, ErrApprovalRejected) && saga.Status() == SagaStatusCompensated {
+		// Отклонение - штатный исход: сага компенсирована
+		return nil
+	}
+	return err
+}
+
 func (o *DefaultOrchestrator) GetStatus(ctx context.Context, sagaID string) (SagaStatus, error) {
 	if o.persistence == nil {
 		return "", fmt.Errorf("persistence not configured, cannot get saga status")
@@ -305,34 +799,45 @@ func (o *DefaultOrchestrator) GetStatus(ctx context.Context, sagaID string) (Sag
 	return saga.Status(), nil
 }
 
+// Cancel отменяет выполняющуюся сагу: контекст текущего шага отменяется с причиной
+// ErrSagaCancelled, шаг записывается как отмененный (StepStatusCancelled), а выполненные
+// шаги компенсируются. Cancel ожидает остановки саги в пределах ctx.
 func (o *DefaultOrchestrator) Cancel(ctx context.Context, sagaID string) error {
-	// Отменяем выполнение через cancel функцию
-	o.mu.Lock()
-	cancel, exists := o.runningSagas[sagaID]
-	o.mu.Unlock()
+	if err := o.checkWritable("cancel saga", sagaID); err != nil {
+		return err
+	}
 
-	if !exists {
+	running, err := o.stopRunning(ctx, sagaID, ErrSagaCancelled)
+	if err != nil {
+		return err
+	}
+	if !running {
 		return fmt.Errorf("saga %s is not running", sagaID)
 	}
+	return nil
+}
 
-	// Отменяем контекст
-	cancel()
-
-	// Загружаем сагу и помечаем как отмененную
-	if o.persistence != nil {
-		saga, err := o.persistence.Load(ctx, sagaID)
-		if err == nil {
-			// Можно добавить специальный статус для отмененных саг
-			// Пока просто сохраняем текущее состояние
-			_ = o.persistence.Save(ctx, saga)
-		}
+// reloadSaga возвращает актуальное состояние саги из persistence (без persistence - переданную сагу)
+func (o *DefaultOrchestrator) reloadSaga(ctx context.Context, saga Saga) (Saga, error) {
+	if o.persistence == nil {
+		return saga, nil
+	}
+	loaded, err := o.persistence.Load(ctx, saga.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saga %s: %w", saga.ID(), err)
 	}
+	return loaded, nil
+}
 
-	// Удаляем из running sagas
-	o.mu.Lock()
-	delete(o.runningSagas, sagaID)
-	o.mu.Unlock()
+// RunningSagas возвращает ID выполняющихся саг (для диагностики)
+func (o *DefaultOrchestrator) RunningSagas() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
 
-	return nil
+	ids := make([]string, 0, len(o.runningSagas))
+	for sagaID := range o.runningSagas {
+		ids = append(ids, sagaID)
+	}
+	sort.Strings(ids)
+	return ids
 }
-
@@ -2,9 +2,15 @@ package saga
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/eventsourcing"
+	"github.com/akriventsev/potter/framework/invoke"
+	"github.com/akriventsev/potter/framework/transport"
 )
 
 func TestDefaultOrchestrator_Execute(t *testing.T) {
@@ -166,3 +172,975 @@ func TestDefaultOrchestrator_Compensate(t *testing.T) {
 }
 
 
+
+func TestDefaultOrchestrator_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+	persistence := NewInMemoryPersistence()
+
+	definition := NewBaseSagaDefinition("test-saga")
+	definition.AddStep(NewBaseStep("step1"))
+
+	saga, err := NewBaseSaga("test-id", definition, NewSagaContext(), persistence)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := persistence.Save(ctx, saga); err != nil {
+		t.Fatalf("Failed to save saga: %v", err)
+	}
+
+	orchestrator := NewDefaultOrchestrator(persistence, nil).WithReadOnly(true)
+	if err := orchestrator.RegisterSaga("test-saga", definition); err != nil {
+		t.Fatalf("Failed to register saga: %v", err)
+	}
+
+	// Запросы статуса обслуживаются
+	status, err := orchestrator.GetStatus(ctx, "test-id")
+	if err != nil || status != SagaStatusPending {
+		t.Errorf("Expected status Pending, got %s (%v)", status, err)
+	}
+
+	// Выполнение запрещено
+	if _, err := orchestrator.StartSaga(ctx, "test-saga", NewSagaContext()); !errors.Is(err, ErrReadOnlyOrchestrator) {
+		t.Errorf("Expected ErrReadOnlyOrchestrator from StartSaga, got %v", err)
+	}
+	if err := orchestrator.Execute(ctx, saga); !errors.Is(err, ErrReadOnlyOrchestrator) {
+		t.Errorf("Expected ErrReadOnlyOrchestrator from Execute, got %v", err)
+	}
+	if err := orchestrator.Compensate(ctx, saga); !errors.Is(err, ErrReadOnlyOrchestrator) {
+		t.Errorf("Expected ErrReadOnlyOrchestrator from Compensate, got %v", err)
+	}
+	if err := orchestrator.Resume(ctx, "test-id"); !errors.Is(err, ErrReadOnlyOrchestrator) {
+		t.Errorf("Expected ErrReadOnlyOrchestrator from Resume, got %v", err)
+	}
+	if err := orchestrator.Cancel(ctx, "test-id"); !errors.Is(err, ErrReadOnlyOrchestrator) {
+		t.Errorf("Expected ErrReadOnlyOrchestrator from Cancel, got %v", err)
+	}
+	if saga.Status() != SagaStatusPending {
+		t.Errorf("Expected saga to stay Pending, got %s", saga.Status())
+	}
+
+	// Оркестратор поверх реплики event store доступен только для чтения до Promote
+	local := eventsourcing.NewInMemoryEventStore(eventsourcing.DefaultInMemoryEventStoreConfig())
+	replicator := eventsourcing.NewEventReplicator(
+		eventsourcing.NewInMemoryEventStore(eventsourcing.DefaultInMemoryEventStoreConfig()),
+		local,
+		eventsourcing.NewInMemoryCheckpointStore(),
+		eventsourcing.DefaultReplicationConfig(),
+	)
+	follower := eventsourcing.NewFollowerEventStore(local, replicator)
+	replica := NewDefaultOrchestrator(NewEventStorePersistence(follower, nil), nil)
+	if !replica.IsReadOnly() {
+		t.Error("Expected orchestrator over follower event store to be read-only")
+	}
+	if err := follower.Promote(ctx, eventsourcing.PromoteOptions{}); err != nil {
+		t.Fatalf("Failed to promote follower: %v", err)
+	}
+	if replica.IsReadOnly() {
+		t.Error("Expected orchestrator to become writable after promote")
+	}
+}
+
+func TestDefaultOrchestrator_StartConsistencyCheck(t *testing.T) {
+	ctx := context.Background()
+	persistence := NewInMemoryPersistence()
+
+	v1 := NewBaseSagaDefinition("orders")
+	v1.AddStep(NewBaseStep("reserve"))
+	v2 := NewBaseSagaDefinition("orders").WithVersion(2)
+	v2.AddStep(NewBaseStep("reserve"))
+	v2.AddStep(NewBaseStep("ship"))
+	unknown := NewBaseSagaDefinition("payments")
+	unknown.AddStep(NewBaseStep("charge"))
+
+	for id, definition := range map[string]*BaseSagaDefinition{"saga-1": v1, "saga-2": v2, "saga-3": unknown} {
+		instance, err := NewBaseSaga(id, definition, NewSagaContext(), persistence)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		instance.status = SagaStatusRunning
+		instance.currentStep = definition.Steps()[len(definition.Steps())-1].Name()
+		_ = persistence.Save(ctx, instance)
+	}
+
+	registry := NewSagaRegistry()
+	_ = registry.RegisterSaga("orders", v1)
+
+	// Fail fast: старт завершается ошибкой с отчетом
+	orchestrator := NewDefaultOrchestrator(persistence, nil).
+		WithRegistry(registry).
+		WithConsistencyCheck(ConsistencyCheckFailFast, nil)
+	err := orchestrator.Start(ctx)
+	if !errors.Is(err, ErrRegistryInconsistent) {
+		t.Fatalf("Expected ErrRegistryInconsistent, got %v", err)
+	}
+
+	// Warn: отчет передается обработчику, старт продолжается
+	var report *ConsistencyReport
+	orchestrator.WithConsistencyCheck(ConsistencyCheckWarn, func(r *ConsistencyReport) { report = r })
+	if err := orchestrator.Start(ctx); err != nil {
+		t.Fatalf("Expected no error in warn mode, got %v", err)
+	}
+	if report == nil || report.CheckedSagas != 3 || len(report.Issues) != 2 {
+		t.Fatalf("Expected 2 issues for 3 sagas, got %+v", report)
+	}
+	if report.Issues[0].SagaID != "saga-2" || report.Issues[1].SagaID != "saga-3" {
+		t.Errorf("Unexpected issues: %s", report)
+	}
+
+	// Без обработчика отчет доступен после старта
+	orchestrator.WithConsistencyCheck(ConsistencyCheckWarn, nil)
+	if err := orchestrator.Start(ctx); err != nil {
+		t.Fatalf("Expected no error in warn mode, got %v", err)
+	}
+	if last := orchestrator.LastConsistencyReport(); last == nil || len(last.Issues) != 2 {
+		t.Errorf("Expected last report with 2 issues, got %+v", last)
+	}
+
+	// После регистрации недостающих определений проблем нет
+	_ = registry.RegisterSaga("orders", v2)
+	_ = registry.RegisterSaga("payments", unknown)
+	orchestrator.WithConsistencyCheck(ConsistencyCheckFailFast, nil)
+	if err := orchestrator.Start(ctx); err != nil {
+		t.Errorf("Expected consistent registry, got %v", err)
+	}
+}
+
+func TestDefaultOrchestrator_DefinitionWarmUp(t *testing.T) {
+	ctx := context.Background()
+	persistence := NewInMemoryPersistence()
+
+	v1 := NewBaseSagaDefinition("orders")
+	v1.AddStep(NewBaseStep("reserve"))
+	v2 := NewBaseSagaDefinition("orders").WithVersion(2)
+	v2.AddStep(NewBaseStep("reserve"))
+	v2.AddStep(NewBaseStep("ship"))
+
+	registry := NewSagaRegistry()
+	_ = registry.RegisterSaga("orders", v1)
+	_ = registry.RegisterSaga("orders", v2)
+	if err := NewDefaultOrchestrator(persistence, nil).WithRegistry(registry).WithDefinitionWarmUp(true, nil).Start(ctx); err != nil {
+		t.Fatalf("Failed to warm up registry: %v", err)
+	}
+
+	// Деплой без кода версии 2: версия загружается из метаданных только для чтения
+	deployed := NewSagaRegistry()
+	_ = deployed.RegisterSaga("orders", v1)
+	var warmUp *WarmUpReport
+	var consistency *ConsistencyReport
+	orchestrator := NewDefaultOrchestrator(persistence, nil).
+		WithRegistry(deployed).
+		WithDefinitionWarmUp(true, func(r *WarmUpReport) { warmUp = r }).
+		WithConsistencyCheck(ConsistencyCheckWarn, func(r *ConsistencyReport) { consistency = r })
+
+	// Сага версии 2 в том виде, в каком ее восстанавливает persistence после деплоя
+	instance, err := NewBaseSaga("saga-2", newMissingSagaDefinition(DefinitionMetadataOf(v2)), NewSagaContext(), persistence)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	instance.status = SagaStatusRunning
+	instance.currentStep = "ship"
+	_ = persistence.Save(ctx, instance)
+
+	if err := orchestrator.Start(ctx); err != nil {
+		t.Fatalf("Expected start in warn mode, got %v", err)
+	}
+	if warmUp == nil || len(warmUp.Missing) != 1 || warmUp.Missing[0].Version != 2 {
+		t.Fatalf("Expected orders v2 to be missing, got %+v", warmUp)
+	}
+	if warmUp.Missing[0].RegisteredAt.IsZero() || len(warmUp.Missing[0].Steps) != 2 {
+		t.Errorf("Expected persisted metadata, got %+v", warmUp.Missing[0])
+	}
+	if orchestrator.LastWarmUpReport() != warmUp {
+		t.Errorf("Expected last warm-up report to match the reported one")
+	}
+	if consistency == nil || len(consistency.Issues) != 1 || !strings.Contains(consistency.Issues[0].Reason, "definition code is missing") {
+		t.Fatalf("Expected missing code issue, got %+v", consistency)
+	}
+
+	// Новые саги создаются по последней версии с кодом
+	if latest, _ := deployed.GetSaga("orders"); SagaDefinitionVersion(latest) != 1 {
+		t.Errorf("Expected latest available version 1, got %d", SagaDefinitionVersion(latest))
+	}
+
+	// Статус доступен, выполнение - нет
+	if status, err := orchestrator.GetStatus(ctx, "saga-2"); err != nil || status != SagaStatusRunning {
+		t.Errorf("Expected running status, got %s, %v", status, err)
+	}
+	if err := orchestrator.Resume(ctx, "saga-2"); !errors.Is(err, ErrSagaDefinitionMissing) {
+		t.Errorf("Expected ErrSagaDefinitionMissing, got %v", err)
+	}
+
+	// Регистрация кода заменяет определение только для чтения
+	_ = deployed.RegisterSaga("orders", v2)
+	definition, _ := deployed.GetSagaVersion("orders", 2)
+	if _, missing := MissingDefinitionMetadata(definition); missing {
+		t.Error("Expected registered definition to replace read-only one")
+	}
+}
+
+func newTestChoreography() *ChoreographyDefinition {
+	return NewChoreographyDefinition("order-choreography").
+		On("order.created", "reserve-stock", func(ctx context.Context, instance Saga, event events.Event) ([]transport.Command, error) {
+			return []transport.Command{&mockCommand{name: "reserve-stock"}}, nil
+		}).
+		On("stock.reserved", "charge-payment", func(ctx context.Context, instance Saga, event events.Event) ([]transport.Command, error) {
+			return []transport.Command{&mockCommand{name: "charge-payment"}}, nil
+		}).
+		On("payment.charged", "confirm-order", nil).
+		WithCompensation("reserve-stock", func(ctx context.Context, instance Saga) ([]transport.Command, error) {
+			return []transport.Command{&mockCommand{name: "release-stock"}}, nil
+		}).
+		WithCompensation("charge-payment", func(ctx context.Context, instance Saga) ([]transport.Command, error) {
+			return []transport.Command{&mockCommand{name: "refund-payment"}}, nil
+		}).
+		FailOn("payment.declined")
+}
+
+func TestChoreographer_HappyPath(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	eventBus := events.NewInMemoryEventBus()
+	commandBus := &mockCommandBus{}
+	choreographer := NewChoreographer(persistence, eventBus, commandBus)
+
+	if err := choreographer.Register(newTestChoreography()); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, eventType := range []string{"order.created", "stock.reserved", "stock.reserved", "payment.charged"} {
+		if err := eventBus.Publish(ctx, events.NewBaseEvent(eventType, "order-1").WithMetadata("saga_id", "saga-1")); err != nil {
+			t.Fatalf("Publish %s failed: %v", eventType, err)
+		}
+	}
+
+	status, err := choreographer.GetStatus(ctx, "saga-1")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status != SagaStatusCompleted {
+		t.Errorf("Expected status %s, got %s", SagaStatusCompleted, status)
+	}
+
+	instance, _ := persistence.Load(ctx, "saga-1")
+	if history := instance.GetHistory(); len(history) != 3 {
+		t.Errorf("Expected 3 history entries (duplicate event ignored), got %d", len(history))
+	}
+	if !commandBus.commands["reserve-stock"] || !commandBus.commands["charge-payment"] {
+		t.Errorf("Expected step commands to be sent, got %v", commandBus.commands)
+	}
+}
+
+func TestChoreographer_FailureEventCompensates(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	eventBus := events.NewInMemoryEventBus()
+	commandBus := &mockCommandBus{}
+	choreographer := NewChoreographer(persistence, eventBus, commandBus)
+
+	if err := choreographer.Register(newTestChoreography()); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	ctx := context.Background()
+	_ = eventBus.Publish(ctx, events.NewBaseEvent("order.created", "order-1").WithMetadata("saga_id", "saga-1"))
+	_ = eventBus.Publish(ctx, events.NewBaseEvent("payment.declined", "order-1").WithMetadata("saga_id", "saga-1"))
+
+	status, err := choreographer.GetStatus(ctx, "saga-1")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status != SagaStatusCompensated {
+		t.Errorf("Expected status %s, got %s", SagaStatusCompensated, status)
+	}
+	if !commandBus.commands["release-stock"] {
+		t.Error("Expected compensation command for completed step")
+	}
+	if commandBus.commands["refund-payment"] {
+		t.Error("Step that was not executed must not be compensated")
+	}
+}
+
+func TestChoreographer_Register_Validation(t *testing.T) {
+	choreographer := NewChoreographer(nil, events.NewInMemoryEventBus(), nil)
+
+	if err := choreographer.Register(NewChoreographyDefinition("empty")); err == nil {
+		t.Error("Expected error for definition without steps")
+	}
+
+	definition := NewChoreographyDefinition("conflict").
+		On("order.created", "step1", nil).
+		FailOn("order.created")
+	if err := choreographer.Register(definition); err == nil {
+		t.Error("Expected error for event that is both trigger and failure event")
+	}
+}
+
+func TestDefaultOrchestrator_CompensationRetryAndResume(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	mockEventBus := &mockEventBus{events: make([]events.Event, 0)}
+	orchestrator := NewDefaultOrchestrator(persistence, mockEventBus).
+		WithCompensationRetry(&RetryPolicy{MaxAttempts: 2, Backoff: 1.0})
+
+	var reserveCompensations, chargeCompensations int
+	chargeBroken := true
+
+	definition := NewBaseSagaDefinition("order-saga")
+	reserve := NewBaseStep("reserve")
+	reserve.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil }).
+		WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+			reserveCompensations++
+			return nil
+		})
+	charge := NewBaseStep("charge")
+	charge.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil }).
+		WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+			chargeCompensations++
+			if chargeBroken {
+				return errors.New("payment gateway unavailable")
+			}
+			return nil
+		}).
+		// Политика шага имеет приоритет над политикой оркестратора
+		WithCompensationRetry(&RetryPolicy{MaxAttempts: 3, Backoff: 1.0})
+	ship := NewBaseStep("ship")
+	ship.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		return errors.New("no courier")
+	})
+	definition.AddStep(reserve)
+	definition.AddStep(charge)
+	definition.AddStep(ship)
+
+	saga, err := NewBaseSaga("saga-1", definition, NewSagaContext(), persistence)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := orchestrator.Execute(ctx, saga); err == nil {
+		t.Fatal("Expected saga to fail")
+	}
+	if saga.Status() != SagaStatusCompensationStuck {
+		t.Fatalf("Expected status %s, got %s", SagaStatusCompensationStuck, saga.Status())
+	}
+	if chargeCompensations != 3 || reserveCompensations != 0 {
+		t.Fatalf("Expected 3 compensation attempts of charge and none of reserve, got %d and %d", chargeCompensations, reserveCompensations)
+	}
+
+	stuck := false
+	for _, event := range mockEventBus.events {
+		if e, ok := event.(*SagaCompensationStuckEvent); ok && e.StepName == "charge" && e.Attempts == 3 {
+			stuck = true
+		}
+	}
+	if !stuck {
+		t.Error("Expected SagaCompensationStuck event for step charge")
+	}
+
+	// Причина ошибки устранена вручную
+	chargeBroken = false
+
+	if err := orchestrator.ResumeCompensation(ctx, "saga-1"); err != nil {
+		t.Fatalf("ResumeCompensation failed: %v", err)
+	}
+	if saga.Status() != SagaStatusCompensated {
+		t.Errorf("Expected status Compensated, got %s", saga.Status())
+	}
+	if chargeCompensations != 4 || reserveCompensations != 1 {
+		t.Errorf("Expected charge and reserve to be compensated once after resume, got %d and %d", chargeCompensations, reserveCompensations)
+	}
+
+	if err := orchestrator.ResumeCompensation(ctx, "saga-1"); err == nil {
+		t.Error("Expected error when resuming compensation of compensated saga")
+	}
+}
+
+// recordingInterceptor записывает вызовы перехватчика шагов
+type recordingInterceptor struct {
+	BaseStepInterceptor
+	name     string
+	calls    *[]string
+	disabled map[string]bool
+}
+
+func (i *recordingInterceptor) Before(ctx context.Context, invocation StepInvocation) error {
+	*i.calls = append(*i.calls, i.name+".before:"+invocation.Step.Name())
+	if i.disabled[invocation.Step.Name()] {
+		return NewBusinessError("step_disabled", errors.New("step disabled by feature flag"))
+	}
+	return nil
+}
+
+func (i *recordingInterceptor) After(ctx context.Context, invocation StepInvocation) {
+	*i.calls = append(*i.calls, i.name+".after:"+invocation.Step.Name())
+}
+
+func (i *recordingInterceptor) OnError(ctx context.Context, invocation StepInvocation, err error) error {
+	*i.calls = append(*i.calls, i.name+".error:"+invocation.Step.Name())
+	return err
+}
+
+func (i *recordingInterceptor) OnCompensate(ctx context.Context, invocation StepInvocation, err error) error {
+	*i.calls = append(*i.calls, i.name+".compensate:"+invocation.Step.Name())
+	return err
+}
+
+func TestDefaultOrchestrator_StepInterceptors(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	var calls []string
+	shipExecuted := false
+
+	orchestrator := NewDefaultOrchestrator(persistence, nil).
+		WithStepInterceptors(&recordingInterceptor{name: "audit", calls: &calls})
+
+	definition := NewBaseSagaDefinition("order-saga")
+	definition.WithInterceptors(&recordingInterceptor{name: "flags", calls: &calls, disabled: map[string]bool{"ship": true}})
+	reserve := NewBaseStep("reserve")
+	reserve.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil }).
+		WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error { return nil })
+	ship := NewBaseStep("ship")
+	ship.WithRetry(SimpleRetry(3))
+	ship.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		shipExecuted = true
+		return nil
+	})
+	definition.AddStep(reserve)
+	definition.AddStep(ship)
+
+	saga, err := NewBaseSaga("saga-1", definition, NewSagaContext(), persistence)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := orchestrator.Execute(context.Background(), saga); err == nil {
+		t.Fatal("Expected saga to fail")
+	}
+	if shipExecuted {
+		t.Error("Expected disabled step not to be executed")
+	}
+	if saga.Status() != SagaStatusCompensated {
+		t.Errorf("Expected status Compensated, got %s", saga.Status())
+	}
+
+	expected := []string{
+		"audit.before:reserve", "flags.before:reserve", "flags.after:reserve", "audit.after:reserve",
+		// Ошибка Before неповторяемая: шаг не повторяется по политике SimpleRetry(3)
+		"audit.before:ship", "flags.before:ship", "flags.error:ship", "audit.error:ship",
+		"flags.compensate:reserve", "audit.compensate:reserve",
+	}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestDefaultOrchestrator_StepDeadline(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	escalated := make(chan *StepDeadlineExceededEvent, 2)
+	orchestrator := NewDefaultOrchestrator(persistence, nil).
+		WithEscalationHandler(func(ctx context.Context, event *StepDeadlineExceededEvent) {
+			escalated <- event
+		})
+
+	newDefinition := func(escalation DeadlineEscalation, execute func(ctx context.Context, sagaCtx SagaContext) error) (*BaseSagaDefinition, *bool) {
+		compensated := false
+		reserve := NewBaseStep("reserve")
+		reserve.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil }).
+			WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+				compensated = true
+				return nil
+			})
+		charge := NewBaseStep("charge")
+		charge.WithExecute(execute).WithRetry(SimpleRetry(100)).WithDeadline(50*time.Millisecond, escalation)
+
+		definition := NewBaseSagaDefinition("payment-saga")
+		definition.AddStep(reserve)
+		definition.AddStep(charge)
+		return definition, &compensated
+	}
+
+	// Зависший шаг прерывается дедлайном (с учетом повторов) и сага компенсируется
+	definition, compensated := newDefinition(EscalateCompensate, func(ctx context.Context, sagaCtx SagaContext) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	saga, err := NewBaseSaga("saga-deadline", definition, NewSagaContext(), persistence)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := orchestrator.Execute(context.Background(), saga); !errors.Is(err, ErrStepDeadlineExceeded) {
+		t.Fatalf("Expected ErrStepDeadlineExceeded, got %v", err)
+	}
+	if saga.Status() != SagaStatusCompensated || !*compensated {
+		t.Errorf("Expected compensated saga, got %s (compensated: %v)", saga.Status(), *compensated)
+	}
+	for _, entry := range saga.GetHistory() {
+		if entry.StepName == "charge" && (entry.Failure == nil || entry.Failure.Code != FailureCodeStepDeadline) {
+			t.Errorf("Expected step deadline failure, got %+v", entry.Failure)
+		}
+	}
+	select {
+	case event := <-escalated:
+		if event.StepName != "charge" || event.Escalation != EscalateCompensate || event.Deadline != 50*time.Millisecond {
+			t.Errorf("Unexpected escalation event: %+v", event)
+		}
+	default:
+		t.Fatal("Expected escalation handler to be called")
+	}
+
+	// EscalateNotify только уведомляет: медленный шаг завершается успешно
+	definition, compensated = newDefinition(EscalateNotify, func(ctx context.Context, sagaCtx SagaContext) error {
+		time.Sleep(100 * time.Millisecond)
+		return ctx.Err()
+	})
+	saga, err = NewBaseSaga("saga-deadline-notify", definition, NewSagaContext(), persistence)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := orchestrator.Execute(context.Background(), saga); err != nil {
+		t.Fatalf("Expected slow step to complete, got %v", err)
+	}
+	if saga.Status() != SagaStatusCompleted || *compensated {
+		t.Errorf("Expected completed saga, got %s", saga.Status())
+	}
+	if len(escalated) != 1 {
+		t.Errorf("Expected one escalation for slow step, got %d", len(escalated))
+	}
+}
+
+func TestDefaultOrchestrator_Approve(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	mockEventBus := &mockEventBus{events: make([]events.Event, 0)}
+	orchestrator := NewDefaultOrchestrator(persistence, mockEventBus)
+
+	newSaga := func(id string) (*BaseSaga, *int, *int) {
+		var shipped, reserveCompensations int
+		definition := NewBaseSagaDefinition("refund-saga")
+		reserve := NewBaseStep("reserve")
+		reserve.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil }).
+			WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+				reserveCompensations++
+				return nil
+			})
+		ship := NewBaseStep("ship")
+		ship.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+			shipped++
+			return nil
+		})
+		definition.AddStep(reserve)
+		definition.AddStep(NewWaitForApprovalStep("manager-approval"))
+		definition.AddStep(ship)
+
+		saga, err := NewBaseSaga(id, definition, NewSagaContext(), persistence)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		return saga, &shipped, &reserveCompensations
+	}
+
+	ctx := context.Background()
+
+	// Одобрение продолжает сагу со следующего шага
+	saga, shipped, _ := newSaga("saga-approved")
+	if err := orchestrator.Execute(ctx, saga); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if saga.Status() != SagaStatusWaitingApproval || saga.CurrentStep() != "manager-approval" {
+		t.Fatalf("Expected saga waiting for approval at manager-approval, got %s at %s", saga.Status(), saga.CurrentStep())
+	}
+	if *shipped != 0 {
+		t.Fatal("Expected ship step not to run before approval")
+	}
+
+	requested := false
+	for _, event := range mockEventBus.events {
+		if e, ok := event.(*ApprovalRequestedEvent); ok && e.SagaID == "saga-approved" && e.StepName == "manager-approval" {
+			requested = true
+		}
+		if _, ok := event.(*SagaCompletedEvent); ok {
+			t.Fatal("Expected no SagaCompleted event while waiting for approval")
+		}
+	}
+	if !requested {
+		t.Error("Expected ApprovalRequested event")
+	}
+
+	if err := orchestrator.Resume(ctx, "saga-approved"); err == nil {
+		t.Error("Expected Resume to reject saga waiting for approval")
+	}
+	if err := orchestrator.Approve(ctx, "saga-approved", "ship", ApprovalDecision{Approved: true}); err == nil {
+		t.Error("Expected error when approving a step the saga is not waiting on")
+	}
+
+	decision := ApprovalDecision{Approved: true, Approver: "alice", Comment: "ok"}
+	if err := orchestrator.Approve(ctx, "saga-approved", "manager-approval", decision); err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if saga.Status() != SagaStatusCompleted || *shipped != 1 {
+		t.Fatalf("Expected completed saga with ship executed once, got %s and %d", saga.Status(), *shipped)
+	}
+	if got, ok := ApprovalDecisionOf(saga.Context(), "manager-approval"); !ok || got != decision {
+		t.Errorf("Expected stored decision %+v, got %+v", decision, got)
+	}
+	if err := orchestrator.Approve(ctx, "saga-approved", "manager-approval", decision); err == nil {
+		t.Error("Expected error when approving completed saga")
+	}
+
+	// Отклонение компенсирует выполненные шаги
+	saga, shipped, reserveCompensations := newSaga("saga-rejected")
+	if err := orchestrator.Execute(ctx, saga); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if err := orchestrator.Approve(ctx, "saga-rejected", "manager-approval", ApprovalDecision{Approver: "bob", Comment: "too expensive"}); err != nil {
+		t.Fatalf("Approve (reject) failed: %v", err)
+	}
+	if saga.Status() != SagaStatusCompensated {
+		t.Fatalf("Expected status Compensated, got %s", saga.Status())
+	}
+	if *shipped != 0 || *reserveCompensations != 1 {
+		t.Errorf("Expected ship not executed and reserve compensated once, got %d and %d", *shipped, *reserveCompensations)
+	}
+}
+
+func TestDefaultOrchestrator_SendMessage(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	mockEventBus := &mockEventBus{events: make([]events.Event, 0)}
+	orchestrator := NewDefaultOrchestrator(persistence, mockEventBus)
+	ctx := context.Background()
+
+	newBillingSaga := func(id, correlationID string) (*BaseSaga, *[]string) {
+		var charged []string
+		definition := NewBaseSagaDefinition("billing-saga")
+		charge := NewBaseStep("charge").WithReadAccess("await-shipment")
+		charge.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+			message, ok := ReceivedMessageOf(sagaCtx, "await-shipment")
+			if !ok {
+				return errors.New("shipment message not received")
+			}
+			charged = append(charged, message.Payload["tracking"].(string))
+			return nil
+		})
+		definition.AddStep(NewWaitForMessageStep("await-shipment", "shipment.shipped"))
+		definition.AddStep(charge)
+
+		sagaCtx := NewSagaContext()
+		sagaCtx.SetCorrelationID(correlationID)
+		saga, err := NewBaseSaga(id, definition, sagaCtx, persistence)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		return saga, &charged
+	}
+
+	// Сообщение продолжает сагу, ожидающую его на текущем шаге
+	billing, charged := newBillingSaga("billing-1", "order-1")
+	if err := orchestrator.Execute(ctx, billing); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if billing.Status() != SagaStatusWaitingMessage || billing.CurrentStep() != "await-shipment" {
+		t.Fatalf("Expected saga waiting for message at await-shipment, got %s at %s", billing.Status(), billing.CurrentStep())
+	}
+
+	message := SagaMessage{
+		ID:         "shipment-1-shipped",
+		Type:       "shipment.shipped",
+		FromSagaID: "shipment-1",
+		Payload:    map[string]interface{}{"tracking": "TRK-1"},
+	}
+	delivered, err := orchestrator.SendMessage(ctx, ToCorrelation("order-1"), message)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if len(delivered) != 1 || delivered[0] != "billing-1" {
+		t.Fatalf("Expected message delivered to billing-1, got %v", delivered)
+	}
+	if billing.Status() != SagaStatusCompleted {
+		t.Fatalf("Expected billing saga completed, got %s", billing.Status())
+	}
+	if len(*charged) != 1 || (*charged)[0] != "TRK-1" {
+		t.Fatalf("Expected charge with tracking TRK-1, got %v", *charged)
+	}
+
+	awaited, received := false, false
+	for _, event := range mockEventBus.events {
+		if e, ok := event.(*MessageAwaitedEvent); ok && e.SagaID == "billing-1" && e.MessageType == "shipment.shipped" {
+			awaited = true
+		}
+		if e, ok := event.(*SagaMessageReceivedEvent); ok && e.SagaID == "billing-1" && e.StepName == "await-shipment" {
+			received = true
+		}
+	}
+	if !awaited || !received {
+		t.Errorf("Expected MessageAwaited and SagaMessageReceived events, got awaited=%v received=%v", awaited, received)
+	}
+
+	// Завершенной саге сообщение не доставляется
+	if _, err := orchestrator.SendMessage(ctx, ToSaga("billing-1"), message); !errors.Is(err, ErrSagaMessageUndeliverable) {
+		t.Errorf("Expected ErrSagaMessageUndeliverable for completed saga, got %v", err)
+	}
+
+	// Сообщение, доставленное до шага ожидания, сохраняется и получается шагом без остановки саги
+	early, earlyCharged := newBillingSaga("billing-2", "order-2")
+	if err := persistence.Save(ctx, early); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	message.ID = "shipment-2-shipped"
+	message.Payload = map[string]interface{}{"tracking": "TRK-2"}
+	for i := 0; i < 2; i++ {
+		if _, err := orchestrator.SendMessage(ctx, ToSaga("billing-2"), message); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+	if pending := PendingMessages(early.Context()); len(pending) != 1 || pending[0].ID != "shipment-2-shipped" {
+		t.Fatalf("Expected one pending message after duplicate send, got %v", pending)
+	}
+	if err := orchestrator.Execute(ctx, early); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if early.Status() != SagaStatusCompleted || len(*earlyCharged) != 1 || (*earlyCharged)[0] != "TRK-2" {
+		t.Fatalf("Expected early message to complete saga, got %s with charges %v", early.Status(), *earlyCharged)
+	}
+	if pending := PendingMessages(early.Context()); len(pending) != 0 {
+		t.Errorf("Expected mailbox to be empty after step received message, got %v", pending)
+	}
+}
+
+func TestDefaultOrchestrator_CancelRunningStep(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	orchestrator := NewDefaultOrchestrator(persistence, nil)
+
+	newSaga := func(id string) (*BaseSaga, chan struct{}, *int) {
+		started := make(chan struct{})
+		var reserveCompensations int
+		definition := NewBaseSagaDefinition("cancel-saga")
+		reserve := NewBaseStep("reserve")
+		reserve.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil }).
+			WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				reserveCompensations++
+				return nil
+			})
+		// Шаг ожидает внешнего ответа и учитывает отмену контекста
+		wait := NewBaseStep("wait")
+		wait.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		definition.AddStep(reserve)
+		definition.AddStep(wait)
+		definition.AddStep(NewBaseStep("ship"))
+
+		saga, err := NewBaseSaga(id, definition, NewSagaContext(), persistence)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		return saga, started, &reserveCompensations
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name  string
+		stop  func(saga Saga) error
+		cause error
+	}{
+		{"cancel", func(saga Saga) error { return orchestrator.Cancel(ctx, saga.ID()) }, ErrSagaCancelled},
+		{"compensate", func(saga Saga) error { return orchestrator.Compensate(ctx, saga) }, ErrCompensationRequested},
+	} {
+		saga, started, reserveCompensations := newSaga("saga-" + tc.name)
+		done := make(chan error, 1)
+		go func() { done <- orchestrator.Execute(ctx, saga) }()
+		<-started
+
+		if err := tc.stop(saga); err != nil {
+			t.Fatalf("%s: stop failed: %v", tc.name, err)
+		}
+		if err := <-done; !errors.Is(err, tc.cause) {
+			t.Fatalf("%s: expected Execute error %v, got %v", tc.name, tc.cause, err)
+		}
+
+		if saga.Status() != SagaStatusCompensated {
+			t.Errorf("%s: expected compensated saga, got %s", tc.name, saga.Status())
+		}
+		if *reserveCompensations != 1 {
+			t.Errorf("%s: expected reserve compensated once, got %d", tc.name, *reserveCompensations)
+		}
+		for _, hist := range saga.GetHistory() {
+			if hist.StepName == "wait" && hist.Status != StepStatusCancelled {
+				t.Errorf("%s: expected wait step cancelled, got %s", tc.name, hist.Status)
+			}
+			if hist.StepName == "ship" {
+				t.Errorf("%s: expected ship step not to run", tc.name)
+			}
+		}
+		if len(orchestrator.RunningSagas()) != 0 {
+			t.Errorf("%s: expected no running sagas, got %v", tc.name, orchestrator.RunningSagas())
+		}
+	}
+
+	if err := orchestrator.Cancel(ctx, "saga-cancel"); err == nil {
+		t.Error("Expected error when cancelling saga that is not running")
+	}
+}
+
+func TestDefaultOrchestrator_ListStuck(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	orchestrator := NewDefaultOrchestrator(persistence, nil)
+
+	started := make(chan struct{})
+	beat := make(chan struct{})
+	beaten := make(chan struct{})
+	definition := NewBaseSagaDefinition("stuck-saga")
+	reserve := NewBaseStep("reserve")
+	reserve.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil })
+	definition.AddStep(reserve)
+	// Долгий шаг отмечает прогресс heartbeat и ждет отмены
+	wait := NewBaseStep("wait")
+	wait.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		close(started)
+		<-beat
+		Heartbeat(sagaCtx)
+		close(beaten)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	definition.AddStep(wait)
+
+	saga, err := NewBaseSaga("saga-stuck", definition, NewSagaContext(), persistence)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Execute(ctx, saga) }()
+	select {
+	case <-started:
+	case err := <-done:
+		t.Fatalf("Execute finished before wait step: %v", err)
+	}
+
+	stuck, err := orchestrator.ListStuck(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("ListStuck failed: %v", err)
+	}
+	if len(stuck) != 0 {
+		t.Fatalf("Expected no stuck sagas within window, got %v", stuck)
+	}
+
+	var handled []StuckSaga
+	monitor := NewStuckSagaMonitor(orchestrator, StuckSagaMonitorConfig{Window: 0, CheckInterval: time.Hour}).
+		OnStuck(func(ctx context.Context, stuck StuckSaga) { handled = append(handled, stuck) })
+
+	reported, err := monitor.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(reported) != 1 || len(handled) != 1 {
+		t.Fatalf("Expected one stuck saga reported, got %v", reported)
+	}
+	first := reported[0]
+	if first.SagaID != "saga-stuck" || first.DefinitionName != "stuck-saga" || first.Status != SagaStatusRunning || !first.Local {
+		t.Errorf("Unexpected stuck saga: %+v", first)
+	}
+
+	// Без нового прогресса повторно не сообщается
+	if reported, _ := monitor.Check(ctx); len(reported) != 0 {
+		t.Errorf("Expected stuck saga not to be reported twice, got %v", reported)
+	}
+
+	// Heartbeat сдвигает время последнего прогресса
+	close(beat)
+	<-beaten
+	reported, _ = monitor.Check(ctx)
+	if len(reported) != 1 || !reported[0].LastProgressAt.After(first.LastProgressAt) {
+		t.Errorf("Expected stuck saga reported again after heartbeat, got %v", reported)
+	}
+
+	if err := orchestrator.Cancel(ctx, saga.ID()); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	<-done
+	if stuck, _ := orchestrator.ListStuck(ctx, 0); len(stuck) != 0 {
+		t.Errorf("Expected compensated saga not to be stuck, got %v", stuck)
+	}
+}
+
+// recordingPublisher запоминает опубликованные сообщения
+type recordingPublisher struct {
+	subjects []string
+	headers  []map[string]string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, subject string, data []byte, headers map[string]string) error {
+	p.subjects = append(p.subjects, subject)
+	p.headers = append(p.headers, headers)
+	return nil
+}
+
+// failingScheduleStore хранилище отложенных команд, не сохраняющее команды
+type failingScheduleStore struct {
+	*invoke.InMemoryScheduleStore
+}
+
+func (s *failingScheduleStore) Save(ctx context.Context, cmd *invoke.ScheduledCommand) error {
+	return errors.New("outbox unavailable")
+}
+
+func TestDefaultOrchestrator_StartSagaWithCommand(t *testing.T) {
+	ctx := context.Background()
+	definition := NewBaseSagaDefinition("outbox-saga")
+	step := NewBaseStep("await_reply")
+	step.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil })
+	definition.AddStep(step)
+
+	store := invoke.NewInMemoryScheduleStore()
+	publisher := &recordingPublisher{}
+	scheduler := invoke.NewCommandScheduler(store, publisher)
+
+	// Без persistence с outbox сага не создается
+	plain := NewDefaultOrchestrator(NewInMemoryPersistence(), nil).WithOutbox(scheduler)
+	if err := plain.RegisterSaga("outbox-saga", definition); err != nil {
+		t.Fatalf("Failed to register saga: %v", err)
+	}
+	if _, err := plain.StartSagaWithCommand(ctx, "outbox-saga", NewSagaContext(), &mockCommand{name: "reserve"}); !errors.Is(err, ErrSagaOutboxNotSupported) {
+		t.Errorf("Expected ErrSagaOutboxNotSupported, got %v", err)
+	}
+
+	// Ошибка outbox не оставляет сохраненной саги
+	failing := NewInMemoryPersistence().WithOutbox(&failingScheduleStore{invoke.NewInMemoryScheduleStore()})
+	failingOrchestrator := NewDefaultOrchestrator(failing, nil).WithOutbox(scheduler)
+	if err := failingOrchestrator.RegisterSaga("outbox-saga", definition); err != nil {
+		t.Fatalf("Failed to register saga: %v", err)
+	}
+	if _, err := failingOrchestrator.StartSagaWithCommand(ctx, "outbox-saga", NewSagaContext(), &mockCommand{name: "reserve"}); err == nil {
+		t.Error("Expected error when outbox command is not saved")
+	}
+	if sagas, _ := failing.LoadAll(ctx, SagaStatusPending); len(sagas) != 0 {
+		t.Errorf("Expected no saga saved without its command, got %d", len(sagas))
+	}
+
+	persistence := NewInMemoryPersistence().WithOutbox(store)
+	orchestrator := NewDefaultOrchestrator(persistence, nil).WithOutbox(scheduler)
+	if err := orchestrator.RegisterSaga("outbox-saga", definition); err != nil {
+		t.Fatalf("Failed to register saga: %v", err)
+	}
+	sagaCtx := NewSagaContext()
+	instance, err := orchestrator.StartSagaWithCommand(ctx, "outbox-saga", sagaCtx, &mockCommand{name: "reserve"})
+	if err != nil {
+		t.Fatalf("StartSagaWithCommand failed: %v", err)
+	}
+	if _, err := persistence.Load(ctx, instance.ID()); err != nil {
+		t.Errorf("Expected saga saved with its command: %v", err)
+	}
+
+	dispatched, err := scheduler.DispatchDue(ctx)
+	if err != nil || dispatched != 1 {
+		t.Fatalf("Expected initial command dispatched from outbox, got %d (%v)", dispatched, err)
+	}
+	headers := publisher.headers[0]
+	if publisher.subjects[0] != "commands.reserve" {
+		t.Errorf("Unexpected subject %q", publisher.subjects[0])
+	}
+	if headers["saga_id"] != instance.ID() || headers["correlation_id"] != sagaCtx.CorrelationID() || headers[invoke.CommandSourceKey] != string(invoke.CommandSourceSaga) {
+		t.Errorf("Unexpected initial command headers: %v", headers)
+	}
+}
@@ -0,0 +1,129 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akriventsev/potter/framework/invoke"
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// ErrSagaOutboxNotSupported возникает, если оркестратор или persistence не поддерживают
+// атомарное сохранение саги и начальной команды (StartSagaWithCommand)
+var ErrSagaOutboxNotSupported = errors.New("saga outbox is not supported")
+
+// SagaOutboxPersistence реализуется persistence, которые сохраняют сагу и команды outbox
+// атомарно. Команды записываются в хранилище отложенных команд (таблица scheduled_commands)
+// и отправляются dispatcher'ом invoke.CommandScheduler после коммита.
+type SagaOutboxPersistence interface {
+	// SaveWithCommands сохраняет сагу и ставит команды в outbox: либо сохраняется все, либо ничего
+	SaveWithCommands(ctx context.Context, saga Saga, commands ...*invoke.ScheduledCommand) error
+}
+
+// WithOutbox устанавливает планировщик, который сериализует начальные команды саг и отправляет
+// их из outbox (StartSagaWithCommand). Dispatcher планировщика должен быть запущен и работать
+// с тем же хранилищем, в которое persistence записывает команды.
+func (o *DefaultOrchestrator) WithOutbox(scheduler *invoke.CommandScheduler) *DefaultOrchestrator {
+	o.outbox = scheduler
+	return o
+}
+
+// StartSagaWithCommand создает сагу, атомарно сохраняет ее вместе с начальной командой в outbox
+// и запускает выполнение, как StartSaga. Сага не может оказаться сохраненной без команды
+// (и наоборот) при падении процесса между сохранением и публикацией: команду отправит
+// dispatcher CommandScheduler, а сохраненную сагу можно возобновить через Resume.
+// Команда публикуется at-least-once с correlation ID саги и заголовком saga_id.
+// Требует WithOutbox и persistence с SagaOutboxPersistence.
+func (o *DefaultOrchestrator) StartSagaWithCommand(ctx context.Context, definitionName string, sagaCtx SagaContext, initialCmd transport.Command) (Saga, error) {
+	if err := o.checkWritable("start saga", definitionName); err != nil {
+		return nil, err
+	}
+	if o.outbox == nil {
+		return nil, fmt.Errorf("%w: outbox scheduler not configured", ErrSagaOutboxNotSupported)
+	}
+	outbox, ok := o.persistence.(SagaOutboxPersistence)
+	if !ok {
+		return nil, fmt.Errorf("%w: persistence %T does not implement SagaOutboxPersistence", ErrSagaOutboxNotSupported, o.persistence)
+	}
+
+	instance, err := o.newInstance(ctx, definitionName, sagaCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdCtx := invoke.WithCorrelationID(ctx, instance.Context().CorrelationID())
+	scheduled, err := o.outbox.Prepare(cmdCtx, initialCmd, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare initial command: %w", err)
+	}
+	scheduled.Headers[invoke.CommandSourceKey] = string(invoke.CommandSourceSaga)
+	scheduled.Headers["saga_id"] = instance.ID()
+
+	if err := outbox.SaveWithCommands(ctx, instance, scheduled); err != nil {
+		return nil, fmt.Errorf("failed to save saga with initial command: %w", err)
+	}
+
+	o.launch(ctx, instance)
+	return instance, nil
+}
+
+// WithOutbox устанавливает хранилище отложенных команд, в которое SaveWithCommands
+// записывает команды outbox (то же хранилище, что у CommandScheduler)
+func (p *InMemoryPersistence) WithOutbox(store invoke.ScheduleStore) *InMemoryPersistence {
+	p.outbox = store
+	return p
+}
+
+// SaveWithCommands сохраняет сагу и команды outbox (реализация SagaOutboxPersistence).
+// Если команду не удалось сохранить, предыдущее состояние саги восстанавливается.
+func (p *InMemoryPersistence) SaveWithCommands(ctx context.Context, saga Saga, commands ...*invoke.ScheduledCommand) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.outbox == nil {
+		return fmt.Errorf("%w: outbox store not configured", ErrSagaOutboxNotSupported)
+	}
+
+	previous, existed := p.sagas[saga.ID()]
+	p.sagas[saga.ID()] = saga
+	for _, cmd := range commands {
+		if err := p.outbox.Save(ctx, cmd); err != nil {
+			if existed {
+				p.sagas[saga.ID()] = previous
+			} else {
+				delete(p.sagas, saga.ID())
+			}
+			return fmt.Errorf("failed to save outbox command %s: %w", cmd.ID, err)
+		}
+	}
+	return nil
+}
+
+// SaveWithCommands сохраняет сагу и команды outbox в одной транзакции (реализация
+// SagaOutboxPersistence). Команды записываются в таблицу scheduled_commands той же базы
+// (migrations invoke), их отправляет CommandScheduler с PostgresScheduleStore.
+func (p *PostgresPersistence) SaveWithCommands(ctx context.Context, saga Saga, commands ...*invoke.ScheduledCommand) error {
+	tx, err := p.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := p.save(ctx, tx, saga); err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if err := invoke.SaveScheduledCommandTx(ctx, tx, cmd); err != nil {
+			return fmt.Errorf("failed to save outbox command %s: %w", cmd.ID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
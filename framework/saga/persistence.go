@@ -4,13 +4,16 @@ package saga
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/akriventsev/potter/framework/events"
 	"github.com/akriventsev/potter/framework/eventsourcing"
+	"github.com/akriventsev/potter/framework/invoke"
 )
 
 // SagaPersistence интерфейс для сохранения состояния саг
@@ -29,17 +32,33 @@ type SagaPersistence interface {
 
 // InMemoryPersistence реализация persistence в памяти для тестирования
 type InMemoryPersistence struct {
-	mu    sync.RWMutex
-	sagas map[string]Saga
+	mu          sync.RWMutex
+	sagas       map[string]Saga
+	definitions map[sagaDefinitionKey]SagaDefinitionMetadata
+	registry    *SagaRegistry // реестр определений для импорта саг (ImportSaga)
+	outbox      invoke.ScheduleStore // outbox начальных команд (SaveWithCommands)
+}
+
+// sagaDefinitionKey ключ версии определения саги
+type sagaDefinitionKey struct {
+	name    string
+	version int
 }
 
 // NewInMemoryPersistence создает новую in-memory persistence
 func NewInMemoryPersistence() *InMemoryPersistence {
 	return &InMemoryPersistence{
-		sagas: make(map[string]Saga),
+		sagas:       make(map[string]Saga),
+		definitions: make(map[sagaDefinitionKey]SagaDefinitionMetadata),
 	}
 }
 
+// WithRegistry устанавливает реестр определений для импорта саг
+func (p *InMemoryPersistence) WithRegistry(registry *SagaRegistry) *InMemoryPersistence {
+	p.registry = registry
+	return p
+}
+
 func (p *InMemoryPersistence) Save(ctx context.Context, saga Saga) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -69,6 +88,61 @@ func (p *InMemoryPersistence) LoadAll(ctx context.Context, status SagaStatus) ([
 	return result, nil
 }
 
+// ListSagaRefs возвращает сведения о сагах с указанными статусами (реализация SagaRefLister)
+func (p *InMemoryPersistence) ListSagaRefs(ctx context.Context, statuses ...SagaStatus) ([]PersistedSagaRef, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var refs []PersistedSagaRef
+	for _, saga := range p.sagas {
+		if !sagaStatusIn(saga.Status(), statuses) {
+			continue
+		}
+		refs = append(refs, PersistedSagaRef{
+			SagaID:            saga.ID(),
+			DefinitionName:    saga.Definition().Name(),
+			DefinitionVersion: SagaDefinitionVersion(saga.Definition()),
+			Status:            saga.Status(),
+			CurrentStep:       saga.CurrentStep(),
+		})
+	}
+	return refs, nil
+}
+
+// SaveDefinitionMetadata сохраняет метаданные версий определений (реализация SagaDefinitionStore)
+func (p *InMemoryPersistence) SaveDefinitionMetadata(ctx context.Context, definitions []SagaDefinitionMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.definitions == nil {
+		p.definitions = make(map[sagaDefinitionKey]SagaDefinitionMetadata)
+	}
+	now := time.Now()
+	for _, metadata := range definitions {
+		key := sagaDefinitionKey{name: metadata.Name, version: metadata.Version}
+		metadata.Steps = append([]string(nil), metadata.Steps...)
+		metadata.RegisteredAt = now
+		if existing, ok := p.definitions[key]; ok {
+			metadata.RegisteredAt = existing.RegisteredAt
+		}
+		p.definitions[key] = metadata
+	}
+	return nil
+}
+
+// ListDefinitionMetadata возвращает метаданные сохраненных версий определений (реализация SagaDefinitionStore)
+func (p *InMemoryPersistence) ListDefinitionMetadata(ctx context.Context) ([]SagaDefinitionMetadata, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make([]SagaDefinitionMetadata, 0, len(p.definitions))
+	for _, metadata := range p.definitions {
+		metadata.Steps = append([]string(nil), metadata.Steps...)
+		result = append(result, metadata)
+	}
+	return result, nil
+}
+
 func (p *InMemoryPersistence) Delete(ctx context.Context, sagaID string) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -107,6 +181,15 @@ func NewEventStorePersistence(
 	}
 }
 
+// IsReadOnly проверяет, является ли event store репликой только для чтения
+// (реализация eventsourcing.ReadOnlyAware)
+func (p *EventStorePersistence) IsReadOnly() bool {
+	if aware, ok := p.eventStore.(eventsourcing.ReadOnlyAware); ok {
+		return aware.IsReadOnly()
+	}
+	return false
+}
+
 // WithRegistry устанавливает реестр саг
 func (p *EventStorePersistence) WithRegistry(registry *SagaRegistry) *EventStorePersistence {
 	p.registry = registry
@@ -199,7 +282,7 @@ func (p *EventStorePersistence) getExpectedVersionAndHistoryCount(ctx context.Co
 						eventType := storedEvent.EventType
 						if eventType == "StepStarted" || eventType == "StepCompleted" ||
 							eventType == "StepFailed" || eventType == "StepCompensating" ||
-							eventType == "StepCompensated" {
+							eventType == "StepCompensated" || eventType == "StepCancelled" || eventType == "StepSkipped" {
 							savedHistoryCount++
 						}
 					}
@@ -245,7 +328,7 @@ func (p *EventStorePersistence) getExpectedVersionAndHistoryCount(ctx context.Co
 						eventType := storedEvent.EventType
 						if eventType == "StepStarted" || eventType == "StepCompleted" ||
 							eventType == "StepFailed" || eventType == "StepCompensating" ||
-							eventType == "StepCompensated" {
+							eventType == "StepCompensated" || eventType == "StepCancelled" || eventType == "StepSkipped" {
 							savedHistoryCount++
 						}
 					}
@@ -297,7 +380,7 @@ func (p *EventStorePersistence) getExpectedVersionAndHistoryCount(ctx context.Co
 						eventType := storedEvent.EventType
 						if eventType == "StepStarted" || eventType == "StepCompleted" ||
 							eventType == "StepFailed" || eventType == "StepCompensating" ||
-							eventType == "StepCompensated" {
+							eventType == "StepCompensated" || eventType == "StepCancelled" || eventType == "StepSkipped" {
 							savedHistoryCount++
 						}
 					}
@@ -323,7 +406,7 @@ func (p *EventStorePersistence) getExpectedVersionAndHistoryCount(ctx context.Co
 			eventType := storedEvent.EventType
 			if eventType == "StepStarted" || eventType == "StepCompleted" ||
 				eventType == "StepFailed" || eventType == "StepCompensating" ||
-				eventType == "StepCompensated" {
+				eventType == "StepCompensated" || eventType == "StepCancelled" || eventType == "StepSkipped" {
 				savedHistoryCount++
 			}
 		}
@@ -343,11 +426,17 @@ func (p *EventStorePersistence) Save(ctx context.Context, saga Saga) error {
 	history := saga.GetHistory()
 	currentHistoryCount := len(history)
 	
+	contextData, err := encodeSagaContext(saga.Context())
+	if err != nil {
+		return fmt.Errorf("failed to encode context: %w", err)
+	}
+
 	stateEvent := events.NewBaseEvent("SagaStateChanged", sagaID)
 	stateEvent.WithMetadata("status", string(saga.Status()))
 	stateEvent.WithMetadata("step", saga.CurrentStep())
-	stateEvent.WithMetadata("context", saga.Context().ToMap())
+	stateEvent.WithMetadata("context", contextData)
 	stateEvent.WithMetadata("definition_name", saga.Definition().Name())
+	stateEvent.WithMetadata("definition_version", SagaDefinitionVersion(saga.Definition()))
 	stateEvent.WithMetadata("saved_history_count", currentHistoryCount) // Сохраняем текущее количество для оптимизации
 	stateEvent.WithCorrelationID(saga.Context().CorrelationID())
 	
@@ -376,6 +465,9 @@ func (p *EventStorePersistence) Save(ctx context.Context, saga Saga) error {
 				baseEvent.WithMetadata("duration_ms", duration.Milliseconds())
 			}
 			baseEvent.WithMetadata("retry_attempt", hist.RetryAttempt)
+			if hist.Branch != "" {
+				baseEvent.WithMetadata("branch", hist.Branch)
+			}
 		case StepStatusFailed:
 			// Событие ошибки шага
 			baseEvent = events.NewBaseEvent("StepFailed", sagaID)
@@ -388,7 +480,31 @@ func (p *EventStorePersistence) Save(ctx context.Context, saga Saga) error {
 				baseEvent.WithMetadata("error", hist.Error.Error())
 				baseEvent.WithMetadata("error_message", hist.Error.Error())
 			}
+			if hist.Failure != nil {
+				baseEvent.WithMetadata("failure", hist.Failure)
+			}
+			if hist.StackTrace != "" {
+				baseEvent.WithMetadata("stack_trace", hist.StackTrace)
+			}
 			baseEvent.WithMetadata("retry_attempt", hist.RetryAttempt)
+		case StepStatusCancelled:
+			// Событие прерывания шага отменой саги
+			baseEvent = events.NewBaseEvent("StepCancelled", sagaID)
+			baseEvent.WithMetadata("step_name", hist.StepName)
+			baseEvent.WithMetadata("started_at", hist.StartedAt.Format(time.RFC3339))
+			if hist.CompletedAt != nil {
+				baseEvent.WithMetadata("completed_at", hist.CompletedAt.Format(time.RFC3339))
+			}
+			if hist.Error != nil {
+				baseEvent.WithMetadata("reason", hist.Error.Error())
+			}
+			baseEvent.WithMetadata("retry_attempt", hist.RetryAttempt)
+		case StepStatusSkipped:
+			// Событие пропуска шага по условию или переходу по результату
+			baseEvent = events.NewBaseEvent("StepSkipped", sagaID)
+			baseEvent.WithMetadata("step_name", hist.StepName)
+			baseEvent.WithMetadata("started_at", hist.StartedAt.Format(time.RFC3339))
+			baseEvent.WithMetadata("branch", hist.Branch)
 		case StepStatusCompensating:
 			// Событие начала компенсации шага
 			baseEvent = events.NewBaseEvent("StepCompensating", sagaID)
@@ -566,6 +682,7 @@ func (p *EventStorePersistence) Load(ctx context.Context, sagaID string) (Saga,
 
 	// Восстанавливаем состояние из событий
 	var definitionName string
+	var definitionVersion int
 	var sagaStatus SagaStatus
 	var currentStep string
 	var sagaCtx SagaContext
@@ -588,7 +705,9 @@ func (p *EventStorePersistence) Load(ctx context.Context, sagaID string) (Saga,
 			if contextVal, ok := storedEvent.Metadata["context"]; ok {
 				if contextMap, ok := contextVal.(map[string]interface{}); ok {
 					sagaCtx = NewSagaContext()
-					sagaCtx.FromMap(contextMap)
+					if err := restoreSagaContext(sagaCtx, contextMap); err != nil {
+						return nil, fmt.Errorf("failed to restore context: %w", err)
+					}
 				}
 			}
 		}
@@ -603,6 +722,7 @@ func (p *EventStorePersistence) Load(ctx context.Context, sagaID string) (Saga,
 	if snapshot != nil && snapshot.Metadata != nil {
 		if defName, ok := snapshot.Metadata["definition_name"].(string); ok {
 			definitionName = defName
+			definitionVersion = metadataInt(snapshot.Metadata["definition_version"])
 		}
 	}
 
@@ -611,6 +731,7 @@ func (p *EventStorePersistence) Load(ctx context.Context, sagaID string) (Saga,
 		for _, storedEvent := range storedEvents {
 			if defName, ok := storedEvent.Metadata["definition_name"].(string); ok {
 				definitionName = defName
+				definitionVersion = metadataInt(storedEvent.Metadata["definition_version"])
 				break
 			}
 		}
@@ -621,8 +742,8 @@ func (p *EventStorePersistence) Load(ctx context.Context, sagaID string) (Saga,
 		return nil, fmt.Errorf("cannot determine saga definition for saga %s", sagaID)
 	}
 
-	// Получаем definition из registry
-	definition, err := p.registry.GetSaga(definitionName)
+	// Получаем из registry версию definition, с которой была запущена сага
+	definition, err := p.registry.GetSagaVersion(definitionName, definitionVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get saga definition %s: %w", definitionName, err)
 	}
@@ -674,6 +795,9 @@ func (p *EventStorePersistence) Load(ctx context.Context, sagaID string) (Saga,
 			} else if retryAttemptFloat, ok := storedEvent.Metadata["retry_attempt"].(float64); ok {
 				hist.RetryAttempt = int(retryAttemptFloat)
 			}
+			if branch, ok := storedEvent.Metadata["branch"].(string); ok {
+				hist.Branch = branch
+			}
 			
 		case "StepFailed":
 			hist.Status = StepStatusFailed
@@ -692,12 +816,39 @@ func (p *EventStorePersistence) Load(ctx context.Context, sagaID string) (Saga,
 			} else if errorMsg, ok := storedEvent.Metadata["error_message"].(string); ok && errorMsg != "" {
 				hist.Error = fmt.Errorf(errorMsg)
 			}
+			hist.Failure = failureFromValue(storedEvent.Metadata["failure"])
+			if stackTrace, ok := storedEvent.Metadata["stack_trace"].(string); ok {
+				hist.StackTrace = stackTrace
+			}
 			if retryAttempt, ok := storedEvent.Metadata["retry_attempt"].(int); ok {
 				hist.RetryAttempt = retryAttempt
 			} else if retryAttemptFloat, ok := storedEvent.Metadata["retry_attempt"].(float64); ok {
 				hist.RetryAttempt = int(retryAttemptFloat)
 			}
 			
+		case "StepSkipped":
+			hist.Status = StepStatusSkipped
+			hist.StartedAt = storedEvent.OccurredAt
+			hist.CompletedAt = &storedEvent.OccurredAt
+			if branch, ok := storedEvent.Metadata["branch"].(string); ok {
+				hist.Branch = branch
+			}
+
+		case "StepCancelled":
+			hist.Status = StepStatusCancelled
+			if completedAtStr, ok := storedEvent.Metadata["completed_at"].(string); ok {
+				if t, err := time.Parse(time.RFC3339, completedAtStr); err == nil {
+					hist.CompletedAt = &t
+				} else {
+					hist.CompletedAt = &storedEvent.OccurredAt
+				}
+			} else {
+				hist.CompletedAt = &storedEvent.OccurredAt
+			}
+			if reason, ok := storedEvent.Metadata["reason"].(string); ok && reason != "" {
+				hist.Error = errors.New(reason)
+			}
+
 		case "StepCompensating":
 			hist.Status = StepStatusCompensating
 			if hist.StartedAt.IsZero() {
@@ -796,6 +947,41 @@ func (p *EventStorePersistence) LoadAll(ctx context.Context, status SagaStatus)
 	return sagas, nil
 }
 
+// ListSagaRefs возвращает сведения о сагах с указанными статусами по последнему событию
+// SagaStateChanged каждой саги, не восстанавливая определения (реализация SagaRefLister)
+func (p *EventStorePersistence) ListSagaRefs(ctx context.Context, statuses ...SagaStatus) ([]PersistedSagaRef, error) {
+	storedEvents, err := p.eventStore.GetEventsByType(ctx, "SagaStateChanged", time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events by type: %w", err)
+	}
+
+	// Берем последнее состояние каждой саги
+	latest := make(map[string]eventsourcing.StoredEvent)
+	for _, storedEvent := range storedEvents {
+		if prev, ok := latest[storedEvent.AggregateID]; !ok || storedEvent.Version > prev.Version {
+			latest[storedEvent.AggregateID] = storedEvent
+		}
+	}
+
+	var refs []PersistedSagaRef
+	for sagaID, storedEvent := range latest {
+		statusStr, _ := storedEvent.Metadata["status"].(string)
+		if !sagaStatusIn(SagaStatus(statusStr), statuses) {
+			continue
+		}
+		definitionName, _ := storedEvent.Metadata["definition_name"].(string)
+		currentStep, _ := storedEvent.Metadata["step"].(string)
+		refs = append(refs, PersistedSagaRef{
+			SagaID:            sagaID,
+			DefinitionName:    definitionName,
+			DefinitionVersion: metadataInt(storedEvent.Metadata["definition_version"]),
+			Status:            SagaStatus(statusStr),
+			CurrentStep:       currentStep,
+		})
+	}
+	return refs, nil
+}
+
 func (p *EventStorePersistence) Delete(ctx context.Context, sagaID string) error {
 	// EventStore обычно не поддерживает удаление событий
 	return fmt.Errorf("Delete not supported for EventStorePersistence")
@@ -810,6 +996,11 @@ func (p *EventStorePersistence) GetHistory(ctx context.Context, sagaID string) (
 }
 
 func (p *EventStorePersistence) serializeSagaState(saga Saga) ([]byte, error) {
+	contextData, err := encodeSagaContext(saga.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode context: %w", err)
+	}
+
 	// Сериализуем историю с явной обработкой ошибок
 	history := saga.GetHistory()
 	historyData := make([]map[string]interface{}, len(history))
@@ -827,6 +1018,15 @@ func (p *EventStorePersistence) serializeSagaState(saga Saga) ([]byte, error) {
 		if hist.Error != nil {
 			histMap["error_message"] = hist.Error.Error()
 		}
+		if hist.Failure != nil {
+			histMap["failure"] = hist.Failure
+		}
+		if hist.StackTrace != "" {
+			histMap["stack_trace"] = hist.StackTrace
+		}
+		if hist.Branch != "" {
+			histMap["branch"] = hist.Branch
+		}
 		historyData[i] = histMap
 	}
 
@@ -834,9 +1034,10 @@ func (p *EventStorePersistence) serializeSagaState(saga Saga) ([]byte, error) {
 		"id":             saga.ID(),
 		"status":         string(saga.Status()),
 		"step":           saga.CurrentStep(),
-		"context":        saga.Context().ToMap(),
+		"context":        contextData,
 		"history":        historyData,
 		"definition":     saga.Definition().Name(),
+		"definition_version": SagaDefinitionVersion(saga.Definition()),
 		"correlation_id": saga.Context().CorrelationID(),
 	}
 	return json.Marshal(state)
@@ -851,6 +1052,7 @@ func (p *EventStorePersistence) deserializeSagaState(data []byte) (Saga, error)
 	// Извлекаем основные поля
 	sagaID, _ := state["id"].(string)
 	definitionName, _ := state["definition"].(string)
+	definitionVersion := metadataInt(state["definition_version"])
 	statusStr, _ := state["status"].(string)
 	currentStep, _ := state["step"].(string)
 
@@ -858,8 +1060,8 @@ func (p *EventStorePersistence) deserializeSagaState(data []byte) (Saga, error)
 		return nil, fmt.Errorf("invalid saga state: missing id or definition")
 	}
 
-	// Получаем definition из registry
-	definition, err := p.registry.GetSaga(definitionName)
+	// Получаем из registry версию definition, с которой была запущена сага
+	definition, err := p.registry.GetSagaVersion(definitionName, definitionVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get saga definition %s: %w", definitionName, err)
 	}
@@ -867,7 +1069,7 @@ func (p *EventStorePersistence) deserializeSagaState(data []byte) (Saga, error)
 	// Восстанавливаем контекст
 	sagaCtx := NewSagaContext()
 	if contextData, ok := state["context"].(map[string]interface{}); ok {
-		if err := sagaCtx.FromMap(contextData); err != nil {
+		if err := restoreSagaContext(sagaCtx, contextData); err != nil {
 			return nil, fmt.Errorf("failed to restore context: %w", err)
 		}
 	}
@@ -914,6 +1116,13 @@ func (p *EventStorePersistence) deserializeSagaState(data []byte) (Saga, error)
 				if errorMsg, ok := histMap["error_message"].(string); ok && errorMsg != "" {
 					hist.Error = fmt.Errorf(errorMsg)
 				}
+				hist.Failure = failureFromValue(histMap["failure"])
+				if stackTrace, ok := histMap["stack_trace"].(string); ok {
+					hist.StackTrace = stackTrace
+				}
+				if branch, ok := histMap["branch"].(string); ok {
+					hist.Branch = branch
+				}
 				history = append(history, hist)
 			}
 		}
@@ -959,11 +1168,26 @@ func (p *PostgresPersistence) WithRegistry(registry *SagaRegistry) *PostgresPers
 }
 
 func (p *PostgresPersistence) Save(ctx context.Context, saga Saga) error {
+	return p.save(ctx, p.conn, saga)
+}
+
+// postgresExecutor выполняет запрос через соединение или транзакцию
+type postgresExecutor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// save сохраняет сагу и историю шагов через exec
+func (p *PostgresPersistence) save(ctx context.Context, exec postgresExecutor, saga Saga) error {
 	sagaID := saga.ID()
 	definitionName := saga.Definition().Name()
+	definitionVersion := SagaDefinitionVersion(saga.Definition())
 	status := string(saga.Status())
 	currentStep := saga.CurrentStep()
-	contextJSON, err := json.Marshal(saga.Context().ToMap())
+	contextData, err := encodeSagaContext(saga.Context())
+	if err != nil {
+		return fmt.Errorf("failed to encode context: %w", err)
+	}
+	contextJSON, err := json.Marshal(contextData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal context: %w", err)
 	}
@@ -973,16 +1197,16 @@ func (p *PostgresPersistence) Save(ctx context.Context, saga Saga) error {
 
 	// Сохраняем или обновляем сагу
 	query := `
-		INSERT INTO saga_instances (id, definition_name, status, context, correlation_id, current_step, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO saga_instances (id, definition_name, status, context, correlation_id, current_step, created_at, updated_at, definition_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (id) DO UPDATE SET
 			status = $3,
 			context = $4,
 			current_step = $6,
 			updated_at = $8
 	`
-	_, err = p.conn.Exec(ctx, query,
-		sagaID, definitionName, status, contextJSON, correlationID, currentStep, now, now)
+	_, err = exec.Exec(ctx, query,
+		sagaID, definitionName, status, contextJSON, correlationID, currentStep, now, now, definitionVersion)
 	if err != nil {
 		return fmt.Errorf("failed to save saga: %w", err)
 	}
@@ -995,19 +1219,26 @@ func (p *PostgresPersistence) Save(ctx context.Context, saga Saga) error {
 		histID := fmt.Sprintf("%s:%s:%d", sagaID, hist.StepName, hist.StartedAt.UnixNano())
 		
 		histQuery := `
-			INSERT INTO saga_history (id, saga_id, step_name, status, error, retry_attempt, started_at, completed_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			INSERT INTO saga_history (id, saga_id, step_name, status, error, retry_attempt, started_at, completed_at, stack_trace, failure, branch)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 			ON CONFLICT (id) DO UPDATE SET
 				status = $4,
 				error = $5,
-				completed_at = $8
+				completed_at = $8,
+				stack_trace = $9,
+				failure = $10,
+				branch = $11
 		`
 		errorStr := ""
 		if hist.Error != nil {
 			errorStr = hist.Error.Error()
 		}
-		_, err = p.conn.Exec(ctx, histQuery,
-			histID, sagaID, hist.StepName, string(hist.Status), errorStr, hist.RetryAttempt, hist.StartedAt, hist.CompletedAt)
+		var failureJSON []byte
+		if hist.Failure != nil {
+			failureJSON, _ = json.Marshal(hist.Failure)
+		}
+		_, err = exec.Exec(ctx, histQuery,
+			histID, sagaID, hist.StepName, string(hist.Status), errorStr, hist.RetryAttempt, hist.StartedAt, hist.CompletedAt, hist.StackTrace, failureJSON, hist.Branch)
 		if err != nil {
 			// Логируем ошибку, но не прерываем сохранение
 			_ = err
@@ -1019,23 +1250,24 @@ func (p *PostgresPersistence) Save(ctx context.Context, saga Saga) error {
 
 func (p *PostgresPersistence) Load(ctx context.Context, sagaID string) (Saga, error) {
 	query := `
-		SELECT id, definition_name, status, context, correlation_id, current_step, created_at, updated_at, completed_at
+		SELECT id, definition_name, definition_version, status, context, correlation_id, current_step, created_at, updated_at, completed_at
 		FROM saga_instances
 		WHERE id = $1
 	`
 	var id, definitionName, statusStr, currentStep, correlationID string
+	var definitionVersion int
 	var contextJSON []byte
 	var createdAt, updatedAt time.Time
 	var completedAt *time.Time
 
 	err := p.conn.QueryRow(ctx, query, sagaID).Scan(
-		&id, &definitionName, &statusStr, &contextJSON, &correlationID, &currentStep, &createdAt, &updatedAt, &completedAt)
+		&id, &definitionName, &definitionVersion, &statusStr, &contextJSON, &correlationID, &currentStep, &createdAt, &updatedAt, &completedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load saga: %w", err)
 	}
 
-	// Получаем definition из registry
-	definition, err := p.registry.GetSaga(definitionName)
+	// Получаем из registry версию definition, с которой была запущена сага
+	definition, err := p.registry.GetSagaVersion(definitionName, definitionVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get saga definition %s: %w", definitionName, err)
 	}
@@ -1046,7 +1278,9 @@ func (p *PostgresPersistence) Load(ctx context.Context, sagaID string) (Saga, er
 	if err := json.Unmarshal(contextJSON, &contextData); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal context: %w", err)
 	}
-	sagaCtx.FromMap(contextData)
+	if err := restoreSagaContext(sagaCtx, contextData); err != nil {
+		return nil, fmt.Errorf("failed to restore context: %w", err)
+	}
 	
 	// Восстанавливаем correlation ID
 	if correlationID != "" {
@@ -1087,7 +1321,7 @@ func (p *PostgresPersistence) Load(ctx context.Context, sagaID string) (Saga, er
 
 func (p *PostgresPersistence) LoadAll(ctx context.Context, status SagaStatus) ([]Saga, error) {
 	query := `
-		SELECT id, definition_name, status, context, correlation_id, current_step, created_at, updated_at, completed_at
+		SELECT id, definition_name, definition_version, status, context, correlation_id, current_step, created_at, updated_at, completed_at
 		FROM saga_instances
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -1101,16 +1335,17 @@ func (p *PostgresPersistence) LoadAll(ctx context.Context, status SagaStatus) ([
 	var sagas []Saga
 	for rows.Next() {
 		var id, definitionName, statusStr, currentStep, correlationID string
+		var definitionVersion int
 		var contextJSON []byte
 		var createdAt, updatedAt time.Time
 		var completedAt *time.Time
 
-		if err := rows.Scan(&id, &definitionName, &statusStr, &contextJSON, &correlationID, &currentStep, &createdAt, &updatedAt, &completedAt); err != nil {
+		if err := rows.Scan(&id, &definitionName, &definitionVersion, &statusStr, &contextJSON, &correlationID, &currentStep, &createdAt, &updatedAt, &completedAt); err != nil {
 			continue
 		}
 
-		// Получаем definition из registry
-		definition, err := p.registry.GetSaga(definitionName)
+		// Получаем из registry версию definition, с которой была запущена сага
+		definition, err := p.registry.GetSagaVersion(definitionName, definitionVersion)
 		if err != nil {
 			// Пропускаем саги с неизвестными определениями
 			continue
@@ -1122,7 +1357,9 @@ func (p *PostgresPersistence) LoadAll(ctx context.Context, status SagaStatus) ([
 		if err := json.Unmarshal(contextJSON, &contextData); err != nil {
 			continue
 		}
-		sagaCtx.FromMap(contextData)
+		if err := restoreSagaContext(sagaCtx, contextData); err != nil {
+			continue
+		}
 		
 		if correlationID != "" {
 			sagaCtx.SetCorrelationID(correlationID)
@@ -1165,6 +1402,81 @@ func (p *PostgresPersistence) LoadAll(ctx context.Context, status SagaStatus) ([
 	return sagas, nil
 }
 
+// ListSagaRefs возвращает сведения о сагах с указанными статусами (реализация SagaRefLister)
+func (p *PostgresPersistence) ListSagaRefs(ctx context.Context, statuses ...SagaStatus) ([]PersistedSagaRef, error) {
+	query := `SELECT id, definition_name, definition_version, status, current_step FROM saga_instances`
+	var args []interface{}
+	if len(statuses) > 0 {
+		statusStrs := make([]string, len(statuses))
+		for i, status := range statuses {
+			statusStrs[i] = string(status)
+		}
+		query += ` WHERE status = ANY($1)`
+		args = append(args, statusStrs)
+	}
+
+	rows, err := p.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []PersistedSagaRef
+	for rows.Next() {
+		var ref PersistedSagaRef
+		var statusStr string
+		if err := rows.Scan(&ref.SagaID, &ref.DefinitionName, &ref.DefinitionVersion, &statusStr, &ref.CurrentStep); err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		ref.Status = SagaStatus(statusStr)
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// SaveDefinitionMetadata сохраняет метаданные версий определений в таблицу saga_definitions
+// (миграция migrations/postgres/005_create_saga_definitions.sql, реализация SagaDefinitionStore)
+func (p *PostgresPersistence) SaveDefinitionMetadata(ctx context.Context, definitions []SagaDefinitionMetadata) error {
+	query := `
+		INSERT INTO saga_definitions (name, version, steps, registered_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (name, version) DO UPDATE SET steps = EXCLUDED.steps, updated_at = NOW()
+	`
+	for _, metadata := range definitions {
+		stepsJSON, err := json.Marshal(metadata.Steps)
+		if err != nil {
+			return fmt.Errorf("failed to marshal steps of %s v%d: %w", metadata.Name, metadata.Version, err)
+		}
+		if _, err := p.conn.Exec(ctx, query, metadata.Name, metadata.Version, stepsJSON); err != nil {
+			return fmt.Errorf("failed to save saga definition %s v%d: %w", metadata.Name, metadata.Version, err)
+		}
+	}
+	return nil
+}
+
+// ListDefinitionMetadata возвращает метаданные сохраненных версий определений (реализация SagaDefinitionStore)
+func (p *PostgresPersistence) ListDefinitionMetadata(ctx context.Context) ([]SagaDefinitionMetadata, error) {
+	rows, err := p.conn.Query(ctx, `SELECT name, version, steps, registered_at FROM saga_definitions ORDER BY name, version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saga definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var result []SagaDefinitionMetadata
+	for rows.Next() {
+		var metadata SagaDefinitionMetadata
+		var stepsJSON []byte
+		if err := rows.Scan(&metadata.Name, &metadata.Version, &stepsJSON, &metadata.RegisteredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saga definition: %w", err)
+		}
+		if err := json.Unmarshal(stepsJSON, &metadata.Steps); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal steps of %s v%d: %w", metadata.Name, metadata.Version, err)
+		}
+		result = append(result, metadata)
+	}
+	return result, rows.Err()
+}
+
 func (p *PostgresPersistence) Delete(ctx context.Context, sagaID string) error {
 	query := `DELETE FROM saga_instances WHERE id = $1`
 	_, err := p.conn.Exec(ctx, query, sagaID)
@@ -1173,7 +1485,7 @@ func (p *PostgresPersistence) Delete(ctx context.Context, sagaID string) error {
 
 func (p *PostgresPersistence) GetHistory(ctx context.Context, sagaID string) ([]SagaHistory, error) {
 	query := `
-		SELECT step_name, status, error, retry_attempt, started_at, completed_at
+		SELECT step_name, status, error, retry_attempt, started_at, completed_at, stack_trace, failure, branch
 		FROM saga_history
 		WHERE saga_id = $1
 		ORDER BY started_at ASC
@@ -1186,12 +1498,13 @@ func (p *PostgresPersistence) GetHistory(ctx context.Context, sagaID string) ([]
 
 	var history []SagaHistory
 	for rows.Next() {
-		var stepName, statusStr, errorStr string
+		var stepName, statusStr, errorStr, stackTrace, branch string
 		var retryAttempt int
 		var startedAt time.Time
 		var completedAt *time.Time
+		var failureJSON []byte
 
-		if err := rows.Scan(&stepName, &statusStr, &errorStr, &retryAttempt, &startedAt, &completedAt); err != nil {
+		if err := rows.Scan(&stepName, &statusStr, &errorStr, &retryAttempt, &startedAt, &completedAt, &stackTrace, &failureJSON, &branch); err != nil {
 			continue
 		}
 
@@ -1206,7 +1519,10 @@ func (p *PostgresPersistence) GetHistory(ctx context.Context, sagaID string) ([]
 			StartedAt:    startedAt,
 			CompletedAt:  completedAt,
 			Error:        err,
+			Failure:      failureFromValue(failureJSON),
 			RetryAttempt: retryAttempt,
+			StackTrace:   stackTrace,
+			Branch:       branch,
 		})
 	}
 
@@ -1218,3 +1534,15 @@ func (p *PostgresPersistence) Close(ctx context.Context) error {
 	return p.conn.Close(ctx)
 }
 
+// metadataInt извлекает целое число из метаданных (после JSON числа становятся float64)
+func metadataInt(val interface{}) int {
+	switch v := val.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
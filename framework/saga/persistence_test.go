@@ -1,9 +1,12 @@
 package saga
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/akriventsev/potter/framework/events"
 	"github.com/akriventsev/potter/framework/eventsourcing"
@@ -144,6 +147,64 @@ func TestInMemoryPersistence_GetHistory(t *testing.T) {
 	}
 }
 
+func TestInMemoryPersistence_SagaAdminStore(t *testing.T) {
+	persistence := NewInMemoryPersistence()
+	ctx := context.Background()
+
+	for _, def := range []string{"order-saga", "order-saga", "payment-saga"} {
+		definition := NewBaseSagaDefinition(def)
+		definition.AddStep(NewBaseStep("step1"))
+		saga, err := NewBaseSaga(fmt.Sprintf("%s-%d", def, len(persistence.sagas)), definition, NewSagaContext(), persistence)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		if err := persistence.Save(ctx, saga); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	var store SagaAdminStore = persistence
+	records, err := store.ListSagaRecords(ctx, SagaRecordFilter{DefinitionName: "order-saga"})
+	if err != nil {
+		t.Fatalf("ListSagaRecords failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 order sagas, got %d", len(records))
+	}
+
+	if err := store.ForceFail(ctx, "order-saga-0", "manual cleanup"); err != nil {
+		t.Fatalf("ForceFail failed: %v", err)
+	}
+	if err := store.ForceFail(ctx, "order-saga-0", "again"); err == nil {
+		t.Error("Expected error when force-failing finished saga")
+	}
+
+	failed, err := store.ListSagaRecords(ctx, SagaRecordFilter{Statuses: []SagaStatus{SagaStatusFailed}})
+	if err != nil {
+		t.Fatalf("ListSagaRecords failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0].SagaID != "order-saga-0" {
+		t.Fatalf("Expected order-saga-0 to be failed, got %+v", failed)
+	}
+
+	history, err := persistence.GetHistory(ctx, "order-saga-0")
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	last := history[len(history)-1]
+	if last.Failure == nil || last.Failure.Code != FailureCodeForceFailed || last.Failure.Details["reason"] != "manual cleanup" {
+		t.Errorf("Expected force-fail history entry, got %+v", last)
+	}
+
+	pending, err := store.ListSagaRecords(ctx, SagaRecordFilter{Statuses: []SagaStatus{SagaStatusPending}, Limit: 1})
+	if err != nil {
+		t.Fatalf("ListSagaRecords failed: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Errorf("Expected limit to be applied, got %d sagas", len(pending))
+	}
+}
+
 func TestEventStorePersistence_SaveAndLoadWithStepEvents(t *testing.T) {
 	// Создаем mock EventStore и SnapshotStore
 	eventStore := eventsourcing.NewInMemoryEventStore(eventsourcing.DefaultInMemoryEventStoreConfig())
@@ -282,6 +343,57 @@ func TestEventStorePersistence_LoadAll(t *testing.T) {
 	}
 }
 
+func TestEventStorePersistence_VersionedDefinitions(t *testing.T) {
+	eventStore := eventsourcing.NewInMemoryEventStore(eventsourcing.DefaultInMemoryEventStoreConfig())
+	snapshotStore := eventsourcing.NewInMemorySnapshotStore()
+	registry := NewSagaRegistry()
+
+	persistence := NewEventStorePersistence(eventStore, snapshotStore).WithRegistry(registry)
+
+	v1 := NewBaseSagaDefinition("order-saga")
+	v1.AddStep(NewBaseStep("reserve"))
+	if err := registry.RegisterSaga("order-saga", v1); err != nil {
+		t.Fatalf("Failed to register saga: %v", err)
+	}
+
+	ctx := context.Background()
+	inFlight, err := NewBaseSagaWithEventBus("saga-v1", v1, NewSagaContext(), persistence, nil)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := persistence.Save(ctx, inFlight); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Новая версия определения регистрируется, пока сага v1 еще выполняется
+	v2 := NewBaseSagaDefinition("order-saga").WithVersion(2)
+	v2.AddStep(NewBaseStep("reserve"))
+	v2.AddStep(NewBaseStep("notify"))
+	if err := registry.RegisterSaga("order-saga", v2); err != nil {
+		t.Fatalf("Failed to register saga: %v", err)
+	}
+
+	latest, err := registry.GetSaga("order-saga")
+	if err != nil || SagaDefinitionVersion(latest) != 2 {
+		t.Fatalf("Expected latest version 2, got %v (%v)", latest, err)
+	}
+	if versions := registry.ListVersions("order-saga"); len(versions) != 2 || versions[0] != 1 || versions[1] != 2 {
+		t.Errorf("Expected versions [1 2], got %v", versions)
+	}
+
+	loaded, err := persistence.Load(ctx, "saga-v1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if SagaDefinitionVersion(loaded.Definition()) != 1 || len(loaded.Definition().Steps()) != 1 {
+		t.Errorf("Expected in-flight saga to keep definition v1, got v%d", SagaDefinitionVersion(loaded.Definition()))
+	}
+
+	if _, err := registry.GetSagaVersion("order-saga", 3); err == nil {
+		t.Error("Expected error for unknown definition version")
+	}
+}
+
 func TestEventStorePersistence_RoundTripWithErrorsAndRetryAttempt(t *testing.T) {
 	// Создаем mock EventStore и SnapshotStore
 	eventStore := eventsourcing.NewInMemoryEventStore(eventsourcing.DefaultInMemoryEventStoreConfig())
@@ -350,5 +462,108 @@ func TestEventStorePersistence_RoundTripWithErrorsAndRetryAttempt(t *testing.T)
 	if firstHist.RetryAttempt != 3 {
 		t.Errorf("Expected RetryAttempt to be 3, got %d", firstHist.RetryAttempt)
 	}
+
+	if firstHist.Failure == nil {
+		t.Error("Expected structured failure to be restored from history")
+	} else if firstHist.Failure.Category != FailureCategoryTechnical || firstHist.Failure.Code != FailureCodeUnknown {
+		t.Errorf("Expected technical/%s failure, got %+v", FailureCodeUnknown, firstHist.Failure)
+	}
 }
 
+
+func TestInMemoryPersistence_SagaBundle(t *testing.T) {
+	ctx := context.Background()
+	source := NewInMemoryPersistence()
+
+	definition := NewBaseSagaDefinition("order-saga")
+	definition.AddStep(NewBaseStep("reserve"))
+	definition.AddStep(NewBaseStep("charge"))
+	sagaCtx := NewSagaContext()
+	sagaCtx.Set("order_id", "order-1")
+	sagaCtx.Set("payment", map[string]interface{}{"card_number": "4111111111111111", "amount": 100})
+	sagaCtx.Set("api_token", "secret-token")
+	sagaCtx.SetCorrelationID("corr-1")
+	instance, err := NewBaseSaga("saga-1", definition, sagaCtx, source)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	now := time.Now()
+	instance.status = SagaStatusCompensationStuck
+	instance.currentStep = "charge"
+	instance.history = []SagaHistory{
+		{StepName: "reserve", Status: StepStatusCompleted, StartedAt: now, CompletedAt: &now},
+		{StepName: "charge", Status: StepStatusFailed, StartedAt: now.Add(time.Second), Error: errors.New("declined"),
+			Failure: &SagaFailure{Category: FailureCategoryBusiness, Code: "DECLINED", Details: map[string]interface{}{"password": "p", "reason": "limit"}}},
+	}
+	_ = source.Save(ctx, instance)
+
+	bundle, err := ExportSagaBundle(ctx, source, SagaExportOptions{
+		Filter:     SagaRecordFilter{Statuses: []SagaStatus{SagaStatusCompensationStuck}},
+		RedactKeys: DefaultRedactedKeys,
+	})
+	if err != nil {
+		t.Fatalf("ExportSagaBundle failed: %v", err)
+	}
+
+	// Выгрузка переносится через JSON
+	var buf bytes.Buffer
+	if err := WriteSagaBundle(&buf, bundle); err != nil {
+		t.Fatalf("WriteSagaBundle failed: %v", err)
+	}
+	bundle, err = ReadSagaBundle(&buf)
+	if err != nil {
+		t.Fatalf("ReadSagaBundle failed: %v", err)
+	}
+	if len(bundle.Sagas) != 1 {
+		t.Fatalf("Expected 1 saga in bundle, got %d", len(bundle.Sagas))
+	}
+	entry := bundle.Sagas[0]
+	if entry.Context["api_token"] != RedactedValue || entry.Context["payment"].(map[string]interface{})["card_number"] != RedactedValue {
+		t.Errorf("Expected secrets to be redacted, got %+v", entry.Context)
+	}
+	if entry.History[1].Failure.Details["password"] != RedactedValue || entry.History[1].Failure.Details["reason"] != "limit" {
+		t.Errorf("Expected failure details to be redacted, got %+v", entry.History[1].Failure.Details)
+	}
+	if sagaCtx.GetString("api_token") != "secret-token" {
+		t.Error("Redaction must not modify the source saga")
+	}
+
+	// Импорт в другое окружение с зарегистрированным определением
+	registry := NewSagaRegistry()
+	_ = registry.RegisterSaga("order-saga", definition)
+	target := NewInMemoryPersistence().WithRegistry(registry)
+	if imported, err := ImportSagaBundle(ctx, target, bundle, false); err != nil || imported != 1 {
+		t.Fatalf("ImportSagaBundle = %d, %v", imported, err)
+	}
+	loaded, err := target.Load(ctx, "saga-1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Status() != SagaStatusCompensationStuck || loaded.CurrentStep() != "charge" || loaded.Context().CorrelationID() != "corr-1" {
+		t.Errorf("Unexpected imported saga state: %s %s %s", loaded.Status(), loaded.CurrentStep(), loaded.Context().CorrelationID())
+	}
+	if loaded.Context().GetString("order_id") != "order-1" || len(loaded.GetHistory()) != 2 || loaded.GetHistory()[1].Error.Error() != "declined" {
+		t.Errorf("Unexpected imported context or history: %+v", loaded.GetHistory())
+	}
+	if _, missing := MissingDefinitionMetadata(loaded.Definition()); missing {
+		t.Error("Expected registered definition for imported saga")
+	}
+
+	// Повторный импорт требует overwrite
+	if _, err := ImportSagaBundle(ctx, target, bundle, false); !errors.Is(err, ErrSagaAlreadyExists) {
+		t.Errorf("Expected ErrSagaAlreadyExists, got %v", err)
+	}
+	if _, err := ImportSagaBundle(ctx, target, bundle, true); err != nil {
+		t.Errorf("Expected overwrite to succeed, got %v", err)
+	}
+
+	// Без определения сага доступна только для чтения
+	readOnly := NewInMemoryPersistence()
+	if _, err := ImportSagaBundle(ctx, readOnly, bundle, false); err != nil {
+		t.Fatalf("ImportSagaBundle failed: %v", err)
+	}
+	loaded, _ = readOnly.Load(ctx, "saga-1")
+	if metadata, missing := MissingDefinitionMetadata(loaded.Definition()); !missing || len(metadata.Steps) != 2 {
+		t.Errorf("Expected read-only definition restored from history, got %+v", metadata)
+	}
+}
@@ -68,7 +68,19 @@ type SagaStatusResponse struct {
 	CorrelationID  string
 	Context        map[string]interface{}
 	LastError      *string
+	// LastFailure структурированная ошибка, на которой остановилась сага
+	LastFailure    *SagaFailure
 	RetryCount     int
+	// SLADeadline срок завершения по SLA определения (nil - SLA не задан)
+	SLADeadline *time.Time
+	// SLARemaining оставшееся до срока SLA время (0 после превышения)
+	SLARemaining *time.Duration
+	// SLABreached SLA превышен
+	SLABreached bool
+	// LastHeartbeatAt время последнего heartbeat текущего шага (nil - шаг не сообщал о прогрессе)
+	LastHeartbeatAt *time.Time
+	// StepProgress доля выполнения текущего шага из последнего heartbeat (от 0 до 1)
+	StepProgress *float64
 }
 
 // SagaHistoryResponse ответ с историей саги
@@ -86,6 +98,12 @@ type SagaStepHistory struct {
 	Duration     *time.Duration
 	RetryAttempt int
 	Error        *string
+	// Failure структурированная ошибка шага
+	Failure *SagaFailure
+	// StackTrace stack trace, если шаг завершился паникой
+	StackTrace *string
+	// Branch переход по результату шага или причина пропуска шага
+	Branch *string
 }
 
 // SagaListResponse ответ со списком саг
@@ -116,12 +134,19 @@ type SagaMetricsResponse struct {
 	SuccessRate      float64
 	AvgDuration      time.Duration
 	Throughput       float64 // саг в час
+	// FailuresByCategory число неуспешных саг по категории последней ошибки
+	FailuresByCategory map[FailureCategory]int
+	// FailuresByCode число неуспешных саг по коду последней ошибки
+	FailuresByCode map[string]int
+	// Steps латентность (p50/p95) и доля ошибок каждого шага, отсортированные по имени шага
+	Steps []StepMetrics
 }
 
 // SagaQueryHandler обработчик запросов о сагах
 type SagaQueryHandler struct {
 	persistence    SagaPersistence
 	readModelStore SagaReadModelStore
+	metricsStore   SagaMetricsStore
 }
 
 // NewSagaQueryHandler создает новый SagaQueryHandler
@@ -132,6 +157,13 @@ func NewSagaQueryHandler(persistence SagaPersistence, readModelStore SagaReadMod
 	}
 }
 
+// WithMetricsStore задает хранилище материализованных метрик (SagaMetricsProjection)
+// для GetSagaMetricsTrendQuery
+func (h *SagaQueryHandler) WithMetricsStore(store SagaMetricsStore) *SagaQueryHandler {
+	h.metricsStore = store
+	return h
+}
+
 // Handle обрабатывает запрос
 func (h *SagaQueryHandler) Handle(ctx context.Context, q transport.Query) (interface{}, error) {
 	switch query := q.(type) {
@@ -143,6 +175,8 @@ func (h *SagaQueryHandler) Handle(ctx context.Context, q transport.Query) (inter
 		return h.handleListSagas(ctx, query)
 	case *GetSagaMetricsQuery:
 		return h.handleGetMetrics(ctx, query)
+	case *GetSagaMetricsTrendQuery:
+		return h.handleGetMetricsTrend(ctx, query)
 	default:
 		return nil, fmt.Errorf("unknown query type: %T", q)
 	}
@@ -210,6 +244,7 @@ func (h *SagaQueryHandler) handleGetStatus(ctx context.Context, query *GetSagaSt
 			errMsg := lastEntry.Error.Error()
 			response.LastError = &errMsg
 		}
+		response.LastFailure = lastFailure(history)
 		response.RetryCount = lastEntry.RetryAttempt
 	}
 
@@ -239,6 +274,15 @@ func (h *SagaQueryHandler) handleGetHistory(ctx context.Context, query *GetSagaH
 			errMsg := h.Error.Error()
 			stepHistory[i].Error = &errMsg
 		}
+		stepHistory[i].Failure = h.Failure
+		if h.StackTrace != "" {
+			stackTrace := h.StackTrace
+			stepHistory[i].StackTrace = &stackTrace
+		}
+		if h.Branch != "" {
+			branch := h.Branch
+			stepHistory[i].Branch = &branch
+		}
 	}
 
 	return &SagaHistoryResponse{
@@ -343,11 +387,13 @@ func (h *SagaQueryHandler) handleGetMetrics(ctx context.Context, query *GetSagaM
 	}
 
 	// Базовая реализация через persistence
-	allStatuses := []SagaStatus{SagaStatusRunning, SagaStatusCompleted, SagaStatusFailed, SagaStatusCompensated}
+	allStatuses := []SagaStatus{SagaStatusRunning, SagaStatusWaitingApproval, SagaStatusWaitingMessage, SagaStatusCompleted, SagaStatusFailed, SagaStatusCompensated, SagaStatusCompensationStuck}
 
 	var total, completed, failed, compensated int
 	var totalDuration time.Duration
 	var sagaCount int
+	response := &SagaMetricsResponse{}
+	steps := newStepMetricsCollector()
 
 	for _, status := range allStatuses {
 		sagas, err := h.persistence.LoadAll(ctx, status)
@@ -378,10 +424,12 @@ func (h *SagaQueryHandler) handleGetMetrics(ctx context.Context, query *GetSagaM
 			switch saga.Status() {
 			case SagaStatusCompleted:
 				completed++
-			case SagaStatusFailed:
+			case SagaStatusFailed, SagaStatusCompensationStuck:
 				failed++
+				response.addFailure(lastFailure(history))
 			case SagaStatusCompensated:
 				compensated++
+				response.addFailure(lastFailure(history))
 			}
 
 			if len(history) > 0 {
@@ -392,6 +440,15 @@ func (h *SagaQueryHandler) handleGetMetrics(ctx context.Context, query *GetSagaM
 					sagaCount++
 				}
 			}
+
+			for _, entry := range history {
+				var duration *time.Duration
+				if entry.CompletedAt != nil {
+					d := entry.CompletedAt.Sub(entry.StartedAt)
+					duration = &d
+				}
+				steps.add(entry.StepName, string(entry.Status), duration)
+			}
 		}
 	}
 
@@ -405,13 +462,49 @@ func (h *SagaQueryHandler) handleGetMetrics(ctx context.Context, query *GetSagaM
 		avgDuration = totalDuration / time.Duration(sagaCount)
 	}
 
-	return &SagaMetricsResponse{
-		TotalSagas:       total,
-		CompletedSagas:   completed,
-		FailedSagas:      failed,
-		CompensatedSagas: compensated,
-		SuccessRate:      successRate,
-		AvgDuration:      avgDuration,
-		Throughput:       0, // Требует дополнительных данных
-	}, nil
+	response.TotalSagas = total
+	response.CompletedSagas = completed
+	response.FailedSagas = failed
+	response.CompensatedSagas = compensated
+	response.SuccessRate = successRate
+	response.AvgDuration = avgDuration
+	response.Throughput = 0 // Требует дополнительных данных
+	response.Steps = steps.result()
+	return response, nil
+}
+
+// countedStatuses статусы, по которым StatusCounts считает саги
+var countedStatuses = []SagaStatus{
+	SagaStatusPending,
+	SagaStatusRunning,
+	SagaStatusWaitingApproval,
+	SagaStatusWaitingMessage,
+	SagaStatusCompensating,
+	SagaStatusCompleted,
+	SagaStatusFailed,
+	SagaStatusCompensated,
+	SagaStatusCompensationStuck,
+}
+
+// StatusCounts возвращает число саг по статусам (для metrics.PrometheusExporter.RegisterSagaCounts).
+// С read model store используется Total постраничного запроса, иначе саги загружаются из persistence.
+func (h *SagaQueryHandler) StatusCounts(ctx context.Context) (map[string]int64, error) {
+	counts := make(map[string]int64, len(countedStatuses))
+	for _, status := range countedStatuses {
+		if h.readModelStore != nil {
+			list, err := h.readModelStore.ListSagas(ctx, SagaFilter{Status: &status, Limit: 1})
+			if err != nil {
+				return nil, fmt.Errorf("failed to count sagas with status %s: %w", status, err)
+			}
+			counts[string(status)] = int64(list.Total)
+			continue
+		}
+
+		sagas, err := h.persistence.LoadAll(ctx, status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count sagas with status %s: %w", status, err)
+		}
+		counts[string(status)] = int64(len(sagas))
+	}
+	return counts, nil
 }
@@ -1,10 +1,14 @@
 package saga
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/akriventsev/potter/framework/transport"
 )
 
 // mockSagaPersistence mock реализация SagaPersistence для тестов
@@ -506,3 +510,456 @@ func TestSagaQueryHandler_GetMetrics_InMemoryStore(t *testing.T) {
 		t.Errorf("Expected 1 completed saga, got %d", metrics.CompletedSagas)
 	}
 }
+
+func TestSagaQueryHandler_GetMetrics_StepStatistics(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySagaReadModelStore()
+	now := time.Now()
+
+	definitions := []string{"order_saga", "order_saga", "order_saga", "other_saga"}
+	for i, definition := range definitions {
+		sagaID := fmt.Sprintf("steps-saga-%d", i+1)
+		if err := store.UpsertSagaReadModel(ctx, &SagaReadModel{
+			SagaID: sagaID, DefinitionName: definition, Status: SagaStatusCompleted, StartedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("Failed to upsert read model: %v", err)
+		}
+
+		duration := time.Duration(i+1) * 100 * time.Millisecond
+		steps := []*SagaStepReadModel{
+			{SagaID: sagaID, StepName: "reserve", Status: "started", StartedAt: now},
+			{SagaID: sagaID, StepName: "reserve", Status: "completed", StartedAt: now.Add(time.Millisecond), Duration: &duration},
+			{SagaID: sagaID, StepName: "charge", Status: "completed", StartedAt: now.Add(2 * time.Millisecond), Duration: &duration},
+		}
+		if i == 0 {
+			steps = append(steps, &SagaStepReadModel{SagaID: sagaID, StepName: "charge", Status: "failed", StartedAt: now.Add(3 * time.Millisecond)})
+		}
+		for _, step := range steps {
+			if err := store.UpsertSagaStepReadModel(ctx, step); err != nil {
+				t.Fatalf("Failed to upsert step read model: %v", err)
+			}
+		}
+	}
+
+	handler := NewSagaQueryHandler(nil, store)
+	definition := "order_saga"
+	result, err := handler.Handle(ctx, &GetSagaMetricsQuery{DefinitionName: &definition})
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	metrics := result.(*SagaMetricsResponse)
+
+	if len(metrics.Steps) != 2 {
+		t.Fatalf("Expected 2 steps, got %+v", metrics.Steps)
+	}
+	charge, reserve := metrics.Steps[0], metrics.Steps[1]
+	if charge.StepName != "charge" || charge.Executions != 4 || charge.Failures != 1 || charge.FailureRate != 25 {
+		t.Errorf("Unexpected charge statistics: %+v", charge)
+	}
+	if reserve.StepName != "reserve" || reserve.Executions != 3 || reserve.Failures != 0 {
+		t.Errorf("Unexpected reserve statistics: %+v", reserve)
+	}
+	if reserve.P50Duration != 200*time.Millisecond || reserve.P95Duration != 300*time.Millisecond || reserve.AvgDuration != 200*time.Millisecond {
+		t.Errorf("Unexpected reserve latency: p50=%v p95=%v avg=%v", reserve.P50Duration, reserve.P95Duration, reserve.AvgDuration)
+	}
+}
+
+func TestSagaMetricsProjection_Trend(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySagaMetricsStore()
+	projection := NewSagaMetricsProjection(store)
+	hour := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	durations := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second}
+	for i, duration := range durations {
+		sagaID := fmt.Sprintf("order-%d", i)
+		startedAt := hour.Add(time.Duration(i) * time.Minute)
+		if err := projection.HandleSagaStarted(ctx, &SagaStartedEvent{SagaID: sagaID, DefinitionName: "order_saga", Timestamp: startedAt}); err != nil {
+			t.Fatalf("HandleSagaStarted failed: %v", err)
+		}
+		if err := projection.HandleSagaCompleted(ctx, &SagaCompletedEvent{SagaID: sagaID, Duration: duration, Timestamp: startedAt.Add(duration)}); err != nil {
+			t.Fatalf("HandleSagaCompleted failed: %v", err)
+		}
+	}
+
+	// Неудачная сага другого определения в следующем часе: длительность вычисляется от старта
+	failedStart := hour.Add(50 * time.Minute)
+	_ = projection.HandleSagaStarted(ctx, &SagaStartedEvent{SagaID: "payment-1", DefinitionName: "payment_saga", Timestamp: failedStart})
+	_ = projection.HandleSagaFailed(ctx, &SagaFailedEvent{
+		SagaID:    "payment-1",
+		Failure:   &SagaFailure{Category: FailureCategoryBusiness, Code: "CARD_DECLINED"},
+		Timestamp: failedStart.Add(20 * time.Minute),
+	})
+
+	handler := NewSagaQueryHandler(nil, nil).WithMetricsStore(store)
+	result, err := handler.Handle(ctx, &GetSagaMetricsTrendQuery{})
+	if err != nil {
+		t.Fatalf("GetSagaMetricsTrend failed: %v", err)
+	}
+	trend := result.(*SagaMetricsTrendResponse)
+	if len(trend.Points) != 2 {
+		t.Fatalf("Expected 2 hourly points, got %d", len(trend.Points))
+	}
+
+	first := trend.Points[0]
+	if !first.BucketStart.Equal(hour) || first.Started != 5 || first.Completed != 4 {
+		t.Errorf("Unexpected first bucket: %+v", first)
+	}
+	if first.SuccessRate != 100 || first.AvgDuration != 2500*time.Millisecond {
+		t.Errorf("Expected 100%% success and 2.5s average, got %v and %v", first.SuccessRate, first.AvgDuration)
+	}
+	if first.P50Duration != 2500*time.Millisecond || first.P95Duration != 4*time.Second {
+		t.Errorf("Expected p50 2.5s and p95 4s, got %v and %v", first.P50Duration, first.P95Duration)
+	}
+
+	second := trend.Points[1]
+	if second.Failed != 1 || second.FailuresByCode["CARD_DECLINED"] != 1 || second.FailuresByCategory[FailureCategoryBusiness] != 1 {
+		t.Errorf("Expected failure reason in second bucket, got %+v", second)
+	}
+	if second.P95Duration != 20*time.Minute {
+		t.Errorf("Expected failed saga duration 20m, got %v", second.P95Duration)
+	}
+
+	definition := "payment_saga"
+	result, err = handler.Handle(ctx, &GetSagaMetricsTrendQuery{DefinitionName: &definition, Granularity: MetricsGranularityDay})
+	if err != nil {
+		t.Fatalf("GetSagaMetricsTrend failed: %v", err)
+	}
+	daily := result.(*SagaMetricsTrendResponse)
+	if len(daily.Points) != 1 || daily.Points[0].Started != 1 || daily.Points[0].Failed != 1 || daily.Points[0].SuccessRate != 0 {
+		t.Errorf("Unexpected daily trend for payment_saga: %+v", daily.Points)
+	}
+
+	if err := projection.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	buckets, _ := store.GetMetricsBuckets(ctx, MetricsBucketFilter{})
+	if len(buckets) != 0 {
+		t.Errorf("Expected no buckets after reset, got %d", len(buckets))
+	}
+}
+
+func TestSagaQueryHandler_StatusCounts(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySagaReadModelStore()
+
+	statuses := []SagaStatus{SagaStatusRunning, SagaStatusRunning, SagaStatusCompleted, SagaStatusCompensationStuck}
+	for i, status := range statuses {
+		model := &SagaReadModel{
+			SagaID:         fmt.Sprintf("count-saga-%d", i+1),
+			DefinitionName: "test_saga",
+			Status:         status,
+			StartedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+		if err := store.UpsertSagaReadModel(ctx, model); err != nil {
+			t.Fatalf("Failed to upsert read model: %v", err)
+		}
+	}
+
+	handler := NewSagaQueryHandler(nil, store)
+	counts, err := handler.StatusCounts(ctx)
+	if err != nil {
+		t.Fatalf("StatusCounts failed: %v", err)
+	}
+
+	expected := map[SagaStatus]int64{
+		SagaStatusRunning:           2,
+		SagaStatusCompleted:         1,
+		SagaStatusCompensationStuck: 1,
+		SagaStatusFailed:            0,
+	}
+	for status, want := range expected {
+		if got := counts[string(status)]; got != want {
+			t.Errorf("Expected %d sagas with status %s, got %d", want, status, got)
+		}
+	}
+}
+
+func TestSagaQueryHandler_ExportSagas(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySagaReadModelStore()
+
+	startedAt := time.Now()
+	total := DefaultExportPageSize + 3
+	for i := 0; i < total; i++ {
+		model := &SagaReadModel{
+			SagaID:         fmt.Sprintf("saga-%04d", i),
+			DefinitionName: "test_saga",
+			Status:         SagaStatusCompleted,
+			StartedAt:      startedAt,
+			UpdatedAt:      startedAt,
+		}
+		if err := store.UpsertSagaReadModel(ctx, model); err != nil {
+			t.Fatalf("Failed to upsert read model: %v", err)
+		}
+	}
+
+	handler := NewSagaQueryHandler(nil, store)
+	completed := SagaStatusCompleted
+
+	var csvOut bytes.Buffer
+	if err := handler.ExportSagas(ctx, &ListSagasQuery{Status: &completed}, &csvOut, transport.ExportFormatCSV); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != total+1 {
+		t.Fatalf("Expected %d CSV lines, got %d", total+1, len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "SagaID,DefinitionName,Status") {
+		t.Errorf("Unexpected CSV header: %s", lines[0])
+	}
+	seen := make(map[string]bool)
+	for _, line := range lines[1:] {
+		sagaID := strings.Split(line, ",")[0]
+		if seen[sagaID] {
+			t.Fatalf("Saga %s exported twice", sagaID)
+		}
+		seen[sagaID] = true
+	}
+
+	var ndjsonOut bytes.Buffer
+	if err := handler.ExportSagas(ctx, &ListSagasQuery{Status: &completed, Limit: 10}, &ndjsonOut, transport.ExportFormatNDJSON); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count := strings.Count(ndjsonOut.String(), "\n"); count != 10 {
+		t.Errorf("Expected 10 NDJSON lines, got %d", count)
+	}
+}
+
+func TestSagaSLA(t *testing.T) {
+	ctx := context.Background()
+	bus := &mockEventBus{}
+	orchestrator := NewDefaultOrchestrator(nil, bus)
+
+	definition := NewBaseSagaDefinition("sla_saga").WithSLA(20 * time.Millisecond)
+	definition.AddStep(NewBaseStep("step1"))
+	instance, err := NewBaseSaga("sla-saga-1", definition, NewSagaContext(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+
+	stop := orchestrator.watchSLA(ctx, instance)
+	time.Sleep(100 * time.Millisecond)
+	stop()
+
+	var breached *SagaSLABreachedEvent
+	for _, event := range bus.events {
+		if e, ok := event.(*SagaSLABreachedEvent); ok {
+			breached = e
+		}
+	}
+	if breached == nil {
+		t.Fatal("Expected SagaSLABreached event")
+	}
+	if breached.SLA != 20*time.Millisecond || breached.Elapsed < breached.SLA || breached.DefinitionName != "sla_saga" {
+		t.Errorf("Unexpected breach event: %+v", breached)
+	}
+	if !isSLABreached(instance) {
+		t.Error("Expected breach to be marked in saga metadata")
+	}
+
+	// Повторное отслеживание (например, после Resume) не публикует событие снова
+	published := len(bus.events)
+	orchestrator.watchSLA(ctx, instance)()
+	time.Sleep(30 * time.Millisecond)
+	if len(bus.events) != published {
+		t.Error("Expected breach to be reported once per saga")
+	}
+
+	// Read model показывает оставшееся время SLA и превышение
+	store := NewInMemorySagaReadModelStore()
+	projection := NewSagaReadModelProjection(store)
+	deadline := time.Now().Add(time.Hour)
+	if err := projection.HandleSagaStarted(ctx, &SagaStartedEvent{
+		SagaID: "sla-saga-2", DefinitionName: "sla_saga", Timestamp: time.Now(), SLADeadline: &deadline,
+	}); err != nil {
+		t.Fatalf("HandleSagaStarted failed: %v", err)
+	}
+	status, err := store.GetSagaStatus(ctx, "sla-saga-2")
+	if err != nil {
+		t.Fatalf("Failed to get saga status: %v", err)
+	}
+	if status.SLARemaining == nil || *status.SLARemaining <= 59*time.Minute || status.SLABreached {
+		t.Errorf("Expected about an hour of SLA remaining, got %v (breached %v)", status.SLARemaining, status.SLABreached)
+	}
+
+	if err := projection.HandleSagaSLABreached(ctx, &SagaSLABreachedEvent{SagaID: "sla-saga-2", Deadline: time.Now()}); err != nil {
+		t.Fatalf("HandleSagaSLABreached failed: %v", err)
+	}
+	status, _ = store.GetSagaStatus(ctx, "sla-saga-2")
+	if !status.SLABreached || status.SLARemaining == nil || *status.SLARemaining != 0 {
+		t.Errorf("Expected breached SLA with no time remaining, got %v (breached %v)", status.SLARemaining, status.SLABreached)
+	}
+}
+
+// countingBatchStore считает пакетные записи read models
+type countingBatchStore struct {
+	*InMemorySagaReadModelStore
+	batches int
+}
+
+func (s *countingBatchStore) UpsertSagaReadModels(ctx context.Context, models []*SagaReadModel) error {
+	s.batches++
+	return s.InMemorySagaReadModelStore.UpsertSagaReadModels(ctx, models)
+}
+
+func TestSagaReadModelProjection_Batching(t *testing.T) {
+	ctx := context.Background()
+	store := &countingBatchStore{InMemorySagaReadModelStore: NewInMemorySagaReadModelStore()}
+	projection := NewSagaReadModelProjection(store).WithBatching(3, 0)
+
+	for _, sagaID := range []string{"batch-1", "batch-2"} {
+		if err := projection.HandleSagaStarted(ctx, &SagaStartedEvent{SagaID: sagaID, DefinitionName: "batch_saga", Timestamp: time.Now()}); err != nil {
+			t.Fatalf("HandleSagaStarted failed: %v", err)
+		}
+	}
+	// Изменение той же саги объединяется с несохраненным состоянием из буфера
+	if err := projection.HandleSagaSLABreached(ctx, &SagaSLABreachedEvent{SagaID: "batch-1", Deadline: time.Now()}); err != nil {
+		t.Fatalf("HandleSagaSLABreached failed: %v", err)
+	}
+	if _, err := store.GetSagaStatus(ctx, "batch-1"); err == nil {
+		t.Fatal("Expected read model to stay buffered until flush")
+	}
+
+	if err := projection.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if store.batches != 1 {
+		t.Errorf("Expected 1 batch write, got %d", store.batches)
+	}
+	status, err := store.GetSagaStatus(ctx, "batch-1")
+	if err != nil {
+		t.Fatalf("Failed to get saga status: %v", err)
+	}
+	if status.DefinitionName != "batch_saga" || !status.SLABreached {
+		t.Errorf("Expected merged read model, got %+v", status)
+	}
+
+	// Достижение размера пакета сохраняет буфер без явного Flush
+	for _, sagaID := range []string{"batch-3", "batch-4", "batch-5"} {
+		if err := projection.HandleSagaStarted(ctx, &SagaStartedEvent{SagaID: sagaID, DefinitionName: "batch_saga", Timestamp: time.Now()}); err != nil {
+			t.Fatalf("HandleSagaStarted failed: %v", err)
+		}
+	}
+	if _, err := store.GetSagaStatus(ctx, "batch-5"); err != nil {
+		t.Errorf("Expected full batch to be flushed: %v", err)
+	}
+	if err := projection.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+}
+
+func TestSagaFailureTaxonomy(t *testing.T) {
+	ctx := context.Background()
+
+	failures := map[string]*SagaFailure{
+		"timeout":  ClassifyFailure(fmt.Errorf("call payment: %w", context.DeadlineExceeded)),
+		"approval": ClassifyFailure(fmt.Errorf("step failed: %w", ErrApprovalRejected)),
+		"unknown":  ClassifyFailure(fmt.Errorf("connection reset")),
+	}
+	if f := failures["timeout"]; f.Category != FailureCategoryTimeout || f.Code != FailureCodeDeadlineExceeded || !f.Retryable {
+		t.Errorf("Unexpected timeout classification: %+v", f)
+	}
+	if f := failures["approval"]; f.Category != FailureCategoryBusiness || f.Code != FailureCodeApprovalRejected || f.Retryable {
+		t.Errorf("Unexpected approval classification: %+v", f)
+	}
+	if f := failures["unknown"]; f.Category != FailureCategoryTechnical || f.Code != FailureCodeUnknown {
+		t.Errorf("Unexpected default classification: %+v", f)
+	}
+
+	// Неповторяемая бизнес-ошибка не повторяется политикой повторов
+	bus := &mockEventBus{}
+	attempts := 0
+	definition := NewBaseSagaDefinition("payment_saga")
+	definition.AddStep(NewBaseStep("charge").
+		WithRetry(SimpleRetry(3)).
+		WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+			attempts++
+			return NewBusinessError("PAYMENT_DECLINED", fmt.Errorf("card declined")).WithDetail("amount", 100)
+		}))
+	instance, err := NewBaseSagaWithEventBus("failure-saga-1", definition, NewSagaContext(), nil, bus)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := instance.Execute(ctx); err == nil {
+		t.Fatal("Expected saga to fail")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected non-retryable error to be attempted once, got %d", attempts)
+	}
+
+	failure := lastFailure(instance.GetHistory())
+	if failure == nil || failure.Category != FailureCategoryBusiness || failure.Code != "PAYMENT_DECLINED" || failure.Details["amount"] != 100 {
+		t.Fatalf("Unexpected failure in history: %+v", failure)
+	}
+
+	// Read model хранит структурированную ошибку и агрегирует причины
+	store := NewInMemorySagaReadModelStore()
+	projection := NewSagaReadModelProjection(store)
+	subscriber := NewSagaReadModelSubscriber(projection)
+	for _, event := range bus.events {
+		if err := subscriber.Handle(ctx, event); err != nil {
+			t.Fatalf("Failed to handle %T: %v", event, err)
+		}
+	}
+	if err := projection.HandleSagaFailed(ctx, &SagaFailedEvent{
+		SagaID: "failure-saga-1", Error: "step charge failed", Failure: failure,
+	}); err != nil {
+		t.Fatalf("HandleSagaFailed failed: %v", err)
+	}
+
+	status, err := store.GetSagaStatus(ctx, "failure-saga-1")
+	if err != nil {
+		t.Fatalf("Failed to get saga status: %v", err)
+	}
+	if status.LastFailure == nil || status.LastFailure.Code != "PAYMENT_DECLINED" {
+		t.Errorf("Expected last failure PAYMENT_DECLINED, got %+v", status.LastFailure)
+	}
+
+	metrics, err := store.GetMetrics(ctx, MetricsFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	if metrics.FailuresByCategory[FailureCategoryBusiness] != 1 || metrics.FailuresByCode["PAYMENT_DECLINED"] != 1 {
+		t.Errorf("Unexpected failure aggregates: %v %v", metrics.FailuresByCategory, metrics.FailuresByCode)
+	}
+}
+
+func TestSagaQueryHandler_Export(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySagaReadModelStore()
+	for i := 0; i < 3; i++ {
+		model := &SagaReadModel{
+			SagaID:         fmt.Sprintf("saga-%d", i),
+			DefinitionName: "test_saga",
+			Status:         SagaStatusRunning,
+			StartedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+		if err := store.UpsertSagaReadModel(ctx, model); err != nil {
+			t.Fatalf("Failed to upsert read model: %v", err)
+		}
+	}
+	handler := NewSagaQueryHandler(nil, store)
+
+	// Handler реализует transport.QueryExporter: шина запросов выгружает саги постранично
+	var exporter transport.QueryExporter = handler
+	var out bytes.Buffer
+	writer, _ := transport.NewExportWriter(&out, transport.ExportFormatNDJSON)
+	if err := exporter.Export(ctx, &ListSagasQuery{}, writer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count := strings.Count(out.String(), "\n"); count != 3 {
+		t.Errorf("Expected 3 NDJSON lines, got %d", count)
+	}
+
+	out.Reset()
+	writer, _ = transport.NewExportWriter(&out, transport.ExportFormatNDJSON)
+	if err := exporter.Export(ctx, &GetSagaStatusQuery{SagaID: "saga-1"}, writer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "saga-1") {
+		t.Errorf("Expected saga status in export, got %s", out.String())
+	}
+}
@@ -3,10 +3,13 @@ package saga
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -43,12 +46,14 @@ type MetricsFilter struct {
 // InMemorySagaReadModelStore реализация read model store в памяти для тестирования
 type InMemorySagaReadModelStore struct {
 	models map[string]*SagaReadModel
+	steps  map[sagaStepKey]*SagaStepReadModel
 }
 
 // NewInMemorySagaReadModelStore создает новый InMemorySagaReadModelStore
 func NewInMemorySagaReadModelStore() *InMemorySagaReadModelStore {
 	return &InMemorySagaReadModelStore{
 		models: make(map[string]*SagaReadModel),
+		steps:  make(map[sagaStepKey]*SagaStepReadModel),
 	}
 }
 
@@ -78,6 +83,10 @@ func (s *InMemorySagaReadModelStore) GetSagaStatus(ctx context.Context, sagaID s
 		errMsg := *model.LastError
 		response.LastError = &errMsg
 	}
+	response.LastFailure = model.LastFailure
+	response.applySLA(model.SLADeadline, model.SLABreached, time.Now())
+	response.LastHeartbeatAt = model.LastHeartbeatAt
+	response.StepProgress = model.StepProgress
 
 	return response, nil
 }
@@ -115,6 +124,14 @@ func (s *InMemorySagaReadModelStore) ListSagas(ctx context.Context, filter SagaF
 		summaries = append(summaries, summary)
 	}
 
+	// Стабильный порядок, чтобы постраничное чтение не пропускало и не дублировало саги
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].StartedAt.Equal(summaries[j].StartedAt) {
+			return summaries[i].StartedAt.After(summaries[j].StartedAt)
+		}
+		return summaries[i].SagaID < summaries[j].SagaID
+	})
+
 	// Применяем пагинацию
 	total := len(summaries)
 	start := filter.Offset
@@ -145,8 +162,7 @@ func (s *InMemorySagaReadModelStore) UpsertSagaReadModel(ctx context.Context, mo
 }
 
 func (s *InMemorySagaReadModelStore) UpsertSagaStepReadModel(ctx context.Context, step *SagaStepReadModel) error {
-	// Для in-memory store шаги хранятся в самой саге через GetHistory
-	// Можно расширить структуру для хранения шагов отдельно если нужно
+	s.steps[newSagaStepKey(step)] = step
 	return nil
 }
 
@@ -154,6 +170,8 @@ func (s *InMemorySagaReadModelStore) GetMetrics(ctx context.Context, filter Metr
 	var total, completed, failed, compensated int
 	var totalDuration time.Duration
 	var sagaCount int
+	response := &SagaMetricsResponse{}
+	matched := make(map[string]bool)
 
 	for _, model := range s.models {
 		// Применяем фильтры
@@ -168,13 +186,16 @@ func (s *InMemorySagaReadModelStore) GetMetrics(ctx context.Context, filter Metr
 		}
 
 		total++
+		matched[model.SagaID] = true
 		switch model.Status {
 		case SagaStatusCompleted:
 			completed++
-		case SagaStatusFailed:
+		case SagaStatusFailed, SagaStatusCompensationStuck:
 			failed++
+			response.addFailure(model.LastFailure)
 		case SagaStatusCompensated:
 			compensated++
+			response.addFailure(model.LastFailure)
 		}
 
 		if model.CompletedAt != nil && model.Duration != nil {
@@ -193,15 +214,21 @@ func (s *InMemorySagaReadModelStore) GetMetrics(ctx context.Context, filter Metr
 		avgDuration = totalDuration / time.Duration(sagaCount)
 	}
 
-	return &SagaMetricsResponse{
-		TotalSagas:       total,
-		CompletedSagas:   completed,
-		FailedSagas:      failed,
-		CompensatedSagas: compensated,
-		SuccessRate:      successRate,
-		AvgDuration:      avgDuration,
-		Throughput:       0,
-	}, nil
+	response.TotalSagas = total
+	response.CompletedSagas = completed
+	response.FailedSagas = failed
+	response.CompensatedSagas = compensated
+	response.SuccessRate = successRate
+	response.AvgDuration = avgDuration
+
+	steps := newStepMetricsCollector()
+	for _, step := range s.steps {
+		if matched[step.SagaID] {
+			steps.add(step.StepName, step.Status, step.Duration)
+		}
+	}
+	response.Steps = steps.result()
+	return response, nil
 }
 
 // SagaReadModel денормализованное представление саги
@@ -219,7 +246,14 @@ type SagaReadModel struct {
 	CorrelationID string
 	Context       map[string]interface{}
 	LastError     *string
+	// LastFailure структурированная ошибка, на которой остановилась сага
+	LastFailure   *SagaFailure
 	RetryCount    int
+	SLADeadline   *time.Time
+	SLABreached   bool
+	// LastHeartbeatAt время последнего heartbeat текущего шага, StepProgress - его доля выполнения
+	LastHeartbeatAt *time.Time
+	StepProgress    *float64
 	UpdatedAt     time.Time
 }
 
@@ -233,9 +267,122 @@ type SagaStepReadModel struct {
 	Duration      *time.Duration
 	RetryAttempt  int
 	Error         *string
+	Failure       *SagaFailure
 	UpdatedAt     time.Time
 }
 
+// postgresSagaReadModelUpsertQuery запрос upsert read model саги
+const postgresSagaReadModelUpsertQuery = `
+		INSERT INTO saga_read_models (
+			saga_id, definition_name, status, current_step, total_steps,
+			completed_steps, failed_steps, started_at, completed_at, duration_ms,
+			correlation_id, context, last_error, retry_count, updated_at,
+			sla_deadline, sla_breached, last_failure, last_heartbeat_at, step_progress
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		ON CONFLICT (saga_id) DO UPDATE SET
+			definition_name = EXCLUDED.definition_name,
+			status = EXCLUDED.status,
+			current_step = EXCLUDED.current_step,
+			total_steps = EXCLUDED.total_steps,
+			completed_steps = EXCLUDED.completed_steps,
+			failed_steps = EXCLUDED.failed_steps,
+			started_at = EXCLUDED.started_at,
+			completed_at = EXCLUDED.completed_at,
+			duration_ms = EXCLUDED.duration_ms,
+			correlation_id = EXCLUDED.correlation_id,
+			context = EXCLUDED.context,
+			last_error = EXCLUDED.last_error,
+			retry_count = EXCLUDED.retry_count,
+			updated_at = EXCLUDED.updated_at,
+			sla_deadline = EXCLUDED.sla_deadline,
+			sla_breached = EXCLUDED.sla_breached,
+			last_failure = EXCLUDED.last_failure,
+			last_heartbeat_at = EXCLUDED.last_heartbeat_at,
+			step_progress = EXCLUDED.step_progress
+	`
+
+// postgresSagaReadModelArgs возвращает аргументы postgresSagaReadModelUpsertQuery
+func postgresSagaReadModelArgs(model *SagaReadModel) []interface{} {
+	var durationMs *int64
+	if model.Duration != nil {
+		ms := int64(model.Duration.Milliseconds())
+		durationMs = &ms
+	}
+
+	return []interface{}{
+		model.SagaID,
+		model.DefinitionName,
+		string(model.Status),
+		model.CurrentStep,
+		model.TotalSteps,
+		model.CompletedSteps,
+		model.FailedSteps,
+		model.StartedAt,
+		model.CompletedAt,
+		durationMs,
+		model.CorrelationID,
+		model.Context,
+		model.LastError,
+		model.RetryCount,
+		model.UpdatedAt,
+		model.SLADeadline,
+		model.SLABreached,
+		failureJSON(model.LastFailure),
+		model.LastHeartbeatAt,
+		model.StepProgress,
+	}
+}
+
+// postgresSagaStepReadModelUpsertQuery запрос upsert read model шага саги
+const postgresSagaStepReadModelUpsertQuery = `
+		INSERT INTO saga_step_read_models (
+			saga_id, step_name, status, started_at, completed_at, duration_ms,
+			retry_attempt, error, updated_at, failure
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (saga_id, step_name, started_at) DO UPDATE SET
+			status = EXCLUDED.status,
+			completed_at = EXCLUDED.completed_at,
+			duration_ms = EXCLUDED.duration_ms,
+			retry_attempt = EXCLUDED.retry_attempt,
+			error = EXCLUDED.error,
+			updated_at = EXCLUDED.updated_at,
+			failure = EXCLUDED.failure
+	`
+
+// postgresSagaStepReadModelArgs возвращает аргументы postgresSagaStepReadModelUpsertQuery
+func postgresSagaStepReadModelArgs(step *SagaStepReadModel) []interface{} {
+	var durationMs *int64
+	if step.Duration != nil {
+		ms := int64(step.Duration.Milliseconds())
+		durationMs = &ms
+	}
+
+	return []interface{}{
+		step.SagaID,
+		step.StepName,
+		step.Status,
+		step.StartedAt,
+		step.CompletedAt,
+		durationMs,
+		step.RetryAttempt,
+		step.Error,
+		step.UpdatedAt,
+		failureJSON(step.Failure),
+	}
+}
+
+// failureJSON сериализует SagaFailure для колонки JSONB (nil - NULL)
+func failureJSON(failure *SagaFailure) []byte {
+	if failure == nil {
+		return nil
+	}
+	data, err := json.Marshal(failure)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
 // PostgresSagaReadModelStore реализация read model store для PostgreSQL
 type PostgresSagaReadModelStore struct {
 	conn *pgx.Conn
@@ -256,61 +403,26 @@ func NewPostgresSagaReadModelStore(dsn string) (*PostgresSagaReadModelStore, err
 	return store, nil
 }
 
+// ensureTable создает таблицы read model миграциями 006 и 008 через отдельное соединение database/sql
 func (s *PostgresSagaReadModelStore) ensureTable(ctx context.Context) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS saga_read_models (
-			saga_id VARCHAR(255) PRIMARY KEY,
-			definition_name VARCHAR(255) NOT NULL,
-			status VARCHAR(50) NOT NULL,
-			current_step VARCHAR(255),
-			total_steps INTEGER,
-			completed_steps INTEGER,
-			failed_steps INTEGER,
-			started_at TIMESTAMP NOT NULL,
-			completed_at TIMESTAMP,
-			duration_ms INTEGER,
-			correlation_id VARCHAR(255),
-			context JSONB,
-			last_error TEXT,
-			retry_count INTEGER,
-			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-		);
-		
-		CREATE TABLE IF NOT EXISTS saga_step_read_models (
-			saga_id VARCHAR(255) NOT NULL,
-			step_name VARCHAR(255) NOT NULL,
-			status VARCHAR(50) NOT NULL,
-			started_at TIMESTAMP NOT NULL,
-			completed_at TIMESTAMP,
-			duration_ms INTEGER,
-			retry_attempt INTEGER DEFAULT 0,
-			error TEXT,
-			updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
-			PRIMARY KEY (saga_id, step_name, started_at)
-		);
-		
-		CREATE INDEX IF NOT EXISTS idx_saga_rm_status ON saga_read_models(status);
-		CREATE INDEX IF NOT EXISTS idx_saga_rm_definition ON saga_read_models(definition_name);
-		CREATE INDEX IF NOT EXISTS idx_saga_rm_correlation ON saga_read_models(correlation_id);
-		CREATE INDEX IF NOT EXISTS idx_saga_rm_started_at ON saga_read_models(started_at);
-		CREATE INDEX IF NOT EXISTS idx_saga_step_rm_saga_id ON saga_step_read_models(saga_id);
-		CREATE INDEX IF NOT EXISTS idx_saga_step_rm_status ON saga_step_read_models(status);
-	`
-	_, err := s.conn.Exec(ctx, query)
-	return err
+	db := stdlib.OpenDB(*s.conn.Config())
+	defer db.Close()
+	return ensureReadModelSchema(ctx, db, "postgres")
 }
 
 func (s *PostgresSagaReadModelStore) GetSagaStatus(ctx context.Context, sagaID string) (*SagaStatusResponse, error) {
 	query := `
 		SELECT saga_id, definition_name, status, current_step, total_steps,
 		       completed_steps, failed_steps, started_at, completed_at, duration_ms,
-		       correlation_id, context, last_error, retry_count
+		       correlation_id, context, last_error, retry_count, sla_deadline, sla_breached, last_failure,
+		       last_heartbeat_at, step_progress
 		FROM saga_read_models
 		WHERE saga_id = $1
 	`
 
 	var model SagaReadModel
 	var durationMs *int64
+	var lastFailure []byte
 	err := s.conn.QueryRow(ctx, query, sagaID).Scan(
 		&model.SagaID,
 		&model.DefinitionName,
@@ -326,10 +438,16 @@ func (s *PostgresSagaReadModelStore) GetSagaStatus(ctx context.Context, sagaID s
 		&model.Context,
 		&model.LastError,
 		&model.RetryCount,
+		&model.SLADeadline,
+		&model.SLABreached,
+		&lastFailure,
+		&model.LastHeartbeatAt,
+		&model.StepProgress,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get saga status: %w", err)
 	}
+	model.LastFailure = failureFromValue(lastFailure)
 
 	if durationMs != nil {
 		duration := time.Duration(*durationMs) * time.Millisecond
@@ -350,92 +468,23 @@ func (s *PostgresSagaReadModelStore) GetSagaStatus(ctx context.Context, sagaID s
 		CorrelationID: model.CorrelationID,
 		Context:       model.Context,
 		LastError:     model.LastError,
+		LastFailure:   model.LastFailure,
 		RetryCount:    model.RetryCount,
 	}
+	response.applySLA(model.SLADeadline, model.SLABreached, time.Now())
+	response.LastHeartbeatAt = model.LastHeartbeatAt
+	response.StepProgress = model.StepProgress
 
 	return response, nil
 }
 
 func (s *PostgresSagaReadModelStore) UpsertSagaReadModel(ctx context.Context, model *SagaReadModel) error {
-	var durationMs *int64
-	if model.Duration != nil {
-		ms := int64(model.Duration.Milliseconds())
-		durationMs = &ms
-	}
-
-	query := `
-		INSERT INTO saga_read_models (
-			saga_id, definition_name, status, current_step, total_steps,
-			completed_steps, failed_steps, started_at, completed_at, duration_ms,
-			correlation_id, context, last_error, retry_count, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
-		ON CONFLICT (saga_id) DO UPDATE SET
-			definition_name = EXCLUDED.definition_name,
-			status = EXCLUDED.status,
-			current_step = EXCLUDED.current_step,
-			total_steps = EXCLUDED.total_steps,
-			completed_steps = EXCLUDED.completed_steps,
-			failed_steps = EXCLUDED.failed_steps,
-			started_at = EXCLUDED.started_at,
-			completed_at = EXCLUDED.completed_at,
-			duration_ms = EXCLUDED.duration_ms,
-			correlation_id = EXCLUDED.correlation_id,
-			context = EXCLUDED.context,
-			last_error = EXCLUDED.last_error,
-			retry_count = EXCLUDED.retry_count,
-			updated_at = EXCLUDED.updated_at
-	`
-	_, err := s.conn.Exec(ctx, query,
-		model.SagaID,
-		model.DefinitionName,
-		string(model.Status),
-		model.CurrentStep,
-		model.TotalSteps,
-		model.CompletedSteps,
-		model.FailedSteps,
-		model.StartedAt,
-		model.CompletedAt,
-		durationMs,
-		model.CorrelationID,
-		model.Context,
-		model.LastError,
-		model.RetryCount,
-		model.UpdatedAt,
-	)
+	_, err := s.conn.Exec(ctx, postgresSagaReadModelUpsertQuery, postgresSagaReadModelArgs(model)...)
 	return err
 }
 
 func (s *PostgresSagaReadModelStore) UpsertSagaStepReadModel(ctx context.Context, step *SagaStepReadModel) error {
-	var durationMs *int64
-	if step.Duration != nil {
-		ms := int64(step.Duration.Milliseconds())
-		durationMs = &ms
-	}
-
-	query := `
-		INSERT INTO saga_step_read_models (
-			saga_id, step_name, status, started_at, completed_at, duration_ms,
-			retry_attempt, error, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (saga_id, step_name, started_at) DO UPDATE SET
-			status = EXCLUDED.status,
-			completed_at = EXCLUDED.completed_at,
-			duration_ms = EXCLUDED.duration_ms,
-			retry_attempt = EXCLUDED.retry_attempt,
-			error = EXCLUDED.error,
-			updated_at = EXCLUDED.updated_at
-	`
-	_, err := s.conn.Exec(ctx, query,
-		step.SagaID,
-		step.StepName,
-		step.Status,
-		step.StartedAt,
-		step.CompletedAt,
-		durationMs,
-		step.RetryAttempt,
-		step.Error,
-		step.UpdatedAt,
-	)
+	_, err := s.conn.Exec(ctx, postgresSagaStepReadModelUpsertQuery, postgresSagaStepReadModelArgs(step)...)
 	return err
 }
 
@@ -527,32 +576,34 @@ func (s *PostgresSagaReadModelStore) ListSagas(ctx context.Context, filter SagaF
 }
 
 func (s *PostgresSagaReadModelStore) GetMetrics(ctx context.Context, filter MetricsFilter) (*SagaMetricsResponse, error) {
-	query := `SELECT 
-		COUNT(*) as total,
-		COUNT(*) FILTER (WHERE status = 'completed') as completed,
-		COUNT(*) FILTER (WHERE status = 'failed') as failed,
-		COUNT(*) FILTER (WHERE status = 'compensated') as compensated,
-		AVG(duration_ms) as avg_duration_ms
-		FROM saga_read_models WHERE 1=1`
+	where := " WHERE 1=1"
 	args := []interface{}{}
 	argIndex := 1
 
 	if filter.DefinitionName != nil {
-		query += fmt.Sprintf(" AND definition_name = $%d", argIndex)
+		where += fmt.Sprintf(" AND definition_name = $%d", argIndex)
 		args = append(args, *filter.DefinitionName)
 		argIndex++
 	}
 	if filter.StartedAfter != nil {
-		query += fmt.Sprintf(" AND started_at >= $%d", argIndex)
+		where += fmt.Sprintf(" AND started_at >= $%d", argIndex)
 		args = append(args, *filter.StartedAfter)
 		argIndex++
 	}
 	if filter.StartedBefore != nil {
-		query += fmt.Sprintf(" AND started_at <= $%d", argIndex)
+		where += fmt.Sprintf(" AND started_at <= $%d", argIndex)
 		args = append(args, *filter.StartedBefore)
 		argIndex++
 	}
 
+	query := `SELECT 
+		COUNT(*) as total,
+		COUNT(*) FILTER (WHERE status = 'completed') as completed,
+		COUNT(*) FILTER (WHERE status = 'failed') as failed,
+		COUNT(*) FILTER (WHERE status = 'compensated') as compensated,
+		AVG(duration_ms) as avg_duration_ms
+		FROM saga_read_models` + where
+
 	var total, completed, failed, compensated int
 	var avgDurationMs *float64
 
@@ -577,7 +628,7 @@ func (s *PostgresSagaReadModelStore) GetMetrics(ctx context.Context, filter Metr
 		avgDuration = time.Duration(*avgDurationMs) * time.Millisecond
 	}
 
-	return &SagaMetricsResponse{
+	response := &SagaMetricsResponse{
 		TotalSagas:       total,
 		CompletedSagas:   completed,
 		FailedSagas:      failed,
@@ -585,7 +636,137 @@ func (s *PostgresSagaReadModelStore) GetMetrics(ctx context.Context, filter Metr
 		SuccessRate:      successRate,
 		AvgDuration:      avgDuration,
 		Throughput:       0,
-	}, nil
+	}
+
+	// Причины ошибок неуспешных саг по категориям и кодам
+	failuresQuery := `SELECT last_failure->>'category', COALESCE(last_failure->>'code', ''), COUNT(*)
+		FROM saga_read_models` + where + `
+		AND last_failure IS NOT NULL
+		AND status IN ('failed', 'compensated', 'compensation_stuck')
+		GROUP BY 1, 2`
+	rows, err := s.conn.Query(ctx, failuresQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failure metrics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category, code string
+		var count int
+		if err := rows.Scan(&category, &code, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan failure metrics: %w", err)
+		}
+		response.addFailureCount(FailureCategory(category), code, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get failure metrics: %w", err)
+	}
+
+	// Латентность и доля ошибок шагов саг, попавших в фильтр
+	stepsQuery := `SELECT step_name,
+		COUNT(*),
+		COUNT(*) FILTER (WHERE status = 'failed'),
+		percentile_disc(0.5) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE status = 'completed'),
+		percentile_disc(0.95) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE status = 'completed'),
+		AVG(duration_ms) FILTER (WHERE status = 'completed')
+		FROM saga_step_read_models
+		WHERE status IN ('completed', 'failed')
+		AND saga_id IN (SELECT saga_id FROM saga_read_models` + where + `)
+		GROUP BY step_name
+		ORDER BY step_name`
+	stepRows, err := s.conn.Query(ctx, stepsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step metrics: %w", err)
+	}
+	defer stepRows.Close()
+
+	for stepRows.Next() {
+		var step StepMetrics
+		var p50Ms, p95Ms *int64
+		var avgMs *float64
+		if err := stepRows.Scan(&step.StepName, &step.Executions, &step.Failures, &p50Ms, &p95Ms, &avgMs); err != nil {
+			return nil, fmt.Errorf("failed to scan step metrics: %w", err)
+		}
+		step.FailureRate = stepFailureRate(step.Executions, step.Failures)
+		if p50Ms != nil {
+			step.P50Duration = time.Duration(*p50Ms) * time.Millisecond
+		}
+		if p95Ms != nil {
+			step.P95Duration = time.Duration(*p95Ms) * time.Millisecond
+		}
+		if avgMs != nil {
+			step.AvgDuration = time.Duration(*avgMs) * time.Millisecond
+		}
+		response.Steps = append(response.Steps, step)
+	}
+	if err := stepRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get step metrics: %w", err)
+	}
+
+	return response, nil
+}
+
+// mongoSagaReadModelDocument возвращает документ read model саги
+func mongoSagaReadModelDocument(model *SagaReadModel) bson.M {
+	var durationMs *int64
+	if model.Duration != nil {
+		ms := int64(model.Duration.Milliseconds())
+		durationMs = &ms
+	}
+
+	return bson.M{
+		"_id":            model.SagaID,
+		"definition_name": model.DefinitionName,
+		"status":         string(model.Status),
+		"current_step":   model.CurrentStep,
+		"total_steps":    model.TotalSteps,
+		"completed_steps": model.CompletedSteps,
+		"failed_steps":   model.FailedSteps,
+		"started_at":     model.StartedAt,
+		"completed_at":   model.CompletedAt,
+		"duration_ms":    durationMs,
+		"correlation_id": model.CorrelationID,
+		"context":        model.Context,
+		"last_error":     model.LastError,
+		"retry_count":    model.RetryCount,
+		"updated_at":     model.UpdatedAt,
+		"sla_deadline":   model.SLADeadline,
+		"sla_breached":   model.SLABreached,
+		"last_failure":   model.LastFailure,
+		"last_heartbeat_at": model.LastHeartbeatAt,
+		"step_progress":  model.StepProgress,
+	}
+}
+
+// mongoSagaStepReadModelDocument возвращает документ read model шага саги
+func mongoSagaStepReadModelDocument(step *SagaStepReadModel) bson.M {
+	var durationMs *int64
+	if step.Duration != nil {
+		ms := int64(step.Duration.Milliseconds())
+		durationMs = &ms
+	}
+
+	return bson.M{
+		"saga_id":       step.SagaID,
+		"step_name":     step.StepName,
+		"status":        step.Status,
+		"started_at":    step.StartedAt,
+		"completed_at":  step.CompletedAt,
+		"duration_ms":   durationMs,
+		"retry_attempt": step.RetryAttempt,
+		"error":         step.Error,
+		"failure":       step.Failure,
+		"updated_at":    step.UpdatedAt,
+	}
+}
+
+// mongoSagaStepReadModelKey возвращает составной ключ документа шага саги
+func mongoSagaStepReadModelKey(step *SagaStepReadModel) bson.M {
+	return bson.M{
+		"saga_id":    step.SagaID,
+		"step_name":  step.StepName,
+		"started_at": step.StartedAt,
+	}
 }
 
 // MongoSagaReadModelStore реализация read model store для MongoDB
@@ -642,72 +823,27 @@ func (s *MongoSagaReadModelStore) GetSagaStatus(ctx context.Context, sagaID stri
 		CorrelationID: model.CorrelationID,
 		Context:       model.Context,
 		LastError:     model.LastError,
+		LastFailure:   model.LastFailure,
 		RetryCount:    model.RetryCount,
 	}
+	response.applySLA(model.SLADeadline, model.SLABreached, time.Now())
+	response.LastHeartbeatAt = model.LastHeartbeatAt
+	response.StepProgress = model.StepProgress
 
 	return response, nil
 }
 
 func (s *MongoSagaReadModelStore) UpsertSagaReadModel(ctx context.Context, model *SagaReadModel) error {
-	var durationMs *int64
-	if model.Duration != nil {
-		ms := int64(model.Duration.Milliseconds())
-		durationMs = &ms
-	}
-
-	doc := bson.M{
-		"_id":            model.SagaID,
-		"definition_name": model.DefinitionName,
-		"status":         string(model.Status),
-		"current_step":   model.CurrentStep,
-		"total_steps":    model.TotalSteps,
-		"completed_steps": model.CompletedSteps,
-		"failed_steps":   model.FailedSteps,
-		"started_at":     model.StartedAt,
-		"completed_at":   model.CompletedAt,
-		"duration_ms":    durationMs,
-		"correlation_id": model.CorrelationID,
-		"context":        model.Context,
-		"last_error":     model.LastError,
-		"retry_count":    model.RetryCount,
-		"updated_at":     model.UpdatedAt,
-	}
-
 	opts := options.Update().SetUpsert(true)
-	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": model.SagaID}, bson.M{"$set": doc}, opts)
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": model.SagaID}, bson.M{"$set": mongoSagaReadModelDocument(model)}, opts)
 	return err
 }
 
 func (s *MongoSagaReadModelStore) UpsertSagaStepReadModel(ctx context.Context, step *SagaStepReadModel) error {
 	stepCollection := s.collection.Database().Collection("saga_step_read_models")
-	
-	var durationMs *int64
-	if step.Duration != nil {
-		ms := int64(step.Duration.Milliseconds())
-		durationMs = &ms
-	}
-
-	doc := bson.M{
-		"saga_id":       step.SagaID,
-		"step_name":     step.StepName,
-		"status":        step.Status,
-		"started_at":    step.StartedAt,
-		"completed_at":  step.CompletedAt,
-		"duration_ms":   durationMs,
-		"retry_attempt": step.RetryAttempt,
-		"error":         step.Error,
-		"updated_at":    step.UpdatedAt,
-	}
-
-	// Используем составной ключ для уникальности
-	filter := bson.M{
-		"saga_id":    step.SagaID,
-		"step_name":  step.StepName,
-		"started_at": step.StartedAt,
-	}
 
 	opts := options.Update().SetUpsert(true)
-	_, err := stepCollection.UpdateOne(ctx, filter, bson.M{"$set": doc}, opts)
+	_, err := stepCollection.UpdateOne(ctx, mongoSagaStepReadModelKey(step), bson.M{"$set": mongoSagaStepReadModelDocument(step)}, opts)
 	return err
 }
 
@@ -820,7 +956,7 @@ func (s *MongoSagaReadModelStore) GetMetrics(ctx context.Context, filter Metrics
 		avgDuration = time.Duration(*result.AvgDurationMs) * time.Millisecond
 	}
 
-	return &SagaMetricsResponse{
+	response := &SagaMetricsResponse{
 		TotalSagas:       result.Total,
 		CompletedSagas:   result.Completed,
 		FailedSagas:      result.Failed,
@@ -828,6 +964,102 @@ func (s *MongoSagaReadModelStore) GetMetrics(ctx context.Context, filter Metrics
 		SuccessRate:      successRate,
 		AvgDuration:      avgDuration,
 		Throughput:       0,
-	}, nil
+	}
+
+	// Причины ошибок неуспешных саг по категориям и кодам
+	failuresFilter := bson.M{
+		"last_failure": bson.M{"$ne": nil},
+		"status":       bson.M{"$in": []string{string(SagaStatusFailed), string(SagaStatusCompensated), string(SagaStatusCompensationStuck)}},
+	}
+	for k, v := range mongoFilter {
+		failuresFilter[k] = v
+	}
+	failuresPipeline := []bson.M{
+		{"$match": failuresFilter},
+		{"$group": bson.M{
+			"_id":   bson.M{"category": "$last_failure.category", "code": "$last_failure.code"},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	failuresCursor, err := s.collection.Aggregate(ctx, failuresPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failure metrics: %w", err)
+	}
+	defer failuresCursor.Close(ctx)
+
+	for failuresCursor.Next(ctx) {
+		var group struct {
+			ID struct {
+				Category string `bson:"category"`
+				Code     string `bson:"code"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		}
+		if err := failuresCursor.Decode(&group); err != nil {
+			return nil, fmt.Errorf("failed to decode failure metrics: %w", err)
+		}
+		response.addFailureCount(FailureCategory(group.ID.Category), group.ID.Code, group.Count)
+	}
+
+	steps, err := s.stepMetrics(ctx, mongoFilter)
+	if err != nil {
+		return nil, err
+	}
+	response.Steps = steps
+
+	return response, nil
+}
+
+// stepMetrics вычисляет латентность и долю ошибок шагов саг, попавших в sagaFilter
+func (s *MongoSagaReadModelStore) stepMetrics(ctx context.Context, sagaFilter bson.M) ([]StepMetrics, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"status": bson.M{"$in": []string{string(StepStatusCompleted), string(StepStatusFailed)}}}},
+	}
+	if len(sagaFilter) > 0 {
+		sagaMatch := bson.M{}
+		for k, v := range sagaFilter {
+			sagaMatch["saga."+k] = v
+		}
+		pipeline = append(pipeline,
+			bson.M{"$lookup": bson.M{
+				"from":         s.collection.Name(),
+				"localField":   "saga_id",
+				"foreignField": "_id",
+				"as":           "saga",
+			}},
+			bson.M{"$match": sagaMatch},
+		)
+	}
+	pipeline = append(pipeline, bson.M{"$project": bson.M{"step_name": 1, "status": 1, "duration_ms": 1}})
+
+	cursor, err := s.collection.Database().Collection("saga_step_read_models").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step metrics: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	collector := newStepMetricsCollector()
+	for cursor.Next(ctx) {
+		var step struct {
+			StepName   string `bson:"step_name"`
+			Status     string `bson:"status"`
+			DurationMs *int64 `bson:"duration_ms"`
+		}
+		if err := cursor.Decode(&step); err != nil {
+			return nil, fmt.Errorf("failed to decode step metrics: %w", err)
+		}
+		var duration *time.Duration
+		if step.DurationMs != nil {
+			d := time.Duration(*step.DurationMs) * time.Millisecond
+			duration = &d
+		}
+		collector.add(step.StepName, step.Status, duration)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get step metrics: %w", err)
+	}
+
+	return collector.result(), nil
 }
 
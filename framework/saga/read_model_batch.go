@@ -0,0 +1,371 @@
+// Package saga предоставляет механизмы для работы с сагами.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SagaReadModelBatchStore опциональный интерфейс SagaReadModelStore для пакетной записи.
+// Запись должна быть безопасной при конфликтах: состояние с более ранним UpdatedAt
+// не перезаписывает уже сохраненное более новое состояние.
+type SagaReadModelBatchStore interface {
+	UpsertSagaReadModels(ctx context.Context, models []*SagaReadModel) error
+	UpsertSagaStepReadModels(ctx context.Context, steps []*SagaStepReadModel) error
+}
+
+// sagaStepKey ключ read model шага саги
+type sagaStepKey struct {
+	sagaID    string
+	stepName  string
+	startedAt time.Time
+}
+
+func newSagaStepKey(step *SagaStepReadModel) sagaStepKey {
+	return sagaStepKey{sagaID: step.SagaID, stepName: step.StepName, startedAt: step.StartedAt}
+}
+
+// sagaReadModelBatch буфер несохраненных read models проекции.
+// Повторные изменения одной саги или шага объединяются в одну запись.
+type sagaReadModelBatch struct {
+	size     int
+	models   map[string]*SagaReadModel
+	steps    map[sagaStepKey]*SagaStepReadModel
+	inflight map[string]*SagaReadModel // read models, записываемые текущим Flush
+	flushMu  sync.Mutex
+	ticker   *time.Ticker
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func (b *sagaReadModelBatch) len() int {
+	return len(b.models) + len(b.steps)
+}
+
+// WithBatching включает микро-батчинг записи read models: изменения накапливаются в памяти
+// и сохраняются пакетом при достижении size записей или раз в flushInterval.
+// Store, реализующий SagaReadModelBatchStore, пишет пакет одним запросом.
+// При остановке сервиса вызовите Stop, чтобы сохранить накопленные изменения.
+func (p *SagaReadModelProjection) WithBatching(size int, flushInterval time.Duration) *SagaReadModelProjection {
+	if size <= 0 {
+		size = 1
+	}
+
+	batch := &sagaReadModelBatch{
+		size:   size,
+		models: make(map[string]*SagaReadModel),
+		steps:  make(map[sagaStepKey]*SagaStepReadModel),
+		stopCh: make(chan struct{}),
+	}
+	if flushInterval > 0 {
+		batch.ticker = time.NewTicker(flushInterval)
+		go p.flushLoop(batch)
+	}
+
+	p.batchMu.Lock()
+	p.batch = batch
+	p.batchMu.Unlock()
+	return p
+}
+
+func (p *SagaReadModelProjection) flushLoop(batch *sagaReadModelBatch) {
+	for {
+		select {
+		case <-batch.ticker.C:
+			_ = p.Flush(context.Background())
+		case <-batch.stopCh:
+			return
+		}
+	}
+}
+
+// Flush сохраняет накопленные изменения read models.
+// При ошибке записи изменения возвращаются в буфер, если не были перекрыты более новыми.
+func (p *SagaReadModelProjection) Flush(ctx context.Context) error {
+	p.batchMu.Lock()
+	batch := p.batch
+	p.batchMu.Unlock()
+	if batch == nil {
+		return nil
+	}
+
+	batch.flushMu.Lock()
+	defer batch.flushMu.Unlock()
+
+	p.batchMu.Lock()
+	if batch.len() == 0 {
+		p.batchMu.Unlock()
+		return nil
+	}
+	models, steps := batch.models, batch.steps
+	batch.models = make(map[string]*SagaReadModel)
+	batch.steps = make(map[sagaStepKey]*SagaStepReadModel)
+	batch.inflight = models
+	p.batchMu.Unlock()
+
+	err := p.writeBatch(ctx, models, steps)
+
+	p.batchMu.Lock()
+	defer p.batchMu.Unlock()
+	batch.inflight = nil
+	if err != nil {
+		for id, model := range models {
+			if _, ok := batch.models[id]; !ok {
+				batch.models[id] = model
+			}
+		}
+		for key, step := range steps {
+			if _, ok := batch.steps[key]; !ok {
+				batch.steps[key] = step
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// Stop останавливает периодическое сохранение и сохраняет накопленные изменения
+func (p *SagaReadModelProjection) Stop(ctx context.Context) error {
+	p.batchMu.Lock()
+	batch := p.batch
+	p.batchMu.Unlock()
+	if batch == nil {
+		return nil
+	}
+
+	batch.stopOnce.Do(func() {
+		if batch.ticker != nil {
+			batch.ticker.Stop()
+		}
+		close(batch.stopCh)
+	})
+	return p.Flush(ctx)
+}
+
+// writeBatch записывает пакет в store. Шаги пишутся первыми, так как read model саги
+// ссылается на текущий шаг.
+func (p *SagaReadModelProjection) writeBatch(ctx context.Context, models map[string]*SagaReadModel, steps map[sagaStepKey]*SagaStepReadModel) error {
+	stepList := make([]*SagaStepReadModel, 0, len(steps))
+	for _, step := range steps {
+		stepList = append(stepList, step)
+	}
+	sort.Slice(stepList, func(i, j int) bool {
+		if stepList[i].SagaID != stepList[j].SagaID {
+			return stepList[i].SagaID < stepList[j].SagaID
+		}
+		if stepList[i].StepName != stepList[j].StepName {
+			return stepList[i].StepName < stepList[j].StepName
+		}
+		return stepList[i].StartedAt.Before(stepList[j].StartedAt)
+	})
+
+	modelList := make([]*SagaReadModel, 0, len(models))
+	for _, model := range models {
+		modelList = append(modelList, model)
+	}
+	// Стабильный порядок ключей исключает взаимные блокировки параллельных пакетов
+	sort.Slice(modelList, func(i, j int) bool { return modelList[i].SagaID < modelList[j].SagaID })
+
+	if batchStore, ok := p.store.(SagaReadModelBatchStore); ok {
+		if len(stepList) > 0 {
+			if err := batchStore.UpsertSagaStepReadModels(ctx, stepList); err != nil {
+				return fmt.Errorf("failed to save step read models batch: %w", err)
+			}
+		}
+		if len(modelList) > 0 {
+			if err := batchStore.UpsertSagaReadModels(ctx, modelList); err != nil {
+				return fmt.Errorf("failed to save read models batch: %w", err)
+			}
+		}
+		return nil
+	}
+
+	for _, step := range stepList {
+		if err := p.store.UpsertSagaStepReadModel(ctx, step); err != nil {
+			return fmt.Errorf("failed to save step read model: %w", err)
+		}
+	}
+	for _, model := range modelList {
+		if err := p.store.UpsertSagaReadModel(ctx, model); err != nil {
+			return fmt.Errorf("failed to save read model: %w", err)
+		}
+	}
+	return nil
+}
+
+// bufferReadModel добавляет read model в буфер. Возвращает false, если батчинг выключен.
+func (p *SagaReadModelProjection) bufferReadModel(ctx context.Context, model *SagaReadModel) (bool, error) {
+	p.batchMu.Lock()
+	batch := p.batch
+	if batch == nil {
+		p.batchMu.Unlock()
+		return false, nil
+	}
+	batch.models[model.SagaID] = model
+	full := batch.len() >= batch.size
+	p.batchMu.Unlock()
+
+	if full {
+		return true, p.Flush(ctx)
+	}
+	return true, nil
+}
+
+// bufferStepReadModel добавляет read model шага в буфер. Возвращает false, если батчинг выключен.
+func (p *SagaReadModelProjection) bufferStepReadModel(ctx context.Context, step *SagaStepReadModel) (bool, error) {
+	p.batchMu.Lock()
+	batch := p.batch
+	if batch == nil {
+		p.batchMu.Unlock()
+		return false, nil
+	}
+	batch.steps[newSagaStepKey(step)] = step
+	full := batch.len() >= batch.size
+	p.batchMu.Unlock()
+
+	if full {
+		return true, p.Flush(ctx)
+	}
+	return true, nil
+}
+
+// pendingReadModel возвращает копию несохраненной read model саги из буфера
+func (p *SagaReadModelProjection) pendingReadModel(sagaID string) (*SagaReadModel, bool) {
+	p.batchMu.Lock()
+	defer p.batchMu.Unlock()
+
+	if p.batch == nil {
+		return nil, false
+	}
+	model, ok := p.batch.models[sagaID]
+	if !ok {
+		// До завершения Flush store может еще не содержать записываемое состояние
+		model, ok = p.batch.inflight[sagaID]
+	}
+	if !ok {
+		return nil, false
+	}
+	copied := *model
+	return &copied, true
+}
+
+// discardBatch очищает буфер без сохранения
+func (p *SagaReadModelProjection) discardBatch() {
+	p.batchMu.Lock()
+	defer p.batchMu.Unlock()
+
+	if p.batch != nil {
+		p.batch.models = make(map[string]*SagaReadModel)
+		p.batch.steps = make(map[sagaStepKey]*SagaStepReadModel)
+	}
+}
+
+// UpsertSagaReadModels сохраняет пакет read models
+func (s *InMemorySagaReadModelStore) UpsertSagaReadModels(ctx context.Context, models []*SagaReadModel) error {
+	for _, model := range models {
+		if existing, ok := s.models[model.SagaID]; ok && existing.UpdatedAt.After(model.UpdatedAt) {
+			continue
+		}
+		s.models[model.SagaID] = model
+	}
+	return nil
+}
+
+// UpsertSagaStepReadModels сохраняет пакет read models шагов
+func (s *InMemorySagaReadModelStore) UpsertSagaStepReadModels(ctx context.Context, steps []*SagaStepReadModel) error {
+	for _, step := range steps {
+		if err := s.UpsertSagaStepReadModel(ctx, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertSagaReadModels сохраняет пакет read models одним round-trip.
+// Строка не обновляется, если в таблице уже более новое состояние.
+func (s *PostgresSagaReadModelStore) UpsertSagaReadModels(ctx context.Context, models []*SagaReadModel) error {
+	query := postgresSagaReadModelUpsertQuery + " WHERE saga_read_models.updated_at <= EXCLUDED.updated_at"
+
+	batch := &pgx.Batch{}
+	for _, model := range models {
+		batch.Queue(query, postgresSagaReadModelArgs(model)...)
+	}
+	return s.sendBatch(ctx, batch)
+}
+
+// UpsertSagaStepReadModels сохраняет пакет read models шагов одним round-trip
+func (s *PostgresSagaReadModelStore) UpsertSagaStepReadModels(ctx context.Context, steps []*SagaStepReadModel) error {
+	query := postgresSagaStepReadModelUpsertQuery + " WHERE saga_step_read_models.updated_at <= EXCLUDED.updated_at"
+
+	batch := &pgx.Batch{}
+	for _, step := range steps {
+		batch.Queue(query, postgresSagaStepReadModelArgs(step)...)
+	}
+	return s.sendBatch(ctx, batch)
+}
+
+// sendBatch выполняет пакет запросов в одной неявной транзакции
+func (s *PostgresSagaReadModelStore) sendBatch(ctx context.Context, batch *pgx.Batch) error {
+	results := s.conn.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return err
+		}
+	}
+	return results.Close()
+}
+
+// UpsertSagaReadModels сохраняет пакет read models одним BulkWrite.
+// Документ не обновляется, если в коллекции уже более новое состояние.
+func (s *MongoSagaReadModelStore) UpsertSagaReadModels(ctx context.Context, models []*SagaReadModel) error {
+	writes := make([]mongo.WriteModel, 0, len(models))
+	for _, model := range models {
+		filter := bson.M{"_id": model.SagaID, "updated_at": bson.M{"$lte": model.UpdatedAt}}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(filter).
+			SetUpdate(bson.M{"$set": mongoSagaReadModelDocument(model)}).
+			SetUpsert(true))
+	}
+	_, err := s.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return ignoreStaleUpserts(err)
+}
+
+// UpsertSagaStepReadModels сохраняет пакет read models шагов одним BulkWrite
+func (s *MongoSagaReadModelStore) UpsertSagaStepReadModels(ctx context.Context, steps []*SagaStepReadModel) error {
+	stepCollection := s.collection.Database().Collection("saga_step_read_models")
+
+	writes := make([]mongo.WriteModel, 0, len(steps))
+	for _, step := range steps {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(mongoSagaStepReadModelKey(step)).
+			SetUpdate(bson.M{"$set": mongoSagaStepReadModelDocument(step)}).
+			SetUpsert(true))
+	}
+	_, err := stepCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// ignoreStaleUpserts пропускает ошибки дублирования ключа: upsert с фильтром по updated_at
+// пытается вставить документ, когда в коллекции уже более новое состояние
+func ignoreStaleUpserts(err error) error {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return err
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return err
+		}
+	}
+	return nil
+}
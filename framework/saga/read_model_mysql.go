@@ -0,0 +1,462 @@
+// Package saga предоставляет механизмы для работы с сагами.
+package saga
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akriventsev/potter/framework/eventsourcing"
+)
+
+// mysqlSagaReadModelColumns колонки saga_read_models в порядке mysqlSagaReadModelArgs
+var mysqlSagaReadModelColumns = []string{
+	"saga_id", "definition_name", "status", "current_step", "total_steps",
+	"completed_steps", "failed_steps", "started_at", "completed_at", "duration_ms",
+	"correlation_id", "context", "last_error", "retry_count",
+	"sla_deadline", "sla_breached", "last_failure", "last_heartbeat_at", "step_progress", "updated_at",
+}
+
+// mysqlSagaStepReadModelColumns колонки saga_step_read_models в порядке mysqlSagaStepReadModelArgs
+var mysqlSagaStepReadModelColumns = []string{
+	"saga_id", "step_name", "status", "started_at", "completed_at", "duration_ms",
+	"retry_attempt", "error", "failure", "updated_at",
+}
+
+// mysqlSagaStepReadModelKeyColumns колонки первичного ключа saga_step_read_models
+var mysqlSagaStepReadModelKeyColumns = []string{"saga_id", "step_name", "started_at"}
+
+// mysqlTime приводит время к UTC для колонок DATETIME(6) (nil - NULL)
+func mysqlTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// mysqlDurationMs возвращает длительность в миллисекундах (nil - NULL)
+func mysqlDurationMs(duration *time.Duration) *int64 {
+	if duration == nil {
+		return nil
+	}
+	ms := duration.Milliseconds()
+	return &ms
+}
+
+// mysqlSagaReadModelArgs возвращает значения колонок mysqlSagaReadModelColumns
+func mysqlSagaReadModelArgs(model *SagaReadModel) ([]interface{}, error) {
+	contextJSON, err := json.Marshal(model.Context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal context: %w", err)
+	}
+
+	return []interface{}{
+		model.SagaID,
+		model.DefinitionName,
+		string(model.Status),
+		model.CurrentStep,
+		model.TotalSteps,
+		model.CompletedSteps,
+		model.FailedSteps,
+		model.StartedAt.UTC(),
+		mysqlTime(model.CompletedAt),
+		mysqlDurationMs(model.Duration),
+		model.CorrelationID,
+		contextJSON,
+		model.LastError,
+		model.RetryCount,
+		mysqlTime(model.SLADeadline),
+		model.SLABreached,
+		failureJSON(model.LastFailure),
+		mysqlTime(model.LastHeartbeatAt),
+		model.StepProgress,
+		model.UpdatedAt.UTC(),
+	}, nil
+}
+
+// mysqlSagaStepReadModelArgs возвращает значения колонок mysqlSagaStepReadModelColumns
+func mysqlSagaStepReadModelArgs(step *SagaStepReadModel) []interface{} {
+	return []interface{}{
+		step.SagaID,
+		step.StepName,
+		step.Status,
+		step.StartedAt.UTC(),
+		mysqlTime(step.CompletedAt),
+		mysqlDurationMs(step.Duration),
+		step.RetryAttempt,
+		step.Error,
+		failureJSON(step.Failure),
+		step.UpdatedAt.UTC(),
+	}
+}
+
+// mysqlUpsertQuery строит INSERT ... ON DUPLICATE KEY UPDATE для rows строк.
+// Если guarded, строка обновляется только состоянием с updated_at не раньше сохраненного:
+// MySQL применяет присваивания слева направо, поэтому updated_at должен быть последней колонкой.
+func mysqlUpsertQuery(table string, columns, keyColumns []string, rows int, guarded bool) string {
+	placeholders := "(?" + strings.Repeat(", ?", len(columns)-1) + ")"
+	values := placeholders + strings.Repeat(", "+placeholders, rows-1)
+
+	isKey := make(map[string]bool, len(keyColumns))
+	for _, column := range keyColumns {
+		isKey[column] = true
+	}
+
+	assignments := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if isKey[column] {
+			continue
+		}
+		if guarded {
+			assignments = append(assignments, fmt.Sprintf("%s = IF(%s.updated_at <= new.updated_at, new.%s, %s.%s)",
+				column, table, column, table, column))
+		} else {
+			assignments = append(assignments, fmt.Sprintf("%s = new.%s", column, column))
+		}
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s AS new ON DUPLICATE KEY UPDATE %s",
+		table, strings.Join(columns, ", "), values, strings.Join(assignments, ", "))
+}
+
+// MySQLSagaReadModelStore реализация read model store для MySQL 8
+type MySQLSagaReadModelStore struct {
+	db *sql.DB
+}
+
+// NewMySQLSagaReadModelStore создает новый MySQLSagaReadModelStore.
+// DSN указывается в формате драйвера go-sql-driver/mysql (префикс mysql:// допускается).
+func NewMySQLSagaReadModelStore(dsn string) (*MySQLSagaReadModelStore, error) {
+	db, err := sql.Open("mysql", eventsourcing.MySQLDSN(dsn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MySQL (is driver \"mysql\" imported?): %w", err)
+	}
+	if err := db.PingContext(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+
+	return NewMySQLSagaReadModelStoreWithDB(db)
+}
+
+// NewMySQLSagaReadModelStoreWithDB создает MySQLSagaReadModelStore поверх существующего пула
+// соединений (открытого с parseTime=true) и создает таблицы, если их нет
+func NewMySQLSagaReadModelStoreWithDB(db *sql.DB) (*MySQLSagaReadModelStore, error) {
+	store := &MySQLSagaReadModelStore{db: db}
+	if err := store.ensureTable(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ensure table: %w", err)
+	}
+	return store, nil
+}
+
+// ensureTable создает таблицы read model миграцией 003
+func (s *MySQLSagaReadModelStore) ensureTable(ctx context.Context) error {
+	return ensureReadModelSchema(ctx, s.db, "mysql")
+}
+
+func (s *MySQLSagaReadModelStore) GetSagaStatus(ctx context.Context, sagaID string) (*SagaStatusResponse, error) {
+	query := `
+		SELECT saga_id, definition_name, status, COALESCE(current_step, ''), COALESCE(total_steps, 0),
+		       COALESCE(completed_steps, 0), COALESCE(failed_steps, 0), started_at, completed_at, duration_ms,
+		       COALESCE(correlation_id, ''), context, last_error, COALESCE(retry_count, 0), sla_deadline, sla_breached, last_failure,
+		       last_heartbeat_at, step_progress
+		FROM saga_read_models
+		WHERE saga_id = ?
+	`
+
+	var model SagaReadModel
+	var durationMs *int64
+	var contextJSON, lastFailure []byte
+	err := s.db.QueryRowContext(ctx, query, sagaID).Scan(
+		&model.SagaID,
+		&model.DefinitionName,
+		&model.Status,
+		&model.CurrentStep,
+		&model.TotalSteps,
+		&model.CompletedSteps,
+		&model.FailedSteps,
+		&model.StartedAt,
+		&model.CompletedAt,
+		&durationMs,
+		&model.CorrelationID,
+		&contextJSON,
+		&model.LastError,
+		&model.RetryCount,
+		&model.SLADeadline,
+		&model.SLABreached,
+		&lastFailure,
+		&model.LastHeartbeatAt,
+		&model.StepProgress,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga status: %w", err)
+	}
+	model.LastFailure = failureFromValue(lastFailure)
+	if len(contextJSON) > 0 {
+		if err := json.Unmarshal(contextJSON, &model.Context); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal context: %w", err)
+		}
+	}
+
+	if durationMs != nil {
+		duration := time.Duration(*durationMs) * time.Millisecond
+		model.Duration = &duration
+	}
+
+	response := &SagaStatusResponse{
+		SagaID:         model.SagaID,
+		DefinitionName: model.DefinitionName,
+		Status:         model.Status,
+		CurrentStep:    model.CurrentStep,
+		TotalSteps:     model.TotalSteps,
+		CompletedSteps: model.CompletedSteps,
+		FailedSteps:    model.FailedSteps,
+		StartedAt:      model.StartedAt,
+		CompletedAt:    model.CompletedAt,
+		Duration:       model.Duration,
+		CorrelationID:  model.CorrelationID,
+		Context:        model.Context,
+		LastError:      model.LastError,
+		LastFailure:    model.LastFailure,
+		RetryCount:     model.RetryCount,
+	}
+	response.applySLA(model.SLADeadline, model.SLABreached, time.Now())
+	response.LastHeartbeatAt = model.LastHeartbeatAt
+	response.StepProgress = model.StepProgress
+
+	return response, nil
+}
+
+func (s *MySQLSagaReadModelStore) UpsertSagaReadModel(ctx context.Context, model *SagaReadModel) error {
+	return s.UpsertSagaReadModels(ctx, []*SagaReadModel{model})
+}
+
+func (s *MySQLSagaReadModelStore) UpsertSagaStepReadModel(ctx context.Context, step *SagaStepReadModel) error {
+	query := mysqlUpsertQuery("saga_step_read_models", mysqlSagaStepReadModelColumns, mysqlSagaStepReadModelKeyColumns, 1, false)
+	_, err := s.db.ExecContext(ctx, query, mysqlSagaStepReadModelArgs(step)...)
+	return err
+}
+
+// UpsertSagaReadModels сохраняет пакет read models одним запросом (реализация SagaReadModelBatchStore).
+// Строка не обновляется, если в таблице уже более новое состояние.
+func (s *MySQLSagaReadModelStore) UpsertSagaReadModels(ctx context.Context, models []*SagaReadModel) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(models)*len(mysqlSagaReadModelColumns))
+	for _, model := range models {
+		modelArgs, err := mysqlSagaReadModelArgs(model)
+		if err != nil {
+			return err
+		}
+		args = append(args, modelArgs...)
+	}
+
+	query := mysqlUpsertQuery("saga_read_models", mysqlSagaReadModelColumns, []string{"saga_id"}, len(models), true)
+	_, err := s.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// UpsertSagaStepReadModels сохраняет пакет read models шагов одним запросом
+func (s *MySQLSagaReadModelStore) UpsertSagaStepReadModels(ctx context.Context, steps []*SagaStepReadModel) error {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(steps)*len(mysqlSagaStepReadModelColumns))
+	for _, step := range steps {
+		args = append(args, mysqlSagaStepReadModelArgs(step)...)
+	}
+
+	query := mysqlUpsertQuery("saga_step_read_models", mysqlSagaStepReadModelColumns, mysqlSagaStepReadModelKeyColumns, len(steps), true)
+	_, err := s.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// mysqlSagaFilterWhere возвращает условие WHERE и аргументы фильтра саг
+func mysqlSagaFilterWhere(status *SagaStatus, definitionName, correlationID *string, startedAfter, startedBefore *time.Time) (string, []interface{}) {
+	where := " WHERE 1=1"
+	var args []interface{}
+	if status != nil {
+		where += " AND status = ?"
+		args = append(args, string(*status))
+	}
+	if definitionName != nil {
+		where += " AND definition_name = ?"
+		args = append(args, *definitionName)
+	}
+	if correlationID != nil {
+		where += " AND correlation_id = ?"
+		args = append(args, *correlationID)
+	}
+	if startedAfter != nil {
+		where += " AND started_at >= ?"
+		args = append(args, startedAfter.UTC())
+	}
+	if startedBefore != nil {
+		where += " AND started_at <= ?"
+		args = append(args, startedBefore.UTC())
+	}
+	return where, args
+}
+
+func (s *MySQLSagaReadModelStore) ListSagas(ctx context.Context, filter SagaFilter) (*SagaListResponse, error) {
+	where, args := mysqlSagaFilterWhere(filter.Status, filter.DefinitionName, filter.CorrelationID, filter.StartedAfter, filter.StartedBefore)
+
+	query := `SELECT saga_id, definition_name, status, COALESCE(current_step, ''), started_at, completed_at, COALESCE(correlation_id, '')
+	          FROM saga_read_models` + where + " ORDER BY started_at DESC"
+	pageArgs := append([]interface{}{}, args...)
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		pageArgs = append(pageArgs, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			pageArgs = append(pageArgs, filter.Offset)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, pageArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []SagaSummary
+	for rows.Next() {
+		var summary SagaSummary
+		if err := rows.Scan(
+			&summary.SagaID,
+			&summary.DefinitionName,
+			&summary.Status,
+			&summary.CurrentStep,
+			&summary.StartedAt,
+			&summary.CompletedAt,
+			&summary.CorrelationID,
+		); err != nil {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sagas: %w", err)
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM saga_read_models"+where, args...).Scan(&total); err != nil {
+		total = len(summaries)
+	}
+
+	return &SagaListResponse{
+		Sagas:  summaries,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+func (s *MySQLSagaReadModelStore) GetMetrics(ctx context.Context, filter MetricsFilter) (*SagaMetricsResponse, error) {
+	where, args := mysqlSagaFilterWhere(nil, filter.DefinitionName, nil, filter.StartedAfter, filter.StartedBefore)
+
+	query := `SELECT
+		COUNT(*),
+		COALESCE(SUM(status = 'completed'), 0),
+		COALESCE(SUM(status = 'failed'), 0),
+		COALESCE(SUM(status = 'compensated'), 0),
+		AVG(duration_ms)
+		FROM saga_read_models` + where
+
+	var total, completed, failed, compensated int
+	var avgDurationMs *float64
+
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(
+		&total,
+		&completed,
+		&failed,
+		&compensated,
+		&avgDurationMs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics: %w", err)
+	}
+
+	var successRate float64
+	if total > 0 {
+		successRate = float64(completed) / float64(total) * 100
+	}
+
+	var avgDuration time.Duration
+	if avgDurationMs != nil {
+		avgDuration = time.Duration(*avgDurationMs) * time.Millisecond
+	}
+
+	response := &SagaMetricsResponse{
+		TotalSagas:       total,
+		CompletedSagas:   completed,
+		FailedSagas:      failed,
+		CompensatedSagas: compensated,
+		SuccessRate:      successRate,
+		AvgDuration:      avgDuration,
+		Throughput:       0,
+	}
+
+	// Причины ошибок неуспешных саг по категориям и кодам
+	failuresQuery := `SELECT COALESCE(failure_category, ''), COALESCE(last_failure->>'$.code', ''), COUNT(*)
+		FROM saga_read_models` + where + `
+		AND last_failure IS NOT NULL
+		AND status IN ('failed', 'compensated', 'compensation_stuck')
+		GROUP BY 1, 2`
+	rows, err := s.db.QueryContext(ctx, failuresQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failure metrics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category, code string
+		var count int
+		if err := rows.Scan(&category, &code, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan failure metrics: %w", err)
+		}
+		response.addFailureCount(FailureCategory(category), code, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get failure metrics: %w", err)
+	}
+
+	// MySQL не поддерживает percentile_disc, перцентили считаются по выборке длительностей
+	stepsQuery := `SELECT step_name, status, duration_ms
+		FROM saga_step_read_models
+		WHERE status IN ('completed', 'failed')
+		AND saga_id IN (SELECT saga_id FROM saga_read_models` + where + `)`
+	stepRows, err := s.db.QueryContext(ctx, stepsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step metrics: %w", err)
+	}
+	defer stepRows.Close()
+
+	steps := newStepMetricsCollector()
+	for stepRows.Next() {
+		var stepName, status string
+		var durationMs sql.NullInt64
+		if err := stepRows.Scan(&stepName, &status, &durationMs); err != nil {
+			return nil, fmt.Errorf("failed to scan step metrics: %w", err)
+		}
+		var duration *time.Duration
+		if durationMs.Valid {
+			d := time.Duration(durationMs.Int64) * time.Millisecond
+			duration = &d
+		}
+		steps.add(stepName, status, duration)
+	}
+	if err := stepRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get step metrics: %w", err)
+	}
+	response.Steps = steps.result()
+
+	return response, nil
+}
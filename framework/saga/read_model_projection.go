@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/akriventsev/potter/framework/eventsourcing"
@@ -13,6 +14,9 @@ import (
 // SagaReadModelProjection проекция для обновления read model из событий саги
 type SagaReadModelProjection struct {
 	store SagaReadModelStore
+
+	batchMu sync.Mutex
+	batch   *sagaReadModelBatch
 }
 
 // NewSagaReadModelProjection создает новую проекцию для read model саг
@@ -38,35 +42,7 @@ func (p *SagaReadModelProjection) HandleEvent(ctx context.Context, event eventso
 		return nil // Игнорируем события не саг
 	}
 	
-	// Создаем eventData из метаданных или EventData
-	eventData := make(map[string]interface{})
-	if event.Metadata != nil {
-		for k, v := range event.Metadata {
-			eventData[k] = v
-		}
-	}
-	eventData["saga_id"] = sagaID
-	
-	// Если EventData доступно, пытаемся извлечь данные из него
-	if event.EventData != nil {
-		eventJSON, err := json.Marshal(event.EventData)
-		if err == nil {
-			var dataFromEvent map[string]interface{}
-			if err := json.Unmarshal(eventJSON, &dataFromEvent); err == nil {
-				// Объединяем данные из EventData с метаданными
-				for k, v := range dataFromEvent {
-					if _, exists := eventData[k]; !exists {
-						eventData[k] = v
-					}
-				}
-			}
-		}
-	}
-
-	// Добавляем timestamp из StoredEvent
-	if !event.OccurredAt.IsZero() {
-		eventData["timestamp"] = event.OccurredAt.Format(time.RFC3339)
-	}
+	eventData := storedSagaEventData(event)
 
 	// Обрабатываем события по типу
 	switch event.EventType {
@@ -84,28 +60,80 @@ func (p *SagaReadModelProjection) HandleEvent(ctx context.Context, event eventso
 		}
 	case "StepStarted":
 		return p.handleStepStartedFromMap(ctx, eventData)
+	case "StepHeartbeat":
+		return p.handleStepHeartbeatFromMap(ctx, eventData)
 	case "StepCompleted":
 		return p.handleStepCompletedFromMap(ctx, eventData)
 	case "StepFailed":
 		return p.handleStepFailedFromMap(ctx, eventData)
 	case "StepCompensated":
 		return p.handleStepCompensatedFromMap(ctx, eventData)
+	case "StepCancelled":
+		return p.handleStepCancelledFromMap(ctx, eventData)
 	case "SagaCompleted":
 		return p.handleSagaCompletedFromMap(ctx, eventData)
 	case "SagaFailed":
 		return p.handleSagaFailedFromMap(ctx, eventData)
 	case "SagaCompensated":
 		return p.handleSagaCompensatedFromMap(ctx, eventData)
+	case "SagaSLABreached":
+		return p.handleSagaSLABreachedFromMap(ctx, eventData)
+	case "SagaCompensationStuck":
+		return p.handleSagaCompensationStuckFromMap(ctx, eventData)
+	case "ApprovalRequested":
+		return p.handleApprovalRequestedFromMap(ctx, eventData)
+	case "ApprovalDecided":
+		return p.handleApprovalDecidedFromMap(ctx, eventData)
+	case "MessageAwaited":
+		return p.handleMessageAwaitedFromMap(ctx, eventData)
+	case "SagaMessageReceived":
+		return p.handleSagaMessageReceivedFromMap(ctx, eventData)
 	}
 
 	return nil // Игнорируем неизвестные типы событий
 }
 
+// storedSagaEventData собирает данные события саги из StoredEvent: метаданные, поля события
+// (без перезаписи метаданных), saga_id и timestamp
+func storedSagaEventData(event eventsourcing.StoredEvent) map[string]interface{} {
+	// Создаем eventData из метаданных или EventData
+	eventData := make(map[string]interface{})
+	if event.Metadata != nil {
+		for k, v := range event.Metadata {
+			eventData[k] = v
+		}
+	}
+	eventData["saga_id"] = event.AggregateID
+	
+	// Если EventData доступно, пытаемся извлечь данные из него
+	if event.EventData != nil {
+		eventJSON, err := json.Marshal(event.EventData)
+		if err == nil {
+			var dataFromEvent map[string]interface{}
+			if err := json.Unmarshal(eventJSON, &dataFromEvent); err == nil {
+				// Объединяем данные из EventData с метаданными
+				for k, v := range dataFromEvent {
+					if _, exists := eventData[k]; !exists {
+						eventData[k] = v
+					}
+				}
+			}
+		}
+	}
+
+	// Добавляем timestamp из StoredEvent
+	if !event.OccurredAt.IsZero() {
+		eventData["timestamp"] = event.OccurredAt.Format(time.RFC3339)
+	}
+	return eventData
+}
+
 // isSagaEventType проверяет, является ли тип события событием саги
 func (p *SagaReadModelProjection) isSagaEventType(eventType string) bool {
 	sagaEventTypes := []string{
-		"SagaStarted", "SagaStateChanged", "SagaCompleted", "SagaFailed", "SagaCompensated",
-		"StepStarted", "StepCompleted", "StepFailed", "StepCompensated",
+		"SagaStarted", "SagaStateChanged", "SagaCompleted", "SagaFailed", "SagaCompensated", "SagaSLABreached", "SagaCompensationStuck",
+		"StepStarted", "StepHeartbeat", "StepCompleted", "StepFailed", "StepCompensated", "StepCancelled",
+		"ApprovalRequested", "ApprovalDecided", "MessageAwaited", "SagaMessageReceived",
 	}
 	for _, t := range sagaEventTypes {
 		if eventType == t {
@@ -117,6 +145,9 @@ func (p *SagaReadModelProjection) isSagaEventType(eventType string) bool {
 
 // hasReadModel проверяет, существует ли read model для саги
 func (p *SagaReadModelProjection) hasReadModel(ctx context.Context, sagaID string) bool {
+	if _, ok := p.pendingReadModel(sagaID); ok {
+		return true
+	}
 	_, err := p.store.GetSagaStatus(ctx, sagaID)
 	return err == nil
 }
@@ -143,6 +174,9 @@ func (p *SagaReadModelProjection) handleSagaStateChangedFromMap(ctx context.Cont
 
 // Reset сбрасывает состояние проекции (удаляет все read models)
 func (p *SagaReadModelProjection) Reset(ctx context.Context) error {
+	// Несохраненные изменения больше не актуальны
+	p.discardBatch()
+
 	// Для reset нужно удалить все read models
 	// Это зависит от реализации store, пока возвращаем nil
 	// В реальной реализации можно добавить метод ClearAll в SagaReadModelStore
@@ -166,6 +200,96 @@ func (p *SagaReadModelProjection) HandleSagaStarted(ctx context.Context, event *
 	model.Status = SagaStatusRunning
 	model.StartedAt = event.Timestamp
 	model.CorrelationID = event.CorrelationID
+	model.SLADeadline = event.SLADeadline
+	model.UpdatedAt = time.Now()
+
+	return p.saveReadModel(ctx, model)
+}
+
+// HandleSagaSLABreached обрабатывает событие превышения SLA саги
+func (p *SagaReadModelProjection) HandleSagaSLABreached(ctx context.Context, event *SagaSLABreachedEvent) error {
+	if p.store == nil {
+		return nil
+	}
+
+	model, err := p.getOrCreateReadModel(ctx, event.SagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get read model: %w", err)
+	}
+
+	deadline := event.Deadline
+	model.SLADeadline = &deadline
+	model.SLABreached = true
+	model.UpdatedAt = time.Now()
+
+	return p.saveReadModel(ctx, model)
+}
+
+// HandleSagaCompensationStuck обрабатывает событие остановки компенсации саги
+func (p *SagaReadModelProjection) HandleSagaCompensationStuck(ctx context.Context, event *SagaCompensationStuckEvent) error {
+	if p.store == nil {
+		return nil
+	}
+
+	model, err := p.getOrCreateReadModel(ctx, event.SagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get read model: %w", err)
+	}
+
+	model.Status = SagaStatusCompensationStuck
+	model.CurrentStep = event.StepName
+	errorMsg := event.Error
+	model.LastError = &errorMsg
+	model.LastFailure = event.Failure
+	model.CompletedAt = nil
+	model.UpdatedAt = time.Now()
+
+	return p.saveReadModel(ctx, model)
+}
+
+// HandleApprovalRequested обрабатывает событие остановки саги на шаге подтверждения
+func (p *SagaReadModelProjection) HandleApprovalRequested(ctx context.Context, event *ApprovalRequestedEvent) error {
+	if p.store == nil {
+		return nil
+	}
+	return p.setApprovalStatus(ctx, event.SagaID, event.StepName, SagaStatusWaitingApproval)
+}
+
+// HandleApprovalDecided обрабатывает событие решения по шагу подтверждения
+func (p *SagaReadModelProjection) HandleApprovalDecided(ctx context.Context, event *ApprovalDecidedEvent) error {
+	if p.store == nil {
+		return nil
+	}
+	return p.setApprovalStatus(ctx, event.SagaID, event.StepName, SagaStatusRunning)
+}
+
+// HandleMessageAwaited обрабатывает событие остановки саги на шаге ожидания сообщения
+func (p *SagaReadModelProjection) HandleMessageAwaited(ctx context.Context, event *MessageAwaitedEvent) error {
+	if p.store == nil {
+		return nil
+	}
+	return p.setApprovalStatus(ctx, event.SagaID, event.StepName, SagaStatusWaitingMessage)
+}
+
+// HandleSagaMessageReceived обрабатывает событие доставки сообщения: сага, ожидавшая его, продолжается
+func (p *SagaReadModelProjection) HandleSagaMessageReceived(ctx context.Context, event *SagaMessageReceivedEvent) error {
+	if p.store == nil || event.StepName == "" {
+		return nil
+	}
+	return p.setApprovalStatus(ctx, event.SagaID, event.StepName, SagaStatusRunning)
+}
+
+// setApprovalStatus обновляет статус и текущий шаг саги при запросе и принятии решения
+func (p *SagaReadModelProjection) setApprovalStatus(ctx context.Context, sagaID, stepName string, status SagaStatus) error {
+	model, err := p.getOrCreateReadModel(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get read model: %w", err)
+	}
+
+	model.Status = status
+	if stepName != "" {
+		model.CurrentStep = stepName
+	}
 	model.UpdatedAt = time.Now()
 
 	return p.saveReadModel(ctx, model)
@@ -183,11 +307,38 @@ func (p *SagaReadModelProjection) HandleStepStarted(ctx context.Context, event *
 	}
 
 	model.CurrentStep = event.StepName
+	model.LastHeartbeatAt = nil
+	model.StepProgress = nil
 	model.UpdatedAt = time.Now()
 
 	return p.saveReadModel(ctx, model)
 }
 
+// HandleStepHeartbeat обрабатывает heartbeat долгого шага
+func (p *SagaReadModelProjection) HandleStepHeartbeat(ctx context.Context, event *StepHeartbeatEvent) error {
+	if p.store == nil {
+		return nil
+	}
+
+	model, err := p.getOrCreateReadModel(ctx, event.SagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get read model: %w", err)
+	}
+
+	p.applyStepHeartbeat(model, event.StepName, event.Timestamp, event.Progress)
+	return p.saveReadModel(ctx, model)
+}
+
+// applyStepHeartbeat записывает heartbeat шага в read model
+func (p *SagaReadModelProjection) applyStepHeartbeat(model *SagaReadModel, stepName string, at time.Time, progress float64) {
+	if stepName != "" {
+		model.CurrentStep = stepName
+	}
+	model.LastHeartbeatAt = &at
+	model.StepProgress = &progress
+	model.UpdatedAt = time.Now()
+}
+
 // HandleStepCompleted обрабатывает событие успешного завершения шага
 func (p *SagaReadModelProjection) HandleStepCompleted(ctx context.Context, event *StepCompletedEvent) error {
 	if p.store == nil {
@@ -218,12 +369,48 @@ func (p *SagaReadModelProjection) HandleStepFailed(ctx context.Context, event *S
 
 	model.FailedSteps++
 	model.LastError = &event.Error
+	model.LastFailure = event.Failure
 	model.RetryCount = event.RetryAttempt
 	model.UpdatedAt = time.Now()
 
 	return p.saveReadModel(ctx, model)
 }
 
+// HandleStepCancelled обрабатывает событие прерывания шага отменой саги
+func (p *SagaReadModelProjection) HandleStepCancelled(ctx context.Context, event *StepCancelledEvent) error {
+	if p.store == nil {
+		return nil
+	}
+	return p.setStepCancelled(ctx, event.SagaID, event.StepName, event.Reason, event.Timestamp)
+}
+
+// setStepCancelled записывает шаг как отмененный; отмена не считается ошибкой шага
+func (p *SagaReadModelProjection) setStepCancelled(ctx context.Context, sagaID, stepName, reason string, cancelledAt time.Time) error {
+	model, err := p.getOrCreateReadModel(ctx, sagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get read model: %w", err)
+	}
+	if stepName != "" {
+		model.CurrentStep = stepName
+	}
+	model.UpdatedAt = time.Now()
+
+	stepModel := &SagaStepReadModel{
+		SagaID:      sagaID,
+		StepName:    stepName,
+		Status:      string(StepStatusCancelled),
+		StartedAt:   cancelledAt,
+		CompletedAt: &cancelledAt,
+		Error:       &reason,
+		UpdatedAt:   time.Now(),
+	}
+	if err := p.saveStepReadModel(ctx, stepModel); err != nil {
+		return fmt.Errorf("failed to save step read model: %w", err)
+	}
+
+	return p.saveReadModel(ctx, model)
+}
+
 // HandleSagaCompleted обрабатывает событие успешного завершения саги
 func (p *SagaReadModelProjection) HandleSagaCompleted(ctx context.Context, event *SagaCompletedEvent) error {
 	if p.store == nil {
@@ -256,21 +443,42 @@ func (p *SagaReadModelProjection) HandleSagaFailed(ctx context.Context, event *S
 		return fmt.Errorf("failed to get read model: %w", err)
 	}
 
-	model.Status = SagaStatusFailed
+	// Сага с остановленной компенсацией не завершена: она ожидает ручного возобновления
 	now := time.Now()
-	model.CompletedAt = &now
-	if model.StartedAt != (time.Time{}) {
-		duration := now.Sub(model.StartedAt)
-		model.Duration = &duration
+	if model.Status != SagaStatusCompensationStuck {
+		model.Status = SagaStatusFailed
+		model.CompletedAt = &now
+		if model.StartedAt != (time.Time{}) {
+			duration := now.Sub(model.StartedAt)
+			model.Duration = &duration
+		}
 	}
 	model.LastError = &event.Error
+	if event.Failure != nil {
+		model.LastFailure = event.Failure
+	}
 	model.UpdatedAt = now
 
 	return p.saveReadModel(ctx, model)
 }
 
+// HandleSagaCompensated обрабатывает событие завершения компенсации саги
+// (в том числе после отмены выполняющейся саги)
+func (p *SagaReadModelProjection) HandleSagaCompensated(ctx context.Context, event *SagaCompensatedEvent) error {
+	if p.store == nil {
+		return nil
+	}
+	return p.handleSagaCompensatedFromMap(ctx, map[string]interface{}{"saga_id": event.SagaID})
+}
+
 // getOrCreateReadModel получает или создает read model
 func (p *SagaReadModelProjection) getOrCreateReadModel(ctx context.Context, sagaID string) (*SagaReadModel, error) {
+	// Несохраненное состояние из буфера новее состояния в store
+	if model, ok := p.pendingReadModel(sagaID); ok {
+		model.UpdatedAt = time.Now()
+		return model, nil
+	}
+
 	// Пытаемся получить существующий read model
 	status, err := p.store.GetSagaStatus(ctx, sagaID)
 	if err == nil && status != nil {
@@ -289,7 +497,12 @@ func (p *SagaReadModelProjection) getOrCreateReadModel(ctx context.Context, saga
 			CorrelationID: status.CorrelationID,
 			Context:       status.Context,
 			LastError:     status.LastError,
+			LastFailure:   status.LastFailure,
 			RetryCount:    status.RetryCount,
+			SLADeadline:   status.SLADeadline,
+			SLABreached:   status.SLABreached,
+			LastHeartbeatAt: status.LastHeartbeatAt,
+			StepProgress:  status.StepProgress,
 			UpdatedAt:     time.Now(),
 		}
 		return model, nil
@@ -311,9 +524,20 @@ func (p *SagaReadModelProjection) getOrCreateReadModel(ctx context.Context, saga
 
 // saveReadModel сохраняет read model
 func (p *SagaReadModelProjection) saveReadModel(ctx context.Context, model *SagaReadModel) error {
+	if buffered, err := p.bufferReadModel(ctx, model); buffered {
+		return err
+	}
 	return p.store.UpsertSagaReadModel(ctx, model)
 }
 
+// saveStepReadModel сохраняет read model шага
+func (p *SagaReadModelProjection) saveStepReadModel(ctx context.Context, step *SagaStepReadModel) error {
+	if buffered, err := p.bufferStepReadModel(ctx, step); buffered {
+		return err
+	}
+	return p.store.UpsertSagaStepReadModel(ctx, step)
+}
+
 // Вспомогательные методы для обработки событий из map (для HandleEvent)
 func (p *SagaReadModelProjection) handleSagaStartedFromMap(ctx context.Context, eventData map[string]interface{}) error {
 	sagaID, _ := eventData["saga_id"].(string)
@@ -339,6 +563,9 @@ func (p *SagaReadModelProjection) handleSagaStartedFromMap(ctx context.Context,
 		Context:       make(map[string]interface{}),
 		UpdatedAt:     time.Now(),
 	}
+	if deadline, ok := parseMapTime(eventData["SLADeadline"]); ok {
+		model.SLADeadline = &deadline
+	}
 
 	return p.saveReadModel(ctx, model)
 }
@@ -363,6 +590,8 @@ func (p *SagaReadModelProjection) handleStepStartedFromMap(ctx context.Context,
 	}
 
 	model.CurrentStep = stepName
+	model.LastHeartbeatAt = nil
+	model.StepProgress = nil
 	model.UpdatedAt = time.Now()
 
 	// Сохраняем шаг в истории
@@ -373,7 +602,7 @@ func (p *SagaReadModelProjection) handleStepStartedFromMap(ctx context.Context,
 		StartedAt: timestamp,
 		UpdatedAt: time.Now(),
 	}
-	if err := p.store.UpsertSagaStepReadModel(ctx, stepModel); err != nil {
+	if err := p.saveStepReadModel(ctx, stepModel); err != nil {
 		return fmt.Errorf("failed to save step read model: %w", err)
 	}
 
@@ -419,7 +648,7 @@ func (p *SagaReadModelProjection) handleStepCompletedFromMap(ctx context.Context
 		Duration:    &duration,
 		UpdatedAt:   time.Now(),
 	}
-	if err := p.store.UpsertSagaStepReadModel(ctx, stepModel); err != nil {
+	if err := p.saveStepReadModel(ctx, stepModel); err != nil {
 		return fmt.Errorf("failed to save step read model: %w", err)
 	}
 
@@ -450,8 +679,11 @@ func (p *SagaReadModelProjection) handleStepFailedFromMap(ctx context.Context, e
 		return err
 	}
 
+	failure := eventFailure(eventData)
+
 	model.FailedSteps++
 	model.LastError = &errorMsg
+	model.LastFailure = failure
 	model.RetryCount = retryAttempt
 	model.UpdatedAt = time.Now()
 
@@ -464,9 +696,10 @@ func (p *SagaReadModelProjection) handleStepFailedFromMap(ctx context.Context, e
 		CompletedAt: &timestamp,
 		RetryAttempt: retryAttempt,
 		Error:       &errorMsg,
+		Failure:     failure,
 		UpdatedAt:   time.Now(),
 	}
-	if err := p.store.UpsertSagaStepReadModel(ctx, stepModel); err != nil {
+	if err := p.saveStepReadModel(ctx, stepModel); err != nil {
 		return fmt.Errorf("failed to save step read model: %w", err)
 	}
 
@@ -486,6 +719,24 @@ func (p *SagaReadModelProjection) handleStepCompensatedFromMap(ctx context.Conte
 	return p.saveReadModel(ctx, model)
 }
 
+func (p *SagaReadModelProjection) handleStepCancelledFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+	stepName, _ := eventData["step_name"].(string)
+	reason, _ := eventData["reason"].(string)
+
+	var timestamp time.Time
+	if ts, ok := eventData["timestamp"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			timestamp = t
+		}
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	return p.setStepCancelled(ctx, sagaID, stepName, reason, timestamp)
+}
+
 func (p *SagaReadModelProjection) handleSagaCompletedFromMap(ctx context.Context, eventData map[string]interface{}) error {
 	sagaID, _ := eventData["saga_id"].(string)
 	
@@ -519,14 +770,19 @@ func (p *SagaReadModelProjection) handleSagaFailedFromMap(ctx context.Context, e
 		return err
 	}
 
-	model.Status = SagaStatusFailed
 	now := time.Now()
-	model.CompletedAt = &now
-	if model.StartedAt != (time.Time{}) {
-		duration := now.Sub(model.StartedAt)
-		model.Duration = &duration
+	if model.Status != SagaStatusCompensationStuck {
+		model.Status = SagaStatusFailed
+		model.CompletedAt = &now
+		if model.StartedAt != (time.Time{}) {
+			duration := now.Sub(model.StartedAt)
+			model.Duration = &duration
+		}
 	}
 	model.LastError = &errorMsg
+	if failure := eventFailure(eventData); failure != nil {
+		model.LastFailure = failure
+	}
 	model.UpdatedAt = now
 
 	return p.saveReadModel(ctx, model)
@@ -552,3 +808,95 @@ func (p *SagaReadModelProjection) handleSagaCompensatedFromMap(ctx context.Conte
 	return p.saveReadModel(ctx, model)
 }
 
+func (p *SagaReadModelProjection) handleSagaCompensationStuckFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+	errorMsg, _ := eventData["error"].(string)
+
+	model, err := p.getOrCreateReadModel(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	model.Status = SagaStatusCompensationStuck
+	if stepName, ok := eventData["step_name"].(string); ok && stepName != "" {
+		model.CurrentStep = stepName
+	}
+	model.LastError = &errorMsg
+	model.LastFailure = eventFailure(eventData)
+	model.CompletedAt = nil
+	model.UpdatedAt = time.Now()
+
+	return p.saveReadModel(ctx, model)
+}
+
+func (p *SagaReadModelProjection) handleApprovalRequestedFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+	stepName, _ := eventData["step_name"].(string)
+	return p.setApprovalStatus(ctx, sagaID, stepName, SagaStatusWaitingApproval)
+}
+
+func (p *SagaReadModelProjection) handleApprovalDecidedFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+	stepName, _ := eventData["step_name"].(string)
+	return p.setApprovalStatus(ctx, sagaID, stepName, SagaStatusRunning)
+}
+
+func (p *SagaReadModelProjection) handleMessageAwaitedFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+	stepName, _ := eventData["step_name"].(string)
+	return p.setApprovalStatus(ctx, sagaID, stepName, SagaStatusWaitingMessage)
+}
+
+func (p *SagaReadModelProjection) handleSagaMessageReceivedFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+	stepName, _ := eventData["step_name"].(string)
+	if stepName == "" {
+		return nil
+	}
+	return p.setApprovalStatus(ctx, sagaID, stepName, SagaStatusRunning)
+}
+
+func (p *SagaReadModelProjection) handleStepHeartbeatFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+	stepName, _ := eventData["StepName"].(string)
+	progress, _ := eventData["Progress"].(float64)
+	at, ok := parseMapTime(eventData["Timestamp"])
+	if !ok {
+		at = time.Now()
+	}
+
+	model, err := p.getOrCreateReadModel(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	p.applyStepHeartbeat(model, stepName, at, progress)
+	return p.saveReadModel(ctx, model)
+}
+
+func (p *SagaReadModelProjection) handleSagaSLABreachedFromMap(ctx context.Context, eventData map[string]interface{}) error {
+	sagaID, _ := eventData["saga_id"].(string)
+
+	model, err := p.getOrCreateReadModel(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := parseMapTime(eventData["Deadline"]); ok {
+		model.SLADeadline = &deadline
+	}
+	model.SLABreached = true
+	model.UpdatedAt = time.Now()
+
+	return p.saveReadModel(ctx, model)
+}
+
+// parseMapTime разбирает время из JSON-представления события
+func parseMapTime(value interface{}) (time.Time, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, text)
+	return t, err == nil
+}
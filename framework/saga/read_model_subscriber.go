@@ -32,14 +32,32 @@ func (s *SagaReadModelSubscriber) Handle(ctx context.Context, event events.Event
 		return s.projection.HandleSagaStarted(ctx, e)
 	case *StepStartedEvent:
 		return s.projection.HandleStepStarted(ctx, e)
+	case *StepHeartbeatEvent:
+		return s.projection.HandleStepHeartbeat(ctx, e)
 	case *StepCompletedEvent:
 		return s.projection.HandleStepCompleted(ctx, e)
 	case *StepFailedEvent:
 		return s.projection.HandleStepFailed(ctx, e)
+	case *StepCancelledEvent:
+		return s.projection.HandleStepCancelled(ctx, e)
 	case *SagaCompletedEvent:
 		return s.projection.HandleSagaCompleted(ctx, e)
 	case *SagaFailedEvent:
 		return s.projection.HandleSagaFailed(ctx, e)
+	case *SagaCompensatedEvent:
+		return s.projection.HandleSagaCompensated(ctx, e)
+	case *SagaSLABreachedEvent:
+		return s.projection.HandleSagaSLABreached(ctx, e)
+	case *SagaCompensationStuckEvent:
+		return s.projection.HandleSagaCompensationStuck(ctx, e)
+	case *ApprovalRequestedEvent:
+		return s.projection.HandleApprovalRequested(ctx, e)
+	case *ApprovalDecidedEvent:
+		return s.projection.HandleApprovalDecided(ctx, e)
+	case *MessageAwaitedEvent:
+		return s.projection.HandleMessageAwaited(ctx, e)
+	case *SagaMessageReceivedEvent:
+		return s.projection.HandleSagaMessageReceived(ctx, e)
 	default:
 		// Игнорируем неизвестные события
 		return nil
@@ -51,10 +69,19 @@ func (s *SagaReadModelSubscriber) EventTypes() []string {
 	return []string{
 		"SagaStarted",
 		"StepStarted",
+		"StepHeartbeat",
 		"StepCompleted",
 		"StepFailed",
+		"StepCancelled",
 		"SagaCompleted",
 		"SagaFailed",
+		"SagaCompensated",
+		"SagaSLABreached",
+		"SagaCompensationStuck",
+		"ApprovalRequested",
+		"ApprovalDecided",
+		"MessageAwaited",
+		"SagaMessageReceived",
 	}
 }
 
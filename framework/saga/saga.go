@@ -3,13 +3,17 @@ package saga
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/events"
 	"github.com/akriventsev/potter/framework/fsm"
 	"github.com/akriventsev/potter/framework/invoke"
+	"github.com/akriventsev/potter/framework/metrics"
 
 	"github.com/google/uuid"
 )
@@ -24,6 +28,15 @@ const (
 	SagaStatusCompensating SagaStatus = "compensating"
 	SagaStatusCompensated  SagaStatus = "compensated"
 	SagaStatusFailed       SagaStatus = "failed"
+	// SagaStatusCompensationStuck компенсация шага не удалась после всех повторов;
+	// сага ожидает ручного возобновления (DefaultOrchestrator.ResumeCompensation)
+	SagaStatusCompensationStuck SagaStatus = "compensation_stuck"
+	// SagaStatusWaitingApproval сага остановлена на WaitForApprovalStep и ожидает
+	// решения (DefaultOrchestrator.Approve)
+	SagaStatusWaitingApproval SagaStatus = "waiting_approval"
+	// SagaStatusWaitingMessage сага остановлена на WaitForMessageStep и ожидает
+	// сообщения от другой саги (DefaultOrchestrator.SendMessage)
+	SagaStatusWaitingMessage SagaStatus = "waiting_message"
 )
 
 // Saga основной интерфейс саги
@@ -90,6 +103,17 @@ type SagaContext interface {
 	CorrelationID() string
 	// SetCorrelationID устанавливает correlation ID
 	SetCorrelationID(id string)
+	// IdempotencyKey возвращает ключ идемпотентности текущей попытки шага (sagaID, stepName, attempt)
+	IdempotencyKey() string
+	// SetIdempotencyKey устанавливает ключ идемпотентности текущей попытки шага
+	SetIdempotencyKey(key string)
+	// Update атомарно изменяет контекст: изменения, сделанные через view, применяются
+	// целиком после успешного завершения fn и отбрасываются, если fn вернула ошибку.
+	// Внутри ParallelStep запись одного ключа несколькими параллельными шагами
+	// завершается ошибкой ErrContextConflict.
+	Update(fn func(view SagaContextView) error) error
+	// ForStep возвращает пространство имен шага (см. StepContext)
+	ForStep(stepName string) StepContext
 	// ToMap преобразует контекст в map
 	ToMap() map[string]interface{}
 	// FromMap восстанавливает контекст из map
@@ -113,7 +137,13 @@ type SagaHistory struct {
 	StartedAt    time.Time
 	CompletedAt  *time.Time
 	Error        error
+	// Failure структурированная ошибка шага (категория, код, повторяемость, детали)
+	Failure      *SagaFailure
 	RetryAttempt int
+	// StackTrace stack trace, если шаг завершился паникой (см. core.ErrPanicRecovered)
+	StackTrace string
+	// Branch переход по результату шага ("approved -> ship") или причина пропуска шага
+	Branch string
 }
 
 // StepStatus статус выполнения шага
@@ -126,6 +156,15 @@ const (
 	StepStatusFailed       StepStatus = "failed"
 	StepStatusCompensating StepStatus = "compensating"
 	StepStatusCompensated  StepStatus = "compensated"
+	// StepStatusWaitingApproval шаг ожидает ручного подтверждения
+	StepStatusWaitingApproval StepStatus = "waiting_approval"
+	// StepStatusWaitingMessage шаг ожидает сообщения от другой саги
+	StepStatusWaitingMessage StepStatus = "waiting_message"
+	// StepStatusCancelled выполнение шага прервано отменой саги (Cancel, Compensate)
+	StepStatusCancelled StepStatus = "cancelled"
+	// StepStatusSkipped шаг пропущен: не выполнено условие шага или сага перешла
+	// через него по результату предыдущего шага (см. BranchingStep)
+	StepStatusSkipped StepStatus = "skipped"
 )
 
 // BaseSaga базовая реализация саги
@@ -142,6 +181,14 @@ type BaseSaga struct {
 	currentStep string
 	startedAt   time.Time
 	completedAt *time.Time
+	// compensationRetry политика повторов компенсации для шагов без собственной политики
+	compensationRetry *RetryPolicy
+	// recorder backend метрик шагов (задается оркестратором с WithMetrics)
+	recorder metrics.Recorder
+	// interceptors перехватчики шагов оркестратора (см. StepInterceptor)
+	interceptors []StepInterceptor
+	// escalationHandlers обработчики превышения дедлайнов шагов (см. DefaultOrchestrator.WithEscalationHandler)
+	escalationHandlers []EscalationHandler
 }
 
 // NewBaseSaga создает новую базовую сагу
@@ -247,9 +294,27 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 		}
 	}
 
-	// Выполняем шаги последовательно
+	return s.runSteps(ctx, 0)
+}
+
+// runSteps выполняет шаги последовательно, начиная с startIndex
+// (startIndex > 0 - продолжение после ручного подтверждения шага).
+// Шаги с невыполненным условием пропускаются, переходы по результату шага
+// пропускают шаги до целевого (см. BranchingStep).
+func (s *BaseSaga) runSteps(ctx context.Context, startIndex int) error {
 	steps := s.definition.Steps()
-	for i, step := range steps {
+	for i, next := startIndex, startIndex+1; i < len(steps); i, next = next, next+1 {
+		step := steps[i]
+		if cause := cancellationCause(ctx); cause != nil {
+			// Сага отменена после завершения предыдущего шага - следующий не запускается
+			return s.cancelAt(ctx, step, i, nil, cause)
+		}
+
+		if condition := stepCondition(step); condition != nil && !condition(s.context) {
+			s.skipStep(step, "condition not met")
+			continue
+		}
+
 		s.mu.Lock()
 		s.currentStep = step.Name()
 		s.mu.Unlock()
@@ -261,7 +326,7 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 				"step_name": step.Name(),
 				"saga_id":   s.id,
 			})
-			if err := s.fsm.Trigger(ctx, stepEvent); err != nil {
+			if err := s.fsm.Trigger(withSagaContext(ctx, s.context), stepEvent); err != nil {
 				// Если переход не удался, логируем, но продолжаем выполнение
 				// Это может быть нормально, если FSM уже в нужном состоянии
 			}
@@ -290,12 +355,15 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 		}
 
 		// Проверяем guard
-		if !step.CanExecute(ctx, s.context) {
+		// Шаг получает контекст с правилами доступа к пространствам имен шагов
+		stepSagaCtx := newStepScopedContext(s.context, step)
+		if !step.CanExecute(ctx, stepSagaCtx) {
 			s.mu.Lock()
 			s.status = SagaStatusFailed
 			s.mu.Unlock()
 			historyEntry.Status = StepStatusFailed
 			historyEntry.Error = fmt.Errorf("step %s guard check failed", step.Name())
+			historyEntry.Failure = guardFailure(historyEntry.Error)
 			now := time.Now()
 			historyEntry.CompletedAt = &now
 			s.updateHistory(historyEntry)
@@ -312,31 +380,27 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 			return fmt.Errorf("step %s guard check failed", step.Name())
 		}
 
-		// Выполняем шаг с retry
+		// Выполняем шаг с retry (с учетом настроек шагов по умолчанию из определения)
 		var stepErr error
-		retryPolicy := step.RetryPolicy()
+		settings := resolveStepSettings(s.definition, step)
+		retryPolicy := settings.RetryPolicy
 		if retryPolicy == nil {
 			retryPolicy = NoRetry()
 		}
 
+		// Дедлайн шага охватывает все попытки и задержки между ними
+		runCtx, stopDeadline := s.watchDeadline(ctx, step, settings)
+
 		for attempt := 0; attempt < retryPolicy.MaxAttempts; attempt++ {
 			historyEntry.RetryAttempt = attempt
+			stepErr = s.executeStepAttempt(runCtx, step, stepSagaCtx, settings, attempt)
 
-			// Создаем контекст с timeout если задан
-			stepCtx := ctx
-			var cancel context.CancelFunc
-			if timeout := step.Timeout(); timeout > 0 {
-				stepCtx, cancel = context.WithTimeout(ctx, timeout)
-			}
-
-			stepErr = step.Execute(stepCtx, s.context)
-
-			// Явно отменяем контекст после выполнения шага
-			if cancel != nil {
-				cancel()
+			if stepErr == nil || isStepWaiting(stepErr) {
+				break
 			}
 
-			if stepErr == nil {
+			// Отмененный шаг и шаг, превысивший дедлайн, не повторяются
+			if cancellationCause(ctx) != nil || deadlineCause(runCtx) != nil {
 				break
 			}
 
@@ -350,19 +414,63 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 				delay := retryPolicy.CalculateDelay(attempt)
 				select {
 				case <-time.After(delay):
-				case <-ctx.Done():
-					return ctx.Err()
+				case <-runCtx.Done():
+					if cause := cancellationCause(ctx); cause != nil {
+						stopDeadline()
+						return s.cancelAt(ctx, step, i, &historyEntry, cause)
+					}
+					if ctx.Err() != nil {
+						stopDeadline()
+						return ctx.Err()
+					}
 				}
+				if deadlineCause(runCtx) != nil {
+					break
+				}
+			}
+		}
+
+		if stepErr != nil && !isStepWaiting(stepErr) && cancellationCause(ctx) == nil && deadlineCause(runCtx) == nil {
+			// Прямое восстановление: шаг повторяется вместо компенсации (ForwardRecoveryStrategy)
+			var interrupted bool
+			stepErr, interrupted = s.recoverForward(runCtx, step, stepSagaCtx, settings, &historyEntry, retryPolicy.MaxAttempts, stepErr)
+			if interrupted && cancellationCause(ctx) == nil && deadlineCause(runCtx) == nil {
+				stopDeadline()
+				return ctx.Err()
+			}
+		}
+		if cause := deadlineCause(runCtx); cause != nil && stepErr != nil && cancellationCause(ctx) == nil {
+			// Шаг прерван дедлайном с эскалацией EscalateCompensate - выполненные шаги компенсируются
+			stepErr = cause
+		}
+		stopDeadline()
+
+		if stepErr != nil {
+			if cause := cancellationCause(ctx); cause != nil {
+				// Шаг прерван отменой саги - записывается как отмененный, а не как ошибка
+				return s.cancelAt(ctx, step, i, &historyEntry, cause)
 			}
 		}
 
+		if errors.Is(stepErr, ErrApprovalPending) {
+			// Шаг ожидает ручного решения - сага продолжится в Approve
+			return s.awaitApproval(ctx, step, historyEntry)
+		}
+		if errors.Is(stepErr, ErrMessagePending) {
+			// Шаг ожидает сообщения другой саги - сага продолжится при его доставке
+			return s.awaitMessage(ctx, step, historyEntry)
+		}
+
 		if stepErr != nil {
 			// Ошибка выполнения шага - запускаем компенсацию
 			stepFailedAt := time.Now()
 			historyEntry.Status = StepStatusFailed
 			historyEntry.Error = stepErr
+			historyEntry.Failure = ClassifyFailure(stepErr)
+			historyEntry.StackTrace = core.StackTraceOf(stepErr)
 			historyEntry.CompletedAt = &stepFailedAt
 			s.updateHistory(historyEntry)
+			s.recordStepDuration(ctx, step.Name(), StepStatusFailed, stepFailedAt.Sub(stepStartedAt))
 
 			// Публикуем событие ошибки шага
 			if s.eventBus != nil {
@@ -371,6 +479,8 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 					SagaID:       s.id,
 					StepName:     step.Name(),
 					Error:        stepErr.Error(),
+					Failure:      historyEntry.Failure,
+					StackTrace:   historyEntry.StackTrace,
 					RetryAttempt: historyEntry.RetryAttempt,
 					Timestamp:    stepFailedAt,
 				}
@@ -387,12 +497,10 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 				_ = s.fsm.Trigger(ctx, errorEvent)
 			}
 
-			// Компенсируем выполненные шаги в обратном порядке
-			compensateErr := s.compensateSteps(ctx, i-1)
+			// Компенсируем выполненные шаги по стратегии компенсации определения
+			// (при неудаче сага остается в SagaStatusCompensationStuck или SagaStatusFailed)
+			compensateErr := s.compensateSteps(ctx, i-1, step.Name())
 			if compensateErr != nil {
-				s.mu.Lock()
-				s.status = SagaStatusFailed
-				s.mu.Unlock()
 				return fmt.Errorf("step %s failed: %w, compensation also failed: %w", step.Name(), stepErr, compensateErr)
 			}
 
@@ -413,7 +521,12 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 		stepCompletedAt := time.Now()
 		historyEntry.Status = StepStatusCompleted
 		historyEntry.CompletedAt = &stepCompletedAt
+		next, historyEntry.Branch = nextStepIndex(steps, i, s.context)
 		s.updateHistory(historyEntry)
+		for _, bypassed := range steps[i+1 : next] {
+			s.skipStep(bypassed, fmt.Sprintf("bypassed by %s: %s", step.Name(), historyEntry.Branch))
+		}
+		s.recordStepDuration(ctx, step.Name(), StepStatusCompleted, stepCompletedAt.Sub(stepStartedAt))
 
 		// Публикуем событие успешного завершения шага
 		if s.eventBus != nil {
@@ -455,7 +568,7 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 	// Все шаги выполнены успешно
 	s.mu.Lock()
 	s.status = SagaStatusCompleted
-	now = time.Now()
+	now := time.Now()
 	s.completedAt = &now
 	s.mu.Unlock()
 
@@ -479,6 +592,91 @@ func (s *BaseSaga) Execute(ctx context.Context) error {
 	return nil
 }
 
+// executeStepAttempt выполняет попытку attempt шага с таймаутом, контролем heartbeat
+// и ключом идемпотентности попытки
+func (s *BaseSaga) executeStepAttempt(ctx context.Context, step SagaStep, stepSagaCtx SagaContext, settings StepSettings, attempt int) error {
+	// Ключ идемпотентности детерминирован для (sagaID, stepName, attempt):
+	// при повторной отправке после восстановления саги получатель отбросит дубликат
+	s.context.SetIdempotencyKey(invoke.GenerateIdempotencyKey(s.id, step.Name(), attempt))
+	stepCtx := invoke.WithSagaStep(ctx, s.id, step.Name(), attempt)
+
+	// Heartbeat шага публикуется в read model и продлевает окно WithHeartbeatTimeout
+	heartbeat := func(progress float64) {
+		s.publishStepHeartbeat(ctx, step.Name(), progress)
+	}
+	var heartbeatCtx context.Context
+	if settings.HeartbeatTimeout > 0 {
+		var stop func()
+		heartbeatCtx, heartbeat, stop = watchHeartbeat(stepCtx, settings.HeartbeatTimeout, heartbeat)
+		defer stop()
+		stepCtx = heartbeatCtx
+	}
+	if scoped, ok := stepSagaCtx.(*stepScopedContext); ok {
+		stepSagaCtx = scoped.withHeartbeat(heartbeat)
+	}
+
+	// Создаем контекст с timeout если задан
+	if settings.Timeout > 0 {
+		var cancel context.CancelFunc
+		stepCtx, cancel = context.WithTimeout(stepCtx, settings.Timeout)
+		defer cancel()
+	}
+
+	invocation := s.newStepInvocation(step, stepSagaCtx, attempt)
+	err := interceptStep(stepCtx, s.stepInterceptors(), invocation, func() error {
+		return safeExecuteStep(stepCtx, step, stepSagaCtx)
+	})
+	if err != nil && heartbeatCtx != nil {
+		// Шаг, прерванный отсутствием heartbeat, завершается ошибкой ErrStepHeartbeatTimeout
+		if cause := context.Cause(heartbeatCtx); errors.Is(cause, ErrStepHeartbeatTimeout) {
+			return cause
+		}
+	}
+	return err
+}
+
+// recoverForward повторяет упавший шаг, пока стратегия ForwardRecoveryStrategy разрешает повторы.
+// Перед каждым ожиданием ошибка последней попытки сохраняется в истории шага.
+// Возвращает итоговую ошибку шага и true, если ожидание повтора прервано контекстом.
+func (s *BaseSaga) recoverForward(ctx context.Context, step SagaStep, stepSagaCtx SagaContext, settings StepSettings, historyEntry *SagaHistory, firstAttempt int, stepErr error) (error, bool) {
+	strategy, ok := SagaCompensationStrategy(s.definition).(ForwardRecoveryStrategy)
+	if !ok {
+		return stepErr, false
+	}
+
+	for recovery := 0; ; recovery++ {
+		delay, retry := strategy.RecoveryDelay(step, recovery, stepErr)
+		if !retry {
+			return stepErr, false
+		}
+
+		historyEntry.Error = stepErr
+		historyEntry.Failure = ClassifyFailure(stepErr)
+		s.updateHistory(*historyEntry)
+		if s.persistence != nil {
+			_ = s.persistence.Save(ctx, s)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return stepErr, true
+		}
+
+		attempt := firstAttempt + recovery
+		historyEntry.RetryAttempt = attempt
+		stepErr = s.executeStepAttempt(ctx, step, stepSagaCtx, settings, attempt)
+		if stepErr == nil || isStepWaiting(stepErr) {
+			historyEntry.Error = nil
+			historyEntry.Failure = nil
+			return stepErr, false
+		}
+		if cancellationCause(ctx) != nil {
+			return stepErr, false
+		}
+	}
+}
+
 func (s *BaseSaga) Compensate(ctx context.Context) error {
 	s.mu.Lock()
 	if s.status != SagaStatusRunning && s.status != SagaStatusCompleted {
@@ -497,11 +695,34 @@ func (s *BaseSaga) Compensate(ctx context.Context) error {
 	}
 
 	steps := s.definition.Steps()
-	return s.compensateSteps(ctx, len(steps)-1)
+	return s.compensateSteps(ctx, len(steps)-1, "")
 }
 
-// compensateSteps компенсирует шаги в обратном порядке
-func (s *BaseSaga) compensateSteps(ctx context.Context, lastStepIndex int) error {
+// ResumeCompensation возобновляет остановленную компенсацию (SagaStatusCompensationStuck):
+// уже компенсированные шаги пропускаются, компенсация продолжается с шага, на котором остановилась
+func (s *BaseSaga) ResumeCompensation(ctx context.Context) error {
+	s.mu.Lock()
+	if s.status != SagaStatusCompensationStuck {
+		s.mu.Unlock()
+		return fmt.Errorf("saga %s compensation cannot be resumed, current status: %s", s.id, s.status)
+	}
+	now := time.Now()
+	s.status = SagaStatusCompensating
+	s.mu.Unlock()
+
+	if ctxImpl, ok := s.context.(*SagaContextImpl); ok {
+		ctxImpl.mu.Lock()
+		ctxImpl.metadata.UpdatedAt = now
+		ctxImpl.mu.Unlock()
+	}
+
+	steps := s.definition.Steps()
+	return s.compensateSteps(ctx, len(steps)-1, "")
+}
+
+// compensateSteps компенсирует выполненные шаги по плану стратегии компенсации определения.
+// failedStep - шаг, на котором остановилась сага ("" - ручная компенсация).
+func (s *BaseSaga) compensateSteps(ctx context.Context, lastStepIndex int, failedStep string) error {
 	steps := s.definition.Steps()
 
 	// Получаем копию истории под блокировкой
@@ -510,86 +731,72 @@ func (s *BaseSaga) compensateSteps(ctx context.Context, lastStepIndex int) error
 	copy(historyCopy, s.history)
 	s.mu.RUnlock()
 
-	for i := lastStepIndex; i >= 0; i-- {
+	// Отбираем выполненные и еще не компенсированные шаги в порядке выполнения
+	// (шаги, компенсированные до остановки компенсации, пропускаются при возобновлении)
+	executed := make([]SagaStep, 0, lastStepIndex+1)
+	for i := 0; i <= lastStepIndex; i++ {
 		step := steps[i]
-
-		// Проверяем, был ли шаг выполнен
-		wasExecuted := false
+		completed, compensated := false, false
 		for _, hist := range historyCopy {
-			if hist.StepName == step.Name() && hist.Status == StepStatusCompleted {
-				wasExecuted = true
-				break
+			if hist.StepName != step.Name() {
+				continue
+			}
+			switch hist.Status {
+			case StepStatusCompleted:
+				completed = true
+			case StepStatusCompensated:
+				compensated = true
 			}
 		}
-
-		if !wasExecuted {
-			continue
+		if completed && !compensated {
+			executed = append(executed, step)
 		}
+	}
 
+	waves, err := SagaCompensationStrategy(s.definition).PlanCompensation(ctx, CompensationRequest{
+		Definition: s.definition,
+		Context:    s.context,
+		Executed:   executed,
+		FailedStep: failedStep,
+	})
+	if err == nil {
+		err = validateCompensationPlan(executed, waves)
+	}
+	if err != nil {
 		s.mu.Lock()
-		s.currentStep = step.Name()
+		s.status = SagaStatusFailed
 		s.mu.Unlock()
 
-		// Добавляем запись в историю
-		stepCompensatingAt := time.Now()
-		historyEntry := SagaHistory{
-			StepName:     step.Name(),
-			Status:       StepStatusCompensating,
-			StartedAt:    stepCompensatingAt,
-			RetryAttempt: 0,
+		if s.persistence != nil {
+			_ = s.persistence.Save(ctx, s)
 		}
-		s.addHistory(historyEntry)
 
-		// Публикуем событие начала компенсации шага
-		if s.eventBus != nil {
-			stepCompensatingEvent := &StepCompensatingEvent{
-				BaseEvent: events.NewBaseEvent("StepCompensating", s.id),
-				SagaID:    s.id,
-				StepName:  step.Name(),
-				Timestamp: stepCompensatingAt,
-			}
-			stepCompensatingEvent.WithCorrelationID(s.context.CorrelationID())
-			_ = s.eventBus.Publish(ctx, stepCompensatingEvent)
-		}
+		return fmt.Errorf("failed to plan compensation: %w", err)
+	}
 
-		// Выполняем компенсацию
-		compensateErr := step.Compensate(ctx, s.context)
-		if compensateErr != nil {
-			historyEntry.Status = StepStatusFailed
-			historyEntry.Error = compensateErr
-			now := time.Now()
-			historyEntry.CompletedAt = &now
-			s.updateHistory(historyEntry)
+	for _, wave := range waves {
+		var waveErr error
+		if len(wave) == 1 {
+			waveErr = s.compensateStep(ctx, wave[0])
+		} else {
+			waveErr = s.compensateWave(ctx, wave)
+		}
 
+		if waveErr != nil {
+			// Компенсация остановлена: побочные эффекты невыполненных компенсаций
+			// остаются, сага ожидает ручного возобновления
 			s.mu.Lock()
-			s.status = SagaStatusFailed
+			s.status = SagaStatusCompensationStuck
 			s.mu.Unlock()
 
 			if s.persistence != nil {
 				_ = s.persistence.Save(ctx, s)
 			}
 
-			return fmt.Errorf("compensation failed for step %s: %w", step.Name(), compensateErr)
-		}
-
-		// Компенсация успешна
-		stepCompensatedAt := time.Now()
-		historyEntry.Status = StepStatusCompensated
-		historyEntry.CompletedAt = &stepCompensatedAt
-		s.updateHistory(historyEntry)
-
-		// Публикуем событие завершения компенсации шага
-		if s.eventBus != nil {
-			stepCompensatedEvent := &StepCompensatedEvent{
-				BaseEvent: events.NewBaseEvent("StepCompensated", s.id),
-				SagaID:    s.id,
-				StepName:  step.Name(),
-				Timestamp: stepCompensatedAt,
-			}
-			stepCompensatedEvent.WithCorrelationID(s.context.CorrelationID())
-			_ = s.eventBus.Publish(ctx, stepCompensatedEvent)
+			return waveErr
 		}
 
+		// Сохраняем состояние после каждой волны (для последовательных стратегий - после каждого шага)
 		if s.persistence != nil {
 			_ = s.persistence.Save(ctx, s)
 		}
@@ -615,6 +822,171 @@ func (s *BaseSaga) compensateSteps(ctx context.Context, lastStepIndex int) error
 	return nil
 }
 
+// compensateWave параллельно компенсирует независимые шаги волны.
+// Дожидается завершения всех шагов волны, чтобы состояние саги сохранялось целиком.
+func (s *BaseSaga) compensateWave(ctx context.Context, wave []SagaStep) error {
+	errCh := make(chan error, len(wave))
+	for _, step := range wave {
+		go func(st SagaStep) {
+			errCh <- s.compensateStep(ctx, st)
+		}(step)
+	}
+
+	var errs []error
+	for range wave {
+		if err := <-errCh; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compensateStep компенсирует один шаг, обновляя историю и публикуя события компенсации
+func (s *BaseSaga) compensateStep(ctx context.Context, step SagaStep) error {
+	s.mu.Lock()
+	s.currentStep = step.Name()
+	s.mu.Unlock()
+
+	// Добавляем запись в историю
+	stepCompensatingAt := time.Now()
+	historyEntry := SagaHistory{
+		StepName:     step.Name(),
+		Status:       StepStatusCompensating,
+		StartedAt:    stepCompensatingAt,
+		RetryAttempt: 0,
+	}
+	s.addHistory(historyEntry)
+
+	// Публикуем событие начала компенсации шага
+	if s.eventBus != nil {
+		stepCompensatingEvent := &StepCompensatingEvent{
+			BaseEvent: events.NewBaseEvent("StepCompensating", s.id),
+			SagaID:    s.id,
+			StepName:  step.Name(),
+			Timestamp: stepCompensatingAt,
+		}
+		stepCompensatingEvent.WithCorrelationID(s.context.CorrelationID())
+		_ = s.eventBus.Publish(ctx, stepCompensatingEvent)
+	}
+
+	// Выполняем компенсацию с retry (политика компенсации не зависит от политики выполнения шага)
+	compensationStep := step.Name() + ".compensate"
+	retryPolicy := s.compensationRetryPolicy(step)
+	interceptors := s.stepInterceptors()
+	var compensateErr error
+	for attempt := 0; attempt < retryPolicy.MaxAttempts; attempt++ {
+		historyEntry.RetryAttempt = attempt
+		s.context.SetIdempotencyKey(invoke.GenerateIdempotencyKey(s.id, compensationStep, attempt))
+		compensateCtx := invoke.WithSagaStep(ctx, s.id, compensationStep, attempt)
+		stepSagaCtx := newStepScopedContext(s.context, step)
+		compensateErr = interceptCompensation(compensateCtx, interceptors, s.newStepInvocation(step, stepSagaCtx, attempt), func() error {
+			return safeCompensateStep(compensateCtx, step, stepSagaCtx)
+		})
+		if compensateErr == nil || !retryPolicy.ShouldRetry(compensateErr, attempt) {
+			break
+		}
+
+		if attempt < retryPolicy.MaxAttempts-1 {
+			select {
+			case <-time.After(retryPolicy.CalculateDelay(attempt)):
+			case <-ctx.Done():
+				compensateErr = ctx.Err()
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	if compensateErr != nil {
+		historyEntry.Status = StepStatusFailed
+		historyEntry.Error = compensateErr
+		historyEntry.Failure = classifyCompensationFailure(compensateErr)
+		historyEntry.StackTrace = core.StackTraceOf(compensateErr)
+		now := time.Now()
+		historyEntry.CompletedAt = &now
+		s.updateHistory(historyEntry)
+		s.recordCompensation(ctx, step.Name(), false)
+
+		// Публикуем событие остановки компенсации
+		if s.eventBus != nil {
+			stuckEvent := &SagaCompensationStuckEvent{
+				BaseEvent: events.NewBaseEvent("SagaCompensationStuck", s.id),
+				SagaID:    s.id,
+				StepName:  step.Name(),
+				Error:     compensateErr.Error(),
+				Failure:   historyEntry.Failure,
+				Attempts:  historyEntry.RetryAttempt + 1,
+				Timestamp: now,
+			}
+			stuckEvent.WithCorrelationID(s.context.CorrelationID())
+			_ = s.eventBus.Publish(ctx, stuckEvent)
+		}
+
+		return fmt.Errorf("compensation failed for step %s: %w", step.Name(), compensateErr)
+	}
+
+	// Компенсация успешна
+	stepCompensatedAt := time.Now()
+	historyEntry.Status = StepStatusCompensated
+	historyEntry.CompletedAt = &stepCompensatedAt
+	s.updateHistory(historyEntry)
+	s.recordCompensation(ctx, step.Name(), true)
+
+	// Публикуем событие завершения компенсации шага
+	if s.eventBus != nil {
+		stepCompensatedEvent := &StepCompensatedEvent{
+			BaseEvent: events.NewBaseEvent("StepCompensated", s.id),
+			SagaID:    s.id,
+			StepName:  step.Name(),
+			Timestamp: stepCompensatedAt,
+		}
+		stepCompensatedEvent.WithCorrelationID(s.context.CorrelationID())
+		_ = s.eventBus.Publish(ctx, stepCompensatedEvent)
+	}
+
+	return nil
+}
+
+// recordStepDuration записывает длительность выполнения шага (saga_step_duration_seconds)
+func (s *BaseSaga) recordStepDuration(ctx context.Context, stepName string, status StepStatus, duration time.Duration) {
+	if s.recorder == nil {
+		return
+	}
+	metrics.ObserveDuration(ctx, s.recorder, "saga_step_duration_seconds", duration, metrics.Labels{
+		"saga":   s.definition.Name(),
+		"step":   stepName,
+		"status": string(status),
+	})
+}
+
+// recordCompensation учитывает компенсацию шага (saga_step_compensations_total)
+func (s *BaseSaga) recordCompensation(ctx context.Context, stepName string, success bool) {
+	if s.recorder == nil {
+		return
+	}
+	s.recorder.Counter(ctx, "saga_step_compensations_total", 1, metrics.Labels{
+		"saga":    s.definition.Name(),
+		"step":    stepName,
+		"success": strconv.FormatBool(success),
+	})
+}
+
+// compensationRetryPolicy возвращает политику повторов компенсации шага:
+// политику шага, политику саги по умолчанию или NoRetry
+func (s *BaseSaga) compensationRetryPolicy(step SagaStep) *RetryPolicy {
+	if withRetry, ok := stepAs[StepCompensationRetry](step); ok {
+		if policy := withRetry.CompensationRetryPolicy(); policy != nil {
+			return policy
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.compensationRetry != nil {
+		return s.compensationRetry
+	}
+	return NoRetry()
+}
+
 func (s *BaseSaga) addHistory(entry SagaHistory) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -634,10 +1006,11 @@ func (s *BaseSaga) updateHistory(entry SagaHistory) {
 
 // SagaContextImpl реализация SagaContext
 type SagaContextImpl struct {
-	mu            sync.RWMutex
-	data          map[string]interface{}
-	metadata      SagaMetadata
-	correlationID string
+	mu             sync.RWMutex
+	data           map[string]interface{}
+	metadata       SagaMetadata
+	correlationID  string
+	idempotencyKey string
 }
 
 // NewSagaContext создает новый контекст саги
@@ -797,6 +1170,41 @@ func (c *SagaContextImpl) SetCorrelationID(id string) {
 	c.metadata.CorrelationID = id
 }
 
+func (c *SagaContextImpl) IdempotencyKey() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.idempotencyKey
+}
+
+func (c *SagaContextImpl) SetIdempotencyKey(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.idempotencyKey = key
+}
+
+// ForStep возвращает пространство имен шага без ограничений доступа
+func (c *SagaContextImpl) ForStep(stepName string) StepContext {
+	return &stepContext{sagaCtx: c, step: stepName, readable: true, writable: true}
+}
+
+// Update выполняет fn под блокировкой контекста и применяет изменения только при успехе.
+// fn не должна вызывать методы самого контекста - только методы view.
+func (c *SagaContextImpl) Update(fn func(view SagaContextView) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	view := newContextView(c.data)
+	if err := fn(view); err != nil {
+		return err
+	}
+
+	if c.data == nil {
+		c.data = make(map[string]interface{})
+	}
+	view.apply(c.data)
+	return nil
+}
+
 func (c *SagaContextImpl) ToMap() map[string]interface{} {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -827,22 +1235,79 @@ func (c *SagaContextImpl) FromMap(data map[string]interface{}) error {
 
 // BaseSagaDefinition базовая реализация SagaDefinition
 type BaseSagaDefinition struct {
-	name  string
-	steps []SagaStep
+	name                 string
+	version              int
+	compensationOrder    CompensationOrder
+	compensationStrategy CompensationStrategy
+	steps                []SagaStep
+	sla                  time.Duration
+	defaults             StepSettings
+	overrides            []StepSettingsOverride
+	interceptors         []StepInterceptor
 }
 
 // NewBaseSagaDefinition создает новое определение саги
 func NewBaseSagaDefinition(name string) *BaseSagaDefinition {
 	return &BaseSagaDefinition{
-		name:  name,
-		steps: make([]SagaStep, 0),
+		name:    name,
+		version: DefaultSagaDefinitionVersion,
+		steps:   make([]SagaStep, 0),
 	}
 }
 
+// WithVersion устанавливает версию определения саги
+func (d *BaseSagaDefinition) WithVersion(version int) *BaseSagaDefinition {
+	d.version = version
+	return d
+}
+
+// WithSLA устанавливает SLA: время, за которое экземпляр саги должен завершиться
+// (отсчитывается от создания экземпляра). При превышении оркестратор публикует SagaSLABreachedEvent.
+func (d *BaseSagaDefinition) WithSLA(sla time.Duration) *BaseSagaDefinition {
+	d.sla = sla
+	return d
+}
+
+// SLA возвращает SLA определения саги (0 - SLA не задан)
+func (d *BaseSagaDefinition) SLA() time.Duration {
+	return d.sla
+}
+
 func (d *BaseSagaDefinition) Name() string {
 	return d.name
 }
 
+// Version возвращает версию определения саги
+func (d *BaseSagaDefinition) Version() int {
+	return d.version
+}
+
+// WithCompensationOrder устанавливает стратегию порядка компенсации шагов
+func (d *BaseSagaDefinition) WithCompensationOrder(order CompensationOrder) *BaseSagaDefinition {
+	d.compensationOrder = order
+	return d
+}
+
+// CompensationOrder возвращает стратегию порядка компенсации шагов
+func (d *BaseSagaDefinition) CompensationOrder() CompensationOrder {
+	if d.compensationOrder == "" {
+		return CompensationOrderReverse
+	}
+	return d.compensationOrder
+}
+
+// WithCompensationStrategy устанавливает стратегию восстановления после ошибки шага
+// (BackwardCompensation по умолчанию, ForwardRecovery или собственная CompensationStrategy)
+func (d *BaseSagaDefinition) WithCompensationStrategy(strategy CompensationStrategy) *BaseSagaDefinition {
+	d.compensationStrategy = strategy
+	return d
+}
+
+// CompensationStrategy возвращает стратегию восстановления (nil - BackwardCompensation)
+func (d *BaseSagaDefinition) CompensationStrategy() CompensationStrategy {
+	return d.compensationStrategy
+}
+
 func (d *BaseSagaDefinition) Steps() []SagaStep {
 	return d.steps
 }
@@ -873,15 +1338,11 @@ func (d *BaseSagaDefinition) Build() (*fsm.FSM, error) {
 		}
 	}
 
-	// Создаем переходы между шагами
-	for i := 0; i < len(d.steps); i++ {
-		fromState := states[i]
-		toState := states[i+1]
-		eventName := fmt.Sprintf("execute_%s", d.steps[i].Name())
-
-		transition := fsm.NewTransition(fromState, toState, eventName)
-		if err := fsmInstance.AddTransition(transition); err != nil {
-			return nil, fmt.Errorf("failed to add transition for step %s: %w", d.steps[i].Name(), err)
+	// Создаем переходы между шагами: по порядку, через шаги с условием выполнения
+	// (охранник - условие шага) и по результату шага (охранник - результат)
+	for i := -1; i < len(d.steps); i++ {
+		if err := addStepTransitions(fsmInstance, d.steps, states, i); err != nil {
+			return nil, err
 		}
 	}
 
@@ -2,9 +2,16 @@ package saga
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/akriventsev/potter/framework/invoke"
 )
 
 func TestNewBaseSaga(t *testing.T) {
@@ -239,6 +246,96 @@ func TestBaseSagaDefinition_Build(t *testing.T) {
 	}
 }
 
+
+func TestBaseSaga_Branching(t *testing.T) {
+	newDefinition := func(executed *[]string) *BaseSagaDefinition {
+		record := func(name string) func(ctx context.Context, sagaCtx SagaContext) error {
+			return func(ctx context.Context, sagaCtx SagaContext) error {
+				*executed = append(*executed, name)
+				return nil
+			}
+		}
+		check := NewBaseStep("check")
+		check.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+			*executed = append(*executed, "check")
+			result := "manual"
+			if sagaCtx.GetFloat64("amount") < 100 {
+				result = "auto"
+			}
+			return SetStepResult(sagaCtx, "check", result)
+		}).NextStepOn("auto", "ship")
+		review := NewBaseStep("review").WithExecute(record("review"))
+		vip := NewBaseStep("vip").WithExecute(record("vip")).WithCondition(func(sagaCtx SagaContext) bool {
+			return sagaCtx.GetBool("vip")
+		})
+		ship := NewBaseStep("ship").WithExecute(record("ship"))
+
+		definition := NewBaseSagaDefinition("branching-saga")
+		definition.AddStep(check)
+		definition.AddStep(review)
+		definition.AddStep(vip)
+		definition.AddStep(ship)
+		return definition
+	}
+
+	run := func(amount float64, vip bool) (*BaseSaga, []string) {
+		var executed []string
+		sagaCtx := NewSagaContext()
+		sagaCtx.Set("amount", amount)
+		sagaCtx.Set("vip", vip)
+		saga, err := NewBaseSaga(fmt.Sprintf("saga-%v-%v", amount, vip), newDefinition(&executed), sagaCtx, nil)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		if err := saga.Execute(context.Background()); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return saga, executed
+	}
+
+	// Результат "auto" переходит к ship, минуя review и vip
+	saga, executed := run(50, true)
+	if strings.Join(executed, ",") != "check,ship" {
+		t.Errorf("Expected check,ship to execute, got %v", executed)
+	}
+	branches := make(map[string]string)
+	statuses := make(map[string]StepStatus)
+	for _, hist := range saga.GetHistory() {
+		branches[hist.StepName] = hist.Branch
+		statuses[hist.StepName] = hist.Status
+	}
+	if branches["check"] != "auto -> ship" {
+		t.Errorf("Expected check branch 'auto -> ship', got %q", branches["check"])
+	}
+	if statuses["review"] != StepStatusSkipped || statuses["vip"] != StepStatusSkipped {
+		t.Errorf("Expected review and vip to be skipped, got %v", statuses)
+	}
+	if state := saga.fsm.CurrentState().Name(); state != "step_ship" {
+		t.Errorf("Expected FSM in step_ship state, got %s", state)
+	}
+
+	// Результат без перехода продолжает сагу по порядку, шаг с невыполненным условием пропускается
+	saga, executed = run(500, false)
+	if strings.Join(executed, ",") != "check,review,ship" {
+		t.Errorf("Expected check,review,ship to execute, got %v", executed)
+	}
+	for _, hist := range saga.GetHistory() {
+		if hist.StepName == "vip" && (hist.Status != StepStatusSkipped || hist.Branch != "condition not met") {
+			t.Errorf("Expected vip skipped by condition, got %s %q", hist.Status, hist.Branch)
+		}
+	}
+	if state := saga.fsm.CurrentState().Name(); state != "step_ship" {
+		t.Errorf("Expected FSM in step_ship state, got %s", state)
+	}
+
+	// Переход назад не допускается
+	backward := NewBaseSagaDefinition("backward")
+	backward.AddStep(NewBaseStep("first"))
+	backward.AddStep(NewBaseStep("second").NextStepOn("retry", "first"))
+	if _, err := backward.Build(); err == nil {
+		t.Error("Expected Build to reject branch to a previous step")
+	}
+}
 func TestBaseSaga_Execute_WithTimeout(t *testing.T) {
 	definition := NewBaseSagaDefinition("test-saga")
 	step1 := NewBaseStep("step1")
@@ -266,6 +363,68 @@ func TestBaseSaga_Execute_WithTimeout(t *testing.T) {
 	}
 }
 
+func TestBaseSagaDefinition_WithDefaults(t *testing.T) {
+	attempts := 0
+	definition := NewBaseSagaDefinition("test-saga").WithDefaults(50*time.Millisecond, ExponentialBackoff(2, time.Millisecond, 1.0))
+
+	// Шаг без своих настроек получает таймаут и повторы из определения
+	step1 := NewBaseStep("step1").WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		attempts++
+		if _, ok := ctx.Deadline(); !ok {
+			return fmt.Errorf("expected default timeout")
+		}
+		if attempts < 2 {
+			return fmt.Errorf("temporary error")
+		}
+		return nil
+	})
+	// Явные настройки шага приоритетнее значений по умолчанию
+	step2 := NewBaseStep("step2").WithTimeout(time.Second).WithRetry(NoRetry()).
+		WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil })
+	definition.AddStep(step1)
+	definition.AddStep(step2)
+
+	if settings := definition.StepSettings(step2); settings.Timeout != time.Second || settings.RetryPolicy.MaxAttempts != 1 {
+		t.Errorf("Expected explicit step settings, got %+v", settings)
+	}
+
+	saga, err := NewBaseSaga("test-id", definition, NewSagaContext(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := saga.Execute(context.Background()); err != nil {
+		t.Fatalf("Expected saga to complete, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts from default retry policy, got %d", attempts)
+	}
+}
+
+func TestEnvStepOverride(t *testing.T) {
+	t.Setenv("ORDER_SAGA_TIMEOUT", "10s")
+	t.Setenv("ORDER_SAGA_RESERVE_INVENTORY_TIMEOUT", "3s")
+	t.Setenv("ORDER_SAGA_RESERVE_INVENTORY_MAX_ATTEMPTS", "5")
+	t.Setenv("ORDER_SAGA_CHARGE_MAX_ATTEMPTS", "invalid")
+
+	defaultPolicy := SimpleRetry(2)
+	definition := NewBaseSagaDefinition("order_saga").
+		WithDefaults(time.Second, defaultPolicy).
+		WithStepOverride(EnvStepOverride("order_saga"))
+
+	reserve := definition.StepSettings(NewBaseStep("reserve-inventory"))
+	if reserve.Timeout != 3*time.Second || reserve.RetryPolicy.MaxAttempts != 5 {
+		t.Errorf("Expected step overrides, got timeout=%v attempts=%d", reserve.Timeout, reserve.RetryPolicy.MaxAttempts)
+	}
+	if defaultPolicy.MaxAttempts != 2 {
+		t.Error("Expected shared default policy not to be modified")
+	}
+
+	charge := definition.StepSettings(NewBaseStep("charge"))
+	if charge.Timeout != 10*time.Second || charge.RetryPolicy != defaultPolicy {
+		t.Errorf("Expected saga-wide timeout and default policy, got %+v", charge)
+	}
+}
+
 func TestBaseSaga_Execute_WithRetry(t *testing.T) {
 	attempts := 0
 	definition := NewBaseSagaDefinition("test-saga")
@@ -297,3 +456,367 @@ func TestBaseSaga_Execute_WithRetry(t *testing.T) {
 	}
 }
 
+
+func TestBaseSaga_Execute_IdempotencyKeys(t *testing.T) {
+	var keys []string
+	definition := NewBaseSagaDefinition("test-saga")
+	step1 := NewBaseStep("step1")
+	step1.WithRetry(&RetryPolicy{MaxAttempts: 2, Backoff: 1.0})
+	step1.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		if invoke.ExtractIdempotencyKey(ctx) != sagaCtx.IdempotencyKey() {
+			t.Errorf("Expected context key %s, got %s", sagaCtx.IdempotencyKey(), invoke.ExtractIdempotencyKey(ctx))
+		}
+		keys = append(keys, sagaCtx.IdempotencyKey())
+		if len(keys) == 1 {
+			return fmt.Errorf("temporary error")
+		}
+		return nil
+	})
+	definition.AddStep(step1)
+
+	saga, err := NewBaseSaga("test-id", definition, NewSagaContext(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := saga.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	expected := []string{
+		invoke.GenerateIdempotencyKey("test-id", "step1", 0),
+		invoke.GenerateIdempotencyKey("test-id", "step1", 1),
+	}
+	if len(keys) != 2 || keys[0] != expected[0] || keys[1] != expected[1] {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+}
+
+func TestBaseSaga_CompensationOrder(t *testing.T) {
+	newDefinition := func(order CompensationOrder, log *[]string, mu *sync.Mutex) *BaseSagaDefinition {
+		definition := NewBaseSagaDefinition("order-saga").WithCompensationOrder(order)
+		for _, step := range []*BaseStep{
+			NewBaseStep("reserve"),
+			NewBaseStep("notify"),
+			NewBaseStep("charge").WithDependsOn("reserve"),
+			NewBaseStep("ship").WithDependsOn("charge"),
+		} {
+			name := step.Name()
+			step.WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+				mu.Lock()
+				defer mu.Unlock()
+				*log = append(*log, name)
+				return nil
+			})
+			definition.AddStep(step)
+		}
+		return definition
+	}
+
+	tests := []struct {
+		order CompensationOrder
+		waves [][]string
+	}{
+		{CompensationOrderReverse, [][]string{{"ship"}, {"charge"}, {"notify"}, {"reserve"}}},
+		{CompensationOrderDependency, [][]string{{"ship"}, {"notify"}, {"charge"}, {"reserve"}}},
+		{CompensationOrderParallel, [][]string{{"ship", "notify"}, {"charge"}, {"reserve"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			var log []string
+			var mu sync.Mutex
+			definition := newDefinition(tt.order, &log, &mu)
+
+			waves, err := compensationWaves(definition.Steps(), definition.CompensationOrder())
+			if err != nil {
+				t.Fatalf("Failed to plan compensation: %v", err)
+			}
+			got := make([][]string, 0, len(waves))
+			for _, wave := range waves {
+				names := make([]string, 0, len(wave))
+				for _, step := range wave {
+					names = append(names, step.Name())
+				}
+				got = append(got, names)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.waves) {
+				t.Fatalf("Expected waves %v, got %v", tt.waves, got)
+			}
+
+			saga, err := NewBaseSaga("order-id", definition, NewSagaContext(), nil)
+			if err != nil {
+				t.Fatalf("Failed to create saga: %v", err)
+			}
+			saga.status = SagaStatusCompleted
+			for _, step := range definition.Steps() {
+				saga.addHistory(SagaHistory{StepName: step.Name(), Status: StepStatusCompleted, StartedAt: time.Now()})
+			}
+
+			if err := saga.Compensate(context.Background()); err != nil {
+				t.Fatalf("Compensate failed: %v", err)
+			}
+			if saga.Status() != SagaStatusCompensated {
+				t.Errorf("Expected status Compensated, got %s", saga.Status())
+			}
+
+			position := make(map[string]int)
+			for i, name := range log {
+				position[name] = i
+			}
+			if len(position) != 4 {
+				t.Fatalf("Expected 4 compensations, got %v", log)
+			}
+			if position["ship"] > position["charge"] || position["charge"] > position["reserve"] {
+				t.Errorf("Dependent steps must be compensated first, got %v", log)
+			}
+		})
+	}
+}
+
+func TestSagaBuilder_CompensationDependencies(t *testing.T) {
+	_, err := NewSagaBuilder("cycle-saga").
+		WithCompensationOrder(CompensationOrderParallel).
+		AddStep(NewBaseStep("a").WithDependsOn("b")).
+		AddStep(NewBaseStep("b").WithDependsOn("a")).
+		Build()
+	if err == nil {
+		t.Error("Expected error for dependency cycle")
+	}
+
+	_, err = NewSagaBuilder("unknown-saga").
+		AddStep(NewBaseStep("a").WithDependsOn("missing")).
+		Build()
+	if err == nil {
+		t.Error("Expected error for unknown dependency")
+	}
+
+	_, err = NewSagaBuilder("bad-order-saga").
+		WithCompensationOrder("random").
+		AddStep(NewBaseStep("a")).
+		Build()
+	if err == nil {
+		t.Error("Expected error for unknown compensation order")
+	}
+}
+
+func TestBaseSaga_ForwardRecovery(t *testing.T) {
+	newSaga := func(failures int, stepErr error) (*BaseSaga, *[]string) {
+		var compensated []string
+		definition := NewBaseSagaDefinition("shipping-saga").
+			WithCompensationStrategy(NewForwardRecovery(time.Millisecond, 2*time.Millisecond).WithMaxAttempts(5))
+		reserve := NewBaseStep("reserve").
+			WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil }).
+			WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+				compensated = append(compensated, "reserve")
+				return nil
+			})
+		attempts := 0
+		ship := NewBaseStep("ship").WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+			attempts++
+			if attempts <= failures {
+				return stepErr
+			}
+			return nil
+		})
+		definition.AddStep(reserve)
+		definition.AddStep(ship)
+
+		saga, err := NewBaseSaga("shipping-id", definition, NewSagaContext(), nil)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		return saga, &compensated
+	}
+
+	// Шаг повторяется после исчерпания политики повторов, пока не выполнится
+	saga, compensated := newSaga(3, errors.New("carrier unavailable"))
+	if err := saga.Execute(context.Background()); err != nil {
+		t.Fatalf("Expected forward recovery, got %v", err)
+	}
+	history := saga.GetHistory()
+	if saga.Status() != SagaStatusCompleted || len(*compensated) != 0 {
+		t.Fatalf("Expected completed saga without compensation, got %s, %v", saga.Status(), *compensated)
+	}
+	if last := history[len(history)-1]; last.RetryAttempt != 3 || last.Error != nil {
+		t.Errorf("Expected recovered step at attempt 3 without error, got %+v", last)
+	}
+
+	// Неповторяемая ошибка приводит к компенсации
+	saga, compensated = newSaga(1, NewBusinessError("ADDRESS_INVALID", errors.New("invalid address")))
+	if err := saga.Execute(context.Background()); err == nil {
+		t.Fatal("Expected step error")
+	}
+	if saga.Status() != SagaStatusCompensated || len(*compensated) != 1 {
+		t.Errorf("Expected compensated saga, got %s, %v", saga.Status(), *compensated)
+	}
+
+	// После MaxAttempts повторов сага компенсируется
+	saga, compensated = newSaga(100, errors.New("carrier unavailable"))
+	if err := saga.Execute(context.Background()); err == nil {
+		t.Fatal("Expected step error")
+	}
+	if saga.Status() != SagaStatusCompensated || len(*compensated) != 1 {
+		t.Errorf("Expected compensated saga after max attempts, got %s, %v", saga.Status(), *compensated)
+	}
+}
+
+// skipStepsStrategy тестовая стратегия частичной компенсации
+type skipStepsStrategy struct {
+	skip map[string]bool
+}
+
+func (s skipStepsStrategy) PlanCompensation(ctx context.Context, request CompensationRequest) ([][]SagaStep, error) {
+	var wave []SagaStep
+	for _, step := range request.Executed {
+		if !s.skip[step.Name()] {
+			wave = append(wave, step)
+		}
+	}
+	return [][]SagaStep{wave}, nil
+}
+
+func TestBaseSaga_CustomCompensationStrategy(t *testing.T) {
+	var mu sync.Mutex
+	var compensated []string
+	definition, err := NewSagaBuilder("order-saga").
+		WithCompensationStrategy(skipStepsStrategy{skip: map[string]bool{"notify": true}}).
+		AddStep(NewBaseStep("reserve").WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil })).
+		AddStep(NewBaseStep("notify").WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil })).
+		AddStep(NewBaseStep("charge").WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return errors.New("declined") })).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	for _, step := range definition.Steps() {
+		name := step.Name()
+		step.(*BaseStep).WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			compensated = append(compensated, name)
+			return nil
+		})
+	}
+
+	saga, err := NewBaseSaga("order-id", definition, NewSagaContext(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create saga: %v", err)
+	}
+	if err := saga.Execute(context.Background()); err == nil {
+		t.Fatal("Expected step error")
+	}
+	if saga.Status() != SagaStatusCompensated || fmt.Sprint(compensated) != "[reserve]" {
+		t.Errorf("Expected only reserve to be compensated, got %s, %v", saga.Status(), compensated)
+	}
+
+	// План со шагом, который не выполнялся, отклоняется
+	if err := validateCompensationPlan(definition.Steps()[:1], [][]SagaStep{definition.Steps()[1:2]}); err == nil {
+		t.Error("Expected error for step that was not executed")
+	}
+}
+
+// testMoney тестовый тип с текстовым представлением
+type testMoney struct {
+	cents int64
+}
+
+func (m testMoney) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100)), nil
+}
+
+func (m *testMoney) UnmarshalText(text []byte) error {
+	var units, cents int64
+	if _, err := fmt.Sscanf(string(text), "%d.%d", &units, &cents); err != nil {
+		return err
+	}
+	m.cents = units*100 + cents
+	return nil
+}
+
+func TestContextSerializer_RoundTrip(t *testing.T) {
+	serializer := NewContextSerializer().Register(NewTextContextCodec[testMoney]("money"))
+
+	deadline := time.Date(2025, 3, 1, 12, 30, 45, 123456789, time.FixedZone("MSK", 3*3600))
+	sagaCtx := NewSagaContextWithCorrelationID("corr-1")
+	sagaCtx.Set("deadline", deadline)
+	sagaCtx.Set("timeout", 90*time.Second)
+	sagaCtx.Set("quantity", 3)
+	sagaCtx.Set("order_number", int64(1<<60+1))
+	sagaCtx.Set("amount", big.NewRat(1999, 100))
+	sagaCtx.Set("price", testMoney{cents: 12345})
+	sagaCtx.Set("items", []interface{}{map[string]interface{}{"reserved_at": deadline}})
+	sagaCtx.Set("ratio", 0.5)
+	sagaCtx.Set("legacy_time", "2025-03-01T09:30:45Z")
+
+	encoded, err := serializer.Encode(sagaCtx.ToMap())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var stored map[string]interface{}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	decoded, err := serializer.Decode(stored)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	restored := NewSagaContext()
+	if err := restored.FromMap(decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if value, ok := restored.Get("deadline").(time.Time); !ok || !value.Equal(deadline) {
+		t.Errorf("Expected deadline %v, got %#v", deadline, restored.Get("deadline"))
+	}
+	if value, ok := restored.Get("timeout").(time.Duration); !ok || value != 90*time.Second {
+		t.Errorf("Expected timeout 1m30s, got %#v", restored.Get("timeout"))
+	}
+	if value, ok := restored.Get("quantity").(int); !ok || value != 3 {
+		t.Errorf("Expected int quantity 3, got %#v", restored.Get("quantity"))
+	}
+	if value, ok := restored.Get("order_number").(int64); !ok || value != 1<<60+1 {
+		t.Errorf("Expected exact int64 order number, got %#v", restored.Get("order_number"))
+	}
+	if value, ok := restored.Get("amount").(*big.Rat); !ok || value.Cmp(big.NewRat(1999, 100)) != 0 {
+		t.Errorf("Expected decimal amount 19.99, got %#v", restored.Get("amount"))
+	}
+	if value, ok := restored.Get("price").(testMoney); !ok || value.cents != 12345 {
+		t.Errorf("Expected money 123.45, got %#v", restored.Get("price"))
+	}
+	items, _ := restored.Get("items").([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %#v", restored.Get("items"))
+	}
+	if value, ok := items[0].(map[string]interface{})["reserved_at"].(time.Time); !ok || !value.Equal(deadline) {
+		t.Errorf("Expected nested time to be restored, got %#v", items[0])
+	}
+	if value, ok := restored.Get("ratio").(float64); !ok || value != 0.5 {
+		t.Errorf("Expected float ratio 0.5, got %#v", restored.Get("ratio"))
+	}
+	if restored.CorrelationID() != "corr-1" {
+		t.Errorf("Expected correlation ID corr-1, got %s", restored.CorrelationID())
+	}
+
+	// Строки RFC3339, сохраненные до регистрации кодеков, остаются строками
+	if _, ok := restored.Get("legacy_time").(string); !ok {
+		t.Errorf("Expected legacy time to stay a string, got %#v", restored.Get("legacy_time"))
+	}
+	if value, ok := ContextTime(restored, "legacy_time"); !ok || value.Hour() != 9 {
+		t.Errorf("Expected ContextTime to parse legacy time, got %v", value)
+	}
+	if value, ok := ContextDuration(restored, "timeout"); !ok || value != 90*time.Second {
+		t.Errorf("Expected ContextDuration 1m30s, got %v", value)
+	}
+
+	// Значение неизвестного кодека возвращается как есть
+	unknown := map[string]interface{}{"value": map[string]interface{}{"$type": "unknown", "$value": "x"}}
+	if decoded, err := serializer.Decode(unknown); err != nil || decoded["value"].(map[string]interface{})["$value"] != "x" {
+		t.Errorf("Expected unknown codec value to pass through, got %#v (err %v)", decoded, err)
+	}
+	if _, err := serializer.Decode(map[string]interface{}{"value": map[string]interface{}{"$type": "time", "$value": "yesterday"}}); err == nil || !strings.Contains(err.Error(), "value") {
+		t.Errorf("Expected decode error for invalid time, got %v", err)
+	}
+}
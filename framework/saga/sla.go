@@ -0,0 +1,120 @@
+package saga
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// SagaSLABreachedKey ключ кастомных метаданных саги, отмечающий превышение SLA.
+// Сохраняется вместе с сагой, поэтому после Resume событие о превышении не публикуется повторно.
+const SagaSLABreachedKey = "sla_breached"
+
+// SLADefinition реализуется определениями саг с SLA (например, BaseSagaDefinition)
+type SLADefinition interface {
+	SLA() time.Duration
+}
+
+// DefinitionSLA возвращает SLA определения саги (0, если определение не задает SLA)
+func DefinitionSLA(definition SagaDefinition) time.Duration {
+	if withSLA, ok := definition.(SLADefinition); ok {
+		return withSLA.SLA()
+	}
+	return 0
+}
+
+// sagaSLADeadline возвращает срок завершения саги по SLA, отсчитываемый от создания экземпляра
+func sagaSLADeadline(saga Saga) (time.Time, bool) {
+	sla := DefinitionSLA(saga.Definition())
+	if sla <= 0 {
+		return time.Time{}, false
+	}
+	createdAt := saga.Context().Metadata().CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return createdAt.Add(sla), true
+}
+
+// isSLABreached проверяет, отмечено ли превышение SLA в метаданных саги
+func isSLABreached(saga Saga) bool {
+	breached, _ := saga.Context().Metadata().Custom[SagaSLABreachedKey].(bool)
+	return breached
+}
+
+// isTerminalSagaStatus проверяет, завершена ли сага
+func isTerminalSagaStatus(status SagaStatus) bool {
+	return status == SagaStatusCompleted || status == SagaStatusCompensated || status == SagaStatusFailed
+}
+
+// watchSLA отслеживает SLA выполняющейся саги и публикует SagaSLABreachedEvent, если сага
+// не завершилась к сроку. Возвращаемая функция останавливает отслеживание.
+func (o *DefaultOrchestrator) watchSLA(ctx context.Context, saga Saga) func() {
+	deadline, ok := sagaSLADeadline(saga)
+	if !ok || isSLABreached(saga) {
+		return func() {}
+	}
+
+	var once sync.Once
+	timer := time.AfterFunc(time.Until(deadline), func() {
+		once.Do(func() {
+			if isTerminalSagaStatus(saga.Status()) {
+				return
+			}
+			o.reportSLABreach(context.WithoutCancel(ctx), saga, deadline)
+		})
+	})
+	return func() {
+		timer.Stop()
+	}
+}
+
+// reportSLABreach отмечает превышение SLA в саге и публикует событие
+func (o *DefaultOrchestrator) reportSLABreach(ctx context.Context, saga Saga, deadline time.Time) {
+	sagaID := saga.ID()
+	saga.Context().SetCustomValue(SagaSLABreachedKey, true)
+
+	if o.metrics != nil {
+		o.metrics.RecordEvent(ctx, "saga.sla_breached")
+	}
+	if o.eventBus == nil {
+		return
+	}
+
+	now := time.Now()
+	sla := DefinitionSLA(saga.Definition())
+	breachedEvent := &SagaSLABreachedEvent{
+		BaseEvent:      events.NewBaseEvent("SagaSLABreached", sagaID),
+		SagaID:         sagaID,
+		DefinitionName: saga.Definition().Name(),
+		CurrentStep:    saga.CurrentStep(),
+		SLA:            sla,
+		Deadline:       deadline,
+		Elapsed:        now.Sub(deadline) + sla,
+		Timestamp:      now,
+	}
+	breachedEvent.WithCorrelationID(saga.Context().CorrelationID())
+	_ = o.eventBus.Publish(ctx, breachedEvent)
+}
+
+// applySLA вычисляет оставшееся время SLA на момент now (для завершенной саги - на момент завершения)
+func (r *SagaStatusResponse) applySLA(deadline *time.Time, breached bool, now time.Time) {
+	r.SLADeadline = deadline
+	r.SLABreached = breached
+	if deadline == nil {
+		return
+	}
+
+	at := now
+	if r.CompletedAt != nil {
+		at = *r.CompletedAt
+	}
+	remaining := deadline.Sub(at)
+	if remaining <= 0 {
+		remaining = 0
+		r.SLABreached = true
+	}
+	r.SLARemaining = &remaining
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/akriventsev/potter/framework/core"
 	"github.com/akriventsev/potter/framework/events"
 	"github.com/akriventsev/potter/framework/invoke"
 	"github.com/akriventsev/potter/framework/transport"
@@ -41,6 +42,11 @@ func (p *RetryPolicy) ShouldRetry(err error, attempt int) bool {
 		return false
 	}
 
+	// Ошибка, явно помеченная шагом как неповторяемая (StepError), не повторяется
+	if isNonRetryable(err) {
+		return false
+	}
+
 	// Если указаны retryable errors, проверяем соответствие
 	if len(p.RetryableErrors) > 0 {
 		for _, retryableErr := range p.RetryableErrors {
@@ -61,6 +67,13 @@ func (p *RetryPolicy) CalculateDelay(attempt int) time.Duration {
 	return delay
 }
 
+// StepCompensationRetry шаг с собственной политикой повторов компенсации.
+// Реализуется BaseStep (см. BaseStep.WithCompensationRetry).
+type StepCompensationRetry interface {
+	// CompensationRetryPolicy возвращает политику повторов компенсации (nil - политика по умолчанию)
+	CompensationRetryPolicy() *RetryPolicy
+}
+
 // NoRetry создает политику без повторов
 func NoRetry() *RetryPolicy {
 	return &RetryPolicy{
@@ -95,8 +108,18 @@ type BaseStep struct {
 	compensateAction func(ctx context.Context, sagaCtx SagaContext) error
 	guard           func(ctx context.Context, sagaCtx SagaContext) bool
 	timeout         time.Duration
+	heartbeatTimeout time.Duration
+	deadline        time.Duration
+	escalation      DeadlineEscalation
 	retryPolicy     *RetryPolicy
+	retryProvider   func() *RetryPolicy
+	compensationRetry *RetryPolicy
 	metadata        map[string]interface{}
+	readsFrom       []string
+	dependsOn       []string
+	// condition условие выполнения шага, nextSteps - переходы по результату (см. BranchingStep)
+	condition       func(sagaCtx SagaContext) bool
+	nextSteps       map[string]string
 }
 
 // NewBaseStep создает новый базовый шаг
@@ -111,6 +134,11 @@ func (s *BaseStep) Name() string {
 	return s.name
 }
 
+// baseStep возвращает BaseStep, встроенный в шаг (используется при экспорте диаграммы)
+func (s *BaseStep) baseStep() *BaseStep {
+	return s
+}
+
 func (s *BaseStep) Execute(ctx context.Context, sagaCtx SagaContext) error {
 	if s.executeAction == nil {
 		return fmt.Errorf("execute action not set for step %s", s.name)
@@ -138,6 +166,9 @@ func (s *BaseStep) Timeout() time.Duration {
 }
 
 func (s *BaseStep) RetryPolicy() *RetryPolicy {
+	if s.retryProvider != nil {
+		return s.retryProvider()
+	}
 	return s.retryPolicy
 }
 
@@ -159,18 +190,94 @@ func (s *BaseStep) WithGuard(guard func(ctx context.Context, sagaCtx SagaContext
 	return s
 }
 
+// WithReadAccess разрешает шагу читать пространства имен указанных шагов (sagaCtx.ForStep)
+func (s *BaseStep) WithReadAccess(stepNames ...string) *BaseStep {
+	s.readsFrom = append(s.readsFrom, stepNames...)
+	return s
+}
+
+// ReadsFrom возвращает имена шагов, пространства имен которых шаг может читать
+func (s *BaseStep) ReadsFrom() []string {
+	return s.readsFrom
+}
+
+// WithDependsOn объявляет зависимости шага от других шагов саги.
+// Используется стратегиями компенсации CompensationOrderDependency и CompensationOrderParallel.
+func (s *BaseStep) WithDependsOn(stepNames ...string) *BaseStep {
+	s.dependsOn = append(s.dependsOn, stepNames...)
+	return s
+}
+
+// DependsOn возвращает имена шагов, от которых зависит шаг
+func (s *BaseStep) DependsOn() []string {
+	return s.dependsOn
+}
+
 // WithTimeout устанавливает timeout
 func (s *BaseStep) WithTimeout(timeout time.Duration) *BaseStep {
 	s.timeout = timeout
 	return s
 }
 
+// WithHeartbeatTimeout задает максимальный интервал между heartbeat шага (StepContext.Heartbeat).
+// Если шаг не сообщил о прогрессе дольше timeout, его контекст отменяется и попытка
+// завершается ошибкой ErrStepHeartbeatTimeout (с повторами по retry policy шага).
+func (s *BaseStep) WithHeartbeatTimeout(timeout time.Duration) *BaseStep {
+	s.heartbeatTimeout = timeout
+	return s
+}
+
+// HeartbeatTimeout возвращает максимальный интервал между heartbeat шага (0 - не отслеживается)
+func (s *BaseStep) HeartbeatTimeout() time.Duration {
+	return s.heartbeatTimeout
+}
+
+// WithDeadline задает дедлайн шага: максимальное время от начала шага до его завершения
+// с учетом всех попыток и задержек между ними. По истечении публикуется
+// StepDeadlineExceededEvent, вызываются обработчики эскалации оркестратора
+// (DefaultOrchestrator.WithEscalationHandler) и выполняется escalation.
+func (s *BaseStep) WithDeadline(deadline time.Duration, escalation DeadlineEscalation) *BaseStep {
+	s.deadline = deadline
+	s.escalation = escalation
+	return s
+}
+
+// Deadline возвращает дедлайн шага (0 - не задан)
+func (s *BaseStep) Deadline() time.Duration {
+	return s.deadline
+}
+
+// DeadlineEscalation возвращает действие при превышении дедлайна шага
+func (s *BaseStep) DeadlineEscalation() DeadlineEscalation {
+	return s.escalation
+}
+
 // WithRetry устанавливает retry policy
 func (s *BaseStep) WithRetry(policy *RetryPolicy) *BaseStep {
 	s.retryPolicy = policy
 	return s
 }
 
+// WithRetryProvider устанавливает функцию, возвращающую текущую retry policy.
+// Политика читается перед каждым выполнением шага и может изменяться во время работы
+// (например config.Value[*RetryPolicy].Get). Имеет приоритет над WithRetry.
+func (s *BaseStep) WithRetryProvider(provider func() *RetryPolicy) *BaseStep {
+	s.retryProvider = provider
+	return s
+}
+
+// WithCompensationRetry устанавливает политику повторов компенсации шага,
+// независимую от политики повторов выполнения (WithRetry)
+func (s *BaseStep) WithCompensationRetry(policy *RetryPolicy) *BaseStep {
+	s.compensationRetry = policy
+	return s
+}
+
+// CompensationRetryPolicy возвращает политику повторов компенсации шага
+func (s *BaseStep) CompensationRetryPolicy() *RetryPolicy {
+	return s.compensationRetry
+}
+
 // WithMetadata добавляет метаданные
 func (s *BaseStep) WithMetadata(key string, value interface{}) *BaseStep {
 	if s.metadata == nil {
@@ -209,6 +316,7 @@ func NewCommandStep(
 		if correlationID := sagaCtx.CorrelationID(); correlationID != "" {
 			ctx = invoke.WithCorrelationID(ctx, correlationID)
 		}
+		ctx = withStepIdempotencyKey(ctx, sagaCtx)
 
 		// Отправляем команду через CommandBus
 		return commandBus.Send(ctx, forwardCommand)
@@ -220,6 +328,7 @@ func NewCommandStep(
 			if correlationID := sagaCtx.CorrelationID(); correlationID != "" {
 				ctx = invoke.WithCorrelationID(ctx, correlationID)
 			}
+			ctx = withStepIdempotencyKey(ctx, sagaCtx)
 			return commandBus.Send(ctx, compensateCommand)
 		})
 	}
@@ -227,6 +336,18 @@ func NewCommandStep(
 	return step
 }
 
+// withStepIdempotencyKey добавляет в контекст ключ идемпотентности шага из SagaContext,
+// если он еще не определен контекстом выполнения
+func withStepIdempotencyKey(ctx context.Context, sagaCtx SagaContext) context.Context {
+	if invoke.ExtractIdempotencyKey(ctx) != "" {
+		return ctx
+	}
+	if key := sagaCtx.IdempotencyKey(); key != "" {
+		return invoke.WithIdempotencyKey(ctx, key)
+	}
+	return ctx
+}
+
 // EventStep шаг для публикации события через EventBus
 type EventStep struct {
 	*BaseStep
@@ -374,11 +495,15 @@ func NewParallelStep(name string, steps ...SagaStep) *ParallelStep {
 		}
 
 		resultCh := make(chan stepResult, len(steps))
+		// Запись одного ключа контекста несколькими шагами группы считается ошибкой
+		tracker := newContextWriteTracker()
 		
 		// Запускаем все шаги параллельно
 		for i, step := range steps {
 			go func(idx int, st SagaStep) {
-				err := st.Execute(ctx, sagaCtx)
+				// Вложенные шаги получают собственные ключи идемпотентности
+				branchCtx := newParallelBranchContext(sagaCtx, st.Name(), tracker)
+				err := safeExecuteStep(invoke.WithSagaSubStep(ctx, st.Name()), st, newStepScopedContext(branchCtx, st))
 				resultCh <- stepResult{index: idx, err: err}
 			}(i, step)
 		}
@@ -397,6 +522,11 @@ func NewParallelStep(name string, steps ...SagaStep) *ParallelStep {
 			}
 		}
 
+		// Конфликты записи в контекст приоритетнее ошибок шагов, которые они могли вызвать
+		if err := tracker.err(); err != nil {
+			return fmt.Errorf("parallel execution failed: %w", err)
+		}
+
 		if len(errors) > 0 {
 			return fmt.Errorf("parallel execution failed: %v", errors)
 		}
@@ -416,7 +546,7 @@ func NewParallelStep(name string, steps ...SagaStep) *ParallelStep {
 		// Компенсируем все шаги параллельно (в обратном порядке)
 		for i := len(steps) - 1; i >= 0; i-- {
 			go func(idx int, st SagaStep) {
-				err := st.Compensate(ctx, sagaCtx)
+				err := safeCompensateStep(invoke.WithSagaSubStep(ctx, st.Name()), st, newStepScopedContext(sagaCtx, st))
 				resultCh <- stepResult{index: idx, err: err}
 			}(i, steps[i])
 		}
@@ -472,7 +602,7 @@ func NewConditionalStep(
 		if !condition(ctx, sagaCtx) {
 			return nil // Условие не выполнено, пропускаем шаг
 		}
-		return step.Execute(ctx, sagaCtx)
+		return step.Execute(ctx, newStepScopedContext(sagaCtx, step))
 	})
 
 	// Устанавливаем compensate action
@@ -480,9 +610,24 @@ func NewConditionalStep(
 		if !condition(ctx, sagaCtx) {
 			return nil // Условие не выполнено, компенсация не нужна
 		}
-		return step.Compensate(ctx, sagaCtx)
+		return step.Compensate(ctx, newStepScopedContext(sagaCtx, step))
 	})
 
 	return conditionalStep
 }
 
+
+// safeExecuteStep выполняет шаг, преобразуя панику в ошибку с кодом core.ErrPanicRecovered.
+// Паника в шаге не завершает горутину оркестратора, а обрабатывается как ошибка шага (retry, компенсация).
+func safeExecuteStep(ctx context.Context, step SagaStep, sagaCtx SagaContext) error {
+	return core.SafeCall(func() error {
+		return step.Execute(ctx, sagaCtx)
+	})
+}
+
+// safeCompensateStep выполняет компенсацию шага, преобразуя панику в ошибку с кодом core.ErrPanicRecovered
+func safeCompensateStep(ctx context.Context, step SagaStep, sagaCtx SagaContext) error {
+	return core.SafeCall(func() error {
+		return step.Compensate(ctx, sagaCtx)
+	})
+}
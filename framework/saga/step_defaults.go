@@ -0,0 +1,139 @@
+// Package saga предоставляет настройки шагов по умолчанию на уровне определения саги.
+package saga
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StepSettings таймаут и политика повторов, с которыми выполняется шаг
+type StepSettings struct {
+	Timeout     time.Duration
+	RetryPolicy *RetryPolicy
+	// HeartbeatTimeout максимальный интервал между heartbeat шага (0 - не отслеживается)
+	HeartbeatTimeout time.Duration
+	// Deadline дедлайн шага с учетом повторов (0 - не задан), Escalation - действие по его истечении
+	Deadline   time.Duration
+	Escalation DeadlineEscalation
+}
+
+// StepSettingsOverride хук переопределения настроек шага (например, из переменных окружения).
+// Получает настройки шага с учетом значений по умолчанию и возвращает итоговые.
+type StepSettingsOverride func(stepName string, settings StepSettings) StepSettings
+
+// StepSettingsResolver реализуется определениями саг, задающими настройки шагов
+// по умолчанию (например, BaseSagaDefinition)
+type StepSettingsResolver interface {
+	StepSettings(step SagaStep) StepSettings
+}
+
+// WithDefaults задает таймаут и политику повторов для шагов, у которых они не заданы явно.
+// Нулевой timeout и nil retryPolicy не меняют соответствующее значение по умолчанию.
+func (d *BaseSagaDefinition) WithDefaults(timeout time.Duration, retryPolicy *RetryPolicy) *BaseSagaDefinition {
+	if timeout > 0 {
+		d.defaults.Timeout = timeout
+	}
+	if retryPolicy != nil {
+		d.defaults.RetryPolicy = retryPolicy
+	}
+	return d
+}
+
+// WithStepOverride добавляет хук переопределения настроек шагов.
+// Хуки применяются по порядку добавления после значений по умолчанию.
+func (d *BaseSagaDefinition) WithStepOverride(override StepSettingsOverride) *BaseSagaDefinition {
+	d.overrides = append(d.overrides, override)
+	return d
+}
+
+// StepSettings возвращает настройки выполнения шага: явные настройки шага,
+// значения по умолчанию определения и хуки переопределения
+func (d *BaseSagaDefinition) StepSettings(step SagaStep) StepSettings {
+	settings := stepOwnSettings(step)
+	if settings.Timeout == 0 {
+		settings.Timeout = d.defaults.Timeout
+	}
+	if settings.RetryPolicy == nil {
+		settings.RetryPolicy = d.defaults.RetryPolicy
+	}
+	for _, override := range d.overrides {
+		settings = override(step.Name(), settings)
+	}
+	return settings
+}
+
+// resolveStepSettings возвращает настройки шага с учетом определения саги
+func resolveStepSettings(definition SagaDefinition, step SagaStep) StepSettings {
+	if resolver, ok := definition.(StepSettingsResolver); ok {
+		return resolver.StepSettings(step)
+	}
+	return stepOwnSettings(step)
+}
+
+// stepOwnSettings возвращает настройки, заданные самим шагом
+func stepOwnSettings(step SagaStep) StepSettings {
+	settings := StepSettings{Timeout: step.Timeout(), RetryPolicy: step.RetryPolicy(), HeartbeatTimeout: stepHeartbeatTimeout(step)}
+	settings.Deadline, settings.Escalation = stepDeadline(step)
+	return settings
+}
+
+// EnvStepOverride возвращает хук, читающий настройки шагов из переменных окружения:
+//
+//	<PREFIX>_TIMEOUT, <PREFIX>_MAX_ATTEMPTS               - для всех шагов
+//	<PREFIX>_<STEP>_TIMEOUT, <PREFIX>_<STEP>_MAX_ATTEMPTS - для шага (приоритетнее)
+//
+// Имя шага приводится к верхнему регистру, символы кроме букв и цифр заменяются на "_"
+// (шаг "reserve-inventory" с префиксом "ORDER_SAGA" -> ORDER_SAGA_RESERVE_INVENTORY_TIMEOUT).
+// Таймаут задается в формате time.ParseDuration; некорректные значения игнорируются.
+func EnvStepOverride(prefix string) StepSettingsOverride {
+	prefix = envKey(prefix)
+
+	return func(stepName string, settings StepSettings) StepSettings {
+		stepPrefix := prefix + "_" + envKey(stepName)
+
+		for _, key := range []string{prefix + "_TIMEOUT", stepPrefix + "_TIMEOUT"} {
+			if value, ok := os.LookupEnv(key); ok {
+				if timeout, err := time.ParseDuration(value); err == nil && timeout >= 0 {
+					settings.Timeout = timeout
+				}
+			}
+		}
+
+		for _, key := range []string{prefix + "_MAX_ATTEMPTS", stepPrefix + "_MAX_ATTEMPTS"} {
+			if value, ok := os.LookupEnv(key); ok {
+				if attempts, err := strconv.Atoi(value); err == nil && attempts > 0 {
+					settings.RetryPolicy = withMaxAttempts(settings.RetryPolicy, attempts)
+				}
+			}
+		}
+
+		return settings
+	}
+}
+
+// withMaxAttempts возвращает копию политики с другим числом попыток
+// (политика может быть общей для нескольких шагов)
+func withMaxAttempts(policy *RetryPolicy, attempts int) *RetryPolicy {
+	if policy == nil {
+		return SimpleRetry(attempts)
+	}
+	copied := *policy
+	copied.MaxAttempts = attempts
+	return &copied
+}
+
+// envKey приводит имя к формату переменной окружения
+func envKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,135 @@
+package saga
+
+import "context"
+
+// StepInvocation вызов шага саги, передаваемый перехватчикам
+type StepInvocation struct {
+	SagaID   string
+	SagaName string
+	Step     SagaStep
+	// Attempt номер попытки выполнения (или компенсации) шага, начиная с 0
+	Attempt int
+	// Context контекст саги с правилами доступа шага
+	Context SagaContext
+}
+
+// StepInterceptor перехватчик выполнения шагов саги: аудит, feature flags, rate limiting,
+// проверки тенанта и т.д. без изменения реализаций SagaStep. Перехватчики регистрируются
+// в оркестраторе (DefaultOrchestrator.WithStepInterceptors) или определении саги
+// (BaseSagaDefinition.WithInterceptors); перехватчики оркестратора вызываются первыми.
+// Before вызывается в порядке регистрации, After, OnError и OnCompensate - в обратном.
+type StepInterceptor interface {
+	// Before вызывается перед каждой попыткой выполнения шага. Ошибка отменяет попытку и
+	// обрабатывается как ошибка шага (повторы по политике шага, затем компенсация).
+	Before(ctx context.Context, invocation StepInvocation) error
+	// After вызывается после успешной попытки выполнения шага
+	After(ctx context.Context, invocation StepInvocation)
+	// OnError вызывается после неуспешной попытки (в т.ч. отмененной в Before) и возвращает
+	// ошибку попытки: перехватчик может заменить ее (например, пометить как неповторяемую)
+	OnError(ctx context.Context, invocation StepInvocation, err error) error
+	// OnCompensate вызывается после каждой попытки компенсации шага с ее результатом
+	// (nil - компенсация выполнена) и возвращает итоговый результат попытки
+	OnCompensate(ctx context.Context, invocation StepInvocation, err error) error
+}
+
+// StepInterceptorProvider реализуется определениями саг с собственными перехватчиками шагов
+// (например, BaseSagaDefinition)
+type StepInterceptorProvider interface {
+	StepInterceptors() []StepInterceptor
+}
+
+// BaseStepInterceptor перехватчик без действий для встраивания: перехватчику
+// достаточно переопределить нужные методы
+type BaseStepInterceptor struct{}
+
+// Before не ограничивает выполнение шага
+func (BaseStepInterceptor) Before(ctx context.Context, invocation StepInvocation) error {
+	return nil
+}
+
+// After ничего не делает
+func (BaseStepInterceptor) After(ctx context.Context, invocation StepInvocation) {}
+
+// OnError возвращает ошибку шага без изменений
+func (BaseStepInterceptor) OnError(ctx context.Context, invocation StepInvocation, err error) error {
+	return err
+}
+
+// OnCompensate возвращает результат компенсации без изменений
+func (BaseStepInterceptor) OnCompensate(ctx context.Context, invocation StepInvocation, err error) error {
+	return err
+}
+
+// WithInterceptors добавляет перехватчики шагов определения саги
+func (d *BaseSagaDefinition) WithInterceptors(interceptors ...StepInterceptor) *BaseSagaDefinition {
+	d.interceptors = append(d.interceptors, interceptors...)
+	return d
+}
+
+// StepInterceptors возвращает перехватчики шагов определения
+func (d *BaseSagaDefinition) StepInterceptors() []StepInterceptor {
+	return d.interceptors
+}
+
+// stepInterceptors возвращает перехватчики оркестратора и определения саги
+func (s *BaseSaga) stepInterceptors() []StepInterceptor {
+	s.mu.RLock()
+	interceptors := s.interceptors
+	s.mu.RUnlock()
+
+	if provider, ok := s.definition.(StepInterceptorProvider); ok {
+		interceptors = append(interceptors[:len(interceptors):len(interceptors)], provider.StepInterceptors()...)
+	}
+	return interceptors
+}
+
+// newStepInvocation создает описание вызова шага для перехватчиков
+func (s *BaseSaga) newStepInvocation(step SagaStep, stepSagaCtx SagaContext, attempt int) StepInvocation {
+	return StepInvocation{
+		SagaID:   s.id,
+		SagaName: s.definition.Name(),
+		Step:     step,
+		Attempt:  attempt,
+		Context:  stepSagaCtx,
+	}
+}
+
+// interceptStep выполняет попытку шага через цепочку перехватчиков.
+// Ожидание подтверждения или сообщения (ErrApprovalPending, ErrMessagePending) не считается
+// ни успехом, ни ошибкой попытки.
+func interceptStep(ctx context.Context, interceptors []StepInterceptor, invocation StepInvocation, execute func() error) error {
+	if len(interceptors) == 0 {
+		return execute()
+	}
+
+	var err error
+	for _, interceptor := range interceptors {
+		if err = interceptor.Before(ctx, invocation); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = execute()
+	}
+
+	if isStepWaiting(err) {
+		return err
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if err == nil {
+			interceptors[i].After(ctx, invocation)
+		} else {
+			err = interceptors[i].OnError(ctx, invocation, err)
+		}
+	}
+	return err
+}
+
+// interceptCompensation выполняет попытку компенсации шага и передает результат перехватчикам
+func interceptCompensation(ctx context.Context, interceptors []StepInterceptor, invocation StepInvocation, compensate func() error) error {
+	err := compensate()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		err = interceptors[i].OnCompensate(ctx, invocation, err)
+	}
+	return err
+}
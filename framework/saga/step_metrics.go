@@ -0,0 +1,122 @@
+// Package saga предоставляет механизмы для работы с сагами.
+package saga
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// StepMetrics статистика выполнения шага по всем сагам, попавшим в фильтр метрик.
+// Учитываются завершенные выполнения шага (completed и failed), включая повторные попытки.
+type StepMetrics struct {
+	StepName string
+	// Executions число завершенных выполнений шага
+	Executions int
+	// Failures число выполнений, завершившихся ошибкой
+	Failures int
+	// FailureRate доля неуспешных выполнений в процентах
+	FailureRate float64
+	// P50Duration медиана длительности успешных выполнений
+	P50Duration time.Duration
+	// P95Duration 95-й перцентиль длительности успешных выполнений
+	P95Duration time.Duration
+	// AvgDuration средняя длительность успешных выполнений
+	AvgDuration time.Duration
+}
+
+// stepMetricsCollector накапливает выполнения шагов и вычисляет StepMetrics
+type stepMetricsCollector struct {
+	steps map[string]*stepSamples
+}
+
+// stepSamples выполнения одного шага
+type stepSamples struct {
+	executions int
+	failures   int
+	durations  []time.Duration
+}
+
+func newStepMetricsCollector() *stepMetricsCollector {
+	return &stepMetricsCollector{steps: make(map[string]*stepSamples)}
+}
+
+// add учитывает выполнение шага со статусом status; незавершенные выполнения пропускаются
+func (c *stepMetricsCollector) add(stepName, status string, duration *time.Duration) {
+	switch StepStatus(status) {
+	case StepStatusCompleted, StepStatusFailed:
+	default:
+		return
+	}
+
+	samples, ok := c.steps[stepName]
+	if !ok {
+		samples = &stepSamples{}
+		c.steps[stepName] = samples
+	}
+	samples.executions++
+	if StepStatus(status) == StepStatusFailed {
+		samples.failures++
+		return
+	}
+	if duration != nil {
+		samples.durations = append(samples.durations, *duration)
+	}
+}
+
+// result возвращает статистику шагов, отсортированную по имени шага
+func (c *stepMetricsCollector) result() []StepMetrics {
+	if len(c.steps) == 0 {
+		return nil
+	}
+
+	steps := make([]StepMetrics, 0, len(c.steps))
+	for name, samples := range c.steps {
+		sort.Slice(samples.durations, func(i, j int) bool {
+			return samples.durations[i] < samples.durations[j]
+		})
+
+		step := StepMetrics{
+			StepName:    name,
+			Executions:  samples.executions,
+			Failures:    samples.failures,
+			FailureRate: stepFailureRate(samples.executions, samples.failures),
+			P50Duration: durationPercentile(samples.durations, 0.5),
+			P95Duration: durationPercentile(samples.durations, 0.95),
+		}
+		if len(samples.durations) > 0 {
+			var total time.Duration
+			for _, duration := range samples.durations {
+				total += duration
+			}
+			step.AvgDuration = total / time.Duration(len(samples.durations))
+		}
+		steps = append(steps, step)
+	}
+
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].StepName < steps[j].StepName
+	})
+	return steps
+}
+
+// stepFailureRate возвращает долю неуспешных выполнений в процентах
+func stepFailureRate(executions, failures int) float64 {
+	if executions == 0 {
+		return 0
+	}
+	return float64(failures) / float64(executions) * 100
+}
+
+// durationPercentile возвращает перцентиль p отсортированных длительностей методом
+// ближайшего ранга (как percentile_disc в PostgreSQL)
+func durationPercentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -3,8 +3,12 @@ package saga
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/akriventsev/potter/framework/core"
 )
 
 func TestBaseStep_Execute(t *testing.T) {
@@ -184,6 +188,124 @@ func TestParallelStep(t *testing.T) {
 	}
 }
 
+func TestSagaContext_Update(t *testing.T) {
+	sagaCtx := NewSagaContext()
+	sagaCtx.Set("counter", 1)
+
+	err := sagaCtx.Update(func(view SagaContextView) error {
+		view.Set("counter", view.Get("counter").(int)+1)
+		view.Set("status", "updated")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if sagaCtx.GetInt("counter") != 2 || sagaCtx.GetString("status") != "updated" {
+		t.Errorf("Expected update to be applied, got %v", sagaCtx.ToMap())
+	}
+
+	// Ошибка внутри Update отбрасывает все изменения
+	err = sagaCtx.Update(func(view SagaContextView) error {
+		view.Set("counter", 100)
+		view.Delete("status")
+		return errors.New("validation failed")
+	})
+	if err == nil {
+		t.Fatal("Expected error from Update")
+	}
+	if sagaCtx.GetInt("counter") != 2 || sagaCtx.GetString("status") != "updated" {
+		t.Errorf("Expected update to be rolled back, got %v", sagaCtx.ToMap())
+	}
+}
+
+func TestParallelStep_ContextConflict(t *testing.T) {
+	writer := func(name string) *BaseStep {
+		step := NewBaseStep(name)
+		step.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+			return sagaCtx.Update(func(view SagaContextView) error {
+				view.Set(name, "done")
+				view.Set("reservation_id", name)
+				return nil
+			})
+		})
+		return step
+	}
+
+	parallelStep := NewParallelStep("parallel", writer("step1"), writer("step2"))
+
+	sagaCtx := NewSagaContext()
+	err := parallelStep.Execute(context.Background(), sagaCtx)
+	if !errors.Is(err, ErrContextConflict) {
+		t.Fatalf("Expected ErrContextConflict, got %v", err)
+	}
+
+	// Применились изменения только одного шага целиком
+	winner := sagaCtx.GetString("reservation_id")
+	if winner == "" || sagaCtx.GetString(winner) != "done" {
+		t.Errorf("Expected changes of one step, got %v", sagaCtx.ToMap())
+	}
+	loser := "step1"
+	if winner == "step1" {
+		loser = "step2"
+	}
+	if sagaCtx.Get(loser) != nil {
+		t.Errorf("Expected changes of conflicting step %s to be discarded", loser)
+	}
+
+	// Запись разных ключей конфликтом не является
+	step3 := NewBaseStep("step3")
+	step3.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		sagaCtx.Set("step3", "done")
+		return nil
+	})
+	if err := NewParallelStep("parallel", writer("step4"), step3).Execute(context.Background(), NewSagaContext()); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+}
+
+func TestSagaContext_ForStep(t *testing.T) {
+	reserve := NewBaseStep("reserve_stock")
+	reserve.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		return sagaCtx.ForStep("reserve_stock").Set("amount", 5)
+	})
+
+	charge := NewBaseStep("charge").WithReadAccess("reserve_stock")
+	charge.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		if err := sagaCtx.ForStep("reserve_stock").Set("amount", 10); !errors.Is(err, ErrStepContextAccess) {
+			return fmt.Errorf("expected ErrStepContextAccess, got %v", err)
+		}
+		amount := sagaCtx.ForStep("reserve_stock").GetInt("amount")
+		return sagaCtx.ForStep("charge").Set("amount", amount*100)
+	})
+
+	notify := NewBaseStep("notify")
+	notify.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		if sagaCtx.ForStep("charge").Get("amount") != nil {
+			return errors.New("expected no read access to charge namespace")
+		}
+		return nil
+	})
+
+	sagaCtx := NewSagaContext()
+	sagaCtx.Set("amount", "generic")
+	for _, step := range []*BaseStep{reserve, charge, notify} {
+		if err := step.Execute(context.Background(), newStepScopedContext(sagaCtx, step)); err != nil {
+			t.Fatalf("Step %s failed: %v", step.Name(), err)
+		}
+	}
+
+	if sagaCtx.GetString("amount") != "generic" {
+		t.Errorf("Expected generic key to be untouched, got %v", sagaCtx.Get("amount"))
+	}
+	data := StepContextData(sagaCtx)
+	if data["reserve_stock"]["amount"] != 5 || data["charge"]["amount"] != 500 {
+		t.Errorf("Unexpected step data: %v", data)
+	}
+	if amount := sagaCtx.ForStep("charge").GetInt("amount"); amount != 500 {
+		t.Errorf("Expected unrestricted access outside steps, got %d", amount)
+	}
+}
+
 func TestConditionalStep(t *testing.T) {
 	innerStep := NewBaseStep("inner")
 	innerStep.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
@@ -230,3 +352,161 @@ func (c *mockCommand) CommandName() string {
 	return c.name
 }
 
+
+func TestStep_PanicRecovered(t *testing.T) {
+	step := NewBaseStep("panicking")
+	step.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error {
+		var items map[string]int
+		items["reserved"]++
+		return nil
+	})
+	step.WithCompensate(func(ctx context.Context, sagaCtx SagaContext) error {
+		panic("compensation bug")
+	})
+
+	err := safeExecuteStep(context.Background(), step, NewSagaContext())
+	if !core.IsPanic(err) || core.StackTraceOf(err) == "" {
+		t.Errorf("Expected recovered panic with stack trace, got %v", err)
+	}
+	if err := safeCompensateStep(context.Background(), step, NewSagaContext()); !core.IsPanic(err) {
+		t.Errorf("Expected recovered compensation panic, got %v", err)
+	}
+
+	// Паника в ветке параллельного шага не завершает процесс
+	ok := NewBaseStep("ok")
+	ok.WithExecute(func(ctx context.Context, sagaCtx SagaContext) error { return nil })
+	err = NewParallelStep("parallel", step, ok).Execute(context.Background(), NewSagaContext())
+	if err == nil || !strings.Contains(err.Error(), "panic recovered") {
+		t.Errorf("Expected parallel step to fail with recovered panic, got %v", err)
+	}
+}
+
+func TestStep_Heartbeat(t *testing.T) {
+	ctx := context.Background()
+	newSaga := func(id string, execute func(ctx context.Context, sagaCtx SagaContext) error) (*BaseSaga, *mockEventBus) {
+		step := NewBaseStep("export")
+		step.WithExecute(execute).WithHeartbeatTimeout(50 * time.Millisecond)
+		definition := NewBaseSagaDefinition("heartbeat-saga")
+		definition.AddStep(step)
+
+		bus := &mockEventBus{}
+		saga, err := NewBaseSagaWithEventBus(id, definition, NewSagaContext(), NewInMemoryPersistence(), bus)
+		if err != nil {
+			t.Fatalf("Failed to create saga: %v", err)
+		}
+		return saga, bus
+	}
+
+	// Шаг дольше окна heartbeat завершается, пока сообщает о прогрессе
+	saga, bus := newSaga("heartbeat-ok", func(ctx context.Context, sagaCtx SagaContext) error {
+		for i := 1; i <= 4; i++ {
+			time.Sleep(20 * time.Millisecond)
+			if err := sagaCtx.ForStep("export").Heartbeat(float64(i) / 4); err != nil {
+				return err
+			}
+		}
+		if err := sagaCtx.ForStep("other").Heartbeat(1); !errors.Is(err, ErrStepContextAccess) {
+			return fmt.Errorf("expected heartbeat of other step to be denied, got %v", err)
+		}
+		return nil
+	})
+	if err := saga.Execute(ctx); err != nil {
+		t.Fatalf("Expected step with heartbeats to complete, got %v", err)
+	}
+	var heartbeats []*StepHeartbeatEvent
+	for _, event := range bus.events {
+		if e, ok := event.(*StepHeartbeatEvent); ok {
+			heartbeats = append(heartbeats, e)
+		}
+	}
+	if len(heartbeats) != 4 || heartbeats[3].Progress != 1 || heartbeats[3].StepName != "export" {
+		t.Errorf("Expected 4 heartbeat events, got %d", len(heartbeats))
+	}
+	if _, ok := saga.Context().Metadata().Custom[SagaHeartbeatKey]; !ok {
+		t.Error("Expected heartbeat recorded in saga metadata")
+	}
+
+	// Шаг без heartbeat прерывается и завершается ошибкой heartbeat timeout
+	saga, _ = newSaga("heartbeat-lost", func(ctx context.Context, sagaCtx SagaContext) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := saga.Execute(ctx); err == nil {
+		t.Fatal("Expected step without heartbeats to fail")
+	}
+	history := saga.GetHistory()
+	if len(history) == 0 || !errors.Is(history[0].Error, ErrStepHeartbeatTimeout) {
+		t.Fatalf("Expected ErrStepHeartbeatTimeout in step history, got %+v", history)
+	}
+	if history[0].Failure.Code != FailureCodeHeartbeatTimeout || history[0].Failure.Category != FailureCategoryTimeout {
+		t.Errorf("Unexpected failure classification: %+v", history[0].Failure)
+	}
+
+	// Read model хранит время heartbeat и прогресс текущего шага
+	store := NewInMemorySagaReadModelStore()
+	projection := NewSagaReadModelProjection(store)
+	if err := projection.HandleStepStarted(ctx, &StepStartedEvent{SagaID: "heartbeat-ok", StepName: "export", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("HandleStepStarted failed: %v", err)
+	}
+	if err := projection.HandleStepHeartbeat(ctx, heartbeats[1]); err != nil {
+		t.Fatalf("HandleStepHeartbeat failed: %v", err)
+	}
+	status, err := store.GetSagaStatus(ctx, "heartbeat-ok")
+	if err != nil {
+		t.Fatalf("GetSagaStatus failed: %v", err)
+	}
+	if status.StepProgress == nil || *status.StepProgress != 0.5 || status.LastHeartbeatAt == nil || status.CurrentStep != "export" {
+		t.Errorf("Expected heartbeat progress in read model, got %+v", status)
+	}
+}
+
+func TestExportDiagram(t *testing.T) {
+	noop := func(ctx context.Context, sagaCtx SagaContext) error { return nil }
+
+	reserve := NewBaseStep("reserve")
+	reserve.WithExecute(noop).WithCompensate(noop)
+	notify := NewBaseStep("notify")
+	notify.WithExecute(noop)
+	ship := NewBaseStep("ship")
+	ship.WithExecute(noop).WithCompensate(noop)
+
+	definition := NewBaseSagaDefinition("order").WithVersion(2)
+	definition.AddStep(NewParallelStep("prepare", reserve, notify))
+	definition.AddStep(NewConditionalStep("needs_shipping", func(ctx context.Context, sagaCtx SagaContext) bool {
+		return true
+	}, ship))
+
+	mermaid, err := definition.ExportDiagram(DiagramFormatMermaid)
+	if err != nil {
+		t.Fatalf("Failed to export mermaid diagram: %v", err)
+	}
+	for _, expected := range []string{
+		"%% saga order v2",
+		"flowchart TD",
+		`{{"prepare"}}`,
+		`{"needs_shipping?"}`,
+		"-.->|compensate|",
+		"-->|yes|",
+		"-->|no|",
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("Expected mermaid diagram to contain %q, got:\n%s", expected, mermaid)
+		}
+	}
+	// Компенсации есть только у reserve и ship
+	if count := strings.Count(mermaid, "-.->"); count != 2 {
+		t.Errorf("Expected 2 compensation edges, got %d:\n%s", count, mermaid)
+	}
+
+	dot, err := ExportDiagram(definition, DiagramFormatDOT)
+	if err != nil {
+		t.Fatalf("Failed to export dot diagram: %v", err)
+	}
+	if !strings.HasPrefix(dot, `digraph "order v2" {`) || !strings.Contains(dot, "shape=diamond") || !strings.Contains(dot, "style=dashed") {
+		t.Errorf("Unexpected dot diagram:\n%s", dot)
+	}
+
+	if _, err := ExportDiagram(definition, DiagramFormat("svg")); !errors.Is(err, ErrUnsupportedDiagramFormat) {
+		t.Errorf("Expected ErrUnsupportedDiagramFormat, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Package saga предоставляет поддержку оберток шагов саги (трассировка, логирование и т.д.).
+package saga
+
+// StepUnwrapper реализуется обертками шагов (например, шагами со spans из observability).
+// Оркестратор ищет необязательные интерфейсы шага (BranchingStep, DeadlineStep, HeartbeatStep,
+// StepDependencies, StepContextReader, StepCompensationRetry и т.д.) по всей цепочке Unwrap,
+// поэтому обертке достаточно переопределить Execute и Compensate.
+type StepUnwrapper interface {
+	// Unwrap возвращает обернутый шаг
+	Unwrap() SagaStep
+}
+
+// UnwrapStep возвращает исходный шаг, снимая все обертки StepUnwrapper
+func UnwrapStep(step SagaStep) SagaStep {
+	for {
+		wrapper, ok := step.(StepUnwrapper)
+		if !ok {
+			return step
+		}
+		inner := wrapper.Unwrap()
+		if inner == nil {
+			return step
+		}
+		step = inner
+	}
+}
+
+// stepAs ищет в цепочке оберток шага первый шаг, реализующий T
+func stepAs[T any](step SagaStep) (T, bool) {
+	for step != nil {
+		if target, ok := step.(T); ok {
+			return target, true
+		}
+		wrapper, ok := step.(StepUnwrapper)
+		if !ok {
+			break
+		}
+		step = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// WrapSteps возвращает копию определения со всеми настройками (версия, порядок и стратегия
+// компенсации, SLA, настройки шагов по умолчанию, хуки переопределения, перехватчики),
+// шаги которой обернуты wrap. Исходное определение не изменяется.
+func (d *BaseSagaDefinition) WrapSteps(wrap func(step SagaStep) SagaStep) *BaseSagaDefinition {
+	wrapped := *d
+	wrapped.steps = make([]SagaStep, 0, len(d.steps))
+	for _, step := range d.steps {
+		wrapped.steps = append(wrapped.steps, wrap(step))
+	}
+	wrapped.overrides = append([]StepSettingsOverride(nil), d.overrides...)
+	wrapped.interceptors = append([]StepInterceptor(nil), d.interceptors...)
+	return &wrapped
+}
+
+// WrapDefinitionSteps возвращает BaseSagaDefinition с шагами определения, обернутыми wrap.
+// Для *BaseSagaDefinition используется WrapSteps; для других определений переносятся
+// настройки, доступные через необязательные интерфейсы (версия, порядок и стратегия
+// компенсации, SLA, StepSettingsResolver и StepInterceptorProvider).
+func WrapDefinitionSteps(definition SagaDefinition, wrap func(step SagaStep) SagaStep) *BaseSagaDefinition {
+	if base, ok := definition.(*BaseSagaDefinition); ok {
+		return base.WrapSteps(wrap)
+	}
+
+	wrapped := NewBaseSagaDefinition(definition.Name()).
+		WithVersion(SagaDefinitionVersion(definition)).
+		WithCompensationOrder(SagaCompensationOrder(definition)).
+		WithSLA(DefinitionSLA(definition))
+	if configured, ok := definition.(CompensationStrategyDefinition); ok {
+		wrapped.WithCompensationStrategy(configured.CompensationStrategy())
+	}
+	if provider, ok := definition.(StepInterceptorProvider); ok {
+		wrapped.WithInterceptors(provider.StepInterceptors()...)
+	}
+
+	steps := make(map[string]SagaStep)
+	for _, step := range definition.Steps() {
+		steps[step.Name()] = step
+		wrapped.AddStep(wrap(step))
+	}
+	if resolver, ok := definition.(StepSettingsResolver); ok {
+		// Настройки шагов вычисляются исходным определением по исходному шагу
+		wrapped.WithStepOverride(func(stepName string, settings StepSettings) StepSettings {
+			if step, ok := steps[stepName]; ok {
+				return resolver.StepSettings(step)
+			}
+			return settings
+		})
+	}
+	return wrapped
+}
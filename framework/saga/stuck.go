@@ -0,0 +1,307 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akriventsev/potter/framework/core"
+	"github.com/akriventsev/potter/framework/events"
+)
+
+// SagaHeartbeatKey ключ кастомных метаданных саги с временем последнего heartbeat шага (RFC 3339)
+const SagaHeartbeatKey = "heartbeat_at"
+
+// stuckSagaStatuses статусы, в которых сага должна продвигаться без внешнего ввода.
+// Саги, ожидающие подтверждения или сообщения, не считаются зависшими.
+var stuckSagaStatuses = []SagaStatus{SagaStatusPending, SagaStatusRunning, SagaStatusCompensating}
+
+// Heartbeat отмечает прогресс долгого шага: монитор зависших саг отсчитывает окно
+// от последнего heartbeat, а не от завершения предыдущего шага
+func Heartbeat(sagaCtx SagaContext) {
+	sagaCtx.SetCustomValue(SagaHeartbeatKey, time.Now().UTC().Format(time.RFC3339Nano))
+}
+
+// LastProgressAt возвращает время последнего прогресса саги: завершения шага (в том числе
+// неуспешного или компенсации), heartbeat шага или создания саги
+func LastProgressAt(saga Saga) time.Time {
+	metadata := saga.Context().Metadata()
+	last := metadata.CreatedAt
+	for _, entry := range saga.GetHistory() {
+		if entry.CompletedAt != nil && entry.CompletedAt.After(last) {
+			last = *entry.CompletedAt
+		}
+	}
+	if heartbeat, ok := metadata.Custom[SagaHeartbeatKey].(string); ok {
+		if at, err := time.Parse(time.RFC3339Nano, heartbeat); err == nil && at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// StuckSaga сага без прогресса дольше заданного окна
+type StuckSaga struct {
+	SagaID         string
+	DefinitionName string
+	Status         SagaStatus
+	CurrentStep    string
+	LastProgressAt time.Time
+	// StuckFor время без прогресса на момент проверки
+	StuckFor time.Duration
+	// Local сага выполняется в этом оркестраторе; иначе состояние прочитано из persistence
+	// и может отставать от экземпляра, выполняющего сагу
+	Local bool
+}
+
+// ListStuck возвращает саги в статусах pending, running и compensating, у которых не было
+// прогресса (см. LastProgressAt) дольше olderThan, в порядке давности прогресса.
+// Саги, выполняющиеся в этом оркестраторе, проверяются по текущему состоянию, остальные -
+// по сохраненному в persistence.
+func (o *DefaultOrchestrator) ListStuck(ctx context.Context, olderThan time.Duration) ([]StuckSaga, error) {
+	now := time.Now()
+	seen := make(map[string]bool)
+	var stuck []StuckSaga
+
+	check := func(saga Saga, local bool) {
+		if seen[saga.ID()] {
+			return
+		}
+		seen[saga.ID()] = true
+		if !isStuckCandidate(saga.Status()) {
+			return
+		}
+		last := LastProgressAt(saga)
+		if now.Sub(last) < olderThan {
+			return
+		}
+		stuck = append(stuck, StuckSaga{
+			SagaID:         saga.ID(),
+			DefinitionName: saga.Definition().Name(),
+			Status:         saga.Status(),
+			CurrentStep:    saga.CurrentStep(),
+			LastProgressAt: last,
+			StuckFor:       now.Sub(last),
+			Local:          local,
+		})
+	}
+
+	o.mu.RLock()
+	running := make([]Saga, 0, len(o.runningSagas))
+	for _, run := range o.runningSagas {
+		if run.saga != nil {
+			running = append(running, run.saga)
+		}
+	}
+	o.mu.RUnlock()
+	for _, saga := range running {
+		check(saga, true)
+	}
+
+	for _, status := range stuckSagaStatuses {
+		sagas, err := o.persistence.LoadAll(ctx, status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s sagas: %w", status, err)
+		}
+		for _, saga := range sagas {
+			check(saga, false)
+		}
+	}
+
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].LastProgressAt.Before(stuck[j].LastProgressAt)
+	})
+	return stuck, nil
+}
+
+// isStuckCandidate проверяет, может ли сага в статусе status считаться зависшей
+func isStuckCandidate(status SagaStatus) bool {
+	for _, candidate := range stuckSagaStatuses {
+		if status == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// StuckSagaMonitorConfig конфигурация StuckSagaMonitor
+type StuckSagaMonitorConfig struct {
+	// Window время без прогресса, после которого сага считается зависшей
+	Window time.Duration
+	// CheckInterval интервал проверки
+	CheckInterval time.Duration
+}
+
+// DefaultStuckSagaMonitorConfig возвращает конфигурацию монитора по умолчанию
+func DefaultStuckSagaMonitorConfig() StuckSagaMonitorConfig {
+	return StuckSagaMonitorConfig{
+		Window:        15 * time.Minute,
+		CheckInterval: time.Minute,
+	}
+}
+
+// StuckSagaMonitor периодически ищет зависшие саги (DefaultOrchestrator.ListStuck),
+// публикует SagaStuckEvent и метрику saga.stuck и вызывает обработчики OnStuck
+// (алертинг, автоматическое восстановление). О зависании сообщается один раз, пока
+// у саги не появится новый прогресс (реализация core.Lifecycle).
+type StuckSagaMonitor struct {
+	orchestrator *DefaultOrchestrator
+	config       StuckSagaMonitorConfig
+	handlers     []func(ctx context.Context, stuck StuckSaga)
+
+	mu       sync.Mutex
+	reported map[string]time.Time
+	running  bool
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewStuckSagaMonitor создает монитор зависших саг оркестратора
+func NewStuckSagaMonitor(orchestrator *DefaultOrchestrator, config StuckSagaMonitorConfig) *StuckSagaMonitor {
+	return &StuckSagaMonitor{
+		orchestrator: orchestrator,
+		config:       config,
+		reported:     make(map[string]time.Time),
+	}
+}
+
+// OnStuck добавляет обработчик зависшей саги
+func (m *StuckSagaMonitor) OnStuck(handler func(ctx context.Context, stuck StuckSaga)) *StuckSagaMonitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+	return m
+}
+
+// Check выполняет одну проверку и возвращает саги, о зависании которых сообщено впервые.
+// Вызывается по таймеру, но может использоваться и напрямую (например, из cron job).
+func (m *StuckSagaMonitor) Check(ctx context.Context) ([]StuckSaga, error) {
+	stuck, err := m.orchestrator.ListStuck(ctx, m.config.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	current := make(map[string]time.Time, len(stuck))
+	reported := make([]StuckSaga, 0)
+	for _, s := range stuck {
+		current[s.SagaID] = s.LastProgressAt
+		if last, ok := m.reported[s.SagaID]; ok && last.Equal(s.LastProgressAt) {
+			continue
+		}
+		reported = append(reported, s)
+	}
+	// Саги с новым прогрессом или завершенные снова могут быть отмечены как зависшие
+	m.reported = current
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	for _, s := range reported {
+		m.orchestrator.reportStuck(ctx, s, m.config.Window)
+		for _, handler := range handlers {
+			handler := handler
+			_ = core.SafeCall(func() error {
+				handler(ctx, s)
+				return nil
+			})
+		}
+	}
+	return reported, nil
+}
+
+// Start запускает периодическую проверку (реализация core.Lifecycle)
+func (m *StuckSagaMonitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return nil
+	}
+	m.running = true
+	m.stopCh = make(chan struct{})
+	m.doneCh = make(chan struct{})
+
+	go m.loop(m.stopCh, m.doneCh)
+	return nil
+}
+
+// Stop останавливает проверку (реализация core.Lifecycle)
+func (m *StuckSagaMonitor) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	m.running = false
+	close(m.stopCh)
+	doneCh := m.doneCh
+	m.mu.Unlock()
+
+	select {
+	case <-doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRunning проверяет, запущен ли монитор (реализация core.Lifecycle)
+func (m *StuckSagaMonitor) IsRunning() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+// loop периодически проверяет зависшие саги
+func (m *StuckSagaMonitor) loop(stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer close(doneCh)
+
+	interval := m.config.CheckInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			// Ошибка persistence не останавливает монитор: саги будут проверены на следующем тике
+			_, _ = m.Check(ctx)
+		}
+	}
+}
+
+// reportStuck публикует событие и метрику зависшей саги
+func (o *DefaultOrchestrator) reportStuck(ctx context.Context, stuck StuckSaga, window time.Duration) {
+	if o.metrics != nil {
+		o.metrics.RecordEvent(ctx, "saga.stuck")
+	}
+	if o.eventBus == nil {
+		return
+	}
+
+	stuckEvent := &SagaStuckEvent{
+		BaseEvent:      events.NewBaseEvent("SagaStuck", stuck.SagaID),
+		SagaID:         stuck.SagaID,
+		DefinitionName: stuck.DefinitionName,
+		Status:         stuck.Status,
+		CurrentStep:    stuck.CurrentStep,
+		LastProgressAt: stuck.LastProgressAt,
+		StuckFor:       stuck.StuckFor,
+		Window:         window,
+		Timestamp:      time.Now(),
+	}
+	_ = o.eventBus.Publish(ctx, stuckEvent)
+}
@@ -0,0 +1,361 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// MessageKind вид сообщения контракта
+type MessageKind string
+
+const (
+	// MessageKindEvent доменное событие
+	MessageKindEvent MessageKind = "event"
+	// MessageKindCommand команда
+	MessageKindCommand MessageKind = "command"
+)
+
+// FieldType JSON-тип поля сообщения
+type FieldType string
+
+// JSON-типы полей сообщения
+const (
+	FieldString  FieldType = "string"
+	FieldNumber  FieldType = "number"
+	FieldBoolean FieldType = "boolean"
+	FieldObject  FieldType = "object"
+	FieldArray   FieldType = "array"
+	FieldNull    FieldType = "null"
+)
+
+// MessageSchema схема payload сообщения: JSON-типы полей по путям.
+// Вложенные поля записываются через точку (customer.id), элементы массивов - через [] (items[].sku).
+type MessageSchema struct {
+	Kind   MessageKind          `json:"kind"`
+	Type   string               `json:"type"`
+	Fields map[string]FieldType `json:"fields"`
+}
+
+// SchemaOf строит схему сообщения по его JSON-представлению
+func SchemaOf(kind MessageKind, messageType string, payload interface{}) (MessageSchema, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return MessageSchema{}, fmt.Errorf("failed to marshal %s %s: %w", kind, messageType, err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return MessageSchema{}, fmt.Errorf("failed to unmarshal %s %s: %w", kind, messageType, err)
+	}
+
+	schema := MessageSchema{Kind: kind, Type: messageType, Fields: make(map[string]FieldType)}
+	collectFields(schema.Fields, "", value)
+	return schema, nil
+}
+
+func collectFields(fields map[string]FieldType, path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if path != "" {
+			mergeField(fields, path, FieldObject)
+			path += "."
+		}
+		for key, item := range v {
+			collectFields(fields, path+key, item)
+		}
+	case []interface{}:
+		mergeField(fields, path, FieldArray)
+		for _, item := range v {
+			collectFields(fields, path+"[]", item)
+		}
+	case string:
+		mergeField(fields, path, FieldString)
+	case float64:
+		mergeField(fields, path, FieldNumber)
+	case bool:
+		mergeField(fields, path, FieldBoolean)
+	case nil:
+		mergeField(fields, path, FieldNull)
+	}
+}
+
+// mergeField добавляет тип поля; null не заменяет известный тип (опциональное поле)
+func mergeField(fields map[string]FieldType, path string, fieldType FieldType) {
+	if path == "" {
+		return
+	}
+	if existing, ok := fields[path]; ok && fieldType == FieldNull && existing != FieldNull {
+		return
+	}
+	fields[path] = fieldType
+}
+
+// RecordedMessage сообщение, опубликованное сервисом в тесте
+type RecordedMessage struct {
+	Kind    MessageKind
+	Type    string
+	Payload interface{}
+}
+
+// ContractRecorder записывает события и команды, которые сервис публикует в тестах,
+// для публикации их схем в реестр (provider) и проверки контрактов потребителей
+type ContractRecorder struct {
+	mu       sync.Mutex
+	messages []RecordedMessage
+}
+
+// NewContractRecorder создает новый ContractRecorder
+func NewContractRecorder() *ContractRecorder {
+	return &ContractRecorder{}
+}
+
+// RecordEvent записывает событие
+func (r *ContractRecorder) RecordEvent(event events.Event) {
+	r.record(RecordedMessage{Kind: MessageKindEvent, Type: event.EventType(), Payload: event})
+}
+
+// RecordCommand записывает команду
+func (r *ContractRecorder) RecordCommand(cmd transport.Command) {
+	r.record(RecordedMessage{Kind: MessageKindCommand, Type: cmd.CommandName(), Payload: cmd})
+}
+
+func (r *ContractRecorder) record(message RecordedMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+}
+
+// Messages возвращает записанные сообщения
+func (r *ContractRecorder) Messages() []RecordedMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedMessage(nil), r.messages...)
+}
+
+// Schemas возвращает схемы записанных сообщений, объединенные по виду и типу сообщения
+func (r *ContractRecorder) Schemas() ([]MessageSchema, error) {
+	merged := make(map[string]*MessageSchema)
+	var keys []string
+	for _, message := range r.Messages() {
+		schema, err := SchemaOf(message.Kind, message.Type, message.Payload)
+		if err != nil {
+			return nil, err
+		}
+		key := string(message.Kind) + ":" + message.Type
+		existing, ok := merged[key]
+		if !ok {
+			merged[key] = &schema
+			keys = append(keys, key)
+			continue
+		}
+		for path, fieldType := range schema.Fields {
+			mergeField(existing.Fields, path, fieldType)
+		}
+	}
+
+	sort.Strings(keys)
+	result := make([]MessageSchema, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *merged[key])
+	}
+	return result, nil
+}
+
+// EventPublisher возвращает EventPublisher, записывающий события перед публикацией в next
+// (next может быть nil - события только записываются)
+func (r *ContractRecorder) EventPublisher(next events.EventPublisher) events.EventPublisher {
+	return &recordingEventPublisher{recorder: r, next: next}
+}
+
+// CommandBus возвращает CommandBus, записывающий команды перед отправкой в next
+func (r *ContractRecorder) CommandBus(next transport.CommandBus) transport.CommandBus {
+	return &recordingCommandBus{recorder: r, next: next}
+}
+
+type recordingEventPublisher struct {
+	recorder *ContractRecorder
+	next     events.EventPublisher
+}
+
+func (p *recordingEventPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.recorder.RecordEvent(event)
+	if p.next == nil {
+		return nil
+	}
+	return p.next.Publish(ctx, event)
+}
+
+type recordingCommandBus struct {
+	recorder *ContractRecorder
+	next     transport.CommandBus
+}
+
+func (b *recordingCommandBus) Send(ctx context.Context, cmd transport.Command) error {
+	b.recorder.RecordCommand(cmd)
+	return b.next.Send(ctx, cmd)
+}
+
+func (b *recordingCommandBus) Register(handler transport.CommandHandler) error {
+	return b.next.Register(handler)
+}
+
+// MessageExpectation ожидание потребителя: сообщение с полями указанных типов
+type MessageExpectation struct {
+	Kind   MessageKind          `json:"kind"`
+	Type   string               `json:"type"`
+	Fields map[string]FieldType `json:"fields"`
+}
+
+// ConsumerContract контракт потребителя (Pact-style): какие события и команды провайдера
+// и какие поля в них использует потребитель
+type ConsumerContract struct {
+	Consumer     string               `json:"consumer"`
+	Provider     string               `json:"provider"`
+	Expectations []MessageExpectation `json:"expectations"`
+}
+
+// NewConsumerContract создает контракт потребителя consumer с провайдером provider
+func NewConsumerContract(consumer, provider string) *ConsumerContract {
+	return &ConsumerContract{
+		Consumer: consumer,
+		Provider: provider,
+	}
+}
+
+// ExpectEvent добавляет ожидание события eventType с указанными полями
+func (c *ConsumerContract) ExpectEvent(eventType string, fields map[string]FieldType) *ConsumerContract {
+	c.Expectations = append(c.Expectations, MessageExpectation{Kind: MessageKindEvent, Type: eventType, Fields: fields})
+	return c
+}
+
+// ExpectCommand добавляет ожидание команды commandName с указанными полями
+func (c *ConsumerContract) ExpectCommand(commandName string, fields map[string]FieldType) *ConsumerContract {
+	c.Expectations = append(c.Expectations, MessageExpectation{Kind: MessageKindCommand, Type: commandName, Fields: fields})
+	return c
+}
+
+// ContractViolation нарушение контракта потребителя
+type ContractViolation struct {
+	Consumer string
+	Kind     MessageKind
+	Type     string
+	Field    string
+	Message  string
+}
+
+func (v ContractViolation) String() string {
+	if v.Field == "" {
+		return fmt.Sprintf("%s expects %s %s: %s", v.Consumer, v.Kind, v.Type, v.Message)
+	}
+	return fmt.Sprintf("%s expects %s %s field %s: %s", v.Consumer, v.Kind, v.Type, v.Field, v.Message)
+}
+
+// Verify проверяет ожидания контракта по схемам сообщений провайдера.
+// Поле со значением null в схеме провайдера совместимо с любым ожидаемым типом.
+func (c *ConsumerContract) Verify(schemas []MessageSchema) []ContractViolation {
+	var violations []ContractViolation
+	for _, expectation := range c.Expectations {
+		schema, found := findSchema(schemas, expectation.Kind, expectation.Type)
+		if !found {
+			violations = append(violations, ContractViolation{
+				Consumer: c.Consumer,
+				Kind:     expectation.Kind,
+				Type:     expectation.Type,
+				Message:  "message is not published by " + c.Provider,
+			})
+			continue
+		}
+
+		paths := make([]string, 0, len(expectation.Fields))
+		for path := range expectation.Fields {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			expected := expectation.Fields[path]
+			actual, ok := schema.Fields[path]
+			switch {
+			case !ok:
+				violations = append(violations, ContractViolation{
+					Consumer: c.Consumer, Kind: expectation.Kind, Type: expectation.Type, Field: path,
+					Message: "field is missing",
+				})
+			case actual != expected && actual != FieldNull:
+				violations = append(violations, ContractViolation{
+					Consumer: c.Consumer, Kind: expectation.Kind, Type: expectation.Type, Field: path,
+					Message: fmt.Sprintf("expected %s, got %s", expected, actual),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+func findSchema(schemas []MessageSchema, kind MessageKind, messageType string) (MessageSchema, bool) {
+	for _, schema := range schemas {
+		if schema.Kind == kind && schema.Type == messageType {
+			return schema, true
+		}
+	}
+	return MessageSchema{}, false
+}
+
+// PublishSchemas сохраняет в реестр схемы сообщений, записанных recorder в тестах провайдера
+func PublishSchemas(t *testing.T, registry ContractRegistry, provider string, recorder *ContractRecorder) {
+	t.Helper()
+
+	schemas, err := recorder.Schemas()
+	if err != nil {
+		t.Fatalf("failed to build message schemas: %v", err)
+	}
+	for _, schema := range schemas {
+		if err := registry.SaveSchema(provider, schema); err != nil {
+			t.Fatalf("failed to save schema of %s %s: %v", schema.Kind, schema.Type, err)
+		}
+	}
+}
+
+// VerifyConsumerContract проверяет контракт потребителя по схемам провайдера из реестра
+// и сохраняет контракт в реестр для проверки на стороне провайдера
+func VerifyConsumerContract(t *testing.T, registry ContractRegistry, contract *ConsumerContract) {
+	t.Helper()
+
+	schemas, err := registry.ListSchemas(contract.Provider)
+	if err != nil {
+		t.Fatalf("failed to load schemas of %s: %v", contract.Provider, err)
+	}
+	for _, violation := range contract.Verify(schemas) {
+		t.Errorf("contract violation: %s", violation)
+	}
+	if err := registry.SaveContract(*contract); err != nil {
+		t.Fatalf("failed to save contract %s -> %s: %v", contract.Consumer, contract.Provider, err)
+	}
+}
+
+// VerifyProviderContracts проверяет контракты всех потребителей провайдера из реестра
+// по сообщениям, записанным recorder. Ломающее изменение payload (удаление или смена типа
+// поля, используемого потребителем) приводит к ошибке теста.
+func VerifyProviderContracts(t *testing.T, registry ContractRegistry, provider string, recorder *ContractRecorder) {
+	t.Helper()
+
+	schemas, err := recorder.Schemas()
+	if err != nil {
+		t.Fatalf("failed to build message schemas: %v", err)
+	}
+	contracts, err := registry.ListContracts(provider)
+	if err != nil {
+		t.Fatalf("failed to load contracts of %s: %v", provider, err)
+	}
+	for _, contract := range contracts {
+		for _, violation := range contract.Verify(schemas) {
+			t.Errorf("contract violation: %s", violation)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ContractRegistry реестр схем сообщений провайдеров и контрактов потребителей для
+// контрактного тестирования (не путать с events.SchemaRegistry схем событий)
+type ContractRegistry interface {
+	// SaveSchema сохраняет схему сообщения провайдера
+	SaveSchema(provider string, schema MessageSchema) error
+	// ListSchemas возвращает схемы сообщений провайдера
+	ListSchemas(provider string) ([]MessageSchema, error)
+	// SaveContract сохраняет контракт потребителя
+	SaveContract(contract ConsumerContract) error
+	// ListContracts возвращает контракты потребителей провайдера
+	ListContracts(provider string) ([]ConsumerContract, error)
+}
+
+// FileContractRegistry реестр в директории, которую можно хранить в репозитории
+// или передавать между CI-пайплайнами сервисов:
+//
+//	<dir>/<provider>/schemas/<kind>.<type>.json
+//	<dir>/<provider>/contracts/<consumer>.json
+type FileContractRegistry struct {
+	dir string
+}
+
+// NewFileContractRegistry создает реестр в директории dir
+func NewFileContractRegistry(dir string) *FileContractRegistry {
+	return &FileContractRegistry{dir: dir}
+}
+
+// SaveSchema сохраняет схему сообщения провайдера
+func (r *FileContractRegistry) SaveSchema(provider string, schema MessageSchema) error {
+	name := fmt.Sprintf("%s.%s.json", schema.Kind, schema.Type)
+	return r.writeJSON(filepath.Join(r.dir, provider, "schemas", name), schema)
+}
+
+// ListSchemas возвращает схемы сообщений провайдера
+func (r *FileContractRegistry) ListSchemas(provider string) ([]MessageSchema, error) {
+	var schemas []MessageSchema
+	err := r.readDir(filepath.Join(r.dir, provider, "schemas"), func(data []byte) error {
+		var schema MessageSchema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return err
+		}
+		schemas = append(schemas, schema)
+		return nil
+	})
+	return schemas, err
+}
+
+// SaveContract сохраняет контракт потребителя
+func (r *FileContractRegistry) SaveContract(contract ConsumerContract) error {
+	return r.writeJSON(filepath.Join(r.dir, contract.Provider, "contracts", contract.Consumer+".json"), contract)
+}
+
+// ListContracts возвращает контракты потребителей провайдера
+func (r *FileContractRegistry) ListContracts(provider string) ([]ConsumerContract, error) {
+	var contracts []ConsumerContract
+	err := r.readDir(filepath.Join(r.dir, provider, "contracts"), func(data []byte) error {
+		var contract ConsumerContract
+		if err := json.Unmarshal(data, &contract); err != nil {
+			return err
+		}
+		contracts = append(contracts, contract)
+		return nil
+	})
+	return contracts, err
+}
+
+func (r *FileContractRegistry) writeJSON(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// readDir читает JSON файлы директории в порядке имен; отсутствующая директория - пустой реестр
+func (r *FileContractRegistry) readDir(dir string, decode func(data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if err := decode(data); err != nil {
+			return fmt.Errorf("invalid registry file %s: %w", filepath.Join(dir, name), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package testing
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/akriventsev/potter/framework/events"
+)
+
+type orderCreated struct {
+	*events.BaseEvent
+	OrderID  string      `json:"order_id"`
+	Amount   float64     `json:"amount"`
+	Paid     bool        `json:"paid"`
+	Coupon   *string     `json:"coupon"`
+	Customer customer    `json:"customer"`
+	Items    []orderItem `json:"items"`
+}
+
+type customer struct {
+	ID string `json:"id"`
+}
+
+type orderItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+func newOrderCreated(coupon *string) *orderCreated {
+	return &orderCreated{
+		BaseEvent: events.NewBaseEvent("OrderCreated", "order-1"),
+		OrderID:   "order-1",
+		Amount:    100,
+		Coupon:    coupon,
+		Customer:  customer{ID: "customer-1"},
+		Items:     []orderItem{{SKU: "sku-1", Qty: 2}},
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema, err := SchemaOf(MessageKindEvent, "OrderCreated", map[string]interface{}{
+		"order_id": "order-1",
+		"amount":   100,
+		"paid":     false,
+		"coupon":   nil,
+		"customer": map[string]interface{}{"id": "customer-1"},
+		"items":    []interface{}{map[string]interface{}{"sku": "sku-1"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]FieldType{
+		"order_id":    FieldString,
+		"amount":      FieldNumber,
+		"paid":        FieldBoolean,
+		"coupon":      FieldNull,
+		"customer":    FieldObject,
+		"customer.id": FieldString,
+		"items":       FieldArray,
+		"items[]":     FieldObject,
+		"items[].sku": FieldString,
+	}
+	if !reflect.DeepEqual(schema.Fields, expected) {
+		t.Errorf("Unexpected schema fields: %v", schema.Fields)
+	}
+
+	if _, err := SchemaOf(MessageKindEvent, "Broken", map[string]interface{}{"ch": make(chan int)}); err == nil {
+		t.Error("Expected error for payload that cannot be marshaled")
+	}
+}
+
+func TestContractRecorder_SchemasMergeOptionalFields(t *testing.T) {
+	recorder := NewContractRecorder()
+	publisher := recorder.EventPublisher(nil)
+
+	coupon := "SALE"
+	if err := publisher.Publish(context.Background(), newOrderCreated(nil)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := publisher.Publish(context.Background(), newOrderCreated(&coupon)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	schemas, err := recorder.Schemas()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(schemas) != 1 || schemas[0].Type != "OrderCreated" || schemas[0].Kind != MessageKindEvent {
+		t.Fatalf("Expected one merged OrderCreated schema, got %+v", schemas)
+	}
+	// null из первого события уточняется типом из второго
+	if got := schemas[0].Fields["coupon"]; got != FieldString {
+		t.Errorf("Expected coupon to be string, got %s", got)
+	}
+	if got := schemas[0].Fields["items[].qty"]; got != FieldNumber {
+		t.Errorf("Expected items[].qty to be number, got %s", got)
+	}
+}
+
+func TestConsumerContract_Verify(t *testing.T) {
+	schemas := []MessageSchema{{
+		Kind: MessageKindEvent,
+		Type: "OrderCreated",
+		Fields: map[string]FieldType{
+			"order_id": FieldString,
+			"amount":   FieldNumber,
+			"coupon":   FieldNull,
+		},
+	}}
+
+	compatible := NewConsumerContract("billing", "orders").
+		ExpectEvent("OrderCreated", map[string]FieldType{
+			"order_id": FieldString,
+			"coupon":   FieldString,
+		})
+	if violations := compatible.Verify(schemas); len(violations) != 0 {
+		t.Errorf("Expected compatible contract, got %v", violations)
+	}
+
+	broken := NewConsumerContract("billing", "orders").
+		ExpectEvent("OrderCreated", map[string]FieldType{
+			"amount":   FieldString,
+			"currency": FieldString,
+		}).
+		ExpectCommand("ShipOrder", map[string]FieldType{"order_id": FieldString})
+	violations := broken.Verify(schemas)
+	if len(violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %v", violations)
+	}
+	messages := []string{violations[0].String(), violations[1].String(), violations[2].String()}
+	for i, want := range []string{
+		"billing expects event OrderCreated field amount: expected string, got number",
+		"billing expects event OrderCreated field currency: field is missing",
+		"billing expects command ShipOrder: message is not published by orders",
+	} {
+		if messages[i] != want {
+			t.Errorf("Expected violation %q, got %q", want, messages[i])
+		}
+	}
+}
+
+func TestFileContractRegistry(t *testing.T) {
+	registry := NewFileContractRegistry(t.TempDir())
+
+	schemas, err := registry.ListSchemas("orders")
+	if err != nil || len(schemas) != 0 {
+		t.Fatalf("Expected empty registry, got %v (%v)", schemas, err)
+	}
+
+	for _, schema := range []MessageSchema{
+		{Kind: MessageKindEvent, Type: "OrderShipped", Fields: map[string]FieldType{"order_id": FieldString}},
+		{Kind: MessageKindEvent, Type: "OrderCreated", Fields: map[string]FieldType{"amount": FieldNumber}},
+	} {
+		if err := registry.SaveSchema("orders", schema); err != nil {
+			t.Fatalf("Failed to save schema: %v", err)
+		}
+	}
+	contract := NewConsumerContract("billing", "orders").
+		ExpectEvent("OrderCreated", map[string]FieldType{"amount": FieldNumber})
+	if err := registry.SaveContract(*contract); err != nil {
+		t.Fatalf("Failed to save contract: %v", err)
+	}
+
+	schemas, err = registry.ListSchemas("orders")
+	if err != nil {
+		t.Fatalf("Failed to list schemas: %v", err)
+	}
+	if len(schemas) != 2 || schemas[0].Type != "OrderCreated" || schemas[1].Type != "OrderShipped" {
+		t.Errorf("Expected schemas in file name order, got %+v", schemas)
+	}
+
+	contracts, err := registry.ListContracts("orders")
+	if err != nil {
+		t.Fatalf("Failed to list contracts: %v", err)
+	}
+	if len(contracts) != 1 || !reflect.DeepEqual(contracts[0], *contract) {
+		t.Errorf("Expected saved contract, got %+v", contracts)
+	}
+	if contracts, _ := registry.ListContracts("payments"); len(contracts) != 0 {
+		t.Errorf("Expected no contracts of other provider, got %+v", contracts)
+	}
+}
+
+func TestFileContractRegistry_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	registry := NewFileContractRegistry(dir)
+	if err := registry.writeJSON(dir+"/orders/schemas/event.OrderCreated.json", "not a schema"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := registry.ListSchemas("orders"); err == nil || !strings.Contains(err.Error(), "invalid registry file") {
+		t.Errorf("Expected invalid registry file error, got %v", err)
+	}
+}
+
+func TestContractVerification_ThroughRegistry(t *testing.T) {
+	registry := NewFileContractRegistry(t.TempDir())
+
+	recorder := NewContractRecorder()
+	recorder.RecordEvent(newOrderCreated(nil))
+	PublishSchemas(t, registry, "orders", recorder)
+
+	contract := NewConsumerContract("billing", "orders").
+		ExpectEvent("OrderCreated", map[string]FieldType{"order_id": FieldString, "customer.id": FieldString})
+	VerifyConsumerContract(t, registry, contract)
+
+	// Провайдер проверяет сохраненные контракты по текущим сообщениям
+	VerifyProviderContracts(t, registry, "orders", recorder)
+
+	changed := NewContractRecorder()
+	changed.RecordEvent(&struct {
+		*events.BaseEvent
+		OrderID int `json:"order_id"`
+	}{BaseEvent: events.NewBaseEvent("OrderCreated", "order-1"), OrderID: 1})
+	schemas, err := changed.Schemas()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	contracts, err := registry.ListContracts("orders")
+	if err != nil || len(contracts) != 1 {
+		t.Fatalf("Expected saved contract, got %v (%v)", contracts, err)
+	}
+	if violations := contracts[0].Verify(schemas); len(violations) != 2 {
+		t.Errorf("Expected breaking change to violate the contract twice, got %v", violations)
+	}
+}
@@ -0,0 +1,100 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaProvisioner создает Kafka topics
+type KafkaProvisioner struct {
+	brokers []string
+}
+
+// NewKafkaProvisioner создает провижинер для кластера Kafka
+func NewKafkaProvisioner(brokers []string) *KafkaProvisioner {
+	return &KafkaProvisioner{brokers: brokers}
+}
+
+// Provision создает отсутствующие topics. Число партиций существующего topic
+// не изменяется - расхождение возвращается как ActionConflict.
+func (p *KafkaProvisioner) Provision(ctx context.Context, spec *Spec, opts ProvisionOptions) (*Result, error) {
+	result := &Result{}
+	if len(spec.Kafka.Topics) == 0 {
+		return result, nil
+	}
+	if len(p.brokers) == 0 {
+		return nil, fmt.Errorf("at least one Kafka broker is required")
+	}
+
+	conn, err := kafka.DialContext(ctx, "tcp", p.brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topics: %w", err)
+	}
+	existing := make(map[string]int)
+	for _, partition := range partitions {
+		existing[partition.Topic]++
+	}
+
+	missing := make([]kafka.TopicConfig, 0)
+	for _, topic := range spec.Kafka.Topics {
+		if count, ok := existing[topic.Name]; ok {
+			if count != topic.Partitions {
+				result.add("topic", topic.Name, ActionConflict, fmt.Sprintf("partitions: %d -> %d", count, topic.Partitions))
+			} else {
+				result.add("topic", topic.Name, ActionUnchanged, "")
+			}
+			continue
+		}
+		missing = append(missing, topicConfig(topic))
+		result.add("topic", topic.Name, ActionCreate, fmt.Sprintf("partitions: %d", topic.Partitions))
+	}
+	if len(missing) == 0 || opts.DryRun {
+		return result, nil
+	}
+
+	// Topics создаются через контроллер кластера
+	controller, err := conn.Controller()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Kafka controller: %w", err)
+	}
+	controllerConn, err := kafka.DialContext(ctx, "tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Kafka controller: %w", err)
+	}
+	defer controllerConn.Close()
+
+	if err := controllerConn.CreateTopics(missing...); err != nil {
+		return nil, fmt.Errorf("failed to create topics: %w", err)
+	}
+	return result, nil
+}
+
+// topicConfig конвертирует спецификацию в конфигурацию Kafka topic
+func topicConfig(topic TopicSpec) kafka.TopicConfig {
+	replicationFactor := topic.ReplicationFactor
+	if replicationFactor <= 0 {
+		replicationFactor = 1
+	}
+	config := kafka.TopicConfig{
+		Topic:             topic.Name,
+		NumPartitions:     topic.Partitions,
+		ReplicationFactor: replicationFactor,
+	}
+	if topic.Retention > 0 {
+		config.ConfigEntries = append(config.ConfigEntries, kafka.ConfigEntry{
+			ConfigName:  "retention.ms",
+			ConfigValue: strconv.FormatInt(topic.Retention.Milliseconds(), 10),
+		})
+	}
+	return config
+}
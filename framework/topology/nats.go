@@ -0,0 +1,240 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSProvisioner создает и обновляет JetStream streams и durable consumers
+type NATSProvisioner struct {
+	js nats.JetStreamManager
+}
+
+// NewNATSProvisioner создает провижинер поверх JetStream (nats.Conn.JetStream())
+func NewNATSProvisioner(js nats.JetStreamManager) *NATSProvisioner {
+	return &NATSProvisioner{js: js}
+}
+
+// Provision создает отсутствующие streams и consumers и обновляет изменяемые параметры
+// существующих. Retention и storage stream после создания не меняются - такие
+// расхождения возвращаются как ActionConflict.
+func (p *NATSProvisioner) Provision(ctx context.Context, spec *Spec, opts ProvisionOptions) (*Result, error) {
+	result := &Result{}
+
+	for _, stream := range spec.NATS.Streams {
+		if err := p.provisionStream(ctx, stream, opts, result); err != nil {
+			return result, err
+		}
+	}
+	for _, consumer := range spec.NATS.Consumers {
+		if err := p.provisionConsumer(ctx, consumer, opts, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// provisionStream создает или обновляет stream
+func (p *NATSProvisioner) provisionStream(ctx context.Context, stream StreamSpec, opts ProvisionOptions, result *Result) error {
+	desired, err := streamConfig(stream)
+	if err != nil {
+		return err
+	}
+
+	info, err := p.js.StreamInfo(stream.Name, nats.Context(ctx))
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		if !opts.DryRun {
+			if _, err := p.js.AddStream(desired, nats.Context(ctx)); err != nil {
+				return fmt.Errorf("failed to create stream %s: %w", stream.Name, err)
+			}
+		}
+		result.add("stream", stream.Name, ActionCreate, "subjects: "+strings.Join(stream.Subjects, ","))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get stream %s: %w", stream.Name, err)
+	}
+
+	current := info.Config
+	var immutable diffs
+	immutable.check("retention", current.Retention, desired.Retention)
+	immutable.check("storage", current.Storage, desired.Storage)
+	if len(immutable) > 0 {
+		result.add("stream", stream.Name, ActionConflict, immutable.String())
+		return nil
+	}
+
+	var changed diffs
+	changed.check("subjects", sortedSubjects(current.Subjects), sortedSubjects(desired.Subjects))
+	changed.check("replicas", current.Replicas, desired.Replicas)
+	changed.check("max_age", current.MaxAge, desired.MaxAge)
+	changed.check("max_bytes", current.MaxBytes, desired.MaxBytes)
+	if len(changed) == 0 {
+		result.add("stream", stream.Name, ActionUnchanged, "")
+		return nil
+	}
+
+	if !opts.DryRun {
+		// Остальные параметры stream (лимиты, настроенные вручную) сохраняются
+		updated := current
+		updated.Subjects = desired.Subjects
+		updated.Replicas = desired.Replicas
+		updated.MaxAge = desired.MaxAge
+		updated.MaxBytes = desired.MaxBytes
+		if _, err := p.js.UpdateStream(&updated, nats.Context(ctx)); err != nil {
+			return fmt.Errorf("failed to update stream %s: %w", stream.Name, err)
+		}
+	}
+	result.add("stream", stream.Name, ActionUpdate, changed.String())
+	return nil
+}
+
+// provisionConsumer создает или обновляет durable consumer
+func (p *NATSProvisioner) provisionConsumer(ctx context.Context, consumer ConsumerSpec, opts ProvisionOptions, result *Result) error {
+	desired, err := consumerConfig(consumer)
+	if err != nil {
+		return err
+	}
+	name := consumer.Stream + "/" + consumer.Name
+
+	info, err := p.js.ConsumerInfo(consumer.Stream, consumer.Name, nats.Context(ctx))
+	// В dry-run stream может еще не существовать
+	if errors.Is(err, nats.ErrConsumerNotFound) || (opts.DryRun && errors.Is(err, nats.ErrStreamNotFound)) {
+		if !opts.DryRun {
+			if _, err := p.js.AddConsumer(consumer.Stream, desired, nats.Context(ctx)); err != nil {
+				return fmt.Errorf("failed to create consumer %s: %w", name, err)
+			}
+		}
+		result.add("consumer", name, ActionCreate, "filter: "+consumer.FilterSubject)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get consumer %s: %w", name, err)
+	}
+
+	current := info.Config
+	var immutable diffs
+	immutable.check("deliver_policy", current.DeliverPolicy, desired.DeliverPolicy)
+	if len(immutable) > 0 {
+		result.add("consumer", name, ActionConflict, immutable.String())
+		return nil
+	}
+
+	var changed diffs
+	changed.check("filter_subject", current.FilterSubject, desired.FilterSubject)
+	changed.check("ack_wait", current.AckWait, desired.AckWait)
+	changed.check("max_deliver", current.MaxDeliver, desired.MaxDeliver)
+	if len(changed) == 0 {
+		result.add("consumer", name, ActionUnchanged, "")
+		return nil
+	}
+
+	if !opts.DryRun {
+		updated := current
+		updated.FilterSubject = desired.FilterSubject
+		updated.AckWait = desired.AckWait
+		updated.MaxDeliver = desired.MaxDeliver
+		if _, err := p.js.UpdateConsumer(consumer.Stream, &updated, nats.Context(ctx)); err != nil {
+			return fmt.Errorf("failed to update consumer %s: %w", name, err)
+		}
+	}
+	result.add("consumer", name, ActionUpdate, changed.String())
+	return nil
+}
+
+// streamConfig конвертирует спецификацию в конфигурацию JetStream
+func streamConfig(stream StreamSpec) (*nats.StreamConfig, error) {
+	retention, err := parseRetention(stream.Retention)
+	if err != nil {
+		return nil, fmt.Errorf("stream %s: %w", stream.Name, err)
+	}
+	storage, err := parseStorage(stream.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("stream %s: %w", stream.Name, err)
+	}
+	replicas := stream.Replicas
+	if replicas <= 0 {
+		replicas = 1
+	}
+	maxBytes := stream.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = -1
+	}
+
+	return &nats.StreamConfig{
+		Name:      stream.Name,
+		Subjects:  stream.Subjects,
+		Retention: retention,
+		Storage:   storage,
+		Replicas:  replicas,
+		MaxAge:    stream.MaxAge,
+		MaxBytes:  maxBytes,
+		MaxMsgs:   -1,
+	}, nil
+}
+
+// consumerConfig конвертирует спецификацию в конфигурацию durable consumer
+func consumerConfig(consumer ConsumerSpec) (*nats.ConsumerConfig, error) {
+	deliverPolicy, err := parseDeliverPolicy(consumer.DeliverPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("consumer %s: %w", consumer.Name, err)
+	}
+	return &nats.ConsumerConfig{
+		Durable:       consumer.Name,
+		DeliverPolicy: deliverPolicy,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       consumer.AckWait,
+		MaxDeliver:    consumer.MaxDeliver,
+		FilterSubject: consumer.FilterSubject,
+	}, nil
+}
+
+// parseRetention разбирает политику хранения stream
+func parseRetention(value string) (nats.RetentionPolicy, error) {
+	switch strings.ToLower(value) {
+	case "", "limits":
+		return nats.LimitsPolicy, nil
+	case "interest":
+		return nats.InterestPolicy, nil
+	case "workqueue":
+		return nats.WorkQueuePolicy, nil
+	default:
+		return 0, fmt.Errorf("unknown retention %q (expected limits, interest or workqueue)", value)
+	}
+}
+
+// parseStorage разбирает тип хранилища stream
+func parseStorage(value string) (nats.StorageType, error) {
+	switch strings.ToLower(value) {
+	case "", "file":
+		return nats.FileStorage, nil
+	case "memory":
+		return nats.MemoryStorage, nil
+	default:
+		return 0, fmt.Errorf("unknown storage %q (expected file or memory)", value)
+	}
+}
+
+// parseDeliverPolicy разбирает начальную позицию consumer
+func parseDeliverPolicy(value string) (nats.DeliverPolicy, error) {
+	switch strings.ToLower(value) {
+	case "", "all":
+		return nats.DeliverAllPolicy, nil
+	case "new":
+		return nats.DeliverNewPolicy, nil
+	default:
+		return 0, fmt.Errorf("unknown deliver policy %q (expected all or new)", value)
+	}
+}
+
+// sortedSubjects возвращает отсортированную копию subjects для сравнения
+func sortedSubjects(subjects []string) []string {
+	sorted := append([]string(nil), subjects...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,222 @@
+// Package topology предоставляет провижининг топологии message bus (NATS JetStream streams
+// и consumers, Kafka topics) по спецификации, сгенерированной potter-gen (deploy/topology.yaml),
+// чтобы окружения не настраивались вручную.
+package topology
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec спецификация топологии message bus
+type Spec struct {
+	NATS  NATSTopology  `yaml:"nats"`
+	Kafka KafkaTopology `yaml:"kafka"`
+}
+
+// NATSTopology streams и consumers NATS JetStream
+type NATSTopology struct {
+	Streams   []StreamSpec   `yaml:"streams"`
+	Consumers []ConsumerSpec `yaml:"consumers"`
+}
+
+// StreamSpec спецификация JetStream stream
+type StreamSpec struct {
+	Name     string   `yaml:"name"`
+	Subjects []string `yaml:"subjects"`
+	// Retention политика хранения: limits (по умолчанию), interest или workqueue
+	Retention string `yaml:"retention"`
+	// Storage тип хранилища: file (по умолчанию) или memory
+	Storage string `yaml:"storage"`
+	// Replicas количество реплик (0 - 1)
+	Replicas int `yaml:"replicas"`
+	// MaxAge максимальный возраст сообщений (0 - без ограничения)
+	MaxAge time.Duration `yaml:"max_age"`
+	// MaxBytes максимальный размер stream (0 - без ограничения)
+	MaxBytes int64 `yaml:"max_bytes"`
+}
+
+// ConsumerSpec спецификация durable JetStream consumer
+type ConsumerSpec struct {
+	Stream        string `yaml:"stream"`
+	Name          string `yaml:"name"`
+	FilterSubject string `yaml:"filter_subject"`
+	// DeliverPolicy начальная позиция: all (по умолчанию) или new
+	DeliverPolicy string        `yaml:"deliver_policy"`
+	AckWait       time.Duration `yaml:"ack_wait"`
+	// MaxDeliver максимальное количество доставок (0 - без ограничения)
+	MaxDeliver int `yaml:"max_deliver"`
+}
+
+// KafkaTopology topics Kafka
+type KafkaTopology struct {
+	Topics []TopicSpec `yaml:"topics"`
+}
+
+// TopicSpec спецификация Kafka topic
+type TopicSpec struct {
+	Name       string `yaml:"name"`
+	Partitions int    `yaml:"partitions"`
+	// ReplicationFactor фактор репликации (0 - 1)
+	ReplicationFactor int `yaml:"replication_factor"`
+	// Retention время хранения сообщений (0 - настройка брокера)
+	Retention time.Duration `yaml:"retention"`
+}
+
+// LoadSpec читает спецификацию из YAML файла
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topology spec: %w", err)
+	}
+	return ParseSpec(data)
+}
+
+// ParseSpec разбирает и проверяет спецификацию в формате YAML
+func ParseSpec(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse topology spec: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate проверяет спецификацию
+func (s *Spec) Validate() error {
+	streams := make(map[string]bool, len(s.NATS.Streams))
+	for _, stream := range s.NATS.Streams {
+		if stream.Name == "" {
+			return fmt.Errorf("stream name cannot be empty")
+		}
+		if strings.ContainsAny(stream.Name, ". *>") {
+			return fmt.Errorf("stream %s: name cannot contain '.', ' ', '*' or '>'", stream.Name)
+		}
+		if streams[stream.Name] {
+			return fmt.Errorf("duplicate stream %s", stream.Name)
+		}
+		streams[stream.Name] = true
+		if len(stream.Subjects) == 0 {
+			return fmt.Errorf("stream %s: at least one subject is required", stream.Name)
+		}
+		if _, err := parseRetention(stream.Retention); err != nil {
+			return fmt.Errorf("stream %s: %w", stream.Name, err)
+		}
+		if _, err := parseStorage(stream.Storage); err != nil {
+			return fmt.Errorf("stream %s: %w", stream.Name, err)
+		}
+	}
+
+	consumers := make(map[string]bool, len(s.NATS.Consumers))
+	for _, consumer := range s.NATS.Consumers {
+		if consumer.Name == "" {
+			return fmt.Errorf("consumer name cannot be empty")
+		}
+		if !streams[consumer.Stream] {
+			return fmt.Errorf("consumer %s: unknown stream %q", consumer.Name, consumer.Stream)
+		}
+		key := consumer.Stream + "/" + consumer.Name
+		if consumers[key] {
+			return fmt.Errorf("duplicate consumer %s", key)
+		}
+		consumers[key] = true
+		if _, err := parseDeliverPolicy(consumer.DeliverPolicy); err != nil {
+			return fmt.Errorf("consumer %s: %w", consumer.Name, err)
+		}
+	}
+
+	topics := make(map[string]bool, len(s.Kafka.Topics))
+	for _, topic := range s.Kafka.Topics {
+		if topic.Name == "" {
+			return fmt.Errorf("topic name cannot be empty")
+		}
+		if topics[topic.Name] {
+			return fmt.Errorf("duplicate topic %s", topic.Name)
+		}
+		topics[topic.Name] = true
+		if topic.Partitions <= 0 {
+			return fmt.Errorf("topic %s: partitions must be positive", topic.Name)
+		}
+	}
+	return nil
+}
+
+// Action действие провижининга над ресурсом
+type Action string
+
+const (
+	// ActionCreate ресурс отсутствует и создается
+	ActionCreate Action = "create"
+	// ActionUpdate конфигурация ресурса отличается от спецификации и обновляется
+	ActionUpdate Action = "update"
+	// ActionUnchanged ресурс соответствует спецификации
+	ActionUnchanged Action = "unchanged"
+	// ActionConflict расхождение нельзя устранить автоматически (например, смена retention
+	// stream или уменьшение числа партиций topic) - требуется ручное вмешательство
+	ActionConflict Action = "conflict"
+)
+
+// Change изменение ресурса
+type Change struct {
+	// Kind тип ресурса: stream, consumer или topic
+	Kind   string
+	Name   string
+	Action Action
+	// Details описание расхождений со спецификацией
+	Details string
+}
+
+// Result результат провижининга
+type Result struct {
+	Changes []Change
+}
+
+// add добавляет изменение
+func (r *Result) add(kind, name string, action Action, details string) {
+	r.Changes = append(r.Changes, Change{Kind: kind, Name: name, Action: action, Details: details})
+}
+
+// HasConflicts проверяет наличие конфликтов
+func (r *Result) HasConflicts() bool {
+	for _, change := range r.Changes {
+		if change.Action == ActionConflict {
+			return true
+		}
+	}
+	return false
+}
+
+// ProvisionOptions параметры провижининга
+type ProvisionOptions struct {
+	// DryRun только вычисляет изменения, не применяя их
+	DryRun bool
+}
+
+// Provisioner создает и обновляет ресурсы брокера по спецификации. Провижининг
+// идемпотентен: существующие ресурсы, соответствующие спецификации, не изменяются,
+// а ресурсы, отсутствующие в спецификации, не удаляются.
+type Provisioner interface {
+	Provision(ctx context.Context, spec *Spec, opts ProvisionOptions) (*Result, error)
+}
+
+// diffs собирает описания расхождений
+type diffs []string
+
+// check добавляет расхождение, если значения различаются
+func (d *diffs) check(field string, current, desired interface{}) {
+	if fmt.Sprint(current) != fmt.Sprint(desired) {
+		*d = append(*d, fmt.Sprintf("%s: %v -> %v", field, current, desired))
+	}
+}
+
+// String возвращает расхождения через запятую
+func (d diffs) String() string {
+	return strings.Join(d, ", ")
+}
@@ -0,0 +1,192 @@
+package topology
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const testSpec = `
+nats:
+  streams:
+    - name: ORDERS_COMMANDS
+      subjects: [commands.create_order, commands.cancel_order]
+      retention: workqueue
+      replicas: 3
+    - name: ORDERS_EVENTS
+      subjects: [events.order.>]
+      max_age: 168h
+  consumers:
+    - stream: ORDERS_COMMANDS
+      name: orders-commands
+      filter_subject: commands.>
+      ack_wait: 30s
+      max_deliver: 5
+kafka:
+  topics:
+    - name: events.order.created
+      partitions: 3
+      retention: 168h
+`
+
+// fakeJetStream JetStreamManager в памяти для проверки провижининга
+type fakeJetStream struct {
+	nats.JetStreamManager
+	streams   map[string]*nats.StreamConfig
+	consumers map[string]*nats.ConsumerConfig
+	calls     []string
+}
+
+func newFakeJetStream() *fakeJetStream {
+	return &fakeJetStream{
+		streams:   make(map[string]*nats.StreamConfig),
+		consumers: make(map[string]*nats.ConsumerConfig),
+	}
+}
+
+func (f *fakeJetStream) StreamInfo(stream string, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	cfg, ok := f.streams[stream]
+	if !ok {
+		return nil, nats.ErrStreamNotFound
+	}
+	return &nats.StreamInfo{Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) AddStream(cfg *nats.StreamConfig, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	f.calls = append(f.calls, "add stream "+cfg.Name)
+	f.streams[cfg.Name] = cfg
+	return &nats.StreamInfo{Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) UpdateStream(cfg *nats.StreamConfig, opts ...nats.JSOpt) (*nats.StreamInfo, error) {
+	f.calls = append(f.calls, "update stream "+cfg.Name)
+	f.streams[cfg.Name] = cfg
+	return &nats.StreamInfo{Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) ConsumerInfo(stream, name string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	if _, ok := f.streams[stream]; !ok {
+		return nil, nats.ErrStreamNotFound
+	}
+	cfg, ok := f.consumers[stream+"/"+name]
+	if !ok {
+		return nil, nats.ErrConsumerNotFound
+	}
+	return &nats.ConsumerInfo{Stream: stream, Name: name, Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) AddConsumer(stream string, cfg *nats.ConsumerConfig, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	f.calls = append(f.calls, "add consumer "+stream+"/"+cfg.Durable)
+	f.consumers[stream+"/"+cfg.Durable] = cfg
+	return &nats.ConsumerInfo{Stream: stream, Name: cfg.Durable, Config: *cfg}, nil
+}
+
+func (f *fakeJetStream) UpdateConsumer(stream string, cfg *nats.ConsumerConfig, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	f.calls = append(f.calls, "update consumer "+stream+"/"+cfg.Durable)
+	f.consumers[stream+"/"+cfg.Durable] = cfg
+	return &nats.ConsumerInfo{Stream: stream, Name: cfg.Durable, Config: *cfg}, nil
+}
+
+func actions(result *Result) map[string]Action {
+	byName := make(map[string]Action, len(result.Changes))
+	for _, change := range result.Changes {
+		byName[change.Kind+" "+change.Name] = change.Action
+	}
+	return byName
+}
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	if len(spec.NATS.Streams) != 2 || spec.NATS.Streams[1].MaxAge != 168*time.Hour {
+		t.Errorf("Unexpected streams: %+v", spec.NATS.Streams)
+	}
+	if spec.NATS.Consumers[0].AckWait != 30*time.Second || spec.Kafka.Topics[0].Partitions != 3 {
+		t.Errorf("Unexpected spec: %+v", spec)
+	}
+
+	invalid := []string{
+		"nats:\n  streams:\n    - name: ORDERS\n",
+		"nats:\n  streams:\n    - name: orders.events\n      subjects: [events.>]\n",
+		"nats:\n  streams:\n    - name: ORDERS\n      subjects: [events.>]\n      retention: forever\n",
+		"nats:\n  consumers:\n    - stream: MISSING\n      name: c\n",
+		"kafka:\n  topics:\n    - name: events\n",
+	}
+	for _, data := range invalid {
+		if _, err := ParseSpec([]byte(data)); err == nil {
+			t.Errorf("Expected validation error for:\n%s", data)
+		}
+	}
+}
+
+func TestNATSProvisioner_Provision(t *testing.T) {
+	ctx := context.Background()
+	spec, err := ParseSpec([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	js := newFakeJetStream()
+	provisioner := NewNATSProvisioner(js)
+
+	// Dry-run вычисляет изменения без вызовов JetStream API
+	result, err := provisioner.Provision(ctx, spec, ProvisionOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry-run failed: %v", err)
+	}
+	if len(js.calls) != 0 || len(result.Changes) != 3 {
+		t.Fatalf("Expected 3 planned changes without calls, got %v / %+v", js.calls, result.Changes)
+	}
+
+	result, err = provisioner.Provision(ctx, spec, ProvisionOptions{})
+	if err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+	got := actions(result)
+	if got["stream ORDERS_COMMANDS"] != ActionCreate || got["consumer ORDERS_COMMANDS/orders-commands"] != ActionCreate {
+		t.Errorf("Expected streams and consumer to be created, got %v", got)
+	}
+	if cfg := js.streams["ORDERS_COMMANDS"]; cfg.Retention != nats.WorkQueuePolicy || cfg.Replicas != 3 {
+		t.Errorf("Unexpected stream config: %+v", cfg)
+	}
+	if cfg := js.consumers["ORDERS_COMMANDS/orders-commands"]; cfg.AckPolicy != nats.AckExplicitPolicy || cfg.MaxDeliver != 5 {
+		t.Errorf("Unexpected consumer config: %+v", cfg)
+	}
+
+	// Повторный провижининг идемпотентен
+	js.calls = nil
+	result, err = provisioner.Provision(ctx, spec, ProvisionOptions{})
+	if err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+	for _, change := range result.Changes {
+		if change.Action != ActionUnchanged {
+			t.Errorf("Expected no changes, got %+v", change)
+		}
+	}
+	if len(js.calls) != 0 {
+		t.Errorf("Expected no JetStream calls, got %v", js.calls)
+	}
+
+	// Изменяемые параметры обновляются, смена retention - конфликт
+	spec.NATS.Streams[0].Subjects = append(spec.NATS.Streams[0].Subjects, "commands.ship_order")
+	spec.NATS.Streams[1].Retention = "interest"
+	spec.NATS.Consumers[0].AckWait = time.Minute
+	result, err = provisioner.Provision(ctx, spec, ProvisionOptions{})
+	if err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+	got = actions(result)
+	if got["stream ORDERS_COMMANDS"] != ActionUpdate || got["consumer ORDERS_COMMANDS/orders-commands"] != ActionUpdate {
+		t.Errorf("Expected stream and consumer updates, got %v", got)
+	}
+	if got["stream ORDERS_EVENTS"] != ActionConflict || !result.HasConflicts() {
+		t.Errorf("Expected retention conflict, got %v", got)
+	}
+	if len(js.streams["ORDERS_COMMANDS"].Subjects) != 3 || js.consumers["ORDERS_COMMANDS/orders-commands"].AckWait != time.Minute {
+		t.Errorf("Expected updated configs, got %+v", js.calls)
+	}
+}
@@ -1,8 +1,10 @@
 package transport
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -369,3 +371,149 @@ func TestInMemoryQueryBus_HandlerError(t *testing.T) {
 	}
 }
 
+
+func TestNegotiateExportFormat(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected ExportFormat
+	}{
+		{"", ExportFormatJSON},
+		{"application/json", ExportFormatJSON},
+		{"text/csv", ExportFormatCSV},
+		{"text/csv; charset=utf-8", ExportFormatCSV},
+		{"application/x-ndjson", ExportFormatNDJSON},
+		{"text/html, application/ndjson;q=0.9", ExportFormatNDJSON},
+	}
+
+	for _, tt := range tests {
+		if got := NegotiateExportFormat(tt.accept); got != tt.expected {
+			t.Errorf("Accept %q: expected %s, got %s", tt.accept, tt.expected, got)
+		}
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	type item struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Count   int    `json:"count"`
+		Skipped string `json:"-"`
+	}
+	type listResponse struct {
+		Items []item
+		Total int
+	}
+	result := &listResponse{
+		Items: []item{{ID: "1", Name: "first, one", Count: 2}, {ID: "2", Name: "second", Count: 5}},
+		Total: 2,
+	}
+
+	var csvOut bytes.Buffer
+	if err := WriteExport(&csvOut, ExportFormatCSV, result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expectedCSV := "id,name,count\n1,\"first, one\",2\n2,second,5\n"
+	if csvOut.String() != expectedCSV {
+		t.Errorf("Expected CSV %q, got %q", expectedCSV, csvOut.String())
+	}
+
+	var ndjsonOut bytes.Buffer
+	if err := WriteExport(&ndjsonOut, ExportFormatNDJSON, result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(ndjsonOut.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 NDJSON lines, got %d", len(lines))
+	}
+	if lines[0] != `{"id":"1","name":"first, one","count":2}` {
+		t.Errorf("Unexpected NDJSON line: %s", lines[0])
+	}
+}
+
+// streamingQueryHandler обработчик, выгружающий строки потоково
+type streamingQueryHandler struct {
+	MockQueryHandler
+	rows int
+}
+
+func (h *streamingQueryHandler) Export(ctx context.Context, q Query, w *ExportWriter) error {
+	for i := 0; i < h.rows; i++ {
+		if err := w.WriteRow(map[string]int{"n": i}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushRecorder writer, считающий сброс данных клиенту
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes++
+}
+
+func TestInMemoryQueryBus_Export(t *testing.T) {
+	bus := NewInMemoryQueryBus()
+	streaming := &streamingQueryHandler{MockQueryHandler: MockQueryHandler{name: "stream_query"}, rows: 250}
+	if err := bus.Register(streaming); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+	if err := bus.Register(&MockQueryHandler{name: "list_query", result: []string{"a", "b"}}); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+	ctx := context.Background()
+
+	// Обработчик с QueryExporter пишет строки потоково, Handle не вызывается
+	out := &flushRecorder{}
+	writer, _ := NewExportWriter(out, ExportFormatNDJSON)
+	if err := ExportQuery(ctx, bus, MockQuery{name: "stream_query"}, writer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if streaming.handled {
+		t.Error("Expected Handle not to be called for streaming export")
+	}
+	if count := strings.Count(out.String(), "\n"); count != 250 {
+		t.Errorf("Expected 250 NDJSON lines, got %d", count)
+	}
+	if out.flushes < 2 {
+		t.Errorf("Expected rows to be flushed while exporting, got %d flushes", out.flushes)
+	}
+
+	// Остальные обработчики выгружаются по результату Handle
+	var csvOut bytes.Buffer
+	writer, _ = NewExportWriter(&csvOut, ExportFormatCSV)
+	if err := ExportQuery(ctx, bus, MockQuery{name: "list_query"}, writer); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if csvOut.String() != "value\na\nb\n" {
+		t.Errorf("Unexpected CSV: %q", csvOut.String())
+	}
+
+	writer, _ = NewExportWriter(&csvOut, ExportFormatCSV)
+	if err := ExportQuery(ctx, bus, MockQuery{name: "unknown"}, writer); err == nil {
+		t.Error("Expected error for unregistered query")
+	}
+}
+
+func TestWriteExport_NilEmbeddedPointer(t *testing.T) {
+	type Audit struct {
+		CreatedBy string `json:"created_by"`
+	}
+	type row struct {
+		ID string `json:"id"`
+		*Audit
+	}
+
+	var out bytes.Buffer
+	rows := []row{{ID: "1", Audit: &Audit{CreatedBy: "alice"}}, {ID: "2"}}
+	if err := WriteExport(&out, ExportFormatCSV, rows); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "id,created_by\n1,alice\n2,\n"
+	if out.String() != expected {
+		t.Errorf("Expected CSV %q, got %q", expected, out.String())
+	}
+}
@@ -0,0 +1,389 @@
+package transport
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat формат выгрузки результатов запроса
+type ExportFormat string
+
+const (
+	// ExportFormatJSON обычный JSON ответ (без выгрузки)
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatCSV выгрузка в CSV с заголовком
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatNDJSON выгрузка в NDJSON (одна JSON запись на строку)
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ContentType возвращает MIME тип формата
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case ExportFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// IsExport проверяет, является ли формат потоковой выгрузкой
+func (f ExportFormat) IsExport() bool {
+	return f == ExportFormatCSV || f == ExportFormatNDJSON
+}
+
+// NegotiateExportFormat определяет формат выгрузки по заголовку Accept.
+// Поддерживаются text/csv, application/x-ndjson и application/ndjson;
+// для остальных типов возвращается ExportFormatJSON.
+func NegotiateExportFormat(accept string) ExportFormat {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return ExportFormatCSV
+		case "application/x-ndjson", "application/ndjson":
+			return ExportFormatNDJSON
+		case "application/json", "*/*":
+			return ExportFormatJSON
+		}
+	}
+	return ExportFormatJSON
+}
+
+// exportFlushRows количество строк, после записи которых ExportWriter сбрасывает данные
+// клиенту: выгрузка отдается по мере чтения, а не одним ответом в конце
+const exportFlushRows = 100
+
+// QueryExporter выгружает результат запроса потоково, не собирая его в памяти.
+// Реализуется обработчиками запросов, читающими данные постранично (например,
+// saga.SagaQueryHandler для ListSagasQuery), и шинами запросов (InMemoryQueryBus).
+type QueryExporter interface {
+	Export(ctx context.Context, q Query, w *ExportWriter) error
+}
+
+// ExportWriter потоково записывает строки результата в CSV или NDJSON.
+// Для CSV заголовок формируется по полям первой записи (с учетом json тегов).
+type ExportWriter struct {
+	format  ExportFormat
+	out     io.Writer
+	csv     *csv.Writer
+	encoder *json.Encoder
+	columns []exportColumn
+	header  bool
+	rows    int
+}
+
+// exportColumn колонка CSV выгрузки
+type exportColumn struct {
+	name  string
+	index []int
+}
+
+// NewExportWriter создает новый ExportWriter
+func NewExportWriter(out io.Writer, format ExportFormat) (*ExportWriter, error) {
+	w := &ExportWriter{
+		format: format,
+		out:    out,
+	}
+
+	switch format {
+	case ExportFormatCSV:
+		w.csv = csv.NewWriter(out)
+	case ExportFormatNDJSON:
+		w.encoder = json.NewEncoder(out)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	return w, nil
+}
+
+// WriteRow записывает одну строку результата. Каждые exportFlushRows строк данные
+// сбрасываются в writer (см. Flush).
+func (w *ExportWriter) WriteRow(row interface{}) error {
+	if err := w.writeRow(row); err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%exportFlushRows == 0 {
+		return w.Flush()
+	}
+	return nil
+}
+
+// writeRow записывает строку в формате выгрузки
+func (w *ExportWriter) writeRow(row interface{}) error {
+	if w.format == ExportFormatNDJSON {
+		return w.encoder.Encode(row)
+	}
+
+	value := reflect.ValueOf(row)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if err := w.writeHeader(value); err != nil {
+		return err
+	}
+
+	record := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		if column.index == nil {
+			record[i] = formatExportValue(value)
+			continue
+		}
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+		// Поле встроенной структуры по nil указателю выгружается пустой ячейкой
+		field, err := value.FieldByIndexErr(column.index)
+		if err != nil {
+			continue
+		}
+		record[i] = formatExportValue(field)
+	}
+	return w.csv.Write(record)
+}
+
+// WriteHeader записывает заголовок CSV по образцу записи (например, нулевому значению типа).
+// Позволяет получить заголовок даже для пустой выгрузки; для NDJSON ничего не делает.
+func (w *ExportWriter) WriteHeader(sample interface{}) error {
+	if w.format != ExportFormatCSV {
+		return nil
+	}
+	value := reflect.ValueOf(sample)
+	for value.Kind() == reflect.Ptr {
+		value = reflect.New(value.Type().Elem()).Elem()
+	}
+	return w.writeHeader(value)
+}
+
+// writeHeader записывает заголовок CSV, если он еще не записан
+func (w *ExportWriter) writeHeader(value reflect.Value) error {
+	if w.header {
+		return nil
+	}
+	w.columns = exportColumns(value)
+	names := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		names[i] = column.name
+	}
+	if err := w.csv.Write(names); err != nil {
+		return err
+	}
+	w.header = true
+	return nil
+}
+
+// Flush сбрасывает буферизованные данные в writer. Если writer поддерживает
+// http.Flusher (например, gin.ResponseWriter), данные сразу отправляются клиенту.
+func (w *ExportWriter) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := w.out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// WriteResult записывает уже полученный результат запроса. Результат может быть
+// слайсом или структурой (указателем на структуру) со слайсом записей,
+// например SagaListResponse.Sagas; иначе результат выгружается как одна запись.
+func (w *ExportWriter) WriteResult(result interface{}) error {
+	rows, ok := exportRows(reflect.ValueOf(result))
+	if !ok {
+		return w.WriteRow(result)
+	}
+
+	for i := 0; i < rows.Len(); i++ {
+		if err := w.WriteRow(rows.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteExport выгружает уже полученный результат запроса в указанном формате (см. WriteResult).
+// Для выгрузки без материализации результата используйте ExportQuery.
+func WriteExport(out io.Writer, format ExportFormat, result interface{}) error {
+	w, err := NewExportWriter(out, format)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteResult(result); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// ExportQuery выполняет запрос и выгружает результат в w. Если шина реализует
+// QueryExporter, строки пишутся по мере чтения обработчиком; иначе результат Ask
+// выгружается целиком после выполнения запроса.
+func ExportQuery(ctx context.Context, bus QueryBus, q Query, w *ExportWriter) error {
+	if exporter, ok := bus.(QueryExporter); ok {
+		return exporter.Export(ctx, q, w)
+	}
+
+	result, err := bus.Ask(ctx, q)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteResult(result); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Export выгружает результат запроса в w (реализация QueryExporter). Обработчики,
+// реализующие QueryExporter, пишут строки потоково, результат остальных выгружается
+// после Handle. Middleware шины применяются, кэш не используется.
+func (b *InMemoryQueryBus) Export(ctx context.Context, q Query, w *ExportWriter) error {
+	b.mu.RLock()
+	handler, exists := b.handlers[q.QueryName()]
+	middleware := b.middleware
+	b.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("no handler registered for query: %s", q.QueryName())
+	}
+
+	next := func(ctx context.Context, q Query) (interface{}, error) {
+		if exporter, ok := handler.(QueryExporter); ok {
+			return nil, exporter.Export(ctx, q, w)
+		}
+		result, err := handler.Handle(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return nil, w.WriteResult(result)
+	}
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw := middleware[i]
+		prevNext := next
+		next = func(ctx context.Context, q Query) (interface{}, error) {
+			return mw.Intercept(ctx, q, prevNext)
+		}
+	}
+
+	if _, err := next(ctx, q); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// exportRows находит слайс записей в результате запроса
+func exportRows(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		return value, true
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if kind := field.Type.Kind(); kind == reflect.Slice && field.Type.Elem().Kind() != reflect.Uint8 {
+				return value.Field(i), true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// exportColumns формирует колонки CSV по полям структуры
+func exportColumns(value reflect.Value) []exportColumn {
+	if value.Kind() != reflect.Struct || value.Type() == reflect.TypeOf(time.Time{}) {
+		return []exportColumn{{name: "value"}}
+	}
+
+	columns := make([]exportColumn, 0, value.NumField())
+	for _, field := range reflect.VisibleFields(value.Type()) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		columns = append(columns, exportColumn{name: name, index: field.Index})
+	}
+	return columns
+}
+
+// formatExportValue форматирует значение для ячейки CSV
+func formatExportValue(value reflect.Value) string {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		data, err := json.Marshal(value.Interface())
+		if err != nil {
+			return fmt.Sprint(value.Interface())
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
@@ -38,6 +38,13 @@ type Subscriber interface {
 	Unsubscribe(subject string) error
 }
 
+// QueueSubscriber опциональный интерфейс шин с группами потребителей (NATS queue groups):
+// каждое сообщение subject получает только один подписчик группы queue
+type QueueSubscriber interface {
+	// QueueSubscribe подписывается на subject в группе потребителей queue
+	QueueSubscribe(ctx context.Context, subject, queue string, handler MessageHandler) error
+}
+
 // Publisher публикатор сообщений
 type Publisher interface {
 	// Publish публикует сообщение в subject
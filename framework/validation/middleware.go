@@ -0,0 +1,143 @@
+// Package validation предоставляет middleware шины команд для валидации.
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+// CommandValidationFailedEventType тип события об отклонении невалидной команды
+const CommandValidationFailedEventType = "command.validation_failed"
+
+// CommandValidationFailedEvent событие об отклонении команды валидацией.
+// Реализует интерфейс invoke.ErrorEvent, поэтому ожидающие ответа клиенты
+// получают ошибку с перечнем нарушенных правил.
+type CommandValidationFailedEvent struct {
+	*events.BaseEvent
+	CommandName string       `json:"command_name"`
+	Fields      []FieldError `json:"fields"`
+	err         error
+	command     transport.Command
+}
+
+// NewCommandValidationFailedEvent создает событие об отклонении команды
+func NewCommandValidationFailedEvent(cmd transport.Command, err error) *CommandValidationFailedEvent {
+	aggregateID := ""
+	if withAggregate, ok := cmd.(interface{ AggregateID() string }); ok {
+		aggregateID = withAggregate.AggregateID()
+	}
+
+	event := &CommandValidationFailedEvent{
+		BaseEvent:   events.NewBaseEvent(CommandValidationFailedEventType, aggregateID),
+		CommandName: cmd.CommandName(),
+		err:         err,
+		command:     cmd,
+	}
+
+	var validationErr *Error
+	if errors.As(err, &validationErr) {
+		event.Fields = validationErr.Fields
+	}
+
+	// Correlation ID связывает событие с командой для ожидающих ответа клиентов
+	if withMetadata, ok := cmd.(interface{ Metadata() transport.CommandMetadata }); ok && withMetadata.Metadata() != nil {
+		metadata := withMetadata.Metadata()
+		if metadata.CorrelationID() != "" {
+			event.WithCorrelationID(metadata.CorrelationID())
+		}
+		if metadata.ID() != "" {
+			event.WithCausationID(metadata.ID())
+		}
+	}
+	return event
+}
+
+// Error возвращает ошибку валидации
+func (e *CommandValidationFailedEvent) Error() error {
+	return e.err
+}
+
+// ErrorCode возвращает код ошибки
+func (e *CommandValidationFailedEvent) ErrorCode() string {
+	return ErrorCode
+}
+
+// ErrorMessage возвращает сообщение об ошибке
+func (e *CommandValidationFailedEvent) ErrorMessage() string {
+	return e.err.Error()
+}
+
+// IsRetryable всегда false: повтор невалидной команды завершится той же ошибкой
+func (e *CommandValidationFailedEvent) IsRetryable() bool {
+	return false
+}
+
+// OriginalCommand возвращает отклоненную команду
+func (e *CommandValidationFailedEvent) OriginalCommand() interface{} {
+	return e.command
+}
+
+// CommandValidationMiddleware middleware шины команд (transport.CommandInterceptor),
+// отклоняющий невалидные команды до вызова обработчика.
+// Команда проверяется методом Validate (Validatable) и валидаторами, зарегистрированными
+// через WithValidator. При отклонении публикуется CommandValidationFailedEvent.
+type CommandValidationMiddleware struct {
+	mu         sync.RWMutex
+	publisher  events.EventPublisher
+	validators map[string][]transport.CommandValidator
+}
+
+// NewCommandValidationMiddleware создает middleware валидации.
+// publisher может быть nil - тогда события об ошибках не публикуются.
+func NewCommandValidationMiddleware(publisher events.EventPublisher) *CommandValidationMiddleware {
+	return &CommandValidationMiddleware{
+		publisher:  publisher,
+		validators: make(map[string][]transport.CommandValidator),
+	}
+}
+
+// WithValidator добавляет валидатор для команды (например, проверки, зависящие от состояния)
+func (m *CommandValidationMiddleware) WithValidator(commandName string, validator transport.CommandValidator) *CommandValidationMiddleware {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validators[commandName] = append(m.validators[commandName], validator)
+	return m
+}
+
+// Intercept реализует transport.CommandInterceptor
+func (m *CommandValidationMiddleware) Intercept(ctx context.Context, cmd transport.Command, next func(ctx context.Context, cmd transport.Command) error) error {
+	if err := m.Validate(ctx, cmd); err != nil {
+		if m.publisher != nil {
+			if pubErr := m.publisher.Publish(ctx, NewCommandValidationFailedEvent(cmd, err)); pubErr != nil {
+				return fmt.Errorf("%w (failed to publish validation event: %v)", err, pubErr)
+			}
+		}
+		return err
+	}
+	return next(ctx, cmd)
+}
+
+// Validate проверяет команду без вызова обработчика
+func (m *CommandValidationMiddleware) Validate(ctx context.Context, cmd transport.Command) error {
+	if validatable, ok := cmd.(Validatable); ok {
+		if err := validatable.Validate(); err != nil {
+			return err
+		}
+	}
+
+	m.mu.RLock()
+	validators := m.validators[cmd.CommandName()]
+	m.mu.RUnlock()
+
+	for _, validator := range validators {
+		if err := validator.Validate(ctx, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
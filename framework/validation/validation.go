@@ -0,0 +1,148 @@
+// Package validation предоставляет валидацию команд по правилам полей, объявленным в proto (potter.field).
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Правила валидации полей
+const (
+	RuleRequired = "required"
+	RuleMin      = "min"
+	RuleMax      = "max"
+	RuleRegex    = "regex"
+)
+
+// ErrorCode код ошибки валидации команды
+const ErrorCode = "VALIDATION_FAILED"
+
+// FieldError нарушение правила валидации поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Params параметры правила для локализации сообщения: min, max, min_length, max_length, pattern
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Error ошибка валидации команды со списком нарушенных правил
+type Error struct {
+	Command string
+	Fields  []FieldError
+}
+
+// Error реализует интерфейс error
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field.Field, field.Message))
+	}
+	return fmt.Sprintf("validation failed for command %s: %s", e.Command, strings.Join(messages, "; "))
+}
+
+// Validatable команда, проверяющая собственные правила.
+// potter-gen генерирует Validate для команд с правилами potter.field.
+type Validatable interface {
+	Validate() error
+}
+
+// Validator собирает нарушения правил полей команды.
+// Используется сгенерированными методами Validate:
+//
+//	v := validation.New()
+//	v.Required("name", c.Name)
+//	v.Min("price", float64(c.Price), 0)
+//	return v.Err("create_product")
+type Validator struct {
+	fields []FieldError
+}
+
+// New создает новый Validator
+func New() *Validator {
+	return &Validator{}
+}
+
+// Required проверяет, что значение задано: непустая строка, коллекция или ненулевое значение
+func (v *Validator) Required(field string, value interface{}) {
+	if isEmpty(value) {
+		v.add(field, RuleRequired, "is required", nil)
+	}
+}
+
+// Min проверяет, что числовое значение не меньше min
+func (v *Validator) Min(field string, value, min float64) {
+	if value < min {
+		v.add(field, RuleMin, "must be greater than or equal to "+formatNumber(min), map[string]string{"min": formatNumber(min)})
+	}
+}
+
+// Max проверяет, что числовое значение не больше max
+func (v *Validator) Max(field string, value, max float64) {
+	if value > max {
+		v.add(field, RuleMax, "must be less than or equal to "+formatNumber(max), map[string]string{"max": formatNumber(max)})
+	}
+}
+
+// MinLength проверяет, что длина строки или коллекции не меньше min
+func (v *Validator) MinLength(field string, length, min int) {
+	if length < min {
+		v.add(field, RuleMin, fmt.Sprintf("length must be at least %d", min), map[string]string{"min_length": strconv.Itoa(min)})
+	}
+}
+
+// MaxLength проверяет, что длина строки или коллекции не больше max
+func (v *Validator) MaxLength(field string, length, max int) {
+	if length > max {
+		v.add(field, RuleMax, fmt.Sprintf("length must be at most %d", max), map[string]string{"max_length": strconv.Itoa(max)})
+	}
+}
+
+// Match проверяет, что строка соответствует шаблону. Пустая строка не проверяется
+// (обязательность задается правилом required).
+func (v *Validator) Match(field, value string, pattern *regexp.Regexp) {
+	if value != "" && !pattern.MatchString(value) {
+		v.add(field, RuleRegex, fmt.Sprintf("must match pattern %s", pattern.String()), map[string]string{"pattern": pattern.String()})
+	}
+}
+
+// Add добавляет нарушение пользовательского правила
+func (v *Validator) Add(field, rule, message string) {
+	v.add(field, rule, message, nil)
+}
+
+// Err возвращает *Error с собранными нарушениями или nil, если нарушений нет
+func (v *Validator) Err(command string) error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &Error{Command: command, Fields: v.fields}
+}
+
+func (v *Validator) add(field, rule, message string, params map[string]string) {
+	v.fields = append(v.fields, FieldError{Field: field, Rule: rule, Message: message, Params: params})
+}
+
+// isEmpty проверяет, является ли значение пустым для правила required
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
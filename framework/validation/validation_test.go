@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/akriventsev/potter/framework/events"
+	"github.com/akriventsev/potter/framework/transport"
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Z]{3}-\d+$`)
+
+// createProductCommand команда с правилами, как генерирует potter-gen
+type createProductCommand struct {
+	Name  string
+	Price float64
+	Sku   string
+	Tags  []string
+}
+
+func (c createProductCommand) CommandName() string {
+	return "create_product"
+}
+
+func (c createProductCommand) Validate() error {
+	v := New()
+	v.Required("name", c.Name)
+	v.MaxLength("name", len(c.Name), 10)
+	v.Min("price", c.Price, 0)
+	v.Match("sku", c.Sku, skuPattern)
+	v.MaxLength("tags", len(c.Tags), 2)
+	return v.Err("create_product")
+}
+
+func TestValidator_Rules(t *testing.T) {
+	if err := (createProductCommand{Name: "Phone", Price: 10, Sku: "ABC-1"}).Validate(); err != nil {
+		t.Fatalf("Expected valid command, got %v", err)
+	}
+
+	err := createProductCommand{Name: "", Price: -1, Sku: "abc", Tags: []string{"a", "b", "c"}}.Validate()
+	var validationErr *Error
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	want := []FieldError{
+		{Field: "name", Rule: RuleRequired},
+		{Field: "price", Rule: RuleMin},
+		{Field: "sku", Rule: RuleRegex},
+		{Field: "tags", Rule: RuleMax},
+	}
+	if len(validationErr.Fields) != len(want) {
+		t.Fatalf("Expected %d field errors, got %+v", len(want), validationErr.Fields)
+	}
+	for i, field := range validationErr.Fields {
+		if field.Field != want[i].Field || field.Rule != want[i].Rule {
+			t.Errorf("Field error %d = %+v, want %s/%s", i, field, want[i].Field, want[i].Rule)
+		}
+	}
+}
+
+// recordingHandler запоминает обработанные команды
+type recordingHandler struct {
+	calls int
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, cmd transport.Command) error {
+	h.calls++
+	return nil
+}
+
+func (h *recordingHandler) CommandName() string {
+	return "create_product"
+}
+
+// recordingPublisher запоминает опубликованные события
+type recordingPublisher struct {
+	published []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestCommandValidationMiddleware_RejectsInvalidCommand(t *testing.T) {
+	publisher := &recordingPublisher{}
+	handler := &recordingHandler{}
+	bus := transport.NewInMemoryCommandBus().WithMiddleware(NewCommandValidationMiddleware(publisher))
+	if err := bus.Register(handler); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := bus.Send(context.Background(), createProductCommand{Name: "Phone", Sku: "ABC-1"}); err != nil {
+		t.Fatalf("Expected valid command to pass, got %v", err)
+	}
+
+	err := bus.Send(context.Background(), createProductCommand{Price: 5})
+	var validationErr *Error
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+	if handler.calls != 1 {
+		t.Errorf("Expected invalid command not to reach handler, calls = %d", handler.calls)
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 error event, got %d", len(publisher.published))
+	}
+
+	event, ok := publisher.published[0].(*CommandValidationFailedEvent)
+	if !ok {
+		t.Fatalf("Expected *CommandValidationFailedEvent, got %T", publisher.published[0])
+	}
+	if event.EventType() != CommandValidationFailedEventType || event.ErrorCode() != ErrorCode || event.IsRetryable() {
+		t.Errorf("Unexpected event: type=%s code=%s retryable=%v", event.EventType(), event.ErrorCode(), event.IsRetryable())
+	}
+	if event.CommandName != "create_product" || len(event.Fields) != 1 || event.Fields[0].Field != "name" {
+		t.Errorf("Unexpected event fields: %s %+v", event.CommandName, event.Fields)
+	}
+}
+
+func TestCommandValidationMiddleware_WithValidator(t *testing.T) {
+	middleware := NewCommandValidationMiddleware(nil).
+		WithValidator("create_product", validatorFunc(func(ctx context.Context, cmd transport.Command) error {
+			v := New()
+			v.Add("name", "unique", "already exists")
+			return v.Err(cmd.CommandName())
+		}))
+
+	err := middleware.Intercept(context.Background(), createProductCommand{Name: "Phone"}, func(ctx context.Context, cmd transport.Command) error {
+		t.Fatal("Expected handler not to be called")
+		return nil
+	})
+	if err == nil || err.Error() != "validation failed for command create_product: name: already exists" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+type validatorFunc func(ctx context.Context, cmd transport.Command) error
+
+func (f validatorFunc) Validate(ctx context.Context, cmd transport.Command) error {
+	return f(ctx, cmd)
+}
@@ -6,14 +6,17 @@ go 1.25.0
 
 require (
 	github.com/99designs/gqlgen v0.17.49 // GraphQL server library
+	github.com/EventStore/EventStore-Client-Go/v4 v4.2.0 // EventStoreDB event store adapter
 	github.com/getkin/kin-openapi v0.122.0 // OpenAPI 3.0 parser and validator
 	github.com/gin-gonic/gin v1.9.1
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.1 // WebSocket transport adapter
 	github.com/jackc/pgx/v5 v5.7.5 // PostgreSQL repository adapter
 	github.com/nats-io/nats.go v1.31.0
+	github.com/prometheus/client_golang v1.17.0 // Prometheus metrics recorder
 	github.com/redis/go-redis/v9 v9.3.0 // Redis Streams messagebus adapter
 	github.com/segmentio/kafka-go v0.4.47 // Kafka messagebus and event adapter
+	github.com/ugorji/go/codec v1.2.11 // MessagePack event payload codec
 	github.com/vektah/gqlparser/v2 v2.5.16 // GraphQL parser (dependency of gqlgen)
 	go.mongodb.org/mongo-driver v1.13.1 // MongoDB repository adapter
 	go.opentelemetry.io/otel v1.38.0
@@ -27,6 +30,8 @@ require (
 )
 
 require (
+	github.com/klauspost/compress v1.18.0
+	github.com/mattn/go-sqlite3 v1.14.22 // SQLite driver for SQLiteEventStore tests
 	github.com/pressly/goose/v3 v3.26.0
 	github.com/stretchr/testify v1.11.1
 	go.opentelemetry.io/otel/exporters/jaeger v1.17.0
@@ -34,6 +39,7 @@ require (
 	go.opentelemetry.io/otel/sdk v1.38.0
 	go.opentelemetry.io/otel/trace v1.38.0
 	google.golang.org/protobuf v1.36.6
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -65,7 +71,6 @@ require (
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.4 // indirect
 	github.com/leodido/go-urn v1.2.4 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
@@ -84,14 +89,12 @@ require (
 	github.com/perimeterx/marshmallow v1.1.5 // indirect
 	github.com/pierrec/lz4/v4 v4.1.22 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/prometheus/client_golang v1.17.0 // indirect
 	github.com/prometheus/client_model v0.5.0 // indirect
 	github.com/prometheus/common v0.44.0 // indirect
 	github.com/prometheus/procfs v0.15.1 // indirect
 	github.com/sethvargo/go-retry v0.3.0 // indirect
 	github.com/sosodev/duration v1.3.1 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
-	github.com/ugorji/go/codec v1.2.11 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.1.2 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
@@ -107,5 +110,4 @@ require (
 	golang.org/x/text v0.27.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250603155806-513f23925822 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250603155806-513f23925822 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )